@@ -35,6 +35,9 @@
       "urlPrefix": "http://127.0.0.1:10262/scheduler",
       "filterVerb": "filter",
       "preemptVerb": "preempt",
+{{- if has "CapacityScheduling=true" .Values.features }}
+      "prioritizeVerb": "prioritize",
+{{- end }}
       "weight": 1,
       "httpTimeout": 30000000000,
       "enableHttps": false
@@ -14,6 +14,7 @@
 package main
 
 import (
+	"encoding/base64"
 	"flag"
 	"fmt"
 	"math/rand"
@@ -24,9 +25,18 @@ import (
 	"github.com/openshift/generic-admission-server/pkg/cmd"
 	"github.com/pingcap/tidb-operator/pkg/features"
 	"github.com/pingcap/tidb-operator/pkg/version"
+	"github.com/pingcap/tidb-operator/pkg/webhook/backup"
+	"github.com/pingcap/tidb-operator/pkg/webhook/configuration"
 	"github.com/pingcap/tidb-operator/pkg/webhook/pod"
+	"github.com/pingcap/tidb-operator/pkg/webhook/policy"
 	"github.com/pingcap/tidb-operator/pkg/webhook/statefulset"
 	"github.com/pingcap/tidb-operator/pkg/webhook/strategy"
+	"github.com/pingcap/tidb-operator/pkg/webhook/util"
+	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/component-base/logs"
 	"k8s.io/klog"
 )
@@ -35,6 +45,20 @@ var (
 	printVersion         bool
 	extraServiceAccounts string
 	minResyncDuration    time.Duration
+	deletionProtection   bool
+	auditMode            bool
+	operatorSA           string
+
+	manageWebhookConfigs    bool
+	validatingWebhooks      string
+	mutatingWebhooks        string
+	validationFailurePolicy string
+	mutationFailurePolicy   string
+	namespaceSelector       string
+	objectSelector          string
+	webhookCABundle         string
+	webhookSyncInterval     time.Duration
+	policyConfigMap         string
 )
 
 func init() {
@@ -43,6 +67,19 @@ func init() {
 	flag.BoolVar(&printVersion, "version", false, "Show version and quit")
 	flag.StringVar(&extraServiceAccounts, "extraServiceAccounts", "", "comma-separated, extra Service Accounts the Webhook should control. The full pattern for each common service account is system:serviceaccount:<namespace>:<serviceaccount-name>")
 	flag.DurationVar(&minResyncDuration, "min-resync-duration", 12*time.Hour, "The resync period in reflectors will be random between MinResyncPeriod and 2*MinResyncPeriod.")
+	flag.BoolVar(&deletionProtection, "pod-deletion-protection", false, "Refuse deleting the PD leader, TiKV stores whose region leaders are being evicted and the last healthy replica of each component by unknown Service Accounts unless the pod is annotated with tidb.pingcap.com/force-delete=true")
+	flag.BoolVar(&auditMode, "audit-mode", false, "Switch all validating webhooks into audit mode, the requests violating the validations are logged, emitted as events and recorded in the audit annotations but not denied")
+	flag.StringVar(&operatorSA, "operator-service-account", "", "The Service Account of tidb-controller-manager, the statefulset changes made by it are not checked against the member health. Defaults to system:serviceaccount:<namespace>:tidb-controller-manager")
+	flag.BoolVar(&manageWebhookConfigs, "manage-webhook-configurations", false, "Reconcile the ValidatingWebhookConfigurations and MutatingWebhookConfigurations of the webhooks by the admission webhook itself, so that they can't drift")
+	flag.StringVar(&validatingWebhooks, "validating-webhooks", "pods", "comma-separated, the validating webhooks to enable when --manage-webhook-configurations is set, available webhooks: pods, statefulsets, pingcapresources, backups, policies")
+	flag.StringVar(&mutatingWebhooks, "mutating-webhooks", "pods", "comma-separated, the mutating webhooks to enable when --manage-webhook-configurations is set, available webhooks: pods, pingcapresources")
+	flag.StringVar(&validationFailurePolicy, "validation-failure-policy", "", "The failurePolicy (Ignore or Fail) of the validating webhooks, the default policy of each webhook is used if it is empty")
+	flag.StringVar(&mutationFailurePolicy, "mutation-failure-policy", "", "The failurePolicy (Ignore or Fail) of the mutating webhooks, the default policy of each webhook is used if it is empty")
+	flag.StringVar(&namespaceSelector, "webhook-namespace-selector", "", "The label selector of the namespaces whose requests are sent to the webhooks, e.g. 'tidb-admission=enabled'")
+	flag.StringVar(&objectSelector, "webhook-object-selector", "", "The label selector of the objects whose requests are sent to the webhooks, it is merged with the default object selector of each webhook")
+	flag.StringVar(&webhookCABundle, "webhook-ca-bundle", "", "The base64 encoded CA bundle used by the webhooks to verify the kube-apiserver")
+	flag.DurationVar(&webhookSyncInterval, "webhook-configuration-sync-interval", time.Minute, "The interval to reconcile the webhook configurations")
+	flag.StringVar(&policyConfigMap, "tidbcluster-policy-configmap", "", "The name of the ConfigMap in the namespace of the webhook defining the per-namespace limits of TidbClusters, the policy webhook is disabled if it is empty")
 	features.DefaultFeatureGate.AddFlag(flag.CommandLine)
 }
 
@@ -72,9 +109,76 @@ func main() {
 	}
 	pod.AstsControllerServiceAccounts = fmt.Sprintf("system:serviceaccount:%s:advanced-statefulset-controller", ns)
 
-	podAdmissionHook := pod.NewPodAdmissionControl(strings.Split(extraServiceAccounts, ","), resyncDuration)
-	statefulSetAdmissionHook := statefulset.NewStatefulSetAdmissionControl()
+	podAdmissionHook := pod.NewPodAdmissionControl(strings.Split(extraServiceAccounts, ","), resyncDuration, deletionProtection)
+	if len(operatorSA) < 1 {
+		operatorSA = fmt.Sprintf("system:serviceaccount:%s:tidb-controller-manager", ns)
+	}
+	statefulSetAdmissionHook := statefulset.NewStatefulSetAdmissionControl([]string{operatorSA})
 	strategyAdmissionHook := strategy.NewStrategyAdmissionHook(&strategy.Registry)
+	backupAdmissionHook := backup.NewBackupAdmissionControl()
+
+	hooks := []cmd.AdmissionHook{podAdmissionHook, statefulSetAdmissionHook, strategyAdmissionHook, backupAdmissionHook}
+	if len(policyConfigMap) > 0 {
+		hooks = append(hooks, policy.NewPolicyAdmissionControl(ns, policyConfigMap, []string{operatorSA}, resyncDuration))
+	}
+	if auditMode {
+		klog.Info("validating webhooks are running in audit mode, violations would not be denied")
+		for i := range hooks {
+			hooks[i] = util.WithAuditMode(hooks[i])
+		}
+	}
+
+	if manageWebhookConfigs {
+		go reconcileWebhookConfigurations()
+	}
+
+	cmd.RunAdmissionServer(hooks...)
+}
+
+func reconcileWebhookConfigurations() {
+	config := configuration.Config{
+		ValidatingWebhooks:      splitList(validatingWebhooks),
+		MutatingWebhooks:        splitList(mutatingWebhooks),
+		ValidationFailurePolicy: admissionregistration.FailurePolicyType(validationFailurePolicy),
+		MutationFailurePolicy:   admissionregistration.FailurePolicyType(mutationFailurePolicy),
+	}
+	var err error
+	if len(namespaceSelector) > 0 {
+		if config.NamespaceSelector, err = metav1.ParseToLabelSelector(namespaceSelector); err != nil {
+			klog.Fatalf("failed to parse namespace selector %q: %v", namespaceSelector, err)
+		}
+	}
+	if len(objectSelector) > 0 {
+		if config.ObjectSelector, err = metav1.ParseToLabelSelector(objectSelector); err != nil {
+			klog.Fatalf("failed to parse object selector %q: %v", objectSelector, err)
+		}
+	}
+	if len(webhookCABundle) > 0 {
+		if config.CABundle, err = base64.StdEncoding.DecodeString(webhookCABundle); err != nil {
+			klog.Fatalf("failed to decode webhook CA bundle: %v", err)
+		}
+	}
+	if err := configuration.ValidateConfig(config); err != nil {
+		klog.Fatal(err)
+	}
 
-	cmd.RunAdmissionServer(podAdmissionHook, statefulSetAdmissionHook, strategyAdmissionHook)
+	cfg, err := rest.InClusterConfig()
+	if err != nil {
+		klog.Fatalf("failed to get config: %v", err)
+	}
+	kubeCli, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		klog.Fatalf("failed to create Clientset: %v", err)
+	}
+	configuration.NewReconciler(kubeCli, config).Run(webhookSyncInterval, wait.NeverStop)
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			list = append(list, item)
+		}
+	}
+	return list
 }
@@ -125,6 +125,9 @@ func constructOptions(backup *v1alpha1.Backup) ([]string, error) {
 	if config.TimeAgo != "" {
 		args = append(args, fmt.Sprintf("--timeago=%s", config.TimeAgo))
 	}
+	if config.BackupTS != "" {
+		args = append(args, fmt.Sprintf("--backupts=%s", config.BackupTS))
+	}
 	if config.Checksum != nil {
 		args = append(args, fmt.Sprintf("--checksum=%t", *config.Checksum))
 	}
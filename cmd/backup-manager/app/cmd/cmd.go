@@ -14,6 +14,7 @@
 package cmd
 
 import (
+	"github.com/pingcap/tidb-operator/pkg/backup/externalsecret"
 	"github.com/spf13/cobra"
 )
 
@@ -25,6 +26,10 @@ func NewBackupMgrCommand() *cobra.Command {
 		Short: "Helper for backup manage",
 		Long:  "Dump tidb cluster data, as well as backup and restore tidb cluster data",
 		Run:   runHelp,
+		// fetch the credentials referenced by externalSecret into the envs before running the commands
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return externalsecret.SetEnvs()
+		},
 	}
 
 	cmds.PersistentFlags().StringVarP(&kubecfg, "kubeconfig", "k", "", "Path to kubeconfig file, omit this if run in cluster.")
@@ -34,6 +39,7 @@ func NewBackupMgrCommand() *cobra.Command {
 	cmds.AddCommand(NewRestoreCommand())
 	cmds.AddCommand(NewImportCommand())
 	cmds.AddCommand(NewCleanCommand())
+	cmds.AddCommand(NewFetchSecretCommand())
 	return cmds
 }
 
@@ -0,0 +1,53 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/pingcap/tidb-operator/cmd/backup-manager/app/util"
+	"github.com/pingcap/tidb-operator/pkg/backup/externalsecret"
+	"github.com/spf13/cobra"
+	"k8s.io/klog"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+// NewFetchSecretCommand implements the fetch-secret command, which writes the keys of the external secrets
+// into the files of the output directory, e.g. the passwords of the users for TidbInitializer
+func NewFetchSecretCommand() *cobra.Command {
+	var outputDir string
+
+	cmd := &cobra.Command{
+		Use:   "fetch-secret",
+		Short: "Fetch the secrets from the external secret managers into files.",
+		// the secrets are written into files instead of the envs
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			util.ValidCmdFlags(cmd.CommandPath(), cmd.LocalFlags())
+			cmdutil.CheckErr(runFetchSecret(outputDir))
+		},
+	}
+
+	cmd.Flags().StringVar(&outputDir, "output-dir", "", "The directory to write the keys of the secrets into")
+	return cmd
+}
+
+func runFetchSecret(outputDir string) error {
+	bindings, err := externalsecret.LoadBindings()
+	if err != nil {
+		return err
+	}
+	klog.Infof("start to fetch %d external secrets into %s", len(bindings), outputDir)
+	return externalsecret.WriteFiles(bindings, outputDir, externalsecret.NewFetcher)
+}
@@ -21,29 +21,41 @@ import (
 	"os"
 	"os/signal"
 	"reflect"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
 	asclientset "github.com/pingcap/advanced-statefulset/client/client/clientset/versioned"
 	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
+	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/controller/autoscaler"
 	"github.com/pingcap/tidb-operator/pkg/controller/backup"
 	"github.com/pingcap/tidb-operator/pkg/controller/backupschedule"
 	"github.com/pingcap/tidb-operator/pkg/controller/dmcluster"
+	"github.com/pingcap/tidb-operator/pkg/controller/nodemaintenance"
 	"github.com/pingcap/tidb-operator/pkg/controller/periodicity"
 	"github.com/pingcap/tidb-operator/pkg/controller/restore"
+	"github.com/pingcap/tidb-operator/pkg/controller/tidbclienttls"
 	"github.com/pingcap/tidb-operator/pkg/controller/tidbcluster"
+	"github.com/pingcap/tidb-operator/pkg/controller/tidbclusterclone"
+	"github.com/pingcap/tidb-operator/pkg/controller/tidbclusterfederation"
+	"github.com/pingcap/tidb-operator/pkg/controller/tidbclusterreplication"
 	"github.com/pingcap/tidb-operator/pkg/controller/tidbinitializer"
 	"github.com/pingcap/tidb-operator/pkg/controller/tidbmonitor"
 	"github.com/pingcap/tidb-operator/pkg/features"
 	"github.com/pingcap/tidb-operator/pkg/metrics"
 	"github.com/pingcap/tidb-operator/pkg/scheme"
 	"github.com/pingcap/tidb-operator/pkg/upgrader"
+	"github.com/pingcap/tidb-operator/pkg/util/logging"
+	"github.com/pingcap/tidb-operator/pkg/util/tracing"
 	"github.com/pingcap/tidb-operator/pkg/version"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apiserver/pkg/server/healthz"
+	"k8s.io/apiserver/pkg/server/routes"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -55,11 +67,23 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+const (
+	// leaderElectionHealthTimeout is how long the leader may fail to renew
+	// an expired lock before it is reported unhealthy
+	leaderElectionHealthTimeout = 20 * time.Second
+	// apiServerHealthTimeout is the timeout of the kube-apiserver health check
+	apiServerHealthTimeout = 5 * time.Second
+	// controllerShutdownTimeout is how long to wait for the controllers to finish the items in process on shutdown
+	controllerShutdownTimeout = 15 * time.Second
+)
+
 func main() {
 	var cfg *rest.Config
 	cliCfg := controller.DefaultCLIConfig()
 	cliCfg.AddFlag(flag.CommandLine)
 	features.DefaultFeatureGate.AddFlag(flag.CommandLine)
+	logging.AddFlags(flag.CommandLine)
+	tracing.AddFlags(flag.CommandLine)
 	flag.Parse()
 
 	if cliCfg.PrintVersion {
@@ -69,6 +93,15 @@ func main() {
 
 	logs.InitLogs()
 	defer logs.FlushLogs()
+	if err := logging.Init(flag.CommandLine); err != nil {
+		klog.Fatal(err)
+	}
+	stopTracing, err := tracing.Init("tidb-controller-manager")
+	if err != nil {
+		klog.Fatal(err)
+	}
+	// the spans in the queue are flushed on graceful shutdown
+	defer stopTracing()
 
 	version.LogVersionInfo()
 	flag.VisitAll(func(flag *flag.Flag) {
@@ -121,12 +154,27 @@ func main() {
 		klog.Fatalf("failed to get the generic kube-apiserver client: %v", err)
 	}
 
+	if cliCfg.ClusterScoped && cliCfg.WatchNamespaces != "" {
+		klog.Fatal("watch-namespaces can only be set if cluster-scoped is false")
+	}
+	if err := cliCfg.ValidateControllerOverrides(); err != nil {
+		klog.Fatal(err)
+	}
+	if err := cliCfg.ValidateLeaderElection(); err != nil {
+		klog.Fatal(err)
+	}
+	namespaces := cliCfg.GetWatchNamespaces(ns)
+	klog.Infof("tidb-operator watches namespaces %q", namespaces)
+
 	// note that kubeCli here must not be the hijacked one
-	var operatorUpgrader upgrader.Interface
-	if cliCfg.ClusterScoped {
-		operatorUpgrader = upgrader.NewUpgrader(kubeCli, cli, asCli, metav1.NamespaceAll)
-	} else {
-		operatorUpgrader = upgrader.NewUpgrader(kubeCli, cli, asCli, ns)
+	var operatorUpgraders []upgrader.Interface
+	for _, watchNs := range namespaces {
+		operatorUpgraders = append(operatorUpgraders, upgrader.NewUpgrader(kubeCli, cli, asCli, watchNs))
+	}
+
+	if features.DefaultFeatureGate.Enabled(features.AdvancedStatefulSet) && features.DefaultFeatureGate.Enabled(features.ServerSideApply) {
+		// The StatefulSets are applied with the REST client of apps/v1, which is not hijacked.
+		klog.Fatalf("feature gates %s and %s can not be enabled at the same time", features.AdvancedStatefulSet, features.ServerSideApply)
 	}
 
 	if features.DefaultFeatureGate.Enabled(features.AdvancedStatefulSet) {
@@ -135,13 +183,30 @@ func main() {
 		kubeCli = helper.NewHijackClient(kubeCli, asCli)
 	}
 
-	deps := controller.NewDependencies(ns, cliCfg, cli, kubeCli, genericCli)
+	// The informers and controllers are run for each watched namespace if the operator is not
+	// cluster scoped, so that only the permissions of Roles are required in these namespaces.
+	var depsList []*controller.Dependencies
+	var tcListers []listers.TidbClusterLister
+	for _, watchNs := range namespaces {
+		deps := controller.NewDependencies(watchNs, cliCfg, cli, kubeCli, genericCli)
+		depsList = append(depsList, deps)
+		tcListers = append(tcListers, deps.TiDBClusterLister)
+	}
+	metrics.RegisterClusterStatusCollector(tcListers...)
 
+	informerSync := controller.NewInformerSyncChecker()
+	// controllersStop is closed on shutdown to stop the controllers, controllersRunning waits for them to
+	// return, shutdownLock makes sure no controller is started after the shutdown begins
+	controllersStop := make(chan struct{})
+	var controllersRunning sync.WaitGroup
+	var shutdownLock sync.Mutex
 	onStarted := func(ctx context.Context) {
 		// Upgrade before running any controller logic. If it fails, we wait
 		// for process supervisor to restart it again.
-		if err := operatorUpgrader.Upgrade(); err != nil {
-			klog.Fatalf("failed to upgrade: %v", err)
+		for _, operatorUpgrader := range operatorUpgraders {
+			if err := operatorUpgrader.Upgrade(); err != nil {
+				klog.Fatalf("failed to upgrade: %v", err)
+			}
 		}
 
 		// Define some nested types to simplify the codebase
@@ -154,28 +219,47 @@ func main() {
 		}
 
 		// Initialize all controllers
-		controllers := []Controller{
-			tidbcluster.NewController(deps),
-			dmcluster.NewController(deps),
-			backup.NewController(deps),
-			restore.NewController(deps),
-			backupschedule.NewController(deps),
-			tidbinitializer.NewController(deps),
-			tidbmonitor.NewController(deps),
-		}
-		if cliCfg.PodWebhookEnabled {
-			controllers = append(controllers, periodicity.NewController(deps))
-		}
-		if features.DefaultFeatureGate.Enabled(features.AutoScaling) {
-			controllers = append(controllers, autoscaler.NewController(deps))
+		var controllers []Controller
+		var informerFactories []InformerFactory
+		workers := map[Controller]int{}
+		addController := func(name string, c Controller) {
+			controllers = append(controllers, c)
+			workers[c] = cliCfg.GetWorkers(name)
 		}
+		for _, deps := range depsList {
+			addController("tidbcluster", tidbcluster.NewController(deps))
+			addController("dmcluster", dmcluster.NewController(deps))
+			addController("backup", backup.NewController(deps))
+			addController("restore", restore.NewController(deps))
+			addController("backupschedule", backupschedule.NewController(deps))
+			addController("tidbinitializer", tidbinitializer.NewController(deps))
+			addController("tidbclusterfederation", tidbclusterfederation.NewController(deps))
+			addController("tidbclusterreplication", tidbclusterreplication.NewController(deps))
+			addController("tidbclusterclone", tidbclusterclone.NewController(deps))
+			addController("tidbmonitor", tidbmonitor.NewController(deps))
+			if cliCfg.PodWebhookEnabled {
+				addController("periodicity", periodicity.NewController(deps))
+			}
+			if features.DefaultFeatureGate.Enabled(features.AutoScaling) {
+				addController("autoscaler", autoscaler.NewController(deps))
+			}
+			if cliCfg.NodeMaintenance && cliCfg.HasNodePermission() {
+				// the pods on the nodes are handled by the controller of their namespace
+				addController("nodemaintenance", nodemaintenance.NewController(deps))
+			}
+			if cliCfg.ClusterScoped {
+				// the secrets are propagated across namespaces, so it requires the cluster permissions
+				addController("tidbclienttls", tidbclienttls.NewController(deps))
+			}
 
-		// Start informer factories after all controllers are initialized.
-		informerFactories := []InformerFactory{
-			deps.InformerFactory,
-			deps.KubeInformerFactory,
-			deps.LabelFilterKubeInformerFactory,
+			// Start informer factories after all controllers are initialized.
+			informerFactories = append(informerFactories,
+				deps.InformerFactory,
+				deps.KubeInformerFactory,
+				deps.LabelFilterKubeInformerFactory,
+			)
 		}
+		informerSync.Started()
 		for _, f := range informerFactories {
 			f.Start(ctx.Done())
 			for v, synced := range f.WaitForCacheSync(wait.NeverStop) {
@@ -184,47 +268,100 @@ func main() {
 				}
 			}
 		}
+		informerSync.Synced()
 		klog.Info("cache of informer factories sync successfully")
 
+		shutdownLock.Lock()
+		defer shutdownLock.Unlock()
+		select {
+		case <-controllersStop:
+			klog.Info("shutting down, the controllers are not started")
+			return
+		default:
+		}
+		stopCh := make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+			case <-controllersStop:
+			}
+			close(stopCh)
+		}()
 		// Start syncLoop for all controllers
 		for _, controller := range controllers {
 			c := controller
-			go wait.Forever(func() { c.Run(cliCfg.Workers, ctx.Done()) }, cliCfg.WaitDuration)
+			controllersRunning.Add(1)
+			go func() {
+				defer controllersRunning.Done()
+				wait.Until(func() { c.Run(workers[c], stopCh) }, cliCfg.WaitDuration, stopCh)
+			}()
 		}
 	}
+	// leaderCtx is canceled on shutdown to release the lock, so that
+	// the other instances can take over without waiting for it to expire
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
 	onStopped := func() {
-		klog.Fatal("leader election lost")
+		select {
+		case <-leaderCtx.Done():
+			klog.Info("leader election stopped on shutdown")
+		default:
+			klog.Fatal("leader election lost")
+		}
 	}
 
-	endPointsName := "tidb-controller-manager"
+	lockName := "tidb-controller-manager"
 	if helmRelease != "" {
-		endPointsName += "-" + helmRelease
+		lockName += "-" + helmRelease
+	}
+	lock, err := controller.NewLeaderElectionLock(cliCfg.LeaderResourceLock, ns, lockName, kubeCli, resourcelock.ResourceLockConfig{
+		Identity:      hostName,
+		EventRecorder: &record.FakeRecorder{},
+	})
+	if err != nil {
+		klog.Fatalf("failed to create the leader election lock: %v", err)
 	}
+	// leaderHealth fails if the leader does not renew the lock in time
+	leaderHealth := leaderelection.NewLeaderHealthzAdaptor(leaderElectionHealthTimeout)
 	// leader election for multiple tidb-controller-manager instances
-	go wait.Forever(func() {
-		leaderelection.RunOrDie(context.TODO(), leaderelection.LeaderElectionConfig{
-			Lock: &resourcelock.EndpointsLock{
-				EndpointsMeta: metav1.ObjectMeta{
-					Namespace: ns,
-					Name:      endPointsName,
-				},
-				Client: kubeCli.CoreV1(),
-				LockConfig: resourcelock.ResourceLockConfig{
-					Identity:      hostName,
-					EventRecorder: &record.FakeRecorder{},
+	leaderElectionDone := make(chan struct{})
+	go func() {
+		defer close(leaderElectionDone)
+		wait.Until(func() {
+			leaderelection.RunOrDie(leaderCtx, leaderelection.LeaderElectionConfig{
+				Lock:            lock,
+				LeaseDuration:   cliCfg.LeaseDuration,
+				RenewDeadline:   cliCfg.RenewDeadline,
+				RetryPeriod:     cliCfg.RetryPeriod,
+				ReleaseOnCancel: true,
+				WatchDog:        leaderHealth,
+				Callbacks: leaderelection.LeaderCallbacks{
+					OnStartedLeading: onStarted,
+					OnStoppedLeading: onStopped,
 				},
-			},
-			LeaseDuration: cliCfg.LeaseDuration,
-			RenewDeadline: cliCfg.RenewDeadline,
-			RetryPeriod:   cliCfg.RetryPeriod,
-			Callbacks: leaderelection.LeaderCallbacks{
-				OnStartedLeading: onStarted,
-				OnStoppedLeading: onStopped,
-			},
-		})
-	}, cliCfg.WaitDuration)
+			})
+		}, cliCfg.WaitDuration, leaderCtx.Done())
+	}()
 
-	srv := createHTTPServer()
+	healthCfg := rest.CopyConfig(cfg)
+	healthCfg.Timeout = apiServerHealthTimeout
+	discoveryCli, err := discovery.NewDiscoveryClientForConfig(healthCfg)
+	if err != nil {
+		klog.Fatalf("failed to create the discovery client: %v", err)
+	}
+	// healthz restarts the wedged operator, readyz also reports the dependencies
+	// which are not fixed by a restart
+	healthzChecks := []healthz.HealthChecker{
+		healthz.PingHealthz,
+		leaderHealth,
+		informerSync.Checker(cliCfg.CacheSyncTimeout),
+	}
+	readyzChecks := []healthz.HealthChecker{
+		healthz.PingHealthz,
+		leaderHealth,
+		informerSync.Checker(0),
+		controller.APIServerChecker(discoveryCli),
+	}
+	srv := createHTTPServer(healthzChecks, readyzChecks)
 	sc := make(chan os.Signal, 1)
 	signal.Notify(sc,
 		syscall.SIGHUP,
@@ -236,6 +373,29 @@ func main() {
 	go func() {
 		sig := <-sc
 		klog.Infof("got signal %s to exit", sig)
+		// the controllers are drained before the lock is released, so that the new leader
+		// does not start while this instance is still writing
+		shutdownLock.Lock()
+		close(controllersStop)
+		shutdownLock.Unlock()
+		drained := make(chan struct{})
+		go func() {
+			controllersRunning.Wait()
+			close(drained)
+		}()
+		select {
+		case <-drained:
+			klog.Info("all controllers are stopped")
+			cancelLeader()
+			select {
+			case <-leaderElectionDone:
+			case <-time.After(cliCfg.RenewDeadline):
+				klog.Warning("timeout to release the leader election lock")
+			}
+		case <-time.After(controllerShutdownTimeout):
+			// the lock is kept until it expires, since the workers may still be writing
+			klog.Warningf("timeout to stop the controllers in %s, exit without releasing the leader election lock", controllerShutdownTimeout)
+		}
 		if err2 := srv.Shutdown(context.Background()); err2 != nil {
 			klog.Fatal("fail to shutdown the HTTP server", err2)
 		}
@@ -247,10 +407,16 @@ func main() {
 	klog.Infof("tidb-controller-manager exited")
 }
 
-func createHTTPServer() *http.Server {
+func createHTTPServer(healthzChecks, readyzChecks []healthz.HealthChecker) *http.Server {
 	serverMux := http.NewServeMux()
 	// HTTP path for prometheus.
 	serverMux.Handle("/metrics", promhttp.Handler())
+	// HTTP paths for the liveness and readiness probes.
+	healthz.InstallHandler(serverMux, healthzChecks...)
+	healthz.InstallPathHandler(serverMux, "/readyz", readyzChecks...)
+	// HTTP paths to change the verbosity of the logs at runtime.
+	serverMux.Handle("/debug/flags/v", routes.StringFlagPutHandler(logs.GlogSetter))
+	serverMux.Handle("/debug/flags/log-module-levels", routes.StringFlagPutHandler(logging.SetModuleLevels))
 
 	return &http.Server{
 		Addr:    ":6060",
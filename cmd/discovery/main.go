@@ -91,7 +91,9 @@ func main() {
 	go wait.Forever(func() {
 		addr := fmt.Sprintf("0.0.0.0:%d", port)
 		klog.Infof("starting TiDB Discovery server, listening on %s", addr)
-		discoveryServer := server.NewServer(pdapi.NewDefaultPDControl(kubeCli), dmapi.NewDefaultMasterControl(kubeCli), cli, kubeCli)
+		tlsConfigs := pdapi.NewTLSConfigCache(kubeCli)
+		pdControl := pdapi.NewDefaultPDControlWithOptions(kubeCli, pdapi.PDControlOptions{TLSConfigs: tlsConfigs})
+		discoveryServer := server.NewServer(pdControl, dmapi.NewDefaultMasterControl(kubeCli, tlsConfigs), cli, kubeCli)
 		discoveryServer.ListenAndServe(addr)
 	}, 5*time.Second)
 	go wait.Forever(func() {
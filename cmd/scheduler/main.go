@@ -25,8 +25,10 @@ import (
 
 	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
 	"github.com/pingcap/tidb-operator/pkg/features"
+	"github.com/pingcap/tidb-operator/pkg/metrics"
 	"github.com/pingcap/tidb-operator/pkg/scheduler/server"
 	"github.com/pingcap/tidb-operator/pkg/version"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -74,6 +76,10 @@ func main() {
 		klog.Fatalf("failed to create Clientset: %v", err)
 	}
 
+	metrics.RegisterSchedulerMetrics()
+	// HTTP path for prometheus.
+	http.Handle("/metrics", promhttp.Handler())
+
 	go wait.Forever(func() {
 		server.StartServer(kubeCli, cli, port)
 	}, 5*time.Second)
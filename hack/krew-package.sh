@@ -0,0 +1,64 @@
+#!/usr/bin/env bash
+
+# Copyright 2020 PingCAP, Inc.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+set -euo pipefail
+# Builds the archives of the kubectl-tidb plugin for the platforms supported by
+# krew and renders the krew plugin manifest misc/krew/tidb.yaml with them.
+#
+# Usage: hack/krew-package.sh VERSION
+#
+# The archives and the manifest are written to output/krew. The archives are
+# expected to be uploaded to DOWNLOAD_URL, which defaults to the GitHub release
+# of the version.
+
+ROOT=$(unset CDPATH && cd $(dirname "${BASH_SOURCE[0]}")/.. && pwd)
+cd $ROOT
+
+if [ $# -ne 1 ]; then
+    echo "usage: $0 VERSION" >&2
+    exit 1
+fi
+
+VERSION=$1
+DOWNLOAD_URL=${DOWNLOAD_URL:-https://github.com/pingcap/tidb-operator/releases/download/${VERSION}}
+OUTPUT=${ROOT}/output/krew
+PLATFORMS=(linux/amd64 linux/arm64 darwin/amd64)
+
+rm -rf ${OUTPUT}
+mkdir -p ${OUTPUT}
+
+declare -A SHA256
+for platform in ${PLATFORMS[@]}; do
+    os=${platform%/*}
+    arch=${platform#*/}
+    name=kubectl-tidb-${os}-${arch}
+    workdir=${OUTPUT}/${name}
+    mkdir -p ${workdir}
+    echo "info: building ${name}"
+    GOOS=${os} GOARCH=${arch} make cli CLI_OUTPUT=${workdir}/kubectl-tidb
+    cp LICENSE ${workdir}/
+    tar -C ${workdir} -czf ${OUTPUT}/${name}.tar.gz kubectl-tidb LICENSE
+    rm -rf ${workdir}
+    SHA256[${os}_${arch}]=$(sha256sum ${OUTPUT}/${name}.tar.gz | awk '{print $1}')
+done
+
+sed -e "s#\${VERSION}#${VERSION}#g" \
+    -e "s#\${DOWNLOAD_URL}#${DOWNLOAD_URL}#g" \
+    -e "s#\${SHA256_LINUX_AMD64}#${SHA256[linux_amd64]}#g" \
+    -e "s#\${SHA256_LINUX_ARM64}#${SHA256[linux_arm64]}#g" \
+    -e "s#\${SHA256_DARWIN_AMD64}#${SHA256[darwin_amd64]}#g" \
+    misc/krew/tidb.yaml > ${OUTPUT}/tidb.yaml
+
+echo "info: krew plugin manifest is written to ${OUTPUT}/tidb.yaml"
@@ -30,6 +30,7 @@ FILELIST=($(find . -type f -not \( -path './vendor/*' \
     -o -path './tests/images/*/bin/*' \
     -o -path '*.png' \
     -o -path './tkctl' \
+    -o -path './kubectl-tidb' \
     -o -path './.idea/*' \
     -o -path './.DS_Store' \
     -o -path './*/.DS_Store' \
@@ -40,6 +40,9 @@ to-crdgen generate backupschedule >> $crd_target
 to-crdgen generate tidbmonitor >> $crd_target
 to-crdgen generate tidbinitializer >> $crd_target
 to-crdgen generate tidbclusterautoscaler >> $crd_target
+to-crdgen generate tidbclusterfederation >> $crd_target
+to-crdgen generate tidbclusterreplication >> $crd_target
+to-crdgen generate tidbclusterclone >> $crd_target
 
 hack::ensure_gen_crd_api_references_docs
 
@@ -150,3 +150,11 @@ func IsCleanCandidate(backup *Backup) bool {
 func NeedNotClean(backup *Backup) bool {
 	return backup.Spec.CleanPolicy == CleanPolicyTypeOnFailure && !IsBackupFailed(backup)
 }
+
+// GetMountPath returns the mount path of the Kubernetes auth method of Vault
+func (v *ExternalSecretVault) GetMountPath() string {
+	if v.MountPath == "" {
+		return defaultVaultKubernetesAuthPath
+	}
+	return v.MountPath
+}
@@ -53,6 +53,18 @@ const (
 	TidbClusterAutoScalerKind    = "TidbClusterAutoScaler"
 	TidbClusterAutoScalerKindKey = "tidbclusterautoscaler"
 
+	TidbClusterFederationName    = "tidbclusterfederations"
+	TidbClusterFederationKind    = "TidbClusterFederation"
+	TidbClusterFederationKindKey = "tidbclusterfederation"
+
+	TidbClusterReplicationName    = "tidbclusterreplications"
+	TidbClusterReplicationKind    = "TidbClusterReplication"
+	TidbClusterReplicationKindKey = "tidbclusterreplication"
+
+	TidbClusterCloneName    = "tidbclusterclones"
+	TidbClusterCloneKind    = "TidbClusterClone"
+	TidbClusterCloneKindKey = "tidbclusterclone"
+
 	SpecPath = "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1."
 )
 
@@ -65,25 +77,31 @@ type CrdKind struct {
 }
 
 type CrdKinds struct {
-	KindsString           string
-	TiDBCluster           CrdKind
-	DMCluster             CrdKind
-	Backup                CrdKind
-	Restore               CrdKind
-	BackupSchedule        CrdKind
-	TiDBMonitor           CrdKind
-	TiDBInitializer       CrdKind
-	TidbClusterAutoScaler CrdKind
+	KindsString            string
+	TiDBCluster            CrdKind
+	DMCluster              CrdKind
+	Backup                 CrdKind
+	Restore                CrdKind
+	BackupSchedule         CrdKind
+	TiDBMonitor            CrdKind
+	TiDBInitializer        CrdKind
+	TidbClusterAutoScaler  CrdKind
+	TidbClusterFederation  CrdKind
+	TidbClusterReplication CrdKind
+	TidbClusterClone       CrdKind
 }
 
 var DefaultCrdKinds = CrdKinds{
-	KindsString:           "",
-	TiDBCluster:           CrdKind{Plural: TiDBClusterName, Kind: TiDBClusterKind, ShortNames: []string{"tc"}, SpecName: SpecPath + TiDBClusterKind},
-	DMCluster:             CrdKind{Plural: DMClusterName, Kind: DMClusterKind, ShortNames: []string{"dc"}, SpecName: SpecPath + DMClusterKind},
-	Backup:                CrdKind{Plural: BackupName, Kind: BackupKind, ShortNames: []string{"bk"}, SpecName: SpecPath + BackupKind},
-	Restore:               CrdKind{Plural: RestoreName, Kind: RestoreKind, ShortNames: []string{"rt"}, SpecName: SpecPath + RestoreKind},
-	BackupSchedule:        CrdKind{Plural: BackupScheduleName, Kind: BackupScheduleKind, ShortNames: []string{"bks"}, SpecName: SpecPath + BackupScheduleKind},
-	TiDBMonitor:           CrdKind{Plural: TiDBMonitorName, Kind: TiDBMonitorKind, ShortNames: []string{"tm"}, SpecName: SpecPath + TiDBMonitorKind},
-	TiDBInitializer:       CrdKind{Plural: TiDBInitializerName, Kind: TiDBInitializerKind, ShortNames: []string{"ti"}, SpecName: SpecPath + TiDBInitializerKind},
-	TidbClusterAutoScaler: CrdKind{Plural: TidbClusterAutoScalerName, Kind: TidbClusterAutoScalerKind, ShortNames: []string{"ta"}, SpecName: SpecPath + TidbClusterAutoScalerKind},
+	KindsString:            "",
+	TiDBCluster:            CrdKind{Plural: TiDBClusterName, Kind: TiDBClusterKind, ShortNames: []string{"tc"}, SpecName: SpecPath + TiDBClusterKind},
+	DMCluster:              CrdKind{Plural: DMClusterName, Kind: DMClusterKind, ShortNames: []string{"dc"}, SpecName: SpecPath + DMClusterKind},
+	Backup:                 CrdKind{Plural: BackupName, Kind: BackupKind, ShortNames: []string{"bk"}, SpecName: SpecPath + BackupKind},
+	Restore:                CrdKind{Plural: RestoreName, Kind: RestoreKind, ShortNames: []string{"rt"}, SpecName: SpecPath + RestoreKind},
+	BackupSchedule:         CrdKind{Plural: BackupScheduleName, Kind: BackupScheduleKind, ShortNames: []string{"bks"}, SpecName: SpecPath + BackupScheduleKind},
+	TiDBMonitor:            CrdKind{Plural: TiDBMonitorName, Kind: TiDBMonitorKind, ShortNames: []string{"tm"}, SpecName: SpecPath + TiDBMonitorKind},
+	TiDBInitializer:        CrdKind{Plural: TiDBInitializerName, Kind: TiDBInitializerKind, ShortNames: []string{"ti"}, SpecName: SpecPath + TiDBInitializerKind},
+	TidbClusterAutoScaler:  CrdKind{Plural: TidbClusterAutoScalerName, Kind: TidbClusterAutoScalerKind, ShortNames: []string{"ta"}, SpecName: SpecPath + TidbClusterAutoScalerKind},
+	TidbClusterFederation:  CrdKind{Plural: TidbClusterFederationName, Kind: TidbClusterFederationKind, ShortNames: []string{"tf"}, SpecName: SpecPath + TidbClusterFederationKind},
+	TidbClusterReplication: CrdKind{Plural: TidbClusterReplicationName, Kind: TidbClusterReplicationKind, ShortNames: []string{"tr"}, SpecName: SpecPath + TidbClusterReplicationKind},
+	TidbClusterClone:       CrdKind{Plural: TidbClusterCloneName, Kind: TidbClusterCloneKind, ShortNames: []string{"tcc"}, SpecName: SpecPath + TidbClusterCloneKind},
 }
@@ -71,6 +71,15 @@ func setTidbClusterSpecDefault(tc *v1alpha1.TidbCluster) {
 		tc.Spec.PVReclaimPolicy = &retainPVP
 	}
 
+	if tc.Spec.SecurityProfile == "" {
+		// the pods of the existing clusters are not restarted to apply the Restricted profile
+		if isNewTidbCluster(tc) {
+			tc.Spec.SecurityProfile = v1alpha1.SecurityProfileRestricted
+		} else {
+			tc.Spec.SecurityProfile = v1alpha1.SecurityProfileUnrestricted
+		}
+	}
+
 	if tc.Spec.Cluster != nil {
 		if tc.Spec.Cluster.Name != "" && tc.Spec.Cluster.Namespace == "" {
 			tc.Spec.Cluster.Namespace = tc.GetNamespace()
@@ -78,6 +87,19 @@ func setTidbClusterSpecDefault(tc *v1alpha1.TidbCluster) {
 	}
 }
 
+// isNewTidbCluster returns whether none of the components of the TidbCluster has been created
+func isNewTidbCluster(tc *v1alpha1.TidbCluster) bool {
+	status := &tc.Status
+	return status.ClusterID == "" &&
+		len(status.Conditions) == 0 &&
+		status.PD.StatefulSet == nil &&
+		status.TiKV.StatefulSet == nil &&
+		status.TiDB.StatefulSet == nil &&
+		status.TiFlash.StatefulSet == nil &&
+		status.TiCDC.StatefulSet == nil &&
+		status.Pump.StatefulSet == nil
+}
+
 func setTidbSpecDefault(tc *v1alpha1.TidbCluster) {
 	if len(tc.Spec.Version) > 0 || tc.Spec.TiDB.Version != nil {
 		if tc.Spec.TiDB.BaseImage == "" {
@@ -18,6 +18,7 @@ import (
 
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	apps "k8s.io/api/apps/v1"
 )
 
 func TestSetTidbSpecDefault(t *testing.T) {
@@ -79,6 +80,26 @@ func TestSetTidbSpecDefault(t *testing.T) {
 
 }
 
+func TestSetSecurityProfileDefault(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbCluster()
+	setTidbClusterSpecDefault(tc)
+	g.Expect(tc.Spec.SecurityProfile).Should(Equal(v1alpha1.SecurityProfileRestricted))
+
+	// the existing clusters keep running with the Unrestricted profile
+	tc = newTidbCluster()
+	tc.Status.PD.StatefulSet = &apps.StatefulSetStatus{Replicas: 3}
+	setTidbClusterSpecDefault(tc)
+	g.Expect(tc.Spec.SecurityProfile).Should(Equal(v1alpha1.SecurityProfileUnrestricted))
+
+	tc = newTidbCluster()
+	tc.Status.PD.StatefulSet = &apps.StatefulSetStatus{Replicas: 3}
+	tc.Spec.SecurityProfile = v1alpha1.SecurityProfileRestricted
+	setTidbClusterSpecDefault(tc)
+	g.Expect(tc.Spec.SecurityProfile).Should(Equal(v1alpha1.SecurityProfileRestricted))
+}
+
 func newTidbCluster() *v1alpha1.TidbCluster {
 	return &v1alpha1.TidbCluster{
 		Spec: v1alpha1.TidbClusterSpec{
@@ -29,8 +29,10 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 	return map[string]common.OpenAPIDefinition{
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AutoResource":                  schema_pkg_apis_pingcap_v1alpha1_AutoResource(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AutoRule":                      schema_pkg_apis_pingcap_v1alpha1_AutoRule(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AutoScalerReplicasStatus":      schema_pkg_apis_pingcap_v1alpha1_AutoScalerReplicasStatus(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BRConfig":                      schema_pkg_apis_pingcap_v1alpha1_BRConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Backup":                        schema_pkg_apis_pingcap_v1alpha1_Backup(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BackupFederationSpec":          schema_pkg_apis_pingcap_v1alpha1_BackupFederationSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BackupList":                    schema_pkg_apis_pingcap_v1alpha1_BackupList(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BackupSchedule":                schema_pkg_apis_pingcap_v1alpha1_BackupSchedule(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BackupScheduleList":            schema_pkg_apis_pingcap_v1alpha1_BackupScheduleList(ref),
@@ -41,6 +43,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BasicAutoScalerStatus":         schema_pkg_apis_pingcap_v1alpha1_BasicAutoScalerStatus(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Binlog":                        schema_pkg_apis_pingcap_v1alpha1_Binlog(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ClusterRef":                    schema_pkg_apis_pingcap_v1alpha1_ClusterRef(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ColumnMask":                      schema_pkg_apis_pingcap_v1alpha1_ColumnMask(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.CommonConfig":                  schema_pkg_apis_pingcap_v1alpha1_CommonConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ComponentSpec":                 schema_pkg_apis_pingcap_v1alpha1_ComponentSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ConfigMapRef":                  schema_pkg_apis_pingcap_v1alpha1_ConfigMapRef(ref),
@@ -54,6 +57,9 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Experimental":                  schema_pkg_apis_pingcap_v1alpha1_Experimental(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ExternalConfig":                schema_pkg_apis_pingcap_v1alpha1_ExternalConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ExternalEndpoint":              schema_pkg_apis_pingcap_v1alpha1_ExternalEndpoint(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ExternalSecretRef":             schema_pkg_apis_pingcap_v1alpha1_ExternalSecretRef(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ExternalSecretVault":           schema_pkg_apis_pingcap_v1alpha1_ExternalSecretVault(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FailoverRecoveryPolicy":        schema_pkg_apis_pingcap_v1alpha1_FailoverRecoveryPolicy(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FileLogConfig":                 schema_pkg_apis_pingcap_v1alpha1_FileLogConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Flash":                         schema_pkg_apis_pingcap_v1alpha1_Flash(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FlashCluster":                  schema_pkg_apis_pingcap_v1alpha1_FlashCluster(ref),
@@ -64,6 +70,9 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GcsStorageProvider":            schema_pkg_apis_pingcap_v1alpha1_GcsStorageProvider(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec":                    schema_pkg_apis_pingcap_v1alpha1_HelperSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.IngressSpec":                   schema_pkg_apis_pingcap_v1alpha1_IngressSpec(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.InitSqlScript":                   schema_pkg_apis_pingcap_v1alpha1_InitSqlScript(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.InitSqlScriptStatus":             schema_pkg_apis_pingcap_v1alpha1_InitSqlScriptStatus(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.InitializerAttempt":              schema_pkg_apis_pingcap_v1alpha1_InitializerAttempt(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.IsolationRead":                 schema_pkg_apis_pingcap_v1alpha1_IsolationRead(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Log":                           schema_pkg_apis_pingcap_v1alpha1_Log(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LogTailerSpec":                 schema_pkg_apis_pingcap_v1alpha1_LogTailerSpec(ref),
@@ -90,6 +99,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PessimisticTxn":                schema_pkg_apis_pingcap_v1alpha1_PessimisticTxn(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PlanCache":                     schema_pkg_apis_pingcap_v1alpha1_PlanCache(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Plugin":                        schema_pkg_apis_pingcap_v1alpha1_Plugin(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PostUpgradeHookSpec":             schema_pkg_apis_pingcap_v1alpha1_PostUpgradeHookSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PreparedPlanCache":             schema_pkg_apis_pingcap_v1alpha1_PreparedPlanCache(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PrometheusConfiguration":       schema_pkg_apis_pingcap_v1alpha1_PrometheusConfiguration(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ProxyConfig":                   schema_pkg_apis_pingcap_v1alpha1_ProxyConfig(ref),
@@ -98,6 +108,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.QueueConfig":                   schema_pkg_apis_pingcap_v1alpha1_QueueConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RelabelConfig":                 schema_pkg_apis_pingcap_v1alpha1_RelabelConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RemoteWriteSpec":               schema_pkg_apis_pingcap_v1alpha1_RemoteWriteSpec(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RestartSchedule":                 schema_pkg_apis_pingcap_v1alpha1_RestartSchedule(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Restore":                       schema_pkg_apis_pingcap_v1alpha1_Restore(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RestoreList":                   schema_pkg_apis_pingcap_v1alpha1_RestoreList(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RestoreSpec":                   schema_pkg_apis_pingcap_v1alpha1_RestoreSpec(ref),
@@ -115,6 +126,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiCDCSpec":                     schema_pkg_apis_pingcap_v1alpha1_TiCDCSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBAccessConfig":              schema_pkg_apis_pingcap_v1alpha1_TiDBAccessConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBConfig":                    schema_pkg_apis_pingcap_v1alpha1_TiDBConfig(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBFailoverProbe":             schema_pkg_apis_pingcap_v1alpha1_TiDBFailoverProbe(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBProbe":                     schema_pkg_apis_pingcap_v1alpha1_TiDBProbe(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBServiceSpec":               schema_pkg_apis_pingcap_v1alpha1_TiDBServiceSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBSlowLogTailerSpec":         schema_pkg_apis_pingcap_v1alpha1_TiDBSlowLogTailerSpec(ref),
@@ -129,9 +141,11 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCoprocessorConfig":         schema_pkg_apis_pingcap_v1alpha1_TiKVCoprocessorConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVCoprocessorReadPoolConfig": schema_pkg_apis_pingcap_v1alpha1_TiKVCoprocessorReadPoolConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVDbConfig":                  schema_pkg_apis_pingcap_v1alpha1_TiKVDbConfig(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVEncryption":                schema_pkg_apis_pingcap_v1alpha1_TiKVEncryption(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVEncryptionConfig":          schema_pkg_apis_pingcap_v1alpha1_TiKVEncryptionConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVGCConfig":                  schema_pkg_apis_pingcap_v1alpha1_TiKVGCConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVImportConfig":              schema_pkg_apis_pingcap_v1alpha1_TiKVImportConfig(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVKMSMasterKey":              schema_pkg_apis_pingcap_v1alpha1_TiKVKMSMasterKey(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVMasterKeyConfig":           schema_pkg_apis_pingcap_v1alpha1_TiKVMasterKeyConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVPDConfig":                  schema_pkg_apis_pingcap_v1alpha1_TiKVPDConfig(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVPessimisticTxn":            schema_pkg_apis_pingcap_v1alpha1_TiKVPessimisticTxn(ref),
@@ -154,8 +168,21 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterAutoScalerRef":      schema_pkg_apis_pingcap_v1alpha1_TidbClusterAutoScalerRef(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterAutoScalerSpec":     schema_pkg_apis_pingcap_v1alpha1_TidbClusterAutoScalerSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterAutoScalerStatus":   schema_pkg_apis_pingcap_v1alpha1_TidbClusterAutoScalerStatus(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterClone":                schema_pkg_apis_pingcap_v1alpha1_TidbClusterClone(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterCloneList":            schema_pkg_apis_pingcap_v1alpha1_TidbClusterCloneList(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterCloneSpec":            schema_pkg_apis_pingcap_v1alpha1_TidbClusterCloneSpec(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterCloneTarget":          schema_pkg_apis_pingcap_v1alpha1_TidbClusterCloneTarget(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterFederation":         schema_pkg_apis_pingcap_v1alpha1_TidbClusterFederation(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterFederationList":     schema_pkg_apis_pingcap_v1alpha1_TidbClusterFederationList(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterFederationMember":   schema_pkg_apis_pingcap_v1alpha1_TidbClusterFederationMember(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterFederationSpec":     schema_pkg_apis_pingcap_v1alpha1_TidbClusterFederationSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterList":               schema_pkg_apis_pingcap_v1alpha1_TidbClusterList(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRef":                schema_pkg_apis_pingcap_v1alpha1_TidbClusterRef(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterReplication":          schema_pkg_apis_pingcap_v1alpha1_TidbClusterReplication(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterReplicationList":      schema_pkg_apis_pingcap_v1alpha1_TidbClusterReplicationList(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterReplicationSecondary": schema_pkg_apis_pingcap_v1alpha1_TidbClusterReplicationSecondary(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterReplicationSpec":      schema_pkg_apis_pingcap_v1alpha1_TidbClusterReplicationSpec(ref),
+		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRestoreSpec":          schema_pkg_apis_pingcap_v1alpha1_TidbClusterRestoreSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterSpec":               schema_pkg_apis_pingcap_v1alpha1_TidbClusterSpec(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbInitializer":               schema_pkg_apis_pingcap_v1alpha1_TidbInitializer(ref),
 		"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbInitializerList":           schema_pkg_apis_pingcap_v1alpha1_TidbInitializerList(ref),
@@ -506,6 +533,34 @@ func schema_pkg_apis_pingcap_v1alpha1_AutoRule(ref common.ReferenceCallback) com
 	}
 }
 
+func schema_pkg_apis_pingcap_v1alpha1_AutoScalerReplicasStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "AutoScalerReplicasStatus describes the current and the target replicas of a component",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"current": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Current is the number of the ready replicas",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"target": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Target is the number of the desired replicas in the spec",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"current", "target"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_pingcap_v1alpha1_BRConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -576,6 +631,13 @@ func schema_pkg_apis_pingcap_v1alpha1_BRConfig(ref common.ReferenceCallback) com
 							Format:      "",
 						},
 					},
+					"backupTS": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BackupTS is the TSO the snapshot is backed up at, it can not be set with timeAgo",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"checksum": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Checksum specifies whether to run checksum after backup",
@@ -653,6 +715,35 @@ func schema_pkg_apis_pingcap_v1alpha1_Backup(ref common.ReferenceCallback) commo
 	}
 }
 
+func schema_pkg_apis_pingcap_v1alpha1_BackupFederationSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "BackupFederationSpec describes the members of a federated backup",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"members": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Members are the TidbClusters to back up. A Backup is created for each member in its namespace with the same spec at the TS of the federated backup, and the data is saved under the member name in the storage, so the secrets referred by the spec must exist in the namespaces of all the members.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterFederationMember"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"members"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterFederationMember"},
+	}
+}
+
 func schema_pkg_apis_pingcap_v1alpha1_BackupList(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -934,6 +1025,12 @@ func schema_pkg_apis_pingcap_v1alpha1_BackupSpec(ref common.ReferenceCallback) c
 							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BRConfig"),
 						},
 					},
+					"federation": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Federation backs up the TidbClusters in multiple Kubernetes clusters at the same TS. It requires BR, and the members share the PD cluster of `br.cluster`.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BackupFederationSpec"),
+						},
+					},
 					"dumpling": {
 						SchemaProps: spec.SchemaProps{
 							Description: "DumplingConfig is the configs for dumpling",
@@ -1031,7 +1128,7 @@ func schema_pkg_apis_pingcap_v1alpha1_BackupSpec(ref common.ReferenceCallback) c
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BRConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DumplingConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GcsStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LocalStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.S3StorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBAccessConfig", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.ResourceRequirements", "k8s.io/api/core/v1.Toleration"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BRConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BackupFederationSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DumplingConfig", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.GcsStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LocalStorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.S3StorageProvider", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBAccessConfig", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.ResourceRequirements", "k8s.io/api/core/v1.Toleration"},
 	}
 }
 
@@ -1229,6 +1326,41 @@ func schema_pkg_apis_pingcap_v1alpha1_ClusterRef(ref common.ReferenceCallback) c
 	}
 }
 
+func schema_pkg_apis_pingcap_v1alpha1_ColumnMask(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ColumnMask scrubs a column of the target, e.g. the emails or the phone numbers of the users",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"table": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Table is the table of the column in the format of `<database>.<table>`",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"column": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Column is the name of the column",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"expression": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Expression is the SQL expression the column is set to, e.g. `MD5(email)`. Optional: Defaults to NULL",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"table", "column"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_pingcap_v1alpha1_CommonConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -1512,6 +1644,35 @@ func schema_pkg_apis_pingcap_v1alpha1_ComponentSpec(ref common.ReferenceCallback
 							},
 						},
 					},
+					"pvcDeletePolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PVCDeletePolicy determines whether the orphan PVCs left by the scale-in of the component are deleted. Delete is the same as setting the cluster-level enablePVReclaim to true for the component, and Retain is the same as setting it to false. Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"pvcAnnotations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PVCAnnotations are added to the PVCs of the component, e.g. `ebs.csi.aws.com/iops` and `ebs.csi.aws.com/throughput` of EBS gp3 volumes for the provisioners reading volume parameters from the PVC annotations. They are set on the volumeClaimTemplates and updated to the existing PVCs. Optional: Defaults to nil",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"securityProfile": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SecurityProfile of the component. Override the cluster-level securityProfile if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
@@ -1699,7 +1860,7 @@ func schema_pkg_apis_pingcap_v1alpha1_DMClusterSpec(ref common.ReferenceCallback
 					},
 					"enablePVReclaim": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Whether enable PVC reclaim for orphan PVC left by statefulset scale-in Optional: Defaults to false",
+							Description: "Whether enable PVC reclaim for orphan PVC left by statefulset scale-in It can be overridden by `pvcDeletePolicy` of each component Optional: Defaults to false",
 							Type:        []string{"boolean"},
 							Format:      "",
 						},
@@ -2125,6 +2286,113 @@ func schema_pkg_apis_pingcap_v1alpha1_ExternalEndpoint(ref common.ReferenceCallb
 	}
 }
 
+func schema_pkg_apis_pingcap_v1alpha1_ExternalSecretRef(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ExternalSecretRef references the credentials stored in an external secret manager, which are fetched by backup-manager when the job starts instead of being read from a Kubernetes secret. The secret is a JSON object of the same keys as the Kubernetes secret it replaces, e.g. {\"password\": \"...\"}. The job authenticates to the secret manager with its service account, e.g. by IAM roles for service accounts on EKS, workload identity on GKE or the Kubernetes auth method of Vault.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"provider": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Provider is the secret manager, one of aws-secrets-manager, gcp-secret-manager and vault",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"path": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Path of the secret, the secret ID or ARN of AWS Secrets Manager, the resource name projects/<project>/secrets/<secret>[/versions/<version>] of GCP Secret Manager, or the path of the secret in Vault, e.g. secret/data/backup for the KV version 2 engine",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"region": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Region of AWS Secrets Manager Optional: Defaults to the region of the job, e.g. the env AWS_REGION",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"vault": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Vault configures how to access Vault, it is required by the vault provider",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ExternalSecretVault"),
+						},
+					},
+				},
+				Required: []string{"provider", "path"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ExternalSecretVault"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_ExternalSecretVault(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ExternalSecretVault configures how the job accesses Vault",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"address": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Address of Vault, e.g. https://vault.vault.svc:8200",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"role": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Role is the role of the Kubernetes auth method bound to the service account of the job. Optional: Defaults to empty, which means the token in the env VAULT_TOKEN is used",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"mountPath": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MountPath is the mount path of the Kubernetes auth method Optional: Defaults to kubernetes",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"address"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_FailoverRecoveryPolicy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "FailoverRecoveryPolicy is the policy to remove the failover replicas after the failed members recover",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"mode": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Mode is the mode to remove the failover replicas, Manual or Auto",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"healthyPeriod": {
+						SchemaProps: spec.SchemaProps{
+							Description: "HealthyPeriod is how long all the members must be healthy before the failover replicas are removed in Auto mode Optional: Defaults to 10m",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+				},
+				Required: []string{"mode"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
+	}
+}
+
 func schema_pkg_apis_pingcap_v1alpha1_FileLogConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -2656,6 +2924,12 @@ func schema_pkg_apis_pingcap_v1alpha1_GcsStorageProvider(ref common.ReferenceCal
 							Format:      "",
 						},
 					},
+					"externalSecret": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExternalSecret references the service account credentials JSON with the key credentials in an external secret manager, it can be set instead of secretName.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ExternalSecretRef"),
+						},
+					},
 					"prefix": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Prefix of the data path.",
@@ -2667,6 +2941,8 @@ func schema_pkg_apis_pingcap_v1alpha1_GcsStorageProvider(ref common.ReferenceCal
 				Required: []string{"projectId"},
 			},
 		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ExternalSecretRef"},
 	}
 }
 
@@ -2755,78 +3031,197 @@ func schema_pkg_apis_pingcap_v1alpha1_IngressSpec(ref common.ReferenceCallback)
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_IsolationRead(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_InitSqlScript(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "IsolationRead is the config for isolation read.",
+				Description: "InitSqlScript is a SQL script stored in a key of a ConfigMap or a Secret, exactly one of configMap and secret must be set",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"engines": {
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Engines filters tidb-server access paths by engine type. imported from v3.1.0",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Type:   []string{"string"},
-										Format: "",
-									},
-								},
-							},
+							Description: "Name identifies the script in the status, it must be unique in the TidbInitializer and consist of alphanumeric characters, '-', '_' or '.'",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"configMap": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ConfigMap selects the key of a ConfigMap which stores the script",
+							Ref:         ref("k8s.io/api/core/v1.ConfigMapKeySelector"),
+						},
+					},
+					"secret": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Secret selects the key of a Secret which stores the script",
+							Ref:         ref("k8s.io/api/core/v1.SecretKeySelector"),
 						},
 					},
 				},
+				Required: []string{"name"},
 			},
 		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.ConfigMapKeySelector", "k8s.io/api/core/v1.SecretKeySelector"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_Log(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_InitSqlScriptStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "Log is the log section of config.",
+				Description: "InitSqlScriptStatus is the state of a script of initSqlScripts",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"level": {
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Log level. Optional: Defaults to info",
+							Description: "Name is the name of the script",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"format": {
+					"phase": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Log format. one of json, text, or console. Optional: Defaults to text",
+							Description: "Phase is Pending until the script is executed by the job, then Completed or Failed",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"disable-timestamp": {
+					"message": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Deprecated in v3.0.5. Use EnableTimestamp instead Disable automatic timestamps in output.",
-							Type:        []string{"boolean"},
+							Description: "Message is the error of the script if it fails",
+							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"enable-timestamp": {
+				},
+				Required: []string{"name", "phase"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_InitializerAttempt(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "InitializerAttempt is a job run for the initialization",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"startTime": {
 						SchemaProps: spec.SchemaProps{
-							Description: "EnableTimestamp enables automatic timestamps in log output.",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "StartTime is the time the job is created",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
 						},
 					},
-					"enable-error-stack": {
+					"completionTime": {
 						SchemaProps: spec.SchemaProps{
-							Description: "EnableErrorStack enables annotating logs with the full stack error message.",
-							Type:        []string{"boolean"},
-							Format:      "",
+							Description: "CompletionTime is the time the job completes or fails",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
 						},
 					},
-					"file": {
+					"phase": {
 						SchemaProps: spec.SchemaProps{
-							Description: "File log config.",
+							Description: "Phase is Running, Completed or Failed",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Reason is the reason of the failure of the job, e.g. BackoffLimitExceeded or DeadlineExceeded",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message is the message of the failure of the job",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"startTime", "phase"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_IsolationRead(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "IsolationRead is the config for isolation read.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"engines": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Engines filters tidb-server access paths by engine type. imported from v3.1.0",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_Log(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Log is the log section of config.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"level": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Log level. Optional: Defaults to info",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"format": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Log format. one of json, text, or console. Optional: Defaults to text",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"disable-timestamp": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Deprecated in v3.0.5. Use EnableTimestamp instead Disable automatic timestamps in output.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"enable-timestamp": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EnableTimestamp enables automatic timestamps in log output.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"enable-error-stack": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EnableErrorStack enables annotating logs with the full stack error message.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"file": {
+						SchemaProps: spec.SchemaProps{
+							Description: "File log config.",
 							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FileLogConfig"),
 						},
 					},
@@ -3281,6 +3676,35 @@ func schema_pkg_apis_pingcap_v1alpha1_MasterSpec(ref common.ReferenceCallback) c
 							},
 						},
 					},
+					"pvcDeletePolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PVCDeletePolicy determines whether the orphan PVCs left by the scale-in of the component are deleted. Delete is the same as setting the cluster-level enablePVReclaim to true for the component, and Retain is the same as setting it to false. Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"pvcAnnotations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PVCAnnotations are added to the PVCs of the component, e.g. `ebs.csi.aws.com/iops` and `ebs.csi.aws.com/throughput` of EBS gp3 volumes for the provisioners reading volume parameters from the PVC annotations. They are set on the volumeClaimTemplates and updated to the existing PVCs. Optional: Defaults to nil",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"securityProfile": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SecurityProfile of the component. Override the cluster-level securityProfile if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"limits": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
@@ -4478,6 +4902,35 @@ func schema_pkg_apis_pingcap_v1alpha1_PDSpec(ref common.ReferenceCallback) commo
 							},
 						},
 					},
+					"pvcDeletePolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PVCDeletePolicy determines whether the orphan PVCs left by the scale-in of the component are deleted. Delete is the same as setting the cluster-level enablePVReclaim to true for the component, and Retain is the same as setting it to false. Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"pvcAnnotations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PVCAnnotations are added to the PVCs of the component, e.g. `ebs.csi.aws.com/iops` and `ebs.csi.aws.com/throughput` of EBS gp3 volumes for the provisioners reading volume parameters from the PVC annotations. They are set on the volumeClaimTemplates and updated to the existing PVCs. Optional: Defaults to nil",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"securityProfile": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SecurityProfile of the component. Override the cluster-level securityProfile if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"limits": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
@@ -4540,6 +4993,19 @@ func schema_pkg_apis_pingcap_v1alpha1_PDSpec(ref common.ReferenceCallback) commo
 							Format:      "int32",
 						},
 					},
+					"failoverRecovery": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FailoverRecovery is the policy to remove the failover replicas after the failed members recover. Optional: the failover replicas are removed as soon as all the members are healthy if it is not set",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FailoverRecoveryPolicy"),
+						},
+					},
+					"recoverFailover": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RecoverFailover indicates that Operator can recover the failover Pods once all the members are healthy",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 					"storageClassName": {
 						SchemaProps: spec.SchemaProps{
 							Description: "The storageClassName of the persistent volume for PD data storage. Defaults to Kubernetes default storage class.",
@@ -4594,12 +5060,26 @@ func schema_pkg_apis_pingcap_v1alpha1_PDSpec(ref common.ReferenceCallback) commo
 							Format:      "",
 						},
 					},
+					"deleteSlots": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DeleteSlots are the ordinals of the PD pods to delete when scaling in, or to skip when scaling out. It takes effect only if the AdvancedStatefulSet feature is enabled. The legacy annotation `pd.tidb.pingcap.com/delete-slots` is still respected and merged with it.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"integer"},
+										Format: "int32",
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"replicas"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDConfigWraper", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ServiceSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FailoverRecoveryPolicy", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDConfigWraper", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ServiceSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
 	}
 }
 
@@ -4839,6 +5319,68 @@ func schema_pkg_apis_pingcap_v1alpha1_Plugin(ref common.ReferenceCallback) commo
 	}
 }
 
+func schema_pkg_apis_pingcap_v1alpha1_PostUpgradeHookSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "PostUpgradeHookSpec is the SQL executed by a job after TiDB is upgraded to a new version",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"sql": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SQL is the statements executed after the upgrade, one statement per line",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"sqlConfigMap": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SQLConfigMap references the key of a ConfigMap which contains the statements, one statement per line. Only one of sql and sqlConfigMap can be set.",
+							Ref:         ref("k8s.io/api/core/v1.ConfigMapKeySelector"),
+						},
+					},
+					"image": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Image of the job, which is required to have python and the MySQLdb module Optional: Defaults to tnir/mysqlclient",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"user": {
+						SchemaProps: spec.SchemaProps{
+							Description: "User is the user who executes the statements Optional: Defaults to root",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"passwordSecret": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PasswordSecret is the name of the secret which stores the password of the user under the `password` key Optional: Defaults to empty, which means no password",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"tlsClientSecretName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TLSClientSecretName is the name of the secret which stores the client certificate to access TiDB Optional: Defaults to the client certificate of the TidbCluster",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"resources": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Resources of the job",
+							Ref:         ref("k8s.io/api/core/v1.ResourceRequirements"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.ConfigMapKeySelector", "k8s.io/api/core/v1.ResourceRequirements"},
+	}
+}
+
 func schema_pkg_apis_pingcap_v1alpha1_PreparedPlanCache(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -5261,6 +5803,35 @@ func schema_pkg_apis_pingcap_v1alpha1_PumpSpec(ref common.ReferenceCallback) com
 							},
 						},
 					},
+					"pvcDeletePolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PVCDeletePolicy determines whether the orphan PVCs left by the scale-in of the component are deleted. Delete is the same as setting the cluster-level enablePVReclaim to true for the component, and Retain is the same as setting it to false. Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"pvcAnnotations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PVCAnnotations are added to the PVCs of the component, e.g. `ebs.csi.aws.com/iops` and `ebs.csi.aws.com/throughput` of EBS gp3 volumes for the provisioners reading volume parameters from the PVC annotations. They are set on the volumeClaimTemplates and updated to the existing PVCs. Optional: Defaults to nil",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"securityProfile": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SecurityProfile of the component. Override the cluster-level securityProfile if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"limits": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
@@ -5542,46 +6113,47 @@ func schema_pkg_apis_pingcap_v1alpha1_RemoteWriteSpec(ref common.ReferenceCallba
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_Restore(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_RestartSchedule(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "Restore represents the restoration of backup of a tidb cluster.",
+				Description: "RestartSchedule schedules the periodic rolling restarts of a component",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"kind": {
+					"component": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Description: "Component is the component to restart, one of pd, tikv, tiflash and tidb",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"apiVersion": {
+					"schedule": {
 						SchemaProps: spec.SchemaProps{
-							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Description: "Schedule is the cron string of the time the maintenance windows begin, e.g. \"0 3 * * 6\"",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"spec": {
+					"window": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RestoreSpec"),
+							Description: "Window is the length of the maintenance windows, the pods which have not begun to restart when the window ends are not restarted until the next window. Defaults to 2h.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
 						},
 					},
 				},
-				Required: []string{"spec"},
+				Required: []string{"component", "schedule"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RestoreSpec"},
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_RestoreList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_Restore(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "RestoreList contains a list of Restore.",
+				Description: "Restore represents the restoration of backup of a tidb cluster.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
 					"kind": {
@@ -5598,10 +6170,45 @@ func schema_pkg_apis_pingcap_v1alpha1_RestoreList(ref common.ReferenceCallback)
 							Format:      "",
 						},
 					},
-					"items": {
+					"spec": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
-							Items: &spec.SchemaOrArray{
+							Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RestoreSpec"),
+						},
+					},
+				},
+				Required: []string{"spec"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RestoreSpec"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_RestoreList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "RestoreList contains a list of Restore.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
 										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.Restore"),
@@ -5850,6 +6457,12 @@ func schema_pkg_apis_pingcap_v1alpha1_S3StorageProvider(ref common.ReferenceCall
 							Format:      "",
 						},
 					},
+					"externalSecret": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExternalSecret references the access key and secret key with the keys access_key and secret_key in an external secret manager, it can be set instead of secretName.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ExternalSecretRef"),
+						},
+					},
 					"prefix": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Prefix of the data path.",
@@ -5882,6 +6495,8 @@ func schema_pkg_apis_pingcap_v1alpha1_S3StorageProvider(ref common.ReferenceCall
 				Required: []string{"provider"},
 			},
 		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ExternalSecretRef"},
 	}
 }
 
@@ -6590,6 +7205,35 @@ func schema_pkg_apis_pingcap_v1alpha1_TiCDCSpec(ref common.ReferenceCallback) co
 							},
 						},
 					},
+					"pvcDeletePolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PVCDeletePolicy determines whether the orphan PVCs left by the scale-in of the component are deleted. Delete is the same as setting the cluster-level enablePVReclaim to true for the component, and Retain is the same as setting it to false. Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"pvcAnnotations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PVCAnnotations are added to the PVCs of the component, e.g. `ebs.csi.aws.com/iops` and `ebs.csi.aws.com/throughput` of EBS gp3 volumes for the provisioners reading volume parameters from the PVC annotations. They are set on the volumeClaimTemplates and updated to the existing PVCs. Optional: Defaults to nil",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"securityProfile": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SecurityProfile of the component. Override the cluster-level securityProfile if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"limits": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
@@ -6718,11 +7362,17 @@ func schema_pkg_apis_pingcap_v1alpha1_TiDBAccessConfig(ref common.ReferenceCallb
 					},
 					"secretName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "SecretName is the name of secret which stores tidb cluster's password.",
+							Description: "SecretName is the name of secret which stores tidb cluster's password. One of secretName and externalSecret must be set.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
+					"externalSecret": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExternalSecret references the password with the key password in an external secret manager, it can be set instead of secretName.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ExternalSecretRef"),
+						},
+					},
 					"tlsClientSecretName": {
 						SchemaProps: spec.SchemaProps{
 							Description: "TLSClientSecretName is the name of secret which stores tidb server client certificate Optional: Defaults to nil",
@@ -6731,9 +7381,11 @@ func schema_pkg_apis_pingcap_v1alpha1_TiDBAccessConfig(ref common.ReferenceCallb
 						},
 					},
 				},
-				Required: []string{"host", "secretName"},
+				Required: []string{"host"},
 			},
 		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ExternalSecretRef"},
 	}
 }
 
@@ -7048,6 +7700,55 @@ func schema_pkg_apis_pingcap_v1alpha1_TiDBConfig(ref common.ReferenceCallback) c
 	}
 }
 
+func schema_pkg_apis_pingcap_v1alpha1_TiDBFailoverProbe(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiDBFailoverProbe is the SQL-level probe executed by the controller through the MySQL protocol to detect the TiDB members which are Ready but fail to serve queries",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"query": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Query is the SQL statement executed on each member, it should read data from TiKV so that the members which fail to access the storage are detected Optional: Defaults to \"SELECT COUNT(*) FROM mysql.tidb\"",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"user": {
+						SchemaProps: spec.SchemaProps{
+							Description: "User is the user to execute the query Optional: Defaults to root",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"passwordSecret": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PasswordSecret is the name of the secret which contains the password of the user keyed by the user name, the same as the passwordSecret of TidbInitializer. The password is empty if it is not set",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"timeoutSeconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TimeoutSeconds is the timeout of the query Optional: Defaults to 5",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"failurePeriod": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FailurePeriod is how long the probe must keep failing before the member is counted as failed Optional: Defaults to the TiDB failover period of the controller manager",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
+	}
+}
+
 func schema_pkg_apis_pingcap_v1alpha1_TiDBProbe(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -7397,6 +8098,35 @@ func schema_pkg_apis_pingcap_v1alpha1_TiDBSpec(ref common.ReferenceCallback) com
 							},
 						},
 					},
+					"pvcDeletePolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PVCDeletePolicy determines whether the orphan PVCs left by the scale-in of the component are deleted. Delete is the same as setting the cluster-level enablePVReclaim to true for the component, and Retain is the same as setting it to false. Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"pvcAnnotations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PVCAnnotations are added to the PVCs of the component, e.g. `ebs.csi.aws.com/iops` and `ebs.csi.aws.com/throughput` of EBS gp3 volumes for the provisioners reading volume parameters from the PVC annotations. They are set on the volumeClaimTemplates and updated to the existing PVCs. Optional: Defaults to nil",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"securityProfile": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SecurityProfile of the component. Override the cluster-level securityProfile if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"limits": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
@@ -7466,6 +8196,12 @@ func schema_pkg_apis_pingcap_v1alpha1_TiDBSpec(ref common.ReferenceCallback) com
 							Format:      "int32",
 						},
 					},
+					"failoverProbe": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FailoverProbe is the SQL-level probe executed by the controller on each member, the members which are Ready but keep failing the probe for the failure period are counted as failed and replaced. Optional: the members are counted as failed only if they are not Ready if it is not set",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBFailoverProbe"),
+						},
+					},
 					"separateSlowLog": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Whether output the slow log in an separate sidecar container Optional: Defaults to true",
@@ -7544,12 +8280,26 @@ func schema_pkg_apis_pingcap_v1alpha1_TiDBSpec(ref common.ReferenceCallback) com
 							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBProbe"),
 						},
 					},
+					"deleteSlots": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DeleteSlots are the ordinals of the TiDB pods to delete when scaling in, or to skip when scaling out. It takes effect only if the AdvancedStatefulSet feature is enabled. The legacy annotation `tidb.tidb.pingcap.com/delete-slots` is still respected and merged with it.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"integer"},
+										Format: "int32",
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"replicas"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBConfigWraper", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBProbe", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBServiceSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBSlowLogTailerSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBTLSClient", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.Lifecycle", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBConfigWraper", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBFailoverProbe", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBProbe", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBServiceSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBSlowLogTailerSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBTLSClient", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.Lifecycle", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
 	}
 }
 
@@ -7810,6 +8560,35 @@ func schema_pkg_apis_pingcap_v1alpha1_TiFlashSpec(ref common.ReferenceCallback)
 							},
 						},
 					},
+					"pvcDeletePolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PVCDeletePolicy determines whether the orphan PVCs left by the scale-in of the component are deleted. Delete is the same as setting the cluster-level enablePVReclaim to true for the component, and Retain is the same as setting it to false. Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"pvcAnnotations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PVCAnnotations are added to the PVCs of the component, e.g. `ebs.csi.aws.com/iops` and `ebs.csi.aws.com/throughput` of EBS gp3 volumes for the provisioners reading volume parameters from the PVC annotations. They are set on the volumeClaimTemplates and updated to the existing PVCs. Optional: Defaults to nil",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"securityProfile": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SecurityProfile of the component. Override the cluster-level securityProfile if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"limits": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
@@ -7905,6 +8684,20 @@ func schema_pkg_apis_pingcap_v1alpha1_TiFlashSpec(ref common.ReferenceCallback)
 							Format:      "",
 						},
 					},
+					"deleteSlots": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DeleteSlots are the ordinals of the TiFlash pods to delete when scaling in, or to skip when scaling out. It takes effect only if the AdvancedStatefulSet feature is enabled. The legacy annotation `tiflash.tidb.pingcap.com/delete-slots` is still respected and merged with it.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"integer"},
+										Format: "int32",
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"replicas", "storageClaims"},
 			},
@@ -8764,6 +9557,41 @@ func schema_pkg_apis_pingcap_v1alpha1_TiKVDbConfig(ref common.ReferenceCallback)
 	}
 }
 
+func schema_pkg_apis_pingcap_v1alpha1_TiKVEncryption(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiKVEncryption is the encryption at rest of TiKV",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"method": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Method is the encryption method of the data files, one of aes128-ctr, aes192-ctr and aes256-ctr Optional: Defaults to aes256-ctr",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"dataKeyRotationPeriod": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DataKeyRotationPeriod is how often TiKV rotates the data keys Optional: Defaults to 168h",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"masterKey": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MasterKey is the KMS key to encrypt the data keys. Once it is changed, the TiKV Pods are restarted one by one with the previous master key to re-encrypt the data keys, and the previous master key is recorded in the status.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVKMSMasterKey"),
+						},
+					},
+				},
+				Required: []string{"masterKey"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVKMSMasterKey", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
+	}
+}
+
 func schema_pkg_apis_pingcap_v1alpha1_TiKVEncryptionConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -8907,6 +9735,55 @@ func schema_pkg_apis_pingcap_v1alpha1_TiKVImportConfig(ref common.ReferenceCallb
 	}
 }
 
+func schema_pkg_apis_pingcap_v1alpha1_TiKVKMSMasterKey(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TiKVKMSMasterKey is the master key of the TiKV encryption in a KMS",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"vendor": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Vendor is the vendor of the KMS, aws or gcp Optional: Defaults to aws",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"keyID": {
+						SchemaProps: spec.SchemaProps{
+							Description: "KeyID is the ID or ARN of the AWS KMS key, or the resource name of the GCP Cloud KMS key in the format of projects/<project>/locations/<location>/keyRings/<ring>/cryptoKeys/<key>",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"region": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Region is the region of the key",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"endpoint": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Endpoint is the endpoint of the KMS, leave it empty to use the default endpoint of the vendor",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"secretName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SecretName is the name of the secret with the credentials to access the KMS, the keys `access_key` and `secret_key` for AWS, or the key `credentials` with the service account key for GCP. The IAM role or the workload identity of the Pod is used if it is not set. The AWS credentials of the current master key are also used to access the previous one.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"keyID"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_pingcap_v1alpha1_TiKVMasterKeyConfig(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -10100,25 +10977,54 @@ func schema_pkg_apis_pingcap_v1alpha1_TiKVSpec(ref common.ReferenceCallback) com
 							},
 						},
 					},
-					"limits": {
+					"pvcDeletePolicy": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Description: "PVCDeletePolicy determines whether the orphan PVCs left by the scale-in of the component are deleted. Delete is the same as setting the cluster-level enablePVReclaim to true for the component, and Retain is the same as setting it to false. Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"pvcAnnotations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PVCAnnotations are added to the PVCs of the component, e.g. `ebs.csi.aws.com/iops` and `ebs.csi.aws.com/throughput` of EBS gp3 volumes for the provisioners reading volume parameters from the PVC annotations. They are set on the volumeClaimTemplates and updated to the existing PVCs. Optional: Defaults to nil",
 							Type:        []string{"object"},
 							AdditionalProperties: &spec.SchemaOrBool{
 								Allows: true,
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+										Type:   []string{"string"},
+										Format: "",
 									},
 								},
 							},
 						},
 					},
-					"requests": {
+					"securityProfile": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
+							Description: "SecurityProfile of the component. Override the cluster-level securityProfile if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"limits": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+									},
+								},
+							},
+						},
+					},
+					"requests": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Requests describes the minimum amount of compute resources required. If Requests is omitted for a container, it defaults to Limits if that is explicitly specified, otherwise to an implementation-defined value. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
 								Allows: true,
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
@@ -10163,6 +11069,12 @@ func schema_pkg_apis_pingcap_v1alpha1_TiKVSpec(ref common.ReferenceCallback) com
 							Format:      "int32",
 						},
 					},
+					"failoverRecovery": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FailoverRecovery is the policy to remove the failover replicas after the failed stores recover. Optional: Defaults to the Manual mode",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FailoverRecoveryPolicy"),
+						},
+					},
 					"separateRocksDBLog": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Whether output the RocksDB log in a separate sidecar container Optional: Defaults to false",
@@ -10258,12 +11170,32 @@ func schema_pkg_apis_pingcap_v1alpha1_TiKVSpec(ref common.ReferenceCallback) com
 							Format:      "",
 						},
 					},
+					"deleteSlots": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DeleteSlots are the ordinals of the TiKV pods to delete when scaling in, or to skip when scaling out. It takes effect only if the AdvancedStatefulSet feature is enabled. The legacy annotation `tikv.tidb.pingcap.com/delete-slots` is still respected and merged with it.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"integer"},
+										Format: "int32",
+									},
+								},
+							},
+						},
+					},
+					"encryption": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Encryption enables the encryption at rest of TiKV with the master key from a KMS. The settings in `security.encryption` of the config are overridden if it is set.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVEncryption"),
+						},
+					},
 				},
 				Required: []string{"replicas"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LogTailerSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVConfigWraper", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.FailoverRecoveryPolicy", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.LogTailerSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.StorageVolume", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVConfigWraper", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVEncryption", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.Container", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/api/core/v1.Volume", "k8s.io/api/core/v1.VolumeMount", "k8s.io/apimachinery/pkg/api/resource.Quantity"},
 	}
 }
 
@@ -10814,131 +11746,759 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbClusterAutoScalerSpec(ref common.Refer
 						},
 					},
 				},
-				Required: []string{"cluster"},
+				Required: []string{"cluster"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbAutoScalerSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRef", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TikvAutoScalerSpec"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TidbClusterAutoScalerStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TidbClusterAutoScalerStatus describe the whole status",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"observedGeneration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ObservedGeneration is the most recent generation observed by the controller.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"tikv": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Tikv describes the status of each group for the tikv in the last auto-scaling reconciliation",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TikvAutoScalerStatus"),
+									},
+								},
+							},
+						},
+					},
+					"tidb": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Tidb describes the status of each group for the tidb in the last auto-scaling reconciliation",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbAutoScalerStatus"),
+									},
+								},
+							},
+						},
+					},
+					"tikvReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TiKVReplicas describes the replicas of tikv summed up over the target cluster and the auto-scaled clusters",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AutoScalerReplicasStatus"),
+						},
+					},
+					"tidbReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TiDBReplicas describes the replicas of tidb summed up over the target cluster and the auto-scaled clusters",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AutoScalerReplicasStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.AutoScalerReplicasStatus", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbAutoScalerStatus", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TikvAutoScalerStatus"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TidbClusterClone(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TidbClusterClone clones a source TidbCluster into a new TidbCluster, e.g. in another namespace as a staging copy of the production cluster",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Spec defines the desired state of TidbClusterClone",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterCloneSpec"),
+						},
+					},
+				},
+				Required: []string{"spec"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterCloneSpec"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TidbClusterCloneList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TidbClusterCloneList is TidbClusterClone list",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterClone"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterClone"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TidbClusterCloneSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TidbClusterCloneSpec describes the clone of the source into the target. The clone is done once, changing the spec after the target is created does not take effect.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"source": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Source is the TidbCluster to clone",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRef"),
+						},
+					},
+					"target": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Target is the TidbCluster created with the spec and the data of the source",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterCloneTarget"),
+						},
+					},
+					"backup": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Backup is the spec of the Backup of the source by BR, e.g. the storage of the backup data. The Backup is created in the namespace of the TidbClusterClone, and the data is restored from the same storage, so the secret of the storage must exist in the namespaces of both the TidbClusterClone and the target. The target is not created until all the secrets referenced by the source and the Backup exist in the namespace of the target.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BackupSpec"),
+						},
+					},
+					"masks": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Masks are the columns scrubbed in the target after the data is restored",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ColumnMask"),
+									},
+								},
+							},
+						},
+					},
+					"maskImage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaskImage is the image of the TidbInitializer which updates the masked columns. Optional: Defaults to the image set by the flag --tidb-initializer-image of the operator",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"source", "target", "backup"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.BackupSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ColumnMask", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterCloneTarget", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRef"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TidbClusterCloneTarget(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TidbClusterCloneTarget describes the TidbCluster created by the clone. The replicas of the components not set are the same as the source.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name is the name of the TidbCluster. Optional: Defaults to the name of the source",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"namespace": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Namespace is the namespace of the TidbCluster, it must exist",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"pdReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PDReplicas is the replicas of PD",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"tikvReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TiKVReplicas is the replicas of TiKV, it must not be less than the max replicas of the regions in the PD config of the source",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"tiflashReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TiFlashReplicas is the replicas of TiFlash",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"tidbReplicas": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TiDBReplicas is the replicas of TiDB",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"namespace"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TidbClusterFederation(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TidbClusterFederation coordinates the operations that must be ordered globally across several TidbClusters, which may live in different namespaces or Kubernetes clusters",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Spec defines the desired state of TidbClusterFederation",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterFederationSpec"),
+						},
+					},
+				},
+				Required: []string{"spec"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterFederationSpec"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TidbClusterFederationList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TidbClusterFederationList is TidbClusterFederation list",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterFederation"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterFederation"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TidbClusterFederationMember(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TidbClusterFederationMember refers to a TidbCluster of the federation",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name is the name of the TidbCluster",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"namespace": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Namespace is the namespace of the TidbCluster. Optional: Defaults to the namespace of the TidbClusterFederation",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"kubeConfigSecretName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "KubeConfigSecretName is the name of the secret in the namespace of the TidbClusterFederation which stores the kubeconfig of the Kubernetes cluster the TidbCluster lives in under the `kubeconfig` key. Optional: Defaults to empty, which means the local Kubernetes cluster",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TidbClusterFederationSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TidbClusterFederationSpec describes the desired state of the federated TidbClusters",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"members": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Members are the TidbClusters of the federation. Operations are applied to the members one by one in this order.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterFederationMember"),
+									},
+								},
+							},
+						},
+					},
+					"version": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Version is the TiDB version all the members are upgraded to. Optional: Defaults to nil, which means the version of the members is not managed",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"tlsCertRotation": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TLSCertRotation is an arbitrary identifier of a TLS certificates rotation. Changing it rotates the certificates of all the members one by one.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"paused": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Paused pauses or resumes the sync of all the members. Optional: Defaults to nil, which means the paused state of the members is not managed",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"members"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterFederationMember"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TidbClusterList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TidbClusterList is TidbCluster list",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbCluster"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbCluster"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TidbClusterRef(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TidbClusterRef reference to a TidbCluster",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"namespace": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Namespace is the namespace that TidbCluster object locates, default to the same namespace with TidbMonitor",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name is the name of TidbCluster object",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"clusterDomain": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ClusterDomain is the domain of TidbCluster object",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TidbClusterReplication(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TidbClusterReplication replicates the changes of a primary TidbCluster to a secondary TidbCluster by a TiCDC changefeed of the primary",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Spec defines the desired state of TidbClusterReplication",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterReplicationSpec"),
+						},
+					},
+				},
+				Required: []string{"spec"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterReplicationSpec"},
+	}
+}
+
+func schema_pkg_apis_pingcap_v1alpha1_TidbClusterReplicationList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TidbClusterReplicationList is TidbClusterReplication list",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterReplication"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbAutoScalerSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRef", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TikvAutoScalerSpec"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterReplication"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_TidbClusterAutoScalerStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_TidbClusterReplicationSecondary(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "TidbClusterAutoScalerStatus describe the whole status",
+				Description: "TidbClusterReplicationSecondary refers to the TiDB of the secondary TidbCluster",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"tikv": {
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Tikv describes the status of each group for the tikv in the last auto-scaling reconciliation",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TikvAutoScalerStatus"),
-									},
-								},
-							},
+							Description: "Name is the name of the TidbCluster",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"tidb": {
+					"namespace": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Tidb describes the status of each group for the tidb in the last auto-scaling reconciliation",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbAutoScalerStatus"),
-									},
-								},
-							},
+							Description: "Namespace is the namespace of the TidbCluster. Optional: Defaults to the namespace of the TidbClusterReplication",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"clusterDomain": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ClusterDomain is the domain of the Kubernetes cluster the TidbCluster lives in",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"host": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Host is the address of TiDB of the TidbCluster. Optional: Defaults to the TiDB service of the TidbCluster",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"port": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Port is the port of TiDB of the TidbCluster. Optional: Defaults to 4000",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"user": {
+						SchemaProps: spec.SchemaProps{
+							Description: "User is the user TiCDC logs in TiDB as. Optional: Defaults to root",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"secretName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SecretName is the name of the secret in the namespace of the TidbClusterReplication which stores the password of the user under the `password` key. Optional: Defaults to empty, which means no password",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"tlsClientSecretName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TLSClientSecretName is the name of the secret which stores the client certificate to access TiDB, it must be one of `spec.ticdc.tlsClientSecretNames` of the primary TidbCluster. Optional: Defaults to nil",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
 				},
+				Required: []string{"name"},
 			},
 		},
-		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbAutoScalerStatus", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TikvAutoScalerStatus"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_TidbClusterList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_TidbClusterReplicationSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "TidbClusterList is TidbCluster list",
+				Description: "TidbClusterReplicationSpec describes the replication from the primary to the secondary",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"kind": {
+					"primary": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Description: "Primary is the TidbCluster the changes are replicated from, TiCDC must be deployed in it",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRef"),
+						},
+					},
+					"secondary": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Secondary is the TidbCluster the changes are replicated to. Changing it or the password in its secret pauses the changefeed, updates the sink and resumes the changefeed.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterReplicationSecondary"),
+						},
+					},
+					"changefeedID": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ChangefeedID is the ID of the changefeed, which consists of letters, numbers and the hyphens between them. Optional: Defaults to the name of the TidbClusterReplication",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"apiVersion": {
+					"startTS": {
 						SchemaProps: spec.SchemaProps{
-							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Description: "StartTS is the TSO the replication starts from. Optional: Defaults to the current TSO",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"items": {
+					"filterRules": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
+							Description: "FilterRules are the table filter rules of the changefeed. Changing them pauses the changefeed, updates it and resumes it.",
+							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbCluster"),
+										Type:   []string{"string"},
+										Format: "",
 									},
 								},
 							},
 						},
 					},
+					"paused": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Paused pauses or resumes the changefeed",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"maxLag": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxLag is the max lag of the checkpoint of the changefeed before the replication is lagging. Optional: Defaults to 5m",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"promote": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Promote promotes the secondary to take over the writes in a DR event: the changefeed is removed after its checkpoint catches up, and the read-only flags of the secondary are turned off. The promotion is irreversible once it begins, setting it back to false does not resume the replication.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"promotionTimeout": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PromotionTimeout is how long the promotion waits for the checkpoint to catch up and for TiCDC of the primary to remove the changefeed. The promotion goes on after the timeout, e.g. if the primary is unavailable, and the changes after the checkpoint in the status may be lost. Optional: Defaults to 5m",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
 				},
-				Required: []string{"items"},
+				Required: []string{"primary", "secondary"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbCluster"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRef", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterReplicationSecondary", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
 	}
 }
 
-func schema_pkg_apis_pingcap_v1alpha1_TidbClusterRef(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_pingcap_v1alpha1_TidbClusterRestoreSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "TidbClusterRef reference to a TidbCluster",
+				Description: "TidbClusterRestoreSpec is the source of the data restored into the TidbCluster when it is created",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"namespace": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Namespace is the namespace that TidbCluster object locates, default to the same namespace with TidbMonitor",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"name": {
+					"backup": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Name is the name of TidbCluster object",
+							Description: "Backup is the name of a completed BR Backup in the namespace of the TidbCluster, the storage, the credentials and the filters of the backup are used to restore it into the TidbCluster instead of the cluster of the backup",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"clusterDomain": {
+					"restoreSpec": {
 						SchemaProps: spec.SchemaProps{
-							Description: "ClusterDomain is the domain of TidbCluster object",
-							Type:        []string{"string"},
-							Format:      "",
+							Description: "RestoreSpec is the spec of the Restore if the data is not backed up by a Backup, e.g. the storage of the backup data. It can not be set with backup. The cluster and the cluster namespace of `br` must be empty or refer to the TidbCluster, the data is always restored into the TidbCluster.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RestoreSpec"),
 						},
 					},
 				},
-				Required: []string{"name"},
 			},
 		},
+		Dependencies: []string{
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RestoreSpec"},
 	}
 }
 
@@ -11011,6 +12571,20 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbClusterSpec(ref common.ReferenceCallba
 							Format:      "",
 						},
 					},
+					"suspend": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether the cluster is suspended, e.g. to save the cost of a non-production cluster overnight or on weekends. The components are scaled to zero in the order of TiDB, TiCDC, Pump, TiFlash, TiKV and PD, and the PVCs and the metadata in PD are kept. They are resumed in the reverse order when it is unset.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"failoverSimulation": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Whether the failover of the components is simulated, the members which would be declared failed and the failover replicas which would be created are only recorded in events and `status.simulatedFailovers`, no failover replica is created or removed. Optional: Defaults to false",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 					"version": {
 						SchemaProps: spec.SchemaProps{
 							Description: "TiDB cluster version",
@@ -11061,11 +12635,23 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbClusterSpec(ref common.ReferenceCallba
 					},
 					"enablePVReclaim": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Whether enable PVC reclaim for orphan PVC left by statefulset scale-in Optional: Defaults to false",
+							Description: "Whether enable PVC reclaim for orphan PVC left by statefulset scale-in It can be overridden by `pvcDeletePolicy` of each component Optional: Defaults to false",
 							Type:        []string{"boolean"},
 							Format:      "",
 						},
 					},
+					"pvcRetentionPeriod": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PVCRetentionPeriod is how long the orphan PVCs left by scale-in are retained before being deleted when they are to be deleted according to EnablePVReclaim or pvcDeletePolicy. The data of a retained PVC can be recovered by removing the annotation `tidb.pingcap.com/pvc-defer-deleting` from it before scaling out the component again. Optional: Defaults to 24h",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"tombstoneStoreRetentionPeriod": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TombstoneStoreRetentionPeriod is how long the tombstone stores left by scale-in or failover are kept in PD before being removed. The tombstone stores are removed only when all of them have been offline for longer than this period. Optional: Defaults to nil, which means the tombstone stores are never removed",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
 					"tlsCluster": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Whether enable the TLS connection between TiDB server components Optional: Defaults to nil",
@@ -11179,7 +12765,7 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbClusterSpec(ref common.ReferenceCallba
 					},
 					"pdAddresses": {
 						SchemaProps: spec.SchemaProps{
-							Description: "PDAddresses are the external PD addresses, if configured, the PDs in this TidbCluster will join to the configured PD cluster.",
+							Description: "PDAddresses are the external PD addresses, if configured, the PDs in this TidbCluster will join to the configured PD cluster. If PD is not specified in this TidbCluster, the other components connect to the PD cluster at the addresses, e.g. a PD cluster out of Kubernetes, and the https scheme must be used if TLS is enabled.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
@@ -11204,6 +12790,13 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbClusterSpec(ref common.ReferenceCallba
 							Ref:         ref("k8s.io/api/core/v1.PodSecurityContext"),
 						},
 					},
+					"securityProfile": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SecurityProfile of TiDB cluster Pods, Restricted or Unrestricted Optional: Defaults to Restricted for new clusters and Unrestricted for the existing ones",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"topologySpreadConstraints": {
 						VendorExtensible: spec.VendorExtensible{
 							Extensions: spec.Extensions{
@@ -11223,11 +12816,36 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbClusterSpec(ref common.ReferenceCallba
 							},
 						},
 					},
+					"postUpgradeHook": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PostUpgradeHook is the SQL executed by a job each time TiDB is upgraded to a new version, e.g. analyzing the critical tables or setting the system variables of the new features",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PostUpgradeHookSpec"),
+						},
+					},
+					"restore": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Restore restores the data into the TidbCluster by BR when it is created, the cluster is not Ready until the restore completes. It is ignored if it is set after the cluster is created.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRestoreSpec"),
+						},
+					},
+					"restartSchedules": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RestartSchedules schedule the periodic rolling restarts of the components, e.g. restarting TiDB weekly to clear the memory fragmentation. The pods are restarted gracefully one at a time as the upgraders do.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RestartSchedule"),
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DiscoverySpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PumpSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TLSCluster", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiCDCSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiFlashSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRef", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.DiscoverySpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.HelperSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PDSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PostUpgradeHookSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.PumpSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.RestartSchedule", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TLSCluster", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiCDCSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiDBSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiFlashSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TiKVSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRef", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRestoreSpec", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TopologySpreadConstraint", "k8s.io/api/core/v1.Affinity", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.Toleration", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
 	}
 }
 
@@ -11372,12 +12990,38 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbInitializerSpec(ref common.ReferenceCa
 							Format:      "",
 						},
 					},
+					"initSqlScripts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "InitSqlScripts are the SQL scripts executed one by one in order after the TiDB cluster is bootstrapped, the statements of a script are executed in a transaction, except the DDL statements which are committed implicitly by TiDB. The state of each script is reported in the status. If the initialization fails, deleting the job resumes it from the failed script. It can not be set with initSql or initSqlConfigMap.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.InitSqlScript"),
+									},
+								},
+							},
+						},
+					},
 					"passwordSecret": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"string"},
 							Format: "",
 						},
 					},
+					"passwordExternalSecret": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PasswordExternalSecret references the passwords in an external secret manager, which are fetched by backup-manager in an init container of the job. Like passwordSecret, the keys of the secret are the users and the values are the passwords. It can not be set with passwordSecret. The region of AWS Secrets Manager and the access of Vault default to the ones configured in the operator.",
+							Ref:         ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ExternalSecretRef"),
+						},
+					},
+					"serviceAccount": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ServiceAccount of the job, e.g. the one bound to the IAM role or the role of the Kubernetes auth method of Vault which is allowed to read passwordExternalSecret",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"resources": {
 						SchemaProps: spec.SchemaProps{
 							Ref: ref("k8s.io/api/core/v1.ResourceRequirements"),
@@ -11397,12 +13041,38 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbInitializerSpec(ref common.ReferenceCa
 							Format:      "",
 						},
 					},
+					"onFailure": {
+						SchemaProps: spec.SchemaProps{
+							Description: "OnFailure is the policy when the job fails: Retry recreates the job after the backoff until maxRetries are exhausted, Abort leaves the initialization failed, and Ignore marks the initialization completed. The initialization fails if the retries are exhausted or it times out. Optional: Defaults to Abort",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"maxRetries": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxRetries is the max number of the retries if onFailure is Retry. Optional: Defaults to 3",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"retryBackoff": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RetryBackoff is the backoff before the first retry, which doubles for each retry up to 5m. Optional: Defaults to 10s",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"timeout": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Timeout is the overall timeout of the initialization including the retries, e.g. it covers waiting for TiDB to be ready. The running job is terminated after it and the initialization fails. Optional: Defaults to nil, which means no timeout",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
 				},
 				Required: []string{"image", "cluster"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRef", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.ResourceRequirements"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.ExternalSecretRef", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.InitSqlScript", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.TidbClusterRef", "k8s.io/api/core/v1.LocalObjectReference", "k8s.io/api/core/v1.PodSecurityContext", "k8s.io/api/core/v1.ResourceRequirements", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
 	}
 }
 
@@ -11471,11 +13141,50 @@ func schema_pkg_apis_pingcap_v1alpha1_TidbInitializerStatus(ref common.Reference
 							Format:      "",
 						},
 					},
+					"scripts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Scripts are the states of initSqlScripts in order",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.InitSqlScriptStatus"),
+									},
+								},
+							},
+						},
+					},
+					"attempts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Attempts are the jobs run for the initialization, the latest last",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.InitializerAttempt"),
+									},
+								},
+							},
+						},
+					},
+					"nextRetryTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NextRetryTime is the time the job is recreated to retry the initialization",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Message explains the phase, e.g. why the initialization fails",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/api/batch/v1.JobCondition", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+			"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.InitSqlScriptStatus", "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1.InitializerAttempt", "k8s.io/api/batch/v1.JobCondition", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
@@ -12251,6 +13960,35 @@ func schema_pkg_apis_pingcap_v1alpha1_WorkerSpec(ref common.ReferenceCallback) c
 							},
 						},
 					},
+					"pvcDeletePolicy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PVCDeletePolicy determines whether the orphan PVCs left by the scale-in of the component are deleted. Delete is the same as setting the cluster-level enablePVReclaim to true for the component, and Retain is the same as setting it to false. Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"pvcAnnotations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PVCAnnotations are added to the PVCs of the component, e.g. `ebs.csi.aws.com/iops` and `ebs.csi.aws.com/throughput` of EBS gp3 volumes for the provisioners reading volume parameters from the PVC annotations. They are set on the volumeClaimTemplates and updated to the existing PVCs. Optional: Defaults to nil",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"securityProfile": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SecurityProfile of the component. Override the cluster-level securityProfile if present Optional: Defaults to cluster-level setting",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 					"limits": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Limits describes the maximum amount of compute resources allowed. More info: https://kubernetes.io/docs/concepts/configuration/manage-compute-resources-container/",
@@ -66,6 +66,12 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&TidbMonitorList{},
 		&TidbClusterAutoScaler{},
 		&TidbClusterAutoScalerList{},
+		&TidbClusterFederation{},
+		&TidbClusterFederationList{},
+		&TidbClusterReplication{},
+		&TidbClusterReplicationList{},
+		&TidbClusterClone{},
+		&TidbClusterCloneList{},
 		&DMCluster{},
 		&DMClusterList{},
 	)
@@ -16,6 +16,7 @@ package v1alpha1
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"strings"
 	"time"
 
@@ -37,6 +38,24 @@ const (
 	defaultEnablePVReclaim    = false
 	// defaultEvictLeaderTimeout is the timeout limit of evict leader
 	defaultEvictLeaderTimeout = 1500 * time.Minute
+	// defaultPVCRetentionPeriod is how long the orphan PVCs left by scale-in are retained
+	defaultPVCRetentionPeriod = 24 * time.Hour
+	// defaultFailoverRecoveryHealthyPeriod is how long all the members must be healthy before the failover
+	// replicas are removed in Auto mode
+	defaultFailoverRecoveryHealthyPeriod = 10 * time.Minute
+	// defaultTLSCertRenewBefore is how long before the expiration the certificates are re-issued
+	defaultTLSCertRenewBefore = 30 * 24 * time.Hour
+	// defaultVaultPKIPath is the default mount path of the Vault PKI secrets engine
+	defaultVaultPKIPath = "pki"
+	// defaultVaultKubernetesAuthPath is the default mount path of the Vault Kubernetes auth method
+	defaultVaultKubernetesAuthPath = "kubernetes"
+	// defaultTLSCABundleReloadDelay covers the sync period of the kubelet to update the mounted secrets
+	// and the interval of the components to reload the certificates
+	defaultTLSCABundleReloadDelay = 3 * time.Minute
+	// defaultTiDBFailoverProbeQuery reads from TiKV so that the TiDB members which fail to access the storage are detected
+	defaultTiDBFailoverProbeQuery   = "SELECT COUNT(*) FROM mysql.tidb"
+	defaultTiDBFailoverProbeUser    = "root"
+	defaultTiDBFailoverProbeTimeout = 5 * time.Second
 )
 
 var (
@@ -234,6 +253,23 @@ func (tc *TidbCluster) TiDBImage() string {
 	return image
 }
 
+// TiDBVersion return the image version used by TiDB.
+//
+// If TiDB isn't specified, return empty string.
+func (tc *TidbCluster) TiDBVersion() string {
+	if tc.Spec.TiDB == nil {
+		return ""
+	}
+
+	image := tc.TiDBImage()
+	colonIdx := strings.LastIndexByte(image, ':')
+	if colonIdx >= 0 {
+		return image[colonIdx+1:]
+	}
+
+	return "latest"
+}
+
 // PumpImage return the image used by Pump.
 //
 // If Pump isn't specified, return nil.
@@ -330,25 +366,37 @@ func (tc *TidbCluster) TiFlashScaling() bool {
 	return tc.Status.TiFlash.Phase == ScalePhase
 }
 
-func (tc *TidbCluster) getDeleteSlots(component string) (deleteSlots sets.Int32) {
+// GetDeleteSlots returns the delete slots of the given component, which is
+// the union of `spec.<component>.deleteSlots` and the legacy delete slots
+// annotation.
+func (tc *TidbCluster) GetDeleteSlots(component string) (deleteSlots sets.Int32) {
 	deleteSlots = sets.NewInt32()
-	annotations := tc.GetAnnotations()
-	if annotations == nil {
-		return deleteSlots
-	}
 	var key string
-	if component == label.PDLabelVal {
+	switch component {
+	case label.PDLabelVal:
 		key = label.AnnPDDeleteSlots
-	} else if component == label.TiDBLabelVal {
+		if tc.Spec.PD != nil {
+			deleteSlots.Insert(tc.Spec.PD.DeleteSlots...)
+		}
+	case label.TiDBLabelVal:
 		key = label.AnnTiDBDeleteSlots
-	} else if component == label.TiKVLabelVal {
+		if tc.Spec.TiDB != nil {
+			deleteSlots.Insert(tc.Spec.TiDB.DeleteSlots...)
+		}
+	case label.TiKVLabelVal:
 		key = label.AnnTiKVDeleteSlots
-	} else if component == label.TiFlashLabelVal {
+		if tc.Spec.TiKV != nil {
+			deleteSlots.Insert(tc.Spec.TiKV.DeleteSlots...)
+		}
+	case label.TiFlashLabelVal:
 		key = label.AnnTiFlashDeleteSlots
-	} else {
+		if tc.Spec.TiFlash != nil {
+			deleteSlots.Insert(tc.Spec.TiFlash.DeleteSlots...)
+		}
+	default:
 		return
 	}
-	value, ok := annotations[key]
+	value, ok := tc.GetAnnotations()[key]
 	if !ok {
 		return
 	}
@@ -437,7 +485,7 @@ func (tc *TidbCluster) PDStsDesiredOrdinals(excludeFailover bool) sets.Int32 {
 	if !excludeFailover {
 		replicas = tc.PDStsDesiredReplicas()
 	}
-	return helper.GetPodOrdinalsFromReplicasAndDeleteSlots(replicas, tc.getDeleteSlots(label.PDLabelVal))
+	return helper.GetPodOrdinalsFromReplicasAndDeleteSlots(replicas, tc.GetDeleteSlots(label.PDLabelVal))
 }
 
 // TiKVAllPodsStarted return whether all pods of TiKV are started.
@@ -494,7 +542,7 @@ func (tc *TidbCluster) TiKVStsDesiredOrdinals(excludeFailover bool) sets.Int32 {
 	if !excludeFailover {
 		replicas = tc.TiKVStsDesiredReplicas()
 	}
-	return helper.GetPodOrdinalsFromReplicasAndDeleteSlots(replicas, tc.getDeleteSlots(label.TiKVLabelVal))
+	return helper.GetPodOrdinalsFromReplicasAndDeleteSlots(replicas, tc.GetDeleteSlots(label.TiKVLabelVal))
 }
 
 // TiFlashAllPodsStarted return whether all pods of TiFlash are started.
@@ -559,7 +607,7 @@ func (tc *TidbCluster) TiFlashStsDesiredOrdinals(excludeFailover bool) sets.Int3
 	if !excludeFailover {
 		replicas = tc.TiFlashStsDesiredReplicas()
 	}
-	return helper.GetPodOrdinalsFromReplicasAndDeleteSlots(replicas, tc.getDeleteSlots(label.TiFlashLabelVal))
+	return helper.GetPodOrdinalsFromReplicasAndDeleteSlots(replicas, tc.GetDeleteSlots(label.TiFlashLabelVal))
 }
 
 // TiDBAllPodsStarted return whether all pods of TiDB are started.
@@ -575,6 +623,16 @@ func (tc *TidbCluster) TiDBAllPodsStarted() bool {
 // TiDBAllMembersReady return whether all members of TiDB are ready.
 //
 // If TiDB isn't specified, return false.
+// TiDBSQLProbeFailing returns true if the failover probe of any TiDB member is failing
+func (tc *TidbCluster) TiDBSQLProbeFailing() bool {
+	for _, member := range tc.Status.TiDB.Members {
+		if member.SQLProbeFailingSince != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func (tc *TidbCluster) TiDBAllMembersReady() bool {
 	if tc.Spec.TiDB == nil {
 		return false
@@ -616,7 +674,7 @@ func (tc *TidbCluster) TiDBStsDesiredOrdinals(excludeFailover bool) sets.Int32 {
 	if !excludeFailover {
 		replicas = tc.TiDBStsDesiredReplicas()
 	}
-	return helper.GetPodOrdinalsFromReplicasAndDeleteSlots(replicas, tc.getDeleteSlots(label.TiDBLabelVal))
+	return helper.GetPodOrdinalsFromReplicasAndDeleteSlots(replicas, tc.GetDeleteSlots(label.TiDBLabelVal))
 }
 
 // PDIsAvailable return whether PD is available.
@@ -708,6 +766,74 @@ func (tc *TidbCluster) IsTLSClusterEnabled() bool {
 	return tc.Spec.TLSCluster != nil && tc.Spec.TLSCluster.Enabled
 }
 
+// TLSCertRotationEnabled returns whether the certificates in the cluster secrets are rotated by the operator
+func (tc *TidbCluster) TLSCertRotationEnabled() bool {
+	return tc.IsTLSClusterEnabled() && tc.Spec.TLSCluster.CertRotation != nil
+}
+
+// GetIssuer returns the issuer which re-issues the certificates
+func (r *TLSCertRotation) GetIssuer() TLSCertIssuer {
+	if r.Issuer == "" {
+		return TLSCertIssuerCSR
+	}
+	return r.Issuer
+}
+
+// GetRenewBefore returns how long before the expiration the certificates are re-issued
+func (r *TLSCertRotation) GetRenewBefore() time.Duration {
+	if r.RenewBefore == nil {
+		return defaultTLSCertRenewBefore
+	}
+	return r.RenewBefore.Duration
+}
+
+// CertManagerEnabled returns whether the cert-manager Certificates of the cluster secrets are generated by the operator
+func (tc *TidbCluster) CertManagerEnabled() bool {
+	return tc.IsTLSClusterEnabled() && tc.Spec.TLSCluster.CertManager != nil
+}
+
+// VaultEnabled returns whether the certificates of the cluster secrets are issued from Vault by the operator
+func (tc *TidbCluster) VaultEnabled() bool {
+	return tc.IsTLSClusterEnabled() && tc.Spec.TLSCluster.Vault != nil
+}
+
+// GetPKIPath returns the mount path of the PKI secrets engine
+func (v *TLSVault) GetPKIPath() string {
+	if v.PKIPath == "" {
+		return defaultVaultPKIPath
+	}
+	return v.PKIPath
+}
+
+// GetRenewBefore returns how long before the expiration the certificates are re-issued
+func (v *TLSVault) GetRenewBefore() time.Duration {
+	if v.RenewBefore == nil {
+		return defaultTLSCertRenewBefore
+	}
+	return v.RenewBefore.Duration
+}
+
+// GetMountPath returns the mount path of the Kubernetes auth method
+func (a *VaultKubernetesAuth) GetMountPath() string {
+	if a.MountPath == "" {
+		return defaultVaultKubernetesAuthPath
+	}
+	return a.MountPath
+}
+
+// TLSCABundleEnabled returns whether the CA bundle is propagated into the cluster secrets by the operator
+func (tc *TidbCluster) TLSCABundleEnabled() bool {
+	return tc.IsTLSClusterEnabled() && tc.Spec.TLSCluster.CABundle != nil
+}
+
+// GetReloadDelay returns how long the components reloading the CA bundle online take to reload it
+func (b *TLSCABundle) GetReloadDelay() time.Duration {
+	if b.ReloadDelay == nil {
+		return defaultTLSCABundleReloadDelay
+	}
+	return b.ReloadDelay.Duration
+}
+
 func (tc *TidbCluster) Scheme() string {
 	if tc.IsTLSClusterEnabled() {
 		return "https"
@@ -731,6 +857,22 @@ func (tc *TidbCluster) IsPVReclaimEnabled() bool {
 	return *enabled
 }
 
+// PVCRetentionPeriod returns how long the orphan PVCs left by scale-in are retained before being deleted
+func (tc *TidbCluster) PVCRetentionPeriod() time.Duration {
+	if tc.Spec.PVCRetentionPeriod == nil {
+		return defaultPVCRetentionPeriod
+	}
+	return tc.Spec.PVCRetentionPeriod.Duration
+}
+
+// GetHealthyPeriod returns how long all the members must be healthy before the failover replicas are removed in Auto mode
+func (p *FailoverRecoveryPolicy) GetHealthyPeriod() time.Duration {
+	if p.HealthyPeriod == nil {
+		return defaultFailoverRecoveryHealthyPeriod
+	}
+	return p.HealthyPeriod.Duration
+}
+
 func (tc *TidbCluster) IsTiDBBinlogEnabled() bool {
 	var binlogEnabled *bool
 	if tc.Spec.TiDB != nil {
@@ -748,6 +890,39 @@ func (tidb *TiDBSpec) IsTLSClientEnabled() bool {
 	return tidb.TLSClient != nil && tidb.TLSClient.Enabled
 }
 
+// GetQuery returns the SQL statement of the failover probe
+func (p *TiDBFailoverProbe) GetQuery() string {
+	if p.Query == "" {
+		return defaultTiDBFailoverProbeQuery
+	}
+	return p.Query
+}
+
+// GetUser returns the user to execute the failover probe
+func (p *TiDBFailoverProbe) GetUser() string {
+	if p.User == "" {
+		return defaultTiDBFailoverProbeUser
+	}
+	return p.User
+}
+
+// GetTimeout returns the timeout of the failover probe
+func (p *TiDBFailoverProbe) GetTimeout() time.Duration {
+	if p.TimeoutSeconds == nil || *p.TimeoutSeconds <= 0 {
+		return defaultTiDBFailoverProbeTimeout
+	}
+	return time.Duration(*p.TimeoutSeconds) * time.Second
+}
+
+// GetFailurePeriod returns how long the failover probe must keep failing before the member is counted as failed,
+// defaultPeriod is returned if it is not set
+func (p *TiDBFailoverProbe) GetFailurePeriod(defaultPeriod time.Duration) time.Duration {
+	if p.FailurePeriod == nil {
+		return defaultPeriod
+	}
+	return p.FailurePeriod.Duration
+}
+
 func (tidb *TiDBSpec) ShouldSeparateSlowLog() bool {
 	separateSlowLog := tidb.SeparateSlowLog
 	if separateSlowLog == nil {
@@ -892,3 +1067,69 @@ func (tc *TidbCluster) TiCDCLogLevel() string {
 func (tc *TidbCluster) HeterogeneousWithoutLocalPD() bool {
 	return tc.Spec.Cluster != nil && len(tc.Spec.Cluster.Name) > 0 && tc.Spec.PD == nil
 }
+
+// ExternalPDOnly returns whether the components of the TidbCluster join the PD cluster out of Kubernetes,
+// e.g. deployed on bare metal or by TiUP, at `spec.pdAddresses` without a local PD or a referred TidbCluster
+func (tc *TidbCluster) ExternalPDOnly() bool {
+	return tc.Spec.PD == nil && !tc.HeterogeneousWithoutLocalPD() && len(tc.Spec.PDAddresses) > 0
+}
+
+// ExternalPDHosts returns the host:port of the PD addresses in `spec.pdAddresses`
+func (tc *TidbCluster) ExternalPDHosts() []string {
+	hosts := make([]string, 0, len(tc.Spec.PDAddresses))
+	for _, address := range tc.Spec.PDAddresses {
+		if u, err := url.Parse(address); err == nil && u.Host != "" {
+			hosts = append(hosts, u.Host)
+			continue
+		}
+		hosts = append(hosts, address)
+	}
+	return hosts
+}
+
+// AdoptionComponents are the components created in Kubernetes one at a time in the order when
+// an existing cluster is adopted
+var AdoptionComponents = []MemberType{PDMemberType, TiKVMemberType, TiFlashMemberType, TiDBMemberType, TiCDCMemberType, PumpMemberType}
+
+// SuspendComponents are the components which are suspended in the order, they are resumed in the reverse order
+var SuspendComponents = []MemberType{TiDBMemberType, TiCDCMemberType, PumpMemberType, TiFlashMemberType, TiKVMemberType, PDMemberType}
+
+// ComponentSuspended returns whether the component is scaled to zero, or being scaled back, by the suspension,
+// the StatefulSet of the component is not synced by its member manager until it is resumed
+func (tc *TidbCluster) ComponentSuspended(memberType MemberType) bool {
+	if tc.Status.Suspend == nil {
+		return false
+	}
+	for _, component := range tc.Status.Suspend.Components {
+		if component.Component == memberType {
+			return true
+		}
+	}
+	return false
+}
+
+// Adopting returns whether the TidbCluster is adopting an existing cluster, the operations which may
+// delete the members or the stores, e.g. scaling in and failover, are refused until the adoption completes
+func (tc *TidbCluster) Adopting() bool {
+	return tc.Status.Adoption != nil && tc.Status.Adoption.Phase != AdoptionPhaseComplete
+}
+
+// AdoptionPending returns whether the component is not created in Kubernetes yet as the adoption
+// of the components before it is in progress
+func (tc *TidbCluster) AdoptionPending(memberType MemberType) bool {
+	if !tc.Adopting() {
+		return false
+	}
+	if tc.Status.Adoption.Phase == AdoptionPhaseDiscovering {
+		return true
+	}
+	order := func(memberType MemberType) int {
+		for i, component := range AdoptionComponents {
+			if component == memberType {
+				return i
+			}
+		}
+		return len(AdoptionComponents)
+	}
+	return order(memberType) > order(tc.Status.Adoption.Component)
+}
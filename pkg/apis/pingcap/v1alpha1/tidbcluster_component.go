@@ -49,6 +49,9 @@ type ComponentAccessor interface {
 	TerminationGracePeriodSeconds() *int64
 	StatefulSetUpdateStrategy() apps.StatefulSetUpdateStrategyType
 	TopologySpreadConstraints() []corev1.TopologySpreadConstraint
+	PVCDeletePolicy() PVCDeletePolicy
+	PVCAnnotations() map[string]string
+	SecurityProfile() SecurityProfile
 }
 
 // Component defines component identity of all components
@@ -85,7 +88,9 @@ type componentAccessorImpl struct {
 	configUpdateStrategy      ConfigUpdateStrategy
 	statefulSetUpdateStrategy apps.StatefulSetUpdateStrategyType
 	podSecurityContext        *corev1.PodSecurityContext
+	securityProfile           SecurityProfile
 	topologySpreadConstraints []TopologySpreadConstraint
+	pvReclaimEnabled          bool
 
 	// ComponentSpec is the Component Spec
 	ComponentSpec *ComponentSpec
@@ -108,6 +113,16 @@ func (a *componentAccessorImpl) PodSecurityContext() *corev1.PodSecurityContext
 	return a.ComponentSpec.PodSecurityContext
 }
 
+func (a *componentAccessorImpl) SecurityProfile() SecurityProfile {
+	if a.ComponentSpec == nil || len(a.ComponentSpec.SecurityProfile) == 0 {
+		if len(a.securityProfile) == 0 {
+			return SecurityProfileUnrestricted
+		}
+		return a.securityProfile
+	}
+	return a.ComponentSpec.SecurityProfile
+}
+
 func (a *componentAccessorImpl) ImagePullPolicy() corev1.PullPolicy {
 	if a.ComponentSpec == nil || a.ComponentSpec.ImagePullPolicy == nil {
 		return a.imagePullPolicy
@@ -322,6 +337,58 @@ func (a *componentAccessorImpl) TopologySpreadConstraints() []corev1.TopologySpr
 	return ptscs
 }
 
+func (a *componentAccessorImpl) PVCDeletePolicy() PVCDeletePolicy {
+	if a.ComponentSpec == nil || a.ComponentSpec.PVCDeletePolicy == nil {
+		if a.pvReclaimEnabled {
+			return PVCDeletePolicyDelete
+		}
+		return PVCDeletePolicyRetain
+	}
+	return *a.ComponentSpec.PVCDeletePolicy
+}
+
+func (a *componentAccessorImpl) PVCAnnotations() map[string]string {
+	anno := map[string]string{}
+	if a.ComponentSpec != nil {
+		for k, v := range a.ComponentSpec.PVCAnnotations {
+			anno[k] = v
+		}
+	}
+	return anno
+}
+
+// PVCDeletePolicies returns the PVC delete policies of the components with PVCs left by scale-in,
+// keyed by the component label value
+func (tc *TidbCluster) PVCDeletePolicies() map[string]PVCDeletePolicy {
+	return map[string]PVCDeletePolicy{
+		label.PDLabelVal:      tc.BasePDSpec().PVCDeletePolicy(),
+		label.TiKVLabelVal:    tc.BaseTiKVSpec().PVCDeletePolicy(),
+		label.TiFlashLabelVal: tc.BaseTiFlashSpec().PVCDeletePolicy(),
+		label.PumpLabelVal:    tc.BasePumpSpec().PVCDeletePolicy(),
+	}
+}
+
+// PVCAnnotations returns the PVC annotations of the components keyed by the component label value
+func (tc *TidbCluster) PVCAnnotations() map[string]map[string]string {
+	return map[string]map[string]string{
+		label.PDLabelVal:      tc.BasePDSpec().PVCAnnotations(),
+		label.TiKVLabelVal:    tc.BaseTiKVSpec().PVCAnnotations(),
+		label.TiDBLabelVal:    tc.BaseTiDBSpec().PVCAnnotations(),
+		label.TiFlashLabelVal: tc.BaseTiFlashSpec().PVCAnnotations(),
+		label.TiCDCLabelVal:   tc.BaseTiCDCSpec().PVCAnnotations(),
+		label.PumpLabelVal:    tc.BasePumpSpec().PVCAnnotations(),
+	}
+}
+
+// PVCDeletePolicies returns the PVC delete policies of the components with PVCs left by scale-in,
+// keyed by the component label value
+func (dc *DMCluster) PVCDeletePolicies() map[string]PVCDeletePolicy {
+	return map[string]PVCDeletePolicy{
+		label.DMMasterLabelVal: dc.BaseMasterSpec().PVCDeletePolicy(),
+		label.DMWorkerLabelVal: dc.BaseWorkerSpec().PVCDeletePolicy(),
+	}
+}
+
 func getComponentLabelValue(c Component) string {
 	switch c {
 	case ComponentPD:
@@ -367,7 +434,9 @@ func buildTidbClusterComponentAccessor(c Component, tc *TidbCluster, componentSp
 		configUpdateStrategy:      spec.ConfigUpdateStrategy,
 		statefulSetUpdateStrategy: spec.StatefulSetUpdateStrategy,
 		podSecurityContext:        spec.PodSecurityContext,
+		securityProfile:           spec.SecurityProfile,
 		topologySpreadConstraints: spec.TopologySpreadConstraints,
+		pvReclaimEnabled:          tc.IsPVReclaimEnabled(),
 
 		ComponentSpec: componentSpec,
 	}
@@ -392,6 +461,7 @@ func buildDMClusterComponentAccessor(c Component, dc *DMCluster, componentSpec *
 		configUpdateStrategy:      ConfigUpdateStrategyRollingUpdate,
 		podSecurityContext:        spec.PodSecurityContext,
 		topologySpreadConstraints: spec.TopologySpreadConstraints,
+		pvReclaimEnabled:          dc.IsPVReclaimEnabled(),
 
 		ComponentSpec: componentSpec,
 	}
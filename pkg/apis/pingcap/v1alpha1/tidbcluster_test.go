@@ -17,6 +17,7 @@ import (
 	"testing"
 
 	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/label"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -407,6 +408,35 @@ func TestComponentAccessor(t *testing.T) {
 	}
 }
 
+func TestPVCDeletePolicies(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	retain := PVCDeletePolicyRetain
+	del := PVCDeletePolicyDelete
+	tc := &TidbCluster{
+		Spec: TidbClusterSpec{
+			EnablePVReclaim: pointer.BoolPtr(true),
+			PD:              &PDSpec{},
+			TiKV:            &TiKVSpec{ComponentSpec: ComponentSpec{PVCDeletePolicy: &retain}},
+		},
+	}
+	g.Expect(tc.PVCDeletePolicies()).To(Equal(map[string]PVCDeletePolicy{
+		label.PDLabelVal:      PVCDeletePolicyDelete,
+		label.TiKVLabelVal:    PVCDeletePolicyRetain,
+		label.TiFlashLabelVal: PVCDeletePolicyDelete,
+		label.PumpLabelVal:    PVCDeletePolicyDelete,
+	}))
+
+	tc.Spec.EnablePVReclaim = pointer.BoolPtr(false)
+	tc.Spec.TiKV.PVCDeletePolicy = &del
+	g.Expect(tc.PVCDeletePolicies()).To(Equal(map[string]PVCDeletePolicy{
+		label.PDLabelVal:      PVCDeletePolicyRetain,
+		label.TiKVLabelVal:    PVCDeletePolicyDelete,
+		label.TiFlashLabelVal: PVCDeletePolicyRetain,
+		label.PumpLabelVal:    PVCDeletePolicyRetain,
+	}))
+}
+
 func TestHelperImage(t *testing.T) {
 	g := NewGomegaWithT(t)
 
@@ -568,6 +598,29 @@ func TestPDVersion(t *testing.T) {
 	}
 }
 
+func TestAdoptionPending(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbCluster()
+	g.Expect(tc.Adopting()).To(BeFalse())
+	g.Expect(tc.AdoptionPending(PDMemberType)).To(BeFalse())
+
+	// no component is created until the members are discovered
+	tc.Status.Adoption = &AdoptionStatus{Phase: AdoptionPhaseDiscovering}
+	g.Expect(tc.Adopting()).To(BeTrue())
+	g.Expect(tc.AdoptionPending(PDMemberType)).To(BeTrue())
+
+	tc.Status.Adoption = &AdoptionStatus{Phase: AdoptionPhaseAdopting, Component: TiKVMemberType}
+	g.Expect(tc.AdoptionPending(PDMemberType)).To(BeFalse())
+	g.Expect(tc.AdoptionPending(TiKVMemberType)).To(BeFalse())
+	g.Expect(tc.AdoptionPending(TiFlashMemberType)).To(BeTrue())
+	g.Expect(tc.AdoptionPending(TiDBMemberType)).To(BeTrue())
+
+	tc.Status.Adoption.Phase = AdoptionPhaseComplete
+	g.Expect(tc.Adopting()).To(BeFalse())
+	g.Expect(tc.AdoptionPending(TiDBMemberType)).To(BeFalse())
+}
+
 func newTidbCluster() *TidbCluster {
 	return &TidbCluster{
 		TypeMeta: metav1.TypeMeta{
@@ -156,12 +156,30 @@ type TidbMonitorRef struct {
 // +k8s:openapi-gen=true
 // TidbClusterAutoScalerStatus describe the whole status
 type TidbClusterAutoScalerStatus struct {
+	// ObservedGeneration is the most recent generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// Tikv describes the status of each group for the tikv in the last auto-scaling reconciliation
 	// +optional
 	TiKV map[string]TikvAutoScalerStatus `json:"tikv,omitempty"`
 	// Tidb describes the status of each group for the tidb in the last auto-scaling reconciliation
 	// +optional
 	TiDB map[string]TidbAutoScalerStatus `json:"tidb,omitempty"`
+	// TiKVReplicas describes the replicas of tikv summed up over the target cluster and the auto-scaled clusters
+	// +optional
+	TiKVReplicas *AutoScalerReplicasStatus `json:"tikvReplicas,omitempty"`
+	// TiDBReplicas describes the replicas of tidb summed up over the target cluster and the auto-scaled clusters
+	// +optional
+	TiDBReplicas *AutoScalerReplicasStatus `json:"tidbReplicas,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// AutoScalerReplicasStatus describes the current and the target replicas of a component
+type AutoScalerReplicasStatus struct {
+	// Current is the number of the ready replicas
+	Current int32 `json:"current"`
+	// Target is the number of the desired replicas in the spec
+	Target int32 `json:"target"`
 }
 
 // +k8s:openapi-gen=true
@@ -0,0 +1,168 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type ClonePhase string
+
+const (
+	// ClonePhaseBackingUp indicates that the data of the source is being backed up
+	ClonePhaseBackingUp ClonePhase = "BackingUp"
+	// ClonePhaseRestoring indicates that the target is being created and the data is being restored into it
+	ClonePhaseRestoring ClonePhase = "Restoring"
+	// ClonePhaseMasking indicates that the masked columns are being updated in the target
+	ClonePhaseMasking ClonePhase = "Masking"
+	// ClonePhaseComplete indicates that the target is ready with the data of the source
+	ClonePhaseComplete ClonePhase = "Complete"
+	// ClonePhaseFailed indicates that the clone stops because of an error
+	ClonePhaseFailed ClonePhase = "Failed"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// +k8s:openapi-gen=true
+// TidbClusterClone clones a source TidbCluster into a new TidbCluster, e.g. in
+// another namespace as a staging copy of the production cluster
+type TidbClusterClone struct {
+	metav1.TypeMeta `json:",inline"`
+	// +k8s:openapi-gen=false
+	metav1.ObjectMeta `json:"metadata"`
+
+	// Spec defines the desired state of TidbClusterClone
+	Spec TidbClusterCloneSpec `json:"spec"`
+
+	// +k8s:openapi-gen=false
+	// Most recently observed status of the TidbClusterClone
+	Status TidbClusterCloneStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// +k8s:openapi-gen=true
+// TidbClusterCloneList is TidbClusterClone list
+type TidbClusterCloneList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +k8s:openapi-gen=false
+	metav1.ListMeta `json:"metadata"`
+
+	Items []TidbClusterClone `json:"items"`
+}
+
+// +k8s:openapi-gen=true
+// TidbClusterCloneSpec describes the clone of the source into the target.
+// The clone is done once, changing the spec after the target is created does not take effect.
+type TidbClusterCloneSpec struct {
+	// Source is the TidbCluster to clone
+	Source TidbClusterRef `json:"source"`
+
+	// Target is the TidbCluster created with the spec and the data of the source
+	Target TidbClusterCloneTarget `json:"target"`
+
+	// Backup is the spec of the Backup of the source by BR, e.g. the storage of the
+	// backup data. The Backup is created in the namespace of the TidbClusterClone, and
+	// the data is restored from the same storage, so the secret of the storage must
+	// exist in the namespaces of both the TidbClusterClone and the target. The target
+	// is not created until all the secrets referenced by the source and the Backup
+	// exist in the namespace of the target.
+	Backup BackupSpec `json:"backup"`
+
+	// Masks are the columns scrubbed in the target after the data is restored
+	// +optional
+	Masks []ColumnMask `json:"masks,omitempty"`
+
+	// MaskImage is the image of the TidbInitializer which updates the masked columns.
+	// Optional: Defaults to the image set by the flag --tidb-initializer-image of the operator
+	// +optional
+	MaskImage string `json:"maskImage,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// TidbClusterCloneTarget describes the TidbCluster created by the clone.
+// The replicas of the components not set are the same as the source.
+type TidbClusterCloneTarget struct {
+	// Name is the name of the TidbCluster.
+	// Optional: Defaults to the name of the source
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Namespace is the namespace of the TidbCluster, it must exist
+	Namespace string `json:"namespace"`
+
+	// PDReplicas is the replicas of PD
+	// +optional
+	PDReplicas *int32 `json:"pdReplicas,omitempty"`
+
+	// TiKVReplicas is the replicas of TiKV, it must not be less than
+	// the max replicas of the regions in the PD config of the source
+	// +optional
+	TiKVReplicas *int32 `json:"tikvReplicas,omitempty"`
+
+	// TiFlashReplicas is the replicas of TiFlash
+	// +optional
+	TiFlashReplicas *int32 `json:"tiflashReplicas,omitempty"`
+
+	// TiDBReplicas is the replicas of TiDB
+	// +optional
+	TiDBReplicas *int32 `json:"tidbReplicas,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// ColumnMask scrubs a column of the target, e.g. the emails or the phone numbers of the users
+type ColumnMask struct {
+	// Table is the table of the column in the format of `<database>.<table>`
+	Table string `json:"table"`
+
+	// Column is the name of the column
+	Column string `json:"column"`
+
+	// Expression is the SQL expression the column is set to, e.g. `MD5(email)`.
+	// Optional: Defaults to NULL
+	// +optional
+	Expression string `json:"expression,omitempty"`
+}
+
+// TidbClusterCloneStatus is the progress of the clone
+type TidbClusterCloneStatus struct {
+	// Phase is the step of the clone in progress
+	// +optional
+	Phase ClonePhase `json:"phase,omitempty"`
+
+	// BackupName is the name of the Backup of the source
+	// +optional
+	BackupName string `json:"backupName,omitempty"`
+
+	// TidbClusterName is the name of the target TidbCluster
+	// +optional
+	TidbClusterName string `json:"tidbClusterName,omitempty"`
+
+	// InitializerName is the name of the TidbInitializer which updates the masked columns in the target
+	// +optional
+	InitializerName string `json:"initializerName,omitempty"`
+
+	// Message is the detail of the step in progress or of the failure
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// StartTime is the time the clone starts
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time the clone completes
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
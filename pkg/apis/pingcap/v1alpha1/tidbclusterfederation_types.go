@@ -0,0 +1,159 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type FederationPhase string
+
+const (
+	// FederationPhaseNormal indicates that all members are in the desired state
+	FederationPhaseNormal FederationPhase = "Normal"
+	// FederationPhaseUpgrading indicates that the members are being upgraded one by one
+	FederationPhaseUpgrading FederationPhase = "Upgrading"
+	// FederationPhaseRotatingTLSCerts indicates that the TLS certificates of the members are being rotated one by one
+	FederationPhaseRotatingTLSCerts FederationPhase = "RotatingTLSCerts"
+	// FederationPhasePaused indicates that the sync of all members is paused
+	FederationPhasePaused FederationPhase = "Paused"
+)
+
+// FederationMemberOperation is the operation the federation is performing on a member
+type FederationMemberOperation string
+
+const (
+	// FederationMemberOperationNone indicates that no operation is in progress on the member
+	FederationMemberOperationNone FederationMemberOperation = ""
+	// FederationMemberOperationUpgrade indicates that the member is being upgraded
+	FederationMemberOperationUpgrade FederationMemberOperation = "Upgrade"
+	// FederationMemberOperationRotateTLSCerts indicates that the TLS certificates of the member are being rotated
+	FederationMemberOperationRotateTLSCerts FederationMemberOperation = "RotateTLSCerts"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// +k8s:openapi-gen=true
+// TidbClusterFederation coordinates the operations that must be ordered
+// globally across several TidbClusters, which may live in different
+// namespaces or Kubernetes clusters
+type TidbClusterFederation struct {
+	metav1.TypeMeta `json:",inline"`
+	// +k8s:openapi-gen=false
+	metav1.ObjectMeta `json:"metadata"`
+
+	// Spec defines the desired state of TidbClusterFederation
+	Spec TidbClusterFederationSpec `json:"spec"`
+
+	// +k8s:openapi-gen=false
+	// Most recently observed status of the TidbClusterFederation
+	Status TidbClusterFederationStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// +k8s:openapi-gen=true
+// TidbClusterFederationList is TidbClusterFederation list
+type TidbClusterFederationList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +k8s:openapi-gen=false
+	metav1.ListMeta `json:"metadata"`
+
+	Items []TidbClusterFederation `json:"items"`
+}
+
+// +k8s:openapi-gen=true
+// TidbClusterFederationSpec describes the desired state of the federated TidbClusters
+type TidbClusterFederationSpec struct {
+	// Members are the TidbClusters of the federation. Operations are
+	// applied to the members one by one in this order.
+	Members []TidbClusterFederationMember `json:"members"`
+
+	// Version is the TiDB version all the members are upgraded to.
+	// Optional: Defaults to nil, which means the version of the members is not managed
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// TLSCertRotation is an arbitrary identifier of a TLS certificates rotation.
+	// Changing it rotates the certificates of all the members one by one.
+	// +optional
+	TLSCertRotation string `json:"tlsCertRotation,omitempty"`
+
+	// Paused pauses or resumes the sync of all the members.
+	// Optional: Defaults to nil, which means the paused state of the members is not managed
+	// +optional
+	Paused *bool `json:"paused,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// TidbClusterFederationMember refers to a TidbCluster of the federation
+type TidbClusterFederationMember struct {
+	// Name is the name of the TidbCluster
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the TidbCluster.
+	// Optional: Defaults to the namespace of the TidbClusterFederation
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// KubeConfigSecretName is the name of the secret in the namespace of the
+	// TidbClusterFederation which stores the kubeconfig of the Kubernetes
+	// cluster the TidbCluster lives in under the `kubeconfig` key.
+	// Optional: Defaults to empty, which means the local Kubernetes cluster
+	// +optional
+	KubeConfigSecretName string `json:"kubeConfigSecretName,omitempty"`
+}
+
+// TidbClusterFederationStatus is the aggregate status of the federated TidbClusters
+type TidbClusterFederationStatus struct {
+	// ObservedGeneration is the most recent generation observed by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Phase is the operation in progress across the federation
+	// +optional
+	Phase FederationPhase `json:"phase,omitempty"`
+
+	// Members is the per-member progress of the federation
+	// +optional
+	Members []TidbClusterFederationMemberStatus `json:"members,omitempty"`
+}
+
+// TidbClusterFederationMemberStatus is the status of a member of the federation
+type TidbClusterFederationMemberStatus struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Version is the TiDB version of the member
+	// +optional
+	Version string `json:"version,omitempty"`
+	// Paused indicates whether the sync of the member is paused
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+	// Ready indicates whether the member is ready
+	// +optional
+	Ready bool `json:"ready,omitempty"`
+	// TLSCertRotation is the last TLS certificates rotation applied to the member
+	// +optional
+	TLSCertRotation string `json:"tlsCertRotation,omitempty"`
+	// Operation is the operation in progress on the member
+	// +optional
+	Operation FederationMemberOperation `json:"operation,omitempty"`
+	// Message is a human readable message indicating details about the member
+	// +optional
+	Message string `json:"message,omitempty"`
+	// LastTransitionTime is the last time the status of the member changed
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
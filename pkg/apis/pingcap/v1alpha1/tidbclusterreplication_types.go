@@ -0,0 +1,250 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type ReplicationPhase string
+
+const (
+	// ReplicationPhasePending indicates that the changefeed is not created yet
+	ReplicationPhasePending ReplicationPhase = "Pending"
+	// ReplicationPhaseReplicating indicates that the changefeed replicates the changes within the max lag
+	ReplicationPhaseReplicating ReplicationPhase = "Replicating"
+	// ReplicationPhaseLagging indicates that the checkpoint of the changefeed lags behind more than the max lag
+	ReplicationPhaseLagging ReplicationPhase = "Lagging"
+	// ReplicationPhasePaused indicates that the changefeed is paused
+	ReplicationPhasePaused ReplicationPhase = "Paused"
+	// ReplicationPhaseFailed indicates that the changefeed stops because of an error
+	ReplicationPhaseFailed ReplicationPhase = "Failed"
+	// ReplicationPhasePromoting indicates that the secondary is being promoted to take over the writes
+	ReplicationPhasePromoting ReplicationPhase = "Promoting"
+	// ReplicationPhasePromoted indicates that the replication is stopped and the secondary is writable
+	ReplicationPhasePromoted ReplicationPhase = "Promoted"
+)
+
+// PromotionStep is the step of the promotion of the secondary
+type PromotionStep string
+
+const (
+	// PromotionStepWaitingForCheckpoint indicates that the promotion waits for the checkpoint of
+	// the changefeed to catch up with the time the promotion starts
+	PromotionStepWaitingForCheckpoint PromotionStep = "WaitingForCheckpoint"
+	// PromotionStepStoppingReplication indicates that the changefeed is being removed
+	PromotionStepStoppingReplication PromotionStep = "StoppingReplication"
+	// PromotionStepDisablingReadOnly indicates that the read-only flags of the secondary are being turned off
+	PromotionStepDisablingReadOnly PromotionStep = "DisablingReadOnly"
+	// PromotionStepComplete indicates that the secondary is promoted
+	PromotionStepComplete PromotionStep = "Complete"
+)
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// +k8s:openapi-gen=true
+// TidbClusterReplication replicates the changes of a primary TidbCluster to
+// a secondary TidbCluster by a TiCDC changefeed of the primary
+type TidbClusterReplication struct {
+	metav1.TypeMeta `json:",inline"`
+	// +k8s:openapi-gen=false
+	metav1.ObjectMeta `json:"metadata"`
+
+	// Spec defines the desired state of TidbClusterReplication
+	Spec TidbClusterReplicationSpec `json:"spec"`
+
+	// +k8s:openapi-gen=false
+	// Most recently observed status of the TidbClusterReplication
+	Status TidbClusterReplicationStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// +k8s:openapi-gen=true
+// TidbClusterReplicationList is TidbClusterReplication list
+type TidbClusterReplicationList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +k8s:openapi-gen=false
+	metav1.ListMeta `json:"metadata"`
+
+	Items []TidbClusterReplication `json:"items"`
+}
+
+// +k8s:openapi-gen=true
+// TidbClusterReplicationSpec describes the replication from the primary to the secondary
+type TidbClusterReplicationSpec struct {
+	// Primary is the TidbCluster the changes are replicated from, TiCDC must be deployed in it
+	Primary TidbClusterRef `json:"primary"`
+
+	// Secondary is the TidbCluster the changes are replicated to.
+	// Changing it or the password in its secret pauses the changefeed,
+	// updates the sink and resumes the changefeed.
+	Secondary TidbClusterReplicationSecondary `json:"secondary"`
+
+	// ChangefeedID is the ID of the changefeed, which consists of letters,
+	// numbers and the hyphens between them.
+	// Optional: Defaults to the name of the TidbClusterReplication
+	// +optional
+	ChangefeedID string `json:"changefeedID,omitempty"`
+
+	// StartTS is the TSO the replication starts from.
+	// Optional: Defaults to the current TSO
+	// +optional
+	StartTS string `json:"startTS,omitempty"`
+
+	// FilterRules are the table filter rules of the changefeed.
+	// Changing them pauses the changefeed, updates it and resumes it.
+	// +optional
+	FilterRules []string `json:"filterRules,omitempty"`
+
+	// Paused pauses or resumes the changefeed
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+
+	// MaxLag is the max lag of the checkpoint of the changefeed before the replication is lagging.
+	// Optional: Defaults to 5m
+	// +optional
+	MaxLag *metav1.Duration `json:"maxLag,omitempty"`
+
+	// Promote promotes the secondary to take over the writes in a DR event: the changefeed is
+	// removed after its checkpoint catches up, and the read-only flags of the secondary are turned off.
+	// The promotion is irreversible once it begins, setting it back to false does not resume the replication.
+	// +optional
+	Promote bool `json:"promote,omitempty"`
+
+	// PromotionTimeout is how long the promotion waits for the checkpoint to catch up and for TiCDC
+	// of the primary to remove the changefeed. The promotion goes on after the timeout, e.g. if the
+	// primary is unavailable, and the changes after the checkpoint in the status may be lost.
+	// Optional: Defaults to 5m
+	// +optional
+	PromotionTimeout *metav1.Duration `json:"promotionTimeout,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// TidbClusterReplicationSecondary refers to the TiDB of the secondary TidbCluster
+type TidbClusterReplicationSecondary struct {
+	// Name is the name of the TidbCluster
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the TidbCluster.
+	// Optional: Defaults to the namespace of the TidbClusterReplication
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// ClusterDomain is the domain of the Kubernetes cluster the TidbCluster lives in
+	// +optional
+	ClusterDomain string `json:"clusterDomain,omitempty"`
+
+	// Host is the address of TiDB of the TidbCluster.
+	// Optional: Defaults to the TiDB service of the TidbCluster
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// Port is the port of TiDB of the TidbCluster.
+	// Optional: Defaults to 4000
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// User is the user TiCDC logs in TiDB as.
+	// Optional: Defaults to root
+	// +optional
+	User string `json:"user,omitempty"`
+
+	// SecretName is the name of the secret in the namespace of the
+	// TidbClusterReplication which stores the password of the user under
+	// the `password` key.
+	// Optional: Defaults to empty, which means no password
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// TLSClientSecretName is the name of the secret which stores the client
+	// certificate to access TiDB, it must be one of
+	// `spec.ticdc.tlsClientSecretNames` of the primary TidbCluster.
+	// Optional: Defaults to nil
+	// +optional
+	TLSClientSecretName *string `json:"tlsClientSecretName,omitempty"`
+}
+
+// TidbClusterReplicationStatus is the health of the replication
+type TidbClusterReplicationStatus struct {
+	// ObservedGeneration is the most recent generation observed by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Phase is the health of the replication
+	// +optional
+	Phase ReplicationPhase `json:"phase,omitempty"`
+
+	// ChangefeedID is the ID of the changefeed created in the primary
+	// +optional
+	ChangefeedID string `json:"changefeedID,omitempty"`
+
+	// ConfigHash is the hash of the sink and the filter rules the changefeed is
+	// created or last updated with. The password is not included in the hash.
+	// +optional
+	ConfigHash string `json:"configHash,omitempty"`
+
+	// State is the state of the changefeed reported by TiCDC
+	// +optional
+	State string `json:"state,omitempty"`
+
+	// CheckpointTS is the TSO the changes before which have been replicated
+	// +optional
+	CheckpointTS string `json:"checkpointTS,omitempty"`
+
+	// CheckpointTime is the physical time of the checkpoint
+	// +optional
+	CheckpointTime *metav1.Time `json:"checkpointTime,omitempty"`
+
+	// Lag is the lag of the checkpoint behind the time the status is updated
+	// +optional
+	Lag *metav1.Duration `json:"lag,omitempty"`
+
+	// Message is a human readable message indicating details about the replication
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// LastTransitionTime is the last time the phase changed
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// Promotion is the progress of the promotion of the secondary
+	// +optional
+	Promotion *ReplicationPromotionStatus `json:"promotion,omitempty"`
+}
+
+// ReplicationPromotionStatus is the progress of the promotion of the secondary
+type ReplicationPromotionStatus struct {
+	// Step is the step of the promotion
+	Step PromotionStep `json:"step"`
+
+	// StartTime is the time the promotion starts, the changes committed in
+	// the primary before it are replicated unless the promotion times out
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time the secondary is promoted
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// CheckpointTS is the checkpoint of the changefeed when the replication is stopped,
+	// the changes after it in the primary are not replicated to the secondary
+	// +optional
+	CheckpointTS string `json:"checkpointTS,omitempty"`
+
+	// CaughtUp is true if the checkpoint caught up with the start time of the promotion
+	// +optional
+	CaughtUp bool `json:"caughtUp,omitempty"`
+}
@@ -13,6 +13,14 @@
 
 package v1alpha1
 
+import "time"
+
+const (
+	defaultInitializerMaxRetries   = 3
+	defaultInitializerRetryBackoff = 10 * time.Second
+	maxInitializerRetryBackoff     = 5 * time.Minute
+)
+
 // GetPermitHost retrieves the permit host from TidbInitializer
 func (ti *TidbInitializer) GetPermitHost() string {
 	var permitHost string
@@ -23,3 +31,47 @@ func (ti *TidbInitializer) GetPermitHost() string {
 	}
 	return permitHost
 }
+
+// GetOnFailure returns the policy when the job of the initialization fails
+func (ti *TidbInitializer) GetOnFailure() InitializerFailurePolicy {
+	if ti.Spec.OnFailure == "" {
+		return InitializerFailurePolicyAbort
+	}
+	return ti.Spec.OnFailure
+}
+
+// GetMaxRetries returns the max number of the retries if onFailure is Retry
+func (ti *TidbInitializer) GetMaxRetries() int32 {
+	if ti.Spec.MaxRetries == nil {
+		return defaultInitializerMaxRetries
+	}
+	return *ti.Spec.MaxRetries
+}
+
+// GetRetryBackoff returns the backoff before the retry, the retries start from 1
+func (ti *TidbInitializer) GetRetryBackoff(retry int) time.Duration {
+	backoff := defaultInitializerRetryBackoff
+	if ti.Spec.RetryBackoff != nil {
+		backoff = ti.Spec.RetryBackoff.Duration
+	}
+	for i := 1; i < retry && backoff < maxInitializerRetryBackoff; i++ {
+		backoff *= 2
+		if backoff > maxInitializerRetryBackoff {
+			return maxInitializerRetryBackoff
+		}
+	}
+	return backoff
+}
+
+// GetDeadline returns the deadline of the initialization, which is counted from the start of
+// the first attempt, or from now if there is no attempt yet. It returns false if there is no timeout.
+func (ti *TidbInitializer) GetDeadline(now time.Time) (time.Time, bool) {
+	if ti.Spec.Timeout == nil {
+		return time.Time{}, false
+	}
+	start := now
+	if len(ti.Status.Attempts) > 0 {
+		start = ti.Status.Attempts[0].StartTime.Time
+	}
+	return start.Add(ti.Spec.Timeout.Duration), true
+}
@@ -33,6 +33,18 @@ const (
 	InitializePhaseFailed InitializePhase = "Failed"
 )
 
+// InitializerFailurePolicy is the policy when the job of the initialization fails
+type InitializerFailurePolicy string
+
+const (
+	// InitializerFailurePolicyRetry recreates the job after the backoff until the retries are exhausted
+	InitializerFailurePolicyRetry InitializerFailurePolicy = "Retry"
+	// InitializerFailurePolicyAbort leaves the initialization failed
+	InitializerFailurePolicyAbort InitializerFailurePolicy = "Abort"
+	// InitializerFailurePolicyIgnore marks the initialization completed
+	InitializerFailurePolicyIgnore InitializerFailurePolicy = "Ignore"
+)
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -81,9 +93,28 @@ type TidbInitializerSpec struct {
 	// +optional
 	InitSqlConfigMap *string `json:"initSqlConfigMap,omitempty"`
 
+	// InitSqlScripts are the SQL scripts executed one by one in order after the TiDB cluster is bootstrapped,
+	// the statements of a script are executed in a transaction, except the DDL statements which are committed
+	// implicitly by TiDB. The state of each script is reported in the status. If the initialization fails,
+	// deleting the job resumes it from the failed script. It can not be set with initSql or initSqlConfigMap.
+	// +optional
+	InitSqlScripts []InitSqlScript `json:"initSqlScripts,omitempty"`
+
 	// +optional
 	PasswordSecret *string `json:"passwordSecret,omitempty"`
 
+	// PasswordExternalSecret references the passwords in an external secret manager, which are fetched
+	// by backup-manager in an init container of the job. Like passwordSecret, the keys of the secret are
+	// the users and the values are the passwords. It can not be set with passwordSecret. The region of
+	// AWS Secrets Manager and the access of Vault default to the ones configured in the operator.
+	// +optional
+	PasswordExternalSecret *ExternalSecretRef `json:"passwordExternalSecret,omitempty"`
+
+	// ServiceAccount of the job, e.g. the one bound to the IAM role or the role of the Kubernetes
+	// auth method of Vault which is allowed to read passwordExternalSecret
+	// +optional
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+
 	// +optional
 	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
 
@@ -95,6 +126,46 @@ type TidbInitializerSpec struct {
 	// Optional: Defaults to nil
 	// +optional
 	TLSClientSecretName *string `json:"tlsClientSecretName,omitempty"`
+
+	// OnFailure is the policy when the job fails: Retry recreates the job after the backoff until
+	// maxRetries are exhausted, Abort leaves the initialization failed, and Ignore marks the
+	// initialization completed. The initialization fails if the retries are exhausted or it times out.
+	// Optional: Defaults to Abort
+	// +optional
+	OnFailure InitializerFailurePolicy `json:"onFailure,omitempty"`
+
+	// MaxRetries is the max number of the retries if onFailure is Retry.
+	// Optional: Defaults to 3
+	// +optional
+	MaxRetries *int32 `json:"maxRetries,omitempty"`
+
+	// RetryBackoff is the backoff before the first retry, which doubles for each retry up to 5m.
+	// Optional: Defaults to 10s
+	// +optional
+	RetryBackoff *metav1.Duration `json:"retryBackoff,omitempty"`
+
+	// Timeout is the overall timeout of the initialization including the retries, e.g. it covers
+	// waiting for TiDB to be ready. The running job is terminated after it and the initialization fails.
+	// Optional: Defaults to nil, which means no timeout
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// InitSqlScript is a SQL script stored in a key of a ConfigMap or a Secret,
+// exactly one of configMap and secret must be set
+type InitSqlScript struct {
+	// Name identifies the script in the status, it must be unique in the TidbInitializer
+	// and consist of alphanumeric characters, '-', '_' or '.'
+	Name string `json:"name"`
+
+	// ConfigMap selects the key of a ConfigMap which stores the script
+	// +optional
+	ConfigMap *corev1.ConfigMapKeySelector `json:"configMap,omitempty"`
+
+	// Secret selects the key of a Secret which stores the script
+	// +optional
+	Secret *corev1.SecretKeySelector `json:"secret,omitempty"`
 }
 
 // +k8s:openapi-gen=true
@@ -103,6 +174,58 @@ type TidbInitializerStatus struct {
 
 	// Phase is a user readable state inferred from the underlying Job status and TidbCluster status
 	Phase InitializePhase `json:"phase,omitempty"`
+
+	// Scripts are the states of initSqlScripts in order
+	// +optional
+	Scripts []InitSqlScriptStatus `json:"scripts,omitempty"`
+
+	// Attempts are the jobs run for the initialization, the latest last
+	// +optional
+	Attempts []InitializerAttempt `json:"attempts,omitempty"`
+
+	// NextRetryTime is the time the job is recreated to retry the initialization
+	// +optional
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+
+	// Message explains the phase, e.g. why the initialization fails
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// InitializerAttempt is a job run for the initialization
+type InitializerAttempt struct {
+	// StartTime is the time the job is created
+	StartTime metav1.Time `json:"startTime"`
+
+	// CompletionTime is the time the job completes or fails
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Phase is Running, Completed or Failed
+	Phase InitializePhase `json:"phase"`
+
+	// Reason is the reason of the failure of the job, e.g. BackoffLimitExceeded or DeadlineExceeded
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is the message of the failure of the job
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// InitSqlScriptStatus is the state of a script of initSqlScripts
+type InitSqlScriptStatus struct {
+	// Name is the name of the script
+	Name string `json:"name"`
+
+	// Phase is Pending until the script is executed by the job, then Completed or Failed
+	Phase InitializePhase `json:"phase"`
+
+	// Message is the error of the script if it fails
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
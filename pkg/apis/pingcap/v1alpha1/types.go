@@ -17,6 +17,7 @@ import (
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -96,6 +97,29 @@ const (
 	ConfigUpdateStrategyRollingUpdate ConfigUpdateStrategy = "RollingUpdate"
 )
 
+// PVCDeletePolicy determines what happens to the orphan PVCs left by the scale-in of a component
+type PVCDeletePolicy string
+
+const (
+	// PVCDeletePolicyDelete deletes the orphan PVCs after they are no longer used by any pod
+	PVCDeletePolicyDelete PVCDeletePolicy = "Delete"
+	// PVCDeletePolicyRetain retains the orphan PVCs
+	PVCDeletePolicyRetain PVCDeletePolicy = "Retain"
+)
+
+// SecurityProfile determines the security settings the operator applies to the pods of a component
+type SecurityProfile string
+
+const (
+	// SecurityProfileRestricted runs the containers as non-root users with all capabilities dropped,
+	// privilege escalation disallowed, the RuntimeDefault seccomp profile and a read-only root
+	// filesystem if the component does not write to it, so the pods are admitted by the
+	// `restricted` Pod Security Standard. Explicitly configured security contexts take precedence.
+	SecurityProfileRestricted SecurityProfile = "Restricted"
+	// SecurityProfileUnrestricted leaves the security contexts of the pods as configured
+	SecurityProfileUnrestricted SecurityProfile = "Unrestricted"
+)
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -168,6 +192,19 @@ type TidbClusterSpec struct {
 	// +optional
 	Paused bool `json:"paused,omitempty"`
 
+	// Whether the cluster is suspended, e.g. to save the cost of a non-production cluster overnight or on
+	// weekends. The components are scaled to zero in the order of TiDB, TiCDC, Pump, TiFlash, TiKV and PD,
+	// and the PVCs and the metadata in PD are kept. They are resumed in the reverse order when it is unset.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// Whether the failover of the components is simulated, the members which would be declared
+	// failed and the failover replicas which would be created are only recorded in events and
+	// `status.simulatedFailovers`, no failover replica is created or removed.
+	// Optional: Defaults to false
+	// +optional
+	FailoverSimulation bool `json:"failoverSimulation,omitempty"`
+
 	// TiDB cluster version
 	// +optional
 	Version string `json:"version"`
@@ -199,10 +236,26 @@ type TidbClusterSpec struct {
 	ConfigUpdateStrategy ConfigUpdateStrategy `json:"configUpdateStrategy,omitempty"`
 
 	// Whether enable PVC reclaim for orphan PVC left by statefulset scale-in
+	// It can be overridden by `pvcDeletePolicy` of each component
 	// Optional: Defaults to false
 	// +optional
 	EnablePVReclaim *bool `json:"enablePVReclaim,omitempty"`
 
+	// PVCRetentionPeriod is how long the orphan PVCs left by scale-in are retained before being
+	// deleted when they are to be deleted according to EnablePVReclaim or pvcDeletePolicy. The data of a retained PVC can
+	// be recovered by removing the annotation `tidb.pingcap.com/pvc-defer-deleting` from it before
+	// scaling out the component again.
+	// Optional: Defaults to 24h
+	// +optional
+	PVCRetentionPeriod *metav1.Duration `json:"pvcRetentionPeriod,omitempty"`
+
+	// TombstoneStoreRetentionPeriod is how long the tombstone stores left by scale-in or failover are
+	// kept in PD before being removed. The tombstone stores are removed only when all of them have been
+	// offline for longer than this period.
+	// Optional: Defaults to nil, which means the tombstone stores are never removed
+	// +optional
+	TombstoneStoreRetentionPeriod *metav1.Duration `json:"tombstoneStoreRetentionPeriod,omitempty"`
+
 	// Whether enable the TLS connection between TiDB server components
 	// Optional: Defaults to nil
 	// +optional
@@ -266,6 +319,8 @@ type TidbClusterSpec struct {
 	Cluster *TidbClusterRef `json:"cluster,omitempty"`
 
 	// PDAddresses are the external PD addresses, if configured, the PDs in this TidbCluster will join to the configured PD cluster.
+	// If PD is not specified in this TidbCluster, the other components connect to the PD cluster at the addresses,
+	// e.g. a PD cluster out of Kubernetes, and the https scheme must be used if TLS is enabled.
 	// +optional
 	PDAddresses []string `json:"pdAddresses,omitempty"`
 
@@ -277,6 +332,12 @@ type TidbClusterSpec struct {
 	// +optional
 	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
 
+	// SecurityProfile of TiDB cluster Pods, Restricted or Unrestricted
+	// Optional: Defaults to Restricted for new clusters and Unrestricted for the existing ones
+	// +kubebuilder:validation:Enum=Restricted;Unrestricted
+	// +optional
+	SecurityProfile SecurityProfile `json:"securityProfile,omitempty"`
+
 	// TopologySpreadConstraints describes how a group of pods ought to spread across topology
 	// domains. Scheduler will schedule pods in a way which abides by the constraints.
 	// This field is is only honored by clusters that enables the EvenPodsSpread feature.
@@ -285,29 +346,542 @@ type TidbClusterSpec struct {
 	// +listType=map
 	// +listMapKey=topologyKey
 	TopologySpreadConstraints []TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// PostUpgradeHook is the SQL executed by a job each time TiDB is upgraded to a new version,
+	// e.g. analyzing the critical tables or setting the system variables of the new features
+	// +optional
+	PostUpgradeHook *PostUpgradeHookSpec `json:"postUpgradeHook,omitempty"`
+
+	// Restore restores the data into the TidbCluster by BR when it is created, the cluster is not Ready
+	// until the restore completes. It is ignored if it is set after the cluster is created.
+	// +optional
+	Restore *TidbClusterRestoreSpec `json:"restore,omitempty"`
+
+	// RestartSchedules schedule the periodic rolling restarts of the components, e.g. restarting TiDB weekly
+	// to clear the memory fragmentation. The pods are restarted gracefully one at a time as the upgraders do.
+	// +optional
+	RestartSchedules []RestartSchedule `json:"restartSchedules,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// RestartSchedule schedules the periodic rolling restarts of a component
+type RestartSchedule struct {
+	// Component is the component to restart, one of pd, tikv, tiflash and tidb
+	Component MemberType `json:"component"`
+
+	// Schedule is the cron string of the time the maintenance windows begin, e.g. "0 3 * * 6"
+	Schedule string `json:"schedule"`
+
+	// Window is the length of the maintenance windows, the pods which have not begun to restart when
+	// the window ends are not restarted until the next window. Defaults to 2h.
+	// +optional
+	Window *metav1.Duration `json:"window,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// TidbClusterRestoreSpec is the source of the data restored into the TidbCluster when it is created
+type TidbClusterRestoreSpec struct {
+	// Backup is the name of a completed BR Backup in the namespace of the TidbCluster, the storage, the
+	// credentials and the filters of the backup are used to restore it into the TidbCluster instead of the
+	// cluster of the backup
+	// +optional
+	Backup string `json:"backup,omitempty"`
+
+	// RestoreSpec is the spec of the Restore if the data is not backed up by a Backup, e.g. the storage of
+	// the backup data. It can not be set with backup. The cluster and the cluster namespace of `br` must be
+	// empty or refer to the TidbCluster, the data is always restored into the TidbCluster.
+	// +optional
+	RestoreSpec *RestoreSpec `json:"restoreSpec,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// PostUpgradeHookSpec is the SQL executed by a job after TiDB is upgraded to a new version
+type PostUpgradeHookSpec struct {
+	// SQL is the statements executed after the upgrade, one statement per line
+	// +optional
+	SQL string `json:"sql,omitempty"`
+
+	// SQLConfigMap references the key of a ConfigMap which contains the statements, one statement
+	// per line. Only one of sql and sqlConfigMap can be set.
+	// +optional
+	SQLConfigMap *corev1.ConfigMapKeySelector `json:"sqlConfigMap,omitempty"`
+
+	// Image of the job, which is required to have python and the MySQLdb module
+	// Optional: Defaults to tnir/mysqlclient
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// User is the user who executes the statements
+	// Optional: Defaults to root
+	// +optional
+	User string `json:"user,omitempty"`
+
+	// PasswordSecret is the name of the secret which stores the password of the user under the `password` key
+	// Optional: Defaults to empty, which means no password
+	// +optional
+	PasswordSecret *string `json:"passwordSecret,omitempty"`
+
+	// TLSClientSecretName is the name of the secret which stores the client certificate to access TiDB
+	// Optional: Defaults to the client certificate of the TidbCluster
+	// +optional
+	TLSClientSecretName *string `json:"tlsClientSecretName,omitempty"`
+
+	// Resources of the job
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
 }
 
 // TidbClusterStatus represents the current status of a tidb cluster.
 type TidbClusterStatus struct {
-	ClusterID  string                    `json:"clusterID,omitempty"`
-	PD         PDStatus                  `json:"pd,omitempty"`
-	TiKV       TiKVStatus                `json:"tikv,omitempty"`
-	TiDB       TiDBStatus                `json:"tidb,omitempty"`
-	Pump       PumpStatus                `json:"pump,omitempty"`
+	// ObservedGeneration is the most recent generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	ClusterID string     `json:"clusterID,omitempty"`
+	PD        PDStatus   `json:"pd,omitempty"`
+	TiKV      TiKVStatus `json:"tikv,omitempty"`
+	TiDB      TiDBStatus `json:"tidb,omitempty"`
+	Pump      PumpStatus `json:"pump,omitempty"`
+	// Drainer is the status of the drainers registered in the cluster,
+	// which are deployed out of the TidbCluster
+	// +optional
+	Drainer    DrainerStatus             `json:"drainer,omitempty"`
 	TiFlash    TiFlashStatus             `json:"tiflash,omitempty"`
 	TiCDC      TiCDCStatus               `json:"ticdc,omitempty"`
 	AutoScaler *TidbClusterAutoScalerRef `json:"auto-scaler,omitempty"`
+	// RetainedPVCs contains the orphan PVCs left by scale-in which are retained until
+	// their deletion time, keyed by the PVC name.
+	// +optional
+	RetainedPVCs map[string]RetainedPVCStatus `json:"retainedPVCs,omitempty"`
+	// TLSCerts contains the rotation states of the certificates in the cluster secrets when
+	// `spec.tlsCluster.certRotation` is set, keyed by the component, e.g. pd, tikv or client.
+	// +optional
+	TLSCerts map[string]TLSCertStatus `json:"tlsCerts,omitempty"`
+	// TLSCABundle is the propagation state of the CA bundle when `spec.tlsCluster.caBundle` is set
+	// +optional
+	TLSCABundle *TLSCABundleStatus `json:"tlsCABundle,omitempty"`
+	// SimulatedFailovers contains the latest results of the failover simulation of the components,
+	// keyed by the member type, when `spec.failoverSimulation` is enabled.
+	// +optional
+	SimulatedFailovers map[MemberType]SimulatedFailover `json:"simulatedFailovers,omitempty"`
+	// Operations is the history of the recent significant operations on the components, e.g. scaling,
+	// upgrades, failovers and config rollouts, from the oldest to the latest. The oldest finished
+	// operations are dropped once the number of the records exceeds the limit.
+	// +optional
+	Operations []OperationRecord `json:"operations,omitempty"`
+	// PendingChanges contains the changes of the StatefulSets, ConfigMaps and Services which would be
+	// applied for the current spec. They are computed without being applied only if the cluster is paused
+	// and the annotation `tidb.pingcap.com/dry-run: "true"` is set.
+	// +optional
+	PendingChanges []PendingChange `json:"pendingChanges,omitempty"`
+	// Federation is the state of the peering with the TidbClusters in the other Kubernetes clusters,
+	// it is reported only if `spec.clusterDomain` is set and the cluster has peers.
+	// +optional
+	Federation *FederationStatus `json:"federation,omitempty"`
+	// Adoption is the progress of adopting the existing cluster at `spec.pdAddresses`, it is reported
+	// only if the annotation `tidb.pingcap.com/adopt: "true"` is set.
+	// +optional
+	Adoption *AdoptionStatus `json:"adoption,omitempty"`
+	// PostUpgradeHook is the state of the post-upgrade hook for the latest TiDB version, it is reported
+	// only if `spec.postUpgradeHook` is set.
+	// +optional
+	PostUpgradeHook *PostUpgradeHookStatus `json:"postUpgradeHook,omitempty"`
+	// Suspend is the progress of suspending or resuming the cluster, it is reported until the cluster
+	// is resumed after `spec.suspend` is unset.
+	// +optional
+	Suspend *SuspendStatus `json:"suspend,omitempty"`
+	// RestartSchedules are the progress of the scheduled rolling restarts of the components
+	// +optional
+	RestartSchedules []RestartScheduleStatus `json:"restartSchedules,omitempty"`
+	// Restore is the progress of restoring the data into the cluster when it is created, it is reported
+	// only if `spec.restore` is set.
+	// +optional
+	Restore *TidbClusterRestoreStatus `json:"restore,omitempty"`
 	// Represents the latest available observations of a tidb cluster's state.
 	// +optional
 	Conditions []TidbClusterCondition `json:"conditions,omitempty"`
 }
 
+// AdoptionPhase is the phase of adopting an existing cluster
+type AdoptionPhase string
+
+const (
+	// AdoptionPhaseDiscovering means the members of the existing cluster are being discovered through PD
+	AdoptionPhaseDiscovering AdoptionPhase = "Discovering"
+	// AdoptionPhaseAdopting means the components are being created in Kubernetes one at a time
+	AdoptionPhaseAdopting AdoptionPhase = "Adopting"
+	// AdoptionPhaseComplete means all the components in the spec are created and ready in Kubernetes
+	AdoptionPhaseComplete AdoptionPhase = "Complete"
+)
+
+// RestartScheduleStatus is the progress of the scheduled rolling restarts of a component
+type RestartScheduleStatus struct {
+	// Component is the component restarted by the schedule
+	Component MemberType `json:"component"`
+	// LastScheduleTime is the time the last maintenance window begins
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// WindowEndTime is the time the current maintenance window ends, it is reported until all the
+	// pods are restarted in the window or the window ends
+	// +optional
+	WindowEndTime *metav1.Time `json:"windowEndTime,omitempty"`
+	// LastCompletionTime is the time all the pods are restarted in the last maintenance window they are restarted in
+	// +optional
+	LastCompletionTime *metav1.Time `json:"lastCompletionTime,omitempty"`
+	// Message is a human readable message indicating why the pods are not restarted in the last window
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// SuspendPhase is the phase of suspending or resuming a TidbCluster
+type SuspendPhase string
+
+const (
+	// SuspendPhaseSuspending means the components are being scaled to zero
+	SuspendPhaseSuspending SuspendPhase = "Suspending"
+	// SuspendPhaseSuspended means all the components are scaled to zero
+	SuspendPhaseSuspended SuspendPhase = "Suspended"
+	// SuspendPhaseResuming means the components are being scaled to the replicas before the suspension
+	SuspendPhaseResuming SuspendPhase = "Resuming"
+)
+
+// SuspendStatus is the progress of suspending or resuming a TidbCluster
+type SuspendStatus struct {
+	// Phase is the phase of the suspension
+	Phase SuspendPhase `json:"phase"`
+	// Components are the suspended components and the replicas of their StatefulSets before the
+	// suspension, in the order they are suspended
+	// +optional
+	Components []SuspendedComponent `json:"components,omitempty"`
+	// Message is a human readable message indicating which component is being suspended or resumed
+	// +optional
+	Message string `json:"message,omitempty"`
+	// SuspendTime is the time all the components are scaled to zero
+	// +optional
+	SuspendTime *metav1.Time `json:"suspendTime,omitempty"`
+}
+
+// SuspendedComponent is a component scaled to zero by the suspension
+type SuspendedComponent struct {
+	// Component is the type of the component
+	Component MemberType `json:"component"`
+	// Replicas is the replicas of the StatefulSet before the suspension, it is restored when resuming
+	Replicas int32 `json:"replicas"`
+}
+
+// TidbClusterRestorePhase is the phase of restoring the data into a new TidbCluster
+type TidbClusterRestorePhase string
+
+const (
+	// TidbClusterRestorePending means the Restore is not created until the components are ready
+	TidbClusterRestorePending TidbClusterRestorePhase = "Pending"
+	// TidbClusterRestoreRunning means the Restore is created and not finished yet
+	TidbClusterRestoreRunning TidbClusterRestorePhase = "Running"
+	// TidbClusterRestoreComplete means the Restore completes
+	TidbClusterRestoreComplete TidbClusterRestorePhase = "Complete"
+	// TidbClusterRestoreFailed means the Restore fails, it is created again if it is deleted
+	TidbClusterRestoreFailed TidbClusterRestorePhase = "Failed"
+	// TidbClusterRestoreSkipped means `spec.restore` is set after the cluster is created, nothing is restored
+	TidbClusterRestoreSkipped TidbClusterRestorePhase = "Skipped"
+)
+
+// TidbClusterRestoreStatus is the progress of restoring the data into a new TidbCluster
+type TidbClusterRestoreStatus struct {
+	// Phase is the phase of the restore
+	Phase TidbClusterRestorePhase `json:"phase"`
+	// RestoreName is the name of the Restore created for the cluster
+	// +optional
+	RestoreName string `json:"restoreName,omitempty"`
+	// Message is a human readable message indicating why the restore is pending or fails
+	// +optional
+	Message string `json:"message,omitempty"`
+	// StartTime is the time the Restore is created
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time the Restore completes
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// PostUpgradeHookPhase is the phase of the post-upgrade hook
+type PostUpgradeHookPhase string
+
+const (
+	// PostUpgradeHookObserved means the version is observed without an upgrade, e.g. it is the version
+	// when the cluster is created or the hook is added, the hook is not executed for it
+	PostUpgradeHookObserved PostUpgradeHookPhase = "Observed"
+	// PostUpgradeHookRunning means the job of the hook is running
+	PostUpgradeHookRunning PostUpgradeHookPhase = "Running"
+	// PostUpgradeHookCompleted means the job of the hook completes
+	PostUpgradeHookCompleted PostUpgradeHookPhase = "Completed"
+	// PostUpgradeHookFailed means the job of the hook fails, it is executed again if the job is deleted
+	PostUpgradeHookFailed PostUpgradeHookPhase = "Failed"
+)
+
+// PostUpgradeHookStatus is the state of the post-upgrade hook
+type PostUpgradeHookStatus struct {
+	// Version is the latest version of TiDB which all the TiDB pods are upgraded to
+	Version string `json:"version"`
+	// Phase is the phase of the hook for the version
+	Phase PostUpgradeHookPhase `json:"phase"`
+	// JobName is the name of the job which executes the hook for the version
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+	// StartTime is the time the job is created
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time the job completes or fails
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Message is a human readable message indicating why the job fails
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// AdoptionStatus is the progress of adopting an existing cluster deployed out of the operator
+type AdoptionStatus struct {
+	// Phase is the phase of the adoption
+	Phase AdoptionPhase `json:"phase"`
+	// Component is the component being created in Kubernetes, the components after it are not created
+	// until it is ready
+	// +optional
+	Component MemberType `json:"component,omitempty"`
+	// AdoptedComponents are the components which are created and ready in Kubernetes
+	// +optional
+	AdoptedComponents []MemberType `json:"adoptedComponents,omitempty"`
+	// ExternalMembers are the PD members and the stores of the existing cluster out of the TidbCluster,
+	// which are discovered through PD and never changed by the operator
+	// +optional
+	ExternalMembers []AdoptionMember `json:"externalMembers,omitempty"`
+	// Message is a human readable message indicating why the adoption is blocked
+	// +optional
+	Message string `json:"message,omitempty"`
+	// StartTime is the time the adoption starts
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is the time the adoption completes
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// AdoptionMember is a member of the existing cluster discovered through PD
+type AdoptionMember struct {
+	// Component is the component of the member, i.e. pd, tikv or tiflash
+	Component MemberType `json:"component"`
+	// Name is the name of the PD member or the ID of the store
+	Name string `json:"name"`
+	// Address is the client URL of the PD member or the address of the store
+	// +optional
+	Address string `json:"address,omitempty"`
+}
+
+// FederationStatus is the state of the peering of a TidbCluster deployed across Kubernetes clusters
+type FederationStatus struct {
+	// Peers are the PD members in the other Kubernetes clusters, sorted by the name
+	// +optional
+	Peers []FederationPeer `json:"peers,omitempty"`
+	// Joined is true if all the PD members and TiKV stores of the TidbCluster have joined the PD cluster
+	Joined bool `json:"joined"`
+	// Message explains why the TidbCluster has not joined the PD cluster
+	// +optional
+	Message string `json:"message,omitempty"`
+	// LastTransitionTime is the last time the join state or the resolvability of the peers changed
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// FederationPeer is a PD member in another Kubernetes cluster
+type FederationPeer struct {
+	// Name is the name of the PD member
+	Name string `json:"name"`
+	// ClientURL is the client URL of the PD member
+	ClientURL string `json:"clientURL"`
+	// PeerURLs are the peer URLs of the PD member
+	// +optional
+	PeerURLs []string `json:"peerURLs,omitempty"`
+	// Resolvable is true if the host of the client URL can be resolved in the local Kubernetes cluster
+	Resolvable bool `json:"resolvable"`
+	// Error is the error of the resolution of the host
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// PendingChangeAction is the action which would be applied to an object
+type PendingChangeAction string
+
+const (
+	// PendingChangeCreate means the object would be created
+	PendingChangeCreate PendingChangeAction = "Create"
+	// PendingChangeUpdate means the object would be updated
+	PendingChangeUpdate PendingChangeAction = "Update"
+)
+
+// PendingChange is a change of an object which would be applied for the current spec
+type PendingChange struct {
+	// Kind is the kind of the object, i.e. StatefulSet, ConfigMap or Service
+	Kind string `json:"kind"`
+	// Name is the name of the object
+	Name string `json:"name"`
+	// Component is the component the object belongs to
+	Component MemberType `json:"component"`
+	// Action is the action which would be applied to the object
+	Action PendingChangeAction `json:"action"`
+	// Diff is the difference between the applied and the desired object, where the lines starting with
+	// "-" are removed and the lines starting with "+" are added. It is truncated if it is too long.
+	// +optional
+	Diff string `json:"diff,omitempty"`
+}
+
+// OperationType is the type of an operation on a component
+type OperationType string
+
+const (
+	// ScaleOperation means the replicas of the component are changed
+	ScaleOperation OperationType = "Scale"
+	// UpgradeOperation means the pods of the component are rolling updated
+	UpgradeOperation OperationType = "Upgrade"
+	// FailoverOperation means a member of the component failed and a new member is created to replace it
+	FailoverOperation OperationType = "Failover"
+	// ConfigRolloutOperation means the pods of the component are restarted to use the changed config
+	ConfigRolloutOperation OperationType = "ConfigRollout"
+)
+
+// OperationResult is the outcome of an operation
+type OperationResult string
+
+const (
+	// OperationInProgress means the operation is not finished yet
+	OperationInProgress OperationResult = "InProgress"
+	// OperationSucceeded means the operation is finished
+	OperationSucceeded OperationResult = "Succeeded"
+)
+
+// OperationRecord is the record of a significant operation on a component
+type OperationRecord struct {
+	// Type is the type of the operation
+	Type OperationType `json:"type"`
+	// Component is the component the operation is applied to
+	Component MemberType `json:"component"`
+	// Target is the pod the operation is applied to, it is set only for failovers
+	// +optional
+	Target string `json:"target,omitempty"`
+	// Result is the outcome of the operation
+	Result OperationResult `json:"result"`
+	// StartTime is the time the operation is observed to begin
+	StartTime metav1.Time `json:"startTime"`
+	// CompletionTime is the time the operation is observed to finish
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Message is a human readable message indicating details about the operation
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// TLSCertPhase is the rotation phase of a certificate
+type TLSCertPhase string
+
+const (
+	// TLSCertValid means the certificate is not to be rotated
+	TLSCertValid TLSCertPhase = "Valid"
+	// TLSCertExpiring means the certificate expires within the renew period and is to be re-issued
+	TLSCertExpiring TLSCertPhase = "Expiring"
+	// TLSCertRenewing means the certificate is being re-issued
+	TLSCertRenewing TLSCertPhase = "Renewing"
+	// TLSCertPendingReload means the certificate is rotated and the pods are waiting to reload it
+	TLSCertPendingReload TLSCertPhase = "PendingReload"
+	// TLSCertReloading means the pods are being restarted to reload the rotated certificate
+	TLSCertReloading TLSCertPhase = "Reloading"
+)
+
+// TLSCertStatus is the rotation state of the certificate in a cluster secret
+type TLSCertStatus struct {
+	// SecretName is the name of the secret containing the certificate
+	SecretName string `json:"secretName"`
+	// Phase is the rotation phase of the certificate
+	Phase TLSCertPhase `json:"phase"`
+	// NotAfter is the expiration time of the certificate
+	NotAfter metav1.Time `json:"notAfter"`
+	// CertHash is the hash of the certificate in the secret
+	CertHash string `json:"certHash"`
+	// ReloadCertHash is the hash of the certificate the pods are restarted to reload,
+	// it is empty until the certificate is rotated for the first time
+	// +optional
+	ReloadCertHash string `json:"reloadCertHash,omitempty"`
+	// CSRName is the name of the CertificateSigningRequest re-issuing the certificate
+	// +optional
+	CSRName string `json:"csrName,omitempty"`
+	// LastRotationTime is the last time the certificate in the secret was rotated
+	// +optional
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+	// RotationID is the value of the annotation `tidb.pingcap.com/tls-cert-rotate` the certificate
+	// is last re-issued for
+	// +optional
+	RotationID string `json:"rotationID,omitempty"`
+}
+
+// TLSCABundlePhase is the propagation phase of the CA bundle
+type TLSCABundlePhase string
+
+const (
+	// TLSCABundlePropagating means the CA bundle is propagated into the cluster secrets and not all the
+	// members are confirmed to trust it yet
+	TLSCABundlePropagating TLSCABundlePhase = "Propagating"
+	// TLSCABundleTrusted means all the members trust the CA bundle, so the CAs removed from it are removed
+	// from the cluster secrets
+	TLSCABundleTrusted TLSCABundlePhase = "Trusted"
+)
+
+// TLSCABundleStatus is the propagation state of the CA bundle in the cluster secrets
+type TLSCABundleStatus struct {
+	// Phase is the propagation phase of the CA bundle
+	Phase TLSCABundlePhase `json:"phase"`
+	// Hash is the hash of the CA bundle in the cluster secrets
+	Hash string `json:"hash"`
+	// CAs are the SHA-256 fingerprints of the CAs in the cluster secrets
+	CAs []string `json:"cas,omitempty"`
+	// RestartHash is the hash of the CA bundle the pods of the components which do not reload it online are
+	// restarted to reload, it is empty until the CA bundle is changed by the operator for the first time
+	// +optional
+	RestartHash string `json:"restartHash,omitempty"`
+	// PendingComponents are the components whose members are not confirmed to trust the CA bundle yet
+	// +optional
+	PendingComponents []string `json:"pendingComponents,omitempty"`
+	// LastPropagationTime is the last time the CA bundle was propagated into the cluster secrets
+	// +optional
+	LastPropagationTime *metav1.Time `json:"lastPropagationTime,omitempty"`
+}
+
+// SimulatedFailover is what the failover of a component would do if the failover simulation was disabled
+type SimulatedFailover struct {
+	// FailurePods are the pods which would be declared failed
+	FailurePods []string `json:"failurePods,omitempty"`
+	// FailoverReplicas is the number of the failover replicas which would be created
+	FailoverReplicas int32 `json:"failoverReplicas"`
+	// Replicas is the number of the replicas the component would have after the failover
+	Replicas int32 `json:"replicas"`
+	// LastTransitionTime is the last time the failure pods changed
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
+
+// RetainedPVCStatus is the status of an orphan PVC left by scale-in
+type RetainedPVCStatus struct {
+	// PodName is the name of the pod which used the PVC
+	PodName string `json:"podName"`
+	// DeferDeletingTime is the time when the PVC was marked to be deleted
+	DeferDeletingTime metav1.Time `json:"deferDeletingTime"`
+	// DeletionTime is the time after which the PVC will be deleted
+	DeletionTime metav1.Time `json:"deletionTime"`
+}
+
 // TidbClusterCondition describes the state of a tidb cluster at a certain point.
 type TidbClusterCondition struct {
 	// Type of the condition.
 	Type TidbClusterConditionType `json:"type"`
 	// Status of the condition, one of True, False, Unknown.
 	Status corev1.ConditionStatus `json:"status"`
+	// ObservedGeneration is the generation of the tidb cluster the condition was computed from.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// The last time this condition was updated.
 	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
 	// Last time the condition transitioned from one status to another.
@@ -333,8 +907,78 @@ const (
 	// - All TiKV stores are up.
 	// - All TiFlash stores are up.
 	TidbClusterReady TidbClusterConditionType = "Ready"
+	// TidbClusterAvailable indicates that the tidb cluster is able to serve requests,
+	// i.e. PD has the quorum, and there are TiKV stores up and TiDB members healthy.
+	TidbClusterAvailable TidbClusterConditionType = "Available"
+	// TidbClusterProgressing indicates that the tidb cluster is being rolled out or scaled,
+	// i.e. some statefulsets are not up to date or the replicas differ from the spec.
+	TidbClusterProgressing TidbClusterConditionType = "Progressing"
+	// TidbClusterDegraded indicates that some members of the tidb cluster are unhealthy,
+	// or the calls to PD are short-circuited, while the cluster may still be available.
+	TidbClusterDegraded TidbClusterConditionType = "Degraded"
+	// TidbClusterPDDegraded indicates that the calls to PD are short-circuited
+	// since PD is unreachable, and the status of the cluster is synced from
+	// the last-known responses of PD.
+	TidbClusterPDDegraded TidbClusterConditionType = "PDDegraded"
+	// TidbClusterFailoverLimited indicates that the failover of some components
+	// is capped by their maxFailoverCount, so their failed members are not
+	// replaced any more.
+	TidbClusterFailoverLimited TidbClusterConditionType = "FailoverLimited"
+)
+
+// ComponentCondition describes the state of a component of a tidb cluster at a certain point.
+type ComponentCondition struct {
+	// Type of the condition.
+	Type ComponentConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+	// Last time the condition transitioned from one status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// The machine-readable reason for the condition's last transition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// A human readable message indicating details about the transition.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ComponentConditionType represents a component condition value.
+type ComponentConditionType string
+
+const (
+	// ComponentHealthy indicates that all members of the component are healthy,
+	// i.e. the PD, TiDB and Pump members are healthy and the TiKV and TiFlash stores are up.
+	ComponentHealthy ComponentConditionType = "Healthy"
+	// ComponentUpgrading indicates that the component is being rolling updated,
+	// the reason tells which step the upgrade is waiting for.
+	ComponentUpgrading ComponentConditionType = "Upgrading"
+	// ComponentConfigSynced indicates that all pods of the component use the latest ConfigMap
+	// rendered from the spec. Changes applied with the InPlace ConfigUpdateStrategy are not tracked.
+	ComponentConfigSynced ComponentConditionType = "ConfigSynced"
 )
 
+// UpgradeProgress is the progress of the rolling upgrade of a component
+type UpgradeProgress struct {
+	// TargetVersion is the version the component is upgraded to, i.e. the tag of the image in the spec
+	// +optional
+	TargetVersion string `json:"targetVersion,omitempty"`
+	// Replicas is the number of the pods of the component
+	Replicas int32 `json:"replicas"`
+	// UpdatedReplicas is the number of the pods which are upgraded
+	UpdatedReplicas int32 `json:"updatedReplicas"`
+	// CurrentOrdinal is the ordinal of the pod being upgraded
+	// +optional
+	CurrentOrdinal *int32 `json:"currentOrdinal,omitempty"`
+	// BlockingReason is the machine-readable reason why the upgrade is stalled, it is the reason
+	// of the Upgrading condition, e.g. WaitingForLeaderEviction or WaitingForPodReady
+	// +optional
+	BlockingReason string `json:"blockingReason,omitempty"`
+	// Message is a human readable message indicating details about the blocking reason
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
 // +k8s:openapi-gen=true
 // DiscoverySpec contains details of Discovery members
 type DiscoverySpec struct {
@@ -370,6 +1014,15 @@ type PDSpec struct {
 	// +optional
 	MaxFailoverCount *int32 `json:"maxFailoverCount,omitempty"`
 
+	// FailoverRecovery is the policy to remove the failover replicas after the failed members recover.
+	// Optional: the failover replicas are removed as soon as all the members are healthy if it is not set
+	// +optional
+	FailoverRecovery *FailoverRecoveryPolicy `json:"failoverRecovery,omitempty"`
+
+	// RecoverFailover indicates that Operator can recover the failover Pods once all the members are healthy
+	// +optional
+	RecoverFailover bool `json:"recoverFailover,omitempty"`
+
 	// The storageClassName of the persistent volume for PD data storage.
 	// Defaults to Kubernetes default storage class.
 	// +optional
@@ -407,6 +1060,36 @@ type PDSpec struct {
 	// MountClusterClientSecret indicates whether to mount `cluster-client-secret` to the Pod
 	// +optional
 	MountClusterClientSecret *bool `json:"mountClusterClientSecret,omitempty"`
+
+	// DeleteSlots are the ordinals of the PD pods to delete when scaling in, or to skip when scaling out.
+	// It takes effect only if the AdvancedStatefulSet feature is enabled.
+	// The legacy annotation `pd.tidb.pingcap.com/delete-slots` is still respected and merged with it.
+	// +optional
+	DeleteSlots []int32 `json:"deleteSlots,omitempty"`
+}
+
+// FailoverRecoveryMode is the mode to remove the failover replicas after the failed members recover
+type FailoverRecoveryMode string
+
+const (
+	// FailoverRecoveryManual means the failover replicas are removed only if `recoverFailover` of the component is set
+	FailoverRecoveryManual FailoverRecoveryMode = "Manual"
+	// FailoverRecoveryAuto means the failover replicas are removed once all the members of the component are healthy
+	// for the healthy period, or `recoverFailover` of the component is set
+	FailoverRecoveryAuto FailoverRecoveryMode = "Auto"
+)
+
+// FailoverRecoveryPolicy is the policy to remove the failover replicas after the failed members recover
+// +k8s:openapi-gen=true
+type FailoverRecoveryPolicy struct {
+	// Mode is the mode to remove the failover replicas, Manual or Auto
+	// +kubebuilder:validation:Enum=Manual;Auto
+	Mode FailoverRecoveryMode `json:"mode"`
+
+	// HealthyPeriod is how long all the members must be healthy before the failover replicas are removed in Auto mode
+	// Optional: Defaults to 10m
+	// +optional
+	HealthyPeriod *metav1.Duration `json:"healthyPeriod,omitempty"`
 }
 
 // TiKVSpec contains details of TiKV members
@@ -439,6 +1122,11 @@ type TiKVSpec struct {
 	// +optional
 	MaxFailoverCount *int32 `json:"maxFailoverCount,omitempty"`
 
+	// FailoverRecovery is the policy to remove the failover replicas after the failed stores recover.
+	// Optional: Defaults to the Manual mode
+	// +optional
+	FailoverRecovery *FailoverRecoveryPolicy `json:"failoverRecovery,omitempty"`
+
 	// Whether output the RocksDB log in a separate sidecar container
 	// Optional: Defaults to false
 	// +optional
@@ -497,6 +1185,76 @@ type TiKVSpec struct {
 	// EnableNamedStatusPort enables status port(20180) in the Pod spec.
 	// If you set it to `true` for an existing cluster, the TiKV cluster will be rolling updated.
 	EnableNamedStatusPort bool `json:"enableNamedStatusPort,omitempty"`
+
+	// DeleteSlots are the ordinals of the TiKV pods to delete when scaling in, or to skip when scaling out.
+	// It takes effect only if the AdvancedStatefulSet feature is enabled.
+	// The legacy annotation `tikv.tidb.pingcap.com/delete-slots` is still respected and merged with it.
+	// +optional
+	DeleteSlots []int32 `json:"deleteSlots,omitempty"`
+
+	// Encryption enables the encryption at rest of TiKV with the master key from a KMS.
+	// The settings in `security.encryption` of the config are overridden if it is set.
+	// +optional
+	Encryption *TiKVEncryption `json:"encryption,omitempty"`
+}
+
+// KMSVendor is the vendor of the KMS which supplies the master key of the TiKV encryption
+type KMSVendor string
+
+const (
+	// KMSVendorAWS is the AWS KMS
+	KMSVendorAWS KMSVendor = "aws"
+	// KMSVendorGCP is the GCP Cloud KMS
+	KMSVendorGCP KMSVendor = "gcp"
+)
+
+// TiKVEncryption is the encryption at rest of TiKV
+// +k8s:openapi-gen=true
+type TiKVEncryption struct {
+	// Method is the encryption method of the data files, one of aes128-ctr, aes192-ctr and aes256-ctr
+	// Optional: Defaults to aes256-ctr
+	// +kubebuilder:validation:Enum=aes128-ctr;aes192-ctr;aes256-ctr
+	// +optional
+	Method string `json:"method,omitempty"`
+
+	// DataKeyRotationPeriod is how often TiKV rotates the data keys
+	// Optional: Defaults to 168h
+	// +optional
+	DataKeyRotationPeriod *metav1.Duration `json:"dataKeyRotationPeriod,omitempty"`
+
+	// MasterKey is the KMS key to encrypt the data keys. Once it is changed, the TiKV Pods are
+	// restarted one by one with the previous master key to re-encrypt the data keys, and the
+	// previous master key is recorded in the status.
+	MasterKey TiKVKMSMasterKey `json:"masterKey"`
+}
+
+// TiKVKMSMasterKey is the master key of the TiKV encryption in a KMS
+// +k8s:openapi-gen=true
+type TiKVKMSMasterKey struct {
+	// Vendor is the vendor of the KMS, aws or gcp
+	// Optional: Defaults to aws
+	// +kubebuilder:validation:Enum=aws;gcp
+	// +optional
+	Vendor KMSVendor `json:"vendor,omitempty"`
+
+	// KeyID is the ID or ARN of the AWS KMS key, or the resource name of the GCP Cloud KMS key
+	// in the format of projects/<project>/locations/<location>/keyRings/<ring>/cryptoKeys/<key>
+	KeyID string `json:"keyID"`
+
+	// Region is the region of the key
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// Endpoint is the endpoint of the KMS, leave it empty to use the default endpoint of the vendor
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// SecretName is the name of the secret with the credentials to access the KMS, the keys
+	// `access_key` and `secret_key` for AWS, or the key `credentials` with the service account
+	// key for GCP. The IAM role or the workload identity of the Pod is used if it is not set.
+	// The AWS credentials of the current master key are also used to access the previous one.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
 }
 
 // TiFlashSpec contains details of TiFlash members
@@ -544,6 +1302,12 @@ type TiFlashSpec struct {
 	// RecoverFailover indicates that Operator can recover the failover Pods
 	// +optional
 	RecoverFailover bool `json:"recoverFailover,omitempty"`
+
+	// DeleteSlots are the ordinals of the TiFlash pods to delete when scaling in, or to skip when scaling out.
+	// It takes effect only if the AdvancedStatefulSet feature is enabled.
+	// The legacy annotation `tiflash.tidb.pingcap.com/delete-slots` is still respected and merged with it.
+	// +optional
+	DeleteSlots []int32 `json:"deleteSlots,omitempty"`
 }
 
 // TiCDCSpec contains details of TiCDC members
@@ -661,6 +1425,12 @@ type TiDBSpec struct {
 	// +optional
 	MaxFailoverCount *int32 `json:"maxFailoverCount,omitempty"`
 
+	// FailoverProbe is the SQL-level probe executed by the controller on each member, the members which are
+	// Ready but keep failing the probe for the failure period are counted as failed and replaced.
+	// Optional: the members are counted as failed only if they are not Ready if it is not set
+	// +optional
+	FailoverProbe *TiDBFailoverProbe `json:"failoverProbe,omitempty"`
+
 	// Whether output the slow log in an separate sidecar container
 	// Optional: Defaults to true
 	// +optional
@@ -707,6 +1477,12 @@ type TiDBSpec struct {
 	// the default behavior is like setting type as "tcp"
 	// +optional
 	ReadinessProbe *TiDBProbe `json:"readinessProbe,omitempty"`
+
+	// DeleteSlots are the ordinals of the TiDB pods to delete when scaling in, or to skip when scaling out.
+	// It takes effect only if the AdvancedStatefulSet feature is enabled.
+	// The legacy annotation `tidb.tidb.pingcap.com/delete-slots` is still respected and merged with it.
+	// +optional
+	DeleteSlots []int32 `json:"deleteSlots,omitempty"`
 }
 
 const (
@@ -730,6 +1506,38 @@ type TiDBProbe struct {
 	Type *string `json:"type,omitempty"` // tcp or command
 }
 
+// TiDBFailoverProbe is the SQL-level probe executed by the controller through the MySQL protocol to detect
+// the TiDB members which are Ready but fail to serve queries
+// +k8s:openapi-gen=true
+type TiDBFailoverProbe struct {
+	// Query is the SQL statement executed on each member, it should read data from TiKV so that the members
+	// which fail to access the storage are detected
+	// Optional: Defaults to "SELECT COUNT(*) FROM mysql.tidb"
+	// +optional
+	Query string `json:"query,omitempty"`
+
+	// User is the user to execute the query
+	// Optional: Defaults to root
+	// +optional
+	User string `json:"user,omitempty"`
+
+	// PasswordSecret is the name of the secret which contains the password of the user keyed by the user name,
+	// the same as the passwordSecret of TidbInitializer. The password is empty if it is not set
+	// +optional
+	PasswordSecret *string `json:"passwordSecret,omitempty"`
+
+	// TimeoutSeconds is the timeout of the query
+	// Optional: Defaults to 5
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// FailurePeriod is how long the probe must keep failing before the member is counted as failed
+	// Optional: Defaults to the TiDB failover period of the controller manager
+	// +optional
+	FailurePeriod *metav1.Duration `json:"failurePeriod,omitempty"`
+}
+
 // PumpSpec contains details of Pump members
 // +k8s:openapi-gen=true
 type PumpSpec struct {
@@ -918,6 +1726,27 @@ type ComponentSpec struct {
 	// +listType=map
 	// +listMapKey=topologyKey
 	TopologySpreadConstraints []TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// PVCDeletePolicy determines whether the orphan PVCs left by the scale-in of the component are
+	// deleted. Delete is the same as setting the cluster-level enablePVReclaim to true for the
+	// component, and Retain is the same as setting it to false.
+	// Optional: Defaults to cluster-level setting
+	// +kubebuilder:validation:Enum=Delete;Retain
+	// +optional
+	PVCDeletePolicy *PVCDeletePolicy `json:"pvcDeletePolicy,omitempty"`
+
+	// SecurityProfile of the component. Override the cluster-level securityProfile if present
+	// Optional: Defaults to cluster-level setting
+	// +kubebuilder:validation:Enum=Restricted;Unrestricted
+	// +optional
+	SecurityProfile SecurityProfile `json:"securityProfile,omitempty"`
+
+	// PVCAnnotations are added to the PVCs of the component, e.g. `ebs.csi.aws.com/iops` and
+	// `ebs.csi.aws.com/throughput` of EBS gp3 volumes for the provisioners reading volume parameters
+	// from the PVC annotations. They are set on the volumeClaimTemplates and updated to the existing PVCs.
+	// Optional: Defaults to nil
+	// +optional
+	PVCAnnotations map[string]string `json:"pvcAnnotations,omitempty"`
 }
 
 // ServiceSpec specifies the service object in k8s
@@ -1009,6 +1838,64 @@ type PDStatus struct {
 	FailureMembers  map[string]PDFailureMember `json:"failureMembers,omitempty"`
 	UnjoinedMembers map[string]UnjoinedMember  `json:"unjoinedMembers,omitempty"`
 	Image           string                     `json:"image,omitempty"`
+	// Volumes contains the status of the PVCs of the component, keyed by the PVC name
+	Volumes map[string]StorageVolumeStatus `json:"volumes,omitempty"`
+	// StorageUsage is the aggregate disk usage of the component
+	// +optional
+	StorageUsage *StorageUsage `json:"storageUsage,omitempty"`
+	// APIVersion is the newest API version supported by both PD and the operator, e.g. v1 and v2
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+	// MicroServices contains the addresses of the members of the PD microservices discovered
+	// by the PD API v2, keyed by the service name, e.g. tso and scheduling
+	// +optional
+	MicroServices map[string][]string `json:"microServices,omitempty"`
+	// Conditions contains the latest observed conditions of the component
+	// +optional
+	Conditions []ComponentCondition `json:"conditions,omitempty"`
+	// UpgradeProgress is the progress of the rolling upgrade, it is set only if the phase is Upgrade
+	// +optional
+	UpgradeProgress *UpgradeProgress `json:"upgradeProgress,omitempty"`
+	// ClusterDomainMigration is the progress of migrating the members to `spec.clusterDomain`,
+	// it is set only if the members advertise the URLs in another cluster domain
+	// +optional
+	ClusterDomainMigration *ClusterDomainMigrationStatus `json:"clusterDomainMigration,omitempty"`
+}
+
+// ClusterDomainMigrationPhase is the step of the cluster domain migration
+type ClusterDomainMigrationPhase string
+
+const (
+	// ClusterDomainMigrationWaitingForCerts means the certificates of the cluster are being re-issued
+	// with the SANs in the new cluster domain
+	ClusterDomainMigrationWaitingForCerts ClusterDomainMigrationPhase = "WaitingForCerts"
+	// ClusterDomainMigrationUpdatingPeerURLs means the peer URLs of the members are being updated
+	// to the new cluster domain one by one
+	ClusterDomainMigrationUpdatingPeerURLs ClusterDomainMigrationPhase = "UpdatingPeerURLs"
+	// ClusterDomainMigrationRollingPods means the pods are being rolled to advertise the URLs in
+	// the new cluster domain
+	ClusterDomainMigrationRollingPods ClusterDomainMigrationPhase = "RollingPods"
+)
+
+// ClusterDomainMigrationStatus is the progress of migrating the PD members to a new cluster domain
+type ClusterDomainMigrationStatus struct {
+	// From is the cluster domain the members are migrated from, empty means no cluster domain
+	// +optional
+	From string `json:"from,omitempty"`
+	// To is the cluster domain the members are migrated to, empty means no cluster domain
+	// +optional
+	To string `json:"to,omitempty"`
+	// Phase is the current step of the migration
+	Phase ClusterDomainMigrationPhase `json:"phase"`
+	// MigratedMembers are the names of the members whose peer URLs are updated to the new cluster domain
+	// +optional
+	MigratedMembers []string `json:"migratedMembers,omitempty"`
+	// Message is a human readable message indicating why the migration is blocked
+	// +optional
+	Message string `json:"message,omitempty"`
+	// StartTime is the time the migration starts
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
 }
 
 // PDMember is PD member
@@ -1021,6 +1908,23 @@ type PDMember struct {
 	Health    bool   `json:"health"`
 	// Last time the health transitioned from one to another.
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Etcd is the health of the embedded etcd of the member
+	// +optional
+	Etcd *PDEtcdMemberStatus `json:"etcd,omitempty"`
+}
+
+// PDEtcdMemberStatus is the health of the embedded etcd of a PD member
+type PDEtcdMemberStatus struct {
+	// Alarms are the alarms raised on the member, e.g. NOSPACE and CORRUPT
+	// +optional
+	Alarms []string `json:"alarms,omitempty"`
+	// DBSize is the size of the backend database of the member
+	// +optional
+	DBSize *resource.Quantity `json:"dbSize,omitempty"`
+	// Lagging is true if the raft index of the member falls behind the leader
+	// by more than 1000 entries
+	// +optional
+	Lagging bool `json:"lagging,omitempty"`
 }
 
 // PDFailureMember is the pd failure member information
@@ -1049,6 +1953,14 @@ type TiDBStatus struct {
 	FailureMembers           map[string]TiDBFailureMember `json:"failureMembers,omitempty"`
 	ResignDDLOwnerRetryCount int32                        `json:"resignDDLOwnerRetryCount,omitempty"`
 	Image                    string                       `json:"image,omitempty"`
+	// Volumes contains the status of the PVCs of the component, keyed by the PVC name
+	Volumes map[string]StorageVolumeStatus `json:"volumes,omitempty"`
+	// Conditions contains the latest observed conditions of the component
+	// +optional
+	Conditions []ComponentCondition `json:"conditions,omitempty"`
+	// UpgradeProgress is the progress of the rolling upgrade, it is set only if the phase is Upgrade
+	// +optional
+	UpgradeProgress *UpgradeProgress `json:"upgradeProgress,omitempty"`
 }
 
 // TiDBMember is TiDB member
@@ -1059,6 +1971,31 @@ type TiDBMember struct {
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
 	// Node hosting pod of this TiDB member.
 	NodeName string `json:"node,omitempty"`
+	// Info is reported by the status API of the member, it is nil if the member is not healthy
+	// +optional
+	Info *TiDBMemberInfo `json:"info,omitempty"`
+	// SQLProbeFailingSince is the time since which the failover probe of the member keeps failing,
+	// it is nil if the probe succeeds or is not configured
+	// +optional
+	SQLProbeFailingSince *metav1.Time `json:"sqlProbeFailingSince,omitempty"`
+	// SQLProbeMessage is the error of the last failed failover probe of the member
+	// +optional
+	SQLProbeMessage string `json:"sqlProbeMessage,omitempty"`
+}
+
+// TiDBMemberInfo is the information of a TiDB member reported by its status API
+type TiDBMemberInfo struct {
+	// Version is the version of the member, e.g. 5.7.25-TiDB-v4.0.0
+	// +optional
+	Version string `json:"version,omitempty"`
+	// DDLOwner is true if the member is the DDL owner of the cluster
+	// +optional
+	DDLOwner bool `json:"ddlOwner,omitempty"`
+	// Connections is the number of the client connections of the member
+	Connections int32 `json:"connections"`
+	// StartTime is the time when the member started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
 }
 
 // TiDBFailureMember is the tidb failure member information
@@ -1078,6 +2015,34 @@ type TiKVStatus struct {
 	TombstoneStores map[string]TiKVStore        `json:"tombstoneStores,omitempty"`
 	FailureStores   map[string]TiKVFailureStore `json:"failureStores,omitempty"`
 	Image           string                      `json:"image,omitempty"`
+	// Volumes contains the status of the PVCs of the component, keyed by the PVC name
+	Volumes map[string]StorageVolumeStatus `json:"volumes,omitempty"`
+	// StorageUsage is the aggregate disk usage of the component
+	// +optional
+	StorageUsage *StorageUsage `json:"storageUsage,omitempty"`
+	// Encryption is the status of the encryption at rest
+	// +optional
+	Encryption *TiKVEncryptionStatus `json:"encryption,omitempty"`
+	// Conditions contains the latest observed conditions of the component
+	// +optional
+	Conditions []ComponentCondition `json:"conditions,omitempty"`
+	// UpgradeProgress is the progress of the rolling upgrade, it is set only if the phase is Upgrade
+	// +optional
+	UpgradeProgress *UpgradeProgress `json:"upgradeProgress,omitempty"`
+}
+
+// TiKVEncryptionStatus is the status of the encryption at rest of TiKV
+type TiKVEncryptionStatus struct {
+	// MasterKey is the master key rendered in the config of TiKV
+	// +optional
+	MasterKey *TiKVKMSMasterKey `json:"masterKey,omitempty"`
+	// PreviousMasterKey is the master key before the last rotation, TiKV re-encrypts the
+	// data keys with the current master key on startup if it is set
+	// +optional
+	PreviousMasterKey *TiKVKMSMasterKey `json:"previousMasterKey,omitempty"`
+	// LastRotationTime is the time when the master key is rotated last time
+	// +optional
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
 }
 
 // TiFlashStatus is TiFlash status
@@ -1090,6 +2055,17 @@ type TiFlashStatus struct {
 	TombstoneStores map[string]TiKVStore        `json:"tombstoneStores,omitempty"`
 	FailureStores   map[string]TiKVFailureStore `json:"failureStores,omitempty"`
 	Image           string                      `json:"image,omitempty"`
+	// Volumes contains the status of the PVCs of the component, keyed by the PVC name
+	Volumes map[string]StorageVolumeStatus `json:"volumes,omitempty"`
+	// StorageUsage is the aggregate disk usage of the component
+	// +optional
+	StorageUsage *StorageUsage `json:"storageUsage,omitempty"`
+	// Conditions contains the latest observed conditions of the component
+	// +optional
+	Conditions []ComponentCondition `json:"conditions,omitempty"`
+	// UpgradeProgress is the progress of the rolling upgrade, it is set only if the phase is Upgrade
+	// +optional
+	UpgradeProgress *UpgradeProgress `json:"upgradeProgress,omitempty"`
 }
 
 // TiCDCStatus is TiCDC status
@@ -1098,6 +2074,14 @@ type TiCDCStatus struct {
 	Phase       MemberPhase             `json:"phase,omitempty"`
 	StatefulSet *apps.StatefulSetStatus `json:"statefulSet,omitempty"`
 	Captures    map[string]TiCDCCapture `json:"captures,omitempty"`
+	// Volumes contains the status of the PVCs of the component, keyed by the PVC name
+	Volumes map[string]StorageVolumeStatus `json:"volumes,omitempty"`
+	// Conditions contains the latest observed conditions of the component
+	// +optional
+	Conditions []ComponentCondition `json:"conditions,omitempty"`
+	// UpgradeProgress is the progress of the rolling upgrade, it is set only if the phase is Upgrade
+	// +optional
+	UpgradeProgress *UpgradeProgress `json:"upgradeProgress,omitempty"`
 }
 
 // TiCDCCapture is TiCDC Capture status
@@ -1115,9 +2099,18 @@ type TiKVStore struct {
 	PodName     string `json:"podName"`
 	IP          string `json:"ip"`
 	LeaderCount int32  `json:"leaderCount"`
+	// RegionCount is the number of the region peers on the store reported by PD
+	// +optional
+	RegionCount int32  `json:"regionCount,omitempty"`
 	State       string `json:"state"`
 	// Last time the health transitioned from one to another.
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Capacity is the capacity of the store reported by PD
+	// +optional
+	Capacity resource.Quantity `json:"capacity,omitempty"`
+	// Available is the available size of the store reported by PD
+	// +optional
+	Available resource.Quantity `json:"available,omitempty"`
 }
 
 // TiKVFailureStore is the tikv failure store information
@@ -1125,6 +2118,60 @@ type TiKVFailureStore struct {
 	PodName   string      `json:"podName,omitempty"`
 	StoreID   string      `json:"storeID,omitempty"`
 	CreatedAt metav1.Time `json:"createdAt,omitempty"`
+	// StorageClassMigration is true if the failure store is recorded by the storage class migration
+	// to provision an extra store on the new storage class, instead of by the failover
+	StorageClassMigration bool `json:"storageClassMigration,omitempty"`
+}
+
+// VolumeResizeState is the state of resizing a PVC
+type VolumeResizeState string
+
+const (
+	// VolumeResizing means the storage request of the PVC is increased and the volume is being expanded
+	VolumeResizing VolumeResizeState = "Resizing"
+	// VolumeFileSystemResizePending means the volume is expanded and the file system is waiting to be
+	// resized by kubelet, the pod may need to be restarted if online expansion is not supported
+	VolumeFileSystemResizePending VolumeResizeState = "FileSystemResizePending"
+	// VolumeResized means the capacity of the volume is not less than the desired capacity
+	VolumeResized VolumeResizeState = "Resized"
+	// VolumeResizeUnsupported means the storage class of the PVC does not allow volume expansion
+	VolumeResizeUnsupported VolumeResizeState = "Unsupported"
+)
+
+// StorageVolumeStatus is the status of a PVC of a component
+type StorageVolumeStatus struct {
+	// PodName is the name of the pod which the PVC belongs to
+	PodName string `json:"podName"`
+	// CurrentCapacity is the capacity of the volume in the status of the PVC
+	// +optional
+	CurrentCapacity resource.Quantity `json:"currentCapacity,omitempty"`
+	// DesiredCapacity is the storage request in the spec of the component
+	DesiredCapacity resource.Quantity `json:"desiredCapacity"`
+	// ResizeState is the state of resizing the PVC to the desired capacity
+	// +optional
+	ResizeState VolumeResizeState `json:"resizeState,omitempty"`
+	// UsedCapacity is the used size of the volume reported by the kubelet
+	// +optional
+	UsedCapacity *resource.Quantity `json:"usedCapacity,omitempty"`
+	// AvailableCapacity is the available size of the volume reported by the kubelet
+	// +optional
+	AvailableCapacity *resource.Quantity `json:"availableCapacity,omitempty"`
+}
+
+// StorageUsage is the aggregate disk usage of the stores or volumes of a component
+type StorageUsage struct {
+	// Capacity is the total capacity of the stores or volumes
+	Capacity resource.Quantity `json:"capacity"`
+	// Used is the total used size of the stores or volumes
+	Used resource.Quantity `json:"used"`
+	// UsedPercent is the percentage of Used in Capacity, e.g. "42%"
+	UsedPercent string `json:"usedPercent"`
+	// WorstPodName is the name of the pod with the highest used percentage
+	// +optional
+	WorstPodName string `json:"worstPodName,omitempty"`
+	// WorstPodUsedPercent is the used percentage of the pod WorstPodName
+	// +optional
+	WorstPodUsedPercent string `json:"worstPodUsedPercent,omitempty"`
 }
 
 // PumpNodeStatus represents the status saved in etcd.
@@ -1140,11 +2187,41 @@ type PumpNodeStatus struct {
 	// UpdateTS    int64  `json:"updateTS"`
 }
 
+// DrainerNodeStatus represents the status of a drainer saved in etcd.
+type DrainerNodeStatus struct {
+	NodeID string `json:"nodeId"`
+	Host   string `json:"host"`
+	State  string `json:"state"`
+	// CheckpointTS is the commit TS of the checkpoint of the drainer,
+	// the binlogs before which are replicated to the downstream
+	// +optional
+	CheckpointTS int64 `json:"checkpointTS,omitempty"`
+	// CheckpointTime is the physical time of CheckpointTS
+	// +optional
+	CheckpointTime *metav1.Time `json:"checkpointTime,omitempty"`
+	// Lag is the duration from CheckpointTime to the time the status is synced, e.g. 3s
+	// +optional
+	Lag string `json:"lag,omitempty"`
+}
+
+// DrainerStatus is Drainer status
+type DrainerStatus struct {
+	Members []*DrainerNodeStatus `json:"members,omitempty"`
+}
+
 // PumpStatus is Pump status
 type PumpStatus struct {
 	Phase       MemberPhase             `json:"phase,omitempty"`
 	StatefulSet *apps.StatefulSetStatus `json:"statefulSet,omitempty"`
 	Members     []*PumpNodeStatus       `json:"members,omitempty"`
+	// Volumes contains the status of the PVCs of the component, keyed by the PVC name
+	Volumes map[string]StorageVolumeStatus `json:"volumes,omitempty"`
+	// StorageUsage is the aggregate disk usage of the component
+	// +optional
+	StorageUsage *StorageUsage `json:"storageUsage,omitempty"`
+	// Conditions contains the latest observed conditions of the component
+	// +optional
+	Conditions []ComponentCondition `json:"conditions,omitempty"`
 }
 
 // TiDBTLSClient can enable TLS connection between TiDB server and MySQL client
@@ -1165,6 +2242,26 @@ type TiDBTLSClient struct {
 	//   4. Set Enabled to `true`.
 	// +optional
 	Enabled bool `json:"enabled,omitempty"`
+
+	// Policy restricts the TLS versions and the cipher suites accepted on the MySQL port
+	// Optional: Defaults to nil, which means the defaults of TiDB
+	// +optional
+	Policy *TLSPolicy `json:"policy,omitempty"`
+}
+
+// TLSPolicy restricts the TLS versions and the cipher suites of the TLS connections
+type TLSPolicy struct {
+	// MinVersion is the minimum TLS version, TLSv1.0, TLSv1.1, TLSv1.2 or TLSv1.3
+	// Optional: Defaults to the default of the component
+	// +kubebuilder:validation:Enum=TLSv1.0;TLSv1.1;TLSv1.2;TLSv1.3
+	// +optional
+	MinVersion string `json:"minVersion,omitempty"`
+
+	// CipherSuites are the allowed cipher suites of TLSv1.2 and below in the IANA names,
+	// e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, the cipher suites of TLSv1.3 are not configurable
+	// Optional: Defaults to the default of the component
+	// +optional
+	CipherSuites []string `json:"cipherSuites,omitempty"`
 }
 
 // TLSCluster can enable mutual TLS connection between TiDB cluster components
@@ -1188,6 +2285,166 @@ type TLSCluster struct {
 	//        Same for other components.
 	// +optional
 	Enabled bool `json:"enabled,omitempty"`
+
+	// CertRotation configures the automated rotation of the certificates in the secrets above,
+	// the certificates are re-issued before they expire and the pods of the components are
+	// rolling restarted one component at a time to reload them.
+	// Optional: Defaults to nil, which means the certificates are not rotated by the operator
+	// +optional
+	CertRotation *TLSCertRotation `json:"certRotation,omitempty"`
+
+	// CertManager makes the operator generate the cert-manager Certificates of the secrets above,
+	// so that they are not required to be created by the user.
+	// Optional: Defaults to nil, which means the secrets are provided by the user
+	// +optional
+	CertManager *TLSCertManager `json:"certManager,omitempty"`
+
+	// Vault makes the operator issue the certificates of the secrets above from a Vault PKI role,
+	// and re-issue them before they expire, so that no long-lived CA is kept in the cluster.
+	// Optional: Defaults to nil, which means the secrets are provided by the user
+	// +optional
+	Vault *TLSVault `json:"vault,omitempty"`
+
+	// Policy restricts the TLS versions and the cipher suites of the connections between the components,
+	// it applies to PD, TiKV, the status port of TiDB and the clients of the operator
+	// Optional: Defaults to nil, which means the defaults of the components
+	// +optional
+	Policy *TLSPolicy `json:"policy,omitempty"`
+
+	// CABundle makes the operator propagate the CA bundle in the secret into the ca.crt of the secrets above,
+	// so that a new CA is trusted by all the members without a rolling restart of PD, TiKV and TiDB, and a
+	// CA removed from the bundle is kept until all the members trust the new bundle.
+	// It can not be set with certManager or vault, which manage the ca.crt of the secrets.
+	// Optional: Defaults to nil, which means the ca.crt of the secrets are managed by the user
+	// +optional
+	CABundle *TLSCABundle `json:"caBundle,omitempty"`
+}
+
+// TLSCABundle configures the CA bundle trusted by the components of the cluster
+type TLSCABundle struct {
+	// SecretName is the name of the secret with the key ca.crt of the PEM encoded CAs trusted by the cluster.
+	// To rotate the CA, append the new CA, re-issue the certificates by the new CA once `status.tlsCABundle.phase`
+	// is Trusted, and then remove the old CA.
+	SecretName string `json:"secretName"`
+
+	// ReloadDelay is how long PD, TiKV and TiDB are considered to have reloaded the CA bundle online after it is
+	// propagated, which covers the sync period of the kubelet to update the mounted secrets. The other
+	// components are rolling restarted to reload it.
+	// Optional: Defaults to 3m
+	// +optional
+	ReloadDelay *metav1.Duration `json:"reloadDelay,omitempty"`
+}
+
+// TLSVault configures the Vault PKI role which issues the certificates of the cluster
+type TLSVault struct {
+	// Address of Vault, e.g. https://vault.vault.svc:8200
+	Address string `json:"address"`
+
+	// CASecretName is the name of the secret with the key ca.crt to verify the certificate of Vault
+	// Optional: Defaults to the system CAs
+	// +optional
+	CASecretName string `json:"caSecretName,omitempty"`
+
+	// PKIPath is the mount path of the PKI secrets engine
+	// Optional: Defaults to pki
+	// +optional
+	PKIPath string `json:"pkiPath,omitempty"`
+
+	// Role is the PKI role which issues the certificates
+	Role string `json:"role"`
+
+	// TTL is the requested TTL of the certificates
+	// Optional: Defaults to the TTL of the role
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+
+	// RenewBefore is how long before the expiration the certificates are re-issued
+	// Optional: Defaults to 720h
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+
+	// TokenSecretName is the name of the secret with the key token to authenticate to Vault,
+	// one of tokenSecretName and kubernetesAuth must be set
+	// +optional
+	TokenSecretName string `json:"tokenSecretName,omitempty"`
+
+	// KubernetesAuth authenticates to Vault with the service account JWT of the operator
+	// +optional
+	KubernetesAuth *VaultKubernetesAuth `json:"kubernetesAuth,omitempty"`
+}
+
+// VaultKubernetesAuth configures the Kubernetes auth method of Vault
+type VaultKubernetesAuth struct {
+	// MountPath is the mount path of the Kubernetes auth method
+	// Optional: Defaults to kubernetes
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
+
+	// Role is the role of the Kubernetes auth method bound to the service account of the operator
+	Role string `json:"role"`
+}
+
+// TLSCertManager configures the cert-manager Certificates generated by the operator for the
+// cluster certificates, one for each component and one for the client
+type TLSCertManager struct {
+	// IssuerRef is the cert-manager Issuer or ClusterIssuer which issues the certificates
+	IssuerRef CertManagerIssuerRef `json:"issuerRef"`
+
+	// Duration is the requested duration of the certificates
+	// Optional: Defaults to the default of cert-manager
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// RenewBefore is how long before the expiration the certificates are renewed by cert-manager
+	// Optional: Defaults to the default of cert-manager
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+}
+
+// CertManagerIssuerRef refers to a cert-manager Issuer or ClusterIssuer
+type CertManagerIssuerRef struct {
+	// Name of the issuer
+	Name string `json:"name"`
+
+	// Kind of the issuer, Issuer or ClusterIssuer
+	// Optional: Defaults to Issuer
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Group of the issuer
+	// Optional: Defaults to cert-manager.io
+	// +optional
+	Group string `json:"group,omitempty"`
+}
+
+// TLSCertIssuer is the issuer which re-issues the certificates before they expire
+type TLSCertIssuer string
+
+const (
+	// TLSCertIssuerCSR means the certificates are re-issued by the operator via the Kubernetes
+	// CertificateSigningRequest API, it requires the certificates to be signed by the Kubernetes CA
+	TLSCertIssuerCSR TLSCertIssuer = "CSR"
+	// TLSCertIssuerCertManager means the certificates are re-issued by cert-manager,
+	// the operator only reloads them
+	TLSCertIssuerCertManager TLSCertIssuer = "CertManager"
+	// TLSCertIssuerVault means the certificates are re-issued from the Vault PKI role in
+	// `spec.tlsCluster.vault`, the operator only reloads them here
+	TLSCertIssuerVault TLSCertIssuer = "Vault"
+)
+
+// TLSCertRotation configures the automated rotation of the TLS certificates of the cluster
+type TLSCertRotation struct {
+	// Issuer is the issuer which re-issues the certificates
+	// Optional: Defaults to CSR
+	// +kubebuilder:validation:Enum=CSR;CertManager;Vault
+	// +optional
+	Issuer TLSCertIssuer `json:"issuer,omitempty"`
+
+	// RenewBefore is how long before the expiration the certificates are re-issued by the operator
+	// if the issuer is CSR
+	// Optional: Defaults to 720h
+	// +optional
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
 }
 
 // +genclient
@@ -1279,6 +2536,10 @@ type S3StorageProvider struct {
 	// SecretName is the name of secret which stores
 	// S3 compliant storage access key and secret key.
 	SecretName string `json:"secretName,omitempty"`
+	// ExternalSecret references the access key and secret key with the keys access_key and secret_key
+	// in an external secret manager, it can be set instead of secretName.
+	// +optional
+	ExternalSecret *ExternalSecretRef `json:"externalSecret,omitempty"`
 	// Prefix of the data path.
 	Prefix string `json:"prefix,omitempty"`
 	// SSE Sever-Side Encryption.
@@ -1308,6 +2569,10 @@ type GcsStorageProvider struct {
 	// SecretName is the name of secret which stores the
 	// gcs service account credentials JSON.
 	SecretName string `json:"secretName,omitempty"`
+	// ExternalSecret references the service account credentials JSON with the key credentials
+	// in an external secret manager, it can be set instead of secretName.
+	// +optional
+	ExternalSecret *ExternalSecretRef `json:"externalSecret,omitempty"`
 	// Prefix of the data path.
 	Prefix string `json:"prefix,omitempty"`
 }
@@ -1339,13 +2604,68 @@ type TiDBAccessConfig struct {
 	// User is the user for login tidb cluster
 	User string `json:"user,omitempty"`
 	// SecretName is the name of secret which stores tidb cluster's password.
-	SecretName string `json:"secretName"`
+	// One of secretName and externalSecret must be set.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+	// ExternalSecret references the password with the key password in an external secret manager,
+	// it can be set instead of secretName.
+	// +optional
+	ExternalSecret *ExternalSecretRef `json:"externalSecret,omitempty"`
 	// TLSClientSecretName is the name of secret which stores tidb server client certificate
 	// Optional: Defaults to nil
 	// +optional
 	TLSClientSecretName *string `json:"tlsClientSecretName,omitempty"`
 }
 
+// ExternalSecretProvider is the external secret manager which stores the credentials
+type ExternalSecretProvider string
+
+const (
+	// ExternalSecretProviderAWS reads the credentials from AWS Secrets Manager
+	ExternalSecretProviderAWS ExternalSecretProvider = "aws-secrets-manager"
+	// ExternalSecretProviderGCP reads the credentials from GCP Secret Manager
+	ExternalSecretProviderGCP ExternalSecretProvider = "gcp-secret-manager"
+	// ExternalSecretProviderVault reads the credentials from a KV secrets engine of Vault
+	ExternalSecretProviderVault ExternalSecretProvider = "vault"
+)
+
+// +k8s:openapi-gen=true
+// ExternalSecretRef references the credentials stored in an external secret manager, which are fetched
+// by backup-manager when the job starts instead of being read from a Kubernetes secret. The secret is
+// a JSON object of the same keys as the Kubernetes secret it replaces, e.g. {"password": "..."}.
+// The job authenticates to the secret manager with its service account, e.g. by IAM roles for service
+// accounts on EKS, workload identity on GKE or the Kubernetes auth method of Vault.
+type ExternalSecretRef struct {
+	// Provider is the secret manager, one of aws-secrets-manager, gcp-secret-manager and vault
+	Provider ExternalSecretProvider `json:"provider"`
+	// Path of the secret, the secret ID or ARN of AWS Secrets Manager, the resource name
+	// projects/<project>/secrets/<secret>[/versions/<version>] of GCP Secret Manager, or the
+	// path of the secret in Vault, e.g. secret/data/backup for the KV version 2 engine
+	Path string `json:"path"`
+	// Region of AWS Secrets Manager
+	// Optional: Defaults to the region of the job, e.g. the env AWS_REGION
+	// +optional
+	Region string `json:"region,omitempty"`
+	// Vault configures how to access Vault, it is required by the vault provider
+	// +optional
+	Vault *ExternalSecretVault `json:"vault,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// ExternalSecretVault configures how the job accesses Vault
+type ExternalSecretVault struct {
+	// Address of Vault, e.g. https://vault.vault.svc:8200
+	Address string `json:"address"`
+	// Role is the role of the Kubernetes auth method bound to the service account of the job.
+	// Optional: Defaults to empty, which means the token in the env VAULT_TOKEN is used
+	// +optional
+	Role string `json:"role,omitempty"`
+	// MountPath is the mount path of the Kubernetes auth method
+	// Optional: Defaults to kubernetes
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
+}
+
 // +k8s:openapi-gen=true
 // CleanPolicyType represents the clean policy of backup data in remote storage
 type CleanPolicyType string
@@ -1400,6 +2720,10 @@ type BackupSpec struct {
 	StorageSize string `json:"storageSize,omitempty"`
 	// BRConfig is the configs for BR
 	BR *BRConfig `json:"br,omitempty"`
+	// Federation backs up the TidbClusters in multiple Kubernetes clusters at the same TS.
+	// It requires BR, and the members share the PD cluster of `br.cluster`.
+	// +optional
+	Federation *BackupFederationSpec `json:"federation,omitempty"`
 	// DumplingConfig is the configs for dumpling
 	Dumpling *DumplingConfig `json:"dumpling,omitempty"`
 	// Base tolerations of backup Pods, components may add more tolerations upon this respectively
@@ -1463,6 +2787,9 @@ type BRConfig struct {
 	RateLimit *uint `json:"rateLimit,omitempty"`
 	// TimeAgo is the history version of the backup task, e.g. 1m, 1h
 	TimeAgo string `json:"timeAgo,omitempty"`
+	// BackupTS is the TSO the snapshot is backed up at, it can not be set with timeAgo
+	// +optional
+	BackupTS string `json:"backupTS,omitempty"`
 	// Checksum specifies whether to run checksum after backup
 	Checksum *bool `json:"checksum,omitempty"`
 	// SendCredToTikv specifies whether to send credentials to TiKV
@@ -1473,6 +2800,16 @@ type BRConfig struct {
 	Options []string `json:"options,omitempty"`
 }
 
+// +k8s:openapi-gen=true
+// BackupFederationSpec describes the members of a federated backup
+type BackupFederationSpec struct {
+	// Members are the TidbClusters to back up. A Backup is created for each member in its
+	// namespace with the same spec at the TS of the federated backup, and the data is saved
+	// under the member name in the storage, so the secrets referred by the spec must exist in
+	// the namespaces of all the members.
+	Members []TidbClusterFederationMember `json:"members"`
+}
+
 // BackupConditionType represents a valid condition of a Backup.
 type BackupConditionType string
 
@@ -1507,12 +2844,18 @@ type BackupCondition struct {
 
 // BackupStatus represents the current status of a backup.
 type BackupStatus struct {
+	// ObservedGeneration is the most recent generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// BackupPath is the location of the backup.
 	BackupPath string `json:"backupPath"`
 	// TimeStarted is the time at which the backup was started.
 	TimeStarted metav1.Time `json:"timeStarted"`
 	// TimeCompleted is the time at which the backup was completed.
 	TimeCompleted metav1.Time `json:"timeCompleted"`
+	// TimeTaken is the time taken by the backup, in a human readable format.
+	// +optional
+	TimeTaken string `json:"timeTaken,omitempty"`
 	// BackupSizeReadable is the data size of the backup.
 	// the difference with BackupSize is that its format is human readable
 	BackupSizeReadable string `json:"backupSizeReadable"`
@@ -1523,6 +2866,37 @@ type BackupStatus struct {
 	// Phase is a user readable state inferred from the underlying Backup conditions
 	Phase      BackupConditionType `json:"phase"`
 	Conditions []BackupCondition   `json:"conditions"`
+	// Federation is the progress of the member backups of a federated backup
+	// +optional
+	Federation *BackupFederationStatus `json:"federation,omitempty"`
+}
+
+// BackupFederationStatus is the progress of a federated backup
+type BackupFederationStatus struct {
+	// BackupTS is the TS all the members are backed up at
+	BackupTS string `json:"backupTS"`
+	// Members are the states of the member backups
+	// +optional
+	Members []BackupFederationMemberStatus `json:"members,omitempty"`
+}
+
+// BackupFederationMemberStatus is the state of the backup of a member
+type BackupFederationMemberStatus struct {
+	// Name is the name of the TidbCluster
+	Name string `json:"name"`
+	// Namespace is the namespace of the TidbCluster and the Backup
+	Namespace string `json:"namespace"`
+	// BackupName is the name of the Backup of the member
+	BackupName string `json:"backupName"`
+	// Phase is the phase of the Backup of the member
+	// +optional
+	Phase BackupConditionType `json:"phase,omitempty"`
+	// BackupPath is the location of the backup of the member
+	// +optional
+	BackupPath string `json:"backupPath,omitempty"`
+	// Message explains why the backup of the member is not complete
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // +genclient
@@ -1580,6 +2954,9 @@ type BackupScheduleSpec struct {
 
 // BackupScheduleStatus represents the current state of a BackupSchedule.
 type BackupScheduleStatus struct {
+	// ObservedGeneration is the most recent generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// LastBackup represents the last backup.
 	LastBackup string `json:"lastBackup"`
 	// LastBackupTime represents the last time the backup was successfully created.
@@ -1715,6 +3092,9 @@ type RestoreSpec struct {
 
 // RestoreStatus represents the current status of a tidb cluster restore.
 type RestoreStatus struct {
+	// ObservedGeneration is the most recent generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 	// TimeStarted is the time at which the restore was started.
 	TimeStarted metav1.Time `json:"timeStarted"`
 	// TimeCompleted is the time at which the restore was completed.
@@ -1825,6 +3205,7 @@ type DMClusterSpec struct {
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 
 	// Whether enable PVC reclaim for orphan PVC left by statefulset scale-in
+	// It can be overridden by `pvcDeletePolicy` of each component
 	// Optional: Defaults to false
 	// +optional
 	EnablePVReclaim *bool `json:"enablePVReclaim,omitempty"`
@@ -1892,6 +3273,10 @@ type DMClusterSpec struct {
 
 // DMClusterStatus represents the current status of a dm cluster.
 type DMClusterStatus struct {
+	// ObservedGeneration is the most recent generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
 	Master MasterStatus `json:"master,omitempty"`
 	Worker WorkerStatus `json:"worker,omitempty"`
 
@@ -2095,6 +3480,8 @@ type WorkerMember struct {
 	Name  string `json:"name,omitempty"`
 	Addr  string `json:"addr,omitempty"`
 	Stage string `json:"stage"`
+	// Source bound to the worker, it is the last bound source for an offline worker
+	Source string `json:"source,omitempty"`
 	// Last time the health transitioned from one to another.
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
 }
@@ -2103,6 +3490,10 @@ type WorkerMember struct {
 type WorkerFailureMember struct {
 	PodName   string      `json:"podName,omitempty"`
 	CreatedAt metav1.Time `json:"createdAt,omitempty"`
+	// Source bound to the worker when it failed
+	Source string `json:"source,omitempty"`
+	// SourceReboundTo is the worker which the source is rebound to
+	SourceReboundTo string `json:"sourceReboundTo,omitempty"`
 }
 
 // StorageVolume configures additional PVC template for StatefulSets and volumeMount for pods that mount this PVC.
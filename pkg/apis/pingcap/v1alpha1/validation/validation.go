@@ -27,10 +27,13 @@ import (
 	"github.com/Masterminds/semver"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/util/crypto"
 	"github.com/prometheus/common/model"
+	"github.com/robfig/cron"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	utilnet "k8s.io/utils/net"
@@ -46,6 +49,9 @@ func ValidateTidbCluster(tc *v1alpha1.TidbCluster) field.ErrorList {
 	allErrs = append(allErrs, validateAnnotations(tc.ObjectMeta.Annotations, fldPath.Child("annotations"))...)
 	// validate spec
 	allErrs = append(allErrs, validateTiDBClusterSpec(&tc.Spec, field.NewPath("spec"))...)
+	if tc.Annotations[label.AnnAdoption] == "true" && len(tc.Spec.PDAddresses) == 0 {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec", "pdAddresses"), fmt.Sprintf("the PD addresses of the cluster to adopt must be set with annotation %s", label.AnnAdoption)))
+	}
 	return allErrs
 }
 
@@ -84,6 +90,9 @@ func validateAnnotations(anns map[string]string, fldPath *field.Path) field.Erro
 	for _, key := range []string{label.AnnPDDeleteSlots, label.AnnTiDBDeleteSlots, label.AnnTiKVDeleteSlots, label.AnnTiFlashDeleteSlots} {
 		allErrs = append(allErrs, validateDeleteSlots(anns, key, fldPath.Child(key))...)
 	}
+	for _, key := range []string{label.AnnPDRestartOrdinals, label.AnnTiDBRestartOrdinals, label.AnnTiKVRestartOrdinals, label.AnnTiFlashRestartOrdinals} {
+		allErrs = append(allErrs, validateDeleteSlots(anns, key, fldPath.Child(key))...)
+	}
 	return allErrs
 }
 
@@ -117,7 +126,112 @@ func validateTiDBClusterSpec(spec *v1alpha1.TidbClusterSpec, fldPath *field.Path
 		allErrs = append(allErrs, validateTiCDCSpec(spec.TiCDC, fldPath.Child("ticdc"))...)
 	}
 	if spec.PDAddresses != nil {
-		allErrs = append(allErrs, validatePDAddresses(spec.PDAddresses, fldPath.Child("pdAddresses"))...)
+		scheme := "http"
+		if spec.TLSCluster != nil && spec.TLSCluster.Enabled {
+			scheme = "https"
+		}
+		allErrs = append(allErrs, validatePDAddresses(spec.PDAddresses, scheme, fldPath.Child("pdAddresses"))...)
+		if spec.PD == nil && (spec.Cluster == nil || spec.Cluster.Name == "") {
+			// the components join the PD cluster out of Kubernetes
+			if spec.Pump != nil {
+				allErrs = append(allErrs, field.Forbidden(fldPath.Child("pump"), "pump is not supported with the PD cluster out of Kubernetes at pdAddresses"))
+			}
+			if spec.TiCDC != nil {
+				allErrs = append(allErrs, field.Forbidden(fldPath.Child("ticdc"), "ticdc is not supported with the PD cluster out of Kubernetes at pdAddresses"))
+			}
+		}
+	}
+	if spec.TLSCluster != nil {
+		allErrs = append(allErrs, validateTLSCluster(spec.TLSCluster, fldPath.Child("tlsCluster"))...)
+	}
+	if spec.PostUpgradeHook != nil {
+		allErrs = append(allErrs, validatePostUpgradeHook(spec.PostUpgradeHook, fldPath.Child("postUpgradeHook"))...)
+	}
+	if spec.Restore != nil {
+		allErrs = append(allErrs, validateTidbClusterRestore(spec, fldPath.Child("restore"))...)
+	}
+	allErrs = append(allErrs, validateRestartSchedules(spec.RestartSchedules, fldPath.Child("restartSchedules"))...)
+	return allErrs
+}
+
+func validateRestartSchedules(schedules []v1alpha1.RestartSchedule, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	components := sets.NewString()
+	for i, schedule := range schedules {
+		idxPath := fldPath.Index(i)
+		switch schedule.Component {
+		case v1alpha1.PDMemberType, v1alpha1.TiKVMemberType, v1alpha1.TiFlashMemberType, v1alpha1.TiDBMemberType:
+		default:
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("component"), schedule.Component,
+				[]string{v1alpha1.PDMemberType.String(), v1alpha1.TiKVMemberType.String(), v1alpha1.TiFlashMemberType.String(), v1alpha1.TiDBMemberType.String()}))
+		}
+		if components.Has(schedule.Component.String()) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("component"), schedule.Component))
+		}
+		components.Insert(schedule.Component.String())
+		if _, err := cron.ParseStandard(schedule.Schedule); err != nil {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("schedule"), schedule.Schedule, err.Error()))
+		}
+		if schedule.Window != nil && schedule.Window.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("window"), schedule.Window.Duration.String(), "window must be positive"))
+		}
+	}
+	return allErrs
+}
+
+func validateTidbClusterRestore(spec *v1alpha1.TidbClusterSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	restore := spec.Restore
+	if restore.Backup == "" && restore.RestoreSpec == nil {
+		allErrs = append(allErrs, field.Required(fldPath.Child("backup"), "one of backup and restoreSpec must be set"))
+	}
+	if restore.Backup != "" && restore.RestoreSpec != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("restoreSpec"), "restoreSpec can not be set with backup"))
+	}
+	if spec.PD == nil || spec.TiKV == nil {
+		allErrs = append(allErrs, field.Required(fldPath, "pd and tikv must be deployed to restore the data into the cluster"))
+	}
+	return allErrs
+}
+
+func validatePostUpgradeHook(hook *v1alpha1.PostUpgradeHookSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if hook.SQL == "" && hook.SQLConfigMap == nil {
+		allErrs = append(allErrs, field.Required(fldPath.Child("sql"), "one of sql and sqlConfigMap must be set"))
+	}
+	if hook.SQL != "" && hook.SQLConfigMap != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("sqlConfigMap"), "sqlConfigMap can not be set with sql"))
+	}
+	return allErrs
+}
+
+func validateTLSCluster(tlsCluster *v1alpha1.TLSCluster, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if tlsCluster.Policy != nil {
+		allErrs = append(allErrs, validateTLSPolicy(tlsCluster.Policy, fldPath.Child("policy"))...)
+	}
+	if tlsCluster.CABundle != nil {
+		if tlsCluster.CABundle.SecretName == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("caBundle", "secretName"), "secretName must not be empty"))
+		}
+		if tlsCluster.CertManager != nil || tlsCluster.Vault != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("caBundle"), "caBundle can not be set with certManager or vault, which manage the ca.crt of the cluster secrets"))
+		}
+	}
+	return allErrs
+}
+
+func validateTLSPolicy(policy *v1alpha1.TLSPolicy, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if policy.MinVersion != "" {
+		if _, err := crypto.ParseTLSVersion(policy.MinVersion); err != nil {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("minVersion"), policy.MinVersion, []string{"TLSv1.0", "TLSv1.1", "TLSv1.2", "TLSv1.3"}))
+		}
+	}
+	for i, name := range policy.CipherSuites {
+		if _, err := crypto.ParseCipherSuites([]string{name}); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("cipherSuites").Index(i), name, err.Error()))
+		}
 	}
 	return allErrs
 }
@@ -129,19 +243,22 @@ func validatePDSpec(spec *v1alpha1.PDSpec, fldPath *field.Path) field.ErrorList
 	if len(spec.StorageVolumes) > 0 {
 		allErrs = append(allErrs, validateStorageVolumes(spec.StorageVolumes, fldPath.Child("storageVolumes"))...)
 	}
+	if len(spec.DeleteSlots) > 0 {
+		allErrs = append(allErrs, validateDeleteSlotsField(spec.DeleteSlots, fldPath.Child("deleteSlots"))...)
+	}
 	return allErrs
 }
 
-func validatePDAddresses(arrayOfAddresses []string, fldPath *field.Path) field.ErrorList {
+func validatePDAddresses(arrayOfAddresses []string, scheme string, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	for i, address := range arrayOfAddresses {
 		idxPath := fldPath.Index(i)
 		u, err := url.Parse(address)
-		example := " PD address format example: http://{ADDRESS}:{PORT}"
+		example := fmt.Sprintf(" PD address format example: %s://{ADDRESS}:{PORT}", scheme)
 		if err != nil {
 			allErrs = append(allErrs, field.Invalid(idxPath, address, err.Error()+example))
-		} else if u.Scheme != "http" {
-			allErrs = append(allErrs, field.Invalid(idxPath, address, "Support 'http' scheme only."+example))
+		} else if u.Scheme != scheme {
+			allErrs = append(allErrs, field.Invalid(idxPath, address, fmt.Sprintf("Support '%s' scheme only.", scheme)+example))
 		}
 	}
 	return allErrs
@@ -158,6 +275,34 @@ func validateTiKVSpec(spec *v1alpha1.TiKVSpec, fldPath *field.Path) field.ErrorL
 		allErrs = append(allErrs, validateStorageVolumes(spec.StorageVolumes, fldPath.Child("storageVolumes"))...)
 	}
 	allErrs = append(allErrs, validateTimeDurationStr(spec.EvictLeaderTimeout, fldPath.Child("evictLeaderTimeout"))...)
+	if len(spec.DeleteSlots) > 0 {
+		allErrs = append(allErrs, validateDeleteSlotsField(spec.DeleteSlots, fldPath.Child("deleteSlots"))...)
+	}
+	if spec.Encryption != nil {
+		allErrs = append(allErrs, validateTiKVEncryption(spec.Encryption, fldPath.Child("encryption"))...)
+	}
+	return allErrs
+}
+
+func validateTiKVEncryption(encryption *v1alpha1.TiKVEncryption, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch encryption.Method {
+	case "", "aes128-ctr", "aes192-ctr", "aes256-ctr":
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("method"), encryption.Method, []string{"aes128-ctr", "aes192-ctr", "aes256-ctr"}))
+	}
+	if encryption.DataKeyRotationPeriod != nil && encryption.DataKeyRotationPeriod.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("dataKeyRotationPeriod"), encryption.DataKeyRotationPeriod.Duration.String(), "must be a positive duration"))
+	}
+	masterKeyPath := fldPath.Child("masterKey")
+	switch encryption.MasterKey.Vendor {
+	case "", v1alpha1.KMSVendorAWS, v1alpha1.KMSVendorGCP:
+	default:
+		allErrs = append(allErrs, field.NotSupported(masterKeyPath.Child("vendor"), encryption.MasterKey.Vendor, []string{string(v1alpha1.KMSVendorAWS), string(v1alpha1.KMSVendorGCP)}))
+	}
+	if encryption.MasterKey.KeyID == "" {
+		allErrs = append(allErrs, field.Required(masterKeyPath.Child("keyID"), "the ID of the KMS key must be set"))
+	}
 	return allErrs
 }
 
@@ -169,6 +314,9 @@ func validateTiFlashSpec(spec *v1alpha1.TiFlashSpec, fldPath *field.Path) field.
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("spec.StorageClaims"),
 			spec.StorageClaims, "storageClaims should be configured at least one item."))
 	}
+	if len(spec.DeleteSlots) > 0 {
+		allErrs = append(allErrs, validateDeleteSlotsField(spec.DeleteSlots, fldPath.Child("deleteSlots"))...)
+	}
 	return allErrs
 }
 
@@ -241,9 +389,15 @@ func validateTiDBSpec(spec *v1alpha1.TiDBSpec, fldPath *field.Path) field.ErrorL
 	if len(spec.StorageVolumes) > 0 {
 		allErrs = append(allErrs, validateStorageVolumes(spec.StorageVolumes, fldPath.Child("storageVolumes"))...)
 	}
+	if spec.TLSClient != nil && spec.TLSClient.Policy != nil {
+		allErrs = append(allErrs, validateTLSPolicy(spec.TLSClient.Policy, fldPath.Child("tlsClient", "policy"))...)
+	}
 	if spec.ShouldSeparateSlowLog() && spec.SlowLogVolumeName != "" {
 		allErrs = append(allErrs, validateSlowQueryLogVolume(spec.SlowLogVolumeName, spec.StorageVolumes, spec.AdditionalVolumes, spec.AdditionalVolumeMounts, fldPath)...)
 	}
+	if len(spec.DeleteSlots) > 0 {
+		allErrs = append(allErrs, validateDeleteSlotsField(spec.DeleteSlots, fldPath.Child("deleteSlots"))...)
+	}
 	return allErrs
 }
 
@@ -453,6 +607,10 @@ func ValidateUpdateTidbCluster(old, tc *v1alpha1.TidbCluster) field.ErrorList {
 	}
 	allErrs = append(allErrs, validateUpdatePDConfig(old.Spec.PD.Config, tc.Spec.PD.Config, field.NewPath("spec.pd.config"))...)
 	allErrs = append(allErrs, disallowUsingLegacyAPIInNewCluster(old, tc)...)
+	if old.Spec.TiKV != nil && old.Spec.TiKV.Encryption != nil && tc.Spec.TiKV != nil && tc.Spec.TiKV.Encryption == nil {
+		// the master key is required to decrypt the data keys of the encrypted data
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec.tikv.encryption"), "the encryption of TiKV can not be removed once it is enabled"))
+	}
 
 	return allErrs
 }
@@ -570,6 +728,22 @@ func validateDeleteSlots(annotations map[string]string, key string, fldPath *fie
 	return allErrs
 }
 
+// validateDeleteSlotsField validates `spec.<component>.deleteSlots`, the
+// ordinals must be non-negative and unique.
+func validateDeleteSlotsField(deleteSlots []int32, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	seen := sets.NewInt32()
+	for i, ordinal := range deleteSlots {
+		if ordinal < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), ordinal, "must be greater than or equal to 0"))
+		} else if seen.Has(ordinal) {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Index(i), ordinal))
+		}
+		seen.Insert(ordinal)
+	}
+	return allErrs
+}
+
 func validateService(spec *v1alpha1.ServiceSpec, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	//validate LoadBalancerSourceRanges field from service
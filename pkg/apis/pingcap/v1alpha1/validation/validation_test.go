@@ -16,6 +16,7 @@ package validation
 import (
 	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
@@ -38,8 +39,9 @@ func TestValidateAnnotations(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "test",
 					Annotations: map[string]string{
-						label.AnnTiKVDeleteSlots:    "[1,2]",
-						label.AnnTiFlashDeleteSlots: "[1]",
+						label.AnnTiKVDeleteSlots:     "[1,2]",
+						label.AnnTiFlashDeleteSlots:  "[1]",
+						label.AnnTiKVRestartOrdinals: "[0,3]",
 					},
 				},
 				Spec: v1alpha1.TidbClusterSpec{
@@ -166,6 +168,23 @@ func TestValidateAnnotations(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "restart ordinals invalid format",
+			tc: v1alpha1.TidbCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+					Annotations: map[string]string{
+						label.AnnPDRestartOrdinals: "pd-1",
+					},
+				},
+			},
+			errs: []field.Error{
+				{
+					Type:   field.ErrorTypeInvalid,
+					Detail: `value of "pd.tidb.pingcap.com/restart-ordinals" annotation must be a JSON list of int32`,
+				},
+			},
+		},
 	}
 
 	for _, v := range errorCases {
@@ -587,6 +606,33 @@ func TestValidateEvictLeaderTimeout(t *testing.T) {
 	}
 }
 
+func TestValidateDeleteSlotsField(t *testing.T) {
+	successCases := [][]int32{
+		nil,
+		{1},
+		{0, 3, 5},
+	}
+
+	for _, c := range successCases {
+		errs := validateDeleteSlotsField(c, field.NewPath("spec", "pd", "deleteSlots"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := [][]int32{
+		{-1},
+		{1, 3, 1},
+	}
+
+	for _, c := range errorCases {
+		errs := validateDeleteSlotsField(c, field.NewPath("spec", "pd", "deleteSlots"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
 func TestValidatePromDurationStr(t *testing.T) {
 	successCases := []*string{
 		nil,
@@ -630,7 +676,7 @@ func TestValidatePDAddresses(t *testing.T) {
 	}
 
 	for _, c := range successCases {
-		errs := validatePDAddresses(c, field.NewPath("pdAddresses"))
+		errs := validatePDAddresses(c, "http", field.NewPath("pdAddresses"))
 		if len(errs) > 0 {
 			t.Errorf("expected success: %v", errs)
 		}
@@ -650,9 +696,251 @@ func TestValidatePDAddresses(t *testing.T) {
 	}
 
 	for _, c := range errorCases {
-		errs := validatePDAddresses(c, field.NewPath("pdAddresses"))
+		errs := validatePDAddresses(c, "http", field.NewPath("pdAddresses"))
 		if len(errs) == 0 {
 			t.Errorf("expected failure for %s", c)
 		}
 	}
+
+	// the PD addresses must use https if TLS is enabled
+	if errs := validatePDAddresses([]string{"https://1.2.3.4:2379"}, "https", field.NewPath("pdAddresses")); len(errs) > 0 {
+		t.Errorf("expected success: %v", errs)
+	}
+	if errs := validatePDAddresses([]string{"http://1.2.3.4:2379"}, "https", field.NewPath("pdAddresses")); len(errs) == 0 {
+		t.Errorf("expected failure for http address with TLS enabled")
+	}
+}
+
+func TestValidateExternalPD(t *testing.T) {
+	spec := &v1alpha1.TidbClusterSpec{
+		PDAddresses: []string{"http://1.2.3.4:2379"},
+	}
+	if errs := validateTiDBClusterSpec(spec, field.NewPath("spec")); len(errs) > 0 {
+		t.Errorf("expected success: %v", errs)
+	}
+
+	// pump and ticdc are not supported with the PD cluster out of Kubernetes
+	spec.Pump = &v1alpha1.PumpSpec{}
+	spec.TiCDC = &v1alpha1.TiCDCSpec{}
+	errs := validateTiDBClusterSpec(spec, field.NewPath("spec"))
+	var forbidden int
+	for _, err := range errs {
+		if err.Type == field.ErrorTypeForbidden {
+			forbidden++
+		}
+	}
+	if forbidden != 2 {
+		t.Errorf("expected pump and ticdc to be forbidden: %v", errs)
+	}
+
+	// the https scheme must be used if TLS is enabled
+	spec.Pump, spec.TiCDC = nil, nil
+	spec.TLSCluster = &v1alpha1.TLSCluster{Enabled: true}
+	if errs := validateTiDBClusterSpec(spec, field.NewPath("spec")); len(errs) == 0 {
+		t.Errorf("expected failure for http address with TLS enabled")
+	}
+}
+
+func TestValidateAdoption(t *testing.T) {
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test",
+			Annotations: map[string]string{label.AnnAdoption: "true"},
+		},
+	}
+	errs := ValidateTidbCluster(tc)
+	if len(errs) != 1 || errs[0].Type != field.ErrorTypeRequired || errs[0].Field != "spec.pdAddresses" {
+		t.Errorf("expected spec.pdAddresses to be required: %v", errs)
+	}
+
+	tc.Spec.PDAddresses = []string{"http://1.2.3.4:2379"}
+	if errs := ValidateTidbCluster(tc); len(errs) > 0 {
+		t.Errorf("expected success: %v", errs)
+	}
+}
+
+func TestValidateTLSPolicy(t *testing.T) {
+	successCases := []v1alpha1.TLSPolicy{
+		{},
+		{MinVersion: "TLSv1.2"},
+		{MinVersion: "TLSv1.3", CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}},
+	}
+
+	for _, c := range successCases {
+		errs := validateTLSPolicy(&c, field.NewPath("spec", "tlsCluster", "policy"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.TLSPolicy{
+		{MinVersion: "SSLv3"},
+		{CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_AES_128_GCM_SHA256"}},
+	}
+
+	for _, c := range errorCases {
+		errs := validateTLSPolicy(&c, field.NewPath("spec", "tlsCluster", "policy"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateTLSCluster(t *testing.T) {
+	successCases := []v1alpha1.TLSCluster{
+		{Enabled: true},
+		{Enabled: true, CABundle: &v1alpha1.TLSCABundle{SecretName: "ca-bundle"}},
+		{Enabled: true, CertManager: &v1alpha1.TLSCertManager{}},
+	}
+
+	for _, c := range successCases {
+		errs := validateTLSCluster(&c, field.NewPath("spec", "tlsCluster"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.TLSCluster{
+		{Enabled: true, CABundle: &v1alpha1.TLSCABundle{}},
+		{Enabled: true, CABundle: &v1alpha1.TLSCABundle{SecretName: "ca-bundle"}, CertManager: &v1alpha1.TLSCertManager{}},
+		{Enabled: true, CABundle: &v1alpha1.TLSCABundle{SecretName: "ca-bundle"}, Vault: &v1alpha1.TLSVault{}},
+		{Enabled: true, Policy: &v1alpha1.TLSPolicy{MinVersion: "SSLv3"}},
+	}
+
+	for _, c := range errorCases {
+		errs := validateTLSCluster(&c, field.NewPath("spec", "tlsCluster"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidatePostUpgradeHook(t *testing.T) {
+	configMap := &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "hook"}, Key: "upgrade.sql"}
+	successCases := []v1alpha1.PostUpgradeHookSpec{
+		{SQL: "ANALYZE TABLE test.t;"},
+		{SQLConfigMap: configMap},
+	}
+
+	for _, c := range successCases {
+		errs := validatePostUpgradeHook(&c, field.NewPath("spec", "postUpgradeHook"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.PostUpgradeHookSpec{
+		{},
+		{SQL: "ANALYZE TABLE test.t;", SQLConfigMap: configMap},
+	}
+
+	for _, c := range errorCases {
+		errs := validatePostUpgradeHook(&c, field.NewPath("spec", "postUpgradeHook"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateTidbClusterRestore(t *testing.T) {
+	newSpec := func(restore v1alpha1.TidbClusterRestoreSpec) v1alpha1.TidbClusterSpec {
+		return v1alpha1.TidbClusterSpec{
+			PD:      &v1alpha1.PDSpec{},
+			TiKV:    &v1alpha1.TiKVSpec{},
+			Restore: &restore,
+		}
+	}
+	restoreSpec := &v1alpha1.RestoreSpec{
+		StorageProvider: v1alpha1.StorageProvider{S3: &v1alpha1.S3StorageProvider{Bucket: "backup", Prefix: "prod"}},
+	}
+	successCases := []v1alpha1.TidbClusterSpec{
+		newSpec(v1alpha1.TidbClusterRestoreSpec{Backup: "prod"}),
+		newSpec(v1alpha1.TidbClusterRestoreSpec{RestoreSpec: restoreSpec}),
+	}
+
+	for _, c := range successCases {
+		errs := validateTidbClusterRestore(&c, field.NewPath("spec", "restore"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.TidbClusterSpec{
+		newSpec(v1alpha1.TidbClusterRestoreSpec{}),
+		newSpec(v1alpha1.TidbClusterRestoreSpec{Backup: "prod", RestoreSpec: restoreSpec}),
+		{Restore: &v1alpha1.TidbClusterRestoreSpec{Backup: "prod"}},
+	}
+
+	for _, c := range errorCases {
+		errs := validateTidbClusterRestore(&c, field.NewPath("spec", "restore"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c.Restore)
+		}
+	}
+}
+
+func TestValidateRestartSchedules(t *testing.T) {
+	successCases := [][]v1alpha1.RestartSchedule{
+		nil,
+		{
+			{Component: v1alpha1.TiDBMemberType, Schedule: "0 3 * * 6"},
+			{Component: v1alpha1.TiKVMemberType, Schedule: "0 4 1 * *", Window: &metav1.Duration{Duration: time.Hour}},
+		},
+	}
+
+	for _, c := range successCases {
+		errs := validateRestartSchedules(c, field.NewPath("spec", "restartSchedules"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := [][]v1alpha1.RestartSchedule{
+		{{Component: v1alpha1.PumpMemberType, Schedule: "0 3 * * 6"}},
+		{{Component: v1alpha1.TiDBMemberType, Schedule: "every saturday"}},
+		{{Component: v1alpha1.TiDBMemberType, Schedule: "0 3 * * 6", Window: &metav1.Duration{}}},
+		{
+			{Component: v1alpha1.TiDBMemberType, Schedule: "0 3 * * 6"},
+			{Component: v1alpha1.TiDBMemberType, Schedule: "0 3 * * 0"},
+		},
+	}
+
+	for _, c := range errorCases {
+		errs := validateRestartSchedules(c, field.NewPath("spec", "restartSchedules"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
+}
+
+func TestValidateTiKVEncryption(t *testing.T) {
+	successCases := []v1alpha1.TiKVEncryption{
+		{MasterKey: v1alpha1.TiKVKMSMasterKey{KeyID: "key"}},
+		{
+			Method:                "aes128-ctr",
+			DataKeyRotationPeriod: &metav1.Duration{Duration: time.Hour},
+			MasterKey:             v1alpha1.TiKVKMSMasterKey{Vendor: v1alpha1.KMSVendorGCP, KeyID: "key", SecretName: "kms"},
+		},
+	}
+
+	for _, c := range successCases {
+		errs := validateTiKVEncryption(&c, field.NewPath("spec", "tikv", "encryption"))
+		if len(errs) > 0 {
+			t.Errorf("expected success: %v", errs)
+		}
+	}
+
+	errorCases := []v1alpha1.TiKVEncryption{
+		{},
+		{Method: "sm4-ctr", MasterKey: v1alpha1.TiKVKMSMasterKey{KeyID: "key"}},
+		{DataKeyRotationPeriod: &metav1.Duration{}, MasterKey: v1alpha1.TiKVKMSMasterKey{KeyID: "key"}},
+		{MasterKey: v1alpha1.TiKVKMSMasterKey{Vendor: "azure", KeyID: "key"}},
+	}
+
+	for _, c := range errorCases {
+		errs := validateTiKVEncryption(&c, field.NewPath("spec", "tikv", "encryption"))
+		if len(errs) == 0 {
+			t.Errorf("expected failure for %v", c)
+		}
+	}
 }
@@ -25,10 +25,61 @@ import (
 	v1 "k8s.io/api/core/v1"
 	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
 	v1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	types "k8s.io/apimachinery/pkg/types"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdoptionMember) DeepCopyInto(out *AdoptionMember) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdoptionMember.
+func (in *AdoptionMember) DeepCopy() *AdoptionMember {
+	if in == nil {
+		return nil
+	}
+	out := new(AdoptionMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdoptionStatus) DeepCopyInto(out *AdoptionStatus) {
+	*out = *in
+	if in.AdoptedComponents != nil {
+		in, out := &in.AdoptedComponents, &out.AdoptedComponents
+		*out = make([]MemberType, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExternalMembers != nil {
+		in, out := &in.ExternalMembers, &out.ExternalMembers
+		*out = make([]AdoptionMember, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdoptionStatus.
+func (in *AdoptionStatus) DeepCopy() *AdoptionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AdoptionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AutoResource) DeepCopyInto(out *AutoResource) {
 	*out = *in
@@ -79,6 +130,22 @@ func (in *AutoRule) DeepCopy() *AutoRule {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoScalerReplicasStatus) DeepCopyInto(out *AutoScalerReplicasStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoScalerReplicasStatus.
+func (in *AutoScalerReplicasStatus) DeepCopy() *AutoScalerReplicasStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoScalerReplicasStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BRConfig) DeepCopyInto(out *BRConfig) {
 	*out = *in
@@ -170,6 +237,64 @@ func (in *BackupCondition) DeepCopy() *BackupCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupFederationMemberStatus) DeepCopyInto(out *BackupFederationMemberStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupFederationMemberStatus.
+func (in *BackupFederationMemberStatus) DeepCopy() *BackupFederationMemberStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupFederationMemberStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupFederationSpec) DeepCopyInto(out *BackupFederationSpec) {
+	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]TidbClusterFederationMember, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupFederationSpec.
+func (in *BackupFederationSpec) DeepCopy() *BackupFederationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupFederationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupFederationStatus) DeepCopyInto(out *BackupFederationStatus) {
+	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]BackupFederationMemberStatus, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupFederationStatus.
+func (in *BackupFederationStatus) DeepCopy() *BackupFederationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupFederationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupList) DeepCopyInto(out *BackupList) {
 	*out = *in
@@ -357,6 +482,11 @@ func (in *BackupSpec) DeepCopyInto(out *BackupSpec) {
 		*out = new(BRConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Federation != nil {
+		in, out := &in.Federation, &out.Federation
+		*out = new(BackupFederationSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Dumpling != nil {
 		in, out := &in.Dumpling, &out.Dumpling
 		*out = new(DumplingConfig)
@@ -414,6 +544,11 @@ func (in *BackupStatus) DeepCopyInto(out *BackupStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Federation != nil {
+		in, out := &in.Federation, &out.Federation
+		*out = new(BackupFederationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -571,6 +706,47 @@ func (in *CDCConfigWraper) DeepCopy() *CDCConfigWraper {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertManagerIssuerRef) DeepCopyInto(out *CertManagerIssuerRef) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerIssuerRef.
+func (in *CertManagerIssuerRef) DeepCopy() *CertManagerIssuerRef {
+	if in == nil {
+		return nil
+	}
+	out := new(CertManagerIssuerRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterDomainMigrationStatus) DeepCopyInto(out *ClusterDomainMigrationStatus) {
+	*out = *in
+	if in.MigratedMembers != nil {
+		in, out := &in.MigratedMembers, &out.MigratedMembers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDomainMigrationStatus.
+func (in *ClusterDomainMigrationStatus) DeepCopy() *ClusterDomainMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterDomainMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterRef) DeepCopyInto(out *ClusterRef) {
 	*out = *in
@@ -587,6 +763,22 @@ func (in *ClusterRef) DeepCopy() *ClusterRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ColumnMask) DeepCopyInto(out *ColumnMask) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ColumnMask.
+func (in *ColumnMask) DeepCopy() *ColumnMask {
+	if in == nil {
+		return nil
+	}
+	out := new(ColumnMask)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CommonConfig) DeepCopyInto(out *CommonConfig) {
 	*out = *in
@@ -713,6 +905,23 @@ func (in *CommonConfig) DeepCopy() *CommonConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentCondition) DeepCopyInto(out *ComponentCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentCondition.
+func (in *ComponentCondition) DeepCopy() *ComponentCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComponentSpec) DeepCopyInto(out *ComponentSpec) {
 	*out = *in
@@ -834,6 +1043,18 @@ func (in *ComponentSpec) DeepCopyInto(out *ComponentSpec) {
 		*out = make([]TopologySpreadConstraint, len(*in))
 		copy(*out, *in)
 	}
+	if in.PVCDeletePolicy != nil {
+		in, out := &in.PVCDeletePolicy, &out.PVCDeletePolicy
+		*out = new(PVCDeletePolicy)
+		**out = **in
+	}
+	if in.PVCAnnotations != nil {
+		in, out := &in.PVCAnnotations, &out.PVCAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -1061,7 +1282,7 @@ func (in *DMClusterSpec) DeepCopyInto(out *DMClusterSpec) {
 	if in.TLSCluster != nil {
 		in, out := &in.TLSCluster, &out.TLSCluster
 		*out = new(TLSCluster)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.TLSClientSecretNames != nil {
 		in, out := &in.TLSClientSecretNames, &out.TLSClientSecretNames
@@ -1387,6 +1608,53 @@ func (in *DiscoverySpec) DeepCopy() *DiscoverySpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrainerNodeStatus) DeepCopyInto(out *DrainerNodeStatus) {
+	*out = *in
+	if in.CheckpointTime != nil {
+		in, out := &in.CheckpointTime, &out.CheckpointTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainerNodeStatus.
+func (in *DrainerNodeStatus) DeepCopy() *DrainerNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DrainerNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrainerStatus) DeepCopyInto(out *DrainerStatus) {
+	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]*DrainerNodeStatus, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(DrainerNodeStatus)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainerStatus.
+func (in *DrainerStatus) DeepCopy() *DrainerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DrainerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DumplingConfig) DeepCopyInto(out *DumplingConfig) {
 	*out = *in
@@ -1478,104 +1746,207 @@ func (in *ExternalEndpoint) DeepCopy() *ExternalEndpoint {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FileLogConfig) DeepCopyInto(out *FileLogConfig) {
+func (in *ExternalSecretRef) DeepCopyInto(out *ExternalSecretRef) {
 	*out = *in
-	if in.Filename != nil {
-		in, out := &in.Filename, &out.Filename
-		*out = new(string)
-		**out = **in
-	}
-	if in.LogRotate != nil {
-		in, out := &in.LogRotate, &out.LogRotate
-		*out = new(bool)
-		**out = **in
-	}
-	if in.MaxSize != nil {
-		in, out := &in.MaxSize, &out.MaxSize
-		*out = new(int)
-		**out = **in
-	}
-	if in.MaxDays != nil {
-		in, out := &in.MaxDays, &out.MaxDays
-		*out = new(int)
-		**out = **in
-	}
-	if in.MaxBackups != nil {
-		in, out := &in.MaxBackups, &out.MaxBackups
-		*out = new(int)
+	if in.Vault != nil {
+		in, out := &in.Vault, &out.Vault
+		*out = new(ExternalSecretVault)
 		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileLogConfig.
-func (in *FileLogConfig) DeepCopy() *FileLogConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalSecretRef.
+func (in *ExternalSecretRef) DeepCopy() *ExternalSecretRef {
 	if in == nil {
 		return nil
 	}
-	out := new(FileLogConfig)
+	out := new(ExternalSecretRef)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Flash) DeepCopyInto(out *Flash) {
+func (in *ExternalSecretVault) DeepCopyInto(out *ExternalSecretVault) {
 	*out = *in
-	if in.TiDBStatusAddr != nil {
-		in, out := &in.TiDBStatusAddr, &out.TiDBStatusAddr
-		*out = new(string)
-		**out = **in
-	}
-	if in.ServiceAddr != nil {
-		in, out := &in.ServiceAddr, &out.ServiceAddr
-		*out = new(string)
-		**out = **in
-	}
-	if in.OverlapThreshold != nil {
-		in, out := &in.OverlapThreshold, &out.OverlapThreshold
-		*out = new(float64)
-		**out = **in
-	}
-	if in.CompactLogMinPeriod != nil {
-		in, out := &in.CompactLogMinPeriod, &out.CompactLogMinPeriod
-		*out = new(int32)
-		**out = **in
-	}
-	if in.FlashCluster != nil {
-		in, out := &in.FlashCluster, &out.FlashCluster
-		*out = new(FlashCluster)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.FlashProxy != nil {
-		in, out := &in.FlashProxy, &out.FlashProxy
-		*out = new(FlashProxy)
-		(*in).DeepCopyInto(*out)
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Flash.
-func (in *Flash) DeepCopy() *Flash {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalSecretVault.
+func (in *ExternalSecretVault) DeepCopy() *ExternalSecretVault {
 	if in == nil {
 		return nil
 	}
-	out := new(Flash)
+	out := new(ExternalSecretVault)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FlashApplication) DeepCopyInto(out *FlashApplication) {
+func (in *FailoverRecoveryPolicy) DeepCopyInto(out *FailoverRecoveryPolicy) {
 	*out = *in
-	if in.RunAsDaemon != nil {
-		in, out := &in.RunAsDaemon, &out.RunAsDaemon
-		*out = new(bool)
+	if in.HealthyPeriod != nil {
+		in, out := &in.HealthyPeriod, &out.HealthyPeriod
+		*out = new(metav1.Duration)
 		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlashApplication.
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailoverRecoveryPolicy.
+func (in *FailoverRecoveryPolicy) DeepCopy() *FailoverRecoveryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(FailoverRecoveryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederationPeer) DeepCopyInto(out *FederationPeer) {
+	*out = *in
+	if in.PeerURLs != nil {
+		in, out := &in.PeerURLs, &out.PeerURLs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederationPeer.
+func (in *FederationPeer) DeepCopy() *FederationPeer {
+	if in == nil {
+		return nil
+	}
+	out := new(FederationPeer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FederationStatus) DeepCopyInto(out *FederationStatus) {
+	*out = *in
+	if in.Peers != nil {
+		in, out := &in.Peers, &out.Peers
+		*out = make([]FederationPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FederationStatus.
+func (in *FederationStatus) DeepCopy() *FederationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FederationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileLogConfig) DeepCopyInto(out *FileLogConfig) {
+	*out = *in
+	if in.Filename != nil {
+		in, out := &in.Filename, &out.Filename
+		*out = new(string)
+		**out = **in
+	}
+	if in.LogRotate != nil {
+		in, out := &in.LogRotate, &out.LogRotate
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxDays != nil {
+		in, out := &in.MaxDays, &out.MaxDays
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxBackups != nil {
+		in, out := &in.MaxBackups, &out.MaxBackups
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileLogConfig.
+func (in *FileLogConfig) DeepCopy() *FileLogConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(FileLogConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Flash) DeepCopyInto(out *Flash) {
+	*out = *in
+	if in.TiDBStatusAddr != nil {
+		in, out := &in.TiDBStatusAddr, &out.TiDBStatusAddr
+		*out = new(string)
+		**out = **in
+	}
+	if in.ServiceAddr != nil {
+		in, out := &in.ServiceAddr, &out.ServiceAddr
+		*out = new(string)
+		**out = **in
+	}
+	if in.OverlapThreshold != nil {
+		in, out := &in.OverlapThreshold, &out.OverlapThreshold
+		*out = new(float64)
+		**out = **in
+	}
+	if in.CompactLogMinPeriod != nil {
+		in, out := &in.CompactLogMinPeriod, &out.CompactLogMinPeriod
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FlashCluster != nil {
+		in, out := &in.FlashCluster, &out.FlashCluster
+		*out = new(FlashCluster)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FlashProxy != nil {
+		in, out := &in.FlashProxy, &out.FlashProxy
+		*out = new(FlashProxy)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Flash.
+func (in *Flash) DeepCopy() *Flash {
+	if in == nil {
+		return nil
+	}
+	out := new(Flash)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FlashApplication) DeepCopyInto(out *FlashApplication) {
+	*out = *in
+	if in.RunAsDaemon != nil {
+		in, out := &in.RunAsDaemon, &out.RunAsDaemon
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlashApplication.
 func (in *FlashApplication) DeepCopy() *FlashApplication {
 	if in == nil {
 		return nil
@@ -1904,6 +2275,11 @@ func (in *FlashUser) DeepCopy() *FlashUser {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GcsStorageProvider) DeepCopyInto(out *GcsStorageProvider) {
 	*out = *in
+	if in.ExternalSecret != nil {
+		in, out := &in.ExternalSecret, &out.ExternalSecret
+		*out = new(ExternalSecretRef)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -2015,6 +2391,69 @@ func (in *IngressSpec) DeepCopy() *IngressSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InitSqlScript) DeepCopyInto(out *InitSqlScript) {
+	*out = *in
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitSqlScript.
+func (in *InitSqlScript) DeepCopy() *InitSqlScript {
+	if in == nil {
+		return nil
+	}
+	out := new(InitSqlScript)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InitSqlScriptStatus) DeepCopyInto(out *InitSqlScriptStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitSqlScriptStatus.
+func (in *InitSqlScriptStatus) DeepCopy() *InitSqlScriptStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InitSqlScriptStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InitializerAttempt) DeepCopyInto(out *InitializerAttempt) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitializerAttempt.
+func (in *InitializerAttempt) DeepCopy() *InitializerAttempt {
+	if in == nil {
+		return nil
+	}
+	out := new(InitializerAttempt)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InitializerSpec) DeepCopyInto(out *InitializerSpec) {
 	*out = *in
@@ -2629,6 +3068,27 @@ func (in *OpenTracingSampler) DeepCopy() *OpenTracingSampler {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationRecord) DeepCopyInto(out *OperationRecord) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationRecord.
+func (in *OperationRecord) DeepCopy() *OperationRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PDConfig) DeepCopyInto(out *PDConfig) {
 	*out = *in
@@ -2799,6 +3259,32 @@ func (in *PDConfigWraper) DeepCopy() *PDConfigWraper {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PDEtcdMemberStatus) DeepCopyInto(out *PDEtcdMemberStatus) {
+	*out = *in
+	if in.Alarms != nil {
+		in, out := &in.Alarms, &out.Alarms
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DBSize != nil {
+		in, out := &in.DBSize, &out.DBSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PDEtcdMemberStatus.
+func (in *PDEtcdMemberStatus) DeepCopy() *PDEtcdMemberStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PDEtcdMemberStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PDFailureMember) DeepCopyInto(out *PDFailureMember) {
 	*out = *in
@@ -2915,6 +3401,11 @@ func (in *PDLogConfig) DeepCopy() *PDLogConfig {
 func (in *PDMember) DeepCopyInto(out *PDMember) {
 	*out = *in
 	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	if in.Etcd != nil {
+		in, out := &in.Etcd, &out.Etcd
+		*out = new(PDEtcdMemberStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -3326,6 +3817,11 @@ func (in *PDSpec) DeepCopyInto(out *PDSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.FailoverRecovery != nil {
+		in, out := &in.FailoverRecovery, &out.FailoverRecovery
+		*out = new(FailoverRecoveryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.StorageClassName != nil {
 		in, out := &in.StorageClassName, &out.StorageClassName
 		*out = new(string)
@@ -3358,6 +3854,11 @@ func (in *PDSpec) DeepCopyInto(out *PDSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.DeleteSlots != nil {
+		in, out := &in.DeleteSlots, &out.DeleteSlots
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -3408,6 +3909,50 @@ func (in *PDStatus) DeepCopyInto(out *PDStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make(map[string]StorageVolumeStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.StorageUsage != nil {
+		in, out := &in.StorageUsage, &out.StorageUsage
+		*out = new(StorageUsage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MicroServices != nil {
+		in, out := &in.MicroServices, &out.MicroServices
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ComponentCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UpgradeProgress != nil {
+		in, out := &in.UpgradeProgress, &out.UpgradeProgress
+		*out = new(UpgradeProgress)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClusterDomainMigration != nil {
+		in, out := &in.ClusterDomainMigration, &out.ClusterDomainMigration
+		*out = new(ClusterDomainMigrationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -3470,14 +4015,30 @@ func (in PDStoreLabels) DeepCopy() PDStoreLabels {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Performance) DeepCopyInto(out *Performance) {
+func (in *PendingChange) DeepCopyInto(out *PendingChange) {
 	*out = *in
-	if in.MaxProcs != nil {
-		in, out := &in.MaxProcs, &out.MaxProcs
-		*out = new(uint)
-		**out = **in
-	}
-	if in.MaxMemory != nil {
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingChange.
+func (in *PendingChange) DeepCopy() *PendingChange {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Performance) DeepCopyInto(out *Performance) {
+	*out = *in
+	if in.MaxProcs != nil {
+		in, out := &in.MaxProcs, &out.MaxProcs
+		*out = new(uint)
+		**out = **in
+	}
+	if in.MaxMemory != nil {
 		in, out := &in.MaxMemory, &out.MaxMemory
 		*out = new(uint64)
 		**out = **in
@@ -3653,6 +4214,66 @@ func (in *Plugin) DeepCopy() *Plugin {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostUpgradeHookSpec) DeepCopyInto(out *PostUpgradeHookSpec) {
+	*out = *in
+	if in.SQLConfigMap != nil {
+		in, out := &in.SQLConfigMap, &out.SQLConfigMap
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PasswordSecret != nil {
+		in, out := &in.PasswordSecret, &out.PasswordSecret
+		*out = new(string)
+		**out = **in
+	}
+	if in.TLSClientSecretName != nil {
+		in, out := &in.TLSClientSecretName, &out.TLSClientSecretName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostUpgradeHookSpec.
+func (in *PostUpgradeHookSpec) DeepCopy() *PostUpgradeHookSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostUpgradeHookSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostUpgradeHookStatus) DeepCopyInto(out *PostUpgradeHookStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostUpgradeHookStatus.
+func (in *PostUpgradeHookStatus) DeepCopy() *PostUpgradeHookStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostUpgradeHookStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PreparedPlanCache) DeepCopyInto(out *PreparedPlanCache) {
 	*out = *in
@@ -3981,6 +4602,25 @@ func (in *PumpStatus) DeepCopyInto(out *PumpStatus) {
 			}
 		}
 	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make(map[string]StorageVolumeStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.StorageUsage != nil {
+		in, out := &in.StorageUsage, &out.StorageUsage
+		*out = new(StorageUsage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ComponentCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -4113,6 +4753,79 @@ func (in *RemoteWriteSpec) DeepCopy() *RemoteWriteSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationPromotionStatus) DeepCopyInto(out *ReplicationPromotionStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationPromotionStatus.
+func (in *ReplicationPromotionStatus) DeepCopy() *ReplicationPromotionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationPromotionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestartSchedule) DeepCopyInto(out *RestartSchedule) {
+	*out = *in
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestartSchedule.
+func (in *RestartSchedule) DeepCopy() *RestartSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(RestartSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestartScheduleStatus) DeepCopyInto(out *RestartScheduleStatus) {
+	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.WindowEndTime != nil {
+		in, out := &in.WindowEndTime, &out.WindowEndTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastCompletionTime != nil {
+		in, out := &in.LastCompletionTime, &out.LastCompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestartScheduleStatus.
+func (in *RestartScheduleStatus) DeepCopy() *RestartScheduleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RestartScheduleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Restore) DeepCopyInto(out *Restore) {
 	*out = *in
@@ -4288,9 +5001,32 @@ func (in *RestoreStatus) DeepCopy() *RestoreStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetainedPVCStatus) DeepCopyInto(out *RetainedPVCStatus) {
+	*out = *in
+	in.DeferDeletingTime.DeepCopyInto(&out.DeferDeletingTime)
+	in.DeletionTime.DeepCopyInto(&out.DeletionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetainedPVCStatus.
+func (in *RetainedPVCStatus) DeepCopy() *RetainedPVCStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RetainedPVCStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *S3StorageProvider) DeepCopyInto(out *S3StorageProvider) {
 	*out = *in
+	if in.ExternalSecret != nil {
+		in, out := &in.ExternalSecret, &out.ExternalSecret
+		*out = new(ExternalSecretRef)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Options != nil {
 		in, out := &in.Options, &out.Options
 		*out = make([]string, len(*in))
@@ -4532,6 +5268,28 @@ func (in *Status) DeepCopy() *Status {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SimulatedFailover) DeepCopyInto(out *SimulatedFailover) {
+	*out = *in
+	if in.FailurePods != nil {
+		in, out := &in.FailurePods, &out.FailurePods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SimulatedFailover.
+func (in *SimulatedFailover) DeepCopy() *SimulatedFailover {
+	if in == nil {
+		return nil
+	}
+	out := new(SimulatedFailover)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StmtSummary) DeepCopyInto(out *StmtSummary) {
 	*out = *in
@@ -4611,7 +5369,7 @@ func (in *StorageProvider) DeepCopyInto(out *StorageProvider) {
 	if in.Gcs != nil {
 		in, out := &in.Gcs, &out.Gcs
 		*out = new(GcsStorageProvider)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Local != nil {
 		in, out := &in.Local, &out.Local
@@ -4631,6 +5389,24 @@ func (in *StorageProvider) DeepCopy() *StorageProvider {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageUsage) DeepCopyInto(out *StorageUsage) {
+	*out = *in
+	out.Capacity = in.Capacity.DeepCopy()
+	out.Used = in.Used.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageUsage.
+func (in *StorageUsage) DeepCopy() *StorageUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageVolume) DeepCopyInto(out *StorageVolume) {
 	*out = *in
@@ -4653,144 +5429,410 @@ func (in *StorageVolume) DeepCopy() *StorageVolume {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TLSCluster) DeepCopyInto(out *TLSCluster) {
+func (in *StorageVolumeStatus) DeepCopyInto(out *StorageVolumeStatus) {
 	*out = *in
+	out.CurrentCapacity = in.CurrentCapacity.DeepCopy()
+	out.DesiredCapacity = in.DesiredCapacity.DeepCopy()
+	if in.UsedCapacity != nil {
+		in, out := &in.UsedCapacity, &out.UsedCapacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.AvailableCapacity != nil {
+		in, out := &in.AvailableCapacity, &out.AvailableCapacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSCluster.
-func (in *TLSCluster) DeepCopy() *TLSCluster {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageVolumeStatus.
+func (in *StorageVolumeStatus) DeepCopy() *StorageVolumeStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(TLSCluster)
+	out := new(StorageVolumeStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TLSConfig) DeepCopyInto(out *TLSConfig) {
+func (in *SuspendStatus) DeepCopyInto(out *SuspendStatus) {
 	*out = *in
-	in.SafeTLSConfig.DeepCopyInto(&out.SafeTLSConfig)
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]SuspendedComponent, len(*in))
+		copy(*out, *in)
+	}
+	if in.SuspendTime != nil {
+		in, out := &in.SuspendTime, &out.SuspendTime
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSConfig.
-func (in *TLSConfig) DeepCopy() *TLSConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SuspendStatus.
+func (in *SuspendStatus) DeepCopy() *SuspendStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(TLSConfig)
+	out := new(SuspendStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ThanosSpec) DeepCopyInto(out *ThanosSpec) {
+func (in *SuspendedComponent) DeepCopyInto(out *SuspendedComponent) {
 	*out = *in
-	in.MonitorContainer.DeepCopyInto(&out.MonitorContainer)
-	if in.ObjectStorageConfig != nil {
-		in, out := &in.ObjectStorageConfig, &out.ObjectStorageConfig
-		*out = new(v1.SecretKeySelector)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.ObjectStorageConfigFile != nil {
-		in, out := &in.ObjectStorageConfigFile, &out.ObjectStorageConfigFile
-		*out = new(string)
-		**out = **in
-	}
-	if in.TracingConfig != nil {
-		in, out := &in.TracingConfig, &out.TracingConfig
-		*out = new(v1.SecretKeySelector)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.TracingConfigFile != nil {
-		in, out := &in.TracingConfigFile, &out.TracingConfigFile
-		*out = new(string)
-		**out = **in
-	}
-	if in.GRPCServerTLSConfig != nil {
-		in, out := &in.GRPCServerTLSConfig, &out.GRPCServerTLSConfig
-		*out = new(TLSConfig)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.AdditionalVolumeMounts != nil {
-		in, out := &in.AdditionalVolumeMounts, &out.AdditionalVolumeMounts
-		*out = make([]v1.VolumeMount, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosSpec.
-func (in *ThanosSpec) DeepCopy() *ThanosSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SuspendedComponent.
+func (in *SuspendedComponent) DeepCopy() *SuspendedComponent {
 	if in == nil {
 		return nil
 	}
-	out := new(ThanosSpec)
+	out := new(SuspendedComponent)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TiCDCCapture) DeepCopyInto(out *TiCDCCapture) {
+func (in *TLSCABundle) DeepCopyInto(out *TLSCABundle) {
 	*out = *in
+	if in.ReloadDelay != nil {
+		in, out := &in.ReloadDelay, &out.ReloadDelay
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiCDCCapture.
-func (in *TiCDCCapture) DeepCopy() *TiCDCCapture {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSCABundle.
+func (in *TLSCABundle) DeepCopy() *TLSCABundle {
 	if in == nil {
 		return nil
 	}
-	out := new(TiCDCCapture)
+	out := new(TLSCABundle)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TiCDCConfig) DeepCopyInto(out *TiCDCConfig) {
+func (in *TLSCABundleStatus) DeepCopyInto(out *TLSCABundleStatus) {
 	*out = *in
-	if in.Timezone != nil {
-		in, out := &in.Timezone, &out.Timezone
-		*out = new(string)
-		**out = **in
-	}
-	if in.GCTTL != nil {
-		in, out := &in.GCTTL, &out.GCTTL
-		*out = new(int32)
-		**out = **in
+	if in.CAs != nil {
+		in, out := &in.CAs, &out.CAs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.LogLevel != nil {
-		in, out := &in.LogLevel, &out.LogLevel
-		*out = new(string)
-		**out = **in
+	if in.PendingComponents != nil {
+		in, out := &in.PendingComponents, &out.PendingComponents
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.LogFile != nil {
-		in, out := &in.LogFile, &out.LogFile
-		*out = new(string)
-		**out = **in
+	if in.LastPropagationTime != nil {
+		in, out := &in.LastPropagationTime, &out.LastPropagationTime
+		*out = (*in).DeepCopy()
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiCDCConfig.
-func (in *TiCDCConfig) DeepCopy() *TiCDCConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSCABundleStatus.
+func (in *TLSCABundleStatus) DeepCopy() *TLSCABundleStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(TiCDCConfig)
+	out := new(TLSCABundleStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TiCDCSpec) DeepCopyInto(out *TiCDCSpec) {
+func (in *TLSCertManager) DeepCopyInto(out *TLSCertManager) {
 	*out = *in
-	in.ComponentSpec.DeepCopyInto(&out.ComponentSpec)
-	in.ResourceRequirements.DeepCopyInto(&out.ResourceRequirements)
+	out.IssuerRef = in.IssuerRef
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSCertManager.
+func (in *TLSCertManager) DeepCopy() *TLSCertManager {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSCertManager)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSCertRotation) DeepCopyInto(out *TLSCertRotation) {
+	*out = *in
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSCertRotation.
+func (in *TLSCertRotation) DeepCopy() *TLSCertRotation {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSCertRotation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSCertStatus) DeepCopyInto(out *TLSCertStatus) {
+	*out = *in
+	in.NotAfter.DeepCopyInto(&out.NotAfter)
+	if in.LastRotationTime != nil {
+		in, out := &in.LastRotationTime, &out.LastRotationTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSCertStatus.
+func (in *TLSCertStatus) DeepCopy() *TLSCertStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSCertStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSCluster) DeepCopyInto(out *TLSCluster) {
+	*out = *in
+	if in.CertRotation != nil {
+		in, out := &in.CertRotation, &out.CertRotation
+		*out = new(TLSCertRotation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CertManager != nil {
+		in, out := &in.CertManager, &out.CertManager
+		*out = new(TLSCertManager)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Vault != nil {
+		in, out := &in.Vault, &out.Vault
+		*out = new(TLSVault)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Policy != nil {
+		in, out := &in.Policy, &out.Policy
+		*out = new(TLSPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = new(TLSCABundle)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSCluster.
+func (in *TLSCluster) DeepCopy() *TLSCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSConfig) DeepCopyInto(out *TLSConfig) {
+	*out = *in
+	in.SafeTLSConfig.DeepCopyInto(&out.SafeTLSConfig)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSConfig.
+func (in *TLSConfig) DeepCopy() *TLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSPolicy) DeepCopyInto(out *TLSPolicy) {
+	*out = *in
+	if in.CipherSuites != nil {
+		in, out := &in.CipherSuites, &out.CipherSuites
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSPolicy.
+func (in *TLSPolicy) DeepCopy() *TLSPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSVault) DeepCopyInto(out *TLSVault) {
+	*out = *in
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.KubernetesAuth != nil {
+		in, out := &in.KubernetesAuth, &out.KubernetesAuth
+		*out = new(VaultKubernetesAuth)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSVault.
+func (in *TLSVault) DeepCopy() *TLSVault {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSVault)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThanosSpec) DeepCopyInto(out *ThanosSpec) {
+	*out = *in
+	in.MonitorContainer.DeepCopyInto(&out.MonitorContainer)
+	if in.ObjectStorageConfig != nil {
+		in, out := &in.ObjectStorageConfig, &out.ObjectStorageConfig
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ObjectStorageConfigFile != nil {
+		in, out := &in.ObjectStorageConfigFile, &out.ObjectStorageConfigFile
+		*out = new(string)
+		**out = **in
+	}
+	if in.TracingConfig != nil {
+		in, out := &in.TracingConfig, &out.TracingConfig
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TracingConfigFile != nil {
+		in, out := &in.TracingConfigFile, &out.TracingConfigFile
+		*out = new(string)
+		**out = **in
+	}
+	if in.GRPCServerTLSConfig != nil {
+		in, out := &in.GRPCServerTLSConfig, &out.GRPCServerTLSConfig
+		*out = new(TLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdditionalVolumeMounts != nil {
+		in, out := &in.AdditionalVolumeMounts, &out.AdditionalVolumeMounts
+		*out = make([]v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThanosSpec.
+func (in *ThanosSpec) DeepCopy() *ThanosSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ThanosSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiCDCCapture) DeepCopyInto(out *TiCDCCapture) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiCDCCapture.
+func (in *TiCDCCapture) DeepCopy() *TiCDCCapture {
+	if in == nil {
+		return nil
+	}
+	out := new(TiCDCCapture)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiCDCConfig) DeepCopyInto(out *TiCDCConfig) {
+	*out = *in
+	if in.Timezone != nil {
+		in, out := &in.Timezone, &out.Timezone
+		*out = new(string)
+		**out = **in
+	}
+	if in.GCTTL != nil {
+		in, out := &in.GCTTL, &out.GCTTL
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LogLevel != nil {
+		in, out := &in.LogLevel, &out.LogLevel
+		*out = new(string)
+		**out = **in
+	}
+	if in.LogFile != nil {
+		in, out := &in.LogFile, &out.LogFile
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiCDCConfig.
+func (in *TiCDCConfig) DeepCopy() *TiCDCConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TiCDCConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiCDCSpec) DeepCopyInto(out *TiCDCSpec) {
+	*out = *in
+	in.ComponentSpec.DeepCopyInto(&out.ComponentSpec)
+	in.ResourceRequirements.DeepCopyInto(&out.ResourceRequirements)
 	if in.TLSClientSecretNames != nil {
 		in, out := &in.TLSClientSecretNames, &out.TLSClientSecretNames
 		*out = make([]string, len(*in))
@@ -4841,6 +5883,25 @@ func (in *TiCDCStatus) DeepCopyInto(out *TiCDCStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make(map[string]StorageVolumeStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ComponentCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UpgradeProgress != nil {
+		in, out := &in.UpgradeProgress, &out.UpgradeProgress
+		*out = new(UpgradeProgress)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -4862,6 +5923,11 @@ func (in *TiDBAccessConfig) DeepCopyInto(out *TiDBAccessConfig) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.ExternalSecret != nil {
+		in, out := &in.ExternalSecret, &out.ExternalSecret
+		*out = new(ExternalSecretRef)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -5139,26 +6205,66 @@ func (in *TiDBConfigWraper) DeepCopy() *TiDBConfigWraper {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TiDBFailureMember) DeepCopyInto(out *TiDBFailureMember) {
+func (in *TiDBFailoverProbe) DeepCopyInto(out *TiDBFailoverProbe) {
 	*out = *in
-	in.CreatedAt.DeepCopyInto(&out.CreatedAt)
+	if in.PasswordSecret != nil {
+		in, out := &in.PasswordSecret, &out.PasswordSecret
+		*out = new(string)
+		**out = **in
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailurePeriod != nil {
+		in, out := &in.FailurePeriod, &out.FailurePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiDBFailureMember.
-func (in *TiDBFailureMember) DeepCopy() *TiDBFailureMember {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiDBFailoverProbe.
+func (in *TiDBFailoverProbe) DeepCopy() *TiDBFailoverProbe {
 	if in == nil {
 		return nil
 	}
-	out := new(TiDBFailureMember)
+	out := new(TiDBFailoverProbe)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TiDBMember) DeepCopyInto(out *TiDBMember) {
+func (in *TiDBFailureMember) DeepCopyInto(out *TiDBFailureMember) {
+	*out = *in
+	in.CreatedAt.DeepCopyInto(&out.CreatedAt)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiDBFailureMember.
+func (in *TiDBFailureMember) DeepCopy() *TiDBFailureMember {
+	if in == nil {
+		return nil
+	}
+	out := new(TiDBFailureMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiDBMember) DeepCopyInto(out *TiDBMember) {
 	*out = *in
 	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	if in.Info != nil {
+		in, out := &in.Info, &out.Info
+		*out = new(TiDBMemberInfo)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SQLProbeFailingSince != nil {
+		in, out := &in.SQLProbeFailingSince, &out.SQLProbeFailingSince
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -5172,6 +6278,26 @@ func (in *TiDBMember) DeepCopy() *TiDBMember {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiDBMemberInfo) DeepCopyInto(out *TiDBMemberInfo) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiDBMemberInfo.
+func (in *TiDBMemberInfo) DeepCopy() *TiDBMemberInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(TiDBMemberInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiDBProbe) DeepCopyInto(out *TiDBProbe) {
 	*out = *in
@@ -5282,6 +6408,11 @@ func (in *TiDBSpec) DeepCopyInto(out *TiDBSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.FailoverProbe != nil {
+		in, out := &in.FailoverProbe, &out.FailoverProbe
+		*out = new(TiDBFailoverProbe)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.SeparateSlowLog != nil {
 		in, out := &in.SeparateSlowLog, &out.SeparateSlowLog
 		*out = new(bool)
@@ -5295,7 +6426,7 @@ func (in *TiDBSpec) DeepCopyInto(out *TiDBSpec) {
 	if in.TLSClient != nil {
 		in, out := &in.TLSClient, &out.TLSClient
 		*out = new(TiDBTLSClient)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Plugins != nil {
 		in, out := &in.Plugins, &out.Plugins
@@ -5329,6 +6460,11 @@ func (in *TiDBSpec) DeepCopyInto(out *TiDBSpec) {
 		*out = new(TiDBProbe)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DeleteSlots != nil {
+		in, out := &in.DeleteSlots, &out.DeleteSlots
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -5364,6 +6500,25 @@ func (in *TiDBStatus) DeepCopyInto(out *TiDBStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make(map[string]StorageVolumeStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ComponentCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UpgradeProgress != nil {
+		in, out := &in.UpgradeProgress, &out.UpgradeProgress
+		*out = new(UpgradeProgress)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -5380,6 +6535,11 @@ func (in *TiDBStatus) DeepCopy() *TiDBStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiDBTLSClient) DeepCopyInto(out *TiDBTLSClient) {
 	*out = *in
+	if in.Policy != nil {
+		in, out := &in.Policy, &out.Policy
+		*out = new(TLSPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -5517,6 +6677,11 @@ func (in *TiFlashSpec) DeepCopyInto(out *TiFlashSpec) {
 		*out = new(LogTailerSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DeleteSlots != nil {
+		in, out := &in.DeleteSlots, &out.DeleteSlots
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -5566,6 +6731,30 @@ func (in *TiFlashStatus) DeepCopyInto(out *TiFlashStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make(map[string]StorageVolumeStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.StorageUsage != nil {
+		in, out := &in.StorageUsage, &out.StorageUsage
+		*out = new(StorageUsage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ComponentCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UpgradeProgress != nil {
+		in, out := &in.UpgradeProgress, &out.UpgradeProgress
+		*out = new(UpgradeProgress)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -6322,6 +7511,28 @@ func (in *TiKVDbConfig) DeepCopy() *TiKVDbConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiKVEncryption) DeepCopyInto(out *TiKVEncryption) {
+	*out = *in
+	if in.DataKeyRotationPeriod != nil {
+		in, out := &in.DataKeyRotationPeriod, &out.DataKeyRotationPeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	out.MasterKey = in.MasterKey
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiKVEncryption.
+func (in *TiKVEncryption) DeepCopy() *TiKVEncryption {
+	if in == nil {
+		return nil
+	}
+	out := new(TiKVEncryption)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiKVEncryptionConfig) DeepCopyInto(out *TiKVEncryptionConfig) {
 	*out = *in
@@ -6358,6 +7569,36 @@ func (in *TiKVEncryptionConfig) DeepCopy() *TiKVEncryptionConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiKVEncryptionStatus) DeepCopyInto(out *TiKVEncryptionStatus) {
+	*out = *in
+	if in.MasterKey != nil {
+		in, out := &in.MasterKey, &out.MasterKey
+		*out = new(TiKVKMSMasterKey)
+		**out = **in
+	}
+	if in.PreviousMasterKey != nil {
+		in, out := &in.PreviousMasterKey, &out.PreviousMasterKey
+		*out = new(TiKVKMSMasterKey)
+		**out = **in
+	}
+	if in.LastRotationTime != nil {
+		in, out := &in.LastRotationTime, &out.LastRotationTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiKVEncryptionStatus.
+func (in *TiKVEncryptionStatus) DeepCopy() *TiKVEncryptionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TiKVEncryptionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiKVFailureStore) DeepCopyInto(out *TiKVFailureStore) {
 	*out = *in
@@ -6472,6 +7713,22 @@ func (in *TiKVImportConfig) DeepCopy() *TiKVImportConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TiKVKMSMasterKey) DeepCopyInto(out *TiKVKMSMasterKey) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TiKVKMSMasterKey.
+func (in *TiKVKMSMasterKey) DeepCopy() *TiKVKMSMasterKey {
+	if in == nil {
+		return nil
+	}
+	out := new(TiKVKMSMasterKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TiKVMasterKeyConfig) DeepCopyInto(out *TiKVMasterKeyConfig) {
 	*out = *in
@@ -7321,6 +8578,11 @@ func (in *TiKVSpec) DeepCopyInto(out *TiKVSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.FailoverRecovery != nil {
+		in, out := &in.FailoverRecovery, &out.FailoverRecovery
+		*out = new(FailoverRecoveryPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.SeparateRocksDBLog != nil {
 		in, out := &in.SeparateRocksDBLog, &out.SeparateRocksDBLog
 		*out = new(bool)
@@ -7368,6 +8630,16 @@ func (in *TiKVSpec) DeepCopyInto(out *TiKVSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.DeleteSlots != nil {
+		in, out := &in.DeleteSlots, &out.DeleteSlots
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(TiKVEncryption)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -7417,6 +8689,35 @@ func (in *TiKVStatus) DeepCopyInto(out *TiKVStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make(map[string]StorageVolumeStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.StorageUsage != nil {
+		in, out := &in.StorageUsage, &out.StorageUsage
+		*out = new(StorageUsage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(TiKVEncryptionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ComponentCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UpgradeProgress != nil {
+		in, out := &in.UpgradeProgress, &out.UpgradeProgress
+		*out = new(UpgradeProgress)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -7541,6 +8842,8 @@ func (in *TiKVStorageReadPoolConfig) DeepCopy() *TiKVStorageReadPoolConfig {
 func (in *TiKVStore) DeepCopyInto(out *TiKVStore) {
 	*out = *in
 	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	out.Capacity = in.Capacity.DeepCopy()
+	out.Available = in.Available.DeepCopy()
 	return
 }
 
@@ -7709,55 +9012,538 @@ func (in *TidbAutoScalerSpec) DeepCopyInto(out *TidbAutoScalerSpec) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbAutoScalerSpec.
-func (in *TidbAutoScalerSpec) DeepCopy() *TidbAutoScalerSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbAutoScalerSpec.
+func (in *TidbAutoScalerSpec) DeepCopy() *TidbAutoScalerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbAutoScalerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbAutoScalerStatus) DeepCopyInto(out *TidbAutoScalerStatus) {
+	*out = *in
+	in.BasicAutoScalerStatus.DeepCopyInto(&out.BasicAutoScalerStatus)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbAutoScalerStatus.
+func (in *TidbAutoScalerStatus) DeepCopy() *TidbAutoScalerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbAutoScalerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbCluster) DeepCopyInto(out *TidbCluster) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbCluster.
+func (in *TidbCluster) DeepCopy() *TidbCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TidbCluster) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterAutoScaler) DeepCopyInto(out *TidbClusterAutoScaler) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterAutoScaler.
+func (in *TidbClusterAutoScaler) DeepCopy() *TidbClusterAutoScaler {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterAutoScaler)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TidbClusterAutoScaler) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterAutoScalerList) DeepCopyInto(out *TidbClusterAutoScalerList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TidbClusterAutoScaler, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterAutoScalerList.
+func (in *TidbClusterAutoScalerList) DeepCopy() *TidbClusterAutoScalerList {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterAutoScalerList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TidbClusterAutoScalerList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterAutoScalerRef) DeepCopyInto(out *TidbClusterAutoScalerRef) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterAutoScalerRef.
+func (in *TidbClusterAutoScalerRef) DeepCopy() *TidbClusterAutoScalerRef {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterAutoScalerRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterAutoScalerSpec) DeepCopyInto(out *TidbClusterAutoScalerSpec) {
+	*out = *in
+	out.Cluster = in.Cluster
+	if in.TiKV != nil {
+		in, out := &in.TiKV, &out.TiKV
+		*out = new(TikvAutoScalerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TiDB != nil {
+		in, out := &in.TiDB, &out.TiDB
+		*out = new(TidbAutoScalerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterAutoScalerSpec.
+func (in *TidbClusterAutoScalerSpec) DeepCopy() *TidbClusterAutoScalerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterAutoScalerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterAutoScalerStatus) DeepCopyInto(out *TidbClusterAutoScalerStatus) {
+	*out = *in
+	if in.TiKV != nil {
+		in, out := &in.TiKV, &out.TiKV
+		*out = make(map[string]TikvAutoScalerStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.TiDB != nil {
+		in, out := &in.TiDB, &out.TiDB
+		*out = make(map[string]TidbAutoScalerStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.TiKVReplicas != nil {
+		in, out := &in.TiKVReplicas, &out.TiKVReplicas
+		*out = new(AutoScalerReplicasStatus)
+		**out = **in
+	}
+	if in.TiDBReplicas != nil {
+		in, out := &in.TiDBReplicas, &out.TiDBReplicas
+		*out = new(AutoScalerReplicasStatus)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterAutoScalerStatus.
+func (in *TidbClusterAutoScalerStatus) DeepCopy() *TidbClusterAutoScalerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterAutoScalerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterClone) DeepCopyInto(out *TidbClusterClone) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterClone.
+func (in *TidbClusterClone) DeepCopy() *TidbClusterClone {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterClone)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TidbClusterClone) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterCloneList) DeepCopyInto(out *TidbClusterCloneList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TidbClusterClone, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterCloneList.
+func (in *TidbClusterCloneList) DeepCopy() *TidbClusterCloneList {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterCloneList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TidbClusterCloneList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterCloneSpec) DeepCopyInto(out *TidbClusterCloneSpec) {
+	*out = *in
+	out.Source = in.Source
+	in.Target.DeepCopyInto(&out.Target)
+	in.Backup.DeepCopyInto(&out.Backup)
+	if in.Masks != nil {
+		in, out := &in.Masks, &out.Masks
+		*out = make([]ColumnMask, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterCloneSpec.
+func (in *TidbClusterCloneSpec) DeepCopy() *TidbClusterCloneSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterCloneSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterCloneStatus) DeepCopyInto(out *TidbClusterCloneStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterCloneStatus.
+func (in *TidbClusterCloneStatus) DeepCopy() *TidbClusterCloneStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterCloneStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterCloneTarget) DeepCopyInto(out *TidbClusterCloneTarget) {
+	*out = *in
+	if in.PDReplicas != nil {
+		in, out := &in.PDReplicas, &out.PDReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TiKVReplicas != nil {
+		in, out := &in.TiKVReplicas, &out.TiKVReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TiFlashReplicas != nil {
+		in, out := &in.TiFlashReplicas, &out.TiFlashReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TiDBReplicas != nil {
+		in, out := &in.TiDBReplicas, &out.TiDBReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterCloneTarget.
+func (in *TidbClusterCloneTarget) DeepCopy() *TidbClusterCloneTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterCloneTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterCondition) DeepCopyInto(out *TidbClusterCondition) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterCondition.
+func (in *TidbClusterCondition) DeepCopy() *TidbClusterCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterFederation) DeepCopyInto(out *TidbClusterFederation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterFederation.
+func (in *TidbClusterFederation) DeepCopy() *TidbClusterFederation {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterFederation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TidbClusterFederation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterFederationList) DeepCopyInto(out *TidbClusterFederationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TidbClusterFederation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterFederationList.
+func (in *TidbClusterFederationList) DeepCopy() *TidbClusterFederationList {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterFederationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TidbClusterFederationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterFederationMember) DeepCopyInto(out *TidbClusterFederationMember) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterFederationMember.
+func (in *TidbClusterFederationMember) DeepCopy() *TidbClusterFederationMember {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterFederationMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterFederationMemberStatus) DeepCopyInto(out *TidbClusterFederationMemberStatus) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterFederationMemberStatus.
+func (in *TidbClusterFederationMemberStatus) DeepCopy() *TidbClusterFederationMemberStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterFederationMemberStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterFederationSpec) DeepCopyInto(out *TidbClusterFederationSpec) {
+	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]TidbClusterFederationMember, len(*in))
+		copy(*out, *in)
+	}
+	if in.Paused != nil {
+		in, out := &in.Paused, &out.Paused
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterFederationSpec.
+func (in *TidbClusterFederationSpec) DeepCopy() *TidbClusterFederationSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(TidbAutoScalerSpec)
+	out := new(TidbClusterFederationSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TidbAutoScalerStatus) DeepCopyInto(out *TidbAutoScalerStatus) {
+func (in *TidbClusterFederationStatus) DeepCopyInto(out *TidbClusterFederationStatus) {
 	*out = *in
-	in.BasicAutoScalerStatus.DeepCopyInto(&out.BasicAutoScalerStatus)
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]TidbClusterFederationMemberStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbAutoScalerStatus.
-func (in *TidbAutoScalerStatus) DeepCopy() *TidbAutoScalerStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterFederationStatus.
+func (in *TidbClusterFederationStatus) DeepCopy() *TidbClusterFederationStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(TidbAutoScalerStatus)
+	out := new(TidbClusterFederationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TidbCluster) DeepCopyInto(out *TidbCluster) {
+func (in *TidbClusterList) DeepCopyInto(out *TidbClusterList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]TidbCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbCluster.
-func (in *TidbCluster) DeepCopy() *TidbCluster {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterList.
+func (in *TidbClusterList) DeepCopy() *TidbClusterList {
 	if in == nil {
 		return nil
 	}
-	out := new(TidbCluster)
+	out := new(TidbClusterList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *TidbCluster) DeepCopyObject() runtime.Object {
+func (in *TidbClusterList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -7765,7 +9551,23 @@ func (in *TidbCluster) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TidbClusterAutoScaler) DeepCopyInto(out *TidbClusterAutoScaler) {
+func (in *TidbClusterRef) DeepCopyInto(out *TidbClusterRef) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterRef.
+func (in *TidbClusterRef) DeepCopy() *TidbClusterRef {
+	if in == nil {
+		return nil
+	}
+	out := new(TidbClusterRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TidbClusterReplication) DeepCopyInto(out *TidbClusterReplication) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -7774,18 +9576,18 @@ func (in *TidbClusterAutoScaler) DeepCopyInto(out *TidbClusterAutoScaler) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterAutoScaler.
-func (in *TidbClusterAutoScaler) DeepCopy() *TidbClusterAutoScaler {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterReplication.
+func (in *TidbClusterReplication) DeepCopy() *TidbClusterReplication {
 	if in == nil {
 		return nil
 	}
-	out := new(TidbClusterAutoScaler)
+	out := new(TidbClusterReplication)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *TidbClusterAutoScaler) DeepCopyObject() runtime.Object {
+func (in *TidbClusterReplication) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -7793,13 +9595,13 @@ func (in *TidbClusterAutoScaler) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TidbClusterAutoScalerList) DeepCopyInto(out *TidbClusterAutoScalerList) {
+func (in *TidbClusterReplicationList) DeepCopyInto(out *TidbClusterReplicationList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]TidbClusterAutoScaler, len(*in))
+		*out = make([]TidbClusterReplication, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -7807,18 +9609,18 @@ func (in *TidbClusterAutoScalerList) DeepCopyInto(out *TidbClusterAutoScalerList
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterAutoScalerList.
-func (in *TidbClusterAutoScalerList) DeepCopy() *TidbClusterAutoScalerList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterReplicationList.
+func (in *TidbClusterReplicationList) DeepCopy() *TidbClusterReplicationList {
 	if in == nil {
 		return nil
 	}
-	out := new(TidbClusterAutoScalerList)
+	out := new(TidbClusterReplicationList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *TidbClusterAutoScalerList) DeepCopyObject() runtime.Object {
+func (in *TidbClusterReplicationList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -7826,141 +9628,131 @@ func (in *TidbClusterAutoScalerList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TidbClusterAutoScalerRef) DeepCopyInto(out *TidbClusterAutoScalerRef) {
+func (in *TidbClusterReplicationSecondary) DeepCopyInto(out *TidbClusterReplicationSecondary) {
 	*out = *in
+	if in.TLSClientSecretName != nil {
+		in, out := &in.TLSClientSecretName, &out.TLSClientSecretName
+		*out = new(string)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterAutoScalerRef.
-func (in *TidbClusterAutoScalerRef) DeepCopy() *TidbClusterAutoScalerRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterReplicationSecondary.
+func (in *TidbClusterReplicationSecondary) DeepCopy() *TidbClusterReplicationSecondary {
 	if in == nil {
 		return nil
 	}
-	out := new(TidbClusterAutoScalerRef)
+	out := new(TidbClusterReplicationSecondary)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TidbClusterAutoScalerSpec) DeepCopyInto(out *TidbClusterAutoScalerSpec) {
+func (in *TidbClusterReplicationSpec) DeepCopyInto(out *TidbClusterReplicationSpec) {
 	*out = *in
-	out.Cluster = in.Cluster
-	if in.TiKV != nil {
-		in, out := &in.TiKV, &out.TiKV
-		*out = new(TikvAutoScalerSpec)
-		(*in).DeepCopyInto(*out)
+	out.Primary = in.Primary
+	in.Secondary.DeepCopyInto(&out.Secondary)
+	if in.FilterRules != nil {
+		in, out := &in.FilterRules, &out.FilterRules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.TiDB != nil {
-		in, out := &in.TiDB, &out.TiDB
-		*out = new(TidbAutoScalerSpec)
-		(*in).DeepCopyInto(*out)
+	if in.MaxLag != nil {
+		in, out := &in.MaxLag, &out.MaxLag
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.PromotionTimeout != nil {
+		in, out := &in.PromotionTimeout, &out.PromotionTimeout
+		*out = new(metav1.Duration)
+		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterAutoScalerSpec.
-func (in *TidbClusterAutoScalerSpec) DeepCopy() *TidbClusterAutoScalerSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterReplicationSpec.
+func (in *TidbClusterReplicationSpec) DeepCopy() *TidbClusterReplicationSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(TidbClusterAutoScalerSpec)
+	out := new(TidbClusterReplicationSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TidbClusterAutoScalerStatus) DeepCopyInto(out *TidbClusterAutoScalerStatus) {
+func (in *TidbClusterReplicationStatus) DeepCopyInto(out *TidbClusterReplicationStatus) {
 	*out = *in
-	if in.TiKV != nil {
-		in, out := &in.TiKV, &out.TiKV
-		*out = make(map[string]TikvAutoScalerStatus, len(*in))
-		for key, val := range *in {
-			(*out)[key] = *val.DeepCopy()
-		}
-	}
-	if in.TiDB != nil {
-		in, out := &in.TiDB, &out.TiDB
-		*out = make(map[string]TidbAutoScalerStatus, len(*in))
-		for key, val := range *in {
-			(*out)[key] = *val.DeepCopy()
-		}
+	if in.CheckpointTime != nil {
+		in, out := &in.CheckpointTime, &out.CheckpointTime
+		*out = (*in).DeepCopy()
 	}
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterAutoScalerStatus.
-func (in *TidbClusterAutoScalerStatus) DeepCopy() *TidbClusterAutoScalerStatus {
-	if in == nil {
-		return nil
+	if in.Lag != nil {
+		in, out := &in.Lag, &out.Lag
+		*out = new(metav1.Duration)
+		**out = **in
 	}
-	out := new(TidbClusterAutoScalerStatus)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TidbClusterCondition) DeepCopyInto(out *TidbClusterCondition) {
-	*out = *in
-	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
 	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	if in.Promotion != nil {
+		in, out := &in.Promotion, &out.Promotion
+		*out = new(ReplicationPromotionStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterCondition.
-func (in *TidbClusterCondition) DeepCopy() *TidbClusterCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterReplicationStatus.
+func (in *TidbClusterReplicationStatus) DeepCopy() *TidbClusterReplicationStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(TidbClusterCondition)
+	out := new(TidbClusterReplicationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TidbClusterList) DeepCopyInto(out *TidbClusterList) {
+func (in *TidbClusterRestoreSpec) DeepCopyInto(out *TidbClusterRestoreSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]TidbCluster, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.RestoreSpec != nil {
+		in, out := &in.RestoreSpec, &out.RestoreSpec
+		*out = new(RestoreSpec)
+		(*in).DeepCopyInto(*out)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterList.
-func (in *TidbClusterList) DeepCopy() *TidbClusterList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterRestoreSpec.
+func (in *TidbClusterRestoreSpec) DeepCopy() *TidbClusterRestoreSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(TidbClusterList)
+	out := new(TidbClusterRestoreSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *TidbClusterList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TidbClusterRef) DeepCopyInto(out *TidbClusterRef) {
+func (in *TidbClusterRestoreStatus) DeepCopyInto(out *TidbClusterRestoreStatus) {
 	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterRef.
-func (in *TidbClusterRef) DeepCopy() *TidbClusterRef {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TidbClusterRestoreStatus.
+func (in *TidbClusterRestoreStatus) DeepCopy() *TidbClusterRestoreStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(TidbClusterRef)
+	out := new(TidbClusterRestoreStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -8019,10 +9811,20 @@ func (in *TidbClusterSpec) DeepCopyInto(out *TidbClusterSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.PVCRetentionPeriod != nil {
+		in, out := &in.PVCRetentionPeriod, &out.PVCRetentionPeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.TombstoneStoreRetentionPeriod != nil {
+		in, out := &in.TombstoneStoreRetentionPeriod, &out.TombstoneStoreRetentionPeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	if in.TLSCluster != nil {
 		in, out := &in.TLSCluster, &out.TLSCluster
 		*out = new(TLSCluster)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.HostNetwork != nil {
 		in, out := &in.HostNetwork, &out.HostNetwork
@@ -8097,6 +9899,23 @@ func (in *TidbClusterSpec) DeepCopyInto(out *TidbClusterSpec) {
 		*out = make([]TopologySpreadConstraint, len(*in))
 		copy(*out, *in)
 	}
+	if in.PostUpgradeHook != nil {
+		in, out := &in.PostUpgradeHook, &out.PostUpgradeHook
+		*out = new(PostUpgradeHookSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Restore != nil {
+		in, out := &in.Restore, &out.Restore
+		*out = new(TidbClusterRestoreSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RestartSchedules != nil {
+		in, out := &in.RestartSchedules, &out.RestartSchedules
+		*out = make([]RestartSchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -8117,6 +9936,7 @@ func (in *TidbClusterStatus) DeepCopyInto(out *TidbClusterStatus) {
 	in.TiKV.DeepCopyInto(&out.TiKV)
 	in.TiDB.DeepCopyInto(&out.TiDB)
 	in.Pump.DeepCopyInto(&out.Pump)
+	in.Drainer.DeepCopyInto(&out.Drainer)
 	in.TiFlash.DeepCopyInto(&out.TiFlash)
 	in.TiCDC.DeepCopyInto(&out.TiCDC)
 	if in.AutoScaler != nil {
@@ -8124,6 +9944,76 @@ func (in *TidbClusterStatus) DeepCopyInto(out *TidbClusterStatus) {
 		*out = new(TidbClusterAutoScalerRef)
 		**out = **in
 	}
+	if in.RetainedPVCs != nil {
+		in, out := &in.RetainedPVCs, &out.RetainedPVCs
+		*out = make(map[string]RetainedPVCStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.TLSCerts != nil {
+		in, out := &in.TLSCerts, &out.TLSCerts
+		*out = make(map[string]TLSCertStatus, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.TLSCABundle != nil {
+		in, out := &in.TLSCABundle, &out.TLSCABundle
+		*out = new(TLSCABundleStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SimulatedFailovers != nil {
+		in, out := &in.SimulatedFailovers, &out.SimulatedFailovers
+		*out = make(map[MemberType]SimulatedFailover, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Operations != nil {
+		in, out := &in.Operations, &out.Operations
+		*out = make([]OperationRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PendingChanges != nil {
+		in, out := &in.PendingChanges, &out.PendingChanges
+		*out = make([]PendingChange, len(*in))
+		copy(*out, *in)
+	}
+	if in.Federation != nil {
+		in, out := &in.Federation, &out.Federation
+		*out = new(FederationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Adoption != nil {
+		in, out := &in.Adoption, &out.Adoption
+		*out = new(AdoptionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PostUpgradeHook != nil {
+		in, out := &in.PostUpgradeHook, &out.PostUpgradeHook
+		*out = new(PostUpgradeHookStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Suspend != nil {
+		in, out := &in.Suspend, &out.Suspend
+		*out = new(SuspendStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RestartSchedules != nil {
+		in, out := &in.RestartSchedules, &out.RestartSchedules
+		*out = make([]RestartScheduleStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Restore != nil {
+		in, out := &in.Restore, &out.Restore
+		*out = new(TidbClusterRestoreStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]TidbClusterCondition, len(*in))
@@ -8239,11 +10129,23 @@ func (in *TidbInitializerSpec) DeepCopyInto(out *TidbInitializerSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.InitSqlScripts != nil {
+		in, out := &in.InitSqlScripts, &out.InitSqlScripts
+		*out = make([]InitSqlScript, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.PasswordSecret != nil {
 		in, out := &in.PasswordSecret, &out.PasswordSecret
 		*out = new(string)
 		**out = **in
 	}
+	if in.PasswordExternalSecret != nil {
+		in, out := &in.PasswordExternalSecret, &out.PasswordExternalSecret
+		*out = new(ExternalSecretRef)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
 		*out = new(v1.ResourceRequirements)
@@ -8254,6 +10156,21 @@ func (in *TidbInitializerSpec) DeepCopyInto(out *TidbInitializerSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.MaxRetries != nil {
+		in, out := &in.MaxRetries, &out.MaxRetries
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RetryBackoff != nil {
+		in, out := &in.RetryBackoff, &out.RetryBackoff
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	return
 }
 
@@ -8271,6 +10188,22 @@ func (in *TidbInitializerSpec) DeepCopy() *TidbInitializerSpec {
 func (in *TidbInitializerStatus) DeepCopyInto(out *TidbInitializerStatus) {
 	*out = *in
 	in.JobStatus.DeepCopyInto(&out.JobStatus)
+	if in.Scripts != nil {
+		in, out := &in.Scripts, &out.Scripts
+		*out = make([]InitSqlScriptStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Attempts != nil {
+		in, out := &in.Attempts, &out.Attempts
+		*out = make([]InitializerAttempt, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -8620,6 +10553,27 @@ func (in *UnjoinedMember) DeepCopy() *UnjoinedMember {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeProgress) DeepCopyInto(out *UpgradeProgress) {
+	*out = *in
+	if in.CurrentOrdinal != nil {
+		in, out := &in.CurrentOrdinal, &out.CurrentOrdinal
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeProgress.
+func (in *UpgradeProgress) DeepCopy() *UpgradeProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *User) DeepCopyInto(out *User) {
 	*out = *in
@@ -8651,6 +10605,22 @@ func (in *User) DeepCopy() *User {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultKubernetesAuth) DeepCopyInto(out *VaultKubernetesAuth) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultKubernetesAuth.
+func (in *VaultKubernetesAuth) DeepCopy() *VaultKubernetesAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultKubernetesAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkerConfig) DeepCopyInto(out *WorkerConfig) {
 	*out = *in
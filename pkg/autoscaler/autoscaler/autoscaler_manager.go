@@ -74,9 +74,38 @@ func (am *autoScalerManager) Sync(tac *v1alpha1.TidbClusterAutoScaler) error {
 		return err
 	}
 
+	if err := am.syncReplicasStatus(tc, updatedTac); err != nil {
+		return err
+	}
+
+	updatedTac.Status.ObservedGeneration = tac.Generation
 	return am.updateTidbClusterAutoScaler(updatedTac)
 }
 
+// syncReplicasStatus sums up the replicas of the auto-scaled components over the target cluster and the
+// auto-scaled clusters into the status
+func (am *autoScalerManager) syncReplicasStatus(tc *v1alpha1.TidbCluster, tac *v1alpha1.TidbClusterAutoScaler) error {
+	tac.Status.TiKVReplicas = nil
+	tac.Status.TiDBReplicas = nil
+	for _, component := range []v1alpha1.MemberType{v1alpha1.TiKVMemberType, v1alpha1.TiDBMemberType} {
+		if (component == v1alpha1.TiKVMemberType && tac.Spec.TiKV == nil) ||
+			(component == v1alpha1.TiDBMemberType && tac.Spec.TiDB == nil) {
+			continue
+		}
+		tcList, err := am.getAutoScaledClusters(tac, []v1alpha1.MemberType{component})
+		if err != nil {
+			return err
+		}
+		replicas := sumReplicas(append([]*v1alpha1.TidbCluster{tc}, tcList...), component)
+		if component == v1alpha1.TiKVMemberType {
+			tac.Status.TiKVReplicas = replicas
+		} else {
+			tac.Status.TiDBReplicas = replicas
+		}
+	}
+	return nil
+}
+
 func (am *autoScalerManager) syncExternal(tc *v1alpha1.TidbCluster, tac *v1alpha1.TidbClusterAutoScaler, component v1alpha1.MemberType) error {
 	var cfg *v1alpha1.ExternalConfig
 	switch component {
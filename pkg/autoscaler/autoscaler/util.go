@@ -471,3 +471,29 @@ func newAutoScalingCluster(tc *v1alpha1.TidbCluster, tac *v1alpha1.TidbClusterAu
 
 	return autoTc
 }
+
+// sumReplicas sums up the ready and the desired replicas of the component over the clusters
+func sumReplicas(tcs []*v1alpha1.TidbCluster, component v1alpha1.MemberType) *v1alpha1.AutoScalerReplicasStatus {
+	replicas := &v1alpha1.AutoScalerReplicasStatus{}
+	for _, tc := range tcs {
+		switch component {
+		case v1alpha1.TiKVMemberType:
+			if tc.Spec.TiKV == nil {
+				continue
+			}
+			replicas.Target += tc.Spec.TiKV.Replicas
+			if tc.Status.TiKV.StatefulSet != nil {
+				replicas.Current += tc.Status.TiKV.StatefulSet.ReadyReplicas
+			}
+		case v1alpha1.TiDBMemberType:
+			if tc.Spec.TiDB == nil {
+				continue
+			}
+			replicas.Target += tc.Spec.TiDB.Replicas
+			if tc.Status.TiDB.StatefulSet != nil {
+				replicas.Current += tc.Status.TiDB.StatefulSet.ReadyReplicas
+			}
+		}
+	}
+	return replicas
+}
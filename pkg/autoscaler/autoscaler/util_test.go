@@ -20,6 +20,7 @@ import (
 
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -458,6 +459,26 @@ func TestAutoscalerToStrategy(t *testing.T) {
 	g.Expect(len(tikvStrategy.Rules)).Should(Equal(1))
 }
 
+func TestSumReplicas(t *testing.T) {
+	g := NewGomegaWithT(t)
+	tc := newTidbCluster()
+	tc.Spec.TiKV.Replicas = 3
+	tc.Spec.TiDB.Replicas = 2
+	tc.Status.TiKV.StatefulSet = &appsv1.StatefulSetStatus{ReadyReplicas: 3}
+	tc.Status.TiDB.StatefulSet = &appsv1.StatefulSetStatus{ReadyReplicas: 1}
+
+	autoTc := newTidbCluster()
+	autoTc.Name = "auto-tc"
+	autoTc.Spec.TiKV.Replicas = 2
+	autoTc.Spec.TiDB = nil
+
+	tikvReplicas := sumReplicas([]*v1alpha1.TidbCluster{tc, autoTc}, v1alpha1.TiKVMemberType)
+	g.Expect(*tikvReplicas).Should(Equal(v1alpha1.AutoScalerReplicasStatus{Current: 3, Target: 5}))
+
+	tidbReplicas := sumReplicas([]*v1alpha1.TidbCluster{tc, autoTc}, v1alpha1.TiDBMemberType)
+	g.Expect(*tidbReplicas).Should(Equal(v1alpha1.AutoScalerReplicasStatus{Current: 1, Target: 2}))
+}
+
 func TestValidateTidbClusterAutoScaler(t *testing.T) {
 	g := NewGomegaWithT(t)
 	minThreshold := 0.1
@@ -119,6 +119,11 @@ func (bc *backupCleaner) makeCleanJob(backup *v1alpha1.Backup) (*batchv1.Job, st
 	if err != nil {
 		return nil, reason, err
 	}
+	externalSecretEnv, err := backuputil.GenerateExternalSecretEnv(backup.Spec.StorageProvider, nil)
+	if err != nil {
+		return nil, "GenerateExternalSecretEnvFailed", err
+	}
+	envVars = append(envVars, externalSecretEnv...)
 
 	// set env vars specified in backup.Spec.Env
 	envVars = util.AppendOverwriteEnv(envVars, backup.Spec.Env)
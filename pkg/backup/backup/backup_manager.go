@@ -36,19 +36,26 @@ type backupManager struct {
 	deps          *controller.Dependencies
 	backupCleaner BackupCleaner
 	statusUpdater controller.BackupConditionUpdaterInterface
+	// newMemberClient returns the client of the Kubernetes cluster a member of a federated backup lives in
+	newMemberClient controller.FederationMemberClientFunc
 }
 
 // NewBackupManager return backupManager
 func NewBackupManager(deps *controller.Dependencies) backup.BackupManager {
 	statusUpdater := controller.NewRealBackupConditionUpdater(deps.Clientset, deps.BackupLister, deps.Recorder)
 	return &backupManager{
-		deps:          deps,
-		backupCleaner: NewBackupCleaner(deps, statusUpdater),
-		statusUpdater: statusUpdater,
+		deps:            deps,
+		backupCleaner:   NewBackupCleaner(deps, statusUpdater),
+		statusUpdater:   statusUpdater,
+		newMemberClient: controller.NewFederationMemberClient,
 	}
 }
 
 func (bm *backupManager) Sync(backup *v1alpha1.Backup) error {
+	if backup.Spec.Federation != nil && backup.Spec.BR != nil {
+		return bm.syncFederatedBackup(backup)
+	}
+
 	// because a finalizer is installed on the backup on creation, when backup is deleted,
 	// backup.DeletionTimestamp will be set, controller will be informed with an onUpdate event,
 	// this is the moment that we can do clean up work.
@@ -192,6 +199,12 @@ func (bm *backupManager) makeExportJob(backup *v1alpha1.Backup) (*batchv1.Job, s
 	}
 	envVars = append(envVars, storageEnv...)
 
+	externalSecretEnv, err := backuputil.GenerateExternalSecretEnv(backup.Spec.StorageProvider, backup.Spec.From)
+	if err != nil {
+		return nil, "GenerateExternalSecretEnvFailed", fmt.Errorf("backup %s/%s, %v", ns, name, err)
+	}
+	envVars = append(envVars, externalSecretEnv...)
+
 	// set env vars specified in backup.Spec.Env
 	envVars = util.AppendOverwriteEnv(envVars, backup.Spec.Env)
 
@@ -360,6 +373,13 @@ func (bm *backupManager) makeBackupJob(backup *v1alpha1.Backup) (*batchv1.Job, s
 	}
 
 	envVars = append(envVars, storageEnv...)
+
+	externalSecretEnv, err := backuputil.GenerateExternalSecretEnv(backup.Spec.StorageProvider, backup.Spec.From)
+	if err != nil {
+		return nil, "GenerateExternalSecretEnvFailed", fmt.Errorf("backup %s/%s, %v", ns, name, err)
+	}
+	envVars = append(envVars, externalSecretEnv...)
+
 	envVars = append(envVars, corev1.EnvVar{
 		Name:  "BR_LOG_TO_TERM",
 		Value: string(rune(1)),
@@ -24,6 +24,8 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/backup/testutils"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
@@ -240,6 +242,84 @@ func TestBackupManagerBR(t *testing.T) {
 	}
 }
 
+func TestBackupManagerFederation(t *testing.T) {
+	g := NewGomegaWithT(t)
+	helper := newHelper(t)
+	defer helper.Close()
+	deps := helper.Deps
+
+	bm := NewBackupManager(deps).(*backupManager)
+
+	backup := genValidBRBackups()[0]
+	backup.Spec.Federation = &v1alpha1.BackupFederationSpec{
+		Members: []v1alpha1.TidbClusterFederationMember{
+			{Name: "tidb_0", Namespace: "ns1"},
+			{Name: "tidb_1", Namespace: "ns2"},
+		},
+	}
+	_, err := deps.Clientset.PingcapV1alpha1().Backups(backup.Namespace).Create(backup)
+	g.Expect(err).Should(BeNil())
+	helper.CreateTC(backup.Spec.BR.ClusterNamespace, backup.Spec.BR.Cluster)
+	tc, err := deps.Clientset.PingcapV1alpha1().TidbClusters(backup.Spec.BR.ClusterNamespace).Get(backup.Spec.BR.Cluster, metav1.GetOptions{})
+	g.Expect(err).Should(BeNil())
+	// the fake pd client is only used without tls
+	tc.Spec.TLSCluster = nil
+	_, err = deps.Clientset.PingcapV1alpha1().TidbClusters(tc.Namespace).Update(tc)
+	g.Expect(err).Should(BeNil())
+	g.Eventually(func() bool {
+		tc, err := deps.TiDBClusterLister.TidbClusters(tc.Namespace).Get(tc.Name)
+		return err == nil && !tc.IsTLSClusterEnabled()
+	}, time.Second*10).Should(BeTrue())
+
+	// failed to get the min resolved ts
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	pdClient.AddReaction(pdapi.GetMinResolvedTSActionType, func(action *pdapi.Action) (interface{}, error) {
+		return nil, fmt.Errorf("min resolved ts is not enabled")
+	})
+	err = bm.Sync(backup)
+	g.Expect(err).ShouldNot(BeNil())
+	helper.hasCondition(backup.Namespace, backup.Name, v1alpha1.BackupRetryFailed, "GetMinResolvedTSFailed")
+
+	// the backups of the members are created at the same ts
+	pdClient.AddReaction(pdapi.GetMinResolvedTSActionType, func(action *pdapi.Action) (interface{}, error) {
+		return uint64(424242), nil
+	})
+	err = bm.Sync(backup)
+	g.Expect(controller.IsRequeueError(err)).Should(BeTrue())
+	helper.hasCondition(backup.Namespace, backup.Name, v1alpha1.BackupRunning, "")
+	for _, member := range backup.Spec.Federation.Members {
+		memberBackup, err := deps.Clientset.PingcapV1alpha1().Backups(member.Namespace).Get(backup.Name+"-"+member.Name, metav1.GetOptions{})
+		g.Expect(err).Should(BeNil())
+		g.Expect(memberBackup.Annotations[label.AnnFederatedBackup]).Should(Equal(backup.Namespace + "/" + backup.Name))
+		g.Expect(memberBackup.Spec.Federation).Should(BeNil())
+		g.Expect(memberBackup.Spec.BR.Cluster).Should(Equal(member.Name))
+		g.Expect(memberBackup.Spec.BR.ClusterNamespace).Should(Equal(member.Namespace))
+		g.Expect(memberBackup.Spec.BR.BackupTS).Should(Equal("424242"))
+		g.Expect(memberBackup.Spec.S3.Prefix).Should(HaveSuffix("/" + member.Name))
+	}
+
+	// complete after the backups of all the members are complete
+	backup, err = deps.Clientset.PingcapV1alpha1().Backups(backup.Namespace).Get(backup.Name, metav1.GetOptions{})
+	g.Expect(err).Should(BeNil())
+	g.Expect(backup.Status.Federation.BackupTS).Should(Equal("424242"))
+	for _, member := range backup.Spec.Federation.Members {
+		memberBackup, err := deps.Clientset.PingcapV1alpha1().Backups(member.Namespace).Get(backup.Name+"-"+member.Name, metav1.GetOptions{})
+		g.Expect(err).Should(BeNil())
+		memberBackup.Status.Phase = v1alpha1.BackupComplete
+		memberBackup.Status.BackupSize = 1024
+		_, err = deps.Clientset.PingcapV1alpha1().Backups(member.Namespace).Update(memberBackup)
+		g.Expect(err).Should(BeNil())
+	}
+	g.Eventually(func() error {
+		return bm.Sync(backup)
+	}, time.Second*10).Should(BeNil())
+	helper.hasCondition(backup.Namespace, backup.Name, v1alpha1.BackupComplete, "")
+	backup, err = deps.Clientset.PingcapV1alpha1().Backups(backup.Namespace).Get(backup.Name, metav1.GetOptions{})
+	g.Expect(err).Should(BeNil())
+	g.Expect(backup.Status.BackupSize).Should(Equal(int64(2048)))
+	g.Expect(backup.Status.Federation.Members).Should(HaveLen(2))
+}
+
 func TestClean(t *testing.T) {
 	g := NewGomegaWithT(t)
 	helper := newHelper(t)
@@ -0,0 +1,274 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog"
+)
+
+// syncFederatedBackup backs up the members of a federated backup at the same TS.
+//
+// The min resolved TS is got from PD of `spec.br.cluster` once and kept in the status, then a Backup
+// at the TS is created for each member in its namespace of the Kubernetes cluster it lives in. The
+// federated backup is complete when all the member backups are complete, and failed once any of them
+// fails, so the member backups of a complete federated backup are a consistent restore set.
+func (bm *backupManager) syncFederatedBackup(backup *v1alpha1.Backup) error {
+	if backup.DeletionTimestamp != nil {
+		if v1alpha1.IsCleanCandidate(backup) {
+			// the data of the members is cleaned by the member backups with the same clean policy
+			if err := bm.deleteMemberBackups(backup); err != nil {
+				return err
+			}
+		}
+		return bm.backupCleaner.Clean(backup)
+	}
+
+	ns := backup.GetNamespace()
+	name := backup.GetName()
+	backupNamespace := ns
+	if backup.Spec.BR.ClusterNamespace != "" {
+		backupNamespace = backup.Spec.BR.ClusterNamespace
+	}
+	tc, err := bm.deps.TiDBClusterLister.TidbClusters(backupNamespace).Get(backup.Spec.BR.Cluster)
+	if err != nil {
+		bm.statusUpdater.Update(backup, &v1alpha1.BackupCondition{
+			Type:    v1alpha1.BackupRetryFailed,
+			Status:  corev1.ConditionTrue,
+			Reason:  fmt.Sprintf("failed to fetch tidbcluster %s/%s", backupNamespace, backup.Spec.BR.Cluster),
+			Message: err.Error(),
+		}, nil)
+		return err
+	}
+	if err := backuputil.ValidateBackup(backup, tc.TiKVImage()); err != nil {
+		bm.statusUpdater.Update(backup, &v1alpha1.BackupCondition{
+			Type:    v1alpha1.BackupInvalid,
+			Status:  corev1.ConditionTrue,
+			Reason:  "InvalidSpec",
+			Message: err.Error(),
+		}, nil)
+		return controller.IgnoreErrorf("invalid backup spec %s/%s cause %s", ns, name, err.Error())
+	}
+
+	status := backup.Status.Federation
+	if status == nil || status.BackupTS == "" {
+		ts, err := controller.GetPDClient(bm.deps.PDControl, tc).GetMinResolvedTS()
+		if err != nil {
+			bm.statusUpdater.Update(backup, &v1alpha1.BackupCondition{
+				Type:    v1alpha1.BackupRetryFailed,
+				Status:  corev1.ConditionTrue,
+				Reason:  "GetMinResolvedTSFailed",
+				Message: err.Error(),
+			}, nil)
+			return fmt.Errorf("backup %s/%s get min resolved ts from tidbcluster %s/%s failed, err: %v", ns, name, tc.GetNamespace(), tc.GetName(), err)
+		}
+		status = &v1alpha1.BackupFederationStatus{BackupTS: strconv.FormatUint(ts, 10)}
+		klog.Infof("backup %s/%s backs up the members at ts %s", ns, name, status.BackupTS)
+		now := metav1.Now()
+		if err := bm.statusUpdater.Update(backup, &v1alpha1.BackupCondition{
+			Type:   v1alpha1.BackupRunning,
+			Status: corev1.ConditionTrue,
+		}, &controller.BackupUpdateStatus{
+			TimeStarted: &now,
+			CommitTs:    &status.BackupTS,
+			Federation:  status,
+		}); err != nil {
+			return err
+		}
+	}
+
+	status = status.DeepCopy()
+	status.Members = nil
+	var errs []error
+	var failed []string
+	complete := true
+	var size int64
+	for _, member := range backup.Spec.Federation.Members {
+		memberStatus, memberSize, err := bm.syncMemberBackup(backup, member, status.BackupTS)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		switch memberStatus.Phase {
+		case v1alpha1.BackupComplete:
+			size += memberSize
+		case v1alpha1.BackupFailed, v1alpha1.BackupInvalid:
+			failed = append(failed, fmt.Sprintf("%s/%s", memberStatus.Namespace, memberStatus.Name))
+			complete = false
+		default:
+			complete = false
+		}
+		status.Members = append(status.Members, memberStatus)
+	}
+
+	newStatus := &controller.BackupUpdateStatus{Federation: status}
+	var condition *v1alpha1.BackupCondition
+	switch {
+	case len(failed) > 0:
+		now := metav1.Now()
+		newStatus.TimeCompleted = &now
+		condition = &v1alpha1.BackupCondition{
+			Type:    v1alpha1.BackupFailed,
+			Status:  corev1.ConditionTrue,
+			Reason:  "MemberBackupFailed",
+			Message: fmt.Sprintf("the backups of members %s failed", strings.Join(failed, ",")),
+		}
+	case complete:
+		now := metav1.Now()
+		sizeReadable := humanize.Bytes(uint64(size))
+		newStatus.TimeCompleted = &now
+		newStatus.BackupSize = &size
+		newStatus.BackupSizeReadable = &sizeReadable
+		condition = &v1alpha1.BackupCondition{
+			Type:   v1alpha1.BackupComplete,
+			Status: corev1.ConditionTrue,
+		}
+	default:
+		condition = &v1alpha1.BackupCondition{
+			Type:   v1alpha1.BackupRunning,
+			Status: corev1.ConditionTrue,
+		}
+	}
+	if err := bm.statusUpdater.Update(backup, condition, newStatus); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return errorutils.NewAggregate(errs)
+	}
+	if condition.Type == v1alpha1.BackupRunning {
+		// the member backups may live in other Kubernetes clusters, so they are polled instead of watched
+		return controller.RequeueErrorf("backup %s/%s: waiting for the backups of the members", ns, name)
+	}
+	return nil
+}
+
+// syncMemberBackup creates the backup of the member if it does not exist, and returns its state and size
+func (bm *backupManager) syncMemberBackup(backup *v1alpha1.Backup, member v1alpha1.TidbClusterFederationMember, backupTS string) (v1alpha1.BackupFederationMemberStatus, int64, error) {
+	ns := backup.GetNamespace()
+	memberNamespace := controller.FederationMemberNamespace(ns, member)
+	status := v1alpha1.BackupFederationMemberStatus{
+		Name:       member.Name,
+		Namespace:  memberNamespace,
+		BackupName: memberBackupName(backup, member),
+	}
+	cli, err := bm.newMemberClient(bm.deps, ns, member)
+	if err != nil {
+		status.Message = fmt.Sprintf("failed to get the client of the member: %v", err)
+		return status, 0, fmt.Errorf("backup %s/%s get the client of member %s/%s failed, err: %v", ns, backup.GetName(), memberNamespace, member.Name, err)
+	}
+	backupClient := cli.PingcapV1alpha1().Backups(memberNamespace)
+	memberBackup, err := backupClient.Get(status.BackupName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		memberBackup, err = backupClient.Create(newMemberBackup(backup, member, memberNamespace, backupTS))
+		if err == nil {
+			klog.Infof("backup %s/%s created backup %s/%s for member %s at ts %s", ns, backup.GetName(), memberNamespace, status.BackupName, member.Name, backupTS)
+		}
+	}
+	if err != nil {
+		status.Message = fmt.Sprintf("failed to sync the backup of the member: %v", err)
+		return status, 0, fmt.Errorf("backup %s/%s sync backup %s/%s of member %s failed, err: %v", ns, backup.GetName(), memberNamespace, status.BackupName, member.Name, err)
+	}
+
+	status.Phase = memberBackup.Status.Phase
+	status.BackupPath = memberBackup.Status.BackupPath
+	if status.Phase == v1alpha1.BackupFailed || status.Phase == v1alpha1.BackupInvalid {
+		if _, cond := v1alpha1.GetBackupCondition(&memberBackup.Status, status.Phase); cond != nil {
+			status.Message = cond.Message
+		}
+	}
+	return status, memberBackup.Status.BackupSize, nil
+}
+
+// deleteMemberBackups deletes the backups of the members created for the federated backup
+func (bm *backupManager) deleteMemberBackups(backup *v1alpha1.Backup) error {
+	ns := backup.GetNamespace()
+	var errs []error
+	for _, member := range backup.Spec.Federation.Members {
+		memberNamespace := controller.FederationMemberNamespace(ns, member)
+		memberBackupName := memberBackupName(backup, member)
+		cli, err := bm.newMemberClient(bm.deps, ns, member)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		backupClient := cli.PingcapV1alpha1().Backups(memberNamespace)
+		memberBackup, err := backupClient.Get(memberBackupName, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if memberBackup.Annotations[label.AnnFederatedBackup] != fmt.Sprintf("%s/%s", ns, backup.GetName()) {
+			// not created for the federated backup
+			continue
+		}
+		if err := backupClient.Delete(memberBackupName, &metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, err)
+			continue
+		}
+		klog.Infof("backup %s/%s deleted backup %s/%s of member %s", ns, backup.GetName(), memberNamespace, memberBackupName, member.Name)
+	}
+	return errorutils.NewAggregate(errs)
+}
+
+// newMemberBackup returns the backup of the member at the TS, the data is saved under the member name
+func newMemberBackup(backup *v1alpha1.Backup, member v1alpha1.TidbClusterFederationMember, memberNamespace, backupTS string) *v1alpha1.Backup {
+	spec := backup.Spec.DeepCopy()
+	spec.Federation = nil
+	spec.BR.Cluster = member.Name
+	spec.BR.ClusterNamespace = memberNamespace
+	spec.BR.BackupTS = backupTS
+	switch {
+	case spec.S3 != nil:
+		spec.S3.Prefix = path.Join(spec.S3.Prefix, member.Name)
+	case spec.Gcs != nil:
+		spec.Gcs.Prefix = path.Join(spec.Gcs.Prefix, member.Name)
+	case spec.Local != nil:
+		spec.Local.Prefix = path.Join(spec.Local.Prefix, member.Name)
+	}
+
+	labels := map[string]string{}
+	for k, v := range backup.Labels {
+		labels[k] = v
+	}
+	return &v1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      memberBackupName(backup, member),
+			Namespace: memberNamespace,
+			Labels:    labels,
+			Annotations: map[string]string{
+				label.AnnFederatedBackup: fmt.Sprintf("%s/%s", backup.GetNamespace(), backup.GetName()),
+			},
+		},
+		Spec: *spec,
+	}
+}
+
+func memberBackupName(backup *v1alpha1.Backup, member v1alpha1.TidbClusterFederationMember) string {
+	return fmt.Sprintf("%s-%s", backup.GetName(), member.Name)
+}
@@ -195,6 +195,28 @@ func buildBackup(bs *v1alpha1.BackupSchedule, timestamp time.Time) *v1alpha1.Bac
 	ns := bs.GetNamespace()
 	bsName := bs.GetName()
 
+	bsLabel := util.CombineStringMap(label.NewBackupSchedule().Instance(bsName).BackupSchedule(bsName), bs.Labels)
+	backup := &v1alpha1.Backup{
+		Spec: BuildBackupSpec(bs, timestamp),
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   ns,
+			Name:        bs.GetBackupCRDName(timestamp),
+			Labels:      bsLabel,
+			Annotations: bs.Annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				controller.GetBackupScheduleOwnerRef(bs),
+			},
+		},
+	}
+
+	return backup
+}
+
+// BuildBackupSpec builds the spec of a backup taken at timestamp from the template of the backup schedule,
+// the backups taken by BR are saved under a prefix unique to the cluster and the timestamp.
+func BuildBackupSpec(bs *v1alpha1.BackupSchedule, timestamp time.Time) v1alpha1.BackupSpec {
+	ns := bs.GetNamespace()
+
 	backupSpec := *bs.Spec.BackupTemplate.DeepCopy()
 	if backupSpec.BR == nil {
 		if backupSpec.StorageClassName == nil || *backupSpec.StorageClassName == "" {
@@ -230,21 +252,7 @@ func buildBackup(bs *v1alpha1.BackupSchedule, timestamp time.Time) *v1alpha1.Bac
 		backupSpec.ImagePullSecrets = bs.Spec.ImagePullSecrets
 	}
 
-	bsLabel := util.CombineStringMap(label.NewBackupSchedule().Instance(bsName).BackupSchedule(bsName), bs.Labels)
-	backup := &v1alpha1.Backup{
-		Spec: backupSpec,
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace:   ns,
-			Name:        bs.GetBackupCRDName(timestamp),
-			Labels:      bsLabel,
-			Annotations: bs.Annotations,
-			OwnerReferences: []metav1.OwnerReference{
-				controller.GetBackupScheduleOwnerRef(bs),
-			},
-		},
-	}
-
-	return backup
+	return backupSpec
 }
 
 func createBackup(bkController controller.BackupControlInterface, bs *v1alpha1.BackupSchedule, timestamp time.Time) (*v1alpha1.Backup, error) {
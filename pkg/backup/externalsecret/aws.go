@@ -0,0 +1,51 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package externalsecret
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+)
+
+// awsFetcher fetches the secrets from AWS Secrets Manager with the default credential chain,
+// e.g. the IAM role of the service account
+type awsFetcher struct{}
+
+func (f *awsFetcher) Fetch(ref *v1alpha1.ExternalSecretRef) (map[string]string, error) {
+	awsConfig := aws.NewConfig()
+	if ref.Region != "" {
+		awsConfig.WithRegion(ref.Region)
+	}
+	ses, err := session.NewSessionWithOptions(session.Options{
+		Config:            *awsConfig,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out, err := secretsmanager.New(ses).GetSecretValue(&secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(ref.Path),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out.SecretString == nil {
+		return nil, fmt.Errorf("secret %s is binary, only the secret string is supported", ref.Path)
+	}
+	return parseSecret([]byte(*out.SecretString))
+}
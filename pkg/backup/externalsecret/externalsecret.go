@@ -0,0 +1,181 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package externalsecret
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// EnvVarName is the env of backup-manager which stores the bindings of the external secrets
+	EnvVarName = "BACKUP_MANAGER_EXTERNAL_SECRETS"
+)
+
+// Binding binds the keys of an external secret to the envs of backup-manager
+type Binding struct {
+	Ref v1alpha1.ExternalSecretRef `json:"ref"`
+	// Envs maps the keys of the secret to the names of the envs, all the keys
+	// of the secret are fetched if it is empty
+	Envs map[string]string `json:"envs,omitempty"`
+}
+
+// Fetcher fetches the data of the secret from an external secret manager
+type Fetcher interface {
+	Fetch(ref *v1alpha1.ExternalSecretRef) (map[string]string, error)
+}
+
+// NewFetcher returns the Fetcher of the provider
+func NewFetcher(provider v1alpha1.ExternalSecretProvider) (Fetcher, error) {
+	switch provider {
+	case v1alpha1.ExternalSecretProviderAWS:
+		return &awsFetcher{}, nil
+	case v1alpha1.ExternalSecretProviderGCP:
+		return newGCPFetcher(), nil
+	case v1alpha1.ExternalSecretProviderVault:
+		return newVaultFetcher(), nil
+	default:
+		return nil, fmt.Errorf("unsupported external secret provider %q", provider)
+	}
+}
+
+// EnvVar returns the env which passes the bindings to backup-manager
+func EnvVar(bindings []Binding) (corev1.EnvVar, error) {
+	data, err := json.Marshal(bindings)
+	if err != nil {
+		return corev1.EnvVar{}, err
+	}
+	return corev1.EnvVar{Name: EnvVarName, Value: string(data)}, nil
+}
+
+// LoadBindings loads the bindings from the env, nil is returned if the env is not set
+func LoadBindings() ([]Binding, error) {
+	data := os.Getenv(EnvVarName)
+	if data == "" {
+		return nil, nil
+	}
+	var bindings []Binding
+	if err := json.Unmarshal([]byte(data), &bindings); err != nil {
+		return nil, fmt.Errorf("failed to parse env %s, error: %v", EnvVarName, err)
+	}
+	return bindings, nil
+}
+
+// Resolve fetches the secrets of the bindings and returns the values of the envs
+func Resolve(bindings []Binding, newFetcher func(v1alpha1.ExternalSecretProvider) (Fetcher, error)) (map[string]string, error) {
+	envs := map[string]string{}
+	for i := range bindings {
+		binding := &bindings[i]
+		data, err := fetch(&binding.Ref, newFetcher)
+		if err != nil {
+			return nil, err
+		}
+		for key, env := range binding.Envs {
+			value, ok := data[key]
+			if !ok {
+				return nil, fmt.Errorf("no key %s is found in secret %s of %s", key, binding.Ref.Path, binding.Ref.Provider)
+			}
+			envs[env] = value
+		}
+	}
+	return envs, nil
+}
+
+// SetEnvs fetches the secrets of the bindings in the env and sets them into the envs of the process
+func SetEnvs() error {
+	bindings, err := LoadBindings()
+	if err != nil || len(bindings) == 0 {
+		return err
+	}
+	envs, err := Resolve(bindings, NewFetcher)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(envs))
+	for name := range envs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := os.Setenv(name, envs[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteFiles fetches the secrets of the bindings and writes each key of them into a file
+// of the same name in dir, just like the keys of a secret volume
+func WriteFiles(bindings []Binding, dir string, newFetcher func(v1alpha1.ExternalSecretProvider) (Fetcher, error)) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for i := range bindings {
+		data, err := fetch(&bindings[i].Ref, newFetcher)
+		if err != nil {
+			return err
+		}
+		for key, value := range data {
+			if key != filepath.Base(key) || key == "." || key == ".." {
+				return fmt.Errorf("invalid key %q in secret %s", key, bindings[i].Ref.Path)
+			}
+			if err := ioutil.WriteFile(filepath.Join(dir, key), []byte(value), 0600); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func fetch(ref *v1alpha1.ExternalSecretRef, newFetcher func(v1alpha1.ExternalSecretProvider) (Fetcher, error)) (map[string]string, error) {
+	fetcher, err := newFetcher(ref.Provider)
+	if err != nil {
+		return nil, err
+	}
+	data, err := fetcher.Fetch(ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch secret %s from %s, error: %v", ref.Path, ref.Provider, err)
+	}
+	return data, nil
+}
+
+// parseSecret parses the secret string of the secret managers which is a JSON object,
+// the values which are not strings are kept in JSON, e.g. the credentials of GCS
+func parseSecret(secret []byte) (map[string]string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(secret, &raw); err != nil {
+		return nil, fmt.Errorf("the secret is not a JSON object, error: %v", err)
+	}
+	return toStrings(raw)
+}
+
+func toStrings(raw map[string]json.RawMessage) (map[string]string, error) {
+	data := make(map[string]string, len(raw))
+	for key, value := range raw {
+		var s string
+		if err := json.Unmarshal(value, &s); err == nil {
+			data[key] = s
+			continue
+		}
+		data[key] = string(value)
+	}
+	return data, nil
+}
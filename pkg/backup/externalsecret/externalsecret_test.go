@@ -0,0 +1,211 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package externalsecret
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/vaultapi"
+)
+
+type fakeFetcher map[string]map[string]string
+
+func (f fakeFetcher) Fetch(ref *v1alpha1.ExternalSecretRef) (map[string]string, error) {
+	data, ok := f[ref.Path]
+	if !ok {
+		return nil, fmt.Errorf("secret %s not found", ref.Path)
+	}
+	return data, nil
+}
+
+func TestResolve(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fetcher := fakeFetcher{
+		"s3":   {"access_key": "ak", "secret_key": "sk"},
+		"tidb": {"password": "pass"},
+	}
+	newFetcher := func(v1alpha1.ExternalSecretProvider) (Fetcher, error) { return fetcher, nil }
+	bindings := []Binding{
+		{
+			Ref:  v1alpha1.ExternalSecretRef{Provider: v1alpha1.ExternalSecretProviderAWS, Path: "s3"},
+			Envs: map[string]string{"access_key": "AWS_ACCESS_KEY_ID", "secret_key": "AWS_SECRET_ACCESS_KEY"},
+		},
+		{
+			Ref:  v1alpha1.ExternalSecretRef{Provider: v1alpha1.ExternalSecretProviderVault, Path: "tidb"},
+			Envs: map[string]string{"password": "BACKUP_MANAGER_PASSWORD"},
+		},
+	}
+	envs, err := Resolve(bindings, newFetcher)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(envs).To(Equal(map[string]string{
+		"AWS_ACCESS_KEY_ID":       "ak",
+		"AWS_SECRET_ACCESS_KEY":   "sk",
+		"BACKUP_MANAGER_PASSWORD": "pass",
+	}))
+
+	bindings[1].Envs = map[string]string{"passwd": "BACKUP_MANAGER_PASSWORD"}
+	_, err = Resolve(bindings, newFetcher)
+	g.Expect(err).To(MatchError("no key passwd is found in secret tidb of vault"))
+
+	bindings[1].Ref.Path = "unknown"
+	_, err = Resolve(bindings, newFetcher)
+	g.Expect(err).To(HaveOccurred())
+
+	// the bindings are passed by the env
+	env, err := EnvVar(bindings)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(os.Setenv(env.Name, env.Value)).To(Succeed())
+	defer os.Unsetenv(env.Name)
+	loaded, err := LoadBindings()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(loaded).To(Equal(bindings))
+}
+
+func TestWriteFiles(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	dir, err := ioutil.TempDir("", "externalsecret")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	fetcher := fakeFetcher{"users": {"root": "pass", "app": "app-pass"}}
+	newFetcher := func(v1alpha1.ExternalSecretProvider) (Fetcher, error) { return fetcher, nil }
+	bindings := []Binding{{Ref: v1alpha1.ExternalSecretRef{Path: "users"}}}
+	g.Expect(WriteFiles(bindings, dir, newFetcher)).To(Succeed())
+	for user, password := range fetcher["users"] {
+		data, err := ioutil.ReadFile(filepath.Join(dir, user))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(string(data)).To(Equal(password))
+	}
+
+	// the keys can not escape from the directory
+	fetcher["users"] = map[string]string{"../root": "pass"}
+	g.Expect(WriteFiles(bindings, dir, newFetcher)).NotTo(Succeed())
+}
+
+func TestParseSecret(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	data, err := parseSecret([]byte(`{"password": "pass", "credentials": {"type": "service_account"}}`))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(data).To(Equal(map[string]string{
+		"password":    "pass",
+		"credentials": `{"type": "service_account"}`,
+	}))
+
+	_, err = parseSecret([]byte("pass"))
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestGCPFetcher(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if r.Header.Get("Metadata-Flavor") != "Google" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`{"access_token": "token"}`))
+		case "/projects/p/secrets/tidb/versions/latest:access":
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			payload := base64.StdEncoding.EncodeToString([]byte(`{"password": "pass"}`))
+			w.Write([]byte(fmt.Sprintf(`{"payload": {"data": %q}}`, payload)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := &gcpFetcher{apiURL: server.URL, tokenURL: server.URL + "/token", client: server.Client()}
+	data, err := fetcher.Fetch(&v1alpha1.ExternalSecretRef{Path: "projects/p/secrets/tidb"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(data).To(Equal(map[string]string{"password": "pass"}))
+
+	_, err = fetcher.Fetch(&v1alpha1.ExternalSecretRef{Path: "projects/p/secrets/tidb/versions/1"})
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestVaultFetcher(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/k8s/login":
+			var req map[string]string
+			json.NewDecoder(r.Body).Decode(&req)
+			if req["role"] != "backup" || req["jwt"] != "jwt" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`{"auth": {"client_token": "token"}}`))
+		case "/v1/secret/data/tidb":
+			if r.Header.Get("X-Vault-Token") != "token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`{"data": {"data": {"password": "pass", "port": 4000}, "metadata": {"version": 1}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	jwtFile, err := ioutil.TempFile("", "jwt")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.Remove(jwtFile.Name())
+	jwtFile.Write([]byte("jwt\n"))
+	jwtFile.Close()
+
+	fetcher := &vaultFetcher{
+		jwtPath: jwtFile.Name(),
+		newClient: func(address string, tlsConfig *tls.Config) vaultapi.VaultClient {
+			return vaultapi.NewVaultClient(address, vaultapi.DefaultTimeout, tlsConfig)
+		},
+	}
+	ref := &v1alpha1.ExternalSecretRef{
+		Provider: v1alpha1.ExternalSecretProviderVault,
+		Path:     "secret/data/tidb",
+		Vault:    &v1alpha1.ExternalSecretVault{Address: server.URL, Role: "backup", MountPath: "k8s"},
+	}
+	data, err := fetcher.Fetch(ref)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(data).To(Equal(map[string]string{"password": "pass", "port": "4000"}))
+
+	// the token in the env is used without the role
+	ref.Vault.Role = ""
+	_, err = fetcher.Fetch(ref)
+	g.Expect(err).To(MatchError(ContainSubstring(vaultTokenEnv)))
+	g.Expect(os.Setenv(vaultTokenEnv, "token")).To(Succeed())
+	defer os.Unsetenv(vaultTokenEnv)
+	data, err = fetcher.Fetch(ref)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(data).To(HaveKeyWithValue("password", "pass"))
+}
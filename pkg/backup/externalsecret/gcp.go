@@ -0,0 +1,116 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package externalsecret
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
+)
+
+const (
+	gcpSecretManagerURL = "https://secretmanager.googleapis.com/v1"
+	gcpMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+	gcpTimeout          = 30 * time.Second
+)
+
+// gcpFetcher fetches the secrets from GCP Secret Manager with the token of the service account
+// in the metadata server, which is the Google service account bound by workload identity on GKE
+type gcpFetcher struct {
+	apiURL   string
+	tokenURL string
+	client   *http.Client
+}
+
+func newGCPFetcher() *gcpFetcher {
+	return &gcpFetcher{
+		apiURL:   gcpSecretManagerURL,
+		tokenURL: gcpMetadataTokenURL,
+		client:   &http.Client{Timeout: gcpTimeout},
+	}
+}
+
+func (f *gcpFetcher) Fetch(ref *v1alpha1.ExternalSecretRef) (map[string]string, error) {
+	token, err := f.token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the token of the service account, error: %v", err)
+	}
+	name := strings.Trim(ref.Path, "/")
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+	body, err := f.get(fmt.Sprintf("%s/%s:access", f.apiURL, name), "Authorization", "Bearer "+token)
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	secret, err := base64.StdEncoding.DecodeString(resp.Payload.Data)
+	if err != nil {
+		return nil, err
+	}
+	return parseSecret(secret)
+}
+
+func (f *gcpFetcher) token() (string, error) {
+	body, err := f.get(f.tokenURL, "Metadata-Flavor", "Google")
+	if err != nil {
+		return "", err
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", err
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("no access_token is returned by %s", f.tokenURL)
+	}
+	return token.AccessToken, nil
+}
+
+// get returns the body or an error if the response is not okay
+func (f *gcpFetcher) get(apiURL, header, value string) ([]byte, error) {
+	req, err := http.NewRequest("GET", apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(header, value)
+	res, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httputil.DeferClose(res.Body)
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode >= 400 {
+		return nil, fmt.Errorf("error response %v URL %s, body response: %s", res.StatusCode, apiURL, string(body))
+	}
+	return body, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package externalsecret
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/vaultapi"
+)
+
+const (
+	vaultTokenEnv           = "VAULT_TOKEN"
+	vaultCACertEnv          = "VAULT_CACERT"
+	serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// vaultFetcher fetches the secrets from a KV secrets engine of Vault, it logs in by the
+// Kubernetes auth method if the role is set, or uses the token in the env VAULT_TOKEN
+type vaultFetcher struct {
+	jwtPath   string
+	newClient func(address string, tlsConfig *tls.Config) vaultapi.VaultClient
+}
+
+func newVaultFetcher() *vaultFetcher {
+	return &vaultFetcher{
+		jwtPath: serviceAccountTokenPath,
+		newClient: func(address string, tlsConfig *tls.Config) vaultapi.VaultClient {
+			return vaultapi.NewVaultClient(address, vaultapi.DefaultTimeout, tlsConfig)
+		},
+	}
+}
+
+func (f *vaultFetcher) Fetch(ref *v1alpha1.ExternalSecretRef) (map[string]string, error) {
+	vault := ref.Vault
+	if vault == nil || vault.Address == "" {
+		return nil, fmt.Errorf("the address of vault is not set")
+	}
+	var tlsConfig *tls.Config
+	if caFile := os.Getenv(vaultCACertEnv); caFile != "" {
+		ca, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("failed to load the CA in %s", caFile)
+		}
+		tlsConfig = &tls.Config{RootCAs: rootCAs}
+	}
+	client := f.newClient(vault.Address, tlsConfig)
+
+	if vault.Role != "" {
+		jwt, err := ioutil.ReadFile(f.jwtPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the service account token, error: %v", err)
+		}
+		if err := client.LoginKubernetes(vault.GetMountPath(), vault.Role, strings.TrimSpace(string(jwt))); err != nil {
+			return nil, fmt.Errorf("failed to login to vault %s, error: %v", vault.Address, err)
+		}
+	} else {
+		token := os.Getenv(vaultTokenEnv)
+		if token == "" {
+			return nil, fmt.Errorf("neither the role of vault nor the env %s is set", vaultTokenEnv)
+		}
+		client.SetToken(token)
+	}
+
+	data, err := client.ReadSecret(ref.Path)
+	if err != nil {
+		return nil, err
+	}
+	raw := make(map[string]json.RawMessage, len(data))
+	for key, value := range data {
+		if raw[key], err = json.Marshal(value); err != nil {
+			return nil, err
+		}
+	}
+	return toStrings(raw)
+}
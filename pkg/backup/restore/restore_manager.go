@@ -181,6 +181,13 @@ func (rm *restoreManager) makeImportJob(restore *v1alpha1.Restore) (*batchv1.Job
 	}
 
 	envVars = append(envVars, storageEnv...)
+
+	externalSecretEnv, err := backuputil.GenerateExternalSecretEnv(restore.Spec.StorageProvider, restore.Spec.To)
+	if err != nil {
+		return nil, "GenerateExternalSecretEnvFailed", fmt.Errorf("restore %s/%s, %v", ns, name, err)
+	}
+	envVars = append(envVars, externalSecretEnv...)
+
 	// set env vars specified in backup.Spec.Env
 	envVars = util.AppendOverwriteEnv(envVars, restore.Spec.Env)
 
@@ -335,6 +342,13 @@ func (rm *restoreManager) makeRestoreJob(restore *v1alpha1.Restore) (*batchv1.Jo
 	}
 
 	envVars = append(envVars, storageEnv...)
+
+	externalSecretEnv, err := backuputil.GenerateExternalSecretEnv(restore.Spec.StorageProvider, restore.Spec.To)
+	if err != nil {
+		return nil, "GenerateExternalSecretEnvFailed", fmt.Errorf("restore %s/%s, %v", ns, name, err)
+	}
+	envVars = append(envVars, externalSecretEnv...)
+
 	envVars = append(envVars, corev1.EnvVar{
 		Name:  "BR_LOG_TO_TERM",
 		Value: string(rune(1)),
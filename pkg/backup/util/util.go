@@ -16,11 +16,14 @@ package util
 import (
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Masterminds/semver"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/backup/constants"
+	"github.com/pingcap/tidb-operator/pkg/backup/externalsecret"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -243,6 +246,10 @@ func getPasswordKey(useKMS bool) string {
 func GenerateTidbPasswordEnv(ns, tcName, tidbSecretName string, useKMS bool, kubeCli kubernetes.Interface) ([]corev1.EnvVar, string, error) {
 	var certEnv []corev1.EnvVar
 	var passwordKey string
+	if tidbSecretName == "" {
+		// the password is referenced by externalSecret
+		return certEnv, "", nil
+	}
 	secret, err := kubeCli.CoreV1().Secrets(ns).Get(tidbSecretName, metav1.GetOptions{})
 	if err != nil {
 		err = fmt.Errorf("backup %s/%s get tidb secret %s failed, err: %v", ns, tcName, tidbSecretName, err)
@@ -271,6 +278,45 @@ func GenerateTidbPasswordEnv(ns, tcName, tidbSecretName string, useKMS bool, kub
 	return certEnv, "", nil
 }
 
+// GenerateExternalSecretEnv generates the env which tells backup-manager to fetch the credentials of
+// the storage and the password of TiDB from the external secret managers when the job starts
+func GenerateExternalSecretEnv(provider v1alpha1.StorageProvider, access *v1alpha1.TiDBAccessConfig) ([]corev1.EnvVar, error) {
+	var bindings []externalsecret.Binding
+	if provider.S3 != nil && provider.S3.ExternalSecret != nil {
+		bindings = append(bindings, externalsecret.Binding{
+			Ref: *provider.S3.ExternalSecret,
+			Envs: map[string]string{
+				constants.S3AccessKey: "AWS_ACCESS_KEY_ID",
+				constants.S3SecretKey: "AWS_SECRET_ACCESS_KEY",
+			},
+		})
+	}
+	if provider.Gcs != nil && provider.Gcs.ExternalSecret != nil {
+		bindings = append(bindings, externalsecret.Binding{
+			Ref: *provider.Gcs.ExternalSecret,
+			Envs: map[string]string{
+				constants.GcsCredentialsKey: "GCS_SERVICE_ACCOUNT_JSON_KEY",
+			},
+		})
+	}
+	if access != nil && access.ExternalSecret != nil {
+		bindings = append(bindings, externalsecret.Binding{
+			Ref: *access.ExternalSecret,
+			Envs: map[string]string{
+				constants.TidbPasswordKey: getPasswordKey(false),
+			},
+		})
+	}
+	if len(bindings) == 0 {
+		return nil, nil
+	}
+	env, err := externalsecret.EnvVar(bindings)
+	if err != nil {
+		return nil, err
+	}
+	return []corev1.EnvVar{env}, nil
+}
+
 // GetBackupBucketName return the bucket name for remote storage
 func GetBackupBucketName(backup *v1alpha1.Backup) (string, string, error) {
 	ns := backup.GetNamespace()
@@ -343,6 +389,58 @@ func GetBackupDataPath(provider v1alpha1.StorageProvider) (string, string, error
 	return fmt.Sprintf("%s://%s", string(storageType), backupPath), "", nil
 }
 
+// ValidateStorageProvider checks whether exactly one storage provider is configured
+func ValidateStorageProvider(provider v1alpha1.StorageProvider) error {
+	var configured []string
+	if provider.S3 != nil {
+		configured = append(configured, string(v1alpha1.BackupStorageTypeS3))
+	}
+	if provider.Gcs != nil {
+		configured = append(configured, string(v1alpha1.BackupStorageTypeGcs))
+	}
+	if provider.Local != nil {
+		configured = append(configured, string(v1alpha1.BackupStorageTypeLocal))
+	}
+	switch len(configured) {
+	case 0:
+		return fmt.Errorf("no storage provider is configured, one of s3, gcs and local should be set")
+	case 1:
+		return nil
+	default:
+		return fmt.Errorf("only one storage provider should be configured, got %s", strings.Join(configured, ","))
+	}
+}
+
+// ValidateBROptions checks whether the options passed to BR can be parsed
+func ValidateBROptions(br *v1alpha1.BRConfig) error {
+	if br == nil {
+		return nil
+	}
+	if br.TimeAgo != "" {
+		if _, err := time.ParseDuration(br.TimeAgo); err != nil {
+			return fmt.Errorf("invalid timeAgo %q for BR: %v", br.TimeAgo, err)
+		}
+	}
+	if br.BackupTS != "" {
+		if br.TimeAgo != "" {
+			return fmt.Errorf("backupTS and timeAgo can not be set at the same time for BR")
+		}
+		if _, err := strconv.ParseUint(br.BackupTS, 10, 64); err != nil {
+			return fmt.Errorf("invalid backupTS %q for BR, it should be a TSO", br.BackupTS)
+		}
+	}
+	for _, opt := range br.Options {
+		if !strings.HasPrefix(opt, "--") {
+			return fmt.Errorf("invalid option %q for BR, it should be in the format of --<flag>[=<value>]", opt)
+		}
+		flag := strings.SplitN(strings.TrimPrefix(opt, "--"), "=", 2)[0]
+		if flag == "" || strings.ContainsAny(flag, " \t") {
+			return fmt.Errorf("invalid option %q for BR, it should be in the format of --<flag>[=<value>]", opt)
+		}
+	}
+	return nil
+}
+
 func validateAccessConfig(config *v1alpha1.TiDBAccessConfig) string {
 	if config == nil {
 		return "missing cluster config in spec of %s/%s"
@@ -351,18 +449,64 @@ func validateAccessConfig(config *v1alpha1.TiDBAccessConfig) string {
 			return "missing cluster config in spec of %s/%s"
 		}
 
-		if config.SecretName == "" {
+		if config.SecretName == "" && config.ExternalSecret == nil {
 			return "missing tidbSecretName config in spec of %s/%s"
 		}
 	}
 	return ""
 }
 
+// validateExternalSecrets checks the external secrets referenced by the storage and the access config
+func validateExternalSecrets(ns, name string, provider v1alpha1.StorageProvider, access *v1alpha1.TiDBAccessConfig) error {
+	if provider.S3 != nil {
+		if err := ValidateExternalSecret(ns, name, "s3", provider.S3.SecretName, provider.S3.ExternalSecret); err != nil {
+			return err
+		}
+	}
+	if provider.Gcs != nil {
+		if err := ValidateExternalSecret(ns, name, "gcs", provider.Gcs.SecretName, provider.Gcs.ExternalSecret); err != nil {
+			return err
+		}
+	}
+	if access != nil {
+		if err := ValidateExternalSecret(ns, name, "cluster config", access.SecretName, access.ExternalSecret); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ValidateExternalSecret(ns, name, field, secretName string, ref *v1alpha1.ExternalSecretRef) error {
+	if ref == nil {
+		return nil
+	}
+	if secretName != "" {
+		return fmt.Errorf("secretName and externalSecret of %s can not be set together in spec of %s/%s", field, ns, name)
+	}
+	switch ref.Provider {
+	case v1alpha1.ExternalSecretProviderAWS, v1alpha1.ExternalSecretProviderGCP:
+	case v1alpha1.ExternalSecretProviderVault:
+		if ref.Vault == nil || ref.Vault.Address == "" {
+			return fmt.Errorf("vault address of the externalSecret of %s should be configured in spec of %s/%s", field, ns, name)
+		}
+	default:
+		return fmt.Errorf("invalid provider %q of the externalSecret of %s in spec of %s/%s", ref.Provider, field, ns, name)
+	}
+	if ref.Path == "" {
+		return fmt.Errorf("path of the externalSecret of %s should be configured in spec of %s/%s", field, ns, name)
+	}
+	return nil
+}
+
 // ValidateBackup validates backup sepc
 func ValidateBackup(backup *v1alpha1.Backup, tikvImage string) error {
 	ns := backup.Namespace
 	name := backup.Name
 
+	if err := validateExternalSecrets(ns, name, backup.Spec.StorageProvider, backup.Spec.From); err != nil {
+		return err
+	}
+
 	if backup.Spec.BR == nil {
 		if reason := validateAccessConfig(backup.Spec.From); reason != "" {
 			return fmt.Errorf(reason, ns, name)
@@ -395,6 +539,10 @@ func ValidateBackup(backup *v1alpha1.Backup, tikvImage string) error {
 			return fmt.Errorf("table should be configured for BR with backup type table in spec of %s/%s", ns, name)
 		}
 
+		if err := validateBackupFederation(backup); err != nil {
+			return err
+		}
+
 		// validate storage providers
 		if backup.Spec.S3 != nil {
 			if err := validateS3(ns, name, backup.Spec.S3); err != nil {
@@ -413,11 +561,43 @@ func ValidateBackup(backup *v1alpha1.Backup, tikvImage string) error {
 	return nil
 }
 
+// validateBackupFederation checks whether the members of a federated backup are valid
+func validateBackupFederation(backup *v1alpha1.Backup) error {
+	federation := backup.Spec.Federation
+	if federation == nil {
+		return nil
+	}
+	ns := backup.Namespace
+	name := backup.Name
+	if len(federation.Members) == 0 {
+		return fmt.Errorf("no member is configured for the federated backup in spec of %s/%s", ns, name)
+	}
+	if backup.Spec.BR.TimeAgo != "" || backup.Spec.BR.BackupTS != "" {
+		return fmt.Errorf("timeAgo and backupTS can not be set for the federated backup in spec of %s/%s", ns, name)
+	}
+	members := map[string]bool{}
+	for _, member := range federation.Members {
+		if member.Name == "" {
+			return fmt.Errorf("name should be configured for the members of the federated backup in spec of %s/%s", ns, name)
+		}
+		// the data of the members is saved under the member names
+		if members[member.Name] {
+			return fmt.Errorf("duplicated member %s of the federated backup in spec of %s/%s", member.Name, ns, name)
+		}
+		members[member.Name] = true
+	}
+	return nil
+}
+
 // ValidateRestore checks whether a restore spec is valid.
 func ValidateRestore(restore *v1alpha1.Restore, tikvImage string) error {
 	ns := restore.Namespace
 	name := restore.Name
 
+	if err := validateExternalSecrets(ns, name, restore.Spec.StorageProvider, restore.Spec.To); err != nil {
+		return err
+	}
+
 	if restore.Spec.BR == nil {
 		if reason := validateAccessConfig(restore.Spec.To); reason != "" {
 			return fmt.Errorf(reason, ns, name)
@@ -14,12 +14,14 @@
 package util
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/backup/constants"
+	"github.com/pingcap/tidb-operator/pkg/backup/externalsecret"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes/fake"
 )
@@ -431,6 +433,82 @@ func TestValidateBackup(t *testing.T) {
 	match("")
 }
 
+func TestValidateExternalSecret(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	backup := new(v1alpha1.Backup)
+	backup.Spec.StorageSize = "1m"
+	backup.Spec.From = &v1alpha1.TiDBAccessConfig{
+		Host:           "localhost",
+		ExternalSecret: &v1alpha1.ExternalSecretRef{Provider: v1alpha1.ExternalSecretProviderAWS, Path: "tidb"},
+	}
+	match := func(sub string) {
+		t.Helper()
+		err := ValidateBackup(backup, "tikv:v4.0.8")
+		if sub == "" {
+			g.Expect(err).Should(BeNil())
+		} else {
+			g.Expect(err).ShouldNot(BeNil())
+			g.Expect(err.Error()).Should(MatchRegexp(".*" + sub + ".*"))
+		}
+	}
+
+	// the password is referenced by externalSecret instead of secretName
+	match("")
+
+	backup.Spec.From.SecretName = "secretName"
+	match("secretName and externalSecret of cluster config can not be set together")
+
+	backup.Spec.From.SecretName = ""
+	backup.Spec.Gcs = &v1alpha1.GcsStorageProvider{
+		ExternalSecret: &v1alpha1.ExternalSecretRef{Provider: "unknown"},
+	}
+	match("invalid provider \"unknown\" of the externalSecret of gcs")
+
+	backup.Spec.Gcs.ExternalSecret.Provider = v1alpha1.ExternalSecretProviderVault
+	match("vault address of the externalSecret of gcs should be configured")
+
+	backup.Spec.Gcs.ExternalSecret.Vault = &v1alpha1.ExternalSecretVault{Address: "https://vault:8200"}
+	match("path of the externalSecret of gcs should be configured")
+
+	backup.Spec.Gcs.ExternalSecret.Path = "secret/data/gcs"
+	match("")
+}
+
+func TestGenerateExternalSecretEnv(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	envs, err := GenerateExternalSecretEnv(v1alpha1.StorageProvider{S3: &v1alpha1.S3StorageProvider{}}, nil)
+	g.Expect(err).Should(BeNil())
+	g.Expect(envs).Should(BeEmpty())
+
+	s3Ref := v1alpha1.ExternalSecretRef{Provider: v1alpha1.ExternalSecretProviderAWS, Path: "s3", Region: "us-west-2"}
+	passwordRef := v1alpha1.ExternalSecretRef{Provider: v1alpha1.ExternalSecretProviderGCP, Path: "projects/p/secrets/tidb"}
+	envs, err = GenerateExternalSecretEnv(
+		v1alpha1.StorageProvider{S3: &v1alpha1.S3StorageProvider{ExternalSecret: &s3Ref}},
+		&v1alpha1.TiDBAccessConfig{ExternalSecret: &passwordRef},
+	)
+	g.Expect(err).Should(BeNil())
+	g.Expect(envs).Should(HaveLen(1))
+	g.Expect(envs[0].Name).Should(Equal(externalsecret.EnvVarName))
+
+	var bindings []externalsecret.Binding
+	g.Expect(json.Unmarshal([]byte(envs[0].Value), &bindings)).Should(Succeed())
+	g.Expect(bindings).Should(Equal([]externalsecret.Binding{
+		{
+			Ref: s3Ref,
+			Envs: map[string]string{
+				constants.S3AccessKey: "AWS_ACCESS_KEY_ID",
+				constants.S3SecretKey: "AWS_SECRET_ACCESS_KEY",
+			},
+		},
+		{
+			Ref:  passwordRef,
+			Envs: map[string]string{constants.TidbPasswordKey: getPasswordKey(false)},
+		},
+	}))
+}
+
 func TestValidateRestore(t *testing.T) {
 	g := NewGomegaWithT(t)
 
@@ -25,6 +25,7 @@ import (
 	"github.com/coreos/etcd/clientv3"
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // Client is the client of binlog.
@@ -135,8 +136,57 @@ func (c *Client) PumpNodeStatus(ctx context.Context) (status []*v1alpha1.PumpNod
 }
 
 // nolint (unused)
-func (c *Client) drainerNodeStatus(ctx context.Context) (status []*v1alpha1.PumpNodeStatus, err error) {
-	return c.nodeStatus(ctx, "drainers")
+// DrainerNodeStatus returns the status of the drainers, including the checkpoint of the replication.
+func (c *Client) DrainerNodeStatus(ctx context.Context) (status []*v1alpha1.DrainerNodeStatus, err error) {
+	key := "/tidb-binlog/v1/drainers"
+
+	resp, err := c.etcdClient.KV.Get(ctx, key, clientv3.WithPrefix())
+	if err != nil {
+		return nil, errors.AddStack(err)
+	}
+
+	for _, kv := range resp.Kvs {
+		s, err := parseDrainerNodeStatus(kv.Value)
+		if err != nil {
+			return nil, errors.Annotatef(err, "key: %s, data: %s", string(kv.Key), string(kv.Value))
+		}
+
+		status = append(status, s)
+	}
+
+	return
+}
+
+// drainerNode is the status saved in etcd by the drainer, whose MaxCommitTS is the checkpoint of the drainer
+type drainerNode struct {
+	v1alpha1.PumpNodeStatus
+	MaxCommitTS int64 `json:"maxCommitTS"`
+}
+
+func parseDrainerNodeStatus(data []byte) (*v1alpha1.DrainerNodeStatus, error) {
+	var node drainerNode
+	if err := json.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+
+	s := &v1alpha1.DrainerNodeStatus{
+		NodeID: node.NodeID,
+		Host:   node.Host,
+		State:  node.State,
+	}
+	if node.MaxCommitTS > 0 {
+		s.CheckpointTS = node.MaxCommitTS
+		checkpointTime := metav1.NewTime(TSToTime(node.MaxCommitTS))
+		s.CheckpointTime = &checkpointTime
+	}
+	return s, nil
+}
+
+// TSToTime returns the physical time of the TSO allocated by PD.
+func TSToTime(ts int64) time.Time {
+	// the lower 18 bits are the logical part, and the others are the physical part in milliseconds
+	ms := ts >> 18
+	return time.Unix(ms/1000, (ms%1000)*int64(time.Millisecond))
 }
 
 func (c *Client) nodeID(ctx context.Context, addr, ty string) (string, error) {
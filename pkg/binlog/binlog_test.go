@@ -0,0 +1,49 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package binlog
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestParseDrainerNodeStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// 2021-01-01 00:00:00.123 UTC
+	ms := time.Date(2021, 1, 1, 0, 0, 0, 123*int(time.Millisecond), time.UTC).UnixNano() / int64(time.Millisecond)
+	ts := ms<<18 + 1
+	g.Expect(TSToTime(ts).UTC()).To(Equal(time.Date(2021, 1, 1, 0, 0, 0, 123*int(time.Millisecond), time.UTC)))
+
+	s, err := parseDrainerNodeStatus([]byte(`{"nodeId":"drainer-0","host":"drainer-0.drainer:8249","state":"online","isAlive":true,"maxCommitTS":` +
+		strconv.FormatInt(ts, 10) + `,"updateTS":` + strconv.FormatInt(ts, 10) + `}`))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(s.NodeID).To(Equal("drainer-0"))
+	g.Expect(s.Host).To(Equal("drainer-0.drainer:8249"))
+	g.Expect(s.State).To(Equal("online"))
+	g.Expect(s.CheckpointTS).To(Equal(ts))
+	g.Expect(s.CheckpointTime.Time.Equal(TSToTime(ts))).To(BeTrue())
+
+	// the drainer has not replicated any binlog yet
+	s, err = parseDrainerNodeStatus([]byte(`{"nodeId":"drainer-1","host":"drainer-1.drainer:8249","state":"paused","maxCommitTS":0}`))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(s.CheckpointTS).To(BeZero())
+	g.Expect(s.CheckpointTime).To(BeNil())
+
+	_, err = parseDrainerNodeStatus([]byte(`{`))
+	g.Expect(err).To(HaveOccurred())
+}
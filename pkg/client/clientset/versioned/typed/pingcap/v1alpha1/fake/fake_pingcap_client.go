@@ -53,6 +53,18 @@ func (c *FakePingcapV1alpha1) TidbClusterAutoScalers(namespace string) v1alpha1.
 	return &FakeTidbClusterAutoScalers{c, namespace}
 }
 
+func (c *FakePingcapV1alpha1) TidbClusterClones(namespace string) v1alpha1.TidbClusterCloneInterface {
+	return &FakeTidbClusterClones{c, namespace}
+}
+
+func (c *FakePingcapV1alpha1) TidbClusterFederations(namespace string) v1alpha1.TidbClusterFederationInterface {
+	return &FakeTidbClusterFederations{c, namespace}
+}
+
+func (c *FakePingcapV1alpha1) TidbClusterReplications(namespace string) v1alpha1.TidbClusterReplicationInterface {
+	return &FakeTidbClusterReplications{c, namespace}
+}
+
 func (c *FakePingcapV1alpha1) TidbInitializers(namespace string) v1alpha1.TidbInitializerInterface {
 	return &FakeTidbInitializers{c, namespace}
 }
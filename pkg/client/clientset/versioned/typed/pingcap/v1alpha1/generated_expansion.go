@@ -29,6 +29,12 @@ type TidbClusterExpansion interface{}
 
 type TidbClusterAutoScalerExpansion interface{}
 
+type TidbClusterCloneExpansion interface{}
+
+type TidbClusterFederationExpansion interface{}
+
+type TidbClusterReplicationExpansion interface{}
+
 type TidbInitializerExpansion interface{}
 
 type TidbMonitorExpansion interface{}
@@ -30,6 +30,9 @@ type PingcapV1alpha1Interface interface {
 	RestoresGetter
 	TidbClustersGetter
 	TidbClusterAutoScalersGetter
+	TidbClusterClonesGetter
+	TidbClusterFederationsGetter
+	TidbClusterReplicationsGetter
 	TidbInitializersGetter
 	TidbMonitorsGetter
 }
@@ -67,6 +70,18 @@ func (c *PingcapV1alpha1Client) TidbClusterAutoScalers(namespace string) TidbClu
 	return newTidbClusterAutoScalers(c, namespace)
 }
 
+func (c *PingcapV1alpha1Client) TidbClusterClones(namespace string) TidbClusterCloneInterface {
+	return newTidbClusterClones(c, namespace)
+}
+
+func (c *PingcapV1alpha1Client) TidbClusterFederations(namespace string) TidbClusterFederationInterface {
+	return newTidbClusterFederations(c, namespace)
+}
+
+func (c *PingcapV1alpha1Client) TidbClusterReplications(namespace string) TidbClusterReplicationInterface {
+	return newTidbClusterReplications(c, namespace)
+}
+
 func (c *PingcapV1alpha1Client) TidbInitializers(namespace string) TidbInitializerInterface {
 	return newTidbInitializers(c, namespace)
 }
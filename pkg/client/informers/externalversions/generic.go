@@ -64,6 +64,12 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Pingcap().V1alpha1().TidbClusters().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("tidbclusterautoscalers"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Pingcap().V1alpha1().TidbClusterAutoScalers().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("tidbclusterclones"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Pingcap().V1alpha1().TidbClusterClones().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("tidbclusterfederations"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Pingcap().V1alpha1().TidbClusterFederations().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("tidbclusterreplications"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Pingcap().V1alpha1().TidbClusterReplications().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("tidbinitializers"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Pingcap().V1alpha1().TidbInitializers().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("tidbmonitors"):
@@ -35,6 +35,12 @@ type Interface interface {
 	TidbClusters() TidbClusterInformer
 	// TidbClusterAutoScalers returns a TidbClusterAutoScalerInformer.
 	TidbClusterAutoScalers() TidbClusterAutoScalerInformer
+	// TidbClusterClones returns a TidbClusterCloneInformer.
+	TidbClusterClones() TidbClusterCloneInformer
+	// TidbClusterFederations returns a TidbClusterFederationInformer.
+	TidbClusterFederations() TidbClusterFederationInformer
+	// TidbClusterReplications returns a TidbClusterReplicationInformer.
+	TidbClusterReplications() TidbClusterReplicationInformer
 	// TidbInitializers returns a TidbInitializerInformer.
 	TidbInitializers() TidbInitializerInformer
 	// TidbMonitors returns a TidbMonitorInformer.
@@ -87,6 +93,21 @@ func (v *version) TidbClusterAutoScalers() TidbClusterAutoScalerInformer {
 	return &tidbClusterAutoScalerInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// TidbClusterClones returns a TidbClusterCloneInformer.
+func (v *version) TidbClusterClones() TidbClusterCloneInformer {
+	return &tidbClusterCloneInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// TidbClusterFederations returns a TidbClusterFederationInformer.
+func (v *version) TidbClusterFederations() TidbClusterFederationInformer {
+	return &tidbClusterFederationInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// TidbClusterReplications returns a TidbClusterReplicationInformer.
+func (v *version) TidbClusterReplications() TidbClusterReplicationInformer {
+	return &tidbClusterReplicationInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // TidbInitializers returns a TidbInitializerInformer.
 func (v *version) TidbInitializers() TidbInitializerInformer {
 	return &tidbInitializerInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -71,6 +71,30 @@ type TidbClusterAutoScalerListerExpansion interface{}
 // TidbClusterAutoScalerNamespaceLister.
 type TidbClusterAutoScalerNamespaceListerExpansion interface{}
 
+// TidbClusterCloneListerExpansion allows custom methods to be added to
+// TidbClusterCloneLister.
+type TidbClusterCloneListerExpansion interface{}
+
+// TidbClusterCloneNamespaceListerExpansion allows custom methods to be added to
+// TidbClusterCloneNamespaceLister.
+type TidbClusterCloneNamespaceListerExpansion interface{}
+
+// TidbClusterFederationListerExpansion allows custom methods to be added to
+// TidbClusterFederationLister.
+type TidbClusterFederationListerExpansion interface{}
+
+// TidbClusterFederationNamespaceListerExpansion allows custom methods to be added to
+// TidbClusterFederationNamespaceLister.
+type TidbClusterFederationNamespaceListerExpansion interface{}
+
+// TidbClusterReplicationListerExpansion allows custom methods to be added to
+// TidbClusterReplicationLister.
+type TidbClusterReplicationListerExpansion interface{}
+
+// TidbClusterReplicationNamespaceListerExpansion allows custom methods to be added to
+// TidbClusterReplicationNamespaceLister.
+type TidbClusterReplicationNamespaceListerExpansion interface{}
+
 // TidbInitializerListerExpansion allows custom methods to be added to
 // TidbInitializerLister.
 type TidbInitializerListerExpansion interface{}
@@ -0,0 +1,42 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// FieldManager is the manager of the fields written by the operator with server-side apply
+const FieldManager = "tidb-operator"
+
+// applyPatch returns the server-side apply patch of the desired object, only the fields set by the
+// operator are included, so the fields of the object managed by others are kept by the API server
+func applyPatch(obj runtime.Object, gvk schema.GroupVersionKind) ([]byte, error) {
+	obj = obj.DeepCopyObject()
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	// the status is not managed by the applier and the server-populated fields can't be applied
+	delete(u, "status")
+	unstructured.RemoveNestedField(u, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(u, "metadata", "resourceVersion")
+	unstructured.RemoveNestedField(u, "metadata", "managedFields")
+	return json.Marshal(u)
+}
@@ -0,0 +1,51 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestApplyPatch(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "tc-pd", ResourceVersion: "1", Labels: map[string]string{"app": "pd"}},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Name: "client", Port: 2379}},
+		},
+		Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{IP: "1.1.1.1"}}}},
+	}
+	data, err := applyPatch(svc, corev1.SchemeGroupVersion.WithKind("Service"))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	patch := map[string]interface{}{}
+	g.Expect(json.Unmarshal(data, &patch)).To(Succeed())
+	g.Expect(patch).To(HaveKeyWithValue("apiVersion", "v1"))
+	g.Expect(patch).To(HaveKeyWithValue("kind", "Service"))
+	g.Expect(patch).NotTo(HaveKey("status"))
+	metadata := patch["metadata"].(map[string]interface{})
+	g.Expect(metadata).To(HaveKeyWithValue("name", "tc-pd"))
+	g.Expect(metadata).NotTo(HaveKey("resourceVersion"))
+	g.Expect(metadata).NotTo(HaveKey("creationTimestamp"))
+	// the fields not set by the operator are not included
+	g.Expect(patch["spec"]).NotTo(HaveKey("clusterIP"))
+	// the desired object is not mutated
+	g.Expect(svc.Kind).To(BeEmpty())
+}
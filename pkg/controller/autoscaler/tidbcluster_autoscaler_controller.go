@@ -22,7 +22,6 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"k8s.io/apimachinery/pkg/api/errors"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
@@ -44,26 +43,16 @@ func NewController(deps *controller.Dependencies) *Controller {
 		),
 	}
 	tidbAutoScalerInformer := deps.InformerFactory.Pingcap().V1alpha1().TidbClusterAutoScalers()
-	controller.WatchForObject(tidbAutoScalerInformer.Informer(), t.queue)
+	controller.WatchForObjectWithResyncPeriod(tidbAutoScalerInformer.Informer(), t.queue, deps.CLIConfig.GetResyncDuration("autoscaler"))
 	return t
 }
 
 func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
 	defer utilruntime.HandleCrash()
-	defer c.queue.ShutDown()
 
 	klog.Info("Starting TidbClusterAutoScaler controller")
 	defer klog.Info("Shutting down tidbclusterAutoScaler controller")
-	for i := 0; i < workers; i++ {
-		go wait.Until(c.worker, time.Second, stopCh)
-	}
-
-	<-stopCh
-}
-
-func (c *Controller) worker() {
-	for c.processNextWorkItem() {
-	}
+	controller.RunWorkers(c.queue, workers, c.processNextWorkItem, stopCh)
 }
 
 func (c *Controller) processNextWorkItem() bool {
@@ -72,7 +61,10 @@ func (c *Controller) processNextWorkItem() bool {
 		return false
 	}
 	defer c.queue.Done(key)
-	if err := c.sync(key.(string)); err != nil {
+	startTime := time.Now()
+	err := c.sync(key.(string))
+	controller.ObserveReconcile("autoscaler", startTime, err)
+	if err != nil {
 		if perrors.Find(err, controller.IsRequeueError) != nil {
 			klog.Infof("TidbClusterAutoScaler: %v, still need sync: %v, requeuing", key.(string), err)
 		} else {
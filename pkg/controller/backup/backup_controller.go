@@ -25,7 +25,6 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
@@ -53,13 +52,13 @@ func NewController(deps *controller.Dependencies) *Controller {
 	}
 
 	backupInformer := deps.InformerFactory.Pingcap().V1alpha1().Backups()
-	backupInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+	backupInformer.Informer().AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
 		AddFunc: c.updateBackup,
 		UpdateFunc: func(old, cur interface{}) {
 			c.updateBackup(cur)
 		},
 		DeleteFunc: c.updateBackup,
-	})
+	}, deps.CLIConfig.GetResyncDuration("backup"))
 
 	return c
 }
@@ -67,24 +66,14 @@ func NewController(deps *controller.Dependencies) *Controller {
 // Run runs the backup controller.
 func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
 	defer utilruntime.HandleCrash()
-	defer c.queue.ShutDown()
 
 	klog.Info("Starting backup controller")
 	defer klog.Info("Shutting down backup controller")
 
-	for i := 0; i < workers; i++ {
-		go wait.Until(c.worker, time.Second, stopCh)
-	}
-
-	<-stopCh
+	controller.RunWorkers(c.queue, workers, c.processNextWorkItem, stopCh)
 }
 
 // worker runs a worker goroutine that invokes processNextWorkItem until the the controller's queue is closed
-func (c *Controller) worker() {
-	for c.processNextWorkItem() {
-	}
-}
-
 // processNextWorkItem dequeues items, processes them, and marks them done. It enforces that the syncHandler is never
 // invoked concurrently with the same key.
 func (c *Controller) processNextWorkItem() bool {
@@ -93,7 +82,10 @@ func (c *Controller) processNextWorkItem() bool {
 		return false
 	}
 	defer c.queue.Done(key)
-	if err := c.sync(key.(string)); err != nil {
+	startTime := time.Now()
+	err := c.sync(key.(string))
+	controller.ObserveReconcile("backup", startTime, err)
+	if err != nil {
 		if perrors.Find(err, controller.IsRequeueError) != nil {
 			klog.Infof("Backup: %v, still need sync: %v, requeuing", key.(string), err)
 			c.queue.AddRateLimited(key)
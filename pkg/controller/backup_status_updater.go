@@ -15,6 +15,7 @@ package controller
 
 import (
 	"fmt"
+	"time"
 
 	"k8s.io/klog"
 
@@ -22,6 +23,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
 	informers "github.com/pingcap/tidb-operator/pkg/client/informers/externalversions/pingcap/v1alpha1"
 	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/tools/cache"
@@ -46,6 +48,8 @@ type BackupUpdateStatus struct {
 	BackupSize *int64
 	// CommitTs is the snapshot time point of tidb cluster.
 	CommitTs *string
+	// Federation is the progress of the member backups of a federated backup.
+	Federation *v1alpha1.BackupFederationStatus
 }
 
 // BackupConditionUpdaterInterface enables updating Backup conditions.
@@ -74,10 +78,16 @@ func NewRealBackupConditionUpdater(
 func (u *realBackupConditionUpdater) Update(backup *v1alpha1.Backup, condition *v1alpha1.BackupCondition, newStatus *BackupUpdateStatus) error {
 	ns := backup.GetNamespace()
 	backupName := backup.GetName()
+	// the backup refetched on conflicts may be of a newer generation which is not processed yet
+	observedGeneration := backup.Generation
 	var isUpdate bool
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		updateBackupStatus(&backup.Status, newStatus)
-		isUpdate = v1alpha1.UpdateBackupCondition(&backup.Status, condition)
+		isStatusUpdate := updateBackupStatus(&backup.Status, newStatus)
+		isUpdate = v1alpha1.UpdateBackupCondition(&backup.Status, condition) || isStatusUpdate
+		if backup.Status.ObservedGeneration != observedGeneration {
+			backup.Status.ObservedGeneration = observedGeneration
+			isUpdate = true
+		}
 		if isUpdate {
 			_, updateErr := u.cli.PingcapV1alpha1().Backups(ns).Update(backup)
 			if updateErr == nil {
@@ -99,11 +109,12 @@ func (u *realBackupConditionUpdater) Update(backup *v1alpha1.Backup, condition *
 }
 
 // updateBackupStatus updates existing Backup status
-// from the fields in BackupUpdateStatus.
-func updateBackupStatus(status *v1alpha1.BackupStatus, newStatus *BackupUpdateStatus) {
+// from the fields in BackupUpdateStatus, it returns whether the status is changed.
+func updateBackupStatus(status *v1alpha1.BackupStatus, newStatus *BackupUpdateStatus) bool {
 	if newStatus == nil {
-		return
+		return false
 	}
+	old := status.DeepCopy()
 	if newStatus.BackupPath != nil {
 		status.BackupPath = *newStatus.BackupPath
 	}
@@ -112,6 +123,9 @@ func updateBackupStatus(status *v1alpha1.BackupStatus, newStatus *BackupUpdateSt
 	}
 	if newStatus.TimeCompleted != nil {
 		status.TimeCompleted = *newStatus.TimeCompleted
+		if !status.TimeStarted.IsZero() {
+			status.TimeTaken = status.TimeCompleted.Sub(status.TimeStarted.Time).Round(time.Second).String()
+		}
 	}
 	if newStatus.BackupSizeReadable != nil {
 		status.BackupSizeReadable = *newStatus.BackupSizeReadable
@@ -122,6 +136,10 @@ func updateBackupStatus(status *v1alpha1.BackupStatus, newStatus *BackupUpdateSt
 	if newStatus.CommitTs != nil {
 		status.CommitTs = *newStatus.CommitTs
 	}
+	if newStatus.Federation != nil {
+		status.Federation = newStatus.Federation.DeepCopy()
+	}
+	return !apiequality.Semantic.DeepEqual(old, status)
 }
 
 var _ BackupConditionUpdaterInterface = &realBackupConditionUpdater{}
@@ -53,6 +53,7 @@ func (c *defaultBackupScheduleControl) UpdateBackupSchedule(bs *v1alpha1.BackupS
 	if err := c.updateBackupSchedule(bs); err != nil {
 		errs = append(errs, err)
 	}
+	bs.Status.ObservedGeneration = bs.Generation
 	if apiequality.Semantic.DeepEqual(&bs.Status, oldStatus) {
 		return errorutils.NewAggregate(errs)
 	}
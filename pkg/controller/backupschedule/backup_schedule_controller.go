@@ -23,7 +23,6 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"k8s.io/apimachinery/pkg/api/errors"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
@@ -51,13 +50,13 @@ func NewController(deps *controller.Dependencies) *Controller {
 	}
 
 	backupScheduleInformer := deps.InformerFactory.Pingcap().V1alpha1().BackupSchedules()
-	backupScheduleInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+	backupScheduleInformer.Informer().AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
 		AddFunc: c.enqueueBackupSchedule,
 		UpdateFunc: func(old, cur interface{}) {
 			c.enqueueBackupSchedule(cur)
 		},
 		DeleteFunc: c.enqueueBackupSchedule,
-	})
+	}, deps.CLIConfig.GetResyncDuration("backupschedule"))
 
 	return c
 }
@@ -65,24 +64,14 @@ func NewController(deps *controller.Dependencies) *Controller {
 // Run runs the backup schedule controller.
 func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
 	defer utilruntime.HandleCrash()
-	defer c.queue.ShutDown()
 
 	klog.Info("Starting backup schedule controller")
 	defer klog.Info("Shutting down backup schedule controller")
 
-	for i := 0; i < workers; i++ {
-		go wait.Until(c.worker, time.Second, stopCh)
-	}
-
-	<-stopCh
+	controller.RunWorkers(c.queue, workers, c.processNextWorkItem, stopCh)
 }
 
 // worker runs a worker goroutine that invokes processNextWorkItem until the the controller's queue is closed
-func (c *Controller) worker() {
-	for c.processNextWorkItem() {
-	}
-}
-
 // processNextWorkItem dequeues items, processes them, and marks them done. It enforces that the syncHandler is never
 // invoked concurrently with the same key.
 func (c *Controller) processNextWorkItem() bool {
@@ -91,7 +80,10 @@ func (c *Controller) processNextWorkItem() bool {
 		return false
 	}
 	defer c.queue.Done(key)
-	if err := c.sync(key.(string)); err != nil {
+	startTime := time.Now()
+	err := c.sync(key.(string))
+	controller.ObserveReconcile("backupschedule", startTime, err)
+	if err != nil {
 		if perrors.Find(err, controller.IsRequeueError) != nil {
 			klog.Infof("BackupSchedule: %v, still need sync: %v, requeuing", key.(string), err)
 			c.queue.AddRateLimited(key)
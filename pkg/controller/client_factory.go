@@ -0,0 +1,91 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"crypto/tls"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/binlog"
+	"github.com/pingcap/tidb-operator/pkg/dmapi"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/tikvapi"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ClientFactory hands out the typed clients of the components of the clusters. The controls
+// created by the factory share the client tls.Configs cached per cluster, which are dropped
+// once the client TLS secrets are updated or deleted, instead of loading the secrets per call.
+type ClientFactory struct {
+	TLSConfigs      *pdapi.TLSConfigCache
+	PDControl       pdapi.PDControlInterface
+	TiKVControl     tikvapi.TiKVControlInterface
+	TiDBControl     TiDBControlInterface
+	CDCControl      TiCDCControlInterface
+	DMMasterControl dmapi.MasterControlInterface
+}
+
+// NewClientFactory returns a ClientFactory whose cached tls.Configs are dropped by the events of
+// the secret informer, the informer is not registered if it is nil
+func NewClientFactory(kubeCli kubernetes.Interface, secretInformer coreinformers.SecretInformer, pdOptions pdapi.PDControlOptions) *ClientFactory {
+	tlsConfigs := pdapi.NewTLSConfigCache(kubeCli)
+	if secretInformer != nil {
+		secretInformer.Informer().AddEventHandler(tlsConfigs.SecretEventHandler())
+	}
+	pdOptions.TLSConfigs = tlsConfigs
+	return &ClientFactory{
+		TLSConfigs:      tlsConfigs,
+		PDControl:       pdapi.NewDefaultPDControlWithOptions(kubeCli, pdOptions),
+		TiKVControl:     tikvapi.NewDefaultTiKVControl(kubeCli, tlsConfigs),
+		TiDBControl:     NewDefaultTiDBControl(kubeCli, tlsConfigs),
+		CDCControl:      NewDefaultTiCDCControl(kubeCli, tlsConfigs),
+		DMMasterControl: dmapi.NewDefaultMasterControl(kubeCli, tlsConfigs),
+	}
+}
+
+// PDClient returns the PD client of the TidbCluster
+func (f *ClientFactory) PDClient(tc *v1alpha1.TidbCluster) pdapi.PDClient {
+	return GetPDClient(f.PDControl, tc)
+}
+
+// TiKVClient returns the client of the TiKV pod of the TidbCluster
+func (f *ClientFactory) TiKVClient(tc *v1alpha1.TidbCluster, podName string) tikvapi.TiKVClient {
+	return f.TiKVControl.GetTiKVPodClient(tc.GetNamespace(), tc.GetName(), podName, tc.IsTLSClusterEnabled())
+}
+
+// DMMasterClient returns the dm-master client of the DMCluster
+func (f *ClientFactory) DMMasterClient(dc *v1alpha1.DMCluster) dmapi.MasterClient {
+	return GetMasterClient(f.DMMasterControl, dc)
+}
+
+// BinlogClient returns the client of the pumps and drainers registered in PD of the TidbCluster,
+// the caller must close it after use
+func (f *ClientFactory) BinlogClient(tc *v1alpha1.TidbCluster) (*binlog.Client, error) {
+	var endpoints []string
+	var tlsConfig *tls.Config
+	var err error
+	if tc.HeterogeneousWithoutLocalPD() {
+		endpoints, tlsConfig, err = f.PDControl.GetEndpoints(pdapi.Namespace(tc.Spec.Cluster.Namespace), tc.Spec.Cluster.Name, tc.IsTLSClusterEnabled())
+	} else {
+		endpoints, tlsConfig, err = f.PDControl.GetEndpoints(pdapi.Namespace(tc.Namespace), tc.Name, tc.IsTLSClusterEnabled())
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tc.ExternalPDOnly() {
+		endpoints = tc.Spec.PDAddresses
+	}
+	return binlog.NewBinlogClient(endpoints, tlsConfig)
+}
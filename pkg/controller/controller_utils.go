@@ -17,9 +17,13 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"sync"
+	"time"
 
 	"github.com/dustin/go-humanize"
+	perrors "github.com/pingcap/errors"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/metrics"
 	"github.com/pingcap/tidb-operator/pkg/scheme"
 	"github.com/pingcap/tidb-operator/pkg/util"
 	corev1 "k8s.io/api/core/v1"
@@ -29,6 +33,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/client-go/util/workqueue"
@@ -57,6 +62,9 @@ var (
 
 	// tidbClusterAutoScalerKind cotnains the schema.GroupVersionKind for TidbClusterAutoScaler controller type.
 	tidbClusterAutoScalerKind = v1alpha1.SchemeGroupVersion.WithKind("TidbClusterAutoScaler")
+
+	// tidbClusterCloneKind contains the schema.GroupVersionKind for TidbClusterClone controller type.
+	tidbClusterCloneKind = v1alpha1.SchemeGroupVersion.WithKind("TidbClusterClone")
 )
 
 // RequeueError is used to requeue the item, this error type should't be considered as a real error
@@ -99,6 +107,30 @@ func IsIgnoreError(err error) bool {
 	return ok
 }
 
+// ObserveReconcile records the duration and the result of a reconcile of the controller started at startTime,
+// the RequeueError and IgnoreError are not counted as errors
+func ObserveReconcile(controllerName string, startTime time.Time, err error) {
+	result := metrics.ReconcileResultSuccess
+	if err != nil && perrors.Find(err, IsIgnoreError) == nil {
+		if perrors.Find(err, IsRequeueError) != nil {
+			result = metrics.ReconcileResultRequeue
+		} else {
+			result = metrics.ReconcileResultError
+			metrics.ReconcileErrors.WithLabelValues(controllerName, ReconcileErrorReason(err)).Inc()
+		}
+	}
+	metrics.ReconcileDuration.WithLabelValues(controllerName, result).Observe(time.Since(startTime).Seconds())
+}
+
+// ReconcileErrorReason returns the reason of the reconcile error, which is the reason of the
+// API status error, e.g. Conflict, or Unknown if the error is not returned by the API server
+func ReconcileErrorReason(err error) string {
+	if reason := errors.ReasonForError(perrors.Cause(err)); reason != metav1.StatusReasonUnknown {
+		return string(reason)
+	}
+	return "Unknown"
+}
+
 // GetOwnerRef returns TidbCluster's OwnerReference
 func GetOwnerRef(tc *v1alpha1.TidbCluster) metav1.OwnerReference {
 	controller := true
@@ -195,6 +227,19 @@ func GetTiDBClusterAutoScalerOwnerRef(tac *v1alpha1.TidbClusterAutoScaler) metav
 	}
 }
 
+func GetTidbClusterCloneOwnerRef(tcc *v1alpha1.TidbClusterClone) metav1.OwnerReference {
+	controller := true
+	blockOwnerDeletion := true
+	return metav1.OwnerReference{
+		APIVersion:         tidbClusterCloneKind.GroupVersion().String(),
+		Kind:               tidbClusterCloneKind.Kind,
+		Name:               tcc.GetName(),
+		UID:                tcc.GetUID(),
+		Controller:         &controller,
+		BlockOwnerDeletion: &blockOwnerDeletion,
+	}
+}
+
 // GetServiceType returns member's service type
 func GetServiceType(services []v1alpha1.Service, serviceName string) corev1.ServiceType {
 	for _, svc := range services {
@@ -458,6 +503,40 @@ func (rt *RequestTracker) GetError() error {
 
 // WacthForObject watch the object change from informer and add it to workqueue
 func WatchForObject(informer cache.SharedIndexInformer, q workqueue.Interface) {
+	informer.AddEventHandler(objectEventHandler(q))
+}
+
+// WatchForObjectWithResyncPeriod is WatchForObject with the resync period of the event handler
+func WatchForObjectWithResyncPeriod(informer cache.SharedIndexInformer, q workqueue.Interface, resyncPeriod time.Duration) {
+	informer.AddEventHandlerWithResyncPeriod(objectEventHandler(q), resyncPeriod)
+}
+
+// RunWorkers runs the workers processing the items of the queue until stopCh is closed, then it shuts down
+// the queue and waits for the items in process to be done, so that no worker is writing after it returns
+func RunWorkers(q workqueue.Interface, workers int, processNextWorkItem func() bool, stopCh <-chan struct{}) {
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wait.Until(func() {
+				for processNextWorkItem() {
+					select {
+					case <-stopCh:
+						return
+					default:
+					}
+				}
+			}, time.Second, stopCh)
+		}()
+	}
+
+	<-stopCh
+	q.ShutDown()
+	wg.Wait()
+}
+
+func objectEventHandler(q workqueue.Interface) cache.ResourceEventHandler {
 	enqueueFn := func(obj interface{}) {
 		key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
 		if err != nil {
@@ -466,13 +545,13 @@ func WatchForObject(informer cache.SharedIndexInformer, q workqueue.Interface) {
 		}
 		q.Add(key)
 	}
-	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+	return cache.ResourceEventHandlerFuncs{
 		AddFunc: enqueueFn,
 		UpdateFunc: func(_, cur interface{}) {
 			enqueueFn(cur)
 		},
 		DeleteFunc: enqueueFn,
-	})
+	}
 }
 
 type GetControllerFn func(ns, name string) (runtime.Object, error)
@@ -16,6 +16,13 @@ package controller
 import (
 	"fmt"
 	"testing"
+	"time"
+
+	perrors "github.com/pingcap/errors"
+	"github.com/pingcap/tidb-operator/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/pingcap/tidb-operator/pkg/label"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -27,6 +34,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
 )
 
 func TestRequeueError(t *testing.T) {
@@ -285,6 +293,67 @@ func TestDMWorkerPeerMemberName(t *testing.T) {
 	g.Expect(DMWorkerPeerMemberName("demo")).To(Equal("demo-dm-worker-peer"))
 }
 
+func TestObserveReconcile(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	conflict := errors.NewConflict(schema.GroupResource{Resource: "tidbclusters"}, "tc", fmt.Errorf("conflict"))
+	g.Expect(ReconcileErrorReason(perrors.Annotate(conflict, "update tc"))).To(Equal("Conflict"))
+	g.Expect(ReconcileErrorReason(fmt.Errorf("pd is unavailable"))).To(Equal("Unknown"))
+
+	errorsTotal := func(reason string) float64 {
+		return testutil.ToFloat64(metrics.ReconcileErrors.WithLabelValues("test", reason))
+	}
+	ObserveReconcile("test", time.Now(), nil)
+	ObserveReconcile("test", time.Now(), RequeueErrorf("waiting"))
+	ObserveReconcile("test", time.Now(), IgnoreErrorf("ignored"))
+	g.Expect(errorsTotal("Unknown")).To(BeZero())
+	ObserveReconcile("test", time.Now(), fmt.Errorf("pd is unavailable"))
+	ObserveReconcile("test", time.Now(), conflict)
+	g.Expect(errorsTotal("Unknown")).To(Equal(1.0))
+	g.Expect(errorsTotal("Conflict")).To(Equal(1.0))
+}
+
+func TestRunWorkers(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	queue := workqueue.New()
+	for i := 0; i < 10; i++ {
+		queue.Add(i)
+	}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	processed := 0
+	processNextWorkItem := func() bool {
+		key, quit := queue.Get()
+		if quit {
+			return false
+		}
+		defer queue.Done(key)
+		if processed == 0 {
+			close(started)
+			<-release
+		}
+		processed++
+		return true
+	}
+
+	stopCh := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		RunWorkers(queue, 1, processNextWorkItem, stopCh)
+		close(done)
+	}()
+	<-started
+	close(stopCh)
+	// it waits for the item in process
+	g.Consistently(done, 100*time.Millisecond).ShouldNot(BeClosed())
+	close(release)
+	g.Eventually(done, time.Second).Should(BeClosed())
+	// no more items are processed after it is stopped
+	g.Expect(processed).To(Equal(1))
+	g.Expect(queue.ShuttingDown()).To(BeTrue())
+}
+
 func TestAnnProm(t *testing.T) {
 	g := NewGomegaWithT(t)
 
@@ -15,6 +15,9 @@ package controller
 
 import (
 	"flag"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
@@ -23,11 +26,13 @@ import (
 	informers "github.com/pingcap/tidb-operator/pkg/client/informers/externalversions"
 	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/dmapi"
+	"github.com/pingcap/tidb-operator/pkg/label"
 	"github.com/pingcap/tidb-operator/pkg/pdapi"
 	"github.com/pingcap/tidb-operator/pkg/scheme"
 	"github.com/pingcap/tidb-operator/pkg/tikvapi"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	kubefake "k8s.io/client-go/kubernetes/fake"
@@ -53,6 +58,10 @@ type CLIConfig struct {
 	// Controls whether operator should manage kubernetes cluster
 	// wide TiDB clusters
 	ClusterScoped bool
+	// WatchNamespaces are the comma separated namespaces watched by the
+	// operator if it is not cluster scoped, empty means the namespace of
+	// the operator
+	WatchNamespaces string
 
 	ClusterPermissionNode bool
 	ClusterPermissionPV   bool
@@ -68,42 +77,98 @@ type CLIConfig struct {
 	LeaseDuration         time.Duration
 	RenewDeadline         time.Duration
 	RetryPeriod           time.Duration
+	LeaderResourceLock    string
 	WaitDuration          time.Duration
 	// ResyncDuration is the resync time of informer
 	ResyncDuration time.Duration
+	// ControllerWorkers are the comma separated <controller>=<workers> pairs to
+	// override Workers for the controllers, e.g. tidbcluster=10,backup=2
+	ControllerWorkers string
+	// ControllerResyncDurations are the comma separated <controller>=<duration>
+	// pairs to override ResyncDuration for the event handlers of the controllers,
+	// e.g. tidbcluster=1m,backupschedule=5m
+	ControllerResyncDurations string
 	// Defines whether tidb operator run in test mode, test mode is
 	// only open when test
 	TestMode               bool
 	TiDBBackupManagerImage string
 	TiDBDiscoveryImage     string
+	TiDBInitializerImage   string
 	// PodWebhookEnabled is the key to indicate whether pod admission
 	// webhook is set up.
 	PodWebhookEnabled bool
 	// Selector is used to filter CR labels to decide
 	// what resources should be watched and synced by controller
 	Selector string
+	// PlacementRebalanceMovesPerHour is the max number of pods migrated by
+	// the placement rebalancer per TidbCluster per hour
+	PlacementRebalanceMovesPerHour int
+	// KubeletVolumeStats is the key to indicate whether to collect the usage
+	// of the PVCs from the kubelet through the node proxy
+	KubeletVolumeStats bool
+	// PDAPICacheTTL is the TTL of the responses of the PD APIs cached by
+	// the PD clients, 0 means no cache
+	PDAPICacheTTL time.Duration
+	// PDAPIQPS and PDAPIBurst limit the rate of the calls to the PD APIs
+	// of a TidbCluster, 0 QPS means no limit
+	PDAPIQPS   float64
+	PDAPIBurst int
+	// PDAPICircuitBreaker is the key to indicate whether to short-circuit the
+	// calls to the PD APIs of a TidbCluster if PD is unreachable
+	PDAPICircuitBreaker bool
+	// NodeFencing is the key to indicate whether to force delete the pods
+	// stuck on the nodes which are gone, it requires the permission of nodes
+	NodeFencing bool
+	// NodeFencingLeaseTimeout is the time after which a not ready node is
+	// considered gone if its lease is not renewed
+	NodeFencingLeaseTimeout time.Duration
+	// NodeMaintenance is the key to indicate whether to move the leaders away
+	// from the pods on the nodes to be drained, it requires the permission of nodes
+	NodeMaintenance bool
+	// FailoverWebhookURL is the URL the failover actions of the components
+	// are posted to as JSON, empty means only events are recorded
+	FailoverWebhookURL string
+	// CacheSyncTimeout is the time after which the operator is reported
+	// unhealthy if the caches of the informers are not synced after it
+	// becomes the leader
+	CacheSyncTimeout time.Duration
+	// ExternalSecretAWSRegion, ExternalSecretVaultAddress, ExternalSecretVaultRole and
+	// ExternalSecretVaultMountPath are the defaults of the external secrets referenced
+	// by TidbInitializer if they are not set in the spec
+	ExternalSecretAWSRegion      string
+	ExternalSecretVaultAddress   string
+	ExternalSecretVaultRole      string
+	ExternalSecretVaultMountPath string
 }
 
 // DefaultCLIConfig returns the default command line configuration
 func DefaultCLIConfig() *CLIConfig {
 	return &CLIConfig{
-		Workers:                5,
-		ClusterScoped:          true,
-		AutoFailover:           true,
-		PDFailoverPeriod:       5 * time.Minute,
-		TiKVFailoverPeriod:     5 * time.Minute,
-		TiDBFailoverPeriod:     5 * time.Minute,
-		TiFlashFailoverPeriod:  5 * time.Minute,
-		MasterFailoverPeriod:   5 * time.Minute,
-		WorkerFailoverPeriod:   5 * time.Minute,
-		LeaseDuration:          15 * time.Second,
-		RenewDeadline:          10 * time.Second,
-		RetryPeriod:            2 * time.Second,
-		WaitDuration:           5 * time.Second,
-		ResyncDuration:         30 * time.Second,
-		TiDBBackupManagerImage: "pingcap/tidb-backup-manager:latest",
-		TiDBDiscoveryImage:     "pingcap/tidb-operator:latest",
-		Selector:               "",
+		Workers:                 5,
+		ClusterScoped:           true,
+		AutoFailover:            true,
+		PDFailoverPeriod:        5 * time.Minute,
+		TiKVFailoverPeriod:      5 * time.Minute,
+		TiDBFailoverPeriod:      5 * time.Minute,
+		TiFlashFailoverPeriod:   5 * time.Minute,
+		MasterFailoverPeriod:    5 * time.Minute,
+		WorkerFailoverPeriod:    5 * time.Minute,
+		LeaseDuration:           15 * time.Second,
+		RenewDeadline:           10 * time.Second,
+		RetryPeriod:             2 * time.Second,
+		LeaderResourceLock:      EndpointsLeasesResourceLock,
+		WaitDuration:            5 * time.Second,
+		ResyncDuration:          30 * time.Second,
+		TiDBBackupManagerImage:  "pingcap/tidb-backup-manager:latest",
+		TiDBDiscoveryImage:      "pingcap/tidb-operator:latest",
+		TiDBInitializerImage:    "pingcap/tidb-initializer:latest",
+		Selector:                "",
+		PDAPICacheTTL:           5 * time.Second,
+		PDAPIQPS:                20,
+		PDAPIBurst:              50,
+		PDAPICircuitBreaker:     true,
+		NodeFencingLeaseTimeout: 10 * time.Minute,
+		CacheSyncTimeout:        5 * time.Minute,
 	}
 }
 
@@ -113,6 +178,7 @@ func (c *CLIConfig) AddFlag(_ *flag.FlagSet) {
 	flag.BoolVar(&c.PrintVersion, "version", false, "Show version and quit")
 	flag.IntVar(&c.Workers, "workers", c.Workers, "The number of workers that are allowed to sync concurrently. Larger number = more responsive management, but more CPU (and network) load")
 	flag.BoolVar(&c.ClusterScoped, "cluster-scoped", c.ClusterScoped, "Whether tidb-operator should manage kubernetes cluster wide TiDB Clusters")
+	flag.StringVar(&c.WatchNamespaces, "watch-namespaces", c.WatchNamespaces, "Comma separated namespaces watched by tidb-operator if cluster-scoped is false, only the permissions of Roles are required in these namespaces, defaults to the namespace of tidb-operator")
 	flag.BoolVar(&c.ClusterPermissionNode, "cluster-permission-node", c.ClusterPermissionNode, "Whether tidb-operator should have node permissions even if cluster-scoped is false")
 	flag.BoolVar(&c.ClusterPermissionPV, "cluster-permission-pv", c.ClusterPermissionPV, "Whether tidb-operator should have persistent volume permissions even if cluster-scoped is false")
 	flag.BoolVar(&c.ClusterPermissionSC, "cluster-permission-sc", c.ClusterPermissionSC, "Whether tidb-operator should have storage class permissions even if cluster-scoped is false")
@@ -124,17 +190,123 @@ func (c *CLIConfig) AddFlag(_ *flag.FlagSet) {
 	flag.DurationVar(&c.MasterFailoverPeriod, "dm-master-failover-period", c.MasterFailoverPeriod, "dm-master failover period")
 	flag.DurationVar(&c.WorkerFailoverPeriod, "dm-worker-failover-period", c.WorkerFailoverPeriod, "dm-worker failover period")
 	flag.DurationVar(&c.ResyncDuration, "resync-duration", c.ResyncDuration, "Resync time of informer")
+	flag.StringVar(&c.ControllerWorkers, "controller-workers", c.ControllerWorkers, fmt.Sprintf("Comma separated <controller>=<workers> pairs to override the number of workers of the controllers, e.g. tidbcluster=10,backup=2, the controllers are %s", strings.Join(ConfigurableControllers.List(), ", ")))
+	flag.StringVar(&c.ControllerResyncDurations, "controller-resync-durations", c.ControllerResyncDurations, fmt.Sprintf("Comma separated <controller>=<duration> pairs to override the resync time of the controllers, e.g. tidbcluster=1m,backupschedule=5m, the controllers are %s", strings.Join(ConfigurableControllers.List(), ", ")))
 	flag.BoolVar(&c.TestMode, "test-mode", false, "whether tidb-operator run in test mode")
 	flag.StringVar(&c.TiDBBackupManagerImage, "tidb-backup-manager-image", c.TiDBBackupManagerImage, "The image of backup manager tool")
 	// TODO: actually we just want to use the same image with tidb-controller-manager, but DownwardAPI cannot get image ID, see if there is any better solution
 	flag.StringVar(&c.TiDBDiscoveryImage, "tidb-discovery-image", c.TiDBDiscoveryImage, "The image of the tidb discovery service")
+	flag.StringVar(&c.TiDBInitializerImage, "tidb-initializer-image", c.TiDBInitializerImage, "The image of the TidbInitializers created by the operator, e.g. to mask the columns of the clusters cloned by TidbClusterClone")
 	flag.BoolVar(&c.PodWebhookEnabled, "pod-webhook-enabled", false, "Whether Pod admission webhook is enabled")
 	flag.StringVar(&c.Selector, "selector", c.Selector, "Selector (label query) to filter on, supports '=', '==', and '!='")
+	flag.IntVar(&c.PlacementRebalanceMovesPerHour, "placement-rebalance-moves-per-hour", 1, "The max number of pods migrated by the placement rebalancer per TidbCluster per hour")
+	flag.BoolVar(&c.KubeletVolumeStats, "collect-kubelet-volume-stats", false, "Whether to collect the usage of the PVCs from the kubelet through the node proxy, which requires the permission to get nodes/proxy")
+	flag.DurationVar(&c.PDAPICacheTTL, "pd-api-cache-ttl", c.PDAPICacheTTL, "The TTL of the responses of the read-only PD APIs cached by the PD clients, e.g. stores, members and config, 0 disables the cache")
+	flag.Float64Var(&c.PDAPIQPS, "pd-api-qps", c.PDAPIQPS, "The max rate of the calls to the PD APIs of a TidbCluster, 0 means no limit")
+	flag.IntVar(&c.PDAPIBurst, "pd-api-burst", c.PDAPIBurst, "The max burst of the calls to the PD APIs of a TidbCluster")
+	flag.BoolVar(&c.NodeFencing, "node-fencing", c.NodeFencing, "Whether to force delete the pods stuck on the nodes which are gone and detach their volumes, so that the failover is not blocked, it requires the permission of nodes")
+	flag.DurationVar(&c.NodeFencingLeaseTimeout, "node-fencing-lease-timeout", c.NodeFencingLeaseTimeout, "The time after which a not ready node is considered gone by the node fencing if its lease is not renewed")
+	flag.BoolVar(&c.NodeMaintenance, "node-maintenance", c.NodeMaintenance, "Whether to evict the TiKV leaders, transfer the PD leader and drain the TiCDC captures of the pods on the nodes to be drained, it requires the permission of nodes")
+	flag.StringVar(&c.FailoverWebhookURL, "failover-webhook-url", c.FailoverWebhookURL, "The URL to post the failover actions of the components to as JSON, e.g. a member is marked as failed, a replacement is created or a failed member is recovered")
+	flag.StringVar(&c.ExternalSecretAWSRegion, "external-secret-aws-region", c.ExternalSecretAWSRegion, "The default region of AWS Secrets Manager of the passwords of TidbInitializer")
+	flag.StringVar(&c.ExternalSecretVaultAddress, "external-secret-vault-address", c.ExternalSecretVaultAddress, "The default address of Vault of the passwords of TidbInitializer, e.g. https://vault.vault.svc:8200")
+	flag.StringVar(&c.ExternalSecretVaultRole, "external-secret-vault-role", c.ExternalSecretVaultRole, "The default role of the Kubernetes auth method of Vault bound to the service accounts of the TidbInitializer jobs")
+	flag.StringVar(&c.ExternalSecretVaultMountPath, "external-secret-vault-mount-path", c.ExternalSecretVaultMountPath, "The default mount path of the Kubernetes auth method of Vault, defaults to kubernetes")
+	flag.BoolVar(&c.PDAPICircuitBreaker, "pd-api-circuit-breaker", c.PDAPICircuitBreaker, "Whether to short-circuit the calls to the PD APIs of a TidbCluster if PD is unreachable, the last-known responses are used meanwhile")
 
 	// see https://pkg.go.dev/k8s.io/client-go/tools/leaderelection#LeaderElectionConfig for the config
 	flag.DurationVar(&c.LeaseDuration, "leader-lease-duration", c.LeaseDuration, "leader-lease-duration is the duration that non-leader candidates will wait to force acquire leadership")
 	flag.DurationVar(&c.RenewDeadline, "leader-renew-deadline", c.RenewDeadline, "leader-renew-deadline is the duration that the acting master will retry refreshing leadership before giving up")
 	flag.DurationVar(&c.RetryPeriod, "leader-retry-period", c.RetryPeriod, "leader-retry-period is the duration the LeaderElector clients should wait between tries of actions")
+	flag.StringVar(&c.LeaderResourceLock, "leader-resource-lock", c.LeaderResourceLock, fmt.Sprintf("leader-resource-lock is the type of the resource lock of the leader election, one of %s, switch to leases only after all the instances hold the endpointsleases lock", strings.Join(LeaderResourceLocks, ", ")))
+	flag.DurationVar(&c.CacheSyncTimeout, "cache-sync-timeout", c.CacheSyncTimeout, "The time after which /healthz fails if the caches of the informers are not synced after becoming the leader, so that the operator is restarted")
+}
+
+// GetWatchNamespaces returns the namespaces watched by the operator running in namespace ns,
+// metav1.NamespaceAll is returned if the operator is cluster scoped
+func (c *CLIConfig) GetWatchNamespaces(ns string) []string {
+	if c.ClusterScoped {
+		return []string{metav1.NamespaceAll}
+	}
+	var namespaces []string
+	seen := sets.NewString()
+	for _, watchNs := range strings.Split(c.WatchNamespaces, ",") {
+		watchNs = strings.TrimSpace(watchNs)
+		if watchNs == "" || seen.Has(watchNs) {
+			continue
+		}
+		seen.Insert(watchNs)
+		namespaces = append(namespaces, watchNs)
+	}
+	if len(namespaces) == 0 {
+		return []string{ns}
+	}
+	return namespaces
+}
+
+// ConfigurableControllers are the controllers whose workers and resync durations can be overridden
+var ConfigurableControllers = sets.NewString("tidbcluster", "dmcluster", "backup", "restore", "backupschedule", "autoscaler")
+
+// ValidateControllerOverrides validates the workers and resync durations of the controllers
+func (c *CLIConfig) ValidateControllerOverrides() error {
+	workers, err := parseControllerValues(c.ControllerWorkers)
+	if err != nil {
+		return fmt.Errorf("invalid controller-workers: %v", err)
+	}
+	for name, value := range workers {
+		if n, err := strconv.Atoi(value); err != nil || n <= 0 {
+			return fmt.Errorf("invalid controller-workers: the workers %q of controller %s must be a positive integer", value, name)
+		}
+	}
+	durations, err := parseControllerValues(c.ControllerResyncDurations)
+	if err != nil {
+		return fmt.Errorf("invalid controller-resync-durations: %v", err)
+	}
+	for name, value := range durations {
+		if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+			return fmt.Errorf("invalid controller-resync-durations: the duration %q of controller %s must be a positive duration", value, name)
+		}
+	}
+	return nil
+}
+
+// GetWorkers returns the number of workers of the controller
+func (c *CLIConfig) GetWorkers(controller string) int {
+	workers, _ := parseControllerValues(c.ControllerWorkers)
+	if n, err := strconv.Atoi(workers[controller]); err == nil && n > 0 {
+		return n
+	}
+	return c.Workers
+}
+
+// GetResyncDuration returns the resync time of the event handlers of the controller
+func (c *CLIConfig) GetResyncDuration(controller string) time.Duration {
+	durations, _ := parseControllerValues(c.ControllerResyncDurations)
+	if d, err := time.ParseDuration(durations[controller]); err == nil && d > 0 {
+		return d
+	}
+	return c.ResyncDuration
+}
+
+// parseControllerValues parses the comma separated <controller>=<value> pairs
+func parseControllerValues(s string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("%q should be <controller>=<value>", pair)
+		}
+		name := strings.TrimSpace(kv[0])
+		if !ConfigurableControllers.Has(name) {
+			return nil, fmt.Errorf("unknown controller %q, it should be one of %s", name, strings.Join(ConfigurableControllers.List(), ", "))
+		}
+		values[name] = strings.TrimSpace(kv[1])
+	}
+	return values, nil
 }
 
 // HasNodePermission returns whether the user has permission for node operations.
@@ -171,6 +343,10 @@ type Controls struct {
 	CDCControl         TiCDCControlInterface
 	TiDBControl        TiDBControlInterface
 	BackupControl      BackupControlInterface
+	// ClientFactory creates PDControl, TiKVControl, DMMasterControl, CDCControl and TiDBControl
+	ClientFactory *ClientFactory
+	// FailoverNotifier notifies the failover actions of the components
+	FailoverNotifier FailoverNotifierInterface
 }
 
 // Dependencies is used to store all shared dependent resources to avoid
@@ -181,35 +357,40 @@ type Dependencies struct {
 	// Operator client interface
 	Clientset versioned.Interface
 	// Kubernetes client interface
-	KubeClientset                  kubernetes.Interface
-	GenericClient                  client.Client
-	InformerFactory                informers.SharedInformerFactory
-	KubeInformerFactory            kubeinformers.SharedInformerFactory
+	KubeClientset       kubernetes.Interface
+	GenericClient       client.Client
+	InformerFactory     informers.SharedInformerFactory
+	KubeInformerFactory kubeinformers.SharedInformerFactory
+	// LabelFilterKubeInformerFactory only caches the resources managed by the operator, it is used for the
+	// Pods, StatefulSets, Services and ConfigMaps so the memory scales with the managed resources
 	LabelFilterKubeInformerFactory kubeinformers.SharedInformerFactory
 	Recorder                       record.EventRecorder
 
 	// Listers
-	ServiceLister               corelisterv1.ServiceLister
-	EndpointLister              corelisterv1.EndpointsLister
-	PVCLister                   corelisterv1.PersistentVolumeClaimLister
-	PVLister                    corelisterv1.PersistentVolumeLister
-	PodLister                   corelisterv1.PodLister
-	NodeLister                  corelisterv1.NodeLister
-	SecretLister                corelisterv1.SecretLister
-	ConfigMapLister             corelisterv1.ConfigMapLister
-	StatefulSetLister           appslisters.StatefulSetLister
-	DeploymentLister            appslisters.DeploymentLister
-	JobLister                   batchlisters.JobLister
-	IngressLister               extensionslister.IngressLister
-	StorageClassLister          storagelister.StorageClassLister
-	TiDBClusterLister           listers.TidbClusterLister
-	TiDBClusterAutoScalerLister listers.TidbClusterAutoScalerLister
-	DMClusterLister             listers.DMClusterLister
-	BackupLister                listers.BackupLister
-	RestoreLister               listers.RestoreLister
-	BackupScheduleLister        listers.BackupScheduleLister
-	TiDBInitializerLister       listers.TidbInitializerLister
-	TiDBMonitorLister           listers.TidbMonitorLister
+	ServiceLister                corelisterv1.ServiceLister
+	EndpointLister               corelisterv1.EndpointsLister
+	PVCLister                    corelisterv1.PersistentVolumeClaimLister
+	PVLister                     corelisterv1.PersistentVolumeLister
+	PodLister                    corelisterv1.PodLister
+	NodeLister                   corelisterv1.NodeLister
+	SecretLister                 corelisterv1.SecretLister
+	ConfigMapLister              corelisterv1.ConfigMapLister
+	StatefulSetLister            appslisters.StatefulSetLister
+	DeploymentLister             appslisters.DeploymentLister
+	JobLister                    batchlisters.JobLister
+	IngressLister                extensionslister.IngressLister
+	StorageClassLister           storagelister.StorageClassLister
+	TiDBClusterLister            listers.TidbClusterLister
+	TiDBClusterAutoScalerLister  listers.TidbClusterAutoScalerLister
+	DMClusterLister              listers.DMClusterLister
+	BackupLister                 listers.BackupLister
+	RestoreLister                listers.RestoreLister
+	BackupScheduleLister         listers.BackupScheduleLister
+	TiDBInitializerLister        listers.TidbInitializerLister
+	TiDBMonitorLister            listers.TidbMonitorLister
+	TiDBClusterFederationLister  listers.TidbClusterFederationLister
+	TiDBClusterReplicationLister listers.TidbClusterReplicationLister
+	TiDBClusterCloneLister       listers.TidbClusterCloneLister
 
 	// Controls
 	Controls
@@ -222,19 +403,24 @@ func newRealControls(
 	genericCli client.Client,
 	informerFactory informers.SharedInformerFactory,
 	kubeInformerFactory kubeinformers.SharedInformerFactory,
+	labelFilterKubeInformerFactory kubeinformers.SharedInformerFactory,
 	recorder record.EventRecorder) Controls {
 	// Shared variables to construct `Dependencies` and some of its fields
 	var (
-		pdControl         = pdapi.NewDefaultPDControl(kubeClientset)
-		tikvControl       = tikvapi.NewDefaultTiKVControl(kubeClientset)
-		masterControl     = dmapi.NewDefaultMasterControl(kubeClientset)
+		clientFactory = NewClientFactory(kubeClientset, kubeInformerFactory.Core().V1().Secrets(), pdapi.PDControlOptions{
+			CacheTTL:       cliCfg.PDAPICacheTTL,
+			QPS:            cliCfg.PDAPIQPS,
+			Burst:          cliCfg.PDAPIBurst,
+			CircuitBreaker: cliCfg.PDAPICircuitBreaker,
+		})
+		pdControl         = clientFactory.PDControl
 		genericCtrl       = NewRealGenericControl(genericCli, recorder)
 		tidbClusterLister = informerFactory.Pingcap().V1alpha1().TidbClusters().Lister()
 		dmClusterLister   = informerFactory.Pingcap().V1alpha1().DMClusters().Lister()
-		statefulSetLister = kubeInformerFactory.Apps().V1().StatefulSets().Lister()
-		serviceLister     = kubeInformerFactory.Core().V1().Services().Lister()
+		statefulSetLister = labelFilterKubeInformerFactory.Apps().V1().StatefulSets().Lister()
+		serviceLister     = labelFilterKubeInformerFactory.Core().V1().Services().Lister()
 		pvcLister         = kubeInformerFactory.Core().V1().PersistentVolumeClaims().Lister()
-		podLister         = kubeInformerFactory.Core().V1().Pods().Lister()
+		podLister         = labelFilterKubeInformerFactory.Core().V1().Pods().Lister()
 		pvLister          corelisterv1.PersistentVolumeLister
 	)
 	if cliCfg.HasPVPermission() {
@@ -253,13 +439,15 @@ func newRealControls(
 		PodControl:         NewRealPodControl(kubeClientset, pdControl, podLister, recorder),
 		TypedControl:       NewTypedControl(genericCtrl),
 		PDControl:          pdControl,
-		TiKVControl:        tikvControl,
-		DMMasterControl:    masterControl,
+		TiKVControl:        clientFactory.TiKVControl,
+		DMMasterControl:    clientFactory.DMMasterControl,
 		TiDBClusterControl: NewRealTidbClusterControl(clientset, tidbClusterLister, recorder),
 		DMClusterControl:   NewRealDMClusterControl(clientset, dmClusterLister, recorder),
-		CDCControl:         NewDefaultTiCDCControl(kubeClientset),
-		TiDBControl:        NewDefaultTiDBControl(kubeClientset),
+		CDCControl:         clientFactory.CDCControl,
+		TiDBControl:        clientFactory.TiDBControl,
 		BackupControl:      NewRealBackupControl(clientset, recorder),
+		ClientFactory:      clientFactory,
+		FailoverNotifier:   NewRealFailoverNotifier(cliCfg.FailoverWebhookURL),
 	}
 }
 
@@ -305,27 +493,30 @@ func newDependencies(
 		Recorder:                       recorder,
 
 		// Listers
-		ServiceLister:               kubeInformerFactory.Core().V1().Services().Lister(),
-		EndpointLister:              kubeInformerFactory.Core().V1().Endpoints().Lister(),
-		PVCLister:                   kubeInformerFactory.Core().V1().PersistentVolumeClaims().Lister(),
-		PVLister:                    pvLister,
-		PodLister:                   kubeInformerFactory.Core().V1().Pods().Lister(),
-		NodeLister:                  nodeLister,
-		SecretLister:                kubeInformerFactory.Core().V1().Secrets().Lister(),
-		ConfigMapLister:             labelFilterKubeInformerFactory.Core().V1().ConfigMaps().Lister(),
-		StatefulSetLister:           kubeInformerFactory.Apps().V1().StatefulSets().Lister(),
-		DeploymentLister:            kubeInformerFactory.Apps().V1().Deployments().Lister(),
-		StorageClassLister:          scLister,
-		JobLister:                   kubeInformerFactory.Batch().V1().Jobs().Lister(),
-		IngressLister:               kubeInformerFactory.Extensions().V1beta1().Ingresses().Lister(),
-		TiDBClusterLister:           informerFactory.Pingcap().V1alpha1().TidbClusters().Lister(),
-		TiDBClusterAutoScalerLister: informerFactory.Pingcap().V1alpha1().TidbClusterAutoScalers().Lister(),
-		DMClusterLister:             informerFactory.Pingcap().V1alpha1().DMClusters().Lister(),
-		BackupLister:                informerFactory.Pingcap().V1alpha1().Backups().Lister(),
-		RestoreLister:               informerFactory.Pingcap().V1alpha1().Restores().Lister(),
-		BackupScheduleLister:        informerFactory.Pingcap().V1alpha1().BackupSchedules().Lister(),
-		TiDBInitializerLister:       informerFactory.Pingcap().V1alpha1().TidbInitializers().Lister(),
-		TiDBMonitorLister:           informerFactory.Pingcap().V1alpha1().TidbMonitors().Lister(),
+		ServiceLister:                labelFilterKubeInformerFactory.Core().V1().Services().Lister(),
+		EndpointLister:               kubeInformerFactory.Core().V1().Endpoints().Lister(),
+		PVCLister:                    kubeInformerFactory.Core().V1().PersistentVolumeClaims().Lister(),
+		PVLister:                     pvLister,
+		PodLister:                    labelFilterKubeInformerFactory.Core().V1().Pods().Lister(),
+		NodeLister:                   nodeLister,
+		SecretLister:                 kubeInformerFactory.Core().V1().Secrets().Lister(),
+		ConfigMapLister:              labelFilterKubeInformerFactory.Core().V1().ConfigMaps().Lister(),
+		StatefulSetLister:            labelFilterKubeInformerFactory.Apps().V1().StatefulSets().Lister(),
+		DeploymentLister:             kubeInformerFactory.Apps().V1().Deployments().Lister(),
+		StorageClassLister:           scLister,
+		JobLister:                    kubeInformerFactory.Batch().V1().Jobs().Lister(),
+		IngressLister:                kubeInformerFactory.Extensions().V1beta1().Ingresses().Lister(),
+		TiDBClusterLister:            informerFactory.Pingcap().V1alpha1().TidbClusters().Lister(),
+		TiDBClusterAutoScalerLister:  informerFactory.Pingcap().V1alpha1().TidbClusterAutoScalers().Lister(),
+		DMClusterLister:              informerFactory.Pingcap().V1alpha1().DMClusters().Lister(),
+		BackupLister:                 informerFactory.Pingcap().V1alpha1().Backups().Lister(),
+		RestoreLister:                informerFactory.Pingcap().V1alpha1().Restores().Lister(),
+		BackupScheduleLister:         informerFactory.Pingcap().V1alpha1().BackupSchedules().Lister(),
+		TiDBInitializerLister:        informerFactory.Pingcap().V1alpha1().TidbInitializers().Lister(),
+		TiDBMonitorLister:            informerFactory.Pingcap().V1alpha1().TidbMonitors().Lister(),
+		TiDBClusterFederationLister:  informerFactory.Pingcap().V1alpha1().TidbClusterFederations().Lister(),
+		TiDBClusterReplicationLister: informerFactory.Pingcap().V1alpha1().TidbClusterReplications().Lister(),
+		TiDBClusterCloneLister:       informerFactory.Pingcap().V1alpha1().TidbClusterClones().Lister(),
 	}
 }
 
@@ -339,11 +530,14 @@ func NewDependencies(ns string, cliCfg *CLIConfig, clientset versioned.Interface
 		options = append(options, informers.WithNamespace(ns))
 		kubeoptions = append(kubeoptions, kubeinformers.WithNamespace(ns))
 	}
+	// the Pods of backup and restore are labeled with their own managers, while the Jobs are
+	// still cached by kubeInformerFactory without the label filter
+	managedBySelector := fmt.Sprintf("%s in (%s,backup-operator,restore-operator,backup-schedule-operator)", label.ManagedByLabelKey, label.TiDBOperator)
 	tweakListOptionsFunc := func(options *metav1.ListOptions) {
 		if len(options.LabelSelector) > 0 {
-			options.LabelSelector += ",app.kubernetes.io/managed-by=tidb-operator"
+			options.LabelSelector += "," + managedBySelector
 		} else {
-			options.LabelSelector = "app.kubernetes.io/managed-by=tidb-operator"
+			options.LabelSelector = managedBySelector
 		}
 	}
 	labelKubeOptions := append(kubeoptions, kubeinformers.WithTweakListOptions(tweakListOptionsFunc))
@@ -366,12 +560,21 @@ func NewDependencies(ns string, cliCfg *CLIConfig, clientset versioned.Interface
 		Interface: eventv1.New(kubeClientset.CoreV1().RESTClient()).Events("")})
 	recorder := eventBroadcaster.NewRecorder(v1alpha1.Scheme, corev1.EventSource{Component: "tidb-controller-manager"})
 	deps := newDependencies(cliCfg, clientset, kubeClientset, genericCli, informerFactory, kubeInformerFactory, labelFilterKubeInformerFactory, recorder)
-	deps.Controls = newRealControls(cliCfg, clientset, kubeClientset, genericCli, informerFactory, kubeInformerFactory, recorder)
+	deps.Controls = newRealControls(cliCfg, clientset, kubeClientset, genericCli, informerFactory, kubeInformerFactory, labelFilterKubeInformerFactory, recorder)
 	return deps
 }
 
 func newFakeControl(kubeClientset kubernetes.Interface, informerFactory informers.SharedInformerFactory, kubeInformerFactory kubeinformers.SharedInformerFactory) Controls {
 	genericCtrl := NewFakeGenericControl()
+	tlsConfigs := pdapi.NewTLSConfigCache(kubeClientset)
+	clientFactory := &ClientFactory{
+		TLSConfigs:      tlsConfigs,
+		PDControl:       pdapi.NewFakePDControl(kubeClientset),
+		TiKVControl:     tikvapi.NewFakeTiKVControl(kubeClientset),
+		TiDBControl:     NewFakeTiDBControl(),
+		CDCControl:      NewDefaultTiCDCControl(kubeClientset, tlsConfigs), // TODO: no fake control?
+		DMMasterControl: dmapi.NewFakeMasterControl(kubeClientset),
+	}
 	// Shared variables to construct `Dependencies` and some of its fields
 	return Controls{
 		JobControl:         NewFakeJobControl(kubeInformerFactory.Batch().V1().Jobs()),
@@ -384,13 +587,15 @@ func newFakeControl(kubeClientset kubernetes.Interface, informerFactory informer
 		GenericControl:     genericCtrl,
 		PodControl:         NewFakePodControl(kubeInformerFactory.Core().V1().Pods()),
 		TypedControl:       NewTypedControl(genericCtrl),
-		PDControl:          pdapi.NewFakePDControl(kubeClientset),
-		TiKVControl:        tikvapi.NewFakeTiKVControl(kubeClientset),
-		DMMasterControl:    dmapi.NewFakeMasterControl(kubeClientset),
+		PDControl:          clientFactory.PDControl,
+		TiKVControl:        clientFactory.TiKVControl,
+		DMMasterControl:    clientFactory.DMMasterControl,
 		TiDBClusterControl: NewFakeTidbClusterControl(informerFactory.Pingcap().V1alpha1().TidbClusters()),
-		CDCControl:         NewDefaultTiCDCControl(kubeClientset), // TODO: no fake control?
-		TiDBControl:        NewFakeTiDBControl(),
+		CDCControl:         clientFactory.CDCControl,
+		TiDBControl:        clientFactory.TiDBControl,
 		BackupControl:      NewFakeBackupControl(informerFactory.Pingcap().V1alpha1().Backups()),
+		ClientFactory:      clientFactory,
+		FailoverNotifier:   NewFakeFailoverNotifier(),
 	}
 }
 
@@ -412,7 +617,8 @@ func NewFakeDependencies() *Dependencies {
 	cliCfg := DefaultCLIConfig()
 	informerFactory := informers.NewSharedInformerFactory(cli, 0)
 	kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeCli, 0)
-	labelFilterKubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeCli, 0)
+	// the fake objects are added to the same informers no matter they are labeled or not
+	labelFilterKubeInformerFactory := kubeInformerFactory
 	recorder := record.NewFakeRecorder(100)
 	deps := newDependencies(cliCfg, cli, kubeCli, genCli, informerFactory, kubeInformerFactory, labelFilterKubeInformerFactory, recorder)
 	deps.Controls = newFakeControl(kubeCli, informerFactory, kubeInformerFactory)
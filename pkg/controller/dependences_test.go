@@ -49,3 +49,46 @@ func TestFakeTidbCluster(t *testing.T) {
 		}, time.Second*10).Should(BeNil())
 	}
 }
+
+func TestGetWatchNamespaces(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cfg := DefaultCLIConfig()
+	cfg.WatchNamespaces = "ns1"
+	g.Expect(cfg.GetWatchNamespaces("tidb-admin")).To(Equal([]string{v1.NamespaceAll}))
+
+	cfg.ClusterScoped = false
+	cfg.WatchNamespaces = ""
+	g.Expect(cfg.GetWatchNamespaces("tidb-admin")).To(Equal([]string{"tidb-admin"}))
+
+	cfg.WatchNamespaces = "ns1, ns2,,ns1"
+	g.Expect(cfg.GetWatchNamespaces("tidb-admin")).To(Equal([]string{"ns1", "ns2"}))
+}
+
+func TestControllerOverrides(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cfg := DefaultCLIConfig()
+	g.Expect(cfg.ValidateControllerOverrides()).To(Succeed())
+	g.Expect(cfg.GetWorkers("tidbcluster")).To(Equal(cfg.Workers))
+	g.Expect(cfg.GetResyncDuration("backup")).To(Equal(cfg.ResyncDuration))
+
+	cfg.ControllerWorkers = "tidbcluster=10, backup=2"
+	cfg.ControllerResyncDurations = "backupschedule=5m"
+	g.Expect(cfg.ValidateControllerOverrides()).To(Succeed())
+	g.Expect(cfg.GetWorkers("tidbcluster")).To(Equal(10))
+	g.Expect(cfg.GetWorkers("backup")).To(Equal(2))
+	g.Expect(cfg.GetWorkers("restore")).To(Equal(cfg.Workers))
+	g.Expect(cfg.GetResyncDuration("backupschedule")).To(Equal(5 * time.Minute))
+	g.Expect(cfg.GetResyncDuration("tidbcluster")).To(Equal(cfg.ResyncDuration))
+
+	cfg.ControllerWorkers = "unknown=1"
+	g.Expect(cfg.ValidateControllerOverrides()).NotTo(Succeed())
+	cfg.ControllerWorkers = "tidbcluster=0"
+	g.Expect(cfg.ValidateControllerOverrides()).NotTo(Succeed())
+	cfg.ControllerWorkers = "tidbcluster"
+	g.Expect(cfg.ValidateControllerOverrides()).NotTo(Succeed())
+	cfg.ControllerWorkers = ""
+	cfg.ControllerResyncDurations = "restore=abc"
+	g.Expect(cfg.ValidateControllerOverrides()).NotTo(Succeed())
+}
@@ -90,6 +90,8 @@ func (c *defaultDMClusterControl) UpdateDMCluster(dc *v1alpha1.DMCluster) error
 	if err := c.updateDMCluster(dc); err != nil {
 		errs = append(errs, err)
 	}
+	// the spec of the generation is processed even if the sync fails, the conditions tell the progress
+	dc.Status.ObservedGeneration = dc.Generation
 
 	if err := c.conditionUpdater.Update(dc); err != nil {
 		errs = append(errs, err)
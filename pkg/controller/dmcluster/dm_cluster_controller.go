@@ -22,15 +22,15 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	mm "github.com/pingcap/tidb-operator/pkg/manager/member"
 	"github.com/pingcap/tidb-operator/pkg/manager/meta"
+	"github.com/pingcap/tidb-operator/pkg/util/logging"
 
 	apps "k8s.io/api/apps/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
-	"k8s.io/klog"
 )
 
 // Controller controls dmclusters.
@@ -40,6 +40,7 @@ type Controller struct {
 	control ControlInterface
 	// dmclusters that need to be synced.
 	queue workqueue.RateLimitingInterface
+	log   logging.Logger
 }
 
 // NewController creates a dmcluster controller.
@@ -59,51 +60,42 @@ func NewController(deps *controller.Dependencies) *Controller {
 			deps.Recorder,
 		),
 		queue: workqueue.NewNamedRateLimitingQueue(
-			controller.NewControllerRateLimiter(1*time.Second, 100*time.Second),
+			controller.NewClusterRateLimiter("dmcluster", 1*time.Second, 100*time.Second),
 			"dmcluster",
 		),
+		log: logging.ForController("dmcluster"),
 	}
 
 	dmClusterInformer := deps.InformerFactory.Pingcap().V1alpha1().DMClusters()
-	statefulsetInformer := deps.KubeInformerFactory.Apps().V1().StatefulSets()
-	dmClusterInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+	statefulsetInformer := deps.LabelFilterKubeInformerFactory.Apps().V1().StatefulSets()
+	dmClusterInformer.Informer().AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
 		AddFunc: c.enqueueDMCluster,
 		UpdateFunc: func(old, cur interface{}) {
-			c.enqueueDMCluster(cur)
+			c.updateDMCluster(old, cur)
 		},
 		DeleteFunc: c.enqueueDMCluster,
-	})
-	statefulsetInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+	}, deps.CLIConfig.GetResyncDuration("dmcluster"))
+	statefulsetInformer.Informer().AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
 		AddFunc: c.addStatefulSet,
 		UpdateFunc: func(old, cur interface{}) {
 			c.updateStatefulSet(old, cur)
 		},
 		DeleteFunc: c.deleteStatefulSet,
-	})
+	}, deps.CLIConfig.GetResyncDuration("dmcluster"))
 	return c
 }
 
 // Run runs the dmcluster controller.
 func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
 	defer utilruntime.HandleCrash()
-	defer c.queue.ShutDown()
 
-	klog.Info("Starting dmcluster controller")
-	defer klog.Info("Shutting down dmcluster controller")
+	c.log.Info("Starting controller")
+	defer c.log.Info("Shutting down controller")
 
-	for i := 0; i < workers; i++ {
-		go wait.Until(c.worker, time.Second, stopCh)
-	}
-
-	<-stopCh
+	controller.RunWorkers(c.queue, workers, c.processNextWorkItem, stopCh)
 }
 
 // worker runs a worker goroutine that invokes processNextWorkItem until the the controller's queue is closed
-func (c *Controller) worker() {
-	for c.processNextWorkItem() {
-	}
-}
-
 // processNextWorkItem dequeues items, processes them, and marks them done. It enforces that the syncHandler is never
 // invoked concurrently with the same key.
 func (c *Controller) processNextWorkItem() bool {
@@ -112,9 +104,12 @@ func (c *Controller) processNextWorkItem() bool {
 		return false
 	}
 	defer c.queue.Done(key)
-	if err := c.sync(key.(string)); err != nil {
+	startTime := time.Now()
+	err := c.sync(key.(string))
+	controller.ObserveReconcile("dmcluster", startTime, err)
+	if err != nil {
 		if perrors.Find(err, controller.IsRequeueError) != nil {
-			klog.Infof("DMCluster: %v, still need sync: %v, requeuing", key.(string), err)
+			c.clusterLog(key.(string)).Info("Still need sync, requeuing", "reason", err)
 		} else {
 			utilruntime.HandleError(fmt.Errorf("DMCluster: %v, sync failed %v, requeuing", key.(string), err))
 		}
@@ -129,7 +124,7 @@ func (c *Controller) processNextWorkItem() bool {
 func (c *Controller) sync(key string) error {
 	startTime := time.Now()
 	defer func() {
-		klog.V(4).Infof("Finished syncing DMCluster %q (%v)", key, time.Since(startTime))
+		c.clusterLog(key).V(4).Info("Finished syncing", "duration", time.Since(startTime))
 	}()
 
 	ns, name, err := cache.SplitMetaNamespaceKey(key)
@@ -138,7 +133,7 @@ func (c *Controller) sync(key string) error {
 	}
 	dc, err := c.deps.DMClusterLister.DMClusters(ns).Get(name)
 	if errors.IsNotFound(err) {
-		klog.Infof("DMCluster has been deleted %v", key)
+		c.clusterLog(key).Info("DMCluster has been deleted")
 		return nil
 	}
 	if err != nil {
@@ -148,6 +143,15 @@ func (c *Controller) sync(key string) error {
 	return c.syncDMCluster(dc.DeepCopy())
 }
 
+// clusterLog returns the logger of the DMCluster of key
+func (c *Controller) clusterLog(key string) logging.Logger {
+	ns, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return c.log.WithValues("key", key)
+	}
+	return c.log.WithCluster(ns, name)
+}
+
 func (c *Controller) syncDMCluster(dc *v1alpha1.DMCluster) error {
 	return c.control.UpdateDMCluster(dc)
 }
@@ -162,6 +166,29 @@ func (c *Controller) enqueueDMCluster(obj interface{}) {
 	c.queue.Add(key)
 }
 
+// updateDMCluster enqueues the DMCluster if it is changed, the backoff of the DMCluster is reset if its spec or
+// metadata is changed, and the status-only changes are skipped while the DMCluster is backing off, so
+// that writing the status of a DMCluster waiting for something does not lead to a hot reconcile loop.
+func (c *Controller) updateDMCluster(old, cur interface{}) {
+	oldDC := old.(*v1alpha1.DMCluster)
+	curDC := cur.(*v1alpha1.DMCluster)
+	key, err := cache.MetaNamespaceKeyFunc(curDC)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("Cound't get key for object %+v: %v", curDC, err))
+		return
+	}
+	if !apiequality.Semantic.DeepEqual(oldDC.Spec, curDC.Spec) ||
+		!apiequality.Semantic.DeepEqual(oldDC.Labels, curDC.Labels) ||
+		!apiequality.Semantic.DeepEqual(oldDC.Annotations, curDC.Annotations) ||
+		curDC.DeletionTimestamp != nil {
+		c.queue.Forget(key)
+	} else if c.queue.NumRequeues(key) > 0 {
+		c.clusterLog(key).V(4).Info("Backing off, skip syncing the status change")
+		return
+	}
+	c.queue.Add(key)
+}
+
 // addStatefulSet adds the dmcluster for the statefulset to the sync queue
 func (c *Controller) addStatefulSet(obj interface{}) {
 	set := obj.(*apps.StatefulSet)
@@ -180,7 +207,7 @@ func (c *Controller) addStatefulSet(obj interface{}) {
 	if dc == nil {
 		return
 	}
-	klog.V(4).Infof("StatefulSet %s/%s created, DMCluster: %s/%s", ns, setName, ns, dc.Name)
+	c.log.WithCluster(ns, dc.Name).V(4).Info("StatefulSet created", "statefulset", setName)
 	c.enqueueDMCluster(dc)
 }
 
@@ -201,7 +228,7 @@ func (c *Controller) updateStatefulSet(old, cur interface{}) {
 	if dc == nil {
 		return
 	}
-	klog.V(4).Infof("StatefulSet %s/%s updated, DMCluster: %s/%s", ns, setName, ns, dc.Name)
+	c.log.WithCluster(ns, dc.Name).V(4).Info("StatefulSet updated", "statefulset", setName)
 	c.enqueueDMCluster(dc)
 }
 
@@ -232,7 +259,7 @@ func (c *Controller) deleteStatefulSet(obj interface{}) {
 	if dc == nil {
 		return
 	}
-	klog.V(4).Infof("StatefulSet %s/%s deleted through %v.", ns, setName, utilruntime.GetCaller())
+	c.log.WithCluster(ns, dc.Name).V(4).Info("StatefulSet deleted", "statefulset", setName, "caller", utilruntime.GetCaller())
 	c.enqueueDMCluster(dc)
 }
 
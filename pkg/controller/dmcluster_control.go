@@ -20,6 +20,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
 	tcinformers "github.com/pingcap/tidb-operator/pkg/client/informers/externalversions/pingcap/v1alpha1"
 	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
@@ -53,9 +54,18 @@ func (c *realDMClusterControl) UpdateDMCluster(dc *v1alpha1.DMCluster, newStatus
 	ns := dc.GetNamespace()
 	dcName := dc.GetName()
 
-	status := dc.Status.DeepCopy()
+	desired := dc.DeepCopy()
 	var updateDC *v1alpha1.DMCluster
 
+	// skip the no-op update if the cached object is the same, e.g. the status has been written by a previous sync
+	if cached, err := c.dcLister.DMClusters(ns).Get(dcName); err == nil && dcUpToDate(cached, desired) {
+		klog.V(4).Infof("DMCluster: [%s/%s] is up to date, skip updating", ns, dcName)
+		return cached.DeepCopy(), nil
+	}
+	// the spec, labels and annotations changed meanwhile by others are kept on conflicts,
+	// only the status is applied again onto the refreshed object
+	specConflicted := false
+
 	// don't wait due to limited number of clients, but backoff after the default number of steps
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		var updateErr error
@@ -67,9 +77,10 @@ func (c *realDMClusterControl) UpdateDMCluster(dc *v1alpha1.DMCluster, newStatus
 		klog.V(4).Infof("failed to update DMCluster: [%s/%s], error: %v", ns, dcName, updateErr)
 
 		if updated, err := c.dcLister.DMClusters(ns).Get(dcName); err == nil {
+			specConflicted = !dcMetaSpecUpToDate(updated, desired)
 			// make a copy so we don't mutate the shared cache
 			dc = updated.DeepCopy()
-			dc.Status = *status
+			dc.Status = *desired.Status.DeepCopy()
 		} else {
 			utilruntime.HandleError(fmt.Errorf("error getting updated DMCluster %s/%s from lister: %v", ns, dcName, err))
 		}
@@ -78,8 +89,27 @@ func (c *realDMClusterControl) UpdateDMCluster(dc *v1alpha1.DMCluster, newStatus
 	})
 	if err != nil {
 		klog.Errorf("failed to update DMCluster: [%s/%s], error: %v", ns, dcName, err)
+		return updateDC, err
 	}
-	return updateDC, err
+	if specConflicted {
+		// the spec changes are computed again by the caller from the refreshed object
+		return updateDC, RequeueErrorf("the spec, labels or annotations of DMCluster %s/%s are changed concurrently, only the status is updated", ns, dcName)
+	}
+	return updateDC, nil
+}
+
+// dcUpToDate returns whether the spec, labels, annotations and status of the cached DMCluster
+// are semantically equal to the desired ones
+func dcUpToDate(cached, desired *v1alpha1.DMCluster) bool {
+	return dcMetaSpecUpToDate(cached, desired) && apiequality.Semantic.DeepEqual(&cached.Status, &desired.Status)
+}
+
+// dcMetaSpecUpToDate returns whether the spec, labels and annotations of the cached DMCluster
+// are semantically equal to the desired ones
+func dcMetaSpecUpToDate(cached, desired *v1alpha1.DMCluster) bool {
+	return apiequality.Semantic.DeepEqual(cached.Labels, desired.Labels) &&
+		apiequality.Semantic.DeepEqual(cached.Annotations, desired.Annotations) &&
+		apiequality.Semantic.DeepEqual(&cached.Spec, &desired.Spec)
 }
 
 // FakeDMClusterControl is a fake DMClusterControlInterface
@@ -34,7 +34,9 @@ func TestDMClusterControlUpdateDMCluster(t *testing.T) {
 	dc := newDMCluster()
 	dc.Spec.Master.Replicas = int32(5)
 	fakeClient := &fake.Clientset{}
-	control := NewRealDMClusterControl(fakeClient, nil, recorder)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	dcLister := listers.NewDMClusterLister(indexer)
+	control := NewRealDMClusterControl(fakeClient, dcLister, recorder)
 	fakeClient.AddReactor("update", "dmclusters", func(action core.Action) (bool, runtime.Object, error) {
 		update := action.(core.UpdateAction)
 		return true, update.GetObject(), nil
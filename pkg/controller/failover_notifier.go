@@ -0,0 +1,153 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+// FailoverAction is the action taken by the failover of a component
+type FailoverAction string
+
+const (
+	// FailoverMemberFailed means a member is marked as failed
+	FailoverMemberFailed FailoverAction = "FailoverMemberFailed"
+	// FailoverReplacementCreated means a replacement replica is added for a failed member
+	FailoverReplacementCreated FailoverAction = "FailoverReplacementCreated"
+	// FailoverRecovered means a failed member is recovered and its failover replica is removed
+	FailoverRecovered FailoverAction = "FailoverRecovered"
+	// FailoverSimulated means the failover of a component is simulated, the failure members and the
+	// failover replicas are recorded without taking any action
+	FailoverSimulated FailoverAction = "FailoverSimulated"
+)
+
+// failoverWebhookTimeout is the timeout to post a notification to the failover webhook
+const failoverWebhookTimeout = 5 * time.Second
+
+// FailoverNotification describes a failover action taken on a member of a cluster,
+// it is posted to the failover webhook as JSON
+type FailoverNotification struct {
+	Namespace string         `json:"namespace"`
+	Cluster   string         `json:"cluster"`
+	Component string         `json:"component"`
+	Action    FailoverAction `json:"action"`
+	PodName   string         `json:"podName,omitempty"`
+	StoreID   string         `json:"storeID,omitempty"`
+	MemberID  string         `json:"memberID,omitempty"`
+	Reason    string         `json:"reason"`
+	Time      metav1.Time    `json:"time"`
+}
+
+// Message returns the message of the event of the notification
+func (n *FailoverNotification) Message() string {
+	target := n.Component
+	if n.PodName != "" {
+		target = fmt.Sprintf("%s pod[%s]", n.Component, n.PodName)
+	}
+	var ids []string
+	if n.StoreID != "" {
+		ids = append(ids, fmt.Sprintf("store[%s]", n.StoreID))
+	}
+	if n.MemberID != "" {
+		ids = append(ids, fmt.Sprintf("member[%s]", n.MemberID))
+	}
+	if len(ids) > 0 {
+		target = fmt.Sprintf("%s %s", target, strings.Join(ids, " "))
+	}
+	return fmt.Sprintf("%s: %s", target, n.Reason)
+}
+
+// FailoverNotifierInterface notifies the failover actions of the components of the clusters
+// to the external systems, the actions are recorded as events by the callers
+type FailoverNotifierInterface interface {
+	Notify(n FailoverNotification)
+}
+
+type realFailoverNotifier struct {
+	webhookURL string
+	httpClient *http.Client
+}
+
+// NewRealFailoverNotifier returns a FailoverNotifierInterface which posts the failover actions
+// to webhookURL in background, nothing is posted if webhookURL is empty
+func NewRealFailoverNotifier(webhookURL string) FailoverNotifierInterface {
+	return &realFailoverNotifier{
+		webhookURL: webhookURL,
+		httpClient: &http.Client{Timeout: failoverWebhookTimeout},
+	}
+}
+
+func (n *realFailoverNotifier) Notify(notification FailoverNotification) {
+	if n.webhookURL == "" {
+		return
+	}
+	// do not block the sync of the cluster by the webhook
+	go func() {
+		if err := n.post(&notification); err != nil {
+			klog.Errorf("failed to post failover notification %s of %s/%s to webhook: %v", notification.Action, notification.Namespace, notification.Cluster, err)
+		}
+	}()
+}
+
+func (n *realFailoverNotifier) post(notification *FailoverNotification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	res, err := n.httpClient.Post(n.webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unexpected status %s", res.Status)
+	}
+	return nil
+}
+
+// FakeFailoverNotifier is a fake FailoverNotifierInterface which keeps the notifications
+type FakeFailoverNotifier struct {
+	mu            sync.Mutex
+	notifications []FailoverNotification
+}
+
+// NewFakeFailoverNotifier returns a FakeFailoverNotifier
+func NewFakeFailoverNotifier() *FakeFailoverNotifier {
+	return &FakeFailoverNotifier{}
+}
+
+func (n *FakeFailoverNotifier) Notify(notification FailoverNotification) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.notifications = append(n.notifications, notification)
+}
+
+// Notifications returns the notifications received so far
+func (n *FakeFailoverNotifier) Notifications() []FailoverNotification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]FailoverNotification(nil), n.notifications...)
+}
+
+var _ FailoverNotifierInterface = &realFailoverNotifier{}
+var _ FailoverNotifierInterface = &FakeFailoverNotifier{}
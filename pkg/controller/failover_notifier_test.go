@@ -0,0 +1,72 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestFailoverNotificationMessage(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	n := FailoverNotification{Component: "tikv", PodName: "demo-tikv-1", StoreID: "4", Reason: "store is Down"}
+	g.Expect(n.Message()).To(Equal("tikv pod[demo-tikv-1] store[4]: store is Down"))
+	n = FailoverNotification{Component: "pd", PodName: "demo-pd-0", MemberID: "123", Reason: "member is unhealthy"}
+	g.Expect(n.Message()).To(Equal("pd pod[demo-pd-0] member[123]: member is unhealthy"))
+	n = FailoverNotification{Component: "tidb", Reason: "recovered"}
+	g.Expect(n.Message()).To(Equal("tidb: recovered"))
+}
+
+func TestRealFailoverNotifier(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	received := make(chan FailoverNotification, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n FailoverNotification
+		if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- n
+	}))
+	defer server.Close()
+
+	notifier := NewRealFailoverNotifier(server.URL)
+	notifier.Notify(FailoverNotification{
+		Namespace: "ns",
+		Cluster:   "demo",
+		Component: "tikv",
+		Action:    FailoverMemberFailed,
+		PodName:   "demo-tikv-1",
+		StoreID:   "4",
+		Reason:    "store is Down",
+	})
+
+	var n FailoverNotification
+	g.Eventually(received, 5*time.Second).Should(Receive(&n))
+	g.Expect(n.Namespace).To(Equal("ns"))
+	g.Expect(n.Cluster).To(Equal("demo"))
+	g.Expect(n.Action).To(Equal(FailoverMemberFailed))
+	g.Expect(n.StoreID).To(Equal("4"))
+
+	// nothing is posted without the webhook URL
+	NewRealFailoverNotifier("").Notify(FailoverNotification{Action: FailoverRecovered})
+	g.Consistently(received, 100*time.Millisecond).ShouldNot(Receive())
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// FederationKubeConfigSecretKey is the key of the kubeconfig in the secret referred by a federation member
+const FederationKubeConfigSecretKey = "kubeconfig"
+
+// FederationMemberClientFunc returns the client of the Kubernetes cluster a federation member lives in,
+// ns is the namespace of the object referring the member
+type FederationMemberClientFunc func(deps *Dependencies, ns string, member v1alpha1.TidbClusterFederationMember) (versioned.Interface, error)
+
+// NewFederationMemberClient returns the client of the local Kubernetes cluster, or the one built from
+// the kubeconfig in the secret referred by the member in namespace ns
+func NewFederationMemberClient(deps *Dependencies, ns string, member v1alpha1.TidbClusterFederationMember) (versioned.Interface, error) {
+	if member.KubeConfigSecretName == "" {
+		return deps.Clientset, nil
+	}
+	secret, err := deps.SecretLister.Secrets(ns).Get(member.KubeConfigSecretName)
+	if err != nil {
+		return nil, err
+	}
+	kubeConfig, ok := secret.Data[FederationKubeConfigSecretKey]
+	if !ok {
+		return nil, fmt.Errorf("key %s is not found in secret %s/%s", FederationKubeConfigSecretKey, ns, member.KubeConfigSecretName)
+	}
+	cfg, err := clientcmd.RESTConfigFromKubeConfig(kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the kubeconfig in secret %s/%s: %v", ns, member.KubeConfigSecretName, err)
+	}
+	return versioned.NewForConfig(cfg)
+}
+
+// FederationMemberNamespace returns the namespace of the member, which defaults to namespace ns
+// of the object referring it
+func FederationMemberNamespace(ns string, member v1alpha1.TidbClusterFederationMember) string {
+	if member.Namespace != "" {
+		return member.Namespace
+	}
+	return ns
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/apiserver/pkg/server/healthz"
+	"k8s.io/client-go/discovery"
+)
+
+// InformerSyncChecker tracks the cache sync of the informers started by the leader,
+// the informers of the other instances are not started so they are always healthy
+type InformerSyncChecker struct {
+	lock      sync.RWMutex
+	startTime time.Time
+	synced    bool
+	now       func() time.Time
+}
+
+// NewInformerSyncChecker returns a InformerSyncChecker
+func NewInformerSyncChecker() *InformerSyncChecker {
+	return &InformerSyncChecker{now: time.Now}
+}
+
+// Started is called before the informers start to wait for the cache sync
+func (c *InformerSyncChecker) Started() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.startTime = c.now()
+	c.synced = false
+}
+
+// Synced is called after the caches of all the informers are synced
+func (c *InformerSyncChecker) Synced() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.synced = true
+}
+
+// Checker returns a health checker which fails if the caches are not synced
+// within timeout after the informers are started, 0 means failing immediately
+func (c *InformerSyncChecker) Checker(timeout time.Duration) healthz.HealthChecker {
+	return healthz.NamedCheck("informer-sync", func(_ *http.Request) error {
+		c.lock.RLock()
+		defer c.lock.RUnlock()
+		if c.startTime.IsZero() || c.synced {
+			return nil
+		}
+		if elapsed := c.now().Sub(c.startTime); elapsed >= timeout {
+			return fmt.Errorf("caches of informers are not synced in %s", elapsed.Round(time.Second))
+		}
+		return nil
+	})
+}
+
+// APIServerChecker returns a health checker which fails if the kube-apiserver is unreachable
+func APIServerChecker(cli discovery.DiscoveryInterface) healthz.HealthChecker {
+	return healthz.NamedCheck("kube-apiserver", func(_ *http.Request) error {
+		if _, err := cli.ServerVersion(); err != nil {
+			return fmt.Errorf("kube-apiserver is unreachable: %v", err)
+		}
+		return nil
+	})
+}
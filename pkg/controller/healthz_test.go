@@ -0,0 +1,77 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+)
+
+func TestInformerSyncChecker(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	now := time.Now()
+	c := NewInformerSyncChecker()
+	c.now = func() time.Time { return now }
+	liveness := c.Checker(time.Minute)
+	readiness := c.Checker(0)
+	g.Expect(liveness.Name()).To(Equal("informer-sync"))
+
+	// the informers are not started if it is not the leader
+	g.Expect(liveness.Check(nil)).To(Succeed())
+	g.Expect(readiness.Check(nil)).To(Succeed())
+
+	c.Started()
+	g.Expect(liveness.Check(nil)).To(Succeed())
+	g.Expect(readiness.Check(nil)).NotTo(Succeed())
+
+	now = now.Add(2 * time.Minute)
+	g.Expect(liveness.Check(nil)).NotTo(Succeed())
+
+	c.Synced()
+	g.Expect(liveness.Check(nil)).To(Succeed())
+	g.Expect(readiness.Check(nil)).To(Succeed())
+
+	// the informers are started again after the leadership is regained
+	c.Started()
+	g.Expect(readiness.Check(nil)).NotTo(Succeed())
+}
+
+func TestAPIServerChecker(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	healthy := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"major":"1","minor":"16","gitVersion":"v1.16.0"}`))
+	}))
+	defer srv.Close()
+
+	checker := APIServerChecker(discovery.NewDiscoveryClientForConfigOrDie(&rest.Config{Host: srv.URL}))
+	g.Expect(checker.Name()).To(Equal("kube-apiserver"))
+	g.Expect(checker.Check(nil)).To(Succeed())
+
+	healthy = false
+	g.Expect(checker.Check(nil)).NotTo(Succeed())
+}
@@ -0,0 +1,143 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+const (
+	// EndpointsLeasesResourceLock holds both the Endpoints and the Lease lock, it is used to
+	// migrate the leader election from Endpoints to Leases without two leaders during the rollout
+	EndpointsLeasesResourceLock = "endpointsleases"
+	// UnknownLeader is the holder of the lock when the Endpoints and the Lease lock are held by
+	// different holders, the candidates wait for the lock to expire then
+	UnknownLeader = "leaderelection.tidb.pingcap.com/unknown"
+)
+
+// LeaderResourceLocks are the supported resource locks of the leader election
+var LeaderResourceLocks = []string{
+	resourcelock.EndpointsResourceLock,
+	resourcelock.LeasesResourceLock,
+	EndpointsLeasesResourceLock,
+}
+
+// ValidateLeaderElection checks whether the leader election config is valid.
+func (c *CLIConfig) ValidateLeaderElection() error {
+	valid := false
+	for _, lock := range LeaderResourceLocks {
+		if c.LeaderResourceLock == lock {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return fmt.Errorf("invalid leader-resource-lock %q, it must be one of %v", c.LeaderResourceLock, LeaderResourceLocks)
+	}
+	if c.RetryPeriod <= 0 {
+		return fmt.Errorf("leader-retry-period must be greater than zero")
+	}
+	if c.RenewDeadline <= c.RetryPeriod {
+		return fmt.Errorf("leader-renew-deadline %s must be greater than leader-retry-period %s", c.RenewDeadline, c.RetryPeriod)
+	}
+	if c.LeaseDuration <= c.RenewDeadline {
+		return fmt.Errorf("leader-lease-duration %s must be greater than leader-renew-deadline %s", c.LeaseDuration, c.RenewDeadline)
+	}
+	return nil
+}
+
+// NewLeaderElectionLock creates the resource lock of the leader election
+func NewLeaderElectionLock(lockType, ns, name string, kubeCli kubernetes.Interface, rlc resourcelock.ResourceLockConfig) (resourcelock.Interface, error) {
+	if lockType != EndpointsLeasesResourceLock {
+		return resourcelock.New(lockType, ns, name, kubeCli.CoreV1(), kubeCli.CoordinationV1(), rlc)
+	}
+	primary, err := resourcelock.New(resourcelock.EndpointsResourceLock, ns, name, kubeCli.CoreV1(), kubeCli.CoordinationV1(), rlc)
+	if err != nil {
+		return nil, err
+	}
+	secondary, err := resourcelock.New(resourcelock.LeasesResourceLock, ns, name, kubeCli.CoreV1(), kubeCli.CoordinationV1(), rlc)
+	if err != nil {
+		return nil, err
+	}
+	return &multiLock{primary: primary, secondary: secondary}, nil
+}
+
+// multiLock is held only if both the primary and the secondary lock are held,
+// the instances using either the primary lock or both locks can elect a single leader
+type multiLock struct {
+	primary   resourcelock.Interface
+	secondary resourcelock.Interface
+}
+
+var _ resourcelock.Interface = &multiLock{}
+
+// Get returns the record of the primary lock, the holder is unknown if the locks are held by different holders
+func (ml *multiLock) Get() (*resourcelock.LeaderElectionRecord, error) {
+	primary, err := ml.primary.Get()
+	if err != nil {
+		return nil, err
+	}
+	secondary, err := ml.secondary.Get()
+	if err != nil {
+		// the primary lock is held by an instance not using the secondary lock
+		if apierrors.IsNotFound(err) && primary.HolderIdentity != ml.Identity() {
+			return primary, nil
+		}
+		return nil, err
+	}
+	if primary.HolderIdentity != secondary.HolderIdentity {
+		primary.HolderIdentity = UnknownLeader
+	}
+	return primary, nil
+}
+
+// Create creates both locks
+func (ml *multiLock) Create(ler resourcelock.LeaderElectionRecord) error {
+	err := ml.primary.Create(ler)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return ml.secondary.Create(ler)
+}
+
+// Update updates both locks, the secondary lock is created if it does not exist
+func (ml *multiLock) Update(ler resourcelock.LeaderElectionRecord) error {
+	if err := ml.primary.Update(ler); err != nil {
+		return err
+	}
+	if _, err := ml.secondary.Get(); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ml.secondary.Create(ler)
+		}
+		return err
+	}
+	return ml.secondary.Update(ler)
+}
+
+func (ml *multiLock) RecordEvent(s string) {
+	ml.primary.RecordEvent(s)
+	ml.secondary.RecordEvent(s)
+}
+
+func (ml *multiLock) Identity() string {
+	return ml.primary.Identity()
+}
+
+func (ml *multiLock) Describe() string {
+	return ml.primary.Describe()
+}
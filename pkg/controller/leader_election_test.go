@@ -0,0 +1,87 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+func TestValidateLeaderElection(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cfg := DefaultCLIConfig()
+	g.Expect(cfg.ValidateLeaderElection()).To(Succeed())
+
+	cfg.LeaderResourceLock = resourcelock.ConfigMapsResourceLock
+	g.Expect(cfg.ValidateLeaderElection()).NotTo(Succeed())
+
+	cfg = DefaultCLIConfig()
+	cfg.RenewDeadline = cfg.LeaseDuration
+	g.Expect(cfg.ValidateLeaderElection()).NotTo(Succeed())
+
+	cfg = DefaultCLIConfig()
+	cfg.RetryPeriod = cfg.RenewDeadline
+	g.Expect(cfg.ValidateLeaderElection()).NotTo(Succeed())
+}
+
+func TestEndpointsLeasesLock(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	newLock := func(kubeCli *kubefake.Clientset, lockType, identity string) resourcelock.Interface {
+		lock, err := NewLeaderElectionLock(lockType, "ns", "lock", kubeCli, resourcelock.ResourceLockConfig{Identity: identity})
+		g.Expect(err).NotTo(HaveOccurred())
+		return lock
+	}
+	record := func(identity string) resourcelock.LeaderElectionRecord {
+		now := metav1.NewTime(time.Now())
+		return resourcelock.LeaderElectionRecord{HolderIdentity: identity, LeaseDurationSeconds: 15, AcquireTime: now, RenewTime: now}
+	}
+
+	// both locks are created
+	kubeCli := kubefake.NewSimpleClientset()
+	lock := newLock(kubeCli, EndpointsLeasesResourceLock, "new")
+	g.Expect(lock.Create(record("new"))).To(Succeed())
+	ler, err := lock.Get()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ler.HolderIdentity).To(Equal("new"))
+	_, err = kubeCli.CoordinationV1().Leases("ns").Get("lock", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// the endpoints lock is held by an old instance
+	kubeCli = kubefake.NewSimpleClientset()
+	old := newLock(kubeCli, resourcelock.EndpointsResourceLock, "old")
+	g.Expect(old.Create(record("old"))).To(Succeed())
+	lock = newLock(kubeCli, EndpointsLeasesResourceLock, "new")
+	ler, err = lock.Get()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ler.HolderIdentity).To(Equal("old"))
+
+	// the lease is created when the lock is taken over
+	g.Expect(lock.Update(record("new"))).To(Succeed())
+	ler, err = newLock(kubeCli, resourcelock.LeasesResourceLock, "new").Get()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ler.HolderIdentity).To(Equal("new"))
+
+	// the holder is unknown if the locks are held by different instances
+	g.Expect(old.Update(record("old"))).To(Succeed())
+	ler, err = lock.Get()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ler.HolderIdentity).To(Equal(UnknownLeader))
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package nodemaintenance dedicates the node maintenance controller.
+// This controller watches the nodes and moves the leaders away from the pods
+// of the TidbClusters on the nodes to be drained, e.g. by `kubectl drain` or
+// cluster-autoscaler, before the pods are removed by the eviction API.
+package nodemaintenance
+
+import (
+	"fmt"
+	"time"
+
+	perrors "github.com/pingcap/errors"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager/member"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+)
+
+// Controller prepares the pods on the nodes to be drained
+type Controller struct {
+	deps       *controller.Dependencies
+	maintainer member.NodeMaintainer
+	queue      workqueue.RateLimitingInterface
+}
+
+// NewController creates a node maintenance controller.
+func NewController(deps *controller.Dependencies) *Controller {
+	c := &Controller{
+		deps:       deps,
+		maintainer: member.NewNodeMaintainer(deps),
+		queue: workqueue.NewNamedRateLimitingQueue(
+			controller.NewControllerRateLimiter(1*time.Second, 100*time.Second),
+			"nodemaintenance",
+		),
+	}
+
+	// the nodes are resynced periodically, so that the stores whose leaders are evicted
+	// are restored once their pods are ready on other nodes
+	nodeInformer := deps.KubeInformerFactory.Core().V1().Nodes()
+	nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueueNode,
+		UpdateFunc: func(_, cur interface{}) {
+			c.enqueueNode(cur)
+		},
+	})
+
+	return c
+}
+
+// Run run workers
+func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+
+	klog.Info("Starting node maintenance controller")
+	defer klog.Info("Shutting down node maintenance controller")
+
+	controller.RunWorkers(c.queue, workers, c.processNextWorkItem, stopCh)
+}
+
+// processNextWorkItem dequeues items, processes them, and marks them done.
+// It enforces that the syncHandler is never
+// invoked concurrently with the same key.
+func (c *Controller) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+	startTime := time.Now()
+	err := c.sync(key.(string))
+	controller.ObserveReconcile("nodemaintenance", startTime, err)
+	if err != nil {
+		if perrors.Find(err, controller.IsRequeueError) != nil {
+			klog.Infof("Node: %v, still need sync: %v, requeuing", key.(string), err)
+		} else {
+			utilruntime.HandleError(fmt.Errorf("Node: %v, sync failed, err: %v, requeuing", key.(string), err))
+		}
+		c.queue.AddRateLimited(key)
+	} else {
+		c.queue.Forget(key)
+	}
+	return true
+}
+
+func (c *Controller) sync(key string) error {
+	startTime := time.Now()
+	defer func() {
+		klog.V(4).Infof("Finished syncing Node %q (%v)", key, time.Since(startTime))
+	}()
+
+	node, err := c.deps.NodeLister.Get(key)
+	if errors.IsNotFound(err) {
+		klog.Infof("Node %v has been deleted", key)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return c.maintainer.Sync(node.DeepCopy())
+}
+
+func (c *Controller) enqueueNode(obj interface{}) {
+	node, ok := obj.(*corev1.Node)
+	if !ok {
+		return
+	}
+	c.queue.Add(node.Name)
+}
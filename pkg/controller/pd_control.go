@@ -35,6 +35,9 @@ func getPDClientFromService(pdControl pdapi.PDControlInterface, tc *v1alpha1.Tid
 // ClientURL example:
 // ClientURL: https://cluster2-pd-0.cluster2-pd-peer.pingcap.svc.cluster2.local
 func GetPDClient(pdControl pdapi.PDControlInterface, tc *v1alpha1.TidbCluster) pdapi.PDClient {
+	if tc.ExternalPDOnly() {
+		return GetExternalPDClient(pdControl, tc)
+	}
 	pdClient := getPDClientFromService(pdControl, tc)
 
 	if len(tc.Status.PD.PeerMembers) == 0 {
@@ -57,6 +60,25 @@ func GetPDClient(pdControl pdapi.PDControlInterface, tc *v1alpha1.TidbCluster) p
 	return pdClient
 }
 
+// GetExternalPDClient returns the client of the first healthy PD in `spec.pdAddresses` of the TidbCluster
+// which joins the PD cluster out of Kubernetes, the client of the first PD is returned if none is healthy
+func GetExternalPDClient(pdControl pdapi.PDControlInterface, tc *v1alpha1.TidbCluster) pdapi.PDClient {
+	var pdClient pdapi.PDClient
+	for _, address := range tc.Spec.PDAddresses {
+		client := pdControl.GetPeerPDClient(pdapi.Namespace(tc.GetNamespace()), tc.GetName(), tc.IsTLSClusterEnabled(), address, address)
+		if pdClient == nil {
+			pdClient = client
+		}
+		if len(tc.Spec.PDAddresses) == 1 {
+			break
+		}
+		if _, err := client.GetHealth(); err == nil {
+			return client
+		}
+	}
+	return pdClient
+}
+
 // NewFakePDClient creates a fake pdclient that is set as the pd client
 func NewFakePDClient(pdControl *pdapi.FakePDControl, tc *v1alpha1.TidbCluster) *pdapi.FakePDClient {
 	pdClient := pdapi.NewFakePDClient()
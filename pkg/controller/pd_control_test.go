@@ -105,6 +105,26 @@ func TestGetPDClient(t *testing.T) {
 				g.Expect(err).To(HaveOccurred())
 			},
 		},
+		{
+			name: "Test GetPDClient with the PD cluster out of Kubernetes",
+			update: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.PD = nil
+				tc.Spec.PDAddresses = []string{"http://10.0.0.1:2379", "http://10.0.0.2:2379"}
+				tc.Status.PD.PeerMembers = nil
+			},
+			expectFn: func(g *GomegaWithT, b bool) {
+				g.Expect(tc.ExternalPDOnly()).To(BeTrue())
+				pdClient1 := NewFakePDClientWithAddress(pdControl, "http://10.0.0.1:2379")
+				pdClient1.AddReaction(pdapi.GetHealthActionType, func(action *pdapi.Action) (interface{}, error) {
+					return nil, fmt.Errorf("Fake external PD 1 crashed")
+				})
+				pdClient2 := NewFakePDClientWithAddress(pdControl, "http://10.0.0.2:2379")
+				pdClient2.AddReaction(pdapi.GetHealthActionType, func(action *pdapi.Action) (interface{}, error) {
+					return &pdapi.HealthInfo{}, nil
+				})
+				g.Expect(GetPDClient(pdControl, tc)).To(Equal(pdClient2))
+			},
+		},
 	}
 
 	for i := range tests {
@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/label"
 	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/util/tracing"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -39,6 +40,9 @@ type PodControlInterface interface {
 	// TODO change this to UpdatePod
 	UpdateMetaInfo(*v1alpha1.TidbCluster, *corev1.Pod) (*corev1.Pod, error)
 	DeletePod(runtime.Object, *corev1.Pod) error
+	// ForceDeletePod deletes the pod immediately without waiting for the kubelet to confirm,
+	// it must only be used when the node of the pod is known to be gone
+	ForceDeletePod(runtime.Object, *corev1.Pod) error
 	UpdatePod(runtime.Object, *corev1.Pod) (*corev1.Pod, error)
 }
 
@@ -81,7 +85,9 @@ func (c *realPodControl) UpdatePod(controller runtime.Object, pod *corev1.Pod) (
 	// don't wait due to limited number of clients, but backoff after the default number of steps
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		var updateErr error
+		span := tracing.StartObject(controller, "UpdatePod", pod.Name)
 		updatePod, updateErr = c.kubeCli.CoreV1().Pods(namespace).Update(pod)
+		span.End(updateErr)
 		if updateErr == nil {
 			klog.Infof("Pod: [%s/%s] updated successfully, %s: [%s/%s]", namespace, podName, kind, namespace, name)
 			return nil
@@ -121,6 +127,8 @@ func (c *realPodControl) UpdateMetaInfo(tc *v1alpha1.TidbCluster, pod *corev1.Po
 	var pdClient pdapi.PDClient
 	if tc.HeterogeneousWithoutLocalPD() {
 		pdClient = c.pdControl.GetPDClient(pdapi.Namespace(tc.Spec.Cluster.Namespace), tc.Spec.Cluster.Name, tc.IsTLSClusterEnabled())
+	} else if tc.ExternalPDOnly() {
+		pdClient = GetPDClient(c.pdControl, tc)
 	} else {
 		pdClient = c.pdControl.GetPDClient(pdapi.Namespace(tc.GetNamespace()), tcName, tc.IsTLSClusterEnabled())
 	}
@@ -181,7 +189,9 @@ func (c *realPodControl) UpdateMetaInfo(tc *v1alpha1.TidbCluster, pod *corev1.Po
 	var updatePod *corev1.Pod
 	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		var updateErr error
+		span := tracing.StartObject(tc, "UpdatePod", pod.Name)
 		updatePod, updateErr = c.kubeCli.CoreV1().Pods(ns).Update(pod)
+		span.End(updateErr)
 		if updateErr == nil {
 			klog.V(4).Infof("update pod %s/%s with cluster labels %v successfully, TidbCluster: %s", ns, podName, labels, tcName)
 			return nil
@@ -213,7 +223,9 @@ func (c *realPodControl) DeletePod(controller runtime.Object, pod *corev1.Pod) e
 	podName := pod.GetName()
 	preconditions := metav1.Preconditions{UID: &pod.UID, ResourceVersion: &pod.ResourceVersion}
 	deleteOptions := metav1.DeleteOptions{Preconditions: &preconditions}
+	span := tracing.StartObject(controller, "DeletePod", podName)
 	err := c.kubeCli.CoreV1().Pods(namespace).Delete(podName, &deleteOptions)
+	span.End(err)
 	if err != nil {
 		klog.Errorf("failed to delete Pod: [%s/%s], %s: %s, %v", namespace, podName, kind, namespace, err)
 	} else {
@@ -223,6 +235,31 @@ func (c *realPodControl) DeletePod(controller runtime.Object, pod *corev1.Pod) e
 	return err
 }
 
+func (c *realPodControl) ForceDeletePod(controller runtime.Object, pod *corev1.Pod) error {
+	controllerMo, ok := controller.(metav1.Object)
+	if !ok {
+		return fmt.Errorf("%T is not a metav1.Object, cannot call setControllerReference", controller)
+	}
+	kind := controller.GetObjectKind().GroupVersionKind().Kind
+	name := controllerMo.GetName()
+	namespace := controllerMo.GetNamespace()
+
+	podName := pod.GetName()
+	var gracePeriodSeconds int64
+	preconditions := metav1.Preconditions{UID: &pod.UID}
+	deleteOptions := metav1.DeleteOptions{Preconditions: &preconditions, GracePeriodSeconds: &gracePeriodSeconds}
+	span := tracing.StartObject(controller, "DeletePod", podName)
+	err := c.kubeCli.CoreV1().Pods(namespace).Delete(podName, &deleteOptions)
+	span.End(err)
+	if err != nil {
+		klog.Errorf("failed to force delete Pod: [%s/%s], %s: %s, %v", namespace, podName, kind, namespace, err)
+	} else {
+		klog.V(4).Infof("force delete Pod: [%s/%s] successfully, %s: %s", namespace, podName, kind, namespace)
+	}
+	c.recordPodEvent("delete", kind, name, controller, podName, err)
+	return err
+}
+
 func (c *realPodControl) recordPodEvent(verb, kind, name string, object runtime.Object, podName string, err error) {
 	if err == nil {
 		reason := fmt.Sprintf("Successful%s", strings.Title(verb))
@@ -342,6 +379,10 @@ func (c *FakePodControl) DeletePod(_ runtime.Object, pod *corev1.Pod) error {
 	return c.PodIndexer.Delete(pod)
 }
 
+func (c *FakePodControl) ForceDeletePod(controller runtime.Object, pod *corev1.Pod) error {
+	return c.DeletePod(controller, pod)
+}
+
 func (c *FakePodControl) UpdatePod(_ runtime.Object, pod *corev1.Pod) (*corev1.Pod, error) {
 	defer c.updatePodTracker.Inc()
 	if c.updatePodTracker.ErrorReady() {
@@ -18,6 +18,7 @@ import (
 	"strings"
 
 	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/util/tracing"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -74,7 +75,9 @@ func (c *realPVCControl) DeletePVC(controller runtime.Object, pvc *corev1.Persis
 	namespace := controllerMo.GetNamespace()
 
 	pvcName := pvc.GetName()
+	span := tracing.StartObject(controller, "DeletePVC", pvcName)
 	err := c.kubeCli.CoreV1().PersistentVolumeClaims(namespace).Delete(pvcName, nil)
+	span.End(err)
 	if err != nil {
 		klog.Errorf("failed to delete PVC: [%s/%s], %s: %s, %v", namespace, pvcName, kind, name, err)
 	}
@@ -94,7 +97,9 @@ func (c *realPVCControl) CreatePVC(controller runtime.Object, pvc *corev1.Persis
 	namespace := controllerMo.GetNamespace()
 
 	pvcName := pvc.GetName()
+	span := tracing.StartObject(controller, "CreatePVC", pvc.Name)
 	_, err := c.kubeCli.CoreV1().PersistentVolumeClaims(namespace).Create(pvc)
+	span.End(err)
 	if err != nil {
 		klog.Errorf("failed to create PVC: [%s/%s], %s: %s, %v", namespace, pvcName, kind, name, err)
 	}
@@ -119,7 +124,9 @@ func (c *realPVCControl) UpdatePVC(controller runtime.Object, pvc *corev1.Persis
 	var updatePVC *corev1.PersistentVolumeClaim
 	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		var updateErr error
+		span := tracing.StartObject(controller, "UpdatePVC", pvc.Name)
 		updatePVC, updateErr = c.kubeCli.CoreV1().PersistentVolumeClaims(namespace).Update(pvc)
+		span.End(updateErr)
 		if updateErr == nil {
 			klog.Infof("update PVC: [%s/%s] successfully, %s: %s", namespace, pvcName, kind, name)
 			return nil
@@ -184,7 +191,9 @@ func (c *realPVCControl) UpdateMetaInfo(controller runtime.Object, pvc *corev1.P
 	var updatePVC *corev1.PersistentVolumeClaim
 	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		var updateErr error
+		span := tracing.StartObject(controller, "UpdatePVC", pvc.Name)
 		updatePVC, updateErr = c.kubeCli.CoreV1().PersistentVolumeClaims(namespace).Update(pvc)
+		span.End(updateErr)
 		if updateErr == nil {
 			klog.V(4).Infof("update PVC: [%s/%s] successfully, %s: %s", namespace, pvcName, kind, name)
 			return nil
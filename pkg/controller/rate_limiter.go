@@ -14,9 +14,14 @@
 package controller
 
 import (
+	"math"
+	"math/rand"
+	"sync"
 	"time"
 
+	"github.com/pingcap/tidb-operator/pkg/metrics"
 	"golang.org/x/time/rate"
+	"k8s.io/client-go/tools/cache"
 	wq "k8s.io/client-go/util/workqueue"
 )
 
@@ -29,3 +34,89 @@ func NewControllerRateLimiter(baseDelay, maxDelay time.Duration) wq.RateLimiter
 		&wq.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
 	)
 }
+
+// NewClusterRateLimiter returns a RateLimiter like NewControllerRateLimiter, but the per-cluster backoff
+// is jittered, so the clusters waiting for the same condition don't retry in lockstep, and the backoff
+// of each cluster is exported by the controller name, the backoff is reset when the item is forgotten.
+func NewClusterRateLimiter(controllerName string, baseDelay, maxDelay time.Duration) wq.RateLimiter {
+	return wq.NewMaxOfRateLimiter(
+		NewJitteredExponentialRateLimiter(controllerName, baseDelay, maxDelay),
+		// 10 qps, 100 bucket size.  This is only for retry speed and its only the overall factor (not per item)
+		&wq.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+	)
+}
+
+// jitteredExponentialRateLimiter doubles the delay of an item on each failure up to maxDelay, the
+// returned delay is randomized between the half and the whole of the backoff
+type jitteredExponentialRateLimiter struct {
+	controllerName string
+	baseDelay      time.Duration
+	maxDelay       time.Duration
+
+	lock     sync.Mutex
+	failures map[interface{}]int
+}
+
+var _ wq.RateLimiter = &jitteredExponentialRateLimiter{}
+
+// NewJitteredExponentialRateLimiter returns a per-item exponential RateLimiter with jitter, the items
+// are expected to be the namespace/name keys and their backoff is exported by the controller name.
+func NewJitteredExponentialRateLimiter(controllerName string, baseDelay, maxDelay time.Duration) wq.RateLimiter {
+	return &jitteredExponentialRateLimiter{
+		controllerName: controllerName,
+		baseDelay:      baseDelay,
+		maxDelay:       maxDelay,
+		failures:       map[interface{}]int{},
+	}
+}
+
+func (r *jitteredExponentialRateLimiter) When(item interface{}) time.Duration {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	exp := r.failures[item]
+	r.failures[item] = exp + 1
+
+	backoff := float64(r.baseDelay.Nanoseconds()) * math.Pow(2, float64(exp))
+	if backoff > float64(r.maxDelay.Nanoseconds()) {
+		backoff = float64(r.maxDelay.Nanoseconds())
+	}
+	r.observe(item, time.Duration(backoff))
+	return time.Duration(backoff/2 + rand.Float64()*backoff/2)
+}
+
+func (r *jitteredExponentialRateLimiter) NumRequeues(item interface{}) int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.failures[item]
+}
+
+func (r *jitteredExponentialRateLimiter) Forget(item interface{}) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.failures[item]; !ok {
+		return
+	}
+	delete(r.failures, item)
+	if ns, name, ok := splitItem(item); ok {
+		metrics.RequeueBackoff.DeleteLabelValues(r.controllerName, ns, name)
+	}
+}
+
+func (r *jitteredExponentialRateLimiter) observe(item interface{}, backoff time.Duration) {
+	if ns, name, ok := splitItem(item); ok {
+		metrics.RequeueBackoff.WithLabelValues(r.controllerName, ns, name).Set(backoff.Seconds())
+	}
+}
+
+func splitItem(item interface{}) (string, string, bool) {
+	key, ok := item.(string)
+	if !ok {
+		return "", "", false
+	}
+	ns, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return "", "", false
+	}
+	return ns, name, true
+}
@@ -0,0 +1,42 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestJitteredExponentialRateLimiter(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	limiter := NewJitteredExponentialRateLimiter("test", time.Second, 10*time.Second)
+	for _, backoff := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second} {
+		delay := limiter.When("ns/tc")
+		g.Expect(delay).To(BeNumerically(">=", backoff/2))
+		g.Expect(delay).To(BeNumerically("<=", backoff))
+		g.Expect(testutil.ToFloat64(metrics.RequeueBackoff.WithLabelValues("test", "ns", "tc"))).To(Equal(backoff.Seconds()))
+	}
+	g.Expect(limiter.NumRequeues("ns/tc")).To(Equal(6))
+	g.Expect(limiter.NumRequeues("ns/other")).To(Equal(0))
+
+	limiter.Forget("ns/tc")
+	g.Expect(limiter.NumRequeues("ns/tc")).To(Equal(0))
+	delay := limiter.When("ns/tc")
+	g.Expect(delay).To(BeNumerically("<=", time.Second))
+}
@@ -25,7 +25,6 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
@@ -53,37 +52,27 @@ func NewController(deps *controller.Dependencies) *Controller {
 	}
 
 	restoreInformer := deps.InformerFactory.Pingcap().V1alpha1().Restores()
-	restoreInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+	restoreInformer.Informer().AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
 		AddFunc: c.updateRestore,
 		UpdateFunc: func(old, cur interface{}) {
 			c.updateRestore(cur)
 		},
 		DeleteFunc: c.enqueueRestore,
-	})
+	}, deps.CLIConfig.GetResyncDuration("restore"))
 	return c
 }
 
 // Run runs the restore controller.
 func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
 	defer utilruntime.HandleCrash()
-	defer c.queue.ShutDown()
 
 	klog.Info("Starting restore controller")
 	defer klog.Info("Shutting down restore controller")
 
-	for i := 0; i < workers; i++ {
-		go wait.Until(c.worker, time.Second, stopCh)
-	}
-
-	<-stopCh
+	controller.RunWorkers(c.queue, workers, c.processNextWorkItem, stopCh)
 }
 
 // worker runs a worker goroutine that invokes processNextWorkItem until the the controller's queue is closed
-func (c *Controller) worker() {
-	for c.processNextWorkItem() {
-	}
-}
-
 // processNextWorkItem dequeues items, processes them, and marks them done. It enforces that the syncHandler is never
 // invoked concurrently with the same key.
 func (c *Controller) processNextWorkItem() bool {
@@ -92,7 +81,10 @@ func (c *Controller) processNextWorkItem() bool {
 		return false
 	}
 	defer c.queue.Done(key)
-	if err := c.sync(key.(string)); err != nil {
+	startTime := time.Now()
+	err := c.sync(key.(string))
+	controller.ObserveReconcile("restore", startTime, err)
+	if err != nil {
 		if perrors.Find(err, controller.IsRequeueError) != nil {
 			klog.Infof("Restore: %v, still need sync: %v, requeuing", key.(string), err)
 			c.queue.AddRateLimited(key)
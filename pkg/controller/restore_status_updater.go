@@ -66,10 +66,16 @@ func NewRealRestoreConditionUpdater(
 func (u *realRestoreConditionUpdater) Update(restore *v1alpha1.Restore, condition *v1alpha1.RestoreCondition, newStatus *RestoreUpdateStatus) error {
 	ns := restore.GetNamespace()
 	restoreName := restore.GetName()
+	// the restore refetched on conflicts may be of a newer generation which is not processed yet
+	observedGeneration := restore.Generation
 	var isUpdate bool
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		updateRestoreStatus(&restore.Status, newStatus)
 		isUpdate = v1alpha1.UpdateRestoreCondition(&restore.Status, condition)
+		if restore.Status.ObservedGeneration != observedGeneration {
+			restore.Status.ObservedGeneration = observedGeneration
+			isUpdate = true
+		}
 		if isUpdate {
 			_, updateErr := u.cli.PingcapV1alpha1().Restores(ns).Update(restore)
 			if updateErr == nil {
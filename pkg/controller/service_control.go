@@ -17,9 +17,11 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/pingcap/tidb-operator/pkg/util/tracing"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
@@ -37,6 +39,8 @@ var ExternalTrafficPolicy string
 type ServiceControlInterface interface {
 	CreateService(runtime.Object, *corev1.Service) error
 	UpdateService(runtime.Object, *corev1.Service) (*corev1.Service, error)
+	// ApplyService applies the desired Service with server-side apply
+	ApplyService(runtime.Object, *corev1.Service) (*corev1.Service, error)
 	DeleteService(runtime.Object, *corev1.Service) error
 }
 
@@ -63,7 +67,9 @@ func (c *realServiceControl) CreateService(controller runtime.Object, svc *corev
 	kind := controller.GetObjectKind().GroupVersionKind().Kind
 	name := controllerMo.GetName()
 	namespace := controllerMo.GetNamespace()
+	span := tracing.StartObject(controller, "CreateService", svc.Name)
 	_, err := c.kubeCli.CoreV1().Services(namespace).Create(svc)
+	span.End(err)
 	c.recordServiceEvent("create", name, kind, controller, svc, err)
 	return err
 }
@@ -82,7 +88,9 @@ func (c *realServiceControl) UpdateService(controller runtime.Object, svc *corev
 	var updateSvc *corev1.Service
 	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		var updateErr error
+		span := tracing.StartObject(controller, "UpdateService", svc.Name)
 		updateSvc, updateErr = c.kubeCli.CoreV1().Services(namespace).Update(svc)
+		span.End(updateErr)
 		if updateErr == nil {
 			klog.Infof("update Service: [%s/%s] successfully, kind: %s, name: %s", namespace, svcName, kind, name)
 			return nil
@@ -100,6 +108,38 @@ func (c *realServiceControl) UpdateService(controller runtime.Object, svc *corev
 	return updateSvc, err
 }
 
+// ApplyService applies the Service, the fields not set in the desired Service, e.g. the cluster IP, are kept
+func (c *realServiceControl) ApplyService(controller runtime.Object, svc *corev1.Service) (*corev1.Service, error) {
+	controllerMo, ok := controller.(metav1.Object)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a metav1.Object, cannot call setControllerReference", controller)
+	}
+	kind := controller.GetObjectKind().GroupVersionKind().Kind
+	name := controllerMo.GetName()
+	namespace := controllerMo.GetNamespace()
+
+	data, err := applyPatch(svc, corev1.SchemeGroupVersion.WithKind("Service"))
+	if err != nil {
+		return nil, err
+	}
+	appliedSvc := &corev1.Service{}
+	err = c.kubeCli.CoreV1().RESTClient().Patch(types.ApplyPatchType).
+		Namespace(svc.GetNamespace()).
+		Resource("services").
+		Name(svc.GetName()).
+		Param("fieldManager", FieldManager).
+		Param("force", "true").
+		Body(data).
+		Do().
+		Into(appliedSvc)
+	if err != nil {
+		klog.Errorf("failed to apply Service: [%s/%s], kind: %s, name: %s, error: %v", namespace, svc.GetName(), kind, name, err)
+		return nil, err
+	}
+	klog.Infof("apply Service: [%s/%s] successfully, kind: %s, name: %s", namespace, svc.GetName(), kind, name)
+	return appliedSvc, nil
+}
+
 func (c *realServiceControl) DeleteService(controller runtime.Object, svc *corev1.Service) error {
 	controllerMo, ok := controller.(metav1.Object)
 	if !ok {
@@ -109,7 +149,9 @@ func (c *realServiceControl) DeleteService(controller runtime.Object, svc *corev
 	name := controllerMo.GetName()
 	namespace := controllerMo.GetNamespace()
 
+	span := tracing.StartObject(controller, "DeleteService", svc.Name)
 	err := c.kubeCli.CoreV1().Services(namespace).Delete(svc.Name, nil)
+	span.End(err)
 	c.recordServiceEvent("delete", name, kind, controller, svc, err)
 	return err
 }
@@ -216,6 +258,15 @@ func (c *FakeServiceControl) UpdateService(_ runtime.Object, svc *corev1.Service
 	return svc, c.SvcIndexer.Update(svc)
 }
 
+// ApplyService applies the service to SvcIndexer, the cluster IP of the existing service is kept
+func (c *FakeServiceControl) ApplyService(controller runtime.Object, svc *corev1.Service) (*corev1.Service, error) {
+	svc = svc.DeepCopy()
+	if existing, err := c.SvcLister.Services(svc.Namespace).Get(svc.Name); err == nil {
+		svc.Spec.ClusterIP = existing.Spec.ClusterIP
+	}
+	return c.UpdateService(controller, svc)
+}
+
 // DeleteService deletes the service of SvcIndexer
 func (c *FakeServiceControl) DeleteService(_ runtime.Object, _ *corev1.Service) error {
 	return nil
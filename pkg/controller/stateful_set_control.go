@@ -17,11 +17,13 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/pingcap/tidb-operator/pkg/util/tracing"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	appsinformers "k8s.io/client-go/informers/apps/v1"
 	"k8s.io/client-go/kubernetes"
@@ -36,6 +38,8 @@ import (
 type StatefulSetControlInterface interface {
 	CreateStatefulSet(runtime.Object, *apps.StatefulSet) error
 	UpdateStatefulSet(runtime.Object, *apps.StatefulSet) (*apps.StatefulSet, error)
+	// ApplyStatefulSet applies the desired StatefulSet with server-side apply
+	ApplyStatefulSet(runtime.Object, *apps.StatefulSet) (*apps.StatefulSet, error)
 	DeleteStatefulSet(runtime.Object, *apps.StatefulSet) error
 }
 
@@ -60,7 +64,9 @@ func (c *realStatefulSetControl) CreateStatefulSet(controller runtime.Object, se
 	name := controllerMo.GetName()
 	namespace := controllerMo.GetNamespace()
 
+	span := tracing.StartObject(controller, "CreateStatefulSet", set.Name)
 	_, err := c.kubeCli.AppsV1().StatefulSets(namespace).Create(set)
+	span.End(err)
 	// sink already exists errors
 	if apierrors.IsAlreadyExists(err) {
 		return err
@@ -86,7 +92,9 @@ func (c *realStatefulSetControl) UpdateStatefulSet(controller runtime.Object, se
 	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		// TODO: verify if StatefulSet identity(name, namespace, labels) matches TidbCluster
 		var updateErr error
+		span := tracing.StartObject(controller, "UpdateStatefulSet", setName)
 		updatedSS, updateErr = c.kubeCli.AppsV1().StatefulSets(namespace).Update(set)
+		span.End(updateErr)
 		if updateErr == nil {
 			klog.Infof("%s: [%s/%s]'s StatefulSet: [%s/%s] updated successfully", kind, namespace, name, namespace, setName)
 			return nil
@@ -106,6 +114,38 @@ func (c *realStatefulSetControl) UpdateStatefulSet(controller runtime.Object, se
 	return updatedSS, err
 }
 
+// ApplyStatefulSet applies a StatefulSet in a TidbCluster, the fields not set in the desired StatefulSet are kept.
+func (c *realStatefulSetControl) ApplyStatefulSet(controller runtime.Object, set *apps.StatefulSet) (*apps.StatefulSet, error) {
+	controllerMo, ok := controller.(metav1.Object)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a metav1.Object, cannot call setControllerReference", controller)
+	}
+	kind := controller.GetObjectKind().GroupVersionKind().Kind
+	name := controllerMo.GetName()
+	namespace := controllerMo.GetNamespace()
+
+	data, err := applyPatch(set, apps.SchemeGroupVersion.WithKind("StatefulSet"))
+	if err != nil {
+		return nil, err
+	}
+	appliedSS := &apps.StatefulSet{}
+	err = c.kubeCli.AppsV1().RESTClient().Patch(types.ApplyPatchType).
+		Namespace(set.GetNamespace()).
+		Resource("statefulsets").
+		Name(set.GetName()).
+		Param("fieldManager", FieldManager).
+		Param("force", "true").
+		Body(data).
+		Do().
+		Into(appliedSS)
+	if err != nil {
+		klog.Errorf("failed to apply %s: [%s/%s]'s StatefulSet: [%s/%s], error: %v", kind, namespace, name, namespace, set.GetName(), err)
+		return nil, err
+	}
+	klog.Infof("%s: [%s/%s]'s StatefulSet: [%s/%s] applied successfully", kind, namespace, name, namespace, set.GetName())
+	return appliedSS, nil
+}
+
 // DeleteStatefulSet delete a StatefulSet in a TidbCluster.
 func (c *realStatefulSetControl) DeleteStatefulSet(controller runtime.Object, set *apps.StatefulSet) error {
 	controllerMo, ok := controller.(metav1.Object)
@@ -116,7 +156,9 @@ func (c *realStatefulSetControl) DeleteStatefulSet(controller runtime.Object, se
 	name := controllerMo.GetName()
 	namespace := controllerMo.GetNamespace()
 
+	span := tracing.StartObject(controller, "DeleteStatefulSet", set.Name)
 	err := c.kubeCli.AppsV1().StatefulSets(namespace).Delete(set.Name, nil)
+	span.End(err)
 	c.recordStatefulSetEvent("delete", kind, name, controller, set, err)
 	return err
 }
@@ -223,6 +265,15 @@ func (c *FakeStatefulSetControl) UpdateStatefulSet(_ runtime.Object, set *apps.S
 	return set, c.SetIndexer.Update(set)
 }
 
+// ApplyStatefulSet applies the statefulset to SetIndexer, the status of the existing statefulset is kept
+func (c *FakeStatefulSetControl) ApplyStatefulSet(controller runtime.Object, set *apps.StatefulSet) (*apps.StatefulSet, error) {
+	set = set.DeepCopy()
+	if existing, err := c.SetLister.StatefulSets(set.Namespace).Get(set.Name); err == nil {
+		set.Status = existing.Status
+	}
+	return c.UpdateStatefulSet(controller, set)
+}
+
 // DeleteStatefulSet deletes the statefulset of SetIndexer
 func (c *FakeStatefulSetControl) DeleteStatefulSet(_ runtime.Object, _ *apps.StatefulSet) error {
 	return nil
@@ -14,10 +14,14 @@
 package controller
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"net/url"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -31,6 +35,68 @@ type CaptureStatus struct {
 type TiCDCControlInterface interface {
 	// GetStatus returns ticdc's status
 	GetStatus(tc *v1alpha1.TidbCluster, ordinal int32) (*CaptureStatus, error)
+	// DrainCapture asks the owner capture of the ordinal to move the tables away from the capture,
+	// it returns the number of the tables left on the capture
+	DrainCapture(tc *v1alpha1.TidbCluster, ownerOrdinal int32, captureID string) (int, error)
+	// ResignOwner asks the owner capture of the ordinal to resign
+	ResignOwner(tc *v1alpha1.TidbCluster, ordinal int32) error
+	// GetChangefeeds returns the changefeeds of the ticdc cluster
+	GetChangefeeds(tc *v1alpha1.TidbCluster) ([]ChangefeedInfo, error)
+	// CreateChangefeed creates a changefeed in the ticdc cluster
+	CreateChangefeed(tc *v1alpha1.TidbCluster, req *CreateChangefeedRequest) error
+	// UpdateChangefeed updates the sink and the filter rules of the stopped changefeed of the id
+	UpdateChangefeed(tc *v1alpha1.TidbCluster, id string, req *UpdateChangefeedRequest) error
+	// PauseChangefeed pauses the changefeed of the id
+	PauseChangefeed(tc *v1alpha1.TidbCluster, id string) error
+	// ResumeChangefeed resumes the changefeed of the id
+	ResumeChangefeed(tc *v1alpha1.TidbCluster, id string) error
+	// RemoveChangefeed removes the changefeed of the id
+	RemoveChangefeed(tc *v1alpha1.TidbCluster, id string) error
+}
+
+// States of the changefeeds reported by ticdc
+const (
+	ChangefeedStateNormal   = "normal"
+	ChangefeedStateStopped  = "stopped"
+	ChangefeedStateError    = "error"
+	ChangefeedStateFailed   = "failed"
+	ChangefeedStateFinished = "finished"
+)
+
+// ChangefeedInfo is a changefeed returned by the ticdc API
+type ChangefeedInfo struct {
+	ID            string           `json:"id"`
+	State         string           `json:"state"`
+	CheckpointTSO uint64           `json:"checkpoint_tso"`
+	Error         *ChangefeedError `json:"error,omitempty"`
+}
+
+type ChangefeedError struct {
+	Addr    string `json:"addr"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// CreateChangefeedRequest is the request to create a changefeed by the ticdc API
+type CreateChangefeedRequest struct {
+	ID          string   `json:"changefeed_id"`
+	SinkURI     string   `json:"sink_uri"`
+	StartTS     uint64   `json:"start_ts,omitempty"`
+	FilterRules []string `json:"filter_rules,omitempty"`
+}
+
+// UpdateChangefeedRequest is the request to update a stopped changefeed by the ticdc API
+type UpdateChangefeedRequest struct {
+	SinkURI     string   `json:"sink_uri,omitempty"`
+	FilterRules []string `json:"filter_rules,omitempty"`
+}
+
+type drainCaptureRequest struct {
+	CaptureID string `json:"capture_id"`
+}
+
+type drainCaptureResponse struct {
+	CurrentTableCount int `json:"current_table_count"`
 }
 
 // defaultTiCDCControl is default implementation of TiCDCControlInterface.
@@ -40,9 +106,9 @@ type defaultTiCDCControl struct {
 	testURL string
 }
 
-// NewDefaultTiCDCControl returns a defaultTiCDCControl instance
-func NewDefaultTiCDCControl(kubeCli kubernetes.Interface) *defaultTiCDCControl {
-	return &defaultTiCDCControl{httpClient: httpClient{kubeCli: kubeCli}}
+// NewDefaultTiCDCControl returns a defaultTiCDCControl instance, which loads the client tls.Configs from tlsConfigs
+func NewDefaultTiCDCControl(kubeCli kubernetes.Interface, tlsConfigs *pdapi.TLSConfigCache) *defaultTiCDCControl {
+	return &defaultTiCDCControl{httpClient: httpClient{kubeCli: kubeCli, tlsConfigs: tlsConfigs}}
 }
 
 func (c *defaultTiCDCControl) GetStatus(tc *v1alpha1.TidbCluster, ordinal int32) (*CaptureStatus, error) {
@@ -63,6 +129,121 @@ func (c *defaultTiCDCControl) GetStatus(tc *v1alpha1.TidbCluster, ordinal int32)
 	return &status, err
 }
 
+func (c *defaultTiCDCControl) DrainCapture(tc *v1alpha1.TidbCluster, ownerOrdinal int32, captureID string) (int, error) {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := json.Marshal(&drainCaptureRequest{CaptureID: captureID})
+	if err != nil {
+		return 0, err
+	}
+	baseURL := c.getBaseURL(tc, ownerOrdinal)
+	url := fmt.Sprintf("%s/api/v1/captures/drain", baseURL)
+	body, err := httputil.DoBodyOK(httpClient, url, "PUT", bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+
+	resp := drainCaptureResponse{}
+	err = json.Unmarshal(body, &resp)
+	return resp.CurrentTableCount, err
+}
+
+func (c *defaultTiCDCControl) ResignOwner(tc *v1alpha1.TidbCluster, ordinal int32) error {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return err
+	}
+
+	baseURL := c.getBaseURL(tc, ordinal)
+	url := fmt.Sprintf("%s/api/v1/owner/resign", baseURL)
+	_, err = httputil.PostBodyOK(httpClient, url, nil)
+	return err
+}
+
+// the changefeed requests are sent to the first capture, which forwards them to the owner
+func (c *defaultTiCDCControl) GetChangefeeds(tc *v1alpha1.TidbCluster) ([]ChangefeedInfo, error) {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/api/v1/changefeeds", c.getBaseURL(tc, 0))
+	body, err := getBodyOK(httpClient, url)
+	if err != nil {
+		return nil, err
+	}
+
+	changefeeds := []ChangefeedInfo{}
+	err = json.Unmarshal(body, &changefeeds)
+	return changefeeds, err
+}
+
+func (c *defaultTiCDCControl) CreateChangefeed(tc *v1alpha1.TidbCluster, req *CreateChangefeedRequest) error {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/api/v1/changefeeds", c.getBaseURL(tc, 0))
+	_, err = httputil.PostBodyOK(httpClient, url, bytes.NewReader(data))
+	return err
+}
+
+func (c *defaultTiCDCControl) UpdateChangefeed(tc *v1alpha1.TidbCluster, id string, req *UpdateChangefeedRequest) error {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	_, err = httputil.DoBodyOK(httpClient, c.getChangefeedURL(tc, id), "PUT", bytes.NewReader(data))
+	return err
+}
+
+func (c *defaultTiCDCControl) PauseChangefeed(tc *v1alpha1.TidbCluster, id string) error {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return err
+	}
+
+	_, err = httputil.PostBodyOK(httpClient, c.getChangefeedURL(tc, id)+"/pause", nil)
+	return err
+}
+
+func (c *defaultTiCDCControl) ResumeChangefeed(tc *v1alpha1.TidbCluster, id string) error {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return err
+	}
+
+	_, err = httputil.PostBodyOK(httpClient, c.getChangefeedURL(tc, id)+"/resume", nil)
+	return err
+}
+
+func (c *defaultTiCDCControl) RemoveChangefeed(tc *v1alpha1.TidbCluster, id string) error {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return err
+	}
+
+	_, err = httputil.DeleteBodyOK(httpClient, c.getChangefeedURL(tc, id))
+	return err
+}
+
+func (c *defaultTiCDCControl) getChangefeedURL(tc *v1alpha1.TidbCluster, id string) string {
+	return fmt.Sprintf("%s/api/v1/changefeeds/%s", c.getBaseURL(tc, 0), url.PathEscape(id))
+}
+
 func (c *defaultTiCDCControl) getBaseURL(tc *v1alpha1.TidbCluster, ordinal int32) string {
 	if c.testURL != "" {
 		return c.testURL
@@ -79,14 +260,144 @@ func (c *defaultTiCDCControl) getBaseURL(tc *v1alpha1.TidbCluster, ordinal int32
 // FakeTiCDCControl is a fake implementation of TiCDCControlInterface.
 type FakeTiCDCControl struct {
 	status *CaptureStatus
+	// statuses are the statuses of the captures by ordinal, status is used if not found
+	statuses map[int32]*CaptureStatus
+	// tableCounts are the numbers of the tables left on the captures by capture ID
+	tableCounts map[string]int
+	// drained and resigned record the calls of DrainCapture and ResignOwner
+	drained  []string
+	resigned []int32
+	// changefeeds are the changefeeds by ID
+	changefeeds map[string]*ChangefeedInfo
+	// createRequests record the calls of CreateChangefeed
+	createRequests []*CreateChangefeedRequest
+	// updateRequests record the calls of UpdateChangefeed by changefeed ID
+	updateRequests map[string][]*UpdateChangefeedRequest
 }
 
 // NewFakeTiCDCControl returns a FakeTiCDCControl instance
 func NewFakeTiCDCControl() *FakeTiCDCControl {
-	return &FakeTiCDCControl{}
+	return &FakeTiCDCControl{
+		statuses:       map[int32]*CaptureStatus{},
+		tableCounts:    map[string]int{},
+		changefeeds:    map[string]*ChangefeedInfo{},
+		updateRequests: map[string][]*UpdateChangefeedRequest{},
+	}
 }
 
 // SetHealth set health info for FakeTiCDCControl
 func (c *FakeTiCDCControl) SetStatus(status *CaptureStatus) {
 	c.status = status
 }
+
+// SetCaptureStatus sets the status of the capture of the ordinal
+func (c *FakeTiCDCControl) SetCaptureStatus(ordinal int32, status *CaptureStatus) {
+	c.statuses[ordinal] = status
+}
+
+// SetTableCount sets the number of the tables left on the capture returned by DrainCapture
+func (c *FakeTiCDCControl) SetTableCount(captureID string, count int) {
+	c.tableCounts[captureID] = count
+}
+
+// Drained returns the IDs of the captures drained
+func (c *FakeTiCDCControl) Drained() []string {
+	return c.drained
+}
+
+// Resigned returns the ordinals of the owners resigned
+func (c *FakeTiCDCControl) Resigned() []int32 {
+	return c.resigned
+}
+
+// SetChangefeed sets the changefeed returned by GetChangefeeds
+func (c *FakeTiCDCControl) SetChangefeed(changefeed *ChangefeedInfo) {
+	c.changefeeds[changefeed.ID] = changefeed
+}
+
+// CreateRequests returns the requests of the changefeeds created
+func (c *FakeTiCDCControl) CreateRequests() []*CreateChangefeedRequest {
+	return c.createRequests
+}
+
+// UpdateRequests returns the requests of the changefeed of the id updated
+func (c *FakeTiCDCControl) UpdateRequests(id string) []*UpdateChangefeedRequest {
+	return c.updateRequests[id]
+}
+
+func (c *FakeTiCDCControl) GetStatus(_ *v1alpha1.TidbCluster, ordinal int32) (*CaptureStatus, error) {
+	if status, ok := c.statuses[ordinal]; ok {
+		return status, nil
+	}
+	if c.status == nil {
+		return nil, fmt.Errorf("no status of capture %d", ordinal)
+	}
+	return c.status, nil
+}
+
+func (c *FakeTiCDCControl) DrainCapture(_ *v1alpha1.TidbCluster, _ int32, captureID string) (int, error) {
+	c.drained = append(c.drained, captureID)
+	return c.tableCounts[captureID], nil
+}
+
+func (c *FakeTiCDCControl) ResignOwner(_ *v1alpha1.TidbCluster, ordinal int32) error {
+	c.resigned = append(c.resigned, ordinal)
+	return nil
+}
+
+func (c *FakeTiCDCControl) GetChangefeeds(_ *v1alpha1.TidbCluster) ([]ChangefeedInfo, error) {
+	changefeeds := []ChangefeedInfo{}
+	for _, changefeed := range c.changefeeds {
+		changefeeds = append(changefeeds, *changefeed)
+	}
+	return changefeeds, nil
+}
+
+func (c *FakeTiCDCControl) CreateChangefeed(_ *v1alpha1.TidbCluster, req *CreateChangefeedRequest) error {
+	if _, ok := c.changefeeds[req.ID]; ok {
+		return fmt.Errorf("changefeed %s already exists", req.ID)
+	}
+	c.createRequests = append(c.createRequests, req)
+	c.changefeeds[req.ID] = &ChangefeedInfo{ID: req.ID, State: ChangefeedStateNormal, CheckpointTSO: req.StartTS}
+	return nil
+}
+
+func (c *FakeTiCDCControl) UpdateChangefeed(_ *v1alpha1.TidbCluster, id string, req *UpdateChangefeedRequest) error {
+	changefeed, ok := c.changefeeds[id]
+	if !ok {
+		return fmt.Errorf("changefeed %s not found", id)
+	}
+	if changefeed.State != ChangefeedStateStopped {
+		return fmt.Errorf("changefeed %s is %s, it can only be updated when stopped", id, changefeed.State)
+	}
+	c.updateRequests[id] = append(c.updateRequests[id], req)
+	return nil
+}
+
+func (c *FakeTiCDCControl) PauseChangefeed(_ *v1alpha1.TidbCluster, id string) error {
+	return c.setChangefeedState(id, ChangefeedStateStopped)
+}
+
+func (c *FakeTiCDCControl) ResumeChangefeed(_ *v1alpha1.TidbCluster, id string) error {
+	return c.setChangefeedState(id, ChangefeedStateNormal)
+}
+
+func (c *FakeTiCDCControl) RemoveChangefeed(_ *v1alpha1.TidbCluster, id string) error {
+	if _, ok := c.changefeeds[id]; !ok {
+		return fmt.Errorf("changefeed %s not found", id)
+	}
+	delete(c.changefeeds, id)
+	return nil
+}
+
+func (c *FakeTiCDCControl) setChangefeedState(id, state string) error {
+	changefeed, ok := c.changefeeds[id]
+	if !ok {
+		return fmt.Errorf("changefeed %s not found", id)
+	}
+	changefeed.State = state
+	return nil
+}
+
+var _ TiCDCControlInterface = &defaultTiCDCControl{}
+var _ TiCDCControlInterface = &FakeTiCDCControl{}
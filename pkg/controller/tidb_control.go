@@ -14,15 +14,22 @@
 package controller
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/util"
 	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
 	"github.com/pingcap/tidb/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -31,10 +38,22 @@ const (
 	// NotDDLOwnerError is the error message which was returned when the tidb node is not a ddl owner
 	NotDDLOwnerError = "This node is not a ddl owner, can't be resigned."
 	timeout          = 5 * time.Second
+	// errUnknownSystemVariable is the MySQL error code returned when the system variable is not supported by tidb
+	errUnknownSystemVariable = 1193
 )
 
 type DBInfo struct {
-	IsOwner bool `json:"is_owner"`
+	IsOwner bool   `json:"is_owner"`
+	Version string `json:"version,omitempty"`
+	// StartTimestamp is the unix timestamp when tidb started
+	StartTimestamp int64 `json:"start_timestamp,omitempty"`
+}
+
+// TiDBStatus is the status returned by the status API of tidb
+type TiDBStatus struct {
+	Connections int    `json:"connections"`
+	Version     string `json:"version"`
+	GitHash     string `json:"git_hash"`
 }
 
 // TiDBControlInterface is the interface that knows how to manage tidb peers
@@ -45,6 +64,16 @@ type TiDBControlInterface interface {
 	GetInfo(tc *v1alpha1.TidbCluster, ordinal int32) (*DBInfo, error)
 	// GetSettings return the TiDB instance settings
 	GetSettings(tc *v1alpha1.TidbCluster, ordinal int32) (*config.Config, error)
+	// GetStatus returns tidb's status, e.g. the number of the connections
+	GetStatus(tc *v1alpha1.TidbCluster, ordinal int32) (*TiDBStatus, error)
+	// ResignDDLOwner resigns the DDL owner, it returns false if tidb is not the DDL owner
+	ResignDDLOwner(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error)
+	// ProbeSQL executes the failover probe of the TidbCluster on tidb through the MySQL protocol,
+	// it returns nil if the probe is not configured
+	ProbeSQL(tc *v1alpha1.TidbCluster, ordinal int32) error
+	// SetReadOnly turns on or off the read-only flags of the tidb at cfg.Addr through the MySQL protocol,
+	// the client certificate is loaded from the secret tlsSecretName in the namespace ns if it is not empty
+	SetReadOnly(ns string, cfg *mysql.Config, tlsSecretName string, readOnly bool) error
 }
 
 // defaultTiDBControl is default implementation of TiDBControlInterface.
@@ -54,9 +83,9 @@ type defaultTiDBControl struct {
 	testURL string
 }
 
-// NewDefaultTiDBControl returns a defaultTiDBControl instance
-func NewDefaultTiDBControl(kubeCli kubernetes.Interface) *defaultTiDBControl {
-	return &defaultTiDBControl{httpClient: httpClient{kubeCli: kubeCli}}
+// NewDefaultTiDBControl returns a defaultTiDBControl instance, which loads the client tls.Configs from tlsConfigs
+func NewDefaultTiDBControl(kubeCli kubernetes.Interface, tlsConfigs *pdapi.TLSConfigCache) *defaultTiDBControl {
+	return &defaultTiDBControl{httpClient: httpClient{kubeCli: kubeCli, tlsConfigs: tlsConfigs}}
 }
 
 func (c *defaultTiDBControl) GetHealth(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error) {
@@ -137,6 +166,159 @@ func (c *defaultTiDBControl) GetSettings(tc *v1alpha1.TidbCluster, ordinal int32
 	return &info, nil
 }
 
+func (c *defaultTiDBControl) GetStatus(tc *v1alpha1.TidbCluster, ordinal int32) (*TiDBStatus, error) {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := c.getBaseURL(tc, ordinal)
+	url := fmt.Sprintf("%s/status", baseURL)
+	body, err := getBodyOK(httpClient, url)
+	if err != nil {
+		return nil, err
+	}
+	status := TiDBStatus{}
+	err = json.Unmarshal(body, &status)
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (c *defaultTiDBControl) ResignDDLOwner(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error) {
+	httpClient, err := c.getHTTPClient(tc)
+	if err != nil {
+		return false, err
+	}
+
+	baseURL := c.getBaseURL(tc, ordinal)
+	url := fmt.Sprintf("%s/ddl/owner/resign", baseURL)
+	req, err := http.NewRequest("POST", url, nil)
+	if err != nil {
+		return false, err
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer httputil.DeferClose(res.Body)
+	if res.StatusCode == http.StatusOK {
+		return true, nil
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return false, err
+	}
+	if strings.Contains(string(body), NotDDLOwnerError) {
+		return false, nil
+	}
+	return false, fmt.Errorf("Error response %s:%v URL: %s", string(body), res.StatusCode, url)
+}
+
+func (c *defaultTiDBControl) ProbeSQL(tc *v1alpha1.TidbCluster, ordinal int32) error {
+	probe := tc.Spec.TiDB.FailoverProbe
+	if probe == nil {
+		return nil
+	}
+	cfg, err := c.getMySQLConfig(tc, ordinal)
+	if err != nil {
+		return err
+	}
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return err
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), probe.GetTimeout())
+	defer cancel()
+	rows, err := db.QueryContext(ctx, probe.GetQuery())
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+	}
+	return rows.Err()
+}
+
+func (c *defaultTiDBControl) SetReadOnly(ns string, cfg *mysql.Config, tlsSecretName string, readOnly bool) error {
+	cfg = cfg.Clone()
+	if tlsSecretName != "" {
+		tlsConfig, err := c.tlsConfigs.GetTLSConfig(pdapi.Namespace(ns), tlsSecretName)
+		if err != nil {
+			return err
+		}
+		cfg.TLSConfig = fmt.Sprintf("tidb-read-only-%s-%s", ns, tlsSecretName)
+		if err := mysql.RegisterTLSConfig(cfg.TLSConfig, tlsConfig); err != nil {
+			return err
+		}
+	}
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return err
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	value := "OFF"
+	if readOnly {
+		value = "ON"
+	}
+	// turning on tidb_restricted_read_only turns on tidb_super_read_only as well, but not vice versa
+	if _, err := db.ExecContext(ctx, fmt.Sprintf("SET GLOBAL tidb_restricted_read_only = %s", value)); err != nil {
+		return err
+	}
+	if readOnly {
+		return nil
+	}
+	_, err = db.ExecContext(ctx, "SET GLOBAL tidb_super_read_only = OFF")
+	if mysqlErr, ok := err.(*mysql.MySQLError); ok && mysqlErr.Number == errUnknownSystemVariable {
+		// tidb_super_read_only is introduced in v6.2.0
+		return nil
+	}
+	return err
+}
+
+func (c *defaultTiDBControl) getMySQLConfig(tc *v1alpha1.TidbCluster, ordinal int32) (*mysql.Config, error) {
+	probe := tc.Spec.TiDB.FailoverProbe
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	cfg := mysql.NewConfig()
+	cfg.User = probe.GetUser()
+	cfg.Net = "tcp"
+	cfg.Addr = fmt.Sprintf("%s-%d.%s.%s:%d", TiDBMemberName(tcName), ordinal, TiDBPeerMemberName(tcName), ns, v1alpha1.DefaultTidbPort)
+	cfg.Timeout = probe.GetTimeout()
+	cfg.ReadTimeout = probe.GetTimeout()
+	cfg.WriteTimeout = probe.GetTimeout()
+
+	if probe.PasswordSecret != nil {
+		secret, err := c.kubeCli.CoreV1().Secrets(ns).Get(*probe.PasswordSecret, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the password from secret %s/%s: %v", ns, *probe.PasswordSecret, err)
+		}
+		cfg.Passwd = string(secret.Data[cfg.User])
+	}
+
+	if tc.Spec.TiDB.IsTLSClientEnabled() && !tc.SkipTLSWhenConnectTiDB() {
+		tlsConfig, err := c.tlsConfigs.GetTLSConfig(pdapi.Namespace(ns), util.TiDBClientTLSSecretName(tcName))
+		if err != nil {
+			return nil, err
+		}
+		// the driver clones the registered tls.Config and sets its ServerName to the host of the member
+		cfg.TLSConfig = fmt.Sprintf("tidb-failover-probe-%s-%s", ns, tcName)
+		if err := mysql.RegisterTLSConfig(cfg.TLSConfig, tlsConfig); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
 func getBodyOK(httpClient *http.Client, apiURL string) ([]byte, error) {
 	res, err := httpClient.Get(apiURL)
 	if err != nil {
@@ -174,11 +356,19 @@ type FakeTiDBControl struct {
 	tiDBInfo     *DBInfo
 	getInfoError error
 	tidbConfig   *config.Config
+	status       map[string]*TiDBStatus
+	ddlOwner     string
+	sqlProbeErrs map[string]error
+	readOnlyErr  error
+	// ReadOnly contains the read-only flags set by the addresses of tidb
+	ReadOnly map[string]bool
+	// Resigned contains the names of the pods whose DDL owner is resigned
+	Resigned []string
 }
 
 // NewFakeTiDBControl returns a FakeTiDBControl instance
 func NewFakeTiDBControl() *FakeTiDBControl {
-	return &FakeTiDBControl{}
+	return &FakeTiDBControl{ReadOnly: map[string]bool{}}
 }
 
 // SetHealth set health info for FakeTiDBControl
@@ -198,9 +388,64 @@ func (c *FakeTiDBControl) GetHealth(tc *v1alpha1.TidbCluster, ordinal int32) (bo
 }
 
 func (c *FakeTiDBControl) GetInfo(tc *v1alpha1.TidbCluster, ordinal int32) (*DBInfo, error) {
+	if c.tiDBInfo == nil && c.getInfoError == nil && c.ddlOwner != "" {
+		podName := fmt.Sprintf("%s-%d", TiDBMemberName(tc.GetName()), ordinal)
+		return &DBInfo{IsOwner: podName == c.ddlOwner}, nil
+	}
 	return c.tiDBInfo, c.getInfoError
 }
 
 func (c *FakeTiDBControl) GetSettings(tc *v1alpha1.TidbCluster, ordinal int32) (*config.Config, error) {
 	return c.tidbConfig, c.getInfoError
 }
+
+// SetStatus sets the status of the pods for FakeTiDBControl
+func (c *FakeTiDBControl) SetStatus(status map[string]*TiDBStatus) {
+	c.status = status
+}
+
+// SetDDLOwner sets the pod which is the DDL owner for FakeTiDBControl
+func (c *FakeTiDBControl) SetDDLOwner(podName string) {
+	c.ddlOwner = podName
+}
+
+func (c *FakeTiDBControl) GetStatus(tc *v1alpha1.TidbCluster, ordinal int32) (*TiDBStatus, error) {
+	podName := fmt.Sprintf("%s-%d", TiDBMemberName(tc.GetName()), ordinal)
+	if status, ok := c.status[podName]; ok {
+		return status, nil
+	}
+	return nil, fmt.Errorf("no status of %s", podName)
+}
+
+func (c *FakeTiDBControl) ResignDDLOwner(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error) {
+	podName := fmt.Sprintf("%s-%d", TiDBMemberName(tc.GetName()), ordinal)
+	if podName != c.ddlOwner {
+		return false, nil
+	}
+	c.ddlOwner = ""
+	c.Resigned = append(c.Resigned, podName)
+	return true, nil
+}
+
+// SetSQLProbeErrors sets the errors of the failover probe of the pods for FakeTiDBControl
+func (c *FakeTiDBControl) SetSQLProbeErrors(errs map[string]error) {
+	c.sqlProbeErrs = errs
+}
+
+func (c *FakeTiDBControl) ProbeSQL(tc *v1alpha1.TidbCluster, ordinal int32) error {
+	podName := fmt.Sprintf("%s-%d", TiDBMemberName(tc.GetName()), ordinal)
+	return c.sqlProbeErrs[podName]
+}
+
+// SetReadOnlyError sets the error returned by SetReadOnly for FakeTiDBControl
+func (c *FakeTiDBControl) SetReadOnlyError(err error) {
+	c.readOnlyErr = err
+}
+
+func (c *FakeTiDBControl) SetReadOnly(_ string, cfg *mysql.Config, _ string, readOnly bool) error {
+	if c.readOnlyErr != nil {
+		return c.readOnlyErr
+	}
+	c.ReadOnly[cfg.Addr] = readOnly
+	return nil
+}
@@ -18,9 +18,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
 	"github.com/pingcap/tidb/config"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -133,7 +135,7 @@ func TestHealth(t *testing.T) {
 		fakeClient := &fake.Clientset{}
 		tc := getTidbCluster()
 
-		control := NewDefaultTiDBControl(fakeClient)
+		control := NewDefaultTiDBControl(fakeClient, pdapi.NewTLSConfigCache(fakeClient))
 		control.testURL = svc.URL
 		result, err := control.GetHealth(tc, 0)
 		g.Expect(err).NotTo(HaveOccurred())
@@ -195,7 +197,7 @@ func TestInfo(t *testing.T) {
 		defer svc.Close()
 
 		fakeClient := &fake.Clientset{}
-		control := NewDefaultTiDBControl(fakeClient)
+		control := NewDefaultTiDBControl(fakeClient, pdapi.NewTLSConfigCache(fakeClient))
 		control.testURL = svc.URL
 		tc := getTidbCluster()
 		result, err := control.GetInfo(tc, 0)
@@ -254,7 +256,7 @@ func TestSettings(t *testing.T) {
 		defer svc.Close()
 
 		fakeClient := &fake.Clientset{}
-		control := NewDefaultTiDBControl(fakeClient)
+		control := NewDefaultTiDBControl(fakeClient, pdapi.NewTLSConfigCache(fakeClient))
 		control.testURL = svc.URL
 		tc := getTidbCluster()
 		result, err := control.GetSettings(tc, 0)
@@ -296,7 +298,7 @@ func TestGetHTTPClient(t *testing.T) {
 	for _, c := range cases {
 		fakeClient := &fake.Clientset{}
 		fakeSecret(fakeClient)
-		control := NewDefaultTiDBControl(fakeClient)
+		control := NewDefaultTiDBControl(fakeClient, pdapi.NewTLSConfigCache(fakeClient))
 		tc := getTidbCluster()
 		c.updateTC(tc)
 		httpClient, err := control.getHTTPClient(tc)
@@ -305,6 +307,46 @@ func TestGetHTTPClient(t *testing.T) {
 	}
 }
 
+func TestGetMySQLConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeClient := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "tidb-secret", Namespace: corev1.NamespaceDefault},
+		Data:       map[string][]byte{"root": []byte("pw"), "probe": []byte("probe-pw")},
+	}, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo-tidb-client-secret", Namespace: corev1.NamespaceDefault},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:              []byte(certData),
+			corev1.TLSPrivateKeyKey:        []byte(keyData),
+			corev1.ServiceAccountRootCAKey: []byte(caData),
+		},
+	})
+	control := NewDefaultTiDBControl(fakeClient, pdapi.NewTLSConfigCache(fakeClient))
+
+	tc := getTidbCluster()
+	tc.Spec.TiDB.FailoverProbe = &v1alpha1.TiDBFailoverProbe{}
+	cfg, err := control.getMySQLConfig(tc, 1)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfg.Addr).To(Equal("demo-tidb-1.demo-tidb-peer.default:4000"))
+	g.Expect(cfg.User).To(Equal("root"))
+	g.Expect(cfg.Passwd).To(BeEmpty())
+	g.Expect(cfg.TLSConfig).To(BeEmpty())
+	g.Expect(cfg.Timeout).To(Equal(5 * time.Second))
+
+	secretName := "tidb-secret"
+	tc.Spec.TiDB.FailoverProbe = &v1alpha1.TiDBFailoverProbe{User: "probe", PasswordSecret: &secretName}
+	tc.Spec.TiDB.TLSClient = &v1alpha1.TiDBTLSClient{Enabled: true}
+	cfg, err = control.getMySQLConfig(tc, 1)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfg.User).To(Equal("probe"))
+	g.Expect(cfg.Passwd).To(Equal("probe-pw"))
+	g.Expect(cfg.TLSConfig).To(Equal("tidb-failover-probe-default-demo"))
+
+	secretName = "not-exist"
+	_, err = control.getMySQLConfig(tc, 1)
+	g.Expect(err).To(HaveOccurred())
+}
+
 func getTidbCluster() *v1alpha1.TidbCluster {
 	return &v1alpha1.TidbCluster{
 		TypeMeta: metav1.TypeMeta{
@@ -0,0 +1,161 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tidbclienttls dedicates the TiDB client TLS controller.
+// This controller watches the namespaces annotated with `tidb.pingcap.com/tidb-client-tls-from`
+// and copies the TiDB client TLS secrets of the TidbClusters into them.
+package tidbclienttls
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/manager/member"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	corelisterv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+)
+
+// Controller copies the TiDB client TLS secrets into the annotated namespaces
+type Controller struct {
+	deps            *controller.Dependencies
+	propagator      member.TiDBClientTLSPropagator
+	namespaceLister corelisterv1.NamespaceLister
+	queue           workqueue.RateLimitingInterface
+}
+
+// NewController creates a TiDB client TLS controller.
+func NewController(deps *controller.Dependencies) *Controller {
+	namespaceInformer := deps.KubeInformerFactory.Core().V1().Namespaces()
+	c := &Controller{
+		deps:            deps,
+		propagator:      member.NewTiDBClientTLSPropagator(deps),
+		namespaceLister: namespaceInformer.Lister(),
+		queue: workqueue.NewNamedRateLimitingQueue(
+			controller.NewControllerRateLimiter(1*time.Second, 100*time.Second),
+			"tidbclienttls",
+		),
+	}
+
+	// the namespaces are resynced periodically, so that the copies are created once
+	// the TidbClusters are created or the TLS of the MySQL client is enabled
+	namespaceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueueNamespace,
+		UpdateFunc: func(_, cur interface{}) {
+			c.enqueueNamespace(cur)
+		},
+	})
+	// the copies are refreshed once the secrets are rotated, and restored once they are modified
+	deps.KubeInformerFactory.Core().V1().Secrets().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: c.enqueueSecret,
+		UpdateFunc: func(_, cur interface{}) {
+			c.enqueueSecret(cur)
+		},
+		DeleteFunc: c.enqueueSecret,
+	})
+
+	return c
+}
+
+// Run run workers
+func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+
+	klog.Info("Starting tidb client tls controller")
+	defer klog.Info("Shutting down tidb client tls controller")
+
+	controller.RunWorkers(c.queue, workers, c.processNextWorkItem, stopCh)
+}
+
+// processNextWorkItem dequeues items, processes them, and marks them done.
+// It enforces that the syncHandler is never
+// invoked concurrently with the same key.
+func (c *Controller) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+	startTime := time.Now()
+	err := c.sync(key.(string))
+	controller.ObserveReconcile("tidbclienttls", startTime, err)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("Namespace: %v, sync failed, err: %v, requeuing", key.(string), err))
+		c.queue.AddRateLimited(key)
+	} else {
+		c.queue.Forget(key)
+	}
+	return true
+}
+
+func (c *Controller) sync(key string) error {
+	startTime := time.Now()
+	defer func() {
+		klog.V(4).Infof("Finished syncing Namespace %q (%v)", key, time.Since(startTime))
+	}()
+
+	ns, err := c.namespaceLister.Get(key)
+	if errors.IsNotFound(err) {
+		klog.Infof("Namespace %v has been deleted", key)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return c.propagator.Sync(ns.DeepCopy())
+}
+
+func (c *Controller) enqueueNamespace(obj interface{}) {
+	ns, ok := obj.(*corev1.Namespace)
+	if !ok {
+		return
+	}
+	c.queue.Add(ns.Name)
+}
+
+func (c *Controller) enqueueSecret(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return
+	}
+	if secret.Labels[label.ComponentLabelKey] == label.TiDBClientTLSLabelVal {
+		c.queue.Add(secret.Namespace)
+		return
+	}
+	namespaces, err := c.namespaceLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list namespaces, error: %v", err)
+		return
+	}
+	for _, ns := range namespaces {
+		sources, _ := member.ParseTiDBClientTLSSources(ns)
+		for _, source := range sources {
+			tcNs, tcName, _ := cache.SplitMetaNamespaceKey(source)
+			if tcNs == secret.Namespace && util.TiDBClientTLSSecretName(tcName) == secret.Name {
+				c.queue.Add(ns.Name)
+				break
+			}
+		}
+	}
+}
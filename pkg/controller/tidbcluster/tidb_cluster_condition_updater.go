@@ -14,6 +14,10 @@
 package tidbcluster
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
 	appsv1 "k8s.io/api/apps/v1"
@@ -33,6 +37,14 @@ var _ TidbClusterConditionUpdater = &tidbClusterConditionUpdater{}
 
 func (u *tidbClusterConditionUpdater) Update(tc *v1alpha1.TidbCluster) error {
 	u.updateReadyCondition(tc)
+	u.updateAvailableCondition(tc)
+	u.updateProgressingCondition(tc)
+	u.updateDegradedCondition(tc)
+	u.updateFailoverLimitedCondition(tc)
+	u.updateComponentConditions(tc)
+	for i := range tc.Status.Conditions {
+		tc.Status.Conditions[i].ObservedGeneration = tc.Generation
+	}
 	// in the future, we may return error when we need to Kubernetes API, etc.
 	return nil
 }
@@ -56,6 +68,9 @@ func (u *tidbClusterConditionUpdater) updateReadyCondition(tc *v1alpha1.TidbClus
 	message := ""
 
 	switch {
+	case tc.Status.Suspend != nil:
+		reason = utiltidbcluster.Suspended
+		message = fmt.Sprintf("TiDB cluster is %s", strings.ToLower(string(tc.Status.Suspend.Phase)))
 	case !allStatefulSetsAreUpToDate(tc):
 		reason = utiltidbcluster.StatfulSetNotUpToDate
 		message = "Statefulset(s) are in progress"
@@ -71,6 +86,13 @@ func (u *tidbClusterConditionUpdater) updateReadyCondition(tc *v1alpha1.TidbClus
 	case tc.Spec.TiFlash != nil && !tc.TiFlashAllStoresReady():
 		reason = utiltidbcluster.TiFlashStoreNotUp
 		message = "TiFlash store(s) are not up"
+	case tc.Status.Restore != nil && tc.Status.Restore.Phase == v1alpha1.TidbClusterRestoreFailed:
+		reason = utiltidbcluster.RestoreFailed
+		message = "The data fails to be restored"
+	case tc.Status.Restore != nil && tc.Status.Restore.Phase != v1alpha1.TidbClusterRestoreComplete &&
+		tc.Status.Restore.Phase != v1alpha1.TidbClusterRestoreSkipped:
+		reason = utiltidbcluster.Restoring
+		message = "The data is being restored"
 	default:
 		status = v1.ConditionTrue
 		reason = utiltidbcluster.Ready
@@ -79,3 +101,339 @@ func (u *tidbClusterConditionUpdater) updateReadyCondition(tc *v1alpha1.TidbClus
 	cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterReady, status, reason, message)
 	utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
 }
+
+func (u *tidbClusterConditionUpdater) updateAvailableCondition(tc *v1alpha1.TidbCluster) {
+	status := v1.ConditionFalse
+	reason := ""
+	message := ""
+
+	switch {
+	case tc.Spec.PD != nil && !pdHasQuorum(tc):
+		reason = utiltidbcluster.PDQuorumLost
+		message = "PD has no quorum"
+	case tc.Spec.TiKV != nil && !anyStoreUp(tc.Status.TiKV.Stores):
+		reason = utiltidbcluster.NoTiKVStoreUp
+		message = "None of the TiKV stores is up"
+	case tc.Spec.TiDB != nil && !anyTiDBHealthy(tc):
+		reason = utiltidbcluster.NoTiDBHealthy
+		message = "None of the TiDBs is healthy"
+	default:
+		status = v1.ConditionTrue
+		reason = utiltidbcluster.Available
+		message = "TiDB cluster is able to serve requests"
+	}
+	cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterAvailable, status, reason, message)
+	utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+}
+
+func (u *tidbClusterConditionUpdater) updateProgressingCondition(tc *v1alpha1.TidbCluster) {
+	status := v1.ConditionTrue
+	reason := ""
+	message := ""
+
+	if !allStatefulSetsAreUpToDate(tc) {
+		reason = utiltidbcluster.StatfulSetNotUpToDate
+		message = "Statefulset(s) are in progress"
+	} else if components := scalingComponents(tc); len(components) > 0 {
+		reason = utiltidbcluster.Scaling
+		message = fmt.Sprintf("%s are scaling", strings.Join(components, ", "))
+	} else {
+		status = v1.ConditionFalse
+		reason = utiltidbcluster.UpToDate
+		message = "All statefulsets are up to date"
+	}
+	cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterProgressing, status, reason, message)
+	utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+}
+
+func (u *tidbClusterConditionUpdater) updateDegradedCondition(tc *v1alpha1.TidbCluster) {
+	status := v1.ConditionTrue
+	reason := ""
+	message := ""
+
+	pdDegraded := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterPDDegraded)
+	if pdDegraded != nil && pdDegraded.Status == v1.ConditionTrue {
+		reason = utiltidbcluster.PDCircuitOpen
+		message = "PD is unreachable, the status of the cluster may be stale"
+	} else if components := unhealthyComponents(tc); len(components) > 0 {
+		reason = utiltidbcluster.MembersUnhealthy
+		message = fmt.Sprintf("Some members of %s are unhealthy", strings.Join(components, ", "))
+	} else {
+		status = v1.ConditionFalse
+		reason = utiltidbcluster.Healthy
+		message = "All members are healthy"
+	}
+	cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterDegraded, status, reason, message)
+	utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+}
+
+// pdHasQuorum returns true if the healthy PD members, including the peer members out of
+// the TidbCluster, are the majority
+func pdHasQuorum(tc *v1alpha1.TidbCluster) bool {
+	total, healthy := 0, 0
+	for _, members := range []map[string]v1alpha1.PDMember{tc.Status.PD.Members, tc.Status.PD.PeerMembers} {
+		for _, member := range members {
+			total++
+			if member.Health {
+				healthy++
+			}
+		}
+	}
+	return healthy > total/2
+}
+
+func anyStoreUp(stores map[string]v1alpha1.TiKVStore) bool {
+	for _, store := range stores {
+		if store.State == v1alpha1.TiKVStateUp {
+			return true
+		}
+	}
+	return false
+}
+
+func allStoresUp(stores map[string]v1alpha1.TiKVStore) bool {
+	for _, store := range stores {
+		if store.State != v1alpha1.TiKVStateUp {
+			return false
+		}
+	}
+	return true
+}
+
+func anyTiDBHealthy(tc *v1alpha1.TidbCluster) bool {
+	for _, member := range tc.Status.TiDB.Members {
+		if member.Health {
+			return true
+		}
+	}
+	return false
+}
+
+// scalingComponents returns the components whose statefulset replicas differ from the desired replicas
+func scalingComponents(tc *v1alpha1.TidbCluster) []string {
+	var components []string
+	if tc.Spec.PD != nil && tc.PDStsDesiredReplicas() != tc.PDStsActualReplicas() {
+		components = append(components, v1alpha1.PDMemberType.String())
+	}
+	if tc.Spec.TiKV != nil && tc.TiKVStsDesiredReplicas() != tc.TiKVStsActualReplicas() {
+		components = append(components, v1alpha1.TiKVMemberType.String())
+	}
+	if tc.Spec.TiDB != nil && tc.TiDBStsDesiredReplicas() != tc.TiDBStsActualReplicas() {
+		components = append(components, v1alpha1.TiDBMemberType.String())
+	}
+	if tc.Spec.TiFlash != nil && tc.TiFlashStsDesiredReplicas() != tc.TiFlashStsActualReplicas() {
+		components = append(components, v1alpha1.TiFlashMemberType.String())
+	}
+	return components
+}
+
+// unhealthyComponents returns the components which have unhealthy members or stores not up
+func unhealthyComponents(tc *v1alpha1.TidbCluster) []string {
+	var components []string
+	if tc.Spec.PD != nil {
+		for _, member := range tc.Status.PD.Members {
+			if !member.Health {
+				components = append(components, v1alpha1.PDMemberType.String())
+				break
+			}
+		}
+	}
+	if tc.Spec.TiKV != nil && !allStoresUp(tc.Status.TiKV.Stores) {
+		components = append(components, v1alpha1.TiKVMemberType.String())
+	}
+	if tc.Spec.TiDB != nil {
+		for _, member := range tc.Status.TiDB.Members {
+			if !member.Health {
+				components = append(components, v1alpha1.TiDBMemberType.String())
+				break
+			}
+		}
+	}
+	if tc.Spec.TiFlash != nil && !allStoresUp(tc.Status.TiFlash.Stores) {
+		components = append(components, v1alpha1.TiFlashMemberType.String())
+	}
+	return components
+}
+
+func (u *tidbClusterConditionUpdater) updateFailoverLimitedCondition(tc *v1alpha1.TidbCluster) {
+	components := failoverLimitedComponents(tc)
+	if len(components) > 0 {
+		message := fmt.Sprintf("the failover replicas of %s reach maxFailoverCount, the failed members are not replaced", strings.Join(components, ", "))
+		cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterFailoverLimited, v1.ConditionTrue, utiltidbcluster.FailoverLimitReached, message)
+		utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+		return
+	}
+	if utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterFailoverLimited) != nil {
+		cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterFailoverLimited, v1.ConditionFalse, utiltidbcluster.FailoverWithinLimit, "the failover replicas of all components are within maxFailoverCount")
+		utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+	}
+}
+
+// failoverLimitedComponents returns the components which have failed members that are not marked as
+// failure members, since the failover replicas of the components reach maxFailoverCount
+func failoverLimitedComponents(tc *v1alpha1.TidbCluster) []string {
+	reached := func(maxFailoverCount *int32, failures int) bool {
+		return maxFailoverCount != nil && failures >= int(*maxFailoverCount)
+	}
+	var components []string
+	if tc.Spec.PD != nil {
+		// the failover replicas of PD are the failure members which are deleted
+		deleted := 0
+		for _, member := range tc.Status.PD.FailureMembers {
+			if member.MemberDeleted {
+				deleted++
+			}
+		}
+		unreplaced := false
+		for name, member := range tc.Status.PD.Members {
+			if _, ok := tc.Status.PD.FailureMembers[name]; !ok && !member.Health {
+				unreplaced = true
+			}
+		}
+		if unreplaced && reached(tc.Spec.PD.MaxFailoverCount, deleted) {
+			components = append(components, v1alpha1.PDMemberType.String())
+		}
+	}
+	if tc.Spec.TiKV != nil && reached(tc.Spec.TiKV.MaxFailoverCount, len(tc.Status.TiKV.FailureStores)) &&
+		hasUnreplacedStore(tc.Status.TiKV.Stores, tc.Status.TiKV.FailureStores) {
+		components = append(components, v1alpha1.TiKVMemberType.String())
+	}
+	if tc.Spec.TiDB != nil && reached(tc.Spec.TiDB.MaxFailoverCount, len(tc.Status.TiDB.FailureMembers)) {
+		for name, member := range tc.Status.TiDB.Members {
+			if _, ok := tc.Status.TiDB.FailureMembers[name]; !ok && (!member.Health || member.SQLProbeFailingSince != nil) {
+				components = append(components, v1alpha1.TiDBMemberType.String())
+				break
+			}
+		}
+	}
+	if tc.Spec.TiFlash != nil && reached(tc.Spec.TiFlash.MaxFailoverCount, len(tc.Status.TiFlash.FailureStores)) &&
+		hasUnreplacedStore(tc.Status.TiFlash.Stores, tc.Status.TiFlash.FailureStores) {
+		components = append(components, v1alpha1.TiFlashMemberType.String())
+	}
+	return components
+}
+
+// hasUnreplacedStore returns true if any store is down but not marked as a failure store
+func hasUnreplacedStore(stores map[string]v1alpha1.TiKVStore, failureStores map[string]v1alpha1.TiKVFailureStore) bool {
+	for storeID, store := range stores {
+		if _, ok := failureStores[storeID]; !ok && store.State == v1alpha1.TiKVStateDown {
+			return true
+		}
+	}
+	return false
+}
+
+// updateComponentConditions updates the Healthy and Upgrading conditions of the components in the spec,
+// the ConfigSynced conditions and the detailed reasons of the Upgrading conditions are set by the member
+// managers and the upgraders during the sync
+func (u *tidbClusterConditionUpdater) updateComponentConditions(tc *v1alpha1.TidbCluster) {
+	if tc.Spec.PD != nil {
+		health := make(map[string]bool, len(tc.Status.PD.Members))
+		for name, member := range tc.Status.PD.Members {
+			health[name] = member.Health
+		}
+		utiltidbcluster.SetComponentCondition(&tc.Status.PD.Conditions, *unhealthyMember(health))
+		setComponentUpgradingCondition(&tc.Status.PD.Conditions, tc.Status.PD.Phase)
+	}
+	if tc.Spec.TiKV != nil {
+		utiltidbcluster.SetComponentCondition(&tc.Status.TiKV.Conditions, *storeNotUp(tc.Status.TiKV.Stores))
+		setComponentUpgradingCondition(&tc.Status.TiKV.Conditions, tc.Status.TiKV.Phase)
+	}
+	if tc.Spec.TiDB != nil {
+		health := make(map[string]bool, len(tc.Status.TiDB.Members))
+		for name, member := range tc.Status.TiDB.Members {
+			health[name] = member.Health
+		}
+		utiltidbcluster.SetComponentCondition(&tc.Status.TiDB.Conditions, *unhealthyMember(health))
+		setComponentUpgradingCondition(&tc.Status.TiDB.Conditions, tc.Status.TiDB.Phase)
+	}
+	if tc.Spec.TiFlash != nil {
+		utiltidbcluster.SetComponentCondition(&tc.Status.TiFlash.Conditions, *storeNotUp(tc.Status.TiFlash.Stores))
+		setComponentUpgradingCondition(&tc.Status.TiFlash.Conditions, tc.Status.TiFlash.Phase)
+	}
+	if tc.Spec.TiCDC != nil {
+		cond := healthyCondition()
+		// the captures have no health, the component is healthy once all of them are registered
+		if captures := len(tc.Status.TiCDC.Captures); int32(captures) < tc.Spec.TiCDC.Replicas {
+			cond = utiltidbcluster.NewComponentCondition(v1alpha1.ComponentHealthy, v1.ConditionFalse, utiltidbcluster.MemberUnhealthy,
+				fmt.Sprintf("%d of %d captures are registered", captures, tc.Spec.TiCDC.Replicas))
+		}
+		utiltidbcluster.SetComponentCondition(&tc.Status.TiCDC.Conditions, *cond)
+		setComponentUpgradingCondition(&tc.Status.TiCDC.Conditions, tc.Status.TiCDC.Phase)
+	}
+	if tc.Spec.Pump != nil {
+		cond := healthyCondition()
+		online := 0
+		for _, member := range tc.Status.Pump.Members {
+			if member.State == "online" {
+				online++
+			}
+		}
+		if int32(online) < tc.Spec.Pump.Replicas {
+			cond = utiltidbcluster.NewComponentCondition(v1alpha1.ComponentHealthy, v1.ConditionFalse, utiltidbcluster.MemberUnhealthy,
+				fmt.Sprintf("%d of %d members are online", online, tc.Spec.Pump.Replicas))
+		}
+		utiltidbcluster.SetComponentCondition(&tc.Status.Pump.Conditions, *cond)
+		setComponentUpgradingCondition(&tc.Status.Pump.Conditions, tc.Status.Pump.Phase)
+	}
+}
+
+func healthyCondition() *v1alpha1.ComponentCondition {
+	return utiltidbcluster.NewComponentCondition(v1alpha1.ComponentHealthy, v1.ConditionTrue, utiltidbcluster.AllMembersHealthy, "")
+}
+
+// setComponentUpgradingCondition sets the Upgrading condition by the phase of the component, the detailed
+// reason set by the upgrader is kept while the component is being upgraded
+func setComponentUpgradingCondition(conditions *[]v1alpha1.ComponentCondition, phase v1alpha1.MemberPhase) {
+	if phase != v1alpha1.UpgradePhase {
+		cond := utiltidbcluster.NewComponentCondition(v1alpha1.ComponentUpgrading, v1.ConditionFalse, utiltidbcluster.UpToDate, "")
+		utiltidbcluster.SetComponentCondition(conditions, *cond)
+		return
+	}
+	if cur := utiltidbcluster.GetComponentCondition(*conditions, v1alpha1.ComponentUpgrading); cur == nil || cur.Status != v1.ConditionTrue {
+		cond := utiltidbcluster.NewComponentCondition(v1alpha1.ComponentUpgrading, v1.ConditionTrue, utiltidbcluster.RollingUpdate, "")
+		utiltidbcluster.SetComponentCondition(conditions, *cond)
+	}
+}
+
+// unhealthyMember returns the Healthy condition of the members keyed by the name, which tells the first
+// unhealthy member in name order if any
+func unhealthyMember(health map[string]bool) *v1alpha1.ComponentCondition {
+	names := make([]string, 0, len(health))
+	for name := range health {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !health[name] {
+			return utiltidbcluster.NewComponentCondition(v1alpha1.ComponentHealthy, v1.ConditionFalse,
+				utiltidbcluster.MemberUnhealthy, fmt.Sprintf("member %s is unhealthy", name))
+		}
+	}
+	return healthyCondition()
+}
+
+// storeNotUp returns the Healthy condition of the stores, which tells the first store not up in id order
+// if any, the down stores take precedence over the other ones
+func storeNotUp(stores map[string]v1alpha1.TiKVStore) *v1alpha1.ComponentCondition {
+	ids := make([]string, 0, len(stores))
+	for id := range stores {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var notUp *v1alpha1.ComponentCondition
+	for _, id := range ids {
+		store := stores[id]
+		message := fmt.Sprintf("store %s of pod %s is %s", id, store.PodName, store.State)
+		if store.State == v1alpha1.TiKVStateDown {
+			return utiltidbcluster.NewComponentCondition(v1alpha1.ComponentHealthy, v1.ConditionFalse, utiltidbcluster.StoreDown, message)
+		}
+		if store.State != v1alpha1.TiKVStateUp && notUp == nil {
+			notUp = utiltidbcluster.NewComponentCondition(v1alpha1.ComponentHealthy, v1.ConditionFalse, utiltidbcluster.StoreNotUp, message)
+		}
+	}
+	if notUp != nil {
+		return notUp
+	}
+	return healthyCondition()
+}
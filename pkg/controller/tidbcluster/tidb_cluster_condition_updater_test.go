@@ -17,10 +17,12 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	. "github.com/onsi/gomega"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
 )
 
 func TestTidbClusterConditionUpdater_Ready(t *testing.T) {
@@ -31,6 +33,25 @@ func TestTidbClusterConditionUpdater_Ready(t *testing.T) {
 		wantReason  string
 		wantMessage string
 	}{
+		{
+			name: "suspended",
+			tc: &v1alpha1.TidbCluster{
+				Spec: v1alpha1.TidbClusterSpec{
+					PD:      &v1alpha1.PDSpec{},
+					TiKV:    &v1alpha1.TiKVSpec{},
+					TiDB:    &v1alpha1.TiDBSpec{},
+					Suspend: true,
+				},
+				Status: v1alpha1.TidbClusterStatus{
+					Suspend: &v1alpha1.SuspendStatus{
+						Phase: v1alpha1.SuspendPhaseSuspended,
+					},
+				},
+			},
+			wantStatus:  v1.ConditionFalse,
+			wantReason:  utiltidbcluster.Suspended,
+			wantMessage: "TiDB cluster is suspended",
+		},
 		{
 			name: "statfulset(s) not up to date",
 			tc: &v1alpha1.TidbCluster{
@@ -273,6 +294,77 @@ func TestTidbClusterConditionUpdater_Ready(t *testing.T) {
 			wantReason:  utiltidbcluster.TiFlashStoreNotUp,
 			wantMessage: "TiFlash store(s) are not up",
 		},
+		{
+			name: "restoring",
+			tc: &v1alpha1.TidbCluster{
+				Spec: v1alpha1.TidbClusterSpec{
+					PD: &v1alpha1.PDSpec{
+						Replicas: 1,
+					},
+					TiKV: &v1alpha1.TiKVSpec{
+						Replicas: 1,
+					},
+					TiDB: &v1alpha1.TiDBSpec{
+						Replicas: 1,
+					},
+					TiFlash: &v1alpha1.TiFlashSpec{
+						Replicas: 1,
+					},
+				},
+				Status: v1alpha1.TidbClusterStatus{
+					Restore: &v1alpha1.TidbClusterRestoreStatus{
+						Phase: v1alpha1.TidbClusterRestoreRunning,
+					},
+					PD: v1alpha1.PDStatus{
+						Members: map[string]v1alpha1.PDMember{
+							"pd-0": {
+								Health: true,
+							},
+						},
+						StatefulSet: &appsv1.StatefulSetStatus{
+							CurrentRevision: "2",
+							UpdateRevision:  "2",
+						},
+					},
+					TiDB: v1alpha1.TiDBStatus{
+						Members: map[string]v1alpha1.TiDBMember{
+							"tidb-0": {
+								Health: true,
+							},
+						},
+						StatefulSet: &appsv1.StatefulSetStatus{
+							CurrentRevision: "2",
+							UpdateRevision:  "2",
+						},
+					},
+					TiKV: v1alpha1.TiKVStatus{
+						Stores: map[string]v1alpha1.TiKVStore{
+							"tikv-0": {
+								State: "Up",
+							},
+						},
+						StatefulSet: &appsv1.StatefulSetStatus{
+							CurrentRevision: "2",
+							UpdateRevision:  "2",
+						},
+					},
+					TiFlash: v1alpha1.TiFlashStatus{
+						Stores: map[string]v1alpha1.TiKVStore{
+							"flash-0": {
+								State: "Up",
+							},
+						},
+						StatefulSet: &appsv1.StatefulSetStatus{
+							CurrentRevision: "2",
+							UpdateRevision:  "2",
+						},
+					},
+				},
+			},
+			wantStatus:  v1.ConditionFalse,
+			wantReason:  utiltidbcluster.Restoring,
+			wantMessage: "The data is being restored",
+		},
 		{
 			name: "all ready",
 			tc: &v1alpha1.TidbCluster{
@@ -360,3 +452,226 @@ func TestTidbClusterConditionUpdater_Ready(t *testing.T) {
 		})
 	}
 }
+
+func TestTidbClusterConditionUpdater_FailoverLimited(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := &v1alpha1.TidbCluster{
+		Spec: v1alpha1.TidbClusterSpec{
+			PD:      &v1alpha1.PDSpec{MaxFailoverCount: pointer.Int32Ptr(1)},
+			TiKV:    &v1alpha1.TiKVSpec{MaxFailoverCount: pointer.Int32Ptr(1)},
+			TiDB:    &v1alpha1.TiDBSpec{MaxFailoverCount: pointer.Int32Ptr(1)},
+			TiFlash: &v1alpha1.TiFlashSpec{MaxFailoverCount: pointer.Int32Ptr(1)},
+		},
+		Status: v1alpha1.TidbClusterStatus{
+			PD: v1alpha1.PDStatus{
+				Members: map[string]v1alpha1.PDMember{
+					"pd-0": {Name: "pd-0", Health: false},
+					"pd-1": {Name: "pd-1", Health: false},
+				},
+				// the failure member is not deleted yet
+				FailureMembers: map[string]v1alpha1.PDFailureMember{
+					"pd-0": {PodName: "pd-0"},
+				},
+			},
+			TiKV: v1alpha1.TiKVStatus{
+				Stores: map[string]v1alpha1.TiKVStore{
+					"1": {ID: "1", State: v1alpha1.TiKVStateDown},
+					"2": {ID: "2", State: v1alpha1.TiKVStateDown},
+				},
+				FailureStores: map[string]v1alpha1.TiKVFailureStore{
+					"1": {StoreID: "1"},
+				},
+			},
+			TiDB: v1alpha1.TiDBStatus{
+				Members: map[string]v1alpha1.TiDBMember{
+					"tidb-0": {Name: "tidb-0", Health: false},
+				},
+			},
+			TiFlash: v1alpha1.TiFlashStatus{
+				Stores: map[string]v1alpha1.TiKVStore{
+					"3": {ID: "3", State: v1alpha1.TiKVStateUp},
+				},
+				FailureStores: map[string]v1alpha1.TiKVFailureStore{
+					"4": {StoreID: "4"},
+				},
+			},
+		},
+	}
+	conditionUpdater := &tidbClusterConditionUpdater{}
+
+	// the condition is not added if no component is limited
+	tc.Spec.TiKV.MaxFailoverCount = pointer.Int32Ptr(3)
+	conditionUpdater.Update(tc)
+	g.Expect(utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterFailoverLimited)).To(BeNil())
+
+	tc.Spec.TiKV.MaxFailoverCount = pointer.Int32Ptr(1)
+	tc.Spec.TiDB.MaxFailoverCount = pointer.Int32Ptr(0)
+	conditionUpdater.Update(tc)
+	cond := utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterFailoverLimited)
+	g.Expect(cond.Status).To(Equal(v1.ConditionTrue))
+	g.Expect(cond.Reason).To(Equal(utiltidbcluster.FailoverLimitReached))
+	g.Expect(cond.Message).To(ContainSubstring("tikv, tidb reach maxFailoverCount"))
+
+	tc.Status.TiKV.Stores["2"] = v1alpha1.TiKVStore{ID: "2", State: v1alpha1.TiKVStateUp}
+	tc.Status.TiDB.Members["tidb-0"] = v1alpha1.TiDBMember{Name: "tidb-0", Health: true}
+	conditionUpdater.Update(tc)
+	cond = utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterFailoverLimited)
+	g.Expect(cond.Status).To(Equal(v1.ConditionFalse))
+	g.Expect(cond.Reason).To(Equal(utiltidbcluster.FailoverWithinLimit))
+}
+
+func TestTidbClusterConditionUpdater_AvailableProgressingDegraded(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := &v1alpha1.TidbCluster{
+		Spec: v1alpha1.TidbClusterSpec{
+			PD:   &v1alpha1.PDSpec{Replicas: 3},
+			TiKV: &v1alpha1.TiKVSpec{Replicas: 2},
+			TiDB: &v1alpha1.TiDBSpec{Replicas: 1},
+		},
+		Status: v1alpha1.TidbClusterStatus{
+			PD: v1alpha1.PDStatus{
+				StatefulSet: &appsv1.StatefulSetStatus{Replicas: 3},
+				Members: map[string]v1alpha1.PDMember{
+					"pd-0": {Name: "pd-0", Health: true},
+					"pd-1": {Name: "pd-1", Health: false},
+					"pd-2": {Name: "pd-2", Health: false},
+				},
+			},
+			TiKV: v1alpha1.TiKVStatus{
+				StatefulSet: &appsv1.StatefulSetStatus{Replicas: 2},
+				Stores: map[string]v1alpha1.TiKVStore{
+					"1": {ID: "1", State: v1alpha1.TiKVStateUp},
+					"2": {ID: "2", State: v1alpha1.TiKVStateDown},
+				},
+			},
+			TiDB: v1alpha1.TiDBStatus{
+				StatefulSet: &appsv1.StatefulSetStatus{Replicas: 1},
+				Members: map[string]v1alpha1.TiDBMember{
+					"tidb-0": {Name: "tidb-0", Health: true},
+				},
+			},
+		},
+	}
+	tc.Generation = 2
+	conditionUpdater := &tidbClusterConditionUpdater{}
+	expectCondition := func(condType v1alpha1.TidbClusterConditionType, status v1.ConditionStatus, reason string) *v1alpha1.TidbClusterCondition {
+		cond := utiltidbcluster.GetTidbClusterCondition(tc.Status, condType)
+		g.Expect(cond).NotTo(BeNil(), string(condType))
+		g.Expect(cond.Status).To(Equal(status), string(condType))
+		g.Expect(cond.Reason).To(Equal(reason), string(condType))
+		g.Expect(cond.ObservedGeneration).To(Equal(tc.Generation), string(condType))
+		return cond
+	}
+
+	// PD has no quorum
+	conditionUpdater.Update(tc)
+	expectCondition(v1alpha1.TidbClusterAvailable, v1.ConditionFalse, utiltidbcluster.PDQuorumLost)
+	expectCondition(v1alpha1.TidbClusterProgressing, v1.ConditionFalse, utiltidbcluster.UpToDate)
+	cond := expectCondition(v1alpha1.TidbClusterDegraded, v1.ConditionTrue, utiltidbcluster.MembersUnhealthy)
+	g.Expect(cond.Message).To(ContainSubstring("pd, tikv"))
+
+	// the cluster is available but degraded, and it is being scaled out
+	tc.Generation = 3
+	tc.Spec.TiDB.Replicas = 2
+	tc.Status.PD.Members["pd-1"] = v1alpha1.PDMember{Name: "pd-1", Health: true}
+	conditionUpdater.Update(tc)
+	available := expectCondition(v1alpha1.TidbClusterAvailable, v1.ConditionTrue, utiltidbcluster.Available)
+	cond = expectCondition(v1alpha1.TidbClusterProgressing, v1.ConditionTrue, utiltidbcluster.Scaling)
+	g.Expect(cond.Message).To(Equal("tidb are scaling"))
+	cond = expectCondition(v1alpha1.TidbClusterDegraded, v1.ConditionTrue, utiltidbcluster.MembersUnhealthy)
+	g.Expect(cond.Message).To(ContainSubstring("pd, tikv"))
+
+	// PD is unreachable
+	tc.Status.Conditions = append(tc.Status.Conditions, *utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterPDDegraded, v1.ConditionTrue, utiltidbcluster.PDCircuitOpen, ""))
+	conditionUpdater.Update(tc)
+	expectCondition(v1alpha1.TidbClusterDegraded, v1.ConditionTrue, utiltidbcluster.PDCircuitOpen)
+
+	// all members are healthy
+	utiltidbcluster.SetTidbClusterCondition(&tc.Status, *utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterPDDegraded, v1.ConditionFalse, utiltidbcluster.PDReachable, ""))
+	tc.Status.PD.Members["pd-2"] = v1alpha1.PDMember{Name: "pd-2", Health: true}
+	tc.Status.TiKV.Stores["2"] = v1alpha1.TiKVStore{ID: "2", State: v1alpha1.TiKVStateUp}
+	tc.Status.TiDB.StatefulSet.Replicas = 2
+	tc.Status.TiDB.Members["tidb-1"] = v1alpha1.TiDBMember{Name: "tidb-1", Health: true}
+	conditionUpdater.Update(tc)
+	expectCondition(v1alpha1.TidbClusterReady, v1.ConditionTrue, utiltidbcluster.Ready)
+	expectCondition(v1alpha1.TidbClusterProgressing, v1.ConditionFalse, utiltidbcluster.UpToDate)
+	expectCondition(v1alpha1.TidbClusterDegraded, v1.ConditionFalse, utiltidbcluster.Healthy)
+	// the transition time is kept if the status is not changed
+	cond = expectCondition(v1alpha1.TidbClusterAvailable, v1.ConditionTrue, utiltidbcluster.Available)
+	g.Expect(cond.LastTransitionTime).To(Equal(available.LastTransitionTime))
+}
+
+func TestTidbClusterConditionUpdater_ComponentConditions(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := &v1alpha1.TidbCluster{
+		Spec: v1alpha1.TidbClusterSpec{
+			PD:    &v1alpha1.PDSpec{Replicas: 2},
+			TiKV:  &v1alpha1.TiKVSpec{Replicas: 3},
+			TiCDC: &v1alpha1.TiCDCSpec{Replicas: 2},
+			Pump:  &v1alpha1.PumpSpec{Replicas: 1},
+		},
+		Status: v1alpha1.TidbClusterStatus{
+			PD: v1alpha1.PDStatus{
+				Phase: v1alpha1.UpgradePhase,
+				Members: map[string]v1alpha1.PDMember{
+					"pd-0": {Name: "pd-0", Health: true},
+					"pd-1": {Name: "pd-1", Health: false},
+				},
+			},
+			TiKV: v1alpha1.TiKVStatus{
+				Phase: v1alpha1.NormalPhase,
+				Stores: map[string]v1alpha1.TiKVStore{
+					"1": {ID: "1", PodName: "tikv-0", State: v1alpha1.TiKVStateUp},
+					"2": {ID: "2", PodName: "tikv-1", State: v1alpha1.TiKVStateOffline},
+					"3": {ID: "3", PodName: "tikv-2", State: v1alpha1.TiKVStateDown},
+				},
+			},
+			TiCDC: v1alpha1.TiCDCStatus{
+				Captures: map[string]v1alpha1.TiCDCCapture{
+					"ticdc-0": {PodName: "ticdc-0", ID: "a"},
+				},
+			},
+			Pump: v1alpha1.PumpStatus{
+				Members: []*v1alpha1.PumpNodeStatus{{NodeID: "pump-0", State: "online"}},
+			},
+		},
+	}
+	conditionUpdater := &tidbClusterConditionUpdater{}
+	expectCondition := func(conditions []v1alpha1.ComponentCondition, condType v1alpha1.ComponentConditionType, status v1.ConditionStatus, reason string) *v1alpha1.ComponentCondition {
+		cond := utiltidbcluster.GetComponentCondition(conditions, condType)
+		g.Expect(cond).NotTo(BeNil(), string(condType))
+		g.Expect(cond.Status).To(Equal(status), string(condType))
+		g.Expect(cond.Reason).To(Equal(reason), string(condType))
+		return cond
+	}
+
+	conditionUpdater.Update(tc)
+	cond := expectCondition(tc.Status.PD.Conditions, v1alpha1.ComponentHealthy, v1.ConditionFalse, utiltidbcluster.MemberUnhealthy)
+	g.Expect(cond.Message).To(Equal("member pd-1 is unhealthy"))
+	expectCondition(tc.Status.PD.Conditions, v1alpha1.ComponentUpgrading, v1.ConditionTrue, utiltidbcluster.RollingUpdate)
+	// the down stores take precedence over the offline ones
+	cond = expectCondition(tc.Status.TiKV.Conditions, v1alpha1.ComponentHealthy, v1.ConditionFalse, utiltidbcluster.StoreDown)
+	g.Expect(cond.Message).To(Equal("store 3 of pod tikv-2 is Down"))
+	expectCondition(tc.Status.TiKV.Conditions, v1alpha1.ComponentUpgrading, v1.ConditionFalse, utiltidbcluster.UpToDate)
+	expectCondition(tc.Status.TiCDC.Conditions, v1alpha1.ComponentHealthy, v1.ConditionFalse, utiltidbcluster.MemberUnhealthy)
+	expectCondition(tc.Status.Pump.Conditions, v1alpha1.ComponentHealthy, v1.ConditionTrue, utiltidbcluster.AllMembersHealthy)
+	g.Expect(tc.Status.TiDB.Conditions).To(BeEmpty())
+
+	// the detailed reason set by the upgrader is kept during the upgrade
+	upgrading := utiltidbcluster.NewComponentCondition(v1alpha1.ComponentUpgrading, v1.ConditionTrue, utiltidbcluster.WaitingForLeaderTransfer, "")
+	utiltidbcluster.SetComponentCondition(&tc.Status.PD.Conditions, *upgrading)
+	tc.Status.TiKV.Stores["3"] = v1alpha1.TiKVStore{ID: "3", PodName: "tikv-2", State: v1alpha1.TiKVStateUp}
+	conditionUpdater.Update(tc)
+	expectCondition(tc.Status.PD.Conditions, v1alpha1.ComponentUpgrading, v1.ConditionTrue, utiltidbcluster.WaitingForLeaderTransfer)
+	expectCondition(tc.Status.TiKV.Conditions, v1alpha1.ComponentHealthy, v1.ConditionFalse, utiltidbcluster.StoreNotUp)
+
+	// the upgrade is done
+	tc.Status.PD.Phase = v1alpha1.NormalPhase
+	tc.Status.PD.Members["pd-1"] = v1alpha1.PDMember{Name: "pd-1", Health: true}
+	conditionUpdater.Update(tc)
+	expectCondition(tc.Status.PD.Conditions, v1alpha1.ComponentUpgrading, v1.ConditionFalse, utiltidbcluster.UpToDate)
+	expectCondition(tc.Status.PD.Conditions, v1alpha1.ComponentHealthy, v1.ConditionTrue, utiltidbcluster.AllMembersHealthy)
+}
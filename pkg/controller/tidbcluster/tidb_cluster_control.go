@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/manager"
 	"github.com/pingcap/tidb-operator/pkg/manager/member"
 	"github.com/pingcap/tidb-operator/pkg/metrics"
+	"github.com/pingcap/tidb-operator/pkg/util/tracing"
 	v1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	errorutils "k8s.io/apimachinery/pkg/util/errors"
@@ -48,6 +49,26 @@ func NewDefaultTidbClusterControl(
 	orphanPodsCleaner member.OrphanPodsCleaner,
 	pvcCleaner member.PVCCleanerInterface,
 	pvcResizer member.PVCResizerInterface,
+	placementRebalancer manager.Manager,
+	podRestarter manager.Manager,
+	localPVRecoverer manager.Manager,
+	nodeFencer manager.Manager,
+	storageClassMigrator manager.Manager,
+	clusterAdopter manager.Manager,
+	pendingChangesPreviewer manager.Manager,
+	storageUsageCollector manager.Manager,
+	tombstoneStoreCleaner manager.Manager,
+	federationSyncer manager.Manager,
+	postUpgradeHook manager.Manager,
+	clusterRestorer manager.Manager,
+	clusterSuspender manager.Manager,
+	restartScheduler manager.Manager,
+	clusterClientTLSReplicator manager.Manager,
+	certManagerCertSyncer manager.Manager,
+	vaultCertIssuer manager.Manager,
+	tlsCABundleReloader manager.Manager,
+	tlsCertRotator manager.Manager,
+	tlsPolicySyncer manager.Manager,
 	pumpMemberManager manager.Manager,
 	tiflashMemberManager manager.Manager,
 	ticdcMemberManager manager.Manager,
@@ -56,42 +77,82 @@ func NewDefaultTidbClusterControl(
 	conditionUpdater TidbClusterConditionUpdater,
 	recorder record.EventRecorder) ControlInterface {
 	return &defaultTidbClusterControl{
-		tcControl:                tcControl,
-		pdMemberManager:          pdMemberManager,
-		tikvMemberManager:        tikvMemberManager,
-		tidbMemberManager:        tidbMemberManager,
-		reclaimPolicyManager:     reclaimPolicyManager,
-		metaManager:              metaManager,
-		orphanPodsCleaner:        orphanPodsCleaner,
-		pvcCleaner:               pvcCleaner,
-		pvcResizer:               pvcResizer,
-		pumpMemberManager:        pumpMemberManager,
-		tiflashMemberManager:     tiflashMemberManager,
-		ticdcMemberManager:       ticdcMemberManager,
-		discoveryManager:         discoveryManager,
-		tidbClusterStatusManager: tidbClusterStatusManager,
-		conditionUpdater:         conditionUpdater,
-		recorder:                 recorder,
+		tcControl:                  tcControl,
+		pdMemberManager:            pdMemberManager,
+		tikvMemberManager:          tikvMemberManager,
+		tidbMemberManager:          tidbMemberManager,
+		reclaimPolicyManager:       reclaimPolicyManager,
+		metaManager:                metaManager,
+		orphanPodsCleaner:          orphanPodsCleaner,
+		pvcCleaner:                 pvcCleaner,
+		pvcResizer:                 pvcResizer,
+		placementRebalancer:        placementRebalancer,
+		podRestarter:               podRestarter,
+		localPVRecoverer:           localPVRecoverer,
+		nodeFencer:                 nodeFencer,
+		storageClassMigrator:       storageClassMigrator,
+		clusterAdopter:             clusterAdopter,
+		pendingChangesPreviewer:    pendingChangesPreviewer,
+		storageUsageCollector:      storageUsageCollector,
+		tombstoneStoreCleaner:      tombstoneStoreCleaner,
+		federationSyncer:           federationSyncer,
+		postUpgradeHook:            postUpgradeHook,
+		clusterRestorer:            clusterRestorer,
+		clusterSuspender:           clusterSuspender,
+		restartScheduler:           restartScheduler,
+		clusterClientTLSReplicator: clusterClientTLSReplicator,
+		certManagerCertSyncer:      certManagerCertSyncer,
+		vaultCertIssuer:            vaultCertIssuer,
+		tlsCABundleReloader:        tlsCABundleReloader,
+		tlsCertRotator:             tlsCertRotator,
+		tlsPolicySyncer:            tlsPolicySyncer,
+		pumpMemberManager:          pumpMemberManager,
+		tiflashMemberManager:       tiflashMemberManager,
+		ticdcMemberManager:         ticdcMemberManager,
+		discoveryManager:           discoveryManager,
+		tidbClusterStatusManager:   tidbClusterStatusManager,
+		conditionUpdater:           conditionUpdater,
+		recorder:                   recorder,
 	}
 }
 
 type defaultTidbClusterControl struct {
-	tcControl                controller.TidbClusterControlInterface
-	pdMemberManager          manager.Manager
-	tikvMemberManager        manager.Manager
-	tidbMemberManager        manager.Manager
-	reclaimPolicyManager     manager.Manager
-	metaManager              manager.Manager
-	orphanPodsCleaner        member.OrphanPodsCleaner
-	pvcCleaner               member.PVCCleanerInterface
-	pvcResizer               member.PVCResizerInterface
-	pumpMemberManager        manager.Manager
-	tiflashMemberManager     manager.Manager
-	ticdcMemberManager       manager.Manager
-	discoveryManager         member.TidbDiscoveryManager
-	tidbClusterStatusManager manager.Manager
-	conditionUpdater         TidbClusterConditionUpdater
-	recorder                 record.EventRecorder
+	tcControl                  controller.TidbClusterControlInterface
+	pdMemberManager            manager.Manager
+	tikvMemberManager          manager.Manager
+	tidbMemberManager          manager.Manager
+	reclaimPolicyManager       manager.Manager
+	metaManager                manager.Manager
+	orphanPodsCleaner          member.OrphanPodsCleaner
+	pvcCleaner                 member.PVCCleanerInterface
+	pvcResizer                 member.PVCResizerInterface
+	placementRebalancer        manager.Manager
+	podRestarter               manager.Manager
+	localPVRecoverer           manager.Manager
+	nodeFencer                 manager.Manager
+	storageClassMigrator       manager.Manager
+	clusterAdopter             manager.Manager
+	pendingChangesPreviewer    manager.Manager
+	storageUsageCollector      manager.Manager
+	tombstoneStoreCleaner      manager.Manager
+	federationSyncer           manager.Manager
+	postUpgradeHook            manager.Manager
+	clusterRestorer            manager.Manager
+	clusterSuspender           manager.Manager
+	restartScheduler           manager.Manager
+	clusterClientTLSReplicator manager.Manager
+	certManagerCertSyncer      manager.Manager
+	vaultCertIssuer            manager.Manager
+	tlsCABundleReloader        manager.Manager
+	tlsCertRotator             manager.Manager
+	tlsPolicySyncer            manager.Manager
+	pumpMemberManager          manager.Manager
+	tiflashMemberManager       manager.Manager
+	ticdcMemberManager         manager.Manager
+	discoveryManager           member.TidbDiscoveryManager
+	tidbClusterStatusManager   manager.Manager
+	conditionUpdater           TidbClusterConditionUpdater
+	recorder                   record.EventRecorder
 }
 
 // UpdateStatefulSet executes the core logic loop for a tidbcluster.
@@ -107,16 +168,22 @@ func (c *defaultTidbClusterControl) UpdateTidbCluster(tc *v1alpha1.TidbCluster)
 	if err := c.updateTidbCluster(tc); err != nil {
 		errs = append(errs, err)
 	}
+	// the spec of the generation is processed even if the sync fails, the conditions tell the progress
+	tc.Status.ObservedGeneration = tc.Generation
 
 	if err := c.conditionUpdater.Update(tc); err != nil {
 		errs = append(errs, err)
 	}
+	recordOperations(tc, oldStatus)
 
 	if apiequality.Semantic.DeepEqual(&tc.Status, oldStatus) {
 		return errorutils.NewAggregate(errs)
 	}
 	if _, err := c.tcControl.UpdateTidbCluster(tc.DeepCopy(), &tc.Status, oldStatus); err != nil {
 		errs = append(errs, err)
+	} else {
+		// the events are recorded once the progress is persisted, so they are not repeated by the next sync
+		recordProgressEvents(c.recorder, tc, oldStatus)
 	}
 
 	return errorutils.NewAggregate(errs)
@@ -140,7 +207,7 @@ func (c *defaultTidbClusterControl) defaulting(tc *v1alpha1.TidbCluster) {
 func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster) error {
 	c.recordMetrics(tc)
 	// syncing all PVs managed by operator's reclaim policy to Retain
-	if err := c.reclaimPolicyManager.Sync(tc); err != nil {
+	if err := syncManager("ReclaimPolicyManager", c.reclaimPolicyManager, tc); err != nil {
 		return err
 	}
 
@@ -156,15 +223,76 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 		}
 	}
 
+	// force deleting the pods stuck on the nodes which are gone, so that they can be recreated
+	// on other nodes and the failover is not blocked
+	if err := syncManager("NodeFencer", c.nodeFencer, tc); err != nil {
+		return err
+	}
+
 	// reconcile TiDB discovery service
 	if err := c.discoveryManager.Reconcile(tc); err != nil {
 		return err
 	}
 
+	// discover the members of the existing cluster to adopt and pick the component to be created in
+	// Kubernetes, the member managers of the components after it are skipped until it is ready
+	if err := syncManager("ClusterAdopter", c.clusterAdopter, tc); err != nil {
+		return err
+	}
+
+	// restoring the data of spec.restore into the new cluster by BR once PD, TiKV and TiDB are ready,
+	// it runs before the member managers to tell whether the cluster is created with spec.restore
+	if err := syncManager("ClusterRestorer", c.clusterRestorer, tc); err != nil {
+		return err
+	}
+
+	// replicating the cluster client TLS secret of the TidbCluster referred by spec.cluster into the
+	// namespace of the joining TidbCluster if the certificates are not issued by the operator
+	if err := syncManager("ClusterClientTLSReplicator", c.clusterClientTLSReplicator, tc); err != nil {
+		return err
+	}
+
+	// generating the cert-manager Certificates of the cluster secrets if they are managed by the operator
+	if err := syncManager("CertManagerCertSyncer", c.certManagerCertSyncer, tc); err != nil {
+		return err
+	}
+
+	// issuing the certificates of the cluster secrets from Vault if they are managed by the operator
+	if err := syncManager("VaultCertIssuer", c.vaultCertIssuer, tc); err != nil {
+		return err
+	}
+
+	// propagating the CA bundle into the cluster secrets, the components which do not reload it
+	// online are restarted by the member managers below
+	if err := syncManager("TLSCABundleReloader", c.tlsCABundleReloader, tc); err != nil {
+		return err
+	}
+
+	// rotating the certificates in the cluster secrets which are to expire, the pods of the
+	// components whose certificates are rotated are restarted by the member managers below
+	if err := syncManager("TLSCertRotator", c.tlsCertRotator, tc); err != nil {
+		return err
+	}
+
+	// applying the TLS policies to the clients of the operator before they are used by the managers below
+	if err := syncManager("TLSPolicySyncer", c.tlsPolicySyncer, tc); err != nil {
+		return err
+	}
+
+	// scaling the components to zero one at a time if spec.suspend is set and back when it is unset,
+	// the member managers of the suspended components are skipped until they are resumed
+	if err := syncManager("ClusterSuspender", c.clusterSuspender, tc); err != nil {
+		return err
+	}
+
+	// removing the members which are healthy again from the results of the failover simulation,
+	// the failovers of the components below record the members which would be declared failed
+	member.PruneSimulatedFailovers(tc)
+
 	//   - waiting for the pd cluster available(pd cluster is in quorum)
 	//   - create or update ticdc deployment
 	//   - sync ticdc cluster status from pd to TidbCluster object
-	if err := c.ticdcMemberManager.Sync(tc); err != nil {
+	if err := syncMemberManager("TiCDCMemberManager", v1alpha1.TiCDCMemberType, c.ticdcMemberManager, tc); err != nil {
 		return err
 	}
 
@@ -179,7 +307,7 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 	//   - upgrade the pd cluster
 	//   - scale out/in the pd cluster
 	//   - failover the pd cluster
-	if err := c.pdMemberManager.Sync(tc); err != nil {
+	if err := syncMemberManager("PDMemberManager", v1alpha1.PDMemberType, c.pdMemberManager, tc); err != nil {
 		return err
 	}
 
@@ -192,7 +320,7 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 	//   - upgrade the tiflash cluster
 	//   - scale out/in the tiflash cluster
 	//   - failover the tiflash cluster
-	if err := c.tiflashMemberManager.Sync(tc); err != nil {
+	if err := syncMemberManager("TiFlashMemberManager", v1alpha1.TiFlashMemberType, c.tiflashMemberManager, tc); err != nil {
 		return err
 	}
 
@@ -205,12 +333,12 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 	//   - upgrade the tikv cluster
 	//   - scale out/in the tikv cluster
 	//   - failover the tikv cluster
-	if err := c.tikvMemberManager.Sync(tc); err != nil {
+	if err := syncMemberManager("TiKVMemberManager", v1alpha1.TiKVMemberType, c.tikvMemberManager, tc); err != nil {
 		return err
 	}
 
 	// syncing the pump cluster
-	if err := c.pumpMemberManager.Sync(tc); err != nil {
+	if err := syncMemberManager("PumpMemberManager", v1alpha1.PumpMemberType, c.pumpMemberManager, tc); err != nil {
 		return err
 	}
 
@@ -222,7 +350,13 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 	//   - upgrade the tidb cluster
 	//   - scale out/in the tidb cluster
 	//   - failover the tidb cluster
-	if err := c.tidbMemberManager.Sync(tc); err != nil {
+	if err := syncMemberManager("TiDBMemberManager", v1alpha1.TiDBMemberType, c.tidbMemberManager, tc); err != nil {
+		return err
+	}
+
+	// record the changes of the objects which would be applied for the current spec in the status
+	// if the cluster is paused with the dry-run annotation
+	if err := syncManager("PendingChangesPreviewer", c.pendingChangesPreviewer, tc); err != nil {
 		return err
 	}
 
@@ -230,7 +364,7 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 	//   - label.StoreIDLabelKey
 	//   - label.MemberIDLabelKey
 	//   - label.NamespaceLabelKey
-	if err := c.metaManager.Sync(tc); err != nil {
+	if err := syncManager("MetaManager", c.metaManager, tc); err != nil {
 		return err
 	}
 
@@ -250,9 +384,76 @@ func (c *defaultTidbClusterControl) updateTidbCluster(tc *v1alpha1.TidbCluster)
 		return err
 	}
 
+	// report the disk usage of the stores and volumes in the status
+	if err := syncManager("StorageUsageCollector", c.storageUsageCollector, tc); err != nil {
+		return err
+	}
+
+	// remove the tombstone stores from PD after the retention period if enabled
+	if err := syncManager("TombstoneStoreCleaner", c.tombstoneStoreCleaner, tc); err != nil {
+		return err
+	}
+
+	// migrate the pd and tikv pods violating HA placement one at a time if enabled
+	if err := syncManager("PlacementRebalancer", c.placementRebalancer, tc); err != nil {
+		return err
+	}
+
+	// request the restarts of the pods of the components in the maintenance windows of spec.restartSchedules
+	if err := syncManager("RestartScheduler", c.restartScheduler, tc); err != nil {
+		return err
+	}
+
+	// restart the pods requested by the restart ordinals annotations gracefully one at a time
+	if err := syncManager("PodRestarter", c.podRestarter, tc); err != nil {
+		return err
+	}
+
+	// release the dead local PVs so that the pods using them can be rescheduled
+	if err := syncManager("LocalPVRecoverer", c.localPVRecoverer, tc); err != nil {
+		return err
+	}
+
+	// migrate the pd and tikv volumes to the storage classes in the spec one member at a time if enabled
+	if err := syncManager("StorageClassMigrator", c.storageClassMigrator, tc); err != nil {
+		return err
+	}
+
+	// report the peering with the TidbClusters in the other Kubernetes clusters if the cluster domain is set
+	if err := syncManager("FederationSyncer", c.federationSyncer, tc); err != nil {
+		return err
+	}
+
+	// execute the post-upgrade hook by a job after all the TiDB pods are upgraded to a new version
+	if err := syncManager("PostUpgradeHook", c.postUpgradeHook, tc); err != nil {
+		return err
+	}
+
 	// syncing the some tidbcluster status attributes
 	// 	- sync tidbmonitor reference
-	return c.tidbClusterStatusManager.Sync(tc)
+	return syncManager("TidbClusterStatusManager", c.tidbClusterStatusManager, tc)
+}
+
+// syncManager syncs the tidbcluster with the manager in a span named after the manager
+func syncManager(name string, m manager.Manager, tc *v1alpha1.TidbCluster) error {
+	span := tracing.Start(tc.Namespace, tc.Name, name)
+	err := m.Sync(tc)
+	span.End(err)
+	return err
+}
+
+// syncMemberManager syncs the member manager of the component unless the component is not created yet
+// when an existing cluster is adopted, or it is suspended
+func syncMemberManager(name string, memberType v1alpha1.MemberType, m manager.Manager, tc *v1alpha1.TidbCluster) error {
+	if tc.AdoptionPending(memberType) {
+		klog.V(4).Infof("tidbcluster %s/%s is being adopted, skip syncing %s", tc.Namespace, tc.Name, memberType)
+		return nil
+	}
+	if tc.ComponentSuspended(memberType) {
+		klog.V(4).Infof("tidbcluster %s/%s is suspended, skip syncing %s", tc.Namespace, tc.Name, memberType)
+		return nil
+	}
+	return syncManager(name, m, tc)
 }
 
 func (c *defaultTidbClusterControl) recordMetrics(tc *v1alpha1.TidbCluster) {
@@ -281,6 +281,20 @@ func TestTidbClusterControlUpdateTidbCluster(t *testing.T) {
 	}
 }
 
+func TestTidbClusterControlObservedGeneration(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTidbClusterControl()
+	tc.Generation = 3
+	control, _, _, pdMemberManager, _, _, _, _, _ := newFakeTidbClusterControl()
+	pdMemberManager.SetSyncError(fmt.Errorf("pd member manager sync error"))
+
+	// the generation is observed even if the sync fails
+	err := control.UpdateTidbCluster(tc)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(tc.Status.ObservedGeneration).To(Equal(int64(3)))
+}
+
 func TestTidbClusterStatusEquality(t *testing.T) {
 	g := NewGomegaWithT(t)
 	tcStatus := v1alpha1.TidbClusterStatus{}
@@ -332,6 +346,26 @@ func newFakeTidbClusterControl() (
 		orphanPodCleaner,
 		pvcCleaner,
 		pvcResizer,
+		mm.NewFakePlacementRebalancer(),
+		mm.NewFakePodRestarter(),
+		mm.NewFakeLocalPVRecoverer(),
+		mm.NewFakeNodeFencer(),
+		mm.NewFakeStorageClassMigrator(),
+		mm.NewFakeClusterAdopter(),
+		mm.NewFakePendingChangesPreviewer(),
+		mm.NewFakeStorageUsageCollector(),
+		mm.NewFakeTombstoneStoreCleaner(),
+		mm.NewFakeFederationSyncer(),
+		mm.NewFakePostUpgradeHook(),
+		mm.NewFakeClusterRestorer(),
+		mm.NewFakeClusterSuspender(),
+		mm.NewFakeRestartScheduler(),
+		mm.NewFakeClusterClientTLSReplicator(),
+		mm.NewFakeCertManagerCertSyncer(),
+		mm.NewFakeVaultCertIssuer(),
+		mm.NewFakeTLSCABundleReloader(),
+		mm.NewFakeTLSCertRotator(),
+		mm.NewFakeTLSPolicySyncer(),
 		pumpMemberManager,
 		tiflashMemberManager,
 		ticdcMemberManager,
@@ -22,14 +22,16 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	mm "github.com/pingcap/tidb-operator/pkg/manager/member"
 	"github.com/pingcap/tidb-operator/pkg/manager/meta"
+	"github.com/pingcap/tidb-operator/pkg/metrics"
+	"github.com/pingcap/tidb-operator/pkg/util/logging"
+	"github.com/pingcap/tidb-operator/pkg/util/tracing"
 	apps "k8s.io/api/apps/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
-	"k8s.io/klog"
 )
 
 // Controller controls tidbclusters.
@@ -40,6 +42,7 @@ type Controller struct {
 	control ControlInterface
 	// tidbclusters that need to be synced.
 	queue workqueue.RateLimitingInterface
+	log   logging.Logger
 }
 
 // NewController creates a tidbcluster controller.
@@ -56,6 +59,26 @@ func NewController(deps *controller.Dependencies) *Controller {
 			mm.NewOrphanPodsCleaner(deps),
 			mm.NewRealPVCCleaner(deps),
 			mm.NewPVCResizer(deps),
+			mm.NewPlacementRebalancer(deps),
+			mm.NewPodRestarter(deps),
+			mm.NewLocalPVRecoverer(deps),
+			mm.NewNodeFencer(deps),
+			mm.NewStorageClassMigrator(deps),
+			mm.NewClusterAdopter(deps),
+			mm.NewPendingChangesPreviewer(deps),
+			mm.NewStorageUsageCollector(deps),
+			mm.NewTombstoneStoreCleaner(deps),
+			mm.NewFederationSyncer(deps),
+			mm.NewPostUpgradeHook(deps),
+			mm.NewClusterRestorer(deps),
+			mm.NewClusterSuspender(deps),
+			mm.NewRestartScheduler(deps),
+			mm.NewClusterClientTLSReplicator(deps),
+			mm.NewCertManagerCertSyncer(deps),
+			mm.NewVaultCertIssuer(deps),
+			mm.NewTLSCABundleReloader(deps),
+			mm.NewTLSCertRotator(deps),
+			mm.NewTLSPolicySyncer(deps),
 			mm.NewPumpMemberManager(deps, mm.NewPumpScaler(deps)),
 			mm.NewTiFlashMemberManager(deps, mm.NewTiFlashFailover(deps), mm.NewTiFlashScaler(deps), mm.NewTiFlashUpgrader(deps)),
 			mm.NewTiCDCMemberManager(deps, mm.NewTiCDCScaler(deps), mm.NewTiCDCUpgrader(deps)),
@@ -65,27 +88,28 @@ func NewController(deps *controller.Dependencies) *Controller {
 			deps.Recorder,
 		),
 		queue: workqueue.NewNamedRateLimitingQueue(
-			controller.NewControllerRateLimiter(1*time.Second, 100*time.Second),
+			controller.NewClusterRateLimiter("tidbcluster", 1*time.Second, 100*time.Second),
 			"tidbcluster",
 		),
+		log: logging.ForController("tidbcluster"),
 	}
 
 	tidbClusterInformer := deps.InformerFactory.Pingcap().V1alpha1().TidbClusters()
-	statefulsetInformer := deps.KubeInformerFactory.Apps().V1().StatefulSets()
-	tidbClusterInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+	statefulsetInformer := deps.LabelFilterKubeInformerFactory.Apps().V1().StatefulSets()
+	tidbClusterInformer.Informer().AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
 		AddFunc: c.enqueueTidbCluster,
 		UpdateFunc: func(old, cur interface{}) {
-			c.enqueueTidbCluster(cur)
+			c.updateTidbCluster(old, cur)
 		},
 		DeleteFunc: c.enqueueTidbCluster,
-	})
-	statefulsetInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+	}, deps.CLIConfig.GetResyncDuration("tidbcluster"))
+	statefulsetInformer.Informer().AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
 		AddFunc: c.addStatefulSet,
 		UpdateFunc: func(old, cur interface{}) {
 			c.updateStatefulSet(old, cur)
 		},
 		DeleteFunc: c.deleteStatefulSet,
-	})
+	}, deps.CLIConfig.GetResyncDuration("tidbcluster"))
 
 	return c
 }
@@ -93,24 +117,14 @@ func NewController(deps *controller.Dependencies) *Controller {
 // Run runs the tidbcluster controller.
 func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
 	defer utilruntime.HandleCrash()
-	defer c.queue.ShutDown()
 
-	klog.Info("Starting tidbcluster controller")
-	defer klog.Info("Shutting down tidbcluster controller")
+	c.log.Info("Starting controller")
+	defer c.log.Info("Shutting down controller")
 
-	for i := 0; i < workers; i++ {
-		go wait.Until(c.worker, time.Second, stopCh)
-	}
-
-	<-stopCh
+	controller.RunWorkers(c.queue, workers, c.processNextWorkItem, stopCh)
 }
 
 // worker runs a worker goroutine that invokes processNextWorkItem until the the controller's queue is closed
-func (c *Controller) worker() {
-	for c.processNextWorkItem() {
-	}
-}
-
 // processNextWorkItem dequeues items, processes them, and marks them done. It enforces that the syncHandler is never
 // invoked concurrently with the same key.
 func (c *Controller) processNextWorkItem() bool {
@@ -119,14 +133,20 @@ func (c *Controller) processNextWorkItem() bool {
 		return false
 	}
 	defer c.queue.Done(key)
-	if err := c.sync(key.(string)); err != nil {
+	startTime := time.Now()
+	span := tracing.StartReconcile(key.(string))
+	err := c.sync(key.(string))
+	span.End(err)
+	controller.ObserveReconcile("tidbcluster", startTime, err)
+	if err != nil {
 		if perrors.Find(err, controller.IsRequeueError) != nil {
-			klog.Infof("TidbCluster: %v, still need sync: %v, requeuing", key.(string), err)
+			c.clusterLog(key.(string)).Info("Still need sync, requeuing", "reason", err)
 		} else {
 			utilruntime.HandleError(fmt.Errorf("TidbCluster: %v, sync failed %v, requeuing", key.(string), err))
 		}
 		c.queue.AddRateLimited(key)
 	} else {
+		metrics.RecordClusterSynced(key.(string))
 		c.queue.Forget(key)
 	}
 	return true
@@ -136,7 +156,7 @@ func (c *Controller) processNextWorkItem() bool {
 func (c *Controller) sync(key string) error {
 	startTime := time.Now()
 	defer func() {
-		klog.V(4).Infof("Finished syncing TidbCluster %q (%v)", key, time.Since(startTime))
+		c.clusterLog(key).V(4).Info("Finished syncing", "duration", time.Since(startTime))
 	}()
 
 	ns, name, err := cache.SplitMetaNamespaceKey(key)
@@ -145,7 +165,7 @@ func (c *Controller) sync(key string) error {
 	}
 	tc, err := c.deps.TiDBClusterLister.TidbClusters(ns).Get(name)
 	if errors.IsNotFound(err) {
-		klog.Infof("TidbCluster has been deleted %v", key)
+		c.clusterLog(key).Info("TidbCluster has been deleted")
 		return nil
 	}
 	if err != nil {
@@ -155,6 +175,15 @@ func (c *Controller) sync(key string) error {
 	return c.syncTidbCluster(tc.DeepCopy())
 }
 
+// clusterLog returns the logger of the TidbCluster of key
+func (c *Controller) clusterLog(key string) logging.Logger {
+	ns, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return c.log.WithValues("key", key)
+	}
+	return c.log.WithCluster(ns, name)
+}
+
 func (c *Controller) syncTidbCluster(tc *v1alpha1.TidbCluster) error {
 	return c.control.UpdateTidbCluster(tc)
 }
@@ -169,6 +198,29 @@ func (c *Controller) enqueueTidbCluster(obj interface{}) {
 	c.queue.Add(key)
 }
 
+// updateTidbCluster enqueues the TidbCluster if it is changed, the backoff of the TidbCluster is reset if its spec or
+// metadata is changed, and the status-only changes are skipped while the TidbCluster is backing off, so
+// that writing the status of a TidbCluster waiting for something does not lead to a hot reconcile loop.
+func (c *Controller) updateTidbCluster(old, cur interface{}) {
+	oldTC := old.(*v1alpha1.TidbCluster)
+	curTC := cur.(*v1alpha1.TidbCluster)
+	key, err := cache.MetaNamespaceKeyFunc(curTC)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("Cound't get key for object %+v: %v", curTC, err))
+		return
+	}
+	if !apiequality.Semantic.DeepEqual(oldTC.Spec, curTC.Spec) ||
+		!apiequality.Semantic.DeepEqual(oldTC.Labels, curTC.Labels) ||
+		!apiequality.Semantic.DeepEqual(oldTC.Annotations, curTC.Annotations) ||
+		curTC.DeletionTimestamp != nil {
+		c.queue.Forget(key)
+	} else if c.queue.NumRequeues(key) > 0 {
+		c.clusterLog(key).V(4).Info("Backing off, skip syncing the status change")
+		return
+	}
+	c.queue.Add(key)
+}
+
 // addStatefulSet adds the tidbcluster for the statefulset to the sync queue
 func (c *Controller) addStatefulSet(obj interface{}) {
 	set := obj.(*apps.StatefulSet)
@@ -187,7 +239,7 @@ func (c *Controller) addStatefulSet(obj interface{}) {
 	if tc == nil {
 		return
 	}
-	klog.V(4).Infof("StatefulSet %s/%s created, TidbCluster: %s/%s", ns, setName, ns, tc.Name)
+	c.log.WithCluster(ns, tc.Name).V(4).Info("StatefulSet created", "statefulset", setName)
 	c.enqueueTidbCluster(tc)
 }
 
@@ -208,7 +260,7 @@ func (c *Controller) updateStatefulSet(old, cur interface{}) {
 	if tc == nil {
 		return
 	}
-	klog.V(4).Infof("StatefulSet %s/%s updated, TidbCluster: %s/%s", ns, setName, ns, tc.Name)
+	c.log.WithCluster(ns, tc.Name).V(4).Info("StatefulSet updated", "statefulset", setName)
 	c.enqueueTidbCluster(tc)
 }
 
@@ -239,7 +291,7 @@ func (c *Controller) deleteStatefulSet(obj interface{}) {
 	if tc == nil {
 		return
 	}
-	klog.V(4).Infof("StatefulSet %s/%s deleted through %v.", ns, setName, utilruntime.GetCaller())
+	c.log.WithCluster(ns, tc.Name).V(4).Info("StatefulSet deleted", "statefulset", setName, "caller", utilruntime.GetCaller())
 	c.enqueueTidbCluster(tc)
 }
 
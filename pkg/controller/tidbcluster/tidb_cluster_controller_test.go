@@ -47,6 +47,35 @@ func TestTidbClusterControllerEnqueueTidbClusterFailed(t *testing.T) {
 	g.Expect(tcc.queue.Len()).To(Equal(0))
 }
 
+func TestTidbClusterControllerUpdateTidbCluster(t *testing.T) {
+	g := NewGomegaWithT(t)
+	tcc := NewController(controller.NewFakeDependencies())
+	tcc.control = NewFakeTidbClusterControlInterface()
+	tc := newTidbCluster()
+	key, err := cache.MetaNamespaceKeyFunc(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	statusChanged := tc.DeepCopy()
+	statusChanged.Status.ClusterID = "cluster-id"
+	tcc.updateTidbCluster(tc, statusChanged)
+	g.Expect(tcc.queue.Len()).To(Equal(1))
+	item, _ := tcc.queue.Get()
+	tcc.queue.Done(item)
+
+	// the status changes are skipped while the cluster is backing off
+	tcc.queue.AddRateLimited(key)
+	tcc.updateTidbCluster(tc, statusChanged)
+	g.Expect(tcc.queue.Len()).To(Equal(0))
+	g.Expect(tcc.queue.NumRequeues(key)).To(Equal(1))
+
+	// the backoff is reset on spec change
+	specChanged := tc.DeepCopy()
+	specChanged.Spec.PD.Replicas++
+	tcc.updateTidbCluster(tc, specChanged)
+	g.Expect(tcc.queue.Len()).To(Equal(1))
+	g.Expect(tcc.queue.NumRequeues(key)).To(Equal(0))
+}
+
 func TestTidbClusterControllerAddStatefulSet(t *testing.T) {
 	g := NewGomegaWithT(t)
 	type testcase struct {
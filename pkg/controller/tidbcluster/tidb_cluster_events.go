@@ -0,0 +1,181 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbcluster
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/manager/member"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+)
+
+// componentProgress is the progress of a component which is compared between two syncs
+type componentProgress struct {
+	memberType v1alpha1.MemberType
+	phase      v1alpha1.MemberPhase
+	replicas   int32
+	configured *v1alpha1.ComponentCondition
+	upgrade    *v1alpha1.UpgradeProgress
+	// failures are the messages of the failed members, keyed by the pod name
+	failures map[string]string
+}
+
+func clusterProgress(status *v1alpha1.TidbClusterStatus) []componentProgress {
+	progress := func(memberType v1alpha1.MemberType, phase v1alpha1.MemberPhase, sts *apps.StatefulSetStatus, conditions []v1alpha1.ComponentCondition,
+		upgrade *v1alpha1.UpgradeProgress, failures map[string]string) componentProgress {
+		p := componentProgress{
+			memberType: memberType,
+			phase:      phase,
+			configured: utiltidbcluster.GetComponentCondition(conditions, v1alpha1.ComponentConfigSynced),
+			upgrade:    upgrade,
+			failures:   failures,
+		}
+		if sts != nil {
+			p.replicas = sts.Replicas
+		}
+		return p
+	}
+	pdFailures := map[string]string{}
+	for _, m := range status.PD.FailureMembers {
+		pdFailures[m.PodName] = fmt.Sprintf("member %s failed", m.MemberID)
+	}
+	tidbFailures := map[string]string{}
+	for _, m := range status.TiDB.FailureMembers {
+		tidbFailures[m.PodName] = "pod failed"
+	}
+	storeFailures := func(stores map[string]v1alpha1.TiKVFailureStore) map[string]string {
+		failures := map[string]string{}
+		for _, s := range stores {
+			// the failure stores recorded by the storage class migration are not failovers
+			if !s.StorageClassMigration {
+				failures[s.PodName] = fmt.Sprintf("store %s failed", s.StoreID)
+			}
+		}
+		return failures
+	}
+	return []componentProgress{
+		progress(v1alpha1.PDMemberType, status.PD.Phase, status.PD.StatefulSet, status.PD.Conditions, status.PD.UpgradeProgress, pdFailures),
+		progress(v1alpha1.TiKVMemberType, status.TiKV.Phase, status.TiKV.StatefulSet, status.TiKV.Conditions, status.TiKV.UpgradeProgress, storeFailures(status.TiKV.FailureStores)),
+		progress(v1alpha1.TiDBMemberType, status.TiDB.Phase, status.TiDB.StatefulSet, status.TiDB.Conditions, status.TiDB.UpgradeProgress, tidbFailures),
+		progress(v1alpha1.TiFlashMemberType, status.TiFlash.Phase, status.TiFlash.StatefulSet, status.TiFlash.Conditions, status.TiFlash.UpgradeProgress, storeFailures(status.TiFlash.FailureStores)),
+		progress(v1alpha1.TiCDCMemberType, status.TiCDC.Phase, status.TiCDC.StatefulSet, status.TiCDC.Conditions, status.TiCDC.UpgradeProgress, nil),
+		progress(v1alpha1.PumpMemberType, status.Pump.Phase, status.Pump.StatefulSet, status.Pump.Conditions, nil, nil),
+	}
+}
+
+// desiredReplicas returns the replicas the component is scaled to
+func desiredReplicas(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) int32 {
+	switch memberType {
+	case v1alpha1.PDMemberType:
+		return tc.PDStsDesiredReplicas()
+	case v1alpha1.TiKVMemberType:
+		return tc.TiKVStsDesiredReplicas()
+	case v1alpha1.TiDBMemberType:
+		return tc.TiDBStsDesiredReplicas()
+	case v1alpha1.TiFlashMemberType:
+		return tc.TiFlashStsDesiredReplicas()
+	case v1alpha1.TiCDCMemberType:
+		return tc.TiCDCDeployDesiredReplicas()
+	case v1alpha1.PumpMemberType:
+		if tc.Spec.Pump != nil {
+			return tc.Spec.Pump.Replicas
+		}
+	}
+	return 0
+}
+
+// recordOperations records the scaling, the upgrade, the failover and the config rollout of the components in
+// the operation history of the status, by comparing the status before and after the sync
+func recordOperations(tc *v1alpha1.TidbCluster, oldStatus *v1alpha1.TidbClusterStatus) {
+	status := &tc.Status
+	olds := clusterProgress(oldStatus)
+	for i, cur := range clusterProgress(status) {
+		old := olds[i]
+		// the phase is empty before the component is created
+		if old.phase != "" && old.phase != cur.phase {
+			switch old.phase {
+			case v1alpha1.ScalePhase:
+				utiltidbcluster.FinishOperation(status, v1alpha1.ScaleOperation, cur.memberType, "", "")
+			case v1alpha1.UpgradePhase:
+				utiltidbcluster.FinishOperation(status, v1alpha1.UpgradeOperation, cur.memberType, "", "")
+			}
+			switch cur.phase {
+			case v1alpha1.ScalePhase:
+				utiltidbcluster.StartOperation(status, v1alpha1.ScaleOperation, cur.memberType, "",
+					fmt.Sprintf("scale from %d to %d replicas", old.replicas, desiredReplicas(tc, cur.memberType)))
+			case v1alpha1.UpgradePhase:
+				message := ""
+				if cur.upgrade != nil && cur.upgrade.TargetVersion != "" {
+					message = fmt.Sprintf("upgrade to %s", cur.upgrade.TargetVersion)
+				}
+				utiltidbcluster.StartOperation(status, v1alpha1.UpgradeOperation, cur.memberType, "", message)
+			}
+		}
+		for _, podName := range sets.StringKeySet(cur.failures).List() {
+			if _, ok := old.failures[podName]; !ok {
+				utiltidbcluster.StartOperation(status, v1alpha1.FailoverOperation, cur.memberType, podName, cur.failures[podName])
+			}
+		}
+		for _, podName := range sets.StringKeySet(old.failures).List() {
+			if _, ok := cur.failures[podName]; !ok {
+				utiltidbcluster.FinishOperation(status, v1alpha1.FailoverOperation, cur.memberType, podName, "")
+			}
+		}
+		if cur.configured == nil || (old.configured != nil && old.configured.Status == cur.configured.Status) {
+			continue
+		}
+		if cur.configured.Status == v1.ConditionFalse {
+			utiltidbcluster.StartOperation(status, v1alpha1.ConfigRolloutOperation, cur.memberType, "", cur.configured.Message)
+		} else if old.configured != nil {
+			utiltidbcluster.FinishOperation(status, v1alpha1.ConfigRolloutOperation, cur.memberType, "", cur.configured.Message)
+		}
+	}
+}
+
+// recordProgressEvents records the beginning and the end of the scaling, the upgrade and the config rollout
+// of the components, by comparing the status before and after the sync
+func recordProgressEvents(recorder record.EventRecorder, tc *v1alpha1.TidbCluster, oldStatus *v1alpha1.TidbClusterStatus) {
+	olds := clusterProgress(oldStatus)
+	for i, cur := range clusterProgress(&tc.Status) {
+		old := olds[i]
+		// the phase is empty before the component is created
+		if old.phase != "" && old.phase != cur.phase {
+			switch old.phase {
+			case v1alpha1.ScalePhase:
+				recorder.Eventf(tc, v1.EventTypeNormal, member.ScaleCompletedReason, "%s is scaled to %d replicas", cur.memberType, cur.replicas)
+			case v1alpha1.UpgradePhase:
+				recorder.Eventf(tc, v1.EventTypeNormal, member.UpgradeCompletedReason, "%s is upgraded", cur.memberType)
+			}
+			switch cur.phase {
+			case v1alpha1.ScalePhase:
+				recorder.Eventf(tc, v1.EventTypeNormal, member.ScaleStartedReason, "%s begins to scale from %d replicas", cur.memberType, old.replicas)
+			case v1alpha1.UpgradePhase:
+				recorder.Eventf(tc, v1.EventTypeNormal, member.UpgradeStartedReason, "%s begins to be upgraded", cur.memberType)
+			}
+		}
+		if cur.configured == nil || (old.configured != nil && old.configured.Status == cur.configured.Status) {
+			continue
+		}
+		if cur.configured.Status == v1.ConditionFalse {
+			recorder.Eventf(tc, v1.EventTypeNormal, member.ConfigRolloutStartedReason, "%s config is being rolled out: %s", cur.memberType, cur.configured.Message)
+		} else if old.configured != nil {
+			recorder.Eventf(tc, v1.EventTypeNormal, member.ConfigRolloutCompletedReason, "%s config is rolled out: %s", cur.memberType, cur.configured.Message)
+		}
+	}
+}
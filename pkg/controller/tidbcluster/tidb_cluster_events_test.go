@@ -0,0 +1,180 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbcluster
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestRecordProgressEvents(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	collect := func(recorder *record.FakeRecorder) []string {
+		var events []string
+		for {
+			select {
+			case e := <-recorder.Events:
+				events = append(events, e)
+			default:
+				return events
+			}
+		}
+	}
+	outOfSync := utiltidbcluster.NewComponentCondition(v1alpha1.ComponentConfigSynced, v1.ConditionFalse, utiltidbcluster.ConfigOutOfSync, "statefulset demo-tikv mounts ConfigMap demo-tikv-a instead of demo-tikv-b")
+	inSync := utiltidbcluster.NewComponentCondition(v1alpha1.ComponentConfigSynced, v1.ConditionTrue, utiltidbcluster.ConfigInSync, "all pods use ConfigMap demo-tikv-b")
+
+	tests := []struct {
+		name     string
+		old      v1alpha1.TidbClusterStatus
+		cur      v1alpha1.TidbClusterStatus
+		expected []string
+	}{
+		{
+			name:     "component created",
+			cur:      v1alpha1.TidbClusterStatus{PD: v1alpha1.PDStatus{Phase: v1alpha1.NormalPhase}},
+			expected: nil,
+		},
+		{
+			name: "scale out begins",
+			old:  v1alpha1.TidbClusterStatus{TiKV: v1alpha1.TiKVStatus{Phase: v1alpha1.NormalPhase, StatefulSet: &apps.StatefulSetStatus{Replicas: 3}}},
+			cur:  v1alpha1.TidbClusterStatus{TiKV: v1alpha1.TiKVStatus{Phase: v1alpha1.ScalePhase, StatefulSet: &apps.StatefulSetStatus{Replicas: 3}}},
+			expected: []string{
+				"Normal ScaleStarted tikv begins to scale from 3 replicas",
+			},
+		},
+		{
+			name: "scale completes and upgrade begins",
+			old:  v1alpha1.TidbClusterStatus{TiKV: v1alpha1.TiKVStatus{Phase: v1alpha1.ScalePhase, StatefulSet: &apps.StatefulSetStatus{Replicas: 4}}},
+			cur:  v1alpha1.TidbClusterStatus{TiKV: v1alpha1.TiKVStatus{Phase: v1alpha1.UpgradePhase, StatefulSet: &apps.StatefulSetStatus{Replicas: 5}}},
+			expected: []string{
+				"Normal ScaleCompleted tikv is scaled to 5 replicas",
+				"Normal UpgradeStarted tikv begins to be upgraded",
+			},
+		},
+		{
+			name: "config rollout begins",
+			old:  v1alpha1.TidbClusterStatus{TiKV: v1alpha1.TiKVStatus{Conditions: []v1alpha1.ComponentCondition{*inSync}}},
+			cur:  v1alpha1.TidbClusterStatus{TiKV: v1alpha1.TiKVStatus{Conditions: []v1alpha1.ComponentCondition{*outOfSync}}},
+			expected: []string{
+				"Normal ConfigRolloutStarted tikv config is being rolled out: " + outOfSync.Message,
+			},
+		},
+		{
+			name: "config rollout completes",
+			old:  v1alpha1.TidbClusterStatus{TiKV: v1alpha1.TiKVStatus{Conditions: []v1alpha1.ComponentCondition{*outOfSync}}},
+			cur:  v1alpha1.TidbClusterStatus{TiKV: v1alpha1.TiKVStatus{Conditions: []v1alpha1.ComponentCondition{*inSync}}},
+			expected: []string{
+				"Normal ConfigRolloutCompleted tikv config is rolled out: " + inSync.Message,
+			},
+		},
+		{
+			name:     "config synced for the first time",
+			cur:      v1alpha1.TidbClusterStatus{TiKV: v1alpha1.TiKVStatus{Conditions: []v1alpha1.ComponentCondition{*inSync}}},
+			expected: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(10)
+			tc := &v1alpha1.TidbCluster{Status: tt.cur}
+			recordProgressEvents(recorder, tc, &tt.old)
+			g.Expect(collect(recorder)).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestRecordOperations(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := &v1alpha1.TidbCluster{
+		Spec: v1alpha1.TidbClusterSpec{TiKV: &v1alpha1.TiKVSpec{Replicas: 5}},
+	}
+	sync := func(update func(status *v1alpha1.TidbClusterStatus)) {
+		old := tc.Status.DeepCopy()
+		update(&tc.Status)
+		recordOperations(tc, old)
+	}
+	expectOperation := func(i int, opType v1alpha1.OperationType, target string, result v1alpha1.OperationResult, message string) {
+		g.Expect(len(tc.Status.Operations)).To(BeNumerically(">", i))
+		op := tc.Status.Operations[i]
+		g.Expect(op.Type).To(Equal(opType))
+		g.Expect(op.Component).To(Equal(v1alpha1.TiKVMemberType))
+		g.Expect(op.Target).To(Equal(target))
+		g.Expect(op.Result).To(Equal(result))
+		g.Expect(op.Message).To(Equal(message))
+	}
+
+	sync(func(status *v1alpha1.TidbClusterStatus) {
+		status.TiKV.Phase = v1alpha1.NormalPhase
+		status.TiKV.StatefulSet = &apps.StatefulSetStatus{Replicas: 3}
+	})
+	g.Expect(tc.Status.Operations).To(BeEmpty())
+
+	sync(func(status *v1alpha1.TidbClusterStatus) {
+		status.TiKV.Phase = v1alpha1.ScalePhase
+	})
+	expectOperation(0, v1alpha1.ScaleOperation, "", v1alpha1.OperationInProgress, "scale from 3 to 5 replicas")
+
+	sync(func(status *v1alpha1.TidbClusterStatus) {
+		status.TiKV.Phase = v1alpha1.UpgradePhase
+		status.TiKV.StatefulSet.Replicas = 5
+		status.TiKV.UpgradeProgress = &v1alpha1.UpgradeProgress{TargetVersion: "v4.0.8", Replicas: 5}
+	})
+	expectOperation(0, v1alpha1.ScaleOperation, "", v1alpha1.OperationSucceeded, "scale from 3 to 5 replicas")
+	g.Expect(tc.Status.Operations[0].CompletionTime).NotTo(BeNil())
+	expectOperation(1, v1alpha1.UpgradeOperation, "", v1alpha1.OperationInProgress, "upgrade to v4.0.8")
+
+	sync(func(status *v1alpha1.TidbClusterStatus) {
+		status.TiKV.Phase = v1alpha1.NormalPhase
+		status.TiKV.UpgradeProgress = nil
+		status.TiKV.FailureStores = map[string]v1alpha1.TiKVFailureStore{
+			"4": {PodName: "demo-tikv-1", StoreID: "4"},
+			"5": {PodName: "demo-tikv-2", StoreID: "5", StorageClassMigration: true},
+		}
+	})
+	expectOperation(1, v1alpha1.UpgradeOperation, "", v1alpha1.OperationSucceeded, "upgrade to v4.0.8")
+	expectOperation(2, v1alpha1.FailoverOperation, "demo-tikv-1", v1alpha1.OperationInProgress, "store 4 failed")
+	g.Expect(tc.Status.Operations).To(HaveLen(3))
+
+	sync(func(status *v1alpha1.TidbClusterStatus) {
+		status.TiKV.FailureStores = nil
+		status.TiKV.Conditions = []v1alpha1.ComponentCondition{
+			*utiltidbcluster.NewComponentCondition(v1alpha1.ComponentConfigSynced, v1.ConditionTrue, utiltidbcluster.ConfigInSync, "all pods use ConfigMap demo-tikv-a"),
+		}
+	})
+	expectOperation(2, v1alpha1.FailoverOperation, "demo-tikv-1", v1alpha1.OperationSucceeded, "store 4 failed")
+	// the config synced for the first time is not a rollout
+	g.Expect(tc.Status.Operations).To(HaveLen(3))
+
+	sync(func(status *v1alpha1.TidbClusterStatus) {
+		status.TiKV.Conditions = []v1alpha1.ComponentCondition{
+			*utiltidbcluster.NewComponentCondition(v1alpha1.ComponentConfigSynced, v1.ConditionFalse, utiltidbcluster.ConfigOutOfSync, "statefulset demo-tikv mounts ConfigMap demo-tikv-a instead of demo-tikv-b"),
+		}
+	})
+	expectOperation(3, v1alpha1.ConfigRolloutOperation, "", v1alpha1.OperationInProgress, "statefulset demo-tikv mounts ConfigMap demo-tikv-a instead of demo-tikv-b")
+
+	sync(func(status *v1alpha1.TidbClusterStatus) {
+		status.TiKV.Conditions = []v1alpha1.ComponentCondition{
+			*utiltidbcluster.NewComponentCondition(v1alpha1.ComponentConfigSynced, v1.ConditionTrue, utiltidbcluster.ConfigInSync, "all pods use ConfigMap demo-tikv-b"),
+		}
+	})
+	expectOperation(3, v1alpha1.ConfigRolloutOperation, "", v1alpha1.OperationSucceeded, "all pods use ConfigMap demo-tikv-b")
+}
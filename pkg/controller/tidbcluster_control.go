@@ -20,6 +20,8 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
 	tcinformers "github.com/pingcap/tidb-operator/pkg/client/informers/externalversions/pingcap/v1alpha1"
 	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/util/tracing"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/tools/cache"
@@ -56,13 +58,24 @@ func (c *realTidbClusterControl) UpdateTidbCluster(tc *v1alpha1.TidbCluster, new
 	ns := tc.GetNamespace()
 	tcName := tc.GetName()
 
-	status := tc.Status.DeepCopy()
+	desired := tc.DeepCopy()
 	var updateTC *v1alpha1.TidbCluster
 
+	// skip the no-op update if the cached object is the same, e.g. the status has been written by a previous sync
+	if cached, err := c.tcLister.TidbClusters(ns).Get(tcName); err == nil && tcUpToDate(cached, desired) {
+		klog.V(4).Infof("TidbCluster: [%s/%s] is up to date, skip updating", ns, tcName)
+		return cached.DeepCopy(), nil
+	}
+	// the spec, labels and annotations changed meanwhile by others are kept on conflicts,
+	// only the status is applied again onto the refreshed object
+	specConflicted := false
+
 	// don't wait due to limited number of clients, but backoff after the default number of steps
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		var updateErr error
+		span := tracing.StartObject(tc, "UpdateTidbCluster", tc.Name)
 		updateTC, updateErr = c.cli.PingcapV1alpha1().TidbClusters(ns).Update(tc)
+		span.End(updateErr)
 		if updateErr == nil {
 			klog.Infof("TidbCluster: [%s/%s] updated successfully", ns, tcName)
 			return nil
@@ -70,9 +83,10 @@ func (c *realTidbClusterControl) UpdateTidbCluster(tc *v1alpha1.TidbCluster, new
 		klog.V(4).Infof("failed to update TidbCluster: [%s/%s], error: %v", ns, tcName, updateErr)
 
 		if updated, err := c.tcLister.TidbClusters(ns).Get(tcName); err == nil {
+			specConflicted = !tcMetaSpecUpToDate(updated, desired)
 			// make a copy so we don't mutate the shared cache
 			tc = updated.DeepCopy()
-			tc.Status = *status
+			tc.Status = *desired.Status.DeepCopy()
 		} else {
 			utilruntime.HandleError(fmt.Errorf("error getting updated TidbCluster %s/%s from lister: %v", ns, tcName, err))
 		}
@@ -81,8 +95,27 @@ func (c *realTidbClusterControl) UpdateTidbCluster(tc *v1alpha1.TidbCluster, new
 	})
 	if err != nil {
 		klog.Errorf("failed to update TidbCluster: [%s/%s], error: %v", ns, tcName, err)
+		return updateTC, err
 	}
-	return updateTC, err
+	if specConflicted {
+		// the spec changes are computed again by the caller from the refreshed object
+		return updateTC, RequeueErrorf("the spec, labels or annotations of TidbCluster %s/%s are changed concurrently, only the status is updated", ns, tcName)
+	}
+	return updateTC, nil
+}
+
+// tcUpToDate returns whether the spec, labels, annotations and status of the cached TidbCluster
+// are semantically equal to the desired ones
+func tcUpToDate(cached, desired *v1alpha1.TidbCluster) bool {
+	return tcMetaSpecUpToDate(cached, desired) && apiequality.Semantic.DeepEqual(&cached.Status, &desired.Status)
+}
+
+// tcMetaSpecUpToDate returns whether the spec, labels and annotations of the cached TidbCluster
+// are semantically equal to the desired ones
+func tcMetaSpecUpToDate(cached, desired *v1alpha1.TidbCluster) bool {
+	return apiequality.Semantic.DeepEqual(cached.Labels, desired.Labels) &&
+		apiequality.Semantic.DeepEqual(cached.Annotations, desired.Annotations) &&
+		apiequality.Semantic.DeepEqual(&cached.Spec, &desired.Spec)
 }
 
 func (c *realTidbClusterControl) Create(*v1alpha1.TidbCluster) error {
@@ -34,7 +34,9 @@ func TestTidbClusterControlUpdateTidbCluster(t *testing.T) {
 	tc := newTidbCluster()
 	tc.Spec.PD.Replicas = int32(5)
 	fakeClient := &fake.Clientset{}
-	control := NewRealTidbClusterControl(fakeClient, nil, recorder)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	tcLister := listers.NewTidbClusterLister(indexer)
+	control := NewRealTidbClusterControl(fakeClient, tcLister, recorder)
 	fakeClient.AddReactor("update", "tidbclusters", func(action core.Action) (bool, runtime.Object, error) {
 		update := action.(core.UpdateAction)
 		return true, update.GetObject(), nil
@@ -64,3 +66,60 @@ func TestTidbClusterControlUpdateTidbClusterConflictSuccess(t *testing.T) {
 	_, err := control.UpdateTidbCluster(tc, &v1alpha1.TidbClusterStatus{}, &v1alpha1.TidbClusterStatus{})
 	g.Expect(err).To(Succeed())
 }
+
+func TestTidbClusterControlUpdateTidbClusterUpToDate(t *testing.T) {
+	g := NewGomegaWithT(t)
+	recorder := record.NewFakeRecorder(10)
+	tc := newTidbCluster()
+	tc.Status.ClusterID = "cluster-id"
+	fakeClient := &fake.Clientset{}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	// the status has been written by a previous sync
+	g.Expect(indexer.Add(tc.DeepCopy())).To(Succeed())
+	tcLister := listers.NewTidbClusterLister(indexer)
+	control := NewRealTidbClusterControl(fakeClient, tcLister, recorder)
+	updates := 0
+	fakeClient.AddReactor("update", "tidbclusters", func(action core.Action) (bool, runtime.Object, error) {
+		updates++
+		update := action.(core.UpdateAction)
+		return true, update.GetObject(), nil
+	})
+	updateTC, err := control.UpdateTidbCluster(tc.DeepCopy(), &tc.Status, &v1alpha1.TidbClusterStatus{})
+	g.Expect(err).To(Succeed())
+	g.Expect(updates).To(Equal(0))
+	g.Expect(updateTC.Status.ClusterID).To(Equal("cluster-id"))
+}
+
+func TestTidbClusterControlUpdateTidbClusterConflictSpecEdited(t *testing.T) {
+	g := NewGomegaWithT(t)
+	recorder := record.NewFakeRecorder(10)
+	tc := newTidbCluster()
+	tc.Spec.TiKV.Replicas = 3
+	fakeClient := &fake.Clientset{}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	// the spec is edited by the user after the sync reads the TidbCluster
+	edited := tc.DeepCopy()
+	edited.Spec.TiKV.Replicas = 5
+	edited.ResourceVersion = "2"
+	g.Expect(indexer.Add(edited)).To(Succeed())
+	tcLister := listers.NewTidbClusterLister(indexer)
+	control := NewRealTidbClusterControl(fakeClient, tcLister, recorder)
+	var written *v1alpha1.TidbCluster
+	fakeClient.AddReactor("update", "tidbclusters", func(action core.Action) (bool, runtime.Object, error) {
+		update := action.(core.UpdateAction)
+		obj := update.GetObject().(*v1alpha1.TidbCluster)
+		if obj.ResourceVersion != edited.ResourceVersion {
+			return true, nil, apierrors.NewConflict(action.GetResource().GroupResource(), tc.Name, errors.New("conflict"))
+		}
+		written = obj.DeepCopy()
+		return true, obj, nil
+	})
+	synced := tc.DeepCopy()
+	synced.Status.ClusterID = "cluster-id"
+	_, err := control.UpdateTidbCluster(synced, &synced.Status, &tc.Status)
+	g.Expect(IsRequeueError(err)).To(BeTrue())
+	// the status is written without reverting the edited spec
+	g.Expect(written).NotTo(BeNil())
+	g.Expect(written.Spec.TiKV.Replicas).To(Equal(int32(5)))
+	g.Expect(written.Status.ClusterID).To(Equal("cluster-id"))
+}
@@ -0,0 +1,511 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbclusterclone
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// CloneReason is the reason of the events emitted by the TidbClusterClone controller
+	CloneReason = "Clone"
+)
+
+// ControlInterface reconciles TidbClusterClone
+type ControlInterface interface {
+	// ReconcileTidbClusterClone implements the reconcile logic of TidbClusterClone
+	ReconcileTidbClusterClone(tcc *v1alpha1.TidbClusterClone) error
+}
+
+// NewDefaultTidbClusterCloneControl returns a new instance of the default TidbClusterClone ControlInterface
+func NewDefaultTidbClusterCloneControl(deps *controller.Dependencies) ControlInterface {
+	return &defaultTidbClusterCloneControl{deps: deps}
+}
+
+// defaultTidbClusterCloneControl clones the source TidbCluster into the target TidbCluster step by step.
+//
+// The data of the source is backed up by the Backup `<clone>-source` in the namespace of the TidbClusterClone.
+// After the Backup completes, the target is created with the spec of the source and the replicas of
+// `spec.target`, and the data is restored into it by `spec.restore` of the target from the storage of the Backup.
+// Then the masked columns are updated by the TidbInitializer `<target>-mask` in the namespace of the target.
+// The clone is done once, the target is not deleted with the TidbClusterClone, and a failed clone is not retried.
+type defaultTidbClusterCloneControl struct {
+	deps *controller.Dependencies
+}
+
+func (c *defaultTidbClusterCloneControl) ReconcileTidbClusterClone(tcc *v1alpha1.TidbClusterClone) error {
+	ns := tcc.GetNamespace()
+	name := tcc.GetName()
+	if tcc.DeletionTimestamp != nil {
+		return nil
+	}
+
+	oldStatus := tcc.Status.DeepCopy()
+	syncErr := c.sync(tcc)
+	if !apiequality.Semantic.DeepEqual(&tcc.Status, oldStatus) {
+		if _, err := c.deps.Clientset.PingcapV1alpha1().TidbClusterClones(ns).UpdateStatus(tcc); err != nil {
+			return fmt.Errorf("tidbclusterclone %s/%s: failed to update status, error: %v", ns, name, err)
+		}
+	}
+	return syncErr
+}
+
+func (c *defaultTidbClusterCloneControl) sync(tcc *v1alpha1.TidbClusterClone) error {
+	status := &tcc.Status
+	switch status.Phase {
+	case "":
+		if err := validateTidbClusterClone(tcc); err != nil {
+			c.fail(tcc, err.Error())
+			return nil
+		}
+		now := metav1.Now()
+		status.StartTime = &now
+		status.Phase = v1alpha1.ClonePhaseBackingUp
+		return c.syncBackup(tcc)
+	case v1alpha1.ClonePhaseBackingUp:
+		return c.syncBackup(tcc)
+	case v1alpha1.ClonePhaseRestoring:
+		return c.syncRestore(tcc)
+	case v1alpha1.ClonePhaseMasking:
+		return c.syncMask(tcc)
+	}
+	return nil
+}
+
+// syncBackup creates the Backup of the source and creates the target after it completes
+func (c *defaultTidbClusterCloneControl) syncBackup(tcc *v1alpha1.TidbClusterClone) error {
+	ns := tcc.GetNamespace()
+	name := tcc.GetName()
+	status := &tcc.Status
+	if status.BackupName == "" {
+		backup := &v1alpha1.Backup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            sourceBackupName(name),
+				Namespace:       ns,
+				OwnerReferences: []metav1.OwnerReference{controller.GetTidbClusterCloneOwnerRef(tcc)},
+			},
+			Spec: *tcc.Spec.Backup.DeepCopy(),
+		}
+		if backup.Spec.BR == nil {
+			backup.Spec.BR = &v1alpha1.BRConfig{}
+		}
+		backup.Spec.BR.Cluster = tcc.Spec.Source.Name
+		backup.Spec.BR.ClusterNamespace = sourceNamespace(tcc)
+		if _, err := c.deps.Clientset.PingcapV1alpha1().Backups(ns).Create(backup); err != nil && !errors.IsAlreadyExists(err) {
+			status.Message = fmt.Sprintf("failed to create backup %s: %v", backup.Name, err)
+			return fmt.Errorf("tidbclusterclone %s/%s: failed to create backup %s, error: %v", ns, name, backup.Name, err)
+		}
+		status.BackupName = backup.Name
+		status.Message = ""
+		klog.Infof("tidbclusterclone %s/%s: created backup %s of the source", ns, name, backup.Name)
+		c.deps.Recorder.Eventf(tcc, corev1.EventTypeNormal, CloneReason, "created backup %s of the source", backup.Name)
+		return controller.RequeueErrorf("tidbclusterclone %s/%s: waiting for backup %s to complete", ns, name, backup.Name)
+	}
+
+	backup, err := c.deps.BackupLister.Backups(ns).Get(status.BackupName)
+	if errors.IsNotFound(err) {
+		c.fail(tcc, fmt.Sprintf("backup %s is deleted before it completes", status.BackupName))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("tidbclusterclone %s/%s: failed to get backup %s, error: %v", ns, name, status.BackupName, err)
+	}
+	if v1alpha1.IsBackupFailed(backup) || v1alpha1.IsBackupInvalid(backup) {
+		c.fail(tcc, fmt.Sprintf("backup %s failed: %s", backup.Name, backupMessage(backup)))
+		return nil
+	}
+	if !v1alpha1.IsBackupComplete(backup) {
+		return controller.RequeueErrorf("tidbclusterclone %s/%s: waiting for backup %s to complete", ns, name, backup.Name)
+	}
+	return c.createTarget(tcc, backup)
+}
+
+// createTarget creates the target with the spec of the source, which restores the data from the Backup
+func (c *defaultTidbClusterCloneControl) createTarget(tcc *v1alpha1.TidbClusterClone, backup *v1alpha1.Backup) error {
+	ns := tcc.GetNamespace()
+	name := tcc.GetName()
+	status := &tcc.Status
+	sourceNs := sourceNamespace(tcc)
+	source, err := c.deps.TiDBClusterLister.TidbClusters(sourceNs).Get(tcc.Spec.Source.Name)
+	if errors.IsNotFound(err) {
+		c.fail(tcc, fmt.Sprintf("source tidbcluster %s/%s is not found", sourceNs, tcc.Spec.Source.Name))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("tidbclusterclone %s/%s: failed to get source tidbcluster %s/%s, error: %v", ns, name, sourceNs, tcc.Spec.Source.Name, err)
+	}
+
+	target := newTargetTidbCluster(tcc, source, backup)
+	// the target and its restore run in the target namespace, so the secrets they reference must exist there
+	missing, err := c.missingSecrets(target)
+	if err != nil {
+		return fmt.Errorf("tidbclusterclone %s/%s: failed to get the secrets in namespace %s, error: %v", ns, name, target.Namespace, err)
+	}
+	if len(missing) > 0 {
+		msg := fmt.Sprintf("secrets %s referenced by the source or the backup are not found in namespace %s, create them to continue the clone", strings.Join(missing, ", "), target.Namespace)
+		if status.Message != msg {
+			klog.Warningf("tidbclusterclone %s/%s: %s", ns, name, msg)
+			c.deps.Recorder.Event(tcc, corev1.EventTypeWarning, CloneReason, msg)
+		}
+		status.Message = msg
+		return controller.RequeueErrorf("tidbclusterclone %s/%s: %s", ns, name, msg)
+	}
+	if _, err := c.deps.Clientset.PingcapV1alpha1().TidbClusters(target.Namespace).Create(target); err != nil {
+		if !errors.IsAlreadyExists(err) {
+			status.Message = fmt.Sprintf("failed to create tidbcluster %s/%s: %v", target.Namespace, target.Name, err)
+			return fmt.Errorf("tidbclusterclone %s/%s: failed to create tidbcluster %s/%s, error: %v", ns, name, target.Namespace, target.Name, err)
+		}
+		existing, err := c.deps.Clientset.PingcapV1alpha1().TidbClusters(target.Namespace).Get(target.Name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("tidbclusterclone %s/%s: failed to get tidbcluster %s/%s, error: %v", ns, name, target.Namespace, target.Name, err)
+		}
+		if existing.Annotations[label.AnnTidbClusterClone] != target.Annotations[label.AnnTidbClusterClone] {
+			c.fail(tcc, fmt.Sprintf("tidbcluster %s/%s already exists", target.Namespace, target.Name))
+			return nil
+		}
+	}
+	status.Phase = v1alpha1.ClonePhaseRestoring
+	status.TidbClusterName = target.Name
+	status.Message = ""
+	klog.Infof("tidbclusterclone %s/%s: created tidbcluster %s/%s to restore the data of the source", ns, name, target.Namespace, target.Name)
+	c.deps.Recorder.Eventf(tcc, corev1.EventTypeNormal, CloneReason, "created tidbcluster %s/%s to restore the data of the source", target.Namespace, target.Name)
+	return controller.RequeueErrorf("tidbclusterclone %s/%s: waiting for the data to be restored", ns, name)
+}
+
+// syncRestore waits for the data to be restored into the target
+func (c *defaultTidbClusterCloneControl) syncRestore(tcc *v1alpha1.TidbClusterClone) error {
+	ns := tcc.GetNamespace()
+	name := tcc.GetName()
+	status := &tcc.Status
+	target, err := c.getTarget(tcc)
+	if err != nil || target == nil {
+		return err
+	}
+	restore := target.Status.Restore
+	if restore == nil {
+		return controller.RequeueErrorf("tidbclusterclone %s/%s: waiting for the data to be restored", ns, name)
+	}
+	switch restore.Phase {
+	case v1alpha1.TidbClusterRestoreFailed:
+		// the failed Restore may be deleted to restore the data again
+		status.Message = fmt.Sprintf("failed to restore the data: %s", restore.Message)
+		return controller.RequeueErrorf("tidbclusterclone %s/%s: %s", ns, name, status.Message)
+	case v1alpha1.TidbClusterRestoreSkipped:
+		c.fail(tcc, fmt.Sprintf("the data is not restored: %s", restore.Message))
+		return nil
+	case v1alpha1.TidbClusterRestoreComplete:
+	default:
+		status.Message = restore.Message
+		return controller.RequeueErrorf("tidbclusterclone %s/%s: waiting for the data to be restored", ns, name)
+	}
+
+	sql := maskSQL(tcc.Spec.Masks)
+	if sql == "" {
+		c.complete(tcc)
+		return nil
+	}
+	image := tcc.Spec.MaskImage
+	if image == "" {
+		image = c.deps.CLIConfig.TiDBInitializerImage
+	}
+	ti := &v1alpha1.TidbInitializer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        maskInitializerName(target.Name),
+			Namespace:   target.Namespace,
+			Annotations: map[string]string{label.AnnTidbClusterClone: cloneKey(tcc)},
+		},
+		Spec: v1alpha1.TidbInitializerSpec{
+			Image:    image,
+			Clusters: v1alpha1.TidbClusterRef{Namespace: target.Namespace, Name: target.Name},
+			InitSql:  pointer.StringPtr(sql),
+		},
+	}
+	if _, err := c.deps.Clientset.PingcapV1alpha1().TidbInitializers(ti.Namespace).Create(ti); err != nil && !errors.IsAlreadyExists(err) {
+		status.Message = fmt.Sprintf("failed to create tidbinitializer %s/%s: %v", ti.Namespace, ti.Name, err)
+		return fmt.Errorf("tidbclusterclone %s/%s: failed to create tidbinitializer %s/%s, error: %v", ns, name, ti.Namespace, ti.Name, err)
+	}
+	status.Phase = v1alpha1.ClonePhaseMasking
+	status.InitializerName = ti.Name
+	status.Message = ""
+	klog.Infof("tidbclusterclone %s/%s: created tidbinitializer %s/%s to mask the columns", ns, name, ti.Namespace, ti.Name)
+	c.deps.Recorder.Eventf(tcc, corev1.EventTypeNormal, CloneReason, "created tidbinitializer %s/%s to mask the columns", ti.Namespace, ti.Name)
+	return controller.RequeueErrorf("tidbclusterclone %s/%s: waiting for the columns to be masked", ns, name)
+}
+
+// syncMask waits for the TidbInitializer to update the masked columns
+func (c *defaultTidbClusterCloneControl) syncMask(tcc *v1alpha1.TidbClusterClone) error {
+	ns := tcc.GetNamespace()
+	name := tcc.GetName()
+	targetNs := tcc.Spec.Target.Namespace
+	ti, err := c.deps.TiDBInitializerLister.TidbInitializers(targetNs).Get(tcc.Status.InitializerName)
+	if errors.IsNotFound(err) {
+		c.fail(tcc, fmt.Sprintf("tidbinitializer %s/%s is deleted before the columns are masked", targetNs, tcc.Status.InitializerName))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("tidbclusterclone %s/%s: failed to get tidbinitializer %s/%s, error: %v", ns, name, targetNs, tcc.Status.InitializerName, err)
+	}
+	switch ti.Status.Phase {
+	case v1alpha1.InitializePhaseCompleted:
+		c.complete(tcc)
+		return nil
+	case v1alpha1.InitializePhaseFailed:
+		c.fail(tcc, fmt.Sprintf("tidbinitializer %s/%s failed to mask the columns", targetNs, ti.Name))
+		return nil
+	}
+	return controller.RequeueErrorf("tidbclusterclone %s/%s: waiting for the columns to be masked", ns, name)
+}
+
+// missingSecrets returns the sorted names of the secrets referenced by the target which don't exist in its namespace
+func (c *defaultTidbClusterCloneControl) missingSecrets(target *v1alpha1.TidbCluster) ([]string, error) {
+	var missing []string
+	for _, secretName := range referencedSecrets(target).List() {
+		_, err := c.deps.SecretLister.Secrets(target.Namespace).Get(secretName)
+		if errors.IsNotFound(err) {
+			missing = append(missing, secretName)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return missing, nil
+}
+
+// getTarget returns the target, nil if it is deleted and the clone fails
+func (c *defaultTidbClusterCloneControl) getTarget(tcc *v1alpha1.TidbClusterClone) (*v1alpha1.TidbCluster, error) {
+	targetNs := tcc.Spec.Target.Namespace
+	target, err := c.deps.TiDBClusterLister.TidbClusters(targetNs).Get(tcc.Status.TidbClusterName)
+	if errors.IsNotFound(err) {
+		c.fail(tcc, fmt.Sprintf("tidbcluster %s/%s is deleted before the clone completes", targetNs, tcc.Status.TidbClusterName))
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tidbclusterclone %s/%s: failed to get tidbcluster %s/%s, error: %v",
+			tcc.GetNamespace(), tcc.GetName(), targetNs, tcc.Status.TidbClusterName, err)
+	}
+	return target, nil
+}
+
+func (c *defaultTidbClusterCloneControl) complete(tcc *v1alpha1.TidbClusterClone) {
+	now := metav1.Now()
+	tcc.Status.Phase = v1alpha1.ClonePhaseComplete
+	tcc.Status.Message = ""
+	tcc.Status.CompletionTime = &now
+	klog.Infof("tidbclusterclone %s/%s: the source is cloned into tidbcluster %s/%s", tcc.GetNamespace(), tcc.GetName(), tcc.Spec.Target.Namespace, tcc.Status.TidbClusterName)
+	c.deps.Recorder.Eventf(tcc, corev1.EventTypeNormal, CloneReason, "the source is cloned into tidbcluster %s/%s", tcc.Spec.Target.Namespace, tcc.Status.TidbClusterName)
+}
+
+func (c *defaultTidbClusterCloneControl) fail(tcc *v1alpha1.TidbClusterClone, msg string) {
+	tcc.Status.Phase = v1alpha1.ClonePhaseFailed
+	tcc.Status.Message = msg
+	klog.Errorf("tidbclusterclone %s/%s: %s", tcc.GetNamespace(), tcc.GetName(), msg)
+	c.deps.Recorder.Event(tcc, corev1.EventTypeWarning, CloneReason, msg)
+}
+
+// newTargetTidbCluster returns the target with the spec of the source, the replicas of `spec.target`, and
+// `spec.restore` from the storage of the Backup
+func newTargetTidbCluster(tcc *v1alpha1.TidbClusterClone, source *v1alpha1.TidbCluster, backup *v1alpha1.Backup) *v1alpha1.TidbCluster {
+	spec := source.Spec.DeepCopy()
+	// the target is a standalone cluster running from the start
+	spec.Cluster = nil
+	spec.PDAddresses = nil
+	spec.Paused = false
+	spec.Suspend = false
+	spec.Restore = &v1alpha1.TidbClusterRestoreSpec{
+		RestoreSpec: &v1alpha1.RestoreSpec{
+			ResourceRequirements: backup.Spec.ResourceRequirements,
+			Env:                  backup.Spec.Env,
+			Type:                 backup.Spec.Type,
+			StorageProvider:      backup.Spec.StorageProvider,
+			BR:                   backup.Spec.BR.DeepCopy(),
+			Tolerations:          backup.Spec.Tolerations,
+			Affinity:             backup.Spec.Affinity,
+			UseKMS:               backup.Spec.UseKMS,
+			ServiceAccount:       backup.Spec.ServiceAccount,
+			ToolImage:            backup.Spec.ToolImage,
+			ImagePullSecrets:     backup.Spec.ImagePullSecrets,
+			TableFilter:          backup.Spec.TableFilter,
+			PodSecurityContext:   backup.Spec.PodSecurityContext,
+			PriorityClassName:    backup.Spec.PriorityClassName,
+		},
+	}
+	target := tcc.Spec.Target
+	if spec.PD != nil && target.PDReplicas != nil {
+		spec.PD.Replicas = *target.PDReplicas
+	}
+	if spec.TiKV != nil && target.TiKVReplicas != nil {
+		spec.TiKV.Replicas = *target.TiKVReplicas
+	}
+	if spec.TiFlash != nil && target.TiFlashReplicas != nil {
+		spec.TiFlash.Replicas = *target.TiFlashReplicas
+	}
+	if spec.TiDB != nil && target.TiDBReplicas != nil {
+		spec.TiDB.Replicas = *target.TiDBReplicas
+	}
+
+	return &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        targetName(tcc),
+			Namespace:   target.Namespace,
+			Labels:      util.CopyStringMap(source.Labels),
+			Annotations: map[string]string{label.AnnTidbClusterClone: cloneKey(tcc)},
+		},
+		Spec: *spec,
+	}
+}
+
+// referencedSecrets returns the names of the secrets referenced by the spec and `spec.restore` of the target,
+// which are the same as those in the namespaces of the source and the Backup. The secrets named after the target,
+// e.g. the TLS certificates of the components, are not included.
+func referencedSecrets(tc *v1alpha1.TidbCluster) sets.String {
+	secrets := sets.NewString()
+	for _, ref := range tc.Spec.ImagePullSecrets {
+		secrets.Insert(ref.Name)
+	}
+	if tls := tc.Spec.TLSCluster; tls != nil {
+		if tls.CABundle != nil {
+			secrets.Insert(tls.CABundle.SecretName)
+		}
+		if tls.Vault != nil {
+			secrets.Insert(tls.Vault.CASecretName, tls.Vault.TokenSecretName)
+		}
+	}
+	if tc.Spec.TiKV != nil && tc.Spec.TiKV.Encryption != nil {
+		secrets.Insert(tc.Spec.TiKV.Encryption.MasterKey.SecretName)
+	}
+	if tc.Spec.TiCDC != nil {
+		secrets.Insert(tc.Spec.TiCDC.TLSClientSecretNames...)
+	}
+	if restore := tc.Spec.Restore; restore != nil && restore.RestoreSpec != nil {
+		spec := restore.RestoreSpec
+		if spec.S3 != nil {
+			secrets.Insert(spec.S3.SecretName)
+		}
+		if spec.Gcs != nil {
+			secrets.Insert(spec.Gcs.SecretName)
+		}
+		if spec.Local != nil && spec.Local.Volume.Secret != nil {
+			secrets.Insert(spec.Local.Volume.Secret.SecretName)
+		}
+		for _, ref := range spec.ImagePullSecrets {
+			secrets.Insert(ref.Name)
+		}
+		for _, env := range spec.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+				secrets.Insert(env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+	}
+	secrets.Delete("")
+	return secrets
+}
+
+// maskSQL returns the statements which update the masked columns, one for each table
+func maskSQL(masks []v1alpha1.ColumnMask) string {
+	sets := map[string][]string{}
+	for _, mask := range masks {
+		expr := mask.Expression
+		if expr == "" {
+			expr = "NULL"
+		}
+		sets[mask.Table] = append(sets[mask.Table], fmt.Sprintf("%s = %s", quoteIdentifier(mask.Column), expr))
+	}
+	tables := make([]string, 0, len(sets))
+	for table := range sets {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	stmts := make([]string, 0, len(tables))
+	for _, table := range tables {
+		parts := strings.SplitN(table, ".", 2)
+		stmts = append(stmts, fmt.Sprintf("UPDATE %s.%s SET %s;", quoteIdentifier(parts[0]), quoteIdentifier(parts[1]), strings.Join(sets[table], ", ")))
+	}
+	return strings.Join(stmts, "\n")
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
+
+func validateTidbClusterClone(tcc *v1alpha1.TidbClusterClone) error {
+	if tcc.Spec.Source.Name == "" {
+		return fmt.Errorf("spec.source.name must be set")
+	}
+	if tcc.Spec.Target.Namespace == "" {
+		return fmt.Errorf("spec.target.namespace must be set")
+	}
+	if tcc.Spec.Target.Namespace == sourceNamespace(tcc) && targetName(tcc) == tcc.Spec.Source.Name {
+		return fmt.Errorf("the target must not be the source")
+	}
+	for _, mask := range tcc.Spec.Masks {
+		parts := strings.SplitN(mask.Table, ".", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("invalid table %q of the mask, it must be in the format of <database>.<table>", mask.Table)
+		}
+		if mask.Column == "" {
+			return fmt.Errorf("the column of the mask of table %s must be set", mask.Table)
+		}
+	}
+	return nil
+}
+
+func backupMessage(backup *v1alpha1.Backup) string {
+	for _, cond := range backup.Status.Conditions {
+		if (cond.Type == v1alpha1.BackupFailed || cond.Type == v1alpha1.BackupInvalid) && cond.Status == corev1.ConditionTrue {
+			return cond.Message
+		}
+	}
+	return ""
+}
+
+func sourceNamespace(tcc *v1alpha1.TidbClusterClone) string {
+	if tcc.Spec.Source.Namespace != "" {
+		return tcc.Spec.Source.Namespace
+	}
+	return tcc.GetNamespace()
+}
+
+func targetName(tcc *v1alpha1.TidbClusterClone) string {
+	if tcc.Spec.Target.Name != "" {
+		return tcc.Spec.Target.Name
+	}
+	return tcc.Spec.Source.Name
+}
+
+func cloneKey(tcc *v1alpha1.TidbClusterClone) string {
+	return fmt.Sprintf("%s/%s", tcc.GetNamespace(), tcc.GetName())
+}
+
+func sourceBackupName(name string) string {
+	return fmt.Sprintf("%s-source", name)
+}
+
+func maskInitializerName(tcName string) string {
+	return fmt.Sprintf("%s-mask", tcName)
+}
@@ -0,0 +1,216 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbclusterclone
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestTidbClusterCloneControl(t *testing.T) {
+	g := NewGomegaWithT(t)
+	deps, control, tcc := newFakeTidbClusterCloneControl(g)
+	tcc.Spec.Target.TiKVReplicas = pointer.Int32Ptr(1)
+	tcc.Spec.Target.TiDBReplicas = pointer.Int32Ptr(1)
+	tcc.Spec.Masks = []v1alpha1.ColumnMask{
+		{Table: "app.users", Column: "email", Expression: "MD5(email)"},
+		{Table: "app.users", Column: "phone"},
+		{Table: "app.orders", Column: "address"},
+	}
+	backupIndexer := deps.InformerFactory.Pingcap().V1alpha1().Backups().Informer().GetIndexer()
+	tcIndexer := deps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer()
+	tiIndexer := deps.InformerFactory.Pingcap().V1alpha1().TidbInitializers().Informer().GetIndexer()
+
+	// the source is backed up first
+	err := control.ReconcileTidbClusterClone(tcc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tcc.Status.Phase).To(Equal(v1alpha1.ClonePhaseBackingUp))
+	g.Expect(tcc.Status.StartTime).NotTo(BeNil())
+	g.Expect(tcc.Status.BackupName).To(Equal("clone-source"))
+	backup, err := deps.Clientset.PingcapV1alpha1().Backups(tcc.Namespace).Get(tcc.Status.BackupName, metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(backup.Spec.BR).To(Equal(&v1alpha1.BRConfig{Cluster: "prod", ClusterNamespace: "prod"}))
+	g.Expect(backup.Spec.S3).To(Equal(tcc.Spec.Backup.S3))
+	g.Expect(backup.OwnerReferences).To(HaveLen(1))
+
+	g.Expect(backupIndexer.Add(backup)).To(Succeed())
+	err = control.ReconcileTidbClusterClone(tcc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tcc.Status.Phase).To(Equal(v1alpha1.ClonePhaseBackingUp))
+
+	// the target is created with the data of the backup after it completes
+	backup.Status.Conditions = []v1alpha1.BackupCondition{{Type: v1alpha1.BackupComplete, Status: corev1.ConditionTrue}}
+	g.Expect(backupIndexer.Update(backup)).To(Succeed())
+	err = control.ReconcileTidbClusterClone(tcc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tcc.Status.Phase).To(Equal(v1alpha1.ClonePhaseRestoring))
+	g.Expect(tcc.Status.TidbClusterName).To(Equal("prod"))
+	target, err := deps.Clientset.PingcapV1alpha1().TidbClusters("staging").Get("prod", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(target.Annotations[label.AnnTidbClusterClone]).To(Equal("prod/clone"))
+	g.Expect(target.Labels).To(Equal(map[string]string{"app": "prod"}))
+	g.Expect(target.Spec.PD.Replicas).To(Equal(int32(3)))
+	g.Expect(target.Spec.TiKV.Replicas).To(Equal(int32(1)))
+	g.Expect(target.Spec.TiDB.Replicas).To(Equal(int32(1)))
+	g.Expect(target.Spec.Paused).To(BeFalse())
+	g.Expect(target.Spec.Restore.RestoreSpec.S3).To(Equal(tcc.Spec.Backup.S3))
+	g.Expect(target.Spec.Restore.RestoreSpec.TableFilter).To(Equal([]string{"app.*"}))
+
+	// the masked columns are updated after the data is restored
+	target.Status.Restore = &v1alpha1.TidbClusterRestoreStatus{Phase: v1alpha1.TidbClusterRestoreRunning}
+	g.Expect(tcIndexer.Add(target)).To(Succeed())
+	err = control.ReconcileTidbClusterClone(tcc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tcc.Status.Phase).To(Equal(v1alpha1.ClonePhaseRestoring))
+
+	target.Status.Restore.Phase = v1alpha1.TidbClusterRestoreComplete
+	g.Expect(tcIndexer.Update(target)).To(Succeed())
+	err = control.ReconcileTidbClusterClone(tcc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tcc.Status.Phase).To(Equal(v1alpha1.ClonePhaseMasking))
+	g.Expect(tcc.Status.InitializerName).To(Equal("prod-mask"))
+	ti, err := deps.Clientset.PingcapV1alpha1().TidbInitializers("staging").Get(tcc.Status.InitializerName, metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ti.Spec.Image).To(Equal(deps.CLIConfig.TiDBInitializerImage))
+	g.Expect(ti.Spec.Clusters).To(Equal(v1alpha1.TidbClusterRef{Namespace: "staging", Name: "prod"}))
+	g.Expect(*ti.Spec.InitSql).To(Equal("UPDATE `app`.`orders` SET `address` = NULL;\nUPDATE `app`.`users` SET `email` = MD5(email), `phone` = NULL;"))
+
+	ti.Status.Phase = v1alpha1.InitializePhaseCompleted
+	g.Expect(tiIndexer.Add(ti)).To(Succeed())
+	g.Expect(control.ReconcileTidbClusterClone(tcc)).To(Succeed())
+	g.Expect(tcc.Status.Phase).To(Equal(v1alpha1.ClonePhaseComplete))
+	g.Expect(tcc.Status.CompletionTime).NotTo(BeNil())
+
+	updated, err := deps.Clientset.PingcapV1alpha1().TidbClusterClones(tcc.Namespace).Get(tcc.Name, metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(updated.Status.Phase).To(Equal(v1alpha1.ClonePhaseComplete))
+}
+
+func TestTidbClusterCloneControlFailed(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps, control, tcc := newFakeTidbClusterCloneControl(g)
+	tcc.Spec.Masks = []v1alpha1.ColumnMask{{Table: "users", Column: "email"}}
+	g.Expect(control.ReconcileTidbClusterClone(tcc)).To(Succeed())
+	g.Expect(tcc.Status.Phase).To(Equal(v1alpha1.ClonePhaseFailed))
+	g.Expect(tcc.Status.Message).To(ContainSubstring("users"))
+
+	deps, control, tcc = newFakeTidbClusterCloneControl(g)
+	err := control.ReconcileTidbClusterClone(tcc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	backup, err := deps.Clientset.PingcapV1alpha1().Backups(tcc.Namespace).Get(tcc.Status.BackupName, metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	backup.Status.Conditions = []v1alpha1.BackupCondition{{Type: v1alpha1.BackupFailed, Status: corev1.ConditionTrue, Message: "access denied"}}
+	g.Expect(deps.InformerFactory.Pingcap().V1alpha1().Backups().Informer().GetIndexer().Add(backup)).To(Succeed())
+	g.Expect(control.ReconcileTidbClusterClone(tcc)).To(Succeed())
+	g.Expect(tcc.Status.Phase).To(Equal(v1alpha1.ClonePhaseFailed))
+	g.Expect(tcc.Status.Message).To(ContainSubstring("access denied"))
+
+	// the failed clone is not retried
+	g.Expect(control.ReconcileTidbClusterClone(tcc)).To(Succeed())
+	g.Expect(tcc.Status.Phase).To(Equal(v1alpha1.ClonePhaseFailed))
+}
+
+func TestTidbClusterCloneControlMissingSecrets(t *testing.T) {
+	g := NewGomegaWithT(t)
+	deps, control, tcc := newFakeTidbClusterCloneControl(g)
+	tcc.Spec.Backup.S3.SecretName = "s3-secret"
+	tcc.Spec.Backup.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry"}}
+
+	err := control.ReconcileTidbClusterClone(tcc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	backup, err := deps.Clientset.PingcapV1alpha1().Backups(tcc.Namespace).Get(tcc.Status.BackupName, metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	backup.Status.Conditions = []v1alpha1.BackupCondition{{Type: v1alpha1.BackupComplete, Status: corev1.ConditionTrue}}
+	g.Expect(deps.InformerFactory.Pingcap().V1alpha1().Backups().Informer().GetIndexer().Add(backup)).To(Succeed())
+
+	// the secrets exist in the namespace of the clone only, the target is not created
+	secretIndexer := deps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer()
+	for _, name := range []string{"s3-secret", "registry"} {
+		g.Expect(secretIndexer.Add(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prod"}})).To(Succeed())
+	}
+	err = control.ReconcileTidbClusterClone(tcc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tcc.Status.Phase).To(Equal(v1alpha1.ClonePhaseBackingUp))
+	g.Expect(tcc.Status.Message).To(ContainSubstring("secrets registry, s3-secret"))
+	_, err = deps.Clientset.PingcapV1alpha1().TidbClusters("staging").Get("prod", metav1.GetOptions{})
+	g.Expect(errors.IsNotFound(err)).To(BeTrue())
+
+	g.Expect(secretIndexer.Add(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "s3-secret", Namespace: "staging"}})).To(Succeed())
+	err = control.ReconcileTidbClusterClone(tcc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tcc.Status.Message).To(ContainSubstring("secrets registry referenced"))
+
+	g.Expect(secretIndexer.Add(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: "staging"}})).To(Succeed())
+	err = control.ReconcileTidbClusterClone(tcc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tcc.Status.Phase).To(Equal(v1alpha1.ClonePhaseRestoring))
+	g.Expect(tcc.Status.Message).To(BeEmpty())
+	target, err := deps.Clientset.PingcapV1alpha1().TidbClusters("staging").Get("prod", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(target.Spec.Restore.RestoreSpec.S3.SecretName).To(Equal("s3-secret"))
+
+	// the labels of the target are not shared with the source
+	source, err := deps.TiDBClusterLister.TidbClusters("prod").Get("prod")
+	g.Expect(err).NotTo(HaveOccurred())
+	target = newTargetTidbCluster(tcc, source, backup)
+	target.Labels["app"] = "staging"
+	g.Expect(source.Labels["app"]).To(Equal("prod"))
+}
+
+func newFakeTidbClusterCloneControl(g *GomegaWithT) (*controller.Dependencies, ControlInterface, *v1alpha1.TidbClusterClone) {
+	deps := controller.NewFakeDependencies()
+
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "prod",
+			Namespace: "prod",
+			Labels:    map[string]string{"app": "prod"},
+		},
+		Spec: v1alpha1.TidbClusterSpec{
+			Paused: true,
+			PD:     &v1alpha1.PDSpec{Replicas: 3},
+			TiKV:   &v1alpha1.TiKVSpec{Replicas: 5},
+			TiDB:   &v1alpha1.TiDBSpec{Replicas: 4},
+		},
+	}
+	err := deps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer().Add(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	tcc := &v1alpha1.TidbClusterClone{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "clone",
+			Namespace: "prod",
+		},
+		Spec: v1alpha1.TidbClusterCloneSpec{
+			Source: v1alpha1.TidbClusterRef{Name: "prod"},
+			Target: v1alpha1.TidbClusterCloneTarget{Namespace: "staging"},
+			Backup: v1alpha1.BackupSpec{
+				StorageProvider: v1alpha1.StorageProvider{S3: &v1alpha1.S3StorageProvider{Bucket: "backup", Prefix: "clone"}},
+				TableFilter:     []string{"app.*"},
+			},
+		},
+	}
+	tcc, err = deps.Clientset.PingcapV1alpha1().TidbClusterClones(tcc.Namespace).Create(tcc)
+	g.Expect(err).NotTo(HaveOccurred())
+	return deps, NewDefaultTidbClusterCloneControl(deps), tcc
+}
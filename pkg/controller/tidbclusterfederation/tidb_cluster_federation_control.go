@@ -0,0 +1,344 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbclusterfederation
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog"
+)
+
+const (
+	// FederationMemberUpgradingReason is the reason of the events emitted when a member starts to be upgraded
+	FederationMemberUpgradingReason = "FederationMemberUpgrading"
+	// FederationMemberRotatingTLSCertsReason is the reason of the events emitted when the TLS certificates
+	// of a member start to be rotated
+	FederationMemberRotatingTLSCertsReason = "FederationMemberRotatingTLSCerts"
+	// FederationMemberPausedReason is the reason of the events emitted when the sync of a member is paused or resumed
+	FederationMemberPausedReason = "FederationMemberPaused"
+	// FederationMemberFailedReason is the reason of the events emitted when a member can not be synced
+	FederationMemberFailedReason = "FederationMemberFailed"
+)
+
+// ControlInterface reconciles TidbClusterFederation
+type ControlInterface interface {
+	// ReconcileTidbClusterFederation implements the reconcile logic of TidbClusterFederation
+	ReconcileTidbClusterFederation(tf *v1alpha1.TidbClusterFederation) error
+}
+
+// NewDefaultTidbClusterFederationControl returns a new instance of the default TidbClusterFederation ControlInterface
+func NewDefaultTidbClusterFederationControl(deps *controller.Dependencies) ControlInterface {
+	return &defaultTidbClusterFederationControl{
+		deps:            deps,
+		newMemberClient: controller.NewFederationMemberClient,
+	}
+}
+
+// defaultTidbClusterFederationControl applies the operations of the TidbClusterFederation to its members.
+//
+// The sync of all the members is paused or resumed at once. The version upgrades and the TLS certificates
+// rotations are applied to one member at a time in the order of `spec.members`, the next member is not
+// touched until the previous one is ready with the desired state. Upgrades take precedence over rotations.
+type defaultTidbClusterFederationControl struct {
+	deps *controller.Dependencies
+	// newMemberClient returns the client of the Kubernetes cluster the member lives in
+	newMemberClient controller.FederationMemberClientFunc
+}
+
+// federationMember is a member of the federation with its client and current TidbCluster
+type federationMember struct {
+	cli    versioned.Interface
+	tc     *v1alpha1.TidbCluster
+	status v1alpha1.TidbClusterFederationMemberStatus
+}
+
+func (c *defaultTidbClusterFederationControl) ReconcileTidbClusterFederation(tf *v1alpha1.TidbClusterFederation) error {
+	oldStatus := tf.Status.DeepCopy()
+	lastStatuses := map[string]v1alpha1.TidbClusterFederationMemberStatus{}
+	for _, status := range oldStatus.Members {
+		lastStatuses[status.Namespace+"/"+status.Name] = status
+	}
+
+	var errs []error
+	members := make([]*federationMember, 0, len(tf.Spec.Members))
+	for _, m := range tf.Spec.Members {
+		ns := controller.FederationMemberNamespace(tf.GetNamespace(), m)
+		member := &federationMember{
+			status: v1alpha1.TidbClusterFederationMemberStatus{Name: m.Name, Namespace: ns},
+		}
+		if last, ok := lastStatuses[ns+"/"+m.Name]; ok {
+			member.status.Operation = last.Operation
+			member.status.LastTransitionTime = last.LastTransitionTime
+		}
+		members = append(members, member)
+
+		cli, err := c.newMemberClient(c.deps, tf.GetNamespace(), m)
+		if err == nil {
+			member.cli = cli
+			member.tc, err = cli.PingcapV1alpha1().TidbClusters(ns).Get(m.Name, metav1.GetOptions{})
+		}
+		if err != nil {
+			member.status.Message = fmt.Sprintf("failed to get the TidbCluster: %v", err)
+			errs = append(errs, fmt.Errorf("tidbclusterfederation %s/%s: failed to get member %s/%s, error: %v", tf.GetNamespace(), tf.GetName(), ns, m.Name, err))
+		}
+	}
+
+	phase := v1alpha1.FederationPhaseNormal
+	if tf.Spec.Paused != nil {
+		errs = append(errs, c.syncPaused(tf, members, *tf.Spec.Paused)...)
+		if *tf.Spec.Paused {
+			phase = v1alpha1.FederationPhasePaused
+		}
+	}
+	if phase != v1alpha1.FederationPhasePaused {
+		upgrading, err := c.syncVersion(tf, members)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if upgrading {
+			phase = v1alpha1.FederationPhaseUpgrading
+		} else {
+			rotating, err := c.syncTLSCertRotation(tf, members)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			if rotating {
+				phase = v1alpha1.FederationPhaseRotatingTLSCerts
+			}
+		}
+	}
+
+	tf.Status.ObservedGeneration = tf.GetGeneration()
+	tf.Status.Phase = phase
+	tf.Status.Members = nil
+	for _, member := range members {
+		if member.tc != nil {
+			member.status.Version = member.tc.Spec.Version
+			member.status.Paused = member.tc.Spec.Paused
+			member.status.Ready = memberReady(member.tc)
+			member.status.TLSCertRotation = completedTLSCertRotation(member.tc)
+		}
+		if last, ok := lastStatuses[member.status.Namespace+"/"+member.status.Name]; !ok || memberStatusChanged(last, member.status) {
+			member.status.LastTransitionTime = metav1.Now()
+		}
+		tf.Status.Members = append(tf.Status.Members, member.status)
+	}
+	if !apiequality.Semantic.DeepEqual(&tf.Status, oldStatus) {
+		if _, err := c.deps.Clientset.PingcapV1alpha1().TidbClusterFederations(tf.GetNamespace()).UpdateStatus(tf); err != nil {
+			errs = append(errs, fmt.Errorf("tidbclusterfederation %s/%s: failed to update status, error: %v", tf.GetNamespace(), tf.GetName(), err))
+		}
+	}
+	if len(errs) > 0 {
+		return errorutils.NewAggregate(errs)
+	}
+	if phase == v1alpha1.FederationPhaseUpgrading || phase == v1alpha1.FederationPhaseRotatingTLSCerts {
+		// the members may live in other Kubernetes clusters, so they are polled instead of watched
+		return controller.RequeueErrorf("tidbclusterfederation %s/%s: %s", tf.GetNamespace(), tf.GetName(), phase)
+	}
+	return nil
+}
+
+// syncPaused pauses or resumes the sync of all the members at once
+func (c *defaultTidbClusterFederationControl) syncPaused(tf *v1alpha1.TidbClusterFederation, members []*federationMember, paused bool) []error {
+	var errs []error
+	for _, member := range members {
+		if member.tc == nil || member.tc.Spec.Paused == paused {
+			continue
+		}
+		tc := member.tc.DeepCopy()
+		tc.Spec.Paused = paused
+		if err := c.updateMember(tf, member, tc); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		klog.Infof("tidbclusterfederation %s/%s: set paused of member %s/%s to %t", tf.GetNamespace(), tf.GetName(), tc.GetNamespace(), tc.GetName(), paused)
+		c.deps.Recorder.Eventf(tf, corev1.EventTypeNormal, FederationMemberPausedReason, "set paused of member %s/%s to %t", tc.GetNamespace(), tc.GetName(), paused)
+	}
+	return errs
+}
+
+// syncVersion upgrades the members to the desired version one at a time, it returns whether
+// any member is not upgraded yet
+func (c *defaultTidbClusterFederationControl) syncVersion(tf *v1alpha1.TidbClusterFederation, members []*federationMember) (bool, error) {
+	version := tf.Spec.Version
+	if version == "" {
+		return false, nil
+	}
+	return c.syncOneByOne(tf, members, v1alpha1.FederationMemberOperationUpgrade,
+		func(tc *v1alpha1.TidbCluster) bool {
+			return tc.Spec.Version == version
+		},
+		func(tc *v1alpha1.TidbCluster) bool {
+			return memberReady(tc)
+		},
+		func(tc *v1alpha1.TidbCluster) {
+			tc.Spec.Version = version
+			klog.Infof("tidbclusterfederation %s/%s: upgrade member %s/%s to %s", tf.GetNamespace(), tf.GetName(), tc.GetNamespace(), tc.GetName(), version)
+			c.deps.Recorder.Eventf(tf, corev1.EventTypeNormal, FederationMemberUpgradingReason, "upgrade member %s/%s to %s", tc.GetNamespace(), tc.GetName(), version)
+		})
+}
+
+// syncTLSCertRotation rotates the TLS certificates of the members one at a time, it returns whether
+// any member is not rotated yet
+func (c *defaultTidbClusterFederationControl) syncTLSCertRotation(tf *v1alpha1.TidbClusterFederation, members []*federationMember) (bool, error) {
+	rotation := tf.Spec.TLSCertRotation
+	if rotation == "" {
+		return false, nil
+	}
+	return c.syncOneByOne(tf, members, v1alpha1.FederationMemberOperationRotateTLSCerts,
+		func(tc *v1alpha1.TidbCluster) bool {
+			// the members without certificate rotation enabled are skipped
+			return !tc.TLSCertRotationEnabled() || tc.GetAnnotations()[label.AnnTLSCertRotate] == rotation
+		},
+		func(tc *v1alpha1.TidbCluster) bool {
+			return !tc.TLSCertRotationEnabled() || (completedTLSCertRotation(tc) == rotation && memberReady(tc))
+		},
+		func(tc *v1alpha1.TidbCluster) {
+			if tc.Annotations == nil {
+				tc.Annotations = map[string]string{}
+			}
+			tc.Annotations[label.AnnTLSCertRotate] = rotation
+			klog.Infof("tidbclusterfederation %s/%s: rotate the TLS certificates of member %s/%s for %s", tf.GetNamespace(), tf.GetName(), tc.GetNamespace(), tc.GetName(), rotation)
+			c.deps.Recorder.Eventf(tf, corev1.EventTypeNormal, FederationMemberRotatingTLSCertsReason, "rotate the TLS certificates of member %s/%s for %s", tc.GetNamespace(), tc.GetName(), rotation)
+		})
+}
+
+// syncOneByOne applies the operation to the first member not applied if all the previous members are done,
+// it returns whether any member is not done yet.
+// applied returns whether the operation is applied to the TidbCluster, done returns whether the applied
+// operation is completed, and apply applies the operation to the TidbCluster.
+func (c *defaultTidbClusterFederationControl) syncOneByOne(tf *v1alpha1.TidbClusterFederation, members []*federationMember, op v1alpha1.FederationMemberOperation,
+	applied, done func(tc *v1alpha1.TidbCluster) bool, apply func(tc *v1alpha1.TidbCluster)) (bool, error) {
+	inProgress := false
+	var blocker string
+	for _, member := range members {
+		if member.tc == nil {
+			// the order can not be guaranteed without knowing the state of the member
+			inProgress = true
+			if blocker == "" {
+				blocker = fmt.Sprintf("%s/%s", member.status.Namespace, member.status.Name)
+			}
+			continue
+		}
+		tc := member.tc
+		if applied(tc) {
+			if done(tc) {
+				if member.status.Operation == op {
+					member.status.Operation = v1alpha1.FederationMemberOperationNone
+					member.status.Message = ""
+				}
+				continue
+			}
+			member.status.Operation = op
+			member.status.Message = ""
+			inProgress = true
+			if blocker == "" {
+				blocker = fmt.Sprintf("%s/%s", tc.GetNamespace(), tc.GetName())
+			}
+			continue
+		}
+
+		inProgress = true
+		if blocker != "" {
+			member.status.Operation = v1alpha1.FederationMemberOperationNone
+			member.status.Message = fmt.Sprintf("waiting for member %s to complete operation %s", blocker, op)
+			continue
+		}
+		updated := tc.DeepCopy()
+		apply(updated)
+		if err := c.updateMember(tf, member, updated); err != nil {
+			return inProgress, err
+		}
+		member.status.Operation = op
+		member.status.Message = ""
+		blocker = fmt.Sprintf("%s/%s", tc.GetNamespace(), tc.GetName())
+	}
+	return inProgress, nil
+}
+
+func (c *defaultTidbClusterFederationControl) updateMember(tf *v1alpha1.TidbClusterFederation, member *federationMember, tc *v1alpha1.TidbCluster) error {
+	updated, err := member.cli.PingcapV1alpha1().TidbClusters(tc.GetNamespace()).Update(tc)
+	if err != nil {
+		member.status.Message = fmt.Sprintf("failed to update the TidbCluster: %v", err)
+		c.deps.Recorder.Eventf(tf, corev1.EventTypeWarning, FederationMemberFailedReason, "failed to update member %s/%s: %v", tc.GetNamespace(), tc.GetName(), err)
+		return fmt.Errorf("tidbclusterfederation %s/%s: failed to update member %s/%s, error: %v", tf.GetNamespace(), tf.GetName(), tc.GetNamespace(), tc.GetName(), err)
+	}
+	member.tc = updated
+	return nil
+}
+
+// memberReady returns whether the latest spec of the TidbCluster is synced and all its components are ready
+func memberReady(tc *v1alpha1.TidbCluster) bool {
+	if tc.Status.ObservedGeneration != tc.GetGeneration() {
+		return false
+	}
+	cond := utiltidbcluster.GetTidbClusterReadyCondition(tc.Status)
+	return cond != nil && cond.Status == corev1.ConditionTrue && cond.ObservedGeneration == tc.GetGeneration()
+}
+
+// completedTLSCertRotation returns the TLS certificates rotation all the certificates of the
+// TidbCluster are re-issued and reloaded for, empty if the rotation is not completed
+func completedTLSCertRotation(tc *v1alpha1.TidbCluster) string {
+	rotation := tc.GetAnnotations()[label.AnnTLSCertRotate]
+	if rotation == "" || len(tc.Status.TLSCerts) == 0 {
+		return ""
+	}
+	for _, status := range tc.Status.TLSCerts {
+		if status.RotationID != rotation {
+			return ""
+		}
+		if status.Phase != v1alpha1.TLSCertValid && status.Phase != v1alpha1.TLSCertExpiring {
+			return ""
+		}
+	}
+	return rotation
+}
+
+func memberStatusChanged(last, cur v1alpha1.TidbClusterFederationMemberStatus) bool {
+	return last.Version != cur.Version || last.Paused != cur.Paused || last.Ready != cur.Ready ||
+		last.TLSCertRotation != cur.TLSCertRotation || last.Operation != cur.Operation || last.Message != cur.Message
+}
+
+var _ ControlInterface = &defaultTidbClusterFederationControl{}
+
+// FakeTidbClusterFederationControl is a fake TidbClusterFederation ControlInterface
+type FakeTidbClusterFederationControl struct {
+	err error
+}
+
+// NewFakeTidbClusterFederationControl returns a FakeTidbClusterFederationControl
+func NewFakeTidbClusterFederationControl() *FakeTidbClusterFederationControl {
+	return &FakeTidbClusterFederationControl{}
+}
+
+// SetReconcileTidbClusterFederationError sets error for TidbClusterFederationControl
+func (c *FakeTidbClusterFederationControl) SetReconcileTidbClusterFederationError(err error) {
+	c.err = err
+}
+
+// ReconcileTidbClusterFederation fake ReconcileTidbClusterFederation
+func (c *FakeTidbClusterFederationControl) ReconcileTidbClusterFederation(_ *v1alpha1.TidbClusterFederation) error {
+	return c.err
+}
+
+var _ ControlInterface = &FakeTidbClusterFederationControl{}
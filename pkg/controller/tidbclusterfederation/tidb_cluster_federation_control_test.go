@@ -0,0 +1,167 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbclusterfederation
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestTidbClusterFederationControlUpgrade(t *testing.T) {
+	g := NewGomegaWithT(t)
+	deps, control, tf := newFakeTidbClusterFederationControl(g, "ns1", "ns2")
+	tf.Spec.Version = "v5.0.0"
+
+	// the first member is upgraded while the second one waits
+	err := control.ReconcileTidbClusterFederation(tf)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tf.Status.Phase).To(Equal(v1alpha1.FederationPhaseUpgrading))
+	g.Expect(tf.Status.Members).To(HaveLen(2))
+	g.Expect(tf.Status.Members[0].Operation).To(Equal(v1alpha1.FederationMemberOperationUpgrade))
+	g.Expect(tf.Status.Members[0].Version).To(Equal("v5.0.0"))
+	g.Expect(tf.Status.Members[1].Operation).To(Equal(v1alpha1.FederationMemberOperationNone))
+	g.Expect(tf.Status.Members[1].Message).To(ContainSubstring("ns1/test"))
+	g.Expect(getTidbCluster(g, deps, "ns2").Spec.Version).To(Equal("v4.0.0"))
+
+	// nothing changes until the first member is ready
+	err = control.ReconcileTidbClusterFederation(tf)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(getTidbCluster(g, deps, "ns2").Spec.Version).To(Equal("v4.0.0"))
+
+	// the second member is upgraded after the first one is ready
+	setTidbClusterReady(g, deps, "ns1")
+	err = control.ReconcileTidbClusterFederation(tf)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tf.Status.Members[0].Operation).To(Equal(v1alpha1.FederationMemberOperationNone))
+	g.Expect(tf.Status.Members[0].Ready).To(BeTrue())
+	g.Expect(tf.Status.Members[1].Operation).To(Equal(v1alpha1.FederationMemberOperationUpgrade))
+	g.Expect(getTidbCluster(g, deps, "ns2").Spec.Version).To(Equal("v5.0.0"))
+
+	setTidbClusterReady(g, deps, "ns2")
+	g.Expect(control.ReconcileTidbClusterFederation(tf)).To(Succeed())
+	g.Expect(tf.Status.Phase).To(Equal(v1alpha1.FederationPhaseNormal))
+	for _, status := range tf.Status.Members {
+		g.Expect(status.Operation).To(Equal(v1alpha1.FederationMemberOperationNone))
+		g.Expect(status.Ready).To(BeTrue())
+	}
+}
+
+func TestTidbClusterFederationControlTLSCertRotation(t *testing.T) {
+	g := NewGomegaWithT(t)
+	deps, control, tf := newFakeTidbClusterFederationControl(g, "ns1", "ns2")
+	tf.Spec.TLSCertRotation = "r1"
+	for _, ns := range []string{"ns1", "ns2"} {
+		tc := getTidbCluster(g, deps, ns)
+		tc.Spec.TLSCluster = &v1alpha1.TLSCluster{Enabled: true, CertRotation: &v1alpha1.TLSCertRotation{}}
+		_, err := deps.Clientset.PingcapV1alpha1().TidbClusters(ns).Update(tc)
+		g.Expect(err).NotTo(HaveOccurred())
+		setTidbClusterReady(g, deps, ns)
+	}
+
+	err := control.ReconcileTidbClusterFederation(tf)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tf.Status.Phase).To(Equal(v1alpha1.FederationPhaseRotatingTLSCerts))
+	g.Expect(tf.Status.Members[0].Operation).To(Equal(v1alpha1.FederationMemberOperationRotateTLSCerts))
+	g.Expect(getTidbCluster(g, deps, "ns1").Annotations[label.AnnTLSCertRotate]).To(Equal("r1"))
+	g.Expect(getTidbCluster(g, deps, "ns2").Annotations).NotTo(HaveKey(label.AnnTLSCertRotate))
+
+	// the second member is rotated after all the certificates of the first one are re-issued and reloaded
+	tc := getTidbCluster(g, deps, "ns1")
+	tc.Status.TLSCerts = map[string]v1alpha1.TLSCertStatus{
+		label.PDLabelVal: {Phase: v1alpha1.TLSCertValid, RotationID: "r1"},
+	}
+	_, err = deps.Clientset.PingcapV1alpha1().TidbClusters("ns1").Update(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	err = control.ReconcileTidbClusterFederation(tf)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tf.Status.Members[0].TLSCertRotation).To(Equal("r1"))
+	g.Expect(tf.Status.Members[1].Operation).To(Equal(v1alpha1.FederationMemberOperationRotateTLSCerts))
+	g.Expect(getTidbCluster(g, deps, "ns2").Annotations[label.AnnTLSCertRotate]).To(Equal("r1"))
+}
+
+func TestTidbClusterFederationControlPause(t *testing.T) {
+	g := NewGomegaWithT(t)
+	deps, control, tf := newFakeTidbClusterFederationControl(g, "ns1", "ns2")
+	tf.Spec.Paused = pointer.BoolPtr(true)
+	tf.Spec.Version = "v5.0.0"
+
+	// all the members are paused at once and not upgraded
+	g.Expect(control.ReconcileTidbClusterFederation(tf)).To(Succeed())
+	g.Expect(tf.Status.Phase).To(Equal(v1alpha1.FederationPhasePaused))
+	for _, ns := range []string{"ns1", "ns2"} {
+		tc := getTidbCluster(g, deps, ns)
+		g.Expect(tc.Spec.Paused).To(BeTrue())
+		g.Expect(tc.Spec.Version).To(Equal("v4.0.0"))
+	}
+
+	tf.Spec.Paused = pointer.BoolPtr(false)
+	err := control.ReconcileTidbClusterFederation(tf)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tf.Status.Phase).To(Equal(v1alpha1.FederationPhaseUpgrading))
+	for _, ns := range []string{"ns1", "ns2"} {
+		g.Expect(getTidbCluster(g, deps, ns).Spec.Paused).To(BeFalse())
+	}
+}
+
+func newFakeTidbClusterFederationControl(g *GomegaWithT, namespaces ...string) (*controller.Dependencies, ControlInterface, *v1alpha1.TidbClusterFederation) {
+	deps := controller.NewFakeDependencies()
+	tf := &v1alpha1.TidbClusterFederation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: corev1.NamespaceDefault,
+		},
+	}
+	for _, ns := range namespaces {
+		tf.Spec.Members = append(tf.Spec.Members, v1alpha1.TidbClusterFederationMember{Name: "test", Namespace: ns})
+		tc := &v1alpha1.TidbCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test",
+				Namespace: ns,
+			},
+			Spec: v1alpha1.TidbClusterSpec{
+				Version: "v4.0.0",
+			},
+		}
+		_, err := deps.Clientset.PingcapV1alpha1().TidbClusters(ns).Create(tc)
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+	_, err := deps.Clientset.PingcapV1alpha1().TidbClusterFederations(tf.Namespace).Create(tf)
+	g.Expect(err).NotTo(HaveOccurred())
+	return deps, NewDefaultTidbClusterFederationControl(deps), tf
+}
+
+func getTidbCluster(g *GomegaWithT, deps *controller.Dependencies, ns string) *v1alpha1.TidbCluster {
+	tc, err := deps.Clientset.PingcapV1alpha1().TidbClusters(ns).Get("test", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	return tc
+}
+
+// setTidbClusterReady marks the TidbCluster as synced and ready as the tidbcluster controller does
+func setTidbClusterReady(g *GomegaWithT, deps *controller.Dependencies, ns string) {
+	tc := getTidbCluster(g, deps, ns)
+	tc.Generation++
+	tc.Status.ObservedGeneration = tc.Generation
+	tc.Status.Conditions = []v1alpha1.TidbClusterCondition{
+		{Type: v1alpha1.TidbClusterReady, Status: corev1.ConditionTrue, ObservedGeneration: tc.Generation},
+	}
+	_, err := deps.Clientset.PingcapV1alpha1().TidbClusters(ns).Update(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+}
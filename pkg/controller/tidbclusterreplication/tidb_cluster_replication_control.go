@@ -0,0 +1,457 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbclusterreplication
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/backup/constants"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/metrics"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+	"k8s.io/kubernetes/pkg/util/slice"
+)
+
+const (
+	// defaultMaxLag is the max lag of the checkpoint if `spec.maxLag` is not set
+	defaultMaxLag = 5 * time.Minute
+	// defaultTiDBPort is the port of TiDB of the secondary if `spec.secondary.port` is not set
+	defaultTiDBPort = 4000
+	// defaultUser is the user TiCDC logs in TiDB of the secondary as if `spec.secondary.user` is not set
+	defaultUser = "root"
+	// sinkCertPath is the path the secrets of `spec.ticdc.tlsClientSecretNames` are mounted at in TiCDC
+	sinkCertPath = "/var/lib/sink-tls"
+	// physicalShiftBits is the number of the bits of the logical part of a TSO
+	physicalShiftBits = 18
+)
+
+var changefeedIDRegexp = regexp.MustCompile(`^[a-zA-Z0-9]+(\-[a-zA-Z0-9]+)*$`)
+
+// ControlInterface reconciles TidbClusterReplication
+type ControlInterface interface {
+	// ReconcileTidbClusterReplication implements the reconcile logic of TidbClusterReplication
+	ReconcileTidbClusterReplication(tr *v1alpha1.TidbClusterReplication) error
+}
+
+// NewDefaultTidbClusterReplicationControl returns a new instance of the default TidbClusterReplication ControlInterface
+func NewDefaultTidbClusterReplicationControl(deps *controller.Dependencies) ControlInterface {
+	return &defaultTidbClusterReplicationControl{deps: deps}
+}
+
+// defaultTidbClusterReplicationControl manages the changefeed of the TidbClusterReplication in TiCDC of the primary.
+//
+// The changefeed is created with the sink to TiDB of the secondary, paused or resumed as the spec, and
+// removed when the TidbClusterReplication is deleted. The lag of its checkpoint is reported in the status
+// and by the metrics. The secondary is promoted to take over the writes if `spec.promote` is set.
+type defaultTidbClusterReplicationControl struct {
+	deps *controller.Dependencies
+}
+
+func (c *defaultTidbClusterReplicationControl) ReconcileTidbClusterReplication(tr *v1alpha1.TidbClusterReplication) error {
+	ns := tr.GetNamespace()
+	name := tr.GetName()
+	if tr.DeletionTimestamp != nil {
+		return c.cleanup(tr)
+	}
+	if !slice.ContainsString(tr.Finalizers, label.ReplicationProtectionFinalizer, nil) {
+		tr.Finalizers = append(tr.Finalizers, label.ReplicationProtectionFinalizer)
+		updated, err := c.deps.Clientset.PingcapV1alpha1().TidbClusterReplications(ns).Update(tr)
+		if err != nil {
+			return fmt.Errorf("tidbclusterreplication %s/%s: failed to add protection finalizer, error: %v", ns, name, err)
+		}
+		updated.DeepCopyInto(tr)
+	}
+
+	oldStatus := tr.Status.DeepCopy()
+	tr.Status.ObservedGeneration = tr.GetGeneration()
+	tr.Status.ChangefeedID = changefeedID(tr)
+	var syncErr error
+	if tr.Spec.Promote || tr.Status.Promotion != nil {
+		syncErr = c.syncPromotion(tr)
+	} else {
+		syncErr = c.syncChangefeed(tr)
+	}
+	if tr.Status.Phase != oldStatus.Phase {
+		tr.Status.LastTransitionTime = metav1.Now()
+	}
+	if !apiequality.Semantic.DeepEqual(&tr.Status, oldStatus) {
+		if _, err := c.deps.Clientset.PingcapV1alpha1().TidbClusterReplications(ns).UpdateStatus(tr); err != nil {
+			return fmt.Errorf("tidbclusterreplication %s/%s: failed to update status, error: %v", ns, name, err)
+		}
+	}
+	return syncErr
+}
+
+// syncChangefeed creates, updates, pauses or resumes the changefeed and updates the status with its state
+func (c *defaultTidbClusterReplicationControl) syncChangefeed(tr *v1alpha1.TidbClusterReplication) error {
+	ns := tr.GetNamespace()
+	name := tr.GetName()
+	id := tr.Status.ChangefeedID
+	if !changefeedIDRegexp.MatchString(id) {
+		tr.Status.Phase = v1alpha1.ReplicationPhaseFailed
+		tr.Status.Message = fmt.Sprintf("invalid changefeed ID %q, it must consist of letters, numbers and the hyphens between them", id)
+		return nil
+	}
+
+	tc, err := c.getPrimary(tr)
+	if err != nil {
+		tr.Status.Message = fmt.Sprintf("failed to get the primary TidbCluster: %v", err)
+		return err
+	}
+	if tc.Spec.TiCDC == nil {
+		tr.Status.Phase = v1alpha1.ReplicationPhasePending
+		tr.Status.Message = "TiCDC is not deployed in the primary TidbCluster"
+		return controller.RequeueErrorf("tidbclusterreplication %s/%s: TiCDC of tidbcluster %s/%s not found", ns, name, tc.GetNamespace(), tc.GetName())
+	}
+
+	changefeed, err := c.getChangefeed(tc, id)
+	if err != nil {
+		tr.Status.Message = fmt.Sprintf("failed to get the changefeed: %v", err)
+		return fmt.Errorf("tidbclusterreplication %s/%s: failed to get changefeed %s, error: %v", ns, name, id, err)
+	}
+	config, err := c.desiredChangefeedConfig(tr, tc)
+	if err != nil {
+		tr.Status.Message = err.Error()
+		return fmt.Errorf("tidbclusterreplication %s/%s: %v", ns, name, err)
+	}
+	if changefeed == nil {
+		tr.Status.Phase = v1alpha1.ReplicationPhasePending
+		req := &controller.CreateChangefeedRequest{
+			ID:          id,
+			SinkURI:     config.sinkURI,
+			StartTS:     config.startTS,
+			FilterRules: config.filterRules,
+		}
+		if err := c.deps.CDCControl.CreateChangefeed(tc, req); err != nil {
+			err = config.redact(err)
+			tr.Status.Message = fmt.Sprintf("failed to create the changefeed: %v", err)
+			return fmt.Errorf("tidbclusterreplication %s/%s: failed to create changefeed %s, error: %v", ns, name, id, err)
+		}
+		klog.Infof("tidbclusterreplication %s/%s: created changefeed %s in tidbcluster %s/%s", ns, name, id, tc.GetNamespace(), tc.GetName())
+		tr.Status.ConfigHash = config.hash
+		tr.Status.Message = "the changefeed is created"
+		return controller.RequeueErrorf("tidbclusterreplication %s/%s: changefeed %s is created", ns, name, id)
+	}
+
+	// the changefeed created before the hash is recorded in the status is taken as up to date
+	if tr.Status.ConfigHash == "" {
+		tr.Status.ConfigHash = config.hash
+	}
+	// the changefeed can only be updated when it is stopped, it is paused first and
+	// resumed below after the update unless `spec.paused` is set
+	if tr.Status.ConfigHash != config.hash {
+		switch changefeed.State {
+		case controller.ChangefeedStateNormal:
+			if err := c.deps.CDCControl.PauseChangefeed(tc, id); err != nil {
+				tr.Status.Message = fmt.Sprintf("failed to pause the changefeed to update it: %v", err)
+				return fmt.Errorf("tidbclusterreplication %s/%s: failed to pause changefeed %s to update it, error: %v", ns, name, id, err)
+			}
+			klog.Infof("tidbclusterreplication %s/%s: paused changefeed %s to update it", ns, name, id)
+			tr.Status.Message = "the changefeed is being paused to update"
+			return controller.RequeueErrorf("tidbclusterreplication %s/%s: changefeed %s is being paused to update", ns, name, id)
+		case controller.ChangefeedStateStopped:
+			req := &controller.UpdateChangefeedRequest{
+				SinkURI:     config.sinkURI,
+				FilterRules: config.filterRules,
+			}
+			if err := c.deps.CDCControl.UpdateChangefeed(tc, id, req); err != nil {
+				err = config.redact(err)
+				tr.Status.Message = fmt.Sprintf("failed to update the changefeed: %v", err)
+				return fmt.Errorf("tidbclusterreplication %s/%s: failed to update changefeed %s, error: %v", ns, name, id, err)
+			}
+			klog.Infof("tidbclusterreplication %s/%s: updated changefeed %s", ns, name, id)
+			tr.Status.ConfigHash = config.hash
+			tr.Status.Message = "the changefeed is updated"
+			return controller.RequeueErrorf("tidbclusterreplication %s/%s: changefeed %s is updated", ns, name, id)
+		}
+	}
+
+	if tr.Spec.Paused && changefeed.State == controller.ChangefeedStateNormal {
+		if err := c.deps.CDCControl.PauseChangefeed(tc, id); err != nil {
+			tr.Status.Message = fmt.Sprintf("failed to pause the changefeed: %v", err)
+			return fmt.Errorf("tidbclusterreplication %s/%s: failed to pause changefeed %s, error: %v", ns, name, id, err)
+		}
+		klog.Infof("tidbclusterreplication %s/%s: paused changefeed %s", ns, name, id)
+		return controller.RequeueErrorf("tidbclusterreplication %s/%s: changefeed %s is being paused", ns, name, id)
+	}
+	if !tr.Spec.Paused && changefeed.State == controller.ChangefeedStateStopped {
+		if err := c.deps.CDCControl.ResumeChangefeed(tc, id); err != nil {
+			tr.Status.Message = fmt.Sprintf("failed to resume the changefeed: %v", err)
+			return fmt.Errorf("tidbclusterreplication %s/%s: failed to resume changefeed %s, error: %v", ns, name, id, err)
+		}
+		klog.Infof("tidbclusterreplication %s/%s: resumed changefeed %s", ns, name, id)
+		return controller.RequeueErrorf("tidbclusterreplication %s/%s: changefeed %s is being resumed", ns, name, id)
+	}
+
+	updateChangefeedStatus(tr, changefeed, time.Now())
+	return nil
+}
+
+// updateChangefeedStatus updates the phase, the checkpoint and the lag of the replication with the changefeed
+func updateChangefeedStatus(tr *v1alpha1.TidbClusterReplication, changefeed *controller.ChangefeedInfo, now time.Time) {
+	tr.Status.State = changefeed.State
+	tr.Status.Message = ""
+	lag := updateCheckpoint(tr, changefeed.CheckpointTSO, now)
+
+	maxLag := defaultMaxLag
+	if tr.Spec.MaxLag != nil {
+		maxLag = tr.Spec.MaxLag.Duration
+	}
+	switch changefeed.State {
+	case controller.ChangefeedStateNormal:
+		if lag > maxLag {
+			tr.Status.Phase = v1alpha1.ReplicationPhaseLagging
+			tr.Status.Message = fmt.Sprintf("the checkpoint lags behind more than %s", maxLag)
+		} else {
+			tr.Status.Phase = v1alpha1.ReplicationPhaseReplicating
+		}
+	case controller.ChangefeedStateStopped:
+		tr.Status.Phase = v1alpha1.ReplicationPhasePaused
+	default:
+		tr.Status.Phase = v1alpha1.ReplicationPhaseFailed
+		tr.Status.Message = fmt.Sprintf("the changefeed is %s", changefeed.State)
+		if changefeed.Error != nil {
+			tr.Status.Message = fmt.Sprintf("the changefeed is %s: [%s] %s", changefeed.State, changefeed.Error.Code, changefeed.Error.Message)
+		}
+	}
+}
+
+// updateCheckpoint updates the checkpoint and its lag in the status, and returns the lag
+func updateCheckpoint(tr *v1alpha1.TidbClusterReplication, checkpointTSO uint64, now time.Time) time.Duration {
+	if checkpointTSO == 0 {
+		return 0
+	}
+	physical := int64(checkpointTSO >> physicalShiftBits)
+	checkpoint := metav1.NewTime(time.Unix(0, physical*int64(time.Millisecond)))
+	lag := now.Sub(checkpoint.Time).Truncate(time.Second)
+	tr.Status.CheckpointTS = strconv.FormatUint(checkpointTSO, 10)
+	tr.Status.CheckpointTime = &checkpoint
+	tr.Status.Lag = &metav1.Duration{Duration: lag}
+	metrics.ReplicationCheckpointLag.WithLabelValues(tr.GetNamespace(), tr.GetName()).Set(lag.Seconds())
+	return lag
+}
+
+// cleanup removes the changefeed and the protection finalizer of the deleted TidbClusterReplication
+func (c *defaultTidbClusterReplicationControl) cleanup(tr *v1alpha1.TidbClusterReplication) error {
+	ns := tr.GetNamespace()
+	name := tr.GetName()
+	if !slice.ContainsString(tr.Finalizers, label.ReplicationProtectionFinalizer, nil) {
+		return nil
+	}
+
+	id := changefeedID(tr)
+	tc, err := c.getPrimary(tr)
+	if errors.IsNotFound(err) {
+		// the changefeed is gone with TiCDC of the primary
+		klog.Infof("tidbclusterreplication %s/%s: primary tidbcluster not found, skip removing changefeed %s", ns, name, id)
+	} else if err != nil {
+		return err
+	} else if tc.Spec.TiCDC != nil {
+		changefeed, err := c.getChangefeed(tc, id)
+		if err != nil {
+			return fmt.Errorf("tidbclusterreplication %s/%s: failed to get changefeed %s, error: %v", ns, name, id, err)
+		}
+		if changefeed != nil {
+			if err := c.deps.CDCControl.RemoveChangefeed(tc, id); err != nil {
+				return fmt.Errorf("tidbclusterreplication %s/%s: failed to remove changefeed %s, error: %v", ns, name, id, err)
+			}
+			klog.Infof("tidbclusterreplication %s/%s: removed changefeed %s", ns, name, id)
+		}
+	}
+
+	metrics.ReplicationCheckpointLag.DeleteLabelValues(ns, name)
+	tr.Finalizers = slice.RemoveString(tr.Finalizers, label.ReplicationProtectionFinalizer, nil)
+	if _, err := c.deps.Clientset.PingcapV1alpha1().TidbClusterReplications(ns).Update(tr); err != nil {
+		return fmt.Errorf("tidbclusterreplication %s/%s: failed to remove protection finalizer, error: %v", ns, name, err)
+	}
+	return nil
+}
+
+func (c *defaultTidbClusterReplicationControl) getPrimary(tr *v1alpha1.TidbClusterReplication) (*v1alpha1.TidbCluster, error) {
+	ns := tr.Spec.Primary.Namespace
+	if ns == "" {
+		ns = tr.GetNamespace()
+	}
+	return c.deps.TiDBClusterLister.TidbClusters(ns).Get(tr.Spec.Primary.Name)
+}
+
+// getChangefeed returns the changefeed of the id, or nil if it does not exist
+func (c *defaultTidbClusterReplicationControl) getChangefeed(tc *v1alpha1.TidbCluster, id string) (*controller.ChangefeedInfo, error) {
+	changefeeds, err := c.deps.CDCControl.GetChangefeeds(tc)
+	if err != nil {
+		return nil, err
+	}
+	for i := range changefeeds {
+		if changefeeds[i].ID == id {
+			return &changefeeds[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// changefeedConfig is the config of the changefeed with the sink to TiDB of the secondary
+type changefeedConfig struct {
+	sinkURI     string
+	startTS     uint64
+	filterRules []string
+	// password is the password of the secondary in the sink URI, which must not be logged.
+	// TiCDC does not support referring to a secret, so the password is sent in the sink URI.
+	password string
+	// hash is the hash of the sink without the password and the filter rules, the
+	// resource version of the secret of the password is hashed instead of the password
+	hash string
+}
+
+// redact removes the password from the error, e.g. if the sink URI is in the error returned by TiCDC
+func (cfg *changefeedConfig) redact(err error) error {
+	if cfg.password == "" {
+		return err
+	}
+	msg := err.Error()
+	escaped := strings.TrimPrefix(url.UserPassword("", cfg.password).String(), ":")
+	for _, password := range []string{escaped, cfg.password} {
+		msg = strings.Replace(msg, password, "xxxxx", -1)
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+// desiredChangefeedConfig returns the config of the changefeed with the sink to TiDB of the secondary
+func (c *defaultTidbClusterReplicationControl) desiredChangefeedConfig(tr *v1alpha1.TidbClusterReplication, tc *v1alpha1.TidbCluster) (*changefeedConfig, error) {
+	secondary := tr.Spec.Secondary
+	cfg, secretVersion, err := c.secondaryMySQLConfig(tr)
+	if err != nil {
+		return nil, err
+	}
+	sink := url.URL{
+		Scheme: "mysql",
+		User:   url.User(cfg.User),
+		Host:   cfg.Addr,
+		Path:   "/",
+	}
+	if secondary.TLSClientSecretName != nil {
+		secretName := *secondary.TLSClientSecretName
+		if !slice.ContainsString(tc.Spec.TiCDC.TLSClientSecretNames, secretName, nil) {
+			return nil, fmt.Errorf("the secret %s is not in spec.ticdc.tlsClientSecretNames of the primary TidbCluster", secretName)
+		}
+		query := url.Values{}
+		query.Set("ssl-ca", fmt.Sprintf("%s/%s/ca.crt", sinkCertPath, secretName))
+		query.Set("ssl-cert", fmt.Sprintf("%s/%s/tls.crt", sinkCertPath, secretName))
+		query.Set("ssl-key", fmt.Sprintf("%s/%s/tls.key", sinkCertPath, secretName))
+		sink.RawQuery = query.Encode()
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%s\n%s", sink.String(), secretVersion, strings.Join(tr.Spec.FilterRules, "\n"))
+	config := &changefeedConfig{
+		filterRules: tr.Spec.FilterRules,
+		hash:        hex.EncodeToString(hash.Sum(nil)),
+	}
+	if secondary.SecretName != "" {
+		sink.User = url.UserPassword(cfg.User, cfg.Passwd)
+		config.password = cfg.Passwd
+	}
+	config.sinkURI = sink.String()
+	if tr.Spec.StartTS != "" {
+		startTS, err := strconv.ParseUint(tr.Spec.StartTS, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start ts %q: %v", tr.Spec.StartTS, err)
+		}
+		config.startTS = startTS
+	}
+	return config, nil
+}
+
+// secondaryMySQLConfig returns the config to connect to TiDB of the secondary without TLS,
+// and the resource version of the secret of the password if it is set
+func (c *defaultTidbClusterReplicationControl) secondaryMySQLConfig(tr *v1alpha1.TidbClusterReplication) (*mysql.Config, string, error) {
+	secondary := tr.Spec.Secondary
+	secondaryNamespace := secondary.Namespace
+	if secondaryNamespace == "" {
+		secondaryNamespace = tr.GetNamespace()
+	}
+	host := secondary.Host
+	if host == "" {
+		host = fmt.Sprintf("%s.%s.svc%s", controller.TiDBMemberName(secondary.Name), secondaryNamespace, controller.FormatClusterDomain(secondary.ClusterDomain))
+	}
+	port := secondary.Port
+	if port == 0 {
+		port = defaultTiDBPort
+	}
+
+	cfg := mysql.NewConfig()
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(host, strconv.Itoa(int(port)))
+	cfg.User = secondary.User
+	if cfg.User == "" {
+		cfg.User = defaultUser
+	}
+	if secondary.SecretName != "" {
+		secret, err := c.deps.KubeClientset.CoreV1().Secrets(tr.GetNamespace()).Get(secondary.SecretName, metav1.GetOptions{})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get the secret %s/%s: %v", tr.GetNamespace(), secondary.SecretName, err)
+		}
+		password, ok := secret.Data[constants.TidbPasswordKey]
+		if !ok {
+			return nil, "", fmt.Errorf("key %s not found in the secret %s/%s", constants.TidbPasswordKey, tr.GetNamespace(), secondary.SecretName)
+		}
+		cfg.Passwd = string(password)
+		return cfg, secret.ResourceVersion, nil
+	}
+	return cfg, "", nil
+}
+
+func changefeedID(tr *v1alpha1.TidbClusterReplication) string {
+	if tr.Spec.ChangefeedID != "" {
+		return tr.Spec.ChangefeedID
+	}
+	return tr.GetName()
+}
+
+var _ ControlInterface = &defaultTidbClusterReplicationControl{}
+
+// FakeTidbClusterReplicationControl is a fake TidbClusterReplication ControlInterface
+type FakeTidbClusterReplicationControl struct {
+	err error
+}
+
+// NewFakeTidbClusterReplicationControl returns a FakeTidbClusterReplicationControl
+func NewFakeTidbClusterReplicationControl() *FakeTidbClusterReplicationControl {
+	return &FakeTidbClusterReplicationControl{}
+}
+
+// SetReconcileTidbClusterReplicationError sets error for TidbClusterReplicationControl
+func (c *FakeTidbClusterReplicationControl) SetReconcileTidbClusterReplicationError(err error) {
+	c.err = err
+}
+
+// ReconcileTidbClusterReplication fake ReconcileTidbClusterReplication
+func (c *FakeTidbClusterReplicationControl) ReconcileTidbClusterReplication(_ *v1alpha1.TidbClusterReplication) error {
+	return c.err
+}
+
+var _ ControlInterface = &FakeTidbClusterReplicationControl{}
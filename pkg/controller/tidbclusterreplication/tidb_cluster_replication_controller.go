@@ -0,0 +1,108 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbclusterreplication
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog"
+
+	perrors "github.com/pingcap/errors"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+)
+
+// Controller syncs TidbClusterReplication
+type Controller struct {
+	deps    *controller.Dependencies
+	control ControlInterface
+	queue   workqueue.RateLimitingInterface
+}
+
+// NewController creates a tidbclusterreplication controller.
+func NewController(deps *controller.Dependencies) *Controller {
+	c := &Controller{
+		deps:    deps,
+		control: NewDefaultTidbClusterReplicationControl(deps),
+		queue: workqueue.NewNamedRateLimitingQueue(
+			controller.NewControllerRateLimiter(1*time.Second, 100*time.Second),
+			"tidbclusterreplication",
+		),
+	}
+
+	tidbClusterReplicationInformer := deps.InformerFactory.Pingcap().V1alpha1().TidbClusterReplications()
+	controller.WatchForObject(tidbClusterReplicationInformer.Informer(), c.queue)
+
+	return c
+}
+
+// Run run workers
+func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+
+	klog.Info("Starting tidbclusterreplication controller")
+	defer klog.Info("Shutting down tidbclusterreplication controller")
+
+	controller.RunWorkers(c.queue, workers, c.processNextWorkItem, stopCh)
+}
+
+// processNextWorkItem dequeues items, processes them, and marks them done.
+// It enforces that the syncHandler is never
+// invoked concurrently with the same key.
+func (c *Controller) processNextWorkItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+	startTime := time.Now()
+	err := c.sync(key.(string))
+	controller.ObserveReconcile("tidbclusterreplication", startTime, err)
+	if err != nil {
+		if perrors.Find(err, controller.IsRequeueError) != nil {
+			klog.Infof("TidbClusterReplication: %v, still need sync: %v, requeuing", key.(string), err)
+		} else {
+			utilruntime.HandleError(fmt.Errorf("TidbClusterReplication: %v, sync failed, err: %v, requeuing", key.(string), err))
+		}
+		c.queue.AddRateLimited(key)
+	} else {
+		c.queue.Forget(key)
+	}
+	return true
+}
+
+func (c *Controller) sync(key string) error {
+	startTime := time.Now()
+	defer func() {
+		klog.V(4).Infof("Finished syncing TidbClusterReplication %q (%v)", key, time.Since(startTime))
+	}()
+
+	ns, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	tr, err := c.deps.TiDBClusterReplicationLister.TidbClusterReplications(ns).Get(name)
+	if errors.IsNotFound(err) {
+		klog.Infof("TidbClusterReplication %v has been deleted", key)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return c.control.ReconcileTidbClusterReplication(tr.DeepCopy())
+}
@@ -0,0 +1,170 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tidbclusterreplication
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+// defaultPromotionTimeout is the timeout of the promotion if `spec.promotionTimeout` is not set
+const defaultPromotionTimeout = 5 * time.Minute
+
+// syncPromotion promotes the secondary to take over the writes in a DR event.
+//
+// The promotion waits for the checkpoint of the changefeed to catch up with the time it starts, removes
+// the changefeed and turns off the read-only flags of the secondary. The primary may be unavailable in
+// a DR event, so the first two steps are skipped after `spec.promotionTimeout`, and the checkpoint the
+// replication stops at is kept in the status. The steps are irreversible once the promotion begins.
+func (c *defaultTidbClusterReplicationControl) syncPromotion(tr *v1alpha1.TidbClusterReplication) error {
+	ns := tr.GetNamespace()
+	name := tr.GetName()
+	promotion := tr.Status.Promotion
+	if promotion == nil {
+		now := metav1.Now()
+		promotion = &v1alpha1.ReplicationPromotionStatus{
+			Step:      v1alpha1.PromotionStepWaitingForCheckpoint,
+			StartTime: &now,
+		}
+		tr.Status.Promotion = promotion
+		klog.Infof("tidbclusterreplication %s/%s: begin to promote secondary tidbcluster %s", ns, name, tr.Spec.Secondary.Name)
+	}
+	if promotion.Step == v1alpha1.PromotionStepComplete {
+		tr.Status.Phase = v1alpha1.ReplicationPhasePromoted
+		if !tr.Spec.Promote {
+			tr.Status.Message = "the secondary is promoted, the replication can not be resumed"
+		}
+		return nil
+	}
+
+	tr.Status.Phase = v1alpha1.ReplicationPhasePromoting
+	timeout := defaultPromotionTimeout
+	if tr.Spec.PromotionTimeout != nil {
+		timeout = tr.Spec.PromotionTimeout.Duration
+	}
+	timedOut := time.Since(promotion.StartTime.Time) > timeout
+
+	if promotion.Step == v1alpha1.PromotionStepWaitingForCheckpoint {
+		caughtUp, err := c.waitForCheckpoint(tr)
+		if !caughtUp && !timedOut {
+			tr.Status.Message = fmt.Sprintf("waiting for the checkpoint to catch up with %s", promotion.StartTime.Format(time.RFC3339))
+			if err != nil {
+				tr.Status.Message = fmt.Sprintf("failed to get the checkpoint: %v", err)
+			}
+			return controller.RequeueErrorf("tidbclusterreplication %s/%s: waiting for the checkpoint of changefeed %s to catch up", ns, name, tr.Status.ChangefeedID)
+		}
+		if !caughtUp {
+			klog.Warningf("tidbclusterreplication %s/%s: the checkpoint does not catch up in %s, the changes after checkpoint %q may be lost, error: %v",
+				ns, name, timeout, tr.Status.CheckpointTS, err)
+		}
+		promotion.CaughtUp = caughtUp
+		promotion.CheckpointTS = tr.Status.CheckpointTS
+		promotion.Step = v1alpha1.PromotionStepStoppingReplication
+	}
+
+	if promotion.Step == v1alpha1.PromotionStepStoppingReplication {
+		if err := c.stopReplication(tr); err != nil {
+			if !timedOut {
+				tr.Status.Message = fmt.Sprintf("failed to remove the changefeed: %v", err)
+				return controller.RequeueErrorf("tidbclusterreplication %s/%s: failed to remove changefeed %s, error: %v", ns, name, tr.Status.ChangefeedID, err)
+			}
+			klog.Warningf("tidbclusterreplication %s/%s: failed to remove changefeed %s in %s, skip it, error: %v", ns, name, tr.Status.ChangefeedID, timeout, err)
+		}
+		promotion.Step = v1alpha1.PromotionStepDisablingReadOnly
+	}
+
+	if err := c.disableSecondaryReadOnly(tr); err != nil {
+		tr.Status.Message = fmt.Sprintf("failed to turn off the read-only flags of the secondary: %v", err)
+		return fmt.Errorf("tidbclusterreplication %s/%s: failed to turn off the read-only flags of the secondary, error: %v", ns, name, err)
+	}
+	now := metav1.Now()
+	promotion.Step = v1alpha1.PromotionStepComplete
+	promotion.CompletionTime = &now
+	tr.Status.Phase = v1alpha1.ReplicationPhasePromoted
+	tr.Status.Message = ""
+	klog.Infof("tidbclusterreplication %s/%s: secondary tidbcluster %s is promoted at checkpoint %q", ns, name, tr.Spec.Secondary.Name, promotion.CheckpointTS)
+	return nil
+}
+
+// waitForCheckpoint updates the checkpoint in the status and returns true if it catches up with
+// the start time of the promotion. The changefeed is resumed if it is paused.
+func (c *defaultTidbClusterReplicationControl) waitForCheckpoint(tr *v1alpha1.TidbClusterReplication) (bool, error) {
+	id := tr.Status.ChangefeedID
+	tc, err := c.getPrimary(tr)
+	if err != nil {
+		return false, err
+	}
+	if tc.Spec.TiCDC == nil {
+		return false, fmt.Errorf("TiCDC is not deployed in the primary TidbCluster")
+	}
+	changefeed, err := c.getChangefeed(tc, id)
+	if err != nil {
+		return false, err
+	}
+	if changefeed == nil {
+		return false, fmt.Errorf("changefeed %s not found", id)
+	}
+	tr.Status.State = changefeed.State
+	updateCheckpoint(tr, changefeed.CheckpointTSO, time.Now())
+	if changefeed.State == controller.ChangefeedStateStopped {
+		// the checkpoint does not advance until the changefeed is resumed
+		return false, c.deps.CDCControl.ResumeChangefeed(tc, id)
+	}
+	checkpoint := tr.Status.CheckpointTime
+	return checkpoint != nil && !checkpoint.Before(tr.Status.Promotion.StartTime), nil
+}
+
+// stopReplication removes the changefeed in the primary if it exists
+func (c *defaultTidbClusterReplicationControl) stopReplication(tr *v1alpha1.TidbClusterReplication) error {
+	id := tr.Status.ChangefeedID
+	tc, err := c.getPrimary(tr)
+	if err != nil {
+		return err
+	}
+	if tc.Spec.TiCDC == nil {
+		return nil
+	}
+	changefeed, err := c.getChangefeed(tc, id)
+	if err != nil || changefeed == nil {
+		return err
+	}
+	if err := c.deps.CDCControl.RemoveChangefeed(tc, id); err != nil {
+		return err
+	}
+	klog.Infof("tidbclusterreplication %s/%s: removed changefeed %s for the promotion", tr.GetNamespace(), tr.GetName(), id)
+	return nil
+}
+
+// disableSecondaryReadOnly turns off the read-only flags of the secondary with the user the changefeed logs in as
+func (c *defaultTidbClusterReplicationControl) disableSecondaryReadOnly(tr *v1alpha1.TidbClusterReplication) error {
+	cfg, _, err := c.secondaryMySQLConfig(tr)
+	if err != nil {
+		return err
+	}
+	// the client certificate is the one mounted in TiCDC of the primary
+	primaryNamespace := tr.Spec.Primary.Namespace
+	if primaryNamespace == "" {
+		primaryNamespace = tr.GetNamespace()
+	}
+	var tlsSecretName string
+	if tr.Spec.Secondary.TLSClientSecretName != nil {
+		tlsSecretName = *tr.Spec.Secondary.TLSClientSecretName
+	}
+	return c.deps.TiDBControl.SetReadOnly(primaryNamespace, cfg, tlsSecretName, false)
+}
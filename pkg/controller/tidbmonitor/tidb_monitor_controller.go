@@ -23,7 +23,6 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
@@ -49,7 +48,7 @@ func NewController(deps *controller.Dependencies) *Controller {
 	}
 
 	tidbMonitorInformer := deps.InformerFactory.Pingcap().V1alpha1().TidbMonitors()
-	statefulsetInformer := deps.KubeInformerFactory.Apps().V1().StatefulSets()
+	statefulsetInformer := deps.LabelFilterKubeInformerFactory.Apps().V1().StatefulSets()
 	controller.WatchForObject(tidbMonitorInformer.Informer(), c.queue)
 	controller.WatchForController(statefulsetInformer.Informer(), c.queue, func(ns, name string) (runtime.Object, error) {
 		return c.deps.TiDBMonitorLister.TidbMonitors(ns).Get(name)
@@ -60,21 +59,11 @@ func NewController(deps *controller.Dependencies) *Controller {
 
 func (c *Controller) Run(workers int, stopCh <-chan struct{}) {
 	defer utilruntime.HandleCrash()
-	defer c.queue.ShutDown()
 
 	klog.Info("Starting tidbmonitor controller")
 	defer klog.Info("Shutting down tidbmonitor controller")
 
-	for i := 0; i < workers; i++ {
-		go wait.Until(c.worker, time.Second, stopCh)
-	}
-
-	<-stopCh
-}
-
-func (c *Controller) worker() {
-	for c.processNextWorkItem() {
-	}
+	controller.RunWorkers(c.queue, workers, c.processNextWorkItem, stopCh)
 }
 
 // processNextWorkItem dequeues items, processes them, and marks them done. It enforces that the syncHandler is never
@@ -85,7 +74,10 @@ func (c *Controller) processNextWorkItem() bool {
 		return false
 	}
 	defer c.queue.Done(key)
-	if err := c.sync(key.(string)); err != nil {
+	startTime := time.Now()
+	err := c.sync(key.(string))
+	controller.ObserveReconcile("tidbmonitor", startTime, err)
+	if err != nil {
 		if perrors.Find(err, controller.IsRequeueError) != nil {
 			klog.Infof("TidbMonitor: %v, still need sync: %v, requeuing", key.(string), err)
 		} else {
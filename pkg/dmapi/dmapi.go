@@ -14,6 +14,7 @@
 package dmapi
 
 import (
+	"bytes"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
@@ -36,11 +37,14 @@ type MasterClient interface {
 	EvictLeader() error
 	DeleteMaster(name string) error
 	DeleteWorker(name string) error
+	// TransferSource binds the source to the worker, which requires the OpenAPI of dm-master
+	TransferSource(source, worker string) error
 }
 
 var (
 	membersPrefix = "apis/v1alpha1/members"
 	leaderPrefix  = "apis/v1alpha1/leader"
+	sourcesPrefix = "api/v1/sources"
 )
 
 type RespHeader struct {
@@ -63,6 +67,10 @@ type WorkersInfo struct {
 	Source string `json:"source,omitempty"`
 }
 
+type TransferSourceReq struct {
+	WorkerName string `json:"worker_name"`
+}
+
 type MembersMaster struct {
 	Msg     string         `json:"msg,omitempty"`
 	Masters []*MastersInfo `json:"masters,omitempty"`
@@ -225,6 +233,18 @@ func (c *masterClient) DeleteWorker(name string) error {
 	return c.deleteMember(query)
 }
 
+func (c *masterClient) TransferSource(source, worker string) error {
+	apiURL := fmt.Sprintf("%s/%s/%s/transfer", c.url, sourcesPrefix, source)
+	data, err := json.Marshal(&TransferSourceReq{WorkerName: worker})
+	if err != nil {
+		return err
+	}
+	if _, err := httputil.PostBodyOK(c.httpClient, apiURL, bytes.NewBuffer(data)); err != nil {
+		return fmt.Errorf("unable to transfer source %s to worker %s, err: %s", source, worker, err)
+	}
+	return nil
+}
+
 // NewMasterClient returns a new MasterClient
 func NewMasterClient(url string, timeout time.Duration, tlsConfig *tls.Config, disableKeepalive bool) MasterClient {
 	return &masterClient{
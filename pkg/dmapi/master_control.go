@@ -36,12 +36,13 @@ type MasterControlInterface interface {
 type defaultMasterControl struct {
 	mutex         sync.Mutex
 	kubeCli       kubernetes.Interface
+	tlsConfigs    *pdapi.TLSConfigCache
 	masterClients map[string]MasterClient
 }
 
-// NewDefaultMasterControl returns a defaultMasterControl instance
-func NewDefaultMasterControl(kubeCli kubernetes.Interface) MasterControlInterface {
-	return &defaultMasterControl{kubeCli: kubeCli, masterClients: map[string]MasterClient{}}
+// NewDefaultMasterControl returns a defaultMasterControl instance, which loads the client tls.Configs from tlsConfigs
+func NewDefaultMasterControl(kubeCli kubernetes.Interface, tlsConfigs *pdapi.TLSConfigCache) MasterControlInterface {
+	return &defaultMasterControl{kubeCli: kubeCli, tlsConfigs: tlsConfigs, masterClients: map[string]MasterClient{}}
 }
 
 // GetMasterClient provides a MasterClient of real dm-master cluster, if the MasterClient not existing, it will create new one.
@@ -55,7 +56,7 @@ func (mc *defaultMasterControl) GetMasterClient(namespace string, dcName string,
 
 	if tlsEnabled {
 		scheme = "https"
-		tlsConfig, err = pdapi.GetTLSConfig(mc.kubeCli, pdapi.Namespace(namespace), dcName, util.DMClientTLSSecretName(dcName))
+		tlsConfig, err = mc.tlsConfigs.GetTLSConfig(pdapi.Namespace(namespace), util.DMClientTLSSecretName(dcName))
 		if err != nil {
 			klog.Errorf("Unable to get tls config for dm cluster %q, master client may not work: %v", dcName, err)
 			return NewMasterClient(MasterClientURL(namespace, dcName, scheme), DefaultTimeout, tlsConfig, true)
@@ -81,7 +82,7 @@ func (mc *defaultMasterControl) GetMasterPeerClient(namespace string, dcName str
 
 	if tlsEnabled {
 		scheme = "https"
-		tlsConfig, err = pdapi.GetTLSConfig(mc.kubeCli, pdapi.Namespace(namespace), dcName, util.DMClientTLSSecretName(dcName))
+		tlsConfig, err = mc.tlsConfigs.GetTLSConfig(pdapi.Namespace(namespace), util.DMClientTLSSecretName(dcName))
 		if err != nil {
 			klog.Errorf("Unable to get tls config for dm cluster %q, master client may not work: %v", dcName, err)
 			return NewMasterClient(MasterPeerClientURL(namespace, dcName, podName, scheme), DefaultTimeout, tlsConfig, true)
@@ -120,7 +121,7 @@ type FakeMasterControl struct {
 
 func NewFakeMasterControl(kubeCli kubernetes.Interface) *FakeMasterControl {
 	return &FakeMasterControl{
-		defaultMasterControl: defaultMasterControl{kubeCli: kubeCli, masterClients: map[string]MasterClient{}},
+		defaultMasterControl: defaultMasterControl{kubeCli: kubeCli, tlsConfigs: pdapi.NewTLSConfigCache(kubeCli), masterClients: map[string]MasterClient{}},
 		masterPeerClients:    map[string]MasterClient{},
 	}
 }
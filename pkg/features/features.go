@@ -26,11 +26,13 @@ import (
 )
 
 var (
-	allFeatures     = sets.NewString(StableScheduling)
+	allFeatures     = sets.NewString(StableScheduling, CapacityScheduling)
 	defaultFeatures = map[string]bool{
 		StableScheduling:    true,
 		AdvancedStatefulSet: false,
 		AutoScaling:         false,
+		CapacityScheduling:  false,
+		ServerSideApply:     false,
 	}
 	// DefaultFeatureGate is a shared global FeatureGate.
 	DefaultFeatureGate FeatureGate = NewDefaultFeatureGate()
@@ -45,6 +47,13 @@ const (
 
 	// AutoScaling controls whether to use TidbClusterAutoScaler to auto scale-in/out pods
 	AutoScaling string = "AutoScaling"
+
+	// CapacityScheduling controls whether tidb-scheduler prioritizes the nodes by the TiKV data they hold
+	CapacityScheduling string = "CapacityScheduling"
+
+	// ServerSideApply controls whether to write the StatefulSets and Services of the components with
+	// server-side apply, so the fields managed by others are kept, it requires Kubernetes v1.16+
+	ServerSideApply string = "ServerSideApply"
 )
 
 type FeatureGate interface {
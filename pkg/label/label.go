@@ -63,6 +63,8 @@ const (
 
 	// BackupProtectionFinalizer is the name of finalizer on backups
 	BackupProtectionFinalizer string = "tidb.pingcap.com/backup-protection"
+	// ReplicationProtectionFinalizer is the name of finalizer on tidbclusterreplications
+	ReplicationProtectionFinalizer string = "tidb.pingcap.com/replication-protection"
 
 	// AutoScalingGroupLabelKey describes the autoscaling group of the TiDB
 	AutoScalingGroupLabelKey = "tidb.pingcap.com/autoscaling-group"
@@ -75,6 +77,22 @@ const (
 
 	// AnnHATopologyKey defines the High availability topology key
 	AnnHATopologyKey = "pingcap.com/ha-topology-key"
+	// AnnHADomainTopologyKeys defines the comma separated node label keys of the failure domains (e.g. region, zone)
+	// which PD and TiKV pods are spread across before being spread across the AnnHATopologyKey topologies
+	AnnHADomainTopologyKeys = "pingcap.com/ha-domain-topology-keys"
+	// AnnPDHADomainTopologyKeys overrides AnnHADomainTopologyKeys for PD
+	AnnPDHADomainTopologyKeys = "pingcap.com/pd.ha-domain-topology-keys"
+	// AnnTiKVHADomainTopologyKeys overrides AnnHADomainTopologyKeys for TiKV
+	AnnTiKVHADomainTopologyKeys = "pingcap.com/tikv.ha-domain-topology-keys"
+	// AnnPDHAPolicy defines whether HA spreading of PD pods is required or preferred
+	AnnPDHAPolicy = "pingcap.com/pd.ha-policy"
+	// AnnTiKVHAPolicy defines whether HA spreading of TiKV pods is required or preferred
+	AnnTiKVHAPolicy = "pingcap.com/tikv.ha-policy"
+
+	// HAPolicyRequired means the pod stays Pending if HA spreading can not be satisfied, it's the default HA policy
+	HAPolicyRequired = "required"
+	// HAPolicyPreferred means the pod is scheduled anyway with a warning event if HA spreading can not be satisfied
+	HAPolicyPreferred = "preferred"
 
 	// AnnFailTiDBScheduler is for injecting a failure into the TiDB custom scheduler
 	// A pod with this annotation will produce an error when scheduled.
@@ -83,6 +101,57 @@ const (
 	AnnPodNameKey string = "tidb.pingcap.com/pod-name"
 	// AnnPVCDeferDeleting is pvc defer deletion annotation key used in PVC for defer deleting PVC
 	AnnPVCDeferDeleting = "tidb.pingcap.com/pvc-defer-deleting"
+	// AnnPlacementRebalance is tc annotation key to enable the placement rebalancer, the value is "true" or "false"
+	AnnPlacementRebalance = "tidb.pingcap.com/placement-rebalance"
+	// AnnPlacementRebalanceBeginTime is pod annotation key to indicate the begin time of migrating the pod
+	AnnPlacementRebalanceBeginTime = "tidb.pingcap.com/placement-rebalance-begin-time"
+	// AnnPlacementRebalanceMovedAt is pvc annotation key to indicate the last time the pod of the pvc is migrated
+	AnnPlacementRebalanceMovedAt = "tidb.pingcap.com/placement-rebalance-moved-at"
+	// AnnPlacementRebalanceEvictingStore is pvc annotation key to indicate the store whose leaders are evicted
+	// for the migration, the evict leader scheduler is removed once the store is up again
+	AnnPlacementRebalanceEvictingStore = "tidb.pingcap.com/placement-rebalance-evicting-store"
+	// AnnStorageClassMigration is tc annotation key to enable migrating the PD and TiKV volumes to the
+	// storage classes in the spec, the value is "true" or "false"
+	AnnStorageClassMigration = "tidb.pingcap.com/storage-class-migration"
+	// AnnAdoption is tc annotation key to adopt the existing cluster at `spec.pdAddresses`, e.g. deployed by TiUP,
+	// the components are created in Kubernetes one at a time, the value is "true" or "false"
+	AnnAdoption = "tidb.pingcap.com/adopt"
+	// AnnDryRun is tc annotation key to preview the changes of the StatefulSets, ConfigMaps and Services
+	// in the status when the cluster is paused, the value is "true" or "false"
+	AnnDryRun = "tidb.pingcap.com/dry-run"
+	// AnnPodRestartBeginTime is pod annotation key to indicate the begin time of restarting the pod requested by the restart ordinals annotations
+	AnnPodRestartBeginTime = "tidb.pingcap.com/restart-begin-time"
+	// AnnRestartEvictingStore is pvc annotation key to indicate the store whose leaders are evicted
+	// for the restart, the evict leader scheduler is removed once the store is up again
+	AnnRestartEvictingStore = "tidb.pingcap.com/restart-evicting-store"
+	// AnnNodeMaintenance is node annotation key to indicate the node is going to be drained, the value is "true" or "false"
+	AnnNodeMaintenance = "tidb.pingcap.com/node-maintenance"
+	// AnnNodeMaintenanceEvictingStore is pvc annotation key to indicate the store whose leaders are evicted
+	// for the node maintenance, the evict leader scheduler is removed once the store is up on an available node
+	AnnNodeMaintenanceEvictingStore = "tidb.pingcap.com/node-maintenance-evicting-store"
+	// AnnTLSCertHash is pod annotation key of the hash of the rotated certificate the pod is restarted to reload
+	AnnTLSCertHash = "tidb.pingcap.com/tls-cert-hash"
+	// AnnTLSCertRotate is tidbcluster annotation key to force re-issuing the certificates in the cluster secrets,
+	// they are re-issued once for each new value
+	AnnTLSCertRotate = "tidb.pingcap.com/tls-cert-rotate"
+	// AnnFederatedBackup is backup annotation key of the federated backup `<namespace>/<name>` which
+	// the backup of a member is created for
+	AnnFederatedBackup = "tidb.pingcap.com/federated-backup"
+	// AnnTLSCABundleHash is pod annotation key of the hash of the CA bundle the pod is restarted to reload
+	AnnTLSCABundleHash = "tidb.pingcap.com/tls-ca-bundle-hash"
+	// AnnTiKVEncryptionMasterKeyHash is pod annotation key of the hash of the master key of the TiKV encryption,
+	// the pods are restarted to re-encrypt the data keys once the master key is rotated
+	AnnTiKVEncryptionMasterKeyHash = "tidb.pingcap.com/tikv-encryption-master-key-hash"
+	// AnnTiDBClientTLSFrom is namespace annotation key of the comma separated <namespace>/<tidbcluster> list whose
+	// TiDB client TLS secrets are copied into the namespace
+	AnnTiDBClientTLSFrom = "tidb.pingcap.com/tidb-client-tls-from"
+	// AnnTiDBClientTLSSource is secret annotation key of the <namespace>/<name> of the TiDB client TLS secret it is copied from
+	AnnTiDBClientTLSSource = "tidb.pingcap.com/tidb-client-tls-source"
+	// AnnClusterClientTLSSource is secret annotation key of the <namespace>/<name> of the cluster client TLS secret
+	// of the TidbCluster referred by `spec.cluster` it is replicated from
+	AnnClusterClientTLSSource = "tidb.pingcap.com/cluster-client-tls-source"
+	// AnnTidbClusterClone is tc annotation key of the <namespace>/<name> of the TidbClusterClone which creates it
+	AnnTidbClusterClone = "tidb.pingcap.com/tidbcluster-clone"
 	// AnnPVCPodScheduling is pod scheduling annotation key, it represents whether the pod is scheduling
 	AnnPVCPodScheduling = "tidb.pingcap.com/pod-scheduling"
 	// AnnTiDBPartition is pod annotation which TiDB pod should upgrade to
@@ -100,8 +169,13 @@ const (
 	// AnnStsLastSyncTimestamp is sts annotation key to indicate the last timestamp the operator sync the sts
 	AnnStsLastSyncTimestamp = "tidb.pingcap.com/sync-timestamp"
 
+	// AnnForceDeletePod is pod annotation key to indicate the pod can be deleted even if it is protected by the admission webhook
+	AnnForceDeletePod = "tidb.pingcap.com/force-delete"
+
 	// AnnForceUpgradeVal is tc annotation value to indicate whether force upgrade should be done
 	AnnForceUpgradeVal = "true"
+	// AnnForceDeletePodVal is pod annotation value to indicate the pod can be deleted even if it is protected by the admission webhook
+	AnnForceDeletePodVal = "true"
 	// AnnSysctlInitVal is pod annotation value to indicate whether configuring sysctls with init container
 	AnnSysctlInitVal = "true"
 
@@ -118,6 +192,15 @@ const (
 	// AnnDMWorkerDeleteSlots is annotation key of dm-worker delete slots.
 	AnnDMWorkerDeleteSlots = "dm-worker.tidb.pingcap.com/delete-slots"
 
+	// AnnPDRestartOrdinals is annotation key of the ordinals of the pd pods to restart gracefully, e.g. "[1,2]".
+	AnnPDRestartOrdinals = "pd.tidb.pingcap.com/restart-ordinals"
+	// AnnTiDBRestartOrdinals is annotation key of the ordinals of the tidb pods to restart gracefully.
+	AnnTiDBRestartOrdinals = "tidb.tidb.pingcap.com/restart-ordinals"
+	// AnnTiKVRestartOrdinals is annotation key of the ordinals of the tikv pods to restart gracefully.
+	AnnTiKVRestartOrdinals = "tikv.tidb.pingcap.com/restart-ordinals"
+	// AnnTiFlashRestartOrdinals is annotation key of the ordinals of the tiflash pods to restart gracefully.
+	AnnTiFlashRestartOrdinals = "tiflash.tidb.pingcap.com/restart-ordinals"
+
 	// AnnTiKVAutoScalingOutOrdinals describe the tikv pods' ordinal list which is created by auto-scaling out
 	AnnTiKVAutoScalingOutOrdinals = "tikv.tidb.pingcap.com/scale-out-ordinals"
 	// AnnTiDBAutoScalingOutOrdinals describe the tidb pods' ordinal list which is created by auto-scaling out
@@ -142,6 +225,11 @@ const (
 	DiscoveryLabelVal string = "discovery"
 	// TiDBMonitorVal is Monitor label value
 	TiDBMonitorVal string = "monitor"
+	// TiDBClientTLSLabelVal is the component label value of the TiDB client TLS secrets copied into other namespaces
+	TiDBClientTLSLabelVal string = "tidb-client-tls"
+	// ClusterClientTLSLabelVal is the component label value of the cluster client TLS secrets replicated from the
+	// TidbClusters referred by `spec.cluster`
+	ClusterClientTLSLabelVal string = "cluster-client-tls"
 
 	// CleanJobLabelVal is clean job label value
 	CleanJobLabelVal string = "clean"
@@ -153,6 +241,8 @@ const (
 	BackupScheduleJobLabelVal string = "backup-schedule"
 	// InitJobLabelVal is TiDB initializer job label value
 	InitJobLabelVal string = "initializer"
+	// PostUpgradeHookLabelVal is the label value of the jobs of the post-upgrade hook
+	PostUpgradeHookLabelVal string = "post-upgrade-hook"
 	// TiDBOperator is ManagedByLabelKey label value
 	TiDBOperator string = "tidb-operator"
 
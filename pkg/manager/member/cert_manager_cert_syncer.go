@@ -0,0 +1,302 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	certManagerGroup             = "cert-manager.io"
+	certManagerDefaultIssuerKind = "Issuer"
+	// tlsCertCommonName is the common name of the cluster certificates, the same as the documents
+	tlsCertCommonName = "TiDB"
+	// certificateNotOwnedReason is recorded when a Certificate of the cluster secrets exists but is
+	// not controlled by the TidbCluster, e.g. it is created by the tidb-cluster chart
+	certificateNotOwnedReason = "CertificateNotOwned"
+)
+
+// CertificateGVK is the GroupVersionKind of the cert-manager Certificate
+var CertificateGVK = schema.GroupVersionKind{Group: certManagerGroup, Version: "v1", Kind: "Certificate"}
+
+// clusterTLSMemberTypes are the components with the cluster secrets
+var clusterTLSMemberTypes = []v1alpha1.MemberType{
+	v1alpha1.PDMemberType,
+	v1alpha1.TiKVMemberType,
+	v1alpha1.TiFlashMemberType,
+	v1alpha1.PumpMemberType,
+	v1alpha1.TiDBMemberType,
+	v1alpha1.TiCDCMemberType,
+}
+
+// certManagerCertSyncer generates the cert-manager Certificates of the cluster secrets of the
+// TidbCluster when `spec.tlsCluster.certManager` is set, one for each component and one for
+// the client used by the operator.
+//
+// The SANs of the certificates cover the services and the pods of the components, including the
+// names with the cluster domain, and the Certificates are updated when the spec changes. The
+// Certificates of the components removed from the spec are deleted. The Certificates with the same
+// names not controlled by the TidbCluster, e.g. created by the tidb-cluster chart, are left untouched.
+//
+// The Certificates are handled as unstructured objects, so that cert-manager is not a dependency.
+type certManagerCertSyncer struct {
+	deps *controller.Dependencies
+	// notControlled are the UIDs of the Certificates not controlled by the TidbClusters by namespace/name,
+	// the warning event is recorded once for each of them instead of on every sync
+	notControlled sync.Map
+}
+
+// NewCertManagerCertSyncer returns a syncer of the cert-manager Certificates of the cluster secrets
+func NewCertManagerCertSyncer(deps *controller.Dependencies) manager.Manager {
+	return &certManagerCertSyncer{
+		deps: deps,
+	}
+}
+
+func (s *certManagerCertSyncer) Sync(tc *v1alpha1.TidbCluster) error {
+	if !tc.CertManagerEnabled() {
+		return nil
+	}
+
+	var errs []error
+	for _, memberType := range clusterTLSMemberTypes {
+		name := util.ClusterTLSSecretName(tc.GetName(), memberType.String())
+		dnsNames := clusterTLSCertDNSNames(tc, memberType)
+		if dnsNames == nil {
+			if err := s.deleteCertificate(tc, name); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		cert := newCertManagerCertificate(tc, memberType.String(), name, dnsNames, []string{"server auth", "client auth"})
+		if err := s.syncCertificate(tc, cert); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	clientCert := newCertManagerCertificate(tc, tlsCertClientComponent, util.ClusterClientTLSSecretName(tc.GetName()), nil, []string{"client auth"})
+	if err := s.syncCertificate(tc, clientCert); err != nil {
+		errs = append(errs, err)
+	}
+	return errorutils.NewAggregate(errs)
+}
+
+// syncCertificate creates the Certificate or updates its spec if it is changed
+func (s *certManagerCertSyncer) syncCertificate(tc *v1alpha1.TidbCluster, desired *unstructured.Unstructured) error {
+	ns := tc.GetNamespace()
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(CertificateGVK)
+	err := s.deps.GenericClient.Get(context.TODO(), client.ObjectKey{Namespace: ns, Name: desired.GetName()}, existing)
+	if errors.IsNotFound(err) {
+		if err := s.deps.GenericClient.Create(context.TODO(), desired); err != nil {
+			return fmt.Errorf("certManagerCertSyncer: failed to create Certificate %s/%s, error: %v", ns, desired.GetName(), err)
+		}
+		klog.Infof("certManagerCertSyncer: Certificate %s/%s is created for tc %s", ns, desired.GetName(), tc.GetName())
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("certManagerCertSyncer: failed to get Certificate %s/%s, error: %v", ns, desired.GetName(), err)
+	}
+	key := fmt.Sprintf("%s/%s", ns, desired.GetName())
+	if !metav1.IsControlledBy(existing, tc) {
+		// do not block the sync of the components, the Certificate is managed by its owner
+		klog.V(4).Infof("certManagerCertSyncer: Certificate %s/%s is not controlled by tc %s, skip syncing it", ns, desired.GetName(), tc.GetName())
+		if uid, ok := s.notControlled.Load(key); !ok || uid != existing.GetUID() {
+			s.notControlled.Store(key, existing.GetUID())
+			s.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, certificateNotOwnedReason, "Certificate %s already exists and is not controlled by the TidbCluster, skip syncing it", desired.GetName())
+		}
+		return nil
+	}
+	s.notControlled.Delete(key)
+
+	if apiequality.Semantic.DeepEqual(existing.Object["spec"], desired.Object["spec"]) {
+		return nil
+	}
+	updated := existing.DeepCopy()
+	updated.Object["spec"] = desired.Object["spec"]
+	if err := s.deps.GenericClient.Update(context.TODO(), updated); err != nil {
+		return fmt.Errorf("certManagerCertSyncer: failed to update Certificate %s/%s, error: %v", ns, desired.GetName(), err)
+	}
+	klog.Infof("certManagerCertSyncer: Certificate %s/%s is updated for tc %s", ns, desired.GetName(), tc.GetName())
+	return nil
+}
+
+// deleteCertificate deletes the Certificate controlled by the TidbCluster if it exists
+func (s *certManagerCertSyncer) deleteCertificate(tc *v1alpha1.TidbCluster, name string) error {
+	ns := tc.GetNamespace()
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(CertificateGVK)
+	err := s.deps.GenericClient.Get(context.TODO(), client.ObjectKey{Namespace: ns, Name: name}, existing)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("certManagerCertSyncer: failed to get Certificate %s/%s, error: %v", ns, name, err)
+	}
+	if !metav1.IsControlledBy(existing, tc) {
+		return nil
+	}
+	if err := s.deps.GenericClient.Delete(context.TODO(), existing); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("certManagerCertSyncer: failed to delete Certificate %s/%s, error: %v", ns, name, err)
+	}
+	klog.Infof("certManagerCertSyncer: Certificate %s/%s is deleted as the component is removed from tc %s", ns, name, tc.GetName())
+	return nil
+}
+
+// newCertManagerCertificate returns the Certificate of the secret, the certificate is issued by the issuer in the spec
+func newCertManagerCertificate(tc *v1alpha1.TidbCluster, component, name string, dnsNames, usages []string) *unstructured.Unstructured {
+	certManager := tc.Spec.TLSCluster.CertManager
+	issuerRef := map[string]interface{}{
+		"name":  certManager.IssuerRef.Name,
+		"kind":  certManager.IssuerRef.Kind,
+		"group": certManager.IssuerRef.Group,
+	}
+	if certManager.IssuerRef.Kind == "" {
+		issuerRef["kind"] = certManagerDefaultIssuerKind
+	}
+	if certManager.IssuerRef.Group == "" {
+		issuerRef["group"] = certManagerGroup
+	}
+
+	spec := map[string]interface{}{
+		"secretName": name,
+		"commonName": tlsCertCommonName,
+		"subject": map[string]interface{}{
+			"organizations": []interface{}{"PingCAP"},
+		},
+		"usages":    toInterfaceSlice(usages),
+		"issuerRef": issuerRef,
+	}
+	if len(dnsNames) > 0 {
+		spec["dnsNames"] = toInterfaceSlice(dnsNames)
+		spec["ipAddresses"] = []interface{}{"127.0.0.1", "::1"}
+	}
+	if certManager.Duration != nil {
+		spec["duration"] = certManager.Duration.Duration.String()
+	}
+	if certManager.RenewBefore != nil {
+		spec["renewBefore"] = certManager.RenewBefore.Duration.String()
+	}
+
+	cert := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	cert.SetGroupVersionKind(CertificateGVK)
+	cert.SetName(name)
+	cert.SetNamespace(tc.GetNamespace())
+	cert.SetLabels(label.New().Instance(tc.GetInstanceName()).Component(component))
+	cert.SetOwnerReferences([]metav1.OwnerReference{controller.GetOwnerRef(tc)})
+	return cert
+}
+
+// clusterTLSCertDNSNames returns the SANs of the certificate of the component, nil if the component is not in the spec
+func clusterTLSCertDNSNames(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) []string {
+	tcName := tc.GetName()
+	var services, peerServices []string
+	switch memberType {
+	case v1alpha1.PDMemberType:
+		if tc.Spec.PD == nil {
+			return nil
+		}
+		services = []string{controller.PDMemberName(tcName), controller.PDPeerMemberName(tcName)}
+		peerServices = []string{controller.PDPeerMemberName(tcName)}
+	case v1alpha1.TiKVMemberType:
+		if tc.Spec.TiKV == nil {
+			return nil
+		}
+		services = []string{controller.TiKVMemberName(tcName), controller.TiKVPeerMemberName(tcName)}
+		peerServices = []string{controller.TiKVPeerMemberName(tcName)}
+	case v1alpha1.TiFlashMemberType:
+		if tc.Spec.TiFlash == nil {
+			return nil
+		}
+		services = []string{controller.TiFlashMemberName(tcName), controller.TiFlashPeerMemberName(tcName)}
+		peerServices = []string{controller.TiFlashPeerMemberName(tcName)}
+	case v1alpha1.PumpMemberType:
+		if tc.Spec.Pump == nil {
+			return nil
+		}
+		services = []string{controller.PumpMemberName(tcName)}
+		peerServices = []string{controller.PumpPeerMemberName(tcName)}
+	case v1alpha1.TiDBMemberType:
+		if tc.Spec.TiDB == nil {
+			return nil
+		}
+		services = []string{controller.TiDBMemberName(tcName), controller.TiDBPeerMemberName(tcName)}
+		peerServices = []string{controller.TiDBPeerMemberName(tcName)}
+	case v1alpha1.TiCDCMemberType:
+		if tc.Spec.TiCDC == nil {
+			return nil
+		}
+		services = []string{controller.TiCDCMemberName(tcName), controller.TiCDCPeerMemberName(tcName)}
+		peerServices = []string{controller.TiCDCPeerMemberName(tcName)}
+	default:
+		return nil
+	}
+
+	// the pods are covered by the wildcard names of the peer services, so the replicas do not matter
+	hosts := services
+	for _, svc := range peerServices {
+		hosts = append(hosts, "*."+svc)
+	}
+	// the certificates cover both cluster domains while the PD members are migrated to the new one
+	domains := []string{tc.Spec.ClusterDomain}
+	if migration := tc.Status.PD.ClusterDomainMigration; migration != nil && migration.From != tc.Spec.ClusterDomain {
+		domains = append(domains, migration.From)
+	}
+	ns := tc.GetNamespace()
+	var dnsNames []string
+	for _, host := range hosts {
+		dnsNames = append(dnsNames, host, fmt.Sprintf("%s.%s", host, ns), fmt.Sprintf("%s.%s.svc", host, ns))
+		for _, domain := range domains {
+			if domain != "" {
+				dnsNames = append(dnsNames, fmt.Sprintf("%s.%s.svc%s", host, ns, controller.FormatClusterDomain(domain)))
+			}
+		}
+	}
+	return dnsNames
+}
+
+func toInterfaceSlice(strs []string) []interface{} {
+	result := make([]interface{}, 0, len(strs))
+	for _, str := range strs {
+		result = append(result, str)
+	}
+	return result
+}
+
+type fakeCertManagerCertSyncer struct{}
+
+// NewFakeCertManagerCertSyncer returns a fake syncer of the cert-manager Certificates
+func NewFakeCertManagerCertSyncer() manager.Manager {
+	return &fakeCertManagerCertSyncer{}
+}
+
+func (s *fakeCertManagerCertSyncer) Sync(_ *v1alpha1.TidbCluster) error {
+	return nil
+}
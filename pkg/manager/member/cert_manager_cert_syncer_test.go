@@ -0,0 +1,113 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestCertManagerCertSyncer(t *testing.T) {
+	g := NewGomegaWithT(t)
+	tc := newTidbClusterForPD()
+	tc.Spec.TLSCluster = &v1alpha1.TLSCluster{
+		Enabled: true,
+		CertManager: &v1alpha1.TLSCertManager{
+			IssuerRef: v1alpha1.CertManagerIssuerRef{Name: "tidb-issuer"},
+			Duration:  &metav1.Duration{Duration: 90 * 24 * time.Hour},
+		},
+	}
+	tc.Spec.TiFlash = &v1alpha1.TiFlashSpec{}
+	fakeDeps := controller.NewFakeDependencies()
+	syncer := NewCertManagerCertSyncer(fakeDeps)
+
+	getCertificate := func(name string) (*unstructured.Unstructured, error) {
+		cert := &unstructured.Unstructured{}
+		cert.SetGroupVersionKind(CertificateGVK)
+		err := fakeDeps.GenericClient.Get(context.TODO(), client.ObjectKey{Namespace: tc.Namespace, Name: name}, cert)
+		return cert, err
+	}
+
+	g.Expect(syncer.Sync(tc)).To(Succeed())
+	for _, component := range []string{"pd", "tikv", "tiflash", "tidb"} {
+		cert, err := getCertificate(util.ClusterTLSSecretName(tc.Name, component))
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(metav1.IsControlledBy(cert, tc)).To(BeTrue())
+		secretName, _, _ := unstructured.NestedString(cert.Object, "spec", "secretName")
+		g.Expect(secretName).To(Equal(util.ClusterTLSSecretName(tc.Name, component)))
+		issuerRef, _, _ := unstructured.NestedStringMap(cert.Object, "spec", "issuerRef")
+		g.Expect(issuerRef).To(Equal(map[string]string{"name": "tidb-issuer", "kind": "Issuer", "group": "cert-manager.io"}))
+		duration, _, _ := unstructured.NestedString(cert.Object, "spec", "duration")
+		g.Expect(duration).To(Equal("2160h0m0s"))
+	}
+	for _, component := range []string{"pump", "ticdc"} {
+		_, err := getCertificate(util.ClusterTLSSecretName(tc.Name, component))
+		g.Expect(errors.IsNotFound(err)).To(BeTrue())
+	}
+	clientCert, err := getCertificate(util.ClusterClientTLSSecretName(tc.Name))
+	g.Expect(err).NotTo(HaveOccurred())
+	usages, _, _ := unstructured.NestedStringSlice(clientCert.Object, "spec", "usages")
+	g.Expect(usages).To(Equal([]string{"client auth"}))
+
+	pdCert, err := getCertificate(util.ClusterTLSSecretName(tc.Name, "pd"))
+	g.Expect(err).NotTo(HaveOccurred())
+	dnsNames, _, _ := unstructured.NestedStringSlice(pdCert.Object, "spec", "dnsNames")
+	g.Expect(dnsNames).To(ContainElement("test-pd.default.svc"))
+	g.Expect(dnsNames).To(ContainElement("*.test-pd-peer.default.svc"))
+	g.Expect(dnsNames).NotTo(ContainElement("*.test-pd-peer.default.svc.cluster.local"))
+
+	// the SANs are updated with the cluster domain and the removed components are cleaned up
+	tc.Spec.ClusterDomain = "cluster.local"
+	tc.Spec.TiFlash = nil
+	g.Expect(syncer.Sync(tc)).To(Succeed())
+	pdCert, err = getCertificate(util.ClusterTLSSecretName(tc.Name, "pd"))
+	g.Expect(err).NotTo(HaveOccurred())
+	dnsNames, _, _ = unstructured.NestedStringSlice(pdCert.Object, "spec", "dnsNames")
+	g.Expect(dnsNames).To(ContainElement("*.test-pd-peer.default.svc.cluster.local"))
+	g.Expect(dnsNames).To(ContainElement("test-pd.default.svc.cluster.local"))
+	_, err = getCertificate(util.ClusterTLSSecretName(tc.Name, "tiflash"))
+	g.Expect(errors.IsNotFound(err)).To(BeTrue())
+
+	// the Certificates not controlled by the tc are left untouched without failing the sync
+	tidbCert, err := getCertificate(util.ClusterTLSSecretName(tc.Name, "tidb"))
+	g.Expect(err).NotTo(HaveOccurred())
+	tidbCert.SetOwnerReferences(nil)
+	g.Expect(unstructured.SetNestedField(tidbCert.Object, "chart-issuer", "spec", "issuerRef", "name")).To(Succeed())
+	g.Expect(fakeDeps.GenericClient.Update(context.TODO(), tidbCert)).To(Succeed())
+	recorder := record.NewFakeRecorder(10)
+	fakeDeps.Recorder = recorder
+	g.Expect(syncer.Sync(tc)).To(Succeed())
+	tidbCert, err = getCertificate(util.ClusterTLSSecretName(tc.Name, "tidb"))
+	g.Expect(err).NotTo(HaveOccurred())
+	issuerName, _, _ := unstructured.NestedString(tidbCert.Object, "spec", "issuerRef", "name")
+	g.Expect(issuerName).To(Equal("chart-issuer"))
+	events := collectEvents(recorder.Events)
+	g.Expect(events).To(HaveLen(1))
+	g.Expect(events[0]).To(ContainSubstring(certificateNotOwnedReason))
+
+	// the event is not recorded again by the next sync
+	g.Expect(syncer.Sync(tc)).To(Succeed())
+	g.Expect(collectEvents(recorder.Events)).To(BeEmpty())
+}
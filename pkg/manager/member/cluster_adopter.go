@@ -0,0 +1,264 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+const (
+	// ClusterAdoptReason is the reason of the events emitted by the cluster adopter
+	ClusterAdoptReason = "ClusterAdopt"
+)
+
+// clusterAdopter adopts the existing cluster at `spec.pdAddresses`, e.g. deployed by TiUP or with raw
+// manifests, into the TidbCluster.
+//
+// It is opt-in by setting the annotation `tidb.pingcap.com/adopt: "true"` on the TidbCluster. The PD members
+// and the stores of the existing cluster are discovered through PD and kept in `status.adoption.externalMembers`,
+// then the components in the spec are created in Kubernetes one at a time in the order of PD, TiKV, TiFlash,
+// TiDB, TiCDC and Pump. The members in Kubernetes join the existing cluster at `spec.pdAddresses`, and the next
+// component is not created until all the members of the current one are healthy.
+//
+// Until the adoption completes, the operations which may delete the members or the stores, e.g. scaling in,
+// failover and cleaning the tombstone stores, are refused. The external members are never changed by the
+// operator, they can be scaled in by the tool which deploys them after the adoption completes.
+type clusterAdopter struct {
+	deps *controller.Dependencies
+}
+
+// NewClusterAdopter returns a cluster adopter
+func NewClusterAdopter(deps *controller.Dependencies) manager.Manager {
+	return &clusterAdopter{
+		deps: deps,
+	}
+}
+
+func (a *clusterAdopter) Sync(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	status := tc.Status.Adoption
+	if tc.Annotations[label.AnnAdoption] != "true" {
+		if tc.Adopting() {
+			tc.Status.Adoption = nil
+			klog.Infof("tidbcluster: [%s/%s] adoption is canceled as annotation %s is removed", ns, tcName, label.AnnAdoption)
+			a.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, ClusterAdoptReason, "adoption is canceled as annotation %s is removed", label.AnnAdoption)
+		}
+		return nil
+	}
+	if status != nil && status.Phase == v1alpha1.AdoptionPhaseComplete {
+		return nil
+	}
+	if len(tc.Spec.PDAddresses) == 0 {
+		// rejected by the validation
+		klog.Warningf("tidbcluster: [%s/%s] spec.pdAddresses is not set, skip adopting the cluster", ns, tcName)
+		return nil
+	}
+
+	if status == nil {
+		now := metav1.Now()
+		status = &v1alpha1.AdoptionStatus{
+			Phase:     v1alpha1.AdoptionPhaseDiscovering,
+			StartTime: &now,
+		}
+		tc.Status.Adoption = status
+		klog.Infof("tidbcluster: [%s/%s] begin to adopt the cluster at %s", ns, tcName, strings.Join(tc.Spec.PDAddresses, ","))
+		a.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, ClusterAdoptReason, "begin to adopt the cluster at %s", strings.Join(tc.Spec.PDAddresses, ","))
+	}
+
+	members, err := a.discover(tc)
+	if err != nil {
+		status.Message = fmt.Sprintf("failed to discover the members through PD: %v", err)
+		return fmt.Errorf("tidbcluster: [%s/%s] failed to discover the members of the cluster to adopt, error: %v", ns, tcName, err)
+	}
+	status.ExternalMembers = members
+	if status.Phase == v1alpha1.AdoptionPhaseDiscovering {
+		status.Phase = v1alpha1.AdoptionPhaseAdopting
+		status.Message = ""
+		klog.Infof("tidbcluster: [%s/%s] discovered %d external members of the cluster to adopt", ns, tcName, len(members))
+		a.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, ClusterAdoptReason, "discovered %d external members of the cluster to adopt", len(members))
+	}
+
+	for _, component := range v1alpha1.AdoptionComponents {
+		if !componentInSpec(tc, component) || containsMemberType(status.AdoptedComponents, component) {
+			continue
+		}
+		status.Component = component
+		if msg := adoptionPendingReason(tc, component); msg != "" {
+			// the component is created by its member manager, the components after it are not created yet
+			status.Message = msg
+			return nil
+		}
+		status.AdoptedComponents = append(status.AdoptedComponents, component)
+		status.Message = ""
+		klog.Infof("tidbcluster: [%s/%s] %s is adopted", ns, tcName, component)
+		a.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, ClusterAdoptReason, "%s is created and ready in Kubernetes", component)
+	}
+
+	now := metav1.Now()
+	status.Phase = v1alpha1.AdoptionPhaseComplete
+	status.Component = ""
+	status.Message = ""
+	status.CompletionTime = &now
+	klog.Infof("tidbcluster: [%s/%s] the cluster is adopted", ns, tcName)
+	a.deps.Recorder.Event(tc, corev1.EventTypeNormal, ClusterAdoptReason, "the cluster is adopted, the external members can be scaled in")
+	return nil
+}
+
+// discover returns the PD members and the stores which are not served by the TidbCluster
+func (a *clusterAdopter) discover(tc *v1alpha1.TidbCluster) ([]v1alpha1.AdoptionMember, error) {
+	domain := controller.FormatClusterDomainForRegex(tc.Spec.ClusterDomain)
+	rePDMembers, err := regexp.Compile(fmt.Sprintf(pdMemberLimitPattern, tc.Name, tc.Name, tc.Namespace, domain))
+	if err != nil {
+		return nil, err
+	}
+	reTiKVStores, err := regexp.Compile(fmt.Sprintf(tikvStoreLimitPattern, tc.Name, tc.Name, tc.Namespace, domain))
+	if err != nil {
+		return nil, err
+	}
+	reTiFlashStores, err := regexp.Compile(fmt.Sprintf(tiflashStoreLimitPattern, tc.Name, tc.Name, tc.Namespace, domain))
+	if err != nil {
+		return nil, err
+	}
+
+	pdClient := controller.GetExternalPDClient(a.deps.PDControl, tc)
+	membersInfo, err := pdClient.GetMembers()
+	if err != nil {
+		return nil, err
+	}
+	var members []v1alpha1.AdoptionMember
+	for _, member := range membersInfo.Members {
+		var clientURL string
+		if len(member.ClientUrls) > 0 {
+			clientURL = member.ClientUrls[0]
+		}
+		if rePDMembers.MatchString(clientURL) {
+			continue
+		}
+		members = append(members, v1alpha1.AdoptionMember{
+			Component: v1alpha1.PDMemberType,
+			Name:      member.Name,
+			Address:   clientURL,
+		})
+	}
+
+	storesInfo, err := pdClient.GetStores()
+	if err != nil {
+		return nil, err
+	}
+	for _, store := range storesInfo.Stores {
+		if store.Store == nil || store.Store.StateName == v1alpha1.TiKVStateTombstone {
+			continue
+		}
+		address := store.Store.GetAddress()
+		component := v1alpha1.TiKVMemberType
+		for _, l := range store.Store.GetLabels() {
+			if l.GetKey() == "engine" && l.GetValue() == "tiflash" {
+				component = v1alpha1.TiFlashMemberType
+			}
+		}
+		if reTiKVStores.MatchString(address) || reTiFlashStores.MatchString(address) {
+			continue
+		}
+		members = append(members, v1alpha1.AdoptionMember{
+			Component: component,
+			Name:      strconv.FormatUint(store.Store.GetId(), 10),
+			Address:   address,
+		})
+	}
+	return members, nil
+}
+
+// adoptionPendingReason returns why the component is not adopted yet, empty if all its members are
+// created and healthy in Kubernetes
+func adoptionPendingReason(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) string {
+	var ready bool
+	switch memberType {
+	case v1alpha1.PDMemberType:
+		ready = tc.PDAllMembersReady()
+	case v1alpha1.TiKVMemberType:
+		ready = tc.TiKVAllStoresReady()
+	case v1alpha1.TiFlashMemberType:
+		ready = tc.TiFlashAllStoresReady()
+	case v1alpha1.TiDBMemberType:
+		ready = tc.TiDBAllMembersReady()
+	case v1alpha1.TiCDCMemberType:
+		ready = tc.Status.TiCDC.StatefulSet != nil && tc.Status.TiCDC.StatefulSet.ReadyReplicas == tc.Spec.TiCDC.Replicas
+	case v1alpha1.PumpMemberType:
+		ready = tc.Status.Pump.StatefulSet != nil && tc.Status.Pump.StatefulSet.ReadyReplicas == tc.Spec.Pump.Replicas
+	}
+	if ready {
+		return ""
+	}
+	return fmt.Sprintf("waiting for the %s members in Kubernetes to join the cluster and be healthy", memberType)
+}
+
+// refuseIfAdopting returns an error refusing the operation which may delete the members or the stores
+// if the TidbCluster is adopting an existing cluster
+func refuseIfAdopting(meta metav1.Object, operation string) error {
+	tc, ok := meta.(*v1alpha1.TidbCluster)
+	if !ok || !tc.Adopting() {
+		return nil
+	}
+	return controller.RequeueErrorf("tidbcluster: [%s/%s] %s is refused until the adoption completes", tc.GetNamespace(), tc.GetName(), operation)
+}
+
+func componentInSpec(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) bool {
+	switch memberType {
+	case v1alpha1.PDMemberType:
+		return tc.Spec.PD != nil
+	case v1alpha1.TiKVMemberType:
+		return tc.Spec.TiKV != nil
+	case v1alpha1.TiFlashMemberType:
+		return tc.Spec.TiFlash != nil
+	case v1alpha1.TiDBMemberType:
+		return tc.Spec.TiDB != nil
+	case v1alpha1.TiCDCMemberType:
+		return tc.Spec.TiCDC != nil
+	case v1alpha1.PumpMemberType:
+		return tc.Spec.Pump != nil
+	}
+	return false
+}
+
+func containsMemberType(memberTypes []v1alpha1.MemberType, memberType v1alpha1.MemberType) bool {
+	for _, t := range memberTypes {
+		if t == memberType {
+			return true
+		}
+	}
+	return false
+}
+
+type fakeClusterAdopter struct{}
+
+// NewFakeClusterAdopter returns a fake cluster adopter
+func NewFakeClusterAdopter() manager.Manager {
+	return &fakeClusterAdopter{}
+}
+
+func (a *fakeClusterAdopter) Sync(_ *v1alpha1.TidbCluster) error {
+	return nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+)
+
+func TestClusterAdopterSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+	deps := controller.NewFakeDependencies()
+	adopter := NewClusterAdopter(deps)
+	tc := newTidbClusterForAdoption()
+	pdClient := controller.NewFakePDClientWithAddress(deps.PDControl.(*pdapi.FakePDControl), "http://10.0.0.1:2379")
+	pdClient.AddReaction(pdapi.GetMembersActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdapi.MembersInfo{Members: []*pdpb.Member{
+			{Name: "pd-10.0.0.1-2379", ClientUrls: []string{"http://10.0.0.1:2379"}},
+			{Name: "test-pd-0", ClientUrls: []string{"http://test-pd-0.test-pd-peer.default.svc:2379"}},
+		}}, nil
+	})
+	pdClient.AddReaction(pdapi.GetStoresActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdapi.StoresInfo{Stores: []*pdapi.StoreInfo{
+			{Store: &pdapi.MetaStore{Store: &metapb.Store{Id: 1, Address: "10.0.0.2:20160"}, StateName: v1alpha1.TiKVStateUp}},
+			{Store: &pdapi.MetaStore{Store: &metapb.Store{Id: 2, Address: "10.0.0.3:20160"}, StateName: v1alpha1.TiKVStateTombstone}},
+			{Store: &pdapi.MetaStore{
+				Store:     &metapb.Store{Id: 3, Address: "10.0.0.4:3930", Labels: []*metapb.StoreLabel{{Key: "engine", Value: "tiflash"}}},
+				StateName: v1alpha1.TiKVStateUp,
+			}},
+			{Store: &pdapi.MetaStore{Store: &metapb.Store{Id: 4, Address: "test-tikv-0.test-tikv-peer.default.svc:20160"}, StateName: v1alpha1.TiKVStateUp}},
+		}}, nil
+	})
+
+	// the members out of the TidbCluster are discovered, and PD is created first
+	g.Expect(adopter.Sync(tc)).To(Succeed())
+	status := tc.Status.Adoption
+	g.Expect(status.Phase).To(Equal(v1alpha1.AdoptionPhaseAdopting))
+	g.Expect(status.Component).To(Equal(v1alpha1.PDMemberType))
+	g.Expect(status.ExternalMembers).To(Equal([]v1alpha1.AdoptionMember{
+		{Component: v1alpha1.PDMemberType, Name: "pd-10.0.0.1-2379", Address: "http://10.0.0.1:2379"},
+		{Component: v1alpha1.TiKVMemberType, Name: "1", Address: "10.0.0.2:20160"},
+		{Component: v1alpha1.TiFlashMemberType, Name: "3", Address: "10.0.0.4:3930"},
+	}))
+	g.Expect(tc.AdoptionPending(v1alpha1.PDMemberType)).To(BeFalse())
+	g.Expect(tc.AdoptionPending(v1alpha1.TiKVMemberType)).To(BeTrue())
+	g.Expect(refuseIfAdopting(tc, "scaling in pd")).To(HaveOccurred())
+
+	// TiKV is created after the PD members are healthy
+	setPDMembersInClusterDomain(tc, "")
+	g.Expect(adopter.Sync(tc)).To(Succeed())
+	g.Expect(status.AdoptedComponents).To(Equal([]v1alpha1.MemberType{v1alpha1.PDMemberType}))
+	g.Expect(status.Component).To(Equal(v1alpha1.TiKVMemberType))
+	g.Expect(status.Message).NotTo(BeEmpty())
+
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{}
+	for i := 0; i < 3; i++ {
+		id := fmt.Sprintf("%d", 10+i)
+		tc.Status.TiKV.Stores[id] = v1alpha1.TiKVStore{ID: id, State: v1alpha1.TiKVStateUp}
+	}
+	g.Expect(adopter.Sync(tc)).To(Succeed())
+	g.Expect(status.Phase).To(Equal(v1alpha1.AdoptionPhaseComplete))
+	g.Expect(status.AdoptedComponents).To(Equal([]v1alpha1.MemberType{v1alpha1.PDMemberType, v1alpha1.TiKVMemberType, v1alpha1.TiDBMemberType}))
+	g.Expect(status.CompletionTime).NotTo(BeNil())
+	g.Expect(tc.Adopting()).To(BeFalse())
+	g.Expect(refuseIfAdopting(tc, "scaling in pd")).To(Succeed())
+}
+
+func TestClusterAdopterCancel(t *testing.T) {
+	g := NewGomegaWithT(t)
+	deps := controller.NewFakeDependencies()
+	adopter := NewClusterAdopter(deps)
+	tc := newTidbClusterForAdoption()
+	pdClient := controller.NewFakePDClientWithAddress(deps.PDControl.(*pdapi.FakePDControl), "http://10.0.0.1:2379")
+	pdClient.AddReaction(pdapi.GetMembersActionType, func(action *pdapi.Action) (interface{}, error) {
+		return nil, fmt.Errorf("pd is unavailable")
+	})
+
+	// no component is created until the members are discovered
+	g.Expect(adopter.Sync(tc)).NotTo(Succeed())
+	g.Expect(tc.Status.Adoption.Phase).To(Equal(v1alpha1.AdoptionPhaseDiscovering))
+	g.Expect(tc.Status.Adoption.Message).To(ContainSubstring("pd is unavailable"))
+	g.Expect(tc.AdoptionPending(v1alpha1.PDMemberType)).To(BeTrue())
+
+	delete(tc.Annotations, label.AnnAdoption)
+	g.Expect(adopter.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.Adoption).To(BeNil())
+}
+
+func newTidbClusterForAdoption() *v1alpha1.TidbCluster {
+	tc := newTidbClusterForPD()
+	tc.Annotations = map[string]string{label.AnnAdoption: "true"}
+	tc.Spec.PDAddresses = []string{"http://10.0.0.1:2379"}
+	return tc
+}
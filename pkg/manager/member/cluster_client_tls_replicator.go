@@ -0,0 +1,135 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+// ClusterClientTLSOutdatedReason is the reason of the events emitted when the cluster client TLS secret
+// which is not replicated by the operator differs from the secret of the TidbCluster referred by `spec.cluster`
+const ClusterClientTLSOutdatedReason = "ClusterClientTLSOutdated"
+
+// clusterClientTLSReplicator replicates the cluster client TLS secret of the TidbCluster referred by
+// `spec.cluster` into the secret of the joining TidbCluster, so that the operator and the components of the
+// joining TidbCluster can connect to the referred cluster with TLS without copying the secret manually, e.g.
+// when the TidbCluster joins a cluster in another namespace. The copy is refreshed once the secret is rotated.
+//
+// The secret is not replicated if the certificates of the joining TidbCluster are issued by the operator,
+// or if the referred TidbCluster is not in the local Kubernetes cluster. A secret which is not replicated
+// by the operator is never overwritten, a warning event is emitted if it differs from the referred secret.
+type clusterClientTLSReplicator struct {
+	deps *controller.Dependencies
+}
+
+// NewClusterClientTLSReplicator returns a replicator of the cluster client TLS secret
+func NewClusterClientTLSReplicator(deps *controller.Dependencies) manager.Manager {
+	return &clusterClientTLSReplicator{
+		deps: deps,
+	}
+}
+
+func (r *clusterClientTLSReplicator) Sync(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.Cluster == nil || !tc.IsTLSClusterEnabled() || tc.CertManagerEnabled() || tc.VaultEnabled() {
+		return nil
+	}
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	refNs := tc.Spec.Cluster.Namespace
+	if refNs == "" {
+		refNs = ns
+	}
+	refName := tc.Spec.Cluster.Name
+	if refNs == ns && refName == tcName {
+		return nil
+	}
+	if tc.Spec.Cluster.ClusterDomain != "" && tc.Spec.Cluster.ClusterDomain != tc.Spec.ClusterDomain {
+		// the referred TidbCluster is in another Kubernetes cluster
+		return nil
+	}
+
+	sourceName := util.ClusterClientTLSSecretName(refName)
+	source, err := r.deps.SecretLister.Secrets(refNs).Get(sourceName)
+	if errors.IsNotFound(err) {
+		// the secret may be not issued yet
+		klog.V(4).Infof("clusterClientTLSReplicator: secret %s/%s referred by tc %s/%s is not found", refNs, sourceName, ns, tcName)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("clusterClientTLSReplicator: failed to get secret %s/%s, error: %v", refNs, sourceName, err)
+	}
+	sourceKey := fmt.Sprintf("%s/%s", refNs, sourceName)
+
+	name := util.ClusterClientTLSSecretName(tcName)
+	existing, err := r.deps.SecretLister.Secrets(ns).Get(name)
+	if errors.IsNotFound(err) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       ns,
+				Labels:          label.New().Instance(tc.GetInstanceName()).Component(label.ClusterClientTLSLabelVal),
+				Annotations:     map[string]string{label.AnnClusterClientTLSSource: sourceKey},
+				OwnerReferences: []metav1.OwnerReference{controller.GetOwnerRef(tc)},
+			},
+			Type: source.Type,
+			Data: source.Data,
+		}
+		if _, err := r.deps.KubeClientset.CoreV1().Secrets(ns).Create(secret); err != nil {
+			return fmt.Errorf("clusterClientTLSReplicator: failed to create secret %s/%s, error: %v", ns, name, err)
+		}
+		klog.Infof("clusterClientTLSReplicator: secret %s is replicated into %s/%s for tc %s/%s", sourceKey, ns, name, ns, tcName)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("clusterClientTLSReplicator: failed to get secret %s/%s, error: %v", ns, name, err)
+	}
+	if reflect.DeepEqual(existing.Data, source.Data) {
+		return nil
+	}
+	if existing.Annotations[label.AnnClusterClientTLSSource] != sourceKey {
+		r.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, ClusterClientTLSOutdatedReason,
+			"secret %s differs from secret %s of the referred cluster, it is not replicated by the operator and is not overwritten, delete it to let the operator replicate it",
+			name, sourceKey)
+		return nil
+	}
+	updated := existing.DeepCopy()
+	updated.Data = source.Data
+	if _, err := r.deps.KubeClientset.CoreV1().Secrets(ns).Update(updated); err != nil {
+		return fmt.Errorf("clusterClientTLSReplicator: failed to update secret %s/%s, error: %v", ns, name, err)
+	}
+	klog.Infof("clusterClientTLSReplicator: secret %s/%s of tc %s/%s is refreshed from %s", ns, name, ns, tcName, sourceKey)
+	return nil
+}
+
+type fakeClusterClientTLSReplicator struct{}
+
+// NewFakeClusterClientTLSReplicator returns a fake replicator of the cluster client TLS secret
+func NewFakeClusterClientTLSReplicator() manager.Manager {
+	return &fakeClusterClientTLSReplicator{}
+}
+
+func (r *fakeClusterClientTLSReplicator) Sync(_ *v1alpha1.TidbCluster) error {
+	return nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestClusterClientTLSReplicatorSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPD()
+	tc.Spec.TLSCluster = &v1alpha1.TLSCluster{Enabled: true}
+	tc.Spec.Cluster = &v1alpha1.TidbClusterRef{Namespace: "main", Name: "main"}
+	deps := controller.NewFakeDependencies()
+	recorder := record.NewFakeRecorder(10)
+	deps.Recorder = recorder
+	replicator := NewClusterClientTLSReplicator(deps)
+	secretIndexer := deps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer()
+	// syncCache refreshes the secrets in the cache from the clientset
+	syncCache := func(namespaces ...string) {
+		for _, ns := range namespaces {
+			list, err := deps.KubeClientset.CoreV1().Secrets(ns).List(metav1.ListOptions{})
+			g.Expect(err).NotTo(HaveOccurred())
+			for _, obj := range secretIndexer.List() {
+				if obj.(*corev1.Secret).Namespace == ns {
+					secretIndexer.Delete(obj)
+				}
+			}
+			for i := range list.Items {
+				secretIndexer.Add(&list.Items[i])
+			}
+		}
+	}
+	setSource := func(data string) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "main", Name: util.ClusterClientTLSSecretName("main")},
+			Type:       corev1.SecretTypeTLS,
+			Data:       map[string][]byte{corev1.TLSCertKey: []byte(data)},
+		}
+		secretClient := deps.KubeClientset.CoreV1().Secrets("main")
+		if _, err := secretClient.Update(secret); err != nil {
+			_, err = secretClient.Create(secret)
+			g.Expect(err).NotTo(HaveOccurred())
+		}
+		syncCache("main")
+	}
+	getCopy := func() *corev1.Secret {
+		secret, err := deps.KubeClientset.CoreV1().Secrets(tc.Namespace).Get(util.ClusterClientTLSSecretName(tc.Name), metav1.GetOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		return secret
+	}
+
+	// the secret is replicated once it is issued
+	g.Expect(replicator.Sync(tc)).To(Succeed())
+	syncCache(tc.Namespace)
+	g.Expect(secretIndexer.ListKeys()).To(BeEmpty())
+	setSource("cert-1")
+	g.Expect(replicator.Sync(tc)).To(Succeed())
+	syncCache(tc.Namespace)
+	secret := getCopy()
+	g.Expect(secret.Type).To(Equal(corev1.SecretTypeTLS))
+	g.Expect(secret.Data).To(HaveKeyWithValue(corev1.TLSCertKey, []byte("cert-1")))
+	g.Expect(secret.Annotations).To(HaveKeyWithValue(label.AnnClusterClientTLSSource, "main/main-cluster-client-secret"))
+	g.Expect(secret.OwnerReferences).To(HaveLen(1))
+
+	// the copy is refreshed once the secret is rotated
+	setSource("cert-2")
+	g.Expect(replicator.Sync(tc)).To(Succeed())
+	syncCache(tc.Namespace)
+	g.Expect(getCopy().Data).To(HaveKeyWithValue(corev1.TLSCertKey, []byte("cert-2")))
+	g.Expect(collectEvents(recorder.Events)).To(BeEmpty())
+
+	// the secret which is not replicated by the operator is not overwritten
+	secret = getCopy()
+	secret.Annotations = nil
+	_, err := deps.KubeClientset.CoreV1().Secrets(tc.Namespace).Update(secret)
+	g.Expect(err).NotTo(HaveOccurred())
+	syncCache(tc.Namespace)
+	setSource("cert-3")
+	g.Expect(replicator.Sync(tc)).To(Succeed())
+	g.Expect(getCopy().Data).To(HaveKeyWithValue(corev1.TLSCertKey, []byte("cert-2")))
+	events := collectEvents(recorder.Events)
+	g.Expect(events).To(HaveLen(1))
+	g.Expect(events[0]).To(ContainSubstring(ClusterClientTLSOutdatedReason))
+
+	// the secret is not replicated if the certificates are issued by the operator
+	tc.Spec.TLSCluster.CertManager = &v1alpha1.TLSCertManager{}
+	g.Expect(deps.KubeClientset.CoreV1().Secrets(tc.Namespace).Delete(secret.Name, nil)).To(Succeed())
+	syncCache(tc.Namespace)
+	g.Expect(replicator.Sync(tc)).To(Succeed())
+	_, err = deps.KubeClientset.CoreV1().Secrets(tc.Namespace).Get(secret.Name, metav1.GetOptions{})
+	g.Expect(err).To(HaveOccurred())
+}
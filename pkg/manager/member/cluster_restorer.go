@@ -0,0 +1,261 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+const (
+	// ClusterRestoreReason is the reason of the events emitted by the cluster restorer
+	ClusterRestoreReason = "ClusterRestore"
+)
+
+// clusterRestorer restores the data of `spec.restore` into the TidbCluster when it is created, e.g. to clone
+// the production data into a dev or staging environment in one step.
+//
+// The Restore `<cluster>-bootstrap` is created by BR once PD, TiKV and TiDB are ready, from the storage of a
+// completed Backup or of `spec.restore.restoreSpec`, and the cluster is not Ready until the Restore completes.
+// The Restore is owned by the TidbCluster, it is created again if it is deleted before it completes. `spec.restore`
+// set after the cluster is created is skipped, the data is never restored into a cluster which is serving.
+type clusterRestorer struct {
+	deps *controller.Dependencies
+}
+
+// NewClusterRestorer returns a cluster restorer
+func NewClusterRestorer(deps *controller.Dependencies) manager.Manager {
+	return &clusterRestorer{
+		deps: deps,
+	}
+}
+
+func (r *clusterRestorer) Sync(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.Restore == nil {
+		return nil
+	}
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	status := tc.Status.Restore
+	if status == nil {
+		status = &v1alpha1.TidbClusterRestoreStatus{Phase: v1alpha1.TidbClusterRestorePending}
+		created, err := r.componentsCreated(tc)
+		if err != nil {
+			return fmt.Errorf("tidbcluster: [%s/%s] failed to check whether the components are created, error: %v", ns, tcName, err)
+		}
+		if created {
+			// the components are created before spec.restore is set
+			status.Phase = v1alpha1.TidbClusterRestoreSkipped
+			status.Message = "spec.restore is set after the cluster is created, nothing is restored"
+			klog.Warningf("tidbcluster: [%s/%s] %s", ns, tcName, status.Message)
+		}
+		tc.Status.Restore = status
+	}
+
+	switch status.Phase {
+	case v1alpha1.TidbClusterRestorePending:
+		if msg := restorePendingReason(tc); msg != "" {
+			status.Message = msg
+			return nil
+		}
+		return r.createRestore(tc, status)
+	case v1alpha1.TidbClusterRestoreRunning, v1alpha1.TidbClusterRestoreFailed:
+		restore, err := r.deps.RestoreLister.Restores(ns).Get(status.RestoreName)
+		if errors.IsNotFound(err) {
+			// the Restore is deleted to restore the data again
+			return r.createRestore(tc, status)
+		}
+		if err != nil {
+			return fmt.Errorf("tidbcluster: [%s/%s] failed to get restore %s, error: %v", ns, tcName, status.RestoreName, err)
+		}
+		r.updateStatus(tc, status, restore)
+	}
+	return nil
+}
+
+// createRestore creates the Restore of the data into the TidbCluster
+func (r *clusterRestorer) createRestore(tc *v1alpha1.TidbCluster, status *v1alpha1.TidbClusterRestoreStatus) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	spec, err := r.restoreSpec(tc)
+	if err != nil {
+		status.Message = err.Error()
+		return fmt.Errorf("tidbcluster: [%s/%s] failed to restore the data, error: %v", ns, tcName, err)
+	}
+	restore := &v1alpha1.Restore{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            bootstrapRestoreName(tcName),
+			Namespace:       ns,
+			OwnerReferences: []metav1.OwnerReference{controller.GetOwnerRef(tc)},
+		},
+		Spec: *spec,
+	}
+	if _, err := r.deps.Clientset.PingcapV1alpha1().Restores(ns).Create(restore); err != nil && !errors.IsAlreadyExists(err) {
+		status.Message = fmt.Sprintf("failed to create restore %s: %v", restore.Name, err)
+		return fmt.Errorf("tidbcluster: [%s/%s] failed to create restore %s, error: %v", ns, tcName, restore.Name, err)
+	}
+	now := metav1.Now()
+	status.Phase = v1alpha1.TidbClusterRestoreRunning
+	status.RestoreName = restore.Name
+	status.Message = ""
+	status.StartTime = &now
+	status.CompletionTime = nil
+	klog.Infof("tidbcluster: [%s/%s] created restore %s to restore the data into the cluster", ns, tcName, restore.Name)
+	r.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, ClusterRestoreReason, "created restore %s to restore the data into the cluster", restore.Name)
+	return nil
+}
+
+// restoreSpec returns the spec of the Restore, which restores from the storage of the Backup if it is set
+func (r *clusterRestorer) restoreSpec(tc *v1alpha1.TidbCluster) (*v1alpha1.RestoreSpec, error) {
+	var spec *v1alpha1.RestoreSpec
+	if name := tc.Spec.Restore.Backup; name != "" {
+		backup, err := r.deps.BackupLister.Backups(tc.GetNamespace()).Get(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get backup %s: %v", name, err)
+		}
+		if !v1alpha1.IsBackupComplete(backup) {
+			return nil, fmt.Errorf("backup %s is not complete", name)
+		}
+		if backup.Spec.BR == nil {
+			return nil, fmt.Errorf("backup %s is not backed up by BR", name)
+		}
+		spec = &v1alpha1.RestoreSpec{
+			ResourceRequirements: backup.Spec.ResourceRequirements,
+			Env:                  backup.Spec.Env,
+			Type:                 backup.Spec.Type,
+			StorageProvider:      backup.Spec.StorageProvider,
+			BR:                   backup.Spec.BR.DeepCopy(),
+			Tolerations:          backup.Spec.Tolerations,
+			Affinity:             backup.Spec.Affinity,
+			UseKMS:               backup.Spec.UseKMS,
+			ServiceAccount:       backup.Spec.ServiceAccount,
+			ToolImage:            backup.Spec.ToolImage,
+			ImagePullSecrets:     backup.Spec.ImagePullSecrets,
+			TableFilter:          backup.Spec.TableFilter,
+			PodSecurityContext:   backup.Spec.PodSecurityContext,
+			PriorityClassName:    backup.Spec.PriorityClassName,
+		}
+	} else {
+		spec = tc.Spec.Restore.RestoreSpec.DeepCopy()
+		if br := spec.BR; br != nil {
+			if br.Cluster != "" && br.Cluster != tc.GetName() {
+				return nil, fmt.Errorf("restoreSpec.br.cluster %s is not the TidbCluster, the data can only be restored into the TidbCluster", br.Cluster)
+			}
+			if br.ClusterNamespace != "" && br.ClusterNamespace != tc.GetNamespace() {
+				return nil, fmt.Errorf("restoreSpec.br.clusterNamespace %s is not the namespace of the TidbCluster, the data can only be restored into the TidbCluster", br.ClusterNamespace)
+			}
+		}
+	}
+	// the cluster of the Backup is the cluster backed up, the data is restored into the TidbCluster instead
+	if spec.BR == nil {
+		spec.BR = &v1alpha1.BRConfig{}
+	}
+	spec.BR.Cluster = tc.GetName()
+	spec.BR.ClusterNamespace = tc.GetNamespace()
+	return spec, nil
+}
+
+func (r *clusterRestorer) updateStatus(tc *v1alpha1.TidbCluster, status *v1alpha1.TidbClusterRestoreStatus, restore *v1alpha1.Restore) {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	switch {
+	case v1alpha1.IsRestoreComplete(restore):
+		now := metav1.Now()
+		status.Phase = v1alpha1.TidbClusterRestoreComplete
+		status.Message = ""
+		status.CompletionTime = &now
+		klog.Infof("tidbcluster: [%s/%s] restore %s completes", ns, tcName, restore.Name)
+		r.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, ClusterRestoreReason, "restore %s completes", restore.Name)
+	case v1alpha1.IsRestoreFailed(restore):
+		if status.Phase == v1alpha1.TidbClusterRestoreFailed {
+			return
+		}
+		_, condition := v1alpha1.GetRestoreCondition(&restore.Status, v1alpha1.RestoreFailed)
+		status.Phase = v1alpha1.TidbClusterRestoreFailed
+		status.Message = fmt.Sprintf("restore %s fails: %s, delete it to restore the data again", restore.Name, condition.Message)
+		klog.Warningf("tidbcluster: [%s/%s] %s", ns, tcName, status.Message)
+		r.deps.Recorder.Event(tc, corev1.EventTypeWarning, ClusterRestoreReason, status.Message)
+	}
+}
+
+// componentsCreated returns whether any component of the TidbCluster is created, in the status or in
+// Kubernetes in case the status is not updated yet
+func (r *clusterRestorer) componentsCreated(tc *v1alpha1.TidbCluster) (bool, error) {
+	if tc.Status.PD.StatefulSet != nil || tc.Status.TiKV.StatefulSet != nil || tc.Status.TiDB.StatefulSet != nil ||
+		tc.Status.TiFlash.StatefulSet != nil || tc.Status.TiCDC.StatefulSet != nil || tc.Status.Pump.StatefulSet != nil {
+		return true, nil
+	}
+	tcName := tc.GetName()
+	for _, name := range []string{
+		controller.PDMemberName(tcName),
+		controller.TiKVMemberName(tcName),
+		controller.TiDBMemberName(tcName),
+		controller.TiFlashMemberName(tcName),
+		controller.TiCDCMemberName(tcName),
+		controller.PumpMemberName(tcName),
+	} {
+		_, err := r.deps.StatefulSetLister.StatefulSets(tc.GetNamespace()).Get(name)
+		if err == nil {
+			return true, nil
+		}
+		if !errors.IsNotFound(err) {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// restorePendingReason returns why the Restore is not created yet, empty if the components are ready to restore
+func restorePendingReason(tc *v1alpha1.TidbCluster) string {
+	for _, component := range []v1alpha1.MemberType{v1alpha1.PDMemberType, v1alpha1.TiKVMemberType, v1alpha1.TiDBMemberType} {
+		if !componentInSpec(tc, component) {
+			continue
+		}
+		var ready bool
+		switch component {
+		case v1alpha1.PDMemberType:
+			ready = tc.PDAllMembersReady()
+		case v1alpha1.TiKVMemberType:
+			ready = tc.TiKVAllStoresReady()
+		case v1alpha1.TiDBMemberType:
+			ready = tc.TiDBAllMembersReady()
+		}
+		if !ready {
+			return fmt.Sprintf("waiting for the %s members to be ready before restoring the data", component)
+		}
+	}
+	return ""
+}
+
+func bootstrapRestoreName(tcName string) string {
+	return fmt.Sprintf("%s-bootstrap", tcName)
+}
+
+type fakeClusterRestorer struct{}
+
+// NewFakeClusterRestorer returns a fake cluster restorer
+func NewFakeClusterRestorer() manager.Manager {
+	return &fakeClusterRestorer{}
+}
+
+func (r *fakeClusterRestorer) Sync(_ *v1alpha1.TidbCluster) error {
+	return nil
+}
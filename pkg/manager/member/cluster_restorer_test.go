@@ -0,0 +1,154 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClusterRestorerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+	deps := controller.NewFakeDependencies()
+	restorer := NewClusterRestorer(deps)
+	backupIndexer := deps.InformerFactory.Pingcap().V1alpha1().Backups().Informer().GetIndexer()
+	restoreIndexer := deps.InformerFactory.Pingcap().V1alpha1().Restores().Informer().GetIndexer()
+	tc := newTidbClusterForRestore()
+	tc.Spec.Restore = &v1alpha1.TidbClusterRestoreSpec{Backup: "prod"}
+
+	// the data is not restored until the components are ready
+	g.Expect(restorer.Sync(tc)).To(Succeed())
+	status := tc.Status.Restore
+	g.Expect(status.Phase).To(Equal(v1alpha1.TidbClusterRestorePending))
+	g.Expect(status.Message).To(ContainSubstring("pd"))
+
+	setPDMembersInClusterDomain(tc, "")
+	setTiKVStoresUp(tc)
+	g.Expect(restorer.Sync(tc)).NotTo(Succeed())
+	g.Expect(status.Phase).To(Equal(v1alpha1.TidbClusterRestorePending))
+	g.Expect(status.Message).To(ContainSubstring("prod"))
+
+	backup := &v1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: tc.Namespace},
+		Spec: v1alpha1.BackupSpec{
+			StorageProvider: v1alpha1.StorageProvider{S3: &v1alpha1.S3StorageProvider{Bucket: "backup", Prefix: "prod"}},
+			BR:              &v1alpha1.BRConfig{Cluster: "prod", ClusterNamespace: "prod"},
+			TableFilter:     []string{"app.*"},
+		},
+		Status: v1alpha1.BackupStatus{
+			Conditions: []v1alpha1.BackupCondition{{Type: v1alpha1.BackupComplete, Status: corev1.ConditionTrue}},
+		},
+	}
+	g.Expect(backupIndexer.Add(backup)).To(Succeed())
+	g.Expect(restorer.Sync(tc)).To(Succeed())
+	g.Expect(status.Phase).To(Equal(v1alpha1.TidbClusterRestoreRunning))
+	g.Expect(status.RestoreName).To(Equal("test-bootstrap"))
+	restore, err := deps.Clientset.PingcapV1alpha1().Restores(tc.Namespace).Get(status.RestoreName, metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(restore.Spec.StorageProvider).To(Equal(backup.Spec.StorageProvider))
+	g.Expect(restore.Spec.TableFilter).To(Equal([]string{"app.*"}))
+	g.Expect(restore.Spec.BR).To(Equal(&v1alpha1.BRConfig{Cluster: tc.Name, ClusterNamespace: tc.Namespace}))
+	g.Expect(restore.OwnerReferences).To(HaveLen(1))
+
+	// the Restore is created again after it fails and is deleted
+	restore.Status.Conditions = []v1alpha1.RestoreCondition{{Type: v1alpha1.RestoreFailed, Status: corev1.ConditionTrue, Message: "bucket not found"}}
+	g.Expect(restoreIndexer.Add(restore)).To(Succeed())
+	g.Expect(restorer.Sync(tc)).To(Succeed())
+	g.Expect(status.Phase).To(Equal(v1alpha1.TidbClusterRestoreFailed))
+	g.Expect(status.Message).To(ContainSubstring("bucket not found"))
+
+	g.Expect(restoreIndexer.Delete(restore)).To(Succeed())
+	g.Expect(restorer.Sync(tc)).To(Succeed())
+	g.Expect(status.Phase).To(Equal(v1alpha1.TidbClusterRestoreRunning))
+
+	restore.Status.Conditions = []v1alpha1.RestoreCondition{{Type: v1alpha1.RestoreComplete, Status: corev1.ConditionTrue}}
+	g.Expect(restoreIndexer.Add(restore)).To(Succeed())
+	g.Expect(restorer.Sync(tc)).To(Succeed())
+	g.Expect(status.Phase).To(Equal(v1alpha1.TidbClusterRestoreComplete))
+	g.Expect(status.CompletionTime).NotTo(BeNil())
+}
+
+func TestClusterRestorerSkipped(t *testing.T) {
+	g := NewGomegaWithT(t)
+	deps := controller.NewFakeDependencies()
+	restorer := NewClusterRestorer(deps)
+
+	// nothing is restored into the cluster created before spec.restore is set
+	tc := newTidbClusterForRestore()
+	tc.Status.PD.StatefulSet = &appsv1.StatefulSetStatus{}
+	tc.Spec.Restore = &v1alpha1.TidbClusterRestoreSpec{
+		RestoreSpec: &v1alpha1.RestoreSpec{
+			StorageProvider: v1alpha1.StorageProvider{S3: &v1alpha1.S3StorageProvider{Bucket: "backup", Prefix: "prod"}},
+		},
+	}
+	setPDMembersInClusterDomain(tc, "")
+	setTiKVStoresUp(tc)
+	g.Expect(restorer.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.Restore.Phase).To(Equal(v1alpha1.TidbClusterRestoreSkipped))
+	restores, err := deps.Clientset.PingcapV1alpha1().Restores(tc.Namespace).List(metav1.ListOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(restores.Items).To(BeEmpty())
+
+	// the StatefulSets are created but the status is not updated yet
+	tc = newTidbClusterForRestore()
+	tc.Spec.Restore = &v1alpha1.TidbClusterRestoreSpec{Backup: "prod"}
+	set := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: controller.TiKVMemberName(tc.Name), Namespace: tc.Namespace}}
+	g.Expect(deps.KubeInformerFactory.Apps().V1().StatefulSets().Informer().GetIndexer().Add(set)).To(Succeed())
+	g.Expect(restorer.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.Restore.Phase).To(Equal(v1alpha1.TidbClusterRestoreSkipped))
+}
+
+func TestClusterRestorerConflictingCluster(t *testing.T) {
+	g := NewGomegaWithT(t)
+	deps := controller.NewFakeDependencies()
+	restorer := NewClusterRestorer(deps)
+
+	tc := newTidbClusterForRestore()
+	tc.Spec.Restore = &v1alpha1.TidbClusterRestoreSpec{
+		RestoreSpec: &v1alpha1.RestoreSpec{
+			StorageProvider: v1alpha1.StorageProvider{S3: &v1alpha1.S3StorageProvider{Bucket: "backup", Prefix: "prod"}},
+			BR:              &v1alpha1.BRConfig{Cluster: "prod", ClusterNamespace: tc.Namespace},
+		},
+	}
+	setPDMembersInClusterDomain(tc, "")
+	setTiKVStoresUp(tc)
+	g.Expect(restorer.Sync(tc)).NotTo(Succeed())
+	g.Expect(tc.Status.Restore.Phase).To(Equal(v1alpha1.TidbClusterRestorePending))
+	g.Expect(tc.Status.Restore.Message).To(ContainSubstring("restoreSpec.br.cluster prod"))
+
+	tc.Spec.Restore.RestoreSpec.BR.Cluster = tc.Name
+	g.Expect(restorer.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.Restore.Phase).To(Equal(v1alpha1.TidbClusterRestoreRunning))
+}
+
+func newTidbClusterForRestore() *v1alpha1.TidbCluster {
+	tc := newTidbClusterForPD()
+	tc.Spec.TiDB = nil
+	return tc
+}
+
+func setTiKVStoresUp(tc *v1alpha1.TidbCluster) {
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{}
+	for i := int32(0); i < tc.Spec.TiKV.Replicas; i++ {
+		id := fmt.Sprintf("%d", i+1)
+		tc.Status.TiKV.Stores[id] = v1alpha1.TiKVStore{ID: id, State: v1alpha1.TiKVStateUp}
+	}
+}
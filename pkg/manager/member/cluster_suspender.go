@@ -0,0 +1,196 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// ClusterSuspendReason is the reason of the events emitted by the cluster suspender
+	ClusterSuspendReason = "ClusterSuspend"
+)
+
+// clusterSuspender scales all the components of the TidbCluster to zero when `spec.suspend` is set, and
+// scales them back when it is unset, e.g. to shut down a non-production cluster overnight or on weekends.
+//
+// The components are suspended one at a time in the order of `v1alpha1.SuspendComponents`, the next one is
+// not suspended until all the pods of the current one are deleted. The replicas of the StatefulSets are
+// changed directly instead of by the scalers, so no PD member or store is deleted, and the PVCs are kept.
+// The suspended components are not synced by their member managers until they are resumed in the reverse
+// order with the replicas before the suspension, the next one is not resumed until the current one is ready.
+type clusterSuspender struct {
+	deps *controller.Dependencies
+}
+
+// NewClusterSuspender returns a cluster suspender
+func NewClusterSuspender(deps *controller.Dependencies) manager.Manager {
+	return &clusterSuspender{
+		deps: deps,
+	}
+}
+
+func (s *clusterSuspender) Sync(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.Suspend {
+		return s.suspend(tc)
+	}
+	if tc.Status.Suspend != nil {
+		return s.resume(tc)
+	}
+	return nil
+}
+
+func (s *clusterSuspender) suspend(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	status := tc.Status.Suspend
+	if status == nil {
+		status = &v1alpha1.SuspendStatus{}
+		tc.Status.Suspend = status
+		klog.Infof("tidbcluster: [%s/%s] begin to suspend the cluster", ns, tcName)
+		s.deps.Recorder.Event(tc, corev1.EventTypeNormal, ClusterSuspendReason, "begin to suspend the cluster")
+	}
+	if status.Phase == v1alpha1.SuspendPhaseSuspended {
+		return nil
+	}
+	status.Phase = v1alpha1.SuspendPhaseSuspending
+
+	for _, component := range v1alpha1.SuspendComponents {
+		if !componentInSpec(tc, component) {
+			continue
+		}
+		stsName := suspendStatefulSetName(tcName, component)
+		sts, err := s.deps.StatefulSetLister.StatefulSets(ns).Get(stsName)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("tidbcluster: [%s/%s] failed to get StatefulSet %s, error: %v", ns, tcName, stsName, err)
+		}
+		if !tc.ComponentSuspended(component) {
+			status.Components = append(status.Components, v1alpha1.SuspendedComponent{
+				Component: component,
+				Replicas:  *sts.Spec.Replicas,
+			})
+		}
+		if *sts.Spec.Replicas != 0 {
+			if err := s.setReplicas(tc, sts, 0); err != nil {
+				return err
+			}
+			klog.Infof("tidbcluster: [%s/%s] %s is scaled to zero for the suspension", ns, tcName, component)
+			s.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, ClusterSuspendReason, "%s is scaled to zero for the suspension", component)
+		}
+		if sts.Status.Replicas != 0 {
+			status.Message = fmt.Sprintf("waiting for the %s pods to be deleted", component)
+			return nil
+		}
+	}
+
+	now := metav1.Now()
+	status.Phase = v1alpha1.SuspendPhaseSuspended
+	status.Message = ""
+	status.SuspendTime = &now
+	klog.Infof("tidbcluster: [%s/%s] the cluster is suspended", ns, tcName)
+	s.deps.Recorder.Event(tc, corev1.EventTypeNormal, ClusterSuspendReason, "the cluster is suspended")
+	return nil
+}
+
+func (s *clusterSuspender) resume(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	status := tc.Status.Suspend
+	if status.Phase != v1alpha1.SuspendPhaseResuming {
+		status.Phase = v1alpha1.SuspendPhaseResuming
+		status.SuspendTime = nil
+		klog.Infof("tidbcluster: [%s/%s] begin to resume the cluster", ns, tcName)
+		s.deps.Recorder.Event(tc, corev1.EventTypeNormal, ClusterSuspendReason, "begin to resume the cluster")
+	}
+
+	for len(status.Components) > 0 {
+		last := status.Components[len(status.Components)-1]
+		stsName := suspendStatefulSetName(tcName, last.Component)
+		sts, err := s.deps.StatefulSetLister.StatefulSets(ns).Get(stsName)
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("tidbcluster: [%s/%s] failed to get StatefulSet %s, error: %v", ns, tcName, stsName, err)
+		}
+		if err == nil && componentInSpec(tc, last.Component) {
+			if *sts.Spec.Replicas != last.Replicas {
+				if err := s.setReplicas(tc, sts, last.Replicas); err != nil {
+					return err
+				}
+				klog.Infof("tidbcluster: [%s/%s] %s is scaled to %d replicas for the resumption", ns, tcName, last.Component, last.Replicas)
+			}
+			if sts.Status.ReadyReplicas != last.Replicas {
+				status.Message = fmt.Sprintf("waiting for the %s pods to be ready", last.Component)
+				return nil
+			}
+		}
+		// the component is synced by its member manager again
+		status.Components = status.Components[:len(status.Components)-1]
+		s.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, ClusterSuspendReason, "%s is resumed", last.Component)
+	}
+
+	tc.Status.Suspend = nil
+	klog.Infof("tidbcluster: [%s/%s] the cluster is resumed", ns, tcName)
+	s.deps.Recorder.Event(tc, corev1.EventTypeNormal, ClusterSuspendReason, "the cluster is resumed")
+	return nil
+}
+
+func (s *clusterSuspender) setReplicas(tc *v1alpha1.TidbCluster, sts *appsv1.StatefulSet, replicas int32) error {
+	newSts := sts.DeepCopy()
+	newSts.Spec.Replicas = pointer.Int32Ptr(replicas)
+	if _, err := s.deps.StatefulSetControl.UpdateStatefulSet(tc, newSts); err != nil {
+		return fmt.Errorf("tidbcluster: [%s/%s] failed to scale StatefulSet %s to %d replicas, error: %v", tc.GetNamespace(), tc.GetName(), sts.Name, replicas, err)
+	}
+	return nil
+}
+
+func suspendStatefulSetName(tcName string, memberType v1alpha1.MemberType) string {
+	switch memberType {
+	case v1alpha1.PDMemberType:
+		return controller.PDMemberName(tcName)
+	case v1alpha1.TiKVMemberType:
+		return controller.TiKVMemberName(tcName)
+	case v1alpha1.TiFlashMemberType:
+		return controller.TiFlashMemberName(tcName)
+	case v1alpha1.TiDBMemberType:
+		return controller.TiDBMemberName(tcName)
+	case v1alpha1.TiCDCMemberType:
+		return controller.TiCDCMemberName(tcName)
+	case v1alpha1.PumpMemberType:
+		return controller.PumpMemberName(tcName)
+	}
+	return ""
+}
+
+type fakeClusterSuspender struct{}
+
+// NewFakeClusterSuspender returns a fake cluster suspender
+func NewFakeClusterSuspender() manager.Manager {
+	return &fakeClusterSuspender{}
+}
+
+func (s *fakeClusterSuspender) Sync(_ *v1alpha1.TidbCluster) error {
+	return nil
+}
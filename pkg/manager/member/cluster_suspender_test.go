@@ -0,0 +1,110 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestClusterSuspenderSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+	deps := controller.NewFakeDependencies()
+	suspender := NewClusterSuspender(deps)
+	setIndexer := deps.KubeInformerFactory.Apps().V1().StatefulSets().Informer().GetIndexer()
+	tc := newTidbClusterForPD()
+	tc.Spec.TiDB.Replicas = 2
+	for _, set := range []*appsv1.StatefulSet{
+		newStatefulSetForSuspension(tc, controller.PDMemberName(tc.Name), 3),
+		newStatefulSetForSuspension(tc, controller.TiKVMemberName(tc.Name), 3),
+		newStatefulSetForSuspension(tc, controller.TiDBMemberName(tc.Name), 2),
+	} {
+		g.Expect(setIndexer.Add(set)).To(Succeed())
+	}
+	replicas := func(name string) int32 {
+		set, err := deps.StatefulSetLister.StatefulSets(tc.Namespace).Get(name)
+		g.Expect(err).NotTo(HaveOccurred())
+		return *set.Spec.Replicas
+	}
+	// setPods updates the status of the StatefulSet as if the pods are created or deleted for its replicas
+	setPods := func(name string) {
+		set, err := deps.StatefulSetLister.StatefulSets(tc.Namespace).Get(name)
+		g.Expect(err).NotTo(HaveOccurred())
+		set = set.DeepCopy()
+		set.Status.Replicas = *set.Spec.Replicas
+		set.Status.ReadyReplicas = *set.Spec.Replicas
+		g.Expect(setIndexer.Update(set)).To(Succeed())
+	}
+
+	g.Expect(suspender.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.Suspend).To(BeNil())
+
+	// TiDB is suspended first, TiKV is not suspended until the TiDB pods are deleted
+	tc.Spec.Suspend = true
+	g.Expect(suspender.Sync(tc)).To(Succeed())
+	status := tc.Status.Suspend
+	g.Expect(status.Phase).To(Equal(v1alpha1.SuspendPhaseSuspending))
+	g.Expect(status.Components).To(Equal([]v1alpha1.SuspendedComponent{{Component: v1alpha1.TiDBMemberType, Replicas: 2}}))
+	g.Expect(tc.ComponentSuspended(v1alpha1.TiDBMemberType)).To(BeTrue())
+	g.Expect(tc.ComponentSuspended(v1alpha1.TiKVMemberType)).To(BeFalse())
+	g.Expect(replicas(controller.TiDBMemberName(tc.Name))).To(Equal(int32(0)))
+	g.Expect(replicas(controller.TiKVMemberName(tc.Name))).To(Equal(int32(3)))
+
+	for _, name := range []string{controller.TiDBMemberName(tc.Name), controller.TiKVMemberName(tc.Name)} {
+		g.Expect(suspender.Sync(tc)).To(Succeed())
+		setPods(name)
+	}
+	g.Expect(suspender.Sync(tc)).To(Succeed())
+	setPods(controller.PDMemberName(tc.Name))
+	g.Expect(suspender.Sync(tc)).To(Succeed())
+	g.Expect(status.Phase).To(Equal(v1alpha1.SuspendPhaseSuspended))
+	g.Expect(status.SuspendTime).NotTo(BeNil())
+	g.Expect(status.Components).To(Equal([]v1alpha1.SuspendedComponent{
+		{Component: v1alpha1.TiDBMemberType, Replicas: 2},
+		{Component: v1alpha1.TiKVMemberType, Replicas: 3},
+		{Component: v1alpha1.PDMemberType, Replicas: 3},
+	}))
+
+	// PD is resumed first with the replicas before the suspension
+	tc.Spec.Suspend = false
+	tc.Spec.PD.Replicas = 5
+	g.Expect(suspender.Sync(tc)).To(Succeed())
+	g.Expect(status.Phase).To(Equal(v1alpha1.SuspendPhaseResuming))
+	g.Expect(replicas(controller.PDMemberName(tc.Name))).To(Equal(int32(3)))
+	g.Expect(replicas(controller.TiKVMemberName(tc.Name))).To(Equal(int32(0)))
+	g.Expect(tc.ComponentSuspended(v1alpha1.PDMemberType)).To(BeTrue())
+
+	for _, name := range []string{controller.PDMemberName(tc.Name), controller.TiKVMemberName(tc.Name), controller.TiDBMemberName(tc.Name)} {
+		setPods(name)
+		g.Expect(suspender.Sync(tc)).To(Succeed())
+	}
+	g.Expect(tc.Status.Suspend).To(BeNil())
+	set, err := deps.StatefulSetLister.StatefulSets(tc.Namespace).Get(controller.TiDBMemberName(tc.Name))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(set.Status.ReadyReplicas).To(Equal(int32(2)))
+}
+
+func newStatefulSetForSuspension(tc *v1alpha1.TidbCluster, name string, replicas int32) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: tc.Namespace},
+		Spec:       appsv1.StatefulSetSpec{Replicas: pointer.Int32Ptr(replicas)},
+		Status:     appsv1.StatefulSetStatus{Replicas: replicas, ReadyReplicas: replicas},
+	}
+}
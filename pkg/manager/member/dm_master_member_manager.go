@@ -116,8 +116,7 @@ func (m *masterMemberManager) syncMasterServiceForDMCluster(dc *v1alpha1.DMClust
 		for k, v := range newSvc.Annotations {
 			svc.Annotations[k] = v
 		}
-		_, err = m.deps.ServiceControl.UpdateService(dc, &svc)
-		return err
+		return updateService(m.deps.ServiceControl, dc, newSvc, &svc)
 	}
 
 	return nil
@@ -156,8 +155,7 @@ func (m *masterMemberManager) syncMasterHeadlessServiceForDMCluster(dc *v1alpha1
 		if err != nil {
 			return err
 		}
-		_, err = m.deps.ServiceControl.UpdateService(dc, &svc)
-		return err
+		return updateService(m.deps.ServiceControl, dc, newSvc, &svc)
 	}
 
 	return nil
@@ -704,6 +702,7 @@ func getNewMasterSetForDMCluster(dc *v1alpha1.DMCluster, cm *corev1.ConfigMap) (
 				}},
 		},
 	}
+	setVolumeClaimTemplatesAnnotations(masterSet, baseMasterSpec.PVCAnnotations())
 
 	return masterSet, nil
 }
@@ -15,6 +15,7 @@ package member
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
@@ -24,6 +25,11 @@ import (
 	"k8s.io/klog"
 )
 
+const (
+	workerSourceReboundReason      = "SourceRebound"
+	workerSourceRebindFailedReason = "SourceRebindFailed"
+)
+
 type workerFailover struct {
 	deps *controller.Dependencies
 }
@@ -68,6 +74,7 @@ func (f *workerFailover) Failover(dc *v1alpha1.DMCluster) error {
 				dc.Status.Worker.FailureMembers[podName] = v1alpha1.WorkerFailureMember{
 					PodName:   podName,
 					CreatedAt: metav1.Now(),
+					Source:    worker.Source,
 				}
 				msg := fmt.Sprintf("worker[%s/%s] is Offline", ns, worker.Name)
 				f.deps.Recorder.Event(dc, corev1.EventTypeWarning, unHealthEventReason, fmt.Sprintf(unHealthEventMsgPattern, "worker", podName, msg))
@@ -75,6 +82,75 @@ func (f *workerFailover) Failover(dc *v1alpha1.DMCluster) error {
 		}
 	}
 
+	return f.rebindSources(dc)
+}
+
+// rebindSources binds the sources of the failure workers to the free workers, e.g. the replacement
+// workers created by the failover, via dm-master, so that the migration tasks of the sources are
+// resumed without manual intervention.
+// If dm-master has already rebound a source to another worker by itself, it is only recorded.
+func (f *workerFailover) rebindSources(dc *v1alpha1.DMCluster) error {
+	var pending []string
+	for key, failureWorker := range dc.Status.Worker.FailureMembers {
+		if failureWorker.Source != "" && failureWorker.SourceReboundTo == "" {
+			pending = append(pending, key)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	sort.Strings(pending)
+
+	ns := dc.GetNamespace()
+	dcName := dc.GetName()
+	dmClient := controller.GetMasterClient(f.deps.DMMasterControl, dc)
+	workers, err := dmClient.GetWorkers()
+	if err != nil {
+		return fmt.Errorf("rebindSources: failed to get workers of dc %s/%s, error: %v", ns, dcName, err)
+	}
+	boundWorkers := map[string]string{}
+	var freeWorkers []string
+	for _, worker := range workers {
+		switch worker.Stage {
+		case v1alpha1.DMWorkerStateBound:
+			boundWorkers[worker.Source] = worker.Name
+		case v1alpha1.DMWorkerStateFree:
+			freeWorkers = append(freeWorkers, worker.Name)
+		}
+	}
+	sort.Strings(freeWorkers)
+
+	for _, key := range pending {
+		failureWorker := dc.Status.Worker.FailureMembers[key]
+		if worker, ok := boundWorkers[failureWorker.Source]; ok {
+			if worker == failureWorker.PodName {
+				// the failure worker is back, it is cleaned up by the recovery of failover
+				continue
+			}
+			failureWorker.SourceReboundTo = worker
+		} else if len(freeWorkers) > 0 {
+			worker := freeWorkers[0]
+			// do not block syncing the replacement workers, the transfer is retried in the next sync
+			if err := dmClient.TransferSource(failureWorker.Source, worker); err != nil {
+				klog.Warningf("rebindSources: failed to transfer source %s of failure worker %s to worker %s for dc %s/%s, error: %v",
+					failureWorker.Source, failureWorker.PodName, worker, ns, dcName, err)
+				f.deps.Recorder.Eventf(dc, corev1.EventTypeWarning, workerSourceRebindFailedReason, "failed to rebind source %s of worker %s to worker %s: %v",
+					failureWorker.Source, failureWorker.PodName, worker, err)
+				continue
+			}
+			freeWorkers = freeWorkers[1:]
+			failureWorker.SourceReboundTo = worker
+		} else {
+			klog.Infof("rebindSources: no free worker for source %s of failure worker %s of dc %s/%s, waiting for the replacement worker",
+				failureWorker.Source, failureWorker.PodName, ns, dcName)
+			continue
+		}
+		dc.Status.Worker.FailureMembers[key] = failureWorker
+		klog.Infof("rebindSources: source %s of failure worker %s is rebound to worker %s for dc %s/%s",
+			failureWorker.Source, failureWorker.PodName, failureWorker.SourceReboundTo, ns, dcName)
+		f.deps.Recorder.Eventf(dc, corev1.EventTypeNormal, workerSourceReboundReason, "source %s of worker %s is rebound to worker %s",
+			failureWorker.Source, failureWorker.PodName, failureWorker.SourceReboundTo)
+	}
 	return nil
 }
 
@@ -14,12 +14,14 @@
 package member
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/dmapi"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 )
@@ -291,3 +293,85 @@ func TestWorkerFailoverFailover(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkerFailoverRebindSources(t *testing.T) {
+	tests := []struct {
+		name           string
+		workers        []*dmapi.WorkersInfo
+		transferErr    error
+		expectRebound  string
+		expectTransfer bool
+	}{
+		{
+			name: "transfer source to the free worker",
+			workers: []*dmapi.WorkersInfo{
+				{Name: "dm-worker-1", Stage: v1alpha1.DMWorkerStateOffline},
+				{Name: "dm-worker-3", Stage: v1alpha1.DMWorkerStateFree},
+			},
+			expectRebound:  "dm-worker-3",
+			expectTransfer: true,
+		},
+		{
+			name: "source is rebound by dm-master",
+			workers: []*dmapi.WorkersInfo{
+				{Name: "dm-worker-1", Stage: v1alpha1.DMWorkerStateOffline},
+				{Name: "dm-worker-2", Stage: v1alpha1.DMWorkerStateBound, Source: "mysql-replica-01"},
+				{Name: "dm-worker-3", Stage: v1alpha1.DMWorkerStateFree},
+			},
+			expectRebound: "dm-worker-2",
+		},
+		{
+			name: "no free worker",
+			workers: []*dmapi.WorkersInfo{
+				{Name: "dm-worker-1", Stage: v1alpha1.DMWorkerStateOffline},
+			},
+		},
+		{
+			name: "failed to transfer source",
+			workers: []*dmapi.WorkersInfo{
+				{Name: "dm-worker-1", Stage: v1alpha1.DMWorkerStateOffline},
+				{Name: "dm-worker-3", Stage: v1alpha1.DMWorkerStateFree},
+			},
+			transferErr:    fmt.Errorf("openapi is not enabled"),
+			expectTransfer: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			dc := newDMClusterForMaster()
+			dc.Spec.Worker.Replicas = 3
+			dc.Spec.Worker.MaxFailoverCount = pointer.Int32Ptr(3)
+			dc.Status.Worker.Members = map[string]v1alpha1.WorkerMember{
+				"dm-worker-1": {
+					Stage:              v1alpha1.DMWorkerStateOffline,
+					Name:               "dm-worker-1",
+					Source:             "mysql-replica-01",
+					LastTransitionTime: metav1.Time{Time: time.Now().Add(-70 * time.Minute)},
+				},
+			}
+
+			fakeDeps := controller.NewFakeDependencies()
+			fakeDeps.CLIConfig.WorkerFailoverPeriod = 1 * time.Hour
+			masterClient := controller.NewFakeMasterClient(fakeDeps.DMMasterControl.(*dmapi.FakeMasterControl), dc)
+			masterClient.AddReaction(dmapi.GetWorkersActionType, func(action *dmapi.Action) (interface{}, error) {
+				return tt.workers, nil
+			})
+			transferred := false
+			masterClient.AddReaction(dmapi.TransferSourceActionType, func(action *dmapi.Action) (interface{}, error) {
+				transferred = true
+				g.Expect(action.Source).To(Equal("mysql-replica-01"))
+				g.Expect(action.Name).To(Equal("dm-worker-3"))
+				return nil, tt.transferErr
+			})
+			workerFailover := &workerFailover{deps: fakeDeps}
+
+			err := workerFailover.Failover(dc)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(transferred).To(Equal(tt.expectTransfer))
+			failureWorker := dc.Status.Worker.FailureMembers["dm-worker-1"]
+			g.Expect(failureWorker.Source).To(Equal("mysql-replica-01"))
+			g.Expect(failureWorker.SourceReboundTo).To(Equal(tt.expectRebound))
+		})
+	}
+}
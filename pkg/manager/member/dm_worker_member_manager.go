@@ -110,8 +110,7 @@ func (m *workerMemberManager) syncWorkerHeadlessServiceForDMCluster(dc *v1alpha1
 		if err != nil {
 			return err
 		}
-		_, err = m.deps.ServiceControl.UpdateService(dc, &svc)
-		return err
+		return updateService(m.deps.ServiceControl, dc, newSvc, &svc)
 	}
 
 	return nil
@@ -252,12 +251,17 @@ func (m *workerMemberManager) syncDMClusterStatus(dc *v1alpha1.DMCluster, set *a
 	for _, worker := range workersInfo {
 		name := worker.Name
 		status := v1alpha1.WorkerMember{
-			Name:  name,
-			Addr:  worker.Addr,
-			Stage: worker.Stage,
+			Name:   name,
+			Addr:   worker.Addr,
+			Stage:  worker.Stage,
+			Source: worker.Source,
 		}
 
 		oldWorkerMember, exist := dc.Status.Worker.Members[name]
+		// dm-master unbinds the source of the offline worker, keep the last bound source for failover
+		if exist && status.Stage == v1alpha1.DMWorkerStateOffline && status.Source == "" {
+			status.Source = oldWorkerMember.Source
+		}
 
 		status.LastTransitionTime = metav1.Now()
 		if exist && status.Stage == oldWorkerMember.Stage {
@@ -512,6 +516,7 @@ func getNewWorkerSetForDMCluster(dc *v1alpha1.DMCluster, cm *corev1.ConfigMap) (
 			},
 		},
 	}
+	setVolumeClaimTemplatesAnnotations(workerSet, baseWorkerSpec.PVCAnnotations())
 
 	return workerSet, nil
 }
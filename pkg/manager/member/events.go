@@ -0,0 +1,70 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Reasons of the events recorded on the TidbCluster and the DMCluster
+const (
+	// unHealthEventReason is a universal reason for the unhealthy members of all components
+	unHealthEventReason     = "Unhealthy"
+	unHealthEventMsgPattern = "%s pod[%s] is unhealthy, msg:%s"
+	FailedSetStoreLabels    = "FailedSetStoreLabels"
+	// failoverRecoveredEventReason is the reason of the events emitted when the failover replicas are removed
+	failoverRecoveredEventReason = "FailoverRecovered"
+
+	// ScaleStartedReason is recorded when a component begins to scale out or scale in
+	ScaleStartedReason = "ScaleStarted"
+	// ScaleCompletedReason is recorded when the replicas of a component reach the desired replicas
+	ScaleCompletedReason = "ScaleCompleted"
+	// UpgradeStartedReason is recorded when a component begins to be rolling updated
+	UpgradeStartedReason = "UpgradeStarted"
+	// UpgradePodReason is recorded when a pod of a component is going to be upgraded
+	UpgradePodReason = "UpgradePod"
+	// UpgradeCompletedReason is recorded when all pods of a component are upgraded
+	UpgradeCompletedReason = "UpgradeCompleted"
+	// LeaderTransferReason is recorded when the pd leader is transferred to another member
+	LeaderTransferReason = "LeaderTransfer"
+	// LeaderEvictionReason is recorded when the region leaders are being evicted from a tikv store
+	LeaderEvictionReason = "LeaderEviction"
+	// ConfigRolloutStartedReason is recorded when the config of a component is changed and being rolled out
+	ConfigRolloutStartedReason = "ConfigRolloutStarted"
+	// ConfigRolloutCompletedReason is recorded when all pods of a component use the latest config
+	ConfigRolloutCompletedReason = "ConfigRolloutCompleted"
+)
+
+// recordPodUpgrade records the upgrade of the pod with the ordinal, it must be called before the partition
+// of the statefulset is lowered to the ordinal, so the upgrade of each pod is recorded only once
+func recordPodUpgrade(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, set *apps.StatefulSet, status *apps.StatefulSetStatus, ordinal int32) {
+	if set.Spec.UpdateStrategy.RollingUpdate != nil && set.Spec.UpdateStrategy.RollingUpdate.Partition != nil &&
+		*set.Spec.UpdateStrategy.RollingUpdate.Partition <= ordinal {
+		return
+	}
+	var updated, replicas int32
+	if status != nil {
+		updated, replicas = status.UpdatedReplicas, status.Replicas
+	}
+	deps.Recorder.Eventf(tc, corev1.EventTypeNormal, UpgradePodReason, "upgrading %s pod %s-%d, %d of %d pods are upgraded",
+		memberType, set.Name, ordinal, updated, replicas)
+}
+
+// recordPDLeaderTransfer records the transfer of the pd leader before the member is upgraded or deleted
+func recordPDLeaderTransfer(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, from, to, operation string) {
+	deps.Recorder.Eventf(tc, corev1.EventTypeNormal, LeaderTransferReason, "transferring pd leader from %s to %s before %s", from, to, operation)
+}
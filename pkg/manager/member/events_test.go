@@ -0,0 +1,52 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	apps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+func TestRecordPodUpgrade(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	recorder := record.NewFakeRecorder(10)
+	deps.Recorder = recorder
+	tc := &v1alpha1.TidbCluster{}
+	set := &apps.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo-tikv"},
+		Spec: apps.StatefulSetSpec{
+			UpdateStrategy: apps.StatefulSetUpdateStrategy{
+				RollingUpdate: &apps.RollingUpdateStatefulSetStrategy{Partition: pointer.Int32Ptr(2)},
+			},
+		},
+	}
+	status := &apps.StatefulSetStatus{Replicas: 3, UpdatedReplicas: 1}
+
+	recordPodUpgrade(deps, tc, v1alpha1.TiKVMemberType, set, status, 1)
+	g.Expect(recorder.Events).To(Receive(Equal("Normal UpgradePod upgrading tikv pod demo-tikv-1, 1 of 3 pods are upgraded")))
+
+	// the pod is being upgraded since the partition is already lowered to its ordinal
+	set.Spec.UpdateStrategy.RollingUpdate.Partition = pointer.Int32Ptr(1)
+	recordPodUpgrade(deps, tc, v1alpha1.TiKVMemberType, set, status, 1)
+	g.Expect(recorder.Events).NotTo(Receive())
+}
@@ -13,15 +13,16 @@
 
 package member
 
-import "github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
-
-// TODO: move this to a centralized place
-// Since the "Unhealthy" is a very universal event reason string, which could apply to all the TiDB/DM cluster components,
-// we should make a global event module, and put event related constants there.
-const (
-	unHealthEventReason     = "Unhealthy"
-	unHealthEventMsgPattern = "%s pod[%s] is unhealthy, msg:%s"
-	FailedSetStoreLabels    = "FailedSetStoreLabels"
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 // Failover implements the logic for pd/tikv/tidb's failover and recovery.
@@ -37,3 +38,101 @@ type DMFailover interface {
 	Recover(*v1alpha1.DMCluster)
 	RemoveUndesiredFailures(*v1alpha1.DMCluster)
 }
+
+// notifyFailover notifies the failover action taken on a member of the TidbCluster by the failover notifier,
+// the events of the action are recorded by the callers with their own reasons.
+// No action is taken if the failover is simulated, so only the simulated failovers are notified then.
+func notifyFailover(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, n controller.FailoverNotification) {
+	if tc.Spec.FailoverSimulation && n.Action != controller.FailoverSimulated {
+		return
+	}
+	n.Namespace = tc.GetNamespace()
+	n.Cluster = tc.GetName()
+	n.Time = metav1.Now()
+	deps.FailoverNotifier.Notify(n)
+}
+
+// recordFailoverEvent records an event of the failover of the TidbCluster,
+// nothing is recorded if the failover is simulated as no action is taken
+func recordFailoverEvent(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, eventType, reason, messageFmt string, args ...interface{}) {
+	if tc.Spec.FailoverSimulation {
+		return
+	}
+	deps.Recorder.Eventf(tc, eventType, reason, messageFmt, args...)
+}
+
+// notifyStoresRecovered notifies the recovery of the failure stores of TiKV or TiFlash in the order of store IDs
+func notifyStoresRecovered(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, failureStores map[string]v1alpha1.TiKVFailureStore) {
+	storeIDs := make([]string, 0, len(failureStores))
+	for storeID := range failureStores {
+		storeIDs = append(storeIDs, storeID)
+	}
+	sort.Strings(storeIDs)
+	for _, storeID := range storeIDs {
+		failureStore := failureStores[storeID]
+		notifyFailover(deps, tc, controller.FailoverNotification{
+			Component: memberType.String(),
+			Action:    controller.FailoverRecovered,
+			PodName:   failureStore.PodName,
+			StoreID:   failureStore.StoreID,
+			Reason:    "store is recovered, removing the failover replica",
+		})
+	}
+}
+
+// failoverRecoveryDue checks whether the failover replicas should be removed according to the recovery
+// policy of the component, given that all the desired members have been healthy since healthySince.
+// If the policy is not set, the failover replicas are removed right away if autoByDefault is true,
+// otherwise only if recoverFailover is set.
+func failoverRecoveryDue(policy *v1alpha1.FailoverRecoveryPolicy, recoverFailover, autoByDefault bool, healthySince time.Time) bool {
+	if recoverFailover {
+		return true
+	}
+	if policy == nil {
+		return autoByDefault
+	}
+	if policy.Mode != v1alpha1.FailoverRecoveryAuto {
+		return false
+	}
+	return time.Since(healthySince) >= policy.GetHealthyPeriod()
+}
+
+// tikvHealthySince returns the last transition time of the stores of the desired TiKV pods,
+// since which all of them are Up if shouldRecover returns true
+func tikvHealthySince(tc *v1alpha1.TidbCluster) time.Time {
+	var since time.Time
+	ordinals := tc.TiKVStsDesiredOrdinals(true)
+	for _, store := range tc.Status.TiKV.Stores {
+		if !isDesiredPod(ordinals, store.PodName) {
+			continue
+		}
+		if store.LastTransitionTime.Time.After(since) {
+			since = store.LastTransitionTime.Time
+		}
+	}
+	return since
+}
+
+// pdHealthySince returns the last transition time of the members of the desired PD pods,
+// since which all of them are healthy if shouldRecover returns true
+func pdHealthySince(tc *v1alpha1.TidbCluster) time.Time {
+	var since time.Time
+	ordinals := tc.PDStsDesiredOrdinals(true)
+	for pdName, member := range tc.Status.PD.Members {
+		if !isDesiredPod(ordinals, strings.Split(pdName, ".")[0]) {
+			continue
+		}
+		if member.LastTransitionTime.Time.After(since) {
+			since = member.LastTransitionTime.Time
+		}
+	}
+	return since
+}
+
+func isDesiredPod(ordinals sets.Int32, podName string) bool {
+	ordinal, err := util.GetOrdinalFromPodName(podName)
+	if err != nil {
+		return false
+	}
+	return ordinals.Has(ordinal)
+}
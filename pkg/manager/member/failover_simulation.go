@@ -0,0 +1,267 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+)
+
+// simulatedFailover wraps the Failover of a component for `spec.failoverSimulation`.
+//
+// If the simulation is enabled, the failover is performed on a copy of the TidbCluster, the pods it
+// would declare failed are recorded in `status.simulatedFailovers` and notified with the action
+// FailoverSimulated, while the failure members of the TidbCluster are left untouched, so that no
+// failover replica is created or removed. The pods simulated failed in the previous syncs are added
+// to the copy as failure members, so the simulation goes on as if the failover had been performed,
+// e.g. it stops at maxFailoverCount.
+type simulatedFailover struct {
+	deps       *controller.Dependencies
+	memberType v1alpha1.MemberType
+	failover   Failover
+}
+
+func newSimulatedFailover(deps *controller.Dependencies, memberType v1alpha1.MemberType, failover Failover) Failover {
+	return &simulatedFailover{
+		deps:       deps,
+		memberType: memberType,
+		failover:   failover,
+	}
+}
+
+func (f *simulatedFailover) Failover(tc *v1alpha1.TidbCluster) error {
+	if !tc.Spec.FailoverSimulation {
+		return f.failover.Failover(tc)
+	}
+
+	previous := tc.Status.SimulatedFailovers[f.memberType]
+	simulated := tc.DeepCopy()
+	addSimulatedFailurePods(simulated, f.memberType, previous.FailurePods)
+	existing := getFailurePods(simulated, f.memberType)
+	// the requeue error only means a member is marked as failed
+	if err := f.failover.Failover(simulated); err != nil && !controller.IsRequeueError(err) {
+		return err
+	}
+	failurePods := getFailurePods(simulated, f.memberType).Difference(existing)
+	if failurePods.Len() == 0 {
+		return nil
+	}
+
+	record := v1alpha1.SimulatedFailover{
+		FailurePods:        sets.NewString(previous.FailurePods...).Union(failurePods).List(),
+		LastTransitionTime: metav1.Now(),
+	}
+	record.FailoverReplicas = int32(len(record.FailurePods))
+	record.Replicas = getStsDesiredReplicas(tc, f.memberType) + record.FailoverReplicas
+	if tc.Status.SimulatedFailovers == nil {
+		tc.Status.SimulatedFailovers = map[v1alpha1.MemberType]v1alpha1.SimulatedFailover{}
+	}
+	tc.Status.SimulatedFailovers[f.memberType] = record
+
+	for _, podName := range failurePods.List() {
+		n := controller.FailoverNotification{
+			Component: f.memberType.String(),
+			Action:    controller.FailoverSimulated,
+			PodName:   podName,
+			Reason: fmt.Sprintf("member would be declared failed and a failover replica would be created, failover replicas %d, replicas %d",
+				record.FailoverReplicas, record.Replicas),
+		}
+		f.deps.Recorder.Event(tc, corev1.EventTypeNormal, string(n.Action), n.Message())
+		notifyFailover(f.deps, tc, n)
+	}
+	return nil
+}
+
+func (f *simulatedFailover) Recover(tc *v1alpha1.TidbCluster) {
+	if tc.Spec.FailoverSimulation {
+		klog.Infof("%s failover of %s/%s is simulated, skip recovering the failure members", f.memberType, tc.GetNamespace(), tc.GetName())
+		return
+	}
+	f.failover.Recover(tc)
+}
+
+func (f *simulatedFailover) RemoveUndesiredFailures(tc *v1alpha1.TidbCluster) {
+	f.failover.RemoveUndesiredFailures(tc)
+}
+
+// PruneSimulatedFailovers removes the pods which are healthy again or not desired any more from the
+// results of the failover simulation, all the results are removed if the simulation is disabled.
+func PruneSimulatedFailovers(tc *v1alpha1.TidbCluster) {
+	if !tc.Spec.FailoverSimulation {
+		tc.Status.SimulatedFailovers = nil
+		return
+	}
+	for memberType, record := range tc.Status.SimulatedFailovers {
+		var failurePods []string
+		for _, podName := range record.FailurePods {
+			if isSimulatedFailurePod(tc, memberType, podName) {
+				failurePods = append(failurePods, podName)
+			}
+		}
+		if len(failurePods) == 0 {
+			delete(tc.Status.SimulatedFailovers, memberType)
+			continue
+		}
+		if len(failurePods) == len(record.FailurePods) {
+			continue
+		}
+		record.FailurePods = failurePods
+		record.FailoverReplicas = int32(len(failurePods))
+		record.Replicas = getStsDesiredReplicas(tc, memberType) + record.FailoverReplicas
+		record.LastTransitionTime = metav1.Now()
+		tc.Status.SimulatedFailovers[memberType] = record
+	}
+	if len(tc.Status.SimulatedFailovers) == 0 {
+		tc.Status.SimulatedFailovers = nil
+	}
+}
+
+// isSimulatedFailurePod returns whether the pod simulated failed is still desired and unhealthy
+func isSimulatedFailurePod(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, podName string) bool {
+	switch memberType {
+	case v1alpha1.PDMemberType:
+		if !isDesiredPod(tc.PDStsDesiredOrdinals(true), podName) {
+			return false
+		}
+		for pdName, member := range tc.Status.PD.Members {
+			if strings.Split(pdName, ".")[0] == podName {
+				return !member.Health
+			}
+		}
+	case v1alpha1.TiKVMemberType:
+		if !isDesiredPod(tc.TiKVStsDesiredOrdinals(true), podName) {
+			return false
+		}
+		for _, store := range tc.Status.TiKV.Stores {
+			if store.PodName == podName {
+				return store.State != v1alpha1.TiKVStateUp
+			}
+		}
+	case v1alpha1.TiFlashMemberType:
+		if !isDesiredPod(tc.TiFlashStsDesiredOrdinals(true), podName) {
+			return false
+		}
+		for _, store := range tc.Status.TiFlash.Stores {
+			if store.PodName == podName {
+				return store.State != v1alpha1.TiKVStateUp
+			}
+		}
+	case v1alpha1.TiDBMemberType:
+		if member, ok := tc.Status.TiDB.Members[podName]; ok {
+			return !member.Health
+		}
+	}
+	return true
+}
+
+// getFailurePods returns the pods of the failure members or stores of the component
+func getFailurePods(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) sets.String {
+	pods := sets.NewString()
+	switch memberType {
+	case v1alpha1.PDMemberType:
+		for _, failureMember := range tc.Status.PD.FailureMembers {
+			pods.Insert(failureMember.PodName)
+		}
+	case v1alpha1.TiKVMemberType:
+		for _, failureStore := range tc.Status.TiKV.FailureStores {
+			pods.Insert(failureStore.PodName)
+		}
+	case v1alpha1.TiFlashMemberType:
+		for _, failureStore := range tc.Status.TiFlash.FailureStores {
+			pods.Insert(failureStore.PodName)
+		}
+	case v1alpha1.TiDBMemberType:
+		for _, failureMember := range tc.Status.TiDB.FailureMembers {
+			pods.Insert(failureMember.PodName)
+		}
+	}
+	return pods
+}
+
+// addSimulatedFailurePods adds the pods simulated failed as the failure members or stores of the
+// component, as if their failover replicas had been created
+func addSimulatedFailurePods(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, podNames []string) {
+	if len(podNames) == 0 {
+		return
+	}
+	failurePods := getFailurePods(tc, memberType)
+	for _, podName := range podNames {
+		if failurePods.Has(podName) {
+			continue
+		}
+		switch memberType {
+		case v1alpha1.PDMemberType:
+			for pdName, member := range tc.Status.PD.Members {
+				if strings.Split(pdName, ".")[0] != podName {
+					continue
+				}
+				if tc.Status.PD.FailureMembers == nil {
+					tc.Status.PD.FailureMembers = map[string]v1alpha1.PDFailureMember{}
+				}
+				tc.Status.PD.FailureMembers[pdName] = v1alpha1.PDFailureMember{
+					PodName:       podName,
+					MemberID:      member.ID,
+					MemberDeleted: true,
+				}
+			}
+		case v1alpha1.TiKVMemberType:
+			for storeID, store := range tc.Status.TiKV.Stores {
+				if store.PodName != podName {
+					continue
+				}
+				if tc.Status.TiKV.FailureStores == nil {
+					tc.Status.TiKV.FailureStores = map[string]v1alpha1.TiKVFailureStore{}
+				}
+				tc.Status.TiKV.FailureStores[storeID] = v1alpha1.TiKVFailureStore{PodName: podName, StoreID: store.ID}
+			}
+		case v1alpha1.TiFlashMemberType:
+			for storeID, store := range tc.Status.TiFlash.Stores {
+				if store.PodName != podName {
+					continue
+				}
+				if tc.Status.TiFlash.FailureStores == nil {
+					tc.Status.TiFlash.FailureStores = map[string]v1alpha1.TiKVFailureStore{}
+				}
+				tc.Status.TiFlash.FailureStores[storeID] = v1alpha1.TiKVFailureStore{PodName: podName, StoreID: store.ID}
+			}
+		case v1alpha1.TiDBMemberType:
+			if tc.Status.TiDB.FailureMembers == nil {
+				tc.Status.TiDB.FailureMembers = map[string]v1alpha1.TiDBFailureMember{}
+			}
+			tc.Status.TiDB.FailureMembers[podName] = v1alpha1.TiDBFailureMember{PodName: podName}
+		}
+	}
+}
+
+// getStsDesiredReplicas returns the desired replicas of the StatefulSet of the component
+func getStsDesiredReplicas(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) int32 {
+	switch memberType {
+	case v1alpha1.PDMemberType:
+		return tc.PDStsDesiredReplicas()
+	case v1alpha1.TiKVMemberType:
+		return tc.TiKVStsDesiredReplicas()
+	case v1alpha1.TiFlashMemberType:
+		return tc.TiFlashStsDesiredReplicas()
+	case v1alpha1.TiDBMemberType:
+		return tc.TiDBStsDesiredReplicas()
+	}
+	return 0
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+)
+
+func TestSimulatedTiKVFailover(t *testing.T) {
+	g := NewGomegaWithT(t)
+	tc := newTidbClusterForPD()
+	tc.Spec.FailoverSimulation = true
+	tc.Spec.TiKV.Replicas = 3
+	tc.Spec.TiKV.MaxFailoverCount = pointer.Int32Ptr(2)
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{}
+	for i := 0; i < 3; i++ {
+		id := fmt.Sprintf("%d", i+1)
+		tc.Status.TiKV.Stores[id] = v1alpha1.TiKVStore{
+			ID:                 id,
+			State:              v1alpha1.TiKVStateDown,
+			PodName:            fmt.Sprintf("tikv-%d", i),
+			LastTransitionTime: metav1.Time{Time: time.Now().Add(-70 * time.Minute)},
+		}
+	}
+
+	fakeDeps := controller.NewFakeDependencies()
+	fakeDeps.CLIConfig.TiKVFailoverPeriod = 1 * time.Hour
+	recorder := record.NewFakeRecorder(10)
+	fakeDeps.Recorder = recorder
+	notifier := fakeDeps.FailoverNotifier.(*controller.FakeFailoverNotifier)
+	failover := NewTiKVFailover(fakeDeps)
+
+	// the failover stops at maxFailoverCount, no failure store is added
+	g.Expect(failover.Failover(tc)).To(Succeed())
+	g.Expect(tc.Status.TiKV.FailureStores).To(BeEmpty())
+	simulated := tc.Status.SimulatedFailovers[v1alpha1.TiKVMemberType]
+	g.Expect(simulated.FailurePods).To(HaveLen(2))
+	g.Expect(simulated.FailoverReplicas).To(Equal(int32(2)))
+	g.Expect(simulated.Replicas).To(Equal(int32(5)))
+	notifications := notifier.Notifications()
+	g.Expect(notifications).To(HaveLen(2))
+	for _, n := range notifications {
+		g.Expect(n.Action).To(Equal(controller.FailoverSimulated))
+	}
+	g.Expect(collectEvents(recorder.Events)).To(HaveLen(2))
+
+	// the pods simulated failed are not notified again
+	g.Expect(failover.Failover(tc)).To(Succeed())
+	g.Expect(notifier.Notifications()).To(HaveLen(2))
+	g.Expect(tc.Status.SimulatedFailovers[v1alpha1.TiKVMemberType].FailurePods).To(Equal(simulated.FailurePods))
+
+	// the pod healthy again is removed from the results
+	healthyPod := simulated.FailurePods[0]
+	for id, store := range tc.Status.TiKV.Stores {
+		if store.PodName == healthyPod {
+			store.State = v1alpha1.TiKVStateUp
+			tc.Status.TiKV.Stores[id] = store
+		}
+	}
+	PruneSimulatedFailovers(tc)
+	g.Expect(tc.Status.SimulatedFailovers[v1alpha1.TiKVMemberType].FailurePods).To(Equal(simulated.FailurePods[1:]))
+	g.Expect(tc.Status.SimulatedFailovers[v1alpha1.TiKVMemberType].Replicas).To(Equal(int32(4)))
+
+	// the results are removed when the simulation is disabled
+	tc.Spec.FailoverSimulation = false
+	PruneSimulatedFailovers(tc)
+	g.Expect(tc.Status.SimulatedFailovers).To(BeNil())
+}
+
+func TestSimulatedPDFailover(t *testing.T) {
+	g := NewGomegaWithT(t)
+	tc := newTidbClusterForPD()
+	tc.Spec.FailoverSimulation = true
+	tc.Spec.PD.MaxFailoverCount = pointer.Int32Ptr(3)
+	tc.Status.PD.Synced = true
+	oneNotReadyMemberAndAFailureMember(tc)
+
+	fakeDeps := controller.NewFakeDependencies()
+	fakeDeps.CLIConfig.PDFailoverPeriod = 5 * time.Minute
+	pdClient := controller.NewFakePDClient(fakeDeps.PDControl.(*pdapi.FakePDControl), tc)
+	deleted := false
+	pdClient.AddReaction(pdapi.DeleteMemberByIDActionType, func(action *pdapi.Action) (interface{}, error) {
+		deleted = true
+		return nil, nil
+	})
+	failover := NewPDFailover(fakeDeps)
+
+	// the failure member which is not deleted yet is kept as is
+	g.Expect(failover.Failover(tc)).To(Succeed())
+	g.Expect(deleted).To(BeFalse())
+	g.Expect(tc.Status.PD.FailureMembers).To(HaveLen(1))
+	for _, failureMember := range tc.Status.PD.FailureMembers {
+		g.Expect(failureMember.MemberDeleted).To(BeFalse())
+	}
+	g.Expect(tc.Status.SimulatedFailovers).To(BeEmpty())
+
+	// the failure members are not recovered
+	failover.Recover(tc)
+	g.Expect(tc.Status.PD.FailureMembers).To(HaveLen(1))
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFailoverRecoveryDue(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	auto := &v1alpha1.FailoverRecoveryPolicy{Mode: v1alpha1.FailoverRecoveryAuto}
+	autoIn1h := &v1alpha1.FailoverRecoveryPolicy{Mode: v1alpha1.FailoverRecoveryAuto, HealthyPeriod: &metav1.Duration{Duration: time.Hour}}
+	manual := &v1alpha1.FailoverRecoveryPolicy{Mode: v1alpha1.FailoverRecoveryManual}
+	healthyFor30m := time.Now().Add(-30 * time.Minute)
+	healthyFor1m := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name            string
+		policy          *v1alpha1.FailoverRecoveryPolicy
+		recoverFailover bool
+		autoByDefault   bool
+		healthySince    time.Time
+		expected        bool
+	}{
+		{name: "no policy, auto by default", autoByDefault: true, healthySince: healthyFor1m, expected: true},
+		{name: "no policy, manual by default", healthySince: healthyFor30m, expected: false},
+		{name: "no policy, recoverFailover is set", recoverFailover: true, healthySince: healthyFor1m, expected: true},
+		{name: "manual", policy: manual, autoByDefault: true, healthySince: healthyFor30m, expected: false},
+		{name: "manual, recoverFailover is set", policy: manual, recoverFailover: true, healthySince: healthyFor1m, expected: true},
+		{name: "auto, healthy for the default period", policy: auto, healthySince: healthyFor30m, expected: true},
+		{name: "auto, not healthy for the default period", policy: auto, autoByDefault: true, healthySince: healthyFor1m, expected: false},
+		{name: "auto, not healthy for the healthy period", policy: autoIn1h, healthySince: healthyFor30m, expected: false},
+		{name: "auto, recoverFailover is set", policy: autoIn1h, recoverFailover: true, healthySince: healthyFor1m, expected: true},
+	}
+	for _, test := range tests {
+		t.Log(test.name)
+		g.Expect(failoverRecoveryDue(test.policy, test.recoverFailover, test.autoByDefault, test.healthySince)).To(Equal(test.expected))
+	}
+}
+
+func TestTiKVHealthySince(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPD()
+	tc.Spec.TiKV.Replicas = 2
+	now := time.Now()
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+		"1": {ID: "1", PodName: "test-tikv-0", State: v1alpha1.TiKVStateUp, LastTransitionTime: metav1.NewTime(now.Add(-time.Hour))},
+		"2": {ID: "2", PodName: "test-tikv-1", State: v1alpha1.TiKVStateUp, LastTransitionTime: metav1.NewTime(now.Add(-time.Minute))},
+		// the store of the failover replica is not counted
+		"3": {ID: "3", PodName: "test-tikv-2", State: v1alpha1.TiKVStateUp, LastTransitionTime: metav1.NewTime(now)},
+	}
+	g.Expect(tikvHealthySince(tc).Equal(now.Add(-time.Minute))).To(BeTrue())
+}
@@ -0,0 +1,211 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+)
+
+const (
+	// FederationPeerUnresolvableReason is the reason of the events emitted when the host of a peer PD member
+	// can not be resolved in the local Kubernetes cluster
+	FederationPeerUnresolvableReason = "FederationPeerUnresolvable"
+	// FederationJoinedReason is the reason of the events emitted when the TidbCluster joins the PD cluster
+	FederationJoinedReason = "FederationJoined"
+)
+
+// federationSyncer reports the peering state of the TidbCluster deployed across Kubernetes clusters
+// with `spec.clusterDomain` in `status.federation`:
+//   - the PD members in the other Kubernetes clusters and their client and peer URLs, so that the
+//     endpoints can be exchanged with the TidbClusters being deployed in the other clusters
+//   - whether the hosts of the peer PD members can be resolved in the local Kubernetes cluster,
+//     as the cross-cluster DNS must be set up before the members can reach each other
+//   - whether the local PD members and TiKV stores have joined the PD cluster
+//
+// It only reports the state and never fails the sync of the TidbCluster.
+type federationSyncer struct {
+	deps       *controller.Dependencies
+	lookupHost func(host string) ([]string, error)
+}
+
+// NewFederationSyncer returns a federation syncer
+func NewFederationSyncer(deps *controller.Dependencies) manager.Manager {
+	return &federationSyncer{
+		deps:       deps,
+		lookupHost: net.LookupHost,
+	}
+}
+
+func (s *federationSyncer) Sync(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.ClusterDomain == "" {
+		tc.Status.Federation = nil
+		return nil
+	}
+	if tc.Spec.Cluster == nil && len(tc.Status.PD.PeerMembers) == 0 {
+		// a single TidbCluster with a cluster domain has no peers to join
+		tc.Status.Federation = nil
+		return nil
+	}
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	status := &v1alpha1.FederationStatus{}
+	members, err := controller.GetPDClient(s.deps.PDControl, tc).GetMembers()
+	if err != nil {
+		klog.Warningf("federationSyncer: failed to get PD members of tc %s/%s, error: %v", ns, tcName, err)
+		status.Message = fmt.Sprintf("failed to get PD members: %v", err)
+		s.setStatus(tc, status)
+		return nil
+	}
+
+	rePDMembers, err := regexp.Compile(fmt.Sprintf(pdMemberLimitPattern, tcName, tcName, ns, controller.FormatClusterDomainForRegex(tc.Spec.ClusterDomain)))
+	if err != nil {
+		return err
+	}
+	memberNames := sets.NewString()
+	for _, member := range members.Members {
+		if member == nil {
+			continue
+		}
+		memberNames.Insert(member.GetName())
+		var clientURL string
+		if len(member.GetClientUrls()) > 0 {
+			clientURL = member.GetClientUrls()[0]
+		}
+		if rePDMembers.MatchString(clientURL) {
+			continue
+		}
+		status.Peers = append(status.Peers, s.probePeer(member.GetName(), clientURL, member.GetPeerUrls()))
+	}
+	sort.Slice(status.Peers, func(i, j int) bool {
+		return status.Peers[i].Name < status.Peers[j].Name
+	})
+
+	var pending []string
+	if tc.Spec.PD != nil {
+		for _, ordinal := range tc.PDStsDesiredOrdinals(true).List() {
+			name := PdName(tcName, ordinal, ns, tc.Spec.ClusterDomain)
+			if !memberNames.Has(name) {
+				pending = append(pending, fmt.Sprintf("PD member %s", name))
+			}
+		}
+	}
+	if tc.Spec.TiKV != nil {
+		upStores := sets.NewString()
+		for _, store := range tc.Status.TiKV.Stores {
+			if store.State == v1alpha1.TiKVStateUp {
+				upStores.Insert(store.PodName)
+			}
+		}
+		for _, ordinal := range tc.TiKVStsDesiredOrdinals(true).List() {
+			name := TikvPodName(tcName, ordinal)
+			if !upStores.Has(name) {
+				pending = append(pending, fmt.Sprintf("TiKV store %s", name))
+			}
+		}
+	}
+	var unresolvable []string
+	for _, peer := range status.Peers {
+		if !peer.Resolvable {
+			unresolvable = append(unresolvable, peer.Name)
+		}
+	}
+
+	status.Joined = len(pending) == 0
+	switch {
+	case len(pending) > 0 && len(unresolvable) > 0:
+		status.Message = fmt.Sprintf("%s not joined, peers %s not resolvable", strings.Join(pending, ", "), strings.Join(unresolvable, ", "))
+	case len(pending) > 0:
+		status.Message = fmt.Sprintf("%s not joined", strings.Join(pending, ", "))
+	case len(unresolvable) > 0:
+		status.Message = fmt.Sprintf("peers %s not resolvable", strings.Join(unresolvable, ", "))
+	}
+	s.setStatus(tc, status)
+	return nil
+}
+
+// probePeer checks whether the host of the client URL of a peer PD member can be resolved
+func (s *federationSyncer) probePeer(name, clientURL string, peerURLs []string) v1alpha1.FederationPeer {
+	peer := v1alpha1.FederationPeer{
+		Name:      name,
+		ClientURL: clientURL,
+		PeerURLs:  peerURLs,
+	}
+	u, err := url.Parse(clientURL)
+	if err != nil || u.Hostname() == "" {
+		peer.Error = fmt.Sprintf("invalid client URL %q", clientURL)
+		return peer
+	}
+	if _, err := s.lookupHost(u.Hostname()); err != nil {
+		peer.Error = err.Error()
+		return peer
+	}
+	peer.Resolvable = true
+	return peer
+}
+
+// setStatus sets the federation status, the transition time is kept unless the join state or
+// the resolvability of the peers changes, and the events are emitted for the changes
+func (s *federationSyncer) setStatus(tc *v1alpha1.TidbCluster, status *v1alpha1.FederationStatus) {
+	old := tc.Status.Federation
+	oldResolvable := map[string]bool{}
+	if old != nil {
+		for _, peer := range old.Peers {
+			oldResolvable[peer.Name] = peer.Resolvable
+		}
+	}
+	newResolvable := map[string]bool{}
+	for _, peer := range status.Peers {
+		newResolvable[peer.Name] = peer.Resolvable
+		if resolvable, ok := oldResolvable[peer.Name]; !peer.Resolvable && (!ok || resolvable) {
+			s.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, FederationPeerUnresolvableReason,
+				"host of peer PD member %s can not be resolved: %s", peer.Name, peer.Error)
+		}
+	}
+	if status.Joined && (old == nil || !old.Joined) {
+		s.deps.Recorder.Event(tc, corev1.EventTypeNormal, FederationJoinedReason, "all PD members and TiKV stores have joined the PD cluster")
+	}
+
+	if old != nil && old.Joined == status.Joined && apiequality.Semantic.DeepEqual(oldResolvable, newResolvable) {
+		status.LastTransitionTime = old.LastTransitionTime
+	} else {
+		status.LastTransitionTime = metav1.Now()
+	}
+	tc.Status.Federation = status
+}
+
+type fakeFederationSyncer struct{}
+
+// NewFakeFederationSyncer returns a fake federation syncer
+func NewFakeFederationSyncer() manager.Manager {
+	return &fakeFederationSyncer{}
+}
+
+func (s *fakeFederationSyncer) Sync(_ *v1alpha1.TidbCluster) error {
+	return nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestFederationSyncerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	const (
+		localPD = "http://test-pd-0.test-pd-peer.default.svc.cluster1.local:2379"
+		peerPD  = "http://peer-pd-0.peer-pd-peer.default.svc.cluster2.local:2379"
+	)
+
+	type testcase struct {
+		name             string
+		clusterDomain    string
+		clusterRef       bool
+		members          []string
+		membersErr       error
+		unresolvable     bool
+		tikvUp           bool
+		expectNil        bool
+		expectPeers      int
+		expectJoined     bool
+		expectMessage    string
+		expectEventCount int
+	}
+
+	testFn := func(test *testcase) {
+		t.Log(test.name)
+
+		deps := controller.NewFakeDependencies()
+		recorder := record.NewFakeRecorder(10)
+		deps.Recorder = recorder
+		tc := newTidbClusterForPD()
+		tc.Spec.PD.Replicas = 1
+		tc.Spec.TiKV.Replicas = 1
+		tc.Spec.ClusterDomain = test.clusterDomain
+		if test.clusterRef {
+			tc.Spec.Cluster = &v1alpha1.TidbClusterRef{Namespace: "default", Name: "peer", ClusterDomain: "cluster2.local"}
+		}
+		if test.tikvUp {
+			tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+				"1": {ID: "1", PodName: "test-tikv-0", State: v1alpha1.TiKVStateUp},
+			}
+		}
+
+		pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+		pdClient.AddReaction(pdapi.GetMembersActionType, func(action *pdapi.Action) (interface{}, error) {
+			if test.membersErr != nil {
+				return nil, test.membersErr
+			}
+			members := &pdapi.MembersInfo{}
+			for _, clientURL := range test.members {
+				name := PdName("test", 0, "default", "cluster1.local")
+				if clientURL == peerPD {
+					name = "peer-pd-0.peer-pd-peer.default.svc.cluster2.local"
+				}
+				members.Members = append(members.Members, &pdpb.Member{Name: name, ClientUrls: []string{clientURL}})
+			}
+			return members, nil
+		})
+
+		s := NewFederationSyncer(deps).(*federationSyncer)
+		s.lookupHost = func(host string) ([]string, error) {
+			if test.unresolvable {
+				return nil, fmt.Errorf("no such host %s", host)
+			}
+			return []string{"10.0.0.1"}, nil
+		}
+		err := s.Sync(tc)
+		g.Expect(err).NotTo(HaveOccurred())
+		events := collectEvents(recorder.Events)
+		g.Expect(events).To(HaveLen(test.expectEventCount))
+		if test.expectNil {
+			g.Expect(tc.Status.Federation).To(BeNil())
+			return
+		}
+		g.Expect(tc.Status.Federation).NotTo(BeNil())
+		g.Expect(tc.Status.Federation.Peers).To(HaveLen(test.expectPeers))
+		g.Expect(tc.Status.Federation.Joined).To(Equal(test.expectJoined))
+		g.Expect(tc.Status.Federation.Message).To(ContainSubstring(test.expectMessage))
+		g.Expect(tc.Status.Federation.LastTransitionTime.IsZero()).To(BeFalse())
+
+		// the state is stable in the next sync
+		lastTransitionTime := tc.Status.Federation.LastTransitionTime
+		g.Expect(s.Sync(tc)).To(Succeed())
+		g.Expect(collectEvents(recorder.Events)).To(BeEmpty())
+		g.Expect(tc.Status.Federation.LastTransitionTime).To(Equal(lastTransitionTime))
+	}
+
+	tests := []testcase{
+		{
+			name:      "cluster domain is not set",
+			members:   []string{localPD, peerPD},
+			expectNil: true,
+		},
+		{
+			name:          "no peers",
+			clusterDomain: "cluster1.local",
+			members:       []string{localPD},
+			expectNil:     true,
+		},
+		{
+			name:             "joined",
+			clusterDomain:    "cluster1.local",
+			clusterRef:       true,
+			members:          []string{localPD, peerPD},
+			tikvUp:           true,
+			expectPeers:      1,
+			expectJoined:     true,
+			expectEventCount: 1,
+		},
+		{
+			name:             "pd and tikv not joined",
+			clusterDomain:    "cluster1.local",
+			clusterRef:       true,
+			members:          []string{peerPD},
+			expectPeers:      1,
+			expectMessage:    "PD member test-pd-0.test-pd-peer.default.svc.cluster1.local, TiKV store test-tikv-0 not joined",
+			expectEventCount: 0,
+		},
+		{
+			name:             "peer not resolvable",
+			clusterDomain:    "cluster1.local",
+			clusterRef:       true,
+			members:          []string{peerPD},
+			unresolvable:     true,
+			expectPeers:      1,
+			expectMessage:    "peers peer-pd-0.peer-pd-peer.default.svc.cluster2.local not resolvable",
+			expectEventCount: 1,
+		},
+		{
+			name:             "failed to get members",
+			clusterDomain:    "cluster1.local",
+			clusterRef:       true,
+			membersErr:       fmt.Errorf("connection refused"),
+			expectMessage:    "failed to get PD members",
+			expectEventCount: 0,
+		},
+	}
+	for i := range tests {
+		testFn(&tests[i])
+	}
+}
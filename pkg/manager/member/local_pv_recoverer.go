@@ -0,0 +1,390 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+const (
+	// LocalPVRecoverReason is the reason of the events emitted by the local PV recoverer
+	LocalPVRecoverReason = "LocalPVRecover"
+	// volumeConditionAbnormalReason is the reason of the events emitted on the PVC by the CSI
+	// external health monitor when the volume is abnormal, e.g. the file system is corrupted
+	volumeConditionAbnormalReason = "VolumeConditionAbnormal"
+	// volumeConditionAbnormalExpiration is how long the abnormal volume condition is considered current after it is
+	// reported, the CSI external health monitor reports it every minute by default while the volume is abnormal
+	volumeConditionAbnormalExpiration = 5 * time.Minute
+)
+
+// localPVRecoverer releases the dead local PVs of PD, TiKV and TiFlash, so that the pods using them
+// can be rescheduled instead of being stuck in Pending or ContainerCreating forever.
+//
+// A PV used by a pod which is not ready is considered dead if:
+// - it is pinned to a node by `kubernetes.io/hostname` node affinity and the node has disappeared
+// - the CSI driver reports that the volume is abnormal, and the condition is reported recently
+//
+// Before the PV is released, the member is removed from the cluster: the PD member unhealthy for
+// longer than the PD failover period is deleted, and the TiKV or TiFlash store which is Down is
+// marked as a failure store and deleted from PD, the PV is released after the store becomes
+// tombstone. The members which are restarting, e.g. the Disconnected stores, are not removed. Releasing the PV deletes the pod, the PVC and the PV
+// if its node has disappeared, so a new pod is created with a new PVC and joins the cluster
+// as a new member.
+//
+// It only works when auto failover is enabled and handles one pod at a time.
+type localPVRecoverer struct {
+	deps *controller.Dependencies
+}
+
+// NewLocalPVRecoverer returns a local PV recoverer
+func NewLocalPVRecoverer(deps *controller.Dependencies) manager.Manager {
+	return &localPVRecoverer{
+		deps: deps,
+	}
+}
+
+func (r *localPVRecoverer) Sync(tc *v1alpha1.TidbCluster) error {
+	if !r.deps.CLIConfig.AutoFailover || r.deps.PVLister == nil || tc.Adopting() {
+		return nil
+	}
+	for _, memberType := range []v1alpha1.MemberType{v1alpha1.PDMemberType, v1alpha1.TiKVMemberType, v1alpha1.TiFlashMemberType} {
+		if err := r.recover(tc, memberType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deadVolume is a PVC whose PV is dead
+type deadVolume struct {
+	pvc         *corev1.PersistentVolumeClaim
+	pv          *corev1.PersistentVolume
+	nodeRemoved bool
+	reason      string
+}
+
+func (r *localPVRecoverer) recover(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) error {
+	ns := tc.GetNamespace()
+	selector, err := label.New().Instance(tc.GetInstanceName()).Component(memberType.String()).Selector()
+	if err != nil {
+		return err
+	}
+	pods, err := r.deps.PodLister.Pods(ns).List(selector)
+	if err != nil {
+		return fmt.Errorf("local pv recoverer: failed to list %s pods for tc %s/%s, error: %v", memberType, ns, tc.GetName(), err)
+	}
+
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil || podutil.IsPodReady(pod) {
+			continue
+		}
+		volumes, err := r.deadVolumes(tc, memberType, pod)
+		if err != nil {
+			return err
+		}
+		if len(volumes) == 0 {
+			continue
+		}
+		klog.Infof("local pv recoverer: tc %s/%s pod %s uses dead volumes, %s", ns, tc.GetName(), pod.Name, volumes[0].reason)
+
+		var removed bool
+		switch memberType {
+		case v1alpha1.PDMemberType:
+			removed, err = r.removePDMember(tc, pod, volumes[0].reason)
+		case v1alpha1.TiKVMemberType:
+			removed, err = r.removeStore(tc, memberType, pod, volumes[0].reason, tc.Status.TiKV.Stores, &tc.Status.TiKV.FailureStores, tc.Spec.TiKV.MaxFailoverCount)
+		case v1alpha1.TiFlashMemberType:
+			removed, err = r.removeStore(tc, memberType, pod, volumes[0].reason, tc.Status.TiFlash.Stores, &tc.Status.TiFlash.FailureStores, tc.Spec.TiFlash.MaxFailoverCount)
+		}
+		if err != nil || !removed {
+			return err
+		}
+		return r.release(tc, pod, volumes)
+	}
+	return nil
+}
+
+// deadVolumes returns the PVCs of the pod whose PVs are dead
+func (r *localPVRecoverer) deadVolumes(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, pod *corev1.Pod) ([]deadVolume, error) {
+	ns := tc.GetNamespace()
+	ordinal, err := util.GetOrdinalFromPodName(pod.Name)
+	if err != nil {
+		return nil, err
+	}
+	pvcSelector, err := GetPVCSelectorForPod(tc, memberType, ordinal)
+	if err != nil {
+		return nil, err
+	}
+	pvcs, err := r.deps.PVCLister.PersistentVolumeClaims(ns).List(pvcSelector)
+	if err != nil {
+		return nil, fmt.Errorf("local pv recoverer: failed to list PVCs of pod %s/%s, error: %v", ns, pod.Name, err)
+	}
+
+	var volumes []deadVolume
+	for _, pvc := range pvcs {
+		if pvc.DeletionTimestamp != nil || pvc.Spec.VolumeName == "" {
+			continue
+		}
+		pv, err := r.deps.PVLister.Get(pvc.Spec.VolumeName)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("local pv recoverer: failed to get PV %s of PVC %s/%s, error: %v", pvc.Spec.VolumeName, ns, pvc.Name, err)
+		}
+
+		if nodeName := localPVNodeName(pv); nodeName != "" && r.deps.NodeLister != nil {
+			exist, err := r.nodeExists(nodeName)
+			if err != nil {
+				return nil, err
+			}
+			if !exist {
+				volumes = append(volumes, deadVolume{
+					pvc:         pvc,
+					pv:          pv,
+					nodeRemoved: true,
+					reason:      fmt.Sprintf("node %s of PV %s has disappeared", nodeName, pv.Name),
+				})
+				continue
+			}
+		}
+
+		abnormal, err := r.volumeAbnormal(pvc)
+		if err != nil {
+			return nil, err
+		}
+		if abnormal != "" {
+			volumes = append(volumes, deadVolume{
+				pvc:    pvc,
+				pv:     pv,
+				reason: fmt.Sprintf("PV %s is abnormal: %s", pv.Name, abnormal),
+			})
+		}
+	}
+	return volumes, nil
+}
+
+// localPVNodeName returns the node name the PV is pinned to by the node affinity
+func localPVNodeName(pv *corev1.PersistentVolume) string {
+	if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+		return ""
+	}
+	for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+		for _, expr := range term.MatchExpressions {
+			if expr.Key == corev1.LabelHostname && expr.Operator == corev1.NodeSelectorOpIn && len(expr.Values) == 1 {
+				return expr.Values[0]
+			}
+		}
+	}
+	return ""
+}
+
+func (r *localPVRecoverer) nodeExists(hostname string) (bool, error) {
+	nodes, err := r.deps.NodeLister.List(labels.SelectorFromSet(labels.Set{corev1.LabelHostname: hostname}))
+	if err != nil {
+		return false, err
+	}
+	if len(nodes) > 0 {
+		return true, nil
+	}
+	_, err = r.deps.NodeLister.Get(hostname)
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// volumeAbnormal returns the message of the abnormal volume condition reported on the PVC by CSI,
+// empty if the condition is not reported in volumeConditionAbnormalExpiration
+func (r *localPVRecoverer) volumeAbnormal(pvc *corev1.PersistentVolumeClaim) (string, error) {
+	selector := fields.SelectorFromSet(fields.Set{
+		"involvedObject.kind": "PersistentVolumeClaim",
+		"involvedObject.name": pvc.Name,
+		"reason":              volumeConditionAbnormalReason,
+	})
+	events, err := r.deps.KubeClientset.CoreV1().Events(pvc.Namespace).List(metav1.ListOptions{FieldSelector: selector.String()})
+	if err != nil {
+		return "", fmt.Errorf("local pv recoverer: failed to list events of PVC %s/%s, error: %v", pvc.Namespace, pvc.Name, err)
+	}
+	var latest *corev1.Event
+	for i := range events.Items {
+		event := &events.Items[i]
+		if event.InvolvedObject.Name != pvc.Name || (event.InvolvedObject.UID != "" && event.InvolvedObject.UID != pvc.UID) || event.Reason != volumeConditionAbnormalReason {
+			continue
+		}
+		if latest == nil || eventLastTime(event).After(eventLastTime(latest)) {
+			latest = event
+		}
+	}
+	if latest == nil {
+		return "", nil
+	}
+	if reported := eventLastTime(latest); time.Since(reported) > volumeConditionAbnormalExpiration {
+		klog.Infof("local pv recoverer: the abnormal condition of PVC %s/%s is reported at %s, it is stale", pvc.Namespace, pvc.Name, reported)
+		return "", nil
+	}
+	return latest.Message, nil
+}
+
+// eventLastTime returns the last time the event is reported
+func eventLastTime(event *corev1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.FirstTimestamp.Time
+}
+
+// removePDMember deletes the PD member of the pod from the cluster, returns true if the member is removed
+func (r *localPVRecoverer) removePDMember(tc *v1alpha1.TidbCluster, pod *corev1.Pod, reason string) (bool, error) {
+	ns := tc.GetNamespace()
+	healthCount := 0
+	var member *v1alpha1.PDMember
+	for name, m := range tc.Status.PD.Members {
+		if m.Health {
+			healthCount++
+		}
+		if strings.Split(name, ".")[0] == pod.Name {
+			m := m
+			member = &m
+		}
+	}
+	if member == nil {
+		return true, nil
+	}
+	if member.Health {
+		klog.Infof("local pv recoverer: PD member %s of pod %s/%s is still healthy, skip", member.Name, ns, pod.Name)
+		return false, nil
+	}
+	if time.Since(member.LastTransitionTime.Time) < r.deps.CLIConfig.PDFailoverPeriod {
+		// the member may be restarting
+		klog.Infof("local pv recoverer: PD member %s of pod %s/%s is unhealthy for less than %s, skip", member.Name, ns, pod.Name, r.deps.CLIConfig.PDFailoverPeriod)
+		return false, nil
+	}
+	if healthCount <= len(tc.Status.PD.Members)/2 {
+		return false, controller.RequeueErrorf("local pv recoverer: tc %s/%s PD is not in quorum, can not delete member %s", ns, tc.GetName(), member.Name)
+	}
+	if err := controller.GetPDClient(r.deps.PDControl, tc).DeleteMember(member.Name); err != nil {
+		return false, fmt.Errorf("local pv recoverer: failed to delete PD member %s of tc %s/%s, error: %v", member.Name, ns, tc.GetName(), err)
+	}
+	klog.Infof("local pv recoverer: PD member %s of tc %s/%s is deleted", member.Name, ns, tc.GetName())
+	r.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, LocalPVRecoverReason, "PD member %s is deleted because %s", member.Name, reason)
+	return true, nil
+}
+
+// removeStore marks the store of the pod as a failure store and deletes it from the cluster,
+// returns true if the store is tombstone or does not exist
+func (r *localPVRecoverer) removeStore(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, pod *corev1.Pod, reason string,
+	stores map[string]v1alpha1.TiKVStore, failureStores *map[string]v1alpha1.TiKVFailureStore, maxFailoverCount *int32) (bool, error) {
+	ns := tc.GetNamespace()
+	var store *v1alpha1.TiKVStore
+	for _, s := range stores {
+		if s.PodName == pod.Name {
+			s := s
+			store = &s
+			break
+		}
+	}
+	if store == nil || store.State == v1alpha1.TiKVStateTombstone {
+		return true, nil
+	}
+	switch store.State {
+	case v1alpha1.TiKVStateDown:
+	case v1alpha1.TiKVStateOffline:
+		return false, controller.RequeueErrorf("local pv recoverer: %s store %s of pod %s/%s is offline, wait for it to become tombstone", memberType, store.ID, ns, pod.Name)
+	default:
+		// the store is not Down until it is disconnected for max-store-down-time, e.g. it may be restarting
+		klog.Infof("local pv recoverer: %s store %s of pod %s/%s is %s, not down, skip", memberType, store.ID, ns, pod.Name, store.State)
+		return false, nil
+	}
+
+	// mark the store as a failure store so that a new store is created to take over its data
+	if _, exist := (*failureStores)[store.ID]; !exist && maxFailoverCount != nil && len(*failureStores) < int(*maxFailoverCount) {
+		if *failureStores == nil {
+			*failureStores = map[string]v1alpha1.TiKVFailureStore{}
+		}
+		(*failureStores)[store.ID] = v1alpha1.TiKVFailureStore{
+			PodName:   pod.Name,
+			StoreID:   store.ID,
+			CreatedAt: metav1.Now(),
+		}
+	}
+
+	id, err := strconv.ParseUint(store.ID, 10, 64)
+	if err != nil {
+		return false, err
+	}
+	if err := controller.GetPDClient(r.deps.PDControl, tc).DeleteStore(id); err != nil {
+		return false, fmt.Errorf("local pv recoverer: failed to delete %s store %s of tc %s/%s, error: %v", memberType, store.ID, ns, tc.GetName(), err)
+	}
+	klog.Infof("local pv recoverer: %s store %s of tc %s/%s is deleted", memberType, store.ID, ns, tc.GetName())
+	r.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, LocalPVRecoverReason, "%s store %s of pod %s is deleted because %s", memberType, store.ID, pod.Name, reason)
+	return false, controller.RequeueErrorf("local pv recoverer: %s store %s of pod %s/%s is deleted, wait for it to become tombstone", memberType, store.ID, ns, pod.Name)
+}
+
+// release deletes the pod and the dead PVCs, and deletes the PVs if their nodes have disappeared.
+// The pod is deleted first so that the PVCs are not protected, see the comment in pd_failover.go
+// for how the new pod is handled if it is created before the PVCs are deleted.
+func (r *localPVRecoverer) release(tc *v1alpha1.TidbCluster, pod *corev1.Pod, volumes []deadVolume) error {
+	if err := r.deps.PodControl.DeletePod(tc, pod); err != nil {
+		return err
+	}
+	for _, volume := range volumes {
+		if err := r.deps.PVCControl.DeletePVC(tc, volume.pvc); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		if volume.nodeRemoved {
+			// no provisioner can clean up the volume on a disappeared node
+			if err := r.deps.PVControl.DeletePV(tc, volume.pv); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		} else if volume.pv.Spec.PersistentVolumeReclaimPolicy != corev1.PersistentVolumeReclaimDelete {
+			if err := r.deps.PVControl.PatchPVReclaimPolicy(tc, volume.pv, corev1.PersistentVolumeReclaimDelete); err != nil {
+				return err
+			}
+		}
+		r.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, LocalPVRecoverReason, "PVC %s and PV %s of pod %s are released because %s", volume.pvc.Name, volume.pv.Name, pod.Name, volume.reason)
+	}
+	klog.Infof("local pv recoverer: dead volumes of pod %s/%s are released", tc.GetNamespace(), pod.Name)
+	return nil
+}
+
+type fakeLocalPVRecoverer struct{}
+
+// NewFakeLocalPVRecoverer returns a fake local PV recoverer
+func NewFakeLocalPVRecoverer() manager.Manager {
+	return &fakeLocalPVRecoverer{}
+}
+
+func (r *fakeLocalPVRecoverer) Sync(_ *v1alpha1.TidbCluster) error {
+	return nil
+}
@@ -0,0 +1,264 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestLocalPVNodeName(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	pv := &corev1.PersistentVolume{}
+	g.Expect(localPVNodeName(pv)).To(BeEmpty())
+	pv.Spec.NodeAffinity = newLocalPVNodeAffinity("node-1")
+	g.Expect(localPVNodeName(pv)).To(Equal("node-1"))
+}
+
+func TestLocalPVRecovererSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type testcase struct {
+		name           string
+		podReady       bool
+		nodeExists     bool
+		abnormalEvent  bool
+		staleEvent     bool
+		stores         map[string]v1alpha1.TiKVStore
+		errExpectFn    func(error)
+		expectStoreDel bool
+		expectReleased bool
+		expectPVDel    bool
+	}
+
+	testFn := func(test *testcase) {
+		t.Log(test.name)
+
+		deps := controller.NewFakeDependencies()
+		tc := newTidbClusterForPlacementRebalancer()
+		tc.Spec.TiKV = &v1alpha1.TiKVSpec{MaxFailoverCount: pointer.Int32Ptr(3)}
+		tc.Status.TiKV.Stores = test.stores
+		pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+		storeDeleted := false
+		pdClient.AddReaction(pdapi.DeleteStoreActionType, func(action *pdapi.Action) (interface{}, error) {
+			storeDeleted = true
+			return nil, nil
+		})
+
+		podName := ordinalPodName(v1alpha1.TiKVMemberType, tc.Name, 1)
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      podName,
+				Namespace: tc.Namespace,
+				Labels:    label.New().Instance(tc.Name).TiKV().Labels(),
+			},
+		}
+		if test.podReady {
+			pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		}
+		deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Add(pod)
+
+		pvcLabels := label.New().Instance(tc.Name).TiKV().Labels()
+		pvcLabels[label.AnnPodNameKey] = podName
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "tikv-" + podName,
+				Namespace: tc.Namespace,
+				UID:       "pvc-uid",
+				Labels:    pvcLabels,
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{VolumeName: "local-pv-1"},
+		}
+		deps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer().Add(pvc)
+		deps.KubeInformerFactory.Core().V1().PersistentVolumes().Informer().GetIndexer().Add(&corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "local-pv-1"},
+			Spec: corev1.PersistentVolumeSpec{
+				PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain,
+				NodeAffinity:                  newLocalPVNodeAffinity("node-1"),
+			},
+		})
+		if test.nodeExists {
+			deps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer().Add(&corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "node-1",
+					Labels: map[string]string{corev1.LabelHostname: "node-1"},
+				},
+			})
+		}
+		if test.staleEvent {
+			deps.KubeClientset.CoreV1().Events(tc.Namespace).Create(&corev1.Event{
+				ObjectMeta: metav1.ObjectMeta{Name: "stale", Namespace: tc.Namespace},
+				InvolvedObject: corev1.ObjectReference{
+					Kind: "PersistentVolumeClaim",
+					Name: pvc.Name,
+					UID:  pvc.UID,
+				},
+				Reason:        volumeConditionAbnormalReason,
+				Message:       "file system is corrupted",
+				LastTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+			})
+		}
+		if test.abnormalEvent {
+			deps.KubeClientset.CoreV1().Events(tc.Namespace).Create(&corev1.Event{
+				ObjectMeta: metav1.ObjectMeta{Name: "abnormal", Namespace: tc.Namespace},
+				InvolvedObject: corev1.ObjectReference{
+					Kind: "PersistentVolumeClaim",
+					Name: pvc.Name,
+					UID:  pvc.UID,
+				},
+				Reason:        volumeConditionAbnormalReason,
+				Message:       "file system is corrupted",
+				LastTimestamp: metav1.Now(),
+			})
+		}
+
+		err := NewLocalPVRecoverer(deps).Sync(tc)
+		test.errExpectFn(err)
+		g.Expect(storeDeleted).To(Equal(test.expectStoreDel))
+		if test.expectStoreDel {
+			g.Expect(tc.Status.TiKV.FailureStores).To(HaveKey("1"))
+		}
+
+		_, podErr := deps.PodLister.Pods(tc.Namespace).Get(podName)
+		_, pvcErr := deps.PVCLister.PersistentVolumeClaims(tc.Namespace).Get(pvc.Name)
+		pv, pvErr := deps.PVLister.Get("local-pv-1")
+		if test.expectReleased {
+			g.Expect(errors.IsNotFound(podErr)).To(BeTrue())
+			g.Expect(errors.IsNotFound(pvcErr)).To(BeTrue())
+		} else {
+			g.Expect(podErr).NotTo(HaveOccurred())
+			g.Expect(pvcErr).NotTo(HaveOccurred())
+		}
+		if test.expectPVDel {
+			g.Expect(errors.IsNotFound(pvErr)).To(BeTrue())
+		} else {
+			g.Expect(pvErr).NotTo(HaveOccurred())
+			if test.expectReleased {
+				g.Expect(pv.Spec.PersistentVolumeReclaimPolicy).To(Equal(corev1.PersistentVolumeReclaimDelete))
+			}
+		}
+	}
+
+	noErr := func(err error) { g.Expect(err).NotTo(HaveOccurred()) }
+	tests := []testcase{
+		{
+			name:        "pod is ready",
+			podReady:    true,
+			errExpectFn: noErr,
+		},
+		{
+			name:        "volume is healthy",
+			nodeExists:  true,
+			errExpectFn: noErr,
+		},
+		{
+			name: "store of the pod is still up",
+			stores: map[string]v1alpha1.TiKVStore{
+				"1": {ID: "1", PodName: "test-tikv-1", State: v1alpha1.TiKVStateUp},
+			},
+			errExpectFn: noErr,
+		},
+		{
+			name: "store of the pod is disconnected",
+			stores: map[string]v1alpha1.TiKVStore{
+				"1": {ID: "1", PodName: "test-tikv-1", State: "Disconnected"},
+			},
+			errExpectFn: noErr,
+		},
+		{
+			name: "disconnected store with an abnormal volume is not deleted",
+			stores: map[string]v1alpha1.TiKVStore{
+				"1": {ID: "1", PodName: "test-tikv-1", State: "Disconnected"},
+			},
+			nodeExists:    true,
+			abnormalEvent: true,
+			errExpectFn:   noErr,
+		},
+		{
+			name: "delete the down store of the pod on the disappeared node",
+			stores: map[string]v1alpha1.TiKVStore{
+				"1": {ID: "1", PodName: "test-tikv-1", State: v1alpha1.TiKVStateDown},
+			},
+			errExpectFn: func(err error) {
+				g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+				g.Expect(err.Error()).To(ContainSubstring("wait for it to become tombstone"))
+			},
+			expectStoreDel: true,
+		},
+		{
+			name: "wait for the offline store to become tombstone",
+			stores: map[string]v1alpha1.TiKVStore{
+				"1": {ID: "1", PodName: "test-tikv-1", State: v1alpha1.TiKVStateOffline},
+			},
+			errExpectFn: func(err error) { g.Expect(controller.IsRequeueError(err)).To(BeTrue()) },
+		},
+		{
+			name:           "release the volume on the disappeared node",
+			errExpectFn:    noErr,
+			expectReleased: true,
+			expectPVDel:    true,
+		},
+		{
+			name:        "stale abnormal condition of the volume",
+			nodeExists:  true,
+			staleEvent:  true,
+			errExpectFn: noErr,
+		},
+		{
+			name: "down store with a stale abnormal condition of the volume",
+			stores: map[string]v1alpha1.TiKVStore{
+				"1": {ID: "1", PodName: "test-tikv-1", State: v1alpha1.TiKVStateDown},
+			},
+			nodeExists:  true,
+			staleEvent:  true,
+			errExpectFn: noErr,
+		},
+		{
+			name:           "release the abnormal volume",
+			nodeExists:     true,
+			abnormalEvent:  true,
+			errExpectFn:    noErr,
+			expectReleased: true,
+		},
+	}
+
+	for i := range tests {
+		testFn(&tests[i])
+	}
+}
+
+func newLocalPVNodeAffinity(nodeName string) *corev1.VolumeNodeAffinity {
+	return &corev1.VolumeNodeAffinity{
+		Required: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{
+					Key:      corev1.LabelHostname,
+					Operator: corev1.NodeSelectorOpIn,
+					Values:   []string{nodeName},
+				}},
+			}},
+		},
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+const (
+	// NodeFencingReason is the reason of the events emitted by the node fencer
+	NodeFencingReason = "NodeFencing"
+	// outOfServiceTaintKey is the taint added to the nodes which are shut down by the administrators, see
+	// https://kubernetes.io/docs/concepts/architecture/nodes/#non-graceful-node-shutdown
+	outOfServiceTaintKey = "node.kubernetes.io/out-of-service"
+)
+
+// nodeFencer force deletes the pods of a TidbCluster stuck on the nodes which are gone, e.g. in
+// Terminating or Unknown state, so that the StatefulSets can recreate them on other nodes and
+// the failover is not blocked.
+//
+// A node is considered gone if:
+// - the node is deleted, e.g. by the cloud node lifecycle controller after the instance is removed
+// - the node has the `node.kubernetes.io/out-of-service` taint
+// - the node is not ready and its lease has not been renewed for `--node-fencing-lease-timeout`
+//
+// Before the pod is deleted, the volume attachments of its PVs on the node are deleted, so that
+// the volumes can be attached to the new node without waiting for the attach detach controller.
+//
+// It only works when node fencing is enabled and the operator has the permission of nodes.
+type nodeFencer struct {
+	deps *controller.Dependencies
+}
+
+// NewNodeFencer returns a node fencer
+func NewNodeFencer(deps *controller.Dependencies) manager.Manager {
+	return &nodeFencer{
+		deps: deps,
+	}
+}
+
+func (f *nodeFencer) Sync(tc *v1alpha1.TidbCluster) error {
+	if !f.deps.CLIConfig.NodeFencing || f.deps.NodeLister == nil {
+		return nil
+	}
+	ns := tc.GetNamespace()
+	selector, err := label.New().Instance(tc.GetInstanceName()).Selector()
+	if err != nil {
+		return err
+	}
+	pods, err := f.deps.PodLister.Pods(ns).List(selector)
+	if err != nil {
+		return fmt.Errorf("node fencer: failed to list pods for tc %s/%s, error: %v", ns, tc.GetName(), err)
+	}
+
+	// the reasons of the nodes which are gone, an empty reason means the node is alive
+	nodes := map[string]string{}
+	for _, pod := range pods {
+		nodeName := pod.Spec.NodeName
+		if nodeName == "" || !podStuck(pod) {
+			continue
+		}
+		reason, ok := nodes[nodeName]
+		if !ok {
+			reason, err = f.nodeGone(nodeName)
+			if err != nil {
+				return err
+			}
+			nodes[nodeName] = reason
+		}
+		if reason == "" {
+			continue
+		}
+		if err := f.fence(tc, pod, reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// podStuck returns true if the pod is terminating or not ready, which is the case for all the pods
+// on a node which is gone
+func podStuck(pod *corev1.Pod) bool {
+	return pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodUnknown || !podutil.IsPodReady(pod)
+}
+
+// nodeGone returns the reason if the node is gone, or an empty string if the node may still be alive
+func (f *nodeFencer) nodeGone(nodeName string) (string, error) {
+	node, err := f.deps.NodeLister.Get(nodeName)
+	if errors.IsNotFound(err) {
+		return fmt.Sprintf("node %s is deleted", nodeName), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("node fencer: failed to get node %s, error: %v", nodeName, err)
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == outOfServiceTaintKey {
+			return fmt.Sprintf("node %s is out of service", nodeName), nil
+		}
+	}
+
+	var ready *corev1.NodeCondition
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == corev1.NodeReady {
+			ready = &node.Status.Conditions[i]
+			break
+		}
+	}
+	if ready != nil && ready.Status == corev1.ConditionTrue {
+		return "", nil
+	}
+
+	// the heartbeat of the Ready condition is only updated every few minutes if the node lease is
+	// used, so the renew time of the lease is preferred
+	var lastHeartbeat time.Time
+	lease, err := f.deps.KubeClientset.CoordinationV1().Leases(corev1.NamespaceNodeLease).Get(nodeName, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return "", fmt.Errorf("node fencer: failed to get lease of node %s, error: %v", nodeName, err)
+	}
+	if err == nil && lease.Spec.RenewTime != nil {
+		lastHeartbeat = lease.Spec.RenewTime.Time
+	} else if ready != nil {
+		lastHeartbeat = ready.LastHeartbeatTime.Time
+	}
+	timeout := f.deps.CLIConfig.NodeFencingLeaseTimeout
+	if lastHeartbeat.IsZero() || time.Since(lastHeartbeat) < timeout {
+		return "", nil
+	}
+	return fmt.Sprintf("node %s is not ready and its lease is not renewed for more than %s", nodeName, timeout), nil
+}
+
+// fence detaches the volumes of the pod from its node and force deletes the pod
+func (f *nodeFencer) fence(tc *v1alpha1.TidbCluster, pod *corev1.Pod, reason string) error {
+	ns := tc.GetNamespace()
+	if err := f.detachVolumes(tc, pod); err != nil {
+		return err
+	}
+	if err := f.deps.PodControl.ForceDeletePod(tc, pod); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("node fencer: failed to force delete pod %s/%s, error: %v", ns, pod.Name, err)
+	}
+	klog.Infof("node fencer: pod %s/%s of tc %s is force deleted because %s", ns, pod.Name, tc.GetName(), reason)
+	f.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, NodeFencingReason, "pod %s is force deleted because %s", pod.Name, reason)
+	return nil
+}
+
+// detachVolumes deletes the volume attachments of the PVs of the pod on its node
+func (f *nodeFencer) detachVolumes(tc *v1alpha1.TidbCluster, pod *corev1.Pod) error {
+	if !f.deps.CLIConfig.HasPVPermission() {
+		return nil
+	}
+	ns := tc.GetNamespace()
+	pvNames := sets.NewString()
+	for _, vol := range pod.Spec.Volumes {
+		if vol.PersistentVolumeClaim == nil {
+			continue
+		}
+		pvc, err := f.deps.PVCLister.PersistentVolumeClaims(ns).Get(vol.PersistentVolumeClaim.ClaimName)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("node fencer: failed to get PVC %s/%s of pod %s, error: %v", ns, vol.PersistentVolumeClaim.ClaimName, pod.Name, err)
+		}
+		if pvc.Spec.VolumeName != "" {
+			pvNames.Insert(pvc.Spec.VolumeName)
+		}
+	}
+	if pvNames.Len() == 0 {
+		return nil
+	}
+
+	attachments, err := f.deps.KubeClientset.StorageV1().VolumeAttachments().List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("node fencer: failed to list volume attachments, error: %v", err)
+	}
+	for _, attachment := range attachments.Items {
+		pvName := attachment.Spec.Source.PersistentVolumeName
+		if attachment.Spec.NodeName != pod.Spec.NodeName || pvName == nil || !pvNames.Has(*pvName) || attachment.DeletionTimestamp != nil {
+			continue
+		}
+		err := f.deps.KubeClientset.StorageV1().VolumeAttachments().Delete(attachment.Name, &metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("node fencer: failed to delete volume attachment %s of PV %s, error: %v", attachment.Name, *pvName, err)
+		}
+		klog.Infof("node fencer: volume attachment %s of PV %s on node %s is deleted", attachment.Name, *pvName, pod.Spec.NodeName)
+		f.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, NodeFencingReason, "PV %s of pod %s is detached from node %s", *pvName, pod.Name, pod.Spec.NodeName)
+	}
+	return nil
+}
+
+type fakeNodeFencer struct{}
+
+// NewFakeNodeFencer returns a fake node fencer
+func NewFakeNodeFencer() manager.Manager {
+	return &fakeNodeFencer{}
+}
+
+func (f *fakeNodeFencer) Sync(_ *v1alpha1.TidbCluster) error {
+	return nil
+}
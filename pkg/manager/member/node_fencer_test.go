@@ -0,0 +1,158 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestNodeFencerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type testcase struct {
+		name          string
+		podReady      bool
+		node          *corev1.Node
+		leaseRenewAgo time.Duration
+		expectFenced  bool
+	}
+
+	notReadyNode := func(taints ...corev1.Taint) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Spec:       corev1.NodeSpec{Taints: taints},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionUnknown}},
+			},
+		}
+	}
+
+	testFn := func(test *testcase) {
+		t.Log(test.name)
+
+		deps := controller.NewFakeDependencies()
+		deps.CLIConfig.NodeFencing = true
+		tc := newTidbClusterForPlacementRebalancer()
+
+		podName := ordinalPodName(v1alpha1.TiKVMemberType, tc.Name, 1)
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      podName,
+				Namespace: tc.Namespace,
+				Labels:    label.New().Instance(tc.Name).TiKV().Labels(),
+			},
+			Spec: corev1.PodSpec{
+				NodeName: "node-1",
+				Volumes: []corev1.Volume{{
+					Name: "tikv",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "tikv-" + podName},
+					},
+				}},
+			},
+		}
+		if test.podReady {
+			pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		}
+		deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Add(pod)
+		deps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer().Add(&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "tikv-" + podName, Namespace: tc.Namespace},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-1"},
+		})
+		if test.node != nil {
+			deps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer().Add(test.node)
+		}
+		if test.leaseRenewAgo > 0 {
+			deps.KubeClientset.CoordinationV1().Leases(corev1.NamespaceNodeLease).Create(&coordinationv1.Lease{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1", Namespace: corev1.NamespaceNodeLease},
+				Spec: coordinationv1.LeaseSpec{
+					RenewTime: &metav1.MicroTime{Time: time.Now().Add(-test.leaseRenewAgo)},
+				},
+			})
+		}
+		deps.KubeClientset.StorageV1().VolumeAttachments().Create(&storagev1.VolumeAttachment{
+			ObjectMeta: metav1.ObjectMeta{Name: "attachment-1"},
+			Spec: storagev1.VolumeAttachmentSpec{
+				NodeName: "node-1",
+				Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: pointer.StringPtr("pv-1")},
+			},
+		})
+
+		err := NewNodeFencer(deps).Sync(tc)
+		g.Expect(err).NotTo(HaveOccurred())
+
+		_, err = deps.PodLister.Pods(tc.Namespace).Get(podName)
+		attachments, _ := deps.KubeClientset.StorageV1().VolumeAttachments().List(metav1.ListOptions{})
+		if test.expectFenced {
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(attachments.Items).To(BeEmpty())
+		} else {
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(attachments.Items).To(HaveLen(1))
+		}
+	}
+
+	tests := []testcase{
+		{
+			name:         "node is deleted",
+			expectFenced: true,
+		},
+		{
+			name:         "pod is ready",
+			podReady:     true,
+			expectFenced: false,
+		},
+		{
+			name:         "node is out of service",
+			node:         notReadyNode(corev1.Taint{Key: outOfServiceTaintKey, Effect: corev1.TaintEffectNoExecute}),
+			expectFenced: true,
+		},
+		{
+			name:          "node lease is expired",
+			node:          notReadyNode(),
+			leaseRenewAgo: time.Hour,
+			expectFenced:  true,
+		},
+		{
+			name:          "node lease is renewed recently",
+			node:          notReadyNode(),
+			leaseRenewAgo: time.Minute,
+			expectFenced:  false,
+		},
+		{
+			name: "node is ready",
+			node: &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+				Status: corev1.NodeStatus{
+					Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+				},
+			},
+			expectFenced: false,
+		},
+	}
+	for i := range tests {
+		testFn(&tests[i])
+	}
+}
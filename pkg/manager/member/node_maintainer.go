@@ -0,0 +1,341 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+const (
+	// NodeMaintenanceReason is the reason of the events emitted by the node maintainer
+	NodeMaintenanceReason = "NodeMaintenance"
+	// toBeDeletedTaintKey is the taint added by cluster-autoscaler to the nodes to be scaled down
+	toBeDeletedTaintKey = "ToBeDeletedByClusterAutoscaler"
+)
+
+// NodeMaintainer prepares the pods of the TidbClusters on a node to be drained
+type NodeMaintainer interface {
+	Sync(node *corev1.Node) error
+}
+
+// nodeMaintainer moves the leaders away from the pods on a node before they are evicted by
+// `kubectl drain` or cluster-autoscaler, so that the eviction does not cause unavailability:
+// - the leaders of the TiKV stores are evicted
+// - the PD leader is transferred to a member on another node
+// - the tables of the TiCDC captures are moved to other captures, and the owner resigns
+//
+// A node is to be drained if it is cordoned, tainted by cluster-autoscaler to be deleted or
+// annotated with `tidb.pingcap.com/node-maintenance=true`.
+//
+// The stores whose leaders are evicted are recorded in the PVCs, and the evict leader schedulers
+// are removed once the stores are up again on a node which is not to be drained, e.g. the pods
+// are recreated on other nodes or the node is uncordoned.
+type nodeMaintainer struct {
+	deps *controller.Dependencies
+}
+
+// NewNodeMaintainer returns a node maintainer
+func NewNodeMaintainer(deps *controller.Dependencies) NodeMaintainer {
+	return &nodeMaintainer{
+		deps: deps,
+	}
+}
+
+func (m *nodeMaintainer) Sync(node *corev1.Node) error {
+	selector, err := label.New().Selector()
+	if err != nil {
+		return err
+	}
+	pods, err := m.deps.PodLister.List(selector)
+	if err != nil {
+		return fmt.Errorf("node maintainer: failed to list pods, error: %v", err)
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].Namespace < pods[j].Namespace || (pods[i].Namespace == pods[j].Namespace && pods[i].Name < pods[j].Name)
+	})
+
+	draining := nodeToBeDrained(node)
+	var errs, requeueErrs []error
+	for _, pod := range pods {
+		if pod.Spec.NodeName != node.Name {
+			continue
+		}
+		tcName, ok := pod.Labels[label.InstanceLabelKey]
+		if !ok {
+			continue
+		}
+		tc, err := m.deps.TiDBClusterLister.TidbClusters(pod.Namespace).Get(tcName)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if draining {
+			err = m.prepare(tc, pod, node)
+		} else {
+			err = m.restore(tc, pod)
+		}
+		if controller.IsRequeueError(err) {
+			requeueErrs = append(requeueErrs, err)
+		} else if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errorutils.NewAggregate(append(errs, requeueErrs...))
+	}
+	if len(requeueErrs) > 0 {
+		return controller.RequeueErrorf("%v", errorutils.NewAggregate(requeueErrs))
+	}
+	return nil
+}
+
+// nodeToBeDrained returns true if the node is cordoned, tainted by cluster-autoscaler to be deleted
+// or annotated to be drained
+func nodeToBeDrained(node *corev1.Node) bool {
+	if node.Spec.Unschedulable || node.Annotations[label.AnnNodeMaintenance] == "true" {
+		return true
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == toBeDeletedTaintKey {
+			return true
+		}
+	}
+	return false
+}
+
+// prepare moves the leaders away from the pod on the node to be drained
+func (m *nodeMaintainer) prepare(tc *v1alpha1.TidbCluster, pod *corev1.Pod, node *corev1.Node) error {
+	switch pod.Labels[label.ComponentLabelKey] {
+	case label.TiKVLabelVal:
+		return m.evictTiKVLeaders(tc, pod, node)
+	case label.PDLabelVal:
+		return m.transferPDLeader(tc, pod, node)
+	case label.TiCDCLabelVal:
+		return m.drainTiCDCCapture(tc, pod, node)
+	}
+	return nil
+}
+
+func (m *nodeMaintainer) evictTiKVLeaders(tc *v1alpha1.TidbCluster, pod *corev1.Pod, node *corev1.Node) error {
+	ns := tc.GetNamespace()
+	storeID, ok := pod.Labels[label.StoreIDLabelKey]
+	if !ok {
+		return nil
+	}
+	id, err := strconv.ParseUint(storeID, 10, 64)
+	if err != nil {
+		return err
+	}
+	pvcName := fmt.Sprintf("%s-%s", v1alpha1.TiKVMemberType, pod.Name)
+	pvc, err := m.deps.PVCLister.PersistentVolumeClaims(ns).Get(pvcName)
+	if err != nil {
+		return fmt.Errorf("node maintainer: failed to get pvc %s/%s, error: %v", ns, pvcName, err)
+	}
+
+	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
+	if pvc.Annotations[label.AnnNodeMaintenanceEvictingStore] != storeID {
+		if err := pdClient.BeginEvictLeader(id); err != nil {
+			return fmt.Errorf("node maintainer: failed to evict leaders of store %d of pod %s/%s, error: %v", id, ns, pod.Name, err)
+		}
+		pvc = pvc.DeepCopy()
+		if pvc.Annotations == nil {
+			pvc.Annotations = map[string]string{}
+		}
+		pvc.Annotations[label.AnnNodeMaintenanceEvictingStore] = storeID
+		if _, err := m.deps.PVCControl.UpdatePVC(tc, pvc); err != nil {
+			return err
+		}
+		klog.Infof("node maintainer: begin to evict leaders of store %d of pod %s/%s on node %s", id, ns, pod.Name, node.Name)
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, NodeMaintenanceReason, "evicting leaders of store %d of pod %s before node %s is drained", id, pod.Name, node.Name)
+	}
+
+	count, err := pdClient.GetStoreLeaderCount(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return controller.RequeueErrorf("node maintainer: store %d of pod %s/%s still has %d leaders", id, ns, pod.Name, count)
+	}
+	return nil
+}
+
+func (m *nodeMaintainer) transferPDLeader(tc *v1alpha1.TidbCluster, pod *corev1.Pod, node *corev1.Node) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	ordinal, err := util.GetOrdinalFromPodName(pod.Name)
+	if err != nil {
+		return err
+	}
+	leader := tc.Status.PD.Leader.Name
+	if leader != pod.Name && leader != PdName(tcName, ordinal, ns, tc.Spec.ClusterDomain) {
+		return nil
+	}
+
+	// transfer the leader to the first healthy member not on the node in name order
+	names := make([]string, 0, len(tc.Status.PD.Members))
+	for name := range tc.Status.PD.Members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var target string
+	for _, name := range names {
+		if name == leader || !tc.Status.PD.Members[name].Health {
+			continue
+		}
+		// the member name is the pod name or the FQDN of the pod
+		podName := strings.SplitN(name, ".", 2)[0]
+		memberPod, err := m.deps.PodLister.Pods(ns).Get(podName)
+		if err != nil || memberPod.Spec.NodeName == node.Name {
+			continue
+		}
+		target = name
+		break
+	}
+	if target == "" {
+		return controller.RequeueErrorf("node maintainer: tidbcluster: [%s/%s] no pd member to transfer leader to from node %s", ns, tcName, node.Name)
+	}
+	if err := controller.GetPDClient(m.deps.PDControl, tc).TransferPDLeader(target); err != nil {
+		return fmt.Errorf("node maintainer: failed to transfer pd leader of tc %s/%s to %s, error: %v", ns, tcName, target, err)
+	}
+	klog.Infof("node maintainer: transfer pd leader of tc %s/%s from pod %s on node %s to %s", ns, tcName, pod.Name, node.Name, target)
+	m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, NodeMaintenanceReason, "transferring pd leader from pod %s to %s before node %s is drained", pod.Name, target, node.Name)
+	return controller.RequeueErrorf("node maintainer: tidbcluster: [%s/%s]'s pd member: [%s] is transferring leader to pd member: [%s]", ns, tcName, pod.Name, target)
+}
+
+func (m *nodeMaintainer) drainTiCDCCapture(tc *v1alpha1.TidbCluster, pod *corev1.Pod, node *corev1.Node) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	ordinal, err := util.GetOrdinalFromPodName(pod.Name)
+	if err != nil {
+		return err
+	}
+	capture, err := m.deps.CDCControl.GetStatus(tc, ordinal)
+	if err != nil {
+		return fmt.Errorf("node maintainer: failed to get status of ticdc pod %s/%s, error: %v", ns, pod.Name, err)
+	}
+
+	selector, err := label.New().Instance(tc.GetInstanceName()).TiCDC().Selector()
+	if err != nil {
+		return err
+	}
+	pods, err := m.deps.PodLister.Pods(ns).List(selector)
+	if err != nil {
+		return err
+	}
+	if len(pods) <= 1 {
+		// there is no other capture to move the tables to
+		return nil
+	}
+
+	if capture.IsOwner {
+		if err := m.deps.CDCControl.ResignOwner(tc, ordinal); err != nil {
+			return fmt.Errorf("node maintainer: failed to resign owner of ticdc pod %s/%s, error: %v", ns, pod.Name, err)
+		}
+		klog.Infof("node maintainer: owner of ticdc pod %s/%s on node %s resigns", ns, pod.Name, node.Name)
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, NodeMaintenanceReason, "ticdc owner %s resigns before node %s is drained", pod.Name, node.Name)
+		return controller.RequeueErrorf("node maintainer: tidbcluster: [%s/%s]'s ticdc pod: [%s] is resigning owner", ns, tcName, pod.Name)
+	}
+
+	// the capture is drained by the owner
+	ownerOrdinal := int32(-1)
+	for _, p := range pods {
+		o, err := util.GetOrdinalFromPodName(p.Name)
+		if err != nil || o == ordinal {
+			continue
+		}
+		status, err := m.deps.CDCControl.GetStatus(tc, o)
+		if err == nil && status.IsOwner {
+			ownerOrdinal = o
+			break
+		}
+	}
+	if ownerOrdinal < 0 {
+		return controller.RequeueErrorf("node maintainer: tidbcluster: [%s/%s] no ticdc owner found to drain capture of pod %s", ns, tcName, pod.Name)
+	}
+	count, err := m.deps.CDCControl.DrainCapture(tc, ownerOrdinal, capture.ID)
+	if err != nil {
+		return fmt.Errorf("node maintainer: failed to drain capture of ticdc pod %s/%s, error: %v", ns, pod.Name, err)
+	}
+	if count > 0 {
+		klog.Infof("node maintainer: draining capture %s of ticdc pod %s/%s on node %s, %d tables left", capture.ID, ns, pod.Name, node.Name, count)
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, NodeMaintenanceReason, "draining ticdc capture %s of pod %s before node %s is drained, %d tables left", capture.ID, pod.Name, node.Name, count)
+		return controller.RequeueErrorf("node maintainer: tidbcluster: [%s/%s]'s ticdc pod: [%s] still has %d tables", ns, tcName, pod.Name, count)
+	}
+	return nil
+}
+
+// restore removes the evict leader scheduler of the store of the pod once it is up on a node which
+// is not to be drained
+func (m *nodeMaintainer) restore(tc *v1alpha1.TidbCluster, pod *corev1.Pod) error {
+	if pod.Labels[label.ComponentLabelKey] != label.TiKVLabelVal || !podutil.IsPodReady(pod) {
+		return nil
+	}
+	ns := tc.GetNamespace()
+	pvcName := fmt.Sprintf("%s-%s", v1alpha1.TiKVMemberType, pod.Name)
+	pvc, err := m.deps.PVCLister.PersistentVolumeClaims(ns).Get(pvcName)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("node maintainer: failed to get pvc %s/%s, error: %v", ns, pvcName, err)
+	}
+	storeID, ok := pvc.Annotations[label.AnnNodeMaintenanceEvictingStore]
+	if !ok {
+		return nil
+	}
+	if store, ok := tc.Status.TiKV.Stores[storeID]; !ok || store.State != v1alpha1.TiKVStateUp {
+		return nil
+	}
+	id, err := strconv.ParseUint(storeID, 10, 64)
+	if err != nil {
+		return err
+	}
+	if err := endEvictLeaderbyStoreID(m.deps, tc, id); err != nil {
+		return err
+	}
+	pvc = pvc.DeepCopy()
+	delete(pvc.Annotations, label.AnnNodeMaintenanceEvictingStore)
+	if _, err := m.deps.PVCControl.UpdatePVC(tc, pvc); err != nil {
+		return err
+	}
+	m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, NodeMaintenanceReason, "leaders are allowed on store %d of pod %s again on node %s", id, pod.Name, pod.Spec.NodeName)
+	return nil
+}
+
+type fakeNodeMaintainer struct{}
+
+// NewFakeNodeMaintainer returns a fake node maintainer
+func NewFakeNodeMaintainer() NodeMaintainer {
+	return &fakeNodeMaintainer{}
+}
+
+func (m *fakeNodeMaintainer) Sync(_ *corev1.Node) error {
+	return nil
+}
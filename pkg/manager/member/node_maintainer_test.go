@@ -0,0 +1,192 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNodeToBeDrained(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	g.Expect(nodeToBeDrained(node)).To(BeFalse())
+	node.Spec.Unschedulable = true
+	g.Expect(nodeToBeDrained(node)).To(BeTrue())
+	node.Spec.Unschedulable = false
+	node.Spec.Taints = []corev1.Taint{{Key: toBeDeletedTaintKey, Effect: corev1.TaintEffectNoSchedule}}
+	g.Expect(nodeToBeDrained(node)).To(BeTrue())
+	node.Spec.Taints = nil
+	node.Annotations = map[string]string{label.AnnNodeMaintenance: "true"}
+	g.Expect(nodeToBeDrained(node)).To(BeTrue())
+}
+
+func TestNodeMaintainerTiKV(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	tc := newTidbClusterForPD()
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+		"1": {ID: "1", PodName: "test-tikv-0", State: v1alpha1.TiKVStateUp},
+	}
+	deps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer().Add(tc)
+
+	l := label.New().Instance(tc.Name).TiKV().Labels()
+	l[label.StoreIDLabelKey] = "1"
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-tikv-0", Namespace: tc.Namespace, Labels: l},
+		Spec:       corev1.PodSpec{NodeName: "node-1"},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Add(pod)
+	deps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer().Add(&corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "tikv-test-tikv-0", Namespace: tc.Namespace},
+	})
+
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	evicting := false
+	pdClient.AddReaction(pdapi.BeginEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		g.Expect(action.ID).To(Equal(uint64(1)))
+		evicting = true
+		return nil, nil
+	})
+	pdClient.AddReaction(pdapi.EndEvictLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		g.Expect(action.ID).To(Equal(uint64(1)))
+		evicting = false
+		return nil, nil
+	})
+	leaderCount := 10
+	pdClient.AddReaction(pdapi.GetStoreLeaderCountActionType, func(action *pdapi.Action) (interface{}, error) {
+		return leaderCount, nil
+	})
+
+	maintainer := NewNodeMaintainer(deps)
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Spec: corev1.NodeSpec{Unschedulable: true}}
+
+	// the leaders are being evicted
+	err := maintainer.Sync(node)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(evicting).To(BeTrue())
+	pvc, _ := deps.PVCLister.PersistentVolumeClaims(tc.Namespace).Get("tikv-test-tikv-0")
+	g.Expect(pvc.Annotations[label.AnnNodeMaintenanceEvictingStore]).To(Equal("1"))
+
+	// the leaders are evicted
+	leaderCount = 0
+	g.Expect(maintainer.Sync(node)).To(Succeed())
+
+	// the evict leader scheduler is removed after the node is uncordoned
+	node.Spec.Unschedulable = false
+	g.Expect(maintainer.Sync(node)).To(Succeed())
+	g.Expect(evicting).To(BeFalse())
+	pvc, _ = deps.PVCLister.PersistentVolumeClaims(tc.Namespace).Get("tikv-test-tikv-0")
+	g.Expect(pvc.Annotations).NotTo(HaveKey(label.AnnNodeMaintenanceEvictingStore))
+}
+
+func TestNodeMaintainerPD(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	tc := newTidbClusterForPD()
+	tc.Status.PD.Leader = v1alpha1.PDMember{Name: "test-pd-0", Health: true}
+	tc.Status.PD.Members = map[string]v1alpha1.PDMember{
+		"test-pd-0": {Name: "test-pd-0", Health: true},
+		"test-pd-1": {Name: "test-pd-1", Health: true},
+		"test-pd-2": {Name: "test-pd-2", Health: true},
+	}
+	deps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer().Add(tc)
+	// test-pd-1 is on the same node as the leader
+	for i, nodeName := range []string{"node-1", "node-1", "node-2"} {
+		deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Add(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      PdPodName(tc.Name, int32(i)),
+				Namespace: tc.Namespace,
+				Labels:    label.New().Instance(tc.Name).PD().Labels(),
+			},
+			Spec: corev1.PodSpec{NodeName: nodeName},
+		})
+	}
+
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	var target string
+	pdClient.AddReaction(pdapi.TransferPDLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		target = action.Name
+		return nil, nil
+	})
+
+	maintainer := NewNodeMaintainer(deps)
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1", Annotations: map[string]string{label.AnnNodeMaintenance: "true"}}}
+	err := maintainer.Sync(node)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(target).To(Equal("test-pd-2"))
+
+	// nothing to do on the other node
+	target = ""
+	node = &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}, Spec: corev1.NodeSpec{Unschedulable: true}}
+	g.Expect(maintainer.Sync(node)).To(Succeed())
+	g.Expect(target).To(BeEmpty())
+}
+
+func TestNodeMaintainerTiCDC(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	cdcControl := controller.NewFakeTiCDCControl()
+	deps.CDCControl = cdcControl
+	tc := newTidbClusterForPD()
+	deps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer().Add(tc)
+	for i, nodeName := range []string{"node-1", "node-2"} {
+		deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Add(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      ordinalPodName(v1alpha1.TiCDCMemberType, tc.Name, int32(i)),
+				Namespace: tc.Namespace,
+				Labels:    label.New().Instance(tc.Name).TiCDC().Labels(),
+			},
+			Spec: corev1.PodSpec{NodeName: nodeName},
+		})
+	}
+	cdcControl.SetCaptureStatus(0, &controller.CaptureStatus{ID: "capture-0", IsOwner: true})
+	cdcControl.SetCaptureStatus(1, &controller.CaptureStatus{ID: "capture-1"})
+	cdcControl.SetTableCount("capture-0", 5)
+
+	maintainer := NewNodeMaintainer(deps)
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: toBeDeletedTaintKey, Effect: corev1.TaintEffectNoSchedule}}},
+	}
+
+	// the owner on the node resigns first
+	err := maintainer.Sync(node)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(cdcControl.Resigned()).To(Equal([]int32{0}))
+
+	// the capture is drained by the new owner
+	cdcControl.SetCaptureStatus(0, &controller.CaptureStatus{ID: "capture-0"})
+	cdcControl.SetCaptureStatus(1, &controller.CaptureStatus{ID: "capture-1", IsOwner: true})
+	err = maintainer.Sync(node)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(cdcControl.Drained()).To(Equal([]string{"capture-0"}))
+
+	cdcControl.SetTableCount("capture-0", 0)
+	g.Expect(maintainer.Sync(node)).To(Succeed())
+}
@@ -0,0 +1,269 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/pingcap/tidb-operator/pkg/util/crypto"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+const (
+	// ClusterDomainMigrateReason is the reason of the events emitted by the cluster domain migration
+	ClusterDomainMigrateReason = "ClusterDomainMigrate"
+)
+
+// syncClusterDomainMigration migrates the PD members to `spec.clusterDomain` when it is changed on a live cluster.
+//
+// The members advertise the peer URLs in the cluster domain they are started with, which are persisted in
+// the embedded etcd, so rolling the pods to the new cluster domain directly makes the members unable to reach
+// each other. Instead the migration is tracked in `status.pd.clusterDomainMigration` and done step by step:
+// - WaitingForCerts: if TLS is enabled, wait until the certificate of PD covers the hosts in the new cluster
+// domain and has been reloaded by the pods, the certificates cover both cluster domains during the migration
+// - UpdatingPeerURLs: the peer URLs of the members are updated to the new cluster domain one member per sync
+// by the etcd API, only when all members are healthy
+// - RollingPods: the pods are rolled by the upgrader to advertise the URLs in the new cluster domain, the
+// migration is complete when all members advertise the client URLs in the new cluster domain
+//
+// Both cluster domains must be resolvable during the migration. The PD StatefulSet is not synced until the
+// RollingPods step, so the pods are not restarted with the new cluster domain before their peer URLs are updated.
+func (m *pdMemberManager) syncClusterDomainMigration(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	migration := tc.Status.PD.ClusterDomainMigration
+	if migration == nil {
+		from, outdated := outdatedPDClusterDomain(tc)
+		if !outdated {
+			return nil
+		}
+		now := metav1.Now()
+		migration = &v1alpha1.ClusterDomainMigrationStatus{
+			From:      from,
+			To:        tc.Spec.ClusterDomain,
+			Phase:     v1alpha1.ClusterDomainMigrationWaitingForCerts,
+			StartTime: &now,
+		}
+		tc.Status.PD.ClusterDomainMigration = migration
+		klog.Infof("tidbcluster: [%s/%s] begin to migrate pd members from cluster domain %q to %q", ns, tcName, migration.From, migration.To)
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, ClusterDomainMigrateReason, "begin to migrate pd members from cluster domain %q to %q", migration.From, migration.To)
+	}
+
+	if migration.To != tc.Spec.ClusterDomain {
+		if migration.Phase != v1alpha1.ClusterDomainMigrationWaitingForCerts {
+			migration.Message = fmt.Sprintf("spec.clusterDomain is changed during the migration, it must be %q until the migration completes", migration.To)
+			return controller.RequeueErrorf("tidbcluster: [%s/%s]'s pd members are being migrated to cluster domain %q, but spec.clusterDomain is %q", ns, tcName, migration.To, tc.Spec.ClusterDomain)
+		}
+		// no member is migrated yet, restart the migration to the latest cluster domain
+		tc.Status.PD.ClusterDomainMigration = nil
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, ClusterDomainMigrateReason, "cancel migrating pd members to cluster domain %q as spec.clusterDomain is changed to %q", migration.To, tc.Spec.ClusterDomain)
+		return m.syncClusterDomainMigration(tc)
+	}
+
+	switch migration.Phase {
+	case v1alpha1.ClusterDomainMigrationWaitingForCerts:
+		if msg, err := m.pdCertsReadyForClusterDomain(tc); err != nil || msg != "" {
+			if err != nil {
+				return err
+			}
+			migration.Message = msg
+			return controller.RequeueErrorf("tidbcluster: [%s/%s]'s pd cluster domain migration is waiting for certificates: %s", ns, tcName, msg)
+		}
+		migration.Phase = v1alpha1.ClusterDomainMigrationUpdatingPeerURLs
+		migration.Message = ""
+		fallthrough
+	case v1alpha1.ClusterDomainMigrationUpdatingPeerURLs:
+		if !tc.PDAllMembersReady() {
+			migration.Message = "waiting for all pd members to be healthy"
+			return controller.RequeueErrorf("tidbcluster: [%s/%s]'s pd cluster domain migration is waiting for all pd members to be healthy", ns, tcName)
+		}
+		updated, err := m.updatePDMemberPeerURLs(tc, migration)
+		if err != nil {
+			return err
+		}
+		if updated {
+			return controller.RequeueErrorf("tidbcluster: [%s/%s]'s pd members are being migrated to cluster domain %q", ns, tcName, migration.To)
+		}
+		migration.Phase = v1alpha1.ClusterDomainMigrationRollingPods
+		migration.Message = ""
+		klog.Infof("tidbcluster: [%s/%s] peer urls of pd members are updated to cluster domain %q, roll the pods", ns, tcName, migration.To)
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, ClusterDomainMigrateReason, "peer urls of pd members are updated to cluster domain %q, roll the pods", migration.To)
+		return nil
+	case v1alpha1.ClusterDomainMigrationRollingPods:
+		if _, outdated := outdatedPDClusterDomain(tc); outdated || !tc.PDAllMembersReady() {
+			return nil
+		}
+		tc.Status.PD.ClusterDomainMigration = nil
+		klog.Infof("tidbcluster: [%s/%s] pd members are migrated to cluster domain %q", ns, tcName, migration.To)
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, ClusterDomainMigrateReason, "pd members are migrated to cluster domain %q", migration.To)
+	}
+	return nil
+}
+
+// updatePDMemberPeerURLs updates the peer URLs of the first member in name order which are not in the
+// new cluster domain, updated is false if the peer URLs of all members are in the new cluster domain
+func (m *pdMemberManager) updatePDMemberPeerURLs(tc *v1alpha1.TidbCluster, migration *v1alpha1.ClusterDomainMigrationStatus) (bool, error) {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
+	members, err := pdClient.GetMembers()
+	if err != nil {
+		return false, fmt.Errorf("tidbcluster: [%s/%s] failed to get pd members, error: %v", ns, tcName, err)
+	}
+	// the members may be cached by the client, so they are sorted in a copy
+	sorted := append([]*pdpb.Member(nil), members.Members...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	for _, member := range sorted {
+		var peerURLs []string
+		outdated := false
+		for _, peerURL := range member.PeerUrls {
+			podName, domain, ok := pdMemberURLDomain(tc, peerURL)
+			if !ok {
+				// not a member of the TidbCluster, e.g. a member of a heterogeneous cluster
+				outdated = false
+				break
+			}
+			if domain != migration.To {
+				outdated = true
+			}
+			u, _ := url.Parse(peerURL)
+			u.Host = net.JoinHostPort(pdMemberHost(tc, podName, migration.To), u.Port())
+			peerURLs = append(peerURLs, u.String())
+		}
+		if !outdated {
+			continue
+		}
+		if err := pdClient.UpdateMemberPeerURLs(member.MemberId, peerURLs); err != nil {
+			migration.Message = fmt.Sprintf("failed to update the peer urls of pd member %s: %v", member.Name, err)
+			return false, fmt.Errorf("tidbcluster: [%s/%s] failed to update the peer urls of pd member %s to %v, error: %v", ns, tcName, member.Name, peerURLs, err)
+		}
+		migration.MigratedMembers = append(migration.MigratedMembers, member.Name)
+		migration.Message = ""
+		klog.Infof("tidbcluster: [%s/%s] peer urls of pd member %s are updated to %v", ns, tcName, member.Name, peerURLs)
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, ClusterDomainMigrateReason, "peer urls of pd member %s are updated to %s", member.Name, strings.Join(peerURLs, ","))
+		return true, nil
+	}
+	return false, nil
+}
+
+// pdCertsReadyForClusterDomain returns the reason why the certificate of PD is not ready for the new
+// cluster domain, empty if it is ready or TLS is not enabled
+func (m *pdMemberManager) pdCertsReadyForClusterDomain(tc *v1alpha1.TidbCluster) (string, error) {
+	if !tc.IsTLSClusterEnabled() {
+		return "", nil
+	}
+	ns := tc.GetNamespace()
+	secretName := util.ClusterTLSSecretName(tc.GetName(), v1alpha1.PDMemberType.String())
+	// the secret is got from the apiserver, as the cache may be stale just after the certificate is re-issued
+	secret, err := m.deps.KubeClientset.CoreV1().Secrets(ns).Get(secretName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return fmt.Sprintf("secret %s is not found", secretName), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("tidbcluster: [%s/%s] failed to get secret %s, error: %v", ns, tc.GetName(), secretName, err)
+	}
+	cert, err := crypto.DecodeCertificate(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return "", fmt.Errorf("tidbcluster: [%s/%s] failed to decode the certificate in secret %s, error: %v", ns, tc.GetName(), secretName, err)
+	}
+	for name := range tc.Status.PD.Members {
+		podName, _, ok := pdMemberURLDomain(tc, tc.Status.PD.Members[name].ClientURL)
+		if !ok {
+			continue
+		}
+		host := pdMemberHost(tc, podName, tc.Spec.ClusterDomain)
+		if err := cert.VerifyHostname(host); err != nil {
+			return fmt.Sprintf("the certificate in secret %s does not cover %s", secretName, host), nil
+		}
+	}
+	if status, ok := tc.Status.TLSCerts[v1alpha1.PDMemberType.String()]; ok {
+		switch status.Phase {
+		case v1alpha1.TLSCertRenewing, v1alpha1.TLSCertPendingReload, v1alpha1.TLSCertReloading:
+			return fmt.Sprintf("the certificate in secret %s is %s", secretName, status.Phase), nil
+		}
+	}
+	return "", nil
+}
+
+// outdatedPDClusterDomain returns the cluster domain of the first member in name order which does not
+// advertise the client URL in `spec.clusterDomain`, outdated is false if there is no such member
+func outdatedPDClusterDomain(tc *v1alpha1.TidbCluster) (string, bool) {
+	var names []string
+	for name := range tc.Status.PD.Members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, domain, ok := pdMemberURLDomain(tc, tc.Status.PD.Members[name].ClientURL)
+		if ok && domain != tc.Spec.ClusterDomain {
+			return domain, true
+		}
+	}
+	return "", false
+}
+
+// pdMemberURLDomain returns the pod name and the cluster domain in the URL advertised by a PD member,
+// ok is false if the URL is not advertised by a member of the TidbCluster
+func pdMemberURLDomain(tc *v1alpha1.TidbCluster, rawURL string) (podName string, domain string, ok bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", false
+	}
+	host := u.Hostname()
+	suffix := fmt.Sprintf(".%s.%s.svc", controller.PDPeerMemberName(tc.GetName()), tc.GetNamespace())
+	i := strings.Index(host, suffix)
+	if i <= 0 {
+		return "", "", false
+	}
+	podName = host[:i]
+	if !strings.HasPrefix(podName, controller.PDMemberName(tc.GetName())+"-") {
+		return "", "", false
+	}
+	domain = host[i+len(suffix):]
+	if domain != "" {
+		if !strings.HasPrefix(domain, ".") {
+			return "", "", false
+		}
+		domain = domain[1:]
+	}
+	return podName, domain, true
+}
+
+// pdMemberHost returns the host advertised by the PD member of the pod in the cluster domain
+func pdMemberHost(tc *v1alpha1.TidbCluster, podName, clusterDomain string) string {
+	return fmt.Sprintf("%s.%s.%s.svc%s", podName, controller.PDPeerMemberName(tc.GetName()), tc.GetNamespace(), controller.FormatClusterDomain(clusterDomain))
+}
+
+// pdMemberNames returns the names the PD member of the pod with the ordinal may have, the member is named
+// after the cluster domain it is started with, which may be the old one during the cluster domain migration
+func pdMemberNames(tc *v1alpha1.TidbCluster, ordinal int32) []string {
+	names := []string{PdName(tc.GetName(), ordinal, tc.GetNamespace(), tc.Spec.ClusterDomain), PdPodName(tc.GetName(), ordinal)}
+	if migration := tc.Status.PD.ClusterDomainMigration; migration != nil && migration.From != "" {
+		names = append(names, PdName(tc.GetName(), ordinal, tc.GetNamespace(), migration.From))
+	}
+	return names
+}
@@ -0,0 +1,167 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+)
+
+func TestPDClusterDomainMigration(t *testing.T) {
+	g := NewGomegaWithT(t)
+	pmm, _, _ := newFakePDMemberManager()
+	tc := newTidbClusterForPD()
+	tc.Spec.ClusterDomain = "cluster.local"
+	setPDMembersInClusterDomain(tc, "")
+
+	// the peer urls persisted in the embedded etcd
+	peerURLs := map[uint64]string{}
+	for i := 0; i < 3; i++ {
+		peerURLs[uint64(i)] = fmt.Sprintf("http://test-pd-%d.test-pd-peer.default.svc:2380", i)
+	}
+	pdClient := controller.NewFakePDClient(pmm.deps.PDControl.(*pdapi.FakePDControl), tc)
+	pdClient.AddReaction(pdapi.GetMembersActionType, func(action *pdapi.Action) (interface{}, error) {
+		members := &pdapi.MembersInfo{}
+		for id, peerURL := range peerURLs {
+			members.Members = append(members.Members, &pdpb.Member{
+				Name:     PdPodName("test", int32(id)),
+				MemberId: id,
+				PeerUrls: []string{peerURL},
+			})
+		}
+		return members, nil
+	})
+	var updated []string
+	pdClient.AddReaction(pdapi.UpdateMemberPeerURLsActionType, func(action *pdapi.Action) (interface{}, error) {
+		g.Expect(action.PeerURLs).To(HaveLen(1))
+		peerURLs[action.ID] = action.PeerURLs[0]
+		updated = append(updated, action.PeerURLs[0])
+		return nil, nil
+	})
+
+	// the peer urls are updated one member per sync
+	for i := 0; i < 3; i++ {
+		err := pmm.syncClusterDomainMigration(tc)
+		g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+		g.Expect(updated).To(HaveLen(i + 1))
+		g.Expect(updated[i]).To(Equal(fmt.Sprintf("http://test-pd-%d.test-pd-peer.default.svc.cluster.local:2380", i)))
+	}
+	migration := tc.Status.PD.ClusterDomainMigration
+	g.Expect(migration).NotTo(BeNil())
+	g.Expect(migration.From).To(Equal(""))
+	g.Expect(migration.To).To(Equal("cluster.local"))
+	g.Expect(migration.MigratedMembers).To(Equal([]string{"test-pd-0", "test-pd-1", "test-pd-2"}))
+
+	// the pods are rolled after all the peer urls are updated
+	g.Expect(pmm.syncClusterDomainMigration(tc)).To(Succeed())
+	g.Expect(migration.Phase).To(Equal(v1alpha1.ClusterDomainMigrationRollingPods))
+	g.Expect(pmm.syncClusterDomainMigration(tc)).To(Succeed())
+	g.Expect(tc.Status.PD.ClusterDomainMigration).NotTo(BeNil())
+
+	setPDMembersInClusterDomain(tc, "cluster.local")
+	g.Expect(pmm.syncClusterDomainMigration(tc)).To(Succeed())
+	g.Expect(tc.Status.PD.ClusterDomainMigration).To(BeNil())
+	g.Expect(updated).To(HaveLen(3))
+}
+
+func TestPDClusterDomainMigrationBlocked(t *testing.T) {
+	g := NewGomegaWithT(t)
+	pmm, _, _ := newFakePDMemberManager()
+	tc := newTidbClusterForPD()
+	tc.Spec.ClusterDomain = "cluster.local"
+	setPDMembersInClusterDomain(tc, "")
+
+	// no peer url is updated while a member is unhealthy
+	member := tc.Status.PD.Members["test-pd-1"]
+	member.Health = false
+	tc.Status.PD.Members["test-pd-1"] = member
+	err := pmm.syncClusterDomainMigration(tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tc.Status.PD.ClusterDomainMigration.Phase).To(Equal(v1alpha1.ClusterDomainMigrationUpdatingPeerURLs))
+	g.Expect(tc.Status.PD.ClusterDomainMigration.Message).To(ContainSubstring("healthy"))
+
+	// the cluster domain can not be changed again once the migration begins to update the peer urls
+	tc.Spec.ClusterDomain = "cluster2.local"
+	err = pmm.syncClusterDomainMigration(tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tc.Status.PD.ClusterDomainMigration.To).To(Equal("cluster.local"))
+	g.Expect(tc.Status.PD.ClusterDomainMigration.Message).To(ContainSubstring("spec.clusterDomain"))
+
+	// but it can be changed if the certificates are not ready yet
+	tc.Status.PD.ClusterDomainMigration.Phase = v1alpha1.ClusterDomainMigrationWaitingForCerts
+	err = pmm.syncClusterDomainMigration(tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(tc.Status.PD.ClusterDomainMigration.To).To(Equal("cluster2.local"))
+}
+
+func TestPDMemberURLDomain(t *testing.T) {
+	g := NewGomegaWithT(t)
+	tc := newTidbClusterForPD()
+
+	tests := []struct {
+		url     string
+		podName string
+		domain  string
+		ok      bool
+	}{
+		{url: "http://test-pd-0.test-pd-peer.default.svc:2379", podName: "test-pd-0", domain: "", ok: true},
+		{url: "https://test-pd-1.test-pd-peer.default.svc.cluster.local:2380", podName: "test-pd-1", domain: "cluster.local", ok: true},
+		{url: "http://test-pd-0.test-pd-peer.default.svcx:2379", ok: false},
+		{url: "http://peer-pd-0.peer-pd-peer.default.svc:2379", ok: false},
+		{url: "http://test-pd-0.test-pd-peer.other.svc:2379", ok: false},
+	}
+	for _, test := range tests {
+		podName, domain, ok := pdMemberURLDomain(tc, test.url)
+		g.Expect(ok).To(Equal(test.ok), test.url)
+		g.Expect(podName).To(Equal(test.podName), test.url)
+		g.Expect(domain).To(Equal(test.domain), test.url)
+	}
+}
+
+func TestClusterTLSCertDNSNamesDuringClusterDomainMigration(t *testing.T) {
+	g := NewGomegaWithT(t)
+	tc := newTidbClusterForPD()
+	tc.Spec.ClusterDomain = "cluster2.local"
+	tc.Status.PD.ClusterDomainMigration = &v1alpha1.ClusterDomainMigrationStatus{From: "cluster1.local", To: "cluster2.local"}
+
+	dnsNames := clusterTLSCertDNSNames(tc, v1alpha1.PDMemberType)
+	g.Expect(dnsNames).To(ContainElement("*.test-pd-peer.default.svc.cluster1.local"))
+	g.Expect(dnsNames).To(ContainElement("*.test-pd-peer.default.svc.cluster2.local"))
+
+	tc.Status.PD.ClusterDomainMigration = nil
+	for _, name := range clusterTLSCertDNSNames(tc, v1alpha1.PDMemberType) {
+		g.Expect(strings.HasSuffix(name, "cluster1.local")).To(BeFalse())
+	}
+}
+
+// setPDMembersInClusterDomain sets the members of the PD pods in the status as healthy members
+// advertising the client URLs in the cluster domain
+func setPDMembersInClusterDomain(tc *v1alpha1.TidbCluster, clusterDomain string) {
+	tc.Status.PD.Members = map[string]v1alpha1.PDMember{}
+	for i := int32(0); i < tc.Spec.PD.Replicas; i++ {
+		name := PdName(tc.Name, i, tc.Namespace, clusterDomain)
+		tc.Status.PD.Members[name] = v1alpha1.PDMember{
+			Name:      name,
+			ClientURL: fmt.Sprintf("http://%s:2379", pdMemberHost(tc, PdPodName(tc.Name, i), clusterDomain)),
+			Health:    true,
+		}
+	}
+}
@@ -15,6 +15,7 @@ package member
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -35,9 +36,9 @@ type pdFailover struct {
 
 // NewPDFailover returns a pd Failover
 func NewPDFailover(deps *controller.Dependencies) Failover {
-	return &pdFailover{
+	return newSimulatedFailover(deps, v1alpha1.PDMemberType, &pdFailover{
 		deps: deps,
-	}
+	})
 }
 
 // Failover is used to failover broken pd member
@@ -65,6 +66,10 @@ func (f *pdFailover) Failover(tc *v1alpha1.TidbCluster) error {
 			ns, tcName, healthCount, tc.PDStsDesiredReplicas(), tc.Spec.PD.Replicas, len(tc.Status.PD.FailureMembers))
 	}
 
+	if tc.Spec.PD.MaxFailoverCount == nil {
+		klog.Infof("pd failover is disabled for %s/%s, skipped", ns, tcName)
+		return nil
+	}
 	pdDeletedFailureReplicas := tc.GetPDDeletedFailureReplicas()
 	if pdDeletedFailureReplicas >= *tc.Spec.PD.MaxFailoverCount {
 		klog.Errorf("PD failover replicas (%d) reaches the limit (%d), skip failover", pdDeletedFailureReplicas, *tc.Spec.PD.MaxFailoverCount)
@@ -78,11 +83,35 @@ func (f *pdFailover) Failover(tc *v1alpha1.TidbCluster) error {
 		return f.tryToMarkAPeerAsFailure(tc)
 	}
 
+	// the failure member is never deleted if the failover is simulated
+	if tc.Spec.FailoverSimulation {
+		klog.Infof("pd failover of %s/%s is simulated, skip deleting the failure member", ns, tcName)
+		return nil
+	}
 	return f.tryToDeleteAFailureMember(tc)
 }
 
 func (f *pdFailover) Recover(tc *v1alpha1.TidbCluster) {
+	pdNames := make([]string, 0, len(tc.Status.PD.FailureMembers))
+	podNames := make([]string, 0, len(tc.Status.PD.FailureMembers))
+	for pdName, failureMember := range tc.Status.PD.FailureMembers {
+		pdNames = append(pdNames, pdName)
+		podNames = append(podNames, failureMember.PodName)
+	}
+	sort.Strings(pdNames)
+	sort.Strings(podNames)
+	for _, pdName := range pdNames {
+		failureMember := tc.Status.PD.FailureMembers[pdName]
+		notifyFailover(f.deps, tc, controller.FailoverNotification{
+			Component: v1alpha1.PDMemberType.String(),
+			Action:    controller.FailoverRecovered,
+			PodName:   failureMember.PodName,
+			MemberID:  failureMember.MemberID,
+			Reason:    "member is recovered, removing the failover replica",
+		})
+	}
 	tc.Status.PD.FailureMembers = nil
+	f.deps.Recorder.Eventf(tc, apiv1.EventTypeNormal, failoverRecoveredEventReason, "pd failure members of pods %v are recovered, removing the failover replicas", podNames)
 	klog.Infof("pd failover: clearing pd failoverMembers, %s/%s", tc.GetNamespace(), tc.GetName())
 }
 
@@ -118,7 +147,14 @@ func (f *pdFailover) tryToMarkAPeerAsFailure(tc *v1alpha1.TidbCluster) error {
 			return fmt.Errorf("tryToMarkAPeerAsFailure: failed to get pvcs for pod %s/%s, error: %s", ns, pod.Name, err)
 		}
 
-		f.deps.Recorder.Eventf(tc, apiv1.EventTypeWarning, "PDMemberUnhealthy", "%s/%s(%s) is unhealthy", ns, podName, pdMember.ID)
+		recordFailoverEvent(f.deps, tc, apiv1.EventTypeWarning, "PDMemberUnhealthy", "%s/%s(%s) is unhealthy", ns, podName, pdMember.ID)
+		notifyFailover(f.deps, tc, controller.FailoverNotification{
+			Component: v1alpha1.PDMemberType.String(),
+			Action:    controller.FailoverMemberFailed,
+			PodName:   podName,
+			MemberID:  pdMember.ID,
+			Reason:    fmt.Sprintf("member is unhealthy for more than %s", f.deps.CLIConfig.PDFailoverPeriod),
+		})
 
 		// mark a peer member failed and return an error to skip reconciliation
 		// note that status of tidb cluster will be updated always
@@ -226,6 +262,13 @@ func (f *pdFailover) tryToDeleteAFailureMember(tc *v1alpha1.TidbCluster) error {
 	}
 
 	setMemberDeleted(tc, failurePDName)
+	notifyFailover(f.deps, tc, controller.FailoverNotification{
+		Component: v1alpha1.PDMemberType.String(),
+		Action:    controller.FailoverReplacementCreated,
+		PodName:   failurePodName,
+		MemberID:  failureMember.MemberID,
+		Reason:    fmt.Sprintf("failure member is deleted from PD cluster, a replacement replica is added, failover replicas %d/%d", tc.GetPDDeletedFailureReplicas(), *tc.Spec.PD.MaxFailoverCount),
+	})
 	return nil
 }
 
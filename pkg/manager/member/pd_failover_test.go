@@ -618,8 +618,18 @@ func TestPDFailoverRecovery(t *testing.T) {
 		test.update(tc)
 
 		pdFailover, _, _, _, _, _ := newFakePDFailover()
+		failureCount := len(tc.Status.PD.FailureMembers)
 		pdFailover.Recover(tc)
 		test.expectFn(tc)
+		events := collectEvents(pdFailover.deps.Recorder.(*record.FakeRecorder).Events)
+		g.Expect(events).To(HaveLen(1))
+		g.Expect(events[0]).To(ContainSubstring(failoverRecoveredEventReason))
+		notifications := pdFailover.deps.FailoverNotifier.(*controller.FakeFailoverNotifier).Notifications()
+		g.Expect(notifications).To(HaveLen(failureCount))
+		for _, n := range notifications {
+			g.Expect(n.Action).To(Equal(controller.FailoverRecovered))
+			g.Expect(n.Component).To(Equal(v1alpha1.PDMemberType.String()))
+		}
 	}
 	tests := []testcase{
 		{
@@ -18,6 +18,7 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -26,10 +27,13 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/label"
 	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
 	"github.com/pingcap/tidb-operator/pkg/util"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -49,6 +53,10 @@ const (
 
 	//find a better way to manage store only managed by pd in Operator
 	pdMemberLimitPattern = `%s-pd-\d+\.%s-pd-peer\.%s\.svc%s\:\d+`
+
+	// pdEtcdLaggingRaftIndexThreshold is the max number of raft entries an etcd member
+	// can fall behind the leader before it is considered lagging
+	pdEtcdLaggingRaftIndexThreshold = 1000
 )
 
 type pdMemberManager struct {
@@ -97,7 +105,7 @@ func (m *pdMemberManager) syncPDServiceForTidbCluster(tc *v1alpha1.TidbCluster)
 	ns := tc.GetNamespace()
 	tcName := tc.GetName()
 
-	newSvc := m.getNewPDServiceForTidbCluster(tc)
+	newSvc := getNewPDServiceForTidbCluster(tc)
 	oldSvcTmp, err := m.deps.ServiceLister.Services(ns).Get(controller.PDMemberName(tcName))
 	if errors.IsNotFound(err) {
 		err = controller.SetServiceLastAppliedConfigAnnotation(newSvc)
@@ -125,8 +133,7 @@ func (m *pdMemberManager) syncPDServiceForTidbCluster(tc *v1alpha1.TidbCluster)
 			return err
 		}
 		svc.Spec.ClusterIP = oldSvc.Spec.ClusterIP
-		_, err = m.deps.ServiceControl.UpdateService(tc, &svc)
-		return err
+		return updateService(m.deps.ServiceControl, tc, newSvc, &svc)
 	}
 
 	return nil
@@ -165,8 +172,7 @@ func (m *pdMemberManager) syncPDHeadlessServiceForTidbCluster(tc *v1alpha1.TidbC
 		if err != nil {
 			return err
 		}
-		_, err = m.deps.ServiceControl.UpdateService(tc, &svc)
-		return err
+		return updateService(m.deps.ServiceControl, tc, newSvc, &svc)
 	}
 
 	return nil
@@ -193,10 +199,16 @@ func (m *pdMemberManager) syncPDStatefulSetForTidbCluster(tc *v1alpha1.TidbClust
 		return nil
 	}
 
+	// the pods must not be restarted in the new cluster domain before the peer urls of the members are updated
+	if err := m.syncClusterDomainMigration(tc); err != nil {
+		return err
+	}
+
 	cm, err := m.syncPDConfigMap(tc, oldPDSet)
 	if err != nil {
 		return err
 	}
+	syncConfigSyncedCondition(&tc.Status.PD.Conditions, oldPDSet, cm, controller.PDMemberName(tc.Name))
 	newPDSet, err := getNewPDSetForTidbCluster(tc, cm)
 	if err != nil {
 		return err
@@ -230,9 +242,12 @@ func (m *pdMemberManager) syncPDStatefulSetForTidbCluster(tc *v1alpha1.TidbClust
 		return err
 	}
 
-	if m.deps.CLIConfig.AutoFailover {
+	// the members are never deleted by the failover until the adoption completes
+	if m.deps.CLIConfig.AutoFailover && !tc.Adopting() {
 		if m.shouldRecover(tc) {
-			m.failover.Recover(tc)
+			if failoverRecoveryDue(tc.Spec.PD.FailoverRecovery, tc.Spec.PD.RecoverFailover, true, pdHealthySince(tc)) {
+				m.failover.Recover(tc)
+			}
 		} else if tc.PDAllPodsStarted() && !tc.PDAllMembersReady() || tc.PDAutoFailovering() {
 			if err := m.failover.Failover(tc); err != nil {
 				return err
@@ -241,9 +256,13 @@ func (m *pdMemberManager) syncPDStatefulSetForTidbCluster(tc *v1alpha1.TidbClust
 	}
 
 	if !templateEqual(newPDSet, oldPDSet) || tc.Status.PD.Phase == v1alpha1.UpgradePhase {
-		if err := m.upgrader.Upgrade(tc, oldPDSet, newPDSet); err != nil {
+		err := m.upgrader.Upgrade(tc, oldPDSet, newPDSet)
+		syncUpgradeProgress(&tc.Status.PD.UpgradeProgress, tc.Status.PD.Phase, tc.Status.PD.Conditions, newPDSet, &oldPDSet.Status, tc.PDImage())
+		if err != nil {
 			return err
 		}
+	} else {
+		tc.Status.PD.UpgradeProgress = nil
 	}
 
 	return UpdateStatefulSet(m.deps.StatefulSetControl, tc, newPDSet, oldPDSet)
@@ -285,6 +304,22 @@ func (m *pdMemberManager) shouldRecover(tc *v1alpha1.TidbCluster) bool {
 	return true
 }
 
+// syncPDDegradedCondition sets the PDDegraded condition if the calls to PD are short-circuited,
+// in which case the status is synced from the last-known responses of PD
+func (m *pdMemberManager) syncPDDegradedCondition(tc *v1alpha1.TidbCluster) {
+	state := m.deps.PDControl.GetPDCircuitState(pdapi.Namespace(tc.GetNamespace()), tc.GetName(), tc.IsTLSClusterEnabled())
+	if state != pdapi.CircuitClosed {
+		message := fmt.Sprintf("The calls to PD are short-circuited since PD is unreachable, the circuit is %s", state)
+		cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterPDDegraded, corev1.ConditionTrue, utiltidbcluster.PDCircuitOpen, message)
+		utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+		return
+	}
+	if utiltidbcluster.GetTidbClusterCondition(tc.Status, v1alpha1.TidbClusterPDDegraded) != nil {
+		cond := utiltidbcluster.NewTidbClusterCondition(v1alpha1.TidbClusterPDDegraded, corev1.ConditionFalse, utiltidbcluster.PDReachable, "PD is reachable")
+		utiltidbcluster.SetTidbClusterCondition(&tc.Status, *cond)
+	}
+}
+
 func (m *pdMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set *apps.StatefulSet) error {
 	if set == nil {
 		// skip if not created yet
@@ -310,6 +345,7 @@ func (m *pdMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set *a
 		tc.Status.PD.Phase = v1alpha1.NormalPhase
 	}
 
+	m.syncPDDegradedCondition(tc)
 	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
 
 	healthInfo, err := pdClient.GetHealth()
@@ -380,10 +416,14 @@ func (m *pdMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set *a
 			}
 			peerPDStatus[name] = status
 		}
+	}
 
-		if name == leader.GetName() {
-			tc.Status.PD.Leader = status
-		}
+	m.syncEtcdMemberStatus(tc, pdClient, pdStatus, peerPDStatus)
+	m.syncMicroServiceStatus(tc, pdClient)
+	if status, ok := pdStatus[leader.GetName()]; ok {
+		tc.Status.PD.Leader = status
+	} else if status, ok := peerPDStatus[leader.GetName()]; ok {
+		tc.Status.PD.Leader = status
 	}
 
 	tc.Status.PD.Synced = true
@@ -400,6 +440,92 @@ func (m *pdMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set *a
 	return nil
 }
 
+// syncMicroServiceStatus records the API version negotiated with PD, and the members of the PD
+// microservices discovered by the PD API v2 if available. The status is kept if PD fails to respond.
+func (m *pdMemberManager) syncMicroServiceStatus(tc *v1alpha1.TidbCluster, pdClient pdapi.PDClient) {
+	apiVersion, err := pdClient.GetAPIVersion()
+	if err != nil {
+		klog.V(4).Infof("failed to negotiate API version with PD of tc %s/%s, error: %v", tc.Namespace, tc.Name, err)
+		return
+	}
+	tc.Status.PD.APIVersion = string(apiVersion)
+	if apiVersion != pdapi.APIVersionV2 {
+		tc.Status.PD.MicroServices = nil
+		return
+	}
+
+	microServices := map[string][]string{}
+	for _, service := range []string{pdapi.PDServiceTSO, pdapi.PDServiceScheduling} {
+		members, err := pdClient.GetServiceMembers(service)
+		if err != nil {
+			klog.V(4).Infof("failed to get members of PD microservice %s of tc %s/%s, error: %v", service, tc.Namespace, tc.Name, err)
+			if addrs, ok := tc.Status.PD.MicroServices[service]; ok {
+				microServices[service] = addrs
+			}
+			continue
+		}
+		addrs := make([]string, 0, len(members))
+		for _, member := range members {
+			addrs = append(addrs, member.ServiceAddr)
+		}
+		if len(addrs) > 0 {
+			sort.Strings(addrs)
+			microServices[service] = addrs
+		}
+	}
+	if len(microServices) == 0 {
+		microServices = nil
+	}
+	tc.Status.PD.MicroServices = microServices
+}
+
+// syncEtcdMemberStatus records the alarms, the db size and whether the member is lagging behind
+// of the embedded etcd of the PD members. It is skipped if the etcd v3 gateway of PD is unavailable.
+func (m *pdMemberManager) syncEtcdMemberStatus(tc *v1alpha1.TidbCluster, pdClient pdapi.PDClient, memberStatuses ...map[string]v1alpha1.PDMember) {
+	alarms, err := pdClient.GetEtcdAlarms()
+	if err != nil {
+		klog.V(4).Infof("failed to get etcd alarms of PD of tc %s/%s, skip syncing etcd status, error: %v", tc.Namespace, tc.Name, err)
+		return
+	}
+	memberAlarms := map[string][]string{}
+	for _, alarm := range alarms {
+		memberID := fmt.Sprintf("%d", alarm.MemberID)
+		memberAlarms[memberID] = append(memberAlarms[memberID], alarm.Alarm)
+	}
+
+	etcdStatuses := map[string]*pdapi.EtcdStatus{}
+	var leaderStatus *pdapi.EtcdStatus
+	for _, members := range memberStatuses {
+		for name, member := range members {
+			if member.ClientURL == "" {
+				continue
+			}
+			peerClient := m.deps.PDControl.GetPeerPDClient(pdapi.Namespace(tc.Namespace), tc.Name, tc.IsTLSClusterEnabled(), member.ClientURL, name)
+			status, err := peerClient.GetEtcdStatus()
+			if err != nil {
+				klog.Warningf("failed to get etcd status of PD member %s of tc %s/%s, error: %v", name, tc.Namespace, tc.Name, err)
+				continue
+			}
+			etcdStatuses[name] = status
+			if status.Header != nil && status.Leader == status.Header.MemberID {
+				leaderStatus = status
+			}
+		}
+	}
+
+	for _, members := range memberStatuses {
+		for name, member := range members {
+			etcd := &v1alpha1.PDEtcdMemberStatus{Alarms: memberAlarms[member.ID]}
+			if status, ok := etcdStatuses[name]; ok {
+				etcd.DBSize = resource.NewQuantity(status.DBSize, resource.BinarySI)
+				etcd.Lagging = leaderStatus != nil && leaderStatus.RaftIndex > status.RaftIndex+pdEtcdLaggingRaftIndexThreshold
+			}
+			member.Etcd = etcd
+			members[name] = member
+		}
+	}
+}
+
 // syncPDConfigMap syncs the configmap of PD
 func (m *pdMemberManager) syncPDConfigMap(tc *v1alpha1.TidbCluster, set *apps.StatefulSet) (*corev1.ConfigMap, error) {
 
@@ -426,7 +552,7 @@ func (m *pdMemberManager) syncPDConfigMap(tc *v1alpha1.TidbCluster, set *apps.St
 	return m.deps.TypedControl.CreateOrUpdateConfigMap(tc, newCm)
 }
 
-func (m *pdMemberManager) getNewPDServiceForTidbCluster(tc *v1alpha1.TidbCluster) *corev1.Service {
+func getNewPDServiceForTidbCluster(tc *v1alpha1.TidbCluster) *corev1.Service {
 	ns := tc.Namespace
 	tcName := tc.Name
 	svcName := controller.PDMemberName(tcName)
@@ -680,7 +806,8 @@ func getNewPDSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (
 	stsLabels := label.New().Instance(instanceName).PD()
 	podLabels := util.CombineStringMap(stsLabels, basePDSpec.Labels())
 	podAnnotations := util.CombineStringMap(controller.AnnProm(2379), basePDSpec.Annotations())
-	stsAnnotations := getStsAnnotations(tc.Annotations, label.PDLabelVal)
+	podAnnotations = util.CombineStringMap(podAnnotations, tlsCertAnnotations(tc, v1alpha1.PDMemberType))
+	stsAnnotations := getTidbClusterStsAnnotations(tc, label.PDLabelVal)
 
 	deleteSlotsNumber, err := util.GetDeleteSlotsNumber(stsAnnotations)
 	if err != nil {
@@ -755,6 +882,7 @@ func getNewPDSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (
 	}
 	podSpec.SecurityContext = podSecurityContext
 	podSpec.InitContainers = append(initContainers, basePDSpec.InitContainers()...)
+	podAnnotations = applySecurityProfile(basePDSpec, &podSpec, podAnnotations, v1alpha1.PDMemberType.String())
 
 	updateStrategy := apps.StatefulSetUpdateStrategy{}
 	if basePDSpec.StatefulSetUpdateStrategy() == apps.OnDeleteStatefulSetStrategyType {
@@ -805,6 +933,7 @@ func getNewPDSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (
 	}
 
 	pdSet.Spec.VolumeClaimTemplates = append(pdSet.Spec.VolumeClaimTemplates, additionalPVCs...)
+	setVolumeClaimTemplatesAnnotations(pdSet, basePDSpec.PVCAnnotations())
 	return pdSet, nil
 }
 
@@ -825,6 +954,7 @@ func getPDConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
 		config.Set("security.cacert-path", path.Join(pdClusterCertPath, tlsSecretRootCAKey))
 		config.Set("security.cert-path", path.Join(pdClusterCertPath, corev1.TLSCertKey))
 		config.Set("security.key-path", path.Join(pdClusterCertPath, corev1.TLSPrivateKeyKey))
+		setTLSPolicyConfig(config.GenericConfig, tc.Spec.TLSCluster.Policy, "security.min-tls-version", "security.cipher-suites")
 	}
 	// Versions below v4.0 do not support Dashboard
 	if tc.Spec.TiDB != nil && tc.Spec.TiDB.IsTLSClientEnabled() && !tc.SkipTLSWhenConnectTiDB() && clusterVersionGE4 {
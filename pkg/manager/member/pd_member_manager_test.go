@@ -2386,8 +2386,7 @@ func TestGetNewPdServiceForTidbCluster(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			pmm, _, _ := newFakePDMemberManager()
-			svc := pmm.getNewPDServiceForTidbCluster(&tt.tc)
+			svc := getNewPDServiceForTidbCluster(&tt.tc)
 			if diff := cmp.Diff(tt.expected, *svc); diff != "" {
 				t.Errorf("unexpected Service (-want, +got): %s", diff)
 			}
@@ -2922,3 +2921,170 @@ func mustPDConfig(x interface{}) *v1alpha1.PDConfigWraper {
 
 	return c
 }
+
+func TestPDMemberManagerSyncEtcdMemberStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type testcase struct {
+		name         string
+		alarmsErr    bool
+		statusErr    bool
+		expectMember map[string]*v1alpha1.PDEtcdMemberStatus
+	}
+
+	testFn := func(test *testcase) {
+		t.Log(test.name)
+
+		pmm, _, _ := newFakePDMemberManager()
+		tc := newTidbClusterForPD()
+		fakePDControl := pmm.deps.PDControl.(*pdapi.FakePDControl)
+		pdClient := controller.NewFakePDClient(fakePDControl, tc)
+		pdClient.AddReaction(pdapi.GetEtcdAlarmsActionType, func(action *pdapi.Action) (interface{}, error) {
+			if test.alarmsErr {
+				return nil, fmt.Errorf("not found")
+			}
+			return []*pdapi.EtcdAlarm{{MemberID: 2, Alarm: "NOSPACE"}}, nil
+		})
+
+		raftIndexes := map[string]uint64{"test-pd-0": 5000, "test-pd-1": 4900, "test-pd-2": 3000}
+		members := map[string]v1alpha1.PDMember{}
+		for i, name := range []string{"test-pd-0", "test-pd-1", "test-pd-2"} {
+			memberID := uint64(i + 1)
+			clientURL := fmt.Sprintf("http://%s.test-pd-peer.default.svc:2379", name)
+			members[name] = v1alpha1.PDMember{Name: name, ID: fmt.Sprintf("%d", memberID), ClientURL: clientURL}
+			peerClient := controller.NewFakePDClientWithAddress(fakePDControl, name)
+			raftIndex := raftIndexes[name]
+			peerClient.AddReaction(pdapi.GetEtcdStatusActionType, func(action *pdapi.Action) (interface{}, error) {
+				if test.statusErr && memberID == 3 {
+					return nil, fmt.Errorf("connection refused")
+				}
+				return &pdapi.EtcdStatus{
+					Header:    &pdapi.EtcdResponseHeader{MemberID: memberID},
+					DBSize:    1024 * int64(memberID),
+					Leader:    1,
+					RaftIndex: raftIndex,
+				}, nil
+			})
+		}
+
+		pmm.syncEtcdMemberStatus(tc, pdClient, members)
+		for name, member := range members {
+			g.Expect(member.Etcd).To(Equal(test.expectMember[name]), name)
+		}
+	}
+
+	tests := []testcase{
+		{
+			name:      "etcd gateway is unavailable",
+			alarmsErr: true,
+			expectMember: map[string]*v1alpha1.PDEtcdMemberStatus{
+				"test-pd-0": nil,
+				"test-pd-1": nil,
+				"test-pd-2": nil,
+			},
+		},
+		{
+			name: "record alarms, db size and lagging members",
+			expectMember: map[string]*v1alpha1.PDEtcdMemberStatus{
+				"test-pd-0": {DBSize: resource.NewQuantity(1024, resource.BinarySI)},
+				"test-pd-1": {Alarms: []string{"NOSPACE"}, DBSize: resource.NewQuantity(2048, resource.BinarySI)},
+				"test-pd-2": {DBSize: resource.NewQuantity(3072, resource.BinarySI), Lagging: true},
+			},
+		},
+		{
+			name:      "failed to get the status of a member",
+			statusErr: true,
+			expectMember: map[string]*v1alpha1.PDEtcdMemberStatus{
+				"test-pd-0": {DBSize: resource.NewQuantity(1024, resource.BinarySI)},
+				"test-pd-1": {Alarms: []string{"NOSPACE"}, DBSize: resource.NewQuantity(2048, resource.BinarySI)},
+				"test-pd-2": {},
+			},
+		},
+	}
+
+	for i := range tests {
+		testFn(&tests[i])
+	}
+}
+
+func TestPDMemberManagerSyncMicroServiceStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type testcase struct {
+		name                string
+		apiVersion          pdapi.APIVersion
+		apiVersionErr       bool
+		schedulingErr       bool
+		previous            map[string][]string
+		expectAPIVersion    string
+		expectMicroServices map[string][]string
+	}
+
+	testFn := func(test *testcase) {
+		t.Log(test.name)
+
+		pmm, _, _ := newFakePDMemberManager()
+		tc := newTidbClusterForPD()
+		tc.Status.PD.MicroServices = test.previous
+		pdClient := controller.NewFakePDClient(pmm.deps.PDControl.(*pdapi.FakePDControl), tc)
+		pdClient.AddReaction(pdapi.GetAPIVersionActionType, func(action *pdapi.Action) (interface{}, error) {
+			if test.apiVersionErr {
+				return nil, fmt.Errorf("connection refused")
+			}
+			return test.apiVersion, nil
+		})
+		pdClient.AddReaction(pdapi.GetServiceMembersActionType, func(action *pdapi.Action) (interface{}, error) {
+			if action.Name == pdapi.PDServiceScheduling {
+				if test.schedulingErr {
+					return nil, fmt.Errorf("internal error")
+				}
+				return []*pdapi.ServiceMember{}, nil
+			}
+			return []*pdapi.ServiceMember{{ServiceAddr: "http://tso-1:2379"}, {ServiceAddr: "http://tso-0:2379"}}, nil
+		})
+
+		pmm.syncMicroServiceStatus(tc, pdClient)
+		g.Expect(tc.Status.PD.APIVersion).To(Equal(test.expectAPIVersion))
+		g.Expect(tc.Status.PD.MicroServices).To(Equal(test.expectMicroServices))
+	}
+
+	tests := []testcase{
+		{
+			name:             "PD only supports v1",
+			apiVersion:       pdapi.APIVersionV1,
+			previous:         map[string][]string{pdapi.PDServiceTSO: {"http://tso-0:2379"}},
+			expectAPIVersion: "v1",
+		},
+		{
+			name:                "failed to negotiate the API version",
+			apiVersionErr:       true,
+			previous:            map[string][]string{pdapi.PDServiceTSO: {"http://tso-0:2379"}},
+			expectMicroServices: map[string][]string{pdapi.PDServiceTSO: {"http://tso-0:2379"}},
+		},
+		{
+			name:             "discover the members of the microservices",
+			apiVersion:       pdapi.APIVersionV2,
+			expectAPIVersion: "v2",
+			expectMicroServices: map[string][]string{
+				pdapi.PDServiceTSO: {"http://tso-0:2379", "http://tso-1:2379"},
+			},
+		},
+		{
+			name:          "keep the members of the microservice which fails to respond",
+			apiVersion:    pdapi.APIVersionV2,
+			schedulingErr: true,
+			previous: map[string][]string{
+				pdapi.PDServiceScheduling: {"http://scheduling-0:2379"},
+			},
+			expectAPIVersion: "v2",
+			expectMicroServices: map[string][]string{
+				pdapi.PDServiceTSO:        {"http://tso-0:2379", "http://tso-1:2379"},
+				pdapi.PDServiceScheduling: {"http://scheduling-0:2379"},
+			},
+		},
+	}
+
+	for i := range tests {
+		testFn(&tests[i])
+	}
+}
@@ -23,7 +23,6 @@ import (
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/klog"
 )
 
 // TODO add e2e test specs
@@ -58,7 +57,7 @@ func (s *pdScaler) ScaleOut(meta metav1.Object, oldSet *apps.StatefulSet, newSet
 	ns := tc.GetNamespace()
 	tcName := tc.GetName()
 
-	klog.Infof("scaling out pd statefulset %s/%s, ordinal: %d (replicas: %d, delete slots: %v)", oldSet.Namespace, oldSet.Name, ordinal, replicas, deleteSlots.List())
+	componentLog(meta, v1alpha1.PDMemberType).Info("Scaling out statefulset", "statefulset", oldSet.Name, "ordinal", ordinal, "replicas", replicas, "deleteSlots", deleteSlots.List())
 	_, err := s.deleteDeferDeletingPVC(tc, v1alpha1.PDMemberType, ordinal)
 	if err != nil {
 		return err
@@ -84,6 +83,9 @@ func (s *pdScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSet
 	tcName := tc.GetName()
 	_, ordinal, replicas, deleteSlots := scaleOne(oldSet, newSet)
 	resetReplicas(newSet, oldSet)
+	if err := refuseIfAdopting(tc, "scaling in pd"); err != nil {
+		return err
+	}
 	memberName := PdName(tcName, ordinal, tc.Namespace, tc.Spec.ClusterDomain)
 	pdPodName := PdPodName(tcName, ordinal)
 
@@ -91,7 +93,8 @@ func (s *pdScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSet
 		return fmt.Errorf("TidbCluster: %s/%s's pd status sync failed, can't scale in now", ns, tcName)
 	}
 
-	klog.Infof("scaling in pd statefulset %s/%s, ordinal: %d (replicas: %d, delete slots: %v)", oldSet.Namespace, oldSet.Name, ordinal, replicas, deleteSlots.List())
+	log := componentLog(meta, v1alpha1.PDMemberType)
+	log.Info("Scaling in statefulset", "statefulset", oldSet.Name, "ordinal", ordinal, "replicas", replicas, "deleteSlots", deleteSlots.List())
 
 	if s.deps.CLIConfig.PodWebhookEnabled {
 		setReplicasAndDeleteSlots(newSet, replicas, deleteSlots)
@@ -119,14 +122,13 @@ func (s *pdScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSet
 				targetOrdinal = minOrdinal
 			}
 			targetPdName := PdName(tcName, targetOrdinal, tc.Namespace, tc.Spec.ClusterDomain)
-			if _, exist := tc.Status.PD.Members[targetPdName]; exist {
-				err = pdClient.TransferPDLeader(targetPdName)
-			} else {
-				err = pdClient.TransferPDLeader(PdPodName(tcName, targetOrdinal))
+			if _, exist := tc.Status.PD.Members[targetPdName]; !exist {
+				targetPdName = PdPodName(tcName, targetOrdinal)
 			}
-			if err != nil {
+			if err := pdClient.TransferPDLeader(targetPdName); err != nil {
 				return err
 			}
+			recordPDLeaderTransfer(s.deps, tc, memberName, targetPdName, "scale-in")
 		} else {
 			for _, member := range tc.Status.PD.PeerMembers {
 				if member.Health && member.Name != memberName {
@@ -134,6 +136,7 @@ func (s *pdScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSet
 					if err != nil {
 						return err
 					}
+					recordPDLeaderTransfer(s.deps, tc, memberName, member.Name, "scale-in")
 					return controller.RequeueErrorf("tc[%s/%s]'s pd pod[%s/%s] is transferring pd leader,can't scale-in now", ns, tcName, ns, memberName)
 				}
 			}
@@ -142,10 +145,10 @@ func (s *pdScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSet
 
 	err = pdClient.DeleteMember(memberName)
 	if err != nil {
-		klog.Errorf("pdScaler.ScaleIn: failed to delete member %s, %v", memberName, err)
+		log.Error(err, "Failed to delete member", "member", memberName)
 		return err
 	}
-	klog.Infof("pdScaler.ScaleIn: delete member %s successfully", memberName)
+	log.Info("Deleted member", "member", memberName)
 
 	pod, err := s.deps.PodLister.Pods(ns).Get(pdPodName)
 	if err != nil {
@@ -186,7 +189,7 @@ func (s *pdScaler) preCheckUpMembers(tc *v1alpha1.TidbCluster, podName string) b
 
 	if upComponents != 0 && tc.Spec.PD.Replicas == 0 {
 		errMsg := fmt.Sprintf("The PD is in use by TidbCluster [%s/%s], can't scale in PD, podname %s", tc.GetNamespace(), tc.GetName(), podName)
-		klog.Error(errMsg)
+		componentLog(tc, v1alpha1.PDMemberType).Error(nil, errMsg)
 		s.deps.Recorder.Event(tc, v1.EventTypeWarning, "FailedScaleIn", errMsg)
 		return false
 	}
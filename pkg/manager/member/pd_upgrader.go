@@ -15,11 +15,14 @@ package member
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
 	apps "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog"
 )
 
@@ -59,6 +62,7 @@ func (u *pdUpgrader) gracefulUpgrade(tc *v1alpha1.TidbCluster, oldSet *apps.Stat
 	}
 
 	tc.Status.PD.Phase = v1alpha1.UpgradePhase
+	setUpgradingCondition(&tc.Status.PD.Conditions, utiltidbcluster.RollingUpdate, "")
 	if !templateEqual(newSet, oldSet) {
 		return nil
 	}
@@ -94,12 +98,14 @@ func (u *pdUpgrader) gracefulUpgrade(tc *v1alpha1.TidbCluster, oldSet *apps.Stat
 
 		if revision == tc.Status.PD.StatefulSet.UpdateRevision {
 			if member, exist := tc.Status.PD.Members[PdName(tc.Name, i, tc.Namespace, tc.Spec.ClusterDomain)]; !exist || !member.Health {
+				setUpgradingCondition(&tc.Status.PD.Conditions, utiltidbcluster.WaitingForPodReady, fmt.Sprintf("upgraded pod %s is not ready", podName))
 				return controller.RequeueErrorf("tidbcluster: [%s/%s]'s pd upgraded pod: [%s] is not ready", ns, tcName, podName)
 			}
 			continue
 		}
 
 		if u.deps.CLIConfig.PodWebhookEnabled {
+			recordPodUpgrade(u.deps, tc, v1alpha1.PDMemberType, newSet, tc.Status.PD.StatefulSet, i)
 			setUpgradePartition(newSet, i)
 			return nil
 		}
@@ -114,17 +120,19 @@ func (u *pdUpgrader) upgradePDPod(tc *v1alpha1.TidbCluster, ordinal int32, newSe
 	ns := tc.GetNamespace()
 	tcName := tc.GetName()
 	upgradePdName := PdName(tcName, ordinal, tc.Namespace, tc.Spec.ClusterDomain)
-	upgradePodName := PdPodName(tcName, ordinal)
-	if tc.Status.PD.Leader.Name == upgradePdName || tc.Status.PD.Leader.Name == upgradePodName {
+	if sets.NewString(pdMemberNames(tc, ordinal)...).Has(tc.Status.PD.Leader.Name) {
 		var targetName string
 		if tc.PDStsActualReplicas() > 1 {
 			targetOrdinal := helper.GetMaxPodOrdinal(*newSet.Spec.Replicas, newSet)
 			if ordinal == targetOrdinal {
 				targetOrdinal = helper.GetMinPodOrdinal(*newSet.Spec.Replicas, newSet)
 			}
-			targetName = PdName(tcName, targetOrdinal, tc.Namespace, tc.Spec.ClusterDomain)
-			if _, exist := tc.Status.PD.Members[targetName]; !exist {
-				targetName = PdPodName(tcName, targetOrdinal)
+			targetName = PdPodName(tcName, targetOrdinal)
+			for _, name := range pdMemberNames(tc, targetOrdinal) {
+				if _, exist := tc.Status.PD.Members[name]; exist {
+					targetName = name
+					break
+				}
 			}
 		} else {
 			for _, member := range tc.Status.PD.PeerMembers {
@@ -141,9 +149,13 @@ func (u *pdUpgrader) upgradePDPod(tc *v1alpha1.TidbCluster, ordinal int32, newSe
 				return err
 			}
 			klog.Infof("pd upgrader: transfer pd leader to: %s successfully", targetName)
+			recordPDLeaderTransfer(u.deps, tc, upgradePdName, targetName, "upgrade")
+			setUpgradingCondition(&tc.Status.PD.Conditions, utiltidbcluster.WaitingForLeaderTransfer,
+				fmt.Sprintf("transferring pd leader from %s to %s", upgradePdName, targetName))
 			return controller.RequeueErrorf("tidbcluster: [%s/%s]'s pd member: [%s] is transferring leader to pd member: [%s]", ns, tcName, upgradePdName, targetName)
 		}
 	}
+	recordPodUpgrade(u.deps, tc, v1alpha1.PDMemberType, newSet, tc.Status.PD.StatefulSet, ordinal)
 	setUpgradePartition(newSet, ordinal)
 	return nil
 }
@@ -152,6 +164,23 @@ func (u *pdUpgrader) transferPDLeaderTo(tc *v1alpha1.TidbCluster, targetName str
 	return controller.GetPDClient(u.deps.PDControl, tc).TransferPDLeader(targetName)
 }
 
+// pdLeaderTransferTarget returns the first healthy pd member other than the leader in name order,
+// empty if there is none
+func pdLeaderTransferTarget(tc *v1alpha1.TidbCluster) string {
+	leader := tc.Status.PD.Leader.Name
+	names := make([]string, 0, len(tc.Status.PD.Members))
+	for name := range tc.Status.PD.Members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name != leader && tc.Status.PD.Members[name].Health {
+			return name
+		}
+	}
+	return ""
+}
+
 type fakePDUpgrader struct{}
 
 // NewFakePDUpgrader returns a fakePDUpgrader
@@ -0,0 +1,343 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// maxPendingChangeDiffSize is the max size of the diff of a pending change kept in the status
+const maxPendingChangeDiffSize = 4096
+
+// pendingChangesPreviewer records the changes of the StatefulSets, ConfigMaps and Services which the
+// member managers would apply for the current spec in `status.pendingChanges`, without applying them.
+//
+// It is opt-in by setting the annotation `tidb.pingcap.com/dry-run: "true"` on a paused TidbCluster,
+// so risky changes can be reviewed before the cluster is resumed. The desired objects are built in
+// the same way as the member managers do, but the scaling and the upgrade are not simulated, i.e. the
+// StatefulSets are compared with the replicas and the pod templates in the spec as a whole.
+type pendingChangesPreviewer struct {
+	deps *controller.Dependencies
+}
+
+// NewPendingChangesPreviewer returns a previewer of the changes of the objects of the components
+func NewPendingChangesPreviewer(deps *controller.Dependencies) manager.Manager {
+	return &pendingChangesPreviewer{
+		deps: deps,
+	}
+}
+
+// componentObjects are the objects of a component built from the spec
+type componentObjects struct {
+	memberType v1alpha1.MemberType
+	// setName is the name of the StatefulSet, which is also the prefix of the names of the ConfigMaps
+	setName        string
+	updateStrategy v1alpha1.ConfigUpdateStrategy
+	// configMap returns the desired ConfigMap, or nil if the ConfigMap is not managed for backward compatibility
+	configMap   func() (*corev1.ConfigMap, error)
+	statefulSet func(cm *corev1.ConfigMap) (*apps.StatefulSet, error)
+	services    []*corev1.Service
+}
+
+func (p *pendingChangesPreviewer) Sync(tc *v1alpha1.TidbCluster) error {
+	if !tc.Spec.Paused || tc.Annotations[label.AnnDryRun] != "true" {
+		tc.Status.PendingChanges = nil
+		return nil
+	}
+
+	var changes []v1alpha1.PendingChange
+	for _, objects := range getComponentObjects(tc) {
+		componentChanges, err := p.preview(tc, objects)
+		if err != nil {
+			return fmt.Errorf("pendingChangesPreviewer.Sync: failed to preview the changes of %s for cluster %s/%s, error: %v",
+				objects.memberType, tc.GetNamespace(), tc.GetName(), err)
+		}
+		changes = append(changes, componentChanges...)
+	}
+	tc.Status.PendingChanges = changes
+	return nil
+}
+
+func getComponentObjects(tc *v1alpha1.TidbCluster) []componentObjects {
+	tcName := tc.GetName()
+	var components []componentObjects
+	if tc.Spec.PD != nil {
+		components = append(components, componentObjects{
+			memberType:     v1alpha1.PDMemberType,
+			setName:        controller.PDMemberName(tcName),
+			updateStrategy: tc.BasePDSpec().ConfigUpdateStrategy(),
+			configMap: func() (*corev1.ConfigMap, error) {
+				if tc.Spec.PD.Config == nil {
+					return nil, nil
+				}
+				return getPDConfigMap(tc)
+			},
+			statefulSet: func(cm *corev1.ConfigMap) (*apps.StatefulSet, error) { return getNewPDSetForTidbCluster(tc, cm) },
+			services:    []*corev1.Service{getNewPDServiceForTidbCluster(tc), getNewPDHeadlessServiceForTidbCluster(tc)},
+		})
+	}
+	if tc.Spec.TiKV != nil {
+		var services []*corev1.Service
+		for _, svc := range tikvSvcList {
+			services = append(services, getNewServiceForTidbCluster(tc, svc))
+		}
+		components = append(components, componentObjects{
+			memberType:     v1alpha1.TiKVMemberType,
+			setName:        controller.TiKVMemberName(tcName),
+			updateStrategy: tc.BaseTiKVSpec().ConfigUpdateStrategy(),
+			configMap: func() (*corev1.ConfigMap, error) {
+				if tc.Spec.TiKV.Config == nil && tc.Spec.TiKV.Encryption == nil {
+					return nil, nil
+				}
+				return getTikVConfigMap(tc)
+			},
+			statefulSet: func(cm *corev1.ConfigMap) (*apps.StatefulSet, error) { return getNewTiKVSetForTidbCluster(tc, cm) },
+			services:    services,
+		})
+	}
+	if tc.Spec.TiFlash != nil {
+		components = append(components, componentObjects{
+			memberType:     v1alpha1.TiFlashMemberType,
+			setName:        controller.TiFlashMemberName(tcName),
+			updateStrategy: tc.BaseTiFlashSpec().ConfigUpdateStrategy(),
+			configMap:      func() (*corev1.ConfigMap, error) { return getTiFlashConfigMap(tc) },
+			statefulSet:    func(cm *corev1.ConfigMap) (*apps.StatefulSet, error) { return getNewStatefulSet(tc, cm) },
+			services:       []*corev1.Service{getNewHeadlessService(tc)},
+		})
+	}
+	if tc.Spec.TiDB != nil {
+		components = append(components, componentObjects{
+			memberType:     v1alpha1.TiDBMemberType,
+			setName:        controller.TiDBMemberName(tcName),
+			updateStrategy: tc.BaseTiDBSpec().ConfigUpdateStrategy(),
+			configMap: func() (*corev1.ConfigMap, error) {
+				if tc.Spec.TiDB.Config == nil {
+					return nil, nil
+				}
+				return getTiDBConfigMap(tc)
+			},
+			statefulSet: func(cm *corev1.ConfigMap) (*apps.StatefulSet, error) { return getNewTiDBSetForTidbCluster(tc, cm) },
+			// the tidb service is nil if it is not specified
+			services: []*corev1.Service{getNewTiDBHeadlessServiceForTidbCluster(tc), getNewTiDBServiceOrNil(tc)},
+		})
+	}
+	if tc.Spec.TiCDC != nil {
+		components = append(components, componentObjects{
+			memberType:     v1alpha1.TiCDCMemberType,
+			setName:        controller.TiCDCMemberName(tcName),
+			updateStrategy: tc.BaseTiCDCSpec().ConfigUpdateStrategy(),
+			configMap: func() (*corev1.ConfigMap, error) {
+				if tc.Spec.TiCDC.Config == nil || tc.Spec.TiCDC.Config.OnlyOldItems() {
+					return nil, nil
+				}
+				return getTiCDCConfigMap(tc)
+			},
+			statefulSet: func(cm *corev1.ConfigMap) (*apps.StatefulSet, error) { return getNewTiCDCStatefulSet(tc, cm) },
+			services:    []*corev1.Service{getNewCDCHeadlessService(tc)},
+		})
+	}
+	if tc.Spec.Pump != nil {
+		components = append(components, componentObjects{
+			memberType:     v1alpha1.PumpMemberType,
+			setName:        controller.PumpMemberName(tcName),
+			updateStrategy: tc.BasePumpSpec().ConfigUpdateStrategy(),
+			configMap:      func() (*corev1.ConfigMap, error) { return getNewPumpConfigMap(tc) },
+			statefulSet:    func(cm *corev1.ConfigMap) (*apps.StatefulSet, error) { return getNewPumpStatefulSet(tc, cm) },
+			services:       []*corev1.Service{getNewPumpHeadlessService(tc)},
+		})
+	}
+	return components
+}
+
+func (p *pendingChangesPreviewer) preview(tc *v1alpha1.TidbCluster, objects componentObjects) ([]v1alpha1.PendingChange, error) {
+	ns := tc.GetNamespace()
+	var changes []v1alpha1.PendingChange
+	change := func(kind, name string, action v1alpha1.PendingChangeAction, diff string) {
+		changes = append(changes, v1alpha1.PendingChange{
+			Kind:      kind,
+			Name:      name,
+			Component: objects.memberType,
+			Action:    action,
+			Diff:      truncateDiff(diff),
+		})
+	}
+
+	for _, newSvc := range objects.services {
+		if newSvc == nil {
+			continue
+		}
+		oldSvc, err := p.deps.ServiceLister.Services(ns).Get(newSvc.Name)
+		if errors.IsNotFound(err) {
+			change("Service", newSvc.Name, v1alpha1.PendingChangeCreate, "")
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		oldSpec := oldSvc.Spec
+		if lastAppliedConfig, ok := oldSvc.Annotations[LastAppliedConfigAnnotation]; ok {
+			oldSpec = corev1.ServiceSpec{}
+			if err := json.Unmarshal([]byte(lastAppliedConfig), &oldSpec); err != nil {
+				return nil, err
+			}
+		}
+		diff, err := objectDiff(&oldSpec, &newSvc.Spec)
+		if err != nil {
+			return nil, err
+		}
+		if diff != "" {
+			change("Service", newSvc.Name, v1alpha1.PendingChangeUpdate, diff)
+		}
+	}
+
+	oldSet, err := p.deps.StatefulSetLister.StatefulSets(ns).Get(objects.setName)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	if errors.IsNotFound(err) {
+		oldSet = nil
+	}
+
+	cm, err := objects.configMap()
+	if err != nil {
+		return nil, err
+	}
+	if cm != nil {
+		var inUse *corev1.ConfigMap
+		var inUseName string
+		if oldSet != nil {
+			inUseName = FindConfigMapVolume(&oldSet.Spec.Template.Spec, func(name string) bool {
+				return strings.HasPrefix(name, objects.setName)
+			})
+			if inUseName != "" {
+				inUse, err = p.deps.ConfigMapLister.ConfigMaps(ns).Get(inUseName)
+				if err != nil && !errors.IsNotFound(err) {
+					return nil, err
+				}
+			}
+		}
+		if err := updateConfigMapIfNeed(p.deps.ConfigMapLister, objects.updateStrategy, inUseName, cm); err != nil {
+			return nil, err
+		}
+		oldCm, err := p.deps.ConfigMapLister.ConfigMaps(ns).Get(cm.Name)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+		if errors.IsNotFound(err) {
+			// the new ConfigMap is compared with the one in use, e.g. the ConfigMap with the digest
+			// of the old config in the name if the config update strategy is RollingUpdate
+			var diff string
+			if inUse != nil {
+				diff = configMapDataDiff(inUse.Data, cm.Data)
+			}
+			change("ConfigMap", cm.Name, v1alpha1.PendingChangeCreate, diff)
+		} else if diff := configMapDataDiff(oldCm.Data, cm.Data); diff != "" {
+			change("ConfigMap", cm.Name, v1alpha1.PendingChangeUpdate, diff)
+		}
+	}
+
+	newSet, err := objects.statefulSet(cm)
+	if err != nil {
+		return nil, err
+	}
+	if oldSet == nil {
+		change("StatefulSet", newSet.Name, v1alpha1.PendingChangeCreate, "")
+		return changes, nil
+	}
+	oldSpec := oldSet.Spec
+	if lastAppliedConfig, ok := oldSet.Annotations[LastAppliedConfigAnnotation]; ok {
+		oldSpec = apps.StatefulSetSpec{}
+		if err := json.Unmarshal([]byte(lastAppliedConfig), &oldSpec); err != nil {
+			return nil, err
+		}
+	}
+	// only the replicas and the pod template are compared, the partition is computed by the upgrader
+	oldTemplate := oldSpec.Template.DeepCopy()
+	delete(oldTemplate.Annotations, LastAppliedConfigAnnotation)
+	diff, err := objectDiff(
+		&apps.StatefulSetSpec{Replicas: oldSpec.Replicas, Template: *oldTemplate},
+		&apps.StatefulSetSpec{Replicas: newSet.Spec.Replicas, Template: newSet.Spec.Template})
+	if err != nil {
+		return nil, err
+	}
+	if diff != "" {
+		change("StatefulSet", newSet.Name, v1alpha1.PendingChangeUpdate, diff)
+	}
+	return changes, nil
+}
+
+// objectDiff returns the diff between the objects in the unstructured form, so that the fields like
+// the quantities are compared by their serialized values
+func objectDiff(old, new interface{}) (string, error) {
+	oldObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(old)
+	if err != nil {
+		return "", err
+	}
+	newObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(new)
+	if err != nil {
+		return "", err
+	}
+	return cmp.Diff(oldObj, newObj), nil
+}
+
+// configMapDataDiff returns the line diff of the changed items of the ConfigMaps, the unchanged
+// items like the startup scripts are omitted
+func configMapDataDiff(old, new map[string]string) string {
+	oldLines := map[string][]string{}
+	newLines := map[string][]string{}
+	for k, v := range old {
+		if nv, ok := new[k]; !ok || nv != v {
+			oldLines[k] = strings.Split(v, "\n")
+		}
+	}
+	for k, v := range new {
+		if ov, ok := old[k]; !ok || ov != v {
+			newLines[k] = strings.Split(v, "\n")
+		}
+	}
+	if len(oldLines) == 0 && len(newLines) == 0 {
+		return ""
+	}
+	return cmp.Diff(oldLines, newLines)
+}
+
+func truncateDiff(diff string) string {
+	if len(diff) <= maxPendingChangeDiffSize {
+		return diff
+	}
+	return strings.ToValidUTF8(diff[:maxPendingChangeDiffSize], "") + "\n... (truncated)"
+}
+
+type fakePendingChangesPreviewer struct{}
+
+// NewFakePendingChangesPreviewer returns a fake previewer of the changes of the objects of the components
+func NewFakePendingChangesPreviewer() manager.Manager {
+	return &fakePendingChangesPreviewer{}
+}
+
+func (p *fakePendingChangesPreviewer) Sync(_ *v1alpha1.TidbCluster) error {
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestPendingChangesPreviewer(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	previewer := NewPendingChangesPreviewer(deps)
+	tc := newTidbClusterForPD()
+	tc.Spec.TiKV = nil
+	tc.Spec.TiDB = nil
+	tc.Spec.PD.Config = v1alpha1.NewPDConfig()
+	tc.Spec.PD.Config.Set("log.level", "info")
+
+	type change struct {
+		kind   string
+		name   string
+		action v1alpha1.PendingChangeAction
+	}
+	changesOf := func(tc *v1alpha1.TidbCluster) []change {
+		var changes []change
+		for _, c := range tc.Status.PendingChanges {
+			g.Expect(c.Component).To(Equal(v1alpha1.PDMemberType))
+			changes = append(changes, change{c.Kind, c.Name, c.Action})
+		}
+		return changes
+	}
+
+	// the changes are not previewed unless the cluster is paused with the annotation
+	tc.Status.PendingChanges = []v1alpha1.PendingChange{{Kind: "StatefulSet", Name: "test-pd"}}
+	g.Expect(previewer.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.PendingChanges).To(BeNil())
+	tc.Spec.Paused = true
+	g.Expect(previewer.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.PendingChanges).To(BeNil())
+
+	tc.Annotations = map[string]string{label.AnnDryRun: "true"}
+	g.Expect(previewer.Sync(tc)).To(Succeed())
+	g.Expect(changesOf(tc)).To(Equal([]change{
+		{"Service", "test-pd", v1alpha1.PendingChangeCreate},
+		{"Service", "test-pd-peer", v1alpha1.PendingChangeCreate},
+		{"ConfigMap", "test-pd", v1alpha1.PendingChangeCreate},
+		{"StatefulSet", "test-pd", v1alpha1.PendingChangeCreate},
+	}))
+
+	// the objects are applied
+	for _, svc := range []*corev1.Service{getNewPDServiceForTidbCluster(tc), getNewPDHeadlessServiceForTidbCluster(tc)} {
+		g.Expect(controller.SetServiceLastAppliedConfigAnnotation(svc)).To(Succeed())
+		g.Expect(deps.KubeInformerFactory.Core().V1().Services().Informer().GetIndexer().Add(svc)).To(Succeed())
+	}
+	cm, err := getPDConfigMap(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(deps.KubeInformerFactory.Core().V1().ConfigMaps().Informer().GetIndexer().Add(cm)).To(Succeed())
+	set, err := getNewPDSetForTidbCluster(tc, cm)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(SetStatefulSetLastAppliedConfigAnnotation(set)).To(Succeed())
+	g.Expect(deps.KubeInformerFactory.Apps().V1().StatefulSets().Informer().GetIndexer().Add(set)).To(Succeed())
+	g.Expect(previewer.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.PendingChanges).To(BeEmpty())
+
+	// the spec is changed
+	tc.Spec.PD.Replicas = 5
+	tc.Spec.PD.Config.Set("log.level", "debug")
+	g.Expect(previewer.Sync(tc)).To(Succeed())
+	g.Expect(changesOf(tc)).To(Equal([]change{
+		{"ConfigMap", "test-pd", v1alpha1.PendingChangeUpdate},
+		{"StatefulSet", "test-pd", v1alpha1.PendingChangeUpdate},
+	}))
+	g.Expect(tc.Status.PendingChanges[0].Diff).To(ContainSubstring("debug"))
+	g.Expect(tc.Status.PendingChanges[1].Diff).To(ContainSubstring("replicas"))
+
+	g.Expect(truncateDiff(strings.Repeat("a", maxPendingChangeDiffSize+1))).To(HaveSuffix("(truncated)"))
+}
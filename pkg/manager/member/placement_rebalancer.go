@@ -0,0 +1,498 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+const (
+	// PlacementRebalanceReason is the reason of the events emitted by the placement rebalancer
+	PlacementRebalanceReason = "PlacementRebalance"
+
+	defaultHATopologyKey = "kubernetes.io/hostname"
+)
+
+// placementRebalancer migrates the PD and TiKV pods piled on a few topologies,
+// e.g. after node failures and failovers, so that the HA placement is restored.
+//
+// It is opt-in by setting the annotation `tidb.pingcap.com/placement-rebalance: "true"`
+// on the TidbCluster, and it is conservative:
+// - it only acts when PD and TiKV are in Normal phase without failure members and all members are healthy
+// - it migrates at most one pod at a time, and at most --placement-rebalance-moves-per-hour pods per hour
+// - it transfers the PD leader or evicts the TiKV region leaders before deleting the pod
+// - it only migrates the pods whose volumes are not bound to a node (e.g. local PVs)
+// - it only migrates a pod if there is a node in a topology which has room for it
+//
+// The new pod is placed by the HA predicate of tidb-scheduler, which never chooses an overloaded topology.
+// The migration history is recorded in the annotations of the PVCs so that it survives operator restarts.
+type placementRebalancer struct {
+	deps *controller.Dependencies
+}
+
+// NewPlacementRebalancer returns a placement rebalancer
+func NewPlacementRebalancer(deps *controller.Dependencies) manager.Manager {
+	return &placementRebalancer{
+		deps: deps,
+	}
+}
+
+func (r *placementRebalancer) Sync(tc *v1alpha1.TidbCluster) error {
+	// clean up the finished TiKV migrations even if the rebalancer is disabled in the middle
+	if err := r.finishMoves(tc); err != nil {
+		return err
+	}
+
+	if tc.Annotations[label.AnnPlacementRebalance] != "true" || tc.Adopting() {
+		return nil
+	}
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	if r.deps.NodeLister == nil || r.deps.PVLister == nil {
+		klog.V(4).Infof("placement rebalancer: no permission for nodes or persistent volumes, skip tidbcluster %s/%s", ns, tcName)
+		return nil
+	}
+	if reason := unsafeToRebalance(tc); reason != "" {
+		klog.V(4).Infof("placement rebalancer: skip tidbcluster %s/%s, %s", ns, tcName, reason)
+		return nil
+	}
+
+	// continue the migration in progress first
+	for _, memberType := range []v1alpha1.MemberType{v1alpha1.PDMemberType, v1alpha1.TiKVMemberType} {
+		pods, err := r.listPods(tc, memberType)
+		if err != nil {
+			return err
+		}
+		for _, pod := range pods {
+			if _, ok := pod.Annotations[label.AnnPlacementRebalanceBeginTime]; ok {
+				return r.movePod(tc, memberType, pod)
+			}
+		}
+	}
+
+	moves, err := r.recentMoves(tc)
+	if err != nil {
+		return err
+	}
+	budget := r.deps.CLIConfig.PlacementRebalanceMovesPerHour
+	if moves >= budget {
+		klog.V(4).Infof("placement rebalancer: tidbcluster %s/%s has migrated %d pods in the last hour, budget is %d", ns, tcName, moves, budget)
+		return nil
+	}
+
+	for _, memberType := range []v1alpha1.MemberType{v1alpha1.PDMemberType, v1alpha1.TiKVMemberType} {
+		pod, topology, err := r.podToMove(tc, memberType)
+		if err != nil {
+			return err
+		}
+		if pod == nil {
+			continue
+		}
+		klog.Infof("placement rebalancer: tidbcluster %s/%s topology %s violates HA placement of %s, migrating pod %s",
+			ns, tcName, topology, memberType, pod.Name)
+		pod = pod.DeepCopy()
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[label.AnnPlacementRebalanceBeginTime] = time.Now().Format(time.RFC3339)
+		if _, err := r.deps.PodControl.UpdatePod(tc, pod); err != nil {
+			return err
+		}
+		r.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, PlacementRebalanceReason,
+			"migrating pod %s away from topology %s which violates HA placement", pod.Name, topology)
+		return r.movePod(tc, memberType, pod)
+	}
+	return nil
+}
+
+// unsafeToRebalance returns the reason why pods can not be migrated, empty if it is safe
+func unsafeToRebalance(tc *v1alpha1.TidbCluster) string {
+	if tc.Spec.PD != nil {
+		if tc.Status.PD.Phase != v1alpha1.NormalPhase {
+			return fmt.Sprintf("pd phase is %s", tc.Status.PD.Phase)
+		}
+		if len(tc.Status.PD.FailureMembers) > 0 {
+			return "pd has failure members"
+		}
+		for name, member := range tc.Status.PD.Members {
+			if !member.Health {
+				return fmt.Sprintf("pd member %s is unhealthy", name)
+			}
+		}
+	}
+	if tc.Spec.TiKV != nil {
+		if tc.Status.TiKV.Phase != v1alpha1.NormalPhase {
+			return fmt.Sprintf("tikv phase is %s", tc.Status.TiKV.Phase)
+		}
+		if len(tc.Status.TiKV.FailureStores) > 0 {
+			return "tikv has failure stores"
+		}
+		for id, store := range tc.Status.TiKV.Stores {
+			if store.State != v1alpha1.TiKVStateUp {
+				return fmt.Sprintf("tikv store %s is %s", id, store.State)
+			}
+		}
+	}
+	return ""
+}
+
+// movePod moves the leaders away from the pod and then deletes it
+func (r *placementRebalancer) movePod(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, pod *corev1.Pod) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	var storeID string
+	switch memberType {
+	case v1alpha1.PDMemberType:
+		ordinal, err := util.GetOrdinalFromPodName(pod.Name)
+		if err != nil {
+			return err
+		}
+		leader := tc.Status.PD.Leader.Name
+		if leader == pod.Name || leader == PdName(tcName, ordinal, ns, tc.Spec.ClusterDomain) {
+			target := pdLeaderTransferTarget(tc)
+			if target == "" {
+				return controller.RequeueErrorf("placement rebalancer: tidbcluster: [%s/%s] no pd member to transfer leader to", ns, tcName)
+			}
+			if err := controller.GetPDClient(r.deps.PDControl, tc).TransferPDLeader(target); err != nil {
+				return err
+			}
+			return controller.RequeueErrorf("placement rebalancer: tidbcluster: [%s/%s]'s pd member: [%s] is transferring leader to pd member: [%s]", ns, tcName, pod.Name, target)
+		}
+	case v1alpha1.TiKVMemberType:
+		var store *v1alpha1.TiKVStore
+		for _, s := range tc.Status.TiKV.Stores {
+			if s.PodName == pod.Name {
+				s := s
+				store = &s
+				break
+			}
+		}
+		if store == nil {
+			return controller.RequeueErrorf("placement rebalancer: tidbcluster: [%s/%s] no store status found for tikv pod: [%s]", ns, tcName, pod.Name)
+		}
+		storeID = store.ID
+		id, err := strconv.ParseUint(storeID, 10, 64)
+		if err != nil {
+			return err
+		}
+		// BeginEvictLeader is idempotent
+		if err := controller.GetPDClient(r.deps.PDControl, tc).BeginEvictLeader(id); err != nil {
+			return err
+		}
+		beginTime, err := time.Parse(time.RFC3339, pod.Annotations[label.AnnPlacementRebalanceBeginTime])
+		if err != nil {
+			return err
+		}
+		if store.LeaderCount > 0 && time.Now().Before(beginTime.Add(tc.TiKVEvictLeaderTimeout())) {
+			return controller.RequeueErrorf("placement rebalancer: tidbcluster: [%s/%s]'s tikv pod: [%s] is evicting leader, %d leaders left", ns, tcName, pod.Name, store.LeaderCount)
+		}
+	}
+
+	pvcName := fmt.Sprintf("%s-%s", memberType, pod.Name)
+	pvc, err := r.deps.PVCLister.PersistentVolumeClaims(ns).Get(pvcName)
+	if err != nil {
+		return fmt.Errorf("placement rebalancer: failed to get pvc %s/%s, error: %v", ns, pvcName, err)
+	}
+	pvc = pvc.DeepCopy()
+	if pvc.Annotations == nil {
+		pvc.Annotations = map[string]string{}
+	}
+	pvc.Annotations[label.AnnPlacementRebalanceMovedAt] = time.Now().Format(time.RFC3339)
+	if storeID != "" {
+		pvc.Annotations[label.AnnPlacementRebalanceEvictingStore] = storeID
+	}
+	if _, err := r.deps.PVCControl.UpdatePVC(tc, pvc); err != nil {
+		return err
+	}
+	if err := r.deps.PodControl.DeletePod(tc, pod); err != nil {
+		return err
+	}
+	r.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, PlacementRebalanceReason, "pod %s on node %s is deleted to be rescheduled", pod.Name, pod.Spec.NodeName)
+	return nil
+}
+
+// finishMoves removes the evict leader schedulers of the migrated TiKV stores once they are up again
+func (r *placementRebalancer) finishMoves(tc *v1alpha1.TidbCluster) error {
+	return endEvictLeaderOfRecreatedStores(r.deps, tc, label.AnnPlacementRebalanceEvictingStore)
+}
+
+// recentMoves returns the number of pods migrated in the last hour
+func (r *placementRebalancer) recentMoves(tc *v1alpha1.TidbCluster) (int, error) {
+	pvcs, err := r.listPVCs(tc)
+	if err != nil {
+		return 0, err
+	}
+	moves := 0
+	for _, pvc := range pvcs {
+		movedAt, err := time.Parse(time.RFC3339, pvc.Annotations[label.AnnPlacementRebalanceMovedAt])
+		if err != nil {
+			continue
+		}
+		if time.Since(movedAt) < time.Hour {
+			moves++
+		}
+	}
+	return moves, nil
+}
+
+// podToMove returns a pod in the topology violating HA placement which can be migrated, nil if there is none
+func (r *placementRebalancer) podToMove(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) (*corev1.Pod, string, error) {
+	var replicas int32
+	var desiredOrdinals sets.Int32
+	switch memberType {
+	case v1alpha1.PDMemberType:
+		if tc.Spec.PD == nil {
+			return nil, "", nil
+		}
+		replicas = tc.PDStsDesiredReplicas()
+		desiredOrdinals = tc.PDStsDesiredOrdinals(false)
+	case v1alpha1.TiKVMemberType:
+		if tc.Spec.TiKV == nil {
+			return nil, "", nil
+		}
+		replicas = tc.TiKVStsDesiredReplicas()
+		desiredOrdinals = tc.TiKVStsDesiredOrdinals(false)
+	}
+
+	topologyKey := tc.Annotations[label.AnnHATopologyKey]
+	if topologyKey == "" {
+		topologyKey = defaultHATopologyKey
+	}
+	nodes, err := r.deps.NodeLister.List(labels.Everything())
+	if err != nil {
+		return nil, "", err
+	}
+	nodeTopology := map[string]string{}
+	availableTopologies := map[string]struct{}{}
+	for _, node := range nodes {
+		topology, ok := node.Labels[topologyKey]
+		if !ok {
+			continue
+		}
+		nodeTopology[node.Name] = topology
+		if isNodeAvailable(node) {
+			availableTopologies[topology] = struct{}{}
+		}
+	}
+
+	maxPods := maxPodsPerTopology(memberType, replicas, len(availableTopologies))
+	if maxPods <= 0 {
+		return nil, "", nil
+	}
+
+	pods, err := r.listPods(tc, memberType)
+	if err != nil {
+		return nil, "", err
+	}
+	podsByTopology := map[string][]*corev1.Pod{}
+	for _, pod := range pods {
+		ordinal, err := util.GetOrdinalFromPodName(pod.Name)
+		if err != nil {
+			continue
+		}
+		if !desiredOrdinals.Has(ordinal) {
+			continue
+		}
+		topology, ok := nodeTopology[pod.Spec.NodeName]
+		if !ok {
+			continue
+		}
+		podsByTopology[topology] = append(podsByTopology[topology], pod)
+	}
+
+	overloaded := ""
+	hasRoom := false
+	for topology := range availableTopologies {
+		if len(podsByTopology[topology]) < maxPods {
+			hasRoom = true
+		}
+	}
+	for topology, topologyPods := range podsByTopology {
+		if len(topologyPods) <= maxPods {
+			continue
+		}
+		if overloaded == "" || len(topologyPods) > len(podsByTopology[overloaded]) ||
+			(len(topologyPods) == len(podsByTopology[overloaded]) && topology < overloaded) {
+			overloaded = topology
+		}
+	}
+	if overloaded == "" || !hasRoom {
+		return nil, "", nil
+	}
+
+	candidates := make([]*corev1.Pod, 0)
+	for _, pod := range podsByTopology[overloaded] {
+		if !podutil.IsPodReady(pod) {
+			return nil, "", nil
+		}
+		pinned, err := r.isPodPinnedToNode(tc, memberType, pod)
+		if err != nil {
+			return nil, "", err
+		}
+		if !pinned {
+			candidates = append(candidates, pod)
+		}
+	}
+	if len(candidates) == 0 {
+		klog.Infof("placement rebalancer: tidbcluster %s/%s topology %s violates HA placement of %s, but no pod can be migrated",
+			tc.GetNamespace(), tc.GetName(), overloaded, memberType)
+		return nil, "", nil
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		ci, cj := migrationCost(tc, memberType, candidates[i]), migrationCost(tc, memberType, candidates[j])
+		if ci != cj {
+			return ci < cj
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+	return candidates[0], overloaded, nil
+}
+
+// migrationCost prefers the PD members which are not the leader and the TiKV stores with fewer leaders
+func migrationCost(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, pod *corev1.Pod) int32 {
+	if memberType == v1alpha1.PDMemberType {
+		ordinal, _ := util.GetOrdinalFromPodName(pod.Name)
+		leader := tc.Status.PD.Leader.Name
+		if leader == pod.Name || leader == PdName(tc.Name, ordinal, tc.Namespace, tc.Spec.ClusterDomain) {
+			return 1
+		}
+		return 0
+	}
+	for _, store := range tc.Status.TiKV.Stores {
+		if store.PodName == pod.Name {
+			return store.LeaderCount
+		}
+	}
+	return 0
+}
+
+// maxPodsPerTopology returns the max pods per topology allowed by tidb-scheduler's HA predicate,
+// 0 if HA is impossible
+func maxPodsPerTopology(memberType v1alpha1.MemberType, replicas int32, topologies int) int {
+	if memberType == v1alpha1.PDMemberType {
+		maxPods := int((replicas+1)/2) - 1
+		if maxPods <= 0 {
+			maxPods = 1
+		}
+		return maxPods
+	}
+	if replicas < 3 || topologies < 3 {
+		return 0
+	}
+	return int(math.Ceil(float64(replicas) / 3))
+}
+
+// isPodPinnedToNode returns true if any volume of the pod can not be attached to other nodes
+func (r *placementRebalancer) isPodPinnedToNode(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, pod *corev1.Pod) (bool, error) {
+	ordinal, err := util.GetOrdinalFromPodName(pod.Name)
+	if err != nil {
+		return true, err
+	}
+	selector, err := GetPVCSelectorForPod(tc, memberType, ordinal)
+	if err != nil {
+		return true, err
+	}
+	pvcs, err := r.deps.PVCLister.PersistentVolumeClaims(tc.GetNamespace()).List(selector)
+	if err != nil {
+		return true, err
+	}
+	if len(pvcs) == 0 {
+		return true, nil
+	}
+	for _, pvc := range pvcs {
+		if pvc.Spec.VolumeName == "" {
+			return true, nil
+		}
+		pv, err := r.deps.PVLister.Get(pvc.Spec.VolumeName)
+		if err != nil {
+			return true, err
+		}
+		if isVolumePinnedToNode(pv) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func isVolumePinnedToNode(pv *corev1.PersistentVolume) bool {
+	if pv.Spec.Local != nil || pv.Spec.HostPath != nil {
+		return true
+	}
+	if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+		return false
+	}
+	for _, term := range pv.Spec.NodeAffinity.Required.NodeSelectorTerms {
+		for _, expr := range term.MatchExpressions {
+			if expr.Key == corev1.LabelHostname {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isNodeAvailable(node *corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func (r *placementRebalancer) listPods(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) ([]*corev1.Pod, error) {
+	selector, err := label.New().Instance(tc.GetInstanceName()).Component(memberType.String()).Selector()
+	if err != nil {
+		return nil, err
+	}
+	return r.deps.PodLister.Pods(tc.GetNamespace()).List(selector)
+}
+
+func (r *placementRebalancer) listPVCs(tc *v1alpha1.TidbCluster) ([]*corev1.PersistentVolumeClaim, error) {
+	selector, err := label.New().Instance(tc.GetInstanceName()).Selector()
+	if err != nil {
+		return nil, err
+	}
+	return r.deps.PVCLister.PersistentVolumeClaims(tc.GetNamespace()).List(selector)
+}
+
+type fakePlacementRebalancer struct{}
+
+// NewFakePlacementRebalancer returns a fake placement rebalancer
+func NewFakePlacementRebalancer() manager.Manager {
+	return &fakePlacementRebalancer{}
+}
+
+func (r *fakePlacementRebalancer) Sync(_ *v1alpha1.TidbCluster) error {
+	return nil
+}
@@ -0,0 +1,239 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMaxPodsPerTopology(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		memberType v1alpha1.MemberType
+		replicas   int32
+		topologies int
+		expected   int
+	}{
+		{v1alpha1.PDMemberType, 1, 1, 1},
+		{v1alpha1.PDMemberType, 3, 3, 1},
+		{v1alpha1.PDMemberType, 5, 3, 2},
+		{v1alpha1.TiKVMemberType, 2, 3, 0},
+		{v1alpha1.TiKVMemberType, 3, 2, 0},
+		{v1alpha1.TiKVMemberType, 3, 3, 1},
+		{v1alpha1.TiKVMemberType, 4, 3, 2},
+	}
+	for _, test := range tests {
+		g.Expect(maxPodsPerTopology(test.memberType, test.replicas, test.topologies)).To(Equal(test.expected),
+			fmt.Sprintf("%s replicas %d topologies %d", test.memberType, test.replicas, test.topologies))
+	}
+}
+
+func TestIsVolumePinnedToNode(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	pv := &corev1.PersistentVolume{}
+	g.Expect(isVolumePinnedToNode(pv)).To(BeFalse())
+
+	pv.Spec.NodeAffinity = &corev1.VolumeNodeAffinity{
+		Required: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{
+					MatchExpressions: []corev1.NodeSelectorRequirement{
+						{Key: corev1.LabelZoneFailureDomain, Operator: corev1.NodeSelectorOpIn, Values: []string{"zone-a"}},
+					},
+				},
+			},
+		},
+	}
+	g.Expect(isVolumePinnedToNode(pv)).To(BeFalse())
+
+	pv.Spec.NodeAffinity.Required.NodeSelectorTerms[0].MatchExpressions = append(pv.Spec.NodeAffinity.Required.NodeSelectorTerms[0].MatchExpressions,
+		corev1.NodeSelectorRequirement{Key: corev1.LabelHostname, Operator: corev1.NodeSelectorOpIn, Values: []string{"node-1"}})
+	g.Expect(isVolumePinnedToNode(pv)).To(BeTrue())
+
+	pv = &corev1.PersistentVolume{}
+	pv.Spec.Local = &corev1.LocalVolumeSource{Path: "/mnt/disks/1"}
+	g.Expect(isVolumePinnedToNode(pv)).To(BeTrue())
+}
+
+func TestPlacementRebalancerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type testcase struct {
+		name          string
+		update        func(tc *v1alpha1.TidbCluster, deps *controller.Dependencies)
+		expectDeleted string
+	}
+
+	testFn := func(test *testcase) {
+		t.Log(test.name)
+
+		deps := controller.NewFakeDependencies()
+		deps.CLIConfig.PlacementRebalanceMovesPerHour = 1
+		tc := newTidbClusterForPlacementRebalancer()
+		// pd-0 and pd-1 are piled on node-1 while node-3 is empty
+		podNodes := map[int32]string{0: "node-1", 1: "node-1", 2: "node-2"}
+		for _, name := range []string{"node-1", "node-2", "node-3"} {
+			deps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer().Add(newNodeForPlacementRebalancer(name))
+		}
+		for ordinal, node := range podNodes {
+			podName := PdPodName(tc.Name, ordinal)
+			deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Add(&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      podName,
+					Namespace: tc.Namespace,
+					Labels:    label.New().Instance(tc.Name).PD().Labels(),
+				},
+				Spec: corev1.PodSpec{NodeName: node},
+				Status: corev1.PodStatus{
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				},
+			})
+			pvcLabels := label.New().Instance(tc.Name).PD().Labels()
+			pvcLabels[label.AnnPodNameKey] = podName
+			deps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer().Add(&corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      fmt.Sprintf("pd-%s", podName),
+					Namespace: tc.Namespace,
+					Labels:    pvcLabels,
+				},
+				Spec: corev1.PersistentVolumeClaimSpec{VolumeName: fmt.Sprintf("pv-%s", podName)},
+			})
+			deps.KubeInformerFactory.Core().V1().PersistentVolumes().Informer().GetIndexer().Add(&corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pv-%s", podName)},
+			})
+		}
+		if test.update != nil {
+			test.update(tc, deps)
+		}
+
+		err := NewPlacementRebalancer(deps).Sync(tc)
+		g.Expect(err).NotTo(HaveOccurred())
+
+		for ordinal := range podNodes {
+			podName := PdPodName(tc.Name, ordinal)
+			_, err := deps.PodLister.Pods(tc.Namespace).Get(podName)
+			if podName == test.expectDeleted {
+				g.Expect(errors.IsNotFound(err)).To(BeTrue())
+				pvc, err := deps.PVCLister.PersistentVolumeClaims(tc.Namespace).Get(fmt.Sprintf("pd-%s", podName))
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(pvc.Annotations).To(HaveKey(label.AnnPlacementRebalanceMovedAt))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		}
+	}
+
+	tests := []testcase{
+		{
+			name:          "migrate the non-leader pd pod",
+			expectDeleted: "test-pd-1",
+		},
+		{
+			name: "not enabled",
+			update: func(tc *v1alpha1.TidbCluster, _ *controller.Dependencies) {
+				delete(tc.Annotations, label.AnnPlacementRebalance)
+			},
+		},
+		{
+			name: "pd member unhealthy",
+			update: func(tc *v1alpha1.TidbCluster, _ *controller.Dependencies) {
+				tc.Status.PD.Members["test-pd-2"] = v1alpha1.PDMember{Name: "test-pd-2", Health: false}
+			},
+		},
+		{
+			name: "volume pinned to node",
+			update: func(tc *v1alpha1.TidbCluster, deps *controller.Dependencies) {
+				for _, podName := range []string{"test-pd-0", "test-pd-1"} {
+					deps.KubeInformerFactory.Core().V1().PersistentVolumes().Informer().GetIndexer().Update(&corev1.PersistentVolume{
+						ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pv-%s", podName)},
+						Spec: corev1.PersistentVolumeSpec{
+							PersistentVolumeSource: corev1.PersistentVolumeSource{
+								Local: &corev1.LocalVolumeSource{Path: "/mnt/disks/1"},
+							},
+						},
+					})
+				}
+			},
+		},
+		{
+			name: "budget exhausted",
+			update: func(tc *v1alpha1.TidbCluster, deps *controller.Dependencies) {
+				pvc, _ := deps.PVCLister.PersistentVolumeClaims(tc.Namespace).Get("pd-test-pd-2")
+				pvc = pvc.DeepCopy()
+				pvc.Annotations = map[string]string{label.AnnPlacementRebalanceMovedAt: time.Now().Add(-10 * time.Minute).Format(time.RFC3339)}
+				deps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer().Update(pvc)
+			},
+		},
+		{
+			name: "budget recovered after an hour",
+			update: func(tc *v1alpha1.TidbCluster, deps *controller.Dependencies) {
+				pvc, _ := deps.PVCLister.PersistentVolumeClaims(tc.Namespace).Get("pd-test-pd-2")
+				pvc = pvc.DeepCopy()
+				pvc.Annotations = map[string]string{label.AnnPlacementRebalanceMovedAt: time.Now().Add(-2 * time.Hour).Format(time.RFC3339)}
+				deps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer().Update(pvc)
+			},
+			expectDeleted: "test-pd-1",
+		},
+		{
+			name: "no room in other topologies",
+			update: func(tc *v1alpha1.TidbCluster, deps *controller.Dependencies) {
+				node := newNodeForPlacementRebalancer("node-3")
+				node.Spec.Unschedulable = true
+				deps.KubeInformerFactory.Core().V1().Nodes().Informer().GetIndexer().Update(node)
+			},
+		},
+	}
+
+	for i := range tests {
+		testFn(&tests[i])
+	}
+}
+
+func newTidbClusterForPlacementRebalancer() *v1alpha1.TidbCluster {
+	tc := newTidbClusterForPD()
+	tc.Spec.TiKV = nil
+	tc.Annotations = map[string]string{label.AnnPlacementRebalance: "true"}
+	tc.Status.PD.Phase = v1alpha1.NormalPhase
+	tc.Status.PD.Leader = v1alpha1.PDMember{Name: "test-pd-0", Health: true}
+	tc.Status.PD.Members = map[string]v1alpha1.PDMember{}
+	for i := int32(0); i < 3; i++ {
+		name := PdPodName(tc.Name, i)
+		tc.Status.PD.Members[name] = v1alpha1.PDMember{Name: name, Health: true}
+	}
+	return tc
+}
+
+func newNodeForPlacementRebalancer(name string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{corev1.LabelHostname: name},
+		},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
@@ -0,0 +1,319 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+const (
+	// PodRestartReason is the reason of the events emitted by the pod restarter
+	PodRestartReason = "PodRestart"
+)
+
+// restartOrder is the order in which the components are restarted, same as the upgrade order
+var restartOrder = []v1alpha1.MemberType{
+	v1alpha1.PDMemberType,
+	v1alpha1.TiKVMemberType,
+	v1alpha1.TiFlashMemberType,
+	v1alpha1.TiDBMemberType,
+}
+
+// podRestarter restarts the pods of the given ordinals gracefully, e.g. restarting tikv-3 and tikv-7 by
+// annotating the TidbCluster with `tikv.tidb.pingcap.com/restart-ordinals: "[3,7]"`.
+//
+// The pods are restarted one at a time with the same safety logic as the upgraders:
+// - it only acts when the component is in Normal phase and all pods of the component are ready
+// - it transfers the PD leader or evicts the TiKV region leaders (until the evict leader timeout) before deleting the pod
+// - it waits for the restarted pod to be ready before restarting the next one
+//
+// The ordinal is removed from the annotation once its pod is deleted, so the annotation is gone
+// when all the requested pods are restarted.
+type podRestarter struct {
+	deps *controller.Dependencies
+}
+
+// NewPodRestarter returns a pod restarter
+func NewPodRestarter(deps *controller.Dependencies) manager.Manager {
+	return &podRestarter{
+		deps: deps,
+	}
+}
+
+func (r *podRestarter) Sync(tc *v1alpha1.TidbCluster) error {
+	// clean up the evict leader schedulers of the restarted TiKV stores
+	if err := endEvictLeaderOfRecreatedStores(r.deps, tc, label.AnnRestartEvictingStore); err != nil {
+		return err
+	}
+
+	// continue the restart in progress first
+	for _, memberType := range restartOrder {
+		pods, err := r.listPods(tc, memberType)
+		if err != nil {
+			return err
+		}
+		for _, pod := range pods {
+			if _, ok := pod.Annotations[label.AnnPodRestartBeginTime]; ok {
+				return r.restartPod(tc, memberType, pod)
+			}
+		}
+	}
+
+	for _, memberType := range restartOrder {
+		ordinals := getRestartOrdinals(tc, memberType)
+		if ordinals.Len() == 0 {
+			continue
+		}
+		if reason, err := r.unsafeToRestart(tc, memberType); err != nil {
+			return err
+		} else if reason != "" {
+			return controller.RequeueErrorf("pod restarter: tidbcluster: [%s/%s] can not restart %s pods %v now, %s",
+				tc.GetNamespace(), tc.GetName(), memberType, ordinals.List(), reason)
+		}
+
+		ordinal := ordinals.List()[0]
+		podName := ordinalPodName(memberType, tc.GetName(), ordinal)
+		pod, err := r.deps.PodLister.Pods(tc.GetNamespace()).Get(podName)
+		if errors.IsNotFound(err) {
+			r.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, PodRestartReason, "pod %s to restart does not exist, ignored", podName)
+			return r.removeRestartOrdinal(tc, memberType, ordinal)
+		}
+		if err != nil {
+			return err
+		}
+
+		klog.Infof("pod restarter: tidbcluster %s/%s begins to restart pod %s", tc.GetNamespace(), tc.GetName(), podName)
+		pod = pod.DeepCopy()
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[label.AnnPodRestartBeginTime] = time.Now().Format(time.RFC3339)
+		if _, err := r.deps.PodControl.UpdatePod(tc, pod); err != nil {
+			return err
+		}
+		r.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, PodRestartReason, "restarting pod %s", podName)
+		return r.restartPod(tc, memberType, pod)
+	}
+	return nil
+}
+
+// unsafeToRestart returns the reason why the pods of the component can not be restarted now, empty if it is safe
+func (r *podRestarter) unsafeToRestart(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) (string, error) {
+	var phase v1alpha1.MemberPhase
+	switch memberType {
+	case v1alpha1.PDMemberType:
+		phase = tc.Status.PD.Phase
+	case v1alpha1.TiKVMemberType:
+		phase = tc.Status.TiKV.Phase
+	case v1alpha1.TiFlashMemberType:
+		phase = tc.Status.TiFlash.Phase
+	case v1alpha1.TiDBMemberType:
+		phase = tc.Status.TiDB.Phase
+	}
+	if phase != v1alpha1.NormalPhase {
+		return fmt.Sprintf("%s phase is %s", memberType, phase), nil
+	}
+	if reason := unsafeToRebalance(tc); reason != "" {
+		return reason, nil
+	}
+	pods, err := r.listPods(tc, memberType)
+	if err != nil {
+		return "", err
+	}
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil || !podutil.IsPodReady(pod) {
+			return fmt.Sprintf("pod %s is not ready", pod.Name), nil
+		}
+	}
+	return "", nil
+}
+
+// restartPod moves the leaders away from the pod and then deletes it
+func (r *podRestarter) restartPod(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, pod *corev1.Pod) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	ordinal, err := util.GetOrdinalFromPodName(pod.Name)
+	if err != nil {
+		return err
+	}
+
+	var storeID string
+	switch memberType {
+	case v1alpha1.PDMemberType:
+		leader := tc.Status.PD.Leader.Name
+		if leader == pod.Name || leader == PdName(tcName, ordinal, ns, tc.Spec.ClusterDomain) {
+			target := pdLeaderTransferTarget(tc)
+			if target == "" {
+				return controller.RequeueErrorf("pod restarter: tidbcluster: [%s/%s] no pd member to transfer leader to", ns, tcName)
+			}
+			if err := controller.GetPDClient(r.deps.PDControl, tc).TransferPDLeader(target); err != nil {
+				return err
+			}
+			return controller.RequeueErrorf("pod restarter: tidbcluster: [%s/%s]'s pd member: [%s] is transferring leader to pd member: [%s]", ns, tcName, pod.Name, target)
+		}
+	case v1alpha1.TiKVMemberType:
+		store := getStoreByOrdinal(tcName, tc.Status.TiKV, ordinal)
+		if store == nil {
+			return controller.RequeueErrorf("pod restarter: tidbcluster: [%s/%s] no store status found for tikv pod: [%s]", ns, tcName, pod.Name)
+		}
+		storeID = store.ID
+		id, err := strconv.ParseUint(storeID, 10, 64)
+		if err != nil {
+			return err
+		}
+		u := &tikvUpgrader{deps: r.deps}
+		if _, evicting := pod.Annotations[EvictLeaderBeginTime]; !evicting {
+			if err := u.beginEvictLeader(tc, id, pod); err != nil {
+				return err
+			}
+			return controller.RequeueErrorf("pod restarter: tidbcluster: [%s/%s]'s tikv pod: [%s] begins to evict leader", ns, tcName, pod.Name)
+		}
+		if !u.readyToUpgrade(pod, tc) {
+			return controller.RequeueErrorf("pod restarter: tidbcluster: [%s/%s]'s tikv pod: [%s] is evicting leader", ns, tcName, pod.Name)
+		}
+	}
+
+	if storeID != "" {
+		pvcName := fmt.Sprintf("%s-%s", memberType, pod.Name)
+		pvc, err := r.deps.PVCLister.PersistentVolumeClaims(ns).Get(pvcName)
+		if err != nil {
+			return fmt.Errorf("pod restarter: failed to get pvc %s/%s, error: %v", ns, pvcName, err)
+		}
+		pvc = pvc.DeepCopy()
+		if pvc.Annotations == nil {
+			pvc.Annotations = map[string]string{}
+		}
+		pvc.Annotations[label.AnnRestartEvictingStore] = storeID
+		if _, err := r.deps.PVCControl.UpdatePVC(tc, pvc); err != nil {
+			return err
+		}
+	}
+	if getRestartOrdinals(tc, memberType).Has(ordinal) {
+		if err := r.removeRestartOrdinal(tc, memberType, ordinal); err != nil {
+			return err
+		}
+	}
+	if err := r.deps.PodControl.DeletePod(tc, pod); err != nil {
+		return err
+	}
+	r.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, PodRestartReason, "pod %s is deleted to be restarted", pod.Name)
+	return nil
+}
+
+// removeRestartOrdinal removes the ordinal from the restart ordinals annotation of the component,
+// the annotation is removed if there are no ordinals left
+func (r *podRestarter) removeRestartOrdinal(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, ordinal int32) error {
+	ordinals := getRestartOrdinals(tc, memberType)
+	ordinals.Delete(ordinal)
+	return patchRestartOrdinals(r.deps, tc, memberType, ordinals)
+}
+
+// patchRestartOrdinals sets the restart ordinals annotation of the component to the ordinals,
+// the annotation is removed if the ordinals are empty
+func patchRestartOrdinals(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, ordinals sets.Int32) error {
+	key := restartOrdinalsAnnKey(memberType)
+	var value interface{}
+	if ordinals.Len() > 0 {
+		b, err := json.Marshal(ordinals.List())
+		if err != nil {
+			return err
+		}
+		value = string(b)
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				key: value,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := deps.TiDBClusterControl.Patch(tc, data); err != nil {
+		return err
+	}
+	if value == nil {
+		delete(tc.Annotations, key)
+	} else {
+		if tc.Annotations == nil {
+			tc.Annotations = map[string]string{}
+		}
+		tc.Annotations[key] = value.(string)
+	}
+	return nil
+}
+
+func (r *podRestarter) listPods(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) ([]*corev1.Pod, error) {
+	selector, err := label.New().Instance(tc.GetInstanceName()).Component(memberType.String()).Selector()
+	if err != nil {
+		return nil, err
+	}
+	return r.deps.PodLister.Pods(tc.GetNamespace()).List(selector)
+}
+
+func restartOrdinalsAnnKey(memberType v1alpha1.MemberType) string {
+	switch memberType {
+	case v1alpha1.PDMemberType:
+		return label.AnnPDRestartOrdinals
+	case v1alpha1.TiKVMemberType:
+		return label.AnnTiKVRestartOrdinals
+	case v1alpha1.TiFlashMemberType:
+		return label.AnnTiFlashRestartOrdinals
+	case v1alpha1.TiDBMemberType:
+		return label.AnnTiDBRestartOrdinals
+	}
+	return ""
+}
+
+// getRestartOrdinals returns the ordinals of the pods of the component to restart
+func getRestartOrdinals(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) sets.Int32 {
+	ordinals := sets.NewInt32()
+	value, ok := tc.Annotations[restartOrdinalsAnnKey(memberType)]
+	if !ok {
+		return ordinals
+	}
+	var slice []int32
+	if err := json.Unmarshal([]byte(value), &slice); err != nil {
+		klog.Warningf("pod restarter: tidbcluster %s/%s has invalid annotation %s: %v", tc.GetNamespace(), tc.GetName(), restartOrdinalsAnnKey(memberType), err)
+		return ordinals
+	}
+	ordinals.Insert(slice...)
+	return ordinals
+}
+
+type fakePodRestarter struct{}
+
+// NewFakePodRestarter returns a fake pod restarter
+func NewFakePodRestarter() manager.Manager {
+	return &fakePodRestarter{}
+}
+
+func (r *fakePodRestarter) Sync(_ *v1alpha1.TidbCluster) error {
+	return nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetRestartOrdinals(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := &v1alpha1.TidbCluster{}
+	g.Expect(getRestartOrdinals(tc, v1alpha1.TiKVMemberType).List()).To(BeEmpty())
+
+	tc.Annotations = map[string]string{label.AnnTiKVRestartOrdinals: "[7,3]"}
+	g.Expect(getRestartOrdinals(tc, v1alpha1.TiKVMemberType).List()).To(Equal([]int32{3, 7}))
+	g.Expect(getRestartOrdinals(tc, v1alpha1.PDMemberType).List()).To(BeEmpty())
+
+	tc.Annotations[label.AnnTiKVRestartOrdinals] = "tikv-3"
+	g.Expect(getRestartOrdinals(tc, v1alpha1.TiKVMemberType).List()).To(BeEmpty())
+}
+
+func TestPodRestarterSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type testcase struct {
+		name          string
+		ordinals      string
+		update        func(tc *v1alpha1.TidbCluster, deps *controller.Dependencies)
+		errExpectFn   func(error)
+		expectDeleted string
+		expectLeft    string
+	}
+
+	testFn := func(test *testcase) {
+		t.Log(test.name)
+
+		deps := controller.NewFakeDependencies()
+		tc := newTidbClusterForPlacementRebalancer()
+		tc.Annotations = map[string]string{}
+		if test.ordinals != "" {
+			tc.Annotations[label.AnnPDRestartOrdinals] = test.ordinals
+		}
+		controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+		for ordinal := int32(0); ordinal < 3; ordinal++ {
+			deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Add(&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      PdPodName(tc.Name, ordinal),
+					Namespace: tc.Namespace,
+					Labels:    label.New().Instance(tc.Name).PD().Labels(),
+				},
+				Status: corev1.PodStatus{
+					Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+				},
+			})
+		}
+		if test.update != nil {
+			test.update(tc, deps)
+		}
+
+		err := NewPodRestarter(deps).Sync(tc)
+		test.errExpectFn(err)
+
+		for ordinal := int32(0); ordinal < 3; ordinal++ {
+			podName := PdPodName(tc.Name, ordinal)
+			_, err := deps.PodLister.Pods(tc.Namespace).Get(podName)
+			if podName == test.expectDeleted {
+				g.Expect(errors.IsNotFound(err)).To(BeTrue())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		}
+		if test.expectLeft == "" {
+			g.Expect(tc.Annotations).NotTo(HaveKey(label.AnnPDRestartOrdinals))
+		} else {
+			g.Expect(tc.Annotations[label.AnnPDRestartOrdinals]).To(Equal(test.expectLeft))
+		}
+	}
+
+	tests := []testcase{
+		{
+			name:          "restart the non-leader pd pod",
+			ordinals:      "[1,2]",
+			errExpectFn:   func(err error) { g.Expect(err).NotTo(HaveOccurred()) },
+			expectDeleted: "test-pd-1",
+			expectLeft:    "[2]",
+		},
+		{
+			name:     "transfer the pd leader before restarting",
+			ordinals: "[0]",
+			errExpectFn: func(err error) {
+				g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+				g.Expect(err.Error()).To(ContainSubstring("is transferring leader"))
+			},
+			expectLeft: "[0]",
+		},
+		{
+			name:     "wait for the pods to be ready",
+			ordinals: "[1]",
+			update: func(tc *v1alpha1.TidbCluster, deps *controller.Dependencies) {
+				pod, _ := deps.PodLister.Pods(tc.Namespace).Get("test-pd-2")
+				pod = pod.DeepCopy()
+				pod.Status.Conditions = nil
+				deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Update(pod)
+			},
+			errExpectFn: func(err error) {
+				g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+				g.Expect(err.Error()).To(ContainSubstring("pod test-pd-2 is not ready"))
+			},
+			expectLeft: "[1]",
+		},
+		{
+			name:     "wait for pd to be normal",
+			ordinals: "[1]",
+			update: func(tc *v1alpha1.TidbCluster, _ *controller.Dependencies) {
+				tc.Status.PD.Phase = v1alpha1.UpgradePhase
+			},
+			errExpectFn: func(err error) { g.Expect(controller.IsRequeueError(err)).To(BeTrue()) },
+			expectLeft:  "[1]",
+		},
+		{
+			name:        "pod to restart does not exist",
+			ordinals:    "[5]",
+			errExpectFn: func(err error) { g.Expect(err).NotTo(HaveOccurred()) },
+		},
+		{
+			name: "continue the restart in progress",
+			update: func(tc *v1alpha1.TidbCluster, deps *controller.Dependencies) {
+				pod, _ := deps.PodLister.Pods(tc.Namespace).Get("test-pd-2")
+				pod = pod.DeepCopy()
+				pod.Annotations = map[string]string{label.AnnPodRestartBeginTime: time.Now().Format(time.RFC3339)}
+				deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Update(pod)
+			},
+			errExpectFn:   func(err error) { g.Expect(err).NotTo(HaveOccurred()) },
+			expectDeleted: "test-pd-2",
+		},
+	}
+
+	for i := range tests {
+		testFn(&tests[i])
+	}
+}
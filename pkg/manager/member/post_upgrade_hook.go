@@ -0,0 +1,358 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// PostUpgradeHookReason is the reason of the events emitted by the post-upgrade hook
+	PostUpgradeHookReason = "PostUpgradeHook"
+
+	defaultPostUpgradeHookImage = "tnir/mysqlclient"
+	defaultPostUpgradeHookUser  = "root"
+
+	postUpgradeHookSQLEnv       = "POST_UPGRADE_HOOK_SQL"
+	postUpgradeHookUserEnv      = "POST_UPGRADE_HOOK_USER"
+	postUpgradeHookSQLDir       = "/etc/post-upgrade-hook"
+	postUpgradeHookSQLPath      = "hook.sql"
+	postUpgradeHookPasswordDir  = "/etc/post-upgrade-hook-password"
+	postUpgradeHookPasswordKey  = "password"
+	postUpgradeHookSQLVolume    = "sql"
+	postUpgradeHookPasswdVolume = "password"
+	postUpgradeHookTLSVolume    = "tidb-client-tls"
+)
+
+var invalidJobNameChars = regexp.MustCompile(`[^a-z0-9.-]`)
+
+// postUpgradeHook executes the SQL of `spec.postUpgradeHook` by a job each time TiDB is upgraded to a new
+// version, e.g. analyzing the critical tables or setting the system variables of the new features.
+//
+// The version is considered upgraded once all the TiDB pods run the image in the spec and are ready. The
+// version when the cluster is created or the hook is added is only observed, the hook is not executed for
+// it. The job for a version is executed once, it is executed again if it is deleted, e.g. after it fails.
+type postUpgradeHook struct {
+	deps *controller.Dependencies
+}
+
+// NewPostUpgradeHook returns a post-upgrade hook
+func NewPostUpgradeHook(deps *controller.Dependencies) manager.Manager {
+	return &postUpgradeHook{
+		deps: deps,
+	}
+}
+
+func (h *postUpgradeHook) Sync(tc *v1alpha1.TidbCluster) error {
+	if tc.Spec.PostUpgradeHook == nil {
+		tc.Status.PostUpgradeHook = nil
+		return nil
+	}
+	if tc.Spec.TiDB == nil {
+		return nil
+	}
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	versions, upgraded, err := h.tidbPodVersions(tc)
+	if err != nil {
+		return err
+	}
+	target := tc.TiDBVersion()
+	status := tc.Status.PostUpgradeHook
+	if status == nil {
+		// the version the pods run before the hook is added, which is the previous version if TiDB is being upgraded
+		observed := target
+		for _, version := range versions.List() {
+			if version != target {
+				observed = version
+			}
+		}
+		tc.Status.PostUpgradeHook = &v1alpha1.PostUpgradeHookStatus{
+			Version: observed,
+			Phase:   v1alpha1.PostUpgradeHookObserved,
+		}
+		return nil
+	}
+
+	if status.Version != target {
+		if !upgraded {
+			return nil
+		}
+		klog.Infof("tidbcluster: [%s/%s] TiDB is upgraded from %s to %s, execute the post-upgrade hook", ns, tcName, status.Version, target)
+		if status.JobName != "" {
+			if err := h.deleteJob(tc, status.JobName); err != nil {
+				return err
+			}
+		}
+		tc.Status.PostUpgradeHook = &v1alpha1.PostUpgradeHookStatus{Version: target}
+		status = tc.Status.PostUpgradeHook
+		return h.createJob(tc, status)
+	}
+
+	switch status.Phase {
+	case v1alpha1.PostUpgradeHookRunning, v1alpha1.PostUpgradeHookFailed:
+		job, err := h.deps.JobLister.Jobs(ns).Get(status.JobName)
+		if errors.IsNotFound(err) {
+			// the job is deleted to execute the hook again
+			return h.createJob(tc, status)
+		}
+		if err != nil {
+			return fmt.Errorf("tidbcluster: [%s/%s] failed to get job %s of the post-upgrade hook, error: %v", ns, tcName, status.JobName, err)
+		}
+		if status.Phase == v1alpha1.PostUpgradeHookRunning {
+			h.updateStatus(tc, status, job)
+		}
+	}
+	return nil
+}
+
+// tidbPodVersions returns the versions of the images the TiDB pods run, and whether TiDB is upgraded
+// to the version in the spec, i.e. all the TiDB pods run the image in the spec and are ready
+func (h *postUpgradeHook) tidbPodVersions(tc *v1alpha1.TidbCluster) (sets.String, bool, error) {
+	selector, err := label.New().Instance(tc.GetInstanceName()).TiDB().Selector()
+	if err != nil {
+		return nil, false, err
+	}
+	pods, err := h.deps.PodLister.Pods(tc.GetNamespace()).List(selector)
+	if err != nil {
+		return nil, false, fmt.Errorf("tidbcluster: [%s/%s] failed to list the TiDB pods, error: %v", tc.GetNamespace(), tc.GetName(), err)
+	}
+	versions := sets.NewString()
+	ready := len(pods) > 0
+	for _, pod := range pods {
+		for _, c := range pod.Spec.Containers {
+			if c.Name != v1alpha1.TiDBMemberType.String() {
+				continue
+			}
+			version := "latest"
+			if colonIdx := strings.LastIndexByte(c.Image, ':'); colonIdx >= 0 {
+				version = c.Image[colonIdx+1:]
+			}
+			versions.Insert(version)
+		}
+		if !podutil.IsPodReady(pod) {
+			ready = false
+		}
+	}
+	upgraded := ready && versions.Len() == 1 && versions.Has(tc.TiDBVersion()) &&
+		!tc.TiDBUpgrading() && tc.TiDBAllMembersReady()
+	return versions, upgraded, nil
+}
+
+func (h *postUpgradeHook) createJob(tc *v1alpha1.TidbCluster, status *v1alpha1.PostUpgradeHookStatus) error {
+	job, err := h.makeJob(tc, status.Version)
+	if err != nil {
+		return err
+	}
+	if err := h.deps.JobControl.CreateJob(tc, job); err != nil {
+		return err
+	}
+	now := metav1.Now()
+	status.Phase = v1alpha1.PostUpgradeHookRunning
+	status.JobName = job.Name
+	status.StartTime = &now
+	status.CompletionTime = nil
+	status.Message = ""
+	h.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, PostUpgradeHookReason, "create job %s to execute the post-upgrade hook for TiDB %s", job.Name, status.Version)
+	return nil
+}
+
+func (h *postUpgradeHook) deleteJob(tc *v1alpha1.TidbCluster, name string) error {
+	job, err := h.deps.JobLister.Jobs(tc.GetNamespace()).Get(name)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if job.DeletionTimestamp != nil {
+		return nil
+	}
+	return h.deps.JobControl.DeleteJob(tc, job)
+}
+
+// updateStatus updates the phase from the conditions of the job
+func (h *postUpgradeHook) updateStatus(tc *v1alpha1.TidbCluster, status *v1alpha1.PostUpgradeHookStatus, job *batchv1.Job) {
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		completionTime := c.LastTransitionTime
+		switch c.Type {
+		case batchv1.JobComplete:
+			status.Phase = v1alpha1.PostUpgradeHookCompleted
+			status.CompletionTime = &completionTime
+			h.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, PostUpgradeHookReason, "the post-upgrade hook for TiDB %s completes", status.Version)
+		case batchv1.JobFailed:
+			status.Phase = v1alpha1.PostUpgradeHookFailed
+			status.CompletionTime = &completionTime
+			status.Message = fmt.Sprintf("job %s failed: %s, delete the job to execute the hook again", job.Name, c.Reason)
+			h.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, PostUpgradeHookReason, "the post-upgrade hook for TiDB %s fails, reason: %s", status.Version, c.Reason)
+		}
+	}
+}
+
+func (h *postUpgradeHook) makeJob(tc *v1alpha1.TidbCluster, version string) (*batchv1.Job, error) {
+	hook := tc.Spec.PostUpgradeHook
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	model := &PostUpgradeHookScriptModel{
+		ClusterName:  tcName,
+		UserEnv:      postUpgradeHookUserEnv,
+		PasswordPath: path.Join(postUpgradeHookPasswordDir, postUpgradeHookPasswordKey),
+		SQLEnv:       postUpgradeHookSQLEnv,
+	}
+	user := hook.User
+	if user == "" {
+		user = defaultPostUpgradeHookUser
+	}
+	image := hook.Image
+	if image == "" {
+		image = defaultPostUpgradeHookImage
+	}
+
+	envs := []corev1.EnvVar{
+		{Name: "TZ", Value: tc.Timezone()},
+		{Name: postUpgradeHookUserEnv, Value: user},
+	}
+	var vms []corev1.VolumeMount
+	var vs []corev1.Volume
+	if hook.SQLConfigMap != nil {
+		model.SQLPath = path.Join(postUpgradeHookSQLDir, postUpgradeHookSQLPath)
+		vms = append(vms, corev1.VolumeMount{Name: postUpgradeHookSQLVolume, ReadOnly: true, MountPath: postUpgradeHookSQLDir})
+		vs = append(vs, corev1.Volume{
+			Name: postUpgradeHookSQLVolume,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: hook.SQLConfigMap.LocalObjectReference,
+					Items:                []corev1.KeyToPath{{Key: hook.SQLConfigMap.Key, Path: postUpgradeHookSQLPath}},
+				},
+			},
+		})
+	} else {
+		envs = append(envs, corev1.EnvVar{Name: postUpgradeHookSQLEnv, Value: hook.SQL})
+	}
+	if hook.PasswordSecret != nil {
+		vms = append(vms, corev1.VolumeMount{Name: postUpgradeHookPasswdVolume, ReadOnly: true, MountPath: postUpgradeHookPasswordDir})
+		vs = append(vs, corev1.Volume{
+			Name: postUpgradeHookPasswdVolume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: *hook.PasswordSecret,
+					Items:      []corev1.KeyToPath{{Key: postUpgradeHookPasswordKey, Path: postUpgradeHookPasswordKey}},
+				},
+			},
+		})
+	}
+	if tc.Spec.TiDB.IsTLSClientEnabled() && !tc.SkipTLSWhenConnectTiDB() {
+		secretName := util.TiDBClientTLSSecretName(tcName)
+		if hook.TLSClientSecretName != nil {
+			secretName = *hook.TLSClientSecretName
+		}
+		model.TLS = true
+		model.CAPath = path.Join(util.TiDBClientTLSPath, corev1.ServiceAccountRootCAKey)
+		model.CertPath = path.Join(util.TiDBClientTLSPath, corev1.TLSCertKey)
+		model.KeyPath = path.Join(util.TiDBClientTLSPath, corev1.TLSPrivateKeyKey)
+		vms = append(vms, corev1.VolumeMount{Name: postUpgradeHookTLSVolume, ReadOnly: true, MountPath: util.TiDBClientTLSPath})
+		vs = append(vs, corev1.Volume{
+			Name: postUpgradeHookTLSVolume,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: secretName,
+				},
+			},
+		})
+	}
+	script, err := RenderPostUpgradeHookScript(model)
+	if err != nil {
+		return nil, fmt.Errorf("tidbcluster: [%s/%s] failed to render the script of the post-upgrade hook, error: %v", ns, tcName, err)
+	}
+
+	jobLabel := label.New().Instance(tc.GetInstanceName()).Component(label.PostUpgradeHookLabelVal)
+	container := corev1.Container{
+		Name:            label.PostUpgradeHookLabelVal,
+		Image:           image,
+		ImagePullPolicy: tc.Spec.ImagePullPolicy,
+		Command:         []string{"python", "-c", script},
+		Env:             envs,
+		VolumeMounts:    vms,
+	}
+	if hook.Resources != nil {
+		container.Resources = *hook.Resources
+	}
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            postUpgradeHookJobName(tcName, version),
+			Namespace:       ns,
+			Labels:          jobLabel,
+			OwnerReferences: []metav1.OwnerReference{controller.GetOwnerRef(tc)},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: pointer.Int32Ptr(0),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: jobLabel,
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: tc.Spec.ServiceAccount,
+					ImagePullSecrets:   tc.Spec.ImagePullSecrets,
+					SecurityContext:    tc.Spec.PodSecurityContext,
+					Containers:         []corev1.Container{container},
+					RestartPolicy:      corev1.RestartPolicyNever,
+					Volumes:            vs,
+				},
+			},
+		},
+	}, nil
+}
+
+// postUpgradeHookJobName returns the name of the job of the post-upgrade hook for the version
+func postUpgradeHookJobName(tcName, version string) string {
+	name := fmt.Sprintf("%s-post-upgrade-%s", tcName, invalidJobNameChars.ReplaceAllString(strings.ToLower(version), "-"))
+	// the name is the value of the job-name label of the pods
+	if len(name) > 63 {
+		name = strings.TrimRight(name[:63], ".-")
+	}
+	return name
+}
+
+type fakePostUpgradeHook struct{}
+
+// NewFakePostUpgradeHook returns a fake post-upgrade hook
+func NewFakePostUpgradeHook() manager.Manager {
+	return &fakePostUpgradeHook{}
+}
+
+func (h *fakePostUpgradeHook) Sync(_ *v1alpha1.TidbCluster) error {
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestPostUpgradeHookSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+	deps := controller.NewFakeDependencies()
+	hook := NewPostUpgradeHook(deps)
+	podIndexer := deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	jobIndexer := deps.KubeInformerFactory.Batch().V1().Jobs().Informer().GetIndexer()
+
+	tc := newTidbClusterForPostUpgradeHook()
+	setTiDBPods(g, podIndexer, tc, "pingcap/tidb:v4.0.9")
+
+	// the version when the hook is added is only observed
+	g.Expect(hook.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.PostUpgradeHook).To(Equal(&v1alpha1.PostUpgradeHookStatus{Version: "v4.0.9", Phase: v1alpha1.PostUpgradeHookObserved}))
+
+	// the hook is not executed until all the TiDB pods are upgraded
+	tc.Spec.Version = "v5.0.0"
+	setTiDBPods(g, podIndexer, tc, "pingcap/tidb:v4.0.9", "pingcap/tidb:v5.0.0")
+	g.Expect(hook.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.PostUpgradeHook.Phase).To(Equal(v1alpha1.PostUpgradeHookObserved))
+	g.Expect(jobIndexer.List()).To(BeEmpty())
+
+	setTiDBPods(g, podIndexer, tc, "pingcap/tidb:v5.0.0", "pingcap/tidb:v5.0.0")
+	g.Expect(hook.Sync(tc)).To(Succeed())
+	status := tc.Status.PostUpgradeHook
+	g.Expect(status.Version).To(Equal("v5.0.0"))
+	g.Expect(status.Phase).To(Equal(v1alpha1.PostUpgradeHookRunning))
+	g.Expect(status.JobName).To(Equal("test-post-upgrade-v5.0.0"))
+	g.Expect(jobIndexer.List()).To(HaveLen(1))
+	job := jobIndexer.List()[0].(*batchv1.Job)
+	container := job.Spec.Template.Spec.Containers[0]
+	g.Expect(container.Image).To(Equal(defaultPostUpgradeHookImage))
+	g.Expect(container.Command[2]).To(ContainSubstring("os.environ['%s']", postUpgradeHookSQLEnv))
+	g.Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: postUpgradeHookSQLEnv, Value: tc.Spec.PostUpgradeHook.SQL}))
+
+	// the job is executed again after it fails and is deleted
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded"}}
+	g.Expect(hook.Sync(tc)).To(Succeed())
+	g.Expect(status.Phase).To(Equal(v1alpha1.PostUpgradeHookFailed))
+	g.Expect(status.Message).To(ContainSubstring("BackoffLimitExceeded"))
+	g.Expect(hook.Sync(tc)).To(Succeed())
+	g.Expect(status.Phase).To(Equal(v1alpha1.PostUpgradeHookFailed))
+
+	g.Expect(jobIndexer.Delete(job)).To(Succeed())
+	g.Expect(hook.Sync(tc)).To(Succeed())
+	g.Expect(status.Phase).To(Equal(v1alpha1.PostUpgradeHookRunning))
+	job = jobIndexer.List()[0].(*batchv1.Job)
+	job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+	g.Expect(hook.Sync(tc)).To(Succeed())
+	g.Expect(status.Phase).To(Equal(v1alpha1.PostUpgradeHookCompleted))
+	g.Expect(status.CompletionTime).NotTo(BeNil())
+
+	// the hook is executed once for a version
+	g.Expect(jobIndexer.Delete(job)).To(Succeed())
+	g.Expect(hook.Sync(tc)).To(Succeed())
+	g.Expect(jobIndexer.List()).To(BeEmpty())
+
+	tc.Spec.PostUpgradeHook = nil
+	g.Expect(hook.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.PostUpgradeHook).To(BeNil())
+}
+
+func TestPostUpgradeHookObservedDuringUpgrade(t *testing.T) {
+	g := NewGomegaWithT(t)
+	deps := controller.NewFakeDependencies()
+	hook := NewPostUpgradeHook(deps)
+	podIndexer := deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+
+	// the hook added with the new version is executed after the upgrade
+	tc := newTidbClusterForPostUpgradeHook()
+	tc.Spec.Version = "v5.0.0"
+	tc.Spec.PostUpgradeHook = &v1alpha1.PostUpgradeHookSpec{
+		SQLConfigMap: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "hook"}, Key: "upgrade.sql"},
+		User:         "admin",
+	}
+	setTiDBPods(g, podIndexer, tc, "pingcap/tidb:v4.0.9", "pingcap/tidb:v4.0.9")
+	g.Expect(hook.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.PostUpgradeHook.Version).To(Equal("v4.0.9"))
+
+	setTiDBPods(g, podIndexer, tc, "pingcap/tidb:v5.0.0", "pingcap/tidb:v5.0.0")
+	g.Expect(hook.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.PostUpgradeHook.Phase).To(Equal(v1alpha1.PostUpgradeHookRunning))
+	job, err := deps.JobLister.Jobs(tc.Namespace).Get(tc.Status.PostUpgradeHook.JobName)
+	g.Expect(err).NotTo(HaveOccurred())
+	podSpec := job.Spec.Template.Spec
+	g.Expect(podSpec.Volumes[0].ConfigMap.Items).To(Equal([]corev1.KeyToPath{{Key: "upgrade.sql", Path: postUpgradeHookSQLPath}}))
+	g.Expect(podSpec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: postUpgradeHookUserEnv, Value: "admin"}))
+	g.Expect(podSpec.Containers[0].Command[2]).To(ContainSubstring(postUpgradeHookSQLDir))
+}
+
+func TestPostUpgradeHookJobName(t *testing.T) {
+	g := NewGomegaWithT(t)
+	g.Expect(postUpgradeHookJobName("test", "v5.0.0-RC_1")).To(Equal("test-post-upgrade-v5.0.0-rc-1"))
+	name := postUpgradeHookJobName("a-very-long-name-of-the-tidbcluster-in-the-namespace", "v5.0.0-nightly")
+	g.Expect(len(name)).To(BeNumerically("<=", 63))
+}
+
+func newTidbClusterForPostUpgradeHook() *v1alpha1.TidbCluster {
+	tc := newTidbClusterForTiDB()
+	tc.Spec.Version = "v4.0.9"
+	tc.Spec.TiDB.Image = ""
+	tc.Spec.TiDB.BaseImage = "pingcap/tidb"
+	tc.Spec.TiDB.Replicas = 2
+	tc.Spec.PostUpgradeHook = &v1alpha1.PostUpgradeHookSpec{
+		SQL: "ANALYZE TABLE test.t;",
+	}
+	return tc
+}
+
+// setTiDBPods replaces the TiDB pods with the ready pods running the images, and sets them healthy in the status
+func setTiDBPods(g *GomegaWithT, indexer cache.Indexer, tc *v1alpha1.TidbCluster, images ...string) {
+	for _, obj := range indexer.List() {
+		g.Expect(indexer.Delete(obj)).To(Succeed())
+	}
+	tc.Status.TiDB.Members = map[string]v1alpha1.TiDBMember{}
+	for i, image := range images {
+		name := fmt.Sprintf("%s-tidb-%d", tc.Name, i)
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: tc.Namespace,
+				Labels:    label.New().Instance(tc.GetInstanceName()).TiDB(),
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: v1alpha1.TiDBMemberType.String(), Image: image}},
+			},
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		}
+		g.Expect(indexer.Add(pod)).To(Succeed())
+		tc.Status.TiDB.Members[name] = v1alpha1.TiDBMember{Name: name, Health: true}
+	}
+}
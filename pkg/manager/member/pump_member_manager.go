@@ -15,18 +15,16 @@ package member
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
-	"github.com/pingcap/tidb-operator/pkg/binlog"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/label"
 	"github.com/pingcap/tidb-operator/pkg/manager"
-	"github.com/pingcap/tidb-operator/pkg/pdapi"
 	"github.com/pingcap/tidb-operator/pkg/util"
 	apps "k8s.io/api/apps/v1"
 	appsv1 "k8s.io/api/apps/v1"
@@ -45,6 +43,7 @@ const (
 
 type binlogClient interface {
 	PumpNodeStatus(ctx context.Context) (status []*v1alpha1.PumpNodeStatus, err error)
+	DrainerNodeStatus(ctx context.Context) (status []*v1alpha1.DrainerNodeStatus, err error)
 	Close() error
 }
 
@@ -96,6 +95,7 @@ func (m *pumpMemberManager) syncPumpStatefulSetForTidbCluster(tc *v1alpha1.TidbC
 	if err != nil {
 		return err
 	}
+	syncConfigSyncedCondition(&tc.Status.Pump.Conditions, oldSet, cm, controller.PumpMemberName(tc.Name))
 
 	newSet, err := getNewPumpStatefulSet(tc, cm)
 	if err != nil {
@@ -128,32 +128,12 @@ func (m *pumpMemberManager) syncPumpStatefulSetForTidbCluster(tc *v1alpha1.TidbC
 	return UpdateStatefulSet(m.deps.StatefulSetControl, tc, newSet, oldSet)
 }
 
-func (p *pumpMemberManager) buildBinlogClient(tc *v1alpha1.TidbCluster, control pdapi.PDControlInterface) (client binlogClient, err error) {
+func (p *pumpMemberManager) buildBinlogClient(tc *v1alpha1.TidbCluster) (client binlogClient, err error) {
 	if p.binlogClient != nil {
 		return p.binlogClient, nil
 	}
 
-	return buildBinlogClient(tc, control)
-}
-
-func buildBinlogClient(tc *v1alpha1.TidbCluster, control pdapi.PDControlInterface) (client *binlog.Client, err error) {
-	var endpoints []string
-	var tlsConfig *tls.Config
-	if tc.HeterogeneousWithoutLocalPD() {
-		endpoints, tlsConfig, err = control.GetEndpoints(pdapi.Namespace(tc.Spec.Cluster.Namespace), tc.Spec.Cluster.Name, tc.IsTLSClusterEnabled())
-	} else {
-		endpoints, tlsConfig, err = control.GetEndpoints(pdapi.Namespace(tc.Namespace), tc.Name, tc.IsTLSClusterEnabled())
-	}
-	if err != nil {
-		return nil, err
-	}
-
-	client, err = binlog.NewBinlogClient(endpoints, tlsConfig)
-	if err != nil {
-		return nil, err
-	}
-
-	return
+	return p.deps.ClientFactory.BinlogClient(tc)
 }
 
 func (m *pumpMemberManager) syncTiDBClusterStatus(tc *v1alpha1.TidbCluster, set *apps.StatefulSet) error {
@@ -175,7 +155,7 @@ func (m *pumpMemberManager) syncTiDBClusterStatus(tc *v1alpha1.TidbCluster, set
 		tc.Status.Pump.Phase = v1alpha1.NormalPhase
 	}
 
-	client, err := m.buildBinlogClient(tc, m.deps.PDControl)
+	client, err := m.buildBinlogClient(tc)
 	if err != nil {
 		return err
 	}
@@ -188,6 +168,19 @@ func (m *pumpMemberManager) syncTiDBClusterStatus(tc *v1alpha1.TidbCluster, set
 
 	tc.Status.Pump.Members = status
 
+	drainers, err := client.DrainerNodeStatus(context.TODO())
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, drainer := range drainers {
+		// the checkpoint of an offline drainer is not updated any more
+		if drainer.CheckpointTime != nil && drainer.State != "offline" {
+			drainer.Lag = now.Sub(drainer.CheckpointTime.Time).Round(time.Second).String()
+		}
+	}
+	tc.Status.Drainer.Members = drainers
+
 	return nil
 }
 
@@ -228,8 +221,7 @@ func (m *pumpMemberManager) syncHeadlessService(tc *v1alpha1.TidbCluster) error
 			svc.OwnerReferences = newSvc.OwnerReferences
 			svc.Labels = newSvc.Labels
 		}
-		_, err = m.deps.ServiceControl.UpdateService(tc, &svc)
-		return err
+		return updateService(m.deps.ServiceControl, tc, newSvc, &svc)
 	}
 	return nil
 }
@@ -326,6 +318,7 @@ func getNewPumpStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*app
 	storageClass := tc.Spec.Pump.StorageClassName
 	podLabels := util.CombineStringMap(stsLabels.Labels(), spec.Labels())
 	podAnnos := util.CombineStringMap(controller.AnnProm(8250), spec.Annotations())
+	podAnnos = util.CombineStringMap(podAnnos, tlsCertAnnotations(tc, v1alpha1.PumpMemberType))
 	storageRequest, err := controller.ParseStorageRequest(tc.Spec.Pump.Requests)
 	if err != nil {
 		return nil, fmt.Errorf("cannot parse storage request for pump, tidbcluster %s/%s, error: %v", tc.Namespace, tc.Name, err)
@@ -453,6 +446,7 @@ func getNewPumpStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*app
 	podSpec.InitContainers = spec.InitContainers()
 	// TODO: change to set field in BuildPodSpec
 	podSpec.DNSPolicy = spec.DnsPolicy()
+	podAnnos = applySecurityProfile(spec, &podSpec, podAnnos, "pump")
 
 	podTemplate := corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
@@ -462,7 +456,7 @@ func getNewPumpStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*app
 		Spec: podSpec,
 	}
 
-	return &appsv1.StatefulSet{
+	pumpSet := &appsv1.StatefulSet{
 		ObjectMeta: objMeta,
 		Spec: appsv1.StatefulSetSpec{
 			Selector:    stsLabels.LabelSelector(),
@@ -475,7 +469,9 @@ func getNewPumpStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*app
 				Type: spec.StatefulSetUpdateStrategy(),
 			},
 		},
-	}, nil
+	}
+	setVolumeClaimTemplatesAnnotations(pumpSet, spec.PVCAnnotations())
+	return pumpSet, nil
 }
 
 func getPumpMeta(tc *v1alpha1.TidbCluster, nameFunc func(string) string) (metav1.ObjectMeta, label.Label) {
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	. "github.com/onsi/gomega"
@@ -713,6 +714,7 @@ func TestSyncTiDBClusterStatus(t *testing.T) {
 		// `upgradingFn` is unused
 		// nolint(structcheck)
 		upgradingFn func(corelisters.PodLister, *appsv1.StatefulSet, *v1alpha1.TidbCluster) (bool, error)
+		drainers    []*v1alpha1.DrainerNodeStatus
 		errExpectFn func(*GomegaWithT, error)
 		tcExpectFn  func(*GomegaWithT, *v1alpha1.TidbCluster)
 	}
@@ -729,6 +731,7 @@ func TestSyncTiDBClusterStatus(t *testing.T) {
 			test.updateTC(set)
 		}
 		pmm, _, _ := newFakePumpMemberManager()
+		pmm.binlogClient = &fakeBinlogClient{drainers: test.drainers}
 
 		err := pmm.syncTiDBClusterStatus(tc, set)
 
@@ -755,6 +758,23 @@ func TestSyncTiDBClusterStatus(t *testing.T) {
 				g.Expect(tc.Status.Pump.Phase).To(Equal(v1alpha1.UpgradePhase))
 			},
 		},
+		{
+			name: "sync drainer status",
+			drainers: []*v1alpha1.DrainerNodeStatus{
+				{NodeID: "drainer-0", State: "online", CheckpointTime: &metav1.Time{Time: time.Now().Add(-time.Minute)}},
+				{NodeID: "drainer-1", State: "offline", CheckpointTime: &metav1.Time{Time: time.Now().Add(-time.Hour)}},
+				{NodeID: "drainer-2", State: "online"},
+			},
+			errExpectFn: func(g *GomegaWithT, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+			tcExpectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster) {
+				g.Expect(tc.Status.Drainer.Members).To(HaveLen(3))
+				g.Expect(tc.Status.Drainer.Members[0].Lag).To(Equal("1m0s"))
+				g.Expect(tc.Status.Drainer.Members[1].Lag).To(BeEmpty())
+				g.Expect(tc.Status.Drainer.Members[2].Lag).To(BeEmpty())
+			},
+		},
 	}
 
 	for i := range tests {
@@ -764,12 +784,17 @@ func TestSyncTiDBClusterStatus(t *testing.T) {
 }
 
 type fakeBinlogClient struct {
+	drainers []*v1alpha1.DrainerNodeStatus
 }
 
 func (c *fakeBinlogClient) PumpNodeStatus(ctx context.Context) (status []*v1alpha1.PumpNodeStatus, err error) {
 	return nil, nil
 }
 
+func (c *fakeBinlogClient) DrainerNodeStatus(ctx context.Context) (status []*v1alpha1.DrainerNodeStatus, err error) {
+	return c.drainers, nil
+}
+
 func (c *fakeBinlogClient) Close() error {
 	return nil
 }
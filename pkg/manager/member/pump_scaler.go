@@ -155,7 +155,7 @@ func (s *pumpScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSe
 
 	tc, _ := meta.(*v1alpha1.TidbCluster)
 
-	client, err := buildBinlogClient(tc, s.deps.PDControl)
+	client, err := s.deps.ClientFactory.BinlogClient(tc)
 	if err != nil {
 		return err
 	}
@@ -15,6 +15,7 @@ package member
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
@@ -41,6 +42,8 @@ const (
 	skipReasonPVCCleanerPVCHasBeenDeleted        = "pvc cleaner: pvc has been deleted"
 	skipReasonPVCCleanerPVCNotFound              = "pvc cleaner: not found pvc from apiserver"
 	skipReasonPVCCleanerPVCChanged               = "pvc cleaner: pvc changed before deletion"
+	skipReasonPVCCleanerPVCRetained              = "pvc cleaner: pvc is retained until its deletion time"
+	skipReasonPVCCleanerPVCDeletePolicyRetain    = "pvc cleaner: pvc delete policy of the component is Retain"
 )
 
 // PVCCleaner implements the logic for cleaning the pvc related resource
@@ -68,17 +71,24 @@ func (c *realPVCCleaner) Clean(meta metav1.Object) (map[string]string, error) {
 
 // reclaimPV reclaims PV used by tidb cluster if necessary.
 func (c *realPVCCleaner) reclaimPV(meta metav1.Object) (map[string]string, error) {
-	var clusterType string
+	var (
+		clusterType string
+		tc          *v1alpha1.TidbCluster
+		retention   time.Duration
+	)
+	policies := pvcDeletePolicies(meta)
+	if !hasPVCDeletePolicyDelete(policies) {
+		if tc, ok := meta.(*v1alpha1.TidbCluster); ok {
+			tc.Status.RetainedPVCs = nil
+		}
+		return nil, nil
+	}
 	switch meta := meta.(type) {
 	case *v1alpha1.TidbCluster:
-		if !meta.IsPVReclaimEnabled() {
-			return nil, nil
-		}
 		clusterType = "tidbcluster"
+		tc = meta
+		retention = meta.PVCRetentionPeriod()
 	case *v1alpha1.DMCluster:
-		if !meta.IsPVReclaimEnabled() {
-			return nil, nil
-		}
 		clusterType = "dmcluster"
 	}
 	ns := meta.GetNamespace()
@@ -91,15 +101,22 @@ func (c *realPVCCleaner) reclaimPV(meta metav1.Object) (map[string]string, error
 		return skipReason, err
 	}
 	runtimeMeta := meta.(runtime.Object)
+	retainedPVCs := map[string]v1alpha1.RetainedPVCStatus{}
 
 	for _, pvc := range pvcs {
 		pvcName := pvc.GetName()
 		l := label.Label(pvc.Labels)
-		if !(l.IsPD() || l.IsTiKV() || l.IsTiFlash() || l.IsDMMaster() || l.IsDMWorker()) {
+		if !(l.IsPD() || l.IsTiKV() || l.IsTiFlash() || l.IsPump() || l.IsDMMaster() || l.IsDMWorker()) {
 			skipReason[pvcName] = skipReasonPVCCleanerIsNotTarget
 			continue
 		}
 
+		if policies[l.ComponentType()] != v1alpha1.PVCDeletePolicyDelete {
+			// The orphan PVCs of this component are retained
+			skipReason[pvcName] = skipReasonPVCCleanerPVCDeletePolicyRetain
+			continue
+		}
+
 		if pvc.Status.Phase != corev1.ClaimBound {
 			// If pvc is not bound yet, it will not be processed
 			skipReason[pvcName] = skipReasonPVCCleanerPVCNotBound
@@ -147,6 +164,22 @@ func (c *realPVCCleaner) reclaimPV(meta metav1.Object) (map[string]string, error
 			return skipReason, fmt.Errorf("%s %s/%s get pvc %s pod %s from apiserver failed, err: %v", clusterType, ns, metaName, pvcName, podName, err)
 		}
 
+		if retention > 0 {
+			deferDeletingTime, err := time.Parse(time.RFC3339, pvc.Annotations[label.AnnPVCDeferDeleting])
+			if err != nil {
+				klog.Warningf("%s %s/%s pvc %s has invalid defer deleting time %q, reclaim it without retention", clusterType, ns, metaName, pvcName, pvc.Annotations[label.AnnPVCDeferDeleting])
+			} else if deletionTime := deferDeletingTime.Add(retention); time.Now().Before(deletionTime) {
+				// PVC is retained for a while so that the data can be recovered after an accidental scale-in
+				retainedPVCs[pvcName] = v1alpha1.RetainedPVCStatus{
+					PodName:           podName,
+					DeferDeletingTime: metav1.NewTime(deferDeletingTime),
+					DeletionTime:      metav1.NewTime(deletionTime),
+				}
+				skipReason[pvcName] = skipReasonPVCCleanerPVCRetained
+				continue
+			}
+		}
+
 		// Without pod reference this defer delete PVC, start to reclaim PV
 		pvName := pvc.Spec.VolumeName
 		if c.deps.PVLister != nil {
@@ -189,9 +222,35 @@ func (c *realPVCCleaner) reclaimPV(meta metav1.Object) (map[string]string, error
 		}
 		klog.Infof("%s %s/%s reclaim pv %s success, pvc %s", clusterType, ns, metaName, pvName, pvcName)
 	}
+	if tc != nil {
+		if len(retainedPVCs) == 0 {
+			retainedPVCs = nil
+		}
+		tc.Status.RetainedPVCs = retainedPVCs
+	}
 	return skipReason, nil
 }
 
+// pvcDeletePolicies returns the PVC delete policies of the components keyed by the component label value.
+func pvcDeletePolicies(meta metav1.Object) map[string]v1alpha1.PVCDeletePolicy {
+	switch meta := meta.(type) {
+	case *v1alpha1.TidbCluster:
+		return meta.PVCDeletePolicies()
+	case *v1alpha1.DMCluster:
+		return meta.PVCDeletePolicies()
+	}
+	return nil
+}
+
+func hasPVCDeletePolicyDelete(policies map[string]v1alpha1.PVCDeletePolicy) bool {
+	for _, policy := range policies {
+		if policy == v1alpha1.PVCDeletePolicyDelete {
+			return true
+		}
+	}
+	return false
+}
+
 // cleanScheduleLock cleans AnnPVCPodScheduling label if necessary.
 func (c *realPVCCleaner) cleanScheduleLock(meta metav1.Object) (map[string]string, error) {
 	ns := meta.GetNamespace()
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/label"
 	corev1 "k8s.io/api/core/v1"
@@ -40,6 +41,8 @@ func TestPVCCleanerReclaimPV(t *testing.T) {
 	type testcase struct {
 		name             string
 		pvReclaimEnabled bool
+		pvcDeletePolicy  *v1alpha1.PVCDeletePolicy
+		retention        time.Duration
 		pods             []*corev1.Pod
 		apiPods          []*corev1.Pod
 		pvcs             []*corev1.PersistentVolumeClaim
@@ -53,6 +56,8 @@ func TestPVCCleanerReclaimPV(t *testing.T) {
 	}
 	testFn := func(test *testcase, t *testing.T) {
 		tc.Spec.EnablePVReclaim = pointer.BoolPtr(test.pvReclaimEnabled)
+		tc.Spec.PVCRetentionPeriod = &metav1.Duration{Duration: test.retention}
+		tc.Spec.PD.PVCDeletePolicy = test.pvcDeletePolicy
 		pcc, fakeCli, podIndexer, pvcIndexer, pvcControl, pvIndexer, pvControl := newFakePVCCleaner()
 		if test.pods != nil {
 			for _, pod := range test.pods {
@@ -99,6 +104,7 @@ func TestPVCCleanerReclaimPV(t *testing.T) {
 		skipReason, err := pcc.reclaimPV(tc)
 		test.expectFn(g, skipReason, pcc, err)
 	}
+	deferDeletingTime := time.Now().Add(-time.Hour).Truncate(time.Second)
 	tests := []testcase{
 		{
 			name:             "no pvcs",
@@ -838,6 +844,134 @@ func TestPVCCleanerReclaimPV(t *testing.T) {
 				g.Expect(pv.Spec.PersistentVolumeReclaimPolicy).To(Equal(corev1.PersistentVolumeReclaimDelete))
 			},
 		},
+		{
+			name:             "the defer delete pvc is retained before its deletion time",
+			pvReclaimEnabled: true,
+			retention:        24 * time.Hour,
+			pvcs: []*corev1.PersistentVolumeClaim{
+				{
+					TypeMeta: metav1.TypeMeta{Kind: "PersistentVolumeClaim", APIVersion: "v1"},
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: metav1.NamespaceDefault,
+						Name:      "pd-test-pd-0",
+						Labels:    label.New().Instance(tc.GetInstanceName()).PD().Labels(),
+						Annotations: map[string]string{
+							label.AnnPVCDeferDeleting: deferDeletingTime.Format(time.RFC3339),
+							label.AnnPodNameKey:       "test-pd-0",
+						},
+					},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						VolumeName: "pd-local-pv-0",
+					},
+					Status: corev1.PersistentVolumeClaimStatus{
+						Phase: corev1.ClaimBound,
+					},
+				},
+			},
+			expectFn: func(g *GomegaWithT, skipReason map[string]string, pcc *realPVCCleaner, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(skipReason["pd-test-pd-0"]).To(Equal(skipReasonPVCCleanerPVCRetained))
+				_, pvcGetErr := pcc.deps.PVCLister.PersistentVolumeClaims(metav1.NamespaceDefault).Get("pd-test-pd-0")
+				g.Expect(pvcGetErr).NotTo(HaveOccurred())
+				g.Expect(tc.Status.RetainedPVCs).To(HaveLen(1))
+				retained := tc.Status.RetainedPVCs["pd-test-pd-0"]
+				g.Expect(retained.PodName).To(Equal("test-pd-0"))
+				g.Expect(retained.DeferDeletingTime.Time).To(BeTemporally("==", deferDeletingTime))
+				g.Expect(retained.DeletionTime.Time).To(BeTemporally("==", deferDeletingTime.Add(24*time.Hour)))
+			},
+		},
+		{
+			name:             "the defer delete pvc is reclaimed after its deletion time",
+			pvReclaimEnabled: true,
+			retention:        time.Hour,
+			pvcs: []*corev1.PersistentVolumeClaim{
+				{
+					TypeMeta: metav1.TypeMeta{Kind: "PersistentVolumeClaim", APIVersion: "v1"},
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace:       metav1.NamespaceDefault,
+						Name:            "pd-test-pd-0",
+						UID:             types.UID("pd-test"),
+						ResourceVersion: "1",
+						Labels:          label.New().Instance(tc.GetInstanceName()).PD().Labels(),
+						Annotations: map[string]string{
+							label.AnnPVCDeferDeleting: time.Now().Add(-2 * time.Hour).Format(time.RFC3339),
+							label.AnnPodNameKey:       "test-pd-0",
+						},
+					},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						VolumeName: "pd-local-pv-0",
+					},
+					Status: corev1.PersistentVolumeClaimStatus{
+						Phase: corev1.ClaimBound,
+					},
+				},
+			},
+			expectFn: func(g *GomegaWithT, skipReason map[string]string, pcc *realPVCCleaner, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(skipReason["pd-test-pd-0"]).To(Equal(skipReasonPVCCleanerNotFoundPV))
+				g.Expect(tc.Status.RetainedPVCs).To(BeNil())
+			},
+		},
+		{
+			name:             "pvc delete policy of the component overrides disabled pv reclaim",
+			pvReclaimEnabled: false,
+			pvcDeletePolicy:  pvcDeletePolicyPtr(v1alpha1.PVCDeletePolicyDelete),
+			pvcs: []*corev1.PersistentVolumeClaim{
+				{
+					TypeMeta: metav1.TypeMeta{Kind: "PersistentVolumeClaim", APIVersion: "v1"},
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: metav1.NamespaceDefault,
+						Name:      "pd-test-pd-0",
+						Labels:    label.New().Instance(tc.GetInstanceName()).PD().Labels(),
+						Annotations: map[string]string{
+							label.AnnPVCDeferDeleting: time.Now().Format(time.RFC3339),
+							label.AnnPodNameKey:       "test-pd-0",
+						},
+					},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						VolumeName: "pd-local-pv-0",
+					},
+					Status: corev1.PersistentVolumeClaimStatus{
+						Phase: corev1.ClaimBound,
+					},
+				},
+			},
+			expectFn: func(g *GomegaWithT, skipReason map[string]string, _ *realPVCCleaner, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(len(skipReason)).To(Equal(1))
+				g.Expect(skipReason["pd-test-pd-0"]).To(Equal(skipReasonPVCCleanerNotFoundPV))
+			},
+		},
+		{
+			name:             "pvc delete policy of the component overrides enabled pv reclaim",
+			pvReclaimEnabled: true,
+			pvcDeletePolicy:  pvcDeletePolicyPtr(v1alpha1.PVCDeletePolicyRetain),
+			pvcs: []*corev1.PersistentVolumeClaim{
+				{
+					TypeMeta: metav1.TypeMeta{Kind: "PersistentVolumeClaim", APIVersion: "v1"},
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: metav1.NamespaceDefault,
+						Name:      "pd-test-pd-0",
+						Labels:    label.New().Instance(tc.GetInstanceName()).PD().Labels(),
+						Annotations: map[string]string{
+							label.AnnPVCDeferDeleting: time.Now().Format(time.RFC3339),
+							label.AnnPodNameKey:       "test-pd-0",
+						},
+					},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						VolumeName: "pd-local-pv-0",
+					},
+					Status: corev1.PersistentVolumeClaimStatus{
+						Phase: corev1.ClaimBound,
+					},
+				},
+			},
+			expectFn: func(g *GomegaWithT, skipReason map[string]string, _ *realPVCCleaner, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(len(skipReason)).To(Equal(1))
+				g.Expect(skipReason["pd-test-pd-0"]).To(Equal(skipReasonPVCCleanerPVCDeletePolicyRetain))
+			},
+		},
 	}
 
 	for i := range tests {
@@ -847,6 +981,10 @@ func TestPVCCleanerReclaimPV(t *testing.T) {
 	}
 }
 
+func pvcDeletePolicyPtr(policy v1alpha1.PVCDeletePolicy) *v1alpha1.PVCDeletePolicy {
+	return &policy
+}
+
 func TestPVCCleanerCleanScheduleLock(t *testing.T) {
 	g := NewGomegaWithT(t)
 
@@ -18,12 +18,15 @@ import (
 	"fmt"
 	"regexp"
 
+	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/label"
 	"github.com/pingcap/tidb-operator/pkg/util"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
@@ -40,6 +43,12 @@ import (
 // for every unmatched PVC (desiredCapacity != actualCapacity)
 //  if storageClass does not support VolumeExpansion, skip and continue
 //  if not patched, patch
+//  record the resize state of the PVC in the status of the component
+//
+// Once all PVCs of a TidbCluster component are resized, the StatefulSet is
+// deleted with its pods orphaned and recreated by the member manager with the
+// larger storage request in `volumeClaimTemplates`, so the pods are not
+// restarted.
 //
 // We patch all PVCs at the same time. For many cloud storage plugins (e.g.
 // AWS-EBS, GCE-PD), they support online file system expansion in latest
@@ -49,7 +58,8 @@ import (
 //
 // - Note that the current statfulset implementation does not allow
 //   `volumeClaimTemplates` to be changed, so new PVCs created by statefulset
-//   controller will use the old storage request.
+//   controller will use the old storage request until the StatefulSet is
+//   recreated. The StatefulSets of DMCluster are not recreated.
 // - This is best effort, before statefulset volume resize feature (e.g.
 //   https://github.com/kubernetes/enhancements/pull/1848) to be implemented.
 // - If the feature `ExpandInUsePersistentVolumes` is not enabled or the volume
@@ -109,7 +119,7 @@ func (p *pvcResizer) Resize(tc *v1alpha1.TidbCluster) error {
 				klog.Warningf("StorageVolume %q in %s/%s .Spec.PD is invalid", sv.Name, ns, tc.Name)
 			}
 		}
-		if err := p.patchPVCs(ns, selector.Add(*pdRequirement), pvcPrefix2Quantity); err != nil {
+		if err := p.resizeComponent(tc, &tc.Status.PD.Volumes, tc.Status.PD.Phase, controller.PDMemberName(tc.Name), selector.Add(*pdRequirement), pvcPrefix2Quantity); err != nil {
 			return err
 		}
 	}
@@ -125,7 +135,7 @@ func (p *pvcResizer) Resize(tc *v1alpha1.TidbCluster) error {
 				klog.Warningf("StorageVolume %q in %s/%s .Spec.TiDB is invalid", sv.Name, ns, tc.Name)
 			}
 		}
-		if err := p.patchPVCs(ns, selector.Add(*tidbRequirement), pvcPrefix2Quantity); err != nil {
+		if err := p.resizeComponent(tc, &tc.Status.TiDB.Volumes, tc.Status.TiDB.Phase, controller.TiDBMemberName(tc.Name), selector.Add(*tidbRequirement), pvcPrefix2Quantity); err != nil {
 			return err
 		}
 	}
@@ -145,7 +155,7 @@ func (p *pvcResizer) Resize(tc *v1alpha1.TidbCluster) error {
 				klog.Warningf("StorageVolume %q in %s/%s .Spec.TiKV is invalid", sv.Name, ns, tc.Name)
 			}
 		}
-		if err := p.patchPVCs(ns, selector.Add(*tikvRequirement), pvcPrefix2Quantity); err != nil {
+		if err := p.resizeComponent(tc, &tc.Status.TiKV.Volumes, tc.Status.TiKV.Phase, controller.TiKVMemberName(tc.Name), selector.Add(*tikvRequirement), pvcPrefix2Quantity); err != nil {
 			return err
 		}
 	}
@@ -159,7 +169,7 @@ func (p *pvcResizer) Resize(tc *v1alpha1.TidbCluster) error {
 				pvcPrefix2Quantity[key] = quantity
 			}
 		}
-		if err := p.patchPVCs(ns, selector.Add(*tiflashRequirement), pvcPrefix2Quantity); err != nil {
+		if err := p.resizeComponent(tc, &tc.Status.TiFlash.Volumes, tc.Status.TiFlash.Phase, controller.TiFlashMemberName(tc.Name), selector.Add(*tiflashRequirement), pvcPrefix2Quantity); err != nil {
 			return err
 		}
 	}
@@ -175,7 +185,7 @@ func (p *pvcResizer) Resize(tc *v1alpha1.TidbCluster) error {
 				klog.Warningf("StorageVolume %q in %s/%s .Spec.TiCDC is invalid", sv.Name, ns, tc.Name)
 			}
 		}
-		if err := p.patchPVCs(ns, selector.Add(*ticdcRequirement), pvcPrefix2Quantity); err != nil {
+		if err := p.resizeComponent(tc, &tc.Status.TiCDC.Volumes, tc.Status.TiCDC.Phase, controller.TiCDCMemberName(tc.Name), selector.Add(*ticdcRequirement), pvcPrefix2Quantity); err != nil {
 			return err
 		}
 	}
@@ -187,7 +197,7 @@ func (p *pvcResizer) Resize(tc *v1alpha1.TidbCluster) error {
 			key := fmt.Sprintf("data-%s-%s", tc.Name, pumpMemberType)
 			pvcPrefix2Quantity[key] = quantity
 		}
-		if err := p.patchPVCs(ns, selector.Add(*pumpRequirement), pvcPrefix2Quantity); err != nil {
+		if err := p.resizeComponent(tc, &tc.Status.Pump.Volumes, tc.Status.Pump.Phase, controller.PumpMemberName(tc.Name), selector.Add(*pumpRequirement), pvcPrefix2Quantity); err != nil {
 			return err
 		}
 	}
@@ -210,7 +220,7 @@ func (p *pvcResizer) ResizeDM(dc *v1alpha1.DMCluster) error {
 			key := fmt.Sprintf("%s-%s-%s", dmMasterMemberType, dc.Name, dmMasterMemberType)
 			pvcPrefix2Quantity[key] = quantity
 		}
-		if err := p.patchPVCs(ns, selector.Add(*dmMasterRequirement), pvcPrefix2Quantity); err != nil {
+		if _, err := p.patchPVCs(ns, controller.DMMasterMemberName(dc.Name), selector.Add(*dmMasterRequirement), pvcPrefix2Quantity); err != nil {
 			return err
 		}
 	}
@@ -223,7 +233,7 @@ func (p *pvcResizer) ResizeDM(dc *v1alpha1.DMCluster) error {
 			key := fmt.Sprintf("%s-%s-%s", dmWorkerMemberType, dc.Name, dmWorkerMemberType)
 			pvcPrefix2Quantity[key] = quantity
 		}
-		if err := p.patchPVCs(ns, selector.Add(*dmWorkerRequirement), pvcPrefix2Quantity); err != nil {
+		if _, err := p.patchPVCs(ns, controller.DMWorkerMemberName(dc.Name), selector.Add(*dmWorkerRequirement), pvcPrefix2Quantity); err != nil {
 			return err
 		}
 	}
@@ -241,35 +251,119 @@ func (p *pvcResizer) isVolumeExpansionSupported(storageClassName string) (bool,
 	return *sc.AllowVolumeExpansion, nil
 }
 
-// patchPVCs patches PVCs filtered by selector and prefix.
-func (p *pvcResizer) patchPVCs(ns string, selector labels.Selector, pvcQuantityInSpec map[string]resource.Quantity) error {
-	if len(pvcQuantityInSpec) == 0 {
+// resizeComponent patches the PVCs of a component, records their resize status and recreates the
+// StatefulSet of the component if necessary.
+func (p *pvcResizer) resizeComponent(tc *v1alpha1.TidbCluster, volumes *map[string]v1alpha1.StorageVolumeStatus, phase v1alpha1.MemberPhase,
+	stsName string, selector labels.Selector, pvcPrefix2Quantity map[string]resource.Quantity) error {
+	status, err := p.patchPVCs(tc.GetNamespace(), stsName, selector, pvcPrefix2Quantity)
+	if err != nil {
+		return err
+	}
+	*volumes = status
+	return p.recreateStatefulSet(tc, phase, stsName, pvcPrefix2Quantity, status)
+}
+
+// recreateStatefulSet deletes the StatefulSet with its pods orphaned if the storage requests in its
+// volumeClaimTemplates are less than the desired ones and all the PVCs in use are resized. The
+// StatefulSet is recreated with the larger volumeClaimTemplates by the member manager later, which
+// adopts the existing pods without restarting them because the pod template is not changed.
+func (p *pvcResizer) recreateStatefulSet(tc *v1alpha1.TidbCluster, phase v1alpha1.MemberPhase, stsName string,
+	pvcPrefix2Quantity map[string]resource.Quantity, volumes map[string]v1alpha1.StorageVolumeStatus) error {
+	ns := tc.GetNamespace()
+	sts, err := p.deps.StatefulSetLister.StatefulSets(ns).Get(stsName)
+	if errors.IsNotFound(err) {
 		return nil
 	}
-	pvcs, err := p.deps.PVCLister.PersistentVolumeClaims(ns).List(selector)
 	if err != nil {
 		return err
 	}
+	if sts.DeletionTimestamp != nil {
+		return nil
+	}
 
+	outdated := false
+	for _, vct := range sts.Spec.VolumeClaimTemplates {
+		desired, ok := pvcPrefix2Quantity[fmt.Sprintf("%s-%s", vct.Name, stsName)]
+		if !ok {
+			continue
+		}
+		if request, ok := vct.Spec.Resources.Requests[corev1.ResourceStorage]; ok && desired.Cmp(request) > 0 {
+			outdated = true
+		}
+	}
+	if !outdated {
+		return nil
+	}
+	if phase != v1alpha1.NormalPhase {
+		klog.Infof("StatefulSet %s/%s has outdated volumeClaimTemplates, wait for the phase %s to be %s before recreating it", ns, stsName, phase, v1alpha1.NormalPhase)
+		return nil
+	}
+	podOrdinals := helper.GetPodOrdinals(*sts.Spec.Replicas, sts)
+	for pvcName, volume := range volumes {
+		ordinal, err := util.GetOrdinalFromPodName(volume.PodName)
+		if err != nil || !podOrdinals.Has(ordinal) {
+			continue
+		}
+		if volume.ResizeState != v1alpha1.VolumeResized {
+			klog.Infof("StatefulSet %s/%s has outdated volumeClaimTemplates, wait for PVC %s to be resized before recreating it, current state: %q", ns, stsName, pvcName, volume.ResizeState)
+			return nil
+		}
+	}
+
+	orphan := metav1.DeletePropagationOrphan
+	err = p.deps.KubeClientset.AppsV1().StatefulSets(ns).Delete(stsName, &metav1.DeleteOptions{
+		PropagationPolicy: &orphan,
+		Preconditions:     &metav1.Preconditions{UID: &sts.UID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete StatefulSet %s/%s to update volumeClaimTemplates, error: %v", ns, stsName, err)
+	}
+	klog.Infof("StatefulSet %s/%s is deleted with pods orphaned to be recreated with the resized volumeClaimTemplates", ns, stsName)
+	p.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, "RecreateStatefulSet", "StatefulSet %s is deleted with pods orphaned to be recreated with the resized volumeClaimTemplates", stsName)
+	return nil
+}
+
+// patchPVCs patches PVCs filtered by selector and prefix, and returns the resize status of them
+// keyed by the PVC name.
+func (p *pvcResizer) patchPVCs(ns string, stsName string, selector labels.Selector, pvcQuantityInSpec map[string]resource.Quantity) (map[string]v1alpha1.StorageVolumeStatus, error) {
+	if len(pvcQuantityInSpec) == 0 {
+		return nil, nil
+	}
+	pvcs, err := p.deps.PVCLister.PersistentVolumeClaims(ns).List(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	volumes := make(map[string]v1alpha1.StorageVolumeStatus)
 	// the PVC name for StatefulSet will be ${pvcNameInTemplate}-${stsName}-${ordinal}, here we want to drop the ordinal
-	rePvcPrefix := regexp.MustCompile(`^(.+)-\d+$`)
+	rePvcPrefix := regexp.MustCompile(`^(.+)-(\d+)$`)
 	for _, pvc := range pvcs {
 		match := rePvcPrefix.FindStringSubmatch(pvc.Name)
+		if match == nil {
+			continue
+		}
 		pvcPrefix := match[1]
 		quantityInSpec, ok := pvcQuantityInSpec[pvcPrefix]
 		if !ok {
 			// TODO: PVC not specified in tc.spec, should we deal with it and raise a warning
 			continue
 		}
+		volume := v1alpha1.StorageVolumeStatus{
+			PodName:         fmt.Sprintf("%s-%s", stsName, match[2]),
+			CurrentCapacity: pvc.Status.Capacity[corev1.ResourceStorage],
+			DesiredCapacity: quantityInSpec,
+		}
 
 		if pvc.Spec.StorageClassName == nil {
 			klog.Warningf("PVC %s/%s has no storage class, skipped", pvc.Namespace, pvc.Name)
+			volumes[pvc.Name] = volume
 			continue
 		}
 
 		currentRequest, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
 		if !ok {
 			klog.Warningf("PVC %s/%s storage request is empty, skipped", pvc.Namespace, pvc.Name)
+			volumes[pvc.Name] = volume
 			continue
 		}
 
@@ -277,10 +371,12 @@ func (p *pvcResizer) patchPVCs(ns string, selector labels.Selector, pvcQuantityI
 			if p.deps.StorageClassLister != nil {
 				volumeExpansionSupported, err := p.isVolumeExpansionSupported(*pvc.Spec.StorageClassName)
 				if err != nil {
-					return err
+					return nil, err
 				}
 				if !volumeExpansionSupported {
 					klog.Warningf("Storage Class %q used by PVC %s/%s does not support volume expansion, skipped", *pvc.Spec.StorageClassName, pvc.Namespace, pvc.Name)
+					volume.ResizeState = v1alpha1.VolumeResizeUnsupported
+					volumes[pvc.Name] = volume
 					continue
 				}
 			} else {
@@ -297,20 +393,37 @@ func (p *pvcResizer) patchPVCs(ns string, selector labels.Selector, pvcQuantityI
 				},
 			})
 			if err != nil {
-				return err
+				return nil, err
 			}
 			_, err = p.deps.KubeClientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Patch(pvc.Name, types.MergePatchType, mergePatch)
 			if err != nil {
-				return err
+				return nil, err
 			}
 			klog.V(2).Infof("PVC %s/%s storage request is updated from %s to %s", pvc.Namespace, pvc.Name, currentRequest.String(), quantityInSpec.String())
+			volume.ResizeState = v1alpha1.VolumeResizing
 		} else if quantityInSpec.Cmp(currentRequest) < 0 {
 			klog.Warningf("PVC %s/%s/ storage request cannot be shrunk (%s to %s), skipped", pvc.Namespace, pvc.Name, currentRequest.String(), quantityInSpec.String())
+			volume.ResizeState = volumeResizeState(pvc, quantityInSpec)
 		} else {
 			klog.V(4).Infof("PVC %s/%s storage request is already %s, skipped", pvc.Namespace, pvc.Name, quantityInSpec.String())
+			volume.ResizeState = volumeResizeState(pvc, quantityInSpec)
 		}
+		volumes[pvc.Name] = volume
 	}
-	return nil
+	return volumes, nil
+}
+
+// volumeResizeState returns the resize state of the PVC whose storage request is already patched
+func volumeResizeState(pvc *corev1.PersistentVolumeClaim, desired resource.Quantity) v1alpha1.VolumeResizeState {
+	if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok && capacity.Cmp(desired) >= 0 {
+		return v1alpha1.VolumeResized
+	}
+	for _, cond := range pvc.Status.Conditions {
+		if cond.Type == corev1.PersistentVolumeClaimFileSystemResizePending && cond.Status == corev1.ConditionTrue {
+			return v1alpha1.VolumeFileSystemResizePending
+		}
+	}
+	return v1alpha1.VolumeResizing
 }
 
 func NewPVCResizer(deps *controller.Dependencies) PVCResizerInterface {
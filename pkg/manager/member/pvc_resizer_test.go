@@ -19,9 +19,11 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	. "github.com/onsi/gomega"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/label"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -409,6 +411,118 @@ func TestPVCResizer(t *testing.T) {
 	}
 }
 
+func TestPVCResizerVolumeStatusAndRecreateStatefulSet(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name          string
+		phase         v1alpha1.MemberPhase
+		capacity      string
+		resizePending bool
+		expectState   v1alpha1.VolumeResizeState
+		expectDeleted bool
+	}{
+		{
+			name:        "PVC is resizing",
+			phase:       v1alpha1.NormalPhase,
+			capacity:    "1Gi",
+			expectState: v1alpha1.VolumeResizing,
+		},
+		{
+			name:          "PVC is waiting for file system resize",
+			phase:         v1alpha1.NormalPhase,
+			capacity:      "1Gi",
+			resizePending: true,
+			expectState:   v1alpha1.VolumeFileSystemResizePending,
+		},
+		{
+			name:          "PVC is resized",
+			phase:         v1alpha1.NormalPhase,
+			capacity:      "2Gi",
+			expectState:   v1alpha1.VolumeResized,
+			expectDeleted: true,
+		},
+		{
+			name:        "PVC is resized but TiKV is upgrading",
+			phase:       v1alpha1.UpgradePhase,
+			capacity:    "2Gi",
+			expectState: v1alpha1.VolumeResized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		tc := &v1alpha1.TidbCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: v1.NamespaceDefault,
+				Name:      "tc",
+			},
+			Spec: v1alpha1.TidbClusterSpec{
+				TiKV: &v1alpha1.TiKVSpec{
+					ResourceRequirements: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceStorage: resource.MustParse("2Gi"),
+						},
+					},
+				},
+			},
+			Status: v1alpha1.TidbClusterStatus{
+				TiKV: v1alpha1.TiKVStatus{Phase: tt.phase},
+			},
+		}
+		pvc := newPVCWithStorage("tikv-tc-tikv-0", label.TiKVLabelVal, "sc", "2Gi")
+		pvc.Status.Capacity = v1.ResourceList{v1.ResourceStorage: resource.MustParse(tt.capacity)}
+		if tt.resizePending {
+			pvc.Status.Conditions = []v1.PersistentVolumeClaimCondition{
+				{Type: v1.PersistentVolumeClaimFileSystemResizePending, Status: v1.ConditionTrue},
+			}
+		}
+		sts := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: v1.NamespaceDefault,
+				Name:      "tc-tikv",
+			},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: pointer.Int32Ptr(1),
+				VolumeClaimTemplates: []v1.PersistentVolumeClaim{
+					*newPVCWithStorage("tikv", label.TiKVLabelVal, "sc", "1Gi"),
+				},
+			},
+		}
+
+		fakeDeps := controller.NewFakeDependencies()
+		fakeDeps.KubeClientset.CoreV1().PersistentVolumeClaims(pvc.Namespace).Create(pvc)
+		fakeDeps.KubeClientset.StorageV1().StorageClasses().Create(newStorageClass("sc", true))
+		fakeDeps.KubeClientset.AppsV1().StatefulSets(sts.Namespace).Create(sts)
+
+		resizer := NewPVCResizer(fakeDeps)
+		informerFactory := fakeDeps.KubeInformerFactory
+		informerFactory.Start(ctx.Done())
+		informerFactory.WaitForCacheSync(ctx.Done())
+
+		err := resizer.Resize(tc)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(tc.Status.TiKV.Volumes).To(Equal(map[string]v1alpha1.StorageVolumeStatus{
+			"tikv-tc-tikv-0": {
+				PodName:         "tc-tikv-0",
+				CurrentCapacity: resource.MustParse(tt.capacity),
+				DesiredCapacity: resource.MustParse("2Gi"),
+				ResizeState:     tt.expectState,
+			},
+		}))
+
+		_, err = fakeDeps.KubeClientset.AppsV1().StatefulSets(sts.Namespace).Get(sts.Name, metav1.GetOptions{})
+		if tt.expectDeleted {
+			g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		} else {
+			g.Expect(err).NotTo(HaveOccurred())
+		}
+		cancel()
+	}
+}
+
 func TestDMPVCResizer(t *testing.T) {
 	tests := []struct {
 		name     string
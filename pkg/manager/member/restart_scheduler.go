@@ -0,0 +1,200 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/robfig/cron"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+)
+
+const (
+	// RestartScheduleReason is the reason of the events emitted by the restart scheduler
+	RestartScheduleReason = "RestartSchedule"
+
+	defaultRestartWindow = 2 * time.Hour
+)
+
+// restartScheduler restarts the pods of the components periodically in the maintenance windows of
+// `spec.restartSchedules`, e.g. restarting TiDB weekly to clear the memory fragmentation.
+//
+// When a maintenance window begins, the ordinals of all the pods of the component are added to the restart
+// ordinals annotation, e.g. `tidb.tidb.pingcap.com/restart-ordinals`, and the pods are restarted gracefully one
+// at a time by the pod restarter with the same safety logic as the upgraders. The ordinals left in the annotation
+// are removed when the window ends, so no pod begins to restart out of the window. The windows which begin before
+// the schedule is added, or when the operator is down and end before it is up again, are skipped.
+type restartScheduler struct {
+	deps *controller.Dependencies
+}
+
+// NewRestartScheduler returns a restart scheduler
+func NewRestartScheduler(deps *controller.Dependencies) manager.Manager {
+	return &restartScheduler{
+		deps: deps,
+	}
+}
+
+func (s *restartScheduler) Sync(tc *v1alpha1.TidbCluster) error {
+	if len(tc.Spec.RestartSchedules) == 0 {
+		tc.Status.RestartSchedules = nil
+		return nil
+	}
+	now := time.Now()
+	statuses := make([]v1alpha1.RestartScheduleStatus, 0, len(tc.Spec.RestartSchedules))
+	for _, schedule := range tc.Spec.RestartSchedules {
+		status := v1alpha1.RestartScheduleStatus{Component: schedule.Component}
+		for _, st := range tc.Status.RestartSchedules {
+			if st.Component == schedule.Component {
+				status = st
+			}
+		}
+		if componentInSpec(tc, schedule.Component) && restartOrdinalsAnnKey(schedule.Component) != "" {
+			if err := s.sync(tc, schedule, &status, now); err != nil {
+				tc.Status.RestartSchedules = append(statuses, status)
+				return err
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	tc.Status.RestartSchedules = statuses
+	return nil
+}
+
+func (s *restartScheduler) sync(tc *v1alpha1.TidbCluster, schedule v1alpha1.RestartSchedule, status *v1alpha1.RestartScheduleStatus, now time.Time) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	memberType := schedule.Component
+	if status.LastScheduleTime == nil {
+		// the window in progress when the schedule is added is skipped
+		status.LastScheduleTime = &metav1.Time{Time: now}
+		return nil
+	}
+
+	if status.WindowEndTime != nil {
+		pods, err := s.listPods(tc, memberType)
+		if err != nil {
+			return err
+		}
+		ordinals := getRestartOrdinals(tc, memberType)
+		restarting := false
+		for _, pod := range pods {
+			if _, ok := pod.Annotations[label.AnnPodRestartBeginTime]; ok {
+				restarting = true
+			}
+		}
+		switch {
+		case ordinals.Len() == 0 && !restarting:
+			status.LastCompletionTime = &metav1.Time{Time: now}
+			status.WindowEndTime = nil
+			status.Message = ""
+			klog.Infof("restart scheduler: tidbcluster %s/%s all the %s pods are restarted in the maintenance window", ns, tcName, memberType)
+			s.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, RestartScheduleReason, "all the %s pods are restarted in the maintenance window", memberType)
+		case now.After(status.WindowEndTime.Time):
+			if ordinals.Len() > 0 {
+				if err := patchRestartOrdinals(s.deps, tc, memberType, sets.NewInt32()); err != nil {
+					return err
+				}
+			}
+			status.Message = fmt.Sprintf("%s pods %v are not restarted in the maintenance window ended at %s", memberType, ordinals.List(), status.WindowEndTime.Format(time.RFC3339))
+			status.WindowEndTime = nil
+			klog.Warningf("restart scheduler: tidbcluster %s/%s %s", ns, tcName, status.Message)
+			s.deps.Recorder.Event(tc, corev1.EventTypeWarning, RestartScheduleReason, status.Message)
+		}
+		return nil
+	}
+
+	window := defaultRestartWindow
+	if schedule.Window != nil {
+		window = schedule.Window.Duration
+	}
+	begin, err := lastRestartWindowBegin(schedule.Schedule, status.LastScheduleTime.Time, now, window)
+	if err != nil {
+		status.Message = err.Error()
+		return nil
+	}
+	if begin.IsZero() {
+		return nil
+	}
+
+	pods, err := s.listPods(tc, memberType)
+	if err != nil {
+		return err
+	}
+	ordinals := getRestartOrdinals(tc, memberType)
+	for _, pod := range pods {
+		ordinal, err := util.GetOrdinalFromPodName(pod.Name)
+		if err != nil {
+			return err
+		}
+		ordinals.Insert(ordinal)
+	}
+	if err := patchRestartOrdinals(s.deps, tc, memberType, ordinals); err != nil {
+		return err
+	}
+	status.LastScheduleTime = &metav1.Time{Time: begin}
+	status.WindowEndTime = &metav1.Time{Time: begin.Add(window)}
+	status.Message = ""
+	klog.Infof("restart scheduler: tidbcluster %s/%s begins to restart %s pods %v in the maintenance window", ns, tcName, memberType, ordinals.List())
+	s.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, RestartScheduleReason, "begin to restart %s pods %v in the maintenance window until %s",
+		memberType, ordinals.List(), status.WindowEndTime.Format(time.RFC3339))
+	return nil
+}
+
+func (s *restartScheduler) listPods(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) ([]*corev1.Pod, error) {
+	selector, err := label.New().Instance(tc.GetInstanceName()).Component(memberType.String()).Selector()
+	if err != nil {
+		return nil, err
+	}
+	return s.deps.PodLister.Pods(tc.GetNamespace()).List(selector)
+}
+
+// lastRestartWindowBegin returns the begin time of the maintenance window in progress at now, zero if there
+// is no window in progress or it begins before last
+func lastRestartWindowBegin(schedule string, last, now time.Time, window time.Duration) (time.Time, error) {
+	sched, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse schedule %q: %v", schedule, err)
+	}
+	// only the windows which begin in the last window length may be in progress
+	earliest := last
+	if earliest.Before(now.Add(-window)) {
+		earliest = now.Add(-window)
+	}
+	var begin time.Time
+	for t := sched.Next(earliest); !t.After(now); t = sched.Next(t) {
+		begin = t
+	}
+	return begin, nil
+}
+
+type fakeRestartScheduler struct{}
+
+// NewFakeRestartScheduler returns a fake restart scheduler
+func NewFakeRestartScheduler() manager.Manager {
+	return &fakeRestartScheduler{}
+}
+
+func (s *fakeRestartScheduler) Sync(_ *v1alpha1.TidbCluster) error {
+	return nil
+}
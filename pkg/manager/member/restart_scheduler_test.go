@@ -0,0 +1,124 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRestartSchedulerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+	deps := controller.NewFakeDependencies()
+	scheduler := NewRestartScheduler(deps)
+	podIndexer := deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	tc := newTidbClusterForTiDB()
+	tc.Spec.RestartSchedules = []v1alpha1.RestartSchedule{
+		{Component: v1alpha1.TiDBMemberType, Schedule: "* * * * *", Window: &metav1.Duration{Duration: time.Hour}},
+	}
+	setTiDBPods(g, podIndexer, tc, "pingcap/tidb:v4.0.9", "pingcap/tidb:v4.0.9")
+
+	// the window in progress when the schedule is added is skipped
+	g.Expect(scheduler.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.RestartSchedules).To(HaveLen(1))
+	status := &tc.Status.RestartSchedules[0]
+	g.Expect(status.LastScheduleTime).NotTo(BeNil())
+	g.Expect(tc.Annotations).NotTo(HaveKey(label.AnnTiDBRestartOrdinals))
+
+	// all the pods are requested to restart when the window begins
+	status.LastScheduleTime = &metav1.Time{Time: time.Now().Add(-2 * time.Minute)}
+	g.Expect(scheduler.Sync(tc)).To(Succeed())
+	status = &tc.Status.RestartSchedules[0]
+	g.Expect(tc.Annotations[label.AnnTiDBRestartOrdinals]).To(Equal("[0,1]"))
+	g.Expect(status.WindowEndTime).NotTo(BeNil())
+	g.Expect(status.WindowEndTime.Sub(status.LastScheduleTime.Time)).To(Equal(time.Hour))
+
+	// the pods which do not begin to restart in the window are not restarted
+	tc.Annotations[label.AnnTiDBRestartOrdinals] = "[1]"
+	status.WindowEndTime = &metav1.Time{Time: time.Now().Add(-time.Second)}
+	g.Expect(scheduler.Sync(tc)).To(Succeed())
+	status = &tc.Status.RestartSchedules[0]
+	g.Expect(tc.Annotations).NotTo(HaveKey(label.AnnTiDBRestartOrdinals))
+	g.Expect(status.WindowEndTime).To(BeNil())
+	g.Expect(status.Message).To(ContainSubstring("[1]"))
+
+	// the completion is reported after all the pods are restarted
+	status.LastScheduleTime = &metav1.Time{Time: time.Now().Add(-2 * time.Minute)}
+	g.Expect(scheduler.Sync(tc)).To(Succeed())
+	g.Expect(tc.Annotations[label.AnnTiDBRestartOrdinals]).To(Equal("[0,1]"))
+	delete(tc.Annotations, label.AnnTiDBRestartOrdinals)
+	g.Expect(scheduler.Sync(tc)).To(Succeed())
+	status = &tc.Status.RestartSchedules[0]
+	g.Expect(status.WindowEndTime).To(BeNil())
+	g.Expect(status.LastCompletionTime).NotTo(BeNil())
+	g.Expect(status.Message).To(BeEmpty())
+
+	tc.Spec.RestartSchedules = nil
+	g.Expect(scheduler.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.RestartSchedules).To(BeNil())
+}
+
+func TestLastRestartWindowBegin(t *testing.T) {
+	g := NewGomegaWithT(t)
+	// Saturday, 2021-01-02
+	saturday := time.Date(2021, 1, 2, 0, 0, 0, 0, time.Local)
+	at := func(d time.Duration) time.Time {
+		return saturday.Add(d)
+	}
+
+	tests := []struct {
+		name   string
+		last   time.Time
+		now    time.Time
+		expect time.Time
+	}{
+		{
+			name:   "in the window",
+			last:   at(-24 * time.Hour),
+			now:    at(4 * time.Hour),
+			expect: at(3 * time.Hour),
+		},
+		{
+			name: "out of the window",
+			last: at(-24 * time.Hour),
+			now:  at(6 * time.Hour),
+		},
+		{
+			name: "before the window",
+			last: at(-24 * time.Hour),
+			now:  at(2 * time.Hour),
+		},
+		{
+			name: "the window begins before the last schedule time",
+			last: at(3*time.Hour + time.Minute),
+			now:  at(4 * time.Hour),
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.name)
+		begin, err := lastRestartWindowBegin("0 3 * * 6", test.last, test.now, 2*time.Hour)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(begin).To(Equal(test.expect))
+	}
+
+	_, err := lastRestartWindowBegin("every saturday", saturday, saturday, time.Hour)
+	g.Expect(err).To(HaveOccurred())
+}
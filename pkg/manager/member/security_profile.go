@@ -0,0 +1,106 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// restrictedUserID is the uid, gid and fsGroup of the pods with the Restricted security profile
+	restrictedUserID int64 = 1000
+)
+
+// applySecurityProfile hardens the pod spec if the security profile of the component is Restricted
+// and returns the pod annotations with the RuntimeDefault seccomp profile. The fields configured
+// explicitly in the podSecurityContext or the security contexts of the containers are kept, and
+// privileged containers, e.g. the sysctl init container, keep running as root.
+//
+// readOnlyContainer is the name of the container whose root filesystem is mounted read-only, it
+// should only be set for the components that do not write to the root filesystem, e.g. TiDB writes
+// the temporary files of the large queries to /tmp.
+func applySecurityProfile(spec v1alpha1.ComponentAccessor, podSpec *corev1.PodSpec, podAnnotations map[string]string, readOnlyContainer string) map[string]string {
+	if spec.SecurityProfile() != v1alpha1.SecurityProfileRestricted {
+		return podAnnotations
+	}
+
+	podSecurityContext := podSpec.SecurityContext.DeepCopy()
+	if podSecurityContext == nil {
+		podSecurityContext = &corev1.PodSecurityContext{}
+	}
+	if podSecurityContext.RunAsNonRoot == nil {
+		podSecurityContext.RunAsNonRoot = pointer.BoolPtr(true)
+	}
+	if podSecurityContext.RunAsUser == nil {
+		podSecurityContext.RunAsUser = pointer.Int64Ptr(restrictedUserID)
+	}
+	if podSecurityContext.RunAsGroup == nil {
+		podSecurityContext.RunAsGroup = pointer.Int64Ptr(restrictedUserID)
+	}
+	if podSecurityContext.FSGroup == nil {
+		podSecurityContext.FSGroup = pointer.Int64Ptr(restrictedUserID)
+	}
+	podSpec.SecurityContext = podSecurityContext
+
+	// the containers may be shared with the spec of the component
+	podSpec.InitContainers = append([]corev1.Container(nil), podSpec.InitContainers...)
+	podSpec.Containers = append([]corev1.Container(nil), podSpec.Containers...)
+	for i := range podSpec.InitContainers {
+		restrictContainer(&podSpec.InitContainers[i], false)
+	}
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		restrictContainer(container, readOnlyContainer != "" && container.Name == readOnlyContainer)
+	}
+
+	if _, ok := podAnnotations[corev1.SeccompPodAnnotationKey]; ok {
+		return podAnnotations
+	}
+	return util.CombineStringMap(podAnnotations, map[string]string{
+		corev1.SeccompPodAnnotationKey: corev1.SeccompProfileRuntimeDefault,
+	})
+}
+
+func restrictContainer(container *corev1.Container, readOnlyRootFilesystem bool) {
+	securityContext := container.SecurityContext.DeepCopy()
+	if securityContext == nil {
+		securityContext = &corev1.SecurityContext{}
+	}
+	container.SecurityContext = securityContext
+
+	if securityContext.Privileged != nil && *securityContext.Privileged {
+		// privileged containers can not be admitted by the restricted namespaces anyway
+		if securityContext.RunAsNonRoot == nil {
+			securityContext.RunAsNonRoot = pointer.BoolPtr(false)
+		}
+		if securityContext.RunAsUser == nil {
+			securityContext.RunAsUser = pointer.Int64Ptr(0)
+		}
+		return
+	}
+	if securityContext.AllowPrivilegeEscalation == nil {
+		securityContext.AllowPrivilegeEscalation = pointer.BoolPtr(false)
+	}
+	if securityContext.Capabilities == nil {
+		securityContext.Capabilities = &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+		}
+	}
+	if securityContext.ReadOnlyRootFilesystem == nil && readOnlyRootFilesystem {
+		securityContext.ReadOnlyRootFilesystem = pointer.BoolPtr(true)
+	}
+}
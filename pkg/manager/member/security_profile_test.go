@@ -0,0 +1,73 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestApplySecurityProfile(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPD()
+	tc.Spec.SecurityProfile = v1alpha1.SecurityProfileRestricted
+	tc.Spec.PD.PodSecurityContext = &corev1.PodSecurityContext{
+		RunAsUser: pointer.Int64Ptr(2000),
+		Sysctls:   []corev1.Sysctl{{Name: "net.core.somaxconn", Value: "32768"}},
+	}
+	tc.Spec.PD.AdditionalContainers = []corev1.Container{{
+		Name:            "sidecar",
+		SecurityContext: &corev1.SecurityContext{AllowPrivilegeEscalation: pointer.BoolPtr(true)},
+	}}
+	tc.Spec.PD.Annotations = map[string]string{label.AnnSysctlInit: label.AnnSysctlInitVal}
+
+	set, err := getNewPDSetForTidbCluster(tc, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	podSpec := set.Spec.Template.Spec
+	g.Expect(set.Spec.Template.Annotations).To(HaveKeyWithValue(corev1.SeccompPodAnnotationKey, corev1.SeccompProfileRuntimeDefault))
+	g.Expect(*podSpec.SecurityContext.RunAsNonRoot).To(BeTrue())
+	g.Expect(*podSpec.SecurityContext.RunAsUser).To(Equal(int64(2000)))
+	g.Expect(*podSpec.SecurityContext.FSGroup).To(Equal(restrictedUserID))
+
+	containers := MapContainers(&podSpec)
+	pd := containers[v1alpha1.PDMemberType.String()].SecurityContext
+	g.Expect(*pd.AllowPrivilegeEscalation).To(BeFalse())
+	g.Expect(*pd.ReadOnlyRootFilesystem).To(BeTrue())
+	g.Expect(pd.Capabilities.Drop).To(Equal([]corev1.Capability{"ALL"}))
+	// the additional containers may write to the root filesystem, and the explicit settings are kept
+	sidecar := containers["sidecar"].SecurityContext
+	g.Expect(*sidecar.AllowPrivilegeEscalation).To(BeTrue())
+	g.Expect(sidecar.ReadOnlyRootFilesystem).To(BeNil())
+	g.Expect(tc.Spec.PD.AdditionalContainers[0].SecurityContext.Capabilities).To(BeNil())
+	// the sysctl init container is privileged and runs as root
+	g.Expect(podSpec.InitContainers).To(HaveLen(1))
+	initContainer := podSpec.InitContainers[0].SecurityContext
+	g.Expect(*initContainer.RunAsUser).To(BeZero())
+	g.Expect(*initContainer.RunAsNonRoot).To(BeFalse())
+	g.Expect(initContainer.Capabilities).To(BeNil())
+
+	// the component-level profile overrides the cluster-level one
+	tc.Spec.PD.SecurityProfile = v1alpha1.SecurityProfileUnrestricted
+	set, err = getNewPDSetForTidbCluster(tc, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(set.Spec.Template.Annotations).NotTo(HaveKey(corev1.SeccompPodAnnotationKey))
+	g.Expect(set.Spec.Template.Spec.SecurityContext.RunAsNonRoot).To(BeNil())
+	g.Expect(MapContainers(&set.Spec.Template.Spec)[v1alpha1.PDMemberType.String()].SecurityContext).To(BeNil())
+}
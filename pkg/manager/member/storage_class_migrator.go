@@ -0,0 +1,380 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+)
+
+const (
+	// StorageClassMigrateReason is the reason of the events emitted by the storage class migrator
+	StorageClassMigrateReason = "StorageClassMigrate"
+)
+
+// storageClassMigrator moves the volumes of PD and TiKV to the storage classes in the spec,
+// e.g. `spec.tikv.storageClassName` and `spec.tikv.storageVolumes[].storageClassName`.
+//
+// It is opt-in by setting the annotation `tidb.pingcap.com/storage-class-migration: "true"`
+// on the TidbCluster. The StatefulSet whose volumeClaimTemplates use other storage classes is
+// deleted with the pods orphaned, so that it is recreated with the new storage classes, then
+// the members whose PVCs use other storage classes are replaced one by one in the order of ordinals:
+// - PD: the member is deleted from the cluster (the leader is transferred away first), then the
+// pod and the PVCs are deleted, so a new member is created on the new storage classes and
+// replicates the data from the other members
+// - TiKV: an extra store is provisioned on the new storage classes by recording a failure store,
+// then the old store is deleted from the cluster and its regions are replicated to the other stores.
+// After it becomes tombstone, the pod and the PVCs are deleted so a new store is created on the new
+// storage classes. The extra store is scaled in after all stores are migrated.
+//
+// It only starts migrating a member when the cluster is in Normal phase and all members are healthy.
+type storageClassMigrator struct {
+	deps *controller.Dependencies
+}
+
+// NewStorageClassMigrator returns a storage class migrator
+func NewStorageClassMigrator(deps *controller.Dependencies) manager.Manager {
+	return &storageClassMigrator{
+		deps: deps,
+	}
+}
+
+func (m *storageClassMigrator) Sync(tc *v1alpha1.TidbCluster) error {
+	if tc.Annotations[label.AnnStorageClassMigration] != "true" || tc.Adopting() {
+		return nil
+	}
+
+	if tc.Spec.PD != nil {
+		classes := desiredStorageClasses(v1alpha1.PDMemberType, tc.Spec.PD.StorageClassName, tc.Spec.PD.StorageVolumes)
+		if err := m.migratePD(tc, classes); err != nil {
+			return err
+		}
+	}
+	if tc.Spec.TiKV != nil {
+		classes := desiredStorageClasses(v1alpha1.TiKVMemberType, tc.Spec.TiKV.StorageClassName, tc.Spec.TiKV.StorageVolumes)
+		if err := m.migrateTiKV(tc, classes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// desiredStorageClasses returns the storage classes in the spec keyed by the name of the volumeClaimTemplates,
+// the volumes using the default storage class are not included
+func desiredStorageClasses(memberType v1alpha1.MemberType, storageClassName *string, storageVolumes []v1alpha1.StorageVolume) map[string]string {
+	classes := map[string]string{}
+	if storageClassName != nil && *storageClassName != "" {
+		classes[memberType.String()] = *storageClassName
+	}
+	_, claims := util.BuildStorageVolumeAndVolumeMount(storageVolumes, storageClassName, memberType)
+	for _, claim := range claims {
+		if claim.Spec.StorageClassName != nil && *claim.Spec.StorageClassName != "" {
+			classes[claim.Name] = *claim.Spec.StorageClassName
+		}
+	}
+	return classes
+}
+
+func storageClassOutdated(claim *corev1.PersistentVolumeClaim, vctName string, classes map[string]string) bool {
+	class, ok := classes[vctName]
+	if !ok {
+		return false
+	}
+	return claim.Spec.StorageClassName == nil || *claim.Spec.StorageClassName != class
+}
+
+func (m *storageClassMigrator) migratePD(tc *v1alpha1.TidbCluster, classes map[string]string) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	if len(classes) == 0 {
+		return nil
+	}
+	if err := m.recreateStatefulSet(tc, controller.PDMemberName(tcName), tc.Status.PD.Phase == v1alpha1.NormalPhase, classes); err != nil {
+		return err
+	}
+
+	podName, pvcs, err := m.outdatedPod(tc, v1alpha1.PDMemberType, tc.PDStsDesiredOrdinals(false), classes)
+	if err != nil || podName == "" {
+		return err
+	}
+	if reason := unsafeToRebalance(tc); reason != "" {
+		klog.Infof("storage class migrator: wait to migrate pd pod %s/%s, %s", ns, podName, reason)
+		return nil
+	}
+	if len(tc.Status.PD.Members) < int(tc.PDStsDesiredReplicas()) {
+		klog.Infof("storage class migrator: wait to migrate pd pod %s/%s, %d of %d pd members are in the cluster",
+			ns, podName, len(tc.Status.PD.Members), tc.PDStsDesiredReplicas())
+		return nil
+	}
+	if len(tc.Status.PD.Members) < 3 {
+		klog.Warningf("storage class migrator: can not migrate pd pod %s/%s, at least 3 pd members are required to keep the quorum", ns, podName)
+		return nil
+	}
+
+	memberName := ""
+	for name := range tc.Status.PD.Members {
+		if strings.Split(name, ".")[0] == podName {
+			memberName = name
+		}
+	}
+	pdClient := controller.GetPDClient(m.deps.PDControl, tc)
+	if memberName != "" {
+		if tc.Status.PD.Leader.Name == memberName {
+			target := pdLeaderTransferTarget(tc)
+			if target == "" {
+				return controller.RequeueErrorf("storage class migrator: tidbcluster: [%s/%s] no pd member to transfer leader to", ns, tcName)
+			}
+			if err := pdClient.TransferPDLeader(target); err != nil {
+				return err
+			}
+			return controller.RequeueErrorf("storage class migrator: tidbcluster: [%s/%s]'s pd member: [%s] is transferring leader to pd member: [%s]", ns, tcName, memberName, target)
+		}
+		if err := pdClient.DeleteMember(memberName); err != nil {
+			return fmt.Errorf("storage class migrator: failed to delete pd member %s of tc %s/%s, error: %v", memberName, ns, tcName, err)
+		}
+		klog.Infof("storage class migrator: pd member %s of tc %s/%s is deleted", memberName, ns, tcName)
+	}
+	return m.replacePod(tc, podName, pvcs)
+}
+
+func (m *storageClassMigrator) migrateTiKV(tc *v1alpha1.TidbCluster, classes map[string]string) error {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+
+	var migrating *v1alpha1.TiKVFailureStore
+	for _, failureStore := range tc.Status.TiKV.FailureStores {
+		if failureStore.StorageClassMigration {
+			failureStore := failureStore
+			migrating = &failureStore
+			break
+		}
+	}
+	if len(classes) == 0 && migrating == nil {
+		return nil
+	}
+	if err := m.recreateStatefulSet(tc, controller.TiKVMemberName(tcName), tc.Status.TiKV.Phase == v1alpha1.NormalPhase || migrating != nil, classes); err != nil {
+		return err
+	}
+
+	podName, pvcs, err := m.outdatedPod(tc, v1alpha1.TiKVMemberType, tc.TiKVStsDesiredOrdinals(false), classes)
+	if err != nil {
+		return err
+	}
+
+	if migrating == nil {
+		if podName == "" {
+			return nil
+		}
+		if reason := unsafeToRebalance(tc); reason != "" {
+			klog.Infof("storage class migrator: wait to migrate tikv pod %s/%s, %s", ns, podName, reason)
+			return nil
+		}
+		return m.beginTiKVMigration(tc, podName)
+	}
+
+	if migrating.PodName != podName {
+		// the volumes of the pod have been migrated, move on to the next pod once its new store is up
+		recreated := false
+		for _, store := range tc.Status.TiKV.Stores {
+			if store.PodName == migrating.PodName && store.ID != migrating.StoreID && store.State == v1alpha1.TiKVStateUp {
+				recreated = true
+			}
+		}
+		if !recreated {
+			return controller.RequeueErrorf("storage class migrator: wait for the new store of tikv pod %s/%s to be up", ns, migrating.PodName)
+		}
+		delete(tc.Status.TiKV.FailureStores, migrating.StoreID)
+		if podName == "" {
+			klog.Infof("storage class migrator: tikv stores of tc %s/%s are migrated, scale in the extra store", ns, tcName)
+			m.deps.Recorder.Event(tc, corev1.EventTypeNormal, StorageClassMigrateReason, "tikv stores are migrated to the new storage classes")
+			return nil
+		}
+		return m.beginTiKVMigration(tc, podName)
+	}
+
+	store, exist := tc.Status.TiKV.Stores[migrating.StoreID]
+	if !exist {
+		store, exist = tc.Status.TiKV.TombstoneStores[migrating.StoreID]
+	}
+	if exist && store.State != v1alpha1.TiKVStateTombstone {
+		if store.State == v1alpha1.TiKVStateOffline {
+			return controller.RequeueErrorf("storage class migrator: tikv store %s of pod %s/%s is offline, wait for it to become tombstone", store.ID, ns, podName)
+		}
+		upStores := 0
+		for _, s := range tc.Status.TiKV.Stores {
+			if s.ID != store.ID && s.State == v1alpha1.TiKVStateUp {
+				upStores++
+			}
+		}
+		if upStores < int(tc.Spec.TiKV.Replicas) {
+			return controller.RequeueErrorf("storage class migrator: wait for the extra tikv store of tc %s/%s to be up, %d of %d other stores are up", ns, tcName, upStores, tc.Spec.TiKV.Replicas)
+		}
+		id, err := strconv.ParseUint(store.ID, 10, 64)
+		if err != nil {
+			return err
+		}
+		if err := controller.GetPDClient(m.deps.PDControl, tc).DeleteStore(id); err != nil {
+			return fmt.Errorf("storage class migrator: failed to delete tikv store %s of tc %s/%s, error: %v", store.ID, ns, tcName, err)
+		}
+		klog.Infof("storage class migrator: tikv store %s of pod %s/%s is deleted", store.ID, ns, podName)
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, StorageClassMigrateReason, "tikv store %s of pod %s is deleted to migrate its volumes", store.ID, podName)
+		return controller.RequeueErrorf("storage class migrator: tikv store %s of pod %s/%s is deleted, wait for it to become tombstone", store.ID, ns, podName)
+	}
+	return m.replacePod(tc, podName, pvcs)
+}
+
+// beginTiKVMigration records the store of the pod as a failure store, so that an extra store is provisioned
+// on the new storage classes before the store is deleted
+func (m *storageClassMigrator) beginTiKVMigration(tc *v1alpha1.TidbCluster, podName string) error {
+	ns := tc.GetNamespace()
+	for _, store := range tc.Status.TiKV.Stores {
+		if store.PodName != podName {
+			continue
+		}
+		if tc.Status.TiKV.FailureStores == nil {
+			tc.Status.TiKV.FailureStores = map[string]v1alpha1.TiKVFailureStore{}
+		}
+		tc.Status.TiKV.FailureStores[store.ID] = v1alpha1.TiKVFailureStore{
+			PodName:               podName,
+			StoreID:               store.ID,
+			CreatedAt:             metav1.Now(),
+			StorageClassMigration: true,
+		}
+		klog.Infof("storage class migrator: begin to migrate tikv store %s of pod %s/%s", store.ID, ns, podName)
+		m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, StorageClassMigrateReason, "begin to migrate the volumes of tikv pod %s", podName)
+		return controller.RequeueErrorf("storage class migrator: wait for the extra tikv store of tc %s/%s to be up before migrating pod %s", ns, tc.GetName(), podName)
+	}
+	return controller.RequeueErrorf("storage class migrator: no store status found for tikv pod %s/%s", ns, podName)
+}
+
+// outdatedPod returns the first pod in the ordinals and its PVCs which do not use the desired storage classes,
+// empty if there is none
+func (m *storageClassMigrator) outdatedPod(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, ordinals sets.Int32,
+	classes map[string]string) (string, []*corev1.PersistentVolumeClaim, error) {
+	ns := tc.GetNamespace()
+	ordinalList := ordinals.List()
+	for _, ordinal := range ordinalList {
+		podName := ordinalPodName(memberType, tc.GetName(), ordinal)
+		selector, err := GetPVCSelectorForPod(tc, memberType, ordinal)
+		if err != nil {
+			return "", nil, err
+		}
+		pvcs, err := m.deps.PVCLister.PersistentVolumeClaims(ns).List(selector)
+		if err != nil {
+			return "", nil, fmt.Errorf("storage class migrator: failed to list PVCs of pod %s/%s, error: %v", ns, podName, err)
+		}
+		var outdated []*corev1.PersistentVolumeClaim
+		for _, pvc := range pvcs {
+			if pvc.DeletionTimestamp != nil {
+				continue
+			}
+			if storageClassOutdated(pvc, strings.TrimSuffix(pvc.Name, "-"+podName), classes) {
+				outdated = append(outdated, pvc)
+			}
+		}
+		if len(outdated) > 0 {
+			return podName, outdated, nil
+		}
+	}
+	return "", nil, nil
+}
+
+// replacePod deletes the pod and its PVCs using other storage classes, so that they are recreated by the
+// StatefulSet with the new storage classes
+func (m *storageClassMigrator) replacePod(tc *v1alpha1.TidbCluster, podName string, pvcs []*corev1.PersistentVolumeClaim) error {
+	ns := tc.GetNamespace()
+	pod, err := m.deps.PodLister.Pods(ns).Get(podName)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("storage class migrator: failed to get pod %s/%s, error: %v", ns, podName, err)
+	}
+	if err == nil && pod.DeletionTimestamp == nil {
+		if err := m.deps.PodControl.DeletePod(tc, pod); err != nil {
+			return err
+		}
+	}
+	for _, pvc := range pvcs {
+		if err := m.deps.PVCControl.DeletePVC(tc, pvc); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	klog.Infof("storage class migrator: pod %s/%s and its PVCs are deleted to be recreated with the new storage classes", ns, podName)
+	m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, StorageClassMigrateReason, "pod %s and its PVCs are deleted to be recreated with the new storage classes", podName)
+	return controller.RequeueErrorf("storage class migrator: wait for pod %s/%s to be recreated with the new storage classes", ns, podName)
+}
+
+// recreateStatefulSet deletes the StatefulSet with the pods orphaned if its volumeClaimTemplates use other
+// storage classes, so that the new pods are created with the new storage classes
+func (m *storageClassMigrator) recreateStatefulSet(tc *v1alpha1.TidbCluster, stsName string, ready bool, classes map[string]string) error {
+	ns := tc.GetNamespace()
+	sts, err := m.deps.StatefulSetLister.StatefulSets(ns).Get(stsName)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if sts.DeletionTimestamp != nil {
+		return nil
+	}
+
+	outdated := false
+	for i := range sts.Spec.VolumeClaimTemplates {
+		vct := &sts.Spec.VolumeClaimTemplates[i]
+		if storageClassOutdated(vct, vct.Name, classes) {
+			outdated = true
+		}
+	}
+	if !outdated {
+		return nil
+	}
+	if !ready {
+		klog.Infof("storage class migrator: StatefulSet %s/%s uses outdated storage classes, wait for it to be in %s phase before recreating it", ns, stsName, v1alpha1.NormalPhase)
+		return nil
+	}
+
+	orphan := metav1.DeletePropagationOrphan
+	err = m.deps.KubeClientset.AppsV1().StatefulSets(ns).Delete(stsName, &metav1.DeleteOptions{
+		PropagationPolicy: &orphan,
+		Preconditions:     &metav1.Preconditions{UID: &sts.UID},
+	})
+	if err != nil {
+		return fmt.Errorf("storage class migrator: failed to delete StatefulSet %s/%s to update volumeClaimTemplates, error: %v", ns, stsName, err)
+	}
+	klog.Infof("storage class migrator: StatefulSet %s/%s is deleted with pods orphaned to be recreated with the new storage classes", ns, stsName)
+	m.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, StorageClassMigrateReason, "StatefulSet %s is deleted with pods orphaned to be recreated with the new storage classes", stsName)
+	return controller.RequeueErrorf("storage class migrator: wait for StatefulSet %s/%s to be recreated", ns, stsName)
+}
+
+type fakeStorageClassMigrator struct{}
+
+// NewFakeStorageClassMigrator returns a fake storage class migrator
+func NewFakeStorageClassMigrator() manager.Manager {
+	return &fakeStorageClassMigrator{}
+}
+
+func (m *fakeStorageClassMigrator) Sync(_ *v1alpha1.TidbCluster) error {
+	return nil
+}
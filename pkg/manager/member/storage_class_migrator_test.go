@@ -0,0 +1,335 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestDesiredStorageClasses(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(desiredStorageClasses(v1alpha1.TiKVMemberType, nil, nil)).To(BeEmpty())
+	g.Expect(desiredStorageClasses(v1alpha1.TiKVMemberType, pointer.StringPtr("new"), []v1alpha1.StorageVolume{
+		{Name: "wal", StorageSize: "1Gi"},
+		{Name: "raft", StorageSize: "1Gi", StorageClassName: pointer.StringPtr("fast")},
+	})).To(Equal(map[string]string{
+		"tikv":      "new",
+		"tikv-wal":  "new",
+		"tikv-raft": "fast",
+	}))
+}
+
+func TestStorageClassMigratorSyncTiKV(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type testcase struct {
+		name                string
+		disabled            bool
+		stsClass            string
+		pvcClasses          []string
+		stores              map[string]v1alpha1.TiKVStore
+		failureStores       map[string]v1alpha1.TiKVFailureStore
+		errExpectFn         func(error)
+		expectStsDeleted    bool
+		expectStoreDel      bool
+		expectFailureStores map[string]string
+		expectReplaced      string
+	}
+
+	newStores := func(states ...string) map[string]v1alpha1.TiKVStore {
+		stores := map[string]v1alpha1.TiKVStore{}
+		for i, state := range states {
+			id := fmt.Sprintf("%d", i+1)
+			stores[id] = v1alpha1.TiKVStore{ID: id, PodName: ordinalPodName(v1alpha1.TiKVMemberType, "test", int32(i)), State: state}
+		}
+		return stores
+	}
+
+	testFn := func(test *testcase) {
+		t.Log(test.name)
+
+		deps := controller.NewFakeDependencies()
+		tc := newTidbClusterForPlacementRebalancer()
+		tc.Annotations = map[string]string{label.AnnStorageClassMigration: "true"}
+		if test.disabled {
+			tc.Annotations = nil
+		}
+		tc.Spec.PD.StorageClassName = nil
+		tc.Spec.TiKV = &v1alpha1.TiKVSpec{Replicas: 3, StorageClassName: pointer.StringPtr("new"), MaxFailoverCount: pointer.Int32Ptr(3)}
+		tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+		tc.Status.TiKV.Stores = test.stores
+		tc.Status.TiKV.FailureStores = test.failureStores
+		pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+		storeDeleted := false
+		pdClient.AddReaction(pdapi.DeleteStoreActionType, func(action *pdapi.Action) (interface{}, error) {
+			storeDeleted = true
+			return nil, nil
+		})
+
+		sts := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: controller.TiKVMemberName(tc.Name), Namespace: tc.Namespace},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: pointer.Int32Ptr(tc.TiKVStsDesiredReplicas()),
+				VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+					ObjectMeta: metav1.ObjectMeta{Name: "tikv"},
+					Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: pointer.StringPtr(test.stsClass)},
+				}},
+			},
+		}
+		deps.KubeInformerFactory.Apps().V1().StatefulSets().Informer().GetIndexer().Add(sts)
+		deps.KubeClientset.AppsV1().StatefulSets(sts.Namespace).Create(sts)
+
+		for i, class := range test.pvcClasses {
+			podName := ordinalPodName(v1alpha1.TiKVMemberType, tc.Name, int32(i))
+			deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Add(&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      podName,
+					Namespace: tc.Namespace,
+					Labels:    label.New().Instance(tc.Name).TiKV().Labels(),
+				},
+			})
+			pvcLabels := label.New().Instance(tc.Name).TiKV().Labels()
+			pvcLabels[label.AnnPodNameKey] = podName
+			deps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer().Add(&corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "tikv-" + podName,
+					Namespace: tc.Namespace,
+					Labels:    pvcLabels,
+				},
+				Spec: corev1.PersistentVolumeClaimSpec{StorageClassName: pointer.StringPtr(class)},
+			})
+		}
+
+		err := NewStorageClassMigrator(deps).Sync(tc)
+		test.errExpectFn(err)
+
+		_, err = deps.KubeClientset.AppsV1().StatefulSets(sts.Namespace).Get(sts.Name, metav1.GetOptions{})
+		if test.expectStsDeleted {
+			g.Expect(errors.IsNotFound(err)).To(BeTrue())
+		} else {
+			g.Expect(err).NotTo(HaveOccurred())
+		}
+		g.Expect(storeDeleted).To(Equal(test.expectStoreDel))
+
+		failureStores := map[string]string{}
+		for id, failureStore := range tc.Status.TiKV.FailureStores {
+			g.Expect(failureStore.StorageClassMigration).To(BeTrue())
+			failureStores[id] = failureStore.PodName
+		}
+		if test.expectFailureStores == nil {
+			g.Expect(failureStores).To(BeEmpty())
+		} else {
+			g.Expect(failureStores).To(Equal(test.expectFailureStores))
+		}
+
+		for i := range test.pvcClasses {
+			podName := ordinalPodName(v1alpha1.TiKVMemberType, tc.Name, int32(i))
+			_, podErr := deps.PodLister.Pods(tc.Namespace).Get(podName)
+			_, pvcErr := deps.PVCLister.PersistentVolumeClaims(tc.Namespace).Get("tikv-" + podName)
+			if podName == test.expectReplaced {
+				g.Expect(errors.IsNotFound(podErr)).To(BeTrue())
+				g.Expect(errors.IsNotFound(pvcErr)).To(BeTrue())
+			} else {
+				g.Expect(podErr).NotTo(HaveOccurred())
+				g.Expect(pvcErr).NotTo(HaveOccurred())
+			}
+		}
+	}
+
+	noErr := func(err error) { g.Expect(err).NotTo(HaveOccurred()) }
+	requeue := func(err error) { g.Expect(controller.IsRequeueError(err)).To(BeTrue()) }
+	migrating := func(id, podName string) map[string]v1alpha1.TiKVFailureStore {
+		return map[string]v1alpha1.TiKVFailureStore{
+			id: {PodName: podName, StoreID: id, StorageClassMigration: true},
+		}
+	}
+	tests := []testcase{
+		{
+			name:        "migration is disabled",
+			disabled:    true,
+			stsClass:    "old",
+			pvcClasses:  []string{"old", "old", "old"},
+			stores:      newStores(v1alpha1.TiKVStateUp, v1alpha1.TiKVStateUp, v1alpha1.TiKVStateUp),
+			errExpectFn: noErr,
+		},
+		{
+			name:             "recreate the statefulset with outdated storage class",
+			stsClass:         "old",
+			pvcClasses:       []string{"old", "old", "old"},
+			stores:           newStores(v1alpha1.TiKVStateUp, v1alpha1.TiKVStateUp, v1alpha1.TiKVStateUp),
+			errExpectFn:      requeue,
+			expectStsDeleted: true,
+		},
+		{
+			name:        "all volumes use the desired storage class",
+			stsClass:    "new",
+			pvcClasses:  []string{"new", "new", "new"},
+			stores:      newStores(v1alpha1.TiKVStateUp, v1alpha1.TiKVStateUp, v1alpha1.TiKVStateUp),
+			errExpectFn: noErr,
+		},
+		{
+			name:        "wait for the unhealthy store",
+			stsClass:    "new",
+			pvcClasses:  []string{"new", "old", "old"},
+			stores:      newStores(v1alpha1.TiKVStateUp, v1alpha1.TiKVStateUp, v1alpha1.TiKVStateDown),
+			errExpectFn: noErr,
+		},
+		{
+			name:                "provision an extra store",
+			stsClass:            "new",
+			pvcClasses:          []string{"new", "old", "old"},
+			stores:              newStores(v1alpha1.TiKVStateUp, v1alpha1.TiKVStateUp, v1alpha1.TiKVStateUp),
+			errExpectFn:         requeue,
+			expectFailureStores: map[string]string{"2": "test-tikv-1"},
+		},
+		{
+			name:                "wait for the extra store to be up",
+			stsClass:            "new",
+			pvcClasses:          []string{"new", "old", "old"},
+			stores:              newStores(v1alpha1.TiKVStateUp, v1alpha1.TiKVStateUp, v1alpha1.TiKVStateUp),
+			failureStores:       migrating("2", "test-tikv-1"),
+			errExpectFn:         requeue,
+			expectFailureStores: map[string]string{"2": "test-tikv-1"},
+		},
+		{
+			name:                "delete the store after the extra store is up",
+			stsClass:            "new",
+			pvcClasses:          []string{"new", "old", "old"},
+			stores:              newStores(v1alpha1.TiKVStateUp, v1alpha1.TiKVStateUp, v1alpha1.TiKVStateUp, v1alpha1.TiKVStateUp),
+			failureStores:       migrating("2", "test-tikv-1"),
+			errExpectFn:         requeue,
+			expectStoreDel:      true,
+			expectFailureStores: map[string]string{"2": "test-tikv-1"},
+		},
+		{
+			name:                "replace the pod after the store becomes tombstone",
+			stsClass:            "new",
+			pvcClasses:          []string{"new", "old", "old"},
+			stores:              newStores(v1alpha1.TiKVStateUp, v1alpha1.TiKVStateTombstone, v1alpha1.TiKVStateUp, v1alpha1.TiKVStateUp),
+			failureStores:       migrating("2", "test-tikv-1"),
+			errExpectFn:         requeue,
+			expectFailureStores: map[string]string{"2": "test-tikv-1"},
+			expectReplaced:      "test-tikv-1",
+		},
+		{
+			name:       "move on to the next pod after the new store is up",
+			stsClass:   "new",
+			pvcClasses: []string{"new", "new", "old"},
+			stores: func() map[string]v1alpha1.TiKVStore {
+				stores := newStores(v1alpha1.TiKVStateUp, v1alpha1.TiKVStateTombstone, v1alpha1.TiKVStateUp, v1alpha1.TiKVStateUp)
+				stores["5"] = v1alpha1.TiKVStore{ID: "5", PodName: "test-tikv-1", State: v1alpha1.TiKVStateUp}
+				return stores
+			}(),
+			failureStores:       migrating("2", "test-tikv-1"),
+			errExpectFn:         requeue,
+			expectFailureStores: map[string]string{"3": "test-tikv-2"},
+		},
+		{
+			name:       "scale in the extra store after all stores are migrated",
+			stsClass:   "new",
+			pvcClasses: []string{"new", "new", "new"},
+			stores: func() map[string]v1alpha1.TiKVStore {
+				stores := newStores(v1alpha1.TiKVStateUp, v1alpha1.TiKVStateUp, v1alpha1.TiKVStateTombstone, v1alpha1.TiKVStateUp)
+				stores["5"] = v1alpha1.TiKVStore{ID: "5", PodName: "test-tikv-2", State: v1alpha1.TiKVStateUp}
+				return stores
+			}(),
+			failureStores: migrating("3", "test-tikv-2"),
+			errExpectFn:   noErr,
+		},
+	}
+
+	for i := range tests {
+		testFn(&tests[i])
+	}
+}
+
+func TestStorageClassMigratorSyncPD(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	tc := newTidbClusterForPlacementRebalancer()
+	tc.Annotations = map[string]string{label.AnnStorageClassMigration: "true"}
+	tc.Spec.PD.StorageClassName = pointer.StringPtr("new")
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	deletedMember := ""
+	pdClient.AddReaction(pdapi.DeleteMemberActionType, func(action *pdapi.Action) (interface{}, error) {
+		deletedMember = action.Name
+		return nil, nil
+	})
+	transferred := ""
+	pdClient.AddReaction(pdapi.TransferPDLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		transferred = action.Name
+		return nil, nil
+	})
+
+	deps.KubeInformerFactory.Apps().V1().StatefulSets().Informer().GetIndexer().Add(&appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: controller.PDMemberName(tc.Name), Namespace: tc.Namespace},
+		Spec: appsv1.StatefulSetSpec{
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+				ObjectMeta: metav1.ObjectMeta{Name: "pd"},
+				Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: pointer.StringPtr("new")},
+			}},
+		},
+	})
+	for i := int32(0); i < 3; i++ {
+		podName := PdPodName(tc.Name, i)
+		deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Add(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      podName,
+				Namespace: tc.Namespace,
+				Labels:    label.New().Instance(tc.Name).PD().Labels(),
+			},
+		})
+		pvcLabels := label.New().Instance(tc.Name).PD().Labels()
+		pvcLabels[label.AnnPodNameKey] = podName
+		deps.KubeInformerFactory.Core().V1().PersistentVolumeClaims().Informer().GetIndexer().Add(&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pd-" + podName,
+				Namespace: tc.Namespace,
+				Labels:    pvcLabels,
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{StorageClassName: pointer.StringPtr("old")},
+		})
+	}
+
+	// the leader is transferred away before its member is deleted
+	err := NewStorageClassMigrator(deps).Sync(tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(transferred).To(Equal("test-pd-1"))
+	g.Expect(deletedMember).To(BeEmpty())
+
+	tc.Status.PD.Leader = tc.Status.PD.Members["test-pd-1"]
+	err = NewStorageClassMigrator(deps).Sync(tc)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(deletedMember).To(Equal("test-pd-0"))
+	_, err = deps.PodLister.Pods(tc.Namespace).Get("test-pd-0")
+	g.Expect(errors.IsNotFound(err)).To(BeTrue())
+	_, err = deps.PVCLister.PersistentVolumeClaims(tc.Namespace).Get("pd-test-pd-0")
+	g.Expect(errors.IsNotFound(err)).To(BeTrue())
+	_, err = deps.PodLister.Pods(tc.Namespace).Get("test-pd-1")
+	g.Expect(err).NotTo(HaveOccurred())
+}
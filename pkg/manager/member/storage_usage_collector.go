@@ -0,0 +1,243 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+	statsapi "k8s.io/kubernetes/pkg/kubelet/apis/stats/v1alpha1"
+)
+
+// storageUsageCollector reports the disk usage of the components in the status of the TidbCluster.
+//
+// The usage of TiKV and TiFlash is the capacity and the available size of the stores reported by PD,
+// which are recorded in the stores status by the member managers.
+// If `--collect-kubelet-volume-stats` is enabled, the usage of the PVCs is also collected from the
+// kubelet summary API through the node proxy of the API server and recorded in the volumes status,
+// which is used as the usage of PD and Pump.
+//
+// The usage of a component is aggregated into `status.<component>.storageUsage`, including the pod
+// with the highest used percentage.
+type storageUsageCollector struct {
+	deps *controller.Dependencies
+	// getNodeSummary returns the summary stats of the node, it is replaced in unit tests
+	getNodeSummary func(nodeName string) (*statsapi.Summary, error)
+}
+
+// NewStorageUsageCollector returns a storage usage collector
+func NewStorageUsageCollector(deps *controller.Dependencies) manager.Manager {
+	c := &storageUsageCollector{
+		deps: deps,
+	}
+	c.getNodeSummary = c.getNodeSummaryFromKubelet
+	return c
+}
+
+func (c *storageUsageCollector) Sync(tc *v1alpha1.TidbCluster) error {
+	if c.deps.CLIConfig.KubeletVolumeStats {
+		volumeStats, err := c.getVolumeStats(tc)
+		if err != nil {
+			// the usage is informational, so the failure should not block syncing the cluster
+			klog.Warningf("storageUsageCollector: failed to get volume stats of tc %s/%s, error: %v", tc.Namespace, tc.Name, err)
+		} else {
+			setVolumesUsage(tc.Status.PD.Volumes, volumeStats)
+			setVolumesUsage(tc.Status.TiDB.Volumes, volumeStats)
+			setVolumesUsage(tc.Status.TiKV.Volumes, volumeStats)
+			setVolumesUsage(tc.Status.TiFlash.Volumes, volumeStats)
+			setVolumesUsage(tc.Status.TiCDC.Volumes, volumeStats)
+			setVolumesUsage(tc.Status.Pump.Volumes, volumeStats)
+		}
+	}
+
+	if tc.Spec.PD != nil {
+		tc.Status.PD.StorageUsage = newStorageUsage(volumesUsage(tc.Status.PD.Volumes))
+	}
+	if tc.Spec.TiKV != nil {
+		tc.Status.TiKV.StorageUsage = newStorageUsage(storesUsage(tc.Status.TiKV.Stores))
+	}
+	if tc.Spec.TiFlash != nil {
+		tc.Status.TiFlash.StorageUsage = newStorageUsage(storesUsage(tc.Status.TiFlash.Stores))
+	}
+	if tc.Spec.Pump != nil {
+		tc.Status.Pump.StorageUsage = newStorageUsage(volumesUsage(tc.Status.Pump.Volumes))
+	}
+	return nil
+}
+
+// getVolumeStats returns the stats of the PVCs used by the pods of the TidbCluster, keyed by the PVC name
+func (c *storageUsageCollector) getVolumeStats(tc *v1alpha1.TidbCluster) (map[string]statsapi.VolumeStats, error) {
+	selector, err := label.New().Instance(tc.Name).Selector()
+	if err != nil {
+		return nil, err
+	}
+	pods, err := c.deps.PodLister.Pods(tc.Namespace).List(selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods of tc %s/%s, selector: %s, error: %v", tc.Namespace, tc.Name, selector, err)
+	}
+	podNames := sets.NewString()
+	nodeNames := sets.NewString()
+	for _, pod := range pods {
+		podNames.Insert(pod.Name)
+		if pod.Spec.NodeName != "" {
+			nodeNames.Insert(pod.Spec.NodeName)
+		}
+	}
+
+	volumeStats := map[string]statsapi.VolumeStats{}
+	for _, nodeName := range nodeNames.List() {
+		summary, err := c.getNodeSummary(nodeName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get summary stats of node %s, error: %v", nodeName, err)
+		}
+		for _, podStats := range summary.Pods {
+			if podStats.PodRef.Namespace != tc.Namespace || !podNames.Has(podStats.PodRef.Name) {
+				continue
+			}
+			for _, stats := range podStats.VolumeStats {
+				if stats.PVCRef == nil {
+					continue
+				}
+				volumeStats[stats.PVCRef.Name] = stats
+			}
+		}
+	}
+	return volumeStats, nil
+}
+
+func (c *storageUsageCollector) getNodeSummaryFromKubelet(nodeName string) (*statsapi.Summary, error) {
+	data, err := c.deps.KubeClientset.CoreV1().RESTClient().Get().
+		Resource("nodes").Name(nodeName).SubResource("proxy").Suffix("stats/summary").DoRaw()
+	if err != nil {
+		return nil, err
+	}
+	summary := &statsapi.Summary{}
+	if err := json.Unmarshal(data, summary); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// setVolumesUsage records the used and available size in the volumes status
+func setVolumesUsage(volumes map[string]v1alpha1.StorageVolumeStatus, volumeStats map[string]statsapi.VolumeStats) {
+	for pvcName, status := range volumes {
+		stats, ok := volumeStats[pvcName]
+		if !ok || stats.UsedBytes == nil || stats.AvailableBytes == nil {
+			continue
+		}
+		status.UsedCapacity = resource.NewQuantity(int64(*stats.UsedBytes), resource.BinarySI)
+		status.AvailableCapacity = resource.NewQuantity(int64(*stats.AvailableBytes), resource.BinarySI)
+		volumes[pvcName] = status
+	}
+}
+
+// podStorageUsage is the total capacity and used size of the stores or volumes of a pod
+type podStorageUsage struct {
+	capacity int64
+	used     int64
+}
+
+func (u *podStorageUsage) ratio() float64 {
+	return float64(u.used) / float64(u.capacity)
+}
+
+func storesUsage(stores map[string]v1alpha1.TiKVStore) map[string]*podStorageUsage {
+	usages := map[string]*podStorageUsage{}
+	for _, store := range stores {
+		capacity := store.Capacity.Value()
+		if capacity <= 0 {
+			continue
+		}
+		addPodStorageUsage(usages, store.PodName, capacity, capacity-store.Available.Value())
+	}
+	return usages
+}
+
+func volumesUsage(volumes map[string]v1alpha1.StorageVolumeStatus) map[string]*podStorageUsage {
+	usages := map[string]*podStorageUsage{}
+	for _, status := range volumes {
+		if status.UsedCapacity == nil || status.AvailableCapacity == nil {
+			continue
+		}
+		used := status.UsedCapacity.Value()
+		addPodStorageUsage(usages, status.PodName, used+status.AvailableCapacity.Value(), used)
+	}
+	return usages
+}
+
+func addPodStorageUsage(usages map[string]*podStorageUsage, podName string, capacity, used int64) {
+	usage, ok := usages[podName]
+	if !ok {
+		usage = &podStorageUsage{}
+		usages[podName] = usage
+	}
+	usage.capacity += capacity
+	usage.used += used
+}
+
+// newStorageUsage aggregates the usage of the pods, it returns nil if there is no usage reported
+func newStorageUsage(usages map[string]*podStorageUsage) *v1alpha1.StorageUsage {
+	podNames := make([]string, 0, len(usages))
+	for podName, usage := range usages {
+		if usage.capacity > 0 {
+			podNames = append(podNames, podName)
+		}
+	}
+	if len(podNames) == 0 {
+		return nil
+	}
+	sort.Strings(podNames)
+
+	var capacity, used int64
+	worstPodName := ""
+	for _, podName := range podNames {
+		usage := usages[podName]
+		capacity += usage.capacity
+		used += usage.used
+		if worst := usages[worstPodName]; worst == nil || usage.ratio() > worst.ratio() {
+			worstPodName = podName
+		}
+	}
+	worst := usages[worstPodName]
+	return &v1alpha1.StorageUsage{
+		Capacity:            *resource.NewQuantity(capacity, resource.BinarySI),
+		Used:                *resource.NewQuantity(used, resource.BinarySI),
+		UsedPercent:         usedPercent(used, capacity),
+		WorstPodName:        worstPodName,
+		WorstPodUsedPercent: usedPercent(worst.used, worst.capacity),
+	}
+}
+
+func usedPercent(used, capacity int64) string {
+	return fmt.Sprintf("%d%%", int64(float64(used)*100/float64(capacity)))
+}
+
+type fakeStorageUsageCollector struct{}
+
+// NewFakeStorageUsageCollector returns a fake storage usage collector
+func NewFakeStorageUsageCollector() manager.Manager {
+	return &fakeStorageUsageCollector{}
+}
+
+func (c *fakeStorageUsageCollector) Sync(_ *v1alpha1.TidbCluster) error {
+	return nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	statsapi "k8s.io/kubernetes/pkg/kubelet/apis/stats/v1alpha1"
+)
+
+func TestNewStorageUsage(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(newStorageUsage(map[string]*podStorageUsage{})).To(BeNil())
+	g.Expect(newStorageUsage(map[string]*podStorageUsage{"pod-0": {}})).To(BeNil())
+
+	usage := newStorageUsage(map[string]*podStorageUsage{
+		"pod-0": {capacity: 100, used: 20},
+		"pod-1": {capacity: 100, used: 70},
+		"pod-2": {capacity: 200, used: 30},
+	})
+	g.Expect(usage.Capacity.Value()).To(Equal(int64(400)))
+	g.Expect(usage.Used.Value()).To(Equal(int64(120)))
+	g.Expect(usage.UsedPercent).To(Equal("30%"))
+	g.Expect(usage.WorstPodName).To(Equal("pod-1"))
+	g.Expect(usage.WorstPodUsedPercent).To(Equal("70%"))
+}
+
+func TestStorageUsageCollectorSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type testcase struct {
+		name               string
+		kubeletVolumeStats bool
+		summaryErr         error
+		expectPDUsage      *v1alpha1.StorageUsage
+		expectTiKVUsage    *v1alpha1.StorageUsage
+	}
+
+	testFn := func(test *testcase) {
+		t.Log(test.name)
+
+		deps := controller.NewFakeDependencies()
+		deps.CLIConfig.KubeletVolumeStats = test.kubeletVolumeStats
+		tc := newTidbClusterForPlacementRebalancer()
+		tc.Spec.TiKV = &v1alpha1.TiKVSpec{}
+		tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+			"1": {ID: "1", PodName: "test-tikv-0", Capacity: resource.MustParse("100Gi"), Available: resource.MustParse("60Gi")},
+			"2": {ID: "2", PodName: "test-tikv-1", Capacity: resource.MustParse("100Gi"), Available: resource.MustParse("20Gi")},
+			"3": {ID: "3", PodName: "test-tikv-2"},
+		}
+		tc.Status.PD.Volumes = map[string]v1alpha1.StorageVolumeStatus{}
+		for i := 0; i < 2; i++ {
+			podName := ordinalPodName(v1alpha1.PDMemberType, tc.Name, int32(i))
+			deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Add(&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      podName,
+					Namespace: tc.Namespace,
+					Labels:    label.New().Instance(tc.Name).PD().Labels(),
+				},
+				Spec: corev1.PodSpec{NodeName: fmt.Sprintf("node-%d", i)},
+			})
+			tc.Status.PD.Volumes["pd-"+podName] = v1alpha1.StorageVolumeStatus{PodName: podName}
+		}
+
+		c := NewStorageUsageCollector(deps).(*storageUsageCollector)
+		c.getNodeSummary = func(nodeName string) (*statsapi.Summary, error) {
+			if test.summaryErr != nil {
+				return nil, test.summaryErr
+			}
+			podName, used, available := "test-pd-0", uint64(1024), uint64(3072)
+			if nodeName == "node-1" {
+				podName, used, available = "test-pd-1", 3072, 1024
+			}
+			return &statsapi.Summary{
+				Pods: []statsapi.PodStats{
+					{
+						PodRef: statsapi.PodReference{Name: podName, Namespace: tc.Namespace},
+						VolumeStats: []statsapi.VolumeStats{
+							{
+								Name:    "pd",
+								PVCRef:  &statsapi.PVCReference{Name: "pd-" + podName, Namespace: tc.Namespace},
+								FsStats: statsapi.FsStats{UsedBytes: &used, AvailableBytes: &available},
+							},
+							{Name: "config", FsStats: statsapi.FsStats{UsedBytes: &used, AvailableBytes: &available}},
+						},
+					},
+					{
+						PodRef: statsapi.PodReference{Name: podName, Namespace: "other"},
+						VolumeStats: []statsapi.VolumeStats{
+							{
+								Name:    "pd",
+								PVCRef:  &statsapi.PVCReference{Name: "pd-" + podName, Namespace: "other"},
+								FsStats: statsapi.FsStats{UsedBytes: &available, AvailableBytes: &used},
+							},
+						},
+					},
+				},
+			}, nil
+		}
+
+		err := c.Sync(tc)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(tc.Status.PD.StorageUsage).To(Equal(test.expectPDUsage))
+		g.Expect(tc.Status.TiKV.StorageUsage).To(Equal(test.expectTiKVUsage))
+		if test.expectPDUsage != nil {
+			g.Expect(tc.Status.PD.Volumes["pd-test-pd-1"].UsedCapacity.Value()).To(Equal(int64(3072)))
+			g.Expect(tc.Status.PD.Volumes["pd-test-pd-1"].AvailableCapacity.Value()).To(Equal(int64(1024)))
+		}
+	}
+
+	tikvUsage := &v1alpha1.StorageUsage{
+		Capacity:            *resource.NewQuantity(200<<30, resource.BinarySI),
+		Used:                *resource.NewQuantity(120<<30, resource.BinarySI),
+		UsedPercent:         "60%",
+		WorstPodName:        "test-tikv-1",
+		WorstPodUsedPercent: "80%",
+	}
+	tests := []testcase{
+		{
+			name:            "kubelet volume stats is disabled",
+			expectTiKVUsage: tikvUsage,
+		},
+		{
+			name:               "failed to get the summary of the nodes",
+			kubeletVolumeStats: true,
+			summaryErr:         fmt.Errorf("proxy error"),
+			expectTiKVUsage:    tikvUsage,
+		},
+		{
+			name:               "collect the volume stats from the kubelet",
+			kubeletVolumeStats: true,
+			expectPDUsage: &v1alpha1.StorageUsage{
+				Capacity:            *resource.NewQuantity(8192, resource.BinarySI),
+				Used:                *resource.NewQuantity(4096, resource.BinarySI),
+				UsedPercent:         "50%",
+				WorstPodName:        "test-pd-1",
+				WorstPodUsedPercent: "75%",
+			},
+			expectTiKVUsage: tikvUsage,
+		},
+	}
+
+	for i := range tests {
+		testFn(&tests[i])
+	}
+}
@@ -49,7 +49,7 @@ then
 fi
 
 # Use HOSTNAME if POD_NAME is unset for backward compatibility.
-POD_NAME=${POD_NAME:-$HOSTNAME}{{ if .FormatClusterDomain }}
+POD_NAME=${POD_NAME:-$HOSTNAME}{{ if .VerifyPDEndpoints }}
 pd_url="{{ .Path }}"
 encoded_domain_url=$(echo $pd_url | base64 | tr "\n" " " | sed "s/ //g")
 discovery_url="${CLUSTER_NAME}-discovery.${NAMESPACE}.svc{{ .FormatClusterDomain }}:10261"
@@ -96,6 +96,8 @@ type TidbStartScriptModel struct {
 	PluginList      string
 	ClusterDomain   string
 	Path            string
+	// ExternalPD is true if the PD cluster is out of Kubernetes, the PD endpoints are not verified by discovery
+	ExternalPD bool
 }
 
 func (t *TidbStartScriptModel) FormatClusterDomain() string {
@@ -105,6 +107,11 @@ func (t *TidbStartScriptModel) FormatClusterDomain() string {
 	return ""
 }
 
+// VerifyPDEndpoints returns whether the PD endpoints are verified by discovery before starting
+func (t *TidbStartScriptModel) VerifyPDEndpoints() bool {
+	return len(t.ClusterDomain) > 0 && !t.ExternalPD
+}
+
 func RenderTiDBStartScript(model *TidbStartScriptModel) (string, error) {
 	return renderTemplateFunc(tidbStartScriptTpl, model)
 }
@@ -249,7 +256,7 @@ then
 fi
 
 # Use HOSTNAME if POD_NAME is unset for backward compatibility.
-POD_NAME=${POD_NAME:-$HOSTNAME}{{ if .FormatClusterDomain }}
+POD_NAME=${POD_NAME:-$HOSTNAME}{{ if .VerifyPDEndpoints }}
 pd_url="{{ .PDAddress }}"
 encoded_domain_url=$(echo $pd_url | base64 | tr "\n" " " | sed "s/ //g")
 discovery_url="${CLUSTER_NAME}-discovery.${NAMESPACE}.svc{{ .FormatClusterDomain }}:10261"
@@ -287,6 +294,8 @@ type TiKVStartScriptModel struct {
 	DataDir                   string
 	ClusterDomain             string
 	PDAddress                 string
+	// ExternalPD is true if the PD cluster is out of Kubernetes, the PD endpoints are not verified by discovery
+	ExternalPD bool
 }
 
 func (t *TiKVStartScriptModel) FormatClusterDomain() string {
@@ -296,6 +305,11 @@ func (t *TiKVStartScriptModel) FormatClusterDomain() string {
 	return ""
 }
 
+// VerifyPDEndpoints returns whether the PD endpoints are verified by discovery before starting
+func (t *TiKVStartScriptModel) VerifyPDEndpoints() bool {
+	return len(t.ClusterDomain) > 0 && !t.ExternalPD
+}
+
 func RenderTiKVStartScript(model *TiKVStartScriptModel) (string, error) {
 	return renderTemplateFunc(tikvStartScriptTpl, model)
 }
@@ -363,13 +377,24 @@ var tidbInitStartScriptTpl = template.Must(template.New("tidb-init-start-script"
 host = '{{ .ClusterName }}-tidb'
 permit_host = '{{ .PermitHost }}'
 port = 4000
+{{- if .InitSQLScripts }}
+import json
+scripts = os.environ['INIT_SQL_SCRIPTS'].split(',')
+# the scripts completed in the previous runs are skipped, and so are the passwords set before them
+completed_scripts = [s for s in os.environ.get('COMPLETED_INIT_SQL_SCRIPTS', '').split(',') if s]
+resuming = len(completed_scripts) > 0
+root_password = ''
+if resuming and os.path.exists('/etc/tidb/password/root'):
+    with open('/etc/tidb/password/root', 'r') as f:
+        root_password = f.read()
+{{- end }}
 retry_count = 0
 for i in range(0, 10):
     try:
 {{- if .TLS }}
-        conn = MySQLdb.connect(host=host, port=port, user='root', charset='utf8mb4',connect_timeout=5, ssl={'ca': '{{ .CAPath }}', 'cert': '{{ .CertPath }}', 'key': '{{ .KeyPath }}'})
+        conn = MySQLdb.connect(host=host, port=port, user='root', charset='utf8mb4',connect_timeout=5, ssl={'ca': '{{ .CAPath }}', 'cert': '{{ .CertPath }}', 'key': '{{ .KeyPath }}'}{{ if .InitSQLScripts }}, passwd=root_password{{ end }})
 {{- else }}
-        conn = MySQLdb.connect(host=host, port=port, user='root', connect_timeout=5, charset='utf8mb4')
+        conn = MySQLdb.connect(host=host, port=port, user='root', connect_timeout=5, charset='utf8mb4'{{ if .InitSQLScripts }}, passwd=root_password{{ end }})
 {{- end }}
     except MySQLdb.OperationalError as e:
         print(e)
@@ -383,7 +408,7 @@ if retry_count == 10:
 {{- if .PasswordSet }}
 password_dir = '/etc/tidb/password'
 for file in os.listdir(password_dir):
-    if file.startswith('.'):
+    if file.startswith('.'){{ if .InitSQLScripts }} or resuming{{ end }}:
         continue
     user = file
     with open(os.path.join(password_dir, file), 'r') as f:
@@ -399,6 +424,29 @@ with open('/data/init.sql', 'r') as sql:
         conn.cursor().execute(line)
         conn.commit()
 {{- end }}
+{{- if .InitSQLScripts }}
+# the result is reported to the operator by the termination message
+def report(failed=None, error=''):
+    with open('/dev/termination-log', 'w') as f:
+        json.dump({'completed': completed_scripts, 'failed': failed, 'error': error[:1024]}, f)
+for i, name in enumerate(scripts):
+    if name in completed_scripts:
+        continue
+    print('info: executing script %s' % name)
+    try:
+        with open(os.path.join('/init-sql-scripts', str(i)), 'r') as sql:
+            for line in sql.readlines():
+                if line.strip():
+                    conn.cursor().execute(line)
+        conn.commit()
+    except Exception as e:
+        conn.rollback()
+        print('error: failed to execute script %s: %s' % (name, e))
+        report(name, str(e))
+        sys.exit(1)
+    completed_scripts.append(name)
+report()
+{{- end }}
 if permit_host != '%%':
     conn.cursor().execute("update mysql.user set Host=%s where User='root';", (permit_host,))
 conn.cursor().execute("flush privileges;")
@@ -406,15 +454,73 @@ conn.commit()
 conn.close()
 `))
 
+// postUpgradeHookScriptTpl is the template string of the script of the post-upgrade hook job
+var postUpgradeHookScriptTpl = template.Must(template.New("post-upgrade-hook-script").Parse(`import os, sys, time, MySQLdb
+host = '{{ .ClusterName }}-tidb'
+port = 4000
+user = os.environ['{{ .UserEnv }}']
+password = ''
+if os.path.exists('{{ .PasswordPath }}'):
+    with open('{{ .PasswordPath }}', 'r') as f:
+        password = f.read()
+for i in range(0, 10):
+    try:
+{{- if .TLS }}
+        conn = MySQLdb.connect(host=host, port=port, user=user, passwd=password, charset='utf8mb4', connect_timeout=5, ssl={'ca': '{{ .CAPath }}', 'cert': '{{ .CertPath }}', 'key': '{{ .KeyPath }}'})
+{{- else }}
+        conn = MySQLdb.connect(host=host, port=port, user=user, passwd=password, charset='utf8mb4', connect_timeout=5)
+{{- end }}
+    except MySQLdb.OperationalError as e:
+        print(e)
+        time.sleep(1)
+        continue
+    break
+else:
+    sys.exit(1)
+{{- if .SQLPath }}
+with open('{{ .SQLPath }}', 'r') as sql:
+    statements = sql.readlines()
+{{- else }}
+statements = os.environ['{{ .SQLEnv }}'].splitlines()
+{{- end }}
+for line in statements:
+    if line.strip():
+        print('info: executing %s' % line.strip())
+        conn.cursor().execute(line)
+        conn.commit()
+conn.close()
+`))
+
+// PostUpgradeHookScriptModel is the model of the script of the post-upgrade hook job
+type PostUpgradeHookScriptModel struct {
+	ClusterName  string
+	UserEnv      string
+	PasswordPath string
+	// SQLPath is the path of the SQL mounted from the ConfigMap, the SQL is read from SQLEnv if it is empty
+	SQLPath  string
+	SQLEnv   string
+	TLS      bool
+	CAPath   string
+	CertPath string
+	KeyPath  string
+}
+
+// RenderPostUpgradeHookScript renders the script of the post-upgrade hook job
+func RenderPostUpgradeHookScript(model *PostUpgradeHookScriptModel) (string, error) {
+	return renderTemplateFunc(postUpgradeHookScriptTpl, model)
+}
+
 type TiDBInitStartScriptModel struct {
 	ClusterName string
 	PermitHost  string
 	PasswordSet bool
 	InitSQL     bool
-	TLS         bool
-	CAPath      string
-	CertPath    string
-	KeyPath     string
+	// InitSQLScripts is true if initSqlScripts are set, whose names are passed by the env of the job
+	InitSQLScripts bool
+	TLS            bool
+	CAPath         string
+	CertPath       string
+	KeyPath        string
 }
 
 func RenderTiDBInitStartScript(model *TiDBInitStartScriptModel) (string, error) {
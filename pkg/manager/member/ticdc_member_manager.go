@@ -162,6 +162,7 @@ func (m *ticdcMemberManager) syncStatefulSet(tc *v1alpha1.TidbCluster) error {
 	if err != nil {
 		return err
 	}
+	syncConfigSyncedCondition(&tc.Status.TiCDC.Conditions, oldSts, cm, controller.TiCDCMemberName(tc.Name))
 
 	newSts, err := getNewTiCDCStatefulSet(tc, cm)
 	if err != nil {
@@ -194,9 +195,13 @@ func (m *ticdcMemberManager) syncStatefulSet(tc *v1alpha1.TidbCluster) error {
 	}
 
 	if !templateEqual(newSts, oldSts) || tc.Status.TiCDC.Phase == v1alpha1.UpgradePhase {
-		if err := m.ticdcUpgrader.Upgrade(tc, oldSts, newSts); err != nil {
+		err := m.ticdcUpgrader.Upgrade(tc, oldSts, newSts)
+		syncUpgradeProgress(&tc.Status.TiCDC.UpgradeProgress, tc.Status.TiCDC.Phase, tc.Status.TiCDC.Conditions, newSts, &oldSts.Status, tc.TiCDCImage())
+		if err != nil {
 			return err
 		}
+	} else {
+		tc.Status.TiCDC.UpgradeProgress = nil
 	}
 
 	return UpdateStatefulSet(m.deps.StatefulSetControl, tc, newSts, oldSts)
@@ -275,8 +280,7 @@ func (m *ticdcMemberManager) syncCDCHeadlessService(tc *v1alpha1.TidbCluster) er
 		if err != nil {
 			return err
 		}
-		_, err = m.deps.ServiceControl.UpdateService(tc, &svc)
-		return err
+		return updateService(m.deps.ServiceControl, tc, newSvc, &svc)
 	}
 
 	return nil
@@ -323,6 +327,7 @@ func getNewTiCDCStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*ap
 	stsName := controller.TiCDCMemberName(tcName)
 	podLabels := util.CombineStringMap(stsLabels, baseTiCDCSpec.Labels())
 	podAnnotations := util.CombineStringMap(controller.AnnProm(8301), baseTiCDCSpec.Annotations())
+	podAnnotations = util.CombineStringMap(podAnnotations, tlsCertAnnotations(tc, v1alpha1.TiCDCMemberType))
 	stsAnnotations := getStsAnnotations(tc.Annotations, label.TiCDCLabelVal)
 	headlessSvcName := controller.TiCDCPeerMemberName(tcName)
 
@@ -476,6 +481,7 @@ done
 	if podSpec.ServiceAccountName == "" {
 		podSpec.ServiceAccountName = tc.Spec.ServiceAccount
 	}
+	podAnnotations = applySecurityProfile(baseTiCDCSpec, &podSpec, podAnnotations, "")
 
 	for _, tlsClientSecretName := range tc.Spec.TiCDC.TLSClientSecretNames {
 		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
@@ -533,6 +539,7 @@ done
 		},
 	}
 	ticdcSts.Spec.VolumeClaimTemplates = append(ticdcSts.Spec.VolumeClaimTemplates, additionalPVCs...)
+	setVolumeClaimTemplatesAnnotations(ticdcSts, baseTiCDCSpec.PVCAnnotations())
 	return ticdcSts, nil
 }
 
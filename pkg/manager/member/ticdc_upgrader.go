@@ -19,6 +19,7 @@ import (
 	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
 	apps "k8s.io/api/apps/v1"
 	"k8s.io/klog"
 )
@@ -45,6 +46,7 @@ func (u *ticdcUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulS
 	tcName := tc.GetName()
 
 	tc.Status.TiCDC.Phase = v1alpha1.UpgradePhase
+	setUpgradingCondition(&tc.Status.TiCDC.Conditions, utiltidbcluster.RollingUpdate, "")
 	if !templateEqual(newSet, oldSet) {
 		return nil
 	}
@@ -79,10 +81,12 @@ func (u *ticdcUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulS
 
 		if revision == tc.Status.TiCDC.StatefulSet.UpdateRevision {
 			if _, exist := tc.Status.TiCDC.Captures[podName]; !exist {
+				setUpgradingCondition(&tc.Status.TiCDC.Conditions, utiltidbcluster.WaitingForPodReady, fmt.Sprintf("capture of upgraded pod %s is not found", podName))
 				return controller.RequeueErrorf("tidbcluster: [%s/%s]'s ticdc upgraded pod: [%s] is not ready", ns, tcName, podName)
 			}
 			continue
 		}
+		recordPodUpgrade(u.deps, tc, v1alpha1.TiCDCMemberType, newSet, tc.Status.TiCDC.StatefulSet, i)
 		setUpgradePartition(newSet, i)
 		return nil
 	}
@@ -0,0 +1,194 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog"
+)
+
+// TiDBClientTLSPropagator copies the TiDB client TLS secrets into the namespaces of the applications
+type TiDBClientTLSPropagator interface {
+	Sync(ns *corev1.Namespace) error
+}
+
+// tidbClientTLSPropagator copies the TiDB client TLS secrets of the TidbClusters listed in the annotation
+// `tidb.pingcap.com/tidb-client-tls-from` of a namespace into the namespace, so the applications in it can
+// connect to TiDB with TLS without distributing the secrets manually. The copies are refreshed once the
+// secrets are rotated, and deleted once the TidbClusters are removed from the annotation.
+//
+// The copy has the same name as the secret of the TidbCluster, a secret which is not copied by the operator
+// is never overwritten.
+type tidbClientTLSPropagator struct {
+	deps *controller.Dependencies
+}
+
+// NewTiDBClientTLSPropagator returns a propagator of the TiDB client TLS secrets
+func NewTiDBClientTLSPropagator(deps *controller.Dependencies) TiDBClientTLSPropagator {
+	return &tidbClientTLSPropagator{
+		deps: deps,
+	}
+}
+
+// ParseTiDBClientTLSSources parses the <namespace>/<tidbcluster> list in the annotation of the namespace,
+// the invalid items are returned separately
+func ParseTiDBClientTLSSources(ns *corev1.Namespace) (sources []string, invalid []string) {
+	for _, source := range strings.Split(ns.Annotations[label.AnnTiDBClientTLSFrom], ",") {
+		source = strings.TrimSpace(source)
+		if source == "" {
+			continue
+		}
+		parts := strings.Split(source, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			invalid = append(invalid, source)
+			continue
+		}
+		sources = append(sources, source)
+	}
+	return sources, invalid
+}
+
+func (p *tidbClientTLSPropagator) Sync(ns *corev1.Namespace) error {
+	// the copies in the namespace keyed by the names
+	wanted := map[string]*corev1.Secret{}
+	var errs []error
+	sources, invalid := ParseTiDBClientTLSSources(ns)
+	if len(invalid) > 0 {
+		klog.Warningf("tidbClientTLSPropagator: invalid TidbClusters %q in annotation %s of namespace %s, they should be <namespace>/<name>", invalid, label.AnnTiDBClientTLSFrom, ns.Name)
+	}
+	for _, source := range sources {
+		secret, err := p.getSource(source)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if secret == nil || secret.Namespace == ns.Name {
+			continue
+		}
+		if exist, ok := wanted[secret.Name]; ok {
+			errs = append(errs, fmt.Errorf("tidbClientTLSPropagator: secrets %s and %s can not be copied into namespace %s with the same name",
+				exist.Annotations[label.AnnTiDBClientTLSSource], source, ns.Name))
+			continue
+		}
+		wanted[secret.Name] = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      secret.Name,
+				Namespace: ns.Name,
+				Labels:    label.NewOperatorManaged().Component(label.TiDBClientTLSLabelVal),
+				Annotations: map[string]string{
+					label.AnnTiDBClientTLSSource: fmt.Sprintf("%s/%s", secret.Namespace, secret.Name),
+				},
+			},
+			Type: secret.Type,
+			Data: secret.Data,
+		}
+	}
+
+	for _, secret := range wanted {
+		if err := p.apply(secret); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// remove the copies of the TidbClusters which are removed from the annotation
+	selector, err := label.NewOperatorManaged().Component(label.TiDBClientTLSLabelVal).Selector()
+	if err != nil {
+		return err
+	}
+	copies, err := p.deps.SecretLister.Secrets(ns.Name).List(selector)
+	if err != nil {
+		return fmt.Errorf("tidbClientTLSPropagator: failed to list secrets in namespace %s, error: %v", ns.Name, err)
+	}
+	for _, secret := range copies {
+		if _, ok := wanted[secret.Name]; ok {
+			continue
+		}
+		err := p.deps.KubeClientset.CoreV1().Secrets(ns.Name).Delete(secret.Name, nil)
+		if err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("tidbClientTLSPropagator: failed to delete secret %s/%s, error: %v", ns.Name, secret.Name, err))
+			continue
+		}
+		klog.Infof("tidbClientTLSPropagator: secret %s/%s copied from %s is deleted", ns.Name, secret.Name, secret.Annotations[label.AnnTiDBClientTLSSource])
+	}
+	return errorutils.NewAggregate(errs)
+}
+
+// getSource returns the TiDB client TLS secret of the TidbCluster, nil is returned if the
+// TidbCluster does not exist or the TLS of the MySQL client is not enabled
+func (p *tidbClientTLSPropagator) getSource(source string) (*corev1.Secret, error) {
+	parts := strings.Split(source, "/")
+	tcNs, tcName := parts[0], parts[1]
+	tc, err := p.deps.TiDBClusterLister.TidbClusters(tcNs).Get(tcName)
+	if errors.IsNotFound(err) {
+		klog.V(4).Infof("tidbClientTLSPropagator: tc %s is not found, skip copying its TiDB client TLS secret", source)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tidbClientTLSPropagator: failed to get tc %s, error: %v", source, err)
+	}
+	if tc.Spec.TiDB == nil || !tc.Spec.TiDB.IsTLSClientEnabled() {
+		return nil, nil
+	}
+	secretName := util.TiDBClientTLSSecretName(tcName)
+	secret, err := p.deps.SecretLister.Secrets(tcNs).Get(secretName)
+	if errors.IsNotFound(err) {
+		// the secret may be not issued yet
+		klog.V(4).Infof("tidbClientTLSPropagator: secret %s/%s of tc %s is not found", tcNs, secretName, source)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tidbClientTLSPropagator: failed to get secret %s/%s, error: %v", tcNs, secretName, err)
+	}
+	return secret, nil
+}
+
+// apply creates the copy or updates it if the secret is rotated
+func (p *tidbClientTLSPropagator) apply(secret *corev1.Secret) error {
+	ns, name := secret.Namespace, secret.Name
+	source := secret.Annotations[label.AnnTiDBClientTLSSource]
+	existing, err := p.deps.SecretLister.Secrets(ns).Get(name)
+	if errors.IsNotFound(err) {
+		if _, err := p.deps.KubeClientset.CoreV1().Secrets(ns).Create(secret); err != nil {
+			return fmt.Errorf("tidbClientTLSPropagator: failed to create secret %s/%s, error: %v", ns, name, err)
+		}
+		klog.Infof("tidbClientTLSPropagator: secret %s is copied into namespace %s", source, ns)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("tidbClientTLSPropagator: failed to get secret %s/%s, error: %v", ns, name, err)
+	}
+	if existing.Annotations[label.AnnTiDBClientTLSSource] != source {
+		return fmt.Errorf("tidbClientTLSPropagator: secret %s/%s is not copied from %s, it is not overwritten", ns, name, source)
+	}
+	if reflect.DeepEqual(existing.Data, secret.Data) {
+		return nil
+	}
+	updated := existing.DeepCopy()
+	updated.Data = secret.Data
+	if _, err := p.deps.KubeClientset.CoreV1().Secrets(ns).Update(updated); err != nil {
+		return fmt.Errorf("tidbClientTLSPropagator: failed to update secret %s/%s, error: %v", ns, name, err)
+	}
+	klog.Infof("tidbClientTLSPropagator: secret %s/%s is refreshed from %s", ns, name, source)
+	return nil
+}
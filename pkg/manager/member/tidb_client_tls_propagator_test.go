@@ -0,0 +1,117 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTiDBClientTLSPropagator(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiDB()
+	tc.Spec.TiDB.TLSClient = &v1alpha1.TiDBTLSClient{Enabled: true}
+	fakeDeps := controller.NewFakeDependencies()
+	propagator := NewTiDBClientTLSPropagator(fakeDeps)
+	fakeDeps.InformerFactory.Pingcap().V1alpha1().TidbClusters().Informer().GetIndexer().Add(tc)
+	secretIndexer := fakeDeps.KubeInformerFactory.Core().V1().Secrets().Informer().GetIndexer()
+	// syncCache refreshes the secrets in the cache from the clientset
+	syncCache := func(namespaces ...string) {
+		for _, ns := range namespaces {
+			list, err := fakeDeps.KubeClientset.CoreV1().Secrets(ns).List(metav1.ListOptions{})
+			g.Expect(err).NotTo(HaveOccurred())
+			for _, obj := range secretIndexer.List() {
+				if obj.(*corev1.Secret).Namespace == ns {
+					secretIndexer.Delete(obj)
+				}
+			}
+			for i := range list.Items {
+				secretIndexer.Add(&list.Items[i])
+			}
+		}
+	}
+	setSource := func(data string) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: tc.Namespace, Name: util.TiDBClientTLSSecretName(tc.Name)},
+			Type:       corev1.SecretTypeTLS,
+			Data:       map[string][]byte{corev1.TLSCertKey: []byte(data)},
+		}
+		secretClient := fakeDeps.KubeClientset.CoreV1().Secrets(tc.Namespace)
+		if _, err := secretClient.Update(secret); err != nil {
+			_, err = secretClient.Create(secret)
+			g.Expect(err).NotTo(HaveOccurred())
+		}
+		syncCache(tc.Namespace)
+	}
+	getCopy := func(ns string) *corev1.Secret {
+		secret, err := fakeDeps.KubeClientset.CoreV1().Secrets(ns).Get(util.TiDBClientTLSSecretName(tc.Name), metav1.GetOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		return secret
+	}
+
+	app := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:        "app",
+		Annotations: map[string]string{label.AnnTiDBClientTLSFrom: " default/test, invalid,default/absent"},
+	}}
+	sources, invalid := ParseTiDBClientTLSSources(app)
+	g.Expect(sources).To(Equal([]string{"default/test", "default/absent"}))
+	g.Expect(invalid).To(Equal([]string{"invalid"}))
+
+	// the copy is created once the secret is issued
+	g.Expect(propagator.Sync(app)).To(Succeed())
+	syncCache(app.Name)
+	g.Expect(secretIndexer.ListKeys()).To(BeEmpty())
+	setSource("cert-1")
+	g.Expect(propagator.Sync(app)).To(Succeed())
+	syncCache(app.Name)
+	secret := getCopy(app.Name)
+	g.Expect(secret.Type).To(Equal(corev1.SecretTypeTLS))
+	g.Expect(secret.Data).To(HaveKeyWithValue(corev1.TLSCertKey, []byte("cert-1")))
+	g.Expect(secret.Annotations).To(HaveKeyWithValue(label.AnnTiDBClientTLSSource, "default/test-tidb-client-secret"))
+
+	// the copy is refreshed once the secret is rotated
+	setSource("cert-2")
+	g.Expect(propagator.Sync(app)).To(Succeed())
+	syncCache(app.Name)
+	g.Expect(getCopy(app.Name).Data).To(HaveKeyWithValue(corev1.TLSCertKey, []byte("cert-2")))
+
+	// the secret which is not copied by the operator is not overwritten
+	other := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:        "other",
+		Annotations: map[string]string{label.AnnTiDBClientTLSFrom: "default/test"},
+	}}
+	_, err := fakeDeps.KubeClientset.CoreV1().Secrets(other.Name).Create(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: other.Name, Name: util.TiDBClientTLSSecretName(tc.Name)},
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("user")},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	syncCache(other.Name)
+	g.Expect(propagator.Sync(other)).NotTo(Succeed())
+	g.Expect(getCopy(other.Name).Data).To(HaveKeyWithValue(corev1.TLSCertKey, []byte("user")))
+
+	// the copy is deleted once the TidbCluster is removed from the annotation
+	app.Annotations = nil
+	g.Expect(propagator.Sync(app)).To(Succeed())
+	list, err := fakeDeps.KubeClientset.CoreV1().Secrets(app.Name).List(metav1.ListOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(list.Items).To(BeEmpty())
+}
@@ -15,6 +15,7 @@ package member
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
@@ -31,9 +32,9 @@ type tidbFailover struct {
 
 // NewTiDBFailover returns a tidbFailover instance
 func NewTiDBFailover(deps *controller.Dependencies) Failover {
-	return &tidbFailover{
+	return newSimulatedFailover(deps, v1alpha1.TiDBMemberType, &tidbFailover{
 		deps: deps,
-	}
+	})
 }
 
 func (f *tidbFailover) Failover(tc *v1alpha1.TidbCluster) error {
@@ -43,9 +44,15 @@ func (f *tidbFailover) Failover(tc *v1alpha1.TidbCluster) error {
 
 	for _, tidbMember := range tc.Status.TiDB.Members {
 		_, exist := tc.Status.TiDB.FailureMembers[tidbMember.Name]
-		if exist && tidbMember.Health {
+		if exist && tidbMember.Health && tidbMember.SQLProbeFailingSince == nil {
 			delete(tc.Status.TiDB.FailureMembers, tidbMember.Name)
 			klog.Infof("tidb failover: delete %s from tidb failoverMembers", tidbMember.Name)
+			notifyFailover(f.deps, tc, controller.FailoverNotification{
+				Component: v1alpha1.TiDBMemberType.String(),
+				Action:    controller.FailoverRecovered,
+				PodName:   tidbMember.Name,
+				Reason:    "member is healthy again, removing the failover replica",
+			})
 		}
 	}
 
@@ -61,11 +68,22 @@ func (f *tidbFailover) Failover(tc *v1alpha1.TidbCluster) error {
 			continue
 		}
 
-		if tidbMember.Health {
+		var deadline time.Time
+		var msg, reason string
+		if !tidbMember.Health {
+			deadline = tidbMember.LastTransitionTime.Add(f.deps.CLIConfig.TiDBFailoverPeriod)
+			msg = fmt.Sprintf("tidb[%s] is unhealthy", tidbMember.Name)
+			reason = fmt.Sprintf("member is unhealthy for more than %s", f.deps.CLIConfig.TiDBFailoverPeriod)
+		} else if tidbMember.SQLProbeFailingSince != nil && tc.Spec.TiDB.FailoverProbe != nil {
+			// the member is Ready but keeps failing queries
+			failurePeriod := tc.Spec.TiDB.FailoverProbe.GetFailurePeriod(f.deps.CLIConfig.TiDBFailoverPeriod)
+			deadline = tidbMember.SQLProbeFailingSince.Add(failurePeriod)
+			msg = fmt.Sprintf("tidb[%s] fails the failover probe: %s", tidbMember.Name, tidbMember.SQLProbeMessage)
+			reason = fmt.Sprintf("member fails the failover probe for more than %s: %s", failurePeriod, tidbMember.SQLProbeMessage)
+		} else {
 			continue
 		}
 
-		deadline := tidbMember.LastTransitionTime.Add(f.deps.CLIConfig.TiDBFailoverPeriod)
 		if time.Now().After(deadline) {
 			if len(tc.Status.TiDB.FailureMembers) >= int(maxFailoverCount) {
 				klog.Warningf("the failover count reaches the limit (%d), no more failover pods will be created", maxFailoverCount)
@@ -89,8 +107,17 @@ func (f *tidbFailover) Failover(tc *v1alpha1.TidbCluster) error {
 				PodName:   tidbMember.Name,
 				CreatedAt: metav1.Now(),
 			}
-			msg := fmt.Sprintf("tidb[%s] is unhealthy", tidbMember.Name)
-			f.deps.Recorder.Event(tc, corev1.EventTypeWarning, unHealthEventReason, fmt.Sprintf(unHealthEventMsgPattern, "tidb", tidbMember.Name, msg))
+			recordFailoverEvent(f.deps, tc, corev1.EventTypeWarning, unHealthEventReason, unHealthEventMsgPattern, "tidb", tidbMember.Name, msg)
+			failure := controller.FailoverNotification{
+				Component: v1alpha1.TiDBMemberType.String(),
+				PodName:   tidbMember.Name,
+			}
+			failure.Action = controller.FailoverMemberFailed
+			failure.Reason = reason
+			notifyFailover(f.deps, tc, failure)
+			failure.Action = controller.FailoverReplacementCreated
+			failure.Reason = fmt.Sprintf("a replacement replica is added, failover replicas %d/%d", len(tc.Status.TiDB.FailureMembers), maxFailoverCount)
+			notifyFailover(f.deps, tc, failure)
 			break
 		}
 	}
@@ -99,6 +126,19 @@ func (f *tidbFailover) Failover(tc *v1alpha1.TidbCluster) error {
 }
 
 func (f *tidbFailover) Recover(tc *v1alpha1.TidbCluster) {
+	podNames := make([]string, 0, len(tc.Status.TiDB.FailureMembers))
+	for _, failureMember := range tc.Status.TiDB.FailureMembers {
+		podNames = append(podNames, failureMember.PodName)
+	}
+	sort.Strings(podNames)
+	for _, podName := range podNames {
+		notifyFailover(f.deps, tc, controller.FailoverNotification{
+			Component: v1alpha1.TiDBMemberType.String(),
+			Action:    controller.FailoverRecovered,
+			PodName:   podName,
+			Reason:    "member is recovered, removing the failover replica",
+		})
+	}
 	tc.Status.TiDB.FailureMembers = nil
 }
 
@@ -16,6 +16,7 @@ package member
 import (
 	"context"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/types"
@@ -109,6 +110,88 @@ func TestTiDBFailoverFailover(t *testing.T) {
 				t.Expect(int(tc.Spec.TiDB.Replicas)).To(Equal(2))
 			},
 		},
+		{
+			name: "one ready tidb member keeps failing the failover probe",
+			pods: []*corev1.Pod{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: corev1.NamespaceDefault,
+						Name:      "failover-tidb-0",
+					},
+					Status: corev1.PodStatus{
+						Conditions: []corev1.PodCondition{
+							{
+								Type:   corev1.PodScheduled,
+								Status: corev1.ConditionTrue,
+							},
+						},
+					},
+				},
+			},
+			update: func(tc *v1alpha1.TidbCluster) {
+				failingSince := metav1.NewTime(time.Now().Add(-10 * time.Minute))
+				tc.Spec.TiDB.FailoverProbe = &v1alpha1.TiDBFailoverProbe{FailurePeriod: &metav1.Duration{Duration: 5 * time.Minute}}
+				tc.Status.TiDB.Members = map[string]v1alpha1.TiDBMember{
+					"failover-tidb-0": {
+						Name:                 "failover-tidb-0",
+						Health:               true,
+						SQLProbeFailingSince: &failingSince,
+						SQLProbeMessage:      "i/o timeout",
+					},
+					"failover-tidb-1": {
+						Name:   "failover-tidb-1",
+						Health: true,
+					},
+				}
+			},
+			errExpectFn: func(t *GomegaWithT, err error) {
+				t.Expect(err).NotTo(HaveOccurred())
+			},
+			expectFn: func(t *GomegaWithT, tc *v1alpha1.TidbCluster) {
+				t.Expect(len(tc.Status.TiDB.FailureMembers)).To(Equal(1))
+				t.Expect(tc.Status.TiDB.FailureMembers).To(HaveKey("failover-tidb-0"))
+			},
+		},
+		{
+			name: "one ready tidb member fails the failover probe within the failure period",
+			pods: []*corev1.Pod{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: corev1.NamespaceDefault,
+						Name:      "failover-tidb-0",
+					},
+					Status: corev1.PodStatus{
+						Conditions: []corev1.PodCondition{
+							{
+								Type:   corev1.PodScheduled,
+								Status: corev1.ConditionTrue,
+							},
+						},
+					},
+				},
+			},
+			update: func(tc *v1alpha1.TidbCluster) {
+				failingSince := metav1.NewTime(time.Now().Add(-time.Minute))
+				tc.Spec.TiDB.FailoverProbe = &v1alpha1.TiDBFailoverProbe{FailurePeriod: &metav1.Duration{Duration: 5 * time.Minute}}
+				tc.Status.TiDB.Members = map[string]v1alpha1.TiDBMember{
+					"failover-tidb-0": {
+						Name:                 "failover-tidb-0",
+						Health:               true,
+						SQLProbeFailingSince: &failingSince,
+					},
+					"failover-tidb-1": {
+						Name:   "failover-tidb-1",
+						Health: true,
+					},
+				}
+			},
+			errExpectFn: func(t *GomegaWithT, err error) {
+				t.Expect(err).NotTo(HaveOccurred())
+			},
+			expectFn: func(t *GomegaWithT, tc *v1alpha1.TidbCluster) {
+				t.Expect(len(tc.Status.TiDB.FailureMembers)).To(Equal(0))
+			},
+		},
 		{
 			name: "one tidb member failed but not scheduled yet",
 			pods: []*corev1.Pod{
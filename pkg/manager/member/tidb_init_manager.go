@@ -14,10 +14,17 @@
 package member
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"path"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/backup/externalsecret"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/label"
 	"github.com/pingcap/tidb-operator/pkg/util"
@@ -26,7 +33,10 @@ import (
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog"
 	"k8s.io/utils/pointer"
@@ -46,6 +56,16 @@ const (
 	startScriptDir      = "/usr/local/bin"
 	startKey            = "start-script"
 	initStartKey        = "init-start-script"
+	sqlScriptsKey       = "init-sql-scripts"
+	sqlScriptsDir       = "/init-sql-scripts"
+
+	// sqlScriptsEnv is the env of the names of initSqlScripts in order, and completedSQLScriptsEnv
+	// is the env of the names of the scripts completed in the previous runs, which are skipped
+	sqlScriptsEnv          = "INIT_SQL_SCRIPTS"
+	completedSQLScriptsEnv = "COMPLETED_INIT_SQL_SCRIPTS"
+
+	// fetchSecretContainerName is the name of the init container which fetches the passwords from passwordExternalSecret
+	fetchSecretContainerName = "fetch-secret"
 )
 
 // InitManager implements the logic for syncing TidbInitializer.
@@ -74,6 +94,23 @@ func (m *tidbInitManager) Sync(ti *v1alpha1.TidbInitializer) error {
 		klog.Infof("TidbInitManager.Sync: Spec.TiDB is nil in tidbcluster %s, skip syncing TidbInitializer %s/%s", tcName, ns, ti.Name)
 		return nil
 	}
+	if ti.Spec.PasswordExternalSecret != nil {
+		var secretName string
+		if ti.Spec.PasswordSecret != nil {
+			secretName = *ti.Spec.PasswordSecret
+		}
+		if err := backuputil.ValidateExternalSecret(ns, ti.Name, "password", secretName, m.passwordExternalSecret(ti)); err != nil {
+			return fmt.Errorf("TidbInitManager.Sync: invalid passwordExternalSecret in TidbInitializer %s/%s, error: %v", ns, ti.Name, err)
+		}
+	}
+	if err := validateInitSqlScripts(ti); err != nil {
+		return fmt.Errorf("TidbInitManager.Sync: invalid initSqlScripts in TidbInitializer %s/%s, error: %v", ns, ti.Name, err)
+	}
+	switch ti.Spec.OnFailure {
+	case "", v1alpha1.InitializerFailurePolicyRetry, v1alpha1.InitializerFailurePolicyAbort, v1alpha1.InitializerFailurePolicyIgnore:
+	default:
+		return fmt.Errorf("TidbInitManager.Sync: invalid onFailure %q in TidbInitializer %s/%s", ti.Spec.OnFailure, ns, ti.Name)
+	}
 
 	err = m.syncTiDBInitConfigMap(ti)
 	if err != nil {
@@ -95,33 +132,183 @@ func (m *tidbInitManager) updateStatus(ti *v1alpha1.TidbInitializer) error {
 	}
 
 	phase := v1alpha1.InitializePhaseRunning
+	var failed *batchv1.JobCondition
 	if len(job.Status.Conditions) > 0 {
-		for _, c := range job.Status.Conditions {
+		for i, c := range job.Status.Conditions {
 			if c.Type == batchv1.JobComplete && c.Status == corev1.ConditionTrue {
 				phase = v1alpha1.InitializePhaseCompleted
 				break
 			}
 			if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
 				phase = v1alpha1.InitializePhaseFailed
+				failed = &job.Status.Conditions[i]
 				break
 			}
 		}
 	}
 
-	var update bool
-	if !apiequality.Semantic.DeepEqual(ti.Status.JobStatus, job.Status) {
-		job.Status.DeepCopyInto(&ti.Status.JobStatus)
-		update = true
+	oldStatus := ti.Status.DeepCopy()
+	job.Status.DeepCopyInto(&ti.Status.JobStatus)
+	ti.Status.Scripts = m.initSqlScriptsStatus(ti, job, phase)
+	updateInitializerAttempts(ti, job, phase, failed)
+	ti.Status.Phase = phase
+	ti.Status.Message = ""
+	if phase != v1alpha1.InitializePhaseFailed {
+		ti.Status.NextRetryTime = nil
 	}
-	if ti.Status.Phase != phase {
-		ti.Status.Phase = phase
-		update = true
+	retry := false
+	if phase == v1alpha1.InitializePhaseFailed {
+		retry = handleInitializerFailure(ti, time.Now())
 	}
-	if update {
-		_, err = m.updateInitializer(ti)
-		return err
+	if !apiequality.Semantic.DeepEqual(&ti.Status, oldStatus) {
+		if _, err := m.updateInitializer(ti); err != nil {
+			return err
+		}
 	}
-	return nil
+	if !retry {
+		return nil
+	}
+
+	// the job is recreated after the backoff, and the scripts completed are skipped
+	if job.DeletionTimestamp == nil {
+		if err := m.deps.JobControl.DeleteJob(ti, job); err != nil {
+			return fmt.Errorf("updateStatus: failed to delete the failed job %s for TidbInitializer %s/%s, error: %v", name, ns, ti.Name, err)
+		}
+	}
+	return controller.RequeueErrorf("TidbInitializer %s/%s: job %s failed, retry at %s", ns, ti.Name, name, ti.Status.NextRetryTime.Format(time.RFC3339))
+}
+
+// updateInitializerAttempts records the job as the latest attempt, the attempts are identified by the creation time of the jobs
+func updateInitializerAttempts(ti *v1alpha1.TidbInitializer, job *batchv1.Job, phase v1alpha1.InitializePhase, failed *batchv1.JobCondition) {
+	attempts := ti.Status.Attempts
+	if len(attempts) == 0 || !attempts[len(attempts)-1].StartTime.Equal(&job.CreationTimestamp) {
+		attempts = append(attempts, v1alpha1.InitializerAttempt{StartTime: job.CreationTimestamp})
+	}
+	attempt := &attempts[len(attempts)-1]
+	attempt.Phase = phase
+	switch phase {
+	case v1alpha1.InitializePhaseCompleted:
+		attempt.CompletionTime = job.Status.CompletionTime
+	case v1alpha1.InitializePhaseFailed:
+		attempt.CompletionTime = &failed.LastTransitionTime
+		attempt.Reason = failed.Reason
+		attempt.Message = failed.Message
+	}
+	ti.Status.Attempts = attempts
+}
+
+// handleInitializerFailure applies onFailure to the failed initialization, it returns true if the job should be retried
+func handleInitializerFailure(ti *v1alpha1.TidbInitializer, now time.Time) bool {
+	attempt := ti.Status.Attempts[len(ti.Status.Attempts)-1]
+	reason := fmt.Sprintf("job failed: %s", attempt.Reason)
+	if attempt.Message != "" {
+		reason = fmt.Sprintf("%s, %s", reason, attempt.Message)
+	}
+	for _, script := range ti.Status.Scripts {
+		if script.Phase == v1alpha1.InitializePhaseFailed {
+			reason = fmt.Sprintf("script %s failed: %s", script.Name, script.Message)
+		}
+	}
+
+	switch ti.GetOnFailure() {
+	case v1alpha1.InitializerFailurePolicyRetry:
+		retries := int32(len(ti.Status.Attempts))
+		if retries > ti.GetMaxRetries() {
+			ti.Status.NextRetryTime = nil
+			ti.Status.Message = fmt.Sprintf("the retries are exhausted, the last %s", reason)
+			return false
+		}
+		// the retry time of the previous attempt is stale once a new attempt starts
+		if ti.Status.NextRetryTime == nil || ti.Status.NextRetryTime.Before(&attempt.StartTime) {
+			next := metav1.NewTime(attempt.CompletionTime.Add(ti.GetRetryBackoff(int(retries))))
+			if next.Before(&metav1.Time{Time: now}) {
+				next = metav1.NewTime(now)
+			}
+			ti.Status.NextRetryTime = &next
+		}
+		if deadline, ok := ti.GetDeadline(now); ok && !ti.Status.NextRetryTime.Time.Before(deadline) {
+			ti.Status.NextRetryTime = nil
+			ti.Status.Message = fmt.Sprintf("the initialization times out, the last %s", reason)
+			return false
+		}
+		ti.Status.Phase = v1alpha1.InitializePhaseRunning
+		ti.Status.Message = fmt.Sprintf("retry %d/%d at %s, the last %s", retries, ti.GetMaxRetries(), ti.Status.NextRetryTime.Format(time.RFC3339), reason)
+		return true
+	case v1alpha1.InitializerFailurePolicyIgnore:
+		ti.Status.Phase = v1alpha1.InitializePhaseCompleted
+		ti.Status.Message = fmt.Sprintf("the failure is ignored, %s", reason)
+	default:
+		ti.Status.Message = reason
+	}
+	return false
+}
+
+// initSqlScriptsResult is the result of initSqlScripts reported by the termination message of the job
+type initSqlScriptsResult struct {
+	// Completed are the names of the scripts completed, including the ones completed in the previous runs
+	Completed []string `json:"completed"`
+	// Failed is the name of the script failed
+	Failed string `json:"failed,omitempty"`
+	// Error is the error of the failed script
+	Error string `json:"error,omitempty"`
+}
+
+// initSqlScriptsStatus returns the states of initSqlScripts, the scripts completed by the previous jobs are kept completed
+func (m *tidbInitManager) initSqlScriptsStatus(ti *v1alpha1.TidbInitializer, job *batchv1.Job, phase v1alpha1.InitializePhase) []v1alpha1.InitSqlScriptStatus {
+	if len(ti.Spec.InitSqlScripts) == 0 {
+		return nil
+	}
+	completed := sets.NewString(completedInitSqlScripts(ti)...)
+	var result *initSqlScriptsResult
+	if phase == v1alpha1.InitializePhaseCompleted || phase == v1alpha1.InitializePhaseFailed {
+		result = m.getInitSqlScriptsResult(job)
+	}
+	if result != nil {
+		completed.Insert(result.Completed...)
+	}
+
+	scripts := make([]v1alpha1.InitSqlScriptStatus, 0, len(ti.Spec.InitSqlScripts))
+	for _, script := range ti.Spec.InitSqlScripts {
+		status := v1alpha1.InitSqlScriptStatus{Name: script.Name, Phase: v1alpha1.InitializePhasePending}
+		if completed.Has(script.Name) || (phase == v1alpha1.InitializePhaseCompleted && result == nil) {
+			status.Phase = v1alpha1.InitializePhaseCompleted
+		} else if result != nil && result.Failed == script.Name {
+			status.Phase = v1alpha1.InitializePhaseFailed
+			status.Message = result.Error
+		}
+		scripts = append(scripts, status)
+	}
+	return scripts
+}
+
+// getInitSqlScriptsResult returns the result in the termination message of the latest pod of the job,
+// or nil if it is not found
+func (m *tidbInitManager) getInitSqlScriptsResult(job *batchv1.Job) *initSqlScriptsResult {
+	pods, err := m.deps.PodLister.Pods(job.Namespace).List(labels.SelectorFromSet(labels.Set{"job-name": job.Name}))
+	if err != nil {
+		klog.Warningf("failed to list the pods of job %s/%s, error: %v", job.Namespace, job.Name, err)
+		return nil
+	}
+	var result *initSqlScriptsResult
+	var latest metav1.Time
+	for _, pod := range pods {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name != containerName || status.State.Terminated == nil || status.State.Terminated.Message == "" {
+				continue
+			}
+			if result != nil && pod.CreationTimestamp.Before(&latest) {
+				continue
+			}
+			r := &initSqlScriptsResult{}
+			if err := json.Unmarshal([]byte(status.State.Terminated.Message), r); err != nil {
+				klog.Warningf("failed to parse the result of initSqlScripts in pod %s/%s, error: %v", pod.Namespace, pod.Name, err)
+				continue
+			}
+			result = r
+			latest = pod.CreationTimestamp
+		}
+	}
+	return result
 }
 
 func (m *tidbInitManager) updateInitializer(ti *v1alpha1.TidbInitializer) (*v1alpha1.TidbInitializer, error) {
@@ -201,16 +388,22 @@ func (m *tidbInitManager) syncTiDBInitJob(ti *v1alpha1.TidbInitializer) error {
 	name := ti.GetName()
 	jobName := controller.TiDBInitializerMemberName(ti.Spec.Clusters.Name)
 
-	_, err := m.deps.JobLister.Jobs(ns).Get(jobName)
+	job, err := m.deps.JobLister.Jobs(ns).Get(jobName)
 	if err == nil {
+		if job.DeletionTimestamp != nil {
+			return controller.RequeueErrorf("TiDBInitializer %s/%s: waiting for the failed job %s to be deleted", ns, name, jobName)
+		}
 		return nil
 	}
 
 	if !errors.IsNotFound(err) {
 		return fmt.Errorf("TiDBInitializer %s/%s get job %s failed, err: %v", ns, ti.Name, name, err)
 	}
+	if next := ti.Status.NextRetryTime; next != nil && time.Now().Before(next.Time) {
+		return controller.RequeueErrorf("TiDBInitializer %s/%s: waiting to retry at %s", ns, name, next.Format(time.RFC3339))
+	}
 
-	job, err := m.makeTiDBInitJob(ti)
+	job, err = m.makeTiDBInitJob(ti)
 	if err != nil {
 		return err
 	}
@@ -313,6 +506,19 @@ func (m *tidbInitManager) makeTiDBInitJob(ti *v1alpha1.TidbInitializer) (*batchv
 				},
 			},
 		})
+	} else if ti.Spec.PasswordExternalSecret != nil {
+		// the passwords are fetched into an emptyDir by backup-manager before the job starts
+		vms = append(vms, corev1.VolumeMount{
+			Name: passwdKey, ReadOnly: true, MountPath: passwdPath,
+		})
+		vs = append(vs, corev1.Volume{
+			Name: passwdKey,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{
+					Medium: corev1.StorageMediumMemory,
+				},
+			},
+		})
 	}
 	if ti.Spec.InitSqlConfigMap != nil {
 		vms = append(vms, corev1.VolumeMount{
@@ -346,6 +552,29 @@ func (m *tidbInitManager) makeTiDBInitJob(ti *v1alpha1.TidbInitializer) (*batchv
 		})
 	}
 
+	containerEnvs := envs
+	if len(ti.Spec.InitSqlScripts) > 0 {
+		vms = append(vms, corev1.VolumeMount{
+			Name: sqlScriptsKey, ReadOnly: true, MountPath: sqlScriptsDir,
+		})
+		vs = append(vs, corev1.Volume{
+			Name: sqlScriptsKey,
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{
+					Sources: initSqlScriptsProjections(ti),
+				},
+			},
+		})
+		names := make([]string, 0, len(ti.Spec.InitSqlScripts))
+		for _, script := range ti.Spec.InitSqlScripts {
+			names = append(names, script.Name)
+		}
+		containerEnvs = append([]corev1.EnvVar{
+			{Name: sqlScriptsEnv, Value: strings.Join(names, ",")},
+			{Name: completedSQLScriptsEnv, Value: strings.Join(completedInitSqlScripts(ti), ",")},
+		}, envs...)
+	}
+
 	meta, initLabel := getInitMeta(ti)
 
 	podSpec := &corev1.PodTemplateSpec{
@@ -354,8 +583,9 @@ func (m *tidbInitManager) makeTiDBInitJob(ti *v1alpha1.TidbInitializer) (*batchv
 			Annotations: util.CopyStringMap(ti.ObjectMeta.Annotations),
 		},
 		Spec: corev1.PodSpec{
-			ImagePullSecrets: ti.Spec.ImagePullSecrets,
-			SecurityContext:  ti.Spec.PodSecurityContext,
+			ServiceAccountName: ti.Spec.ServiceAccount,
+			ImagePullSecrets:   ti.Spec.ImagePullSecrets,
+			SecurityContext:    ti.Spec.PodSecurityContext,
 			InitContainers: []corev1.Container{
 				{
 					Name:    initContainerName,
@@ -378,7 +608,7 @@ func (m *tidbInitManager) makeTiDBInitJob(ti *v1alpha1.TidbInitializer) (*batchv
 					Image:        ti.Spec.Image,
 					Command:      cmds,
 					VolumeMounts: vms,
-					Env:          envs,
+					Env:          containerEnvs,
 				},
 			},
 			RestartPolicy: corev1.RestartPolicyNever,
@@ -394,6 +624,21 @@ func (m *tidbInitManager) makeTiDBInitJob(ti *v1alpha1.TidbInitializer) (*batchv
 		podSpec.Spec.Containers[0].Resources = *ti.Spec.Resources
 		podSpec.Spec.InitContainers[0].Resources = *ti.Spec.Resources
 	}
+	if ti.Spec.PasswordExternalSecret != nil {
+		env, err := externalsecret.EnvVar([]externalsecret.Binding{{Ref: *m.passwordExternalSecret(ti)}})
+		if err != nil {
+			return nil, fmt.Errorf("makeTiDBInitJob: failed to generate the env of passwordExternalSecret for TidbInitializer %s/%s, error: %v", ns, ti.Name, err)
+		}
+		podSpec.Spec.InitContainers = append(podSpec.Spec.InitContainers, corev1.Container{
+			Name:  fetchSecretContainerName,
+			Image: m.deps.CLIConfig.TiDBBackupManagerImage,
+			Args:  []string{"fetch-secret", fmt.Sprintf("--output-dir=%s", passwdPath)},
+			Env:   append([]corev1.EnvVar{env}, envs...),
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: passwdKey, MountPath: passwdPath},
+			},
+		})
+	}
 
 	job := &batchv1.Job{
 		ObjectMeta: meta,
@@ -402,10 +647,44 @@ func (m *tidbInitManager) makeTiDBInitJob(ti *v1alpha1.TidbInitializer) (*batchv
 			Template:     *podSpec,
 		},
 	}
+	if deadline, ok := ti.GetDeadline(time.Now()); ok {
+		// the job is terminated with the reason DeadlineExceeded after the deadline
+		seconds := int64(math.Ceil(time.Until(deadline).Seconds()))
+		if seconds < 1 {
+			seconds = 1
+		}
+		job.Spec.ActiveDeadlineSeconds = pointer.Int64Ptr(seconds)
+	}
 
 	return job, nil
 }
 
+// passwordExternalSecret returns passwordExternalSecret with the defaults configured in the operator
+func (m *tidbInitManager) passwordExternalSecret(ti *v1alpha1.TidbInitializer) *v1alpha1.ExternalSecretRef {
+	ref := ti.Spec.PasswordExternalSecret.DeepCopy()
+	cfg := m.deps.CLIConfig
+	switch ref.Provider {
+	case v1alpha1.ExternalSecretProviderAWS:
+		if ref.Region == "" {
+			ref.Region = cfg.ExternalSecretAWSRegion
+		}
+	case v1alpha1.ExternalSecretProviderVault:
+		if ref.Vault == nil {
+			ref.Vault = &v1alpha1.ExternalSecretVault{}
+		}
+		if ref.Vault.Address == "" {
+			ref.Vault.Address = cfg.ExternalSecretVaultAddress
+		}
+		if ref.Vault.Role == "" {
+			ref.Vault.Role = cfg.ExternalSecretVaultRole
+		}
+		if ref.Vault.MountPath == "" {
+			ref.Vault.MountPath = cfg.ExternalSecretVaultMountPath
+		}
+	}
+	return ref
+}
+
 func getTiDBInitConfigMap(ti *v1alpha1.TidbInitializer, tlsClientEnabled bool) (*corev1.ConfigMap, error) {
 	var initSQL, passwdSet bool
 
@@ -414,7 +693,7 @@ func getTiDBInitConfigMap(ti *v1alpha1.TidbInitializer, tlsClientEnabled bool) (
 	if ti.Spec.InitSql != nil || ti.Spec.InitSqlConfigMap != nil {
 		initSQL = true
 	}
-	if ti.Spec.PasswordSecret != nil {
+	if ti.Spec.PasswordSecret != nil || ti.Spec.PasswordExternalSecret != nil {
 		passwdSet = true
 	}
 
@@ -426,10 +705,11 @@ func getTiDBInitConfigMap(ti *v1alpha1.TidbInitializer, tlsClientEnabled bool) (
 	}
 
 	initModel := &TiDBInitStartScriptModel{
-		ClusterName: ti.Spec.Clusters.Name,
-		PermitHost:  permitHost,
-		InitSQL:     initSQL,
-		PasswordSet: passwdSet,
+		ClusterName:    ti.Spec.Clusters.Name,
+		PermitHost:     permitHost,
+		InitSQL:        initSQL,
+		InitSQLScripts: len(ti.Spec.InitSqlScripts) > 0,
+		PasswordSet:    passwdSet,
 	}
 	if tlsClientEnabled {
 		initModel.TLS = true
@@ -459,6 +739,66 @@ func getTiDBInitConfigMap(ti *v1alpha1.TidbInitializer, tlsClientEnabled bool) (
 	return cm, nil
 }
 
+// initSqlScriptsProjections returns the projections of initSqlScripts, the script of index i is projected to the file named i
+func initSqlScriptsProjections(ti *v1alpha1.TidbInitializer) []corev1.VolumeProjection {
+	var projections []corev1.VolumeProjection
+	for i, script := range ti.Spec.InitSqlScripts {
+		file := strconv.Itoa(i)
+		if script.ConfigMap != nil {
+			projections = append(projections, corev1.VolumeProjection{
+				ConfigMap: &corev1.ConfigMapProjection{
+					LocalObjectReference: script.ConfigMap.LocalObjectReference,
+					Items:                []corev1.KeyToPath{{Key: script.ConfigMap.Key, Path: file}},
+				},
+			})
+		} else if script.Secret != nil {
+			projections = append(projections, corev1.VolumeProjection{
+				Secret: &corev1.SecretProjection{
+					LocalObjectReference: script.Secret.LocalObjectReference,
+					Items:                []corev1.KeyToPath{{Key: script.Secret.Key, Path: file}},
+				},
+			})
+		}
+	}
+	return projections
+}
+
+// completedInitSqlScripts returns the names of the scripts completed in the status
+func completedInitSqlScripts(ti *v1alpha1.TidbInitializer) []string {
+	var names []string
+	for _, script := range ti.Status.Scripts {
+		if script.Phase == v1alpha1.InitializePhaseCompleted {
+			names = append(names, script.Name)
+		}
+	}
+	return names
+}
+
+// validateInitSqlScripts checks that the names of initSqlScripts are valid and unique, and each script
+// has exactly one source
+func validateInitSqlScripts(ti *v1alpha1.TidbInitializer) error {
+	if len(ti.Spec.InitSqlScripts) == 0 {
+		return nil
+	}
+	if ti.Spec.InitSql != nil || ti.Spec.InitSqlConfigMap != nil {
+		return fmt.Errorf("initSqlScripts can not be set with initSql or initSqlConfigMap")
+	}
+	names := sets.NewString()
+	for _, script := range ti.Spec.InitSqlScripts {
+		if errs := validation.IsConfigMapKey(script.Name); len(errs) > 0 {
+			return fmt.Errorf("invalid name %q: %s", script.Name, strings.Join(errs, ", "))
+		}
+		if names.Has(script.Name) {
+			return fmt.Errorf("duplicate name %q", script.Name)
+		}
+		names.Insert(script.Name)
+		if (script.ConfigMap == nil) == (script.Secret == nil) {
+			return fmt.Errorf("exactly one of configMap and secret must be set in script %q", script.Name)
+		}
+	}
+	return nil
+}
+
 func getInitMeta(ti *v1alpha1.TidbInitializer) (metav1.ObjectMeta, label.Label) {
 	name := controller.TiDBInitializerMemberName(ti.Spec.Clusters.Name)
 	initLabel := label.NewInitializer().Instance(ti.Name).Initializer(ti.Name)
@@ -14,17 +14,23 @@
 package member
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/backup/externalsecret"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	apps "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
 )
 
 func TestTiDBInitManagerSync(t *testing.T) {
@@ -128,6 +134,343 @@ func TestTiDBInitManagerSync(t *testing.T) {
 	}
 }
 
+func TestMakeTiDBInitJobWithPasswordExternalSecret(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tim, tmm, _ := newFakeTiDBInitManager()
+	tmm.deps.CLIConfig.TiDBBackupManagerImage = "pingcap/tidb-backup-manager:latest"
+	_, err := tmm.deps.Controls.TiDBClusterControl.UpdateTidbCluster(newTidbClusterForTiDB(), nil, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	ti := newTidbInitializerForTiDB()
+	ti.Spec.PasswordExternalSecret = &v1alpha1.ExternalSecretRef{
+		Provider: v1alpha1.ExternalSecretProviderAWS,
+		Path:     "tidb-users",
+	}
+	job, err := tim.makeTiDBInitJob(ti)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	podSpec := job.Spec.Template.Spec
+	g.Expect(podSpec.InitContainers).To(HaveLen(2))
+	fetch := podSpec.InitContainers[1]
+	g.Expect(fetch.Image).To(Equal("pingcap/tidb-backup-manager:latest"))
+	g.Expect(fetch.Args).To(Equal([]string{"fetch-secret", "--output-dir=" + passwdPath}))
+	g.Expect(fetch.Env[0].Name).To(Equal(externalsecret.EnvVarName))
+	g.Expect(fetch.VolumeMounts).To(Equal([]corev1.VolumeMount{{Name: passwdKey, MountPath: passwdPath}}))
+	g.Expect(podSpec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: passwdKey, ReadOnly: true, MountPath: passwdPath}))
+
+	cm, err := getTiDBInitConfigMap(ti, false)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cm.Data[startKey]).To(ContainSubstring(passwdPath))
+}
+
+func TestPasswordExternalSecretDefaults(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tim, tmm, _ := newFakeTiDBInitManager()
+	_, err := tmm.deps.Controls.TiDBClusterControl.UpdateTidbCluster(newTidbClusterForTiDB(), nil, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	ti := newTidbInitializerForTiDB()
+	ti.Spec.ServiceAccount = "tidb-initializer"
+	ti.Spec.PasswordExternalSecret = &v1alpha1.ExternalSecretRef{
+		Provider: v1alpha1.ExternalSecretProviderVault,
+		Path:     "secret/data/tidb-users",
+	}
+	g.Expect(backuputil.ValidateExternalSecret(ti.Namespace, ti.Name, "password", "", tim.passwordExternalSecret(ti))).To(MatchError(ContainSubstring("vault address")))
+
+	// the access of Vault not set in the spec is configured in the operator
+	tim.deps.CLIConfig.ExternalSecretVaultAddress = "https://vault.vault.svc:8200"
+	tim.deps.CLIConfig.ExternalSecretVaultRole = "tidb-initializer"
+	g.Expect(backuputil.ValidateExternalSecret(ti.Namespace, ti.Name, "password", "", tim.passwordExternalSecret(ti))).To(Succeed())
+	g.Expect(ti.Spec.PasswordExternalSecret.Vault).To(BeNil())
+
+	job, err := tim.makeTiDBInitJob(ti)
+	g.Expect(err).NotTo(HaveOccurred())
+	podSpec := job.Spec.Template.Spec
+	g.Expect(podSpec.ServiceAccountName).To(Equal("tidb-initializer"))
+	var bindings []externalsecret.Binding
+	g.Expect(json.Unmarshal([]byte(podSpec.InitContainers[1].Env[0].Value), &bindings)).To(Succeed())
+	g.Expect(bindings).To(HaveLen(1))
+	g.Expect(bindings[0].Ref.Vault).To(Equal(&v1alpha1.ExternalSecretVault{
+		Address: "https://vault.vault.svc:8200",
+		Role:    "tidb-initializer",
+	}))
+
+	ti.Spec.PasswordExternalSecret.Vault = &v1alpha1.ExternalSecretVault{Address: "https://vault2.vault.svc:8200", MountPath: "k8s"}
+	g.Expect(tim.passwordExternalSecret(ti).Vault).To(Equal(&v1alpha1.ExternalSecretVault{
+		Address:   "https://vault2.vault.svc:8200",
+		Role:      "tidb-initializer",
+		MountPath: "k8s",
+	}))
+
+	ti.Spec.PasswordExternalSecret = &v1alpha1.ExternalSecretRef{Provider: v1alpha1.ExternalSecretProviderAWS}
+	tim.deps.CLIConfig.ExternalSecretAWSRegion = "us-west-2"
+	g.Expect(tim.passwordExternalSecret(ti).Region).To(Equal("us-west-2"))
+	g.Expect(backuputil.ValidateExternalSecret(ti.Namespace, ti.Name, "password", "", tim.passwordExternalSecret(ti))).To(MatchError(ContainSubstring("path")))
+}
+
+func TestMakeTiDBInitJobWithInitSqlScripts(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tim, tmm, _ := newFakeTiDBInitManager()
+	_, err := tmm.deps.Controls.TiDBClusterControl.UpdateTidbCluster(newTidbClusterForTiDB(), nil, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	ti := newTidbInitializerForTiDB()
+	ti.Spec.InitSqlScripts = []v1alpha1.InitSqlScript{
+		{Name: "schema", ConfigMap: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "sql"}, Key: "schema.sql"}},
+		{Name: "users", Secret: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "sql"}, Key: "users.sql"}},
+	}
+	ti.Status.Scripts = []v1alpha1.InitSqlScriptStatus{
+		{Name: "schema", Phase: v1alpha1.InitializePhaseCompleted},
+		{Name: "users", Phase: v1alpha1.InitializePhaseFailed},
+	}
+	g.Expect(validateInitSqlScripts(ti)).To(Succeed())
+	job, err := tim.makeTiDBInitJob(ti)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	podSpec := job.Spec.Template.Spec
+	g.Expect(podSpec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: sqlScriptsKey, ReadOnly: true, MountPath: sqlScriptsDir}))
+	g.Expect(podSpec.Volumes).To(ContainElement(corev1.Volume{
+		Name: sqlScriptsKey,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{
+					{ConfigMap: &corev1.ConfigMapProjection{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "sql"},
+						Items:                []corev1.KeyToPath{{Key: "schema.sql", Path: "0"}},
+					}},
+					{Secret: &corev1.SecretProjection{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "sql"},
+						Items:                []corev1.KeyToPath{{Key: "users.sql", Path: "1"}},
+					}},
+				},
+			},
+		},
+	}))
+	// the completed scripts are skipped when the job is recreated
+	g.Expect(podSpec.Containers[0].Env).To(Equal([]corev1.EnvVar{
+		{Name: sqlScriptsEnv, Value: "schema,users"},
+		{Name: completedSQLScriptsEnv, Value: "schema"},
+	}))
+	g.Expect(podSpec.InitContainers[0].Env).To(BeEmpty())
+
+	cm, err := getTiDBInitConfigMap(ti, false)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cm.Data[startKey]).To(ContainSubstring(sqlScriptsDir))
+	g.Expect(cm.Data[startKey]).To(ContainSubstring("passwd=root_password"))
+	g.Expect(cm.Data[sqlKey]).To(BeEmpty())
+}
+
+func TestTiDBInitManagerInitSqlScriptsStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tim, _, indexers := newFakeTiDBInitManager()
+	ti := newTidbInitializerForTiDB()
+	for _, name := range []string{"schema", "users", "data"} {
+		ti.Spec.InitSqlScripts = append(ti.Spec.InitSqlScripts, v1alpha1.InitSqlScript{
+			Name:      name,
+			ConfigMap: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "sql"}, Key: name},
+		})
+	}
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "test-tidb-initializer", Namespace: corev1.NamespaceDefault}}
+
+	// the scripts are pending until the job terminates
+	g.Expect(tim.initSqlScriptsStatus(ti, job, v1alpha1.InitializePhaseRunning)).To(Equal([]v1alpha1.InitSqlScriptStatus{
+		{Name: "schema", Phase: v1alpha1.InitializePhasePending},
+		{Name: "users", Phase: v1alpha1.InitializePhasePending},
+		{Name: "data", Phase: v1alpha1.InitializePhasePending},
+	}))
+
+	err := indexers.pod.Add(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-tidb-initializer-abcde",
+			Namespace: corev1.NamespaceDefault,
+			Labels:    map[string]string{"job-name": job.Name},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: containerName,
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					ExitCode: 1,
+					Message:  `{"completed": ["schema"], "failed": "users", "error": "(1146, \"Table 'test.t' doesn't exist\")"}`,
+				}},
+			}},
+		},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	ti.Status.Scripts = tim.initSqlScriptsStatus(ti, job, v1alpha1.InitializePhaseFailed)
+	g.Expect(ti.Status.Scripts).To(Equal([]v1alpha1.InitSqlScriptStatus{
+		{Name: "schema", Phase: v1alpha1.InitializePhaseCompleted},
+		{Name: "users", Phase: v1alpha1.InitializePhaseFailed, Message: `(1146, "Table 'test.t' doesn't exist")`},
+		{Name: "data", Phase: v1alpha1.InitializePhasePending},
+	}))
+
+	// the completed scripts are kept completed while the recreated job is running
+	g.Expect(tim.initSqlScriptsStatus(ti, job, v1alpha1.InitializePhaseRunning)).To(Equal([]v1alpha1.InitSqlScriptStatus{
+		{Name: "schema", Phase: v1alpha1.InitializePhaseCompleted},
+		{Name: "users", Phase: v1alpha1.InitializePhasePending},
+		{Name: "data", Phase: v1alpha1.InitializePhasePending},
+	}))
+}
+
+func TestValidateInitSqlScripts(t *testing.T) {
+	g := NewGomegaWithT(t)
+	configMap := &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "sql"}, Key: "init.sql"}
+	secret := &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "sql"}, Key: "init.sql"}
+
+	tests := []struct {
+		name    string
+		scripts []v1alpha1.InitSqlScript
+		initSql *string
+		err     string
+	}{
+		{name: "valid", scripts: []v1alpha1.InitSqlScript{{Name: "a", ConfigMap: configMap}, {Name: "b", Secret: secret}}},
+		{name: "with initSql", scripts: []v1alpha1.InitSqlScript{{Name: "a", ConfigMap: configMap}}, initSql: pointer.StringPtr("select 1;"), err: "initSql"},
+		{name: "invalid name", scripts: []v1alpha1.InitSqlScript{{Name: "a,b", ConfigMap: configMap}}, err: "invalid name"},
+		{name: "duplicate name", scripts: []v1alpha1.InitSqlScript{{Name: "a", ConfigMap: configMap}, {Name: "a", Secret: secret}}, err: "duplicate name"},
+		{name: "no source", scripts: []v1alpha1.InitSqlScript{{Name: "a"}}, err: "exactly one"},
+		{name: "two sources", scripts: []v1alpha1.InitSqlScript{{Name: "a", ConfigMap: configMap, Secret: secret}}, err: "exactly one"},
+	}
+	for _, test := range tests {
+		ti := newTidbInitializerForTiDB()
+		ti.Spec.InitSqlScripts = test.scripts
+		ti.Spec.InitSql = test.initSql
+		err := validateInitSqlScripts(ti)
+		if test.err == "" {
+			g.Expect(err).NotTo(HaveOccurred(), test.name)
+		} else {
+			g.Expect(err).To(MatchError(ContainSubstring(test.err)), test.name)
+		}
+	}
+}
+
+func TestTiDBInitManagerRetry(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tim, _, indexers := newFakeTiDBInitManager()
+	ti := newTidbInitializerForTiDB()
+	ti.Spec.OnFailure = v1alpha1.InitializerFailurePolicyRetry
+	ti.Spec.MaxRetries = pointer.Int32Ptr(1)
+	ti.Spec.RetryBackoff = &metav1.Duration{Duration: time.Hour}
+	_, err := tim.deps.Clientset.PingcapV1alpha1().TidbInitializers(ti.Namespace).Create(ti)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// the failed job is deleted and recreated after the backoff
+	now := time.Now().Truncate(time.Second)
+	g.Expect(indexers.job.Add(newFailedTiDBInitJob(now.Add(-time.Minute), now))).To(Succeed())
+	err = tim.updateStatus(ti.DeepCopy())
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	ti, err = tim.deps.Clientset.PingcapV1alpha1().TidbInitializers(ti.Namespace).Get(ti.Name, metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ti.Status.Phase).To(Equal(v1alpha1.InitializePhaseRunning))
+	g.Expect(ti.Status.NextRetryTime.Time).To(Equal(now.Add(time.Hour)))
+	g.Expect(ti.Status.Message).To(ContainSubstring("retry 1/1"))
+	g.Expect(ti.Status.Attempts).To(HaveLen(1))
+	g.Expect(ti.Status.Attempts[0].Phase).To(Equal(v1alpha1.InitializePhaseFailed))
+	g.Expect(ti.Status.Attempts[0].Reason).To(Equal("BackoffLimitExceeded"))
+
+	g.Expect(indexers.job.Delete(newFailedTiDBInitJob(now, now))).To(Succeed())
+	err = tim.syncTiDBInitJob(ti)
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	_, err = tim.deps.JobLister.Jobs(ti.Namespace).Get(controller.TiDBInitializerMemberName("test"))
+	g.Expect(errors.IsNotFound(err)).To(BeTrue())
+
+	// the initialization fails after the retries are exhausted
+	g.Expect(indexers.job.Add(newFailedTiDBInitJob(now.Add(time.Hour), now.Add(time.Hour+time.Minute)))).To(Succeed())
+	g.Expect(tim.updateStatus(ti.DeepCopy())).To(Succeed())
+	ti, err = tim.deps.Clientset.PingcapV1alpha1().TidbInitializers(ti.Namespace).Get(ti.Name, metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ti.Status.Phase).To(Equal(v1alpha1.InitializePhaseFailed))
+	g.Expect(ti.Status.NextRetryTime).To(BeNil())
+	g.Expect(ti.Status.Message).To(ContainSubstring("exhausted"))
+	g.Expect(ti.Status.Attempts).To(HaveLen(2))
+}
+
+func TestHandleInitializerFailure(t *testing.T) {
+	g := NewGomegaWithT(t)
+	now := time.Now().Truncate(time.Second)
+
+	tests := []struct {
+		name      string
+		onFailure v1alpha1.InitializerFailurePolicy
+		timeout   time.Duration
+		attempts  int
+		retry     bool
+		phase     v1alpha1.InitializePhase
+		message   string
+	}{
+		{name: "abort", phase: v1alpha1.InitializePhaseFailed, message: "job failed: BackoffLimitExceeded"},
+		{name: "ignore", onFailure: v1alpha1.InitializerFailurePolicyIgnore, phase: v1alpha1.InitializePhaseCompleted, message: "ignored"},
+		{name: "retry", onFailure: v1alpha1.InitializerFailurePolicyRetry, attempts: 3, retry: true, phase: v1alpha1.InitializePhaseRunning, message: "retry 3/3"},
+		{name: "retries exhausted", onFailure: v1alpha1.InitializerFailurePolicyRetry, attempts: 4, phase: v1alpha1.InitializePhaseFailed, message: "exhausted"},
+		{name: "timeout", onFailure: v1alpha1.InitializerFailurePolicyRetry, timeout: time.Minute, phase: v1alpha1.InitializePhaseFailed, message: "times out"},
+	}
+	for _, test := range tests {
+		ti := newTidbInitializerForTiDB()
+		ti.Spec.OnFailure = test.onFailure
+		if test.timeout > 0 {
+			ti.Spec.Timeout = &metav1.Duration{Duration: test.timeout}
+		}
+		attempts := test.attempts
+		if attempts == 0 {
+			attempts = 1
+		}
+		for i := 0; i < attempts; i++ {
+			ti.Status.Attempts = append(ti.Status.Attempts, v1alpha1.InitializerAttempt{
+				StartTime:      metav1.NewTime(now.Add(-time.Minute)),
+				CompletionTime: &metav1.Time{Time: now},
+				Phase:          v1alpha1.InitializePhaseFailed,
+				Reason:         "BackoffLimitExceeded",
+			})
+		}
+		ti.Status.Phase = v1alpha1.InitializePhaseFailed
+
+		g.Expect(handleInitializerFailure(ti, now)).To(Equal(test.retry), test.name)
+		g.Expect(ti.Status.Phase).To(Equal(test.phase), test.name)
+		g.Expect(ti.Status.Message).To(ContainSubstring(test.message), test.name)
+		if test.retry {
+			// the backoff doubles for each retry
+			g.Expect(ti.Status.NextRetryTime.Time).To(Equal(now.Add(40*time.Second)), test.name)
+		}
+	}
+}
+
+func TestMakeTiDBInitJobWithTimeout(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tim, tmm, _ := newFakeTiDBInitManager()
+	_, err := tmm.deps.Controls.TiDBClusterControl.UpdateTidbCluster(newTidbClusterForTiDB(), nil, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// the deadline is counted from the first attempt
+	ti := newTidbInitializerForTiDB()
+	ti.Spec.Timeout = &metav1.Duration{Duration: 10 * time.Minute}
+	ti.Status.Attempts = []v1alpha1.InitializerAttempt{{StartTime: metav1.NewTime(time.Now().Add(-5 * time.Minute))}}
+	job, err := tim.makeTiDBInitJob(ti)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(*job.Spec.ActiveDeadlineSeconds).To(BeNumerically("~", 300, 2))
+}
+
+func newFailedTiDBInitJob(start, failed time.Time) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              controller.TiDBInitializerMemberName("test"),
+			Namespace:         corev1.NamespaceDefault,
+			CreationTimestamp: metav1.NewTime(start),
+		},
+		Status: batchv1.JobStatus{
+			Conditions: []batchv1.JobCondition{{
+				Type:               batchv1.JobFailed,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(failed),
+				Reason:             "BackoffLimitExceeded",
+			}},
+		},
+	}
+}
+
 func newFakeTiDBInitManager() (*tidbInitManager, *tidbMemberManager, *fakeIndexers) {
 	tmm, _, _, indexers := newFakeTiDBMemberManager()
 	indexers.job = tmm.deps.KubeInformerFactory.Batch().V1().Jobs().Informer().GetIndexer()
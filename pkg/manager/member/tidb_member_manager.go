@@ -23,6 +23,7 @@ import (
 	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/features"
 	"github.com/pingcap/tidb-operator/pkg/label"
 	"github.com/pingcap/tidb-operator/pkg/manager"
 	"github.com/pingcap/tidb-operator/pkg/util"
@@ -167,8 +168,7 @@ func (m *tidbMemberManager) syncTiDBHeadlessServiceForTidbCluster(tc *v1alpha1.T
 		if err != nil {
 			return err
 		}
-		_, err = m.deps.ServiceControl.UpdateService(tc, &svc)
-		return err
+		return updateService(m.deps.ServiceControl, tc, newSvc, &svc)
 	}
 
 	return nil
@@ -198,6 +198,7 @@ func (m *tidbMemberManager) syncTiDBStatefulSetForTidbCluster(tc *v1alpha1.TidbC
 	if err != nil {
 		return err
 	}
+	syncConfigSyncedCondition(&tc.Status.TiDB.Conditions, oldTiDBSet, cm, controller.TiDBMemberName(tc.Name))
 
 	newTiDBSet, err := getNewTiDBSetForTidbCluster(tc, cm)
 	if err != nil {
@@ -226,10 +227,10 @@ func (m *tidbMemberManager) syncTiDBStatefulSetForTidbCluster(tc *v1alpha1.TidbC
 		return err
 	}
 
-	if m.deps.CLIConfig.AutoFailover {
+	if m.deps.CLIConfig.AutoFailover && !tc.Adopting() {
 		if m.shouldRecover(tc) {
 			m.tidbFailover.Recover(tc)
-		} else if tc.TiDBAllPodsStarted() && !tc.TiDBAllMembersReady() {
+		} else if tc.TiDBAllPodsStarted() && (!tc.TiDBAllMembersReady() || tc.TiDBSQLProbeFailing()) {
 			if err := m.tidbFailover.Failover(tc); err != nil {
 				return err
 			}
@@ -237,9 +238,13 @@ func (m *tidbMemberManager) syncTiDBStatefulSetForTidbCluster(tc *v1alpha1.TidbC
 	}
 
 	if !templateEqual(newTiDBSet, oldTiDBSet) || tc.Status.TiDB.Phase == v1alpha1.UpgradePhase {
-		if err := m.tidbUpgrader.Upgrade(tc, oldTiDBSet, newTiDBSet); err != nil {
+		err := m.tidbUpgrader.Upgrade(tc, oldTiDBSet, newTiDBSet)
+		syncUpgradeProgress(&tc.Status.TiDB.UpgradeProgress, tc.Status.TiDB.Phase, tc.Status.TiDB.Conditions, newTiDBSet, &oldTiDBSet.Status, tc.TiDBImage())
+		if err != nil {
 			return err
 		}
+	} else {
+		tc.Status.TiDB.UpgradeProgress = nil
 	}
 
 	return UpdateStatefulSet(m.deps.StatefulSetControl, tc, newTiDBSet, oldTiDBSet)
@@ -264,7 +269,7 @@ func (m *tidbMemberManager) shouldRecover(tc *v1alpha1.TidbCluster) bool {
 			return false
 		}
 		status, ok := tc.Status.TiDB.Members[pod.Name]
-		if !ok || !status.Health {
+		if !ok || !status.Health || status.SQLProbeFailingSince != nil {
 			return false
 		}
 	}
@@ -319,6 +324,11 @@ func (m *tidbMemberManager) syncTiDBService(tc *v1alpha1.TidbCluster) error {
 	delete(oldSvc.Annotations, LastAppliedConfigAnnotation)
 	annoEqual := equality.Semantic.DeepEqual(newSvc.Annotations, oldSvc.Annotations)
 	labelEqual := equality.Semantic.DeepEqual(newSvc.Labels, oldSvc.Labels)
+	if features.DefaultFeatureGate.Enabled(features.ServerSideApply) {
+		// the annotations and labels added by others are kept by server-side apply
+		annoEqual = util.IsSubMapOf(newSvc.Annotations, oldSvc.Annotations)
+		labelEqual = util.IsSubMapOf(newSvc.Labels, oldSvc.Labels)
+	}
 	isOrphan := metav1.GetControllerOf(oldSvc) == nil
 
 	if equal && annoEqual && labelEqual && !isOrphan {
@@ -341,8 +351,7 @@ func (m *tidbMemberManager) syncTiDBService(tc *v1alpha1.TidbCluster) error {
 		svc.OwnerReferences = newSvc.OwnerReferences
 	}
 
-	_, err = m.deps.ServiceControl.UpdateService(tc, &svc)
-	return err
+	return updateService(m.deps.ServiceControl, tc, newSvc, &svc)
 }
 
 // syncTiDBConfigMap syncs the configmap of tidb
@@ -384,11 +393,13 @@ func getTiDBConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
 		config.Set("security.cluster-ssl-ca", path.Join(clusterCertPath, tlsSecretRootCAKey))
 		config.Set("security.cluster-ssl-cert", path.Join(clusterCertPath, corev1.TLSCertKey))
 		config.Set("security.cluster-ssl-key", path.Join(clusterCertPath, corev1.TLSPrivateKeyKey))
+		setTLSPolicyConfig(config.GenericConfig, tc.Spec.TLSCluster.Policy, "security.cluster-tls-version", "security.cluster-tls-cipher-suites")
 	}
 	if tc.Spec.TiDB.IsTLSClientEnabled() {
 		config.Set("security.ssl-ca", path.Join(serverCertPath, tlsSecretRootCAKey))
 		config.Set("security.ssl-cert", path.Join(serverCertPath, corev1.TLSCertKey))
 		config.Set("security.ssl-key", path.Join(serverCertPath, corev1.TLSPrivateKeyKey))
+		setTLSPolicyConfig(config.GenericConfig, tc.Spec.TiDB.TLSClient.Policy, "security.tls-version", "security.tls-cipher-suites")
 	}
 	confText, err := config.MarshalTOML()
 	if err != nil {
@@ -406,6 +417,9 @@ func getTiDBConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
 	if tc.HeterogeneousWithoutLocalPD() {
 		// FIXME: not work for across k8s cluster without local pd
 		tidbStartScriptModel.Path = controller.PDMemberName(tc.Spec.Cluster.Name) + ":2379"
+	} else if tc.ExternalPDOnly() {
+		tidbStartScriptModel.Path = strings.Join(tc.ExternalPDHosts(), ",")
+		tidbStartScriptModel.ExternalPD = true
 	} else {
 		tidbStartScriptModel.Path = "${CLUSTER_NAME}-pd:2379"
 	}
@@ -787,7 +801,9 @@ func getNewTiDBSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 	stsLabels := label.New().Instance(instanceName).TiDB()
 	podLabels := util.CombineStringMap(stsLabels, baseTiDBSpec.Labels())
 	podAnnotations := util.CombineStringMap(controller.AnnProm(10080), baseTiDBSpec.Annotations())
-	stsAnnotations := getStsAnnotations(tc.Annotations, label.TiDBLabelVal)
+	podAnnotations = util.CombineStringMap(podAnnotations, tlsCertAnnotations(tc, v1alpha1.TiDBMemberType))
+	podAnnotations = applySecurityProfile(baseTiDBSpec, &podSpec, podAnnotations, "")
+	stsAnnotations := getTidbClusterStsAnnotations(tc, label.TiDBLabelVal)
 
 	deleteSlotsNumber, err := util.GetDeleteSlotsNumber(stsAnnotations)
 	if err != nil {
@@ -829,6 +845,7 @@ func getNewTiDBSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 	}
 
 	tidbSet.Spec.VolumeClaimTemplates = append(tidbSet.Spec.VolumeClaimTemplates, additionalPVCs...)
+	setVolumeClaimTemplatesAnnotations(tidbSet, baseTiDBSpec.PVCAnnotations())
 	return tidbSet, nil
 }
 
@@ -875,6 +892,23 @@ func (m *tidbMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set
 				newTidbMember.LastTransitionTime = oldTidbMember.LastTransitionTime
 			}
 		}
+		if health {
+			newTidbMember.Info = m.getTiDBMemberInfo(tc, int32(id))
+			if newTidbMember.Info == nil && exist {
+				// keep the info if the status API fails to respond occasionally
+				newTidbMember.Info = oldTidbMember.Info
+			}
+			if err := m.deps.TiDBControl.ProbeSQL(tc, int32(id)); err != nil {
+				klog.V(4).Infof("failover probe of tidb %s/%s fails, error: %v", tc.GetNamespace(), name, err)
+				newTidbMember.SQLProbeMessage = err.Error()
+				if exist && oldTidbMember.SQLProbeFailingSince != nil {
+					newTidbMember.SQLProbeFailingSince = oldTidbMember.SQLProbeFailingSince
+				} else {
+					now := metav1.Now()
+					newTidbMember.SQLProbeFailingSince = &now
+				}
+			}
+		}
 		pod, err := m.deps.PodLister.Pods(tc.GetNamespace()).Get(name)
 		if err != nil && !errors.IsNotFound(err) {
 			return fmt.Errorf("syncTidbClusterStatus: failed to get pods %s for cluster %s/%s, error: %s", name, tc.GetNamespace(), tc.GetName(), err)
@@ -895,6 +929,30 @@ func (m *tidbMemberManager) syncTidbClusterStatus(tc *v1alpha1.TidbCluster, set
 	return nil
 }
 
+// getTiDBMemberInfo returns the information reported by the status API of the member, or nil if it fails
+func (m *tidbMemberManager) getTiDBMemberInfo(tc *v1alpha1.TidbCluster, ordinal int32) *v1alpha1.TiDBMemberInfo {
+	status, err := m.deps.TiDBControl.GetStatus(tc, ordinal)
+	if err != nil {
+		klog.V(4).Infof("failed to get status of tidb %d of tc %s/%s, error: %v", ordinal, tc.GetNamespace(), tc.GetName(), err)
+		return nil
+	}
+	dbInfo, err := m.deps.TiDBControl.GetInfo(tc, ordinal)
+	if err != nil || dbInfo == nil {
+		klog.V(4).Infof("failed to get info of tidb %d of tc %s/%s, error: %v", ordinal, tc.GetNamespace(), tc.GetName(), err)
+		return nil
+	}
+	info := &v1alpha1.TiDBMemberInfo{
+		Version:     status.Version,
+		DDLOwner:    dbInfo.IsOwner,
+		Connections: int32(status.Connections),
+	}
+	if dbInfo.StartTimestamp > 0 {
+		startTime := metav1.Unix(dbInfo.StartTimestamp, 0)
+		info.StartTime = &startTime
+	}
+	return info
+}
+
 func tidbStatefulSetIsUpgrading(podLister corelisters.PodLister, set *apps.StatefulSet, tc *v1alpha1.TidbCluster) (bool, error) {
 	if statefulSetIsUpgrading(set) {
 		return true, nil
@@ -352,6 +352,9 @@ func TestTiDBMemberManagerSyncTidbClusterStatus(t *testing.T) {
 		updateSts   func(*apps.StatefulSet)
 		upgradingFn func(corelisters.PodLister, *apps.StatefulSet, *v1alpha1.TidbCluster) (bool, error)
 		healthInfo  map[string]bool
+		tidbStatus  map[string]*controller.TiDBStatus
+		ddlOwner    string
+		probeErrs   map[string]error
 		errExpectFn func(*GomegaWithT, error)
 		tcExpectFn  func(*GomegaWithT, *v1alpha1.TidbCluster)
 	}
@@ -386,6 +389,9 @@ func TestTiDBMemberManagerSyncTidbClusterStatus(t *testing.T) {
 		if test.healthInfo != nil {
 			tidbControl.SetHealth(test.healthInfo)
 		}
+		tidbControl.SetStatus(test.tidbStatus)
+		tidbControl.SetDDLOwner(test.ddlOwner)
+		tidbControl.SetSQLProbeErrors(test.probeErrs)
 
 		err := pmm.syncTidbClusterStatus(tc, set)
 		if test.errExpectFn != nil {
@@ -544,6 +550,71 @@ func TestTiDBMemberManagerSyncTidbClusterStatus(t *testing.T) {
 				g.Expect(tc.Status.TiDB.Members["test-tidb-2"].LastTransitionTime).NotTo(Equal(now))
 			},
 		},
+		{
+			name: "record info of the healthy members",
+			updateTC: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.TiDB.Members = map[string]v1alpha1.TiDBMember{
+					"test-tidb-1": {Health: true, Info: &v1alpha1.TiDBMemberInfo{Version: "5.7.25-TiDB-v4.0.0", Connections: 3}},
+					"test-tidb-2": {Health: true, Info: &v1alpha1.TiDBMemberInfo{Version: "5.7.25-TiDB-v4.0.0", Connections: 5}},
+				}
+			},
+			healthInfo: map[string]bool{
+				"test-tidb-0": true,
+				"test-tidb-1": true,
+			},
+			tidbStatus: map[string]*controller.TiDBStatus{
+				"test-tidb-0": {Connections: 10, Version: "5.7.25-TiDB-v4.0.1"},
+			},
+			ddlOwner: "test-tidb-0",
+			upgradingFn: func(lister corelisters.PodLister, set *apps.StatefulSet, cluster *v1alpha1.TidbCluster) (bool, error) {
+				return false, nil
+			},
+			errExpectFn: errExpectNil,
+			tcExpectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster) {
+				g.Expect(len(tc.Status.TiDB.Members)).To(Equal(3))
+				g.Expect(tc.Status.TiDB.Members["test-tidb-0"].Info).To(Equal(&v1alpha1.TiDBMemberInfo{
+					Version:     "5.7.25-TiDB-v4.0.1",
+					DDLOwner:    true,
+					Connections: 10,
+				}))
+				// the info is kept if the status API fails
+				g.Expect(tc.Status.TiDB.Members["test-tidb-1"].Info.Connections).To(Equal(int32(3)))
+				// the info is dropped if the member is not healthy
+				g.Expect(tc.Status.TiDB.Members["test-tidb-2"].Info).To(BeNil())
+			},
+		},
+		{
+			name: "record the failing failover probe of the healthy members",
+			updateTC: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.TiDB.FailoverProbe = &v1alpha1.TiDBFailoverProbe{}
+				tc.Status.TiDB.Members = map[string]v1alpha1.TiDBMember{
+					"test-tidb-1": {Health: true, SQLProbeFailingSince: &metav1.Time{Time: time.Unix(100, 0)}},
+					"test-tidb-2": {Health: true, SQLProbeFailingSince: &metav1.Time{Time: time.Unix(100, 0)}},
+				}
+			},
+			healthInfo: map[string]bool{
+				"test-tidb-0": true,
+				"test-tidb-1": true,
+				"test-tidb-2": true,
+			},
+			probeErrs: map[string]error{
+				"test-tidb-0": fmt.Errorf("i/o timeout"),
+				"test-tidb-1": fmt.Errorf("i/o timeout"),
+			},
+			upgradingFn: func(lister corelisters.PodLister, set *apps.StatefulSet, cluster *v1alpha1.TidbCluster) (bool, error) {
+				return false, nil
+			},
+			errExpectFn: errExpectNil,
+			tcExpectFn: func(g *GomegaWithT, tc *v1alpha1.TidbCluster) {
+				g.Expect(tc.Status.TiDB.Members["test-tidb-0"].SQLProbeFailingSince).NotTo(BeNil())
+				g.Expect(tc.Status.TiDB.Members["test-tidb-0"].SQLProbeMessage).To(Equal("i/o timeout"))
+				// the time since which the probe fails is kept
+				g.Expect(tc.Status.TiDB.Members["test-tidb-1"].SQLProbeFailingSince.Unix()).To(Equal(int64(100)))
+				// the probe succeeds
+				g.Expect(tc.Status.TiDB.Members["test-tidb-2"].SQLProbeFailingSince).To(BeNil())
+				g.Expect(tc.TiDBSQLProbeFailing()).To(BeTrue())
+			},
+		},
 	}
 
 	for i := range tests {
@@ -1934,6 +2005,76 @@ func TestGetTiDBConfigMap(t *testing.T) {
   ssl-ca = "/var/lib/tidb-server-tls/ca.crt"
   ssl-cert = "/var/lib/tidb-server-tls/tls.crt"
   ssl-key = "/var/lib/tidb-server-tls/tls.key"
+`,
+				},
+			},
+		},
+		{
+			name: "TiDB config with tls policies",
+			tc: v1alpha1.TidbCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "ns",
+				},
+				Spec: v1alpha1.TidbClusterSpec{
+					TLSCluster: &v1alpha1.TLSCluster{
+						Enabled: true,
+						Policy:  &v1alpha1.TLSPolicy{MinVersion: "TLSv1.2"},
+					},
+					TiDB: &v1alpha1.TiDBSpec{
+						ComponentSpec: v1alpha1.ComponentSpec{
+							ConfigUpdateStrategy: &updateStrategy,
+						},
+						TLSClient: &v1alpha1.TiDBTLSClient{
+							Enabled: true,
+							Policy: &v1alpha1.TLSPolicy{
+								MinVersion:   "TLSv1.1",
+								CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+							},
+						},
+						Config: v1alpha1.NewTiDBConfig(),
+					},
+					PD:   &v1alpha1.PDSpec{},
+					TiKV: &v1alpha1.TiKVSpec{},
+				},
+			},
+			expected: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo-tidb",
+					Namespace: "ns",
+					Labels: map[string]string{
+						"app.kubernetes.io/name":       "tidb-cluster",
+						"app.kubernetes.io/managed-by": "tidb-operator",
+						"app.kubernetes.io/instance":   "foo",
+						"app.kubernetes.io/component":  "tidb",
+					},
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion: "pingcap.com/v1alpha1",
+							Kind:       "TidbCluster",
+							Name:       "foo",
+							UID:        "",
+							Controller: func(b bool) *bool {
+								return &b
+							}(true),
+							BlockOwnerDeletion: func(b bool) *bool {
+								return &b
+							}(true),
+						},
+					},
+				},
+				Data: map[string]string{
+					"startup-script": "",
+					"config-file": `[security]
+  cluster-ssl-ca = "/var/lib/tidb-tls/ca.crt"
+  cluster-ssl-cert = "/var/lib/tidb-tls/tls.crt"
+  cluster-ssl-key = "/var/lib/tidb-tls/tls.key"
+  cluster-tls-version = "TLSv1.2"
+  ssl-ca = "/var/lib/tidb-server-tls/ca.crt"
+  ssl-cert = "/var/lib/tidb-server-tls/tls.crt"
+  ssl-key = "/var/lib/tidb-server-tls/tls.key"
+  tls-cipher-suites = ["TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"]
+  tls-version = "TLSv1.1"
 `,
 				},
 			},
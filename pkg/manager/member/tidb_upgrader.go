@@ -19,6 +19,7 @@ import (
 	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
 	apps "k8s.io/api/apps/v1"
 	"k8s.io/klog"
 )
@@ -65,6 +66,7 @@ func (u *tidbUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulSe
 	}
 
 	tc.Status.TiDB.Phase = v1alpha1.UpgradePhase
+	setUpgradingCondition(&tc.Status.TiDB.Conditions, utiltidbcluster.RollingUpdate, "")
 	if !templateEqual(newSet, oldSet) {
 		return nil
 	}
@@ -99,10 +101,24 @@ func (u *tidbUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulSe
 
 		if revision == tc.Status.TiDB.StatefulSet.UpdateRevision {
 			if member, exist := tc.Status.TiDB.Members[podName]; !exist || !member.Health {
+				setUpgradingCondition(&tc.Status.TiDB.Conditions, utiltidbcluster.WaitingForPodReady, fmt.Sprintf("upgraded pod %s is not ready", podName))
 				return controller.RequeueErrorf("tidbcluster: [%s/%s]'s tidb upgraded pod: [%s] is not ready", ns, tcName, podName)
 			}
 			continue
 		}
+		// upgrade the DDL owner last to avoid interrupting the running DDL jobs repeatedly,
+		// the owner is resigned before it is upgraded unless it is the last one to upgrade
+		if member, exist := tc.Status.TiDB.Members[podName]; exist && member.Info != nil && member.Info.DDLOwner && _i > 0 {
+			resigned, err := u.deps.TiDBControl.ResignDDLOwner(tc, i)
+			if err != nil {
+				return fmt.Errorf("tidbUpgrader.Upgrade: failed to resign ddl owner of tidb pod %s for cluster %s/%s, error: %s", podName, ns, tcName, err)
+			}
+			if resigned {
+				setUpgradingCondition(&tc.Status.TiDB.Conditions, utiltidbcluster.WaitingForDDLOwnerTransfer,
+					fmt.Sprintf("pod %s resigned the ddl owner", podName))
+				return controller.RequeueErrorf("tidbcluster: [%s/%s]'s tidb pod: [%s] resigned ddl owner, wait for the next round to upgrade it", ns, tcName, podName)
+			}
+		}
 		return u.upgradeTiDBPod(tc, i, newSet)
 	}
 
@@ -110,6 +126,7 @@ func (u *tidbUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.StatefulSe
 }
 
 func (u *tidbUpgrader) upgradeTiDBPod(tc *v1alpha1.TidbCluster, ordinal int32, newSet *apps.StatefulSet) error {
+	recordPodUpgrade(u.deps, tc, v1alpha1.TiDBMemberType, newSet, tc.Status.TiDB.StatefulSet, ordinal)
 	setUpgradePartition(newSet, ordinal)
 	return nil
 }
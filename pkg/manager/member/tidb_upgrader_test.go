@@ -221,6 +221,78 @@ func TestTiDBUpgrader_Upgrade(t *testing.T) {
 
 }
 
+func TestTiDBUpgraderUpgradeDDLOwnerLast(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type testcase struct {
+		name              string
+		ddlOwner          string
+		errorExpect       bool
+		expectResigned    []string
+		expectedPartition int32
+	}
+
+	testFn := func(test *testcase) {
+		t.Log(test.name)
+		upgrader, tidbControl, podInformer := newTiDBUpgrader()
+		tidbControl.SetDDLOwner(test.ddlOwner)
+
+		tc := newTidbClusterForTiDBUpgrader()
+		tc.Status.PD.Phase = v1alpha1.NormalPhase
+		tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+		tc.Status.TiDB.Members["upgrader-tidb-2"] = v1alpha1.TiDBMember{Name: "upgrader-tidb-2", Health: true}
+		for name, member := range tc.Status.TiDB.Members {
+			member.Info = &v1alpha1.TiDBMemberInfo{DDLOwner: name == test.ddlOwner}
+			tc.Status.TiDB.Members[name] = member
+		}
+
+		// upgrader-tidb-2 is upgraded, and upgrader-tidb-1 and upgrader-tidb-0 are to be upgraded
+		pods := getTiDBPods()
+		pods[1].Labels[apps.ControllerRevisionHashLabelKey] = "1"
+		pod := pods[1].DeepCopy()
+		pod.Name = tidbPodName(upgradeTcName, 2)
+		pod.Labels[apps.ControllerRevisionHashLabelKey] = "2"
+		pods = append(pods, pod)
+		for _, pod := range pods {
+			podInformer.Informer().GetIndexer().Add(pod)
+		}
+
+		oldSet := newStatefulSetForTiDBUpgrader()
+		oldSet.Spec.Replicas = pointer.Int32Ptr(3)
+		oldSet.Spec.UpdateStrategy.RollingUpdate.Partition = pointer.Int32Ptr(2)
+		newSet := oldSet.DeepCopy()
+		SetStatefulSetLastAppliedConfigAnnotation(oldSet)
+
+		err := upgrader.Upgrade(tc, oldSet, newSet)
+		if test.errorExpect {
+			g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+		} else {
+			g.Expect(err).NotTo(HaveOccurred())
+		}
+		g.Expect(tidbControl.Resigned).To(Equal(test.expectResigned))
+		g.Expect(newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(pointer.Int32Ptr(test.expectedPartition)))
+	}
+
+	tests := []*testcase{
+		{
+			name:              "the member to upgrade is not the ddl owner",
+			ddlOwner:          "upgrader-tidb-0",
+			expectedPartition: 1,
+		},
+		{
+			name:              "the ddl owner is resigned before it is upgraded",
+			ddlOwner:          "upgrader-tidb-1",
+			errorExpect:       true,
+			expectResigned:    []string{"upgrader-tidb-1"},
+			expectedPartition: 2,
+		},
+	}
+
+	for _, test := range tests {
+		testFn(test)
+	}
+}
+
 func newTiDBUpgrader() (Upgrader, *controller.FakeTiDBControl, podinformers.PodInformer) {
 	fakeDeps := controller.NewFakeDependencies()
 	upgrader := &tidbUpgrader{fakeDeps}
@@ -95,6 +95,17 @@ func getTidbAddr(key string) (addr string) {
 	return
 }
 
+// getExternalPDEtcdClient returns the etcd client of the first PD in `spec.pdAddresses` of the TidbCluster
+// which joins the PD cluster out of Kubernetes
+func (m *TidbClusterStatusManager) getExternalPDEtcdClient(tc *v1alpha1.TidbCluster) (pdapi.PDEtcdClient, error) {
+	// only the TLS config of the client secret is used
+	_, tlsConfig, err := m.deps.PDControl.GetEndpoints(pdapi.Namespace(tc.Namespace), tc.Name, tc.IsTLSClusterEnabled())
+	if err != nil {
+		return nil, err
+	}
+	return pdapi.NewPdEtcdClient(tc.Spec.PDAddresses[0], pdapi.DefaultTimeout, tlsConfig)
+}
+
 func (m *TidbClusterStatusManager) syncTiDBInfoKey(tc *v1alpha1.TidbCluster) error {
 	if tc.Spec.TiDB == nil {
 		return nil
@@ -105,6 +116,8 @@ func (m *TidbClusterStatusManager) syncTiDBInfoKey(tc *v1alpha1.TidbCluster) err
 
 	if tc.HeterogeneousWithoutLocalPD() {
 		pdEtcdClient, err = m.deps.PDControl.GetPDEtcdClient(pdapi.Namespace(tc.Spec.Cluster.Namespace), tc.Spec.Cluster.Name, tc.IsTLSClusterEnabled())
+	} else if tc.ExternalPDOnly() {
+		pdEtcdClient, err = m.getExternalPDEtcdClient(tc)
 	} else {
 		pdEtcdClient, err = m.deps.PDControl.GetPDEtcdClient(pdapi.Namespace(tc.Namespace), tc.Name, tc.IsTLSClusterEnabled())
 	}
@@ -32,7 +32,7 @@ type tiflashFailover struct {
 
 // NewTiFlashFailover returns a tiflash Failover
 func NewTiFlashFailover(deps *controller.Dependencies) Failover {
-	return &tiflashFailover{deps: deps}
+	return newSimulatedFailover(deps, v1alpha1.TiFlashMemberType, &tiflashFailover{deps: deps})
 }
 
 func (f *tiflashFailover) isPodDesired(tc *v1alpha1.TidbCluster, podName string) bool {
@@ -75,7 +75,7 @@ func (f *tiflashFailover) Failover(tc *v1alpha1.TidbCluster) error {
 			if tc.Spec.TiFlash.MaxFailoverCount != nil && *tc.Spec.TiFlash.MaxFailoverCount > 0 {
 				maxFailoverCount := *tc.Spec.TiFlash.MaxFailoverCount
 				if len(tc.Status.TiFlash.FailureStores) >= int(maxFailoverCount) {
-					klog.Warningf("%s/%s TiFlash failure stores count reached the limit: %d", ns, tcName, tc.Spec.TiFlash.MaxFailoverCount)
+					klog.Warningf("%s/%s TiFlash failure stores count reached the limit: %d", ns, tcName, maxFailoverCount)
 					return nil
 				}
 				tc.Status.TiFlash.FailureStores[storeID] = v1alpha1.TiKVFailureStore{
@@ -84,7 +84,18 @@ func (f *tiflashFailover) Failover(tc *v1alpha1.TidbCluster) error {
 					CreatedAt: metav1.Now(),
 				}
 				msg := fmt.Sprintf("store [%s] is Down", store.ID)
-				f.deps.Recorder.Event(tc, corev1.EventTypeWarning, unHealthEventReason, fmt.Sprintf(unHealthEventMsgPattern, "tiflash", podName, msg))
+				recordFailoverEvent(f.deps, tc, corev1.EventTypeWarning, unHealthEventReason, unHealthEventMsgPattern, "tiflash", podName, msg)
+				failure := controller.FailoverNotification{
+					Component: v1alpha1.TiFlashMemberType.String(),
+					PodName:   podName,
+					StoreID:   store.ID,
+				}
+				failure.Action = controller.FailoverMemberFailed
+				failure.Reason = fmt.Sprintf("store is Down for more than %s", f.deps.CLIConfig.TiFlashFailoverPeriod)
+				notifyFailover(f.deps, tc, failure)
+				failure.Action = controller.FailoverReplacementCreated
+				failure.Reason = fmt.Sprintf("a replacement replica is added, failover replicas %d/%d", len(tc.Status.TiFlash.FailureStores), maxFailoverCount)
+				notifyFailover(f.deps, tc, failure)
 			}
 		}
 	}
@@ -103,6 +114,7 @@ func (f *tiflashFailover) RemoveUndesiredFailures(tc *v1alpha1.TidbCluster) {
 }
 
 func (f *tiflashFailover) Recover(tc *v1alpha1.TidbCluster) {
+	notifyStoresRecovered(f.deps, tc, v1alpha1.TiFlashMemberType, tc.Status.TiFlash.FailureStores)
 	tc.Status.TiFlash.FailureStores = nil
 	klog.Infof("TiFlash recover: clear FailureStores, %s/%s", tc.GetNamespace(), tc.GetName())
 }
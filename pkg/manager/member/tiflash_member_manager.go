@@ -29,6 +29,7 @@ import (
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/uuid"
@@ -136,8 +137,7 @@ func (m *tiflashMemberManager) syncHeadlessService(tc *v1alpha1.TidbCluster) err
 		if err != nil {
 			return err
 		}
-		_, err = m.deps.ServiceControl.UpdateService(tc, &svc)
-		return err
+		return updateService(m.deps.ServiceControl, tc, newSvc, &svc)
 	}
 
 	return nil
@@ -168,6 +168,7 @@ func (m *tiflashMemberManager) syncStatefulSet(tc *v1alpha1.TidbCluster) error {
 	if err != nil {
 		return err
 	}
+	syncConfigSyncedCondition(&tc.Status.TiFlash.Conditions, oldSet, cm, controller.TiFlashMemberName(tc.Name))
 
 	// Recover failed stores if any before generating desired statefulset
 	if len(tc.Status.TiFlash.FailureStores) > 0 {
@@ -213,7 +214,7 @@ func (m *tiflashMemberManager) syncStatefulSet(tc *v1alpha1.TidbCluster) error {
 		return err
 	}
 
-	if m.deps.CLIConfig.AutoFailover && tc.Spec.TiFlash.MaxFailoverCount != nil {
+	if m.deps.CLIConfig.AutoFailover && tc.Spec.TiFlash.MaxFailoverCount != nil && !tc.Adopting() {
 		if tc.TiFlashAllPodsStarted() && !tc.TiFlashAllStoresReady() {
 			if err := m.failover.Failover(tc); err != nil {
 				return err
@@ -222,9 +223,13 @@ func (m *tiflashMemberManager) syncStatefulSet(tc *v1alpha1.TidbCluster) error {
 	}
 
 	if !templateEqual(newSet, oldSet) || tc.Status.TiFlash.Phase == v1alpha1.UpgradePhase {
-		if err := m.upgrader.Upgrade(tc, oldSet, newSet); err != nil {
+		err := m.upgrader.Upgrade(tc, oldSet, newSet)
+		syncUpgradeProgress(&tc.Status.TiFlash.UpgradeProgress, tc.Status.TiFlash.Phase, tc.Status.TiFlash.Conditions, newSet, &oldSet.Status, tc.TiFlashImage())
+		if err != nil {
 			return err
 		}
+	} else {
+		tc.Status.TiFlash.UpgradeProgress = nil
 	}
 
 	return UpdateStatefulSet(m.deps.StatefulSetControl, tc, newSet, oldSet)
@@ -400,7 +405,7 @@ func getNewStatefulSet(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap) (*apps.St
 	script := "set -ex;ordinal=`echo ${POD_NAME} | awk -F- '{print $NF}'`;sed s/POD_NUM/${ordinal}/g /etc/tiflash/config_templ.toml > /data0/config.toml;sed s/POD_NUM/${ordinal}/g /etc/tiflash/proxy_templ.toml > /data0/proxy.toml"
 
 	// TODO: for across k8s cluster without local PD, the script here do not support this now.
-	if len(tc.Spec.ClusterDomain) > 0 {
+	if len(tc.Spec.ClusterDomain) > 0 && !tc.ExternalPDOnly() {
 		var pdAddr string
 		if tc.IsTLSClusterEnabled() {
 			pdAddr = fmt.Sprintf("https://%s-pd:2379", tcName)
@@ -442,7 +447,8 @@ sed -i s/PD_ADDR/${result}/g /data0/proxy.toml
 	podLabels := util.CombineStringMap(stsLabels, baseTiFlashSpec.Labels())
 	podAnnotations := util.CombineStringMap(controller.AnnProm(8234), baseTiFlashSpec.Annotations())
 	podAnnotations = util.CombineStringMap(controller.AnnAdditionalProm("tiflash.proxy", 20292), podAnnotations)
-	stsAnnotations := getStsAnnotations(tc.Annotations, label.TiFlashLabelVal)
+	podAnnotations = util.CombineStringMap(podAnnotations, tlsCertAnnotations(tc, v1alpha1.TiFlashMemberType))
+	stsAnnotations := getTidbClusterStsAnnotations(tc, label.TiFlashLabelVal)
 	capacity := controller.TiKVCapacity(tc.Spec.TiFlash.Limits)
 	headlessSvcName := controller.TiFlashPeerMemberName(tcName)
 
@@ -546,6 +552,7 @@ sed -i s/PD_ADDR/${result}/g /data0/proxy.toml
 	if podSpec.ServiceAccountName == "" {
 		podSpec.ServiceAccountName = tc.Spec.ServiceAccount
 	}
+	podAnnotations = applySecurityProfile(baseTiFlashSpec, &podSpec, podAnnotations, "")
 
 	updateStrategy := apps.StatefulSetUpdateStrategy{}
 	if baseTiFlashSpec.StatefulSetUpdateStrategy() == apps.OnDeleteStatefulSetStrategyType {
@@ -581,6 +588,7 @@ sed -i s/PD_ADDR/${result}/g /data0/proxy.toml
 			UpdateStrategy:       updateStrategy,
 		},
 	}
+	setVolumeClaimTemplatesAnnotations(tiflashset, baseTiFlashSpec.PVCAnnotations())
 	return tiflashset, nil
 }
 
@@ -745,7 +753,10 @@ func (m *tiflashMemberManager) getTiFlashStore(store *pdapi.StoreInfo) *v1alpha1
 		PodName:     podName,
 		IP:          ip,
 		LeaderCount: int32(store.Status.LeaderCount),
+		RegionCount: int32(store.Status.RegionCount),
 		State:       store.Store.StateName,
+		Capacity:    *resource.NewQuantity(int64(store.Status.Capacity), resource.BinarySI),
+		Available:   *resource.NewQuantity(int64(store.Status.Available), resource.BinarySI),
 	}
 }
 
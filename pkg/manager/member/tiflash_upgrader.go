@@ -19,6 +19,7 @@ import (
 	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
 	apps "k8s.io/api/apps/v1"
 	"k8s.io/klog"
 	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
@@ -58,6 +59,7 @@ func (u *tiflashUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.Statefu
 	}
 
 	tc.Status.TiFlash.Phase = v1alpha1.UpgradePhase
+	setUpgradingCondition(&tc.Status.TiFlash.Conditions, utiltidbcluster.RollingUpdate, "")
 	if !templateEqual(newSet, oldSet) {
 		return nil
 	}
@@ -97,14 +99,17 @@ func (u *tiflashUpgrader) Upgrade(tc *v1alpha1.TidbCluster, oldSet *apps.Statefu
 
 		if revision == tc.Status.TiFlash.StatefulSet.UpdateRevision {
 			if !podutil.IsPodReady(pod) {
+				setUpgradingCondition(&tc.Status.TiFlash.Conditions, utiltidbcluster.WaitingForPodReady, fmt.Sprintf("upgraded pod %s is not ready", podName))
 				return controller.RequeueErrorf("tidbcluster: [%s/%s]'s upgraded TiFlash pod: [%s] is not ready", ns, tcName, podName)
 			}
 			if store.State != v1alpha1.TiKVStateUp {
+				setUpgradingCondition(&tc.Status.TiFlash.Conditions, utiltidbcluster.WaitingForPodReady, fmt.Sprintf("store %s of upgraded pod %s is %s", store.ID, podName, store.State))
 				return controller.RequeueErrorf("tidbcluster: [%s/%s]'s upgraded TiFlash pod: [%s], store state is not UP", ns, tcName, podName)
 			}
 			continue
 		}
 
+		recordPodUpgrade(u.deps, tc, v1alpha1.TiFlashMemberType, newSet, tc.Status.TiFlash.StatefulSet, i)
 		setUpgradePartition(newSet, i)
 		return nil
 	}
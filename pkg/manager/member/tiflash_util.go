@@ -128,6 +128,10 @@ func getTiFlashConfig(tc *v1alpha1.TidbCluster) *v1alpha1.TiFlashConfigWraper {
 		noLocalTiDB = true
 	}
 
+	if tc.ExternalPDOnly() {
+		// the PD cluster out of Kubernetes is not verified by discovery
+		config.Common.SetIfNil("raft.pd_addr", strings.Join(tc.ExternalPDHosts(), ","))
+	}
 	setTiFlashConfigDefault(config, ref, tc.Name, tc.Namespace, tc.Spec.ClusterDomain, noLocalPD, noLocalTiDB)
 
 	// Note the config of tiflash use "_" by convention, others(proxy) use "-".
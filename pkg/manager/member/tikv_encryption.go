@@ -0,0 +1,189 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"encoding/json"
+	"path"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+const (
+	defaultTiKVEncryptionMethod        = "aes256-ctr"
+	defaultTiKVDataKeyRotationPeriod   = 7 * 24 * time.Hour
+	tikvEncryptionMasterKeyVolName     = "kms-master-key"
+	tikvEncryptionPrevMasterKeyVolName = "kms-previous-master-key"
+	tikvEncryptionKMSPath              = "/var/lib/tikv-kms"
+	// the keys of the credentials in the secret of the master key
+	kmsAWSAccessKey      = "access_key"
+	kmsAWSSecretKey      = "secret_key"
+	kmsGCPCredentialsKey = "credentials"
+)
+
+// syncTiKVEncryptionStatus records the master key to render in the config of TiKV. Once the master key in
+// the spec is changed, the master key in the status becomes the previous master key, and the TiKV Pods are
+// restarted to re-encrypt the data keys with the new one. The master key is not rotated again until the
+// TiKV cluster is upgraded, otherwise the data keys encrypted by the previous master key can not be decrypted.
+func syncTiKVEncryptionStatus(tc *v1alpha1.TidbCluster) {
+	encryption := tc.Spec.TiKV.Encryption
+	if encryption == nil {
+		return
+	}
+	status := tc.Status.TiKV.Encryption
+	if status == nil {
+		status = &v1alpha1.TiKVEncryptionStatus{}
+		tc.Status.TiKV.Encryption = status
+	}
+	if status.MasterKey == nil {
+		status.MasterKey = encryption.MasterKey.DeepCopy()
+		return
+	}
+	if *status.MasterKey == encryption.MasterKey {
+		return
+	}
+	if tc.Status.TiKV.Phase == v1alpha1.UpgradePhase {
+		klog.Infof("tikv cluster %s/%s is upgrading, wait for it to finish before rotating the master key %s", tc.Namespace, tc.Name, status.MasterKey.KeyID)
+		return
+	}
+	klog.Infof("tikv cluster %s/%s rotates the master key from %s to %s", tc.Namespace, tc.Name, status.MasterKey.KeyID, encryption.MasterKey.KeyID)
+	now := metav1.Now()
+	status.PreviousMasterKey = status.MasterKey
+	status.MasterKey = encryption.MasterKey.DeepCopy()
+	status.LastRotationTime = &now
+}
+
+// tikvEncryptionMasterKeys returns the master key and the previous master key to render in the config of TiKV
+func tikvEncryptionMasterKeys(tc *v1alpha1.TidbCluster) (*v1alpha1.TiKVKMSMasterKey, *v1alpha1.TiKVKMSMasterKey) {
+	status := tc.Status.TiKV.Encryption
+	if status == nil || status.MasterKey == nil {
+		return &tc.Spec.TiKV.Encryption.MasterKey, nil
+	}
+	return status.MasterKey, status.PreviousMasterKey
+}
+
+// setTiKVEncryptionConfig sets `security.encryption` in the config of TiKV
+func setTiKVEncryptionConfig(cfg *config.GenericConfig, tc *v1alpha1.TidbCluster) {
+	encryption := tc.Spec.TiKV.Encryption
+	method := encryption.Method
+	if method == "" {
+		method = defaultTiKVEncryptionMethod
+	}
+	period := defaultTiKVDataKeyRotationPeriod
+	if encryption.DataKeyRotationPeriod != nil {
+		period = encryption.DataKeyRotationPeriod.Duration
+	}
+	cfg.Set("security.encryption.data-encryption-method", method)
+	cfg.Set("security.encryption.data-key-rotation-period", period.String())
+
+	masterKey, previousMasterKey := tikvEncryptionMasterKeys(tc)
+	cfg.Del("security.encryption.previous-master-key")
+	setTiKVMasterKeyConfig(cfg, "security.encryption.master-key", masterKey, tikvEncryptionMasterKeyVolName)
+	if previousMasterKey != nil {
+		setTiKVMasterKeyConfig(cfg, "security.encryption.previous-master-key", previousMasterKey, tikvEncryptionPrevMasterKeyVolName)
+	}
+}
+
+func setTiKVMasterKeyConfig(cfg *config.GenericConfig, prefix string, key *v1alpha1.TiKVKMSMasterKey, volName string) {
+	cfg.Del(prefix)
+	cfg.Set(prefix+".type", "kms")
+	cfg.Set(prefix+".key-id", key.KeyID)
+	if key.Region != "" {
+		cfg.Set(prefix+".region", key.Region)
+	}
+	if key.Endpoint != "" {
+		cfg.Set(prefix+".endpoint", key.Endpoint)
+	}
+	if key.Vendor == v1alpha1.KMSVendorGCP {
+		cfg.Set(prefix+".vendor", string(key.Vendor))
+		if key.SecretName != "" {
+			cfg.Set(prefix+".gcp.credential-file-path", path.Join(tikvEncryptionKMSPath, volName, kmsGCPCredentialsKey))
+		}
+	}
+}
+
+// tikvEncryptionVolumes returns the volumes and the envs of the credentials to access the master keys
+func tikvEncryptionVolumes(tc *v1alpha1.TidbCluster) ([]corev1.Volume, []corev1.VolumeMount, []corev1.EnvVar) {
+	if tc.Spec.TiKV.Encryption == nil {
+		return nil, nil, nil
+	}
+	var vols []corev1.Volume
+	var volMounts []corev1.VolumeMount
+	var envs []corev1.EnvVar
+	masterKey, previousMasterKey := tikvEncryptionMasterKeys(tc)
+	for _, key := range []struct {
+		volName string
+		*v1alpha1.TiKVKMSMasterKey
+	}{
+		{tikvEncryptionMasterKeyVolName, masterKey},
+		{tikvEncryptionPrevMasterKeyVolName, previousMasterKey},
+	} {
+		if key.TiKVKMSMasterKey == nil || key.SecretName == "" {
+			continue
+		}
+		if key.Vendor != v1alpha1.KMSVendorGCP {
+			// TiKV reads the credentials of AWS KMS from the environment variables, so the credentials
+			// of the current master key are preferred if both the master keys are in AWS KMS
+			if len(envs) == 0 {
+				envs = append(envs,
+					secretKeyEnv("AWS_ACCESS_KEY_ID", key.SecretName, kmsAWSAccessKey),
+					secretKeyEnv("AWS_SECRET_ACCESS_KEY", key.SecretName, kmsAWSSecretKey),
+				)
+			}
+			continue
+		}
+		vols = append(vols, corev1.Volume{
+			Name: key.volName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: key.SecretName},
+			},
+		})
+		volMounts = append(volMounts, corev1.VolumeMount{
+			Name: key.volName, ReadOnly: true, MountPath: path.Join(tikvEncryptionKMSPath, key.volName),
+		})
+	}
+	return vols, volMounts, envs
+}
+
+func secretKeyEnv(name, secretName, key string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+				Key:                  key,
+			},
+		},
+	}
+}
+
+// tikvEncryptionAnnotations returns the pod annotations to restart the TiKV Pods once the master key is rotated
+func tikvEncryptionAnnotations(tc *v1alpha1.TidbCluster) map[string]string {
+	if tc.Spec.TiKV.Encryption == nil {
+		return nil
+	}
+	masterKey, _ := tikvEncryptionMasterKeys(tc)
+	data, err := json.Marshal(masterKey)
+	if err != nil {
+		klog.Warningf("failed to marshal the master key of tikv cluster %s/%s, error: %v", tc.Namespace, tc.Name, err)
+		return nil
+	}
+	return map[string]string{label.AnnTiKVEncryptionMasterKeyHash: tlsCertHash(data)}
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestTiKVEncryption(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiKV()
+	tc.Spec.TiKV.Config = nil
+	awsKey := v1alpha1.TiKVKMSMasterKey{KeyID: "aws-key", Region: "us-west-2", SecretName: "aws-kms"}
+	tc.Spec.TiKV.Encryption = &v1alpha1.TiKVEncryption{MasterKey: awsKey}
+
+	syncTiKVEncryptionStatus(tc)
+	g.Expect(*tc.Status.TiKV.Encryption.MasterKey).To(Equal(awsKey))
+	g.Expect(tc.Status.TiKV.Encryption.LastRotationTime).To(BeNil())
+
+	// the configmap is rendered even if the config is not set
+	cm, err := getTikVConfigMap(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	config := cm.Data["config-file"]
+	g.Expect(config).To(ContainSubstring(`data-encryption-method = "aes256-ctr"`))
+	g.Expect(config).To(ContainSubstring(`data-key-rotation-period = "168h0m0s"`))
+	g.Expect(config).To(ContainSubstring(`key-id = "aws-key"`))
+	g.Expect(config).NotTo(ContainSubstring("previous-master-key"))
+	set, err := getNewTiKVSetForTidbCluster(tc, cm)
+	g.Expect(err).NotTo(HaveOccurred())
+	annotation := set.Spec.Template.Annotations[label.AnnTiKVEncryptionMasterKeyHash]
+	g.Expect(annotation).NotTo(BeEmpty())
+	env := MapContainers(&set.Spec.Template.Spec)[v1alpha1.TiKVMemberType.String()].Env
+	g.Expect(env).To(ContainElement(secretKeyEnv("AWS_ACCESS_KEY_ID", "aws-kms", kmsAWSAccessKey)))
+
+	// the master key is not rotated until the upgrade finishes
+	gcpKey := v1alpha1.TiKVKMSMasterKey{Vendor: v1alpha1.KMSVendorGCP, KeyID: "projects/p/locations/global/keyRings/r/cryptoKeys/k", SecretName: "gcp-kms"}
+	tc.Spec.TiKV.Encryption.MasterKey = gcpKey
+	tc.Status.TiKV.Phase = v1alpha1.UpgradePhase
+	syncTiKVEncryptionStatus(tc)
+	g.Expect(*tc.Status.TiKV.Encryption.MasterKey).To(Equal(awsKey))
+
+	tc.Status.TiKV.Phase = v1alpha1.NormalPhase
+	syncTiKVEncryptionStatus(tc)
+	status := tc.Status.TiKV.Encryption
+	g.Expect(*status.MasterKey).To(Equal(gcpKey))
+	g.Expect(*status.PreviousMasterKey).To(Equal(awsKey))
+	g.Expect(status.LastRotationTime).NotTo(BeNil())
+
+	cm, err = getTikVConfigMap(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	config = cm.Data["config-file"]
+	g.Expect(config).To(ContainSubstring(`vendor = "gcp"`))
+	g.Expect(config).To(ContainSubstring(`credential-file-path = "/var/lib/tikv-kms/kms-master-key/credentials"`))
+	g.Expect(config).To(ContainSubstring("[security.encryption.previous-master-key]"))
+	set, err = getNewTiKVSetForTidbCluster(tc, cm)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(set.Spec.Template.Annotations[label.AnnTiKVEncryptionMasterKeyHash]).NotTo(Equal(annotation))
+	tikv := MapContainers(&set.Spec.Template.Spec)[v1alpha1.TiKVMemberType.String()]
+	// the credentials of the previous master key in AWS KMS are still required
+	g.Expect(tikv.Env).To(ContainElement(secretKeyEnv("AWS_SECRET_ACCESS_KEY", "aws-kms", kmsAWSSecretKey)))
+	g.Expect(tikv.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: tikvEncryptionMasterKeyVolName, ReadOnly: true, MountPath: "/var/lib/tikv-kms/kms-master-key"}))
+}
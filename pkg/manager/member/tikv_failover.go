@@ -15,6 +15,7 @@ package member
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
@@ -31,7 +32,7 @@ type tikvFailover struct {
 
 // NewTiKVFailover returns a tikv Failover
 func NewTiKVFailover(deps *controller.Dependencies) Failover {
-	return &tikvFailover{deps: deps}
+	return newSimulatedFailover(deps, v1alpha1.TiKVMemberType, &tikvFailover{deps: deps})
 }
 
 func (f *tikvFailover) isPodDesired(tc *v1alpha1.TidbCluster, podName string) bool {
@@ -74,7 +75,7 @@ func (f *tikvFailover) Failover(tc *v1alpha1.TidbCluster) error {
 			if tc.Spec.TiKV.MaxFailoverCount != nil && *tc.Spec.TiKV.MaxFailoverCount > 0 {
 				maxFailoverCount := *tc.Spec.TiKV.MaxFailoverCount
 				if len(tc.Status.TiKV.FailureStores) >= int(maxFailoverCount) {
-					klog.Warningf("%s/%s failure stores count reached the limit: %d", ns, tcName, tc.Spec.TiKV.MaxFailoverCount)
+					klog.Warningf("%s/%s failure stores count reached the limit: %d", ns, tcName, maxFailoverCount)
 					return nil
 				}
 				tc.Status.TiKV.FailureStores[storeID] = v1alpha1.TiKVFailureStore{
@@ -83,7 +84,18 @@ func (f *tikvFailover) Failover(tc *v1alpha1.TidbCluster) error {
 					CreatedAt: metav1.Now(),
 				}
 				msg := fmt.Sprintf("store[%s] is Down", store.ID)
-				f.deps.Recorder.Event(tc, corev1.EventTypeWarning, unHealthEventReason, fmt.Sprintf(unHealthEventMsgPattern, "tikv", podName, msg))
+				recordFailoverEvent(f.deps, tc, corev1.EventTypeWarning, unHealthEventReason, unHealthEventMsgPattern, "tikv", podName, msg)
+				failure := controller.FailoverNotification{
+					Component: v1alpha1.TiKVMemberType.String(),
+					PodName:   podName,
+					StoreID:   store.ID,
+				}
+				failure.Action = controller.FailoverMemberFailed
+				failure.Reason = fmt.Sprintf("store is Down for more than %s", f.deps.CLIConfig.TiKVFailoverPeriod)
+				notifyFailover(f.deps, tc, failure)
+				failure.Action = controller.FailoverReplacementCreated
+				failure.Reason = fmt.Sprintf("a replacement replica is added, failover replicas %d/%d", len(tc.Status.TiKV.FailureStores), maxFailoverCount)
+				notifyFailover(f.deps, tc, failure)
 			}
 		}
 	}
@@ -103,7 +115,14 @@ func (f *tikvFailover) RemoveUndesiredFailures(tc *v1alpha1.TidbCluster) {
 }
 
 func (f *tikvFailover) Recover(tc *v1alpha1.TidbCluster) {
+	podNames := make([]string, 0, len(tc.Status.TiKV.FailureStores))
+	for _, failureStore := range tc.Status.TiKV.FailureStores {
+		podNames = append(podNames, failureStore.PodName)
+	}
+	sort.Strings(podNames)
+	notifyStoresRecovered(f.deps, tc, v1alpha1.TiKVMemberType, tc.Status.TiKV.FailureStores)
 	tc.Status.TiKV.FailureStores = nil
+	f.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, failoverRecoveredEventReason, "tikv failure stores of pods %v are recovered, removing the failover replicas", podNames)
 	klog.Infof("TiKV recover: clear FailureStores, %s/%s", tc.GetNamespace(), tc.GetName())
 }
 
@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 )
 
@@ -302,3 +303,50 @@ func TestTiKVFailoverFailover(t *testing.T) {
 		})
 	}
 }
+
+func TestTiKVFailoverNotify(t *testing.T) {
+	g := NewGomegaWithT(t)
+	tc := newTidbClusterForPD()
+	tc.Spec.TiKV.Replicas = 3
+	tc.Spec.TiKV.MaxFailoverCount = pointer.Int32Ptr(3)
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+		"1": {
+			ID:                 "1",
+			State:              v1alpha1.TiKVStateDown,
+			PodName:            "tikv-1",
+			LastTransitionTime: metav1.Time{Time: time.Now().Add(-70 * time.Minute)},
+		},
+	}
+
+	fakeDeps := controller.NewFakeDependencies()
+	fakeDeps.CLIConfig.TiKVFailoverPeriod = 1 * time.Hour
+	recorder := record.NewFakeRecorder(10)
+	fakeDeps.Recorder = recorder
+	notifier := fakeDeps.FailoverNotifier.(*controller.FakeFailoverNotifier)
+	tikvFailover := &tikvFailover{deps: fakeDeps}
+
+	g.Expect(tikvFailover.Failover(tc)).To(Succeed())
+	events := collectEvents(recorder.Events)
+	g.Expect(events).To(HaveLen(1))
+	g.Expect(events[0]).To(ContainSubstring("Unhealthy tikv pod[tikv-1] is unhealthy, msg:store[1] is Down"))
+	notifications := notifier.Notifications()
+	g.Expect(notifications).To(HaveLen(2))
+	g.Expect(notifications[0].Action).To(Equal(controller.FailoverMemberFailed))
+	g.Expect(notifications[1].Action).To(Equal(controller.FailoverReplacementCreated))
+	for _, n := range notifications {
+		g.Expect(n.Namespace).To(Equal(tc.Namespace))
+		g.Expect(n.Cluster).To(Equal(tc.Name))
+		g.Expect(n.Component).To(Equal(v1alpha1.TiKVMemberType.String()))
+		g.Expect(n.PodName).To(Equal("tikv-1"))
+		g.Expect(n.StoreID).To(Equal("1"))
+	}
+
+	tikvFailover.Recover(tc)
+	events = collectEvents(recorder.Events)
+	g.Expect(events).To(HaveLen(1))
+	g.Expect(events[0]).To(ContainSubstring(failoverRecoveredEventReason))
+	notifications = notifier.Notifications()
+	g.Expect(notifications).To(HaveLen(3))
+	g.Expect(notifications[2].Action).To(Equal(controller.FailoverRecovered))
+	g.Expect(notifications[2].StoreID).To(Equal("1"))
+}
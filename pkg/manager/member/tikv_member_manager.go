@@ -33,6 +33,7 @@ import (
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/uuid"
@@ -82,6 +83,17 @@ type SvcConfig struct {
 	Headless   bool
 }
 
+// tikvSvcList are the services of tikv
+var tikvSvcList = []SvcConfig{
+	{
+		Name:       "peer",
+		Port:       20160,
+		Headless:   true,
+		SvcLabel:   func(l label.Label) label.Label { return l.TiKV() },
+		MemberName: controller.TiKVPeerMemberName,
+	},
+}
+
 // Sync fulfills the manager.Manager interface
 func (m *tikvMemberManager) Sync(tc *v1alpha1.TidbCluster) error {
 	// If tikv is not specified return
@@ -96,16 +108,7 @@ func (m *tikvMemberManager) Sync(tc *v1alpha1.TidbCluster) error {
 		return controller.RequeueErrorf("TidbCluster: [%s/%s], waiting for PD cluster running", ns, tcName)
 	}
 
-	svcList := []SvcConfig{
-		{
-			Name:       "peer",
-			Port:       20160,
-			Headless:   true,
-			SvcLabel:   func(l label.Label) label.Label { return l.TiKV() },
-			MemberName: controller.TiKVPeerMemberName,
-		},
-	}
-	for _, svc := range svcList {
+	for _, svc := range tikvSvcList {
 		if err := m.syncServiceForTidbCluster(tc, svc); err != nil {
 			return err
 		}
@@ -150,8 +153,7 @@ func (m *tikvMemberManager) syncServiceForTidbCluster(tc *v1alpha1.TidbCluster,
 			return err
 		}
 		svc.Spec.ClusterIP = oldSvc.Spec.ClusterIP
-		_, err = m.deps.ServiceControl.UpdateService(tc, &svc)
-		return err
+		return updateService(m.deps.ServiceControl, tc, newSvc, &svc)
 	}
 
 	return nil
@@ -178,18 +180,21 @@ func (m *tikvMemberManager) syncStatefulSetForTidbCluster(tc *v1alpha1.TidbClust
 		return nil
 	}
 
+	syncTiKVEncryptionStatus(tc)
+
 	cm, err := m.syncTiKVConfigMap(tc, oldSet)
 	if err != nil {
 		return err
 	}
+	syncConfigSyncedCondition(&tc.Status.TiKV.Conditions, oldSet, cm, controller.TiKVMemberName(tc.Name))
 
 	// Recover failed stores if any before generating desired statefulset
 	if len(tc.Status.TiKV.FailureStores) > 0 {
 		m.failover.RemoveUndesiredFailures(tc)
 	}
 	if len(tc.Status.TiKV.FailureStores) > 0 &&
-		tc.Spec.TiKV.RecoverFailover &&
-		shouldRecover(tc, label.TiKVLabelVal, m.deps.PodLister) {
+		shouldRecover(tc, label.TiKVLabelVal, m.deps.PodLister) &&
+		failoverRecoveryDue(tc.Spec.TiKV.FailoverRecovery, tc.Spec.TiKV.RecoverFailover, false, tikvHealthySince(tc)) {
 		m.failover.Recover(tc)
 	}
 
@@ -226,7 +231,7 @@ func (m *tikvMemberManager) syncStatefulSetForTidbCluster(tc *v1alpha1.TidbClust
 	// Perform failover logic if necessary. Note that this will only update
 	// TidbCluster status. The actual scaling performs in next sync loop (if a
 	// new replica needs to be added).
-	if m.deps.CLIConfig.AutoFailover && tc.Spec.TiKV.MaxFailoverCount != nil {
+	if m.deps.CLIConfig.AutoFailover && tc.Spec.TiKV.MaxFailoverCount != nil && !tc.Adopting() {
 		if tc.TiKVAllPodsStarted() && !tc.TiKVAllStoresReady() {
 			if err := m.failover.Failover(tc); err != nil {
 				return err
@@ -235,17 +240,21 @@ func (m *tikvMemberManager) syncStatefulSetForTidbCluster(tc *v1alpha1.TidbClust
 	}
 
 	if !templateEqual(newSet, oldSet) || tc.Status.TiKV.Phase == v1alpha1.UpgradePhase {
-		if err := m.upgrader.Upgrade(tc, oldSet, newSet); err != nil {
+		err := m.upgrader.Upgrade(tc, oldSet, newSet)
+		syncUpgradeProgress(&tc.Status.TiKV.UpgradeProgress, tc.Status.TiKV.Phase, tc.Status.TiKV.Conditions, newSet, &oldSet.Status, tc.TiKVImage())
+		if err != nil {
 			return err
 		}
+	} else {
+		tc.Status.TiKV.UpgradeProgress = nil
 	}
 
 	return UpdateStatefulSet(m.deps.StatefulSetControl, tc, newSet, oldSet)
 }
 
 func (m *tikvMemberManager) syncTiKVConfigMap(tc *v1alpha1.TidbCluster, set *apps.StatefulSet) (*corev1.ConfigMap, error) {
-	// For backward compatibility, only sync tidb configmap when .tikv.config is non-nil
-	if tc.Spec.TiKV.Config == nil {
+	// For backward compatibility, only sync tidb configmap when .tikv.config or .tikv.encryption is non-nil
+	if tc.Spec.TiKV.Config == nil && tc.Spec.TiKV.Encryption == nil {
 		return nil, nil
 	}
 	newCm, err := getTikVConfigMap(tc)
@@ -377,6 +386,9 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 			})
 		}
 	}
+	encryptionVols, encryptionVolMounts, encryptionEnv := tikvEncryptionVolumes(tc)
+	vols = append(vols, encryptionVols...)
+	volMounts = append(volMounts, encryptionVolMounts...)
 	// handle StorageVolumes and AdditionalVolumeMounts in ComponentSpec
 	storageVolMounts, additionalPVCs := util.BuildStorageVolumeAndVolumeMount(tc.Spec.TiKV.StorageVolumes, tc.Spec.TiKV.StorageClassName, v1alpha1.TiKVMemberType)
 	volMounts = append(volMounts, storageVolMounts...)
@@ -429,7 +441,9 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 	podLabels := util.CombineStringMap(stsLabels.Labels(), baseTiKVSpec.Labels())
 	setName := controller.TiKVMemberName(tcName)
 	podAnnotations := util.CombineStringMap(controller.AnnProm(20180), baseTiKVSpec.Annotations())
-	stsAnnotations := getStsAnnotations(tc.Annotations, label.TiKVLabelVal)
+	podAnnotations = util.CombineStringMap(podAnnotations, tlsCertAnnotations(tc, v1alpha1.TiKVMemberType))
+	podAnnotations = util.CombineStringMap(podAnnotations, tikvEncryptionAnnotations(tc))
+	stsAnnotations := getTidbClusterStsAnnotations(tc, label.TiKVLabelVal)
 	capacity := controller.TiKVCapacity(tc.Spec.TiKV.Limits)
 	headlessSvcName := controller.TiKVPeerMemberName(tcName)
 
@@ -498,6 +512,7 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 			Value: tc.Spec.Timezone,
 		},
 	}
+	env = append(env, encryptionEnv...)
 	tikvContainer := corev1.Container{
 		Name:            v1alpha1.TiKVMemberType.String(),
 		Image:           tc.TiKVImage(),
@@ -550,6 +565,7 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 	if podSpec.ServiceAccountName == "" {
 		podSpec.ServiceAccountName = tc.Spec.ServiceAccount
 	}
+	podAnnotations = applySecurityProfile(baseTiKVSpec, &podSpec, podAnnotations, v1alpha1.TiKVMemberType.String())
 
 	updateStrategy := apps.StatefulSetUpdateStrategy{}
 	if baseTiKVSpec.StatefulSetUpdateStrategy() == apps.OnDeleteStatefulSetStrategyType {
@@ -589,6 +605,7 @@ func getNewTiKVSetForTidbCluster(tc *v1alpha1.TidbCluster, cm *corev1.ConfigMap)
 	}
 
 	tikvset.Spec.VolumeClaimTemplates = append(tikvset.Spec.VolumeClaimTemplates, additionalPVCs...)
+	setVolumeClaimTemplatesAnnotations(tikvset, baseTiKVSpec.PVCAnnotations())
 	return tikvset, nil
 }
 
@@ -634,8 +651,7 @@ func transformTiKVConfigMap(srcStr string, tc *v1alpha1.TidbCluster) string {
 }
 
 func getTikVConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
-	config := tc.Spec.TiKV.Config
-	if config == nil {
+	if tc.Spec.TiKV.Config == nil && tc.Spec.TiKV.Encryption == nil {
 		return nil, nil
 	}
 
@@ -652,6 +668,9 @@ func getTikVConfigMap(tc *v1alpha1.TidbCluster) (*corev1.ConfigMap, error) {
 	if tc.HeterogeneousWithoutLocalPD() {
 		// TODO: for across k8s cluster, the start script do not support it now.
 		scriptModel.PDAddress = tc.Scheme() + "://" + controller.PDMemberName(tc.Spec.Cluster.Name) + ":2379"
+	} else if tc.ExternalPDOnly() {
+		scriptModel.PDAddress = strings.Join(tc.Spec.PDAddresses, ",")
+		scriptModel.ExternalPD = true
 	} else {
 		scriptModel.PDAddress = tc.Scheme() + "://${CLUSTER_NAME}-pd:2379"
 	}
@@ -797,7 +816,10 @@ func getTiKVStore(store *pdapi.StoreInfo) *v1alpha1.TiKVStore {
 		PodName:     podName,
 		IP:          ip,
 		LeaderCount: int32(store.Status.LeaderCount),
+		RegionCount: int32(store.Status.RegionCount),
 		State:       store.Store.StateName,
+		Capacity:    *resource.NewQuantity(int64(store.Status.Capacity), resource.BinarySI),
+		Available:   *resource.NewQuantity(int64(store.Status.Available), resource.BinarySI),
 	}
 }
 
@@ -827,7 +849,10 @@ func (m *tikvMemberManager) setStoreLabelsForTiKV(tc *v1alpha1.TidbCluster) (int
 		return setCount, err
 	}
 
-	storeLabels := append(config.Replication.LocationLabels, tc.Spec.TiKV.StoreLabels...)
+	// the config may be cached by the PD client, so it must not be modified
+	var storeLabels []string
+	storeLabels = append(storeLabels, config.Replication.LocationLabels...)
+	storeLabels = append(storeLabels, tc.Spec.TiKV.StoreLabels...)
 	if storeLabels == nil {
 		return setCount, nil
 	}
@@ -22,16 +22,25 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/tikvapi"
 	"github.com/pingcap/tidb-operator/pkg/util"
 	apps "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/klog"
 	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
 )
 
+const (
+	// tikvScaleInMaxSnapshotBacklog is the max number of the snapshots received or applied by a remaining store
+	// when scaling in TiKV, which are likely to be generated by the previous scaling in
+	tikvScaleInMaxSnapshotBacklog = 16
+	// tikvScaleInMaxDiskUsageRatio is the max disk usage ratio of the remaining stores after the data of
+	// the store to be deleted is moved to them when scaling in TiKV
+	tikvScaleInMaxDiskUsageRatio = 0.8
+)
+
 type tikvScaler struct {
 	generalScaler
 }
@@ -59,7 +68,7 @@ func (s *tikvScaler) ScaleOut(meta metav1.Object, oldSet *apps.StatefulSet, newS
 	if !ok {
 		return fmt.Errorf("cluster[%s/%s] can't conver to runtime.Object", meta.GetNamespace(), meta.GetName())
 	}
-	klog.Infof("scaling out tikv statefulset %s/%s, ordinal: %d (replicas: %d, delete slots: %v)", oldSet.Namespace, oldSet.Name, ordinal, replicas, deleteSlots.List())
+	componentLog(meta, v1alpha1.TiKVMemberType).Info("Scaling out statefulset", "statefulset", oldSet.Name, "ordinal", ordinal, "replicas", replicas, "deleteSlots", deleteSlots.List())
 	var pvcName string
 	switch meta.(type) {
 	case *v1alpha1.TidbCluster:
@@ -87,8 +96,12 @@ func (s *tikvScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSe
 	// we can only remove one member at a time when scaling in
 	_, ordinal, replicas, deleteSlots := scaleOne(oldSet, newSet)
 	resetReplicas(newSet, oldSet)
+	if err := refuseIfAdopting(meta, "scaling in tikv"); err != nil {
+		return err
+	}
 
-	klog.Infof("scaling in tikv statefulset %s/%s, ordinal: %d (replicas: %d, delete slots: %v)", oldSet.Namespace, oldSet.Name, ordinal, replicas, deleteSlots.List())
+	log := componentLog(meta, v1alpha1.TiKVMemberType)
+	log.Info("Scaling in statefulset", "statefulset", oldSet.Name, "ordinal", ordinal, "replicas", replicas, "deleteSlots", deleteSlots.List())
 	// We need remove member from cluster before reducing statefulset replicas
 	var podName string
 
@@ -109,6 +122,10 @@ func (s *tikvScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSe
 		return err
 	}
 
+	if pass, err := s.preCheckStoreStatus(tc, podName); !pass {
+		return err
+	}
+
 	if s.deps.CLIConfig.PodWebhookEnabled {
 		setReplicasAndDeleteSlots(newSet, replicas, deleteSlots)
 		return nil
@@ -124,10 +141,15 @@ func (s *tikvScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSe
 			}
 			if state != v1alpha1.TiKVStateOffline {
 				if err := controller.GetPDClient(s.deps.PDControl, tc).DeleteStore(id); err != nil {
-					klog.Errorf("tikvScaler.ScaleIn: failed to delete store %d, %v", id, err)
+					log.Error(err, "Failed to delete store", "store", id, "pod", podName)
 					return err
 				}
-				klog.Infof("tikvScaler.ScaleIn: delete store %d for tikv %s/%s successfully", id, ns, podName)
+				log.Info("Deleted store", "store", id, "pod", podName)
+			} else if count, err := transferLeadersOutOfStore(s.deps, tc, id); err != nil {
+				// accelerate the leader eviction of the offline store, which is best effort
+				log.Warning("Failed to transfer leaders out of store", "store", id, "pod", podName, "err", err)
+			} else if count > 0 {
+				log.Info("Transferred leaders out of store", "leaders", count, "store", id, "pod", podName)
 			}
 			return controller.RequeueErrorf("TiKV %s/%s store %d is still in cluster, state: %s", ns, podName, id, state)
 		}
@@ -142,7 +164,7 @@ func (s *tikvScaler) ScaleIn(meta metav1.Object, oldSet *apps.StatefulSet, newSe
 			}
 
 			// TODO: double check if store is really not in Up/Offline/Down state
-			klog.Infof("TiKV %s/%s store %d becomes tombstone", ns, podName, id)
+			log.Info("Store becomes tombstone", "store", id, "pod", podName)
 
 			pvcs, err := util.ResolvePVCFromPod(pod, s.deps.PVCLister)
 			if err != nil {
@@ -233,7 +255,7 @@ func (s *tikvScaler) SyncAutoScalerAnn(meta metav1.Object, actual *apps.Stateful
 
 func (s *tikvScaler) preCheckUpStores(tc *v1alpha1.TidbCluster, podName string) (bool, error) {
 	if !tc.TiKVBootStrapped() {
-		klog.Infof("TiKV of Cluster %s/%s is not bootstrapped yet, skip pre check when scale in TiKV", tc.Namespace, tc.Name)
+		componentLog(tc, v1alpha1.TiKVMemberType).Info("TiKV is not bootstrapped yet, skip pre check when scaling in", "pod", podName)
 		return true, nil
 	}
 
@@ -269,13 +291,13 @@ func (s *tikvScaler) preCheckUpStores(tc *v1alpha1.TidbCluster, podName string)
 	maxReplicas := *(config.Replication.MaxReplicas)
 	if upNumber < int(maxReplicas) {
 		errMsg := fmt.Sprintf("the number of stores in Up state of TidbCluster [%s/%s] is %d, less than MaxReplicas in PD configuration(%d), can't scale in TiKV, podname %s ", tc.GetNamespace(), tc.GetName(), upNumber, maxReplicas, podName)
-		klog.Error(errMsg)
+		componentLog(tc, v1alpha1.TiKVMemberType).Error(nil, errMsg)
 		s.deps.Recorder.Event(tc, v1.EventTypeWarning, "FailedScaleIn", errMsg)
 		return false, nil
 	} else if upNumber == int(maxReplicas) {
 		if storeState == v1alpha1.TiKVStateUp {
 			errMsg := fmt.Sprintf("can't scale in TiKV of TidbCluster [%s/%s], cause the number of up stores is equal to MaxReplicas in PD configuration(%d), and the store in Pod %s which is going to be deleted is up too", tc.GetNamespace(), tc.GetName(), maxReplicas, podName)
-			klog.Error(errMsg)
+			componentLog(tc, v1alpha1.TiKVMemberType).Error(nil, errMsg)
 			s.deps.Recorder.Event(tc, v1.EventTypeWarning, "FailedScaleIn", errMsg)
 			return false, nil
 		}
@@ -313,3 +335,63 @@ func (s *fakeTiKVScaler) ScaleIn(_ metav1.Object, oldSet *apps.StatefulSet, newS
 func (s *fakeTiKVScaler) SyncAutoScalerAnn(_ metav1.Object, actual *apps.StatefulSet) error {
 	return nil
 }
+
+// preCheckStoreStatus checks the store-level signals read from the TiKV servers before the store of the pod
+// is deleted, which are real-time during incidents compared with the views of PD reported by heartbeats.
+// Scaling in is deferred if the remaining stores are busy with snapshots, and refused if they do not have
+// enough disk space to hold the data of the store. The check is best effort and skipped if any signal is
+// not available, since PD still refuses to delete the store if there are not enough stores.
+func (s *tikvScaler) preCheckStoreStatus(tc *v1alpha1.TidbCluster, podName string) (bool, error) {
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	var removed *v1alpha1.TiKVStore
+	for _, store := range tc.Status.TiKV.Stores {
+		if store.PodName == podName && store.State == v1alpha1.TiKVStateUp {
+			store := store
+			removed = &store
+		}
+	}
+	// the store is not in the cluster yet, or it is being deleted already
+	if removed == nil {
+		return true, nil
+	}
+
+	tlsEnabled := tc.IsTLSClusterEnabled()
+	getStoreStatus := func(podName string) *tikvapi.StoreStatus {
+		status, err := s.deps.TiKVControl.GetTiKVPodClient(ns, tcName, podName, tlsEnabled).GetStoreStatus()
+		if err != nil {
+			componentLog(tc, v1alpha1.TiKVMemberType).Warning("Failed to get store status, skip checking store status", "pod", podName, "err", err)
+			return nil
+		}
+		return status
+	}
+
+	removedStatus := getStoreStatus(podName)
+	if removedStatus == nil {
+		return true, nil
+	}
+	var capacity, used int64
+	for _, store := range tc.Status.TiKV.Stores {
+		if store.PodName == podName || store.State != v1alpha1.TiKVStateUp {
+			continue
+		}
+		status := getStoreStatus(store.PodName)
+		if status == nil {
+			return true, nil
+		}
+		if status.SnapshotBacklog() > tikvScaleInMaxSnapshotBacklog {
+			return false, controller.RequeueErrorf("tikv %s/%s store %s is handling %d snapshots, wait for it before scaling in tikv %s", ns, store.PodName, store.ID, status.SnapshotBacklog(), podName)
+		}
+		capacity += status.CapacityBytes
+		used += status.UsedBytes
+	}
+
+	if capacity > 0 && float64(used+removedStatus.UsedBytes) > float64(capacity)*tikvScaleInMaxDiskUsageRatio {
+		errMsg := fmt.Sprintf("can't scale in TiKV of TidbCluster [%s/%s], cause the disk usage of the remaining stores would exceed %.0f%% after the data (%d bytes) of the store in Pod %s is moved to them (used: %d bytes, capacity: %d bytes)",
+			ns, tcName, tikvScaleInMaxDiskUsageRatio*100, removedStatus.UsedBytes, podName, used, capacity)
+		componentLog(tc, v1alpha1.TiKVMemberType).Error(nil, errMsg)
+		s.deps.Recorder.Event(tc, v1.EventTypeWarning, "FailedScaleIn", errMsg)
+		return false, nil
+	}
+	return true, nil
+}
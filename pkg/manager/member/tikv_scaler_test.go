@@ -24,6 +24,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/label"
 	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/tikvapi"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -543,6 +544,88 @@ func TestTiKVScalerScaleIn(t *testing.T) {
 	}
 }
 
+func TestTiKVScalerPreCheckStoreStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+	type testcase struct {
+		name        string
+		storeFun    func(tc *v1alpha1.TidbCluster)
+		statuses    map[string]*tikvapi.StoreStatus
+		pass        bool
+		errExpectFn func(*GomegaWithT, error)
+	}
+
+	newStatus := func(used, capacity int64, backlog int) *tikvapi.StoreStatus {
+		return &tikvapi.StoreStatus{UsedBytes: used, CapacityBytes: capacity, SnapshotsApplying: backlog}
+	}
+	testFn := func(test testcase) {
+		t.Log(test.name)
+		tc := newTidbClusterForPD()
+		test.storeFun(tc)
+		scaler, _, _, _, _ := newFakeTiKVScaler()
+		tikvControl := scaler.deps.TiKVControl.(*tikvapi.FakeTiKVControl)
+		for podName, status := range test.statuses {
+			status := status
+			client := controller.NewFakeTiKVClient(tikvControl, tc, podName)
+			client.AddReaction(tikvapi.GetStoreStatusActionType, func(action *tikvapi.Action) (interface{}, error) {
+				return status, nil
+			})
+		}
+
+		pass, err := scaler.preCheckStoreStatus(tc, ordinalPodName(v1alpha1.TiKVMemberType, tc.GetName(), 4))
+		g.Expect(pass).To(Equal(test.pass))
+		test.errExpectFn(g, err)
+	}
+
+	allStatuses := func(removed *tikvapi.StoreStatus, remaining *tikvapi.StoreStatus) map[string]*tikvapi.StoreStatus {
+		statuses := map[string]*tikvapi.StoreStatus{}
+		for i := int32(0); i < 4; i++ {
+			statuses[ordinalPodName(v1alpha1.TiKVMemberType, "test", i)] = remaining
+		}
+		statuses[ordinalPodName(v1alpha1.TiKVMemberType, "test", 4)] = removed
+		return statuses
+	}
+	tests := []testcase{
+		{
+			name:        "enough disk space",
+			storeFun:    normalStoreFun,
+			statuses:    allStatuses(newStatus(100, 1000, 0), newStatus(100, 1000, 0)),
+			pass:        true,
+			errExpectFn: errExpectNil,
+		},
+		{
+			name:        "the store is not up",
+			storeFun:    notReadyStoreFun,
+			statuses:    allStatuses(newStatus(100, 1000, 0), newStatus(900, 1000, 0)),
+			pass:        true,
+			errExpectFn: errExpectNil,
+		},
+		{
+			name:        "store status is not available",
+			storeFun:    normalStoreFun,
+			statuses:    map[string]*tikvapi.StoreStatus{ordinalPodName(v1alpha1.TiKVMemberType, "test", 4): newStatus(100, 1000, 0)},
+			pass:        true,
+			errExpectFn: errExpectNil,
+		},
+		{
+			name:        "remaining stores are busy with snapshots",
+			storeFun:    normalStoreFun,
+			statuses:    allStatuses(newStatus(100, 1000, 0), newStatus(100, 1000, tikvScaleInMaxSnapshotBacklog+1)),
+			pass:        false,
+			errExpectFn: errExpectRequeue,
+		},
+		{
+			name:        "not enough disk space",
+			storeFun:    normalStoreFun,
+			statuses:    allStatuses(newStatus(800, 1000, 0), newStatus(700, 1000, 0)),
+			pass:        false,
+			errExpectFn: errExpectNil,
+		},
+	}
+	for _, test := range tests {
+		testFn(test)
+	}
+}
+
 func newFakeTiKVScaler(resyncDuration ...time.Duration) (*tikvScaler, *pdapi.FakePDControl, cache.Indexer, cache.Indexer, *controller.FakePVCControl) {
 	fakeDeps := controller.NewFakeDependencies()
 	if len(resyncDuration) > 0 {
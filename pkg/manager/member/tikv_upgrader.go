@@ -21,7 +21,9 @@ import (
 	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
 	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -32,6 +34,8 @@ import (
 const (
 	// EvictLeaderBeginTime is the key of evict Leader begin time
 	EvictLeaderBeginTime = "evictLeaderBeginTime"
+	// tikvLeaderTransferBatchSize is the max number of transfer-leader operators added for a store in a sync
+	tikvLeaderTransferBatchSize = 64
 )
 
 type TiKVUpgrader interface {
@@ -84,6 +88,7 @@ func (u *tikvUpgrader) Upgrade(meta metav1.Object, oldSet *apps.StatefulSet, new
 	}
 
 	status.Phase = v1alpha1.UpgradePhase
+	setUpgradingCondition(&status.Conditions, utiltidbcluster.RollingUpdate, "")
 	if !templateEqual(newSet, oldSet) {
 		return nil
 	}
@@ -124,9 +129,11 @@ func (u *tikvUpgrader) Upgrade(meta metav1.Object, oldSet *apps.StatefulSet, new
 		if revision == status.StatefulSet.UpdateRevision {
 
 			if !podutil.IsPodReady(pod) {
+				setUpgradingCondition(&status.Conditions, utiltidbcluster.WaitingForPodReady, fmt.Sprintf("upgraded pod %s is not ready", podName))
 				return controller.RequeueErrorf("tidbcluster: [%s/%s]'s upgraded tikv pod: [%s] is not ready", ns, tcName, podName)
 			}
 			if store.State != v1alpha1.TiKVStateUp {
+				setUpgradingCondition(&status.Conditions, utiltidbcluster.WaitingForPodReady, fmt.Sprintf("store %s of upgraded pod %s is %s", store.ID, podName, store.State))
 				return controller.RequeueErrorf("tidbcluster: [%s/%s]'s upgraded tikv pod: [%s] is not all ready", ns, tcName, podName)
 			}
 
@@ -145,6 +152,7 @@ func (u *tikvUpgrader) Upgrade(meta metav1.Object, oldSet *apps.StatefulSet, new
 		}
 
 		if u.deps.CLIConfig.PodWebhookEnabled {
+			recordPodUpgrade(u.deps, tc, v1alpha1.TiKVMemberType, newSet, status.StatefulSet, i)
 			setUpgradePartition(newSet, i)
 			return nil
 		}
@@ -171,15 +179,25 @@ func (u *tikvUpgrader) upgradeTiKVPod(tc *v1alpha1.TidbCluster, ordinal int32, n
 				return err
 			}
 			_, evicting := upgradePod.Annotations[EvictLeaderBeginTime]
+			setUpgradingCondition(&tc.Status.TiKV.Conditions, utiltidbcluster.WaitingForLeaderEviction,
+				fmt.Sprintf("evicting leaders from store %d of pod %s", storeID, upgradePodName))
 			if !evicting {
 				return u.beginEvictLeader(tc, storeID, upgradePod)
 			}
 
 			if u.readyToUpgrade(upgradePod, tc) {
+				recordPodUpgrade(u.deps, tc, v1alpha1.TiKVMemberType, newSet, tc.Status.TiKV.StatefulSet, ordinal)
 				setUpgradePartition(newSet, ordinal)
 				return nil
 			}
 
+			// accelerate the leader eviction, which is best effort
+			if count, err := transferLeadersOutOfStore(u.deps, tc, storeID); err != nil {
+				klog.Warningf("tikv upgrader: failed to transfer leaders out of store %d, %s/%s, %v", storeID, ns, upgradePodName, err)
+			} else if count > 0 {
+				klog.Infof("tikv upgrader: transfer %d leaders out of store %d, %s/%s", count, storeID, ns, upgradePodName)
+			}
+
 			return controller.RequeueErrorf("tidbcluster: [%s/%s]'s tikv pod: [%s] is evicting leader", ns, tcName, upgradePodName)
 		}
 	}
@@ -229,6 +247,7 @@ func (u *tikvUpgrader) beginEvictLeader(tc *v1alpha1.TidbCluster, storeID uint64
 		return err
 	}
 	klog.Infof("tikv upgrader: begin evict leader: %d, %s/%s successfully", storeID, ns, podName)
+	u.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, LeaderEvictionReason, "evicting region leaders from store %d of pod %s before upgrade", storeID, podName)
 	if pod.Annotations == nil {
 		pod.Annotations = map[string]string{}
 	}
@@ -245,6 +264,76 @@ func (u *tikvUpgrader) beginEvictLeader(tc *v1alpha1.TidbCluster, storeID uint64
 	return nil
 }
 
+// transferLeadersOutOfStore accelerates the leader eviction of the store by adding transfer-leader
+// operators for the regions whose leaders are on the store, instead of waiting for the schedulers of
+// PD to move them. The operators are paced by the leader count of the store, at most
+// tikvLeaderTransferBatchSize operators are added in a sync, and the leaders are transferred to the
+// Up stores with the fewest leaders. The regions which have no voter on the other Up stores are
+// scattered, so their leaders can be transferred in the following syncs.
+// It returns the number of the operators added.
+func transferLeadersOutOfStore(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, storeID uint64) (int, error) {
+	pdClient := controller.GetPDClient(deps.PDControl, tc)
+	leaderCount, err := pdClient.GetStoreLeaderCount(storeID)
+	if err != nil {
+		return 0, err
+	}
+	limit := tikvLeaderTransferBatchSize
+	if leaderCount < limit {
+		limit = leaderCount
+	}
+	if limit <= 0 {
+		return 0, nil
+	}
+
+	// the leader counts of the stores which can take over the leaders
+	targets := map[uint64]int32{}
+	for _, store := range tc.Status.TiKV.Stores {
+		id, err := strconv.ParseUint(store.ID, 10, 64)
+		if err != nil || id == storeID || store.State != v1alpha1.TiKVStateUp {
+			continue
+		}
+		targets[id] = store.LeaderCount
+	}
+	regions, err := pdClient.GetStoreRegions(storeID)
+	if err != nil {
+		return 0, err
+	}
+
+	added := 0
+	for _, region := range regions.Regions {
+		if added >= limit {
+			break
+		}
+		if region.Leader == nil || region.Leader.StoreID != storeID {
+			continue
+		}
+		var target uint64
+		for _, peer := range region.Peers {
+			if peer.IsLearner {
+				continue
+			}
+			if count, ok := targets[peer.StoreID]; ok && (target == 0 || count < targets[target]) {
+				target = peer.StoreID
+			}
+		}
+		if target == 0 {
+			err = pdClient.ScatterRegion(region.ID)
+		} else {
+			err = pdClient.TransferRegionLeader(region.ID, target)
+		}
+		if err != nil {
+			// the operator is rejected if the region has a running operator, e.g. added by the schedulers
+			klog.V(4).Infof("failed to add operator for region %d of store %d, %v", region.ID, storeID, err)
+			continue
+		}
+		if target != 0 {
+			targets[target]++
+		}
+		added++
+	}
+	return added, nil
+}
+
 func endEvictLeader(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, ordinal int32) error {
 	store := getStoreByOrdinal(tc.GetName(), tc.Status.TiKV, ordinal)
 	if store == nil {
@@ -268,6 +357,8 @@ func endEvictLeaderbyStoreID(deps *controller.Dependencies, tc *v1alpha1.TidbClu
 
 	if tc.HeterogeneousWithoutLocalPD() {
 		err = deps.PDControl.GetPDClient(pdapi.Namespace(tc.Spec.Cluster.Namespace), tc.Spec.Cluster.Name, tc.IsTLSClusterEnabled()).EndEvictLeader(storeID)
+	} else if tc.ExternalPDOnly() {
+		err = controller.GetPDClient(deps.PDControl, tc).EndEvictLeader(storeID)
 	} else {
 		err = deps.PDControl.GetPDClient(pdapi.Namespace(tc.GetNamespace()), tc.GetName(), tc.IsTLSClusterEnabled()).EndEvictLeader(storeID)
 	}
@@ -280,6 +371,47 @@ func endEvictLeaderbyStoreID(deps *controller.Dependencies, tc *v1alpha1.TidbClu
 	return nil
 }
 
+// endEvictLeaderOfRecreatedStores removes the evict leader schedulers of the stores recorded in the
+// annotation annKey of the PVCs once the stores are up again and their pods are ready, then removes
+// the annotation.
+func endEvictLeaderOfRecreatedStores(deps *controller.Dependencies, tc *v1alpha1.TidbCluster, annKey string) error {
+	selector, err := label.New().Instance(tc.GetInstanceName()).Selector()
+	if err != nil {
+		return err
+	}
+	pvcs, err := deps.PVCLister.PersistentVolumeClaims(tc.GetNamespace()).List(selector)
+	if err != nil {
+		return err
+	}
+	for _, pvc := range pvcs {
+		storeID, ok := pvc.Annotations[annKey]
+		if !ok {
+			continue
+		}
+		store, ok := tc.Status.TiKV.Stores[storeID]
+		if !ok || store.State != v1alpha1.TiKVStateUp {
+			continue
+		}
+		pod, err := deps.PodLister.Pods(tc.GetNamespace()).Get(store.PodName)
+		if err != nil || !podutil.IsPodReady(pod) {
+			continue
+		}
+		id, err := strconv.ParseUint(storeID, 10, 64)
+		if err != nil {
+			return err
+		}
+		if err := endEvictLeaderbyStoreID(deps, tc, id); err != nil {
+			return err
+		}
+		pvc = pvc.DeepCopy()
+		delete(pvc.Annotations, annKey)
+		if _, err := deps.PVCControl.UpdatePVC(tc, pvc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func getStoreByOrdinal(name string, status v1alpha1.TiKVStatus, ordinal int32) *v1alpha1.TiKVStore {
 	podName := TikvPodName(name, ordinal)
 	for _, store := range status.Stores {
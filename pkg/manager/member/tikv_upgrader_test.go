@@ -25,6 +25,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/label"
 	"github.com/pingcap/tidb-operator/pkg/pdapi"
 	"github.com/pingcap/tidb-operator/pkg/tikvapi"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -421,6 +422,9 @@ func TestTiKVUpgraderUpgrade(t *testing.T) {
 				g.Expect(*newSet.Spec.UpdateStrategy.RollingUpdate.Partition).To(Equal(int32(2)))
 				_, exist := pods[TikvPodName(upgradeTcName, 1)].Annotations[EvictLeaderBeginTime]
 				g.Expect(exist).To(BeTrue())
+				cond := utiltidbcluster.GetComponentCondition(tc.Status.TiKV.Conditions, v1alpha1.ComponentUpgrading)
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Reason).To(Equal(utiltidbcluster.WaitingForLeaderEviction))
 			},
 		},
 		{
@@ -798,3 +802,63 @@ func getTiKVPods(set *apps.StatefulSet) []*corev1.Pod {
 	}
 	return pods
 }
+
+func TestTransferLeadersOutOfStore(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	deps := controller.NewFakeDependencies()
+	tc := newTidbClusterForTiKVUpgrader()
+	tc.Status.TiKV.Stores = map[string]v1alpha1.TiKVStore{
+		"1": {ID: "1", State: v1alpha1.TiKVStateUp, LeaderCount: 10},
+		"2": {ID: "2", State: v1alpha1.TiKVStateUp, LeaderCount: 5},
+		"3": {ID: "3", State: v1alpha1.TiKVStateUp, LeaderCount: 4},
+		"4": {ID: "4", State: v1alpha1.TiKVStateDown},
+	}
+	pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+	leaderCount := 4
+	pdClient.AddReaction(pdapi.GetStoreLeaderCountActionType, func(action *pdapi.Action) (interface{}, error) {
+		return leaderCount, nil
+	})
+	peers := func(storeIDs ...uint64) []*pdapi.RegionPeer {
+		var peers []*pdapi.RegionPeer
+		for _, id := range storeIDs {
+			peers = append(peers, &pdapi.RegionPeer{StoreID: id})
+		}
+		return peers
+	}
+	pdClient.AddReaction(pdapi.GetStoreRegionsActionType, func(action *pdapi.Action) (interface{}, error) {
+		return &pdapi.RegionsInfo{Regions: []*pdapi.RegionInfo{
+			{ID: 100, Leader: &pdapi.RegionPeer{StoreID: 2}, Peers: peers(1, 2, 3)},
+			{ID: 101, Leader: &pdapi.RegionPeer{StoreID: 1}, Peers: peers(1, 2, 3)},
+			{ID: 102, Leader: &pdapi.RegionPeer{StoreID: 1}, Peers: peers(1, 2, 3)},
+			{ID: 103, Leader: &pdapi.RegionPeer{StoreID: 1}, Peers: peers(1, 2, 3)},
+			{ID: 104, Leader: &pdapi.RegionPeer{StoreID: 1}, Peers: append(peers(1, 4), &pdapi.RegionPeer{StoreID: 3, IsLearner: true})},
+			{ID: 105, Leader: &pdapi.RegionPeer{StoreID: 1}, Peers: peers(1, 2, 3)},
+		}}, nil
+	})
+	transferred := map[uint64]uint64{}
+	pdClient.AddReaction(pdapi.TransferRegionLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		if action.ID == 102 {
+			return nil, fmt.Errorf("region has operator")
+		}
+		transferred[action.ID] = action.ToStoreID
+		return nil, nil
+	})
+	var scattered []uint64
+	pdClient.AddReaction(pdapi.ScatterRegionActionType, func(action *pdapi.Action) (interface{}, error) {
+		scattered = append(scattered, action.ID)
+		return nil, nil
+	})
+
+	// the leaders are transferred to the Up stores with the fewest leaders, and paced by the leader count
+	count, err := transferLeadersOutOfStore(deps, tc, 1)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(count).To(Equal(4))
+	g.Expect(transferred).To(Equal(map[uint64]uint64{101: 3, 103: 2, 105: 3}))
+	g.Expect(scattered).To(Equal([]uint64{104}))
+
+	leaderCount = 0
+	count, err = transferLeadersOutOfStore(deps, tc, 1)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(count).To(Equal(0))
+}
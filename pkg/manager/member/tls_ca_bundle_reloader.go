@@ -0,0 +1,293 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/pingcap/tidb-operator/pkg/util/crypto"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+const (
+	// TLSCABundleUpdatedReason is the reason of the events emitted when the CA bundle is propagated into the cluster secrets
+	TLSCABundleUpdatedReason = "TLSCABundleUpdated"
+	// TLSCABundleTrustedReason is the reason of the events emitted when all the members trust the CA bundle
+	TLSCABundleTrustedReason = "TLSCABundleTrusted"
+	// TLSCARemovalDeferredReason is the reason of the events emitted when the CAs removed from the CA bundle
+	// are kept in the cluster secrets until all the members trust the CA bundle
+	TLSCARemovalDeferredReason = "TLSCARemovalDeferred"
+)
+
+// tlsCAHotReloadMemberTypes are the components reloading the CA bundle online once the mounted secrets are updated
+var tlsCAHotReloadMemberTypes = sets.NewString(
+	v1alpha1.PDMemberType.String(),
+	v1alpha1.TiKVMemberType.String(),
+	v1alpha1.TiDBMemberType.String(),
+)
+
+// tlsCABundleReloader propagates the CA bundle in the secret of `spec.tlsCluster.caBundle` into the ca.crt
+// of the cluster secrets of the TidbCluster, including the client secret used by the operator.
+//
+// PD, TiKV and TiDB reload the CA bundle online once the kubelet updates the mounted secrets, and they are
+// considered to trust it after the reload delay. The pods of the other components are rolling restarted by
+// the pod annotation `tidb.pingcap.com/tls-ca-bundle-hash`. A CA removed from the CA bundle is kept in the
+// cluster secrets until all the members trust the CA bundle, so that rotating a CA by appending the new one
+// and removing the old one does not break the connections between the members. The state is tracked in
+// `status.tlsCABundle`.
+//
+// It must be synced before the member managers, so that the annotation is applied in the same sync.
+type tlsCABundleReloader struct {
+	deps *controller.Dependencies
+}
+
+// NewTLSCABundleReloader returns a reloader of the CA bundle of the cluster secrets
+func NewTLSCABundleReloader(deps *controller.Dependencies) manager.Manager {
+	return &tlsCABundleReloader{
+		deps: deps,
+	}
+}
+
+func (r *tlsCABundleReloader) Sync(tc *v1alpha1.TidbCluster) error {
+	if !tc.TLSCABundleEnabled() {
+		return nil
+	}
+
+	ns := tc.GetNamespace()
+	sourceName := tc.Spec.TLSCluster.CABundle.SecretName
+	// the secrets are got from the apiserver, as the cache may be stale just after they are updated
+	secretClient := r.deps.KubeClientset.CoreV1().Secrets(ns)
+	source, err := secretClient.Get(sourceName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("tlsCABundleReloader: failed to get secret %s/%s for tc %s, error: %v", ns, sourceName, tc.GetName(), err)
+	}
+	desired, err := crypto.DecodeCertificates(source.Data[corev1.ServiceAccountRootCAKey])
+	if err != nil {
+		return fmt.Errorf("tlsCABundleReloader: failed to decode the CA bundle in secret %s/%s, error: %v", ns, sourceName, err)
+	}
+
+	secretNames := []string{util.ClusterClientTLSSecretName(tc.GetName())}
+	for _, memberType := range tlsCertComponents(tc) {
+		secretNames = append(secretNames, util.ClusterTLSSecretName(tc.GetName(), memberType.String()))
+	}
+	var secrets []*corev1.Secret
+	for _, name := range secretNames {
+		secret, err := secretClient.Get(name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			klog.Warningf("tlsCABundleReloader: secret %s/%s for tc %s is not found, skip propagating the CA bundle into it", ns, name, tc.GetName())
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("tlsCABundleReloader: failed to get secret %s/%s for tc %s, error: %v", ns, name, tc.GetName(), err)
+		}
+		secrets = append(secrets, secret)
+	}
+
+	status := tc.Status.TLSCABundle
+	cas := uniqueCAs(desired)
+	if status != nil && status.Phase != v1alpha1.TLSCABundleTrusted {
+		// keep the CAs in the cluster secrets until all the members trust the CA bundle
+		if kept := keptCAs(status, secrets, cas); len(kept) > 0 {
+			klog.Infof("tlsCABundleReloader: %d CAs removed from secret %s/%s are kept until all the members of tc %s trust the CA bundle", len(kept), ns, sourceName, tc.GetName())
+			r.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, TLSCARemovalDeferredReason, "%d CAs removed from secret %s are kept until all the members trust the CA bundle, pending components: %v", len(kept), sourceName, status.PendingComponents)
+			cas = append(kept, cas...)
+		}
+	}
+	data := encodeCABundle(cas)
+	hash := tlsCertHash(data)
+
+	var errs []error
+	propagated := false
+	for _, secret := range secrets {
+		if bytes.Equal(secret.Data[corev1.ServiceAccountRootCAKey], data) {
+			continue
+		}
+		updated := secret.DeepCopy()
+		if updated.Data == nil {
+			updated.Data = map[string][]byte{}
+		}
+		updated.Data[corev1.ServiceAccountRootCAKey] = data
+		if _, err := secretClient.Update(updated); err != nil {
+			errs = append(errs, fmt.Errorf("tlsCABundleReloader: failed to update secret %s/%s, error: %v", ns, secret.Name, err))
+			continue
+		}
+		propagated = true
+		klog.Infof("tlsCABundleReloader: the CA bundle in secret %s/%s is propagated into secret %s", ns, sourceName, secret.Name)
+	}
+	if len(errs) > 0 && !propagated {
+		return errorutils.NewAggregate(errs)
+	}
+
+	switch {
+	case status == nil && !propagated:
+		// the cluster secrets are created with the CA bundle, so no member needs to reload it
+		status = &v1alpha1.TLSCABundleStatus{
+			Phase: v1alpha1.TLSCABundleTrusted,
+			Hash:  hash,
+			CAs:   caFingerprints(cas),
+		}
+	case propagated || status.Hash != hash:
+		now := metav1.Now()
+		status = &v1alpha1.TLSCABundleStatus{
+			Phase:               v1alpha1.TLSCABundlePropagating,
+			Hash:                hash,
+			CAs:                 caFingerprints(cas),
+			RestartHash:         hash,
+			LastPropagationTime: &now,
+		}
+		r.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, TLSCABundleUpdatedReason, "the CA bundle in secret %s is propagated into the cluster secrets", sourceName)
+	}
+
+	if status.Phase == v1alpha1.TLSCABundlePropagating {
+		pending, err := r.pendingComponents(tc, status)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			status.PendingComponents = pending
+			if len(pending) == 0 {
+				status.Phase = v1alpha1.TLSCABundleTrusted
+				klog.Infof("tlsCABundleReloader: all the members of tc %s/%s trust the CA bundle in secret %s", ns, tc.GetName(), sourceName)
+				r.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, TLSCABundleTrustedReason, "all the members trust the CA bundle in secret %s", sourceName)
+			}
+		}
+	}
+	tc.Status.TLSCABundle = status
+	return errorutils.NewAggregate(errs)
+}
+
+// pendingComponents returns the components whose members are not confirmed to trust the propagated CA bundle
+func (r *tlsCABundleReloader) pendingComponents(tc *v1alpha1.TidbCluster, status *v1alpha1.TLSCABundleStatus) ([]string, error) {
+	var pending []string
+	for _, memberType := range tlsCertComponents(tc) {
+		selector, err := label.New().Instance(tc.GetInstanceName()).Component(memberType.String()).Selector()
+		if err != nil {
+			return nil, err
+		}
+		pods, err := r.deps.PodLister.Pods(tc.GetNamespace()).List(selector)
+		if err != nil {
+			return nil, fmt.Errorf("tlsCABundleReloader: failed to list %s pods for tc %s/%s, error: %v", memberType, tc.GetNamespace(), tc.GetName(), err)
+		}
+		for _, pod := range pods {
+			if !tlsCABundleTrusted(tc, memberType, pod, status) {
+				pending = append(pending, memberType.String())
+				break
+			}
+		}
+	}
+	return pending, nil
+}
+
+// tlsCABundleTrusted returns whether the pod has loaded the propagated CA bundle
+func tlsCABundleTrusted(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, pod *corev1.Pod, status *v1alpha1.TLSCABundleStatus) bool {
+	if !podutil.IsPodReady(pod) {
+		return false
+	}
+	propagationTime := status.LastPropagationTime.Time
+	if pod.Status.StartTime != nil && pod.Status.StartTime.After(propagationTime) {
+		// the pod is started with the propagated CA bundle
+		return true
+	}
+	if tlsCAHotReloadMemberTypes.Has(memberType.String()) {
+		return time.Now().After(propagationTime.Add(tc.Spec.TLSCluster.CABundle.GetReloadDelay()))
+	}
+	return pod.Annotations[label.AnnTLSCABundleHash] == status.RestartHash
+}
+
+// keptCAs returns the CAs in the cluster secrets which are propagated before but not in the CA bundle
+func keptCAs(status *v1alpha1.TLSCABundleStatus, secrets []*corev1.Secret, cas []*x509.Certificate) []*x509.Certificate {
+	propagated := sets.NewString(status.CAs...)
+	desired := sets.NewString(caFingerprints(cas)...)
+	var kept []*x509.Certificate
+	for _, secret := range secrets {
+		certs, err := crypto.DecodeCertificates(secret.Data[corev1.ServiceAccountRootCAKey])
+		if err != nil {
+			continue
+		}
+		for _, cert := range certs {
+			fingerprint := tlsCertHash(cert.Raw)
+			if propagated.Has(fingerprint) && !desired.Has(fingerprint) {
+				kept = append(kept, cert)
+				desired.Insert(fingerprint)
+			}
+		}
+	}
+	return kept
+}
+
+// uniqueCAs returns the CAs without the duplicated ones in the original order
+func uniqueCAs(cas []*x509.Certificate) []*x509.Certificate {
+	seen := sets.NewString()
+	var result []*x509.Certificate
+	for _, ca := range cas {
+		fingerprint := tlsCertHash(ca.Raw)
+		if seen.Has(fingerprint) {
+			continue
+		}
+		seen.Insert(fingerprint)
+		result = append(result, ca)
+	}
+	return result
+}
+
+func caFingerprints(cas []*x509.Certificate) []string {
+	fingerprints := make([]string, 0, len(cas))
+	for _, ca := range cas {
+		fingerprints = append(fingerprints, tlsCertHash(ca.Raw))
+	}
+	return fingerprints
+}
+
+func encodeCABundle(cas []*x509.Certificate) []byte {
+	var buf bytes.Buffer
+	for _, ca := range cas {
+		buf.Write(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}))
+	}
+	return buf.Bytes()
+}
+
+// tlsCABundleAnnotations returns the pod annotation to restart the pods of the component to reload the
+// CA bundle, nil if the component reloads it online or the CA bundle is never changed by the operator
+func tlsCABundleAnnotations(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) map[string]string {
+	status := tc.Status.TLSCABundle
+	if status == nil || status.RestartHash == "" || tlsCAHotReloadMemberTypes.Has(memberType.String()) {
+		return nil
+	}
+	return map[string]string{label.AnnTLSCABundleHash: status.RestartHash}
+}
+
+type fakeTLSCABundleReloader struct{}
+
+// NewFakeTLSCABundleReloader returns a fake reloader of the CA bundle
+func NewFakeTLSCABundleReloader() manager.Manager {
+	return &fakeTLSCABundleReloader{}
+}
+
+func (r *fakeTLSCABundleReloader) Sync(_ *v1alpha1.TidbCluster) error {
+	return nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/pingcap/tidb-operator/pkg/util/crypto"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTLSCABundleReloader(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPD()
+	tc.Spec.Pump = &v1alpha1.PumpSpec{}
+	tc.Spec.TLSCluster = &v1alpha1.TLSCluster{
+		Enabled:  true,
+		CABundle: &v1alpha1.TLSCABundle{SecretName: "ca-bundle"},
+	}
+	fakeDeps := controller.NewFakeDependencies()
+	reloader := NewTLSCABundleReloader(fakeDeps)
+	secretClient := fakeDeps.KubeClientset.CoreV1().Secrets(tc.Namespace)
+	podIndexer := fakeDeps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+
+	oldCA, _ := newTLSCertForTest(g, "old-ca", nil, 365*24*time.Hour)
+	newCA, _ := newTLSCertForTest(g, "new-ca", nil, 365*24*time.Hour)
+	setCABundle := func(name string, data []byte) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: tc.Namespace},
+			Data:       map[string][]byte{corev1.ServiceAccountRootCAKey: data},
+		}
+		if _, err := secretClient.Update(secret); err != nil {
+			_, err = secretClient.Create(secret)
+			g.Expect(err).NotTo(HaveOccurred())
+		}
+	}
+	clusterSecretNames := []string{util.ClusterClientTLSSecretName(tc.Name)}
+	for _, component := range []string{label.PDLabelVal, label.TiKVLabelVal, label.TiDBLabelVal, label.PumpLabelVal} {
+		clusterSecretNames = append(clusterSecretNames, util.ClusterTLSSecretName(tc.Name, component))
+	}
+	caNames := func() []string {
+		var names []string
+		for _, name := range clusterSecretNames {
+			secret, err := secretClient.Get(name, metav1.GetOptions{})
+			g.Expect(err).NotTo(HaveOccurred())
+			certs, err := crypto.DecodeCertificates(secret.Data[corev1.ServiceAccountRootCAKey])
+			g.Expect(err).NotTo(HaveOccurred())
+			var cns []string
+			for _, cert := range certs {
+				cns = append(cns, cert.Subject.CommonName)
+			}
+			if names != nil {
+				g.Expect(cns).To(Equal(names), "the CA bundle in secret %s", name)
+			}
+			names = cns
+		}
+		return names
+	}
+
+	setCABundle("ca-bundle", oldCA)
+	for _, name := range clusterSecretNames {
+		setCABundle(name, oldCA)
+	}
+	pods := []*corev1.Pod{
+		newTLSCertPodForTest(tc, v1alpha1.PDMemberType, 0, "", true),
+		newTLSCertPodForTest(tc, v1alpha1.PumpMemberType, 0, "", true),
+	}
+	for _, pod := range pods {
+		podIndexer.Add(pod)
+	}
+
+	// the CA bundle is trusted if the cluster secrets are created with it
+	g.Expect(reloader.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.TLSCABundle.Phase).To(Equal(v1alpha1.TLSCABundleTrusted))
+	g.Expect(tc.Status.TLSCABundle.RestartHash).To(BeEmpty())
+	g.Expect(tlsCertAnnotations(tc, v1alpha1.PumpMemberType)).To(BeNil())
+
+	// the new CA is appended, pump is restarted to reload it while pd reloads it online
+	setCABundle("ca-bundle", append(append([]byte{}, oldCA...), newCA...))
+	g.Expect(reloader.Sync(tc)).To(Succeed())
+	g.Expect(caNames()).To(Equal([]string{"old-ca", "new-ca"}))
+	status := tc.Status.TLSCABundle
+	g.Expect(status.Phase).To(Equal(v1alpha1.TLSCABundlePropagating))
+	g.Expect(status.PendingComponents).To(Equal([]string{label.PDLabelVal, label.PumpLabelVal}))
+	g.Expect(tlsCertAnnotations(tc, v1alpha1.PDMemberType)).To(BeNil())
+	g.Expect(tlsCertAnnotations(tc, v1alpha1.PumpMemberType)).To(Equal(map[string]string{label.AnnTLSCABundleHash: status.RestartHash}))
+
+	// the old CA is kept until all the members trust the new bundle
+	setCABundle("ca-bundle", newCA)
+	g.Expect(reloader.Sync(tc)).To(Succeed())
+	g.Expect(caNames()).To(Equal([]string{"old-ca", "new-ca"}))
+	g.Expect(tc.Status.TLSCABundle.Phase).To(Equal(v1alpha1.TLSCABundlePropagating))
+
+	past := metav1.NewTime(time.Now().Add(-tc.Spec.TLSCluster.CABundle.GetReloadDelay()))
+	tc.Status.TLSCABundle.LastPropagationTime = &past
+	g.Expect(reloader.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.TLSCABundle.PendingComponents).To(Equal([]string{label.PumpLabelVal}))
+
+	pump := pods[1].DeepCopy()
+	pump.Annotations[label.AnnTLSCABundleHash] = status.RestartHash
+	podIndexer.Update(pump)
+	g.Expect(reloader.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.TLSCABundle.Phase).To(Equal(v1alpha1.TLSCABundleTrusted))
+	g.Expect(tc.Status.TLSCABundle.PendingComponents).To(BeEmpty())
+
+	// the old CA is removed once the bundle with both CAs is trusted
+	g.Expect(reloader.Sync(tc)).To(Succeed())
+	g.Expect(caNames()).To(Equal([]string{"new-ca"}))
+	g.Expect(tc.Status.TLSCABundle.Phase).To(Equal(v1alpha1.TLSCABundlePropagating))
+	g.Expect(tc.Status.TLSCABundle.CAs).To(HaveLen(1))
+}
@@ -0,0 +1,443 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/pingcap/tidb-operator/pkg/util/crypto"
+	certificates "k8s.io/api/certificates/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
+)
+
+const (
+	// TLSCertExpiringReason is the reason of the events emitted when a certificate is to be re-issued
+	TLSCertExpiringReason = "TLSCertExpiring"
+	// TLSCertRenewingReason is the reason of the events emitted when a certificate is being re-issued
+	TLSCertRenewingReason = "TLSCertRenewing"
+	// TLSCertRenewFailedReason is the reason of the events emitted when a certificate fails to be re-issued
+	TLSCertRenewFailedReason = "TLSCertRenewFailed"
+	// TLSCertRotatedReason is the reason of the events emitted when a certificate in a secret is rotated
+	TLSCertRotatedReason = "TLSCertRotated"
+	// TLSCertReloadingReason is the reason of the events emitted when the pods are restarted to reload a certificate
+	TLSCertReloadingReason = "TLSCertReloading"
+	// TLSCertReloadedReason is the reason of the events emitted when all the pods have reloaded a certificate
+	TLSCertReloadedReason = "TLSCertReloaded"
+	// TLSCertRotateUnsupportedReason is the reason of the events emitted when a certificate can not be
+	// re-issued on demand
+	TLSCertRotateUnsupportedReason = "TLSCertRotateUnsupported"
+
+	// tlsCertClientComponent is the key of the client certificate used by the operator in the status,
+	// it is reloaded by the operator when it is changed, so no pod is restarted
+	tlsCertClientComponent = "client"
+	// tlsCertRenewingSecretSuffix is the suffix of the name of the secret keeping the private key
+	// of the certificate being re-issued
+	tlsCertRenewingSecretSuffix = "-renewing"
+)
+
+// tlsCertRotator rotates the certificates in the cluster secrets of the TidbCluster when
+// `spec.tlsCluster.certRotation` is set.
+//
+// The certificates expiring within the renew period are re-issued via the Kubernetes CSR API
+// if the issuer is CSR, or by cert-manager if the issuer is CertManager. Once the certificate in
+// a secret changes, no matter who rotates it, the pods of the component are rolling restarted by
+// the pod annotation `tidb.pingcap.com/tls-cert-hash` to reload it. The components are reloaded one
+// at a time in the order of upgrading, and the states are tracked in `status.tlsCerts`.
+//
+// The certificates are also re-issued once for each new value of the TidbCluster annotation
+// `tidb.pingcap.com/tls-cert-rotate` if the issuer is CSR.
+//
+// It must be synced before the member managers, so that the annotation is applied in the same sync.
+type tlsCertRotator struct {
+	deps *controller.Dependencies
+}
+
+// NewTLSCertRotator returns a TLS certificate rotator
+func NewTLSCertRotator(deps *controller.Dependencies) manager.Manager {
+	return &tlsCertRotator{
+		deps: deps,
+	}
+}
+
+func (r *tlsCertRotator) Sync(tc *v1alpha1.TidbCluster) error {
+	// the states are kept even if the rotation is disabled, so that the pods are not restarted
+	// again by removing the annotation
+	if !tc.TLSCertRotationEnabled() {
+		return nil
+	}
+	if tc.Status.TLSCerts == nil {
+		tc.Status.TLSCerts = map[string]v1alpha1.TLSCertStatus{}
+	}
+
+	var errs []error
+	components := tlsCertComponents(tc)
+	for _, memberType := range components {
+		secretName := util.ClusterTLSSecretName(tc.GetName(), memberType.String())
+		if err := r.syncCert(tc, memberType.String(), secretName, true); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := r.syncCert(tc, tlsCertClientComponent, util.ClusterClientTLSSecretName(tc.GetName()), false); err != nil {
+		errs = append(errs, err)
+	}
+
+	// reload the rotated certificates one component at a time
+	for _, memberType := range components {
+		status, ok := tc.Status.TLSCerts[memberType.String()]
+		if !ok {
+			continue
+		}
+		switch status.Phase {
+		case v1alpha1.TLSCertReloading:
+			reloaded, err := r.reloaded(tc, memberType, status.ReloadCertHash)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			if !reloaded {
+				return errorutils.NewAggregate(errs)
+			}
+			status.Phase = tlsCertExpiryPhase(tc, status.NotAfter.Time)
+			tc.Status.TLSCerts[memberType.String()] = status
+			klog.Infof("tlsCertRotator: all the %s pods of tc %s/%s have reloaded the certificate in secret %s", memberType, tc.GetNamespace(), tc.GetName(), status.SecretName)
+			r.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, TLSCertReloadedReason, "all the %s pods have reloaded the certificate in secret %s", memberType, status.SecretName)
+		case v1alpha1.TLSCertPendingReload:
+			status.ReloadCertHash = status.CertHash
+			status.Phase = v1alpha1.TLSCertReloading
+			tc.Status.TLSCerts[memberType.String()] = status
+			klog.Infof("tlsCertRotator: restart the %s pods of tc %s/%s to reload the certificate in secret %s", memberType, tc.GetNamespace(), tc.GetName(), status.SecretName)
+			r.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, TLSCertReloadingReason, "restart the %s pods to reload the certificate in secret %s", memberType, status.SecretName)
+			return errorutils.NewAggregate(errs)
+		}
+	}
+	return errorutils.NewAggregate(errs)
+}
+
+// syncCert observes the certificate in the secret and re-issues it if it expires within the renew period,
+// the certificate needs to be reloaded by the pods if needsReload is true
+func (r *tlsCertRotator) syncCert(tc *v1alpha1.TidbCluster, component, secretName string, needsReload bool) error {
+	ns := tc.GetNamespace()
+	// the secret is got from the apiserver, as the cache may be stale just after the certificate is rotated
+	secret, err := r.deps.KubeClientset.CoreV1().Secrets(ns).Get(secretName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		klog.Warningf("tlsCertRotator: secret %s/%s for tc %s is not found, skip rotating it", ns, secretName, tc.GetName())
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("tlsCertRotator: failed to get secret %s/%s for tc %s, error: %v", ns, secretName, tc.GetName(), err)
+	}
+	status := tc.Status.TLSCerts[component]
+	status.SecretName = secretName
+
+	certData := secret.Data[corev1.TLSCertKey]
+	if status.Phase == v1alpha1.TLSCertRenewing {
+		renewed, err := r.completeRenew(tc, &status, secret)
+		if err != nil {
+			return err
+		}
+		if renewed != nil {
+			certData = renewed.Data[corev1.TLSCertKey]
+		}
+	}
+
+	cert, err := crypto.DecodeCertificate(certData)
+	if err != nil {
+		return fmt.Errorf("tlsCertRotator: failed to decode the certificate in secret %s/%s, error: %v", ns, secretName, err)
+	}
+	hash := tlsCertHash(certData)
+	status.NotAfter = metav1.NewTime(cert.NotAfter)
+	switch {
+	case status.CertHash == "":
+		status.CertHash = hash
+		status.Phase = tlsCertExpiryPhase(tc, cert.NotAfter)
+	case status.CertHash != hash:
+		// the certificate is rotated by the operator, cert-manager or the user
+		now := metav1.Now()
+		status.CertHash = hash
+		status.LastRotationTime = &now
+		if needsReload {
+			status.Phase = v1alpha1.TLSCertPendingReload
+		} else {
+			status.Phase = tlsCertExpiryPhase(tc, cert.NotAfter)
+		}
+		klog.Infof("tlsCertRotator: the certificate in secret %s/%s is rotated, it expires at %s", ns, secretName, cert.NotAfter)
+		r.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, TLSCertRotatedReason, "the certificate in secret %s is rotated, it expires at %s", secretName, cert.NotAfter.Format(time.RFC3339))
+	case status.Phase == v1alpha1.TLSCertValid || status.Phase == v1alpha1.TLSCertExpiring || status.Phase == "":
+		phase := tlsCertExpiryPhase(tc, cert.NotAfter)
+		if phase == v1alpha1.TLSCertExpiring && status.Phase != v1alpha1.TLSCertExpiring {
+			r.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, TLSCertExpiringReason, "the certificate in secret %s expires at %s", secretName, cert.NotAfter.Format(time.RFC3339))
+		}
+		status.Phase = phase
+	}
+
+	issuer := tc.Spec.TLSCluster.CertRotation.GetIssuer()
+	rotationID := tc.GetAnnotations()[label.AnnTLSCertRotate]
+	forced := rotationID != "" && rotationID != status.RotationID && status.Phase == v1alpha1.TLSCertValid
+	if forced && issuer != v1alpha1.TLSCertIssuerCSR {
+		// cert-manager re-issues the certificate by its own schedule only
+		status.RotationID = rotationID
+		forced = false
+		r.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, TLSCertRotateUnsupportedReason, "the certificate in secret %s can not be re-issued on demand by issuer %s", secretName, issuer)
+	}
+	if forced {
+		klog.Infof("tlsCertRotator: re-issue the certificate in secret %s/%s for rotation %s", ns, secretName, rotationID)
+		status.Phase = v1alpha1.TLSCertExpiring
+	}
+
+	// the certificate is re-issued with the SANs in the new cluster domain before the PD members are migrated to it
+	if tc.Status.PD.ClusterDomainMigration != nil && issuer == v1alpha1.TLSCertIssuerCSR && status.Phase == v1alpha1.TLSCertValid &&
+		!sets.NewString(cert.DNSNames...).HasAll(clusterTLSCertDNSNames(tc, v1alpha1.MemberType(component))...) {
+		klog.Infof("tlsCertRotator: re-issue the certificate in secret %s/%s for the cluster domain migration", ns, secretName)
+		status.Phase = v1alpha1.TLSCertExpiring
+	}
+
+	if status.Phase == v1alpha1.TLSCertExpiring && issuer == v1alpha1.TLSCertIssuerCSR {
+		if err := r.beginRenew(tc, component, &status, secret, cert); err != nil {
+			tc.Status.TLSCerts[component] = status
+			return err
+		}
+		if forced {
+			status.RotationID = rotationID
+		}
+	}
+	tc.Status.TLSCerts[component] = status
+	return nil
+}
+
+// beginRenew creates a CSR with a new private key for the certificate and approves it,
+// the private key is kept in a secret until the CSR is signed
+func (r *tlsCertRotator) beginRenew(tc *v1alpha1.TidbCluster, component string, status *v1alpha1.TLSCertStatus, secret *corev1.Secret, cert *x509.Certificate) error {
+	ns := tc.GetNamespace()
+	var ips []string
+	for _, ip := range cert.IPAddresses {
+		ips = append(ips, ip.String())
+	}
+	dnsNames := cert.DNSNames
+	if tc.Status.PD.ClusterDomainMigration != nil {
+		dnsNames = sets.NewString(cert.DNSNames...).Insert(clusterTLSCertDNSNames(tc, v1alpha1.MemberType(component))...).List()
+	}
+	csrData, keyData, err := crypto.NewCSR(cert.Subject.CommonName, dnsNames, ips)
+	if err != nil {
+		return fmt.Errorf("tlsCertRotator: failed to create CSR for secret %s/%s, error: %v", ns, secret.Name, err)
+	}
+
+	keySecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            secret.Name + tlsCertRenewingSecretSuffix,
+			Namespace:       ns,
+			Labels:          label.New().Instance(tc.GetInstanceName()),
+			OwnerReferences: []metav1.OwnerReference{controller.GetOwnerRef(tc)},
+		},
+		Data: map[string][]byte{
+			corev1.TLSPrivateKeyKey: keyData,
+		},
+	}
+	secretClient := r.deps.KubeClientset.CoreV1().Secrets(ns)
+	_, err = secretClient.Create(keySecret)
+	if errors.IsAlreadyExists(err) {
+		// left by a CSR deleted before it is signed
+		_, err = secretClient.Update(keySecret)
+	}
+	if err != nil {
+		return fmt.Errorf("tlsCertRotator: failed to save the private key for secret %s/%s, error: %v", ns, secret.Name, err)
+	}
+
+	// CSR is cluster scoped
+	csr := &certificates.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   fmt.Sprintf("%s-%s-%d", ns, secret.Name, time.Now().Unix()),
+			Labels: label.New().Instance(tc.GetInstanceName()),
+		},
+		Spec: certificates.CertificateSigningRequestSpec{
+			Request: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrData}),
+			Usages: []certificates.KeyUsage{
+				certificates.UsageDigitalSignature,
+				certificates.UsageKeyEncipherment,
+				certificates.UsageServerAuth,
+				certificates.UsageClientAuth,
+			},
+		},
+	}
+	csrClient := r.deps.KubeClientset.CertificatesV1beta1().CertificateSigningRequests()
+	csr, err = csrClient.Create(csr)
+	if err != nil {
+		return fmt.Errorf("tlsCertRotator: failed to create CSR for secret %s/%s, error: %v", ns, secret.Name, err)
+	}
+	status.CSRName = csr.Name
+	status.Phase = v1alpha1.TLSCertRenewing
+
+	csr.Status.Conditions = append(csr.Status.Conditions, certificates.CertificateSigningRequestCondition{
+		Type:           certificates.CertificateApproved,
+		Reason:         "TidbClusterCertRotation",
+		Message:        fmt.Sprintf("approved by tidb-operator to rotate the certificate in secret %s/%s", ns, secret.Name),
+		LastUpdateTime: metav1.Now(),
+	})
+	if _, err := csrClient.UpdateApproval(csr); err != nil {
+		return fmt.Errorf("tlsCertRotator: failed to approve CSR %s for secret %s/%s, error: %v", csr.Name, ns, secret.Name, err)
+	}
+	klog.Infof("tlsCertRotator: CSR %s is created to re-issue the certificate in secret %s/%s", csr.Name, ns, secret.Name)
+	r.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, TLSCertRenewingReason, "CSR %s is created to re-issue the certificate in secret %s", csr.Name, secret.Name)
+	return nil
+}
+
+// completeRenew saves the certificate signed for the CSR and the private key into the secret,
+// it returns the updated secret, or nil if the CSR is not signed yet
+func (r *tlsCertRotator) completeRenew(tc *v1alpha1.TidbCluster, status *v1alpha1.TLSCertStatus, secret *corev1.Secret) (*corev1.Secret, error) {
+	ns := tc.GetNamespace()
+	csrClient := r.deps.KubeClientset.CertificatesV1beta1().CertificateSigningRequests()
+	csr, err := csrClient.Get(status.CSRName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		// the CSR is deleted, e.g. to retry after it is denied
+		klog.Infof("tlsCertRotator: CSR %s for secret %s/%s is not found, re-issue the certificate again", status.CSRName, ns, secret.Name)
+		status.CSRName = ""
+		status.Phase = v1alpha1.TLSCertExpiring
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tlsCertRotator: failed to get CSR %s for secret %s/%s, error: %v", status.CSRName, ns, secret.Name, err)
+	}
+	for _, cond := range csr.Status.Conditions {
+		if cond.Type == certificates.CertificateDenied {
+			// the CSR is kept until it is deleted by the user to retry
+			r.deps.Recorder.Eventf(tc, corev1.EventTypeWarning, TLSCertRenewFailedReason, "CSR %s is denied: %s, delete it to retry", csr.Name, cond.Message)
+			return nil, nil
+		}
+	}
+	if len(csr.Status.Certificate) == 0 {
+		klog.Infof("tlsCertRotator: CSR %s for secret %s/%s is not signed yet", csr.Name, ns, secret.Name)
+		return nil, nil
+	}
+
+	keySecretName := secret.Name + tlsCertRenewingSecretSuffix
+	secretClient := r.deps.KubeClientset.CoreV1().Secrets(ns)
+	keySecret, err := secretClient.Get(keySecretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("tlsCertRotator: failed to get the private key in secret %s/%s, error: %v", ns, keySecretName, err)
+	}
+	renewed := secret.DeepCopy()
+	renewed.Data[corev1.TLSCertKey] = csr.Status.Certificate
+	renewed.Data[corev1.TLSPrivateKeyKey] = keySecret.Data[corev1.TLSPrivateKeyKey]
+	if renewed, err = secretClient.Update(renewed); err != nil {
+		return nil, fmt.Errorf("tlsCertRotator: failed to update secret %s/%s, error: %v", ns, secret.Name, err)
+	}
+	klog.Infof("tlsCertRotator: the certificate signed for CSR %s is saved in secret %s/%s", csr.Name, ns, secret.Name)
+
+	if err := secretClient.Delete(keySecretName, &metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		klog.Errorf("tlsCertRotator: failed to delete secret %s/%s, error: %v", ns, keySecretName, err)
+	}
+	if err := csrClient.Delete(csr.Name, &metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		klog.Errorf("tlsCertRotator: failed to delete CSR %s, error: %v", csr.Name, err)
+	}
+	status.CSRName = ""
+	return renewed, nil
+}
+
+// reloaded returns whether all the pods of the component are ready with the annotation of the certificate hash
+func (r *tlsCertRotator) reloaded(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, hash string) (bool, error) {
+	selector, err := label.New().Instance(tc.GetInstanceName()).Component(memberType.String()).Selector()
+	if err != nil {
+		return false, err
+	}
+	pods, err := r.deps.PodLister.Pods(tc.GetNamespace()).List(selector)
+	if err != nil {
+		return false, fmt.Errorf("tlsCertRotator: failed to list %s pods for tc %s/%s, error: %v", memberType, tc.GetNamespace(), tc.GetName(), err)
+	}
+	if len(pods) == 0 {
+		return false, nil
+	}
+	for _, pod := range pods {
+		if pod.Annotations[label.AnnTLSCertHash] != hash || !podutil.IsPodReady(pod) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// tlsCertComponents returns the components with cluster secrets in the order of upgrading
+func tlsCertComponents(tc *v1alpha1.TidbCluster) []v1alpha1.MemberType {
+	var components []v1alpha1.MemberType
+	if tc.Spec.PD != nil {
+		components = append(components, v1alpha1.PDMemberType)
+	}
+	if tc.Spec.TiFlash != nil {
+		components = append(components, v1alpha1.TiFlashMemberType)
+	}
+	if tc.Spec.TiKV != nil {
+		components = append(components, v1alpha1.TiKVMemberType)
+	}
+	if tc.Spec.Pump != nil {
+		components = append(components, v1alpha1.PumpMemberType)
+	}
+	if tc.Spec.TiDB != nil {
+		components = append(components, v1alpha1.TiDBMemberType)
+	}
+	if tc.Spec.TiCDC != nil {
+		components = append(components, v1alpha1.TiCDCMemberType)
+	}
+	return components
+}
+
+// tlsCertExpiryPhase returns Expiring if the certificate expires within the renew period, otherwise Valid
+func tlsCertExpiryPhase(tc *v1alpha1.TidbCluster, notAfter time.Time) v1alpha1.TLSCertPhase {
+	if time.Until(notAfter) <= tc.Spec.TLSCluster.CertRotation.GetRenewBefore() {
+		return v1alpha1.TLSCertExpiring
+	}
+	return v1alpha1.TLSCertValid
+}
+
+func tlsCertHash(certData []byte) string {
+	sum := sha256.Sum256(certData)
+	return hex.EncodeToString(sum[:])
+}
+
+// tlsCertAnnotations returns the pod annotations to restart the pods of the component to reload the
+// rotated certificate and the CA bundle, nil if neither the certificate nor the CA bundle needs reloading
+func tlsCertAnnotations(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) map[string]string {
+	annotations := tlsCABundleAnnotations(tc, memberType)
+	status, ok := tc.Status.TLSCerts[memberType.String()]
+	if !ok || status.ReloadCertHash == "" {
+		return annotations
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[label.AnnTLSCertHash] = status.ReloadCertHash
+	return annotations
+}
+
+type fakeTLSCertRotator struct{}
+
+// NewFakeTLSCertRotator returns a fake TLS certificate rotator
+func NewFakeTLSCertRotator() manager.Manager {
+	return &fakeTLSCertRotator{}
+}
+
+func (r *fakeTLSCertRotator) Sync(_ *v1alpha1.TidbCluster) error {
+	return nil
+}
@@ -0,0 +1,275 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	certificates "k8s.io/api/certificates/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTLSCertRotatorRenewWithCSR(t *testing.T) {
+	g := NewGomegaWithT(t)
+	tc := newTidbClusterForTLSCertRotation()
+	fakeDeps := controller.NewFakeDependencies()
+	rotator := NewTLSCertRotator(fakeDeps)
+	secretClient := fakeDeps.KubeClientset.CoreV1().Secrets(tc.Namespace)
+	csrClient := fakeDeps.KubeClientset.CertificatesV1beta1().CertificateSigningRequests()
+
+	pdSecretName := util.ClusterTLSSecretName(tc.Name, label.PDLabelVal)
+	for _, secretName := range []string{
+		util.ClusterTLSSecretName(tc.Name, label.TiKVLabelVal),
+		util.ClusterTLSSecretName(tc.Name, label.TiDBLabelVal),
+		util.ClusterClientTLSSecretName(tc.Name),
+	} {
+		_, err := secretClient.Create(newTLSCertSecretForTest(g, tc.Namespace, secretName, 365*24*time.Hour))
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+	// the pd certificate expires within the default renew period
+	_, err := secretClient.Create(newTLSCertSecretForTest(g, tc.Namespace, pdSecretName, 24*time.Hour))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// a CSR is created and approved to re-issue the pd certificate
+	g.Expect(rotator.Sync(tc)).To(Succeed())
+	status := tc.Status.TLSCerts[label.PDLabelVal]
+	g.Expect(status.Phase).To(Equal(v1alpha1.TLSCertRenewing))
+	g.Expect(status.SecretName).To(Equal(pdSecretName))
+	g.Expect(status.CSRName).NotTo(BeEmpty())
+	oldHash := status.CertHash
+	for _, component := range []string{label.TiKVLabelVal, label.TiDBLabelVal, tlsCertClientComponent} {
+		g.Expect(tc.Status.TLSCerts[component].Phase).To(Equal(v1alpha1.TLSCertValid))
+	}
+	csr, err := csrClient.Get(status.CSRName, metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(csr.Status.Conditions).To(HaveLen(1))
+	g.Expect(csr.Status.Conditions[0].Type).To(Equal(certificates.CertificateApproved))
+	block, _ := pem.Decode(csr.Spec.Request)
+	request, err := x509.ParseCertificateRequest(block.Bytes)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(request.Subject.CommonName).To(Equal(pdSecretName))
+	g.Expect(request.DNSNames).To(Equal([]string{pdSecretName}))
+	keySecret, err := secretClient.Get(pdSecretName+tlsCertRenewingSecretSuffix, metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// nothing changes until the CSR is signed
+	g.Expect(rotator.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.TLSCerts[label.PDLabelVal].Phase).To(Equal(v1alpha1.TLSCertRenewing))
+	g.Expect(tlsCertAnnotations(tc, v1alpha1.PDMemberType)).To(BeNil())
+
+	// the signed certificate is saved in the secret and the pd pods are to be restarted
+	renewed := newTLSCertSecretForTest(g, tc.Namespace, pdSecretName, 365*24*time.Hour)
+	csr.Status.Certificate = renewed.Data[corev1.TLSCertKey]
+	_, err = csrClient.UpdateStatus(csr)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(rotator.Sync(tc)).To(Succeed())
+	status = tc.Status.TLSCerts[label.PDLabelVal]
+	g.Expect(status.Phase).To(Equal(v1alpha1.TLSCertReloading))
+	g.Expect(status.CSRName).To(BeEmpty())
+	g.Expect(status.CertHash).NotTo(Equal(oldHash))
+	g.Expect(status.ReloadCertHash).To(Equal(status.CertHash))
+	g.Expect(status.LastRotationTime).NotTo(BeNil())
+	g.Expect(tlsCertAnnotations(tc, v1alpha1.PDMemberType)).To(Equal(map[string]string{label.AnnTLSCertHash: status.CertHash}))
+	secret, err := secretClient.Get(pdSecretName, metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(secret.Data[corev1.TLSCertKey]).To(Equal(renewed.Data[corev1.TLSCertKey]))
+	g.Expect(secret.Data[corev1.TLSPrivateKeyKey]).To(Equal(keySecret.Data[corev1.TLSPrivateKeyKey]))
+	_, err = secretClient.Get(pdSecretName+tlsCertRenewingSecretSuffix, metav1.GetOptions{})
+	g.Expect(errors.IsNotFound(err)).To(BeTrue())
+	_, err = csrClient.Get(csr.Name, metav1.GetOptions{})
+	g.Expect(errors.IsNotFound(err)).To(BeTrue())
+}
+
+func TestTLSCertRotatorForceRotation(t *testing.T) {
+	g := NewGomegaWithT(t)
+	tc := newTidbClusterForTLSCertRotation()
+	fakeDeps := controller.NewFakeDependencies()
+	rotator := NewTLSCertRotator(fakeDeps)
+	secretClient := fakeDeps.KubeClientset.CoreV1().Secrets(tc.Namespace)
+
+	for _, secretName := range []string{
+		util.ClusterTLSSecretName(tc.Name, label.PDLabelVal),
+		util.ClusterTLSSecretName(tc.Name, label.TiKVLabelVal),
+		util.ClusterTLSSecretName(tc.Name, label.TiDBLabelVal),
+		util.ClusterClientTLSSecretName(tc.Name),
+	} {
+		_, err := secretClient.Create(newTLSCertSecretForTest(g, tc.Namespace, secretName, 365*24*time.Hour))
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+	g.Expect(rotator.Sync(tc)).To(Succeed())
+	for _, status := range tc.Status.TLSCerts {
+		g.Expect(status.Phase).To(Equal(v1alpha1.TLSCertValid))
+	}
+
+	// all the valid certificates are re-issued for a new rotation
+	tc.Annotations = map[string]string{label.AnnTLSCertRotate: "r1"}
+	g.Expect(rotator.Sync(tc)).To(Succeed())
+	for component, status := range tc.Status.TLSCerts {
+		g.Expect(status.Phase).To(Equal(v1alpha1.TLSCertRenewing), component)
+		g.Expect(status.CSRName).NotTo(BeEmpty(), component)
+		g.Expect(status.RotationID).To(Equal("r1"), component)
+	}
+
+	// the certificates are re-issued only once for a rotation
+	status := tc.Status.TLSCerts[label.PDLabelVal]
+	status.Phase = v1alpha1.TLSCertValid
+	status.CSRName = ""
+	tc.Status.TLSCerts[label.PDLabelVal] = status
+	g.Expect(rotator.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.TLSCerts[label.PDLabelVal].Phase).To(Equal(v1alpha1.TLSCertValid))
+
+	// cert-manager can not re-issue the certificates on demand
+	tc.Spec.TLSCluster.CertRotation.Issuer = v1alpha1.TLSCertIssuerCertManager
+	tc.Annotations[label.AnnTLSCertRotate] = "r2"
+	g.Expect(rotator.Sync(tc)).To(Succeed())
+	status = tc.Status.TLSCerts[label.PDLabelVal]
+	g.Expect(status.Phase).To(Equal(v1alpha1.TLSCertValid))
+	g.Expect(status.RotationID).To(Equal("r2"))
+}
+
+func TestTLSCertRotatorReload(t *testing.T) {
+	g := NewGomegaWithT(t)
+	tc := newTidbClusterForTLSCertRotation()
+	fakeDeps := controller.NewFakeDependencies()
+	rotator := NewTLSCertRotator(fakeDeps)
+	secretClient := fakeDeps.KubeClientset.CoreV1().Secrets(tc.Namespace)
+	podIndexer := fakeDeps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+
+	components := []string{label.PDLabelVal, label.TiKVLabelVal, label.TiDBLabelVal}
+	for _, component := range components {
+		secret := newTLSCertSecretForTest(g, tc.Namespace, util.ClusterTLSSecretName(tc.Name, component), 365*24*time.Hour)
+		_, err := secretClient.Create(secret)
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+	_, err := secretClient.Create(newTLSCertSecretForTest(g, tc.Namespace, util.ClusterClientTLSSecretName(tc.Name), 365*24*time.Hour))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(rotator.Sync(tc)).To(Succeed())
+
+	// the certificates of tidb and tikv are rotated by the user
+	for _, component := range []string{label.TiKVLabelVal, label.TiDBLabelVal} {
+		secret := newTLSCertSecretForTest(g, tc.Namespace, util.ClusterTLSSecretName(tc.Name, component), 365*24*time.Hour)
+		_, err := secretClient.Update(secret)
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+	g.Expect(rotator.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.TLSCerts[label.PDLabelVal].Phase).To(Equal(v1alpha1.TLSCertValid))
+	g.Expect(tc.Status.TLSCerts[label.TiKVLabelVal].Phase).To(Equal(v1alpha1.TLSCertReloading))
+	g.Expect(tc.Status.TLSCerts[label.TiDBLabelVal].Phase).To(Equal(v1alpha1.TLSCertPendingReload))
+	g.Expect(tlsCertAnnotations(tc, v1alpha1.PDMemberType)).To(BeNil())
+	g.Expect(tlsCertAnnotations(tc, v1alpha1.TiDBMemberType)).To(BeNil())
+	tikvHash := tc.Status.TLSCerts[label.TiKVLabelVal].ReloadCertHash
+
+	// tidb is not reloaded until all the tikv pods are ready with the new certificate
+	pods := []*corev1.Pod{
+		newTLSCertPodForTest(tc, v1alpha1.TiKVMemberType, 0, tikvHash, true),
+		newTLSCertPodForTest(tc, v1alpha1.TiKVMemberType, 1, tikvHash, false),
+	}
+	for _, pod := range pods {
+		podIndexer.Add(pod)
+	}
+	g.Expect(rotator.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.TLSCerts[label.TiKVLabelVal].Phase).To(Equal(v1alpha1.TLSCertReloading))
+	g.Expect(tc.Status.TLSCerts[label.TiDBLabelVal].Phase).To(Equal(v1alpha1.TLSCertPendingReload))
+
+	podIndexer.Update(newTLSCertPodForTest(tc, v1alpha1.TiKVMemberType, 1, tikvHash, true))
+	g.Expect(rotator.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.TLSCerts[label.TiKVLabelVal].Phase).To(Equal(v1alpha1.TLSCertValid))
+	g.Expect(tc.Status.TLSCerts[label.TiDBLabelVal].Phase).To(Equal(v1alpha1.TLSCertReloading))
+	// the annotation is kept after the reload, so that the pods are not restarted again
+	g.Expect(tlsCertAnnotations(tc, v1alpha1.TiKVMemberType)).To(Equal(map[string]string{label.AnnTLSCertHash: tikvHash}))
+	g.Expect(tlsCertAnnotations(tc, v1alpha1.TiDBMemberType)).NotTo(BeNil())
+
+	// the states are kept when the rotation is disabled
+	tc.Spec.TLSCluster.CertRotation = nil
+	g.Expect(rotator.Sync(tc)).To(Succeed())
+	g.Expect(tc.Status.TLSCerts[label.TiDBLabelVal].Phase).To(Equal(v1alpha1.TLSCertReloading))
+}
+
+func newTidbClusterForTLSCertRotation() *v1alpha1.TidbCluster {
+	tc := newTidbClusterForPD()
+	tc.Spec.TLSCluster = &v1alpha1.TLSCluster{
+		Enabled:      true,
+		CertRotation: &v1alpha1.TLSCertRotation{},
+	}
+	return tc
+}
+
+// newTLSCertSecretForTest returns a secret with a self-signed certificate valid for the duration
+func newTLSCertSecretForTest(g *GomegaWithT, ns, name string, validFor time.Duration) *corev1.Secret {
+	certData, keyData := newTLSCertForTest(g, name, []string{name}, validFor)
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+		},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       certData,
+			corev1.TLSPrivateKeyKey: keyData,
+		},
+	}
+}
+
+// newTLSCertForTest returns a PEM encoded self-signed certificate valid for the duration and its private key
+func newTLSCertForTest(g *GomegaWithT, commonName string, dnsNames []string, validFor time.Duration) ([]byte, []byte) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	g.Expect(err).NotTo(HaveOccurred())
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	g.Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(validFor),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	g.Expect(err).NotTo(HaveOccurred())
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+func newTLSCertPodForTest(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType, ordinal int, hash string, ready bool) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s-%s-%d", tc.Name, memberType, ordinal),
+			Namespace:   tc.Namespace,
+			Labels:      label.New().Instance(tc.GetInstanceName()).Component(memberType.String()),
+			Annotations: map[string]string{label.AnnTLSCertHash: hash},
+		},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+		},
+	}
+}
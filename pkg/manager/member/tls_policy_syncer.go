@@ -0,0 +1,96 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/pingcap/tidb-operator/pkg/util/crypto"
+)
+
+// tlsPolicySyncer applies the TLS policies of the TidbCluster to the client tls.Configs used by
+// the operator, `spec.tlsCluster.policy` to the clients of PD, TiKV, TiDB status port and TiCDC,
+// and `spec.tidb.tlsClient.policy` to the MySQL client of TiDB.
+//
+// The policies of the components themselves are rendered into their config files by the member managers.
+type tlsPolicySyncer struct {
+	deps *controller.Dependencies
+}
+
+// NewTLSPolicySyncer returns a syncer of the TLS policies of the clients of the operator
+func NewTLSPolicySyncer(deps *controller.Dependencies) manager.Manager {
+	return &tlsPolicySyncer{
+		deps: deps,
+	}
+}
+
+func (s *tlsPolicySyncer) Sync(tc *v1alpha1.TidbCluster) error {
+	ns := tc.GetNamespace()
+	tlsConfigs := s.deps.ClientFactory.TLSConfigs
+
+	if tc.IsTLSClusterEnabled() {
+		policy, err := parseTLSPolicy(tc.Spec.TLSCluster.Policy)
+		if err != nil {
+			return fmt.Errorf("tlsPolicySyncer: invalid spec.tlsCluster.policy of tc %s/%s, error: %v", ns, tc.GetName(), err)
+		}
+		tlsConfigs.SetPolicy(ns, util.ClusterClientTLSSecretName(tc.GetName()), policy)
+	}
+	if tc.Spec.TiDB != nil && tc.Spec.TiDB.IsTLSClientEnabled() {
+		policy, err := parseTLSPolicy(tc.Spec.TiDB.TLSClient.Policy)
+		if err != nil {
+			return fmt.Errorf("tlsPolicySyncer: invalid spec.tidb.tlsClient.policy of tc %s/%s, error: %v", ns, tc.GetName(), err)
+		}
+		tlsConfigs.SetPolicy(ns, util.TiDBClientTLSSecretName(tc.GetName()), policy)
+	}
+	return nil
+}
+
+// parseTLSPolicy returns the policy of the tls.Configs, the zero value if the policy is not set
+func parseTLSPolicy(policy *v1alpha1.TLSPolicy) (pdapi.TLSPolicy, error) {
+	var result pdapi.TLSPolicy
+	if policy == nil {
+		return result, nil
+	}
+	if policy.MinVersion != "" {
+		version, err := crypto.ParseTLSVersion(policy.MinVersion)
+		if err != nil {
+			return result, err
+		}
+		result.MinVersion = version
+	}
+	if len(policy.CipherSuites) > 0 {
+		cipherSuites, err := crypto.ParseCipherSuites(policy.CipherSuites)
+		if err != nil {
+			return result, err
+		}
+		result.CipherSuites = cipherSuites
+	}
+	return result, nil
+}
+
+type fakeTLSPolicySyncer struct{}
+
+// NewFakeTLSPolicySyncer returns a fake syncer of the TLS policies
+func NewFakeTLSPolicySyncer() manager.Manager {
+	return &fakeTLSPolicySyncer{}
+}
+
+func (s *fakeTLSPolicySyncer) Sync(_ *v1alpha1.TidbCluster) error {
+	return nil
+}
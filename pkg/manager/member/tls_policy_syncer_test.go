@@ -0,0 +1,83 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"crypto/tls"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTLSPolicySyncer(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForTiDB()
+	tc.Spec.TLSCluster = &v1alpha1.TLSCluster{
+		Enabled: true,
+		Policy: &v1alpha1.TLSPolicy{
+			MinVersion:   "TLSv1.2",
+			CipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"},
+		},
+	}
+	fakeDeps := controller.NewFakeDependencies()
+	for _, name := range []string{util.ClusterClientTLSSecretName(tc.Name), util.TiDBClientTLSSecretName(tc.Name)} {
+		certData, keyData := newTLSCertForTest(g, name, nil, time.Hour)
+		_, err := fakeDeps.KubeClientset.CoreV1().Secrets(tc.Namespace).Create(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: tc.Namespace, Name: name},
+			Data: map[string][]byte{
+				corev1.TLSCertKey:              certData,
+				corev1.TLSPrivateKeyKey:        keyData,
+				corev1.ServiceAccountRootCAKey: certData,
+			},
+		})
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+	syncer := NewTLSPolicySyncer(fakeDeps)
+	tlsConfigs := fakeDeps.ClientFactory.TLSConfigs
+
+	g.Expect(syncer.Sync(tc)).To(Succeed())
+	config, err := tlsConfigs.GetTLSConfig(pdapi.Namespace(tc.Namespace), util.ClusterClientTLSSecretName(tc.Name))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(config.MinVersion).To(Equal(uint16(tls.VersionTLS12)))
+	g.Expect(config.CipherSuites).To(Equal([]uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}))
+
+	// the policy of the MySQL client is set by spec.tidb.tlsClient.policy
+	tc.Spec.TiDB.TLSClient = &v1alpha1.TiDBTLSClient{
+		Enabled: true,
+		Policy:  &v1alpha1.TLSPolicy{MinVersion: "TLSv1.3"},
+	}
+	g.Expect(syncer.Sync(tc)).To(Succeed())
+	config, err = tlsConfigs.GetTLSConfig(pdapi.Namespace(tc.Namespace), util.TiDBClientTLSSecretName(tc.Name))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(config.MinVersion).To(Equal(uint16(tls.VersionTLS13)))
+	g.Expect(config.CipherSuites).To(BeEmpty())
+
+	// the cached tls.Config is reloaded without the policy once it is removed
+	tc.Spec.TLSCluster.Policy = nil
+	g.Expect(syncer.Sync(tc)).To(Succeed())
+	config, err = tlsConfigs.GetTLSConfig(pdapi.Namespace(tc.Namespace), util.ClusterClientTLSSecretName(tc.Name))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(config.MinVersion).To(BeZero())
+
+	tc.Spec.TLSCluster.Policy = &v1alpha1.TLSPolicy{MinVersion: "SSLv3"}
+	g.Expect(syncer.Sync(tc)).NotTo(Succeed())
+}
@@ -0,0 +1,142 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+)
+
+// TombstoneStoreRemovedReason is the reason of the events emitted when a tombstone store is removed
+const TombstoneStoreRemovedReason = "TombstoneStoreRemoved"
+
+// tombstoneStoreCleaner removes the tombstone stores left by scale-in or failover from PD after
+// they have been offline for `spec.tombstoneStoreRetentionPeriod`, so that they are not listed in
+// PD and the dashboards forever.
+//
+// PD only supports removing all the tombstone stores at once, so the stores are removed only when
+// all of them are older than the retention period, and none of them is still used by a pod, e.g. the
+// statefulset has not been scaled in yet.
+//
+// It only works for the TidbCluster which owns PD.
+type tombstoneStoreCleaner struct {
+	deps *controller.Dependencies
+}
+
+// NewTombstoneStoreCleaner returns a tombstone store cleaner
+func NewTombstoneStoreCleaner(deps *controller.Dependencies) manager.Manager {
+	return &tombstoneStoreCleaner{
+		deps: deps,
+	}
+}
+
+func (c *tombstoneStoreCleaner) Sync(tc *v1alpha1.TidbCluster) error {
+	// the tombstone stores out of Kubernetes are not cleaned while the cluster is being adopted
+	if tc.Spec.PD == nil || tc.Spec.TombstoneStoreRetentionPeriod == nil || !tc.PDIsAvailable() || tc.Adopting() {
+		return nil
+	}
+	ns := tc.GetNamespace()
+	tcName := tc.GetName()
+	retention := tc.Spec.TombstoneStoreRetentionPeriod.Duration
+
+	pdClient := controller.GetPDClient(c.deps.PDControl, tc)
+	storesInfo, err := pdClient.GetTombStoneStores()
+	if err != nil {
+		return fmt.Errorf("tombstoneStoreCleaner: failed to get tombstone stores of tc %s/%s, error: %v", ns, tcName, err)
+	}
+	if storesInfo == nil || len(storesInfo.Stores) == 0 {
+		return nil
+	}
+
+	storeIDs := sets.NewString()
+	for _, store := range storesInfo.Stores {
+		if store.Store == nil {
+			continue
+		}
+		id := fmt.Sprintf("%d", store.Store.GetId())
+		// the heartbeat time is zero if the store never reported to PD
+		var heartbeat time.Time
+		if store.Status != nil {
+			heartbeat = store.Status.LastHeartbeatTS
+		}
+		if !heartbeat.IsZero() && time.Since(heartbeat) < retention {
+			klog.V(4).Infof("tombstoneStoreCleaner: tombstone store %s of tc %s/%s is retained until %s", id, ns, tcName, heartbeat.Add(retention))
+			return nil
+		}
+		storeIDs.Insert(id)
+	}
+	if storeIDs.Len() == 0 {
+		return nil
+	}
+
+	inUse, err := c.storesInUse(tc, storeIDs)
+	if err != nil {
+		return err
+	}
+	if inUse.Len() > 0 {
+		klog.Infof("tombstoneStoreCleaner: tombstone stores %v of tc %s/%s are still used by pods, skip removing", inUse.List(), ns, tcName)
+		return nil
+	}
+
+	if err := pdClient.RemoveTombStone(); err != nil {
+		return fmt.Errorf("tombstoneStoreCleaner: failed to remove tombstone stores of tc %s/%s, error: %v", ns, tcName, err)
+	}
+	for _, store := range storesInfo.Stores {
+		if store.Store == nil {
+			continue
+		}
+		klog.Infof("tombstoneStoreCleaner: tombstone store %d (%s) of tc %s/%s is removed", store.Store.GetId(), store.Store.GetAddress(), ns, tcName)
+		c.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, TombstoneStoreRemovedReason, "tombstone store %d (%s) is removed after retention period %s",
+			store.Store.GetId(), store.Store.GetAddress(), retention)
+	}
+	return nil
+}
+
+// storesInUse returns the IDs of the stores which are still labeled on the pods of the cluster
+func (c *tombstoneStoreCleaner) storesInUse(tc *v1alpha1.TidbCluster, storeIDs sets.String) (sets.String, error) {
+	selector, err := label.New().Instance(tc.GetInstanceName()).Selector()
+	if err != nil {
+		return nil, err
+	}
+	pods, err := c.deps.PodLister.Pods(tc.GetNamespace()).List(selector)
+	if err != nil {
+		return nil, fmt.Errorf("tombstoneStoreCleaner: failed to list pods for tc %s/%s, error: %v", tc.GetNamespace(), tc.GetName(), err)
+	}
+	inUse := sets.NewString()
+	for _, pod := range pods {
+		if id, ok := pod.Labels[label.StoreIDLabelKey]; ok && storeIDs.Has(id) {
+			inUse.Insert(id)
+		}
+	}
+	return inUse, nil
+}
+
+type fakeTombstoneStoreCleaner struct{}
+
+// NewFakeTombstoneStoreCleaner returns a fake tombstone store cleaner
+func NewFakeTombstoneStoreCleaner() manager.Manager {
+	return &fakeTombstoneStoreCleaner{}
+}
+
+func (c *fakeTombstoneStoreCleaner) Sync(_ *v1alpha1.TidbCluster) error {
+	return nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestTombstoneStoreCleanerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type testcase struct {
+		name          string
+		retention     *metav1.Duration
+		heartbeatsAgo []time.Duration
+		podStoreID    string
+		expectRemoved bool
+	}
+
+	testFn := func(test *testcase) {
+		t.Log(test.name)
+
+		deps := controller.NewFakeDependencies()
+		recorder := record.NewFakeRecorder(10)
+		deps.Recorder = recorder
+		tc := newTidbClusterForPD()
+		tc.Spec.TombstoneStoreRetentionPeriod = test.retention
+		tc.Status.PD.Members = map[string]v1alpha1.PDMember{
+			"test-pd-0": {Name: "test-pd-0", Health: true},
+			"test-pd-1": {Name: "test-pd-1", Health: true},
+			"test-pd-2": {Name: "test-pd-2", Health: true},
+		}
+		tc.Status.PD.StatefulSet = &apps.StatefulSetStatus{ReadyReplicas: 3}
+
+		if test.podStoreID != "" {
+			l := label.New().Instance(tc.Name).TiKV().Labels()
+			l[label.StoreIDLabelKey] = test.podStoreID
+			deps.KubeInformerFactory.Core().V1().Pods().Informer().GetIndexer().Add(&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ordinalPodName(v1alpha1.TiKVMemberType, tc.Name, 0),
+					Namespace: tc.Namespace,
+					Labels:    l,
+				},
+			})
+		}
+
+		stores := &pdapi.StoresInfo{}
+		for i, ago := range test.heartbeatsAgo {
+			stores.Stores = append(stores.Stores, &pdapi.StoreInfo{
+				Store: &pdapi.MetaStore{
+					Store: &metapb.Store{Id: uint64(i + 1), Address: "tikv", State: metapb.StoreState_Tombstone},
+				},
+				Status: &pdapi.StoreStatus{LastHeartbeatTS: time.Now().Add(-ago)},
+			})
+		}
+		stores.Count = len(stores.Stores)
+		pdClient := controller.NewFakePDClient(deps.PDControl.(*pdapi.FakePDControl), tc)
+		pdClient.AddReaction(pdapi.GetTombStoneStoresActionType, func(action *pdapi.Action) (interface{}, error) {
+			return stores, nil
+		})
+		removed := false
+		pdClient.AddReaction(pdapi.RemoveTombStoneActionType, func(action *pdapi.Action) (interface{}, error) {
+			removed = true
+			return nil, nil
+		})
+
+		err := NewTombstoneStoreCleaner(deps).Sync(tc)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(removed).To(Equal(test.expectRemoved))
+		events := collectEvents(recorder.Events)
+		if test.expectRemoved {
+			g.Expect(events).To(HaveLen(len(test.heartbeatsAgo)))
+			g.Expect(events[0]).To(ContainSubstring(TombstoneStoreRemovedReason))
+		} else {
+			g.Expect(events).To(BeEmpty())
+		}
+	}
+
+	tests := []testcase{
+		{
+			name:          "retention period is not set",
+			heartbeatsAgo: []time.Duration{48 * time.Hour},
+			expectRemoved: false,
+		},
+		{
+			name:          "all stores are older than the retention period",
+			retention:     &metav1.Duration{Duration: 24 * time.Hour},
+			heartbeatsAgo: []time.Duration{48 * time.Hour, 25 * time.Hour},
+			expectRemoved: true,
+		},
+		{
+			name:          "a store is within the retention period",
+			retention:     &metav1.Duration{Duration: 24 * time.Hour},
+			heartbeatsAgo: []time.Duration{48 * time.Hour, time.Hour},
+			expectRemoved: false,
+		},
+		{
+			name:          "no tombstone stores",
+			retention:     &metav1.Duration{Duration: 24 * time.Hour},
+			expectRemoved: false,
+		},
+		{
+			name:          "a store is still used by a pod",
+			retention:     &metav1.Duration{Duration: 24 * time.Hour},
+			heartbeatsAgo: []time.Duration{48 * time.Hour},
+			podStoreID:    "1",
+			expectRemoved: false,
+		},
+		{
+			name:          "a pod uses another store",
+			retention:     &metav1.Duration{Duration: 24 * time.Hour},
+			heartbeatsAgo: []time.Duration{48 * time.Hour},
+			podStoreID:    "5",
+			expectRemoved: true,
+		},
+	}
+	for i := range tests {
+		testFn(&tests[i])
+	}
+}
@@ -18,13 +18,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/apis/util/config"
 	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/features"
 	"github.com/pingcap/tidb-operator/pkg/label"
 	"github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/pingcap/tidb-operator/pkg/util/logging"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
 	"github.com/pingcap/tidb-operator/pkg/util/toml"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -248,6 +253,23 @@ func getStsAnnotations(tcAnns map[string]string, component string) map[string]st
 	return anns
 }
 
+// getTidbClusterStsAnnotations gets annotations for statefulset of given component of the TidbCluster,
+// the delete slots are the union of the spec and the annotation of the TidbCluster.
+func getTidbClusterStsAnnotations(tc *v1alpha1.TidbCluster, component string) map[string]string {
+	anns := getStsAnnotations(tc.Annotations, component)
+	deleteSlots := tc.GetDeleteSlots(component)
+	if deleteSlots.Len() == 0 {
+		return anns
+	}
+	b, err := json.Marshal(deleteSlots.List())
+	if err != nil {
+		klog.Errorf("failed to marshal delete slots %v of %s of TidbCluster %s/%s: %v", deleteSlots.List(), component, tc.Namespace, tc.Name, err)
+		return anns
+	}
+	anns[helper.DeleteSlotsAnn] = string(b)
+	return anns
+}
+
 // MapContainers index containers of Pod by container name in favor of looking up
 func MapContainers(podSpec *corev1.PodSpec) map[string]corev1.Container {
 	m := map[string]corev1.Container{}
@@ -257,6 +279,17 @@ func MapContainers(podSpec *corev1.PodSpec) map[string]corev1.Container {
 	return m
 }
 
+// setVolumeClaimTemplatesAnnotations adds the PVC annotations of the component to the volumeClaimTemplates
+func setVolumeClaimTemplatesAnnotations(set *apps.StatefulSet, annotations map[string]string) {
+	if len(annotations) == 0 {
+		return
+	}
+	for i := range set.Spec.VolumeClaimTemplates {
+		vct := &set.Spec.VolumeClaimTemplates[i]
+		vct.Annotations = util.CombineStringMap(annotations, vct.Annotations)
+	}
+}
+
 // UpdateStatefulSet is a template function to update the statefulset of components
 func UpdateStatefulSet(setCtl controller.StatefulSetControlInterface, object runtime.Object, newSet, oldSet *apps.StatefulSet) error {
 	isOrphan := metav1.GetControllerOf(oldSet) == nil
@@ -273,6 +306,10 @@ func UpdateStatefulSet(setCtl controller.StatefulSetControlInterface, object run
 		return nil
 	}
 
+	if features.DefaultFeatureGate.Enabled(features.ServerSideApply) {
+		return applyStatefulSet(setCtl, object, newSet, oldSet)
+	}
+
 	set := *oldSet
 
 	// update specs for sts
@@ -311,6 +348,57 @@ func UpdateStatefulSet(setCtl controller.StatefulSetControlInterface, object run
 	return err
 }
 
+// applyStatefulSet applies the desired statefulset of the component with server-side apply, the immutable
+// fields are taken from the existing statefulset and the fields managed by others are kept by the API server
+func applyStatefulSet(setCtl controller.StatefulSetControlInterface, object runtime.Object, newSet, oldSet *apps.StatefulSet) error {
+	set := newSet.DeepCopy()
+	set.Spec.Selector = oldSet.Spec.Selector
+	set.Spec.ServiceName = oldSet.Spec.ServiceName
+	set.Spec.PodManagementPolicy = oldSet.Spec.PodManagementPolicy
+	set.Spec.VolumeClaimTemplates = oldSet.Spec.VolumeClaimTemplates
+	if podConfig, ok := oldSet.Spec.Template.Annotations[LastAppliedConfigAnnotation]; ok {
+		if set.Spec.Template.Annotations == nil {
+			set.Spec.Template.Annotations = map[string]string{}
+		}
+		set.Spec.Template.Annotations[LastAppliedConfigAnnotation] = podConfig
+	}
+	// the last applied config is still recorded to detect the changes and by the upgraders
+	if err := SetStatefulSetLastAppliedConfigAnnotation(set); err != nil {
+		return err
+	}
+	_, err := setCtl.ApplyStatefulSet(object, set)
+	return err
+}
+
+// updateService writes the Service svc merged from the existing one, or applies the desired Service
+// newSvc with server-side apply if it is enabled, so the fields managed by others are not overwritten
+func updateService(serviceControl controller.ServiceControlInterface, obj runtime.Object, newSvc, svc *corev1.Service) error {
+	if !features.DefaultFeatureGate.Enabled(features.ServerSideApply) {
+		_, err := serviceControl.UpdateService(obj, svc)
+		return err
+	}
+	applied := newSvc.DeepCopy()
+	if err := controller.SetServiceLastAppliedConfigAnnotation(applied); err != nil {
+		return err
+	}
+	_, err := serviceControl.ApplyService(obj, applied)
+	return err
+}
+
+// setTLSPolicyConfig sets the min TLS version and the cipher suites of the policy into the keys of the config,
+// the defaults of the component are kept if they are not set
+func setTLSPolicyConfig(cfg *config.GenericConfig, policy *v1alpha1.TLSPolicy, minVersionKey, cipherSuitesKey string) {
+	if policy == nil {
+		return
+	}
+	if policy.MinVersion != "" {
+		cfg.Set(minVersionKey, policy.MinVersion)
+	}
+	if len(policy.CipherSuites) > 0 {
+		cfg.Set(cipherSuitesKey, policy.CipherSuites)
+	}
+}
+
 // findContainerByName finds targetContainer by containerName, If not find, then return nil
 func findContainerByName(sts *apps.StatefulSet, containerName string) *corev1.Container {
 	for _, c := range sts.Spec.Template.Spec.Containers {
@@ -323,10 +411,17 @@ func findContainerByName(sts *apps.StatefulSet, containerName string) *corev1.Co
 
 func getTikVConfigMapForTiKVSpec(tikvSpec *v1alpha1.TiKVSpec, tc *v1alpha1.TidbCluster, scriptModel *TiKVStartScriptModel) (*corev1.ConfigMap, error) {
 	config := tikvSpec.Config
+	if config == nil {
+		config = v1alpha1.NewTiKVConfig()
+	}
 	if tc.IsTLSClusterEnabled() {
 		config.Set("security.ca-path", path.Join(tikvClusterCertPath, tlsSecretRootCAKey))
 		config.Set("security.cert-path", path.Join(tikvClusterCertPath, corev1.TLSCertKey))
 		config.Set("security.key-path", path.Join(tikvClusterCertPath, corev1.TLSPrivateKeyKey))
+		setTLSPolicyConfig(config.GenericConfig, tc.Spec.TLSCluster.Policy, "security.min-tls-version", "security.cipher-suites")
+	}
+	if tikvSpec.Encryption != nil {
+		setTiKVEncryptionConfig(config.GenericConfig, tc)
 	}
 	confText, err := config.MarshalTOML()
 	if err != nil {
@@ -490,8 +585,7 @@ func CreateOrUpdateService(serviceLister corelisters.ServiceLister, serviceContr
 			svc.OwnerReferences = newSvc.OwnerReferences
 			svc.Labels = newSvc.Labels
 		}
-		_, err = serviceControl.UpdateService(obj, &svc)
-		return err
+		return updateService(serviceControl, obj, newSvc, &svc)
 	}
 	return nil
 }
@@ -532,3 +626,84 @@ func GetPVCSelectorForPod(controller runtime.Object, memberType v1alpha1.MemberT
 	}
 	return l.Selector()
 }
+
+// componentLog returns the logger of the component of the cluster
+func componentLog(meta metav1.Object, memberType v1alpha1.MemberType) logging.Logger {
+	controllerName := "tidbcluster"
+	if _, ok := meta.(*v1alpha1.DMCluster); ok {
+		controllerName = "dmcluster"
+	}
+	return logging.ForController(controllerName).WithCluster(meta.GetNamespace(), meta.GetName()).WithComponent(memberType.String())
+}
+
+// setUpgradingCondition marks the component as being upgraded with the step the upgrade is waiting for
+func setUpgradingCondition(conditions *[]v1alpha1.ComponentCondition, reason, message string) {
+	cond := utiltidbcluster.NewComponentCondition(v1alpha1.ComponentUpgrading, corev1.ConditionTrue, reason, message)
+	utiltidbcluster.SetComponentCondition(conditions, *cond)
+}
+
+// syncConfigSyncedCondition sets the ConfigSynced condition of the component by comparing the ConfigMap
+// rendered from the spec with the one mounted by the statefulset. The configuration is still out of sync
+// after the statefulset mounts the new ConfigMap until the rolling update of the pods is done.
+func syncConfigSyncedCondition(conditions *[]v1alpha1.ComponentCondition, set *apps.StatefulSet, cm *corev1.ConfigMap, prefix string) {
+	if set == nil || cm == nil {
+		return
+	}
+	inUse := FindConfigMapVolume(&set.Spec.Template.Spec, func(name string) bool {
+		return strings.HasPrefix(name, prefix)
+	})
+	cur := utiltidbcluster.GetComponentCondition(*conditions, v1alpha1.ComponentConfigSynced)
+	var cond *v1alpha1.ComponentCondition
+	switch {
+	case inUse != cm.Name:
+		cond = utiltidbcluster.NewComponentCondition(v1alpha1.ComponentConfigSynced, corev1.ConditionFalse, utiltidbcluster.ConfigOutOfSync,
+			fmt.Sprintf("statefulset %s mounts ConfigMap %s instead of %s", set.Name, inUse, cm.Name))
+	case cur != nil && cur.Status == corev1.ConditionFalse &&
+		(set.Status.ObservedGeneration < set.Generation || set.Status.CurrentRevision != set.Status.UpdateRevision):
+		cond = utiltidbcluster.NewComponentCondition(v1alpha1.ComponentConfigSynced, corev1.ConditionFalse, utiltidbcluster.ConfigOutOfSync,
+			fmt.Sprintf("pods of statefulset %s are being restarted to use ConfigMap %s", set.Name, cm.Name))
+	default:
+		cond = utiltidbcluster.NewComponentCondition(v1alpha1.ComponentConfigSynced, corev1.ConditionTrue, utiltidbcluster.ConfigInSync,
+			fmt.Sprintf("all pods use ConfigMap %s", cm.Name))
+	}
+	utiltidbcluster.SetComponentCondition(conditions, *cond)
+}
+
+// syncUpgradeProgress records the progress of the rolling upgrade of a component. set is the statefulset
+// with the partition computed by the upgrader and status is the last observed status of the statefulset.
+func syncUpgradeProgress(progress **v1alpha1.UpgradeProgress, phase v1alpha1.MemberPhase, conditions []v1alpha1.ComponentCondition,
+	set *apps.StatefulSet, status *apps.StatefulSetStatus, image string) {
+	if phase != v1alpha1.UpgradePhase || set == nil || status == nil {
+		*progress = nil
+		return
+	}
+	p := &v1alpha1.UpgradeProgress{
+		TargetVersion:   imageTag(image),
+		Replicas:        *set.Spec.Replicas,
+		UpdatedReplicas: status.UpdatedReplicas,
+	}
+	if ru := set.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil && p.UpdatedReplicas < p.Replicas &&
+		helper.GetPodOrdinals(*set.Spec.Replicas, set).Has(*ru.Partition) {
+		ordinal := *ru.Partition
+		p.CurrentOrdinal = &ordinal
+	}
+	cond := utiltidbcluster.GetComponentCondition(conditions, v1alpha1.ComponentUpgrading)
+	if cond != nil && cond.Status == corev1.ConditionTrue && cond.Reason != utiltidbcluster.RollingUpdate {
+		p.BlockingReason = cond.Reason
+		p.Message = cond.Message
+	}
+	*progress = p
+}
+
+// imageTag returns the tag of the image, e.g. v4.0.0 for pingcap/pd:v4.0.0, or the digest if the image is
+// referenced by digest
+func imageTag(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		return image[i+1:]
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return "latest"
+}
@@ -25,13 +25,17 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/features"
 	"github.com/pingcap/tidb-operator/pkg/label"
+	utiltidbcluster "github.com/pingcap/tidb-operator/pkg/util/tidbcluster"
 	apps "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubeinformers "k8s.io/client-go/informers"
 	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
 )
 
 func TestStatefulSetIsUpgrading(t *testing.T) {
@@ -172,6 +176,67 @@ func TestGetStsAnnotations(t *testing.T) {
 	}
 }
 
+func TestGetTidbClusterStsAnnotations(t *testing.T) {
+	tests := []struct {
+		name      string
+		tc        *v1alpha1.TidbCluster
+		component string
+		expected  map[string]string
+	}{
+		{
+			name: "no delete slots",
+			tc: &v1alpha1.TidbCluster{
+				Spec: v1alpha1.TidbClusterSpec{
+					TiKV: &v1alpha1.TiKVSpec{},
+				},
+			},
+			component: label.TiKVLabelVal,
+			expected:  map[string]string{},
+		},
+		{
+			name: "spec only",
+			tc: &v1alpha1.TidbCluster{
+				Spec: v1alpha1.TidbClusterSpec{
+					TiKV: &v1alpha1.TiKVSpec{
+						DeleteSlots: []int32{3, 1},
+					},
+				},
+			},
+			component: label.TiKVLabelVal,
+			expected: map[string]string{
+				helper.DeleteSlotsAnn: "[1,3]",
+			},
+		},
+		{
+			name: "spec and annotation",
+			tc: &v1alpha1.TidbCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						label.AnnTiKVDeleteSlots: "[2]",
+					},
+				},
+				Spec: v1alpha1.TidbClusterSpec{
+					TiKV: &v1alpha1.TiKVSpec{
+						DeleteSlots: []int32{1},
+					},
+				},
+			},
+			component: label.TiKVLabelVal,
+			expected: map[string]string{
+				helper.DeleteSlotsAnn: "[1,2]",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getTidbClusterStsAnnotations(tt.tc, tt.component)
+			if diff := cmp.Diff(tt.expected, got); diff != "" {
+				t.Errorf("unexpected (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
 func TestShouldRecover(t *testing.T) {
 	notReadyPods := []*v1.Pod{
 		{
@@ -800,6 +865,32 @@ func TestCombineAnnotations(t *testing.T) {
 	}
 }
 
+func TestSetVolumeClaimTemplatesAnnotations(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	set := &apps.StatefulSet{
+		Spec: apps.StatefulSetSpec{
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{ObjectMeta: metav1.ObjectMeta{Name: "tikv"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "tikv-wal", Annotations: map[string]string{"foo": "bar", "ebs.csi.aws.com/iops": "3000"}}},
+			},
+		},
+	}
+	setVolumeClaimTemplatesAnnotations(set, nil)
+	g.Expect(set.Spec.VolumeClaimTemplates[0].Annotations).To(BeNil())
+
+	setVolumeClaimTemplatesAnnotations(set, map[string]string{"ebs.csi.aws.com/iops": "6000", "ebs.csi.aws.com/throughput": "250"})
+	g.Expect(set.Spec.VolumeClaimTemplates[0].Annotations).To(Equal(map[string]string{
+		"ebs.csi.aws.com/iops":       "6000",
+		"ebs.csi.aws.com/throughput": "250",
+	}))
+	g.Expect(set.Spec.VolumeClaimTemplates[1].Annotations).To(Equal(map[string]string{
+		"foo":                        "bar",
+		"ebs.csi.aws.com/iops":       "6000",
+		"ebs.csi.aws.com/throughput": "250",
+	}))
+}
+
 func TestMemberPodName(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -839,3 +930,151 @@ func TestMemberPodName(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateStatefulSetWithServerSideApply(t *testing.T) {
+	g := NewGomegaWithT(t)
+	saved := features.DefaultFeatureGate.String()
+	features.DefaultFeatureGate.Set("ServerSideApply=true")
+	defer features.DefaultFeatureGate.Set(saved) // reset features on exit
+
+	deps := controller.NewFakeDependencies()
+	setControl := deps.StatefulSetControl.(*controller.FakeStatefulSetControl)
+	tc := &v1alpha1.TidbCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "tc"}}
+
+	replicas := int32(3)
+	oldSet := &apps.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "tc-tikv"},
+		Spec: apps.StatefulSetSpec{
+			Replicas:             &replicas,
+			ServiceName:          "tc-tikv-peer",
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{ObjectMeta: metav1.ObjectMeta{Name: "tikv"}}},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "tikv", Image: "tikv:v4.0.0"}}},
+			},
+		},
+		Status: apps.StatefulSetStatus{Replicas: 3},
+	}
+	g.Expect(SetStatefulSetLastAppliedConfigAnnotation(oldSet)).To(Succeed())
+	g.Expect(setControl.SetIndexer.Add(oldSet)).To(Succeed())
+
+	newSet := oldSet.DeepCopy()
+	newSet.Annotations = nil
+	newSet.Status = apps.StatefulSetStatus{}
+	newSet.Spec.ServiceName = "changed"
+	newSet.Spec.VolumeClaimTemplates = nil
+	newSet.Spec.Template.Spec.Containers[0].Image = "tikv:v5.0.0"
+	g.Expect(UpdateStatefulSet(setControl, tc, newSet, oldSet)).To(Succeed())
+
+	set, err := setControl.SetLister.StatefulSets("ns").Get("tc-tikv")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(set.Spec.Template.Spec.Containers[0].Image).To(Equal("tikv:v5.0.0"))
+	// the immutable fields are taken from the existing statefulset
+	g.Expect(set.Spec.ServiceName).To(Equal("tc-tikv-peer"))
+	g.Expect(set.Spec.VolumeClaimTemplates).To(HaveLen(1))
+	g.Expect(set.Status.Replicas).To(Equal(int32(3)))
+	g.Expect(set.Annotations).To(HaveKey(LastAppliedConfigAnnotation))
+}
+
+func TestSyncConfigSyncedCondition(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	set := &apps.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo-tikv", Generation: 1},
+		Spec: apps.StatefulSetSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Volumes: []corev1.Volume{{
+						Name: "config",
+						VolumeSource: corev1.VolumeSource{
+							ConfigMap: &corev1.ConfigMapVolumeSource{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "demo-tikv-aaa"},
+							},
+						},
+					}},
+				},
+			},
+		},
+		Status: apps.StatefulSetStatus{ObservedGeneration: 1, CurrentRevision: "1", UpdateRevision: "1"},
+	}
+	expectCondition := func(conditions []v1alpha1.ComponentCondition, status corev1.ConditionStatus, reason string) {
+		cond := utiltidbcluster.GetComponentCondition(conditions, v1alpha1.ComponentConfigSynced)
+		g.Expect(cond).NotTo(BeNil())
+		g.Expect(cond.Status).To(Equal(status))
+		g.Expect(cond.Reason).To(Equal(reason))
+	}
+
+	var conditions []v1alpha1.ComponentCondition
+	// the condition is not set before the statefulset is created
+	syncConfigSyncedCondition(&conditions, nil, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "demo-tikv-aaa"}}, "demo-tikv")
+	g.Expect(conditions).To(BeEmpty())
+
+	syncConfigSyncedCondition(&conditions, set, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "demo-tikv-aaa"}}, "demo-tikv")
+	expectCondition(conditions, corev1.ConditionTrue, utiltidbcluster.ConfigInSync)
+
+	// the config is changed
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "demo-tikv-bbb"}}
+	syncConfigSyncedCondition(&conditions, set, cm, "demo-tikv")
+	expectCondition(conditions, corev1.ConditionFalse, utiltidbcluster.ConfigOutOfSync)
+
+	// the statefulset mounts the new ConfigMap, and the pods are being restarted
+	set.Generation = 2
+	set.Spec.Template.Spec.Volumes[0].ConfigMap.Name = "demo-tikv-bbb"
+	syncConfigSyncedCondition(&conditions, set, cm, "demo-tikv")
+	expectCondition(conditions, corev1.ConditionFalse, utiltidbcluster.ConfigOutOfSync)
+	set.Status.ObservedGeneration = 2
+	set.Status.UpdateRevision = "2"
+	syncConfigSyncedCondition(&conditions, set, cm, "demo-tikv")
+	expectCondition(conditions, corev1.ConditionFalse, utiltidbcluster.ConfigOutOfSync)
+
+	// the rolling update is done
+	set.Status.CurrentRevision = "2"
+	syncConfigSyncedCondition(&conditions, set, cm, "demo-tikv")
+	expectCondition(conditions, corev1.ConditionTrue, utiltidbcluster.ConfigInSync)
+}
+
+func TestSyncUpgradeProgress(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	set := &apps.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo-tikv"},
+		Spec:       apps.StatefulSetSpec{Replicas: pointer.Int32Ptr(3)},
+	}
+	setUpgradePartition(set, 1)
+	status := &apps.StatefulSetStatus{UpdatedReplicas: 1}
+
+	progress := &v1alpha1.UpgradeProgress{}
+	syncUpgradeProgress(&progress, v1alpha1.NormalPhase, nil, set, status, "pingcap/tikv:v4.0.8")
+	g.Expect(progress).To(BeNil())
+
+	var conditions []v1alpha1.ComponentCondition
+	setUpgradingCondition(&conditions, utiltidbcluster.RollingUpdate, "")
+	syncUpgradeProgress(&progress, v1alpha1.UpgradePhase, conditions, set, status, "pingcap/tikv:v4.0.8")
+	g.Expect(progress).To(Equal(&v1alpha1.UpgradeProgress{
+		TargetVersion:   "v4.0.8",
+		Replicas:        3,
+		UpdatedReplicas: 1,
+		CurrentOrdinal:  pointer.Int32Ptr(1),
+	}))
+
+	// the upgrade is blocked
+	setUpgradingCondition(&conditions, utiltidbcluster.WaitingForLeaderEviction, "evicting leaders of store 1")
+	syncUpgradeProgress(&progress, v1alpha1.UpgradePhase, conditions, set, status, "pingcap/tikv:v4.0.8")
+	g.Expect(progress.BlockingReason).To(Equal(utiltidbcluster.WaitingForLeaderEviction))
+	g.Expect(progress.Message).To(Equal("evicting leaders of store 1"))
+
+	// all pods are upgraded
+	setUpgradePartition(set, 0)
+	status.UpdatedReplicas = 3
+	syncUpgradeProgress(&progress, v1alpha1.UpgradePhase, conditions, set, status, "pingcap/tikv:v4.0.8")
+	g.Expect(progress.UpdatedReplicas).To(Equal(int32(3)))
+	g.Expect(progress.CurrentOrdinal).To(BeNil())
+}
+
+func TestImageTag(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(imageTag("pingcap/pd:v4.0.8")).To(Equal("v4.0.8"))
+	g.Expect(imageTag("localhost:5000/pingcap/pd:v4.0.8")).To(Equal("v4.0.8"))
+	g.Expect(imageTag("localhost:5000/pingcap/pd")).To(Equal("latest"))
+	g.Expect(imageTag("pingcap/pd@sha256:abc")).To(Equal("sha256:abc"))
+}
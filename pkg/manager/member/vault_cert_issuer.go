@@ -0,0 +1,253 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/manager"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/pingcap/tidb-operator/pkg/util/crypto"
+	"github.com/pingcap/tidb-operator/pkg/vaultapi"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	errorutils "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+)
+
+const (
+	// TLSCertIssuedReason is the reason of the events emitted when a certificate is issued into a secret
+	TLSCertIssuedReason = "TLSCertIssued"
+
+	// serviceAccountTokenPath is the path of the service account JWT of the operator
+	serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	// vaultTokenKey is the key of the Vault token in the secret of `spec.tlsCluster.vault.tokenSecretName`
+	vaultTokenKey = "token"
+)
+
+// vaultCertIssuer issues the certificates of the cluster secrets of the TidbCluster from the Vault
+// PKI role when `spec.tlsCluster.vault` is set, one for each component and one for the client used
+// by the operator.
+//
+// A certificate is issued if the secret does not exist, if the certificate expires within the renew
+// period, or if the SANs of the certificate do not match the components. The pods are restarted
+// to reload the re-issued certificates by the TLS certificate rotator if `spec.tlsCluster.certRotation`
+// is set with the issuer Vault.
+type vaultCertIssuer struct {
+	deps *controller.Dependencies
+	// newClient and jwtPath are replaced by the tests
+	newClient func(address string, tlsConfig *tls.Config) vaultapi.VaultClient
+	jwtPath   string
+}
+
+// NewVaultCertIssuer returns an issuer of the certificates of the cluster secrets from Vault
+func NewVaultCertIssuer(deps *controller.Dependencies) manager.Manager {
+	return &vaultCertIssuer{
+		deps: deps,
+		newClient: func(address string, tlsConfig *tls.Config) vaultapi.VaultClient {
+			return vaultapi.NewVaultClient(address, vaultapi.DefaultTimeout, tlsConfig)
+		},
+		jwtPath: serviceAccountTokenPath,
+	}
+}
+
+func (v *vaultCertIssuer) Sync(tc *v1alpha1.TidbCluster) error {
+	if !tc.VaultEnabled() {
+		return nil
+	}
+
+	type pendingCert struct {
+		component  string
+		secretName string
+		dnsNames   []string
+	}
+	var pending []pendingCert
+	var errs []error
+	for _, memberType := range clusterTLSMemberTypes {
+		dnsNames := clusterTLSCertDNSNames(tc, memberType)
+		if dnsNames == nil {
+			continue
+		}
+		secretName := util.ClusterTLSSecretName(tc.GetName(), memberType.String())
+		needed, err := v.needsIssue(tc, secretName, dnsNames)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if needed {
+			pending = append(pending, pendingCert{component: memberType.String(), secretName: secretName, dnsNames: dnsNames})
+		}
+	}
+	clientSecretName := util.ClusterClientTLSSecretName(tc.GetName())
+	needed, err := v.needsIssue(tc, clientSecretName, nil)
+	if err != nil {
+		errs = append(errs, err)
+	} else if needed {
+		pending = append(pending, pendingCert{component: tlsCertClientComponent, secretName: clientSecretName})
+	}
+	if len(pending) == 0 {
+		return errorutils.NewAggregate(errs)
+	}
+
+	client, err := v.login(tc)
+	if err != nil {
+		return err
+	}
+	for _, p := range pending {
+		if err := v.issue(tc, client, p.component, p.secretName, p.dnsNames); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errorutils.NewAggregate(errs)
+}
+
+// needsIssue returns whether the certificate in the secret is to be issued
+func (v *vaultCertIssuer) needsIssue(tc *v1alpha1.TidbCluster, secretName string, dnsNames []string) (bool, error) {
+	ns := tc.GetNamespace()
+	secret, err := v.deps.KubeClientset.CoreV1().Secrets(ns).Get(secretName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("vaultCertIssuer: failed to get secret %s/%s for tc %s, error: %v", ns, secretName, tc.GetName(), err)
+	}
+	cert, err := crypto.DecodeCertificate(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		klog.Warningf("vaultCertIssuer: failed to decode the certificate in secret %s/%s, issue it again, error: %v", ns, secretName, err)
+		return true, nil
+	}
+	if time.Until(cert.NotAfter) <= tc.Spec.TLSCluster.Vault.GetRenewBefore() {
+		klog.Infof("vaultCertIssuer: the certificate in secret %s/%s expires at %s, issue it again", ns, secretName, cert.NotAfter)
+		return true, nil
+	}
+	if !sets.NewString(cert.DNSNames...).Equal(sets.NewString(dnsNames...)) {
+		klog.Infof("vaultCertIssuer: the SANs of the certificate in secret %s/%s are changed, issue it again", ns, secretName)
+		return true, nil
+	}
+	return false, nil
+}
+
+// login returns a Vault client authenticated by the token in the secret or the service account JWT
+func (v *vaultCertIssuer) login(tc *v1alpha1.TidbCluster) (vaultapi.VaultClient, error) {
+	ns := tc.GetNamespace()
+	vault := tc.Spec.TLSCluster.Vault
+	var tlsConfig *tls.Config
+	if vault.CASecretName != "" {
+		secret, err := v.deps.KubeClientset.CoreV1().Secrets(ns).Get(vault.CASecretName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("vaultCertIssuer: failed to get secret %s/%s for tc %s, error: %v", ns, vault.CASecretName, tc.GetName(), err)
+		}
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(secret.Data[corev1.ServiceAccountRootCAKey]) {
+			return nil, fmt.Errorf("vaultCertIssuer: failed to load the CA in secret %s/%s", ns, vault.CASecretName)
+		}
+		tlsConfig = &tls.Config{RootCAs: rootCAs}
+	}
+	client := v.newClient(vault.Address, tlsConfig)
+
+	switch {
+	case vault.TokenSecretName != "":
+		secret, err := v.deps.KubeClientset.CoreV1().Secrets(ns).Get(vault.TokenSecretName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("vaultCertIssuer: failed to get secret %s/%s for tc %s, error: %v", ns, vault.TokenSecretName, tc.GetName(), err)
+		}
+		token := strings.TrimSpace(string(secret.Data[vaultTokenKey]))
+		if token == "" {
+			return nil, fmt.Errorf("vaultCertIssuer: no %s is found in secret %s/%s", vaultTokenKey, ns, vault.TokenSecretName)
+		}
+		client.SetToken(token)
+	case vault.KubernetesAuth != nil:
+		jwt, err := ioutil.ReadFile(v.jwtPath)
+		if err != nil {
+			return nil, fmt.Errorf("vaultCertIssuer: failed to read the service account token, error: %v", err)
+		}
+		if err := client.LoginKubernetes(vault.KubernetesAuth.GetMountPath(), vault.KubernetesAuth.Role, strings.TrimSpace(string(jwt))); err != nil {
+			return nil, fmt.Errorf("vaultCertIssuer: failed to login to vault %s for tc %s/%s, error: %v", vault.Address, ns, tc.GetName(), err)
+		}
+	default:
+		return nil, fmt.Errorf("vaultCertIssuer: neither tokenSecretName nor kubernetesAuth is set for tc %s/%s", ns, tc.GetName())
+	}
+	return client, nil
+}
+
+// issue issues the certificate from the PKI role and saves it into the secret
+func (v *vaultCertIssuer) issue(tc *v1alpha1.TidbCluster, client vaultapi.VaultClient, component, secretName string, dnsNames []string) error {
+	ns := tc.GetNamespace()
+	vault := tc.Spec.TLSCluster.Vault
+	req := &vaultapi.IssueRequest{
+		CommonName: tlsCertCommonName,
+	}
+	if len(dnsNames) > 0 {
+		req.AltNames = strings.Join(dnsNames, ",")
+		req.IPSans = "127.0.0.1,::1"
+	}
+	if vault.TTL != nil {
+		req.TTL = vault.TTL.Duration.String()
+	}
+	cert, err := client.IssueCertificate(vault.GetPKIPath(), vault.Role, req)
+	if err != nil {
+		return fmt.Errorf("vaultCertIssuer: failed to issue the certificate for secret %s/%s, error: %v", ns, secretName, err)
+	}
+
+	data := map[string][]byte{
+		corev1.TLSCertKey:              []byte(cert.Certificate),
+		corev1.TLSPrivateKeyKey:        []byte(cert.PrivateKey),
+		corev1.ServiceAccountRootCAKey: []byte(cert.CABundle()),
+	}
+	secretClient := v.deps.KubeClientset.CoreV1().Secrets(ns)
+	secret, err := secretClient.Get(secretName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            secretName,
+				Namespace:       ns,
+				Labels:          label.New().Instance(tc.GetInstanceName()).Component(component),
+				OwnerReferences: []metav1.OwnerReference{controller.GetOwnerRef(tc)},
+			},
+			Data: data,
+		}
+		_, err = secretClient.Create(secret)
+	} else if err == nil {
+		secret = secret.DeepCopy()
+		secret.Data = data
+		_, err = secretClient.Update(secret)
+	}
+	if err != nil {
+		return fmt.Errorf("vaultCertIssuer: failed to save the certificate into secret %s/%s, error: %v", ns, secretName, err)
+	}
+	klog.Infof("vaultCertIssuer: the certificate issued by vault role %s is saved into secret %s/%s", vault.Role, ns, secretName)
+	v.deps.Recorder.Eventf(tc, corev1.EventTypeNormal, TLSCertIssuedReason, "the certificate issued by vault role %s is saved into secret %s", vault.Role, secretName)
+	return nil
+}
+
+type fakeVaultCertIssuer struct{}
+
+// NewFakeVaultCertIssuer returns a fake issuer of the certificates from Vault
+func NewFakeVaultCertIssuer() manager.Manager {
+	return &fakeVaultCertIssuer{}
+}
+
+func (v *fakeVaultCertIssuer) Sync(_ *v1alpha1.TidbCluster) error {
+	return nil
+}
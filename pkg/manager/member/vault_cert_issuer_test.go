@@ -0,0 +1,127 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/pingcap/tidb-operator/pkg/util/crypto"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVaultCertIssuer(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	issued := map[string]int{}
+	var ttl string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]string{}
+		g.Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+		switch r.URL.Path {
+		case "/v1/auth/kubernetes/login":
+			g.Expect(body).To(Equal(map[string]string{"role": "tidb-operator", "jwt": "service-account-jwt"}))
+			w.Write([]byte(`{"auth":{"client_token":"vault-token"}}`))
+		case "/v1/pki/issue/tidb":
+			g.Expect(r.Header.Get("X-Vault-Token")).To(Equal("vault-token"))
+			var dnsNames []string
+			if body["alt_names"] != "" {
+				dnsNames = strings.Split(body["alt_names"], ",")
+			}
+			issued[body["alt_names"]]++
+			ttl = body["ttl"]
+			certData, keyData := newTLSCertForTest(g, body["common_name"], dnsNames, 90*24*time.Hour)
+			data, err := json.Marshal(map[string]interface{}{
+				"data": map[string]interface{}{
+					"certificate": string(certData),
+					"private_key": string(keyData),
+					"issuing_ca":  string(certData),
+				},
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+			w.Write(data)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	jwtFile, err := ioutil.TempFile("", "jwt")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.Remove(jwtFile.Name())
+	_, err = jwtFile.WriteString("service-account-jwt\n")
+	g.Expect(err).NotTo(HaveOccurred())
+	jwtFile.Close()
+
+	tc := newTidbClusterForPD()
+	tc.Spec.TLSCluster = &v1alpha1.TLSCluster{
+		Enabled: true,
+		Vault: &v1alpha1.TLSVault{
+			Address:        server.URL,
+			Role:           "tidb",
+			TTL:            &metav1.Duration{Duration: 90 * 24 * time.Hour},
+			KubernetesAuth: &v1alpha1.VaultKubernetesAuth{Role: "tidb-operator"},
+		},
+	}
+	fakeDeps := controller.NewFakeDependencies()
+	issuer := NewVaultCertIssuer(fakeDeps).(*vaultCertIssuer)
+	issuer.jwtPath = jwtFile.Name()
+	secretClient := fakeDeps.KubeClientset.CoreV1().Secrets(tc.Namespace)
+
+	// the certificates of the components and the client are issued
+	g.Expect(issuer.Sync(tc)).To(Succeed())
+	g.Expect(issued).To(HaveLen(4))
+	g.Expect(ttl).To(Equal("2160h0m0s"))
+	for _, component := range []string{"pd", "tikv", "tidb"} {
+		secret, err := secretClient.Get(util.ClusterTLSSecretName(tc.Name, component), metav1.GetOptions{})
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(metav1.IsControlledBy(secret, tc)).To(BeTrue())
+		g.Expect(secret.Data[corev1.ServiceAccountRootCAKey]).NotTo(BeEmpty())
+		g.Expect(secret.Data[corev1.TLSPrivateKeyKey]).NotTo(BeEmpty())
+		cert, err := crypto.DecodeCertificate(secret.Data[corev1.TLSCertKey])
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(cert.DNSNames).To(ContainElement("*." + tc.Name + "-" + component + "-peer." + tc.Namespace + ".svc"))
+	}
+	_, err = secretClient.Get(util.ClusterClientTLSSecretName(tc.Name), metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// nothing is issued if the certificates are valid
+	g.Expect(issuer.Sync(tc)).To(Succeed())
+	for _, count := range issued {
+		g.Expect(count).To(Equal(1))
+	}
+
+	// the certificates are issued again if the SANs change or they are to expire
+	tc.Spec.ClusterDomain = "cluster.local"
+	tc.Spec.TLSCluster.Vault.RenewBefore = &metav1.Duration{Duration: 100 * 24 * time.Hour}
+	g.Expect(issuer.Sync(tc)).To(Succeed())
+	g.Expect(issued).To(HaveLen(7))
+	g.Expect(issued[""]).To(Equal(2))
+	secret, err := secretClient.Get(util.ClusterTLSSecretName(tc.Name, "pd"), metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	cert, err := crypto.DecodeCertificate(secret.Data[corev1.TLSCertKey])
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cert.DNSNames).To(ContainElement("test-pd.default.svc.cluster.local"))
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/label"
 	"github.com/pingcap/tidb-operator/pkg/manager"
 	"github.com/pingcap/tidb-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/klog"
 )
@@ -49,6 +50,7 @@ func (m *metaManager) Sync(tc *v1alpha1.TidbCluster) error {
 		return fmt.Errorf("metaManager.Sync: failed to list pods for cluster %s/%s, selector: %s, error: %v", ns, instanceName, l, err)
 	}
 
+	pvcAnnotations := tc.PVCAnnotations()
 	for _, pod := range pods {
 		// update meta info for pod
 		_, err := m.deps.PodControl.UpdateMetaInfo(tc, pod)
@@ -86,6 +88,9 @@ func (m *metaManager) Sync(tc *v1alpha1.TidbCluster) error {
 			if err != nil {
 				return err
 			}
+			if err := m.syncPVCAnnotations(tc, pvc, pvcAnnotations[pod.Labels[label.ComponentLabelKey]]); err != nil {
+				return err
+			}
 			if pvc.Spec.VolumeName == "" {
 				continue
 			}
@@ -110,6 +115,30 @@ func (m *metaManager) Sync(tc *v1alpha1.TidbCluster) error {
 	return nil
 }
 
+// syncPVCAnnotations adds the PVC annotations in the spec to the PVC, the annotations removed
+// from the spec are kept because they can not be told from the ones added by others
+func (m *metaManager) syncPVCAnnotations(tc *v1alpha1.TidbCluster, pvc *corev1.PersistentVolumeClaim, annotations map[string]string) error {
+	synced := true
+	for k, v := range annotations {
+		if pvc.Annotations[k] != v {
+			synced = false
+			break
+		}
+	}
+	if synced {
+		return nil
+	}
+	pvc = pvc.DeepCopy()
+	if pvc.Annotations == nil {
+		pvc.Annotations = map[string]string{}
+	}
+	for k, v := range annotations {
+		pvc.Annotations[k] = v
+	}
+	_, err := m.deps.PVCControl.UpdatePVC(tc, pvc)
+	return err
+}
+
 var _ manager.Manager = &metaManager{}
 
 type FakeMetaManager struct {
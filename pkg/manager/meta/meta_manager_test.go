@@ -350,6 +350,31 @@ func TestMetaManagerSync(t *testing.T) {
 		testFn(&tests[i], t)
 	}
 }
+func TestMetaManagerSyncPVCAnnotations(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForMeta()
+	tc.Spec.TiKV = &v1alpha1.TiKVSpec{
+		ComponentSpec: v1alpha1.ComponentSpec{
+			PVCAnnotations: map[string]string{"ebs.csi.aws.com/iops": "6000"},
+		},
+	}
+	pvc1 := newPVC(tc, "1")
+	pvc1.Annotations = map[string]string{"foo": "bar", "ebs.csi.aws.com/iops": "3000"}
+
+	nmm, _, _, _, podIndexer, pvcIndexer, pvIndexer := newFakeMetaManager()
+	g.Expect(podIndexer.Add(newPod(tc))).To(Succeed())
+	g.Expect(pvcIndexer.Add(pvc1)).To(Succeed())
+	g.Expect(pvIndexer.Add(newPV("1"))).To(Succeed())
+
+	g.Expect(nmm.Sync(tc)).To(Succeed())
+	pvc, err := nmm.deps.PVCLister.PersistentVolumeClaims(tc.Namespace).Get(pvc1.Name)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pvc.Annotations).To(HaveKeyWithValue("foo", "bar"))
+	g.Expect(pvc.Annotations).To(HaveKeyWithValue("ebs.csi.aws.com/iops", "6000"))
+	g.Expect(pvcMetaInfoMatchDesire(pvc)).To(BeTrue())
+}
+
 func TestMetaManagerSyncMultiPVC(t *testing.T) {
 	g := NewGomegaWithT(t)
 	type testcase struct {
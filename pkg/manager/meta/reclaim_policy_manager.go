@@ -39,18 +39,18 @@ func NewReclaimPolicyManager(deps *controller.Dependencies) *reclaimPolicyManage
 }
 
 func (m *reclaimPolicyManager) Sync(tc *v1alpha1.TidbCluster) error {
-	return m.sync(v1alpha1.TiDBClusterKind, tc, tc.IsPVReclaimEnabled(), *tc.Spec.PVReclaimPolicy)
+	return m.sync(v1alpha1.TiDBClusterKind, tc, tc.PVCDeletePolicies(), *tc.Spec.PVReclaimPolicy)
 }
 
 func (m *reclaimPolicyManager) SyncMonitor(tm *v1alpha1.TidbMonitor) error {
-	return m.sync(v1alpha1.TiDBMonitorKind, tm, false, *tm.Spec.PVReclaimPolicy)
+	return m.sync(v1alpha1.TiDBMonitorKind, tm, nil, *tm.Spec.PVReclaimPolicy)
 }
 
 func (m *reclaimPolicyManager) SyncDM(dc *v1alpha1.DMCluster) error {
-	return m.sync(v1alpha1.DMClusterKind, dc, dc.IsPVReclaimEnabled(), *dc.Spec.PVReclaimPolicy)
+	return m.sync(v1alpha1.DMClusterKind, dc, dc.PVCDeletePolicies(), *dc.Spec.PVReclaimPolicy)
 }
 
-func (m *reclaimPolicyManager) sync(kind string, obj runtime.Object, isPVReclaimEnabled bool, policy corev1.PersistentVolumeReclaimPolicy) error {
+func (m *reclaimPolicyManager) sync(kind string, obj runtime.Object, pvcDeletePolicies map[string]v1alpha1.PVCDeletePolicy, policy corev1.PersistentVolumeReclaimPolicy) error {
 	if m.deps.PVLister == nil {
 		klog.V(4).Infof("Persistent volumes lister is unavailable, skip syncing reclaim policy for %s. This may be caused by no relevant permissions", kind)
 		return nil
@@ -86,8 +86,8 @@ func (m *reclaimPolicyManager) sync(kind string, obj runtime.Object, isPVReclaim
 		if pvc.Spec.VolumeName == "" {
 			continue
 		}
-		if isPVReclaimEnabled && len(pvc.Annotations[label.AnnPVCDeferDeleting]) != 0 {
-			// If the PV reclaim setting is enabled, and when PV is a candidate to be reclaimed, skip patching this PV.
+		if pvcDeletePolicies[label.Label(pvc.Labels).ComponentType()] == v1alpha1.PVCDeletePolicyDelete && len(pvc.Annotations[label.AnnPVCDeferDeleting]) != 0 {
+			// If the PVC delete policy of the component is Delete, and when PV is a candidate to be reclaimed, skip patching this PV.
 			continue
 		}
 		if l := label.Label(pvc.Labels); kind == v1alpha1.TiDBClusterKind && (!l.IsPD() && !l.IsTiDB() && !l.IsTiKV() && !l.IsTiFlash() && !l.IsPump()) {
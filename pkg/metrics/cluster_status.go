@@ -0,0 +1,205 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
+)
+
+var (
+	clusterReadyDesc = prometheus.NewDesc(
+		"tidb_operator_cluster_ready",
+		"Whether the TidbCluster is ready, 1 if ready and 0 otherwise",
+		[]string{LabelNamespace, LabelName}, nil)
+	pdMemberHealthDesc = prometheus.NewDesc(
+		"tidb_operator_cluster_pd_member_health",
+		"Whether the PD member is healthy reported by PD, 1 if healthy and 0 otherwise",
+		[]string{LabelNamespace, LabelName, LabelMember}, nil)
+	storeStateDesc = prometheus.NewDesc(
+		"tidb_operator_cluster_store_state",
+		"The state of the TiKV or TiFlash store reported by PD, 1 for the current state",
+		[]string{LabelNamespace, LabelName, LabelComponent, LabelStoreID, LabelPod, LabelState}, nil)
+	storeRegionCountDesc = prometheus.NewDesc(
+		"tidb_operator_cluster_store_region_count",
+		"The number of the region peers on the TiKV or TiFlash store reported by PD",
+		[]string{LabelNamespace, LabelName, LabelComponent, LabelStoreID, LabelPod}, nil)
+	storeLeaderCountDesc = prometheus.NewDesc(
+		"tidb_operator_cluster_store_leader_count",
+		"The number of the region leaders on the TiKV or TiFlash store reported by PD",
+		[]string{LabelNamespace, LabelName, LabelComponent, LabelStoreID, LabelPod}, nil)
+	clusterSyncAgeDesc = prometheus.NewDesc(
+		"tidb_operator_cluster_sync_age_seconds",
+		"Seconds since the TidbCluster was synced successfully, or since the operator started if it has not been synced yet",
+		[]string{LabelNamespace, LabelName}, nil)
+	componentDesiredReplicasDesc = prometheus.NewDesc(
+		"tidb_operator_cluster_component_desired_replicas",
+		"The replicas of the component in the spec of the TidbCluster",
+		[]string{LabelNamespace, LabelName, LabelComponent}, nil)
+	componentHealthyReplicasDesc = prometheus.NewDesc(
+		"tidb_operator_cluster_component_healthy_replicas",
+		"The number of the healthy members of the component, i.e. the healthy PD and TiDB members, the Up TiKV and TiFlash stores, the TiCDC captures and the online Pump nodes",
+		[]string{LabelNamespace, LabelName, LabelComponent}, nil)
+)
+
+// clusterStatusCollector exports the metrics of the TidbClusters derived from the data fetched
+// from PD by the operator, which is recorded in the status, so the clusters without a TidbMonitor
+// still have the basic visibility. The metrics are collected from the informer cache when scraped,
+// so the metrics of the deleted clusters and stores are dropped without any bookkeeping.
+type clusterStatusCollector struct {
+	// listers are the listers of the watched namespaces
+	listers []listers.TidbClusterLister
+	// startTime is the time the collector is created, the sync age of the
+	// clusters which have not been synced yet counts from it
+	startTime time.Time
+}
+
+// RegisterClusterStatusCollector registers the collector of the metrics derived from the status of the TidbClusters.
+func RegisterClusterStatusCollector(tcListers ...listers.TidbClusterLister) {
+	prometheus.MustRegister(NewClusterStatusCollector(tcListers...))
+}
+
+// NewClusterStatusCollector returns the collector of the metrics derived from the status of the TidbClusters.
+func NewClusterStatusCollector(tcListers ...listers.TidbClusterLister) prometheus.Collector {
+	return &clusterStatusCollector{listers: tcListers, startTime: time.Now()}
+}
+
+func (c *clusterStatusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- clusterReadyDesc
+	ch <- pdMemberHealthDesc
+	ch <- storeStateDesc
+	ch <- storeRegionCountDesc
+	ch <- storeLeaderCountDesc
+	ch <- clusterSyncAgeDesc
+	ch <- componentDesiredReplicasDesc
+	ch <- componentHealthyReplicasDesc
+}
+
+func (c *clusterStatusCollector) Collect(ch chan<- prometheus.Metric) {
+	var tcs []*v1alpha1.TidbCluster
+	for _, lister := range c.listers {
+		list, err := lister.List(labels.Everything())
+		if err != nil {
+			klog.Errorf("failed to list TidbClusters to collect metrics, error: %v", err)
+			return
+		}
+		tcs = append(tcs, list...)
+	}
+	keys := make([]string, 0, len(tcs))
+	for _, tc := range tcs {
+		keys = append(keys, tc.GetNamespace()+"/"+tc.GetName())
+	}
+	syncTimes := clusterLastSyncTimes(keys)
+	now := time.Now()
+	for i, tc := range tcs {
+		ns, name := tc.GetNamespace(), tc.GetName()
+
+		syncTime, ok := syncTimes[keys[i]]
+		if !ok {
+			syncTime = c.startTime
+		}
+		ch <- prometheus.MustNewConstMetric(clusterSyncAgeDesc, prometheus.GaugeValue, now.Sub(syncTime).Seconds(), ns, name)
+
+		ready := 0.0
+		for _, cond := range tc.Status.Conditions {
+			if cond.Type == v1alpha1.TidbClusterReady && cond.Status == corev1.ConditionTrue {
+				ready = 1
+			}
+		}
+		ch <- prometheus.MustNewConstMetric(clusterReadyDesc, prometheus.GaugeValue, ready, ns, name)
+
+		for _, member := range tc.Status.PD.Members {
+			health := 0.0
+			if member.Health {
+				health = 1
+			}
+			ch <- prometheus.MustNewConstMetric(pdMemberHealthDesc, prometheus.GaugeValue, health, ns, name, member.Name)
+		}
+
+		collectStores(ch, ns, name, v1alpha1.TiKVMemberType, tc.Status.TiKV.Stores)
+		collectStores(ch, ns, name, v1alpha1.TiFlashMemberType, tc.Status.TiFlash.Stores)
+		collectReplicas(ch, tc)
+	}
+}
+
+// collectReplicas exports the desired and the healthy replicas of the components in the spec
+func collectReplicas(ch chan<- prometheus.Metric, tc *v1alpha1.TidbCluster) {
+	ns, name := tc.GetNamespace(), tc.GetName()
+	replicas := func(memberType v1alpha1.MemberType, desired int32, healthy int) {
+		component := memberType.String()
+		ch <- prometheus.MustNewConstMetric(componentDesiredReplicasDesc, prometheus.GaugeValue, float64(desired), ns, name, component)
+		ch <- prometheus.MustNewConstMetric(componentHealthyReplicasDesc, prometheus.GaugeValue, float64(healthy), ns, name, component)
+	}
+	upStores := func(stores map[string]v1alpha1.TiKVStore) int {
+		count := 0
+		for _, store := range stores {
+			if store.State == v1alpha1.TiKVStateUp {
+				count++
+			}
+		}
+		return count
+	}
+
+	if tc.Spec.PD != nil {
+		healthy := 0
+		for _, member := range tc.Status.PD.Members {
+			if member.Health {
+				healthy++
+			}
+		}
+		replicas(v1alpha1.PDMemberType, tc.Spec.PD.Replicas, healthy)
+	}
+	if tc.Spec.TiKV != nil {
+		replicas(v1alpha1.TiKVMemberType, tc.Spec.TiKV.Replicas, upStores(tc.Status.TiKV.Stores))
+	}
+	if tc.Spec.TiDB != nil {
+		healthy := 0
+		for _, member := range tc.Status.TiDB.Members {
+			if member.Health {
+				healthy++
+			}
+		}
+		replicas(v1alpha1.TiDBMemberType, tc.Spec.TiDB.Replicas, healthy)
+	}
+	if tc.Spec.TiFlash != nil {
+		replicas(v1alpha1.TiFlashMemberType, tc.Spec.TiFlash.Replicas, upStores(tc.Status.TiFlash.Stores))
+	}
+	if tc.Spec.TiCDC != nil {
+		replicas(v1alpha1.TiCDCMemberType, tc.Spec.TiCDC.Replicas, len(tc.Status.TiCDC.Captures))
+	}
+	if tc.Spec.Pump != nil {
+		healthy := 0
+		for _, node := range tc.Status.Pump.Members {
+			if node.State == "online" {
+				healthy++
+			}
+		}
+		replicas(v1alpha1.PumpMemberType, tc.Spec.Pump.Replicas, healthy)
+	}
+}
+
+func collectStores(ch chan<- prometheus.Metric, ns, name string, memberType v1alpha1.MemberType, stores map[string]v1alpha1.TiKVStore) {
+	component := memberType.String()
+	for _, store := range stores {
+		ch <- prometheus.MustNewConstMetric(storeStateDesc, prometheus.GaugeValue, 1, ns, name, component, store.ID, store.PodName, store.State)
+		ch <- prometheus.MustNewConstMetric(storeRegionCountDesc, prometheus.GaugeValue, float64(store.RegionCount), ns, name, component, store.ID, store.PodName)
+		ch <- prometheus.MustNewConstMetric(storeLeaderCountDesc, prometheus.GaugeValue, float64(store.LeaderCount), ns, name, component, store.ID, store.PodName)
+	}
+}
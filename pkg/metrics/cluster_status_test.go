@@ -0,0 +1,142 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestClusterStatusCollector(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "tc"},
+		Spec: v1alpha1.TidbClusterSpec{
+			PD:   &v1alpha1.PDSpec{Replicas: 3},
+			TiKV: &v1alpha1.TiKVSpec{Replicas: 1},
+		},
+		Status: v1alpha1.TidbClusterStatus{
+			Conditions: []v1alpha1.TidbClusterCondition{{Type: v1alpha1.TidbClusterReady, Status: corev1.ConditionTrue}},
+			PD: v1alpha1.PDStatus{
+				Members: map[string]v1alpha1.PDMember{
+					"tc-pd-0": {Name: "tc-pd-0", Health: true},
+					"tc-pd-1": {Name: "tc-pd-1"},
+				},
+			},
+			TiKV: v1alpha1.TiKVStatus{
+				Stores: map[string]v1alpha1.TiKVStore{
+					"1": {ID: "1", PodName: "tc-tikv-0", State: v1alpha1.TiKVStateUp, LeaderCount: 10, RegionCount: 30},
+				},
+			},
+			TiFlash: v1alpha1.TiFlashStatus{
+				Stores: map[string]v1alpha1.TiKVStore{
+					"2": {ID: "2", PodName: "tc-tiflash-0", State: v1alpha1.TiKVStateDown, RegionCount: 5},
+				},
+			},
+		},
+	}
+	g.Expect(indexer.Add(tc)).To(Succeed())
+	g.Expect(indexer.Add(&v1alpha1.TidbCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "tc2"}})).To(Succeed())
+
+	collector := NewClusterStatusCollector(listers.NewTidbClusterLister(indexer))
+	expected := `
+# HELP tidb_operator_cluster_component_desired_replicas The replicas of the component in the spec of the TidbCluster
+# TYPE tidb_operator_cluster_component_desired_replicas gauge
+tidb_operator_cluster_component_desired_replicas{component="pd",name="tc",namespace="ns"} 3
+tidb_operator_cluster_component_desired_replicas{component="tikv",name="tc",namespace="ns"} 1
+# HELP tidb_operator_cluster_component_healthy_replicas The number of the healthy members of the component, i.e. the healthy PD and TiDB members, the Up TiKV and TiFlash stores, the TiCDC captures and the online Pump nodes
+# TYPE tidb_operator_cluster_component_healthy_replicas gauge
+tidb_operator_cluster_component_healthy_replicas{component="pd",name="tc",namespace="ns"} 1
+tidb_operator_cluster_component_healthy_replicas{component="tikv",name="tc",namespace="ns"} 1
+# HELP tidb_operator_cluster_pd_member_health Whether the PD member is healthy reported by PD, 1 if healthy and 0 otherwise
+# TYPE tidb_operator_cluster_pd_member_health gauge
+tidb_operator_cluster_pd_member_health{member="tc-pd-0",name="tc",namespace="ns"} 1
+tidb_operator_cluster_pd_member_health{member="tc-pd-1",name="tc",namespace="ns"} 0
+# HELP tidb_operator_cluster_ready Whether the TidbCluster is ready, 1 if ready and 0 otherwise
+# TYPE tidb_operator_cluster_ready gauge
+tidb_operator_cluster_ready{name="tc",namespace="ns"} 1
+tidb_operator_cluster_ready{name="tc2",namespace="ns"} 0
+# HELP tidb_operator_cluster_store_leader_count The number of the region leaders on the TiKV or TiFlash store reported by PD
+# TYPE tidb_operator_cluster_store_leader_count gauge
+tidb_operator_cluster_store_leader_count{component="tiflash",name="tc",namespace="ns",pod="tc-tiflash-0",store_id="2"} 0
+tidb_operator_cluster_store_leader_count{component="tikv",name="tc",namespace="ns",pod="tc-tikv-0",store_id="1"} 10
+# HELP tidb_operator_cluster_store_region_count The number of the region peers on the TiKV or TiFlash store reported by PD
+# TYPE tidb_operator_cluster_store_region_count gauge
+tidb_operator_cluster_store_region_count{component="tiflash",name="tc",namespace="ns",pod="tc-tiflash-0",store_id="2"} 5
+tidb_operator_cluster_store_region_count{component="tikv",name="tc",namespace="ns",pod="tc-tikv-0",store_id="1"} 30
+# HELP tidb_operator_cluster_store_state The state of the TiKV or TiFlash store reported by PD, 1 for the current state
+# TYPE tidb_operator_cluster_store_state gauge
+tidb_operator_cluster_store_state{component="tiflash",name="tc",namespace="ns",pod="tc-tiflash-0",state="Down",store_id="2"} 1
+tidb_operator_cluster_store_state{component="tikv",name="tc",namespace="ns",pod="tc-tikv-0",state="Up",store_id="1"} 1
+`
+	g.Expect(testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"tidb_operator_cluster_component_desired_replicas",
+		"tidb_operator_cluster_component_healthy_replicas",
+		"tidb_operator_cluster_pd_member_health",
+		"tidb_operator_cluster_ready",
+		"tidb_operator_cluster_store_leader_count",
+		"tidb_operator_cluster_store_region_count",
+		"tidb_operator_cluster_store_state",
+	)).To(Succeed())
+}
+
+func TestClusterSyncAge(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	g.Expect(indexer.Add(&v1alpha1.TidbCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "synced"}})).To(Succeed())
+	g.Expect(indexer.Add(&v1alpha1.TidbCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "unsynced"}})).To(Succeed())
+	RecordClusterSynced("ns/synced")
+	RecordClusterSynced("ns/deleted")
+
+	collector := &clusterStatusCollector{
+		listers:   []listers.TidbClusterLister{listers.NewTidbClusterLister(indexer)},
+		startTime: time.Now().Add(-time.Hour),
+	}
+	registry := prometheus.NewPedanticRegistry()
+	g.Expect(registry.Register(collector)).To(Succeed())
+	families, err := registry.Gather()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	ages := map[string]float64{}
+	for _, family := range families {
+		if family.GetName() != "tidb_operator_cluster_sync_age_seconds" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == LabelName {
+					ages[l.GetValue()] = m.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	g.Expect(ages).To(HaveLen(2))
+	g.Expect(ages["synced"]).To(BeNumerically("<", 60))
+	g.Expect(ages["unsynced"]).To(BeNumerically(">=", time.Hour.Seconds()))
+	// the records of the deleted clusters are dropped
+	g.Expect(clusterSyncTimes.times).NotTo(HaveKey("ns/deleted"))
+}
@@ -0,0 +1,184 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// Results of the reconciles.
+const (
+	// ReconcileResultSuccess means the object is synced successfully
+	ReconcileResultSuccess = "success"
+	// ReconcileResultRequeue means the object is requeued to wait for something
+	ReconcileResultRequeue = "requeue"
+	// ReconcileResultError means the reconcile failed
+	ReconcileResultError = "error"
+)
+
+var (
+	ReconcileDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "controller",
+			Name:      "reconcile_duration_seconds",
+			Help:      "Duration of the reconciles of each controller by result",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 15),
+		}, []string{LabelController, LabelResult})
+	ReconcileErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "controller",
+			Name:      "reconcile_errors_total",
+			Help:      "Number of the failed reconciles of each controller by reason",
+		}, []string{LabelController, LabelReason})
+	RequeueBackoff = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "controller",
+			Name:      "requeue_backoff_seconds",
+			Help:      "Current backoff of the objects which are requeued by the controller, the objects at the maximum backoff are in prolonged backoff",
+		}, []string{LabelController, LabelNamespace, LabelName})
+
+	workqueueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "workqueue",
+			Name:      "depth",
+			Help:      "Current depth of the work queue",
+		}, []string{LabelName})
+	workqueueAdds = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "workqueue",
+			Name:      "adds_total",
+			Help:      "Number of the adds handled by the work queue",
+		}, []string{LabelName})
+	workqueueLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "workqueue",
+			Name:      "queue_duration_seconds",
+			Help:      "How long in seconds an item stays in the work queue before being requested",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 4, 10),
+		}, []string{LabelName})
+	workqueueWorkDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "workqueue",
+			Name:      "work_duration_seconds",
+			Help:      "How long in seconds processing an item from the work queue takes",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 4, 10),
+		}, []string{LabelName})
+	workqueueUnfinishedWork = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "workqueue",
+			Name:      "unfinished_work_seconds",
+			Help:      "How many seconds of work has been done that is in progress and hasn't been observed by work_duration",
+		}, []string{LabelName})
+	workqueueLongestRunningProcessor = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "workqueue",
+			Name:      "longest_running_processor_seconds",
+			Help:      "How many seconds the longest running processor of the work queue has been running",
+		}, []string{LabelName})
+	workqueueRetries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "workqueue",
+			Name:      "retries_total",
+			Help:      "Number of the retries handled by the work queue",
+		}, []string{LabelName})
+)
+
+func registerControllerMetrics() {
+	prometheus.MustRegister(ReconcileDuration)
+	prometheus.MustRegister(ReconcileErrors)
+	prometheus.MustRegister(RequeueBackoff)
+	prometheus.MustRegister(workqueueDepth)
+	prometheus.MustRegister(workqueueAdds)
+	prometheus.MustRegister(workqueueLatency)
+	prometheus.MustRegister(workqueueWorkDuration)
+	prometheus.MustRegister(workqueueUnfinishedWork)
+	prometheus.MustRegister(workqueueLongestRunningProcessor)
+	prometheus.MustRegister(workqueueRetries)
+	// the provider only takes effect on the work queues created afterwards
+	workqueue.SetProvider(workqueueMetricsProvider{})
+}
+
+// workqueueMetricsProvider exports the metrics of the work queues of the controllers.
+type workqueueMetricsProvider struct{}
+
+func (workqueueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return workqueueDepth.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return workqueueAdds.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewLatencyMetric(name string) workqueue.HistogramMetric {
+	return workqueueLatency.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewWorkDurationMetric(name string) workqueue.HistogramMetric {
+	return workqueueWorkDuration.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return workqueueUnfinishedWork.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return workqueueLongestRunningProcessor.WithLabelValues(name)
+}
+
+func (workqueueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return workqueueRetries.WithLabelValues(name)
+}
+
+// clusterSyncTimes records the last time each TidbCluster is synced successfully by its key,
+// the sync age is exported by the cluster status collector for the existing clusters only.
+var clusterSyncTimes = struct {
+	sync.Mutex
+	times map[string]time.Time
+}{times: map[string]time.Time{}}
+
+// RecordClusterSynced records that the TidbCluster with the key is synced successfully.
+func RecordClusterSynced(key string) {
+	clusterSyncTimes.Lock()
+	defer clusterSyncTimes.Unlock()
+	clusterSyncTimes.times[key] = time.Now()
+}
+
+// clusterLastSyncTimes returns the last sync times of the TidbClusters with the keys,
+// the records of the other clusters are dropped as they are deleted.
+func clusterLastSyncTimes(keys []string) map[string]time.Time {
+	clusterSyncTimes.Lock()
+	defer clusterSyncTimes.Unlock()
+	times := make(map[string]time.Time, len(keys))
+	for _, key := range keys {
+		if t, ok := clusterSyncTimes.times[key]; ok {
+			times[key] = t
+		}
+	}
+	clusterSyncTimes.times = times
+	return times
+}
@@ -20,11 +20,24 @@ import (
 // RegisterMetrics registers all metrics of tidb-operator.
 func RegisterMetrics() {
 	prometheus.MustRegister(ClusterSpecReplicas)
+	prometheus.MustRegister(PDAPICacheRequests)
+	prometheus.MustRegister(ReplicationCheckpointLag)
+	registerControllerMetrics()
 }
 
 // Label constants.
 const (
-	LabelNamespace = "namespace"
-	LabelName      = "name"
-	LabelComponent = "component"
+	LabelNamespace  = "namespace"
+	LabelName       = "name"
+	LabelComponent  = "component"
+	LabelPredicate  = "predicate"
+	LabelPriority   = "priority"
+	LabelResult     = "result"
+	LabelEndpoint   = "endpoint"
+	LabelMember     = "member"
+	LabelStoreID    = "store_id"
+	LabelPod        = "pod"
+	LabelState      = "state"
+	LabelController = "controller"
+	LabelReason     = "reason"
 )
@@ -0,0 +1,36 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Results of the lookups in the PD API response cache.
+const (
+	// PDAPICacheResultHit means the response is served from the cache
+	PDAPICacheResultHit = "hit"
+	// PDAPICacheResultMiss means the response is fetched from PD
+	PDAPICacheResultMiss = "miss"
+)
+
+var (
+	PDAPICacheRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "pd_api",
+			Name:      "cache_requests_total",
+			Help:      "Number of requests to the PD API response cache by endpoint and result",
+		}, []string{LabelEndpoint, LabelResult})
+)
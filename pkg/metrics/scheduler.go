@@ -0,0 +1,73 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Results of the scheduler decisions.
+const (
+	// SchedulerResultPassed means some candidate nodes passed the predicate or the priority succeeded
+	SchedulerResultPassed = "passed"
+	// SchedulerResultPreferred means the predicate was not satisfied but the nodes were kept as its policy is preferred
+	SchedulerResultPreferred = "preferred"
+	// SchedulerResultRejected means all candidate nodes were rejected by the predicate
+	SchedulerResultRejected = "rejected"
+	// SchedulerResultFailed means the priority failed and was ignored
+	SchedulerResultFailed = "failed"
+)
+
+var (
+	SchedulerPredicateEvaluations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "scheduler",
+			Name:      "predicate_evaluations_total",
+			Help:      "Number of predicate evaluations of tidb-scheduler by result",
+		}, []string{LabelComponent, LabelPredicate, LabelResult})
+
+	SchedulerPredicateRejectedNodes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "scheduler",
+			Name:      "predicate_rejected_nodes_total",
+			Help:      "Number of candidate nodes rejected by the predicates of tidb-scheduler",
+		}, []string{LabelComponent, LabelPredicate})
+
+	SchedulerPredicateDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "scheduler",
+			Name:      "predicate_duration_seconds",
+			Help:      "Duration of the predicate evaluations of tidb-scheduler",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15),
+		}, []string{LabelComponent, LabelPredicate})
+
+	SchedulerPriorityEvaluations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "tidb_operator",
+			Subsystem: "scheduler",
+			Name:      "priority_evaluations_total",
+			Help:      "Number of priority evaluations of tidb-scheduler by result",
+		}, []string{LabelComponent, LabelPriority, LabelResult})
+)
+
+// RegisterSchedulerMetrics registers all metrics of tidb-scheduler.
+func RegisterSchedulerMetrics() {
+	prometheus.MustRegister(SchedulerPredicateEvaluations)
+	prometheus.MustRegister(SchedulerPredicateRejectedNodes)
+	prometheus.MustRegister(SchedulerPredicateDuration)
+	prometheus.MustRegister(SchedulerPriorityEvaluations)
+}
@@ -97,7 +97,7 @@ func (m *MonitorManager) SyncMonitor(monitor *v1alpha1.TidbMonitor) error {
 			}
 		}
 
-		if firstTc == nil && !tc.HeterogeneousWithoutLocalPD() {
+		if firstTc == nil && !tc.HeterogeneousWithoutLocalPD() && !tc.ExternalPDOnly() {
 			firstTc = tc
 		}
 		err = m.syncDashboardMetricStorage(tc, monitor)
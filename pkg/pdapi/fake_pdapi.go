@@ -31,6 +31,7 @@ const (
 	GetTombStoneStoresActionType       ActionType = "GetTombStoneStores"
 	GetStoreActionType                 ActionType = "GetStore"
 	DeleteStoreActionType              ActionType = "DeleteStore"
+	RemoveTombStoneActionType          ActionType = "RemoveTombStone"
 	SetStoreStateActionType            ActionType = "SetStoreState"
 	DeleteMemberByIDActionType         ActionType = "DeleteMemberByID"
 	DeleteMemberActionType             ActionType = "DeleteMember "
@@ -42,6 +43,17 @@ const (
 	GetPDLeaderActionType              ActionType = "GetPDLeader"
 	TransferPDLeaderActionType         ActionType = "TransferPDLeader"
 	GetAutoscalingPlansActionType      ActionType = "GetAutoscalingPlans"
+	GetEtcdAlarmsActionType            ActionType = "GetEtcdAlarms"
+	GetEtcdStatusActionType            ActionType = "GetEtcdStatus"
+	GetStoreRegionsActionType          ActionType = "GetStoreRegions"
+	GetStoreLeaderCountActionType      ActionType = "GetStoreLeaderCount"
+	TransferRegionLeaderActionType     ActionType = "TransferRegionLeader"
+	ScatterRegionActionType            ActionType = "ScatterRegion"
+	GetVersionActionType               ActionType = "GetVersion"
+	GetAPIVersionActionType            ActionType = "GetAPIVersion"
+	GetServiceMembersActionType        ActionType = "GetServiceMembers"
+	GetMinResolvedTSActionType         ActionType = "GetMinResolvedTS"
+	UpdateMemberPeerURLsActionType     ActionType = "UpdateMemberPeerURLs"
 )
 
 type NotFoundReaction struct {
@@ -57,6 +69,8 @@ type Action struct {
 	Name        string
 	Labels      map[string]string
 	Replication PDReplicationConfig
+	ToStoreID   uint64
+	PeerURLs    []string
 }
 
 type Reaction func(action *Action) (interface{}, error)
@@ -160,6 +174,15 @@ func (c *FakePDClient) DeleteStore(id uint64) error {
 	return nil
 }
 
+func (c *FakePDClient) RemoveTombStone() error {
+	if reaction, ok := c.reactions[RemoveTombStoneActionType]; ok {
+		action := &Action{}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
+
 func (c *FakePDClient) SetStoreState(id uint64, state string) error {
 	if reaction, ok := c.reactions[SetStoreStateActionType]; ok {
 		action := &Action{ID: id}
@@ -178,6 +201,15 @@ func (c *FakePDClient) DeleteMemberByID(id uint64) error {
 	return nil
 }
 
+func (c *FakePDClient) UpdateMemberPeerURLs(id uint64, peerURLs []string) error {
+	if reaction, ok := c.reactions[UpdateMemberPeerURLsActionType]; ok {
+		action := &Action{ID: id, PeerURLs: peerURLs}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
+
 func (c *FakePDClient) DeleteMember(name string) error {
 	if reaction, ok := c.reactions[DeleteMemberActionType]; ok {
 		action := &Action{Name: name}
@@ -260,3 +292,93 @@ func (c *FakePDClient) GetAutoscalingPlans(strategy Strategy) ([]Plan, error) {
 	}
 	return nil, nil
 }
+
+func (c *FakePDClient) GetEtcdAlarms() ([]*EtcdAlarm, error) {
+	action := &Action{}
+	result, err := c.fakeAPI(GetEtcdAlarmsActionType, action)
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*EtcdAlarm), nil
+}
+
+func (c *FakePDClient) GetEtcdStatus() (*EtcdStatus, error) {
+	action := &Action{}
+	result, err := c.fakeAPI(GetEtcdStatusActionType, action)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*EtcdStatus), nil
+}
+
+func (c *FakePDClient) GetStoreRegions(storeID uint64) (*RegionsInfo, error) {
+	action := &Action{ID: storeID}
+	result, err := c.fakeAPI(GetStoreRegionsActionType, action)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*RegionsInfo), nil
+}
+
+func (c *FakePDClient) GetStoreLeaderCount(storeID uint64) (int, error) {
+	action := &Action{ID: storeID}
+	result, err := c.fakeAPI(GetStoreLeaderCountActionType, action)
+	if err != nil {
+		return 0, err
+	}
+	return result.(int), nil
+}
+
+func (c *FakePDClient) TransferRegionLeader(regionID, toStoreID uint64) error {
+	if reaction, ok := c.reactions[TransferRegionLeaderActionType]; ok {
+		action := &Action{ID: regionID, ToStoreID: toStoreID}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
+
+func (c *FakePDClient) ScatterRegion(regionID uint64) error {
+	if reaction, ok := c.reactions[ScatterRegionActionType]; ok {
+		action := &Action{ID: regionID}
+		_, err := reaction(action)
+		return err
+	}
+	return nil
+}
+
+func (c *FakePDClient) GetVersion() (string, error) {
+	action := &Action{}
+	result, err := c.fakeAPI(GetVersionActionType, action)
+	if err != nil {
+		return "", err
+	}
+	return result.(string), nil
+}
+
+func (c *FakePDClient) GetAPIVersion() (APIVersion, error) {
+	action := &Action{}
+	result, err := c.fakeAPI(GetAPIVersionActionType, action)
+	if err != nil {
+		return "", err
+	}
+	return result.(APIVersion), nil
+}
+
+func (c *FakePDClient) GetServiceMembers(service string) ([]*ServiceMember, error) {
+	action := &Action{Name: service}
+	result, err := c.fakeAPI(GetServiceMembersActionType, action)
+	if err != nil {
+		return nil, err
+	}
+	return result.([]*ServiceMember), nil
+}
+
+func (c *FakePDClient) GetMinResolvedTS() (uint64, error) {
+	action := &Action{}
+	result, err := c.fakeAPI(GetMinResolvedTSActionType, action)
+	if err != nil {
+		return 0, err
+	}
+	return result.(uint64), nil
+}
@@ -0,0 +1,176 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pdapi
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/tidb-operator/pkg/metrics"
+)
+
+// the endpoints whose responses are cached, or kept as the last-known responses by the guardedPDClient
+const (
+	cacheEndpointHealth          = "health"
+	cacheEndpointStores          = "stores"
+	cacheEndpointTombStoneStores = "tombstone_stores"
+	cacheEndpointMembers         = "members"
+	cacheEndpointLeader          = "leader"
+	cacheEndpointConfig          = "config"
+	cacheEndpointCluster         = "cluster"
+)
+
+type cacheEntry struct {
+	value    interface{}
+	expireAt time.Time
+}
+
+// cachedPDClient caches the responses of the read-only APIs which are called many times
+// by the member managers in a sync loop, e.g. stores, members and config, for a TTL.
+// The cache is dropped by the APIs which change the stores, members or config, so the
+// changes made by the operator are visible to the following calls immediately.
+//
+// The cached responses are shared by the callers, which must not modify them.
+type cachedPDClient struct {
+	PDClient
+	ttl time.Duration
+	// now returns the current time, it is replaced in unit tests
+	now func() time.Time
+
+	mutex   sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+func newCachedPDClient(client PDClient, ttl time.Duration) *cachedPDClient {
+	return &cachedPDClient{
+		PDClient: client,
+		ttl:      ttl,
+		now:      time.Now,
+		entries:  map[string]*cacheEntry{},
+	}
+}
+
+// get returns the cached response of the endpoint, or fetches and caches it if it is expired
+func (c *cachedPDClient) get(endpoint string, fetch func() (interface{}, error)) (interface{}, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := c.now()
+	if entry, ok := c.entries[endpoint]; ok && now.Before(entry.expireAt) {
+		metrics.PDAPICacheRequests.WithLabelValues(endpoint, metrics.PDAPICacheResultHit).Inc()
+		return entry.value, nil
+	}
+	metrics.PDAPICacheRequests.WithLabelValues(endpoint, metrics.PDAPICacheResultMiss).Inc()
+	value, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	c.entries[endpoint] = &cacheEntry{value: value, expireAt: now.Add(c.ttl)}
+	return value, nil
+}
+
+// invalidate drops the cached responses of the endpoints
+func (c *cachedPDClient) invalidate(endpoints ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, endpoint := range endpoints {
+		delete(c.entries, endpoint)
+	}
+}
+
+func (c *cachedPDClient) GetStores() (*StoresInfo, error) {
+	value, err := c.get(cacheEndpointStores, func() (interface{}, error) { return c.PDClient.GetStores() })
+	if err != nil {
+		return nil, err
+	}
+	return value.(*StoresInfo), nil
+}
+
+func (c *cachedPDClient) GetTombStoneStores() (*StoresInfo, error) {
+	value, err := c.get(cacheEndpointTombStoneStores, func() (interface{}, error) { return c.PDClient.GetTombStoneStores() })
+	if err != nil {
+		return nil, err
+	}
+	return value.(*StoresInfo), nil
+}
+
+func (c *cachedPDClient) GetMembers() (*MembersInfo, error) {
+	value, err := c.get(cacheEndpointMembers, func() (interface{}, error) { return c.PDClient.GetMembers() })
+	if err != nil {
+		return nil, err
+	}
+	return value.(*MembersInfo), nil
+}
+
+func (c *cachedPDClient) GetConfig() (*PDConfigFromAPI, error) {
+	value, err := c.get(cacheEndpointConfig, func() (interface{}, error) { return c.PDClient.GetConfig() })
+	if err != nil {
+		return nil, err
+	}
+	return value.(*PDConfigFromAPI), nil
+}
+
+func (c *cachedPDClient) GetCluster() (*metapb.Cluster, error) {
+	value, err := c.get(cacheEndpointCluster, func() (interface{}, error) { return c.PDClient.GetCluster() })
+	if err != nil {
+		return nil, err
+	}
+	return value.(*metapb.Cluster), nil
+}
+
+func (c *cachedPDClient) SetStoreLabels(storeID uint64, labels map[string]string) (bool, error) {
+	defer c.invalidate(cacheEndpointStores)
+	return c.PDClient.SetStoreLabels(storeID, labels)
+}
+
+func (c *cachedPDClient) UpdateReplicationConfig(config PDReplicationConfig) error {
+	defer c.invalidate(cacheEndpointConfig)
+	return c.PDClient.UpdateReplicationConfig(config)
+}
+
+func (c *cachedPDClient) DeleteStore(storeID uint64) error {
+	defer c.invalidate(cacheEndpointStores, cacheEndpointTombStoneStores)
+	return c.PDClient.DeleteStore(storeID)
+}
+
+func (c *cachedPDClient) RemoveTombStone() error {
+	defer c.invalidate(cacheEndpointStores, cacheEndpointTombStoneStores)
+	return c.PDClient.RemoveTombStone()
+}
+
+func (c *cachedPDClient) SetStoreState(storeID uint64, state string) error {
+	defer c.invalidate(cacheEndpointStores, cacheEndpointTombStoneStores)
+	return c.PDClient.SetStoreState(storeID, state)
+}
+
+func (c *cachedPDClient) DeleteMember(name string) error {
+	defer c.invalidate(cacheEndpointMembers)
+	return c.PDClient.DeleteMember(name)
+}
+
+func (c *cachedPDClient) DeleteMemberByID(memberID uint64) error {
+	defer c.invalidate(cacheEndpointMembers)
+	return c.PDClient.DeleteMemberByID(memberID)
+}
+
+func (c *cachedPDClient) UpdateMemberPeerURLs(memberID uint64, peerURLs []string) error {
+	defer c.invalidate(cacheEndpointMembers)
+	return c.PDClient.UpdateMemberPeerURLs(memberID, peerURLs)
+}
+
+func (c *cachedPDClient) TransferPDLeader(name string) error {
+	defer c.invalidate(cacheEndpointMembers)
+	return c.PDClient.TransferPDLeader(name)
+}
@@ -0,0 +1,107 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pdapi
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCachedPDClient(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeClient := NewFakePDClient()
+	getStoresCount := 0
+	getStoresErr := false
+	fakeClient.AddReaction(GetStoresActionType, func(action *Action) (interface{}, error) {
+		getStoresCount++
+		if getStoresErr {
+			return nil, fmt.Errorf("failed to get stores")
+		}
+		return &StoresInfo{Count: getStoresCount}, nil
+	})
+	fakeClient.AddReaction(DeleteStoreActionType, func(action *Action) (interface{}, error) {
+		return nil, nil
+	})
+
+	now := time.Now()
+	client := newCachedPDClient(fakeClient, 5*time.Second)
+	client.now = func() time.Time { return now }
+
+	stores, err := client.GetStores()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(stores.Count).To(Equal(1))
+
+	// served from the cache before the ttl expires
+	now = now.Add(4 * time.Second)
+	stores, err = client.GetStores()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(stores.Count).To(Equal(1))
+	g.Expect(getStoresCount).To(Equal(1))
+
+	// refetched after the ttl expires
+	now = now.Add(time.Second)
+	stores, err = client.GetStores()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(stores.Count).To(Equal(2))
+
+	// refetched after the stores are changed
+	g.Expect(client.DeleteStore(1)).To(Succeed())
+	stores, err = client.GetStores()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(stores.Count).To(Equal(3))
+
+	// errors are not cached
+	now = now.Add(5 * time.Second)
+	getStoresErr = true
+	_, err = client.GetStores()
+	g.Expect(err).To(HaveOccurred())
+	getStoresErr = false
+	stores, err = client.GetStores()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(stores.Count).To(Equal(5))
+}
+
+func TestPDControlPooledTransport(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	pdControl := NewDefaultPDControlWithOptions(kubefake.NewSimpleClientset(), PDControlOptions{CacheTTL: 5 * time.Second}).(*defaultPDControl)
+
+	client := pdControl.GetPDClient(Namespace("ns"), "tc", false)
+	_, ok := client.(*cachedPDClient)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(pdControl.GetPDClient(Namespace("ns"), "tc", false)).To(BeIdenticalTo(client))
+
+	// the clients of the same cluster share the transport
+	peerClient := pdControl.GetPeerPDClient(Namespace("ns"), "tc", false, "http://tc-pd-0.tc-pd-peer.ns.svc:2379", "tc-pd-0")
+	g.Expect(peerClient).NotTo(BeIdenticalTo(client))
+	g.Expect(pdControl.pdClientTransports["tc-pd-0"]).To(BeIdenticalTo(pdControl.pdClientTransports[pdClientKey("http", "ns", "tc")]))
+
+	// the clients of different clusters use different transports
+	pdControl.GetPDClient(Namespace("ns"), "tc2", false)
+	g.Expect(pdControl.pdClientTransports[pdClientKey("http", "ns", "tc2")]).NotTo(BeIdenticalTo(pdControl.pdClientTransports["tc-pd-0"]))
+
+	// the client is recreated if it is used by another cluster with the same client name
+	otherPeerClient := pdControl.GetPeerPDClient(Namespace("ns2"), "tc", false, "http://tc-pd-0.tc-pd-peer.ns2.svc:2379", "tc-pd-0")
+	g.Expect(otherPeerClient).NotTo(BeIdenticalTo(peerClient))
+
+	// no cache
+	pdControl = NewDefaultPDControl(kubefake.NewSimpleClientset()).(*defaultPDControl)
+	_, ok = pdControl.GetPDClient(Namespace("ns"), "tc", false).(*pdClient)
+	g.Expect(ok).To(BeTrue())
+}
@@ -18,12 +18,19 @@ import (
 	"fmt"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/pingcap/tidb-operator/pkg/util/tracing"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog"
 )
 
+const (
+	// transportIdleConnTimeout is the max time an idle connection of the pooled transports is kept
+	transportIdleConnTimeout = 90 * time.Second
+)
+
 // Namespace is a newtype of a string
 type Namespace string
 
@@ -39,14 +46,41 @@ type PDControlInterface interface {
 	GetPDEtcdClient(namespace Namespace, tcName string, tlsEnabled bool) (PDEtcdClient, error)
 	// GetEndpoints return the endpoints and client tls.Config to connection pd/etcd.
 	GetEndpoints(namespace Namespace, tcName string, tlsEnabled bool) (endpoints []string, tlsConfig *tls.Config, err error)
+	// GetPDCircuitState returns the state of the circuit breaker of the PD clients of the tidb cluster.
+	GetPDCircuitState(namespace Namespace, tcName string, tlsEnabled bool) CircuitState
+}
+
+// PDControlOptions are the options of the PD clients created by the defaultPDControl
+type PDControlOptions struct {
+	// CacheTTL is the TTL of the responses of the read-only APIs cached by the PD clients, 0 means no cache
+	CacheTTL time.Duration
+	// QPS is the max rate of the calls to the PD APIs of a cluster, 0 means no limit
+	QPS float64
+	// Burst is the max burst of the calls to the PD APIs of a cluster
+	Burst int
+	// CircuitBreaker is whether to short-circuit the calls to the PD APIs of a cluster if PD is unreachable
+	CircuitBreaker bool
+	// TLSConfigs is the cache of the client tls.Configs shared with the controls of the other components,
+	// a cache owned by the defaultPDControl is used if it is nil
+	TLSConfigs *TLSConfigCache
 }
 
 // defaultPDControl is the default implementation of PDControlInterface.
 type defaultPDControl struct {
-	kubeCli kubernetes.Interface
+	kubeCli    kubernetes.Interface
+	tlsConfigs *TLSConfigCache
 
 	mutex     sync.Mutex
 	pdClients map[string]PDClient
+	// transports are the HTTP transports shared by the PD clients of a cluster so that
+	// the connections are pooled, keyed by the scheme, the namespace and the name of the cluster
+	transports map[string]*pooledTransport
+	// pdClientTransports records the transport used by each PD client
+	pdClientTransports map[string]*http.Transport
+	// guards limit the rate of the calls to the PD APIs of a cluster and short-circuit them
+	// if PD is unreachable, keyed by the scheme, the namespace and the name of the cluster
+	guards  map[string]*pdGuard
+	options PDControlOptions
 
 	etcdmutex     sync.Mutex
 	pdEtcdClients map[string]PDEtcdClient
@@ -60,14 +94,39 @@ func (c *noOpClose) Close() error {
 	return nil
 }
 
+// pooledTransport is an HTTP transport shared by the PD clients of a cluster
+type pooledTransport struct {
+	*http.Transport
+	// tlsConfig is the client tls.Config the transport is created with
+	tlsConfig *tls.Config
+}
+
 // NewDefaultPDControl returns a defaultPDControl instance
 func NewDefaultPDControl(kubeCli kubernetes.Interface) PDControlInterface {
-	return &defaultPDControl{kubeCli: kubeCli, pdClients: map[string]PDClient{}, pdEtcdClients: map[string]PDEtcdClient{}}
+	return NewDefaultPDControlWithOptions(kubeCli, PDControlOptions{})
+}
+
+// NewDefaultPDControlWithOptions returns a defaultPDControl instance whose PD clients are created with the options
+func NewDefaultPDControlWithOptions(kubeCli kubernetes.Interface, options PDControlOptions) PDControlInterface {
+	tlsConfigs := options.TLSConfigs
+	if tlsConfigs == nil {
+		tlsConfigs = NewTLSConfigCache(kubeCli)
+	}
+	return &defaultPDControl{
+		kubeCli:            kubeCli,
+		tlsConfigs:         tlsConfigs,
+		pdClients:          map[string]PDClient{},
+		transports:         map[string]*pooledTransport{},
+		pdClientTransports: map[string]*http.Transport{},
+		guards:             map[string]*pdGuard{},
+		options:            options,
+		pdEtcdClients:      map[string]PDEtcdClient{},
+	}
 }
 
 func (c *defaultPDControl) GetEndpoints(namespace Namespace, tcName string, tlsEnabled bool) (endpoints []string, tlsConfig *tls.Config, err error) {
 	if tlsEnabled {
-		tlsConfig, err = GetTLSConfig(c.kubeCli, namespace, tcName, util.ClusterClientTLSSecretName(tcName))
+		tlsConfig, err = c.tlsConfigs.GetTLSConfig(namespace, util.ClusterClientTLSSecretName(tcName))
 		if err != nil {
 			return nil, nil, err
 		}
@@ -86,7 +145,7 @@ func (c *defaultPDControl) GetPDEtcdClient(namespace Namespace, tcName string, t
 	var err error
 
 	if tlsEnabled {
-		tlsConfig, err = GetTLSConfig(c.kubeCli, namespace, tcName, util.ClusterClientTLSSecretName(tcName))
+		tlsConfig, err = c.tlsConfigs.GetTLSConfig(namespace, util.ClusterClientTLSSecretName(tcName))
 		if err != nil {
 			klog.Errorf("Unable to get tls config for tidb cluster %q, pd etcd client may not work: %v", tcName, err)
 			return nil, err
@@ -136,22 +195,93 @@ func (pdc *defaultPDControl) GetPeerPDClient(namespace Namespace, tcName string,
 	pdc.mutex.Lock()
 	defer pdc.mutex.Unlock()
 
-	var tlsConfig *tls.Config
-	var err error
+	transport, err := pdc.getTransport(namespace, tcName, tlsEnabled)
+	if err != nil {
+		klog.Errorf("Unable to get tls config for tidb cluster %q in %s, pd client may not work: %v", tcName, namespace, err)
+		return &pdClient{url: clientURL, httpClient: &http.Client{Timeout: DefaultTimeout}}
+	}
+	if client, ok := pdc.pdClients[clientName]; ok {
+		// the client is recreated if the transport of the cluster is recreated
+		if t, ok := pdc.pdClientTransports[clientName]; !ok || t == transport {
+			return client
+		}
+	}
 
+	var client PDClient = &pdClient{
+		url:        clientURL,
+		httpClient: &http.Client{Timeout: DefaultTimeout, Transport: tracing.NewTransport(string(namespace), tcName, transport)},
+	}
+	if guard := pdc.getGuard(namespace, tcName, tlsEnabled); guard != nil {
+		client = newGuardedPDClient(client, guard)
+	}
+	if pdc.options.CacheTTL > 0 {
+		client = newCachedPDClient(client, pdc.options.CacheTTL)
+	}
+	pdc.pdClients[clientName] = client
+	pdc.pdClientTransports[clientName] = transport
+	return client
+}
+
+// getGuard returns the guard shared by the PD clients of the cluster, it returns nil
+// if neither the rate limit nor the circuit breaker is enabled
+func (pdc *defaultPDControl) getGuard(namespace Namespace, tcName string, tlsEnabled bool) *pdGuard {
+	if pdc.options.QPS <= 0 && !pdc.options.CircuitBreaker {
+		return nil
+	}
+	key := pdGuardKey(namespace, tcName, tlsEnabled)
+	if _, ok := pdc.guards[key]; !ok {
+		pdc.guards[key] = newPDGuard(pdc.options.QPS, pdc.options.Burst, pdc.options.CircuitBreaker)
+	}
+	return pdc.guards[key]
+}
+
+func (pdc *defaultPDControl) GetPDCircuitState(namespace Namespace, tcName string, tlsEnabled bool) CircuitState {
+	pdc.mutex.Lock()
+	defer pdc.mutex.Unlock()
+
+	guard, ok := pdc.guards[pdGuardKey(namespace, tcName, tlsEnabled)]
+	if !ok {
+		return CircuitClosed
+	}
+	return guard.getState()
+}
+
+func pdGuardKey(namespace Namespace, tcName string, tlsEnabled bool) string {
+	scheme := "http"
 	if tlsEnabled {
-		tlsConfig, err = GetTLSConfig(pdc.kubeCli, namespace, tcName, util.ClusterClientTLSSecretName(tcName))
+		scheme = "https"
+	}
+	return pdClientKey(scheme, namespace, tcName)
+}
+
+// getTransport returns the transport shared by the PD clients of the cluster.
+// For a TLS enabled cluster, the transport is recreated once the cached tls.Config
+// is reloaded from the secret, whose certificates may be rotated to avoid expiration.
+func (pdc *defaultPDControl) getTransport(namespace Namespace, tcName string, tlsEnabled bool) (*http.Transport, error) {
+	scheme := "http"
+	if tlsEnabled {
+		scheme = "https"
+	}
+	var tlsConfig *tls.Config
+	if tlsEnabled {
+		var err error
+		tlsConfig, err = pdc.tlsConfigs.GetTLSConfig(namespace, util.ClusterClientTLSSecretName(tcName))
 		if err != nil {
-			klog.Errorf("Unable to get tls config for tidb cluster %q in %s, pd client may not work: %v", tcName, namespace, err)
-			return &pdClient{url: clientURL, httpClient: &http.Client{Timeout: DefaultTimeout}}
+			return nil, err
 		}
+	}
 
-		return NewPDClient(clientURL, DefaultTimeout, tlsConfig)
+	key := pdClientKey(scheme, namespace, tcName)
+	old, ok := pdc.transports[key]
+	if ok && old.tlsConfig == tlsConfig {
+		return old.Transport, nil
 	}
-	if _, ok := pdc.pdClients[clientName]; !ok {
-		pdc.pdClients[clientName] = NewPDClient(clientURL, DefaultTimeout, nil)
+	if ok {
+		old.CloseIdleConnections()
 	}
-	return pdc.pdClients[clientName]
+	transport := &http.Transport{TLSClientConfig: tlsConfig, IdleConnTimeout: transportIdleConnTimeout}
+	pdc.transports[key] = &pooledTransport{Transport: transport, tlsConfig: tlsConfig}
+	return transport, nil
 }
 
 // pdClientKey returns the pd client key
@@ -197,7 +327,14 @@ type FakePDControl struct {
 
 func NewFakePDControl(kubeCli kubernetes.Interface) *FakePDControl {
 	return &FakePDControl{
-		defaultPDControl{kubeCli: kubeCli, pdClients: map[string]PDClient{}},
+		defaultPDControl{
+			kubeCli:            kubeCli,
+			tlsConfigs:         NewTLSConfigCache(kubeCli),
+			pdClients:          map[string]PDClient{},
+			transports:         map[string]*pooledTransport{},
+			pdClientTransports: map[string]*http.Transport{},
+			guards:             map[string]*pdGuard{},
+		},
 	}
 }
 
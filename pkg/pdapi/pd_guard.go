@@ -0,0 +1,403 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pdapi
+
+import (
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"golang.org/x/time/rate"
+)
+
+// CircuitState is the state of the circuit breaker of the PD clients of a cluster
+type CircuitState string
+
+const (
+	// CircuitClosed means the calls to PD are allowed
+	CircuitClosed CircuitState = "Closed"
+	// CircuitOpen means the calls to PD are short-circuited since PD is unreachable
+	CircuitOpen CircuitState = "Open"
+	// CircuitHalfOpen means a trial call is allowed to check whether PD recovers
+	CircuitHalfOpen CircuitState = "HalfOpen"
+)
+
+const (
+	// pdCircuitFailureThreshold is the number of consecutive failures to reach PD to open the circuit
+	pdCircuitFailureThreshold = 5
+	// pdCircuitOpenDuration is the duration to short-circuit the calls before a trial call is allowed
+	pdCircuitOpenDuration = 30 * time.Second
+)
+
+var (
+	// ErrPDCircuitOpen is returned if the call is short-circuited since PD is unreachable
+	ErrPDCircuitOpen = errors.New("the calls to PD are short-circuited since PD is unreachable")
+	// ErrPDRateLimited is returned if the call exceeds the rate limit of the PD APIs of the cluster
+	ErrPDRateLimited = errors.New("the calls to PD exceed the rate limit")
+)
+
+// IsPDUnavailableError returns whether the call is rejected by the circuit breaker or the rate limiter
+func IsPDUnavailableError(err error) bool {
+	return err == ErrPDCircuitOpen || err == ErrPDRateLimited
+}
+
+// isPDUnreachable returns whether the error is caused by failing to reach PD, e.g. connection
+// refused and timeout, instead of an error response from PD
+func isPDUnreachable(err error) bool {
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// pdGuard limits the rate of the calls to the PD APIs of a cluster and short-circuits them
+// after PD is unreachable for pdCircuitFailureThreshold consecutive calls. After
+// pdCircuitOpenDuration, a trial call is allowed and the circuit is closed if it succeeds.
+// It is shared by the PD clients of a cluster.
+type pdGuard struct {
+	// limiter is nil if the rate is not limited
+	limiter        *rate.Limiter
+	circuitBreaker bool
+	// now returns the current time, it is replaced in unit tests
+	now func() time.Time
+
+	mutex    sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+}
+
+func newPDGuard(qps float64, burst int, circuitBreaker bool) *pdGuard {
+	g := &pdGuard{
+		circuitBreaker: circuitBreaker,
+		now:            time.Now,
+		state:          CircuitClosed,
+	}
+	if qps > 0 {
+		g.limiter = rate.NewLimiter(rate.Limit(qps), burst)
+	}
+	return g
+}
+
+func (g *pdGuard) getState() CircuitState {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.state
+}
+
+// allow returns an error if the call is rejected by the circuit breaker or the rate limiter
+func (g *pdGuard) allow() error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	switch g.state {
+	case CircuitOpen:
+		if g.now().Sub(g.openedAt) < pdCircuitOpenDuration {
+			return ErrPDCircuitOpen
+		}
+	case CircuitHalfOpen:
+		// the trial call is in flight
+		return ErrPDCircuitOpen
+	}
+	if g.limiter != nil && !g.limiter.AllowN(g.now(), 1) {
+		return ErrPDRateLimited
+	}
+	if g.state == CircuitOpen {
+		g.state = CircuitHalfOpen
+	}
+	return nil
+}
+
+// done records the result of an allowed call
+func (g *pdGuard) done(err error) {
+	if !g.circuitBreaker {
+		return
+	}
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if err == nil || !isPDUnreachable(err) {
+		g.failures = 0
+		g.state = CircuitClosed
+		return
+	}
+	g.failures++
+	if g.state == CircuitHalfOpen || g.failures >= pdCircuitFailureThreshold {
+		g.state = CircuitOpen
+		g.openedAt = g.now()
+	}
+}
+
+// guardedPDClient calls PD through the guard of the cluster, except GetAPIVersion which is
+// negotiated by the underlying client occasionally. If a read-only call is rejected by the
+// guard, the last-known response is returned if any, so the status of the cluster can still be synced.
+type guardedPDClient struct {
+	PDClient
+	guard *pdGuard
+
+	mutex     sync.Mutex
+	lastKnown map[string]interface{}
+}
+
+func newGuardedPDClient(client PDClient, guard *pdGuard) *guardedPDClient {
+	return &guardedPDClient{
+		PDClient:  client,
+		guard:     guard,
+		lastKnown: map[string]interface{}{},
+	}
+}
+
+func (c *guardedPDClient) call(fn func() error) error {
+	if err := c.guard.allow(); err != nil {
+		return err
+	}
+	err := fn()
+	c.guard.done(err)
+	return err
+}
+
+func (c *guardedPDClient) read(endpoint string, fetch func() (interface{}, error)) (interface{}, error) {
+	var value interface{}
+	err := c.call(func() error {
+		var err error
+		value, err = fetch()
+		return err
+	})
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if err == nil {
+		c.lastKnown[endpoint] = value
+		return value, nil
+	}
+	if lastKnown, ok := c.lastKnown[endpoint]; ok && IsPDUnavailableError(err) {
+		return lastKnown, nil
+	}
+	return nil, err
+}
+
+func (c *guardedPDClient) GetHealth() (*HealthInfo, error) {
+	value, err := c.read(cacheEndpointHealth, func() (interface{}, error) { return c.PDClient.GetHealth() })
+	if err != nil {
+		return nil, err
+	}
+	return value.(*HealthInfo), nil
+}
+
+func (c *guardedPDClient) GetConfig() (*PDConfigFromAPI, error) {
+	value, err := c.read(cacheEndpointConfig, func() (interface{}, error) { return c.PDClient.GetConfig() })
+	if err != nil {
+		return nil, err
+	}
+	return value.(*PDConfigFromAPI), nil
+}
+
+func (c *guardedPDClient) GetCluster() (*metapb.Cluster, error) {
+	value, err := c.read(cacheEndpointCluster, func() (interface{}, error) { return c.PDClient.GetCluster() })
+	if err != nil {
+		return nil, err
+	}
+	return value.(*metapb.Cluster), nil
+}
+
+func (c *guardedPDClient) GetMembers() (*MembersInfo, error) {
+	value, err := c.read(cacheEndpointMembers, func() (interface{}, error) { return c.PDClient.GetMembers() })
+	if err != nil {
+		return nil, err
+	}
+	return value.(*MembersInfo), nil
+}
+
+func (c *guardedPDClient) GetStores() (*StoresInfo, error) {
+	value, err := c.read(cacheEndpointStores, func() (interface{}, error) { return c.PDClient.GetStores() })
+	if err != nil {
+		return nil, err
+	}
+	return value.(*StoresInfo), nil
+}
+
+func (c *guardedPDClient) GetTombStoneStores() (*StoresInfo, error) {
+	value, err := c.read(cacheEndpointTombStoneStores, func() (interface{}, error) { return c.PDClient.GetTombStoneStores() })
+	if err != nil {
+		return nil, err
+	}
+	return value.(*StoresInfo), nil
+}
+
+func (c *guardedPDClient) GetPDLeader() (*pdpb.Member, error) {
+	value, err := c.read(cacheEndpointLeader, func() (interface{}, error) { return c.PDClient.GetPDLeader() })
+	if err != nil {
+		return nil, err
+	}
+	return value.(*pdpb.Member), nil
+}
+
+func (c *guardedPDClient) GetStore(storeID uint64) (*StoreInfo, error) {
+	var store *StoreInfo
+	err := c.call(func() error {
+		var err error
+		store, err = c.PDClient.GetStore(storeID)
+		return err
+	})
+	return store, err
+}
+
+func (c *guardedPDClient) SetStoreLabels(storeID uint64, labels map[string]string) (bool, error) {
+	var set bool
+	err := c.call(func() error {
+		var err error
+		set, err = c.PDClient.SetStoreLabels(storeID, labels)
+		return err
+	})
+	return set, err
+}
+
+func (c *guardedPDClient) UpdateReplicationConfig(config PDReplicationConfig) error {
+	return c.call(func() error { return c.PDClient.UpdateReplicationConfig(config) })
+}
+
+func (c *guardedPDClient) DeleteStore(storeID uint64) error {
+	return c.call(func() error { return c.PDClient.DeleteStore(storeID) })
+}
+
+func (c *guardedPDClient) RemoveTombStone() error {
+	return c.call(func() error { return c.PDClient.RemoveTombStone() })
+}
+
+func (c *guardedPDClient) SetStoreState(storeID uint64, state string) error {
+	return c.call(func() error { return c.PDClient.SetStoreState(storeID, state) })
+}
+
+func (c *guardedPDClient) DeleteMember(name string) error {
+	return c.call(func() error { return c.PDClient.DeleteMember(name) })
+}
+
+func (c *guardedPDClient) DeleteMemberByID(memberID uint64) error {
+	return c.call(func() error { return c.PDClient.DeleteMemberByID(memberID) })
+}
+
+func (c *guardedPDClient) UpdateMemberPeerURLs(memberID uint64, peerURLs []string) error {
+	return c.call(func() error { return c.PDClient.UpdateMemberPeerURLs(memberID, peerURLs) })
+}
+
+func (c *guardedPDClient) BeginEvictLeader(storeID uint64) error {
+	return c.call(func() error { return c.PDClient.BeginEvictLeader(storeID) })
+}
+
+func (c *guardedPDClient) EndEvictLeader(storeID uint64) error {
+	return c.call(func() error { return c.PDClient.EndEvictLeader(storeID) })
+}
+
+func (c *guardedPDClient) GetEvictLeaderSchedulers() ([]string, error) {
+	var schedulers []string
+	err := c.call(func() error {
+		var err error
+		schedulers, err = c.PDClient.GetEvictLeaderSchedulers()
+		return err
+	})
+	return schedulers, err
+}
+
+func (c *guardedPDClient) TransferPDLeader(name string) error {
+	return c.call(func() error { return c.PDClient.TransferPDLeader(name) })
+}
+
+func (c *guardedPDClient) GetAutoscalingPlans(strategy Strategy) ([]Plan, error) {
+	var plans []Plan
+	err := c.call(func() error {
+		var err error
+		plans, err = c.PDClient.GetAutoscalingPlans(strategy)
+		return err
+	})
+	return plans, err
+}
+
+func (c *guardedPDClient) GetEtcdAlarms() ([]*EtcdAlarm, error) {
+	var alarms []*EtcdAlarm
+	err := c.call(func() error {
+		var err error
+		alarms, err = c.PDClient.GetEtcdAlarms()
+		return err
+	})
+	return alarms, err
+}
+
+func (c *guardedPDClient) GetEtcdStatus() (*EtcdStatus, error) {
+	var status *EtcdStatus
+	err := c.call(func() error {
+		var err error
+		status, err = c.PDClient.GetEtcdStatus()
+		return err
+	})
+	return status, err
+}
+
+func (c *guardedPDClient) GetStoreRegions(storeID uint64) (*RegionsInfo, error) {
+	var regions *RegionsInfo
+	err := c.call(func() error {
+		var err error
+		regions, err = c.PDClient.GetStoreRegions(storeID)
+		return err
+	})
+	return regions, err
+}
+
+func (c *guardedPDClient) GetStoreLeaderCount(storeID uint64) (int, error) {
+	var count int
+	err := c.call(func() error {
+		var err error
+		count, err = c.PDClient.GetStoreLeaderCount(storeID)
+		return err
+	})
+	return count, err
+}
+
+func (c *guardedPDClient) TransferRegionLeader(regionID, toStoreID uint64) error {
+	return c.call(func() error { return c.PDClient.TransferRegionLeader(regionID, toStoreID) })
+}
+
+func (c *guardedPDClient) ScatterRegion(regionID uint64) error {
+	return c.call(func() error { return c.PDClient.ScatterRegion(regionID) })
+}
+
+func (c *guardedPDClient) GetVersion() (string, error) {
+	var version string
+	err := c.call(func() error {
+		var err error
+		version, err = c.PDClient.GetVersion()
+		return err
+	})
+	return version, err
+}
+
+func (c *guardedPDClient) GetMinResolvedTS() (uint64, error) {
+	var ts uint64
+	err := c.call(func() error {
+		var err error
+		ts, err = c.PDClient.GetMinResolvedTS()
+		return err
+	})
+	return ts, err
+}
+
+func (c *guardedPDClient) GetServiceMembers(service string) ([]*ServiceMember, error) {
+	var members []*ServiceMember
+	err := c.call(func() error {
+		var err error
+		members, err = c.PDClient.GetServiceMembers(service)
+		return err
+	})
+	return members, err
+}
@@ -0,0 +1,150 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pdapi
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGuardedPDClientCircuitBreaker(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeClient := NewFakePDClient()
+	getStoresCount := 0
+	var getStoresErr error
+	fakeClient.AddReaction(GetStoresActionType, func(action *Action) (interface{}, error) {
+		getStoresCount++
+		if getStoresErr != nil {
+			return nil, getStoresErr
+		}
+		return &StoresInfo{Count: getStoresCount}, nil
+	})
+	fakeClient.AddReaction(DeleteStoreActionType, func(action *Action) (interface{}, error) {
+		return nil, nil
+	})
+
+	now := time.Now()
+	guard := newPDGuard(0, 0, true)
+	guard.now = func() time.Time { return now }
+	client := newGuardedPDClient(fakeClient, guard)
+
+	stores, err := client.GetStores()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(stores.Count).To(Equal(1))
+
+	// the error responses of PD do not open the circuit
+	getStoresErr = fmt.Errorf("internal error")
+	for i := 0; i < pdCircuitFailureThreshold; i++ {
+		_, err = client.GetStores()
+		g.Expect(err).To(HaveOccurred())
+	}
+	g.Expect(guard.getState()).To(Equal(CircuitClosed))
+
+	// the circuit is opened after PD is unreachable for pdCircuitFailureThreshold consecutive calls
+	getStoresErr = &url.Error{Op: "Get", URL: "http://pd:2379/pd/api/v1/stores", Err: fmt.Errorf("connection refused")}
+	for i := 0; i < pdCircuitFailureThreshold; i++ {
+		_, err = client.GetStores()
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(IsPDUnavailableError(err)).To(BeFalse())
+	}
+	g.Expect(guard.getState()).To(Equal(CircuitOpen))
+	count := getStoresCount
+
+	// the calls are short-circuited, and the last-known response is returned by the read-only APIs
+	stores, err = client.GetStores()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(stores.Count).To(Equal(1))
+	err = client.DeleteStore(1)
+	g.Expect(err).To(Equal(ErrPDCircuitOpen))
+	_, err = client.GetMembers()
+	g.Expect(err).To(Equal(ErrPDCircuitOpen))
+	g.Expect(getStoresCount).To(Equal(count))
+
+	// the circuit is opened again if the trial call fails
+	now = now.Add(pdCircuitOpenDuration)
+	_, err = client.GetStores()
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(getStoresCount).To(Equal(count + 1))
+	g.Expect(guard.getState()).To(Equal(CircuitOpen))
+
+	// the circuit is closed if the trial call succeeds
+	now = now.Add(pdCircuitOpenDuration)
+	getStoresErr = nil
+	stores, err = client.GetStores()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(stores.Count).To(Equal(count + 2))
+	g.Expect(guard.getState()).To(Equal(CircuitClosed))
+	g.Expect(client.DeleteStore(1)).To(Succeed())
+}
+
+func TestGuardedPDClientRateLimit(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fakeClient := NewFakePDClient()
+	fakeClient.AddReaction(GetHealthActionType, func(action *Action) (interface{}, error) {
+		return &HealthInfo{Healths: []MemberHealth{{Name: "pd-0", Health: true}}}, nil
+	})
+
+	now := time.Now()
+	guard := newPDGuard(1, 2, false)
+	guard.now = func() time.Time { return now }
+	client := newGuardedPDClient(fakeClient, guard)
+
+	for i := 0; i < 2; i++ {
+		g.Expect(client.DeleteMember("pd-0")).NotTo(Equal(ErrPDRateLimited))
+	}
+	g.Expect(client.DeleteMember("pd-0")).To(Equal(ErrPDRateLimited))
+	// no last-known response
+	_, err := client.GetHealth()
+	g.Expect(err).To(Equal(ErrPDRateLimited))
+
+	now = now.Add(time.Second)
+	health, err := client.GetHealth()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(health.Healths).To(HaveLen(1))
+
+	// the last-known response is returned if the call is rate limited
+	health, err = client.GetHealth()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(health.Healths).To(HaveLen(1))
+}
+
+func TestPDControlGuard(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	pdControl := NewDefaultPDControlWithOptions(kubefake.NewSimpleClientset(), PDControlOptions{CircuitBreaker: true}).(*defaultPDControl)
+	_, ok := pdControl.GetPDClient(Namespace("ns"), "tc", false).(*guardedPDClient)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(pdControl.GetPDCircuitState(Namespace("ns"), "tc", false)).To(Equal(CircuitClosed))
+
+	// the clients of the same cluster share the guard
+	peerClient := pdControl.GetPeerPDClient(Namespace("ns"), "tc", false, "http://tc-pd-0.tc-pd-peer.ns.svc:2379", "tc-pd-0")
+	g.Expect(peerClient.(*guardedPDClient).guard).To(BeIdenticalTo(pdControl.guards[pdGuardKey("ns", "tc", false)]))
+	pdControl.guards[pdGuardKey("ns", "tc", false)].state = CircuitOpen
+	g.Expect(pdControl.GetPDCircuitState(Namespace("ns"), "tc", false)).To(Equal(CircuitOpen))
+	g.Expect(pdControl.GetPDCircuitState(Namespace("ns"), "tc2", false)).To(Equal(CircuitClosed))
+
+	// the cache wraps the guarded client
+	pdControl = NewDefaultPDControlWithOptions(kubefake.NewSimpleClientset(), PDControlOptions{CacheTTL: time.Second, QPS: 10, Burst: 10}).(*defaultPDControl)
+	client, ok := pdControl.GetPDClient(Namespace("ns"), "tc", false).(*cachedPDClient)
+	g.Expect(ok).To(BeTrue())
+	_, ok = client.PDClient.(*guardedPDClient)
+	g.Expect(ok).To(BeTrue())
+}
@@ -0,0 +1,135 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pdapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver"
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
+)
+
+// APIVersion is the version of the PD RESTful API
+type APIVersion string
+
+const (
+	// APIVersionV1 is served by all the PD releases
+	APIVersionV1 APIVersion = "v1"
+	// APIVersionV2 is served since PD v7.1.0, including the service discovery of the PD microservices
+	APIVersionV2 APIVersion = "v2"
+)
+
+// the services of PD in the microservice mode
+const (
+	PDServiceTSO        = "tso"
+	PDServiceScheduling = "scheduling"
+)
+
+const (
+	versionPrefix            = "pd/api/v1/version"
+	serviceMembersPrefixV2   = "pd/api/v2/ms/members"
+	apiVersionRenegotiateTTL = 10 * time.Minute
+)
+
+var (
+	// ErrAPIV2Unsupported is returned if an API of v2 is called but PD only supports v1
+	ErrAPIV2Unsupported = errors.New("PD API v2 is not supported by the PD cluster")
+
+	pdAPIV2MinVersion = semver.MustParse("v7.1.0")
+)
+
+// ServiceMember is a member of a PD microservice returned from PD RESTful interface
+type ServiceMember struct {
+	ServiceAddr    string `json:"service-addr"`
+	Version        string `json:"version,omitempty"`
+	StartTimestamp int64  `json:"start-timestamp,omitempty"`
+}
+
+type versionInfo struct {
+	Version string `json:"version"`
+}
+
+// apiVersionNegotiator caches the API version negotiated with the version of PD,
+// which is renegotiated after apiVersionRenegotiateTTL in case PD is upgraded
+type apiVersionNegotiator struct {
+	mutex    sync.Mutex
+	version  APIVersion
+	expireAt time.Time
+}
+
+// negotiateAPIVersion returns the newest API version supported by both PD and the operator.
+// The releases which are not semantic versions, e.g. built from source, are regarded as v1 only.
+func negotiateAPIVersion(version string) APIVersion {
+	v, err := semver.NewVersion(version)
+	if err != nil || v.LessThan(pdAPIV2MinVersion) {
+		return APIVersionV1
+	}
+	return APIVersionV2
+}
+
+func (c *pdClient) GetVersion() (string, error) {
+	apiURL := fmt.Sprintf("%s/%s", c.url, versionPrefix)
+	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	if err != nil {
+		return "", err
+	}
+	info := &versionInfo{}
+	err = json.Unmarshal(body, info)
+	if err != nil {
+		return "", err
+	}
+	return info.Version, nil
+}
+
+func (c *pdClient) GetAPIVersion() (APIVersion, error) {
+	c.negotiator.mutex.Lock()
+	defer c.negotiator.mutex.Unlock()
+
+	now := time.Now()
+	if c.negotiator.version != "" && now.Before(c.negotiator.expireAt) {
+		return c.negotiator.version, nil
+	}
+	version, err := c.GetVersion()
+	if err != nil {
+		return "", err
+	}
+	c.negotiator.version = negotiateAPIVersion(version)
+	c.negotiator.expireAt = now.Add(apiVersionRenegotiateTTL)
+	return c.negotiator.version, nil
+}
+
+func (c *pdClient) GetServiceMembers(service string) ([]*ServiceMember, error) {
+	apiVersion, err := c.GetAPIVersion()
+	if err != nil {
+		return nil, err
+	}
+	if apiVersion != APIVersionV2 {
+		return nil, ErrAPIV2Unsupported
+	}
+	apiURL := fmt.Sprintf("%s/%s/%s", c.url, serviceMembersPrefixV2, service)
+	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	var members []*ServiceMember
+	err = json.Unmarshal(body, &members)
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
@@ -75,12 +75,17 @@ type PDClient interface {
 	UpdateReplicationConfig(config PDReplicationConfig) error
 	// DeleteStore deletes a TiKV store from cluster
 	DeleteStore(storeID uint64) error
+	// RemoveTombStone removes all the tombstone stores from cluster
+	RemoveTombStone() error
 	// SetStoreState sets store to specified state.
 	SetStoreState(storeID uint64, state string) error
 	// DeleteMember deletes a PD member from cluster
 	DeleteMember(name string) error
 	// DeleteMemberByID deletes a PD member from cluster
 	DeleteMemberByID(memberID uint64) error
+	// UpdateMemberPeerURLs updates the peer URLs of a PD member by the embedded etcd,
+	// the member must be restarted to listen on the new URLs
+	UpdateMemberPeerURLs(memberID uint64, peerURLs []string) error
 	// BeginEvictLeader initiates leader eviction for a storeID.
 	// This is used when upgrading a pod.
 	BeginEvictLeader(storeID uint64) error
@@ -94,6 +99,28 @@ type PDClient interface {
 	TransferPDLeader(name string) error
 	// GetAutoscalingPlans returns the scaling plan for the cluster
 	GetAutoscalingPlans(strategy Strategy) ([]Plan, error)
+	// GetEtcdAlarms returns the alarms raised by the embedded etcd of the cluster, e.g. NOSPACE
+	GetEtcdAlarms() ([]*EtcdAlarm, error)
+	// GetEtcdStatus returns the status of the embedded etcd of the PD member which serves the request
+	GetEtcdStatus() (*EtcdStatus, error)
+	// GetStoreRegions returns the regions which have a peer on the store
+	GetStoreRegions(storeID uint64) (*RegionsInfo, error)
+	// GetStoreLeaderCount returns the number of region leaders on the store
+	GetStoreLeaderCount(storeID uint64) (int, error)
+	// TransferRegionLeader adds an operator to transfer the leader of the region to the store
+	TransferRegionLeader(regionID, toStoreID uint64) error
+	// ScatterRegion adds an operator to scatter the peers of the region among the stores
+	ScatterRegion(regionID uint64) error
+	// GetVersion returns the version of PD, e.g. v7.1.0
+	GetVersion() (string, error)
+	// GetAPIVersion returns the newest API version supported by both PD and the operator
+	GetAPIVersion() (APIVersion, error)
+	// GetServiceMembers returns the members of the PD microservice, e.g. tso and scheduling,
+	// which requires PD API v2
+	GetServiceMembers(service string) ([]*ServiceMember, error)
+	// GetMinResolvedTS returns the minimum resolved TS of all the TiKV stores, the data before it
+	// is consistent across the cluster
+	GetMinResolvedTS() (uint64, error)
 }
 
 var (
@@ -111,12 +138,22 @@ var (
 	// config API, available since PD v3.1.0.
 	evictLeaderSchedulerConfigPrefix = "pd/api/v1/scheduler-config/evict-leader-scheduler/list"
 	autoscalingPrefix                = "autoscaling"
+	// the etcd v3 gateway served by the embedded etcd of PD
+	etcdAlarmPrefix        = "v3/maintenance/alarm"
+	etcdStatusPrefix       = "v3/maintenance/status"
+	etcdMemberUpdatePrefix = "v3/cluster/member/update"
+	// regionsStorePrefix and operatorsPrefix are used to transfer the region leaders
+	regionsStorePrefix = "pd/api/v1/regions/store"
+	operatorsPrefix    = "pd/api/v1/operators"
+	// minResolvedTSPrefix is available since PD v5.4.0
+	minResolvedTSPrefix = "pd/api/v1/min-resolved-ts"
 )
 
 // pdClient is default implementation of PDClient
 type pdClient struct {
 	url        string
 	httpClient *http.Client
+	negotiator apiVersionNegotiator
 }
 
 // NewPDClient returns a new PDClient
@@ -185,7 +222,7 @@ type StoresInfo struct {
 }
 
 // MembersInfo is PD members info returned from PD RESTful interface
-//type Members map[string][]*pdpb.Member
+// type Members map[string][]*pdpb.Member
 type MembersInfo struct {
 	Header     *pdpb.ResponseHeader `json:"header,omitempty"`
 	Members    []*pdpb.Member       `json:"members,omitempty"`
@@ -193,6 +230,67 @@ type MembersInfo struct {
 	EtcdLeader *pdpb.Member         `json:"etcd_leader,omitempty"`
 }
 
+// EtcdAlarm is an alarm raised by an etcd member, returned from the etcd v3 gateway
+type EtcdAlarm struct {
+	MemberID uint64 `json:"memberID,string"`
+	// Alarm is the type of the alarm, e.g. NOSPACE and CORRUPT
+	Alarm string `json:"alarm"`
+}
+
+type etcdAlarmResponse struct {
+	Alarms []*EtcdAlarm `json:"alarms"`
+}
+
+// EtcdResponseHeader is the header of the responses from the etcd v3 gateway
+type EtcdResponseHeader struct {
+	ClusterID uint64 `json:"cluster_id,string"`
+	MemberID  uint64 `json:"member_id,string"`
+}
+
+// EtcdStatus is the status of an etcd member returned from the etcd v3 gateway
+type EtcdStatus struct {
+	Header  *EtcdResponseHeader `json:"header"`
+	Version string              `json:"version"`
+	// DBSize is the size of the backend database in bytes
+	DBSize    int64  `json:"dbSize,string"`
+	Leader    uint64 `json:"leader,string"`
+	RaftIndex uint64 `json:"raftIndex,string"`
+	RaftTerm  uint64 `json:"raftTerm,string"`
+}
+
+// MinResolvedTS is the minimum resolved TS returned from PD RESTful interface
+type MinResolvedTS struct {
+	MinResolvedTS uint64 `json:"min_resolved_ts"`
+	IsRealTime    bool   `json:"is_real_time,omitempty"`
+}
+
+// RegionPeer is a peer of a region returned from PD RESTful interface
+type RegionPeer struct {
+	ID        uint64 `json:"id"`
+	StoreID   uint64 `json:"store_id"`
+	IsLearner bool   `json:"is_learner,omitempty"`
+}
+
+// RegionInfo is a region returned from PD RESTful interface
+type RegionInfo struct {
+	ID     uint64        `json:"id"`
+	Peers  []*RegionPeer `json:"peers,omitempty"`
+	Leader *RegionPeer   `json:"leader,omitempty"`
+}
+
+// RegionsInfo is regions info returned from PD RESTful interface
+type RegionsInfo struct {
+	Count   int           `json:"count"`
+	Regions []*RegionInfo `json:"regions"`
+}
+
+// operatorInput is the input of the operators API, only the fields used by the operator are filled
+type operatorInput struct {
+	Name      string `json:"name"`
+	RegionID  uint64 `json:"region_id"`
+	ToStoreID uint64 `json:"to_store_id,omitempty"`
+}
+
 // below copied from github.com/tikv/pd/pkg/autoscaling
 
 // Strategy within a HTTP request provides rules and resources to help make decision for auto scaling.
@@ -391,6 +489,29 @@ func (c *pdClient) DeleteStore(storeID uint64) error {
 	return fmt.Errorf("failed to delete store %d: %v", storeID, string(body))
 }
 
+func (c *pdClient) RemoveTombStone() error {
+	apiURL := fmt.Sprintf("%s/%s/remove-tombstone", c.url, storesPrefix)
+	req, err := http.NewRequest("DELETE", apiURL, nil)
+	if err != nil {
+		return err
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer httputil.DeferClose(res.Body)
+
+	if res.StatusCode == http.StatusOK {
+		return nil
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	return fmt.Errorf("failed to remove tombstone stores: %v", string(body))
+}
+
 // SetStoreState sets store to specified state.
 func (c *pdClient) SetStoreState(storeID uint64, state string) error {
 	apiURL := fmt.Sprintf("%s/%s/%d/state?state=%s", c.url, storePrefix, storeID, state)
@@ -708,6 +829,116 @@ func (c *pdClient) GetAutoscalingPlans(strategy Strategy) ([]Plan, error) {
 	return plans, nil
 }
 
+func (c *pdClient) GetEtcdAlarms() ([]*EtcdAlarm, error) {
+	apiURL := fmt.Sprintf("%s/%s", c.url, etcdAlarmPrefix)
+	body, err := httputil.PostBodyOK(c.httpClient, apiURL, bytes.NewBufferString(`{"action":"GET"}`))
+	if err != nil {
+		return nil, err
+	}
+	resp := &etcdAlarmResponse{}
+	err = json.Unmarshal(body, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Alarms, nil
+}
+
+func (c *pdClient) GetEtcdStatus() (*EtcdStatus, error) {
+	apiURL := fmt.Sprintf("%s/%s", c.url, etcdStatusPrefix)
+	body, err := httputil.PostBodyOK(c.httpClient, apiURL, bytes.NewBufferString(`{}`))
+	if err != nil {
+		return nil, err
+	}
+	status := &EtcdStatus{}
+	err = json.Unmarshal(body, status)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// etcdMemberUpdateRequest is the request to update the peer URLs of a member by the etcd v3 gateway
+type etcdMemberUpdateRequest struct {
+	ID       uint64   `json:"ID,string"`
+	PeerURLs []string `json:"peerURLs"`
+}
+
+func (c *pdClient) UpdateMemberPeerURLs(memberID uint64, peerURLs []string) error {
+	data, err := json.Marshal(&etcdMemberUpdateRequest{ID: memberID, PeerURLs: peerURLs})
+	if err != nil {
+		return err
+	}
+	apiURL := fmt.Sprintf("%s/%s", c.url, etcdMemberUpdatePrefix)
+	_, err = httputil.PostBodyOK(c.httpClient, apiURL, bytes.NewBuffer(data))
+	if err != nil {
+		return fmt.Errorf("failed to update the peer urls of member %d to %v: %v", memberID, peerURLs, err)
+	}
+	return nil
+}
+
+func (c *pdClient) GetStoreRegions(storeID uint64) (*RegionsInfo, error) {
+	apiURL := fmt.Sprintf("%s/%s/%d", c.url, regionsStorePrefix, storeID)
+	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	regions := &RegionsInfo{}
+	err = json.Unmarshal(body, regions)
+	if err != nil {
+		return nil, err
+	}
+	return regions, nil
+}
+
+func (c *pdClient) GetStoreLeaderCount(storeID uint64) (int, error) {
+	store, err := c.GetStore(storeID)
+	if err != nil {
+		return 0, err
+	}
+	if store.Status == nil {
+		return 0, fmt.Errorf("no status of store %d", storeID)
+	}
+	return store.Status.LeaderCount, nil
+}
+
+func (c *pdClient) TransferRegionLeader(regionID, toStoreID uint64) error {
+	return c.addOperator(&operatorInput{Name: "transfer-leader", RegionID: regionID, ToStoreID: toStoreID})
+}
+
+func (c *pdClient) ScatterRegion(regionID uint64) error {
+	return c.addOperator(&operatorInput{Name: "scatter-region", RegionID: regionID})
+}
+
+func (c *pdClient) addOperator(input *operatorInput) error {
+	apiURL := fmt.Sprintf("%s/%s", c.url, operatorsPrefix)
+	data, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	_, err = httputil.PostBodyOK(c.httpClient, apiURL, bytes.NewBuffer(data))
+	if err != nil {
+		return fmt.Errorf("failed to add operator %s for region %d, error: %v", input.Name, input.RegionID, err)
+	}
+	return nil
+}
+
+func (c *pdClient) GetMinResolvedTS() (uint64, error) {
+	apiURL := fmt.Sprintf("%s/%s", c.url, minResolvedTSPrefix)
+	body, err := httputil.GetBodyOK(c.httpClient, apiURL)
+	if err != nil {
+		return 0, err
+	}
+	ts := &MinResolvedTS{}
+	err = json.Unmarshal(body, ts)
+	if err != nil {
+		return 0, err
+	}
+	if ts.MinResolvedTS == 0 {
+		return 0, fmt.Errorf("min resolved ts is not available from %s", apiURL)
+	}
+	return ts.MinResolvedTS, nil
+}
+
 func getLeaderEvictSchedulerInfo(storeID uint64) *schedulerInfo {
 	return &schedulerInfo{"evict-leader-scheduler", storeID}
 }
@@ -23,6 +23,7 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/kvproto/pkg/metapb"
@@ -209,6 +210,169 @@ func TestGetMembers(t *testing.T) {
 	}
 }
 
+func TestGetEtcdStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+		g.Expect(request.Method).To(Equal("POST"), "check method")
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		switch request.URL.Path {
+		case fmt.Sprintf("/%s", etcdAlarmPrefix):
+			w.Write([]byte(`{"header":{"cluster_id":"100","member_id":"1"},"alarms":[{"memberID":"2","alarm":"NOSPACE"}]}`))
+		case fmt.Sprintf("/%s", etcdStatusPrefix):
+			w.Write([]byte(`{"header":{"cluster_id":"100","member_id":"1"},"version":"3.4.3","dbSize":"24576","leader":"1","raftIndex":"42","raftTerm":"2"}`))
+		default:
+			t.Errorf("unexpected url %s", request.URL.Path)
+		}
+	})
+	defer svc.Close()
+
+	pdClient := NewPDClient(svc.URL, DefaultTimeout, &tls.Config{})
+	alarms, err := pdClient.GetEtcdAlarms()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(alarms).To(Equal([]*EtcdAlarm{{MemberID: 2, Alarm: "NOSPACE"}}))
+
+	status, err := pdClient.GetEtcdStatus()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(status).To(Equal(&EtcdStatus{
+		Header:    &EtcdResponseHeader{ClusterID: 100, MemberID: 1},
+		Version:   "3.4.3",
+		DBSize:    24576,
+		Leader:    1,
+		RaftIndex: 42,
+		RaftTerm:  2,
+	}))
+}
+
+func TestUpdateMemberPeerURLs(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+		g.Expect(request.Method).To(Equal("POST"), "check method")
+		g.Expect(request.URL.Path).To(Equal(fmt.Sprintf("/%s", etcdMemberUpdatePrefix)), "check url")
+		data, err := ioutil.ReadAll(request.Body)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(string(data)).To(Equal(`{"ID":"12345678901234567890","peerURLs":["http://pd-0.pd-peer.ns.svc.cluster.local:2380"]}`))
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		w.Write([]byte(`{"header":{"cluster_id":"100","member_id":"1"},"members":[]}`))
+	})
+	defer svc.Close()
+
+	pdClient := NewPDClient(svc.URL, DefaultTimeout, &tls.Config{})
+	err := pdClient.UpdateMemberPeerURLs(12345678901234567890, []string{"http://pd-0.pd-peer.ns.svc.cluster.local:2380"})
+	g.Expect(err).NotTo(HaveOccurred())
+}
+
+func TestGetMinResolvedTS(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	resp := `{"min_resolved_ts":426592034498838529,"is_real_time":true}`
+	svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+		g.Expect(request.Method).To(Equal("GET"), "check method")
+		g.Expect(request.URL.Path).To(Equal(fmt.Sprintf("/%s", minResolvedTSPrefix)), "check url")
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		w.Write([]byte(resp))
+	})
+	defer svc.Close()
+
+	pdClient := NewPDClient(svc.URL, DefaultTimeout, &tls.Config{})
+	ts, err := pdClient.GetMinResolvedTS()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ts).To(Equal(uint64(426592034498838529)))
+
+	// the min resolved ts is 0 if it is not reported by TiKV yet
+	resp = `{"min_resolved_ts":0,"is_real_time":false}`
+	_, err = pdClient.GetMinResolvedTS()
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestTransferRegionLeader(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var operators []string
+	svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		switch request.URL.Path {
+		case fmt.Sprintf("/%s/1", regionsStorePrefix):
+			g.Expect(request.Method).To(Equal("GET"), "check method")
+			w.Write([]byte(`{"count":1,"regions":[{"id":10,"peers":[{"id":11,"store_id":1},{"id":12,"store_id":2},{"id":13,"store_id":3,"is_learner":true}],"leader":{"id":11,"store_id":1}}]}`))
+		case fmt.Sprintf("/%s", operatorsPrefix):
+			g.Expect(request.Method).To(Equal("POST"), "check method")
+			body, err := ioutil.ReadAll(request.Body)
+			g.Expect(err).NotTo(HaveOccurred())
+			operators = append(operators, string(body))
+			w.Write([]byte(`"The operator is created."`))
+		default:
+			t.Errorf("unexpected url %s", request.URL.Path)
+		}
+	})
+	defer svc.Close()
+
+	pdClient := NewPDClient(svc.URL, DefaultTimeout, &tls.Config{})
+	regions, err := pdClient.GetStoreRegions(1)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(regions).To(Equal(&RegionsInfo{
+		Count: 1,
+		Regions: []*RegionInfo{
+			{
+				ID:     10,
+				Peers:  []*RegionPeer{{ID: 11, StoreID: 1}, {ID: 12, StoreID: 2}, {ID: 13, StoreID: 3, IsLearner: true}},
+				Leader: &RegionPeer{ID: 11, StoreID: 1},
+			},
+		},
+	}))
+
+	g.Expect(pdClient.TransferRegionLeader(10, 2)).To(Succeed())
+	g.Expect(pdClient.ScatterRegion(10)).To(Succeed())
+	g.Expect(operators).To(Equal([]string{
+		`{"name":"transfer-leader","region_id":10,"to_store_id":2}`,
+		`{"name":"scatter-region","region_id":10}`,
+	}))
+}
+
+func TestGetServiceMembers(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(negotiateAPIVersion("v4.0.10")).To(Equal(APIVersionV1))
+	g.Expect(negotiateAPIVersion("None")).To(Equal(APIVersionV1))
+	g.Expect(negotiateAPIVersion("v7.1.0")).To(Equal(APIVersionV2))
+	g.Expect(negotiateAPIVersion("v7.6.0-alpha-12-g1234567")).To(Equal(APIVersionV2))
+
+	version := "v6.5.0"
+	versionRequests := 0
+	svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+		g.Expect(request.Method).To(Equal("GET"), "check method")
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		switch request.URL.Path {
+		case fmt.Sprintf("/%s", versionPrefix):
+			versionRequests++
+			w.Write([]byte(fmt.Sprintf(`{"version":"%s"}`, version)))
+		case fmt.Sprintf("/%s/%s", serviceMembersPrefixV2, PDServiceTSO):
+			w.Write([]byte(`[{"service-addr":"http://tso-0:2379","version":"v7.1.0","git-hash":"abc","start-timestamp":1689000000}]`))
+		default:
+			t.Errorf("unexpected url %s", request.URL.Path)
+		}
+	})
+	defer svc.Close()
+
+	client := NewPDClient(svc.URL, DefaultTimeout, &tls.Config{}).(*pdClient)
+	_, err := client.GetServiceMembers(PDServiceTSO)
+	g.Expect(err).To(Equal(ErrAPIV2Unsupported))
+
+	// the API version is renegotiated after the TTL
+	version = "v7.1.0"
+	apiVersion, err := client.GetAPIVersion()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(apiVersion).To(Equal(APIVersionV1))
+	g.Expect(versionRequests).To(Equal(1))
+	client.negotiator.expireAt = time.Now()
+
+	members, err := client.GetServiceMembers(PDServiceTSO)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(members).To(Equal([]*ServiceMember{{ServiceAddr: "http://tso-0:2379", Version: "v7.1.0", StartTimestamp: 1689000000}}))
+	g.Expect(versionRequests).To(Equal(2))
+}
+
 func TestGetStores(t *testing.T) {
 	g := NewGomegaWithT(t)
 	store1 := &StoreInfo{
@@ -634,6 +798,44 @@ func TestDeleteStore(t *testing.T) {
 	}
 }
 
+func TestRemoveTombStone(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tcs := []struct {
+		caseName string
+		want     bool
+	}{{
+		caseName: "success_RemoveTombStone",
+		want:     true,
+	}, {
+		caseName: "failed_RemoveTombStone",
+		want:     false,
+	}}
+
+	for _, tc := range tcs {
+		svc := getClientServer(func(w http.ResponseWriter, request *http.Request) {
+			g.Expect(request.Method).To(Equal("DELETE"), "check method")
+			g.Expect(request.URL.Path).To(Equal(fmt.Sprintf("/%s/remove-tombstone", storesPrefix)), "check url")
+
+			w.Header().Set("Content-Type", ContentTypeJSON)
+			if tc.want {
+				w.WriteHeader(http.StatusOK)
+			} else {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		})
+		defer svc.Close()
+
+		pdClient := NewPDClient(svc.URL, DefaultTimeout, &tls.Config{})
+		err := pdClient.RemoveTombStone()
+		if tc.want {
+			g.Expect(err).NotTo(HaveOccurred(), tc.caseName)
+		} else {
+			g.Expect(err).To(HaveOccurred(), tc.caseName)
+		}
+	}
+}
+
 func readJSON(r io.ReadCloser, data interface{}) error {
 	defer r.Close()
 
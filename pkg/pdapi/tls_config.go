@@ -0,0 +1,147 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pdapi
+
+import (
+	"crypto/tls"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// tlsConfigCacheTTL is the max duration a tls.Config is cached, so the rotated certificates
+// are loaded even if the events of the secret are missed or not watched
+const tlsConfigCacheTTL = time.Minute
+
+// TLSPolicy restricts the TLS versions and the cipher suites of a cached tls.Config, the zero
+// values keep the defaults of crypto/tls
+type TLSPolicy struct {
+	MinVersion   uint16
+	CipherSuites []uint16
+}
+
+type tlsConfigEntry struct {
+	config   *tls.Config
+	expireAt time.Time
+}
+
+// TLSConfigCache caches the tls.Configs loaded from the client TLS secrets of the clusters,
+// so the clients of the components do not load the secrets from the API server in every call.
+// A cached tls.Config is dropped when its secret is updated or deleted if the cache is
+// registered to the secret informer by SecretEventHandler, or after tlsConfigCacheTTL.
+//
+// The cached tls.Configs are shared by the callers, which must not modify them. The TLS policy
+// of a secret set by SetPolicy is applied to its tls.Config when it is loaded.
+type TLSConfigCache struct {
+	kubeCli kubernetes.Interface
+	// now returns the current time, it is replaced in unit tests
+	now func() time.Time
+
+	mutex    sync.Mutex
+	entries  map[string]*tlsConfigEntry
+	policies map[string]TLSPolicy
+}
+
+// NewTLSConfigCache returns a TLSConfigCache which loads the secrets by kubeCli
+func NewTLSConfigCache(kubeCli kubernetes.Interface) *TLSConfigCache {
+	return &TLSConfigCache{
+		kubeCli:  kubeCli,
+		now:      time.Now,
+		entries:  map[string]*tlsConfigEntry{},
+		policies: map[string]TLSPolicy{},
+	}
+}
+
+// GetTLSConfig returns the tls.Config loaded from the secret, which is cached until the secret changes
+func (c *TLSConfigCache) GetTLSConfig(namespace Namespace, secretName string) (*tls.Config, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := tlsConfigKey(string(namespace), secretName)
+	now := c.now()
+	if entry, ok := c.entries[key]; ok && now.Before(entry.expireAt) {
+		return entry.config, nil
+	}
+	config, err := GetTLSConfig(c.kubeCli, namespace, "", secretName)
+	if err != nil {
+		return nil, err
+	}
+	if policy, ok := c.policies[key]; ok {
+		if policy.MinVersion != 0 {
+			config.MinVersion = policy.MinVersion
+		}
+		if len(policy.CipherSuites) > 0 {
+			config.CipherSuites = policy.CipherSuites
+		}
+	}
+	c.entries[key] = &tlsConfigEntry{config: config, expireAt: now.Add(tlsConfigCacheTTL)}
+	return config, nil
+}
+
+// Invalidate drops the cached tls.Config of the secret
+func (c *TLSConfigCache) Invalidate(namespace, secretName string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.entries, tlsConfigKey(namespace, secretName))
+}
+
+// SetPolicy sets the TLS policy of the tls.Config of the secret, the cached tls.Config is dropped
+// if the policy is changed
+func (c *TLSConfigCache) SetPolicy(namespace, secretName string, policy TLSPolicy) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := tlsConfigKey(namespace, secretName)
+	if old, ok := c.policies[key]; ok && reflect.DeepEqual(old, policy) {
+		return
+	}
+	c.policies[key] = policy
+	delete(c.entries, key)
+}
+
+// SecretEventHandler returns the event handler of the secret informer, which drops the cached
+// tls.Configs of the secrets once they are updated or deleted
+func (c *TLSConfigCache) SecretEventHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(old, cur interface{}) {
+			oldSecret, ok := old.(*corev1.Secret)
+			if !ok {
+				return
+			}
+			curSecret, ok := cur.(*corev1.Secret)
+			if !ok || oldSecret.ResourceVersion == curSecret.ResourceVersion {
+				// the periodic resync
+				return
+			}
+			c.Invalidate(curSecret.Namespace, curSecret.Name)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if secret, ok := obj.(*corev1.Secret); ok {
+				c.Invalidate(secret.Namespace, secret.Name)
+			}
+		},
+	}
+}
+
+func tlsConfigKey(namespace, secretName string) string {
+	return fmt.Sprintf("%s/%s", namespace, secretName)
+}
@@ -0,0 +1,152 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pdapi
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+)
+
+// newClientTLSSecret returns a secret with a self-signed certificate
+func newClientTLSSecret(g *GomegaWithT, namespace, name string) *corev1.Secret {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	g.Expect(err).NotTo(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	g.Expect(err).NotTo(HaveOccurred())
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	g.Expect(err).NotTo(HaveOccurred())
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, ResourceVersion: "1"},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:              cert,
+			corev1.TLSPrivateKeyKey:        pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+			corev1.ServiceAccountRootCAKey: cert,
+		},
+	}
+}
+
+func TestTLSConfigCache(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	secretName := util.ClusterClientTLSSecretName("tc")
+	secret := newClientTLSSecret(g, "ns", secretName)
+	kubeCli := kubefake.NewSimpleClientset(secret)
+	gets := 0
+	kubeCli.PrependReactor("get", "secrets", func(action core.Action) (bool, runtime.Object, error) {
+		gets++
+		return false, nil, nil
+	})
+
+	now := time.Now()
+	c := NewTLSConfigCache(kubeCli)
+	c.now = func() time.Time { return now }
+
+	config, err := c.GetTLSConfig(Namespace("ns"), secretName)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(config.Certificates).To(HaveLen(1))
+	cached, err := c.GetTLSConfig(Namespace("ns"), secretName)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cached).To(BeIdenticalTo(config))
+	g.Expect(gets).To(Equal(1))
+
+	_, err = c.GetTLSConfig(Namespace("ns"), "not-exist")
+	g.Expect(err).To(HaveOccurred())
+	gets = 0
+
+	// the resync of the informer does not drop the cache
+	handler := c.SecretEventHandler()
+	handler.OnUpdate(secret, secret)
+	_, err = c.GetTLSConfig(Namespace("ns"), secretName)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(gets).To(Equal(0))
+
+	// the cache is dropped once the secret is updated
+	updated := secret.DeepCopy()
+	updated.ResourceVersion = "2"
+	handler.OnUpdate(secret, updated)
+	reloaded, err := c.GetTLSConfig(Namespace("ns"), secretName)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(reloaded).NotTo(BeIdenticalTo(config))
+	g.Expect(gets).To(Equal(1))
+
+	// the cache is dropped once the secret is deleted
+	handler.OnDelete(updated)
+	_, err = c.GetTLSConfig(Namespace("ns"), secretName)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(gets).To(Equal(2))
+
+	// the cache is dropped after the TTL
+	now = now.Add(tlsConfigCacheTTL)
+	_, err = c.GetTLSConfig(Namespace("ns"), secretName)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(gets).To(Equal(3))
+
+	// the cache is dropped once the policy is changed, and the policy is applied
+	policy := TLSPolicy{MinVersion: tls.VersionTLS12, CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}}
+	c.SetPolicy("ns", secretName, policy)
+	config, err = c.GetTLSConfig(Namespace("ns"), secretName)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(gets).To(Equal(4))
+	g.Expect(config.MinVersion).To(Equal(uint16(tls.VersionTLS12)))
+	g.Expect(config.CipherSuites).To(Equal(policy.CipherSuites))
+	c.SetPolicy("ns", secretName, policy)
+	_, err = c.GetTLSConfig(Namespace("ns"), secretName)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(gets).To(Equal(4))
+}
+
+func TestPDControlTLSTransport(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	secretName := util.ClusterClientTLSSecretName("tc")
+	kubeCli := kubefake.NewSimpleClientset(newClientTLSSecret(g, "ns", secretName))
+	tlsConfigs := NewTLSConfigCache(kubeCli)
+	pdControl := NewDefaultPDControlWithOptions(kubeCli, PDControlOptions{TLSConfigs: tlsConfigs}).(*defaultPDControl)
+
+	client := pdControl.GetPDClient(Namespace("ns"), "tc", true).(*pdClient)
+	g.Expect(client.httpClient.Transport.(*http.Transport).TLSClientConfig).NotTo(BeNil())
+	g.Expect(pdControl.GetPDClient(Namespace("ns"), "tc", true)).To(BeIdenticalTo(client))
+
+	// the transport and the clients are recreated once the tls.Config is reloaded
+	tlsConfigs.Invalidate("ns", secretName)
+	recreated := pdControl.GetPDClient(Namespace("ns"), "tc", true).(*pdClient)
+	g.Expect(recreated).NotTo(BeIdenticalTo(client))
+	g.Expect(recreated.httpClient.Transport).NotTo(BeIdenticalTo(client.httpClient.Transport))
+}
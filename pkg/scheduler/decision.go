@@ -0,0 +1,100 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/metrics"
+	"github.com/pingcap/tidb-operator/pkg/scheduler/predicates"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+)
+
+// filterDecision is the decision made by a predicate for a pod
+type filterDecision struct {
+	verb       string
+	component  string
+	predicate  string
+	candidates []string
+	feasible   []string
+	err        error
+	duration   time.Duration
+}
+
+func newFilterDecision(verb, component, predicate string, candidates, feasible []apiv1.Node, err error, duration time.Duration) *filterDecision {
+	return &filterDecision{
+		verb:       verb,
+		component:  component,
+		predicate:  predicate,
+		candidates: predicates.GetNodeNames(candidates),
+		feasible:   predicates.GetNodeNames(feasible),
+		err:        err,
+		duration:   duration,
+	}
+}
+
+func (d *filterDecision) result() string {
+	if d.err == nil {
+		return metrics.SchedulerResultPassed
+	}
+	if predicates.IsPreferenceError(d.err) {
+		return metrics.SchedulerResultPreferred
+	}
+	return metrics.SchedulerResultRejected
+}
+
+func (d *filterDecision) rejected() []string {
+	return sets.NewString(d.candidates...).Difference(sets.NewString(d.feasible...)).List()
+}
+
+func (d *filterDecision) reason() string {
+	if d.err == nil {
+		return ""
+	}
+	return d.err.Error()
+}
+
+// record writes the decision to the log in the key=value format and exports it as metrics
+func (d *filterDecision) record(pod *apiv1.Pod) {
+	rejected := d.rejected()
+	klog.Infof("scheduler decision: verb=%s pod=%s/%s component=%s predicate=%s result=%s duration=%s candidates=%v feasible=%v rejected=%v reason=%q",
+		d.verb, pod.GetNamespace(), pod.GetName(), d.component, d.predicate, d.result(), d.duration, d.candidates, d.feasible, rejected, d.reason())
+	metrics.SchedulerPredicateEvaluations.WithLabelValues(d.component, d.predicate, d.result()).Inc()
+	metrics.SchedulerPredicateRejectedNodes.WithLabelValues(d.component, d.predicate).Add(float64(len(rejected)))
+	metrics.SchedulerPredicateDuration.WithLabelValues(d.component, d.predicate).Observe(d.duration.Seconds())
+}
+
+// explain summarizes the decisions of the predicates which left no node for the pod, e.g.
+// 0/3 nodes are available: HAScheduling rejected 3 nodes (kube-node-1, kube-node-2, kube-node-3): unable to schedule to topology: ...
+func explain(total int, decisions []*filterDecision) string {
+	reasons := make([]string, 0, len(decisions))
+	for _, d := range decisions {
+		if d.err == nil && len(d.rejected()) == 0 {
+			continue
+		}
+		reason := fmt.Sprintf("%s rejected %d nodes", d.predicate, len(d.rejected()))
+		if rejected := d.rejected(); len(rejected) > 0 {
+			reason = fmt.Sprintf("%s (%s)", reason, strings.Join(rejected, ", "))
+		}
+		if d.err != nil {
+			reason = fmt.Sprintf("%s: %s", reason, d.err.Error())
+		}
+		reasons = append(reasons, reason)
+	}
+	return fmt.Sprintf("0/%d nodes are available: %s", total, strings.Join(reasons, "; "))
+}
@@ -0,0 +1,64 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/metrics"
+	"github.com/pingcap/tidb-operator/pkg/scheduler/predicates"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFilterDecision(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	nodes := []apiv1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-3"}},
+	}
+
+	d := newFilterDecision("filter", "pd", "HAScheduling", nodes, nodes[:1], nil, 0)
+	g.Expect(d.result()).To(Equal(metrics.SchedulerResultPassed))
+	g.Expect(d.rejected()).To(Equal([]string{"node-2", "node-3"}))
+
+	d = newFilterDecision("filter", "pd", "HAScheduling", nodes, nodes, &predicates.PreferenceError{Message: "preferred"}, 0)
+	g.Expect(d.result()).To(Equal(metrics.SchedulerResultPreferred))
+	g.Expect(d.rejected()).To(BeEmpty())
+
+	d = newFilterDecision("filter", "pd", "HAScheduling", nodes, nil, fmt.Errorf("unable to schedule"), 0)
+	g.Expect(d.result()).To(Equal(metrics.SchedulerResultRejected))
+	g.Expect(d.rejected()).To(Equal([]string{"node-1", "node-2", "node-3"}))
+}
+
+func TestExplain(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	nodes := []apiv1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-3"}},
+	}
+	decisions := []*filterDecision{
+		newFilterDecision("filter", "tidb", "StableScheduling", nodes, nodes, nil, 0),
+		newFilterDecision("filter", "tidb", "Foo", nodes, nodes[1:], nil, 0),
+		newFilterDecision("filter", "tidb", "Bar", nodes[1:], nil, fmt.Errorf("max pods per topology: 1"), 0),
+	}
+	g.Expect(explain(3, decisions)).To(Equal("0/3 nodes are available: Foo rejected 1 nodes (node-1); " +
+		"Bar rejected 2 nodes (node-2, node-3): max pods per topology: 1"))
+}
@@ -35,6 +35,9 @@ func (f *FakePredicate) Name() string {
 
 func (f *FakePredicate) Filter(_ string, _ *v1.Pod, nodes []v1.Node) ([]v1.Node, error) {
 	if f.Err != nil {
+		if IsPreferenceError(f.Err) {
+			return nodes, f.Err
+		}
 		return nil, f.Err
 	}
 	if f.Nodes != nil {
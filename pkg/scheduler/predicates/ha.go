@@ -67,6 +67,42 @@ func NewHA(kubeCli kubernetes.Interface, cli versioned.Interface) Predicate {
 	return h
 }
 
+// NewDryRunHA returns a HA Predicate used to simulate the placement of new pods of the TidbCluster,
+// it never acquires the scheduling lock, the given TidbCluster is used as if it were scaled out
+// and the simulated pods are counted as if they were scheduled
+func NewDryRunHA(kubeCli kubernetes.Interface, tc *v1alpha1.TidbCluster, simulatedPods func() []apiv1.Pod) Predicate {
+	h := NewHA(kubeCli, nil).(*ha)
+	h.tcGetFn = func(ns, tcName string) (*v1alpha1.TidbCluster, error) {
+		return tc, nil
+	}
+	h.podListFn = func(ns, instanceName, component string) (*apiv1.PodList, error) {
+		podList, err := h.realPodListFn(ns, instanceName, component)
+		if err != nil {
+			return nil, err
+		}
+		for _, pod := range simulatedPods() {
+			if pod.Labels[label.ComponentLabelKey] == component {
+				podList.Items = append(podList.Items, pod)
+			}
+		}
+		return podList, nil
+	}
+	// the simulated pods are new, their PVCs do not exist
+	h.pvcGetFn = func(ns, pvcName string) (*apiv1.PersistentVolumeClaim, error) {
+		return &apiv1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: pvcName},
+			Status:     apiv1.PersistentVolumeClaimStatus{Phase: apiv1.ClaimPending},
+		}, nil
+	}
+	h.acquireLockFn = func(*apiv1.Pod) (*apiv1.PersistentVolumeClaim, *apiv1.PersistentVolumeClaim, error) {
+		return nil, nil, nil
+	}
+	h.updatePVCFn = func(*apiv1.PersistentVolumeClaim) error {
+		return nil
+	}
+	return h
+}
+
 func (h *ha) Name() string {
 	return "HAScheduling"
 }
@@ -79,6 +115,12 @@ func (h *ha) Name() string {
 //     when replicas is less than 3, no HA is forced because HA is impossible
 //     when replicas is equal or greater than 3, we require TiKV pods are running on more than 3 nodes and no more than ceil(replicas / 3) per node
 //  for PD/TiKV, we both try to balance the number of pods across the nodes
+//  c) if failure domain keys (e.g. region, zone) are configured, among the nodes which can run one more pod, we prefer
+//     the nodes in the failure domains which have the minimum count of the component, domain by domain in order, and
+//     then balance the number of pods across the nodes in these domains
+//  d) with the delete slots of AdvancedStatefulSet, only the pods in the desired ordinals and the pods not scaled in yet are counted
+//  e) if HA spreading can not be satisfied, the pod stays Pending if the HA policy of the component is required (default),
+//     or all the feasible nodes are returned with a PreferenceError if the HA policy is preferred
 // 3. let kube-scheduler to make the final decision
 func (h *ha) Filter(instanceName string, pod *apiv1.Pod, nodes []apiv1.Node) ([]apiv1.Node, error) {
 	h.lock.Lock()
@@ -121,8 +163,13 @@ func (h *ha) Filter(instanceName string, pod *apiv1.Pod, nodes []apiv1.Node) ([]
 	if err != nil {
 		return nil, err
 	}
+	expectedOrdinals := getExpectedOrdinals(tc, component)
 	replicas := getReplicasFrom(tc, component)
-	klog.Infof("ha: tidbcluster %s/%s component %s replicas %d", ns, tcName, component, replicas)
+	if n := int32(expectedOrdinals.Len()); n > replicas {
+		// some pods in the delete slots are still members, count them in the replicas until they are scaled in
+		replicas = n
+	}
+	klog.Infof("ha: tidbcluster %s/%s component %s replicas %d, expected ordinals %v", ns, tcName, component, replicas, expectedOrdinals.List())
 
 	var topologyKey string
 	if tc.Annotations[label.AnnHATopologyKey] != "" {
@@ -142,7 +189,13 @@ func (h *ha) Filter(instanceName string, pod *apiv1.Pod, nodes []apiv1.Node) ([]
 		topologyMap[node.Labels[topologyKey]] = make(sets.String)
 	}
 
+	domainKeys := getFailureDomainKeys(tc, component)
+	if len(domainKeys) > 0 {
+		klog.Infof("current failure domain keys: %v", domainKeys)
+	}
+
 	scheduledNodes := make([]*apiv1.Node, 0)
+	domainScheduledNodes := make([]*apiv1.Node, 0)
 	for _, pod := range podList.Items {
 		nodeName := pod.Spec.NodeName
 		if nodeName == "" {
@@ -153,17 +206,23 @@ func (h *ha) Filter(instanceName string, pod *apiv1.Pod, nodes []apiv1.Node) ([]
 			klog.Errorf("failed to get node by name, nodeName: %s, error: %v", nodeName, err)
 			return nil, err
 		}
+		if len(domainKeys) > 0 {
+			domainScheduledNodes = append(domainScheduledNodes, scheduledNode)
+		}
 		if _, ok := scheduledNode.Labels[topologyKey]; !ok {
 			continue
 		}
 		scheduledNodes = append(scheduledNodes, scheduledNode)
 	}
 
+	// node names of the pods counted in HA, used to count pods per failure domain
+	countedNodeNames := make([]string, 0)
+
 	for _, pod := range podList.Items {
 		pName := pod.GetName()
 
-		if !isPodDesired(tc, component, pName) {
-			klog.Infof("pod %s is not in desired ordinals, do not count its topology", pName)
+		if !isPodExpected(expectedOrdinals, pName) {
+			klog.Infof("pod %s is not in expected ordinals, do not count its topology", pName)
 			continue
 		}
 
@@ -173,6 +232,9 @@ func (h *ha) Filter(instanceName string, pod *apiv1.Pod, nodes []apiv1.Node) ([]
 		}
 
 		nodeName := pod.Spec.NodeName
+		if nodeName != "" {
+			countedNodeNames = append(countedNodeNames, nodeName)
+		}
 
 		topology := getTopologyFromNode(topologyKey, nodeName, nodes, scheduledNodes)
 		if topology != "" {
@@ -187,8 +249,6 @@ func (h *ha) Filter(instanceName string, pod *apiv1.Pod, nodes []apiv1.Node) ([]
 	}
 	klog.V(4).Infof("topologyMap: %+v", topologyMap)
 
-	min := -1
-	minTopologies := make([]string, 0)
 	maxPodsPerTopology := 0
 
 	if component == label.PDLabelVal {
@@ -229,13 +289,15 @@ func (h *ha) Filter(instanceName string, pod *apiv1.Pod, nodes []apiv1.Node) ([]
 		}
 	}
 
+	// the topologies which can run one more pod
+	availableTopologies := make([]string, 0)
 	for topology, podNames := range topologyMap {
 		podsCount := len(podNames)
 
 		// tikv replicas less than 3 cannot achieve high availability
 		if component == label.TiKVLabelVal && replicas < 3 {
-			minTopologies = append(minTopologies, topology)
-			klog.Infof("replicas is %d, add topology %s to minTopologies", replicas, topology)
+			availableTopologies = append(availableTopologies, topology)
+			klog.Infof("replicas is %d, add topology %s to available topologies", replicas, topology)
 			continue
 		}
 
@@ -245,8 +307,46 @@ func (h *ha) Filter(instanceName string, pod *apiv1.Pod, nodes []apiv1.Node) ([]
 				topology, podsCount, component, maxPodsPerTopology)
 			continue
 		}
+		availableTopologies = append(availableTopologies, topology)
+	}
+
+	if len(availableTopologies) == 0 {
+		topologyStrArr := []string{}
+		for topology, podNames := range topologyMap {
+			s := fmt.Sprintf("%s (%d %s pods)", topology, podNames.Len(), strings.ToLower(component))
+			topologyStrArr = append(topologyStrArr, s)
+		}
+		sort.Strings(topologyStrArr)
 
-		// Choose topology which has minimum count of the component
+		// example: unable to schedule to topologies: kube-node-1 (1 pd pods), kube-node-2 (1 pd pods), max pods per topology: 1
+		errMsg := fmt.Sprintf("unable to schedule to topology: %s, max pods per topology: %d",
+			strings.Join(topologyStrArr, ", "), maxPodsPerTopology)
+		if getHAPolicy(tc, component) == label.HAPolicyPreferred {
+			klog.Warningf("ha: %s, HA policy of component %s is %s, schedule pod %s/%s anyway",
+				errMsg, component, label.HAPolicyPreferred, ns, podName)
+			return nodes, &PreferenceError{Message: fmt.Sprintf("%s, HA policy is %s, scheduled anyway", errMsg, label.HAPolicyPreferred)}
+		}
+		return nil, errors.New(errMsg)
+	}
+
+	// the failure domains are balanced first, and then the topologies in the chosen domains
+	candidates := getNodeFromTopologies(nodes, topologyKey, availableTopologies)
+	for _, domainKey := range domainKeys {
+		candidates = filterByFailureDomain(domainKey, candidates, countedNodeNames, nodes, domainScheduledNodes)
+	}
+
+	// Choose topology which has minimum count of the component
+	min := -1
+	minTopologies := make([]string, 0)
+	for _, topology := range sets.NewString(availableTopologies...).List() {
+		if len(getNodeFromTopologies(candidates, topologyKey, []string{topology})) == 0 {
+			continue
+		}
+		podsCount := topologyMap[topology].Len()
+		if component == label.TiKVLabelVal && replicas < 3 {
+			minTopologies = append(minTopologies, topology)
+			continue
+		}
 		if min == -1 {
 			min = podsCount
 		}
@@ -260,22 +360,76 @@ func (h *ha) Filter(instanceName string, pod *apiv1.Pod, nodes []apiv1.Node) ([]
 		}
 		minTopologies = append(minTopologies, topology)
 	}
+	return getNodeFromTopologies(candidates, topologyKey, minTopologies), nil
+}
 
-	if len(minTopologies) == 0 {
-		topologyStrArr := []string{}
-		for topology, podNames := range topologyMap {
-			s := fmt.Sprintf("%s (%d %s pods)", topology, podNames.Len(), strings.ToLower(component))
-			topologyStrArr = append(topologyStrArr, s)
+// getHAPolicy returns the HA policy of the component, defaults to required
+func getHAPolicy(tc *v1alpha1.TidbCluster, component string) string {
+	key := label.AnnTiKVHAPolicy
+	if component == label.PDLabelVal {
+		key = label.AnnPDHAPolicy
+	}
+	if tc.Annotations[key] == label.HAPolicyPreferred {
+		return label.HAPolicyPreferred
+	}
+	return label.HAPolicyRequired
+}
+
+// getFailureDomainKeys returns the node label keys of the failure domains of the component,
+// the component specific annotation takes precedence over the general one
+func getFailureDomainKeys(tc *v1alpha1.TidbCluster, component string) []string {
+	value := tc.Annotations[label.AnnHADomainTopologyKeys]
+	componentKey := label.AnnTiKVHADomainTopologyKeys
+	if component == label.PDLabelVal {
+		componentKey = label.AnnPDHADomainTopologyKeys
+	}
+	if v, ok := tc.Annotations[componentKey]; ok {
+		value = v
+	}
+	keys := make([]string, 0)
+	for _, key := range strings.Split(value, ",") {
+		key = strings.TrimSpace(key)
+		if key != "" {
+			keys = append(keys, key)
 		}
-		sort.Strings(topologyStrArr)
+	}
+	return keys
+}
 
-		// example: unable to schedule to topologies: kube-node-1 (1 pd pods), kube-node-2 (1 pd pods), max pods per topology: 1
-		errMsg := fmt.Sprintf("unable to schedule to topology: %s, max pods per topology: %d",
-			strings.Join(topologyStrArr, ", "), maxPodsPerTopology)
-		return nil, errors.New(errMsg)
+// filterByFailureDomain keeps the candidate nodes in the failure domains which have the minimum count of pods,
+// candidates are returned unchanged if none of them has the domain label
+func filterByFailureDomain(domainKey string, candidates []apiv1.Node, podNodeNames []string, nodes []apiv1.Node, scheduledNodes []*apiv1.Node) []apiv1.Node {
+	domainCount := make(map[string]int)
+	for _, node := range candidates {
+		if domain, ok := node.Labels[domainKey]; ok {
+			domainCount[domain] = 0
+		}
+	}
+	if len(domainCount) == 0 {
+		klog.Infof("no candidate node has failure domain label %s, skip spreading by it", domainKey)
+		return candidates
+	}
+	for _, nodeName := range podNodeNames {
+		domain := getTopologyFromNode(domainKey, nodeName, nodes, scheduledNodes)
+		if _, ok := domainCount[domain]; ok {
+			domainCount[domain]++
+		}
 	}
 
-	return getNodeFromTopologies(nodes, topologyKey, minTopologies), nil
+	min := -1
+	for _, count := range domainCount {
+		if min == -1 || count < min {
+			min = count
+		}
+	}
+	minDomains := make([]string, 0)
+	for domain, count := range domainCount {
+		if count == min {
+			minDomains = append(minDomains, domain)
+		}
+	}
+	klog.Infof("failure domain %s: pods count %v, choose domains %v", domainKey, domainCount, minDomains)
+	return getNodeFromTopologies(candidates, domainKey, minDomains)
 }
 
 // kubernetes scheduling is parallel, to achieve HA, we must ensure the scheduling is serial,
@@ -462,11 +616,47 @@ func getTopologyFromNode(topologyKey string, nodeName string, nodes []apiv1.Node
 	return ""
 }
 
-func isPodDesired(tc *v1alpha1.TidbCluster, component, podName string) bool {
-	ordinals := tc.TiKVStsDesiredOrdinals(false)
+// getExpectedOrdinals returns the ordinals of the pods counted in HA. With the delete slots of
+// AdvancedStatefulSet the ordinals are not consecutive, so they are the desired ordinals computed from
+// the replicas and the delete slots, plus the ordinals which are still members of the PD cluster or
+// TiKV stores, e.g. the pods in the delete slots which are not scaled in yet.
+func getExpectedOrdinals(tc *v1alpha1.TidbCluster, component string) sets.Int32 {
 	if component == v1alpha1.PDMemberType.String() {
-		ordinals = tc.PDStsDesiredOrdinals(false)
+		ordinals := tc.PDStsDesiredOrdinals(false)
+		for _, member := range tc.Status.PD.Members {
+			// the member name is the pod name or the FQDN of the pod
+			podName := strings.Split(member.Name, ".")[0]
+			if ordinal, ok := getMemberOrdinal(tc, component, podName); ok && !ordinals.Has(ordinal) {
+				klog.Infof("pd member %s is not in desired ordinals but not scaled in yet, count its topology", member.Name)
+				ordinals.Insert(ordinal)
+			}
+		}
+		return ordinals
+	}
+
+	ordinals := tc.TiKVStsDesiredOrdinals(false)
+	for _, store := range tc.Status.TiKV.Stores {
+		if ordinal, ok := getMemberOrdinal(tc, component, store.PodName); ok && !ordinals.Has(ordinal) {
+			klog.Infof("tikv store %s of pod %s is not in desired ordinals but not scaled in yet, count its topology", store.ID, store.PodName)
+			ordinals.Insert(ordinal)
+		}
+	}
+	return ordinals
+}
+
+// getMemberOrdinal returns the ordinal of the pod if it belongs to the component of the TidbCluster
+func getMemberOrdinal(tc *v1alpha1.TidbCluster, component, podName string) (int32, bool) {
+	if !strings.HasPrefix(podName, fmt.Sprintf("%s-%s-", tc.GetName(), component)) {
+		return 0, false
 	}
+	ordinal, err := util.GetOrdinalFromPodName(podName)
+	if err != nil {
+		return 0, false
+	}
+	return ordinal, true
+}
+
+func isPodExpected(ordinals sets.Int32, podName string) bool {
 	ordinal, err := util.GetOrdinalFromPodName(podName)
 	if err != nil {
 		klog.Errorf("unexpected pod name %q: %v", podName, err)
@@ -1067,6 +1067,28 @@ func TestHAFilter(t *testing.T) {
 				g.Expect(getSortedNodeNames(nodes)).To(Equal([]string{"kube-node-2", "kube-node-4"}))
 			},
 		},
+		{
+			name:          "[support-asts] set pd.tidb.pingcap.com/delete-slots: '[2]', pd-2 is not scaled in yet",
+			podFn:         newHAPDPod,
+			nodesFn:       fakeFourNodes,
+			podListFn:     podListFn(map[string][]int32{"kube-node-1": {1}, "kube-node-2": {2}, "kube-node-3": {3}, "kube-node-4": {4}}),
+			acquireLockFn: acquireSuccess,
+			tcGetFn: func(ns string, tcName string) (*v1alpha1.TidbCluster, error) {
+				tc, _ := tcGetFn(ns, tcName)
+				tc.Name = clusterName
+				tc.Annotations["pd.tidb.pingcap.com/delete-slots"] = "[2]"
+				pd2 := fmt.Sprintf("%s-%d", controller.PDMemberName(clusterName), 2)
+				tc.Status.PD.Members = map[string]v1alpha1.PDMember{
+					pd2: {Name: pd2, Health: true},
+				}
+				return tc, nil
+			},
+			scheduledNodeGetFn: fakeZeroScheduledNode,
+			expectFn: func(nodes []apiv1.Node, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(getSortedNodeNames(nodes)).To(Equal([]string{"kube-node-4"}))
+			},
+		},
 		{
 			name:          "pd-1 is in failureMembers",
 			podFn:         newHAPDPod,
@@ -1091,6 +1113,117 @@ func TestHAFilter(t *testing.T) {
 				g.Expect(getSortedNodeNames(nodes)).To(Equal([]string{"kube-node-1", "kube-node-4"}))
 			},
 		},
+		{
+			name:          "spread pd pods across zones first, then nodes",
+			podFn:         newHAPDPod,
+			nodesFn:       fakeFourNodesWithThreeTopologies,
+			podListFn:     podListFn(map[string][]int32{"kube-node-3": {1}}),
+			acquireLockFn: acquireSuccess,
+			tcGetFn: func(ns string, tcName string) (*v1alpha1.TidbCluster, error) {
+				tc, _ := tcGetFn(ns, tcName)
+				tc.Annotations[label.AnnHATopologyKey] = "kubernetes.io/hostname"
+				tc.Annotations[label.AnnHADomainTopologyKeys] = "zone"
+				return tc, nil
+			},
+			scheduledNodeGetFn: fakeScheduledNode("kube-node-3", "zone3"),
+			expectFn: func(nodes []apiv1.Node, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(getSortedNodeNames(nodes)).To(Equal([]string{"kube-node-1", "kube-node-2"}))
+			},
+		},
+		{
+			name:          "pd specific failure domain keys override the general ones",
+			podFn:         newHAPDPod,
+			nodesFn:       fakeFourNodesWithThreeTopologies,
+			podListFn:     podListFn(map[string][]int32{"kube-node-3": {1}}),
+			acquireLockFn: acquireSuccess,
+			tcGetFn: func(ns string, tcName string) (*v1alpha1.TidbCluster, error) {
+				tc, _ := tcGetFn(ns, tcName)
+				tc.Annotations[label.AnnHATopologyKey] = "kubernetes.io/hostname"
+				tc.Annotations[label.AnnHADomainTopologyKeys] = "zone"
+				tc.Annotations[label.AnnPDHADomainTopologyKeys] = ""
+				return tc, nil
+			},
+			scheduledNodeGetFn: fakeScheduledNode("kube-node-3", "zone3"),
+			expectFn: func(nodes []apiv1.Node, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(getSortedNodeNames(nodes)).To(Equal([]string{"kube-node-1", "kube-node-2", "kube-node-4"}))
+			},
+		},
+		{
+			name:          "balance the failure domains before the nodes",
+			podFn:         newHAPDPod,
+			nodesFn:       fakeFourNodesWithThreeTopologies,
+			podListFn:     podListFn(map[string][]int32{"kube-node-1": {0}, "kube-node-2": {1}, "kube-node-3": {2, 3}, "kube-node-4": {}}),
+			acquireLockFn: acquireSuccess,
+			tcGetFn: func(ns string, tcName string) (*v1alpha1.TidbCluster, error) {
+				tc, _ := tcGetFn(ns, tcName)
+				tc.Spec.PD.Replicas = 5
+				tc.Annotations[label.AnnHATopologyKey] = "kubernetes.io/hostname"
+				tc.Annotations[label.AnnHADomainTopologyKeys] = "zone"
+				return tc, nil
+			},
+			scheduledNodeGetFn: fakeZeroScheduledNode,
+			expectFn: func(nodes []apiv1.Node, err error) {
+				// kube-node-4 has the fewest pods, but zone3 has more pods than zone1 and zone2
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(getSortedNodeNames(nodes)).To(Equal([]string{"kube-node-1", "kube-node-2"}))
+			},
+		},
+		{
+			name:          "required HA policy, unable to schedule",
+			podFn:         newHAPDPod,
+			nodesFn:       fakeTwoNodes,
+			podListFn:     podListFn(map[string][]int32{"kube-node-1": {1}, "kube-node-2": {2}}),
+			acquireLockFn: acquireSuccess,
+			tcGetFn: func(ns string, tcName string) (*v1alpha1.TidbCluster, error) {
+				tc, _ := tcGetFn(ns, tcName)
+				tc.Annotations[label.AnnPDHAPolicy] = label.HAPolicyRequired
+				return tc, nil
+			},
+			scheduledNodeGetFn: fakeZeroScheduledNode,
+			expectFn: func(nodes []apiv1.Node, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(IsPreferenceError(err)).To(BeFalse())
+				g.Expect(nodes).To(BeNil())
+			},
+		},
+		{
+			name:          "preferred HA policy, schedule anyway",
+			podFn:         newHAPDPod,
+			nodesFn:       fakeTwoNodes,
+			podListFn:     podListFn(map[string][]int32{"kube-node-1": {1}, "kube-node-2": {2}}),
+			acquireLockFn: acquireSuccess,
+			tcGetFn: func(ns string, tcName string) (*v1alpha1.TidbCluster, error) {
+				tc, _ := tcGetFn(ns, tcName)
+				tc.Annotations[label.AnnPDHAPolicy] = label.HAPolicyPreferred
+				return tc, nil
+			},
+			scheduledNodeGetFn: fakeZeroScheduledNode,
+			expectFn: func(nodes []apiv1.Node, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(IsPreferenceError(err)).To(BeTrue())
+				g.Expect(err.Error()).To(ContainSubstring("unable to schedule to topology"))
+				g.Expect(getSortedNodeNames(nodes)).To(Equal([]string{"kube-node-1", "kube-node-2"}))
+			},
+		},
+		{
+			name:          "preferred HA policy of tikv does not apply to pd",
+			podFn:         newHAPDPod,
+			nodesFn:       fakeTwoNodes,
+			podListFn:     podListFn(map[string][]int32{"kube-node-1": {1}, "kube-node-2": {2}}),
+			acquireLockFn: acquireSuccess,
+			tcGetFn: func(ns string, tcName string) (*v1alpha1.TidbCluster, error) {
+				tc, _ := tcGetFn(ns, tcName)
+				tc.Annotations[label.AnnTiKVHAPolicy] = label.HAPolicyPreferred
+				return tc, nil
+			},
+			scheduledNodeGetFn: fakeZeroScheduledNode,
+			expectFn: func(nodes []apiv1.Node, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(IsPreferenceError(err)).To(BeFalse())
+			},
+		},
 	}
 
 	for i := range tests {
@@ -1098,6 +1231,123 @@ func TestHAFilter(t *testing.T) {
 	}
 }
 
+func TestFilterByFailureDomain(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	nodes := []apiv1.Node{
+		fakeDomainNode("node-1", "region1", "zone1"),
+		fakeDomainNode("node-2", "region1", "zone2"),
+		fakeDomainNode("node-3", "region2", "zone3"),
+		fakeDomainNode("node-4", "region2", "zone4"),
+	}
+
+	tests := []struct {
+		name         string
+		domainKeys   []string
+		podNodeNames []string
+		expected     []string
+	}{
+		{
+			name:         "no pods scheduled",
+			domainKeys:   []string{"region", "zone"},
+			podNodeNames: nil,
+			expected:     []string{"node-1", "node-2", "node-3", "node-4"},
+		},
+		{
+			name:         "prefer the region with fewer pods",
+			domainKeys:   []string{"region"},
+			podNodeNames: []string{"node-1"},
+			expected:     []string{"node-3", "node-4"},
+		},
+		{
+			name:         "region first, then zone",
+			domainKeys:   []string{"region", "zone"},
+			podNodeNames: []string{"node-1", "node-2", "node-3"},
+			expected:     []string{"node-4"},
+		},
+		{
+			name:         "unknown domain key is ignored",
+			domainKeys:   []string{"rack"},
+			podNodeNames: []string{"node-1"},
+			expected:     []string{"node-1", "node-2", "node-3", "node-4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+		candidates := nodes
+		for _, key := range tt.domainKeys {
+			candidates = filterByFailureDomain(key, candidates, tt.podNodeNames, nodes, nil)
+		}
+		g.Expect(getSortedNodeNames(candidates)).To(Equal(tt.expected))
+	}
+}
+
+func TestGetFailureDomainKeys(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc, _ := tcGetFn("ns", "tc")
+	g.Expect(getFailureDomainKeys(tc, label.PDLabelVal)).To(BeEmpty())
+
+	tc.Annotations[label.AnnHADomainTopologyKeys] = "region, zone"
+	g.Expect(getFailureDomainKeys(tc, label.PDLabelVal)).To(Equal([]string{"region", "zone"}))
+	g.Expect(getFailureDomainKeys(tc, label.TiKVLabelVal)).To(Equal([]string{"region", "zone"}))
+
+	tc.Annotations[label.AnnTiKVHADomainTopologyKeys] = "zone"
+	g.Expect(getFailureDomainKeys(tc, label.PDLabelVal)).To(Equal([]string{"region", "zone"}))
+	g.Expect(getFailureDomainKeys(tc, label.TiKVLabelVal)).To(Equal([]string{"zone"}))
+}
+
+func TestGetExpectedOrdinals(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "demo",
+			Namespace: corev1.NamespaceDefault,
+			Annotations: map[string]string{
+				label.AnnPDDeleteSlots:   "[1]",
+				label.AnnTiKVDeleteSlots: "[0,2]",
+			},
+		},
+		Spec: v1alpha1.TidbClusterSpec{
+			PD:   &v1alpha1.PDSpec{Replicas: 3},
+			TiKV: &v1alpha1.TiKVSpec{Replicas: 3},
+		},
+		Status: v1alpha1.TidbClusterStatus{
+			PD: v1alpha1.PDStatus{
+				Members: map[string]v1alpha1.PDMember{
+					"demo-pd-0.demo-pd-peer.default.svc.cluster.local": {Name: "demo-pd-0.demo-pd-peer.default.svc.cluster.local"},
+					"demo-pd-1.demo-pd-peer.default.svc.cluster.local": {Name: "demo-pd-1.demo-pd-peer.default.svc.cluster.local"},
+					"other-pd-5": {Name: "other-pd-5"},
+				},
+			},
+			TiKV: v1alpha1.TiKVStatus{
+				Stores: map[string]v1alpha1.TiKVStore{
+					"1": {ID: "1", PodName: "demo-tikv-2", State: v1alpha1.TiKVStateOffline},
+					"4": {ID: "4", PodName: "demo-tikv-4"},
+				},
+			},
+		},
+	}
+	g.Expect(getExpectedOrdinals(tc, label.PDLabelVal).List()).To(Equal([]int32{0, 1, 2, 3}))
+	g.Expect(getExpectedOrdinals(tc, label.TiKVLabelVal).List()).To(Equal([]int32{1, 2, 3, 4}))
+}
+
+func fakeDomainNode(name, region, zone string) apiv1.Node {
+	return apiv1.Node{
+		TypeMeta: metav1.TypeMeta{Kind: "Node", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				"kubernetes.io/hostname": name,
+				"region":                 region,
+				"zone":                   zone,
+			},
+		},
+	}
+}
+
 func newHAPDPod(instanceName, clusterName string, ordinal int32) *apiv1.Pod {
 	return &apiv1.Pod{
 		TypeMeta: metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
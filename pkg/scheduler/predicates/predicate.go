@@ -26,6 +26,23 @@ type Predicate interface {
 	Filter(string, *apiv1.Pod, []apiv1.Node) ([]apiv1.Node, error)
 }
 
+// PreferenceError is returned along with the candidate nodes by a predicate
+// when its scheduling preference can not be satisfied, the pod is scheduled
+// anyway and the error is reported as a warning event
+type PreferenceError struct {
+	Message string
+}
+
+func (e *PreferenceError) Error() string {
+	return e.Message
+}
+
+// IsPreferenceError returns true if the error is a PreferenceError
+func IsPreferenceError(err error) bool {
+	_, ok := err.(*PreferenceError)
+	return ok
+}
+
 func getNodeFromTopologies(nodes []apiv1.Node, topologyKey string, topologies []string) []apiv1.Node {
 	var retNodes []apiv1.Node
 	for _, node := range nodes {
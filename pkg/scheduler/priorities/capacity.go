@@ -0,0 +1,182 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package priorities
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
+)
+
+type capacity struct {
+	kubeCli   kubernetes.Interface
+	cli       versioned.Interface
+	pdControl pdapi.PDControlInterface
+
+	podListFn   func(ns, instanceName, component string) (*apiv1.PodList, error)
+	pvcListFn   func(ns, instanceName, component string) (*apiv1.PersistentVolumeClaimList, error)
+	tcGetFn     func(ns, tcName string) (*v1alpha1.TidbCluster, error)
+	storesGetFn func(tc *v1alpha1.TidbCluster) (*pdapi.StoresInfo, error)
+}
+
+// NewCapacity returns a Priority which prefers the nodes holding less TiKV data
+func NewCapacity(kubeCli kubernetes.Interface, cli versioned.Interface) Priority {
+	c := &capacity{
+		kubeCli:   kubeCli,
+		cli:       cli,
+		pdControl: pdapi.NewDefaultPDControl(kubeCli),
+	}
+	c.podListFn = c.realPodListFn
+	c.pvcListFn = c.realPVCListFn
+	c.tcGetFn = c.realTCGetFn
+	c.storesGetFn = c.realStoresGetFn
+	return c
+}
+
+func (c *capacity) Name() string {
+	return "CapacityScheduling"
+}
+
+// Score scores the nodes by the TiKV data they hold, so that new stores land on
+// the nodes which balance the data distribution rather than just the pod counts.
+// 1. the data size of a TiKV pod is the used size (capacity - available) of its store reported by PD
+// 2. if the store of a TiKV pod is not reported by PD yet, the storage request of its PVC is used instead
+// 3. the node holding the most data gets 0, a node holding no data gets MaxScore
+func (c *capacity) Score(instanceName string, pod *apiv1.Pod, nodes []apiv1.Node) (map[string]int, error) {
+	ns := pod.GetNamespace()
+	podName := pod.GetName()
+	component := pod.Labels[label.ComponentLabelKey]
+
+	scores := make(map[string]int, len(nodes))
+	for _, node := range nodes {
+		scores[node.Name] = 0
+	}
+	if component != label.TiKVLabelVal {
+		return scores, nil
+	}
+
+	tcName := getTCNameFromPod(pod, component)
+	tc, err := c.tcGetFn(ns, tcName)
+	if err != nil {
+		return nil, err
+	}
+	podList, err := c.podListFn(ns, instanceName, component)
+	if err != nil {
+		return nil, err
+	}
+	pvcList, err := c.pvcListFn(ns, instanceName, component)
+	if err != nil {
+		return nil, err
+	}
+	storesInfo, err := c.storesGetFn(tc)
+	if err != nil {
+		return nil, err
+	}
+
+	usedByPod := usedSizeByPod(tc, storesInfo)
+	requestByPVC := make(map[string]int64)
+	for _, pvc := range pvcList.Items {
+		if q, ok := pvc.Spec.Resources.Requests[apiv1.ResourceStorage]; ok {
+			requestByPVC[pvc.Name] = q.Value()
+		}
+	}
+
+	dataByNode := make(map[string]int64)
+	for _, p := range podList.Items {
+		if p.Name == podName || p.Spec.NodeName == "" {
+			continue
+		}
+		size, ok := usedByPod[p.Name]
+		if !ok {
+			size = requestByPVC[fmt.Sprintf("%s-%s", component, p.Name)]
+		}
+		dataByNode[p.Spec.NodeName] += size
+	}
+
+	var maxData int64
+	for _, node := range nodes {
+		if dataByNode[node.Name] > maxData {
+			maxData = dataByNode[node.Name]
+		}
+	}
+	if maxData == 0 {
+		return scores, nil
+	}
+	for _, node := range nodes {
+		scores[node.Name] = int(MaxScore - MaxScore*dataByNode[node.Name]/maxData)
+	}
+	klog.Infof("capacity: pod %s/%s, data size by node: %v, scores: %v", ns, podName, dataByNode, scores)
+	return scores, nil
+}
+
+// usedSizeByPod returns the used size of the stores reported by PD, keyed by the TiKV pod name
+func usedSizeByPod(tc *v1alpha1.TidbCluster, storesInfo *pdapi.StoresInfo) map[string]int64 {
+	used := make(map[string]int64)
+	if storesInfo == nil {
+		return used
+	}
+	for _, s := range storesInfo.Stores {
+		if s.Store == nil || s.Store.Store == nil || s.Status == nil {
+			continue
+		}
+		store, ok := tc.Status.TiKV.Stores[strconv.FormatUint(s.Store.GetId(), 10)]
+		if !ok {
+			continue
+		}
+		if s.Status.Capacity < s.Status.Available {
+			continue
+		}
+		used[store.PodName] = int64(s.Status.Capacity - s.Status.Available)
+	}
+	return used
+}
+
+func (c *capacity) realPodListFn(ns, instanceName, component string) (*apiv1.PodList, error) {
+	selector := label.New().Instance(instanceName).Component(component).Labels()
+	return c.kubeCli.CoreV1().Pods(ns).List(metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(selector).String(),
+	})
+}
+
+func (c *capacity) realPVCListFn(ns, instanceName, component string) (*apiv1.PersistentVolumeClaimList, error) {
+	selector := label.New().Instance(instanceName).Component(component).Labels()
+	return c.kubeCli.CoreV1().PersistentVolumeClaims(ns).List(metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(selector).String(),
+	})
+}
+
+func (c *capacity) realTCGetFn(ns, tcName string) (*v1alpha1.TidbCluster, error) {
+	return c.cli.PingcapV1alpha1().TidbClusters(ns).Get(tcName, metav1.GetOptions{})
+}
+
+func (c *capacity) realStoresGetFn(tc *v1alpha1.TidbCluster) (*pdapi.StoresInfo, error) {
+	return controller.GetPDClient(c.pdControl, tc).GetStores()
+}
+
+func getTCNameFromPod(pod *apiv1.Pod, component string) string {
+	return strings.TrimSuffix(pod.GenerateName, fmt.Sprintf("-%s-", component))
+}
+
+var _ Priority = &capacity{}
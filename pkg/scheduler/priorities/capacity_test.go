@@ -0,0 +1,160 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package priorities
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/tikv/pd/pkg/typeutil"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const gb = int64(1) << 30
+
+func newTiKVPod(name, nodeName string) apiv1.Pod {
+	return apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:         name,
+			Namespace:    apiv1.NamespaceDefault,
+			GenerateName: "demo-tikv-",
+			Labels:       label.New().Instance("demo").TiKV().Labels(),
+		},
+		Spec: apiv1.PodSpec{NodeName: nodeName},
+	}
+}
+
+func newTiKVPVC(podName, size string) apiv1.PersistentVolumeClaim {
+	return apiv1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("tikv-%s", podName)},
+		Spec: apiv1.PersistentVolumeClaimSpec{
+			Resources: apiv1.ResourceRequirements{
+				Requests: apiv1.ResourceList{apiv1.ResourceStorage: resource.MustParse(size)},
+			},
+		},
+	}
+}
+
+func storeInfo(id uint64, capacity, available int64) *pdapi.StoreInfo {
+	return &pdapi.StoreInfo{
+		Store: &pdapi.MetaStore{Store: &metapb.Store{Id: id}},
+		Status: &pdapi.StoreStatus{
+			Capacity:  typeutil.ByteSize(capacity),
+			Available: typeutil.ByteSize(available),
+		},
+	}
+}
+
+func TestCapacityScore(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	nodes := []apiv1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "node-3"}},
+	}
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: apiv1.NamespaceDefault},
+		Status: v1alpha1.TidbClusterStatus{
+			TiKV: v1alpha1.TiKVStatus{
+				Stores: map[string]v1alpha1.TiKVStore{
+					"1": {ID: "1", PodName: "demo-tikv-0"},
+					"2": {ID: "2", PodName: "demo-tikv-1"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		pod      apiv1.Pod
+		pods     []apiv1.Pod
+		pvcs     []apiv1.PersistentVolumeClaim
+		stores   []*pdapi.StoreInfo
+		storeErr error
+		expected map[string]int
+		wantErr  bool
+	}{
+		{
+			name:     "not tikv pod",
+			pod:      apiv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "demo-pd-0", Labels: label.New().Instance("demo").PD().Labels()}},
+			expected: map[string]int{"node-1": 0, "node-2": 0, "node-3": 0},
+		},
+		{
+			name:     "no data",
+			pod:      newTiKVPod("demo-tikv-3", ""),
+			expected: map[string]int{"node-1": 0, "node-2": 0, "node-3": 0},
+		},
+		{
+			name: "score by store used size",
+			pod:  newTiKVPod("demo-tikv-3", ""),
+			pods: []apiv1.Pod{newTiKVPod("demo-tikv-0", "node-1"), newTiKVPod("demo-tikv-1", "node-2")},
+			stores: []*pdapi.StoreInfo{
+				storeInfo(1, 100*gb, 20*gb),
+				storeInfo(2, 100*gb, 60*gb),
+			},
+			expected: map[string]int{"node-1": 0, "node-2": 5, "node-3": 10},
+		},
+		{
+			name: "pvc request is used if the store is not reported",
+			pod:  newTiKVPod("demo-tikv-3", ""),
+			pods: []apiv1.Pod{newTiKVPod("demo-tikv-0", "node-1"), newTiKVPod("demo-tikv-2", "node-2")},
+			pvcs: []apiv1.PersistentVolumeClaim{newTiKVPVC("demo-tikv-2", "20Gi")},
+			stores: []*pdapi.StoreInfo{
+				storeInfo(1, 100*gb, 90*gb),
+			},
+			expected: map[string]int{"node-1": 5, "node-2": 0, "node-3": 10},
+		},
+		{
+			name:     "failed to get stores",
+			pod:      newTiKVPod("demo-tikv-3", ""),
+			storeErr: fmt.Errorf("pd is unavailable"),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+		c := &capacity{
+			podListFn: func(ns, instanceName, component string) (*apiv1.PodList, error) {
+				return &apiv1.PodList{Items: tt.pods}, nil
+			},
+			pvcListFn: func(ns, instanceName, component string) (*apiv1.PersistentVolumeClaimList, error) {
+				return &apiv1.PersistentVolumeClaimList{Items: tt.pvcs}, nil
+			},
+			tcGetFn: func(ns, tcName string) (*v1alpha1.TidbCluster, error) {
+				g.Expect(tcName).To(Equal("demo"))
+				return tc, nil
+			},
+			storesGetFn: func(tc *v1alpha1.TidbCluster) (*pdapi.StoresInfo, error) {
+				return &pdapi.StoresInfo{Count: len(tt.stores), Stores: tt.stores}, tt.storeErr
+			},
+		}
+		pod := tt.pod
+		scores, err := c.Score("demo", &pod, nodes)
+		if tt.wantErr {
+			g.Expect(err).To(HaveOccurred())
+			continue
+		}
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(scores).To(Equal(tt.expected))
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package priorities
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+)
+
+// MaxScore is the max score a priority can give to a node
+const MaxScore = 10
+
+// Priority is an interface as extender-implemented priority functions
+type Priority interface {
+	// Name return the priority name
+	Name() string
+
+	// Score function receives a set of nodes and returns the scores of the nodes,
+	// the scores range from 0 to MaxScore and higher is better.
+	Score(string, *apiv1.Pod, []apiv1.Node) (map[string]int, error)
+}
@@ -16,11 +16,15 @@ package scheduler
 import (
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
 	"github.com/pingcap/tidb-operator/pkg/features"
 	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/metrics"
 	"github.com/pingcap/tidb-operator/pkg/scheduler/predicates"
+	"github.com/pingcap/tidb-operator/pkg/scheduler/priorities"
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -47,13 +51,22 @@ type Scheduler interface {
 	// are used to compute the weighted score for an extender. The weighted scores are added to
 	// the scores computed  by kubernetes scheduler. The total scores are used to do the host selection.
 	Priority(*schedulerapiv1.ExtenderArgs) (schedulerapiv1.HostPriorityList, error)
+
+	// Simulate simulates where the additional replicas of a component would be placed.
+	Simulate(*SimulateArgs) (*SimulateResult, error)
 }
 
 type scheduler struct {
 	// component => predicates
 	predicates map[string][]predicates.Predicate
+	// component => priorities
+	priorities map[string][]priorities.Priority
+
+	// component => predicates used in the placement simulation
+	simulationPredicatesFn func(tc *v1alpha1.TidbCluster, simulatedPods func() []apiv1.Pod) map[string][]predicates.Predicate
 
 	kubeCli  kubernetes.Interface
+	cli      versioned.Interface
 	recorder record.EventRecorder
 }
 
@@ -77,10 +90,29 @@ func NewScheduler(kubeCli kubernetes.Interface, cli versioned.Interface) Schedul
 			predicates.NewStableScheduling(kubeCli, cli),
 		}
 	}
+	prioritiesByComponent := map[string][]priorities.Priority{}
+	if features.DefaultFeatureGate.Enabled(features.CapacityScheduling) {
+		prioritiesByComponent[label.TiKVLabelVal] = []priorities.Priority{
+			priorities.NewCapacity(kubeCli, cli),
+		}
+	}
 	return &scheduler{
 		predicates: predicatesByComponent,
-		kubeCli:    kubeCli,
-		recorder:   recorder,
+		priorities: prioritiesByComponent,
+		simulationPredicatesFn: func(tc *v1alpha1.TidbCluster, simulatedPods func() []apiv1.Pod) map[string][]predicates.Predicate {
+			// StableScheduling is not simulated as the new pods were never scheduled before
+			return map[string][]predicates.Predicate{
+				label.PDLabelVal: {
+					predicates.NewDryRunHA(kubeCli, tc, simulatedPods),
+				},
+				label.TiKVLabelVal: {
+					predicates.NewDryRunHA(kubeCli, tc, simulatedPods),
+				},
+			}
+		},
+		kubeCli:  kubeCli,
+		cli:      cli,
+		recorder: recorder,
 	}
 }
 
@@ -124,16 +156,26 @@ func (s *scheduler) Filter(args *schedulerapiv1.ExtenderArgs) (*schedulerapiv1.E
 	}
 
 	klog.Infof("scheduling pod: %s/%s", ns, podName)
-	var err error
+	total := len(kubeNodes)
+	decisions := make([]*filterDecision, 0, len(predicatesByComponent))
 	for _, predicate := range predicatesByComponent {
 		klog.Infof("entering predicate: %s, nodes: %v", predicate.Name(), predicates.GetNodeNames(kubeNodes))
+		start := time.Now()
+		candidates := kubeNodes
+		var err error
 		kubeNodes, err = predicate.Filter(instanceName, pod, kubeNodes)
 		klog.Infof("leaving predicate: %s, nodes: %v", predicate.Name(), predicates.GetNodeNames(kubeNodes))
+		decision := newFilterDecision("filter", component, predicate.Name(), candidates, kubeNodes, err, time.Since(start))
+		decision.record(pod)
+		decisions = append(decisions, decision)
 		if err != nil {
-			s.recorder.Event(pod, apiv1.EventTypeWarning, predicate.Name(), err.Error())
 			if len(kubeNodes) == 0 {
+				// the pod stays Pending, attach the summarized explanation of all the predicates to it
+				s.recorder.Event(pod, apiv1.EventTypeWarning, predicate.Name(), explain(total, decisions))
 				break
 			}
+			// a PreferenceError comes with the candidate nodes, the warning event is recorded and the pod is scheduled anyway
+			s.recorder.Event(pod, apiv1.EventTypeWarning, predicate.Name(), err.Error())
 		}
 	}
 
@@ -217,12 +259,15 @@ func (s *scheduler) Preempt(args *schedulerapi.ExtenderPreemptionArgs) (*schedul
 		}
 		kubeNodes = append(kubeNodes, *node)
 	}
-	var err error
 	for _, predicate := range predicatesByComponent {
 		klog.Infof("entering preempt/predicate: %s, nodes: %v", predicate.Name(), predicates.GetNodeNames(kubeNodes))
+		start := time.Now()
+		candidates := kubeNodes
+		var err error
 		kubeNodes, err = predicate.Filter(instanceName, pod, kubeNodes)
 		klog.Infof("leaving preempt/predicate: %s, nodes: %v", predicate.Name(), predicates.GetNodeNames(kubeNodes))
-		if err != nil {
+		newFilterDecision("preempt", component, predicate.Name(), candidates, kubeNodes, err, time.Since(start)).record(pod)
+		if err != nil && !predicates.IsPreferenceError(err) {
 			return nil, err
 		}
 	}
@@ -250,19 +295,42 @@ func (ferr FailureError) Error() string {
 	return fmt.Sprintf("pod %s had an intentional failure injected", ferr.PodName)
 }
 
-// We don't pass `prioritizeVerb` to kubernetes scheduler extender's config file, this method will not be called.
+// Priority scores the nodes by the priorities of the component, the scores of all priorities are summed up.
+// `prioritizeVerb` is passed to kubernetes scheduler extender's config file only if the CapacityScheduling
+// feature is enabled, a failed priority is ignored so that it never blocks the scheduling.
 func (s *scheduler) Priority(args *schedulerapiv1.ExtenderArgs) (schedulerapiv1.HostPriorityList, error) {
 	result := schedulerapiv1.HostPriorityList{}
+	if args.Nodes == nil {
+		return result, nil
+	}
 
-	if args.Nodes != nil {
-		for _, node := range args.Nodes.Items {
-			result = append(result, schedulerapiv1.HostPriority{
-				Host:  node.Name,
-				Score: 0,
-			})
+	scores := map[string]int{}
+	if pod := args.Pod; pod != nil {
+		instanceName := pod.Labels[label.InstanceLabelKey]
+		component := pod.Labels[label.ComponentLabelKey]
+		for _, priority := range s.priorities[component] {
+			nodeScores, err := priority.Score(instanceName, pod, args.Nodes.Items)
+			if err != nil {
+				klog.Warningf("priority %s failed for pod %s/%s, ignored: %v", priority.Name(), pod.GetNamespace(), pod.GetName(), err)
+				metrics.SchedulerPriorityEvaluations.WithLabelValues(component, priority.Name(), metrics.SchedulerResultFailed).Inc()
+				continue
+			}
+			klog.Infof("scheduler decision: verb=prioritize pod=%s/%s component=%s priority=%s scores=%v",
+				pod.GetNamespace(), pod.GetName(), component, priority.Name(), nodeScores)
+			metrics.SchedulerPriorityEvaluations.WithLabelValues(component, priority.Name(), metrics.SchedulerResultPassed).Inc()
+			for nodeName, score := range nodeScores {
+				scores[nodeName] += score
+			}
 		}
 	}
 
+	for _, node := range args.Nodes.Items {
+		result = append(result, schedulerapiv1.HostPriority{
+			Host:  node.Name,
+			Score: scores[node.Name],
+		})
+	}
+
 	return result, nil
 }
 
@@ -22,6 +22,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/pingcap/tidb-operator/pkg/label"
 	"github.com/pingcap/tidb-operator/pkg/scheduler/predicates"
+	"github.com/pingcap/tidb-operator/pkg/scheduler/priorities"
 	apiv1 "k8s.io/api/core/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -144,6 +145,42 @@ func TestSchedulerFilter(t *testing.T) {
 				g.Expect(result.Nodes.Items).To(BeNil())
 			},
 		},
+		{
+			name: "predicate returns preference error",
+			args: &schedulerapiv1.ExtenderArgs{
+				Pod: &apiv1.Pod{
+					TypeMeta: metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pod-1",
+						Namespace: corev1.NamespaceDefault,
+						Labels: map[string]string{
+							label.InstanceLabelKey:  "tc-1",
+							label.ComponentLabelKey: "pd",
+						},
+					},
+				},
+				Nodes: &apiv1.NodeList{
+					TypeMeta: metav1.TypeMeta{Kind: "NodeList", APIVersion: "v1"},
+					ListMeta: metav1.ListMeta{ResourceVersion: "9999"},
+					Items: []apiv1.Node{
+						{
+							TypeMeta: metav1.TypeMeta{Kind: "Node", APIVersion: "v1"},
+							ObjectMeta: metav1.ObjectMeta{
+								Name: "node-1",
+							},
+						},
+					},
+				},
+			},
+			predicate: &predicates.FakePredicate{Err: &predicates.PreferenceError{Message: "preference error"}},
+			expectFn: func(g *GomegaWithT, result *schedulerapiv1.ExtenderFilterResult, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				events := predicates.CollectEvents(recorder.Events)
+				g.Expect(events).To(HaveLen(1))
+				g.Expect(events[0]).To(ContainSubstring("preference error"))
+				g.Expect(result.Nodes.Items[0].Name).To(Equal("node-1"))
+			},
+		},
 		{
 			name: "predicate success",
 			args: &schedulerapiv1.ExtenderArgs{
@@ -272,6 +309,51 @@ func TestSchedulerPriority(t *testing.T) {
 	}
 }
 
+func TestSchedulerPriorityWithPriorities(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	pod := &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod-1",
+			Namespace: corev1.NamespaceDefault,
+			Labels: map[string]string{
+				label.InstanceLabelKey:  "tc-1",
+				label.ComponentLabelKey: "tikv",
+			},
+		},
+	}
+	nodes := &apiv1.NodeList{
+		Items: []apiv1.Node{
+			{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+		},
+	}
+	s := scheduler{
+		priorities: map[string][]priorities.Priority{
+			label.TiKVLabelVal: {
+				&priorities.FakePriority{Scores: map[string]int{"node-1": 2, "node-2": 10}},
+				&priorities.FakePriority{Scores: map[string]int{"node-1": 3}},
+				&priorities.FakePriority{Err: fmt.Errorf("priority error")},
+			},
+		},
+	}
+
+	result, err := s.Priority(&schedulerapiv1.ExtenderArgs{Pod: pod, Nodes: nodes})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(schedulerapiv1.HostPriorityList{
+		{Host: "node-1", Score: 5},
+		{Host: "node-2", Score: 10},
+	}))
+
+	pod.Labels[label.ComponentLabelKey] = "pd"
+	result, err = s.Priority(&schedulerapiv1.ExtenderArgs{Pod: pod, Nodes: nodes})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(schedulerapiv1.HostPriorityList{
+		{Host: "node-1", Score: 0},
+		{Host: "node-2", Score: 0},
+	}))
+}
+
 func TestSchedulerPreempt(t *testing.T) {
 	victims := &schedulerapi.Victims{
 		Pods: []*apiv1.Pod{},
@@ -404,6 +486,30 @@ func TestSchedulerPreempt(t *testing.T) {
 				NodeNameToMetaVictims: map[string]*schedulerapi.MetaVictims{},
 			},
 		},
+		{
+			name:  "preference of predicate is not satisfied",
+			nodes: []*apiv1.Node{nodeA, nodeB},
+			predicates: map[string][]predicates.Predicate{
+				label.PDLabelVal: {
+					&predicates.FakePredicate{
+						Err: &predicates.PreferenceError{Message: "preference error"},
+					},
+				},
+			},
+			args: &schedulerapi.ExtenderPreemptionArgs{
+				Pod: pdPod,
+				NodeNameToVictims: map[string]*schedulerapi.Victims{
+					"node-a": victims,
+					"node-b": victims,
+				},
+			},
+			wantResult: &schedulerapi.ExtenderPreemptionResult{
+				NodeNameToMetaVictims: map[string]*schedulerapi.MetaVictims{
+					"node-a": metaVictims,
+					"node-b": metaVictims,
+				},
+			},
+		},
 		{
 			name:  "all nominated nodes are feasible",
 			nodes: []*apiv1.Node{nodeA, nodeB, nodeC},
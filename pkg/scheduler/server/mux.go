@@ -62,6 +62,12 @@ func StartServer(kubeCli kubernetes.Interface, cli versioned.Interface, port int
 		Doc("prioritize nodes").
 		Operation("prioritizeNodes").
 		Writes(schedulerapiv1.HostPriorityList{}))
+
+	ws.Route(ws.POST("/simulate").To(svr.simulate).
+		Doc("simulate the placement of additional replicas").
+		Operation("simulate").
+		Reads(scheduler.SimulateArgs{}).
+		Writes(scheduler.SimulateResult{}))
 	restful.Add(ws)
 
 	klog.Infof("start scheduler extender server, listening on 0.0.0.0:%d", port)
@@ -131,6 +137,28 @@ func (svr *server) prioritizeNode(req *restful.Request, resp *restful.Response)
 	}
 }
 
+func (svr *server) simulate(req *restful.Request, resp *restful.Response) {
+	svr.lock.Lock()
+	defer svr.lock.Unlock()
+
+	args := &scheduler.SimulateArgs{}
+	if err := req.ReadEntity(args); err != nil {
+		errorResponse(resp, errFailToRead)
+		return
+	}
+
+	simulateResult, err := svr.scheduler.Simulate(args)
+	if err != nil {
+		errorResponse(resp, restful.NewError(http.StatusInternalServerError,
+			fmt.Sprintf("unable to simulate placement: %v", err)))
+		return
+	}
+
+	if err := resp.WriteEntity(simulateResult); err != nil {
+		errorResponse(resp, errFailToWrite)
+	}
+}
+
 func errorResponse(resp *restful.Response, svcErr restful.ServiceError) {
 	klog.Error(svcErr.Message)
 	if writeErr := resp.WriteServiceError(svcErr.Code, svcErr); writeErr != nil {
@@ -0,0 +1,196 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/scheduler/predicates"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+)
+
+// SimulateArgs is the request of the placement simulation
+type SimulateArgs struct {
+	Namespace   string `json:"namespace"`
+	TidbCluster string `json:"tidbCluster"`
+	// Component is one of pd, tikv and tidb
+	Component string `json:"component"`
+	// Replicas is the number of additional replicas to place
+	Replicas int32 `json:"replicas"`
+}
+
+// SimulatedPlacement is the simulated placement of a new pod
+type SimulatedPlacement struct {
+	Pod string `json:"pod"`
+	// Node is the node the pod would be placed on, empty if the pod would stay Pending
+	Node string `json:"node,omitempty"`
+	// FeasibleNodes are the nodes passed the predicates of tidb-scheduler
+	FeasibleNodes []string `json:"feasibleNodes,omitempty"`
+	// Message explains why the pod would stay Pending or why the HA placement is not satisfied
+	Message string `json:"message,omitempty"`
+}
+
+// SimulateResult is the response of the placement simulation
+type SimulateResult struct {
+	Placements []SimulatedPlacement `json:"placements"`
+	// Schedulable is true if all the additional replicas can be placed
+	Schedulable bool `json:"schedulable"`
+}
+
+// Simulate simulates where tidb-scheduler would place the additional replicas of the component
+// given the current state of the cluster, the pods are placed one by one and each placed pod is
+// counted for the following ones. The predicates of kube-scheduler (e.g. resources, taints) are not
+// simulated, only the schedulable and ready nodes are considered, and the node with the highest
+// score (then the smallest name) among the feasible nodes is chosen.
+func (s *scheduler) Simulate(args *SimulateArgs) (*SimulateResult, error) {
+	if args.Replicas <= 0 {
+		return nil, fmt.Errorf("replicas must be positive, got %d", args.Replicas)
+	}
+	tc, err := s.cli.PingcapV1alpha1().TidbClusters(args.Namespace).Get(args.TidbCluster, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	scaled := tc.DeepCopy()
+	var oldOrdinals, newOrdinals sets.Int32
+	switch args.Component {
+	case label.PDLabelVal:
+		if tc.Spec.PD == nil {
+			return nil, fmt.Errorf("tidbcluster %s/%s has no pd", args.Namespace, args.TidbCluster)
+		}
+		oldOrdinals = tc.PDStsDesiredOrdinals(false)
+		scaled.Spec.PD.Replicas += args.Replicas
+		newOrdinals = scaled.PDStsDesiredOrdinals(false)
+	case label.TiKVLabelVal:
+		if tc.Spec.TiKV == nil {
+			return nil, fmt.Errorf("tidbcluster %s/%s has no tikv", args.Namespace, args.TidbCluster)
+		}
+		oldOrdinals = tc.TiKVStsDesiredOrdinals(false)
+		scaled.Spec.TiKV.Replicas += args.Replicas
+		newOrdinals = scaled.TiKVStsDesiredOrdinals(false)
+	case label.TiDBLabelVal:
+		if tc.Spec.TiDB == nil {
+			return nil, fmt.Errorf("tidbcluster %s/%s has no tidb", args.Namespace, args.TidbCluster)
+		}
+		oldOrdinals = tc.TiDBStsDesiredOrdinals(false)
+		scaled.Spec.TiDB.Replicas += args.Replicas
+		newOrdinals = scaled.TiDBStsDesiredOrdinals(false)
+	default:
+		return nil, fmt.Errorf("component %q is not supported, must be one of pd, tikv and tidb", args.Component)
+	}
+
+	nodeList, err := s.kubeCli.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]apiv1.Node, 0, len(nodeList.Items))
+	for _, node := range nodeList.Items {
+		if isNodeSchedulable(&node) {
+			nodes = append(nodes, node)
+		}
+	}
+
+	simulatedPods := make([]apiv1.Pod, 0)
+	simulatedPodsFn := func() []apiv1.Pod {
+		return simulatedPods
+	}
+	var predicatesByComponent []predicates.Predicate
+	if s.simulationPredicatesFn != nil {
+		predicatesByComponent = s.simulationPredicatesFn(scaled, simulatedPodsFn)[args.Component]
+	}
+
+	result := &SimulateResult{Schedulable: true}
+	for _, ordinal := range newOrdinals.Difference(oldOrdinals).List() {
+		pod := newSimulatedPod(scaled, args.Component, ordinal)
+		placement := SimulatedPlacement{Pod: pod.Name}
+		feasible := nodes
+		decisions := make([]*filterDecision, 0, len(predicatesByComponent))
+		messages := make([]string, 0)
+		for _, predicate := range predicatesByComponent {
+			candidates := feasible
+			feasible, err = predicate.Filter(tc.GetInstanceName(), pod, feasible)
+			decisions = append(decisions, newFilterDecision("simulate", args.Component, predicate.Name(), candidates, feasible, err, 0))
+			if err != nil {
+				messages = append(messages, fmt.Sprintf("%s: %v", predicate.Name(), err))
+			}
+			if len(feasible) == 0 {
+				break
+			}
+		}
+		placement.FeasibleNodes = predicates.GetNodeNames(feasible)
+		if len(feasible) == 0 {
+			placement.Message = explain(len(nodes), decisions)
+			result.Schedulable = false
+			result.Placements = append(result.Placements, placement)
+			continue
+		}
+		if len(messages) > 0 {
+			placement.Message = messages[0]
+		}
+		placement.Node = s.pickNode(pod, feasible)
+		klog.Infof("simulate: pod %s/%s would be placed on node %s, feasible nodes: %v", args.Namespace, pod.Name, placement.Node, placement.FeasibleNodes)
+		pod.Spec.NodeName = placement.Node
+		simulatedPods = append(simulatedPods, *pod)
+		result.Placements = append(result.Placements, placement)
+	}
+	return result, nil
+}
+
+// pickNode returns the feasible node with the highest score, then the smallest name
+func (s *scheduler) pickNode(pod *apiv1.Pod, nodes []apiv1.Node) string {
+	scores := map[string]int{}
+	for _, priority := range s.priorities[pod.Labels[label.ComponentLabelKey]] {
+		nodeScores, err := priority.Score(pod.Labels[label.InstanceLabelKey], pod, nodes)
+		if err != nil {
+			klog.Warningf("simulate: priority %s failed for pod %s/%s, ignored: %v", priority.Name(), pod.GetNamespace(), pod.GetName(), err)
+			continue
+		}
+		for nodeName, score := range nodeScores {
+			scores[nodeName] += score
+		}
+	}
+	names := predicates.GetNodeNames(nodes)
+	sort.SliceStable(names, func(i, j int) bool {
+		return scores[names[i]] > scores[names[j]]
+	})
+	return names[0]
+}
+
+func newSimulatedPod(tc *v1alpha1.TidbCluster, component string, ordinal int32) *apiv1.Pod {
+	return &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:         fmt.Sprintf("%s-%s-%d", tc.GetName(), component, ordinal),
+			GenerateName: fmt.Sprintf("%s-%s-", tc.GetName(), component),
+			Namespace:    tc.GetNamespace(),
+			Labels:       label.New().Instance(tc.GetInstanceName()).Component(component).Labels(),
+		},
+	}
+}
+
+func isNodeSchedulable(node *apiv1.Node) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == apiv1.NodeReady {
+			return cond.Status == apiv1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	versionedfake "github.com/pingcap/tidb-operator/pkg/client/clientset/versioned/fake"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/scheduler/predicates"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestSchedulerSimulate(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type testcase struct {
+		name     string
+		args     *SimulateArgs
+		nodes    []string
+		expectFn func(*SimulateResult, error)
+	}
+
+	testFn := func(test *testcase) {
+		t.Log(test.name)
+
+		kubeCli := fake.NewSimpleClientset()
+		cli := versionedfake.NewSimpleClientset()
+		tc := &v1alpha1.TidbCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: apiv1.NamespaceDefault},
+			Spec: v1alpha1.TidbClusterSpec{
+				PD:   &v1alpha1.PDSpec{Replicas: 3},
+				TiKV: &v1alpha1.TiKVSpec{Replicas: 3},
+			},
+		}
+		cli.PingcapV1alpha1().TidbClusters(tc.Namespace).Create(tc)
+		for _, name := range test.nodes {
+			kubeCli.CoreV1().Nodes().Create(&apiv1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   name,
+					Labels: map[string]string{"kubernetes.io/hostname": name},
+				},
+				Status: apiv1.NodeStatus{
+					Conditions: []apiv1.NodeCondition{{Type: apiv1.NodeReady, Status: apiv1.ConditionTrue}},
+				},
+			})
+		}
+		kubeCli.CoreV1().Nodes().Create(&apiv1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "node-cordoned",
+				Labels: map[string]string{"kubernetes.io/hostname": "node-cordoned"},
+			},
+			Spec: apiv1.NodeSpec{Unschedulable: true},
+			Status: apiv1.NodeStatus{
+				Conditions: []apiv1.NodeCondition{{Type: apiv1.NodeReady, Status: apiv1.ConditionTrue}},
+			},
+		})
+		for i := 0; i < 3; i++ {
+			kubeCli.CoreV1().Pods(tc.Namespace).Create(&apiv1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:         fmt.Sprintf("demo-pd-%d", i),
+					GenerateName: "demo-pd-",
+					Namespace:    tc.Namespace,
+					Labels:       label.New().Instance("demo").PD().Labels(),
+				},
+				Spec: apiv1.PodSpec{NodeName: fmt.Sprintf("node-%d", i+1)},
+			})
+		}
+
+		s := &scheduler{
+			simulationPredicatesFn: func(tc *v1alpha1.TidbCluster, simulatedPods func() []apiv1.Pod) map[string][]predicates.Predicate {
+				return map[string][]predicates.Predicate{
+					label.PDLabelVal: {
+						predicates.NewDryRunHA(kubeCli, tc, simulatedPods),
+					},
+				}
+			},
+			kubeCli:  kubeCli,
+			cli:      cli,
+			recorder: record.NewFakeRecorder(10),
+		}
+		result, err := s.Simulate(test.args)
+		test.expectFn(result, err)
+	}
+
+	tests := []testcase{
+		{
+			name:  "scale out pd by 2",
+			args:  &SimulateArgs{Namespace: apiv1.NamespaceDefault, TidbCluster: "demo", Component: label.PDLabelVal, Replicas: 2},
+			nodes: []string{"node-1", "node-2", "node-3", "node-4"},
+			expectFn: func(result *SimulateResult, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(result.Schedulable).To(BeTrue())
+				g.Expect(result.Placements).To(HaveLen(2))
+				g.Expect(result.Placements[0].Pod).To(Equal("demo-pd-3"))
+				g.Expect(result.Placements[0].Node).To(Equal("node-4"))
+				g.Expect(result.Placements[1].Pod).To(Equal("demo-pd-4"))
+				g.Expect(result.Placements[1].FeasibleNodes).To(Equal([]string{"node-1", "node-2", "node-3", "node-4"}))
+				g.Expect(result.Placements[1].Node).To(Equal("node-1"))
+			},
+		},
+		{
+			name:  "scale out pd by 1 without room",
+			args:  &SimulateArgs{Namespace: apiv1.NamespaceDefault, TidbCluster: "demo", Component: label.PDLabelVal, Replicas: 1},
+			nodes: []string{"node-1", "node-2", "node-3"},
+			expectFn: func(result *SimulateResult, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(result.Schedulable).To(BeFalse())
+				g.Expect(result.Placements).To(HaveLen(1))
+				g.Expect(result.Placements[0].Node).To(BeEmpty())
+				g.Expect(result.Placements[0].Message).To(ContainSubstring("0/3 nodes are available: HAScheduling rejected 3 nodes"))
+			},
+		},
+		{
+			name:  "component without predicates",
+			args:  &SimulateArgs{Namespace: apiv1.NamespaceDefault, TidbCluster: "demo", Component: label.TiKVLabelVal, Replicas: 1},
+			nodes: []string{"node-1"},
+			expectFn: func(result *SimulateResult, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(result.Schedulable).To(BeTrue())
+				g.Expect(result.Placements[0].Pod).To(Equal("demo-tikv-3"))
+				g.Expect(result.Placements[0].Node).To(Equal("node-1"))
+			},
+		},
+		{
+			name: "unsupported component",
+			args: &SimulateArgs{Namespace: apiv1.NamespaceDefault, TidbCluster: "demo", Component: "tiflash", Replicas: 1},
+			expectFn: func(result *SimulateResult, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("is not supported"))
+			},
+		},
+		{
+			name: "tidbcluster not found",
+			args: &SimulateArgs{Namespace: apiv1.NamespaceDefault, TidbCluster: "other", Component: label.PDLabelVal, Replicas: 1},
+			expectFn: func(result *SimulateResult, err error) {
+				g.Expect(err).To(HaveOccurred())
+			},
+		},
+	}
+
+	for i := range tests {
+		testFn(&tests[i])
+	}
+}
@@ -21,6 +21,7 @@ type ActionType string
 
 const (
 	GetLeaderCountActionType ActionType = "GetLeaderCount"
+	GetStoreStatusActionType ActionType = "GetStoreStatus"
 )
 
 type NotFoundReaction struct {
@@ -72,3 +73,12 @@ func (c *FakeTiKVClient) GetLeaderCount() (int, error) {
 	}
 	return result.(int), nil
 }
+
+func (c *FakeTiKVClient) GetStoreStatus() (*StoreStatus, error) {
+	action := &Action{}
+	result, err := c.fakeAPI(GetStoreStatusActionType, action)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*StoreStatus), nil
+}
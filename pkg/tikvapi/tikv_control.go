@@ -34,12 +34,13 @@ type TiKVControlInterface interface {
 type defaultTiKVControl struct {
 	mutex       sync.Mutex
 	kubeCli     kubernetes.Interface
+	tlsConfigs  *pdapi.TLSConfigCache
 	tikvClients map[string]TiKVClient
 }
 
-// NewDefaultTiKVControl returns a defaultTiKVControl instance
-func NewDefaultTiKVControl(kubeCli kubernetes.Interface) TiKVControlInterface {
-	return &defaultTiKVControl{kubeCli: kubeCli, tikvClients: map[string]TiKVClient{}}
+// NewDefaultTiKVControl returns a defaultTiKVControl instance, which loads the client tls.Configs from tlsConfigs
+func NewDefaultTiKVControl(kubeCli kubernetes.Interface, tlsConfigs *pdapi.TLSConfigCache) TiKVControlInterface {
+	return &defaultTiKVControl{kubeCli: kubeCli, tlsConfigs: tlsConfigs, tikvClients: map[string]TiKVClient{}}
 }
 
 func (tc *defaultTiKVControl) GetTiKVPodClient(namespace string, tcName string, podName string, tlsEnabled bool) TiKVClient {
@@ -52,7 +53,7 @@ func (tc *defaultTiKVControl) GetTiKVPodClient(namespace string, tcName string,
 
 	if tlsEnabled {
 		scheme = "https"
-		tlsConfig, err = pdapi.GetTLSConfig(tc.kubeCli, pdapi.Namespace(namespace), tcName, util.ClusterClientTLSSecretName(tcName))
+		tlsConfig, err = tc.tlsConfigs.GetTLSConfig(pdapi.Namespace(namespace), util.ClusterClientTLSSecretName(tcName))
 		if err != nil {
 			klog.Errorf("Unable to get tls config for TiKV cluster %q, tikv client may not work: %v", tcName, err)
 			return NewTiKVClient(TiKVPodClientURL(namespace, tcName, podName, scheme), DefaultTimeout, tlsConfig, true)
@@ -81,7 +82,7 @@ type FakeTiKVControl struct {
 
 func NewFakeTiKVControl(kubeCli kubernetes.Interface) *FakeTiKVControl {
 	return &FakeTiKVControl{
-		defaultTiKVControl: defaultTiKVControl{kubeCli: kubeCli, tikvClients: map[string]TiKVClient{}},
+		defaultTiKVControl: defaultTiKVControl{kubeCli: kubeCli, tlsConfigs: pdapi.NewTLSConfigCache(kubeCli), tikvClients: map[string]TiKVClient{}},
 		tikvPodClients:     map[string]TiKVClient{},
 	}
 }
@@ -91,5 +92,9 @@ func (ftc *FakeTiKVControl) SetTiKVPodClient(namespace, tcName, podName string,
 }
 
 func (ftc *FakeTiKVControl) GetTiKVPodClient(namespace, tcName, podName string, tlsEnabled bool) TiKVClient {
-	return ftc.tikvPodClients[tikvPodClientKey("http", namespace, tcName, podName)]
+	if client, ok := ftc.tikvPodClients[tikvPodClientKey("http", namespace, tcName, podName)]; ok {
+		return client
+	}
+	// the client without any reaction, whose calls return NotFoundReaction
+	return NewFakeTiKVClient()
 }
@@ -0,0 +1,170 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikvapi
+
+import (
+	"fmt"
+	"strconv"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/prom2json"
+)
+
+const (
+	metricNameStoreSize        = "tikv_store_size_bytes"
+	metricNameSnapshotTraffic  = "tikv_raftstore_snapshot_traffic_total"
+	metricNameApplyWaitSeconds = "tikv_raftstore_apply_wait_time_duration_secs"
+)
+
+// StoreStatus is the store-level signals read from the metrics of a TiKV server directly,
+// which are real-time compared with the aggregate views of PD that are reported by heartbeats
+type StoreStatus struct {
+	LeaderCount int
+	RegionCount int
+	// CapacityBytes, AvailableBytes and UsedBytes are the disk usage of the store
+	CapacityBytes  int64
+	AvailableBytes int64
+	UsedBytes      int64
+	// SnapshotsSending, SnapshotsReceiving and SnapshotsApplying are the number of the snapshots in progress
+	SnapshotsSending   int
+	SnapshotsReceiving int
+	SnapshotsApplying  int
+	// ApplyWaitSeconds is the average time that the committed raft logs wait to be applied since TiKV started
+	ApplyWaitSeconds float64
+}
+
+// SnapshotBacklog returns the number of the snapshots that are received or applied by the store
+func (s *StoreStatus) SnapshotBacklog() int {
+	return s.SnapshotsReceiving + s.SnapshotsApplying
+}
+
+// fetchMetricFamilies fetches all the metric families from the metrics API of TiKV
+func (c *tikvClient) fetchMetricFamilies() ([]*prom2json.Family, error) {
+	apiURL := fmt.Sprintf("%s/%s", c.url, metricsPrefix)
+	mfChan := make(chan *dto.MetricFamily, 1024)
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- prom2json.FetchMetricFamilies(apiURL, mfChan, c.httpClient.Transport)
+	}()
+
+	var families []*prom2json.Family
+	// mfChan is closed by FetchMetricFamilies whether it succeeds or not
+	for mf := range mfChan {
+		families = append(families, prom2json.NewFamily(mf))
+	}
+	if err := <-errChan; err != nil {
+		return nil, fmt.Errorf("failed to fetch metrics from %s, error: %v", apiURL, err)
+	}
+	return families, nil
+}
+
+// GetStoreStatus gets the store-level signals from the metrics of TiKV
+func (c *tikvClient) GetStoreStatus() (*StoreStatus, error) {
+	families, err := c.fetchMetricFamilies()
+	if err != nil {
+		return nil, err
+	}
+	return parseStoreStatus(families)
+}
+
+func parseStoreStatus(families []*prom2json.Family) (*StoreStatus, error) {
+	status := &StoreStatus{}
+	found := false
+	for _, family := range families {
+		switch family.Name {
+		case metricNameRegionCount:
+			found = true
+			err := forEachMetric(family, func(labelValue string, value float64) {
+				switch labelValue {
+				case labelNameLeaderCount:
+					status.LeaderCount = int(value)
+				case "region":
+					status.RegionCount = int(value)
+				}
+			})
+			if err != nil {
+				return nil, err
+			}
+		case metricNameStoreSize:
+			err := forEachMetric(family, func(labelValue string, value float64) {
+				switch labelValue {
+				case "capacity":
+					status.CapacityBytes = int64(value)
+				case "available":
+					status.AvailableBytes = int64(value)
+				case "used":
+					status.UsedBytes = int64(value)
+				}
+			})
+			if err != nil {
+				return nil, err
+			}
+		case metricNameSnapshotTraffic:
+			err := forEachMetric(family, func(labelValue string, value float64) {
+				switch labelValue {
+				case "sending":
+					status.SnapshotsSending = int(value)
+				case "receiving":
+					status.SnapshotsReceiving = int(value)
+				case "applying":
+					status.SnapshotsApplying = int(value)
+				}
+			})
+			if err != nil {
+				return nil, err
+			}
+		case metricNameApplyWaitSeconds:
+			var sum, count float64
+			for _, m := range family.Metrics {
+				h, ok := m.(prom2json.Histogram)
+				if !ok {
+					continue
+				}
+				s, err := strconv.ParseFloat(h.Sum, 64)
+				if err != nil {
+					return nil, err
+				}
+				c, err := strconv.ParseFloat(h.Count, 64)
+				if err != nil {
+					return nil, err
+				}
+				sum += s
+				count += c
+			}
+			if count > 0 {
+				status.ApplyWaitSeconds = sum / count
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("metric %s not found", metricNameRegionCount)
+	}
+	return status, nil
+}
+
+// forEachMetric calls fn with the value of the label "type" and the value of each gauge or counter in the family
+func forEachMetric(family *prom2json.Family, fn func(labelValue string, value float64)) error {
+	for _, m := range family.Metrics {
+		metric, ok := m.(prom2json.Metric)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(metric.Value, 64)
+		if err != nil {
+			return err
+		}
+		fn(metric.Labels["type"], value)
+	}
+	return nil
+}
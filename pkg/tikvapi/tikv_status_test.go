@@ -0,0 +1,73 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikvapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+const storeStatusMetrics = `# TYPE tikv_raftstore_region_count gauge
+tikv_raftstore_region_count{type="leader"} 10
+tikv_raftstore_region_count{type="region"} 30
+# TYPE tikv_store_size_bytes gauge
+tikv_store_size_bytes{type="available"} 600
+tikv_store_size_bytes{type="capacity"} 1000
+tikv_store_size_bytes{type="used"} 300
+# TYPE tikv_raftstore_snapshot_traffic_total gauge
+tikv_raftstore_snapshot_traffic_total{type="applying"} 2
+tikv_raftstore_snapshot_traffic_total{type="receiving"} 3
+tikv_raftstore_snapshot_traffic_total{type="sending"} 1
+# TYPE tikv_raftstore_apply_wait_time_duration_secs histogram
+tikv_raftstore_apply_wait_time_duration_secs_bucket{le="0.001"} 2
+tikv_raftstore_apply_wait_time_duration_secs_bucket{le="+Inf"} 4
+tikv_raftstore_apply_wait_time_duration_secs_sum 0.2
+tikv_raftstore_apply_wait_time_duration_secs_count 4
+`
+
+func TestGetStoreStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	metrics := storeStatusMetrics
+	svc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.Expect(r.URL.Path).To(Equal("/metrics"))
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.Write([]byte(metrics))
+	}))
+	defer svc.Close()
+
+	client := NewTiKVClient(svc.URL, DefaultTimeout, nil, true)
+	status, err := client.GetStoreStatus()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(status).To(Equal(&StoreStatus{
+		LeaderCount:        10,
+		RegionCount:        30,
+		CapacityBytes:      1000,
+		AvailableBytes:     600,
+		UsedBytes:          300,
+		SnapshotsSending:   1,
+		SnapshotsReceiving: 3,
+		SnapshotsApplying:  2,
+		ApplyWaitSeconds:   0.05,
+	}))
+	g.Expect(status.SnapshotBacklog()).To(Equal(5))
+
+	// not a TiKV server
+	metrics = "# TYPE up gauge\nup 1\n"
+	_, err = client.GetStoreStatus()
+	g.Expect(err).To(HaveOccurred())
+}
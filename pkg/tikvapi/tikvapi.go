@@ -36,6 +36,8 @@ const (
 // TiKVClient provides tikv server's api
 type TiKVClient interface {
 	GetLeaderCount() (int, error)
+	// GetStoreStatus gets the store-level signals, e.g. disk usage and snapshot backlog
+	GetStoreStatus() (*StoreStatus, error)
 }
 
 // tikvClient is default implementation of TiKVClient
@@ -0,0 +1,441 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/backup/backupschedule"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/config"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/readable"
+	tkctlUtil "github.com/pingcap/tidb-operator/pkg/tkctl/util"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	kubeprinters "k8s.io/kubernetes/pkg/printers"
+)
+
+const (
+	backupLongDesc = `
+		Create, list and delete the backups of a tidb cluster.
+
+		You may omit --tidbcluster option by running 'tkc use <clusterName>'.
+`
+	backupCreateLongDesc = `
+		Create an ad-hoc backup of a tidb cluster.
+
+		The backup is templated from the backupTemplate of a BackupSchedule, which is either
+		specified by --from-schedule or the only BackupSchedule of the tidb cluster in the
+		namespace. The backups taken by BR are saved under a prefix unique to the cluster and
+		the time of the backup. The ad-hoc backup is not owned by the BackupSchedule, so it is
+		not garbage collected with the scheduled backups.
+`
+	backupCreateExample = `
+		# take a backup of the current tidb cluster (set by tkctl use) and wait for it
+		tkctl backup create --wait
+
+		# take a backup named before-upgrade of demo-cluster from the daily BackupSchedule
+		tkctl backup create before-upgrade -t demo-cluster --from-schedule daily
+`
+	backupListExample = `
+		# list the backups of the current tidb cluster (set by tkctl use)
+		tkctl backup list
+
+		# list the backups with their paths
+		tkctl backup list -o wide
+`
+	backupDeleteExample = `
+		# delete a backup, the backup data is cleaned according to its cleanPolicy
+		tkctl backup delete before-upgrade
+`
+	clusterUsage = `expected '-t CLUSTER_NAME' for the command or
+using 'tkctl use' to set tidb cluster first.`
+
+	pollInterval = 2 * time.Second
+)
+
+// clusterOptions contains the tidb cluster and the clients shared by the backup and restore commands.
+type clusterOptions struct {
+	TidbClusterName string
+	Namespace       string
+
+	TcCli *versioned.Clientset
+
+	genericclioptions.IOStreams
+}
+
+func (o *clusterOptions) Complete(tkcContext *config.TkcContext, cmd *cobra.Command, args []string) error {
+	clientConfig, err := tkcContext.ToTkcClientConfig()
+	if err != nil {
+		return err
+	}
+
+	if tidbClusterName, ok := clientConfig.TidbClusterName(); ok {
+		o.TidbClusterName = tidbClusterName
+	} else {
+		return cmdutil.UsageErrorf(cmd, clusterUsage)
+	}
+
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return err
+	}
+	o.Namespace = namespace
+
+	restConfig, err := clientConfig.RestConfig()
+	if err != nil {
+		return err
+	}
+	tcCli, err := versioned.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	o.TcCli = tcCli
+
+	return nil
+}
+
+func (o *clusterOptions) getTidbCluster() (*v1alpha1.TidbCluster, error) {
+	return o.TcCli.PingcapV1alpha1().TidbClusters(o.Namespace).Get(o.TidbClusterName, metav1.GetOptions{})
+}
+
+// NewCmdBackup creates the backup command which manages the backups of a tidb cluster
+func NewCmdBackup(tkcContext *config.TkcContext, streams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Create, list and delete backups of a tidb cluster",
+		Long:  backupLongDesc,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+	cmd.AddCommand(
+		NewCmdBackupCreate(tkcContext, streams),
+		NewCmdBackupList(tkcContext, streams),
+		NewCmdBackupDelete(tkcContext, streams),
+	)
+	return cmd
+}
+
+// BackupCreateOptions contains the input to the backup create command.
+type BackupCreateOptions struct {
+	clusterOptions
+
+	Name         string
+	FromSchedule string
+	Wait         bool
+	Timeout      time.Duration
+}
+
+// NewCmdBackupCreate creates the backup create command
+func NewCmdBackupCreate(tkcContext *config.TkcContext, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &BackupCreateOptions{clusterOptions: clusterOptions{IOStreams: streams}}
+
+	cmd := &cobra.Command{
+		Use:     "create [NAME]",
+		Short:   "Create an ad-hoc backup of a tidb cluster",
+		Long:    backupCreateLongDesc,
+		Example: backupCreateExample,
+		Args:    cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(tkcContext, cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVar(&o.FromSchedule, "from-schedule", "", "The BackupSchedule whose backupTemplate is used, defaults to the only BackupSchedule of the tidb cluster.")
+	cmd.Flags().BoolVar(&o.Wait, "wait", false, "Wait for the backup to finish and print its progress.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", 0, "The time to wait for the backup to finish, zero means no timeout.")
+	return cmd
+}
+
+func (o *BackupCreateOptions) Complete(tkcContext *config.TkcContext, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		o.Name = args[0]
+	}
+	return o.clusterOptions.Complete(tkcContext, cmd, args)
+}
+
+func (o *BackupCreateOptions) Run() error {
+	tc, err := o.getTidbCluster()
+	if err != nil {
+		return err
+	}
+
+	bs, err := o.getBackupSchedule(tc)
+	if err != nil {
+		return err
+	}
+
+	backup, err := o.TcCli.PingcapV1alpha1().Backups(o.Namespace).Create(newBackup(o.Name, tc, bs, time.Now()))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "backup %s/%s is created from backup schedule %s\n", backup.Namespace, backup.Name, bs.Name)
+
+	if !o.Wait {
+		return nil
+	}
+	err = waitFor(o.Out, o.Timeout, func() ([]condition, bool, error) {
+		backup, err = o.TcCli.PingcapV1alpha1().Backups(o.Namespace).Get(backup.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, false, err
+		}
+		conditions := make([]condition, 0, len(backup.Status.Conditions))
+		for _, c := range backup.Status.Conditions {
+			conditions = append(conditions, condition{string(c.Type), c.Status == corev1.ConditionTrue, c.LastTransitionTime, c.Reason, c.Message})
+		}
+		switch {
+		case v1alpha1.IsBackupComplete(backup):
+			return conditions, true, nil
+		case v1alpha1.IsBackupFailed(backup), v1alpha1.IsBackupInvalid(backup):
+			return conditions, true, fmt.Errorf("backup %s/%s failed", backup.Namespace, backup.Name)
+		}
+		return conditions, false, nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "backup %s/%s is complete, path: %s, size: %s, commitTs: %s\n", backup.Namespace, backup.Name,
+		backup.Status.BackupPath, backup.Status.BackupSizeReadable, backup.Status.CommitTs)
+	return nil
+}
+
+// getBackupSchedule returns the BackupSchedule specified by --from-schedule, or the only BackupSchedule
+// whose backups are taken from the tidb cluster.
+func (o *BackupCreateOptions) getBackupSchedule(tc *v1alpha1.TidbCluster) (*v1alpha1.BackupSchedule, error) {
+	if o.FromSchedule != "" {
+		return o.TcCli.PingcapV1alpha1().BackupSchedules(o.Namespace).Get(o.FromSchedule, metav1.GetOptions{})
+	}
+
+	bsList, err := o.TcCli.PingcapV1alpha1().BackupSchedules(o.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var schedules []*v1alpha1.BackupSchedule
+	for i := range bsList.Items {
+		bs := &bsList.Items[i]
+		if tkctlUtil.IsBackupOf(&v1alpha1.Backup{ObjectMeta: metav1.ObjectMeta{Namespace: bs.Namespace}, Spec: bs.Spec.BackupTemplate}, tc) {
+			schedules = append(schedules, bs)
+		}
+	}
+	switch len(schedules) {
+	case 0:
+		return nil, fmt.Errorf("no backup schedule of tidb cluster %s/%s is found, specify one by --from-schedule", tc.Namespace, tc.Name)
+	case 1:
+		return schedules[0], nil
+	}
+	return nil, fmt.Errorf("%d backup schedules of tidb cluster %s/%s are found, specify one by --from-schedule", len(schedules), tc.Namespace, tc.Name)
+}
+
+// newBackup builds an ad-hoc backup of the tidb cluster from the template of the backup schedule,
+// the template is retargeted to the tidb cluster if it is for another cluster.
+func newBackup(name string, tc *v1alpha1.TidbCluster, bs *v1alpha1.BackupSchedule, now time.Time) *v1alpha1.Backup {
+	bs = bs.DeepCopy()
+	template := &bs.Spec.BackupTemplate
+	if !tkctlUtil.IsBackupOf(&v1alpha1.Backup{ObjectMeta: metav1.ObjectMeta{Namespace: bs.Namespace}, Spec: *template}, tc) {
+		if template.BR != nil {
+			template.BR.Cluster = tc.Name
+			template.BR.ClusterNamespace = tc.Namespace
+		} else if template.From != nil {
+			template.From.Host = tkctlUtil.GetTidbServiceName(tc.Name)
+		}
+	}
+
+	if name == "" {
+		name = fmt.Sprintf("%s-adhoc-%s", tc.Name, now.UTC().Format(v1alpha1.BackupNameTimeFormat))
+	}
+	return &v1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: bs.Namespace,
+			Name:      name,
+		},
+		Spec: backupschedule.BuildBackupSpec(bs, now),
+	}
+}
+
+// BackupListOptions contains the input to the backup list command.
+type BackupListOptions struct {
+	clusterOptions
+
+	PrintFlags *readable.PrintFlags
+}
+
+// NewCmdBackupList creates the backup list command
+func NewCmdBackupList(tkcContext *config.TkcContext, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &BackupListOptions{
+		clusterOptions: clusterOptions{IOStreams: streams},
+		PrintFlags:     readable.NewPrintFlags(),
+	}
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List the backups of a tidb cluster",
+		Example: backupListExample,
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(tkcContext, cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+		SuggestFor: []string{"ls"},
+	}
+
+	o.PrintFlags.AddFlags(cmd)
+	return cmd
+}
+
+func (o *BackupListOptions) Run() error {
+	tc, err := o.getTidbCluster()
+	if err != nil {
+		return err
+	}
+
+	backupList, err := o.TcCli.PingcapV1alpha1().Backups(o.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	backups := &v1alpha1.BackupList{}
+	for _, backup := range backupList.Items {
+		if tkctlUtil.IsBackupOf(&backup, tc) {
+			backup.SetGroupVersionKind(v1alpha1.SchemeGroupVersion.WithKind("Backup"))
+			backups.Items = append(backups.Items, backup)
+		}
+	}
+	sort.SliceStable(backups.Items, func(i, j int) bool {
+		return backups.Items[j].CreationTimestamp.Before(&backups.Items[i].CreationTimestamp)
+	})
+	backups.SetGroupVersionKind(v1alpha1.SchemeGroupVersion.WithKind("BackupList"))
+
+	printer, err := o.PrintFlags.ToPrinter(false, false)
+	if err != nil {
+		return err
+	}
+	w := kubeprinters.GetNewTabWriter(o.Out)
+	if err := printer.PrintObj(backups, w); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// BackupDeleteOptions contains the input to the backup delete command.
+type BackupDeleteOptions struct {
+	clusterOptions
+
+	Names []string
+}
+
+// NewCmdBackupDelete creates the backup delete command
+func NewCmdBackupDelete(tkcContext *config.TkcContext, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &BackupDeleteOptions{clusterOptions: clusterOptions{IOStreams: streams}}
+
+	cmd := &cobra.Command{
+		Use:     "delete NAME...",
+		Short:   "Delete backups of a tidb cluster",
+		Example: backupDeleteExample,
+		Args:    cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(tkcContext, cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+		SuggestFor: []string{"rm"},
+	}
+	return cmd
+}
+
+func (o *BackupDeleteOptions) Complete(tkcContext *config.TkcContext, cmd *cobra.Command, args []string) error {
+	o.Names = args
+	return o.clusterOptions.Complete(tkcContext, cmd, args)
+}
+
+func (o *BackupDeleteOptions) Run() error {
+	tc, err := o.getTidbCluster()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range o.Names {
+		backup, err := o.TcCli.PingcapV1alpha1().Backups(o.Namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		// refuse to delete the backups of other clusters by a mistaken name
+		if !tkctlUtil.IsBackupOf(backup, tc) {
+			return fmt.Errorf("backup %s/%s is not a backup of tidb cluster %s", backup.Namespace, backup.Name, tc.Name)
+		}
+		if err := o.TcCli.PingcapV1alpha1().Backups(o.Namespace).Delete(name, &metav1.DeleteOptions{}); err != nil {
+			return err
+		}
+		fmt.Fprintf(o.Out, "backup %s/%s is deleted\n", o.Namespace, name)
+	}
+	return nil
+}
+
+// condition is a condition of a backup or restore
+type condition struct {
+	Type               string
+	Status             bool
+	LastTransitionTime metav1.Time
+	Reason             string
+	Message            string
+}
+
+// waitFor polls the conditions by get and prints the conditions which are newly true until get reports done.
+func waitFor(out io.Writer, timeout time.Duration, get func() ([]condition, bool, error)) error {
+	printed := map[string]metav1.Time{}
+	var getErr error
+	poll := func() (bool, error) {
+		conditions, done, err := get()
+		for _, c := range conditions {
+			if !c.Status {
+				continue
+			}
+			if t, ok := printed[c.Type]; ok && t.Equal(&c.LastTransitionTime) {
+				continue
+			}
+			printed[c.Type] = c.LastTransitionTime
+			line := fmt.Sprintf("%s\t%s", c.LastTransitionTime.Format(time.RFC3339), c.Type)
+			if c.Reason != "" {
+				line += "\t" + c.Reason
+			}
+			if c.Message != "" {
+				line += "\t" + c.Message
+			}
+			fmt.Fprintln(out, line)
+		}
+		if done {
+			getErr = err
+			return true, nil
+		}
+		return false, err
+	}
+
+	var err error
+	if timeout > 0 {
+		err = wait.PollImmediate(pollInterval, timeout, poll)
+	} else {
+		err = wait.PollImmediateInfinite(pollInterval, poll)
+	}
+	if err != nil {
+		return err
+	}
+	return getErr
+}
@@ -0,0 +1,110 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTidbCluster(name string) *v1alpha1.TidbCluster {
+	return &v1alpha1.TidbCluster{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"}}
+}
+
+func TestNewBackup(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	now := time.Date(2020, 12, 1, 8, 0, 0, 0, time.UTC)
+	bs := &v1alpha1.BackupSchedule{
+		ObjectMeta: metav1.ObjectMeta{Name: "daily", Namespace: "ns"},
+		Spec: v1alpha1.BackupScheduleSpec{
+			BackupTemplate: v1alpha1.BackupSpec{
+				BR: &v1alpha1.BRConfig{Cluster: "demo"},
+				StorageProvider: v1alpha1.StorageProvider{
+					S3: &v1alpha1.S3StorageProvider{Bucket: "bucket", Prefix: "backups"},
+				},
+			},
+		},
+	}
+
+	backup := newBackup("", newTidbCluster("demo"), bs, now)
+	g.Expect(backup.Name).To(Equal("demo-adhoc-2020-12-01t08-00-00"))
+	g.Expect(backup.Namespace).To(Equal("ns"))
+	g.Expect(backup.OwnerReferences).To(BeEmpty())
+	g.Expect(backup.Spec.BR.Cluster).To(Equal("demo"))
+	g.Expect(backup.Spec.S3.Prefix).To(Equal("backups/demo-pd.ns-2379-2020-12-01t08-00-00"))
+	g.Expect(bs.Spec.BackupTemplate.S3.Prefix).To(Equal("backups"))
+
+	// the template of another cluster is retargeted
+	backup = newBackup("before-upgrade", newTidbCluster("other"), bs, now)
+	g.Expect(backup.Name).To(Equal("before-upgrade"))
+	g.Expect(backup.Spec.BR.Cluster).To(Equal("other"))
+	g.Expect(backup.Spec.S3.Prefix).To(Equal("backups/other-pd.ns-2379-2020-12-01t08-00-00"))
+	g.Expect(bs.Spec.BackupTemplate.BR.Cluster).To(Equal("demo"))
+}
+
+func TestNewRestore(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	now := time.Date(2020, 12, 1, 8, 0, 0, 0, time.UTC)
+	complete := v1alpha1.BackupStatus{
+		BackupPath: "s3://bucket/backups/backup-demo.tgz",
+		Conditions: []v1alpha1.BackupCondition{{Type: v1alpha1.BackupComplete, Status: corev1.ConditionTrue}},
+	}
+	brBackup := &v1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{Name: "br", Namespace: "ns"},
+		Spec: v1alpha1.BackupSpec{
+			Type: v1alpha1.BackupTypeFull,
+			BR:   &v1alpha1.BRConfig{Cluster: "demo"},
+			StorageProvider: v1alpha1.StorageProvider{
+				S3: &v1alpha1.S3StorageProvider{Bucket: "bucket", Prefix: "backups/demo"},
+			},
+		},
+	}
+
+	_, err := newRestore("", newTidbCluster("target"), brBackup, now)
+	g.Expect(err).To(HaveOccurred())
+
+	brBackup.Status = complete
+	restore, err := newRestore("", newTidbCluster("target"), brBackup, now)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(restore.Name).To(Equal("target-restore-2020-12-01t08-00-00"))
+	g.Expect(restore.Spec.Type).To(Equal(v1alpha1.BackupTypeFull))
+	g.Expect(restore.Spec.BR.Cluster).To(Equal("target"))
+	g.Expect(restore.Spec.S3.Prefix).To(Equal("backups/demo"))
+	g.Expect(brBackup.Spec.BR.Cluster).To(Equal("demo"))
+
+	dumplingBackup := &v1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{Name: "dumpling", Namespace: "ns"},
+		Spec: v1alpha1.BackupSpec{
+			From: &v1alpha1.TiDBAccessConfig{Host: "demo-tidb", Port: 4000, User: "root", SecretName: "secret"},
+			StorageProvider: v1alpha1.StorageProvider{
+				S3: &v1alpha1.S3StorageProvider{Bucket: "bucket", Prefix: "backups"},
+			},
+		},
+		Status: complete,
+	}
+	restore, err = newRestore("restore", newTidbCluster("target"), dumplingBackup, now)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(restore.Name).To(Equal("restore"))
+	g.Expect(restore.Spec.BR).To(BeNil())
+	g.Expect(restore.Spec.S3.Path).To(Equal("s3://bucket/backups/backup-demo.tgz"))
+	g.Expect(restore.Spec.To.Host).To(Equal("target-tidb"))
+	g.Expect(restore.Spec.To.SecretName).To(Equal("secret"))
+}
@@ -0,0 +1,218 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/config"
+	tkctlUtil "github.com/pingcap/tidb-operator/pkg/tkctl/util"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+const (
+	restoreLongDesc = `
+		Restore backups to a tidb cluster.
+
+		You may omit --tidbcluster option by running 'tkc use <clusterName>'.
+`
+	restoreCreateLongDesc = `
+		Restore a completed backup to a tidb cluster.
+
+		The restore is templated from the backup, the backups taken by BR are restored by BR
+		and the backups taken by dumpling are restored by lightning. The secrets referenced by
+		the backup must exist in the namespace of the tidb cluster.
+`
+	restoreCreateExample = `
+		# restore the backup before-upgrade to the current tidb cluster (set by tkctl use) and wait for it
+		tkctl restore create --from-backup before-upgrade --wait
+
+		# restore a backup of the namespace prod to demo-cluster
+		tkctl restore create -t demo-cluster --from-backup daily-2020-12-01t00-00-00 --backup-namespace prod
+`
+)
+
+// NewCmdRestore creates the restore command which restores backups to a tidb cluster
+func NewCmdRestore(tkcContext *config.TkcContext, streams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Restore backups to a tidb cluster",
+		Long:  restoreLongDesc,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+	cmd.AddCommand(NewCmdRestoreCreate(tkcContext, streams))
+	return cmd
+}
+
+// RestoreCreateOptions contains the input to the restore create command.
+type RestoreCreateOptions struct {
+	clusterOptions
+
+	Name            string
+	FromBackup      string
+	BackupNamespace string
+	Wait            bool
+	Timeout         time.Duration
+}
+
+// NewCmdRestoreCreate creates the restore create command
+func NewCmdRestoreCreate(tkcContext *config.TkcContext, streams genericclioptions.IOStreams) *cobra.Command {
+	o := &RestoreCreateOptions{clusterOptions: clusterOptions{IOStreams: streams}}
+
+	cmd := &cobra.Command{
+		Use:     "create [NAME]",
+		Short:   "Restore a backup to a tidb cluster",
+		Long:    restoreCreateLongDesc,
+		Example: restoreCreateExample,
+		Args:    cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(tkcContext, cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVar(&o.FromBackup, "from-backup", "", "The backup to restore.")
+	cmd.Flags().StringVar(&o.BackupNamespace, "backup-namespace", "", "The namespace of the backup, defaults to the namespace of the tidb cluster.")
+	cmd.Flags().BoolVar(&o.Wait, "wait", false, "Wait for the restore to finish and print its progress.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", 0, "The time to wait for the restore to finish, zero means no timeout.")
+	cmdutil.CheckErr(cmd.MarkFlagRequired("from-backup"))
+	return cmd
+}
+
+func (o *RestoreCreateOptions) Complete(tkcContext *config.TkcContext, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		o.Name = args[0]
+	}
+	if err := o.clusterOptions.Complete(tkcContext, cmd, args); err != nil {
+		return err
+	}
+	if o.BackupNamespace == "" {
+		o.BackupNamespace = o.Namespace
+	}
+	return nil
+}
+
+func (o *RestoreCreateOptions) Run() error {
+	tc, err := o.getTidbCluster()
+	if err != nil {
+		return err
+	}
+
+	backup, err := o.TcCli.PingcapV1alpha1().Backups(o.BackupNamespace).Get(o.FromBackup, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	restore, err := newRestore(o.Name, tc, backup, time.Now())
+	if err != nil {
+		return err
+	}
+	restore, err = o.TcCli.PingcapV1alpha1().Restores(o.Namespace).Create(restore)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "restore %s/%s is created from backup %s/%s\n", restore.Namespace, restore.Name, backup.Namespace, backup.Name)
+
+	if !o.Wait {
+		return nil
+	}
+	err = waitFor(o.Out, o.Timeout, func() ([]condition, bool, error) {
+		restore, err = o.TcCli.PingcapV1alpha1().Restores(o.Namespace).Get(restore.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, false, err
+		}
+		conditions := make([]condition, 0, len(restore.Status.Conditions))
+		for _, c := range restore.Status.Conditions {
+			conditions = append(conditions, condition{string(c.Type), c.Status == corev1.ConditionTrue, c.LastTransitionTime, c.Reason, c.Message})
+		}
+		switch {
+		case v1alpha1.IsRestoreComplete(restore):
+			return conditions, true, nil
+		case v1alpha1.IsRestoreFailed(restore), v1alpha1.IsRestoreInvalid(restore):
+			return conditions, true, fmt.Errorf("restore %s/%s failed", restore.Namespace, restore.Name)
+		}
+		return conditions, false, nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "restore %s/%s is complete, commitTs: %s\n", restore.Namespace, restore.Name, restore.Status.CommitTs)
+	return nil
+}
+
+// newRestore builds a restore of the completed backup to the tidb cluster.
+func newRestore(name string, tc *v1alpha1.TidbCluster, backup *v1alpha1.Backup, now time.Time) (*v1alpha1.Restore, error) {
+	if !v1alpha1.IsBackupComplete(backup) {
+		return nil, fmt.Errorf("backup %s/%s is not complete", backup.Namespace, backup.Name)
+	}
+
+	spec := backup.Spec.DeepCopy()
+	restoreSpec := v1alpha1.RestoreSpec{
+		ResourceRequirements: spec.ResourceRequirements,
+		Env:                  spec.Env,
+		Type:                 spec.Type,
+		TikvGCLifeTime:       spec.TikvGCLifeTime,
+		StorageProvider:      spec.StorageProvider,
+		StorageClassName:     spec.StorageClassName,
+		StorageSize:          spec.StorageSize,
+		Tolerations:          spec.Tolerations,
+		Affinity:             spec.Affinity,
+		UseKMS:               spec.UseKMS,
+		ServiceAccount:       spec.ServiceAccount,
+		ToolImage:            spec.ToolImage,
+		ImagePullSecrets:     spec.ImagePullSecrets,
+		TableFilter:          spec.TableFilter,
+	}
+
+	if spec.BR != nil {
+		// BR restores from the prefix of the backup
+		restoreSpec.BR = spec.BR
+		restoreSpec.BR.Cluster = tc.Name
+		restoreSpec.BR.ClusterNamespace = tc.Namespace
+	} else {
+		if spec.From == nil {
+			return nil, fmt.Errorf("backup %s/%s is taken by neither BR nor dumpling", backup.Namespace, backup.Name)
+		}
+		// lightning restores from the full path of the backup
+		switch {
+		case restoreSpec.S3 != nil:
+			restoreSpec.S3.Path = backup.Status.BackupPath
+		case restoreSpec.Gcs != nil:
+			restoreSpec.Gcs.Path = backup.Status.BackupPath
+		default:
+			return nil, fmt.Errorf("backup %s/%s is not saved in s3 or gcs, which is not supported by lightning", backup.Namespace, backup.Name)
+		}
+		restoreSpec.To = spec.From
+		restoreSpec.To.Host = tkctlUtil.GetTidbServiceName(tc.Name)
+	}
+
+	if name == "" {
+		name = fmt.Sprintf("%s-restore-%s", tc.Name, now.UTC().Format(v1alpha1.BackupNameTimeFormat))
+	}
+	return &v1alpha1.Restore{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: tc.Namespace,
+			Name:      name,
+		},
+		Spec: restoreSpec,
+	}, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package changefeed
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestCDCClient(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var requests []string
+	var created createChangefeedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == http.MethodGet:
+			w.Write([]byte(`[{"id":"to-mysql","state":"normal","checkpoint_tso":420000000000000000,"checkpoint_time":"2020-09-10 00:00:00.000"}]`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/changefeeds":
+			g.Expect(json.NewDecoder(r.Body).Decode(&created)).To(Succeed())
+			w.WriteHeader(http.StatusAccepted)
+		case strings.HasSuffix(r.URL.Path, "/pause"):
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error_msg":"changefeed not exists"}`))
+		default:
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+
+	c := &cdcClient{httpClient: server.Client(), baseURL: server.URL}
+	changefeeds, err := c.list()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(changefeeds).To(HaveLen(1))
+	g.Expect(changefeeds[0].ID).To(Equal("to-mysql"))
+
+	g.Expect(c.create(&createChangefeedRequest{ID: "to-kafka", SinkURI: "kafka://kafka:9092/t"})).To(Succeed())
+	g.Expect(created.ID).To(Equal("to-kafka"))
+	g.Expect(created.SinkURI).To(Equal("kafka://kafka:9092/t"))
+
+	g.Expect(c.pause("foo")).NotTo(Succeed())
+	g.Expect(c.resume("to-mysql")).To(Succeed())
+	g.Expect(c.remove("to-mysql")).To(Succeed())
+	g.Expect(requests).To(Equal([]string{
+		"GET /api/v1/changefeeds",
+		"POST /api/v1/changefeeds",
+		"POST /api/v1/changefeeds/foo/pause",
+		"POST /api/v1/changefeeds/to-mysql/resume",
+		"DELETE /api/v1/changefeeds/to-mysql",
+	}))
+}
+
+func TestRenderChangefeeds(t *testing.T) {
+	g := NewGomegaWithT(t)
+	now := time.Unix(1600000000, 0)
+	tso := uint64(now.Add(-90*time.Second).UnixNano()/int64(time.Millisecond)) << physicalShiftBits
+	g.Expect(checkpointLag(tso, now)).To(Equal(90 * time.Second))
+
+	msg, err := renderChangefeeds([]changefeedInfo{
+		{ID: "to-mysql", State: "normal", CheckpointTSO: tso, CheckpointTime: "2020-09-13 12:25:10.000"},
+		{ID: "to-kafka", State: "failed", Error: &changefeedError{Code: "CDC:ErrKafkaNewSaramaProducer", Message: "dial tcp: i/o timeout"}},
+	}, now)
+	g.Expect(err).NotTo(HaveOccurred())
+	lines := strings.Split(msg, "\n")
+	g.Expect(lines[1]).To(ContainSubstring("90s"))
+	g.Expect(lines[2]).To(ContainSubstring("[CDC:ErrKafkaNewSaramaProducer] dial tcp: i/o timeout"))
+}
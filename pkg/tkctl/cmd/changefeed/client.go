@@ -0,0 +1,129 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package changefeed
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/tkctl/readable"
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+const (
+	// physicalShiftBits is the number of the bits of the logical part of a TSO
+	physicalShiftBits = 18
+)
+
+// changefeedInfo is a changefeed returned by the TiCDC API
+type changefeedInfo struct {
+	ID             string           `json:"id"`
+	State          string           `json:"state"`
+	CheckpointTSO  uint64           `json:"checkpoint_tso"`
+	CheckpointTime string           `json:"checkpoint_time"`
+	Error          *changefeedError `json:"error,omitempty"`
+}
+
+type changefeedError struct {
+	Addr    string `json:"addr"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// createChangefeedRequest is the request to create a changefeed by the TiCDC API
+type createChangefeedRequest struct {
+	ID          string   `json:"changefeed_id"`
+	SinkURI     string   `json:"sink_uri"`
+	StartTS     uint64   `json:"start_ts,omitempty"`
+	TargetTS    uint64   `json:"target_ts,omitempty"`
+	FilterRules []string `json:"filter_rules,omitempty"`
+}
+
+// cdcClient calls the TiCDC API of a capture, the requests are forwarded to the owner by the capture.
+type cdcClient struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+func (c *cdcClient) list() ([]changefeedInfo, error) {
+	body, err := httputil.GetBodyOK(c.httpClient, c.baseURL+"/api/v1/changefeeds")
+	if err != nil {
+		return nil, err
+	}
+	changefeeds := []changefeedInfo{}
+	err = json.Unmarshal(body, &changefeeds)
+	return changefeeds, err
+}
+
+func (c *cdcClient) create(req *createChangefeedRequest) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	_, err = httputil.PostBodyOK(c.httpClient, c.baseURL+"/api/v1/changefeeds", bytes.NewReader(data))
+	return err
+}
+
+func (c *cdcClient) pause(id string) error {
+	_, err := httputil.PostBodyOK(c.httpClient, c.changefeedURL(id)+"/pause", nil)
+	return err
+}
+
+func (c *cdcClient) resume(id string) error {
+	_, err := httputil.PostBodyOK(c.httpClient, c.changefeedURL(id)+"/resume", nil)
+	return err
+}
+
+func (c *cdcClient) remove(id string) error {
+	_, err := httputil.DeleteBodyOK(c.httpClient, c.changefeedURL(id))
+	return err
+}
+
+func (c *cdcClient) changefeedURL(id string) string {
+	return fmt.Sprintf("%s/api/v1/changefeeds/%s", c.baseURL, url.PathEscape(id))
+}
+
+// checkpointLag returns the lag of the checkpoint of the changefeed behind now.
+func checkpointLag(tso uint64, now time.Time) time.Duration {
+	physical := int64(tso >> physicalShiftBits)
+	checkpoint := time.Unix(physical/1000, (physical%1000)*int64(time.Millisecond))
+	return now.Sub(checkpoint)
+}
+
+// renderChangefeeds renders the changefeeds as a table.
+func renderChangefeeds(changefeeds []changefeedInfo, now time.Time) (string, error) {
+	return readable.TabbedString(func(out io.Writer) error {
+		w := readable.NewPrefixWriter(out)
+		w.WriteLine(readable.LEVEL_0, "ID\tSTATE\tCHECKPOINT\tLAG\tERROR")
+		for _, cf := range changefeeds {
+			checkpoint, lag := "<none>", "<none>"
+			if cf.CheckpointTSO > 0 {
+				checkpoint = cf.CheckpointTime
+				lag = duration.HumanDuration(checkpointLag(cf.CheckpointTSO, now))
+			}
+			errMsg := "<none>"
+			if cf.Error != nil {
+				errMsg = fmt.Sprintf("[%s] %s", cf.Error.Code, cf.Error.Message)
+			}
+			w.WriteLine(readable.LEVEL_0, "%s\t%s\t%s\t%s\t%s", cf.ID, cf.State, checkpoint, lag, errMsg)
+		}
+		return nil
+	})
+}
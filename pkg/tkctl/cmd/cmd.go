@@ -19,15 +19,25 @@ import (
 
 	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/diagnose"
 
+	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/backup"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/changefeed"
 	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/completion"
 	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/ctop"
 	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/debug"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/describe"
 	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/get"
 	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/info"
 	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/list"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/pdctl"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/preflight"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/restart"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/scale"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/tikvctl"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/upgrade"
 	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/upinfo"
 	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/use"
 	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/version"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/volume"
 	"github.com/pingcap/tidb-operator/pkg/tkctl/config"
 
 	"github.com/spf13/cobra"
@@ -44,19 +54,33 @@ import (
 const (
 	tkcLongDescription = `
 		"tkctl"(TiDB kubernetes control) is a command line interface for cloud tidb management and troubleshooting.
+`
+	kubectlTiDBLongDescription = `
+		"kubectl tidb" is a kubectl plugin for cloud tidb management and troubleshooting,
+		it provides the same commands as "tkctl".
 `
 )
 
 // NewTkcCommand creates the root `tkc` command and its nested children.
 func NewTkcCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	return newRootCommand("tkctl", "TiDB kubernetes control.", tkcLongDescription, streams)
+}
+
+// NewKubectlTiDBCommand creates the root command of the kubectl plugin `kubectl tidb` and its nested children,
+// the binary is named kubectl-tidb so that kubectl discovers it from PATH.
+func NewKubectlTiDBCommand(streams genericclioptions.IOStreams) *cobra.Command {
+	return newRootCommand("kubectl-tidb", "kubectl plugin of TiDB kubernetes control.", kubectlTiDBLongDescription, streams)
+}
+
+func newRootCommand(name, short, long string, streams genericclioptions.IOStreams) *cobra.Command {
 
 	options := &config.TkcOptions{}
 
 	// Root command that all the subcommands are added to
 	rootCmd := &cobra.Command{
-		Use:   "tkctl",
-		Short: "TiDB kubernetes control.",
-		Long:  tkcLongDescription,
+		Use:   name,
+		Short: short,
+		Long:  long,
 		Run:   runHelp,
 	}
 
@@ -76,11 +100,25 @@ func NewTkcCommand(streams genericclioptions.IOStreams) *cobra.Command {
 			Commands: []*cobra.Command{
 				list.NewCmdList(tkcContext, streams),
 				get.NewCmdGet(tkcContext, streams),
+				describe.NewCmdDescribe(tkcContext, streams),
 				info.NewCmdInfo(tkcContext, streams),
 				use.NewCmdUse(tkcContext, streams),
 				version.NewCmdVersion(tkcContext, streams.Out),
 				upinfo.NewCmdUpInfo(tkcContext, streams),
+				preflight.NewCmdPreflight(tkcContext, streams),
 				diagnose.NewCmdDiagnoseInfo(tkcContext, streams),
+				backup.NewCmdBackup(tkcContext, streams),
+				backup.NewCmdRestore(tkcContext, streams),
+				volume.NewCmdVolume(tkcContext, streams),
+			},
+		},
+		{
+			Message: "Cluster Operation Commands:",
+			Commands: []*cobra.Command{
+				scale.NewCmdScale(tkcContext, streams),
+				upgrade.NewCmdUpgrade(tkcContext, streams),
+				restart.NewCmdRestart(tkcContext, streams),
+				changefeed.NewCmdChangefeed(tkcContext, streams),
 			},
 		},
 		{
@@ -88,6 +126,8 @@ func NewTkcCommand(streams genericclioptions.IOStreams) *cobra.Command {
 			Commands: []*cobra.Command{
 				debug.NewCmdDebug(tkcContext, streams),
 				ctop.NewCmdCtop(tkcContext, streams),
+				pdctl.NewCmdPdctl(tkcContext, streams),
+				tikvctl.NewCmdTikvctl(tkcContext, streams),
 			},
 		},
 		{
@@ -0,0 +1,208 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctop
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/readable"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+)
+
+const (
+	pdClientPort   = "2379"
+	tidbStatusPort = "10080"
+	// clearScreen moves the cursor to the top left and clears the terminal
+	clearScreen = "\x1b[H\x1b[2J"
+)
+
+// podMetricsList is the subset of metrics.k8s.io/v1beta1 PodMetricsList used by ctop,
+// the metrics-server API is queried directly to avoid depending on the metrics clientset.
+type podMetricsList struct {
+	Items []podMetrics `json:"items"`
+}
+
+type podMetrics struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Containers        []containerMetrics `json:"containers"`
+}
+
+type containerMetrics struct {
+	Name  string          `json:"name"`
+	Usage v1.ResourceList `json:"usage"`
+}
+
+// clusterSample is a snapshot of the metrics of the pods of a tidb cluster.
+type clusterSample struct {
+	pods []v1.Pod
+	// usage is keyed by the pod name, it's nil if the metrics-server is unavailable
+	usage map[string]v1.ResourceList
+	// stores is keyed by the pod name of the TiKV and TiFlash stores
+	stores map[string]*pdapi.StoreStatus
+	// connections is keyed by the pod name of tidb
+	connections map[string]int
+	// errs holds the failures of the metric sources, which are rendered instead of failing
+	errs []string
+}
+
+// sampleCluster collects the resource usage from the metrics-server, the store statistics
+// from PD and the connection counts from the tidb status API, all through the apiserver.
+func (o *CtopOptions) sampleCluster(tc *v1alpha1.TidbCluster) (*clusterSample, error) {
+	selector := label.New().Instance(tc.Name).String()
+	podList, err := o.KubeCli.CoreV1().Pods(tc.Namespace).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	sample := &clusterSample{
+		pods:        podList.Items,
+		stores:      map[string]*pdapi.StoreStatus{},
+		connections: map[string]int{},
+	}
+
+	body, err := o.KubeCli.Discovery().RESTClient().Get().
+		AbsPath("/apis/metrics.k8s.io/v1beta1/namespaces", tc.Namespace, "pods").
+		Param("labelSelector", selector).
+		DoRaw()
+	if err == nil {
+		metrics := &podMetricsList{}
+		if err = json.Unmarshal(body, metrics); err == nil {
+			sample.usage = podUsage(metrics)
+		}
+	}
+	if err != nil {
+		sample.errs = append(sample.errs, fmt.Sprintf("metrics-server: %v", err))
+	}
+
+	stores := &pdapi.StoresInfo{}
+	body, err = o.KubeCli.CoreV1().Services(tc.Namespace).
+		ProxyGet(tc.Scheme(), controller.PDMemberName(tc.Name), pdClientPort, "/pd/api/v1/stores", nil).
+		DoRaw()
+	if err == nil {
+		err = json.Unmarshal(body, stores)
+	}
+	if err != nil {
+		sample.errs = append(sample.errs, fmt.Sprintf("pd: %v", err))
+	}
+	for _, store := range stores.Stores {
+		if store.Store == nil || store.Status == nil || store.Store.Store == nil {
+			continue
+		}
+		sample.stores[storePodName(store.Store.Address)] = store.Status
+	}
+
+	for _, pod := range podList.Items {
+		if pod.Labels[label.ComponentLabelKey] != label.TiDBLabelVal || pod.Status.Phase != v1.PodRunning {
+			continue
+		}
+		status := &controller.TiDBStatus{}
+		body, err := o.KubeCli.CoreV1().RESTClient().Get().
+			Namespace(tc.Namespace).
+			Resource("pods").
+			SubResource("proxy").
+			Name(utilnet.JoinSchemeNamePort(tc.Scheme(), pod.Name, tidbStatusPort)).
+			Suffix("status").
+			DoRaw()
+		if err == nil {
+			err = json.Unmarshal(body, status)
+		}
+		if err != nil {
+			sample.errs = append(sample.errs, fmt.Sprintf("%s: %v", pod.Name, err))
+			continue
+		}
+		sample.connections[pod.Name] = status.Connections
+	}
+	return sample, nil
+}
+
+// podUsage sums up the usage of the containers of each pod.
+func podUsage(metrics *podMetricsList) map[string]v1.ResourceList {
+	usage := map[string]v1.ResourceList{}
+	for _, item := range metrics.Items {
+		cpu := resource.Quantity{}
+		memory := resource.Quantity{}
+		for _, c := range item.Containers {
+			cpu.Add(*c.Usage.Cpu())
+			memory.Add(*c.Usage.Memory())
+		}
+		usage[item.Name] = v1.ResourceList{
+			v1.ResourceCPU:    cpu,
+			v1.ResourceMemory: memory,
+		}
+	}
+	return usage
+}
+
+// storePodName returns the pod name of the store address, which is
+// in the form of <pod>.<peer-service>.<namespace>.svc:<port>.
+func storePodName(address string) string {
+	return strings.SplitN(address, ".", 2)[0]
+}
+
+// renderClusterSample renders the sample as a table of the pods sorted by the component and name.
+func renderClusterSample(tc *v1alpha1.TidbCluster, sample *clusterSample, now time.Time) (string, error) {
+	pods := append([]v1.Pod(nil), sample.pods...)
+	sort.Slice(pods, func(i, j int) bool {
+		ci, cj := pods[i].Labels[label.ComponentLabelKey], pods[j].Labels[label.ComponentLabelKey]
+		if ci != cj {
+			return ci < cj
+		}
+		return pods[i].Name < pods[j].Name
+	})
+
+	return readable.TabbedString(func(out io.Writer) error {
+		w := readable.NewPrefixWriter(out)
+		w.WriteLine(readable.LEVEL_0, "Cluster:\t%s/%s\tVersion:\t%s\tTime:\t%s",
+			tc.Namespace, tc.Name, tc.Spec.Version, now.Format(time.RFC3339))
+		w.WriteLine(readable.LEVEL_0, "")
+		w.WriteLine(readable.LEVEL_0, "POD\tCOMPONENT\tSTATUS\tCPU\tMEMORY\tLEADERS\tREGIONS\tCONNECTIONS")
+		for _, pod := range pods {
+			cpu, memory := "-", "-"
+			if usage, ok := sample.usage[pod.Name]; ok {
+				cpu = fmt.Sprintf("%dm", usage.Cpu().MilliValue())
+				memory = fmt.Sprintf("%dMi", usage.Memory().Value()/(1024*1024))
+			}
+			leaders, regions := "-", "-"
+			if store, ok := sample.stores[pod.Name]; ok {
+				leaders = fmt.Sprintf("%d", store.LeaderCount)
+				regions = fmt.Sprintf("%d", store.RegionCount)
+			}
+			connections := "-"
+			if n, ok := sample.connections[pod.Name]; ok {
+				connections = fmt.Sprintf("%d", n)
+			}
+			w.WriteLine(readable.LEVEL_0, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s",
+				pod.Name, pod.Labels[label.ComponentLabelKey], pod.Status.Phase, cpu, memory, leaders, regions, connections)
+		}
+		if len(sample.errs) > 0 {
+			w.WriteLine(readable.LEVEL_0, "")
+			w.WriteLine(readable.LEVEL_0, "Unavailable metrics:")
+			for _, e := range sample.errs {
+				w.WriteLine(readable.LEVEL_1, "%s", e)
+			}
+		}
+		return nil
+	})
+}
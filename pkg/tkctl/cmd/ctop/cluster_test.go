@@ -0,0 +1,95 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ctop
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newPod(name, component string) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{label.ComponentLabelKey: component},
+		},
+		Status: v1.PodStatus{Phase: v1.PodRunning},
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	g := NewGomegaWithT(t)
+	for arg, expected := range map[string][2]string{
+		"basic-tikv-0": {"pod", "basic-tikv-0"},
+		"node/n1":      {"node", "n1"},
+		"cluster/foo":  {"cluster", "foo"},
+		"tc/foo":       {"cluster", "foo"},
+	} {
+		kind, target := parseTarget(arg)
+		g.Expect([2]string{string(kind), target}).To(Equal(expected), arg)
+	}
+}
+
+func TestPodUsage(t *testing.T) {
+	g := NewGomegaWithT(t)
+	usage := podUsage(&podMetricsList{Items: []podMetrics{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "basic-tikv-0"},
+			Containers: []containerMetrics{
+				{Name: "tikv", Usage: v1.ResourceList{v1.ResourceCPU: resource.MustParse("200m"), v1.ResourceMemory: resource.MustParse("1Gi")}},
+				{Name: "slowlog", Usage: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1m"), v1.ResourceMemory: resource.MustParse("1Mi")}},
+			},
+		},
+	}})
+	tikv := usage["basic-tikv-0"]
+	g.Expect(tikv.Cpu().MilliValue()).To(Equal(int64(201)))
+	g.Expect(tikv.Memory().Value()).To(Equal(int64(1025 * 1024 * 1024)))
+}
+
+func TestRenderClusterSample(t *testing.T) {
+	g := NewGomegaWithT(t)
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "basic"},
+		Spec:       v1alpha1.TidbClusterSpec{Version: "v4.0.8"},
+	}
+	sample := &clusterSample{
+		pods: []v1.Pod{newPod("basic-tikv-0", "tikv"), newPod("basic-tidb-0", "tidb"), newPod("basic-pd-0", "pd")},
+		usage: map[string]v1.ResourceList{
+			"basic-tikv-0": {v1.ResourceCPU: resource.MustParse("1500m"), v1.ResourceMemory: resource.MustParse("2Gi")},
+		},
+		stores: map[string]*pdapi.StoreStatus{
+			storePodName("basic-tikv-0.basic-tikv-peer.ns.svc:20160"): {LeaderCount: 10, RegionCount: 30},
+		},
+		connections: map[string]int{"basic-tidb-0": 5},
+		errs:        []string{"pd: timeout"},
+	}
+
+	msg, err := renderClusterSample(tc, sample, time.Now())
+	g.Expect(err).NotTo(HaveOccurred())
+	lines := strings.Split(msg, "\n")
+	g.Expect(lines[0]).To(ContainSubstring("ns/basic"))
+	g.Expect(strings.Fields(lines[3])).To(Equal([]string{"basic-pd-0", "pd", "Running", "-", "-", "-", "-", "-"}))
+	g.Expect(strings.Fields(lines[4])).To(Equal([]string{"basic-tidb-0", "tidb", "Running", "-", "-", "-", "-", "5"}))
+	g.Expect(strings.Fields(lines[5])).To(Equal([]string{"basic-tikv-0", "tikv", "Running", "1500m", "2048Mi", "10", "30", "-"}))
+	g.Expect(msg).To(ContainSubstring("pd: timeout"))
+}
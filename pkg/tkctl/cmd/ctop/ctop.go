@@ -15,13 +15,19 @@ package ctop
 
 import (
 	"fmt"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
 	"github.com/pingcap/tidb-operator/pkg/tkctl/config"
 	"github.com/pingcap/tidb-operator/pkg/tkctl/executor"
 	"github.com/pingcap/tidb-operator/pkg/tkctl/util"
 	"github.com/spf13/cobra"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -37,16 +43,23 @@ const (
 
 	# ctop the specified node
 	tkctl ctop node/NODE_NAME
+
+	# show the live resource usage, store and connection metrics of the pods of a tidb cluster
+	tkctl ctop cluster/CLUSTER_NAME
+
+	# show the metrics of the current tidb cluster once
+	tkctl ctop --once
 `
-	ctopUsage    = "expected 'ctop POD_NAME' or 'ctop node/NODE_NAME' for the ctop command"
+	ctopUsage    = "expected 'ctop POD_NAME', 'ctop node/NODE_NAME' or 'ctop cluster/CLUSTER_NAME' for the ctop command"
 	defaultImage = "quay.io/vektorlab/ctop:0.7.2"
 )
 
 type CtopKind string
 
 const (
-	CtopPod  CtopKind = "pod"
-	CtopNode CtopKind = "node"
+	CtopPod     CtopKind = "pod"
+	CtopNode    CtopKind = "node"
+	CtopCluster CtopKind = "cluster"
 )
 
 // CtopOptions specify the target resource stats to show
@@ -56,8 +69,11 @@ type CtopOptions struct {
 	Namespace        string
 	Image            string
 	HostDockerSocket string
+	Interval         time.Duration
+	Once             bool
 
 	KubeCli *kubernetes.Clientset
+	TcCli   *versioned.Clientset
 
 	RestConfig *rest.Config
 
@@ -70,6 +86,7 @@ func NewCtopOptions(iostreams genericclioptions.IOStreams) *CtopOptions {
 		Kind:             CtopPod,
 		Image:            defaultImage,
 		HostDockerSocket: util.DockerSocket,
+		Interval:         3 * time.Second,
 
 		IOStreams: iostreams,
 	}
@@ -82,7 +99,7 @@ func NewCmdCtop(tkcContext *config.TkcContext, streams genericclioptions.IOStrea
 	cmd := &cobra.Command{
 		Use:     "ctop",
 		Example: ctopExample,
-		Short:   "ctop shows top-like container stats for given pod",
+		Short:   "ctop shows top-like container stats for given pod or tidb cluster",
 		Run: func(cmd *cobra.Command, args []string) {
 			cmdutil.CheckErr(options.Complete(cmd, tkcContext, args))
 			cmdutil.CheckErr(options.Run())
@@ -92,19 +109,25 @@ func NewCmdCtop(tkcContext *config.TkcContext, streams genericclioptions.IOStrea
 		"docker socket path of kubernetes node")
 	cmd.Flags().StringVar(&options.Image, "image", options.Image,
 		"Container Image to run the debug container")
+	cmd.Flags().DurationVar(&options.Interval, "interval", options.Interval,
+		"the refresh interval of the tidb cluster metrics")
+	cmd.Flags().BoolVar(&options.Once, "once", options.Once,
+		"print the tidb cluster metrics once instead of refreshing them")
 	return cmd
 }
 
 func (o *CtopOptions) Complete(cmd *cobra.Command, tkcContext *config.TkcContext, args []string) error {
-	if len(args) < 1 {
-		return cmdutil.UsageErrorf(cmd, ctopUsage)
-	}
-	o.Kind, o.Target = parseTarget(args[0])
-
 	clientConfig, err := tkcContext.ToTkcClientConfig()
 	if err != nil {
 		return err
 	}
+	if len(args) > 0 {
+		o.Kind, o.Target = parseTarget(args[0])
+	} else if tcName, ok := clientConfig.TidbClusterName(); ok {
+		o.Kind, o.Target = CtopCluster, tcName
+	} else {
+		return cmdutil.UsageErrorf(cmd, ctopUsage)
+	}
 	ns, _, err := clientConfig.Namespace()
 	if err != nil {
 		return err
@@ -120,6 +143,11 @@ func (o *CtopOptions) Complete(cmd *cobra.Command, tkcContext *config.TkcContext
 		return err
 	}
 	o.KubeCli = kubeCli
+	tcCli, err := versioned.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	o.TcCli = tcCli
 	return nil
 }
 
@@ -128,7 +156,15 @@ func (o *CtopOptions) Run() error {
 	var nodeName string
 	var filter string
 	switch o.Kind {
+	case CtopCluster:
+		return o.runCluster()
 	case CtopPod:
+		// a bare name refers to a tidb cluster if there is one with that name
+		if _, err := o.TcCli.PingcapV1alpha1().TidbClusters(o.Namespace).Get(o.Target, metav1.GetOptions{}); err == nil {
+			return o.runCluster()
+		} else if !apierrors.IsNotFound(err) {
+			return err
+		}
 		pod, err := o.KubeCli.CoreV1().Pods(o.Namespace).Get(o.Target, metav1.GetOptions{})
 		if err != nil {
 			return err
@@ -145,6 +181,41 @@ func (o *CtopOptions) Run() error {
 	return podExecutor.Execute()
 }
 
+// runCluster renders the metrics of the tidb cluster every interval until interrupted.
+func (o *CtopOptions) runCluster() error {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	ticker := time.NewTicker(o.Interval)
+	defer ticker.Stop()
+	for {
+		tc, err := o.TcCli.PingcapV1alpha1().TidbClusters(o.Namespace).Get(o.Target, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		sample, err := o.sampleCluster(tc)
+		if err != nil {
+			return err
+		}
+		msg, err := renderClusterSample(tc, sample, time.Now())
+		if err != nil {
+			return err
+		}
+		if o.Once {
+			fmt.Fprint(o.Out, msg)
+			return nil
+		}
+		fmt.Fprint(o.Out, clearScreen+msg)
+
+		select {
+		case <-sigCh:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
 func (o *CtopOptions) makeCtopPod(nodeName, filter string) *v1.Pod {
 	args := []string{"-a"}
 	if len(filter) > 0 {
@@ -189,5 +260,9 @@ func parseTarget(arg string) (kind CtopKind, target string) {
 		kind = CtopKind(splits[0])
 		target = splits[1]
 	}
+	switch kind {
+	case "tc", "tidbcluster":
+		kind = CtopCluster
+	}
 	return
 }
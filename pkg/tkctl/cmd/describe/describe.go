@@ -0,0 +1,256 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package describe
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/config"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/readable"
+	"github.com/spf13/cobra"
+	apps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+const (
+	describeLongDesc = `
+		Show the status of a tidb cluster in detail, including the phase, replicas, image and
+		upgrade progress of each component, the conditions, the recent operations and the
+		pending changes previewed for paused clusters.
+
+		You may omit --tidbcluster option by running 'tkc use <clusterName>'.
+`
+	describeExample = `
+		# describe the current tidb cluster (set by tkctl use)
+		tkctl describe
+
+		# describe a specified tidb cluster
+		tkctl describe -t demo-cluster
+`
+	describeUsage = `expected 'describe -t CLUSTER_NAME' for the describe command or
+using 'tkctl use' to set tidb cluster first.`
+
+	// maxOperations is the number of the most recent operations to show
+	maxOperations = 10
+	none          = "<none>"
+)
+
+// DescribeOptions contains the input to the describe command.
+type DescribeOptions struct {
+	TidbClusterName string
+	Namespace       string
+
+	TcCli *versioned.Clientset
+
+	genericclioptions.IOStreams
+}
+
+// NewDescribeOptions returns a DescribeOptions
+func NewDescribeOptions(streams genericclioptions.IOStreams) *DescribeOptions {
+	return &DescribeOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdDescribe creates the describe command which shows the status of a tidb cluster in detail
+func NewCmdDescribe(tkcContext *config.TkcContext, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewDescribeOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:     "describe",
+		Short:   "Show the status of a tidb cluster in detail",
+		Long:    describeLongDesc,
+		Example: describeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(tkcContext, cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+	return cmd
+}
+
+func (o *DescribeOptions) Complete(tkcContext *config.TkcContext, cmd *cobra.Command, args []string) error {
+	clientConfig, err := tkcContext.ToTkcClientConfig()
+	if err != nil {
+		return err
+	}
+
+	if tidbClusterName, ok := clientConfig.TidbClusterName(); ok {
+		o.TidbClusterName = tidbClusterName
+	} else {
+		return cmdutil.UsageErrorf(cmd, describeUsage)
+	}
+
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return err
+	}
+	o.Namespace = namespace
+
+	restConfig, err := clientConfig.RestConfig()
+	if err != nil {
+		return err
+	}
+	tcCli, err := versioned.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	o.TcCli = tcCli
+
+	return nil
+}
+
+func (o *DescribeOptions) Run() error {
+	tc, err := o.TcCli.PingcapV1alpha1().
+		TidbClusters(o.Namespace).
+		Get(o.TidbClusterName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	msg, err := renderTidbCluster(tc, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(o.Out, msg)
+	return nil
+}
+
+// component is the status of a component to render
+type component struct {
+	name     string
+	phase    v1alpha1.MemberPhase
+	replicas int32
+	set      *apps.StatefulSetStatus
+	image    string
+	progress *v1alpha1.UpgradeProgress
+}
+
+// components returns the components deployed in the tidb cluster
+func components(tc *v1alpha1.TidbCluster) []component {
+	var cs []component
+	if tc.Spec.PD != nil {
+		cs = append(cs, component{"PD", tc.Status.PD.Phase, tc.Spec.PD.Replicas, tc.Status.PD.StatefulSet, tc.PDImage(), tc.Status.PD.UpgradeProgress})
+	}
+	if tc.Spec.TiKV != nil {
+		cs = append(cs, component{"TiKV", tc.Status.TiKV.Phase, tc.Spec.TiKV.Replicas, tc.Status.TiKV.StatefulSet, tc.TiKVImage(), tc.Status.TiKV.UpgradeProgress})
+	}
+	if tc.Spec.TiFlash != nil {
+		cs = append(cs, component{"TiFlash", tc.Status.TiFlash.Phase, tc.Spec.TiFlash.Replicas, tc.Status.TiFlash.StatefulSet, tc.TiFlashImage(), tc.Status.TiFlash.UpgradeProgress})
+	}
+	if tc.Spec.TiDB != nil {
+		cs = append(cs, component{"TiDB", tc.Status.TiDB.Phase, tc.Spec.TiDB.Replicas, tc.Status.TiDB.StatefulSet, tc.TiDBImage(), tc.Status.TiDB.UpgradeProgress})
+	}
+	if tc.Spec.TiCDC != nil {
+		cs = append(cs, component{"TiCDC", tc.Status.TiCDC.Phase, tc.Spec.TiCDC.Replicas, tc.Status.TiCDC.StatefulSet, tc.TiCDCImage(), nil})
+	}
+	if tc.Spec.Pump != nil {
+		image := none
+		if tc.PumpImage() != nil {
+			image = *tc.PumpImage()
+		}
+		cs = append(cs, component{"Pump", tc.Status.Pump.Phase, tc.Spec.Pump.Replicas, tc.Status.Pump.StatefulSet, image, nil})
+	}
+	return cs
+}
+
+func since(t metav1.Time, now time.Time) string {
+	if t.IsZero() {
+		return none
+	}
+	return duration.HumanDuration(now.Sub(t.Time))
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return none
+	}
+	return s
+}
+
+// go template is lacking type checking and hard to maintain, in this
+// case we just render manually
+func renderTidbCluster(tc *v1alpha1.TidbCluster, now time.Time) (string, error) {
+	return readable.TabbedString(func(out io.Writer) error {
+		w := readable.NewPrefixWriter(out)
+		w.WriteLine(readable.LEVEL_0, "Name:\t%s", tc.Name)
+		w.WriteLine(readable.LEVEL_0, "Namespace:\t%s", tc.Namespace)
+		w.WriteLine(readable.LEVEL_0, "CreationTimestamp:\t%s", tc.CreationTimestamp)
+		w.WriteLine(readable.LEVEL_0, "Version:\t%s", orNone(tc.Spec.Version))
+		w.WriteLine(readable.LEVEL_0, "Paused:\t%t", tc.Spec.Paused)
+		w.WriteLine(readable.LEVEL_0, "Generation:\t%d (observed %d)", tc.Generation, tc.Status.ObservedGeneration)
+
+		w.WriteLine(readable.LEVEL_0, "Components:")
+		{
+			w.WriteLine(readable.LEVEL_1, "Name\tPhase\tReady\tDesired\tUpdated\tImage\tUpgrade\t")
+			w.WriteLine(readable.LEVEL_1, "----\t-----\t-----\t-------\t-------\t-----\t-------\t")
+			for _, c := range components(tc) {
+				var ready, updated int32
+				if c.set != nil {
+					ready, updated = c.set.ReadyReplicas, c.set.UpdatedReplicas
+				}
+				upgrade := none
+				if p := c.progress; p != nil {
+					upgrade = fmt.Sprintf("%d/%d to %s", p.UpdatedReplicas, p.Replicas, orNone(p.TargetVersion))
+					if p.BlockingReason != "" {
+						upgrade += ", " + p.BlockingReason
+					}
+				}
+				w.WriteLine(readable.LEVEL_1, "%s\t%s\t%d\t%d\t%d\t%s\t%s\t", c.name, orNone(string(c.phase)), ready, c.replicas, updated, orNone(c.image), upgrade)
+			}
+		}
+
+		w.WriteLine(readable.LEVEL_0, "Conditions:")
+		if len(tc.Status.Conditions) == 0 {
+			w.WriteLine(readable.LEVEL_1, none)
+		} else {
+			w.WriteLine(readable.LEVEL_1, "Type\tStatus\tReason\tAge\tMessage\t")
+			w.WriteLine(readable.LEVEL_1, "----\t------\t------\t---\t-------\t")
+			for _, c := range tc.Status.Conditions {
+				w.WriteLine(readable.LEVEL_1, "%s\t%s\t%s\t%s\t%s\t", c.Type, c.Status, orNone(c.Reason), since(c.LastTransitionTime, now), c.Message)
+			}
+		}
+
+		w.WriteLine(readable.LEVEL_0, "Operations:")
+		if len(tc.Status.Operations) == 0 {
+			w.WriteLine(readable.LEVEL_1, none)
+		} else {
+			w.WriteLine(readable.LEVEL_1, "Type\tComponent\tTarget\tResult\tStarted\tMessage\t")
+			w.WriteLine(readable.LEVEL_1, "----\t---------\t------\t------\t-------\t-------\t")
+			operations := tc.Status.Operations
+			if len(operations) > maxOperations {
+				operations = operations[len(operations)-maxOperations:]
+			}
+			for _, op := range operations {
+				w.WriteLine(readable.LEVEL_1, "%s\t%s\t%s\t%s\t%s\t%s\t", op.Type, op.Component, orNone(op.Target), op.Result, since(op.StartTime, now), op.Message)
+			}
+		}
+
+		if len(tc.Status.PendingChanges) > 0 {
+			w.WriteLine(readable.LEVEL_0, "Pending Changes:")
+			w.WriteLine(readable.LEVEL_1, "Kind\tName\tComponent\tAction\t")
+			w.WriteLine(readable.LEVEL_1, "----\t----\t---------\t------\t")
+			for _, c := range tc.Status.PendingChanges {
+				w.WriteLine(readable.LEVEL_1, "%s\t%s\t%s\t%s\t", c.Kind, c.Name, c.Component, c.Action)
+			}
+		}
+		return nil
+	})
+}
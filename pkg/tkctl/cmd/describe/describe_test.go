@@ -0,0 +1,77 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package describe
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRenderTidbCluster(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	now := time.Now()
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns", Generation: 3},
+		Spec: v1alpha1.TidbClusterSpec{
+			Version: "v4.0.9",
+			PD:      &v1alpha1.PDSpec{Replicas: 3},
+			TiKV:    &v1alpha1.TiKVSpec{Replicas: 3},
+		},
+		Status: v1alpha1.TidbClusterStatus{
+			ObservedGeneration: 3,
+			PD: v1alpha1.PDStatus{
+				Phase:       v1alpha1.NormalPhase,
+				StatefulSet: &apps.StatefulSetStatus{ReadyReplicas: 3, UpdatedReplicas: 3},
+			},
+			TiKV: v1alpha1.TiKVStatus{
+				Phase:       v1alpha1.UpgradePhase,
+				StatefulSet: &apps.StatefulSetStatus{ReadyReplicas: 2, UpdatedReplicas: 1},
+				UpgradeProgress: &v1alpha1.UpgradeProgress{
+					TargetVersion:   "v4.0.9",
+					Replicas:        3,
+					UpdatedReplicas: 1,
+					BlockingReason:  "WaitingForLeaderEviction",
+				},
+			},
+			Conditions: []v1alpha1.TidbClusterCondition{{
+				Type:               v1alpha1.TidbClusterReady,
+				Status:             corev1.ConditionFalse,
+				Reason:             "TiKVStoreNotUp",
+				LastTransitionTime: metav1.NewTime(now.Add(-time.Minute)),
+			}},
+			Operations: []v1alpha1.OperationRecord{{
+				Type:      v1alpha1.UpgradeOperation,
+				Component: v1alpha1.TiKVMemberType,
+				Result:    v1alpha1.OperationInProgress,
+				StartTime: metav1.NewTime(now.Add(-5 * time.Minute)),
+			}},
+		},
+	}
+
+	out, err := renderTidbCluster(tc, now)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(out).To(ContainSubstring("Generation:         3 (observed 3)"))
+	g.Expect(out).To(MatchRegexp(`TiKV\s+Upgrade\s+2\s+3\s+1\s+\S+\s+1/3 to v4.0.9, WaitingForLeaderEviction`))
+	g.Expect(out).To(MatchRegexp(`Ready\s+False\s+TiKVStoreNotUp\s+60s`))
+	g.Expect(out).To(MatchRegexp(`Upgrade\s+tikv\s+<none>\s+InProgress\s+5m`))
+	g.Expect(out).NotTo(ContainSubstring("TiFlash"))
+	g.Expect(out).NotTo(ContainSubstring("Pending Changes"))
+}
@@ -17,6 +17,7 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
@@ -38,6 +39,7 @@ import (
 	"github.com/spf13/cobra"
 	appv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -51,7 +53,13 @@ import (
 const (
 	diagnoseLongDesc = `
 		Export a tidb cluster diagnostic information of a specified cluster.
-		
+
+		The spec and status of the TidbCluster, the objects of its components, the
+		recent events, the PD members and stores, and the logs of the component and
+		tidb-operator pods are dumped into the given path and packed into a
+		<path>.tar.gz bundle. Passwords, tokens and secret keys are redacted from
+		the dumped objects.
+
 		You may omit --tidbcluster option by running 'tkc use <clusterName>'.
 `
 	diagnoseExample = `
@@ -59,7 +67,10 @@ const (
 		tkctl diagnose
 
 		# diagnose specify tidb cluster information
-		tkctl diagnose -t demo-cluster
+		tkctl diagnose -t demo-cluster --path /tmp/demo-cluster
+
+		# diagnose a tidb cluster managed by a tidb-operator deployed in namespace 'tidb-operator'
+		tkctl diagnose -t demo-cluster --path /tmp/demo-cluster --operator-namespace tidb-operator
 `
 	diagnoseUsage = `expected 'diagnose -t CLUSTER_NAME' for the diagnose command or
 using 'tkctl use to set tidb cluster first.'`
@@ -74,11 +85,12 @@ type diagnoseInfoOptions struct {
 
 	listOptions metav1.ListOptions
 
-	logPath       string
-	since         time.Duration
-	byteReadLimit int64
-	printer       printers.ResourcePrinter
-	tidbPrinter   printers.ResourcePrinter
+	logPath           string
+	since             time.Duration
+	byteReadLimit     int64
+	operatorNamespace string
+	printer           printers.ResourcePrinter
+	tidbPrinter       printers.ResourcePrinter
 
 	genericclioptions.IOStreams
 }
@@ -108,6 +120,7 @@ func NewCmdDiagnoseInfo(tkcContext *config.TkcContext, streams genericclioptions
 	cmd.Flags().StringVar(&o.logPath, "path", "", "The log path to dump.")
 	cmd.Flags().DurationVar(&o.since, "since", time.Duration(1)*time.Hour, "Return logs newer than a relative duration like 1m, or 3h.")
 	cmd.Flags().Int64Var(&o.byteReadLimit, "byteReadLimit", 500000, "The maximum number of bytes dump log.")
+	cmd.Flags().StringVar(&o.operatorNamespace, "operator-namespace", "tidb-admin", "The namespace in which tidb-operator is deployed, its logs are dumped too.")
 	cmdutil.CheckErr(cmd.MarkFlagRequired("path"))
 	return cmd
 }
@@ -157,13 +170,28 @@ func (o *diagnoseInfoOptions) Complete(tkcContext *config.TkcContext, cmd *cobra
 
 	o.listOptions = metav1.ListOptions{
 		LabelSelector: fmt.Sprintf("%s=%s,%s in (%s)", label.InstanceLabelKey, o.tidbClusterName, label.ComponentLabelKey,
-			strings.Join([]string{label.TiDBLabelVal, label.TiKVLabelVal, label.PDLabelVal}, ",")),
+			strings.Join([]string{label.TiDBLabelVal, label.TiKVLabelVal, label.PDLabelVal, label.TiFlashLabelVal,
+				label.TiCDCLabelVal, label.PumpLabelVal, label.DiscoveryLabelVal}, ",")),
 	}
 
 	return nil
 }
 
 func (o *diagnoseInfoOptions) Run() error {
+	if err := o.dump(); err != nil {
+		return err
+	}
+
+	bundle := filepath.Clean(o.logPath) + ".tar.gz"
+	if err := archive(o.logPath, bundle); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(o.Out, "diagnostic information of tidb cluster %s/%s is dumped to %s\n", o.namespace, o.tidbClusterName, bundle)
+	return err
+}
+
+// dump dumps the diagnostic information of the tidb cluster into the log path.
+func (o *diagnoseInfoOptions) dump() error {
 	if err := createPathIfNotExist(o.logPath); err != nil {
 		return err
 	}
@@ -215,6 +243,22 @@ func (o *diagnoseInfoOptions) Run() error {
 		return err
 	}
 
+	// dump recent events of the objects of a particular tidb cluster.
+	if err := NewEventDumper(o.kubeCli, tc, o.since, o.printer).Dump(o.logPath, rWriter); err != nil {
+		return err
+	}
+
+	// dump members and stores of the pd cluster, failures are recorded in the dumped files
+	// as pd may be unavailable when the tidb cluster is being diagnosed.
+	if err := NewPDDumper(o.kubeCli, tc).Dump(o.logPath, rWriter); err != nil {
+		return err
+	}
+
+	// dump detail information and logs of tidb-operator pods.
+	if err := o.dumpOperator(); err != nil {
+		return err
+	}
+
 	podList, err := o.kubeCli.CoreV1().Pods(o.namespace).List(o.listOptions)
 	if err != nil {
 		return err
@@ -241,6 +285,29 @@ func (o *diagnoseInfoOptions) Run() error {
 	return o.printer.PrintObj(&pods, rWriter)
 }
 
+// dumpOperator dumps detail information and logs of tidb-operator pods.
+func (o *diagnoseInfoOptions) dumpOperator() error {
+	podList, err := o.kubeCli.CoreV1().Pods(o.operatorNamespace).List(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s,%s in (%s)", label.NameLabelKey, "tidb-operator", label.ComponentLabelKey,
+			strings.Join([]string{"controller-manager", "scheduler", "advanced-statefulset-controller", "admission-webhook"}, ",")),
+	})
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(o.logPath, "operator")
+	if err := createPathIfNotExist(path); err != nil {
+		return err
+	}
+
+	for _, pod := range podList.Items {
+		if err := NewPodDumper(o.kubeCli, pod, int64(o.since.Seconds()), o.byteReadLimit).Dump(path, ioutil.Discard); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // tidbClusterDumper generates information about a tidbclusters object.
 type tidbClusterDumper struct {
 	tc      *v1alpha1.TidbCluster
@@ -278,7 +345,7 @@ func (d *tidbClusterDumper) Dump(logPath string, resourceWriter io.Writer) error
 		return err
 	}
 
-	return writeString(logFile, string(data))
+	return writeString(logFile, redact(string(data)))
 }
 
 // tidbClusterStatefulDumper generates information about a statefulset by a specify tidbcluster.
@@ -317,8 +384,15 @@ func (d *tidbClusterStatefulDumper) Dump(logPath string, resourceWriter io.Write
 		controller.TiDBMemberName(d.tc.Name),
 		controller.TiKVMemberName(d.tc.Name),
 		controller.PDMemberName(d.tc.Name),
+		controller.TiFlashMemberName(d.tc.Name),
+		controller.TiCDCMemberName(d.tc.Name),
+		controller.PumpMemberName(d.tc.Name),
 	} {
 		ps, err := d.kubeCli.AppsV1().StatefulSets(d.tc.Namespace).Get(sn, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			// the component is not deployed
+			continue
+		}
 		if err != nil {
 			return err
 		}
@@ -333,7 +407,7 @@ func (d *tidbClusterStatefulDumper) Dump(logPath string, resourceWriter io.Write
 			return err
 		}
 
-		if err = writeString(logFile, string(body)); err != nil {
+		if err = writeString(logFile, redact(string(body))); err != nil {
 			return err
 		}
 
@@ -397,7 +471,7 @@ func (d *pvcDumper) Dump(logPath string, resourceWriter io.Writer) error {
 		if err != nil {
 			return err
 		}
-		if err = writeString(logFile, string(body)); err != nil {
+		if err = writeString(logFile, redact(string(body))); err != nil {
 			return err
 		}
 
@@ -458,7 +532,7 @@ func (d *svcDumper) Dump(logPath string, resourceWriter io.Writer) error {
 		if err != nil {
 			return err
 		}
-		if err = writeString(logFile, string(body)); err != nil {
+		if err = writeString(logFile, redact(string(body))); err != nil {
 			return err
 		}
 
@@ -519,7 +593,7 @@ func (d *configMapDumper) Dump(logPath string, resourceWriter io.Writer) error {
 		if err != nil {
 			return err
 		}
-		if err = writeString(logFile, string(body)); err != nil {
+		if err = writeString(logFile, redact(string(body))); err != nil {
 			return err
 		}
 
@@ -589,7 +663,7 @@ func (d *podDumper) DumpDetail(logPath string) error {
 		return err
 	}
 
-	return writeString(logFile, string(body))
+	return writeString(logFile, redact(string(body)))
 }
 
 // Dump dumps the logs for the last terminated container and current running container. If info about the container is not available then a specific
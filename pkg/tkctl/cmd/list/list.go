@@ -14,10 +14,18 @@
 package list
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
 	"github.com/pingcap/tidb-operator/pkg/tkctl/config"
 	"github.com/pingcap/tidb-operator/pkg/tkctl/readable"
 	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/klog"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
@@ -26,10 +34,12 @@ import (
 
 const (
 	listLongDesc = `
-		List all tidb clusters.
+		List all tidb clusters and dm clusters.
 
-		Prints a table of the general information about each tidb cluster. By specifying
-		namespace or label-selectors, you can filter clusters.
+		Prints a table of the general information about each cluster, including the version,
+		the ready status of each component, the last time the operator synced the cluster
+		and the abnormal conditions. By specifying namespace, label-selectors, version
+		constraints or --unhealthy, you can filter clusters.
 `
 	listExample = `
 		# list all clusters and sync to local config
@@ -38,15 +48,35 @@ const (
 		# filter by namespace
 		tkctl list --namespace=foo
 
-		# get tidb cluster in all namespaces
-		tkctl list -A
+		# get clusters in all namespaces, the least recently synced first
+		tkctl list -A --sort-by=sync
+
+		# list the unhealthy tidb clusters older than v4.0.0 in all namespaces
+		tkctl list -A --kind=tidbcluster --unhealthy --version="<v4.0.0"
 `
+
+	kindAll         = "all"
+	kindTidbCluster = "tidbcluster"
+	kindDMCluster   = "dmcluster"
+
+	sortByName      = "name"
+	sortByNamespace = "namespace"
+	sortByAge       = "age"
+	sortByVersion   = "version"
+	sortBySync      = "sync"
 )
 
 // ListOptions contains the input to the list command.
 type ListOptions struct {
 	AllNamespaces bool
 	Namespace     string
+	Kind          string
+	Selector      string
+	Version       string
+	Unhealthy     bool
+	SortBy        string
+
+	TcCli *versioned.Clientset
 
 	PrintFlags *readable.PrintFlags
 
@@ -56,21 +86,24 @@ type ListOptions struct {
 // NewListOptions returns a ListOptions.
 func NewListOptions(streams genericclioptions.IOStreams) *ListOptions {
 	return &ListOptions{
+		Kind:       kindAll,
+		SortBy:     sortByNamespace,
 		PrintFlags: readable.NewPrintFlags(),
 
 		IOStreams: streams,
 	}
 }
 
-// NewCmdList creates the list command which lists all the tidb cluster
-// in the specified kubernetes cluster and sync to local config file.
-// List only searches for pingcap.com/tidbclusters custom resources.
+// NewCmdList creates the list command which lists all the tidb clusters and
+// dm clusters in the specified kubernetes cluster and sync to local config file.
+// List only searches for pingcap.com/tidbclusters and pingcap.com/dmclusters
+// custom resources.
 func NewCmdList(tkcContext *config.TkcContext, streams genericclioptions.IOStreams) *cobra.Command {
 	options := NewListOptions(streams)
 
 	cmd := &cobra.Command{
 		Use:     "list",
-		Short:   "list all tidb clusters",
+		Short:   "list all tidb clusters and dm clusters",
 		Long:    listLongDesc,
 		Example: listExample,
 		Run: func(cmd *cobra.Command, args []string) {
@@ -83,11 +116,32 @@ func NewCmdList(tkcContext *config.TkcContext, streams genericclioptions.IOStrea
 	options.PrintFlags.AddFlags(cmd)
 
 	cmd.Flags().BoolVarP(&options.AllNamespaces, "all-namespaces", "A", false,
-		"whether list tidb clusters in all namespaces")
+		"whether list clusters in all namespaces")
+	cmd.Flags().StringVar(&options.Kind, "kind", options.Kind,
+		"the kind of clusters to list, one of all|tidbcluster|dmcluster")
+	cmd.Flags().StringVarP(&options.Selector, "selector", "l", options.Selector,
+		"label selector to filter clusters on")
+	cmd.Flags().StringVar(&options.Version, "version", options.Version,
+		"version constraint to filter clusters on, e.g. \"<v4.0.0\" or \"v4.0.x\"")
+	cmd.Flags().BoolVar(&options.Unhealthy, "unhealthy", options.Unhealthy,
+		"only list clusters that are not ready or have abnormal conditions")
+	cmd.Flags().StringVar(&options.SortBy, "sort-by", options.SortBy,
+		"sort clusters by one of name|namespace|age|version|sync")
 	return cmd
 }
 
 func (o *ListOptions) Complete(tkcContext *config.TkcContext, cmd *cobra.Command, args []string) error {
+	switch o.Kind {
+	case kindAll, kindTidbCluster, kindDMCluster:
+	default:
+		return cmdutil.UsageErrorf(cmd, "unknown kind %q", o.Kind)
+	}
+	switch o.SortBy {
+	case sortByName, sortByNamespace, sortByAge, sortByVersion, sortBySync:
+	default:
+		return cmdutil.UsageErrorf(cmd, "unknown sort key %q", o.SortBy)
+	}
+
 	clientConfig, err := tkcContext.ToTkcClientConfig()
 	if err != nil {
 		return err
@@ -97,45 +151,220 @@ func (o *ListOptions) Complete(tkcContext *config.TkcContext, cmd *cobra.Command
 		return err
 	}
 	o.Namespace = namespace
-	return nil
-}
+	if o.AllNamespaces {
+		o.Namespace = metav1.NamespaceAll
+	}
 
-func (o *ListOptions) Run(tkcContext *config.TkcContext, cmd *cobra.Command, args []string) error {
-	r := tkcContext.TkcBuilder().
-		NamespaceParam(o.Namespace).DefaultNamespace().AllNamespaces(o.AllNamespaces).
-		SingleResourceType().ResourceTypes("tidbcluster").
-		SelectAllParam(true).
-		Unstructured().
-		ContinueOnError().
-		Latest().
-		Flatten().
-		Do()
-
-	if err := r.Err(); err != nil {
+	restConfig, err := clientConfig.RestConfig()
+	if err != nil {
 		return err
 	}
+	tcCli, err := versioned.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	o.TcCli = tcCli
+	return nil
+}
 
-	printer, err := o.PrintFlags.ToPrinter(false, o.AllNamespaces)
+func (o *ListOptions) Run(tkcContext *config.TkcContext, cmd *cobra.Command, args []string) error {
+	filter, err := newClusterFilter(o.Version, o.Unhealthy)
 	if err != nil {
 		return err
 	}
 
-	infos, err := r.Infos()
+	printer, err := o.PrintFlags.ToPrinter(false, o.AllNamespaces)
 	if err != nil {
 		return err
 	}
 
+	listOpts := metav1.ListOptions{LabelSelector: o.Selector}
 	w := kubeprinters.GetNewTabWriter(o.Out)
-	for _, info := range infos {
-		internalObj, err := v1alpha1.Scheme.ConvertToVersion(info.Object, v1alpha1.SchemeGroupVersion)
+	defer w.Flush()
+
+	printed := false
+	if o.Kind == kindAll || o.Kind == kindTidbCluster {
+		tcs, err := o.TcCli.PingcapV1alpha1().TidbClusters(o.Namespace).List(listOpts)
 		if err != nil {
-			klog.V(1).Info(err)
-			printer.PrintObj(info.Object, w)
-		} else {
-			printer.PrintObj(internalObj, w)
+			return err
+		}
+		var items []v1alpha1.TidbCluster
+		for i := range tcs.Items {
+			if filter.matchTidbCluster(&tcs.Items[i]) {
+				items = append(items, tcs.Items[i])
+			}
+		}
+		sortTidbClusters(items, o.SortBy)
+		if len(items) > 0 || o.Kind == kindTidbCluster {
+			list := &v1alpha1.TidbClusterList{Items: items}
+			list.SetGroupVersionKind(v1alpha1.SchemeGroupVersion.WithKind("TidbClusterList"))
+			for i := range list.Items {
+				list.Items[i].SetGroupVersionKind(v1alpha1.SchemeGroupVersion.WithKind("TidbCluster"))
+			}
+			if err := printer.PrintObj(list, w); err != nil {
+				return err
+			}
+			printed = true
 		}
 	}
-	w.Flush()
 
+	if o.Kind == kindAll || o.Kind == kindDMCluster {
+		dcs, err := o.TcCli.PingcapV1alpha1().DMClusters(o.Namespace).List(listOpts)
+		if apierrors.IsNotFound(err) && o.Kind == kindAll {
+			// the DMCluster CRD is not installed
+			klog.V(1).Info(err)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var items []v1alpha1.DMCluster
+		for i := range dcs.Items {
+			if filter.matchDMCluster(&dcs.Items[i]) {
+				items = append(items, dcs.Items[i])
+			}
+		}
+		sortDMClusters(items, o.SortBy)
+		if len(items) > 0 || o.Kind == kindDMCluster {
+			if printed {
+				fmt.Fprintln(w)
+			}
+			list := &v1alpha1.DMClusterList{Items: items}
+			list.SetGroupVersionKind(v1alpha1.SchemeGroupVersion.WithKind("DMClusterList"))
+			for i := range list.Items {
+				list.Items[i].SetGroupVersionKind(v1alpha1.SchemeGroupVersion.WithKind("DMCluster"))
+			}
+			return printer.PrintObj(list, w)
+		}
+	}
 	return nil
 }
+
+// clusterFilter filters clusters by the version constraint and the health.
+type clusterFilter struct {
+	constraint *semver.Constraints
+	unhealthy  bool
+}
+
+func newClusterFilter(version string, unhealthy bool) (*clusterFilter, error) {
+	f := &clusterFilter{unhealthy: unhealthy}
+	if version != "" {
+		c, err := semver.NewConstraint(version)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version constraint %q: %v", version, err)
+		}
+		f.constraint = c
+	}
+	return f, nil
+}
+
+func (f *clusterFilter) matchTidbCluster(tc *v1alpha1.TidbCluster) bool {
+	if f.unhealthy && len(readable.TidbClusterAlerts(tc)) == 0 {
+		return false
+	}
+	return f.matchVersion(tc.Spec.Version)
+}
+
+func (f *clusterFilter) matchDMCluster(dc *v1alpha1.DMCluster) bool {
+	if f.unhealthy && len(readable.DMClusterAlerts(dc)) == 0 {
+		return false
+	}
+	return f.matchVersion(dc.Spec.Version)
+}
+
+func (f *clusterFilter) matchVersion(version string) bool {
+	if f.constraint == nil {
+		return true
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		// clusters running nightly or custom builds never satisfy a constraint
+		return false
+	}
+	return f.constraint.Check(v)
+}
+
+// clusterKey holds the fields clusters are sorted by.
+type clusterKey struct {
+	namespace string
+	name      string
+	created   metav1.Time
+	version   string
+	synced    metav1.Time
+}
+
+func sortTidbClusters(tcs []v1alpha1.TidbCluster, sortBy string) {
+	sort.SliceStable(tcs, func(i, j int) bool {
+		return lessCluster(tidbClusterKey(&tcs[i]), tidbClusterKey(&tcs[j]), sortBy)
+	})
+}
+
+func sortDMClusters(dcs []v1alpha1.DMCluster, sortBy string) {
+	sort.SliceStable(dcs, func(i, j int) bool {
+		return lessCluster(dmClusterKey(&dcs[i]), dmClusterKey(&dcs[j]), sortBy)
+	})
+}
+
+func tidbClusterKey(tc *v1alpha1.TidbCluster) clusterKey {
+	return clusterKey{
+		namespace: tc.Namespace,
+		name:      tc.Name,
+		created:   tc.CreationTimestamp,
+		version:   tc.Spec.Version,
+		synced:    readable.TidbClusterLastSyncTime(tc),
+	}
+}
+
+func dmClusterKey(dc *v1alpha1.DMCluster) clusterKey {
+	return clusterKey{
+		namespace: dc.Namespace,
+		name:      dc.Name,
+		created:   dc.CreationTimestamp,
+		version:   dc.Spec.Version,
+		synced:    readable.DMClusterLastSyncTime(dc),
+	}
+}
+
+// lessCluster orders the clusters by the sort key and then by namespace and name.
+// Age sorts the oldest first, version the lowest first and sync the least recently
+// synced first so that the clusters needing attention come at the top.
+func lessCluster(a, b clusterKey, sortBy string) bool {
+	switch sortBy {
+	case sortByName:
+		if a.name != b.name {
+			return a.name < b.name
+		}
+	case sortByAge:
+		if !a.created.Equal(&b.created) {
+			return a.created.Before(&b.created)
+		}
+	case sortByVersion:
+		if c := compareVersion(a.version, b.version); c != 0 {
+			return c < 0
+		}
+	case sortBySync:
+		if !a.synced.Equal(&b.synced) {
+			return a.synced.Before(&b.synced)
+		}
+	}
+	if a.namespace != b.namespace {
+		return a.namespace < b.namespace
+	}
+	return a.name < b.name
+}
+
+// compareVersion compares semantic versions, versions that cannot be parsed
+// are compared literally and sorted after the semantic ones.
+func compareVersion(a, b string) int {
+	va, errA := semver.NewVersion(a)
+	vb, errB := semver.NewVersion(b)
+	switch {
+	case errA == nil && errB == nil:
+		return va.Compare(vb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
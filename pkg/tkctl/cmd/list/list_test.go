@@ -0,0 +1,92 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package list
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTidbCluster(ns, name, version string, synced time.Time, ready corev1.ConditionStatus) v1alpha1.TidbCluster {
+	return v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+		Spec:       v1alpha1.TidbClusterSpec{Version: version},
+		Status: v1alpha1.TidbClusterStatus{
+			Conditions: []v1alpha1.TidbClusterCondition{
+				{
+					Type:           v1alpha1.TidbClusterReady,
+					Status:         ready,
+					LastUpdateTime: metav1.NewTime(synced),
+				},
+			},
+		},
+	}
+}
+
+func names(tcs []v1alpha1.TidbCluster) []string {
+	var ns []string
+	for _, tc := range tcs {
+		ns = append(ns, tc.Namespace+"/"+tc.Name)
+	}
+	return ns
+}
+
+func TestSortTidbClusters(t *testing.T) {
+	g := NewGomegaWithT(t)
+	now := time.Now()
+	tcs := []v1alpha1.TidbCluster{
+		newTidbCluster("b", "basic", "v4.0.8", now, corev1.ConditionTrue),
+		newTidbCluster("a", "demo", "nightly", now.Add(-time.Hour), corev1.ConditionTrue),
+		newTidbCluster("a", "basic", "v3.0.20", now.Add(-time.Minute), corev1.ConditionFalse),
+	}
+
+	sortTidbClusters(tcs, sortByNamespace)
+	g.Expect(names(tcs)).To(Equal([]string{"a/basic", "a/demo", "b/basic"}))
+
+	sortTidbClusters(tcs, sortByName)
+	g.Expect(names(tcs)).To(Equal([]string{"a/basic", "b/basic", "a/demo"}))
+
+	sortTidbClusters(tcs, sortByVersion)
+	g.Expect(names(tcs)).To(Equal([]string{"a/basic", "b/basic", "a/demo"}))
+
+	sortTidbClusters(tcs, sortBySync)
+	g.Expect(names(tcs)).To(Equal([]string{"a/demo", "a/basic", "b/basic"}))
+}
+
+func TestClusterFilter(t *testing.T) {
+	g := NewGomegaWithT(t)
+	now := time.Now()
+	healthy := newTidbCluster("a", "healthy", "v4.0.8", now, corev1.ConditionTrue)
+	unhealthy := newTidbCluster("a", "unhealthy", "v3.0.20", now, corev1.ConditionFalse)
+	nightly := newTidbCluster("a", "nightly", "nightly", now, corev1.ConditionFalse)
+
+	f, err := newClusterFilter("", true)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(f.matchTidbCluster(&healthy)).To(BeFalse())
+	g.Expect(f.matchTidbCluster(&unhealthy)).To(BeTrue())
+
+	f, err = newClusterFilter("<v4.0.0", false)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(f.matchTidbCluster(&healthy)).To(BeFalse())
+	g.Expect(f.matchTidbCluster(&unhealthy)).To(BeTrue())
+	g.Expect(f.matchTidbCluster(&nightly)).To(BeFalse())
+
+	_, err = newClusterFilter("not a version", false)
+	g.Expect(err).To(HaveOccurred())
+}
@@ -15,21 +15,171 @@ package pdctl
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
+	"os/exec"
 
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
+	"github.com/pingcap/tidb-operator/pkg/label"
 	"github.com/pingcap/tidb-operator/pkg/tkctl/config"
+	tkctlUtil "github.com/pingcap/tidb-operator/pkg/tkctl/util"
 	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 )
 
-// TODO: implementation
-// NewCmdPdctl creates the pdctl subcommand
+const (
+	pdctlLongDesc = `
+		Run pd-ctl against the PD of a tidb cluster.
+
+		A local port is forwarded to a running PD pod and pd-ctl is run with the forwarded
+		address. If TLS is enabled between the components of the tidb cluster, the client
+		certificate in the cluster client secret is passed to pd-ctl. The arguments after
+		'--' are passed to pd-ctl as is.
+
+		pd-ctl is looked up in PATH unless its path is given by --binary.
+
+		You may omit --tidbcluster option by running 'tkc use <clusterName>'.
+`
+	pdctlExample = `
+		# show the stores of the current tidb cluster (set by tkctl use)
+		tkctl pd-ctl -- store
+
+		# run pd-ctl interactively against the PD of a specified tidb cluster
+		tkctl pd-ctl -t demo-cluster -- -i
+`
+	pdctlUsage = `expected 'pd-ctl -t CLUSTER_NAME -- [ARGS...]' for the pd-ctl command or
+using 'tkctl use' to set tidb cluster first.`
+
+	pdClientPort = 2379
+)
+
+// PdctlOptions contains the input to the pd-ctl command.
+type PdctlOptions struct {
+	TidbClusterName string
+	Namespace       string
+
+	Binary string
+	Args   []string
+
+	TcCli      *versioned.Clientset
+	KubeCli    *kubernetes.Clientset
+	RestConfig *restclient.Config
+
+	genericclioptions.IOStreams
+}
+
+// NewPdctlOptions returns a PdctlOptions
+func NewPdctlOptions(streams genericclioptions.IOStreams) *PdctlOptions {
+	return &PdctlOptions{
+		Binary: "pd-ctl",
+
+		IOStreams: streams,
+	}
+}
+
+// NewCmdPdctl creates the pd-ctl command which runs pd-ctl against the PD of a tidb cluster
 func NewCmdPdctl(tkcContext *config.TkcContext, streams genericclioptions.IOStreams) *cobra.Command {
-	return &cobra.Command{
-		Use:   "pdctl",
-		Short: "Not implemented",
-		Run: func(_ *cobra.Command, args []string) {
-			fmt.Fprint(os.Stdout, "not implemented")
+	o := NewPdctlOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:     "pd-ctl [-- ARGS...]",
+		Aliases: []string{"pdctl"},
+		Short:   "Run pd-ctl against the PD of a tidb cluster",
+		Long:    pdctlLongDesc,
+		Example: pdctlExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(tkcContext, cmd, args))
+			cmdutil.CheckErr(o.Run())
 		},
 	}
+	cmd.Flags().StringVar(&o.Binary, "binary", o.Binary, "The path of pd-ctl.")
+	return cmd
+}
+
+func (o *PdctlOptions) Complete(tkcContext *config.TkcContext, cmd *cobra.Command, args []string) error {
+	o.Args = args
+
+	clientConfig, err := tkcContext.ToTkcClientConfig()
+	if err != nil {
+		return err
+	}
+
+	if tidbClusterName, ok := clientConfig.TidbClusterName(); ok {
+		o.TidbClusterName = tidbClusterName
+	} else {
+		return cmdutil.UsageErrorf(cmd, pdctlUsage)
+	}
+
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return err
+	}
+	o.Namespace = namespace
+
+	restConfig, err := clientConfig.RestConfig()
+	if err != nil {
+		return err
+	}
+	o.RestConfig = restConfig
+	tcCli, err := versioned.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	o.TcCli = tcCli
+	kubeCli, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	o.KubeCli = kubeCli
+
+	return nil
+}
+
+func (o *PdctlOptions) Run() error {
+	binary, err := exec.LookPath(o.Binary)
+	if err != nil {
+		return fmt.Errorf("pd-ctl is not found, install it or set its path by --binary: %v", err)
+	}
+
+	tc, err := o.TcCli.PingcapV1alpha1().
+		TidbClusters(o.Namespace).
+		Get(o.TidbClusterName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	pod, err := tkctlUtil.GetRunningPod(o.KubeCli, tc, label.PDLabelVal)
+	if err != nil {
+		return err
+	}
+	port, stop, err := tkctlUtil.ForwardPort(o.KubeCli, o.RestConfig, pod, pdClientPort, o.ErrOut)
+	if err != nil {
+		return err
+	}
+	defer close(stop)
+
+	var tlsArgs []string
+	if tc.IsTLSClusterEnabled() {
+		dir, err := ioutil.TempDir("", "tkctl-pd-ctl")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		files, err := tkctlUtil.WriteClusterClientTLS(o.KubeCli, tc, dir)
+		if err != nil {
+			return err
+		}
+		tlsArgs = []string{"--cacert", files.CA, "--cert", files.Cert, "--key", files.Key}
+	}
+
+	ctlArgs := append([]string{"-u", fmt.Sprintf("%s://127.0.0.1:%d", tc.Scheme(), port)}, tlsArgs...)
+	ctl := exec.Command(binary, append(ctlArgs, o.Args...)...)
+	ctl.Stdin = o.In
+	ctl.Stdout = o.Out
+	ctl.Stderr = o.ErrOut
+	return ctl.Run()
 }
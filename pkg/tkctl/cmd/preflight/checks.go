@@ -0,0 +1,233 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	tkctlUtil "github.com/pingcap/tidb-operator/pkg/tkctl/util"
+)
+
+// CheckResult is the result of a preflight check
+type CheckResult string
+
+const (
+	// CheckPass means the check passed
+	CheckPass CheckResult = "PASS"
+	// CheckWarn means the check can not be completed or found a risk which doesn't block the upgrade
+	CheckWarn CheckResult = "WARN"
+	// CheckFail means the check failed and the upgrade should not be started
+	CheckFail CheckResult = "FAIL"
+)
+
+// Check is an item of the preflight checklist
+type Check struct {
+	Name    string
+	Result  CheckResult
+	Message string
+}
+
+func pass(name, format string, args ...interface{}) Check {
+	return Check{Name: name, Result: CheckPass, Message: fmt.Sprintf(format, args...)}
+}
+
+func warn(name, format string, args ...interface{}) Check {
+	return Check{Name: name, Result: CheckWarn, Message: fmt.Sprintf(format, args...)}
+}
+
+func fail(name, format string, args ...interface{}) Check {
+	return Check{Name: name, Result: CheckFail, Message: fmt.Sprintf(format, args...)}
+}
+
+// CheckVersion checks whether the cluster can be upgraded from the current version to the target version,
+// downgrades and jumps over a major version are not supported.
+func CheckVersion(current, target string) Check {
+	const name = "version"
+	to, err := semver.NewVersion(target)
+	if err != nil {
+		return fail(name, "target version %q is not a semantic version: %v", target, err)
+	}
+	from, err := semver.NewVersion(current)
+	if err != nil {
+		return warn(name, "current version %q is not a semantic version, the compatibility can not be checked", current)
+	}
+	switch {
+	case to.Equal(from):
+		return fail(name, "the cluster is already at %s", current)
+	case to.LessThan(from):
+		return fail(name, "downgrading from %s to %s is not supported", current, target)
+	case to.Major()-from.Major() > 1:
+		return fail(name, "upgrading from %s to %s skips major versions, upgrade to v%d.x first", current, target, from.Major()+1)
+	case to.Prerelease() != "":
+		return warn(name, "target version %s is a pre-release", target)
+	}
+	return pass(name, "upgrading from %s to %s is supported", current, target)
+}
+
+// checkPhases checks that no component of the cluster is being upgraded or scaled.
+func checkPhases(tc *v1alpha1.TidbCluster) Check {
+	const name = "cluster-phase"
+	var busy []string
+	for _, c := range []struct {
+		component string
+		phase     v1alpha1.MemberPhase
+	}{
+		{"pd", tc.Status.PD.Phase},
+		{"tikv", tc.Status.TiKV.Phase},
+		{"tiflash", tc.Status.TiFlash.Phase},
+		{"tidb", tc.Status.TiDB.Phase},
+		{"pump", tc.Status.Pump.Phase},
+		{"ticdc", tc.Status.TiCDC.Phase},
+	} {
+		if c.phase == v1alpha1.UpgradePhase || c.phase == v1alpha1.ScalePhase {
+			busy = append(busy, fmt.Sprintf("%s is in %s phase", c.component, c.phase))
+		}
+	}
+	if len(busy) > 0 {
+		return fail(name, "%s", strings.Join(busy, ", "))
+	}
+	return pass(name, "no component is being upgraded or scaled")
+}
+
+// checkPDHealth checks that all the PD members are healthy.
+func checkPDHealth(healths []pdapi.MemberHealth) Check {
+	const name = "pd-health"
+	if len(healths) == 0 {
+		return fail(name, "no pd member is found")
+	}
+	var unhealthy []string
+	for _, h := range healths {
+		if !h.Health {
+			unhealthy = append(unhealthy, h.Name)
+		}
+	}
+	if len(unhealthy) > 0 {
+		sort.Strings(unhealthy)
+		return fail(name, "unhealthy pd members: %s", strings.Join(unhealthy, ", "))
+	}
+	return pass(name, "all %d pd members are healthy", len(healths))
+}
+
+// checkStoreHealth checks that all the TiKV and TiFlash stores are up.
+func checkStoreHealth(stores *pdapi.StoresInfo) Check {
+	const name = "store-health"
+	if stores == nil || len(stores.Stores) == 0 {
+		return fail(name, "no store is found")
+	}
+	var abnormal []string
+	for _, s := range stores.Stores {
+		if s.Store == nil || s.Store.Store == nil {
+			continue
+		}
+		if s.Store.StateName != v1alpha1.TiKVStateUp {
+			abnormal = append(abnormal, fmt.Sprintf("%s(%s)", s.Store.Address, s.Store.StateName))
+		}
+	}
+	if len(abnormal) > 0 {
+		sort.Strings(abnormal)
+		return fail(name, "stores not up: %s", strings.Join(abnormal, ", "))
+	}
+	return pass(name, "all %d stores are up", len(stores.Stores))
+}
+
+// checkDiskHeadroom checks that every store has at least minAvailableRatio of its capacity available,
+// as the stores are restarted one by one and the regions are rebalanced during the upgrade.
+func checkDiskHeadroom(stores *pdapi.StoresInfo, minAvailableRatio float64) Check {
+	const name = "disk-headroom"
+	if stores == nil || len(stores.Stores) == 0 {
+		return fail(name, "no store is found")
+	}
+	var short []string
+	minRatio := 1.0
+	for _, s := range stores.Stores {
+		if s.Store == nil || s.Store.Store == nil || s.Status == nil || s.Status.Capacity == 0 {
+			continue
+		}
+		ratio := float64(s.Status.Available) / float64(s.Status.Capacity)
+		if ratio < minRatio {
+			minRatio = ratio
+		}
+		if ratio < minAvailableRatio {
+			short = append(short, fmt.Sprintf("%s(%.1f%%)", s.Store.Address, ratio*100))
+		}
+	}
+	if len(short) > 0 {
+		sort.Strings(short)
+		return fail(name, "stores with less than %.1f%% disk available: %s", minAvailableRatio*100, strings.Join(short, ", "))
+	}
+	return pass(name, "at least %.1f%% disk is available on every store", minRatio*100)
+}
+
+// finishedDDLStates are the states of the DDL jobs which are finished
+var finishedDDLStates = map[string]bool{
+	"synced":        true,
+	"cancelled":     true,
+	"rollback done": true,
+}
+
+// checkPendingDDLs checks that there is no DDL job running or queueing, the states are
+// the STATE column returned by `ADMIN SHOW DDL JOBS`.
+func checkPendingDDLs(states []string) Check {
+	const name = "pending-ddl"
+	pending := 0
+	for _, state := range states {
+		if !finishedDDLStates[strings.ToLower(state)] {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fail(name, "%d ddl jobs are not finished, wait for them before upgrading", pending)
+	}
+	return pass(name, "no ddl job is pending")
+}
+
+// checkBackupFreshness checks that a backup of the cluster has completed within maxAge.
+func checkBackupFreshness(tc *v1alpha1.TidbCluster, backups []v1alpha1.Backup, maxAge time.Duration, now time.Time) Check {
+	const name = "backup-freshness"
+	var latest *v1alpha1.Backup
+	for i := range backups {
+		backup := &backups[i]
+		if !tkctlUtil.IsBackupOf(backup, tc) || !v1alpha1.IsBackupComplete(backup) {
+			continue
+		}
+		if latest == nil || backup.Status.TimeCompleted.After(latest.Status.TimeCompleted.Time) {
+			latest = backup
+		}
+	}
+	if latest == nil {
+		return fail(name, "no completed backup of the cluster is found")
+	}
+	age := now.Sub(latest.Status.TimeCompleted.Time).Round(time.Minute)
+	if age > maxAge {
+		return fail(name, "the latest backup %s completed %s ago, older than %s", latest.Name, age, maxAge)
+	}
+	return pass(name, "the latest backup %s completed %s ago", latest.Name, age)
+}
+
+// failed returns the number of the failed checks.
+func failed(checks []Check) int {
+	n := 0
+	for _, c := range checks {
+		if c.Result == CheckFail {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,125 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/tikv/pd/pkg/typeutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckVersion(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		current string
+		target  string
+		result  CheckResult
+	}{
+		{"v4.0.8", "v4.0.9", CheckPass},
+		{"v4.0.8", "v5.0.0", CheckPass},
+		{"v3.0.20", "v5.0.0", CheckFail},
+		{"v4.0.9", "v4.0.8", CheckFail},
+		{"v4.0.9", "v4.0.9", CheckFail},
+		{"v4.0.9", "v5.0.0-rc", CheckWarn},
+		{"nightly", "v4.0.9", CheckWarn},
+		{"v4.0.9", "latest", CheckFail},
+	}
+	for _, tt := range tests {
+		g.Expect(CheckVersion(tt.current, tt.target).Result).To(Equal(tt.result), "%s -> %s", tt.current, tt.target)
+	}
+}
+
+func TestCheckStores(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	newStore := func(addr, state string, capacity, available uint64) *pdapi.StoreInfo {
+		return &pdapi.StoreInfo{
+			Store: &pdapi.MetaStore{
+				Store:     &metapb.Store{Address: addr},
+				StateName: state,
+			},
+			Status: &pdapi.StoreStatus{
+				Capacity:  typeutil.ByteSize(capacity),
+				Available: typeutil.ByteSize(available),
+			},
+		}
+	}
+
+	stores := &pdapi.StoresInfo{Stores: []*pdapi.StoreInfo{
+		newStore("tikv-0", v1alpha1.TiKVStateUp, 100, 50),
+		newStore("tikv-1", v1alpha1.TiKVStateUp, 100, 30),
+	}}
+	g.Expect(checkStoreHealth(stores).Result).To(Equal(CheckPass))
+	g.Expect(checkDiskHeadroom(stores, 0.2).Result).To(Equal(CheckPass))
+
+	stores.Stores = append(stores.Stores, newStore("tikv-2", v1alpha1.TiKVStateDown, 100, 10))
+	health := checkStoreHealth(stores)
+	g.Expect(health.Result).To(Equal(CheckFail))
+	g.Expect(health.Message).To(ContainSubstring("tikv-2(Down)"))
+	headroom := checkDiskHeadroom(stores, 0.2)
+	g.Expect(headroom.Result).To(Equal(CheckFail))
+	g.Expect(headroom.Message).To(ContainSubstring("tikv-2(10.0%)"))
+
+	g.Expect(checkStoreHealth(&pdapi.StoresInfo{}).Result).To(Equal(CheckFail))
+}
+
+func TestCheckPendingDDLs(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(checkPendingDDLs(nil).Result).To(Equal(CheckPass))
+	g.Expect(checkPendingDDLs([]string{"synced", "cancelled", "rollback done"}).Result).To(Equal(CheckPass))
+	g.Expect(checkPendingDDLs([]string{"running", "queueing", "synced"}).Message).To(ContainSubstring("2 ddl jobs"))
+}
+
+func TestCheckBackupFreshness(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	now := time.Now()
+	tc := &v1alpha1.TidbCluster{ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "ns"}}
+	newBackup := func(name string, br *v1alpha1.BRConfig, from *v1alpha1.TiDBAccessConfig, completed bool, age time.Duration) v1alpha1.Backup {
+		backup := v1alpha1.Backup{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+			Spec:       v1alpha1.BackupSpec{BR: br, From: from},
+		}
+		if completed {
+			backup.Status.TimeCompleted = metav1.NewTime(now.Add(-age))
+			backup.Status.Conditions = []v1alpha1.BackupCondition{{Type: v1alpha1.BackupComplete, Status: corev1.ConditionTrue}}
+		}
+		return backup
+	}
+
+	g.Expect(checkBackupFreshness(tc, nil, 24*time.Hour, now).Result).To(Equal(CheckFail))
+
+	backups := []v1alpha1.Backup{
+		newBackup("other", &v1alpha1.BRConfig{Cluster: "other"}, nil, true, time.Hour),
+		newBackup("running", &v1alpha1.BRConfig{Cluster: "demo"}, nil, false, 0),
+		newBackup("old", &v1alpha1.BRConfig{Cluster: "demo"}, nil, true, 48*time.Hour),
+	}
+	check := checkBackupFreshness(tc, backups, 24*time.Hour, now)
+	g.Expect(check.Result).To(Equal(CheckFail))
+	g.Expect(check.Message).To(ContainSubstring("old"))
+
+	backups = append(backups, newBackup("dumpling", nil, &v1alpha1.TiDBAccessConfig{Host: "demo-tidb.ns"}, true, 2*time.Hour))
+	check = checkBackupFreshness(tc, backups, 24*time.Hour, now)
+	g.Expect(check.Result).To(Equal(CheckPass))
+	g.Expect(check.Message).To(ContainSubstring("dumpling"))
+}
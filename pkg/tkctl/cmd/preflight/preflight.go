@@ -0,0 +1,312 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/config"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/readable"
+	tkctlUtil "github.com/pingcap/tidb-operator/pkg/tkctl/util"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+const (
+	preflightLongDesc = `
+		Run preflight checks of a tidb cluster before changing it.
+`
+	upgradeLongDesc = `
+		Check whether a tidb cluster is ready to be upgraded to the target version before
+		editing spec.version, the checks are:
+
+		* version: the target version is newer than the current one and doesn't skip a major version
+		* cluster-phase: no component is being upgraded or scaled
+		* pd-health: all the PD members are healthy
+		* store-health: all the TiKV and TiFlash stores are up
+		* disk-headroom: every store has enough disk available
+		* pending-ddl: no DDL job is running or queueing
+		* backup-freshness: a backup of the cluster completed recently
+
+		The command exits with a non-zero code if any check fails.
+
+		You may omit --tidbcluster option by running 'tkc use <clusterName>'.
+`
+	upgradeExample = `
+		# check whether the current tidb cluster (set by tkctl use) can be upgraded to v4.0.9
+		tkctl preflight upgrade --to v4.0.9
+
+		# check a specified tidb cluster and require a backup completed in the last 6 hours
+		tkctl preflight upgrade -t demo-cluster --to v5.0.0 --backup-max-age 6h
+`
+	upgradeUsage = `expected 'preflight upgrade -t CLUSTER_NAME --to VERSION' for the preflight upgrade command or
+using 'tkctl use' to set tidb cluster first.`
+
+	pdClientPort = "2379"
+	tidbSQLPort  = 4000
+)
+
+// UpgradeOptions contains the input to the preflight upgrade command.
+type UpgradeOptions struct {
+	TidbClusterName string
+	Namespace       string
+
+	TargetVersion     string
+	MinAvailableRatio float64
+	BackupMaxAge      time.Duration
+	TiDBUser          string
+	TiDBPassword      string
+
+	RestConfig *restclient.Config
+	TcCli      *versioned.Clientset
+	KubeCli    *kubernetes.Clientset
+
+	genericclioptions.IOStreams
+}
+
+// NewUpgradeOptions returns an UpgradeOptions
+func NewUpgradeOptions(streams genericclioptions.IOStreams) *UpgradeOptions {
+	return &UpgradeOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdPreflight creates the preflight command which checks a tidb cluster before changing it
+func NewCmdPreflight(tkcContext *config.TkcContext, streams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "preflight",
+		Short: "Run preflight checks of a tidb cluster",
+		Long:  preflightLongDesc,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+	cmd.AddCommand(NewCmdUpgrade(tkcContext, streams))
+	return cmd
+}
+
+// NewCmdUpgrade creates the preflight upgrade command
+func NewCmdUpgrade(tkcContext *config.TkcContext, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewUpgradeOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:     "upgrade",
+		Short:   "Check whether a tidb cluster is ready to be upgraded",
+		Long:    upgradeLongDesc,
+		Example: upgradeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(tkcContext, cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVar(&o.TargetVersion, "to", "", "The version to upgrade the tidb cluster to.")
+	cmd.Flags().Float64Var(&o.MinAvailableRatio, "min-available-ratio", 0.2, "The minimal ratio of the available disk to the capacity of every store.")
+	cmd.Flags().DurationVar(&o.BackupMaxAge, "backup-max-age", 24*time.Hour, "The maximal age of the latest completed backup of the tidb cluster.")
+	cmd.Flags().StringVar(&o.TiDBUser, "tidb-user", "root", "The user to query the pending DDL jobs from tidb.")
+	cmd.Flags().StringVar(&o.TiDBPassword, "tidb-password", "", "The password of the tidb user.")
+	cmdutil.CheckErr(cmd.MarkFlagRequired("to"))
+	return cmd
+}
+
+func (o *UpgradeOptions) Complete(tkcContext *config.TkcContext, cmd *cobra.Command, args []string) error {
+	clientConfig, err := tkcContext.ToTkcClientConfig()
+	if err != nil {
+		return err
+	}
+
+	if tidbClusterName, ok := clientConfig.TidbClusterName(); ok {
+		o.TidbClusterName = tidbClusterName
+	} else {
+		return cmdutil.UsageErrorf(cmd, upgradeUsage)
+	}
+
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return err
+	}
+	o.Namespace = namespace
+
+	restConfig, err := clientConfig.RestConfig()
+	if err != nil {
+		return err
+	}
+	o.RestConfig = restConfig
+	tcCli, err := versioned.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	o.TcCli = tcCli
+	kubeCli, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	o.KubeCli = kubeCli
+
+	return nil
+}
+
+func (o *UpgradeOptions) Run() error {
+	tc, err := o.TcCli.PingcapV1alpha1().
+		TidbClusters(o.Namespace).
+		Get(o.TidbClusterName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	checks := []Check{
+		CheckVersion(tc.Spec.Version, o.TargetVersion),
+		checkPhases(tc),
+	}
+
+	healths := []pdapi.MemberHealth{}
+	if err := o.getPD(tc, "health", &healths); err != nil {
+		checks = append(checks, fail("pd-health", "failed to get the health of pd: %v", err))
+	} else {
+		checks = append(checks, checkPDHealth(healths))
+	}
+
+	stores := &pdapi.StoresInfo{}
+	if err := o.getPD(tc, "stores", stores); err != nil {
+		checks = append(checks,
+			fail("store-health", "failed to get the stores from pd: %v", err),
+			fail("disk-headroom", "failed to get the stores from pd: %v", err))
+	} else {
+		checks = append(checks, checkStoreHealth(stores), checkDiskHeadroom(stores, o.MinAvailableRatio))
+	}
+
+	if states, err := o.getDDLJobStates(tc); err != nil {
+		checks = append(checks, warn("pending-ddl", "failed to query the ddl jobs from tidb: %v", err))
+	} else {
+		checks = append(checks, checkPendingDDLs(states))
+	}
+
+	backups, err := o.TcCli.PingcapV1alpha1().Backups(o.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		checks = append(checks, warn("backup-freshness", "failed to list the backups: %v", err))
+	} else {
+		checks = append(checks, checkBackupFreshness(tc, backups.Items, o.BackupMaxAge, time.Now()))
+	}
+
+	msg, err := renderChecks(tc, o.TargetVersion, checks)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(o.Out, msg)
+
+	if n := failed(checks); n > 0 {
+		return fmt.Errorf("%d of %d preflight checks failed", n, len(checks))
+	}
+	return nil
+}
+
+// getPD gets the PD API through the apiserver service proxy and decodes the response into obj.
+func (o *UpgradeOptions) getPD(tc *v1alpha1.TidbCluster, api string, obj interface{}) error {
+	body, err := o.KubeCli.CoreV1().Services(tc.Namespace).
+		ProxyGet(tc.Scheme(), controller.PDMemberName(tc.Name), pdClientPort, "/pd/api/v1/"+api, nil).
+		DoRaw()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, obj)
+}
+
+// getDDLJobStates returns the states of the DDL jobs by `ADMIN SHOW DDL JOBS`, which is
+// executed through a port forwarding to a running tidb pod.
+func (o *UpgradeOptions) getDDLJobStates(tc *v1alpha1.TidbCluster) ([]string, error) {
+	pod, err := tkctlUtil.GetRunningPod(o.KubeCli, tc, label.TiDBLabelVal)
+	if err != nil {
+		return nil, err
+	}
+
+	port, stop, err := tkctlUtil.ForwardPort(o.KubeCli, o.RestConfig, pod, tidbSQLPort, o.ErrOut)
+	if err != nil {
+		return nil, err
+	}
+	defer close(stop)
+
+	db, err := sql.Open("mysql", fmt.Sprintf("%s:%s@tcp(127.0.0.1:%d)/?timeout=10s", o.TiDBUser, o.TiDBPassword, port))
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query("ADMIN SHOW DDL JOBS")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// the columns differ between the tidb versions, so the STATE column is located by name
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	stateIndex := -1
+	for i, c := range columns {
+		if strings.EqualFold(c, "STATE") {
+			stateIndex = i
+		}
+	}
+	if stateIndex < 0 {
+		return nil, fmt.Errorf("no STATE column in the result of ADMIN SHOW DDL JOBS")
+	}
+
+	states := []string{}
+	values := make([]sql.RawBytes, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		states = append(states, string(values[stateIndex]))
+	}
+	return states, rows.Err()
+}
+
+func renderChecks(tc *v1alpha1.TidbCluster, target string, checks []Check) (string, error) {
+	return readable.TabbedString(func(out io.Writer) error {
+		w := readable.NewPrefixWriter(out)
+		w.WriteLine(readable.LEVEL_0, "Name:\t%s", tc.Name)
+		w.WriteLine(readable.LEVEL_0, "Namespace:\t%s", tc.Namespace)
+		w.WriteLine(readable.LEVEL_0, "Version:\t%s ---> %s", tc.Spec.Version, target)
+		w.WriteLine(readable.LEVEL_0, "Checks:")
+		{
+			w.WriteLine(readable.LEVEL_1, "Check\tResult\tMessage\t")
+			w.WriteLine(readable.LEVEL_1, "-----\t------\t-------\t")
+			for _, c := range checks {
+				w.WriteLine(readable.LEVEL_1, "%s\t%s\t%s\t", c.Name, c.Result, c.Message)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,219 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restart
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/config"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+const (
+	restartLongDesc = `
+		Restart the pods of a component of a tidb cluster gracefully one by one, the component
+		is one of pd, tikv, tiflash and tidb. All the pods of the component are restarted
+		unless the ordinals of the pods are given.
+
+		The pods are restarted by the operator through the restart-ordinals annotations of the
+		tidb cluster, the leaders are transferred or evicted before each pod is restarted.
+
+		You may omit --tidbcluster option by running 'tkc use <clusterName>'.
+`
+	restartExample = `
+		# restart all the tikv pods of the current tidb cluster (set by tkctl use)
+		tkctl restart tikv
+
+		# restart the pods demo-cluster-tidb-0 and demo-cluster-tidb-2
+		tkctl restart tidb 0 2 -t demo-cluster
+`
+	restartUsage = `expected 'restart COMPONENT [ORDINAL...] -t CLUSTER_NAME' for the restart command or
+using 'tkctl use' to set tidb cluster first.`
+)
+
+// restartOrdinalsAnnKeys are the annotation keys of the components which can be restarted
+var restartOrdinalsAnnKeys = map[v1alpha1.MemberType]string{
+	v1alpha1.PDMemberType:      label.AnnPDRestartOrdinals,
+	v1alpha1.TiKVMemberType:    label.AnnTiKVRestartOrdinals,
+	v1alpha1.TiFlashMemberType: label.AnnTiFlashRestartOrdinals,
+	v1alpha1.TiDBMemberType:    label.AnnTiDBRestartOrdinals,
+}
+
+// RestartOptions contains the input to the restart command.
+type RestartOptions struct {
+	TidbClusterName string
+	Namespace       string
+
+	Component v1alpha1.MemberType
+	Ordinals  []int32
+
+	TcCli   *versioned.Clientset
+	KubeCli *kubernetes.Clientset
+
+	genericclioptions.IOStreams
+}
+
+// NewRestartOptions returns a RestartOptions
+func NewRestartOptions(streams genericclioptions.IOStreams) *RestartOptions {
+	return &RestartOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdRestart creates the restart command which restarts the pods of a component gracefully
+func NewCmdRestart(tkcContext *config.TkcContext, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewRestartOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:     "restart COMPONENT [ORDINAL...]",
+		Short:   "Restart the pods of a component of a tidb cluster gracefully",
+		Long:    restartLongDesc,
+		Example: restartExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(tkcContext, cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+	return cmd
+}
+
+func (o *RestartOptions) Complete(tkcContext *config.TkcContext, cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return cmdutil.UsageErrorf(cmd, restartUsage)
+	}
+	o.Component = v1alpha1.MemberType(args[0])
+	if _, ok := restartOrdinalsAnnKeys[o.Component]; !ok {
+		return cmdutil.UsageErrorf(cmd, "component %s can not be restarted, expect one of pd, tikv, tiflash and tidb", o.Component)
+	}
+	for _, arg := range args[1:] {
+		ordinal, err := strconv.ParseInt(arg, 10, 32)
+		if err != nil || ordinal < 0 {
+			return cmdutil.UsageErrorf(cmd, "invalid ordinal %q", arg)
+		}
+		o.Ordinals = append(o.Ordinals, int32(ordinal))
+	}
+
+	clientConfig, err := tkcContext.ToTkcClientConfig()
+	if err != nil {
+		return err
+	}
+
+	if tidbClusterName, ok := clientConfig.TidbClusterName(); ok {
+		o.TidbClusterName = tidbClusterName
+	} else {
+		return cmdutil.UsageErrorf(cmd, restartUsage)
+	}
+
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return err
+	}
+	o.Namespace = namespace
+
+	restConfig, err := clientConfig.RestConfig()
+	if err != nil {
+		return err
+	}
+	tcCli, err := versioned.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	o.TcCli = tcCli
+	kubeCli, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	o.KubeCli = kubeCli
+
+	return nil
+}
+
+func (o *RestartOptions) Run() error {
+	tc, err := o.TcCli.PingcapV1alpha1().
+		TidbClusters(o.Namespace).
+		Get(o.TidbClusterName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	ordinals := o.Ordinals
+	if len(ordinals) == 0 {
+		podList, err := o.KubeCli.CoreV1().Pods(o.Namespace).List(metav1.ListOptions{
+			LabelSelector: label.New().Instance(tc.Name).Component(string(o.Component)).String(),
+		})
+		if err != nil {
+			return err
+		}
+		for _, pod := range podList.Items {
+			ordinal, err := util.GetOrdinalFromPodName(pod.Name)
+			if err != nil {
+				return err
+			}
+			ordinals = append(ordinals, ordinal)
+		}
+		if len(ordinals) == 0 {
+			return fmt.Errorf("no %s pod of tidb cluster %s/%s is found", o.Component, tc.Namespace, tc.Name)
+		}
+	}
+
+	annKey := restartOrdinalsAnnKeys[o.Component]
+	value, err := mergeOrdinals(tc.Annotations[annKey], ordinals)
+	if err != nil {
+		return fmt.Errorf("invalid annotation %s of tidb cluster %s/%s: %v", annKey, tc.Namespace, tc.Name, err)
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				annKey: value,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := o.TcCli.PingcapV1alpha1().TidbClusters(o.Namespace).Patch(tc.Name, types.MergePatchType, patch); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "%s pods %s of tidb cluster %s/%s are restarting\n", o.Component, value, tc.Namespace, tc.Name)
+	return nil
+}
+
+// mergeOrdinals merges the ordinals into the ordinals to restart in the annotation value, e.g. "[1,2]".
+func mergeOrdinals(value string, ordinals []int32) (string, error) {
+	merged := sets.NewInt32(ordinals...)
+	if value != "" {
+		var existing []int32
+		if err := json.Unmarshal([]byte(value), &existing); err != nil {
+			return "", err
+		}
+		merged.Insert(existing...)
+	}
+	data, err := json.Marshal(merged.List())
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,42 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restart
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestMergeOrdinals(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		value    string
+		ordinals []int32
+		expected string
+	}{
+		{"", []int32{2, 0, 1}, "[0,1,2]"},
+		{"[1,3]", []int32{0, 1}, "[0,1,3]"},
+		{"[]", []int32{4}, "[4]"},
+	}
+	for _, tt := range tests {
+		value, err := mergeOrdinals(tt.value, tt.ordinals)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(value).To(Equal(tt.expected))
+	}
+
+	_, err := mergeOrdinals("1,2", []int32{0})
+	g.Expect(err).To(HaveOccurred())
+}
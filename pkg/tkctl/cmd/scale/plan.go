@@ -0,0 +1,158 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// minHAReplicas is the number of the replicas of PD and TiKV required to tolerate one failure,
+	// which is also the default max-replicas of the regions in PD
+	minHAReplicas = 3
+)
+
+// scalePlan describes the pods changed by scaling a component with delete slots.
+type scalePlan struct {
+	// DeleteSlots are the delete slots of the component in the spec after scaling
+	DeleteSlots []int32
+	// Deleted and Created are the ordinals of the pods to delete and to create
+	Deleted []int32
+	Created []int32
+	// DrainRegions and DrainSize are the number of the region peers and the size of the data
+	// migrated out of the deleted stores
+	DrainRegions int64
+	DrainSize    resource.Quantity
+	// Problems are the reasons the plan may break the cluster, the plan is applied only if forced
+	Problems []string
+}
+
+// planScale plans to scale the component to the replicas and to add the slots to its delete slots.
+func planScale(tc *v1alpha1.TidbCluster, component v1alpha1.MemberType, replicas int32, slots []int32) (*scalePlan, error) {
+	plan := &scalePlan{}
+	if _, err := componentReplicas(tc, component); err != nil {
+		return nil, err
+	}
+	if !supportDeleteSlots(component) {
+		if len(slots) > 0 {
+			return nil, fmt.Errorf("component %s does not support delete slots, expect one of pd, tikv, tiflash and tidb", component)
+		}
+		return plan, nil
+	}
+	if replicas == 0 && (component == v1alpha1.PDMemberType || component == v1alpha1.TiKVMemberType) {
+		return nil, fmt.Errorf("%s can not be scaled to 0 replicas", component)
+	}
+	for _, slot := range slots {
+		if slot < 0 {
+			return nil, fmt.Errorf("invalid delete slot %d, expect a non-negative ordinal", slot)
+		}
+	}
+
+	current, err := util.GetPodOrdinals(tc, component)
+	if err != nil {
+		return nil, err
+	}
+	desiredTC := tc.DeepCopy()
+	deleteSlots := setComponent(desiredTC, component, replicas, slots)
+	desired, err := util.GetPodOrdinals(desiredTC, component)
+	if err != nil {
+		return nil, err
+	}
+	plan.DeleteSlots = deleteSlots
+	plan.Deleted = current.Difference(desired).List()
+	plan.Created = desired.Difference(current).List()
+
+	switch component {
+	case v1alpha1.PDMemberType:
+		if replicas < minHAReplicas && int32(current.Len()) >= minHAReplicas {
+			plan.Problems = append(plan.Problems, fmt.Sprintf("pd with %d replicas can not tolerate any failure", replicas))
+		}
+		if replicas > 0 && replicas%2 == 0 {
+			plan.Problems = append(plan.Problems, fmt.Sprintf("pd with an even number of replicas %d tolerates no more failures than %d replicas", replicas, replicas-1))
+		}
+	case v1alpha1.TiKVMemberType:
+		if replicas < minHAReplicas && int32(current.Len()) >= minHAReplicas {
+			plan.Problems = append(plan.Problems, fmt.Sprintf("tikv with %d replicas is fewer than the %d replicas of the regions", replicas, minHAReplicas))
+		}
+		planDrain(plan, tc, tc.Status.TiKV.Stores, component)
+	case v1alpha1.TiFlashMemberType:
+		planDrain(plan, tc, tc.Status.TiFlash.Stores, component)
+	}
+	return plan, nil
+}
+
+// planDrain estimates the regions and data migrated out of the stores of the deleted pods,
+// and checks whether the remaining stores have enough space to hold them.
+func planDrain(plan *scalePlan, tc *v1alpha1.TidbCluster, stores map[string]v1alpha1.TiKVStore, component v1alpha1.MemberType) {
+	deleted := sets.NewString()
+	for _, ordinal := range plan.Deleted {
+		deleted.Insert(podName(tc, component, ordinal))
+	}
+	available := resource.Quantity{}
+	for _, store := range stores {
+		if !deleted.Has(store.PodName) {
+			available.Add(store.Available)
+			continue
+		}
+		plan.DrainRegions += int64(store.RegionCount)
+		used := store.Capacity.DeepCopy()
+		used.Sub(store.Available)
+		plan.DrainSize.Add(used)
+	}
+	if deleted.Len() > 0 && available.Cmp(plan.DrainSize) < 0 {
+		plan.Problems = append(plan.Problems, fmt.Sprintf("the remaining %s stores have %s available, less than the %s data to migrate",
+			component, available.String(), plan.DrainSize.String()))
+	}
+}
+
+// setComponent sets the replicas of the component and adds the slots to its delete slots in the spec,
+// it returns the delete slots in the spec.
+func setComponent(tc *v1alpha1.TidbCluster, component v1alpha1.MemberType, replicas int32, slots []int32) []int32 {
+	var specSlots *[]int32
+	switch component {
+	case v1alpha1.PDMemberType:
+		tc.Spec.PD.Replicas = replicas
+		specSlots = &tc.Spec.PD.DeleteSlots
+	case v1alpha1.TiKVMemberType:
+		tc.Spec.TiKV.Replicas = replicas
+		specSlots = &tc.Spec.TiKV.DeleteSlots
+	case v1alpha1.TiFlashMemberType:
+		tc.Spec.TiFlash.Replicas = replicas
+		specSlots = &tc.Spec.TiFlash.DeleteSlots
+	case v1alpha1.TiDBMemberType:
+		tc.Spec.TiDB.Replicas = replicas
+		specSlots = &tc.Spec.TiDB.DeleteSlots
+	default:
+		return nil
+	}
+	*specSlots = sets.NewInt32(*specSlots...).Insert(slots...).List()
+	return *specSlots
+}
+
+func supportDeleteSlots(component v1alpha1.MemberType) bool {
+	switch component {
+	case v1alpha1.PDMemberType, v1alpha1.TiKVMemberType, v1alpha1.TiFlashMemberType, v1alpha1.TiDBMemberType:
+		return true
+	}
+	return false
+}
+
+func podName(tc *v1alpha1.TidbCluster, component v1alpha1.MemberType, ordinal int32) string {
+	return fmt.Sprintf("%s-%s-%d", tc.Name, component, ordinal)
+}
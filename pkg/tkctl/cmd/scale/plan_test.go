@@ -0,0 +1,98 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTidbCluster() *v1alpha1.TidbCluster {
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo"},
+		Spec: v1alpha1.TidbClusterSpec{
+			PD:   &v1alpha1.PDSpec{Replicas: 3},
+			TiKV: &v1alpha1.TiKVSpec{Replicas: 5},
+			TiDB: &v1alpha1.TiDBSpec{Replicas: 2},
+		},
+		Status: v1alpha1.TidbClusterStatus{
+			TiKV: v1alpha1.TiKVStatus{Stores: map[string]v1alpha1.TiKVStore{}},
+		},
+	}
+	for i, id := range []string{"1", "2", "3", "4", "5"} {
+		tc.Status.TiKV.Stores[id] = v1alpha1.TiKVStore{
+			ID:          id,
+			PodName:     podName(tc, v1alpha1.TiKVMemberType, int32(i)),
+			RegionCount: 100,
+			Capacity:    resource.MustParse("100Gi"),
+			Available:   resource.MustParse("60Gi"),
+		}
+	}
+	return tc
+}
+
+func TestPlanScale(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbCluster()
+	plan, err := planScale(tc, v1alpha1.TiKVMemberType, 3, []int32{2, 4})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(plan.DeleteSlots).To(Equal([]int32{2, 4}))
+	g.Expect(plan.Deleted).To(Equal([]int32{2, 4}))
+	g.Expect(plan.Created).To(BeEmpty())
+	g.Expect(plan.DrainRegions).To(Equal(int64(200)))
+	g.Expect(plan.DrainSize.String()).To(Equal("80Gi"))
+	g.Expect(plan.Problems).To(BeEmpty())
+	// the spec of the given tidb cluster is not changed
+	g.Expect(tc.Spec.TiKV.Replicas).To(Equal(int32(5)))
+
+	// the legacy annotation is merged, and the existing delete slots are kept
+	tc = newTidbCluster()
+	tc.Spec.TiKV.Replicas = 4
+	tc.Annotations = map[string]string{label.AnnTiKVDeleteSlots: "[1]"}
+	tc.Spec.TiKV.DeleteSlots = []int32{3}
+	plan, err = planScale(tc, v1alpha1.TiKVMemberType, 4, []int32{0})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(plan.DeleteSlots).To(Equal([]int32{0, 3}))
+	g.Expect(plan.Deleted).To(Equal([]int32{0}))
+	g.Expect(plan.Created).To(Equal([]int32{6}))
+
+	tc = newTidbCluster()
+	for _, id := range []string{"4", "5"} {
+		store := tc.Status.TiKV.Stores[id]
+		store.Available = resource.MustParse("10Gi")
+		tc.Status.TiKV.Stores[id] = store
+	}
+	plan, err = planScale(tc, v1alpha1.TiKVMemberType, 2, []int32{0, 1, 2})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(plan.Problems).To(HaveLen(2))
+
+	tc = newTidbCluster()
+	plan, err = planScale(tc, v1alpha1.PDMemberType, 4, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(plan.Created).To(Equal([]int32{3}))
+	g.Expect(plan.Problems).To(HaveLen(1))
+
+	_, err = planScale(newTidbCluster(), v1alpha1.TiKVMemberType, 0, nil)
+	g.Expect(err).To(HaveOccurred())
+	_, err = planScale(newTidbCluster(), v1alpha1.TiDBMemberType, 1, []int32{-1})
+	g.Expect(err).To(HaveOccurred())
+	_, err = planScale(newTidbCluster(), v1alpha1.TiCDCMemberType, 1, []int32{1})
+	g.Expect(err).To(HaveOccurred())
+}
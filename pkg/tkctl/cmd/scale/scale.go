@@ -0,0 +1,292 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scale
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/config"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+const (
+	scaleLongDesc = `
+		Scale a component of a tidb cluster by setting its replicas in the spec, the
+		component is one of pd, tikv, tiflash, tidb, ticdc and pump.
+
+		The ordinals of the pd, tikv, tiflash and tidb pods to delete when scaling in, or to
+		skip when scaling out, can be given by --delete-slots if the AdvancedStatefulSet feature
+		is enabled. They are added to spec.<component>.deleteSlots and applied together with
+		the replicas in one update. The pods to delete and to create, the regions and data to
+		migrate out of the deleted stores and the impact on high availability are checked and
+		printed before the update, the update is refused on problems unless --force is set.
+
+		You may omit --tidbcluster option by running 'tkc use <clusterName>'.
+`
+	scaleExample = `
+		# scale the tikv of the current tidb cluster (set by tkctl use) to 5 replicas
+		tkctl scale tikv 5
+
+		# scale the tidb of a specified tidb cluster to 3 replicas
+		tkctl scale tidb 3 -t demo-cluster
+
+		# scale the tikv from 5 to 3 replicas by deleting the pods with ordinals 2 and 4
+		tkctl scale tikv 3 --delete-slots 2,4 -t demo-cluster
+
+		# check the scaling without applying it
+		tkctl scale tikv 3 --delete-slots 2,4 --dry-run
+`
+	scaleUsage = `expected 'scale COMPONENT REPLICAS -t CLUSTER_NAME' for the scale command or
+using 'tkctl use' to set tidb cluster first.`
+)
+
+// ScaleOptions contains the input to the scale command.
+type ScaleOptions struct {
+	TidbClusterName string
+	Namespace       string
+
+	Component   v1alpha1.MemberType
+	Replicas    int32
+	DeleteSlots []int
+	Force       bool
+	DryRun      bool
+
+	TcCli   *versioned.Clientset
+	KubeCli *kubernetes.Clientset
+
+	genericclioptions.IOStreams
+}
+
+// NewScaleOptions returns a ScaleOptions
+func NewScaleOptions(streams genericclioptions.IOStreams) *ScaleOptions {
+	return &ScaleOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdScale creates the scale command which scales a component of a tidb cluster
+func NewCmdScale(tkcContext *config.TkcContext, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewScaleOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:     "scale COMPONENT REPLICAS",
+		Short:   "Scale a component of a tidb cluster",
+		Long:    scaleLongDesc,
+		Example: scaleExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(tkcContext, cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+	cmd.Flags().IntSliceVar(&o.DeleteSlots, "delete-slots", nil,
+		"the ordinals of the pods to delete when scaling in, or to skip when scaling out")
+	cmd.Flags().BoolVar(&o.Force, "force", false, "Scale the component even if the checks find problems.")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", false, "Only print the pods to delete and to create.")
+	return cmd
+}
+
+func (o *ScaleOptions) Complete(tkcContext *config.TkcContext, cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return cmdutil.UsageErrorf(cmd, scaleUsage)
+	}
+	o.Component = v1alpha1.MemberType(args[0])
+	replicas, err := strconv.ParseInt(args[1], 10, 32)
+	if err != nil || replicas < 0 {
+		return cmdutil.UsageErrorf(cmd, "invalid replicas %q", args[1])
+	}
+	o.Replicas = int32(replicas)
+
+	clientConfig, err := tkcContext.ToTkcClientConfig()
+	if err != nil {
+		return err
+	}
+
+	if tidbClusterName, ok := clientConfig.TidbClusterName(); ok {
+		o.TidbClusterName = tidbClusterName
+	} else {
+		return cmdutil.UsageErrorf(cmd, scaleUsage)
+	}
+
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return err
+	}
+	o.Namespace = namespace
+
+	restConfig, err := clientConfig.RestConfig()
+	if err != nil {
+		return err
+	}
+	tcCli, err := versioned.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	o.TcCli = tcCli
+	kubeCli, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	o.KubeCli = kubeCli
+
+	return nil
+}
+
+func (o *ScaleOptions) Run() error {
+	tc, err := o.TcCli.PingcapV1alpha1().
+		TidbClusters(o.Namespace).
+		Get(o.TidbClusterName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	current, err := componentReplicas(tc, o.Component)
+	if err != nil {
+		return err
+	}
+	slots := make([]int32, 0, len(o.DeleteSlots))
+	for _, slot := range o.DeleteSlots {
+		slots = append(slots, int32(slot))
+	}
+	if current == o.Replicas && len(slots) == 0 {
+		fmt.Fprintf(o.Out, "%s of tidb cluster %s/%s already has %d replicas\n", o.Component, tc.Namespace, tc.Name, current)
+		return nil
+	}
+
+	plan, err := planScale(tc, o.Component, o.Replicas, slots)
+	if err != nil {
+		return err
+	}
+	if len(slots) > 0 {
+		enabled, err := o.advancedStatefulSetEnabled()
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			return fmt.Errorf("delete slots take effect only if the AdvancedStatefulSet feature is enabled")
+		}
+	}
+	fmt.Fprint(o.Out, renderPlan(tc, o.Component, current, o.Replicas, plan))
+	if len(plan.Problems) > 0 && !o.Force {
+		return fmt.Errorf("found %d problems, use --force to scale anyway", len(plan.Problems))
+	}
+	if o.DryRun {
+		return nil
+	}
+
+	componentPatch := map[string]interface{}{
+		"replicas": o.Replicas,
+	}
+	if len(slots) > 0 {
+		componentPatch["deleteSlots"] = plan.DeleteSlots
+	}
+	// the resource version makes the patch fail if the tidb cluster is changed after the plan
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"resourceVersion": tc.ResourceVersion,
+		},
+		"spec": map[string]interface{}{
+			string(o.Component): componentPatch,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := o.TcCli.PingcapV1alpha1().TidbClusters(o.Namespace).Patch(tc.Name, types.MergePatchType, patch); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "%s of tidb cluster %s/%s is scaled from %d to %d replicas\n", o.Component, tc.Namespace, tc.Name, current, o.Replicas)
+	return nil
+}
+
+// advancedStatefulSetEnabled returns whether the advanced statefulset CRD is served,
+// which is installed when the AdvancedStatefulSet feature of the operator is enabled.
+func (o *ScaleOptions) advancedStatefulSetEnabled() (bool, error) {
+	_, err := o.KubeCli.Discovery().ServerResourcesForGroupVersion("apps.pingcap.com/v1")
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// renderPlan renders the pods to delete and to create and the problems of the plan.
+func renderPlan(tc *v1alpha1.TidbCluster, component v1alpha1.MemberType, current, replicas int32, plan *scalePlan) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Scale %s of tidb cluster %s/%s from %d to %d replicas\n", component, tc.Namespace, tc.Name, current, replicas)
+	if len(plan.DeleteSlots) > 0 {
+		fmt.Fprintf(&b, "  Delete slots: %v\n", plan.DeleteSlots)
+	}
+	for _, ordinal := range plan.Deleted {
+		fmt.Fprintf(&b, "  - %s\n", podName(tc, component, ordinal))
+	}
+	for _, ordinal := range plan.Created {
+		fmt.Fprintf(&b, "  + %s\n", podName(tc, component, ordinal))
+	}
+	if plan.DrainRegions > 0 {
+		fmt.Fprintf(&b, "  %d region peers (%s) will be migrated out of the deleted stores\n", plan.DrainRegions, plan.DrainSize.String())
+	}
+	for _, problem := range plan.Problems {
+		fmt.Fprintf(&b, "  WARN: %s\n", problem)
+	}
+	return b.String()
+}
+
+// componentReplicas returns the replicas of the component in the spec, an error is returned
+// if the component can not be scaled or is not deployed.
+func componentReplicas(tc *v1alpha1.TidbCluster, component v1alpha1.MemberType) (int32, error) {
+	notDeployed := fmt.Errorf("%s is not deployed in tidb cluster %s/%s", component, tc.Namespace, tc.Name)
+	switch component {
+	case v1alpha1.PDMemberType:
+		if tc.Spec.PD == nil {
+			return 0, notDeployed
+		}
+		return tc.Spec.PD.Replicas, nil
+	case v1alpha1.TiKVMemberType:
+		if tc.Spec.TiKV == nil {
+			return 0, notDeployed
+		}
+		return tc.Spec.TiKV.Replicas, nil
+	case v1alpha1.TiFlashMemberType:
+		if tc.Spec.TiFlash == nil {
+			return 0, notDeployed
+		}
+		return tc.Spec.TiFlash.Replicas, nil
+	case v1alpha1.TiDBMemberType:
+		if tc.Spec.TiDB == nil {
+			return 0, notDeployed
+		}
+		return tc.Spec.TiDB.Replicas, nil
+	case v1alpha1.TiCDCMemberType:
+		if tc.Spec.TiCDC == nil {
+			return 0, notDeployed
+		}
+		return tc.Spec.TiCDC.Replicas, nil
+	case v1alpha1.PumpMemberType:
+		if tc.Spec.Pump == nil {
+			return 0, notDeployed
+		}
+		return tc.Spec.Pump.Replicas, nil
+	}
+	return 0, fmt.Errorf("component %s can not be scaled, expect one of pd, tikv, tiflash, tidb, ticdc and pump", component)
+}
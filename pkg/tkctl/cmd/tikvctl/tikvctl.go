@@ -0,0 +1,199 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tikvctl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/config"
+	tkctlUtil "github.com/pingcap/tidb-operator/pkg/tkctl/util"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+const (
+	tikvctlLongDesc = `
+		Run tikv-ctl against a TiKV store of a tidb cluster in the remote mode.
+
+		A local port is forwarded to the TiKV pod with the given ordinal and tikv-ctl is run
+		with the forwarded address as --host. If TLS is enabled between the components of the
+		tidb cluster, the client certificate in the cluster client secret is passed to tikv-ctl.
+		The arguments after '--' are passed to tikv-ctl as is.
+
+		tikv-ctl is looked up in PATH unless its path is given by --binary.
+
+		You may omit --tidbcluster option by running 'tkc use <clusterName>'.
+`
+	tikvctlExample = `
+		# show the properties of region 2 on the store of the pod demo-cluster-tikv-0
+		tkctl tikv-ctl 0 -- region-properties -r 2
+
+		# show the metrics of the store of the pod demo-cluster-tikv-1 of a specified tidb cluster
+		tkctl tikv-ctl 1 -t demo-cluster -- metrics
+`
+	tikvctlUsage = `expected 'tikv-ctl ORDINAL -t CLUSTER_NAME -- [ARGS...]' for the tikv-ctl command or
+using 'tkctl use' to set tidb cluster first.`
+
+	tikvServerPort = 20160
+)
+
+// TikvctlOptions contains the input to the tikv-ctl command.
+type TikvctlOptions struct {
+	TidbClusterName string
+	Namespace       string
+
+	Ordinal int32
+	Binary  string
+	Args    []string
+
+	TcCli      *versioned.Clientset
+	KubeCli    *kubernetes.Clientset
+	RestConfig *restclient.Config
+
+	genericclioptions.IOStreams
+}
+
+// NewTikvctlOptions returns a TikvctlOptions
+func NewTikvctlOptions(streams genericclioptions.IOStreams) *TikvctlOptions {
+	return &TikvctlOptions{
+		Binary: "tikv-ctl",
+
+		IOStreams: streams,
+	}
+}
+
+// NewCmdTikvctl creates the tikv-ctl command which runs tikv-ctl against a TiKV store of a tidb cluster
+func NewCmdTikvctl(tkcContext *config.TkcContext, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewTikvctlOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:     "tikv-ctl ORDINAL [-- ARGS...]",
+		Aliases: []string{"tikvctl"},
+		Short:   "Run tikv-ctl against a TiKV store of a tidb cluster",
+		Long:    tikvctlLongDesc,
+		Example: tikvctlExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(tkcContext, cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+	cmd.Flags().StringVar(&o.Binary, "binary", o.Binary, "The path of tikv-ctl.")
+	return cmd
+}
+
+func (o *TikvctlOptions) Complete(tkcContext *config.TkcContext, cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return cmdutil.UsageErrorf(cmd, tikvctlUsage)
+	}
+	ordinal, err := strconv.ParseInt(args[0], 10, 32)
+	if err != nil || ordinal < 0 {
+		return cmdutil.UsageErrorf(cmd, "invalid ordinal %q", args[0])
+	}
+	o.Ordinal = int32(ordinal)
+	o.Args = args[1:]
+
+	clientConfig, err := tkcContext.ToTkcClientConfig()
+	if err != nil {
+		return err
+	}
+
+	if tidbClusterName, ok := clientConfig.TidbClusterName(); ok {
+		o.TidbClusterName = tidbClusterName
+	} else {
+		return cmdutil.UsageErrorf(cmd, tikvctlUsage)
+	}
+
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return err
+	}
+	o.Namespace = namespace
+
+	restConfig, err := clientConfig.RestConfig()
+	if err != nil {
+		return err
+	}
+	o.RestConfig = restConfig
+	tcCli, err := versioned.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	o.TcCli = tcCli
+	kubeCli, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	o.KubeCli = kubeCli
+
+	return nil
+}
+
+func (o *TikvctlOptions) Run() error {
+	binary, err := exec.LookPath(o.Binary)
+	if err != nil {
+		return fmt.Errorf("tikv-ctl is not found, install it or set its path by --binary: %v", err)
+	}
+
+	tc, err := o.TcCli.PingcapV1alpha1().
+		TidbClusters(o.Namespace).
+		Get(o.TidbClusterName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	podName := fmt.Sprintf("%s-%d", controller.TiKVMemberName(tc.Name), o.Ordinal)
+	pod, err := o.KubeCli.CoreV1().Pods(tc.Namespace).Get(podName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if pod.Status.Phase != v1.PodRunning {
+		return fmt.Errorf("pod %s/%s is %s, not running", pod.Namespace, pod.Name, pod.Status.Phase)
+	}
+	port, stop, err := tkctlUtil.ForwardPort(o.KubeCli, o.RestConfig, pod, tikvServerPort, o.ErrOut)
+	if err != nil {
+		return err
+	}
+	defer close(stop)
+
+	ctlArgs := []string{"--host", fmt.Sprintf("127.0.0.1:%d", port)}
+	if tc.IsTLSClusterEnabled() {
+		dir, err := ioutil.TempDir("", "tkctl-tikv-ctl")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		files, err := tkctlUtil.WriteClusterClientTLS(o.KubeCli, tc, dir)
+		if err != nil {
+			return err
+		}
+		ctlArgs = append(ctlArgs, "--ca-path", files.CA, "--cert-path", files.Cert, "--key-path", files.Key)
+	}
+
+	ctl := exec.Command(binary, append(ctlArgs, o.Args...)...)
+	ctl.Stdin = o.In
+	ctl.Stdout = o.Out
+	ctl.Stderr = o.ErrOut
+	return ctl.Run()
+}
@@ -0,0 +1,155 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package upgrade
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/cmd/preflight"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/config"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+const (
+	upgradeLongDesc = `
+		Upgrade a tidb cluster by setting spec.version, the components are upgraded by the
+		operator one after another.
+
+		Downgrades and upgrades skipping a major version are refused unless --force is set.
+		Run 'preflight upgrade' first for the full checklist before upgrading.
+
+		You may omit --tidbcluster option by running 'tkc use <clusterName>'.
+`
+	upgradeExample = `
+		# upgrade the current tidb cluster (set by tkctl use) to v4.0.9
+		tkctl upgrade v4.0.9
+
+		# upgrade a specified tidb cluster to v5.0.0
+		tkctl upgrade v5.0.0 -t demo-cluster
+`
+	upgradeUsage = `expected 'upgrade VERSION -t CLUSTER_NAME' for the upgrade command or
+using 'tkctl use' to set tidb cluster first.`
+)
+
+// UpgradeOptions contains the input to the upgrade command.
+type UpgradeOptions struct {
+	TidbClusterName string
+	Namespace       string
+
+	Version string
+	Force   bool
+
+	TcCli *versioned.Clientset
+
+	genericclioptions.IOStreams
+}
+
+// NewUpgradeOptions returns an UpgradeOptions
+func NewUpgradeOptions(streams genericclioptions.IOStreams) *UpgradeOptions {
+	return &UpgradeOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdUpgrade creates the upgrade command which upgrades a tidb cluster
+func NewCmdUpgrade(tkcContext *config.TkcContext, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewUpgradeOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:     "upgrade VERSION",
+		Short:   "Upgrade a tidb cluster",
+		Long:    upgradeLongDesc,
+		Example: upgradeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(tkcContext, cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.Force, "force", false, "Set the version even if the version check fails.")
+	return cmd
+}
+
+func (o *UpgradeOptions) Complete(tkcContext *config.TkcContext, cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmdutil.UsageErrorf(cmd, upgradeUsage)
+	}
+	o.Version = args[0]
+
+	clientConfig, err := tkcContext.ToTkcClientConfig()
+	if err != nil {
+		return err
+	}
+
+	if tidbClusterName, ok := clientConfig.TidbClusterName(); ok {
+		o.TidbClusterName = tidbClusterName
+	} else {
+		return cmdutil.UsageErrorf(cmd, upgradeUsage)
+	}
+
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return err
+	}
+	o.Namespace = namespace
+
+	restConfig, err := clientConfig.RestConfig()
+	if err != nil {
+		return err
+	}
+	tcCli, err := versioned.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	o.TcCli = tcCli
+
+	return nil
+}
+
+func (o *UpgradeOptions) Run() error {
+	tc, err := o.TcCli.PingcapV1alpha1().
+		TidbClusters(o.Namespace).
+		Get(o.TidbClusterName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	check := preflight.CheckVersion(tc.Spec.Version, o.Version)
+	switch {
+	case check.Result == preflight.CheckFail && !o.Force:
+		return fmt.Errorf("%s, set --force to upgrade anyway", check.Message)
+	case check.Result != preflight.CheckPass:
+		fmt.Fprintf(o.ErrOut, "warning: %s\n", check.Message)
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"version": o.Version,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := o.TcCli.PingcapV1alpha1().TidbClusters(o.Namespace).Patch(tc.Name, types.MergePatchType, patch); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "tidb cluster %s/%s is being upgraded from %s to %s\n", tc.Namespace, tc.Name, tc.Spec.Version, o.Version)
+	return nil
+}
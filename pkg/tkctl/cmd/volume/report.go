@@ -0,0 +1,206 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package volume
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/readable"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	unset = "<none>"
+
+	// VolumeInUse means the PVC is mounted by a pod
+	VolumeInUse = "InUse"
+	// VolumeUnused means the PVC is not mounted by any pod, but it's not marked to be deleted by the operator
+	VolumeUnused = "Unused"
+	// VolumeOrphan means the PVC is left by a scale-in and not mounted by any pod
+	VolumeOrphan = "Orphan"
+)
+
+// zoneLabelKeys are the label keys of the zone of PVs and nodes, the stable one first
+var zoneLabelKeys = []string{"topology.kubernetes.io/zone", v1.LabelZoneFailureDomain}
+
+// volumeRow is the report of a PVC.
+type volumeRow struct {
+	Name         string
+	Component    string
+	Pod          string
+	Status       string
+	Capacity     resource.Quantity
+	Used         *resource.Quantity
+	StorageClass string
+	Zone         string
+}
+
+// volumeReport is the report of the PVCs of a tidb cluster.
+type volumeReport struct {
+	TidbCluster *v1alpha1.TidbCluster
+	Rows        []volumeRow
+}
+
+// buildReport builds the report of the PVCs of the tidb cluster. The pods are the pods in the namespace of
+// the tidb cluster, the PVs and the nodes are keyed by their names and used to find the zones of the PVCs.
+func buildReport(tc *v1alpha1.TidbCluster, pvcs []v1.PersistentVolumeClaim, pods []v1.Pod, pvs map[string]*v1.PersistentVolume, nodes map[string]*v1.Node) *volumeReport {
+	mountedBy := map[string]*v1.Pod{}
+	for i := range pods {
+		for _, vol := range pods[i].Spec.Volumes {
+			if vol.PersistentVolumeClaim != nil {
+				mountedBy[vol.PersistentVolumeClaim.ClaimName] = &pods[i]
+			}
+		}
+	}
+	volumeStatus := componentVolumes(tc)
+
+	report := &volumeReport{TidbCluster: tc}
+	for i := range pvcs {
+		pvc := &pvcs[i]
+		row := volumeRow{
+			Name:         pvc.Name,
+			Component:    orUnset(pvc.Labels[label.ComponentLabelKey]),
+			Pod:          orUnset(pvc.Annotations[label.AnnPodNameKey]),
+			Capacity:     pvc.Status.Capacity[v1.ResourceStorage],
+			StorageClass: unset,
+			Zone:         unset,
+		}
+		if pvc.Spec.StorageClassName != nil {
+			row.StorageClass = *pvc.Spec.StorageClassName
+		}
+		if status, ok := volumeStatus[pvc.Name]; ok {
+			row.Used = status.UsedCapacity
+		}
+
+		pod, mounted := mountedBy[pvc.Name]
+		_, retained := tc.Status.RetainedPVCs[pvc.Name]
+		_, deferDeleting := pvc.Annotations[label.AnnPVCDeferDeleting]
+		switch {
+		case mounted:
+			row.Status = VolumeInUse
+		case retained || deferDeleting:
+			row.Status = VolumeOrphan
+		default:
+			row.Status = VolumeUnused
+		}
+
+		if pv, ok := pvs[pvc.Spec.VolumeName]; ok {
+			row.Zone = zoneOf(pv.Labels)
+		}
+		if row.Zone == unset && mounted {
+			if node, ok := nodes[pod.Spec.NodeName]; ok {
+				row.Zone = zoneOf(node.Labels)
+			}
+		}
+		report.Rows = append(report.Rows, row)
+	}
+	sort.Slice(report.Rows, func(i, j int) bool {
+		if report.Rows[i].Component != report.Rows[j].Component {
+			return report.Rows[i].Component < report.Rows[j].Component
+		}
+		return report.Rows[i].Name < report.Rows[j].Name
+	})
+	return report
+}
+
+// Orphans returns the names of the orphan PVCs.
+func (r *volumeReport) Orphans() []string {
+	var names []string
+	for _, row := range r.Rows {
+		if row.Status == VolumeOrphan {
+			names = append(names, row.Name)
+		}
+	}
+	return names
+}
+
+// Render renders the report as a table followed by a summary.
+func (r *volumeReport) Render() (string, error) {
+	return readable.TabbedString(func(out io.Writer) error {
+		w := readable.NewPrefixWriter(out)
+		w.WriteLine(readable.LEVEL_0, "Cluster: %s/%s", r.TidbCluster.Namespace, r.TidbCluster.Name)
+		if len(r.Rows) == 0 {
+			w.WriteLine(readable.LEVEL_1, "No volumes")
+			return nil
+		}
+		w.WriteLine(readable.LEVEL_1, "NAME\tCOMPONENT\tPOD\tSTATUS\tCAPACITY\tUSED\tSTORAGECLASS\tZONE")
+		total := resource.Quantity{}
+		used := resource.Quantity{}
+		orphans := resource.Quantity{}
+		orphanCount := 0
+		for _, row := range r.Rows {
+			usedStr := unset
+			if row.Used != nil {
+				usedStr = fmt.Sprintf("%s (%d%%)", row.Used.String(), percent(row.Used, &row.Capacity))
+				used.Add(*row.Used)
+			}
+			total.Add(row.Capacity)
+			if row.Status == VolumeOrphan {
+				orphans.Add(row.Capacity)
+				orphanCount++
+			}
+			w.WriteLine(readable.LEVEL_1, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s",
+				row.Name, row.Component, row.Pod, row.Status, row.Capacity.String(), usedStr, row.StorageClass, row.Zone)
+		}
+		w.WriteLine(readable.LEVEL_1, "Total: %d volumes, %s capacity, %s used", len(r.Rows), total.String(), used.String())
+		w.WriteLine(readable.LEVEL_1, "Orphans: %d volumes, %s capacity", orphanCount, orphans.String())
+		return nil
+	})
+}
+
+// componentVolumes returns the volume status of all the components, keyed by the PVC name.
+func componentVolumes(tc *v1alpha1.TidbCluster) map[string]v1alpha1.StorageVolumeStatus {
+	volumes := map[string]v1alpha1.StorageVolumeStatus{}
+	for _, vs := range []map[string]v1alpha1.StorageVolumeStatus{
+		tc.Status.PD.Volumes,
+		tc.Status.TiKV.Volumes,
+		tc.Status.TiFlash.Volumes,
+		tc.Status.TiDB.Volumes,
+		tc.Status.TiCDC.Volumes,
+		tc.Status.Pump.Volumes,
+	} {
+		for name, status := range vs {
+			volumes[name] = status
+		}
+	}
+	return volumes
+}
+
+func zoneOf(labels map[string]string) string {
+	for _, key := range zoneLabelKeys {
+		if zone, ok := labels[key]; ok {
+			return zone
+		}
+	}
+	return unset
+}
+
+func percent(used, capacity *resource.Quantity) int64 {
+	if capacity.IsZero() {
+		return 0
+	}
+	return used.Value() * 100 / capacity.Value()
+}
+
+func orUnset(s string) string {
+	if s == "" {
+		return unset
+	}
+	return s
+}
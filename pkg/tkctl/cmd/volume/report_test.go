@@ -0,0 +1,112 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package volume
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newPVC(name, component, pod, volume string, annotations map[string]string) v1.PersistentVolumeClaim {
+	sc := "local-storage"
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[label.AnnPodNameKey] = pod
+	return v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      map[string]string{label.ComponentLabelKey: component},
+			Annotations: annotations,
+		},
+		Spec: v1.PersistentVolumeClaimSpec{StorageClassName: &sc, VolumeName: volume},
+		Status: v1.PersistentVolumeClaimStatus{
+			Capacity: v1.ResourceList{v1.ResourceStorage: resource.MustParse("10Gi")},
+		},
+	}
+}
+
+func newPod(name, node, claim string) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1.PodSpec{
+			NodeName: node,
+			Volumes: []v1.Volume{
+				{
+					Name: "data",
+					VolumeSource: v1.VolumeSource{
+						PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	g := NewGomegaWithT(t)
+	used := resource.MustParse("5Gi")
+	tc := &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo"},
+		Status: v1alpha1.TidbClusterStatus{
+			TiKV: v1alpha1.TiKVStatus{
+				Volumes: map[string]v1alpha1.StorageVolumeStatus{
+					"tikv-demo-tikv-0": {PodName: "demo-tikv-0", UsedCapacity: &used},
+				},
+			},
+			RetainedPVCs: map[string]v1alpha1.RetainedPVCStatus{
+				"tikv-demo-tikv-2": {PodName: "demo-tikv-2"},
+			},
+		},
+	}
+	pvcs := []v1.PersistentVolumeClaim{
+		newPVC("tikv-demo-tikv-2", "tikv", "demo-tikv-2", "pv-2", nil),
+		newPVC("tikv-demo-tikv-1", "tikv", "demo-tikv-1", "pv-1", nil),
+		newPVC("tikv-demo-tikv-0", "tikv", "demo-tikv-0", "pv-0", nil),
+		newPVC("pd-demo-pd-3", "pd", "demo-pd-3", "", map[string]string{label.AnnPVCDeferDeleting: "2020-11-01T00:00:00Z"}),
+	}
+	pods := []v1.Pod{newPod("demo-tikv-0", "node-0", "tikv-demo-tikv-0")}
+	pvs := map[string]*v1.PersistentVolume{
+		"pv-2": {ObjectMeta: metav1.ObjectMeta{Name: "pv-2", Labels: map[string]string{"topology.kubernetes.io/zone": "zone-b"}}},
+	}
+	nodes := map[string]*v1.Node{
+		"node-0": {ObjectMeta: metav1.ObjectMeta{Name: "node-0", Labels: map[string]string{v1.LabelZoneFailureDomain: "zone-a"}}},
+	}
+
+	report := buildReport(tc, pvcs, pods, pvs, nodes)
+	g.Expect(report.Rows).To(HaveLen(4))
+	g.Expect(report.Rows[0].Name).To(Equal("pd-demo-pd-3"))
+	g.Expect(report.Rows[0].Status).To(Equal(VolumeOrphan))
+	g.Expect(report.Rows[1].Status).To(Equal(VolumeInUse))
+	g.Expect(report.Rows[1].Zone).To(Equal("zone-a"))
+	g.Expect(report.Rows[1].Used.String()).To(Equal("5Gi"))
+	g.Expect(report.Rows[2].Status).To(Equal(VolumeUnused))
+	g.Expect(report.Rows[3].Status).To(Equal(VolumeOrphan))
+	g.Expect(report.Rows[3].Zone).To(Equal("zone-b"))
+	g.Expect(report.Orphans()).To(Equal([]string{"pd-demo-pd-3", "tikv-demo-tikv-2"}))
+
+	msg, err := report.Render()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(msg).To(ContainSubstring("5Gi (50%)"))
+	g.Expect(msg).To(ContainSubstring("Total: 4 volumes, 40Gi capacity, 5Gi used"))
+	g.Expect(msg).To(ContainSubstring("Orphans: 2 volumes, 20Gi capacity"))
+	g.Expect(strings.Count(msg, "local-storage")).To(Equal(4))
+}
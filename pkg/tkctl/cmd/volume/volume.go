@@ -0,0 +1,273 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package volume
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/tkctl/config"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+const (
+	volumeLongDesc = `
+		Manage the volumes of tidb clusters.
+`
+	reportLongDesc = `
+		Report the PVCs of a tidb cluster with their capacity, the used size reported by the
+		kubelet, the storage class, the zone and the status, which is one of:
+
+		* InUse: the PVC is mounted by a pod
+		* Unused: the PVC is not mounted by any pod
+		* Orphan: the PVC is left by a scale-in, i.e. it's marked to be deleted by the operator
+		  or retained in status.retainedPVCs, and not mounted by any pod
+
+		With --prune, the orphan PVCs are deleted after the report. The PVs are released or
+		deleted according to their reclaim policy.
+
+		You may omit --tidbcluster option by running 'tkc use <clusterName>'.
+`
+	reportExample = `
+		# report the volumes of the current tidb cluster (set by tkctl use)
+		tkctl volume report
+
+		# report the volumes of all the tidb clusters in the namespace
+		tkctl volume report -A
+
+		# report the volumes of a specified tidb cluster and delete its orphan PVCs
+		tkctl volume report -t demo-cluster --prune
+`
+	reportUsage = `expected 'volume report -t CLUSTER_NAME' for the volume report command or
+using 'tkctl use' to set tidb cluster first.`
+)
+
+// ReportOptions contains the input to the volume report command.
+type ReportOptions struct {
+	TidbClusterName string
+	Namespace       string
+	AllClusters     bool
+	Prune           bool
+
+	TcCli   *versioned.Clientset
+	KubeCli *kubernetes.Clientset
+
+	genericclioptions.IOStreams
+}
+
+// NewReportOptions returns a ReportOptions
+func NewReportOptions(streams genericclioptions.IOStreams) *ReportOptions {
+	return &ReportOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdVolume creates the volume command which manages the volumes of tidb clusters
+func NewCmdVolume(tkcContext *config.TkcContext, streams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "volume",
+		Short: "Manage the volumes of tidb clusters",
+		Long:  volumeLongDesc,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+	cmd.AddCommand(NewCmdReport(tkcContext, streams))
+	return cmd
+}
+
+// NewCmdReport creates the volume report command
+func NewCmdReport(tkcContext *config.TkcContext, streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewReportOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:     "report",
+		Short:   "Report the volumes of tidb clusters and prune the orphan ones",
+		Long:    reportLongDesc,
+		Example: reportExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmdutil.CheckErr(o.Complete(tkcContext, cmd, args))
+			cmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVarP(&o.AllClusters, "all-clusters", "A", false, "Report the volumes of all the tidb clusters in the namespace.")
+	cmd.Flags().BoolVar(&o.Prune, "prune", false, "Delete the orphan PVCs left by scale-ins.")
+	return cmd
+}
+
+func (o *ReportOptions) Complete(tkcContext *config.TkcContext, cmd *cobra.Command, args []string) error {
+	clientConfig, err := tkcContext.ToTkcClientConfig()
+	if err != nil {
+		return err
+	}
+
+	if tidbClusterName, ok := clientConfig.TidbClusterName(); ok {
+		o.TidbClusterName = tidbClusterName
+	} else if !o.AllClusters {
+		return cmdutil.UsageErrorf(cmd, reportUsage)
+	}
+
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return err
+	}
+	o.Namespace = namespace
+
+	restConfig, err := clientConfig.RestConfig()
+	if err != nil {
+		return err
+	}
+	tcCli, err := versioned.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	o.TcCli = tcCli
+	kubeCli, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	o.KubeCli = kubeCli
+
+	return nil
+}
+
+func (o *ReportOptions) Run() error {
+	var tcs []v1alpha1.TidbCluster
+	if o.AllClusters {
+		tcList, err := o.TcCli.PingcapV1alpha1().TidbClusters(o.Namespace).List(metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		tcs = tcList.Items
+	} else {
+		tc, err := o.TcCli.PingcapV1alpha1().TidbClusters(o.Namespace).Get(o.TidbClusterName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		tcs = append(tcs, *tc)
+	}
+
+	podList, err := o.KubeCli.CoreV1().Pods(o.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	nodes := map[string]*v1.Node{}
+	nodeList, err := o.KubeCli.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		// the nodes may be forbidden to namespaced users, the zones are then only looked up from the PVs
+		if !apierrors.IsForbidden(err) {
+			return err
+		}
+	} else {
+		for i := range nodeList.Items {
+			nodes[nodeList.Items[i].Name] = &nodeList.Items[i]
+		}
+	}
+
+	for i := range tcs {
+		tc := &tcs[i]
+		report, err := o.report(tc, podList.Items, nodes)
+		if err != nil {
+			return err
+		}
+		msg, err := report.Render()
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(o.Out, msg)
+		if o.Prune {
+			if err := o.prune(report); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (o *ReportOptions) report(tc *v1alpha1.TidbCluster, pods []v1.Pod, nodes map[string]*v1.Node) (*volumeReport, error) {
+	pvcList, err := o.KubeCli.CoreV1().PersistentVolumeClaims(tc.Namespace).List(metav1.ListOptions{
+		LabelSelector: label.New().Instance(tc.Name).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	pvs := map[string]*v1.PersistentVolume{}
+	for _, pvc := range pvcList.Items {
+		if pvc.Spec.VolumeName == "" {
+			continue
+		}
+		pv, err := o.KubeCli.CoreV1().PersistentVolumes().Get(pvc.Spec.VolumeName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		pvs[pv.Name] = pv
+	}
+	return buildReport(tc, pvcList.Items, pods, pvs, nodes), nil
+}
+
+// prune deletes the orphan PVCs in the report, the deletion is skipped if a PVC has been
+// recreated or mounted by a pod since the report.
+func (o *ReportOptions) prune(report *volumeReport) error {
+	ns := report.TidbCluster.Namespace
+	for _, name := range report.Orphans() {
+		pvc, err := o.KubeCli.CoreV1().PersistentVolumeClaims(ns).Get(name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if inUse, err := o.isMounted(pvc); err != nil {
+			return err
+		} else if inUse {
+			fmt.Fprintf(o.Out, "PVC %s/%s is mounted by a pod now, skip deleting it\n", ns, name)
+			continue
+		}
+		err = o.KubeCli.CoreV1().PersistentVolumeClaims(ns).Delete(name, &metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{UID: &pvc.UID},
+		})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		fmt.Fprintf(o.Out, "PVC %s/%s deleted\n", ns, name)
+	}
+	return nil
+}
+
+func (o *ReportOptions) isMounted(pvc *v1.PersistentVolumeClaim) (bool, error) {
+	podList, err := o.KubeCli.CoreV1().Pods(pvc.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return false, err
+	}
+	for _, pod := range podList.Items {
+		for _, vol := range pod.Spec.Volumes {
+			if vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName == pvc.Name {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
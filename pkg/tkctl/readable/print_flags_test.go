@@ -162,7 +162,58 @@ foo    0/0              <none>/<none>   <none>/<none>   0          <unknown>   <
 					},
 				},
 			},
-			expectedOutput: "NAME      PD    TIKV   TIDB   AGE\ncluster   2/3   2/3    2/3    <unknown>\n",
+			expectedOutput: "NAME      VERSION   PD    TIKV   TIDB   READY    SYNCED      ALERTS   AGE\ncluster   <none>    2/3   2/3    2/3    <none>   <unknown>   <none>   <unknown>\n",
+		},
+		{
+			name: "unhealthy tidb cluster",
+			testObject: &v1alpha1.TidbCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "cluster",
+				},
+				Spec: v1alpha1.TidbClusterSpec{
+					Version: "v4.0.8",
+				},
+				Status: v1alpha1.TidbClusterStatus{
+					Conditions: []v1alpha1.TidbClusterCondition{
+						{
+							Type:   v1alpha1.TidbClusterReady,
+							Status: corev1.ConditionFalse,
+							Reason: "PDUnhealthy",
+						},
+						{
+							Type:   v1alpha1.TidbClusterFailoverLimited,
+							Status: corev1.ConditionTrue,
+						},
+					},
+				},
+			},
+			expectedOutput: "NAME      VERSION   PD       TIKV     TIDB     READY   SYNCED      ALERTS                        AGE\ncluster   v4.0.8    <none>   <none>   <none>   False   <unknown>   PDUnhealthy,FailoverLimited   <unknown>\n",
+		},
+		{
+			name: "dm cluster",
+			testObject: &v1alpha1.DMCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "dm",
+				},
+				Spec: v1alpha1.DMClusterSpec{
+					Version: "v2.0.0",
+				},
+				Status: v1alpha1.DMClusterStatus{
+					Master: v1alpha1.MasterStatus{
+						StatefulSet: &apps.StatefulSetStatus{
+							Replicas:      3,
+							ReadyReplicas: 3,
+						},
+					},
+					Conditions: []v1alpha1.DMClusterCondition{
+						{
+							Type:   v1alpha1.DMClusterReady,
+							Status: corev1.ConditionTrue,
+						},
+					},
+				},
+			},
+			expectedOutput: "NAME   VERSION   MASTER   WORKER   READY   SYNCED      ALERTS   AGE\ndm     v2.0.0    3/3      <none>   True    <unknown>   <none>   <unknown>\n",
 		},
 		{
 			name: "tikv list",
@@ -16,11 +16,13 @@ package readable
 import (
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/label"
 	"github.com/pingcap/tidb-operator/pkg/tkctl/alias"
+	apps "k8s.io/api/apps/v1"
 	apiv1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -56,13 +58,32 @@ type podBasicColumns struct {
 func AddHandlers(h printers.PrintHandler) {
 	tidbClusterColumns := []metav1beta1.TableColumnDefinition{
 		{Name: "Name", Type: "string", Format: "name", Description: metav1.ObjectMeta{}.SwaggerDoc()["name"]},
+		{Name: "Version", Type: "string", Description: "The version of the tidb cluster"},
 		{Name: "PD", Type: "string", Description: "The PD nodes ready status"},
 		{Name: "TiKV", Type: "string", Description: "The TiKV nodes ready status"},
 		{Name: "TiDB", Type: "string", Description: "The TiDB nodes ready status"},
+		{Name: "Ready", Type: "string", Description: "The status of the Ready condition"},
+		{Name: "Synced", Type: "string", Description: "The time since the operator last updated the conditions"},
+		{Name: "Alerts", Type: "string", Description: "The abnormal conditions of the tidb cluster"},
 		{Name: "Age", Type: "string", Description: metav1.ObjectMeta{}.SwaggerDoc()["creationTimestamp"]},
+		{Name: "TiFlash", Type: "string", Priority: 1, Description: "The TiFlash nodes ready status"},
+		{Name: "TiCDC", Type: "string", Priority: 1, Description: "The TiCDC nodes ready status"},
+		{Name: "Pump", Type: "string", Priority: 1, Description: "The Pump nodes ready status"},
 	}
 	h.TableHandler(tidbClusterColumns, printTidbClusterList)
 	h.TableHandler(tidbClusterColumns, printTidbCluster)
+	dmClusterColumns := []metav1beta1.TableColumnDefinition{
+		{Name: "Name", Type: "string", Format: "name", Description: metav1.ObjectMeta{}.SwaggerDoc()["name"]},
+		{Name: "Version", Type: "string", Description: "The version of the dm cluster"},
+		{Name: "Master", Type: "string", Description: "The dm-master nodes ready status"},
+		{Name: "Worker", Type: "string", Description: "The dm-worker nodes ready status"},
+		{Name: "Ready", Type: "string", Description: "The status of the Ready condition"},
+		{Name: "Synced", Type: "string", Description: "The time since the operator last updated the conditions"},
+		{Name: "Alerts", Type: "string", Description: "The abnormal conditions of the dm cluster"},
+		{Name: "Age", Type: "string", Description: metav1.ObjectMeta{}.SwaggerDoc()["creationTimestamp"]},
+	}
+	h.TableHandler(dmClusterColumns, printDMClusterList)
+	h.TableHandler(dmClusterColumns, printDMCluster)
 	// TODO: separate different column definitions for PD/TiKV/TiDB Pod,
 	// e.g. show store-id for tikv, show member-id for pd
 	commonPodColumns := []metav1beta1.TableColumnDefinition{
@@ -104,6 +125,18 @@ func AddHandlers(h printers.PrintHandler) {
 	}
 	h.TableHandler(volumeColumns, printVolume)
 	h.TableHandler(volumeColumns, printVolumeList)
+	backupColumns := []metav1beta1.TableColumnDefinition{
+		{Name: "Name", Type: "string", Format: "name", Description: metav1.ObjectMeta{}.SwaggerDoc()["name"]},
+		{Name: "Tool", Type: "string", Description: "The tool taking the backup, BR or dumpling"},
+		{Name: "Status", Type: "string", Description: "The phase of the backup"},
+		{Name: "Size", Type: "string", Description: "The data size of the backup"},
+		{Name: "CommitTS", Type: "string", Description: "The snapshot time point of the backup"},
+		{Name: "Completed", Type: "string", Description: "The time since the backup was completed"},
+		{Name: "Age", Type: "string", Description: metav1.ObjectMeta{}.SwaggerDoc()["creationTimestamp"]},
+		{Name: "Path", Type: "string", Priority: 1, Description: "The location of the backup"},
+	}
+	h.TableHandler(backupColumns, printBackup)
+	h.TableHandler(backupColumns, printBackupList)
 }
 
 func printTidbClusterList(tcs *v1alpha1.TidbClusterList, options printers.GenerateOptions) ([]metav1beta1.TableRow, error) {
@@ -122,25 +155,162 @@ func printTidbCluster(tc *v1alpha1.TidbCluster, options printers.GenerateOptions
 	row := metav1beta1.TableRow{
 		Object: runtime.RawExtension{Object: tc},
 	}
-	pdReady := unset
-	if tc.Status.PD.StatefulSet != nil {
-		pdReady = fmt.Sprintf("%d/%d", tc.Status.PD.StatefulSet.ReadyReplicas, tc.Status.PD.StatefulSet.Replicas)
+	ready := unset
+	if cond := getTidbClusterCondition(tc.Status.Conditions, v1alpha1.TidbClusterReady); cond != nil {
+		ready = string(cond.Status)
+	}
+	alerts := unset
+	if a := TidbClusterAlerts(tc); len(a) > 0 {
+		alerts = strings.Join(a, ",")
 	}
-	tikvReady := unset
-	if tc.Status.TiKV.StatefulSet != nil {
-		tikvReady = fmt.Sprintf("%d/%d", tc.Status.TiKV.StatefulSet.ReadyReplicas, tc.Status.TiKV.StatefulSet.Replicas)
+
+	row.Cells = append(row.Cells,
+		tc.Name,
+		orUnset(tc.Spec.Version),
+		readyReplicas(tc.Status.PD.StatefulSet),
+		readyReplicas(tc.Status.TiKV.StatefulSet),
+		readyReplicas(tc.Status.TiDB.StatefulSet),
+		ready,
+		translateTimestampSince(TidbClusterLastSyncTime(tc)),
+		alerts,
+		translateTimestampSince(tc.CreationTimestamp))
+
+	if options.Wide {
+		row.Cells = append(row.Cells,
+			readyReplicas(tc.Status.TiFlash.StatefulSet),
+			readyReplicas(tc.Status.TiCDC.StatefulSet),
+			readyReplicas(tc.Status.Pump.StatefulSet))
 	}
-	tidbReady := unset
-	if tc.Status.TiDB.StatefulSet != nil {
-		tidbReady = fmt.Sprintf("%d/%d", tc.Status.TiDB.StatefulSet.ReadyReplicas, tc.Status.TiDB.StatefulSet.Replicas)
+	return []metav1beta1.TableRow{row}, nil
+}
+
+func printDMClusterList(dcs *v1alpha1.DMClusterList, options printers.GenerateOptions) ([]metav1beta1.TableRow, error) {
+	rows := make([]metav1beta1.TableRow, 0, len(dcs.Items))
+	for i := range dcs.Items {
+		r, err := printDMCluster(&dcs.Items[i], options)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, r...)
 	}
-	age := translateTimestampSince(tc.CreationTimestamp)
+	return rows, nil
+}
 
-	row.Cells = append(row.Cells, tc.Name, pdReady, tikvReady, tidbReady, age)
+func printDMCluster(dc *v1alpha1.DMCluster, options printers.GenerateOptions) ([]metav1beta1.TableRow, error) {
+	row := metav1beta1.TableRow{
+		Object: runtime.RawExtension{Object: dc},
+	}
+	ready := unset
+	alerts := unset
+	if a := DMClusterAlerts(dc); len(a) > 0 {
+		alerts = strings.Join(a, ",")
+	}
+	for _, cond := range dc.Status.Conditions {
+		if cond.Type == v1alpha1.DMClusterReady {
+			ready = string(cond.Status)
+		}
+	}
 
+	row.Cells = append(row.Cells,
+		dc.Name,
+		orUnset(dc.Spec.Version),
+		readyReplicas(dc.Status.Master.StatefulSet),
+		readyReplicas(dc.Status.Worker.StatefulSet),
+		ready,
+		translateTimestampSince(DMClusterLastSyncTime(dc)),
+		alerts,
+		translateTimestampSince(dc.CreationTimestamp))
 	return []metav1beta1.TableRow{row}, nil
 }
 
+// TidbClusterAlerts returns the abnormal conditions of the tidb cluster, i.e. the reason
+// of an unsatisfied Ready condition and the types of the true problem conditions.
+func TidbClusterAlerts(tc *v1alpha1.TidbCluster) []string {
+	var alerts []string
+	if tc.Spec.Paused {
+		alerts = append(alerts, "Paused")
+	}
+	for _, cond := range tc.Status.Conditions {
+		switch cond.Type {
+		case v1alpha1.TidbClusterReady, v1alpha1.TidbClusterAvailable:
+			if cond.Status != v1.ConditionTrue {
+				alerts = append(alerts, alertOf(string(cond.Type), cond.Reason))
+			}
+		case v1alpha1.TidbClusterDegraded, v1alpha1.TidbClusterPDDegraded, v1alpha1.TidbClusterFailoverLimited:
+			if cond.Status == v1.ConditionTrue {
+				alerts = append(alerts, string(cond.Type))
+			}
+		}
+	}
+	return alerts
+}
+
+// DMClusterAlerts returns the abnormal conditions of the dm cluster.
+func DMClusterAlerts(dc *v1alpha1.DMCluster) []string {
+	var alerts []string
+	if dc.Spec.Paused {
+		alerts = append(alerts, "Paused")
+	}
+	for _, cond := range dc.Status.Conditions {
+		if cond.Type == v1alpha1.DMClusterReady && cond.Status != v1.ConditionTrue {
+			alerts = append(alerts, alertOf(string(cond.Type), cond.Reason))
+		}
+	}
+	return alerts
+}
+
+// TidbClusterLastSyncTime returns the last time the operator updated the conditions of the tidb cluster.
+func TidbClusterLastSyncTime(tc *v1alpha1.TidbCluster) metav1.Time {
+	var last metav1.Time
+	for _, cond := range tc.Status.Conditions {
+		if last.Before(&cond.LastUpdateTime) {
+			last = cond.LastUpdateTime
+		}
+	}
+	return last
+}
+
+// DMClusterLastSyncTime returns the last time the operator updated the conditions of the dm cluster.
+func DMClusterLastSyncTime(dc *v1alpha1.DMCluster) metav1.Time {
+	var last metav1.Time
+	for _, cond := range dc.Status.Conditions {
+		if last.Before(&cond.LastUpdateTime) {
+			last = cond.LastUpdateTime
+		}
+	}
+	return last
+}
+
+func getTidbClusterCondition(conditions []v1alpha1.TidbClusterCondition, condType v1alpha1.TidbClusterConditionType) *v1alpha1.TidbClusterCondition {
+	for i := range conditions {
+		if conditions[i].Type == condType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+func alertOf(condType, reason string) string {
+	if reason == "" {
+		return "Not" + condType
+	}
+	return reason
+}
+
+func readyReplicas(sts *apps.StatefulSetStatus) string {
+	if sts == nil {
+		return unset
+	}
+	return fmt.Sprintf("%d/%d", sts.ReadyReplicas, sts.Replicas)
+}
+
+func orUnset(s string) string {
+	if s == "" {
+		return unset
+	}
+	return s
+}
+
 func printPodList(podList *v1.PodList, options printers.GenerateOptions) ([]metav1beta1.TableRow, error) {
 	rows := make([]metav1beta1.TableRow, 0, len(podList.Items))
 	for i := range podList.Items {
@@ -206,6 +376,54 @@ func printTikvList(tikvList *alias.TikvList, options printers.GenerateOptions) (
 	return metaTableRows, nil
 }
 
+func printBackupList(backups *v1alpha1.BackupList, options printers.GenerateOptions) ([]metav1beta1.TableRow, error) {
+	rows := make([]metav1beta1.TableRow, 0, len(backups.Items))
+	for i := range backups.Items {
+		r, err := printBackup(&backups.Items[i], options)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, r...)
+	}
+	return rows, nil
+}
+
+func printBackup(backup *v1alpha1.Backup, options printers.GenerateOptions) ([]metav1beta1.TableRow, error) {
+	row := metav1beta1.TableRow{
+		Object: runtime.RawExtension{Object: backup},
+	}
+	tool := "dumpling"
+	if backup.Spec.BR != nil {
+		tool = "br"
+	}
+	phase := string(backup.Status.Phase)
+	if phase == "" {
+		phase = unset
+	}
+	size := backup.Status.BackupSizeReadable
+	if size == "" {
+		size = unset
+	}
+	commitTs := backup.Status.CommitTs
+	if commitTs == "" {
+		commitTs = unset
+	}
+	completed := unset
+	if v1alpha1.IsBackupComplete(backup) {
+		completed = translateTimestampSince(backup.Status.TimeCompleted)
+	}
+
+	row.Cells = append(row.Cells, backup.Name, tool, phase, size, commitTs, completed, translateTimestampSince(backup.CreationTimestamp))
+	if options.Wide {
+		backupPath := backup.Status.BackupPath
+		if backupPath == "" {
+			backupPath = unset
+		}
+		row.Cells = append(row.Cells, backupPath)
+	}
+	return []metav1beta1.TableRow{row}, nil
+}
+
 func printVolumeList(volumeList *v1.PersistentVolumeList, options printers.GenerateOptions) ([]metav1beta1.TableRow, error) {
 	rows := make([]metav1beta1.TableRow, 0, len(volumeList.Items))
 	for i := range volumeList.Items {
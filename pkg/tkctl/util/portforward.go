@@ -0,0 +1,85 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// ForwardPort forwards a random local port to the port of the pod, the forwarding is stopped by closing the returned channel.
+func ForwardPort(kubeCli kubernetes.Interface, restConfig *restclient.Config, pod *v1.Pod, port int, errOut io.Writer) (uint16, chan struct{}, error) {
+	transport, upgrader, err := spdy.RoundTripperFor(restConfig)
+	if err != nil {
+		return 0, nil, err
+	}
+	req := kubeCli.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+
+	stop := make(chan struct{})
+	ready := make(chan struct{})
+	fw, err := portforward.New(dialer, []string{fmt.Sprintf("0:%d", port)}, stop, ready, ioutil.Discard, errOut)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fw.ForwardPorts()
+	}()
+	select {
+	case <-ready:
+	case err := <-errCh:
+		close(stop)
+		return 0, nil, err
+	}
+
+	ports, err := fw.GetPorts()
+	if err != nil {
+		close(stop)
+		return 0, nil, err
+	}
+	return ports[0].Local, stop, nil
+}
+
+// GetRunningPod returns a running pod of the component of the tidb cluster.
+func GetRunningPod(kubeCli kubernetes.Interface, tc *v1alpha1.TidbCluster, component string) (*v1.Pod, error) {
+	podList, err := kubeCli.CoreV1().Pods(tc.Namespace).List(metav1.ListOptions{
+		LabelSelector: label.New().Instance(tc.Name).Component(component).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range podList.Items {
+		if podList.Items[i].Status.Phase == v1.PodRunning {
+			return &podList.Items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no running %s pod is found in tidb cluster %s/%s", component, tc.Namespace, tc.Name)
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ClientTLSFiles are the paths of the files of a client certificate.
+type ClientTLSFiles struct {
+	CA   string
+	Cert string
+	Key  string
+}
+
+// WriteClusterClientTLS writes the CA, the certificate and the key in the cluster client secret
+// of the tidb cluster into the dir, which are used by the ctl tools to access the components.
+func WriteClusterClientTLS(kubeCli kubernetes.Interface, tc *v1alpha1.TidbCluster, dir string) (*ClientTLSFiles, error) {
+	secret, err := getClusterClientSecret(kubeCli, tc)
+	if err != nil {
+		return nil, err
+	}
+	files := &ClientTLSFiles{
+		CA:   filepath.Join(dir, v1.ServiceAccountRootCAKey),
+		Cert: filepath.Join(dir, v1.TLSCertKey),
+		Key:  filepath.Join(dir, v1.TLSPrivateKeyKey),
+	}
+	for key, path := range map[string]string{
+		v1.ServiceAccountRootCAKey: files.CA,
+		v1.TLSCertKey:              files.Cert,
+		v1.TLSPrivateKeyKey:        files.Key,
+	} {
+		data, ok := secret.Data[key]
+		if !ok {
+			return nil, fmt.Errorf("no %s in secret %s/%s", key, secret.Namespace, secret.Name)
+		}
+		if err := ioutil.WriteFile(path, data, 0600); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// ClusterClientTLSConfig returns the TLS config with the CA and the certificate in the cluster
+// client secret of the tidb cluster, the serverName is the name verified against the server certificate.
+func ClusterClientTLSConfig(kubeCli kubernetes.Interface, tc *v1alpha1.TidbCluster, serverName string) (*tls.Config, error) {
+	secret, err := getClusterClientSecret(kubeCli, tc)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.X509KeyPair(secret.Data[v1.TLSCertKey], secret.Data[v1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the certificate in secret %s/%s: %v", secret.Namespace, secret.Name, err)
+	}
+	rootCAs := x509.NewCertPool()
+	if !rootCAs.AppendCertsFromPEM(secret.Data[v1.ServiceAccountRootCAKey]) {
+		return nil, fmt.Errorf("unable to load the CA in secret %s/%s", secret.Namespace, secret.Name)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      rootCAs,
+		ServerName:   serverName,
+	}, nil
+}
+
+func getClusterClientSecret(kubeCli kubernetes.Interface, tc *v1alpha1.TidbCluster) (*v1.Secret, error) {
+	return kubeCli.CoreV1().Secrets(tc.Namespace).Get(util.ClusterClientTLSSecretName(tc.Name), metav1.GetOptions{})
+}
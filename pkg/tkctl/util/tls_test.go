@@ -0,0 +1,54 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWriteClusterClientTLS(t *testing.T) {
+	g := NewGomegaWithT(t)
+	tc := &v1alpha1.TidbCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo"}}
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "demo-cluster-client-secret"},
+		Data: map[string][]byte{
+			v1.ServiceAccountRootCAKey: []byte("ca"),
+			v1.TLSCertKey:              []byte("cert"),
+			v1.TLSPrivateKeyKey:        []byte("key"),
+		},
+	}
+
+	dir, err := ioutil.TempDir("", "tls")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	_, err = WriteClusterClientTLS(kubefake.NewSimpleClientset(), tc, dir)
+	g.Expect(err).To(HaveOccurred())
+
+	files, err := WriteClusterClientTLS(kubefake.NewSimpleClientset(secret), tc, dir)
+	g.Expect(err).NotTo(HaveOccurred())
+	for path, expected := range map[string]string{files.CA: "ca", files.Cert: "cert", files.Key: "key"} {
+		data, err := ioutil.ReadFile(path)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(string(data)).To(Equal(expected))
+	}
+}
@@ -13,7 +13,12 @@
 
 package util
 
-import v1 "k8s.io/api/core/v1"
+import (
+	"strings"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+)
 
 const (
 	DockerSocket = "/var/run/docker.sock"
@@ -42,3 +47,18 @@ func MakeDockerSocketMount(hostDockerSocket string, readOnly bool) (volume v1.Vo
 func GetTidbServiceName(tc string) string {
 	return tc + "-tidb"
 }
+
+// IsBackupOf returns whether the backup is taken from the tidb cluster, either by BR or by dumpling.
+func IsBackupOf(backup *v1alpha1.Backup, tc *v1alpha1.TidbCluster) bool {
+	if br := backup.Spec.BR; br != nil {
+		ns := br.ClusterNamespace
+		if ns == "" {
+			ns = backup.Namespace
+		}
+		return br.Cluster == tc.Name && ns == tc.Namespace
+	}
+	if from := backup.Spec.From; from != nil {
+		return backup.Namespace == tc.Namespace && strings.HasPrefix(from.Host, GetTidbServiceName(tc.Name))
+	}
+	return false
+}
@@ -14,6 +14,7 @@
 package upgrader
 
 import (
+	"encoding/json"
 	"fmt"
 
 	asappsv1 "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
@@ -90,6 +91,9 @@ func (u *upgrader) Upgrade() error {
 			if anns := deleteSlotAnns(tc); len(anns) > 0 {
 				return fmt.Errorf("Upgrader: TidbCluster %s/%s has delete slot annotations %v, please remove them before enabling AdvancedStatefulSet feature", tc.Namespace, tc.Name, anns)
 			}
+			if fields := deleteSlotFields(tc); len(fields) > 0 {
+				return fmt.Errorf("Upgrader: TidbCluster %s/%s has delete slots %v in spec, please remove them before enabling AdvancedStatefulSet feature", tc.Namespace, tc.Name, fields)
+			}
 		}
 		klog.Infof("Upgrader: found %d Kubernetes StatefulSets owned by TidbCluster, trying to migrate one by one", len(stsToMigrate))
 		for _, sts := range stsToMigrate {
@@ -111,23 +115,104 @@ func (u *upgrader) Upgrade() error {
 			return err
 		}
 		stsToMigrate := make([]asappsv1.StatefulSet, 0)
+		tidbClusters := make([]*v1alpha1.TidbCluster, 0)
 		for _, sts := range stsList.Items {
-			if ok, _ := util.IsOwnedByTidbCluster(&sts); ok {
+			if ok, tcRef := util.IsOwnedByTidbCluster(&sts); ok {
 				stsToMigrate = append(stsToMigrate, sts)
+				tc, err := u.cli.PingcapV1alpha1().TidbClusters(sts.Namespace).Get(tcRef.Name, metav1.GetOptions{})
+				if err != nil && !apierrors.IsNotFound(err) {
+					return err
+				}
+				if tc != nil {
+					tidbClusters = append(tidbClusters, tc)
+				}
 			}
 		}
 		if len(stsToMigrate) <= 0 {
 			klog.Infof("Upgrader: found %d Advanced StatefulSets owned by TidbCluster, nothing need to do", len(stsToMigrate))
 			return nil
 		}
-		// The upgrader cannot migrate Advanced StatefulSets to Kubernetes
-		// StatefulSets automatically right now.
-		// TODO try our best to allow users to revert AdvancedStatefulSet feature automaticaly
-		return fmt.Errorf("Upgrader: found %d Advanced StatefulSets owned by TidbCluster, the operator cann't run with AdvancedStatefulSet feature disabled", len(stsToMigrate))
+		klog.Infof("Upgrader: %d Advanced Statefulsets owned by TidbCluster should be migrated to Kubernetes Statefulsets", len(stsToMigrate))
+		// Kubernetes StatefulSets can only manage consecutive ordinals, the
+		// delete slots must not be in use, otherwise the pods out of the
+		// range would be lost.
+		for _, tc := range tidbClusters {
+			if anns := deleteSlotAnns(tc); len(anns) > 0 {
+				return fmt.Errorf("Upgrader: TidbCluster %s/%s has delete slot annotations %v, please remove them before disabling AdvancedStatefulSet feature", tc.Namespace, tc.Name, anns)
+			}
+			if fields := deleteSlotFields(tc); len(fields) > 0 {
+				return fmt.Errorf("Upgrader: TidbCluster %s/%s has delete slots %v in spec, please remove them before disabling AdvancedStatefulSet feature", tc.Namespace, tc.Name, fields)
+			}
+		}
+		for _, sts := range stsToMigrate {
+			if deleteSlots := helper.GetDeleteSlots(&sts); deleteSlots.Len() > 0 {
+				return fmt.Errorf("Upgrader: Advanced StatefulSet %s/%s has delete slots %v, please scale in or out to remove them before disabling AdvancedStatefulSet feature", sts.Namespace, sts.Name, deleteSlots.List())
+			}
+		}
+		klog.Infof("Upgrader: found %d Advanced StatefulSets owned by TidbCluster, trying to migrate one by one", len(stsToMigrate))
+		for _, sts := range stsToMigrate {
+			if err := downgrade(u.kubeCli, u.asCli, &sts); err != nil {
+				return err
+			}
+			klog.Infof("Upgrader: successfully migrated Advanced StatefulSet %s/%s", sts.Namespace, sts.Name)
+		}
+	}
+	return nil
+}
+
+// downgrade migrates the Advanced StatefulSet to a Kubernetes StatefulSet.
+// The Kubernetes StatefulSet is created first, then the Advanced StatefulSet
+// is deleted with its pods and controller revisions orphaned, which are
+// adopted by the Kubernetes StatefulSet later. PVCs are not owned by
+// StatefulSets, so they are kept as is. It's safe to retry if it fails in
+// the middle.
+func downgrade(kubeCli kubernetes.Interface, asCli asclientset.Interface, asts *asappsv1.StatefulSet) error {
+	sts, err := toBuiltinStatefulSet(asts)
+	if err != nil {
+		return err
+	}
+	_, err = kubeCli.AppsV1().StatefulSets(sts.Namespace).Create(sts)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	orphan := metav1.DeletePropagationOrphan
+	err = asCli.AppsV1().StatefulSets(asts.Namespace).Delete(asts.Name, &metav1.DeleteOptions{
+		PropagationPolicy: &orphan,
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
 	}
 	return nil
 }
 
+// toBuiltinStatefulSet converts the Advanced StatefulSet to a Kubernetes
+// StatefulSet to be created, they share the same schema.
+func toBuiltinStatefulSet(asts *asappsv1.StatefulSet) (*appsv1.StatefulSet, error) {
+	data, err := json.Marshal(asts)
+	if err != nil {
+		return nil, err
+	}
+	sts := &appsv1.StatefulSet{}
+	if err := json.Unmarshal(data, sts); err != nil {
+		return nil, err
+	}
+	sts.TypeMeta = metav1.TypeMeta{
+		Kind:       "StatefulSet",
+		APIVersion: appsv1.SchemeGroupVersion.String(),
+	}
+	sts.ResourceVersion = ""
+	sts.UID = ""
+	sts.SelfLink = ""
+	sts.CreationTimestamp = metav1.Time{}
+	sts.Generation = 0
+	sts.Status = appsv1.StatefulSetStatus{}
+	delete(sts.Annotations, helper.DeleteSlotsAnn)
+	if len(sts.Annotations) == 0 {
+		sts.Annotations = nil
+	}
+	return sts, nil
+}
+
 func deleteSlotAnns(tc *v1alpha1.TidbCluster) map[string]string {
 	anns := make(map[string]string)
 	if tc == nil || tc.Annotations == nil {
@@ -141,6 +226,28 @@ func deleteSlotAnns(tc *v1alpha1.TidbCluster) map[string]string {
 	return anns
 }
 
+// deleteSlotFields returns the non-empty delete slots in the spec of the
+// TidbCluster, keyed by the field path.
+func deleteSlotFields(tc *v1alpha1.TidbCluster) map[string][]int32 {
+	fields := make(map[string][]int32)
+	if tc == nil {
+		return fields
+	}
+	if tc.Spec.PD != nil && len(tc.Spec.PD.DeleteSlots) > 0 {
+		fields["spec.pd.deleteSlots"] = tc.Spec.PD.DeleteSlots
+	}
+	if tc.Spec.TiDB != nil && len(tc.Spec.TiDB.DeleteSlots) > 0 {
+		fields["spec.tidb.deleteSlots"] = tc.Spec.TiDB.DeleteSlots
+	}
+	if tc.Spec.TiKV != nil && len(tc.Spec.TiKV.DeleteSlots) > 0 {
+		fields["spec.tikv.deleteSlots"] = tc.Spec.TiKV.DeleteSlots
+	}
+	if tc.Spec.TiFlash != nil && len(tc.Spec.TiFlash.DeleteSlots) > 0 {
+		fields["spec.tiflash.deleteSlots"] = tc.Spec.TiFlash.DeleteSlots
+	}
+	return fields
+}
+
 func NewUpgrader(kubeCli kubernetes.Interface, cli versioned.Interface, asCli asclientset.Interface, ns string) Interface {
 	return &upgrader{kubeCli, cli, asCli, ns}
 }
@@ -20,6 +20,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	asappsv1 "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+	"github.com/pingcap/advanced-statefulset/client/apis/apps/v1/helper"
 	asclientsetfake "github.com/pingcap/advanced-statefulset/client/client/clientset/versioned/fake"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	versionedfake "github.com/pingcap/tidb-operator/pkg/client/clientset/versioned/fake"
@@ -160,6 +161,53 @@ func TestDeleteSlotAnns(t *testing.T) {
 	}
 }
 
+func TestDeleteSlotFields(t *testing.T) {
+	tests := []struct {
+		name string
+		tc   *v1alpha1.TidbCluster
+		want map[string][]int32
+	}{
+		{
+			name: "tc nil",
+			tc:   nil,
+			want: map[string][]int32{},
+		},
+		{
+			name: "no delete slots",
+			tc: &v1alpha1.TidbCluster{
+				Spec: v1alpha1.TidbClusterSpec{
+					PD:   &v1alpha1.PDSpec{},
+					TiKV: &v1alpha1.TiKVSpec{},
+				},
+			},
+			want: map[string][]int32{},
+		},
+		{
+			name: "has delete slots",
+			tc: &v1alpha1.TidbCluster{
+				Spec: v1alpha1.TidbClusterSpec{
+					PD: &v1alpha1.PDSpec{},
+					TiKV: &v1alpha1.TiKVSpec{
+						DeleteSlots: []int32{1, 2},
+					},
+				},
+			},
+			want: map[string][]int32{
+				"spec.tikv.deleteSlots": {1, 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := deleteSlotFields(tt.tc)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unexpected (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
 var (
 	ownerTCName    = "foo"
 	validOwnerRefs = []metav1.OwnerReference{
@@ -584,6 +632,122 @@ func TestUpgrade(t *testing.T) {
 				},
 			},
 			feature: "AdvancedStatefulSet=false",
+			advancedStatefulsets: []asappsv1.StatefulSet{
+				{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "StatefulSet",
+						APIVersion: "apps.pingcap.com/v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "sts1",
+						Namespace:       "sts",
+						OwnerReferences: validOwnerRefs,
+					},
+					Spec: asappsv1.StatefulSetSpec{
+						Replicas: pointer.Int32Ptr(3),
+					},
+				},
+			},
+			ns: metav1.NamespaceAll,
+			apiResourceList: []*metav1.APIResourceList{
+				{
+					GroupVersion: "apps.pingcap.com/v1",
+					APIResources: []metav1.APIResource{
+						{
+							Kind: "StatefulSet",
+						},
+					},
+				},
+			},
+			wantErr:                  false,
+			wantAdvancedStatefulsets: nil,
+			wantStatefulsets: []appsv1.StatefulSet{
+				{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "StatefulSet",
+						APIVersion: "apps/v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "sts1",
+						Namespace:       "sts",
+						OwnerReferences: validOwnerRefs,
+					},
+					Spec: appsv1.StatefulSetSpec{
+						Replicas: pointer.Int32Ptr(3),
+					},
+				},
+			},
+		},
+		{
+			name: "[AdvancedStatefulSet=false] should not migrate if asts has delete slots",
+			tidbClusters: []v1alpha1.TidbCluster{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      ownerTCName,
+						Namespace: "sts",
+					},
+				},
+			},
+			feature: "AdvancedStatefulSet=false",
+			advancedStatefulsets: []asappsv1.StatefulSet{
+				{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "StatefulSet",
+						APIVersion: "apps.pingcap.com/v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "sts1",
+						Namespace:       "sts",
+						OwnerReferences: validOwnerRefs,
+						Annotations: map[string]string{
+							helper.DeleteSlotsAnn: "[1]",
+						},
+					},
+				},
+			},
+			ns: metav1.NamespaceAll,
+			apiResourceList: []*metav1.APIResourceList{
+				{
+					GroupVersion: "apps.pingcap.com/v1",
+					APIResources: []metav1.APIResource{
+						{
+							Kind: "StatefulSet",
+						},
+					},
+				},
+			},
+			wantErr: true,
+			wantAdvancedStatefulsets: []asappsv1.StatefulSet{
+				{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "StatefulSet",
+						APIVersion: "apps.pingcap.com/v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "sts1",
+						Namespace:       "sts",
+						OwnerReferences: validOwnerRefs,
+						Annotations: map[string]string{
+							helper.DeleteSlotsAnn: "[1]",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "[AdvancedStatefulSet=false] should not migrate if tc has delete slot annotations",
+			tidbClusters: []v1alpha1.TidbCluster{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      ownerTCName,
+						Namespace: "sts",
+						Annotations: map[string]string{
+							label.AnnTiKVDeleteSlots: "[1]",
+						},
+					},
+				},
+			},
+			feature: "AdvancedStatefulSet=false",
 			advancedStatefulsets: []asappsv1.StatefulSet{
 				{
 					TypeMeta: metav1.TypeMeta{
@@ -59,6 +59,12 @@ var (
 		Description: "The desired replicas number of PD cluster",
 		JSONPath:    ".spec.pd.replicas",
 	}
+	tidbClusterPDPhaseColumn = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "Phase",
+		Type:        "string",
+		Description: "The phase of PD cluster",
+		JSONPath:    ".status.pd.phase",
+	}
 	tidbClusterTiKVColumn = extensionsobj.CustomResourceColumnDefinition{
 		Name:        "TiKV",
 		Type:        "string",
@@ -83,6 +89,26 @@ var (
 		Description: "The desired replicas number of TiKV cluster",
 		JSONPath:    ".spec.tikv.replicas",
 	}
+	tidbClusterTiKVPhaseColumn = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "Phase",
+		Type:        "string",
+		Description: "The phase of TiKV cluster",
+		JSONPath:    ".status.tikv.phase",
+	}
+	tidbClusterTiKVUsedColumn = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "Used",
+		Type:        "string",
+		Description: "The used percentage of the storage of TiKV cluster",
+		JSONPath:    ".status.tikv.storageUsage.usedPercent",
+		Priority:    1,
+	}
+	tidbClusterTiKVWorstUsedColumn = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "WorstUsed",
+		Type:        "string",
+		Description: "The highest used percentage of the storage of TiKV node",
+		JSONPath:    ".status.tikv.storageUsage.worstPodUsedPercent",
+		Priority:    1,
+	}
 	tidbClusterTiDBColumn = extensionsobj.CustomResourceColumnDefinition{
 		Name:        "TiDB",
 		Type:        "string",
@@ -101,6 +127,75 @@ var (
 		Description: "The desired replicas number of TiDB cluster",
 		JSONPath:    ".spec.tidb.replicas",
 	}
+	tidbClusterTiDBPhaseColumn = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "Phase",
+		Type:        "string",
+		Description: "The phase of TiDB cluster",
+		JSONPath:    ".status.tidb.phase",
+	}
+	tidbClusterTiFlashReadyColumn = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "TiFlash-Ready",
+		Type:        "integer",
+		Description: "The ready replicas number of TiFlash cluster",
+		JSONPath:    ".status.tiflash.statefulSet.readyReplicas",
+		Priority:    1,
+	}
+	tidbClusterTiFlashDesireColumn = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "TiFlash-Desire",
+		Type:        "integer",
+		Description: "The desired replicas number of TiFlash cluster",
+		JSONPath:    ".spec.tiflash.replicas",
+		Priority:    1,
+	}
+	tidbClusterTiFlashPhaseColumn = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "TiFlash-Phase",
+		Type:        "string",
+		Description: "The phase of TiFlash cluster",
+		JSONPath:    ".status.tiflash.phase",
+		Priority:    1,
+	}
+	tidbClusterTiCDCReadyColumn = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "TiCDC-Ready",
+		Type:        "integer",
+		Description: "The ready replicas number of TiCDC cluster",
+		JSONPath:    ".status.ticdc.statefulSet.readyReplicas",
+		Priority:    1,
+	}
+	tidbClusterTiCDCDesireColumn = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "TiCDC-Desire",
+		Type:        "integer",
+		Description: "The desired replicas number of TiCDC cluster",
+		JSONPath:    ".spec.ticdc.replicas",
+		Priority:    1,
+	}
+	tidbClusterTiCDCPhaseColumn = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "TiCDC-Phase",
+		Type:        "string",
+		Description: "The phase of TiCDC cluster",
+		JSONPath:    ".status.ticdc.phase",
+		Priority:    1,
+	}
+	tidbClusterPumpReadyColumn = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "Pump-Ready",
+		Type:        "integer",
+		Description: "The ready replicas number of Pump cluster",
+		JSONPath:    ".status.pump.statefulSet.readyReplicas",
+		Priority:    1,
+	}
+	tidbClusterPumpDesireColumn = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "Pump-Desire",
+		Type:        "integer",
+		Description: "The desired replicas number of Pump cluster",
+		JSONPath:    ".spec.pump.replicas",
+		Priority:    1,
+	}
+	tidbClusterPumpPhaseColumn = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "Pump-Phase",
+		Type:        "string",
+		Description: "The phase of Pump cluster",
+		JSONPath:    ".status.pump.phase",
+		Priority:    1,
+	}
 	dmClusteradditionalPrinterColumns []extensionsobj.CustomResourceColumnDefinition
 	dmClusterReadyColumn              = extensionsobj.CustomResourceColumnDefinition{
 		Name:     "Ready",
@@ -168,6 +263,12 @@ var (
 		Description: "The current status of the backup",
 		JSONPath:    ".status.phase",
 	}
+	backupTypeColumn = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "Type",
+		Type:        "string",
+		Description: "The type of the backup, e.g. full, db or table",
+		JSONPath:    ".spec.backupType",
+	}
 	backupPathColumn = extensionsobj.CustomResourceColumnDefinition{
 		Name:        "BackupPath",
 		Type:        "string",
@@ -202,6 +303,12 @@ var (
 		Priority:    1,
 		JSONPath:    ".status.timeCompleted",
 	}
+	backupDurationColumn = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "Duration",
+		Type:        "string",
+		Description: "The time taken by the backup",
+		JSONPath:    ".status.timeTaken",
+	}
 	restoreAdditionalPrinterColumns []extensionsobj.CustomResourceColumnDefinition
 	restoreStatusColumn             = extensionsobj.CustomResourceColumnDefinition{
 		Name:        "Status",
@@ -264,8 +371,52 @@ var (
 		Priority:    1,
 		JSONPath:    ".status.phase",
 	}
-	autoScalerPrinterColumns []extensionsobj.CustomResourceColumnDefinition
-	// TODO add The current replicas number of TiKV cluster
+	tidbClusterFederationPrinterColumns []extensionsobj.CustomResourceColumnDefinition
+	tidbClusterFederationPhase          = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "Phase",
+		Type:        "string",
+		Description: "The operation in progress across the federation",
+		JSONPath:    ".status.phase",
+	}
+	tidbClusterReplicationPrinterColumns []extensionsobj.CustomResourceColumnDefinition
+	tidbClusterReplicationPhase          = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "Phase",
+		Type:        "string",
+		Description: "The health of the replication",
+		JSONPath:    ".status.phase",
+	}
+	tidbClusterReplicationLag = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "Lag",
+		Type:        "string",
+		Description: "The lag of the checkpoint of the changefeed",
+		JSONPath:    ".status.lag",
+	}
+	tidbClusterClonePrinterColumns []extensionsobj.CustomResourceColumnDefinition
+	tidbClusterClonePhase          = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "Phase",
+		Type:        "string",
+		Description: "The step of the clone in progress",
+		JSONPath:    ".status.phase",
+	}
+	tidbClusterCloneTarget = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "Target",
+		Type:        "string",
+		Description: "The namespace of the target TidbCluster",
+		JSONPath:    ".spec.target.namespace",
+	}
+	autoScalerPrinterColumns            []extensionsobj.CustomResourceColumnDefinition
+	autoScalerTiKVCurrentReplicasColumn = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "TiKV-Current",
+		Type:        "integer",
+		Description: "The current replicas of TiKV in the cluster and the auto-scaled clusters",
+		JSONPath:    ".status.tikvReplicas.current",
+	}
+	autoScalerTiKVTargetReplicasColumn = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "TiKV-Target",
+		Type:        "integer",
+		Description: "The target replicas of TiKV in the cluster and the auto-scaled clusters",
+		JSONPath:    ".status.tikvReplicas.target",
+	}
 	autoScalerTiKVMaxReplicasColumn = extensionsobj.CustomResourceColumnDefinition{
 		Name:        "TiKV-MaxReplicas",
 		Type:        "integer",
@@ -278,7 +429,18 @@ var (
 		Description: "The minimal replicas of TiKV",
 		JSONPath:    ".spec.tikv.minReplicas",
 	}
-	// TODO add The current replicas number of TiDB cluster
+	autoScalerTiDBCurrentReplicasColumn = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "TiDB-Current",
+		Type:        "integer",
+		Description: "The current replicas of TiDB in the cluster and the auto-scaled clusters",
+		JSONPath:    ".status.tidbReplicas.current",
+	}
+	autoScalerTiDBTargetReplicasColumn = extensionsobj.CustomResourceColumnDefinition{
+		Name:        "TiDB-Target",
+		Type:        "integer",
+		Description: "The target replicas of TiDB in the cluster and the auto-scaled clusters",
+		JSONPath:    ".status.tidbReplicas.target",
+	}
 	autoScalerTiDBMaxReplicasColumn = extensionsobj.CustomResourceColumnDefinition{
 		Name:        "TiDB-MaxReplicas",
 		Type:        "integer",
@@ -319,20 +481,30 @@ var (
 func init() {
 	tidbClusteradditionalPrinterColumns = append(tidbClusteradditionalPrinterColumns,
 		tidbClusterReadyColumn,
-		tidbClusterPDColumn, tidbClusterPDStorageColumn, tidbClusterPDReadyColumn, tidbClusterPDDesireColumn,
-		tidbClusterTiKVColumn, tidbClusterTiKVStorageColumn, tidbClusterTiKVReadyColumn, tidbClusterTiKVDesireColumn,
-		tidbClusterTiDBColumn, tidbClusterTiDBReadyColumn, tidbClusterTiDBDesireColumn, tidbClusterStatusMessageColumn, ageColumn)
+		tidbClusterPDColumn, tidbClusterPDStorageColumn, tidbClusterPDReadyColumn, tidbClusterPDDesireColumn, tidbClusterPDPhaseColumn,
+		tidbClusterTiKVColumn, tidbClusterTiKVStorageColumn, tidbClusterTiKVReadyColumn, tidbClusterTiKVDesireColumn, tidbClusterTiKVPhaseColumn,
+		tidbClusterTiKVUsedColumn, tidbClusterTiKVWorstUsedColumn,
+		tidbClusterTiDBColumn, tidbClusterTiDBReadyColumn, tidbClusterTiDBDesireColumn, tidbClusterTiDBPhaseColumn,
+		tidbClusterTiFlashReadyColumn, tidbClusterTiFlashDesireColumn, tidbClusterTiFlashPhaseColumn,
+		tidbClusterTiCDCReadyColumn, tidbClusterTiCDCDesireColumn, tidbClusterTiCDCPhaseColumn,
+		tidbClusterPumpReadyColumn, tidbClusterPumpDesireColumn, tidbClusterPumpPhaseColumn,
+		tidbClusterStatusMessageColumn, ageColumn)
 	dmClusteradditionalPrinterColumns = append(dmClusteradditionalPrinterColumns,
 		dmClusterReadyColumn,
 		dmClusterMasterColumn, dmClusterMasterStorageColumn, dmClusterMasterReadyColumn, dmClusterMasterDesireColumn,
 		dmClusterWorkerColumn, dmClusterWorkerStorageColumn, dmClusterWorkerReadyColumn, dmClusterWorkerDesireColumn,
 		dmClusterStatusMessageColumn, ageColumn)
-	backupAdditionalPrinterColumns = append(backupAdditionalPrinterColumns, backupStatusColumn, backupPathColumn, backupBackupSizeColumn, backupCommitTSColumn, backupStartedColumn, backupCompletedColumn, ageColumn)
+	backupAdditionalPrinterColumns = append(backupAdditionalPrinterColumns, backupStatusColumn, backupTypeColumn, backupPathColumn, backupBackupSizeColumn,
+		backupDurationColumn, backupCommitTSColumn, backupStartedColumn, backupCompletedColumn, ageColumn)
 	restoreAdditionalPrinterColumns = append(restoreAdditionalPrinterColumns, restoreStatusColumn, restoreStartedColumn, restoreCompletedColumn, restoreCommitTSColumn, ageColumn)
 	bksAdditionalPrinterColumns = append(bksAdditionalPrinterColumns, bksScheduleColumn, bksMaxBackups, bksLastBackup, bksLastBackupTime, ageColumn)
 	tidbInitializerPrinterColumns = append(tidbInitializerPrinterColumns, tidbInitializerPhase, ageColumn)
-	autoScalerPrinterColumns = append(autoScalerPrinterColumns, autoScalerTiDBMaxReplicasColumn, autoScalerTiDBMinReplicasColumn,
-		autoScalerTiKVMaxReplicasColumn, autoScalerTiKVMinReplicasColumn, ageColumn)
+	tidbClusterFederationPrinterColumns = append(tidbClusterFederationPrinterColumns, tidbClusterFederationPhase, ageColumn)
+	tidbClusterReplicationPrinterColumns = append(tidbClusterReplicationPrinterColumns, tidbClusterReplicationPhase, tidbClusterReplicationLag, ageColumn)
+	tidbClusterClonePrinterColumns = append(tidbClusterClonePrinterColumns, tidbClusterClonePhase, tidbClusterCloneTarget, ageColumn)
+	autoScalerPrinterColumns = append(autoScalerPrinterColumns,
+		autoScalerTiDBCurrentReplicasColumn, autoScalerTiDBTargetReplicasColumn, autoScalerTiDBMaxReplicasColumn, autoScalerTiDBMinReplicasColumn,
+		autoScalerTiKVCurrentReplicasColumn, autoScalerTiKVTargetReplicasColumn, autoScalerTiKVMaxReplicasColumn, autoScalerTiKVMinReplicasColumn, ageColumn)
 	tidbMonitorAdditionalPrinterColumns = append(tidbMonitorAdditionalPrinterColumns, tidbMonitorDesiredColumn, tidbMonitorReadyColumn, tidbMonitorUpdatedColumn, ageColumn)
 }
 
@@ -371,6 +543,12 @@ func GetCrdKindFromKindName(kindName string) (v1alpha1.CrdKind, error) {
 		return v1alpha1.DefaultCrdKinds.TiDBInitializer, nil
 	case v1alpha1.TidbClusterAutoScalerKindKey:
 		return v1alpha1.DefaultCrdKinds.TidbClusterAutoScaler, nil
+	case v1alpha1.TidbClusterFederationKindKey:
+		return v1alpha1.DefaultCrdKinds.TidbClusterFederation, nil
+	case v1alpha1.TidbClusterReplicationKindKey:
+		return v1alpha1.DefaultCrdKinds.TidbClusterReplication, nil
+	case v1alpha1.TidbClusterCloneKindKey:
+		return v1alpha1.DefaultCrdKinds.TidbClusterClone, nil
 	default:
 		return v1alpha1.CrdKind{}, errors.New("unknown CrdKind Name")
 	}
@@ -394,6 +572,12 @@ func addAdditionalPrinterColumnsForCRD(crd *extensionsobj.CustomResourceDefiniti
 		crd.Spec.AdditionalPrinterColumns = tidbInitializerPrinterColumns
 	case v1alpha1.DefaultCrdKinds.TidbClusterAutoScaler.Kind:
 		crd.Spec.AdditionalPrinterColumns = autoScalerPrinterColumns
+	case v1alpha1.DefaultCrdKinds.TidbClusterFederation.Kind:
+		crd.Spec.AdditionalPrinterColumns = tidbClusterFederationPrinterColumns
+	case v1alpha1.DefaultCrdKinds.TidbClusterReplication.Kind:
+		crd.Spec.AdditionalPrinterColumns = tidbClusterReplicationPrinterColumns
+	case v1alpha1.DefaultCrdKinds.TidbClusterClone.Kind:
+		crd.Spec.AdditionalPrinterColumns = tidbClusterClonePrinterColumns
 	default:
 	}
 }
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/klog"
@@ -85,6 +86,58 @@ func NewCSR(commonName string, hostList []string, IPList []string) ([]byte, []by
 	return csr, convertKeyToPEM("RSA PRIVATE KEY", privKey), nil
 }
 
+// DecodeCertificate decodes the first PEM encoded certificate in data, e.g. tls.crt in a TLS secret
+func DecodeCertificate(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no PEM encoded certificate is found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// CheckCertificateFile checks whether the first certificate in the PEM encoded certFile is valid at now
+func CheckCertificateFile(certFile string, now time.Time) error {
+	data, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		return err
+	}
+	cert, err := DecodeCertificate(data)
+	if err != nil {
+		return fmt.Errorf("failed to decode certificate %s: %v", certFile, err)
+	}
+	if now.Before(cert.NotBefore) {
+		return fmt.Errorf("certificate %s is not valid before %s", certFile, cert.NotBefore.Format(time.RFC3339))
+	}
+	if now.After(cert.NotAfter) {
+		return fmt.Errorf("certificate %s expired at %s", certFile, cert.NotAfter.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// DecodeCertificates decodes all the PEM encoded certificates in data, e.g. ca.crt of a CA bundle
+func DecodeCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no PEM encoded certificate is found")
+	}
+	return certs, nil
+}
+
 func readCACerts(tryAppendCAFile string) (*x509.CertPool, error) {
 	// try to load system CA certs
 	rootCAs, err := x509.SystemCertPool()
@@ -18,6 +18,7 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
@@ -78,6 +79,50 @@ i6mx5O+aGtA9aZnuqCij4Tyz8LIRnM98QObd50N9otg6tamN8jSZxNQQ4Qb9CYQQ
 O+7ETPTsJ3xCwnR8gooJybQDJbw=
 -----END CERTIFICATE-----`)
 
+func TestDecodeCertificate(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	cert, err := DecodeCertificate(certData)
+	g.Expect(err).Should(BeNil())
+	g.Expect(cert.Subject.CommonName).Should(Equal("XRamp Global Certification Authority"))
+	g.Expect(cert.NotAfter.Year()).Should(Equal(2035))
+
+	_, err = DecodeCertificate([]byte("messy up data"))
+	g.Expect(err).ShouldNot(BeNil())
+}
+
+func TestDecodeCertificates(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	certs, err := DecodeCertificates(append(append(append([]byte{}, certData...), '\n'), certData...))
+	g.Expect(err).Should(BeNil())
+	g.Expect(certs).Should(HaveLen(2))
+	g.Expect(certs[1].Subject.CommonName).Should(Equal("XRamp Global Certification Authority"))
+
+	_, err = DecodeCertificates([]byte("messy up data"))
+	g.Expect(err).ShouldNot(BeNil())
+}
+
+func TestCheckCertificateFile(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	f, err := ioutil.TempFile("", "tls.crt")
+	g.Expect(err).Should(BeNil())
+	defer os.Remove(f.Name())
+	_, err = f.Write(certData)
+	g.Expect(err).Should(BeNil())
+	g.Expect(f.Close()).Should(BeNil())
+
+	g.Expect(CheckCertificateFile(f.Name(), time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))).Should(BeNil())
+	err = CheckCertificateFile(f.Name(), time.Date(2036, 1, 1, 0, 0, 0, 0, time.UTC))
+	g.Expect(err).ShouldNot(BeNil())
+	g.Expect(err.Error()).Should(ContainSubstring("expired"))
+	err = CheckCertificateFile(f.Name(), time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+	g.Expect(err).ShouldNot(BeNil())
+	g.Expect(err.Error()).Should(ContainSubstring("not valid before"))
+	g.Expect(CheckCertificateFile(f.Name()+".missing", time.Now())).ShouldNot(BeNil())
+}
+
 func TestReadCACerts(t *testing.T) {
 	g := NewGomegaWithT(t)
 
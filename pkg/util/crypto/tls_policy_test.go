@@ -0,0 +1,43 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crypto
+
+import (
+	"crypto/tls"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestParseTLSVersion(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	version, err := ParseTLSVersion("TLSv1.2")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(version).To(Equal(uint16(tls.VersionTLS12)))
+
+	_, err = ParseTLSVersion("SSLv3")
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestParseCipherSuites(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	ids, err := ParseCipherSuites([]string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(ids).To(Equal([]uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305}))
+
+	_, err = ParseCipherSuites([]string{"TLS_AES_128_GCM_SHA256"})
+	g.Expect(err).To(HaveOccurred())
+}
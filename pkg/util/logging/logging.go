@@ -0,0 +1,352 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog"
+)
+
+const (
+	// FormatText writes the logs in the klog text format with the key/value pairs appended
+	FormatText = "text"
+	// FormatJSON writes every log line as a JSON object, including the lines of klog
+	FormatJSON = "json"
+)
+
+var (
+	format = FormatText
+	levels = &moduleLevels{levels: map[string]klog.Level{}}
+
+	outputLock sync.Mutex
+	// output is where the JSON lines are written
+	output io.Writer = os.Stderr
+)
+
+// AddFlags adds the flags of the log format and the verbosity of the modules to fs
+func AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&format, "log-format", format, fmt.Sprintf("The format of the logs, one of %s, %s", FormatText, FormatJSON))
+	fs.Var(levels, "log-module-levels", "Comma separated <module>=<verbosity> pairs overriding -v for the controllers and components, e.g. tidbcluster=4,tikv=5, it can be changed at runtime by PUT /debug/flags/log-module-levels")
+}
+
+// Init applies the log format after the flags are parsed, fs is the flag set of klog
+func Init(fs *flag.FlagSet) error {
+	switch format {
+	case FormatText:
+		return nil
+	case FormatJSON:
+	default:
+		return fmt.Errorf("invalid log-format %q, it must be one of %s, %s", format, FormatText, FormatJSON)
+	}
+	// klog writes the lines to the files of the severities instead of stderr,
+	// and all of them are written to the INFO file exactly once
+	for name, value := range map[string]string{
+		"logtostderr":     "false",
+		"alsologtostderr": "false",
+		"stderrthreshold": "FATAL",
+	} {
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("failed to set klog flag %s: %v", name, err)
+		}
+	}
+	klog.SetOutputBySeverity("INFO", klogWriter{})
+	for _, severity := range []string{"WARNING", "ERROR", "FATAL"} {
+		klog.SetOutputBySeverity(severity, ioutil.Discard)
+	}
+	return nil
+}
+
+// SetModuleLevels replaces the verbosity of the modules, it is used to change them at runtime
+func SetModuleLevels(value string) (string, error) {
+	if err := levels.Set(value); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("successfully set log-module-levels to %q", levels.String()), nil
+}
+
+// moduleLevels is the flag.Value of the verbosity of the modules
+type moduleLevels struct {
+	lock   sync.RWMutex
+	levels map[string]klog.Level
+}
+
+func (m *moduleLevels) String() string {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	var pairs []string
+	for module, level := range m.levels {
+		pairs = append(pairs, fmt.Sprintf("%s=%d", module, level))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (m *moduleLevels) Set(value string) error {
+	parsed := map[string]klog.Level{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return fmt.Errorf("invalid module level %q, it must be <module>=<verbosity>", pair)
+		}
+		level, err := strconv.ParseInt(strings.TrimSpace(kv[1]), 10, 32)
+		if err != nil || level < 0 {
+			return fmt.Errorf("invalid verbosity of module %q: %q", kv[0], kv[1])
+		}
+		parsed[strings.TrimSpace(kv[0])] = klog.Level(level)
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.levels = parsed
+	return nil
+}
+
+func (m *moduleLevels) get(module string) (klog.Level, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	level, ok := m.levels[module]
+	return level, ok
+}
+
+// Logger writes the logs tagged with key/value pairs, e.g. the cluster, controller
+// and component, the verbosity of the controller and component can be overridden
+// by -log-module-levels
+type Logger struct {
+	modules []string
+	values  []interface{}
+}
+
+// ForController returns a Logger of the controller
+func ForController(controller string) Logger {
+	return Logger{}.WithController(controller)
+}
+
+// WithController returns a Logger tagged with the controller
+func (l Logger) WithController(controller string) Logger {
+	return l.withModule("controller", controller)
+}
+
+// WithComponent returns a Logger tagged with the component, e.g. pd, tikv
+func (l Logger) WithComponent(component string) Logger {
+	return l.withModule("component", component)
+}
+
+// WithCluster returns a Logger tagged with the namespace/name of the cluster
+func (l Logger) WithCluster(ns, name string) Logger {
+	return l.WithValues("cluster", ns+"/"+name)
+}
+
+// WithValues returns a Logger tagged with the key/value pairs
+func (l Logger) WithValues(keysAndValues ...interface{}) Logger {
+	values := make([]interface{}, 0, len(l.values)+len(keysAndValues))
+	values = append(values, l.values...)
+	l.values = append(values, keysAndValues...)
+	return l
+}
+
+func (l Logger) withModule(key, module string) Logger {
+	modules := make([]string, 0, len(l.modules)+1)
+	modules = append(modules, l.modules...)
+	l.modules = append(modules, module)
+	return l.WithValues(key, module)
+}
+
+// Verbose logs only if the verbosity is enabled
+type Verbose struct {
+	enabled bool
+	logger  Logger
+}
+
+// V returns a Verbose which logs if the verbosity of the most specific module
+// with an override, or -v if none, is at least level
+func (l Logger) V(level klog.Level) Verbose {
+	for i := len(l.modules) - 1; i >= 0; i-- {
+		if moduleLevel, ok := levels.get(l.modules[i]); ok {
+			return Verbose{enabled: moduleLevel >= level, logger: l}
+		}
+	}
+	return Verbose{enabled: bool(klog.V(level)), logger: l}
+}
+
+// Enabled returns whether the verbosity is enabled
+func (v Verbose) Enabled() bool {
+	return v.enabled
+}
+
+// Info logs msg with the key/value pairs if the verbosity is enabled
+func (v Verbose) Info(msg string, keysAndValues ...interface{}) {
+	if v.enabled {
+		v.logger.output(severityInfo, msg, keysAndValues)
+	}
+}
+
+// Info logs msg with the key/value pairs
+func (l Logger) Info(msg string, keysAndValues ...interface{}) {
+	l.output(severityInfo, msg, keysAndValues)
+}
+
+// Warning logs msg with the key/value pairs as a warning
+func (l Logger) Warning(msg string, keysAndValues ...interface{}) {
+	l.output(severityWarning, msg, keysAndValues)
+}
+
+// Error logs msg with err and the key/value pairs as an error, err may be nil
+func (l Logger) Error(err error, msg string, keysAndValues ...interface{}) {
+	if err != nil {
+		keysAndValues = append([]interface{}{"err", err}, keysAndValues...)
+	}
+	l.output(severityError, msg, keysAndValues)
+}
+
+const (
+	severityInfo    = "info"
+	severityWarning = "warning"
+	severityError   = "error"
+	severityFatal   = "fatal"
+)
+
+// output must be called by the exported methods directly, so that the caller is reported
+func (l Logger) output(severity, msg string, keysAndValues []interface{}) {
+	values := append(append([]interface{}{}, l.values...), keysAndValues...)
+	if format == FormatJSON {
+		_, file, line, _ := runtime.Caller(2)
+		writeJSON(encodeJSON(time.Now(), severity, fmt.Sprintf("%s:%d", filepath.Base(file), line), msg, values))
+		return
+	}
+	text := encodeText(msg, values)
+	switch severity {
+	case severityError:
+		klog.ErrorDepth(2, text)
+	case severityWarning:
+		klog.WarningDepth(2, text)
+	default:
+		klog.InfoDepth(2, text)
+	}
+}
+
+// encodeText appends the key/value pairs to msg, e.g. msg cluster="ns/name" replicas=3
+func encodeText(msg string, keysAndValues []interface{}) string {
+	b := bytes.NewBufferString(msg)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, value := keyValue(keysAndValues, i)
+		switch v := value.(type) {
+		case string:
+			fmt.Fprintf(b, " %s=%q", key, v)
+		case error:
+			fmt.Fprintf(b, " %s=%q", key, v.Error())
+		case fmt.Stringer:
+			fmt.Fprintf(b, " %s=%q", key, v.String())
+		default:
+			fmt.Fprintf(b, " %s=%+v", key, v)
+		}
+	}
+	return b.String()
+}
+
+// encodeJSON encodes a log line as a JSON object whose keys are in order
+func encodeJSON(ts time.Time, severity, caller, msg string, keysAndValues []interface{}) []byte {
+	b := &bytes.Buffer{}
+	b.WriteString(`{"ts":`)
+	writeJSONValue(b, ts.Format(time.RFC3339Nano))
+	b.WriteString(`,"level":`)
+	writeJSONValue(b, severity)
+	if caller != "" {
+		b.WriteString(`,"caller":`)
+		writeJSONValue(b, caller)
+	}
+	b.WriteString(`,"msg":`)
+	writeJSONValue(b, msg)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, value := keyValue(keysAndValues, i)
+		b.WriteByte(',')
+		writeJSONValue(b, key)
+		b.WriteByte(':')
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		writeJSONValue(b, value)
+	}
+	b.WriteString("}\n")
+	return b.Bytes()
+}
+
+func writeJSONValue(b *bytes.Buffer, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprintf("%+v", value))
+	}
+	b.Write(data)
+}
+
+func keyValue(keysAndValues []interface{}, i int) (string, interface{}) {
+	key := fmt.Sprint(keysAndValues[i])
+	if i+1 >= len(keysAndValues) {
+		return key, "(MISSING)"
+	}
+	return key, keysAndValues[i+1]
+}
+
+func writeJSON(data []byte) {
+	outputLock.Lock()
+	defer outputLock.Unlock()
+	output.Write(data)
+}
+
+// klogWriter converts the lines of klog to JSON, e.g.
+// I1017 10:00:00.000000    1 main.go:10] msg
+type klogWriter struct{}
+
+func (klogWriter) Write(data []byte) (int, error) {
+	writeJSON(convertKlogLine(time.Now(), data))
+	return len(data), nil
+}
+
+var klogSeverities = map[byte]string{
+	'I': severityInfo,
+	'W': severityWarning,
+	'E': severityError,
+	'F': severityFatal,
+}
+
+func convertKlogLine(ts time.Time, data []byte) []byte {
+	line := strings.TrimSuffix(string(data), "\n")
+	end := strings.Index(line, "] ")
+	if end < 0 || len(line) == 0 {
+		// e.g. the stacks dumped on fatal
+		return encodeJSON(ts, severityInfo, "", line, nil)
+	}
+	severity, ok := klogSeverities[line[0]]
+	fields := strings.Fields(line[1:end])
+	if !ok || len(fields) != 4 {
+		return encodeJSON(ts, severityInfo, "", line, nil)
+	}
+	return encodeJSON(ts, severity, fields[3], line[end+2:], nil)
+}
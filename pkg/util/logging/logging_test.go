@@ -0,0 +1,100 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestModuleLevels(t *testing.T) {
+	g := NewGomegaWithT(t)
+	defer levels.Set("")
+
+	_, err := SetModuleLevels("tidbcluster=4, tikv=5,")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(levels.String()).To(Equal("tidbcluster=4,tikv=5"))
+
+	logger := ForController("tidbcluster")
+	g.Expect(logger.V(4).Enabled()).To(BeTrue())
+	g.Expect(logger.V(5).Enabled()).To(BeFalse())
+	// the component is more specific than the controller
+	g.Expect(logger.WithComponent("tikv").V(5).Enabled()).To(BeTrue())
+	g.Expect(logger.WithComponent("pd").V(5).Enabled()).To(BeFalse())
+	// -v is used if there is no override
+	g.Expect(ForController("backup").V(4).Enabled()).To(BeFalse())
+
+	for _, value := range []string{"tikv", "=4", "tikv=x", "tikv=-1"} {
+		_, err = SetModuleLevels(value)
+		g.Expect(err).To(HaveOccurred(), value)
+	}
+	// the levels are kept if the value is invalid
+	g.Expect(levels.String()).To(Equal("tidbcluster=4,tikv=5"))
+}
+
+func TestLoggerValues(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	base := ForController("tidbcluster").WithCluster("ns", "demo")
+	tikv := base.WithComponent("tikv")
+	pd := base.WithComponent("pd")
+	// the loggers derived from the same logger do not share the values
+	g.Expect(tikv.values).To(Equal([]interface{}{"controller", "tidbcluster", "cluster", "ns/demo", "component", "tikv"}))
+	g.Expect(pd.values).To(Equal([]interface{}{"controller", "tidbcluster", "cluster", "ns/demo", "component", "pd"}))
+	g.Expect(base.values).To(HaveLen(4))
+}
+
+func TestEncodeText(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	text := encodeText("scaling out", []interface{}{"cluster", "ns/demo", "replicas", 3, "err", fmt.Errorf("timeout"), "odd"})
+	g.Expect(text).To(Equal(`scaling out cluster="ns/demo" replicas=3 err="timeout" odd="(MISSING)"`))
+}
+
+func TestEncodeJSON(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	ts := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	data := encodeJSON(ts, severityError, "tikv_scaler.go:10", "failed to scale", []interface{}{"err", fmt.Errorf("timeout"), "cluster", "ns/demo", "replicas", 3})
+	g.Expect(string(data)).To(Equal(`{"ts":"2021-01-02T03:04:05Z","level":"error","caller":"tikv_scaler.go:10","msg":"failed to scale","err":"timeout","cluster":"ns/demo","replicas":3}` + "\n"))
+}
+
+func TestConvertKlogLine(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	ts := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	data := convertKlogLine(ts, []byte("W0102 03:04:05.000000       1 main.go:10] lost \"leader\"\n"))
+	g.Expect(string(data)).To(Equal(`{"ts":"2021-01-02T03:04:05Z","level":"warning","caller":"main.go:10","msg":"lost \"leader\""}` + "\n"))
+
+	data = convertKlogLine(ts, []byte("goroutine 1 [running]:\n"))
+	g.Expect(string(data)).To(Equal(`{"ts":"2021-01-02T03:04:05Z","level":"info","msg":"goroutine 1 [running]:"}` + "\n"))
+}
+
+func TestJSONOutput(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	buf := &bytes.Buffer{}
+	savedOutput, savedFormat := output, format
+	output, format = buf, FormatJSON
+	defer func() {
+		output, format = savedOutput, savedFormat
+	}()
+
+	ForController("backup").WithCluster("ns", "demo").Info("backup is complete", "backup", "daily")
+	g.Expect(buf.String()).To(MatchRegexp(`^\{"ts":"[^"]+","level":"info","caller":"logging_test.go:\d+","msg":"backup is complete","controller":"backup","cluster":"ns/demo","backup":"daily"\}\n$`))
+}
@@ -34,8 +34,67 @@ const (
 	TiDBUnhealthy = "TiDBUnhealthy"
 	// TiFlashStoreNotUp is added when one of tiflash stores is not up.
 	TiFlashStoreNotUp = "TiFlashStoreNotUp"
+	// Restoring is added when the data of spec.restore is not restored into the new cluster yet.
+	Restoring = "Restoring"
+	// RestoreFailed is added when the data of spec.restore fails to be restored into the new cluster.
+	RestoreFailed = "RestoreFailed"
+	// Suspended is added when the cluster is suspended, or being suspended or resumed.
+	Suspended = "Suspended"
+	// PDCircuitOpen is added when the calls to PD are short-circuited since PD is unreachable.
+	PDCircuitOpen = "PDCircuitOpen"
+	// PDReachable is added when PD is reachable again.
+	PDReachable = "PDReachable"
+	// FailoverLimitReached is added when the failover replicas of some components reach maxFailoverCount
+	// while they still have failed members.
+	FailoverLimitReached = "FailoverLimitReached"
+	// FailoverWithinLimit is added when the failed members of all components can be replaced again.
+	FailoverWithinLimit = "FailoverWithinLimit"
+	// Available is added when the cluster is able to serve requests.
+	Available = "Available"
+	// PDQuorumLost is added when the healthy pd members are not the majority.
+	PDQuorumLost = "PDQuorumLost"
+	// NoTiKVStoreUp is added when none of the tikv stores is up.
+	NoTiKVStoreUp = "NoTiKVStoreUp"
+	// NoTiDBHealthy is added when none of the tidb pods is healthy.
+	NoTiDBHealthy = "NoTiDBHealthy"
+	// Scaling is added when the replicas of some statefulsets differ from the desired replicas.
+	Scaling = "Scaling"
+	// UpToDate is added when all statefulsets are up to date and scaled.
+	UpToDate = "UpToDate"
+	// MembersUnhealthy is added when some members of the components are unhealthy.
+	MembersUnhealthy = "MembersUnhealthy"
+	// Healthy is added when all members of the components are healthy.
+	Healthy = "Healthy"
+
+	// Reasons for component conditions.
+
+	// AllMembersHealthy is added when all members of the component are healthy.
+	AllMembersHealthy = "AllMembersHealthy"
+	// StoreDown is added when one of the stores of the component is down.
+	StoreDown = "StoreDown"
+	// StoreNotUp is added when one of the stores of the component is not up, e.g. offline or disconnected.
+	StoreNotUp = "StoreNotUp"
+	// MemberUnhealthy is added when one of the members of the component is unhealthy.
+	MemberUnhealthy = "MemberUnhealthy"
+	// RollingUpdate is added when the statefulset of the component is being rolling updated.
+	RollingUpdate = "RollingUpdate"
+	// WaitingForLeaderEviction is added when the upgrade waits for the region leaders to be evicted from the store.
+	WaitingForLeaderEviction = "WaitingForLeaderEviction"
+	// WaitingForLeaderTransfer is added when the upgrade waits for the pd leader to be transferred.
+	WaitingForLeaderTransfer = "WaitingForLeaderTransfer"
+	// WaitingForPodReady is added when the upgrade waits for the upgraded pod to be ready.
+	WaitingForPodReady = "WaitingForPodReady"
+	// WaitingForDDLOwnerTransfer is added when the upgrade waits for the DDL owner to be transferred.
+	WaitingForDDLOwnerTransfer = "WaitingForDDLOwnerTransfer"
+	// ConfigInSync is added when all pods of the component use the latest ConfigMap.
+	ConfigInSync = "ConfigInSync"
+	// ConfigOutOfSync is added when some pods of the component do not use the latest ConfigMap yet.
+	ConfigOutOfSync = "ConfigOutOfSync"
 )
 
+// MaxOperationRecords is the max number of the operation records kept in the status of a TidbCluster
+const MaxOperationRecords = 20
+
 // NewTidbClusterCondition creates a new tidbcluster condition.
 func NewTidbClusterCondition(condType v1alpha1.TidbClusterConditionType, status v1.ConditionStatus, reason, message string) *v1alpha1.TidbClusterCondition {
 	return &v1alpha1.TidbClusterCondition{
@@ -91,3 +150,87 @@ func filterOutCondition(conditions []v1alpha1.TidbClusterCondition, condType v1a
 func GetTidbClusterReadyCondition(status v1alpha1.TidbClusterStatus) *v1alpha1.TidbClusterCondition {
 	return GetTidbClusterCondition(status, v1alpha1.TidbClusterReady)
 }
+
+// NewComponentCondition creates a new component condition.
+func NewComponentCondition(condType v1alpha1.ComponentConditionType, status v1.ConditionStatus, reason, message string) *v1alpha1.ComponentCondition {
+	return &v1alpha1.ComponentCondition{
+		Type:               condType,
+		Status:             status,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// GetComponentCondition returns the component condition with the provided type.
+func GetComponentCondition(conditions []v1alpha1.ComponentCondition, condType v1alpha1.ComponentConditionType) *v1alpha1.ComponentCondition {
+	for i := range conditions {
+		c := conditions[i]
+		if c.Type == condType {
+			return &c
+		}
+	}
+	return nil
+}
+
+// SetComponentCondition updates the component conditions to include the provided condition. If the condition that
+// we are about to add already exists and has the same status, reason and message then we are not going to update.
+func SetComponentCondition(conditions *[]v1alpha1.ComponentCondition, condition v1alpha1.ComponentCondition) {
+	currentCond := GetComponentCondition(*conditions, condition.Type)
+	if currentCond != nil && currentCond.Status == condition.Status &&
+		currentCond.Reason == condition.Reason && currentCond.Message == condition.Message {
+		return
+	}
+	// Do not update lastTransitionTime if the status of the condition doesn't change.
+	if currentCond != nil && currentCond.Status == condition.Status {
+		condition.LastTransitionTime = currentCond.LastTransitionTime
+	}
+	var newConditions []v1alpha1.ComponentCondition
+	for _, c := range *conditions {
+		if c.Type != condition.Type {
+			newConditions = append(newConditions, c)
+		}
+	}
+	*conditions = append(newConditions, condition)
+}
+
+// StartOperation appends an in-progress operation record to the status. If the number of the records exceeds
+// MaxOperationRecords, the oldest finished records are dropped first.
+func StartOperation(status *v1alpha1.TidbClusterStatus, opType v1alpha1.OperationType, component v1alpha1.MemberType, target, message string) {
+	status.Operations = append(status.Operations, v1alpha1.OperationRecord{
+		Type:      opType,
+		Component: component,
+		Target:    target,
+		Result:    v1alpha1.OperationInProgress,
+		StartTime: metav1.Now(),
+		Message:   message,
+	})
+	for len(status.Operations) > MaxOperationRecords {
+		drop := 0
+		for i, op := range status.Operations {
+			if op.Result != v1alpha1.OperationInProgress {
+				drop = i
+				break
+			}
+		}
+		status.Operations = append(status.Operations[:drop], status.Operations[drop+1:]...)
+	}
+}
+
+// FinishOperation marks the latest in-progress operation of the type on the component and the target as succeeded,
+// the message of the record is replaced if the message is not empty.
+func FinishOperation(status *v1alpha1.TidbClusterStatus, opType v1alpha1.OperationType, component v1alpha1.MemberType, target, message string) {
+	for i := len(status.Operations) - 1; i >= 0; i-- {
+		op := &status.Operations[i]
+		if op.Type != opType || op.Component != component || op.Target != target || op.Result != v1alpha1.OperationInProgress {
+			continue
+		}
+		now := metav1.Now()
+		op.Result = v1alpha1.OperationSucceeded
+		op.CompletionTime = &now
+		if message != "" {
+			op.Message = message
+		}
+		return
+	}
+}
@@ -71,3 +71,67 @@ func TestCondition(t *testing.T) {
 	getc = GetTidbClusterReadyCondition(status)
 	g.Expect(getc).Should(Equal(c3))
 }
+
+func TestComponentCondition(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	var conditions []v1alpha1.ComponentCondition
+
+	c := NewComponentCondition(v1alpha1.ComponentHealthy, v1.ConditionFalse, StoreDown, "store 1 is down")
+	SetComponentCondition(&conditions, *c)
+	g.Expect(conditions).Should(HaveLen(1))
+	g.Expect(GetComponentCondition(conditions, v1alpha1.ComponentHealthy)).Should(Equal(c))
+	g.Expect(GetComponentCondition(conditions, v1alpha1.ComponentUpgrading)).Should(BeNil())
+
+	// the message is updated while the lastTransitionTime is kept if the status doesn't change
+	c2 := NewComponentCondition(v1alpha1.ComponentHealthy, v1.ConditionFalse, StoreDown, "store 2 is down")
+	for c2.LastTransitionTime.Equal(&c.LastTransitionTime) {
+		c2.LastTransitionTime = metav1.NewTime(time.Now())
+	}
+	SetComponentCondition(&conditions, *c2)
+	getc := GetComponentCondition(conditions, v1alpha1.ComponentHealthy)
+	g.Expect(getc.Message).Should(Equal(c2.Message))
+	g.Expect(getc.LastTransitionTime).Should(Equal(c.LastTransitionTime))
+
+	// status change from False -> True
+	c3 := NewComponentCondition(v1alpha1.ComponentHealthy, v1.ConditionTrue, AllMembersHealthy, "")
+	SetComponentCondition(&conditions, *c3)
+	g.Expect(GetComponentCondition(conditions, v1alpha1.ComponentHealthy)).Should(Equal(c3))
+
+	c4 := NewComponentCondition(v1alpha1.ComponentUpgrading, v1.ConditionTrue, WaitingForLeaderEviction, "")
+	SetComponentCondition(&conditions, *c4)
+	g.Expect(conditions).Should(HaveLen(2))
+}
+
+func TestOperations(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	status := &v1alpha1.TidbClusterStatus{}
+	StartOperation(status, v1alpha1.UpgradeOperation, v1alpha1.TiKVMemberType, "", "upgrade to v4.0.8")
+	StartOperation(status, v1alpha1.FailoverOperation, v1alpha1.TiKVMemberType, "demo-tikv-1", "store 4 is down")
+	g.Expect(status.Operations).To(HaveLen(2))
+	g.Expect(status.Operations[0].Result).To(Equal(v1alpha1.OperationInProgress))
+
+	// finishing an operation which is not started is a no-op
+	FinishOperation(status, v1alpha1.ScaleOperation, v1alpha1.TiKVMemberType, "", "")
+	FinishOperation(status, v1alpha1.FailoverOperation, v1alpha1.TiKVMemberType, "demo-tikv-2", "")
+	g.Expect(status.Operations[1].Result).To(Equal(v1alpha1.OperationInProgress))
+
+	FinishOperation(status, v1alpha1.FailoverOperation, v1alpha1.TiKVMemberType, "demo-tikv-1", "store 4 is recovered")
+	g.Expect(status.Operations[1].Result).To(Equal(v1alpha1.OperationSucceeded))
+	g.Expect(status.Operations[1].CompletionTime).NotTo(BeNil())
+	g.Expect(status.Operations[1].Message).To(Equal("store 4 is recovered"))
+	FinishOperation(status, v1alpha1.UpgradeOperation, v1alpha1.TiKVMemberType, "", "")
+	g.Expect(status.Operations[0].Result).To(Equal(v1alpha1.OperationSucceeded))
+	g.Expect(status.Operations[0].Message).To(Equal("upgrade to v4.0.8"))
+
+	// the oldest finished records are dropped first
+	StartOperation(status, v1alpha1.UpgradeOperation, v1alpha1.PDMemberType, "", "")
+	for i := 0; i < MaxOperationRecords; i++ {
+		StartOperation(status, v1alpha1.ScaleOperation, v1alpha1.TiDBMemberType, "", "")
+		FinishOperation(status, v1alpha1.ScaleOperation, v1alpha1.TiDBMemberType, "", "")
+	}
+	g.Expect(status.Operations).To(HaveLen(MaxOperationRecords))
+	g.Expect(status.Operations[0].Component).To(Equal(v1alpha1.PDMemberType))
+	g.Expect(status.Operations[0].Result).To(Equal(v1alpha1.OperationInProgress))
+}
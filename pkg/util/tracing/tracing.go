@@ -0,0 +1,255 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"go.opentelemetry.io/otel/api/kv"
+	"go.opentelemetry.io/otel/api/standard"
+	apitrace "go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/codes"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// instrumentationName is the name of the tracer of the spans
+	instrumentationName = "github.com/pingcap/tidb-operator"
+
+	// the attributes of the spans
+	clusterKey    = kv.Key("tidbcluster")
+	k8sNameKey    = kv.Key("k8s.name")
+	httpMethodKey = kv.Key("http.method")
+	httpURLKey    = kv.Key("http.url")
+	httpStatusKey = kv.Key("http.status_code")
+)
+
+var (
+	endpoint    string
+	insecure    = true
+	sampleRatio = 1.0
+
+	// tracer is nil if tracing is disabled
+	tracer apitrace.Tracer
+	spans  = &clusterSpans{contexts: map[string][]context.Context{}}
+)
+
+// AddFlags adds the flags of the OTLP exporter of the spans to fs
+func AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&endpoint, "tracing-otlp-endpoint", endpoint, "The host:port of the OpenTelemetry collector receiving the spans of the reconciles over OTLP, tracing is disabled if it is empty")
+	fs.BoolVar(&insecure, "tracing-otlp-insecure", insecure, "Connect to the OpenTelemetry collector without TLS")
+	fs.Float64Var(&sampleRatio, "tracing-sample-ratio", sampleRatio, "The ratio of the reconciles which are traced, between 0 and 1")
+}
+
+// Init starts exporting the spans after the flags are parsed, the returned function
+// flushes the spans and stops the exporter. Tracing is disabled if no endpoint is set.
+func Init(serviceName string) (func(), error) {
+	if endpoint == "" {
+		return func() {}, nil
+	}
+	if sampleRatio < 0 || sampleRatio > 1 {
+		return nil, fmt.Errorf("invalid tracing-sample-ratio %v, it must be between 0 and 1", sampleRatio)
+	}
+	opts := []otlp.ExporterOption{otlp.WithAddress(endpoint)}
+	if insecure {
+		opts = append(opts, otlp.WithInsecure())
+	}
+	exporter, err := otlp.NewExporter(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the OTLP exporter of %s: %v", endpoint, err)
+	}
+	processor, err := sdktrace.NewBatchSpanProcessor(exporter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the span processor: %v", err)
+	}
+	provider, err := sdktrace.NewProvider(
+		sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sdktrace.ProbabilitySampler(sampleRatio)}),
+		sdktrace.WithResource(resource.New(standard.ServiceNameKey.String(serviceName))),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the trace provider: %v", err)
+	}
+	provider.RegisterSpanProcessor(processor)
+	tracer = provider.Tracer(instrumentationName)
+	return func() {
+		// unregistering the processor flushes the spans in the queue
+		provider.UnregisterSpanProcessor(processor)
+		exporter.Stop()
+	}, nil
+}
+
+// clusterSpans are the active spans of the clusters being reconciled. The sync path does not pass a
+// context.Context down, so the spans of a cluster are the children of the innermost active span of it.
+// The workqueue never reconciles a cluster concurrently, so the parent spans of a cluster form a stack.
+type clusterSpans struct {
+	lock     sync.Mutex
+	contexts map[string][]context.Context
+}
+
+// spanKind is how a span is kept in the stack of the cluster
+type spanKind int
+
+const (
+	// rootSpan replaces the stack of the cluster
+	rootSpan spanKind = iota
+	// parentSpan is pushed onto the stack of the cluster, the spans are its children until it is ended
+	parentSpan
+	// leafSpan is not kept in the stack, it is used for the calls which may be made concurrently
+	leafSpan
+)
+
+func (s *clusterSpans) start(key, spanName string, kind spanKind, opts ...apitrace.StartOption) *Span {
+	if tracer == nil {
+		return nil
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	parent := context.Background()
+	stack, ok := s.contexts[key]
+	if kind != rootSpan {
+		if !ok {
+			// the calls which are not made by the reconcile of the cluster are not traced
+			return nil
+		}
+		parent = stack[len(stack)-1]
+	}
+	ctx, span := tracer.Start(parent, spanName, opts...)
+	switch kind {
+	case rootSpan:
+		s.contexts[key] = []context.Context{ctx}
+	case parentSpan:
+		s.contexts[key] = append(stack, ctx)
+	}
+	return &Span{key: key, ctx: ctx, span: span, kind: kind}
+}
+
+func (s *clusterSpans) end(key string, ctx context.Context, kind spanKind) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	switch kind {
+	case rootSpan:
+		delete(s.contexts, key)
+	case parentSpan:
+		stack := s.contexts[key]
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i] == ctx {
+				s.contexts[key] = append(stack[:i], stack[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Span is an active span of a cluster, the methods of a nil Span are no-ops
+type Span struct {
+	key  string
+	ctx  context.Context
+	span apitrace.Span
+	kind spanKind
+}
+
+// End ends the span, the error of the traced call is recorded if it is not nil
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	if err != nil {
+		s.span.RecordError(s.ctx, err)
+		s.span.SetStatus(codes.Unknown, err.Error())
+	}
+	s.span.End()
+	spans.end(s.key, s.ctx, s.kind)
+}
+
+func clusterKeyOf(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// StartReconcile starts the root span of the reconcile of the cluster of key, which is
+// <namespace>/<name>, the spans of the cluster are its descendants until it is ended
+func StartReconcile(key string) *Span {
+	return spans.start(key, "ReconcileTidbCluster", rootSpan, apitrace.WithAttributes(clusterKey.String(key)))
+}
+
+// Start starts a span of the cluster, the spans of the cluster are its children until it is ended.
+// It returns nil if the cluster is not being reconciled.
+func Start(namespace, name, spanName string, attrs ...kv.KeyValue) *Span {
+	return spans.start(clusterKeyOf(namespace, name), spanName, parentSpan, apitrace.WithAttributes(attrs...))
+}
+
+// startLeaf starts a span of a call made for the cluster, it returns nil if the cluster is not being reconciled
+func startLeaf(namespace, name, spanName string, attrs ...kv.KeyValue) *Span {
+	return spans.start(clusterKeyOf(namespace, name), spanName, leafSpan,
+		apitrace.WithAttributes(attrs...), apitrace.WithSpanKind(apitrace.SpanKindClient))
+}
+
+// StartObject starts a span of the calls made for the controller object, it returns nil
+// if the object is not a TidbCluster being reconciled
+func StartObject(controller runtime.Object, spanName, objectName string) *Span {
+	tc, ok := controller.(*v1alpha1.TidbCluster)
+	if !ok {
+		return nil
+	}
+	return startLeaf(tc.Namespace, tc.Name, spanName, k8sNameKey.String(objectName))
+}
+
+// NewTransport returns a http.RoundTripper tracing the requests made by rt for the cluster,
+// rt is returned as is if tracing is disabled
+func NewTransport(namespace, name string, rt http.RoundTripper) http.RoundTripper {
+	if tracer == nil {
+		return rt
+	}
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &transport{namespace: namespace, name: name, rt: rt}
+}
+
+type transport struct {
+	namespace string
+	name      string
+	rt        http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	span := startLeaf(t.namespace, t.name, fmt.Sprintf("%s %s", req.Method, req.URL.Path),
+		httpMethodKey.String(req.Method), httpURLKey.String(req.URL.String()))
+	res, err := t.rt.RoundTrip(req)
+	if span != nil && res != nil {
+		span.span.SetAttributes(httpStatusKey.Int(res.StatusCode))
+		if err == nil && res.StatusCode >= http.StatusBadRequest {
+			span.span.SetStatus(codes.Unknown, res.Status)
+		}
+	}
+	span.End(err)
+	return res, err
+}
+
+// CloseIdleConnections closes the idle connections of the underlying transport
+func (t *transport) CloseIdleConnections() {
+	type closeIdler interface {
+		CloseIdleConnections()
+	}
+	if c, ok := t.rt.(closeIdler); ok {
+		c.CloseIdleConnections()
+	}
+}
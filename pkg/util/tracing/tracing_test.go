@@ -0,0 +1,129 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	export "go.opentelemetry.io/otel/sdk/export/trace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc/codes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type spanRecorder struct {
+	lock  sync.Mutex
+	spans []*export.SpanData
+}
+
+func (r *spanRecorder) ExportSpan(_ context.Context, span *export.SpanData) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.spans = append(r.spans, span)
+}
+
+func (r *spanRecorder) get(name string) *export.SpanData {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, span := range r.spans {
+		if span.Name == name {
+			return span
+		}
+	}
+	return nil
+}
+
+func enableTracing(g *GomegaWithT) *spanRecorder {
+	recorder := &spanRecorder{}
+	provider, err := sdktrace.NewProvider(
+		sdktrace.WithConfig(sdktrace.Config{DefaultSampler: sdktrace.AlwaysSample()}),
+		sdktrace.WithSyncer(recorder),
+	)
+	g.Expect(err).NotTo(HaveOccurred())
+	tracer = provider.Tracer(instrumentationName)
+	return recorder
+}
+
+func TestTracingDisabled(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	span := StartReconcile("default/demo")
+	g.Expect(span).To(BeNil())
+	g.Expect(Start("default", "demo", "PDMemberManager")).To(BeNil())
+	span.End(fmt.Errorf("no-op"))
+	g.Expect(NewTransport("default", "demo", http.DefaultTransport)).To(BeIdenticalTo(http.DefaultTransport))
+}
+
+func TestSpansOfReconcile(t *testing.T) {
+	g := NewGomegaWithT(t)
+	recorder := enableTracing(g)
+	defer func() { tracer = nil }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: NewTransport("default", "demo", nil)}
+	tc := &v1alpha1.TidbCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "demo"}}
+
+	// the calls made out of the reconcile are not traced
+	g.Expect(Start("default", "demo", "PDMemberManager")).To(BeNil())
+
+	root := StartReconcile("default/demo")
+	g.Expect(root).NotTo(BeNil())
+	member := Start("default", "demo", "PDMemberManager")
+	res, err := client.Get(server.URL + "/pd/api/v1/members")
+	g.Expect(err).NotTo(HaveOccurred())
+	res.Body.Close()
+	StartObject(tc, "UpdateStatefulSet", "demo-pd").End(fmt.Errorf("conflict"))
+	g.Expect(StartObject(&corev1.Pod{}, "UpdatePod", "demo-pd-0")).To(BeNil())
+	member.End(nil)
+	StartObject(tc, "UpdateTidbCluster", "demo").End(nil)
+	root.End(nil)
+
+	// the spans of the cluster are not traced after the reconcile
+	g.Expect(Start("default", "demo", "TiKVMemberManager")).To(BeNil())
+	g.Expect(spans.contexts).To(BeEmpty())
+
+	rootData := recorder.get("ReconcileTidbCluster")
+	g.Expect(rootData).NotTo(BeNil())
+	memberData := recorder.get("PDMemberManager")
+	g.Expect(memberData).NotTo(BeNil())
+	g.Expect(memberData.ParentSpanID).To(Equal(rootData.SpanContext.SpanID))
+	g.Expect(memberData.SpanContext.TraceID).To(Equal(rootData.SpanContext.TraceID))
+
+	pdData := recorder.get("GET /pd/api/v1/members")
+	g.Expect(pdData).NotTo(BeNil())
+	g.Expect(pdData.ParentSpanID).To(Equal(memberData.SpanContext.SpanID))
+	g.Expect(pdData.StatusCode).To(Equal(codes.Unknown))
+
+	stsData := recorder.get("UpdateStatefulSet")
+	g.Expect(stsData).NotTo(BeNil())
+	g.Expect(stsData.ParentSpanID).To(Equal(memberData.SpanContext.SpanID))
+	g.Expect(stsData.StatusCode).To(Equal(codes.Unknown))
+	g.Expect(stsData.StatusMessage).To(Equal("conflict"))
+
+	statusData := recorder.get("UpdateTidbCluster")
+	g.Expect(statusData).NotTo(BeNil())
+	g.Expect(statusData.ParentSpanID).To(Equal(rootData.SpanContext.SpanID))
+	g.Expect(statusData.StatusCode).To(Equal(codes.OK))
+}
@@ -76,45 +76,26 @@ func IsPodOrdinalNotExceedReplicas(pod *corev1.Pod, sts *appsv1.StatefulSet) (bo
 	return ordinal < *sts.Spec.Replicas, nil
 }
 
-func getDeleteSlots(tc *v1alpha1.TidbCluster, annKey string) (deleteSlots sets.Int32) {
-	deleteSlots = sets.NewInt32()
-	annotations := tc.GetAnnotations()
-	if annotations == nil {
-		return
-	}
-	value, ok := annotations[annKey]
-	if !ok {
-		return
-	}
-	var slice []int32
-	err := json.Unmarshal([]byte(value), &slice)
-	if err != nil {
-		return
-	}
-	deleteSlots.Insert(slice...)
-	return
-}
-
 // GetPodOrdinals gets desired ordials of member in given TidbCluster.
 func GetPodOrdinals(tc *v1alpha1.TidbCluster, memberType v1alpha1.MemberType) (sets.Int32, error) {
-	var ann string
+	var component string
 	var replicas int32
 	if memberType == v1alpha1.PDMemberType {
-		ann = label.AnnPDDeleteSlots
+		component = label.PDLabelVal
 		replicas = tc.Spec.PD.Replicas
 	} else if memberType == v1alpha1.TiKVMemberType {
-		ann = label.AnnTiKVDeleteSlots
+		component = label.TiKVLabelVal
 		replicas = tc.Spec.TiKV.Replicas
 	} else if memberType == v1alpha1.TiDBMemberType {
-		ann = label.AnnTiDBDeleteSlots
+		component = label.TiDBLabelVal
 		replicas = tc.Spec.TiDB.Replicas
 	} else if memberType == v1alpha1.TiFlashMemberType {
-		ann = label.AnnTiFlashDeleteSlots
+		component = label.TiFlashLabelVal
 		replicas = tc.Spec.TiFlash.Replicas
 	} else {
 		return nil, fmt.Errorf("unknown member type %v", memberType)
 	}
-	deleteSlots := getDeleteSlots(tc, ann)
+	deleteSlots := tc.GetDeleteSlots(component)
 	maxReplicaCount, deleteSlots := helper.GetMaxReplicaCountAndDeleteSlots(replicas, deleteSlots)
 	podOrdinals := sets.NewInt32()
 	for i := int32(0); i < maxReplicaCount; i++ {
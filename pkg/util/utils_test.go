@@ -164,6 +164,24 @@ func TestGetPodOrdinals(t *testing.T) {
 			memberType:  v1alpha1.TiDBMemberType,
 			deleteSlots: sets.NewInt32(0, 3, 4),
 		},
+		{
+			name: "delete slots in spec and annotation",
+			tc: &v1alpha1.TidbCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						label.AnnTiKVDeleteSlots: "[1]",
+					},
+				},
+				Spec: v1alpha1.TidbClusterSpec{
+					TiKV: &v1alpha1.TiKVSpec{
+						Replicas:    3,
+						DeleteSlots: []int32{1, 3},
+					},
+				},
+			},
+			memberType:  v1alpha1.TiKVMemberType,
+			deleteSlots: sets.NewInt32(0, 2, 4),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
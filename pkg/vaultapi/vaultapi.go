@@ -0,0 +1,200 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vaultapi
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
+)
+
+const (
+	DefaultTimeout = 10 * time.Second
+	tokenHeader    = "X-Vault-Token"
+)
+
+// IssueRequest is the request to issue a certificate from a PKI role
+type IssueRequest struct {
+	CommonName string `json:"common_name"`
+	// AltNames is the comma separated DNS SANs
+	AltNames string `json:"alt_names,omitempty"`
+	// IPSans is the comma separated IP SANs
+	IPSans string `json:"ip_sans,omitempty"`
+	TTL    string `json:"ttl,omitempty"`
+}
+
+// Certificate is a certificate issued by a PKI role
+type Certificate struct {
+	Certificate string   `json:"certificate"`
+	PrivateKey  string   `json:"private_key"`
+	IssuingCA   string   `json:"issuing_ca"`
+	CAChain     []string `json:"ca_chain"`
+}
+
+// CABundle returns the PEM encoded CA certificates to verify the certificate
+func (c *Certificate) CABundle() string {
+	if len(c.CAChain) == 0 {
+		return c.IssuingCA
+	}
+	return strings.Join(c.CAChain, "\n")
+}
+
+// VaultClient provides the Vault APIs used to issue the certificates of the cluster and read the credentials
+type VaultClient interface {
+	// SetToken sets the token used by the requests
+	SetToken(token string)
+	// LoginKubernetes logs in with the service account JWT by the Kubernetes auth method mounted at mountPath
+	// and sets the token returned
+	LoginKubernetes(mountPath, role, jwt string) error
+	// IssueCertificate issues a certificate from the PKI role
+	IssueCertificate(pkiPath, role string, req *IssueRequest) (*Certificate, error)
+	// ReadSecret reads the secret at path of a KV secrets engine, the data of the secret is returned for
+	// both version 1 and version 2 of the engine
+	ReadSecret(path string) (map[string]interface{}, error)
+}
+
+// vaultClient is default implementation of VaultClient
+type vaultClient struct {
+	url        string
+	token      string
+	httpClient *http.Client
+}
+
+type response struct {
+	Auth *struct {
+		ClientToken string `json:"client_token"`
+	} `json:"auth"`
+	Data json.RawMessage `json:"data"`
+}
+
+func (c *vaultClient) SetToken(token string) {
+	c.token = token
+}
+
+func (c *vaultClient) LoginKubernetes(mountPath, role, jwt string) error {
+	resp, err := c.post(fmt.Sprintf("auth/%s/login", strings.Trim(mountPath, "/")), map[string]string{
+		"role": role,
+		"jwt":  jwt,
+	})
+	if err != nil {
+		return err
+	}
+	if resp.Auth == nil || resp.Auth.ClientToken == "" {
+		return fmt.Errorf("no client token is returned by logging in to vault %s", c.url)
+	}
+	c.token = resp.Auth.ClientToken
+	return nil
+}
+
+func (c *vaultClient) IssueCertificate(pkiPath, role string, req *IssueRequest) (*Certificate, error) {
+	resp, err := c.post(fmt.Sprintf("%s/issue/%s", strings.Trim(pkiPath, "/"), role), req)
+	if err != nil {
+		return nil, err
+	}
+	cert := &Certificate{}
+	if err := json.Unmarshal(resp.Data, cert); err != nil {
+		return nil, err
+	}
+	if cert.Certificate == "" || cert.PrivateKey == "" {
+		return nil, fmt.Errorf("no certificate is issued by role %s of vault %s", role, c.url)
+	}
+	return cert, nil
+}
+
+func (c *vaultClient) ReadSecret(path string) (map[string]interface{}, error) {
+	resp, err := c.do("GET", strings.Trim(path, "/"), nil)
+	if err != nil {
+		return nil, err
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		return nil, err
+	}
+	// the version 2 of the KV engine wraps the data with its metadata
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = inner
+		}
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("no data is found in secret %s of vault %s", path, c.url)
+	}
+	return data, nil
+}
+
+func (c *vaultClient) post(path string, body interface{}) (*response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return c.do("POST", path, bytes.NewReader(data))
+}
+
+func (c *vaultClient) do(method, path string, body io.Reader) (*response, error) {
+	apiURL := fmt.Sprintf("%s/v1/%s", c.url, path)
+	req, err := http.NewRequest(method, apiURL, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set(tokenHeader, c.token)
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httputil.DeferClose(res.Body)
+	respBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode >= 400 {
+		return nil, fmt.Errorf("Error response %v URL %s,body response: %s", res.StatusCode, apiURL, string(respBody))
+	}
+	resp := &response{}
+	if err := json.Unmarshal(respBody, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// NewVaultClient returns a new VaultClient
+func NewVaultClient(url string, timeout time.Duration, tlsConfig *tls.Config) VaultClient {
+	return &vaultClient{
+		url: strings.TrimSuffix(url, "/"),
+		httpClient: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				TLSClientConfig:       tlsConfig,
+				ResponseHeaderTimeout: 10 * time.Second,
+				TLSHandshakeTimeout:   10 * time.Second,
+				DialContext: (&net.Dialer{
+					Timeout: 10 * time.Second,
+				}).DialContext,
+			},
+		},
+	}
+}
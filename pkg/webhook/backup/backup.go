@@ -0,0 +1,266 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sync"
+
+	"github.com/openshift/generic-admission-server/pkg/apiserver"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	backuputil "github.com/pingcap/tidb-operator/pkg/backup/util"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
+	"github.com/pingcap/tidb-operator/pkg/webhook/util"
+	admission "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog"
+)
+
+// BackupAdmissionControl validates the Backup and Restore CRs at creation, so that
+// the misconfigurations could be found before the backup or restore jobs are created.
+type BackupAdmissionControl struct {
+	lock        sync.RWMutex
+	initialized bool
+	// kubernetes client interface
+	kubeCli kubernetes.Interface
+	// operator client interface
+	operatorCli versioned.Interface
+}
+
+var _ apiserver.ValidatingAdmissionHook = &BackupAdmissionControl{}
+
+func NewBackupAdmissionControl() *BackupAdmissionControl {
+	return &BackupAdmissionControl{}
+}
+
+func (bc *BackupAdmissionControl) ValidatingResource() (plural schema.GroupVersionResource, singular string) {
+	return schema.GroupVersionResource{
+			Group:    "admission.tidb.pingcap.com",
+			Version:  "v1alpha1",
+			Resource: "backupvalidations",
+		},
+		"backupvalidation"
+}
+
+func (bc *BackupAdmissionControl) Validate(ar *admission.AdmissionRequest) *admission.AdmissionResponse {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+	if !bc.initialized {
+		return &admission.AdmissionResponse{
+			Allowed: false,
+		}
+	}
+
+	if ar.Operation != admission.Create {
+		return util.ARSuccess()
+	}
+
+	switch ar.Kind.Kind {
+	case v1alpha1.BackupKind:
+		backup := &v1alpha1.Backup{}
+		if err := json.Unmarshal(ar.Object.Raw, backup); err != nil {
+			klog.Errorf("Could not unmarshal raw object: %v", err)
+			return util.ARFail(err)
+		}
+		if backup.Namespace == "" {
+			backup.Namespace = ar.Namespace
+		}
+		if err := bc.validateBackup(backup); err != nil {
+			klog.Infof("refuse to create backup[%s/%s], %v", ar.Namespace, ar.Name, err)
+			return util.ARFail(err)
+		}
+	case v1alpha1.RestoreKind:
+		restore := &v1alpha1.Restore{}
+		if err := json.Unmarshal(ar.Object.Raw, restore); err != nil {
+			klog.Errorf("Could not unmarshal raw object: %v", err)
+			return util.ARFail(err)
+		}
+		if restore.Namespace == "" {
+			restore.Namespace = ar.Namespace
+		}
+		if err := bc.validateRestore(restore); err != nil {
+			klog.Infof("refuse to create restore[%s/%s], %v", ar.Namespace, ar.Name, err)
+			return util.ARFail(err)
+		}
+	}
+	return util.ARSuccess()
+}
+
+func (bc *BackupAdmissionControl) validateBackup(backup *v1alpha1.Backup) error {
+	ns := backup.Namespace
+	name := backup.Name
+
+	if err := backuputil.ValidateStorageProvider(backup.Spec.StorageProvider); err != nil {
+		return fmt.Errorf("backup %s/%s: %v", ns, name, err)
+	}
+	if err := backuputil.ValidateBROptions(backup.Spec.BR); err != nil {
+		return fmt.Errorf("backup %s/%s: %v", ns, name, err)
+	}
+
+	tikvImage, err := bc.getTiKVImage(ns, backup.Spec.BR)
+	if err != nil {
+		return fmt.Errorf("backup %s/%s: %v", ns, name, err)
+	}
+	if err := backuputil.ValidateBackup(backup, tikvImage); err != nil {
+		return err
+	}
+	if err := bc.checkSecrets(ns, backup.Spec.From, backup.Spec.StorageProvider); err != nil {
+		return fmt.Errorf("backup %s/%s: %v", ns, name, err)
+	}
+	return bc.checkPrefixCollision(backup)
+}
+
+func (bc *BackupAdmissionControl) validateRestore(restore *v1alpha1.Restore) error {
+	ns := restore.Namespace
+	name := restore.Name
+
+	if err := backuputil.ValidateStorageProvider(restore.Spec.StorageProvider); err != nil {
+		return fmt.Errorf("restore %s/%s: %v", ns, name, err)
+	}
+	if err := backuputil.ValidateBROptions(restore.Spec.BR); err != nil {
+		return fmt.Errorf("restore %s/%s: %v", ns, name, err)
+	}
+
+	tikvImage, err := bc.getTiKVImage(ns, restore.Spec.BR)
+	if err != nil {
+		return fmt.Errorf("restore %s/%s: %v", ns, name, err)
+	}
+	if err := backuputil.ValidateRestore(restore, tikvImage); err != nil {
+		return err
+	}
+	if err := bc.checkSecrets(ns, restore.Spec.To, restore.Spec.StorageProvider); err != nil {
+		return fmt.Errorf("restore %s/%s: %v", ns, name, err)
+	}
+	return nil
+}
+
+// getTiKVImage returns the TiKV image of the cluster referenced by BR,
+// an error is returned if the referenced cluster does not exist.
+func (bc *BackupAdmissionControl) getTiKVImage(ns string, br *v1alpha1.BRConfig) (string, error) {
+	if br == nil || br.Cluster == "" {
+		return "", nil
+	}
+	clusterNamespace := br.ClusterNamespace
+	if clusterNamespace == "" {
+		clusterNamespace = ns
+	}
+	tc, err := bc.operatorCli.PingcapV1alpha1().TidbClusters(clusterNamespace).Get(br.Cluster, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", fmt.Errorf("referenced tidbcluster %s/%s does not exist", clusterNamespace, br.Cluster)
+		}
+		return "", err
+	}
+	return tc.TiKVImage(), nil
+}
+
+// checkSecrets checks whether the secrets referenced by the access config and the storage provider exist
+func (bc *BackupAdmissionControl) checkSecrets(ns string, access *v1alpha1.TiDBAccessConfig, provider v1alpha1.StorageProvider) error {
+	var secrets []string
+	if access != nil {
+		if access.SecretName != "" {
+			secrets = append(secrets, access.SecretName)
+		}
+		if access.TLSClientSecretName != nil && *access.TLSClientSecretName != "" {
+			secrets = append(secrets, *access.TLSClientSecretName)
+		}
+	}
+	if provider.S3 != nil && provider.S3.SecretName != "" {
+		secrets = append(secrets, provider.S3.SecretName)
+	}
+	if provider.Gcs != nil && provider.Gcs.SecretName != "" {
+		secrets = append(secrets, provider.Gcs.SecretName)
+	}
+	for _, secretName := range secrets {
+		_, err := bc.kubeCli.CoreV1().Secrets(ns).Get(secretName, metav1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return fmt.Errorf("referenced secret %s/%s does not exist", ns, secretName)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPrefixCollision refuses the BR backup whose remote location is the same as an existing BR backup,
+// otherwise the backup data would be overwritten or mixed up. The dumpling backups are not checked, they
+// write to the `backup-<time>` subfolders of the prefix, e.g. the ones created by a BackupSchedule.
+func (bc *BackupAdmissionControl) checkPrefixCollision(backup *v1alpha1.Backup) error {
+	if backup.Spec.BR == nil {
+		return nil
+	}
+	location := remoteLocation(backup.Spec.StorageProvider)
+	if location == "" {
+		return nil
+	}
+	backups, err := bc.operatorCli.PingcapV1alpha1().Backups(backup.Namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, existing := range backups.Items {
+		if existing.Name == backup.Name || existing.DeletionTimestamp != nil || existing.Spec.BR == nil {
+			continue
+		}
+		if remoteLocation(existing.Spec.StorageProvider) == location {
+			return fmt.Errorf("backup %s/%s uses the same storage location %s as existing backup %s, please use another prefix", backup.Namespace, backup.Name, location, existing.Name)
+		}
+	}
+	return nil
+}
+
+// remoteLocation returns the location where BR stores the backup data, which is the same as the
+// `--storage` option of BR generated by backup-manager, an empty string is returned if the
+// location can't be determined.
+func remoteLocation(provider v1alpha1.StorageProvider) string {
+	switch backuputil.GetStorageType(provider) {
+	case v1alpha1.BackupStorageTypeS3:
+		return fmt.Sprintf("s3://%s", path.Join(provider.S3.Bucket, provider.S3.Prefix))
+	case v1alpha1.BackupStorageTypeGcs:
+		return fmt.Sprintf("gcs://%s", path.Join(provider.Gcs.Bucket, provider.Gcs.Prefix))
+	case v1alpha1.BackupStorageTypeLocal:
+		return fmt.Sprintf("local://%s", path.Join(provider.Local.Volume.Name, provider.Local.Prefix))
+	}
+	return ""
+}
+
+func (bc *BackupAdmissionControl) initialize(cli versioned.Interface, kubeCli kubernetes.Interface) {
+	bc.operatorCli = cli
+	bc.kubeCli = kubeCli
+	bc.initialized = true
+}
+
+// Initialize implements AdmissionHook.Initialize interface. It's is called as
+// a post-start hook.
+func (bc *BackupAdmissionControl) Initialize(cfg *rest.Config, stopCh <-chan struct{}) error {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+
+	cli, err := versioned.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	kubeCli, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	bc.initialize(cli, kubeCli)
+	return nil
+}
@@ -0,0 +1,199 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned/fake"
+	admission "k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	namespace = "ns"
+)
+
+func newBackup(name string) *v1alpha1.Backup {
+	return &v1alpha1.Backup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: v1alpha1.BackupSpec{
+			BR: &v1alpha1.BRConfig{
+				Cluster: "tc",
+			},
+			StorageProvider: v1alpha1.StorageProvider{
+				S3: &v1alpha1.S3StorageProvider{
+					Provider:   v1alpha1.S3StorageProviderTypeAWS,
+					Bucket:     "bucket",
+					Prefix:     name,
+					SecretName: "s3-secret",
+				},
+			},
+		},
+	}
+}
+
+func newDumplingBackup(name string) *v1alpha1.Backup {
+	backup := newBackup(name)
+	backup.Spec.BR = nil
+	backup.Spec.From = &v1alpha1.TiDBAccessConfig{Host: "tc-tidb", SecretName: "s3-secret"}
+	backup.Spec.StorageSize = "10Gi"
+	return backup
+}
+
+func TestValidateBackup(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name        string
+		backup      func() *v1alpha1.Backup
+		noCluster   bool
+		noSecret    bool
+		existing    *v1alpha1.Backup
+		wantAllowed bool
+	}{
+		{
+			name:        "valid backup",
+			backup:      func() *v1alpha1.Backup { return newBackup("bk") },
+			wantAllowed: true,
+		},
+		{
+			name:        "referenced cluster does not exist",
+			backup:      func() *v1alpha1.Backup { return newBackup("bk") },
+			noCluster:   true,
+			wantAllowed: false,
+		},
+		{
+			name:        "storage secret does not exist",
+			backup:      func() *v1alpha1.Backup { return newBackup("bk") },
+			noSecret:    true,
+			wantAllowed: false,
+		},
+		{
+			name: "no storage provider",
+			backup: func() *v1alpha1.Backup {
+				bk := newBackup("bk")
+				bk.Spec.S3 = nil
+				return bk
+			},
+			wantAllowed: false,
+		},
+		{
+			name: "multiple storage providers",
+			backup: func() *v1alpha1.Backup {
+				bk := newBackup("bk")
+				bk.Spec.Gcs = &v1alpha1.GcsStorageProvider{ProjectId: "p", Bucket: "b"}
+				return bk
+			},
+			wantAllowed: false,
+		},
+		{
+			name: "invalid BR options",
+			backup: func() *v1alpha1.Backup {
+				bk := newBackup("bk")
+				bk.Spec.BR.Options = []string{"lastbackupts=1"}
+				return bk
+			},
+			wantAllowed: false,
+		},
+		{
+			name: "invalid BR timeAgo",
+			backup: func() *v1alpha1.Backup {
+				bk := newBackup("bk")
+				bk.Spec.BR.TimeAgo = "1x"
+				return bk
+			},
+			wantAllowed: false,
+		},
+		{
+			name: "prefix collision",
+			backup: func() *v1alpha1.Backup {
+				bk := newBackup("bk")
+				bk.Spec.S3.Prefix = "existing"
+				return bk
+			},
+			existing:    newBackup("existing"),
+			wantAllowed: false,
+		},
+		{
+			name: "dumpling backups with the same prefix",
+			backup: func() *v1alpha1.Backup {
+				bk := newDumplingBackup("bk")
+				bk.Spec.S3.Prefix = "existing"
+				return bk
+			},
+			existing:    newDumplingBackup("existing"),
+			wantAllowed: true,
+		},
+		{
+			name:        "different prefix",
+			backup:      func() *v1alpha1.Backup { return newBackup("bk") },
+			existing:    newBackup("existing"),
+			wantAllowed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+
+		cli := fake.NewSimpleClientset()
+		kubeCli := kubefake.NewSimpleClientset()
+		if !tt.noCluster {
+			cli.PingcapV1alpha1().TidbClusters(namespace).Create(&v1alpha1.TidbCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "tc", Namespace: namespace},
+				Spec: v1alpha1.TidbClusterSpec{
+					TiKV:    &v1alpha1.TiKVSpec{BaseImage: "pingcap/tikv"},
+					Version: "v4.0.9",
+				},
+			})
+		}
+		if !tt.noSecret {
+			kubeCli.CoreV1().Secrets(namespace).Create(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "s3-secret", Namespace: namespace},
+			})
+		}
+		if tt.existing != nil {
+			cli.PingcapV1alpha1().Backups(namespace).Create(tt.existing)
+		}
+
+		bc := NewBackupAdmissionControl()
+		bc.initialize(cli, kubeCli)
+
+		backup := tt.backup()
+		raw, err := json.Marshal(backup)
+		g.Expect(err).NotTo(HaveOccurred())
+		ar := &admission.AdmissionRequest{
+			Name:      backup.Name,
+			Namespace: namespace,
+			Operation: admission.Create,
+			Kind: metav1.GroupVersionKind{
+				Group:   "pingcap.com",
+				Version: "v1alpha1",
+				Kind:    v1alpha1.BackupKind,
+			},
+			Object: runtime.RawExtension{Raw: raw},
+		}
+		resp := bc.Validate(ar)
+		g.Expect(resp.Allowed).Should(Equal(tt.wantAllowed))
+	}
+}
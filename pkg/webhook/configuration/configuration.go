@@ -0,0 +1,448 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configuration
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pingcap/tidb-operator/pkg/label"
+	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
+)
+
+const (
+	// the component label value of the webhook configurations
+	componentLabelVal = "admission-webhook"
+	// the path prefix of the admission resources served by the aggregated apiserver
+	pathPrefix = "/apis/admission.tidb.pingcap.com/v1alpha1/"
+
+	// the names of the webhooks which could be enabled
+	PodsWebhook             = "pods"
+	StatefulSetsWebhook     = "statefulsets"
+	PingcapResourcesWebhook = "pingcapresources"
+	BackupsWebhook          = "backups"
+	PoliciesWebhook         = "policies"
+)
+
+// Config is the configuration of the webhook configurations reconciled by the Reconciler
+type Config struct {
+	// ValidatingWebhooks are the enabled validating webhooks
+	ValidatingWebhooks []string
+	// MutatingWebhooks are the enabled mutating webhooks
+	MutatingWebhooks []string
+	// ValidationFailurePolicy overrides the default failure policy of the validating webhooks if it is not empty
+	ValidationFailurePolicy admissionregistration.FailurePolicyType
+	// MutationFailurePolicy overrides the default failure policy of the mutating webhooks if it is not empty
+	MutationFailurePolicy admissionregistration.FailurePolicyType
+	// NamespaceSelector restricts the namespaces in which the requests are sent to the webhooks
+	NamespaceSelector *metav1.LabelSelector
+	// ObjectSelector restricts the objects whose requests are sent to the webhooks,
+	// it is merged with the default object selector of the webhook
+	ObjectSelector *metav1.LabelSelector
+	// CABundle is the CA bundle used to verify the kube-apiserver which proxies the requests
+	CABundle []byte
+}
+
+// webhook describes a webhook served by tidb-admission-webhook
+type webhook struct {
+	configName            string
+	webhookName           string
+	resource              string
+	rules                 []admissionregistration.RuleWithOperations
+	defaultFailurePolicy  admissionregistration.FailurePolicyType
+	defaultObjectSelector map[string]string
+}
+
+var (
+	managedByOperator = map[string]string{
+		label.ManagedByLabelKey: label.TiDBOperator,
+	}
+
+	validatingWebhooks = map[string]webhook{
+		PodsWebhook: {
+			configName:            "validation-tidb-pod-webhook-cfg",
+			webhookName:           "podadmission.tidb.pingcap.com",
+			resource:              "podvalidations",
+			rules:                 rules([]string{""}, []string{"v1"}, []string{"pods"}, admissionregistration.Delete, admissionregistration.Create),
+			defaultFailurePolicy:  admissionregistration.Fail,
+			defaultObjectSelector: managedByOperator,
+		},
+		StatefulSetsWebhook: {
+			configName:  "validation-tidb-statefulset-webhook-cfg",
+			webhookName: "stsadmission.tidb.pingcap.com",
+			resource:    "statefulsetvalidations",
+			rules: append(
+				rules([]string{"apps"}, []string{"v1beta1", "v1"}, []string{"statefulsets"}, admissionregistration.Update),
+				rules([]string{"apps.pingcap.com"}, []string{"v1alpha1", "v1"}, []string{"statefulsets"}, admissionregistration.Update)...),
+			defaultFailurePolicy:  admissionregistration.Ignore,
+			defaultObjectSelector: managedByOperator,
+		},
+		PingcapResourcesWebhook: {
+			configName:           "pingcap-tidb-resources-validating",
+			webhookName:          "validating.admission.tidb.pingcap.com",
+			resource:             "pingcapresourcevalidations",
+			rules:                rules([]string{"pingcap.com"}, []string{"v1alpha1"}, []string{"tidbclusters"}, admissionregistration.Update, admissionregistration.Create),
+			defaultFailurePolicy: admissionregistration.Ignore,
+		},
+		BackupsWebhook: {
+			configName:           "validation-tidb-backup-webhook-cfg",
+			webhookName:          "backupadmission.tidb.pingcap.com",
+			resource:             "backupvalidations",
+			rules:                rules([]string{"pingcap.com"}, []string{"v1alpha1"}, []string{"backups", "restores"}, admissionregistration.Create),
+			defaultFailurePolicy: admissionregistration.Ignore,
+		},
+		PoliciesWebhook: {
+			configName:           "validation-tidb-policy-webhook-cfg",
+			webhookName:          "policyadmission.tidb.pingcap.com",
+			resource:             "tidbclusterpolicies",
+			rules:                rules([]string{"pingcap.com"}, []string{"v1alpha1"}, []string{"tidbclusters"}, admissionregistration.Update, admissionregistration.Create),
+			defaultFailurePolicy: admissionregistration.Fail,
+		},
+	}
+
+	mutatingWebhooks = map[string]webhook{
+		PodsWebhook: {
+			configName:           "mutation-tidb-pod-webhook-cfg",
+			webhookName:          "podadmission.tidb.pingcap.com",
+			resource:             "podmutations",
+			rules:                rules([]string{""}, []string{"v1"}, []string{"pods"}, admissionregistration.Create),
+			defaultFailurePolicy: admissionregistration.Ignore,
+			defaultObjectSelector: map[string]string{
+				label.ManagedByLabelKey: label.TiDBOperator,
+				label.NameLabelKey:      "tidb-cluster",
+			},
+		},
+		PingcapResourcesWebhook: {
+			configName:           "pingcap-tidb-resources-defaulitng",
+			webhookName:          "defaulting.admission.tidb.pingcap.com",
+			resource:             "pingcapresourcemutations",
+			rules:                rules([]string{"pingcap.com"}, []string{"v1alpha1"}, []string{"tidbclusters"}, admissionregistration.Update, admissionregistration.Create),
+			defaultFailurePolicy: admissionregistration.Ignore,
+		},
+	}
+)
+
+func rules(groups, versions, resources []string, operations ...admissionregistration.OperationType) []admissionregistration.RuleWithOperations {
+	scope := admissionregistration.AllScopes
+	return []admissionregistration.RuleWithOperations{
+		{
+			Operations: operations,
+			Rule: admissionregistration.Rule{
+				APIGroups:   groups,
+				APIVersions: versions,
+				Resources:   resources,
+				Scope:       &scope,
+			},
+		},
+	}
+}
+
+// ValidateConfig checks whether the webhooks and the failure policies in the config are known
+func ValidateConfig(config Config) error {
+	for _, name := range config.ValidatingWebhooks {
+		if _, ok := validatingWebhooks[name]; !ok {
+			return fmt.Errorf("unknown validating webhook %q", name)
+		}
+	}
+	for _, name := range config.MutatingWebhooks {
+		if _, ok := mutatingWebhooks[name]; !ok {
+			return fmt.Errorf("unknown mutating webhook %q", name)
+		}
+	}
+	for _, policy := range []admissionregistration.FailurePolicyType{config.ValidationFailurePolicy, config.MutationFailurePolicy} {
+		if policy != "" && policy != admissionregistration.Ignore && policy != admissionregistration.Fail {
+			return fmt.Errorf("unknown failure policy %q, only %s and %s are supported", policy, admissionregistration.Ignore, admissionregistration.Fail)
+		}
+	}
+	return nil
+}
+
+// Reconciler reconciles the ValidatingWebhookConfigurations and MutatingWebhookConfigurations of
+// tidb-admission-webhook, so that they are consistent with the configuration of the operator
+// and the manual changes are reverted.
+type Reconciler struct {
+	kubeCli kubernetes.Interface
+	config  Config
+}
+
+// NewReconciler returns a Reconciler
+func NewReconciler(kubeCli kubernetes.Interface, config Config) *Reconciler {
+	return &Reconciler{
+		kubeCli: kubeCli,
+		config:  config,
+	}
+}
+
+// Run reconciles the webhook configurations periodically until stopCh is closed
+func (r *Reconciler) Run(interval time.Duration, stopCh <-chan struct{}) {
+	wait.Until(func() {
+		if err := r.Sync(); err != nil {
+			klog.Errorf("failed to sync webhook configurations: %v", err)
+		}
+	}, interval, stopCh)
+}
+
+// Sync creates or updates the webhook configurations of the enabled webhooks,
+// and deletes the ones of the disabled webhooks created by the Reconciler
+func (r *Reconciler) Sync() error {
+	enabled := sets.NewString(r.config.ValidatingWebhooks...)
+	for name, w := range validatingWebhooks {
+		if !enabled.Has(name) {
+			if err := r.deleteValidatingWebhookConfiguration(w.configName); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := r.syncValidatingWebhookConfiguration(r.desiredValidatingWebhookConfiguration(w)); err != nil {
+			return err
+		}
+	}
+	enabled = sets.NewString(r.config.MutatingWebhooks...)
+	for name, w := range mutatingWebhooks {
+		if !enabled.Has(name) {
+			if err := r.deleteMutatingWebhookConfiguration(w.configName); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := r.syncMutatingWebhookConfiguration(r.desiredMutatingWebhookConfiguration(w)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Reconciler) syncValidatingWebhookConfiguration(desired *admissionregistration.ValidatingWebhookConfiguration) error {
+	cli := r.kubeCli.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations()
+	existing, err := cli.Get(desired.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		klog.Infof("create ValidatingWebhookConfiguration %s", desired.Name)
+		_, err = cli.Create(desired)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if apiequality.Semantic.DeepEqual(existing.Webhooks, desired.Webhooks) && labelsMatch(existing.Labels, desired.Labels) {
+		return nil
+	}
+	klog.Infof("update ValidatingWebhookConfiguration %s as it drifts from the desired state", desired.Name)
+	update := existing.DeepCopy()
+	update.Webhooks = desired.Webhooks
+	update.Labels = mergeLabels(update.Labels, desired.Labels)
+	_, err = cli.Update(update)
+	return err
+}
+
+func (r *Reconciler) syncMutatingWebhookConfiguration(desired *admissionregistration.MutatingWebhookConfiguration) error {
+	cli := r.kubeCli.AdmissionregistrationV1beta1().MutatingWebhookConfigurations()
+	existing, err := cli.Get(desired.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		klog.Infof("create MutatingWebhookConfiguration %s", desired.Name)
+		_, err = cli.Create(desired)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if apiequality.Semantic.DeepEqual(existing.Webhooks, desired.Webhooks) && labelsMatch(existing.Labels, desired.Labels) {
+		return nil
+	}
+	klog.Infof("update MutatingWebhookConfiguration %s as it drifts from the desired state", desired.Name)
+	update := existing.DeepCopy()
+	update.Webhooks = desired.Webhooks
+	update.Labels = mergeLabels(update.Labels, desired.Labels)
+	_, err = cli.Update(update)
+	return err
+}
+
+// deleteValidatingWebhookConfiguration deletes the configuration only if it is created by the Reconciler
+func (r *Reconciler) deleteValidatingWebhookConfiguration(name string) error {
+	cli := r.kubeCli.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations()
+	existing, err := cli.Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !labelsMatch(existing.Labels, configurationLabels()) {
+		return nil
+	}
+	klog.Infof("delete ValidatingWebhookConfiguration %s as the webhook is disabled", name)
+	return ignoreNotFound(cli.Delete(name, nil))
+}
+
+// deleteMutatingWebhookConfiguration deletes the configuration only if it is created by the Reconciler
+func (r *Reconciler) deleteMutatingWebhookConfiguration(name string) error {
+	cli := r.kubeCli.AdmissionregistrationV1beta1().MutatingWebhookConfigurations()
+	existing, err := cli.Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !labelsMatch(existing.Labels, configurationLabels()) {
+		return nil
+	}
+	klog.Infof("delete MutatingWebhookConfiguration %s as the webhook is disabled", name)
+	return ignoreNotFound(cli.Delete(name, nil))
+}
+
+func (r *Reconciler) desiredValidatingWebhookConfiguration(w webhook) *admissionregistration.ValidatingWebhookConfiguration {
+	failurePolicy := w.defaultFailurePolicy
+	if r.config.ValidationFailurePolicy != "" {
+		failurePolicy = r.config.ValidationFailurePolicy
+	}
+	matchPolicy := admissionregistration.Exact
+	sideEffects := admissionregistration.SideEffectClassUnknown
+	return &admissionregistration.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   w.configName,
+			Labels: configurationLabels(),
+		},
+		Webhooks: []admissionregistration.ValidatingWebhook{
+			{
+				Name:                    w.webhookName,
+				ClientConfig:            r.clientConfig(w),
+				Rules:                   w.rules,
+				FailurePolicy:           &failurePolicy,
+				MatchPolicy:             &matchPolicy,
+				NamespaceSelector:       r.namespaceSelector(),
+				ObjectSelector:          r.objectSelector(w),
+				SideEffects:             &sideEffects,
+				TimeoutSeconds:          timeoutSeconds(),
+				AdmissionReviewVersions: []string{"v1beta1"},
+			},
+		},
+	}
+}
+
+func (r *Reconciler) desiredMutatingWebhookConfiguration(w webhook) *admissionregistration.MutatingWebhookConfiguration {
+	failurePolicy := w.defaultFailurePolicy
+	if r.config.MutationFailurePolicy != "" {
+		failurePolicy = r.config.MutationFailurePolicy
+	}
+	matchPolicy := admissionregistration.Exact
+	sideEffects := admissionregistration.SideEffectClassUnknown
+	reinvocationPolicy := admissionregistration.NeverReinvocationPolicy
+	return &admissionregistration.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   w.configName,
+			Labels: configurationLabels(),
+		},
+		Webhooks: []admissionregistration.MutatingWebhook{
+			{
+				Name:                    w.webhookName,
+				ClientConfig:            r.clientConfig(w),
+				Rules:                   w.rules,
+				FailurePolicy:           &failurePolicy,
+				MatchPolicy:             &matchPolicy,
+				NamespaceSelector:       r.namespaceSelector(),
+				ObjectSelector:          r.objectSelector(w),
+				SideEffects:             &sideEffects,
+				TimeoutSeconds:          timeoutSeconds(),
+				AdmissionReviewVersions: []string{"v1beta1"},
+				ReinvocationPolicy:      &reinvocationPolicy,
+			},
+		},
+	}
+}
+
+// clientConfig returns the client config of the webhook, the requests are sent to the
+// kubernetes service which proxies them to the aggregated apiserver of tidb-admission-webhook
+func (r *Reconciler) clientConfig(w webhook) admissionregistration.WebhookClientConfig {
+	path := pathPrefix + w.resource
+	port := int32(443)
+	return admissionregistration.WebhookClientConfig{
+		Service: &admissionregistration.ServiceReference{
+			Name:      "kubernetes",
+			Namespace: metav1.NamespaceDefault,
+			Path:      &path,
+			Port:      &port,
+		},
+		CABundle: r.config.CABundle,
+	}
+}
+
+// namespaceSelector returns the configured namespace selector, an empty selector
+// which matches all namespaces is returned if it's not configured, as the apiserver defaults it
+func (r *Reconciler) namespaceSelector() *metav1.LabelSelector {
+	if r.config.NamespaceSelector == nil {
+		return &metav1.LabelSelector{}
+	}
+	return r.config.NamespaceSelector.DeepCopy()
+}
+
+// objectSelector merges the configured object selector into the default object selector of the webhook
+func (r *Reconciler) objectSelector(w webhook) *metav1.LabelSelector {
+	selector := &metav1.LabelSelector{}
+	if r.config.ObjectSelector != nil {
+		selector = r.config.ObjectSelector.DeepCopy()
+	}
+	if len(w.defaultObjectSelector) > 0 && selector.MatchLabels == nil {
+		selector.MatchLabels = map[string]string{}
+	}
+	for k, v := range w.defaultObjectSelector {
+		selector.MatchLabels[k] = v
+	}
+	return selector
+}
+
+func timeoutSeconds() *int32 {
+	timeout := int32(30)
+	return &timeout
+}
+
+func configurationLabels() map[string]string {
+	return map[string]string{
+		label.ManagedByLabelKey: label.TiDBOperator,
+		label.ComponentLabelKey: componentLabelVal,
+	}
+}
+
+// labelsMatch returns true if all the desired labels are in the existing labels
+func labelsMatch(existing, desired map[string]string) bool {
+	for k, v := range desired {
+		if existing[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func mergeLabels(existing, desired map[string]string) map[string]string {
+	if existing == nil {
+		existing = map[string]string{}
+	}
+	for k, v := range desired {
+		existing[k] = v
+	}
+	return existing
+}
+
+func ignoreNotFound(err error) error {
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configuration
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestReconcilerSync(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	kubeCli := kubefake.NewSimpleClientset()
+	config := Config{
+		ValidatingWebhooks:      []string{PodsWebhook, BackupsWebhook},
+		MutatingWebhooks:        []string{PingcapResourcesWebhook},
+		ValidationFailurePolicy: admissionregistration.Fail,
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"tenant": "a"},
+		},
+		ObjectSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"env": "prod"},
+		},
+	}
+	g.Expect(ValidateConfig(config)).Should(Succeed())
+	r := NewReconciler(kubeCli, config)
+	g.Expect(r.Sync()).Should(Succeed())
+
+	vcli := kubeCli.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations()
+	mcli := kubeCli.AdmissionregistrationV1beta1().MutatingWebhookConfigurations()
+
+	pods, err := vcli.Get("validation-tidb-pod-webhook-cfg", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(*pods.Webhooks[0].FailurePolicy).Should(Equal(admissionregistration.Fail))
+	g.Expect(pods.Webhooks[0].NamespaceSelector.MatchLabels).Should(HaveKeyWithValue("tenant", "a"))
+	g.Expect(pods.Webhooks[0].ObjectSelector.MatchLabels).Should(HaveKeyWithValue("env", "prod"))
+	g.Expect(pods.Webhooks[0].ObjectSelector.MatchLabels).Should(HaveKeyWithValue(label.ManagedByLabelKey, label.TiDBOperator))
+	_, err = vcli.Get("validation-tidb-backup-webhook-cfg", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	_, err = vcli.Get("validation-tidb-statefulset-webhook-cfg", metav1.GetOptions{})
+	g.Expect(errors.IsNotFound(err)).Should(BeTrue())
+	_, err = mcli.Get("pingcap-tidb-resources-defaulitng", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	// the manual changes are reverted
+	ignore := admissionregistration.Ignore
+	pods.Webhooks[0].FailurePolicy = &ignore
+	_, err = vcli.Update(pods)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(r.Sync()).Should(Succeed())
+	pods, err = vcli.Get("validation-tidb-pod-webhook-cfg", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(*pods.Webhooks[0].FailurePolicy).Should(Equal(admissionregistration.Fail))
+
+	// the configurations of disabled webhooks are deleted only if they are created by the reconciler
+	_, err = vcli.Create(&admissionregistration.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "validation-tidb-statefulset-webhook-cfg"},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	r = NewReconciler(kubeCli, Config{ValidatingWebhooks: []string{PodsWebhook}})
+	g.Expect(r.Sync()).Should(Succeed())
+	_, err = vcli.Get("validation-tidb-backup-webhook-cfg", metav1.GetOptions{})
+	g.Expect(errors.IsNotFound(err)).Should(BeTrue())
+	_, err = mcli.Get("pingcap-tidb-resources-defaulitng", metav1.GetOptions{})
+	g.Expect(errors.IsNotFound(err)).Should(BeTrue())
+	_, err = vcli.Get("validation-tidb-statefulset-webhook-cfg", metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+}
+
+func TestValidateConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(ValidateConfig(Config{ValidatingWebhooks: []string{"unknown"}})).ShouldNot(Succeed())
+	g.Expect(ValidateConfig(Config{MutatingWebhooks: []string{BackupsWebhook}})).ShouldNot(Succeed())
+	g.Expect(ValidateConfig(Config{ValidationFailurePolicy: "Retry"})).ShouldNot(Succeed())
+	g.Expect(ValidateConfig(Config{MutationFailurePolicy: admissionregistration.Ignore})).Should(Succeed())
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
 	informers "github.com/pingcap/tidb-operator/pkg/client/informers/externalversions"
 	v1alpha1listers "github.com/pingcap/tidb-operator/pkg/client/listers/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/features"
 	"github.com/pingcap/tidb-operator/pkg/label"
 	memberUtils "github.com/pingcap/tidb-operator/pkg/manager/member"
@@ -63,6 +64,8 @@ type PodAdmissionControl struct {
 	tcLister v1alpha1listers.TidbClusterLister
 	// recorder to send event
 	recorder record.EventRecorder
+	// whether to protect critical pods from being deleted by unknown service accounts
+	deletionProtection bool
 }
 
 var _ apiserver.ValidatingAdmissionHook = &PodAdmissionControl{}
@@ -81,7 +84,7 @@ var (
 	AstsControllerServiceAccounts string
 )
 
-func NewPodAdmissionControl(extraServiceAccounts []string, resyncDuration time.Duration) *PodAdmissionControl {
+func NewPodAdmissionControl(extraServiceAccounts []string, resyncDuration time.Duration, deletionProtection bool) *PodAdmissionControl {
 	serviceAccounts := sets.NewString(stsControllerServiceAccounts)
 	for _, sa := range extraServiceAccounts {
 		serviceAccounts.Insert(sa)
@@ -90,8 +93,9 @@ func NewPodAdmissionControl(extraServiceAccounts []string, resyncDuration time.D
 		serviceAccounts.Insert(AstsControllerServiceAccounts)
 	}
 	return &PodAdmissionControl{
-		serviceAccounts: serviceAccounts,
-		resyncDuration:  resyncDuration,
+		serviceAccounts:    serviceAccounts,
+		resyncDuration:     resyncDuration,
+		deletionProtection: deletionProtection,
 	}
 }
 
@@ -158,6 +162,9 @@ func (pc *PodAdmissionControl) Validate(ar *admission.AdmissionRequest) *admissi
 	klog.Infof("receive %s pod[%s/%s] by sa[%s]", operation, namespace, name, serviceAccount)
 
 	if !pc.serviceAccounts.Has(serviceAccount) {
+		if pc.deletionProtection && operation == admission.Delete {
+			return pc.admitDeleteProtectedPods(name, namespace)
+		}
 		klog.Infof("Request was not sent by known controlled ServiceAccounts, admit to %s pod [%s/%s]", operation, namespace, name)
 		return util.ARSuccess()
 	}
@@ -261,6 +268,8 @@ func (pc *PodAdmissionControl) processAdmitDeletePDPod(pod *core.Pod, ownerState
 
 	if tc.HeterogeneousWithoutLocalPD() {
 		payload.pdClient = pc.pdControl.GetPDClient(pdapi.Namespace(tc.Spec.Cluster.Namespace), tc.Spec.Cluster.Name, tc.IsTLSClusterEnabled())
+	} else if tc.ExternalPDOnly() {
+		payload.pdClient = controller.GetPDClient(pc.pdControl, tc)
 	} else {
 		payload.pdClient = pc.pdControl.GetPDClient(pdapi.Namespace(namespace), tcName, tc.IsTLSClusterEnabled())
 	}
@@ -295,6 +304,8 @@ func (pc *PodAdmissionControl) processAdmitDeleteTiKVPod(pod *core.Pod, ownerSta
 		}
 		if tc.HeterogeneousWithoutLocalPD() {
 			payload.pdClient = pc.pdControl.GetPDClient(pdapi.Namespace(tc.Spec.Cluster.Namespace), tc.Spec.Cluster.Name, tc.IsTLSClusterEnabled())
+		} else if tc.ExternalPDOnly() {
+			payload.pdClient = controller.GetPDClient(pc.pdControl, tc)
 		} else {
 			payload.pdClient = pc.pdControl.GetPDClient(pdapi.Namespace(namespace), tcName, tc.IsTLSClusterEnabled())
 		}
@@ -361,6 +372,8 @@ func (pc *PodAdmissionControl) admintCreatePods(ar *admission.AdmissionRequest)
 		var pdClient pdapi.PDClient
 		if ownerTc.HeterogeneousWithoutLocalPD() {
 			pdClient = pc.pdControl.GetPDClient(pdapi.Namespace(ownerTc.Spec.Cluster.Namespace), ownerTc.Spec.Cluster.Name, ownerTc.IsTLSClusterEnabled())
+		} else if ownerTc.ExternalPDOnly() {
+			pdClient = controller.GetPDClient(pc.pdControl, ownerTc)
 		} else {
 			pdClient = pc.pdControl.GetPDClient(pdapi.Namespace(namespace), ownerTc.Name, ownerTc.IsTLSClusterEnabled())
 		}
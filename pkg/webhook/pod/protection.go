@@ -0,0 +1,180 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pod
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	memberUtils "github.com/pingcap/tidb-operator/pkg/manager/member"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	operatorUtils "github.com/pingcap/tidb-operator/pkg/util"
+	"github.com/pingcap/tidb-operator/pkg/webhook/util"
+	admission "k8s.io/api/admission/v1beta1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+const (
+	podDeleteProtectedMsgPattern = "pod[%s/%s] is protected: %s, add annotation %s=%s to the pod to force delete it"
+)
+
+// admitDeleteProtectedPods checks the deletion requests which are not sent by the
+// known controllers, e.g. `kubectl delete pod` by users or buggy node controllers.
+// The current PD leader, TiKV stores whose region leaders are being evicted and the
+// last healthy replica of each component are refused to be deleted unless the pod is
+// annotated with the force-delete annotation.
+func (pc *PodAdmissionControl) admitDeleteProtectedPods(name, namespace string) *admission.AdmissionResponse {
+	pod, err := pc.kubeCli.CoreV1().Pods(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		klog.Infof("failed to find pod[%s/%s] during delete it,admit to delete", namespace, name)
+		return util.ARSuccess()
+	}
+
+	l := label.Label(pod.Labels)
+	if !l.IsManagedByTiDBOperator() || !l.IsTidbClusterPod() {
+		return util.ARSuccess()
+	}
+	if !(l.IsPD() || l.IsTiKV() || l.IsTiDB() || l.IsTiFlash()) {
+		return util.ARSuccess()
+	}
+	if pod.Annotations[label.AnnForceDeletePod] == label.AnnForceDeletePodVal {
+		klog.Infof("pod[%s/%s] has annotation %s, admit to delete", namespace, name, label.AnnForceDeletePod)
+		return util.ARSuccess()
+	}
+
+	tcName, exist := pod.Labels[label.InstanceLabelKey]
+	if !exist {
+		return util.ARSuccess()
+	}
+	tc, err := pc.tcLister.TidbClusters(namespace).Get(tcName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			klog.Infof("tc[%s/%s] had been deleted,admit to delete pod[%s/%s]", namespace, tcName, namespace, name)
+			return util.ARSuccess()
+		}
+		klog.Errorf("failed get tc[%s/%s],refuse to delete pod[%s/%s]", namespace, tcName, namespace, name)
+		return util.ARFail(err)
+	}
+
+	reason, err := pc.protectedReason(tc, pod)
+	if controller.IsRequeueError(err) {
+		klog.Warningf("%v, admit to delete pod[%s/%s] without checking whether it is the PD leader", err, namespace, name)
+		return util.ARSuccess()
+	}
+	if err != nil {
+		return util.ARFail(err)
+	}
+	if len(reason) > 0 {
+		klog.Infof("refuse to delete protected pod[%s/%s]: %s", namespace, name, reason)
+		return util.ARFail(fmt.Errorf(podDeleteProtectedMsgPattern, namespace, name, reason, label.AnnForceDeletePod, label.AnnForceDeletePodVal))
+	}
+	klog.Infof("pod[%s/%s] is not protected, admit to delete", namespace, name)
+	return util.ARSuccess()
+}
+
+// protectedReason returns the reason why the pod should not be deleted,
+// an empty string means the pod is not protected. A requeue error is
+// returned if PD is unavailable to tell whether the pod is the PD leader.
+func (pc *PodAdmissionControl) protectedReason(tc *v1alpha1.TidbCluster, pod *core.Pod) (string, error) {
+	l := label.Label(pod.Labels)
+	switch {
+	case l.IsPD():
+		if tc.Spec.PD == nil {
+			return "", nil
+		}
+		// the PD leader is unknown if PD is unavailable, which does not protect the pod,
+		// e.g. it may be deleted to repair PD
+		var pdErr error
+		isLeader, err := isPDLeader(pc.getPDClient(tc), pod)
+		if err != nil {
+			pdErr = controller.RequeueErrorf("PD of tidbcluster %s/%s is unavailable, failed to get the PD leader: %v", tc.Namespace, tc.Name, err)
+		} else if isLeader {
+			return "it is the PD leader", nil
+		}
+		ordinal, err := operatorUtils.GetOrdinalFromPodName(pod.Name)
+		if err != nil {
+			return "", err
+		}
+		memberName := memberUtils.PdName(tc.Name, ordinal, tc.Namespace, tc.Spec.ClusterDomain)
+		healthy := map[string]bool{}
+		for name, member := range tc.Status.PD.Members {
+			healthy[name] = member.Health
+		}
+		if isLastHealthyReplica(memberName, healthy) {
+			return "it is the last healthy PD member", nil
+		}
+		return "", pdErr
+	case l.IsTiKV():
+		return tikvStoreProtectedReason(pod, "TiKV", tc.Status.TiKV.Stores), nil
+	case l.IsTiFlash():
+		return tikvStoreProtectedReason(pod, "TiFlash", tc.Status.TiFlash.Stores), nil
+	case l.IsTiDB():
+		healthy := map[string]bool{}
+		for name, member := range tc.Status.TiDB.Members {
+			healthy[name] = member.Health
+		}
+		if isLastHealthyReplica(pod.Name, healthy) {
+			return "it is the last healthy TiDB member", nil
+		}
+	}
+	return "", nil
+}
+
+// tikvStoreProtectedReason protects the store whose region leaders are being evicted by the upgrade
+// or the scale-in, as the deletion would interrupt the graceful eviction. The stores holding region
+// leaders without an eviction in progress can be deleted, the leaders are re-elected by PD then.
+func tikvStoreProtectedReason(pod *core.Pod, component string, stores map[string]v1alpha1.TiKVStore) string {
+	_, upgradeEvicting := pod.Annotations[memberUtils.EvictLeaderBeginTime]
+	_, scaleInEvicting := pod.Annotations[EvictLeaderBeginTime]
+	healthy := map[string]bool{}
+	for _, store := range stores {
+		healthy[store.PodName] = store.State == v1alpha1.TiKVStateUp
+		if store.PodName == pod.Name && (upgradeEvicting || scaleInEvicting) && store.LeaderCount > 0 {
+			return fmt.Sprintf("region leaders of %s store %s are being evicted, %d leaders left", component, store.ID, store.LeaderCount)
+		}
+	}
+	if isLastHealthyReplica(pod.Name, healthy) {
+		return fmt.Sprintf("it is the last healthy %s store", component)
+	}
+	return ""
+}
+
+// isLastHealthyReplica returns true if the given member is healthy and there is
+// no other healthy member in the map
+func isLastHealthyReplica(name string, healthy map[string]bool) bool {
+	if !healthy[name] {
+		return false
+	}
+	for n, h := range healthy {
+		if n != name && h {
+			return false
+		}
+	}
+	return true
+}
+
+func (pc *PodAdmissionControl) getPDClient(tc *v1alpha1.TidbCluster) pdapi.PDClient {
+	if tc.HeterogeneousWithoutLocalPD() {
+		return pc.pdControl.GetPDClient(pdapi.Namespace(tc.Spec.Cluster.Namespace), tc.Spec.Cluster.Name, tc.IsTLSClusterEnabled())
+	}
+	if tc.ExternalPDOnly() {
+		return controller.GetPDClient(pc.pdControl, tc)
+	}
+	return pc.pdControl.GetPDClient(pdapi.Namespace(tc.Namespace), tc.Name, tc.IsTLSClusterEnabled())
+}
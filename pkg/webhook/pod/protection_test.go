@@ -0,0 +1,249 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pod
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned/fake"
+	"github.com/pingcap/tidb-operator/pkg/controller"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/manager/member"
+	"github.com/pingcap/tidb-operator/pkg/pdapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAdmitDeleteProtectedPods(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	type testcase struct {
+		name        string
+		component   string
+		podName     string
+		forceDelete bool
+		evicting    bool
+		pdLeader    string
+		pdErr       bool
+		changeTc    func(tc *v1alpha1.TidbCluster)
+		wantAllowed bool
+	}
+
+	tests := []testcase{
+		{
+			name:        "delete pd leader",
+			component:   label.PDLabelVal,
+			podName:     member.PdPodName(tcName, 0),
+			pdLeader:    member.PdPodName(tcName, 0),
+			wantAllowed: false,
+		},
+		{
+			name:        "force delete pd leader",
+			component:   label.PDLabelVal,
+			podName:     member.PdPodName(tcName, 0),
+			pdLeader:    member.PdPodName(tcName, 0),
+			forceDelete: true,
+			wantAllowed: true,
+		},
+		{
+			name:        "delete pd follower",
+			component:   label.PDLabelVal,
+			podName:     member.PdPodName(tcName, 1),
+			pdLeader:    member.PdPodName(tcName, 0),
+			wantAllowed: true,
+		},
+		{
+			name:        "delete pd when pd is unavailable",
+			component:   label.PDLabelVal,
+			podName:     member.PdPodName(tcName, 0),
+			pdErr:       true,
+			wantAllowed: true,
+		},
+		{
+			name:      "delete the last healthy pd when pd is unavailable",
+			component: label.PDLabelVal,
+			podName:   member.PdPodName(tcName, 1),
+			pdErr:     true,
+			changeTc: func(tc *v1alpha1.TidbCluster) {
+				for name, m := range tc.Status.PD.Members {
+					m.Health = name == member.PdPodName(tcName, 1)
+					tc.Status.PD.Members[name] = m
+				}
+			},
+			wantAllowed: false,
+		},
+		{
+			name:      "delete the last healthy pd",
+			component: label.PDLabelVal,
+			podName:   member.PdPodName(tcName, 1),
+			pdLeader:  member.PdPodName(tcName, 0),
+			changeTc: func(tc *v1alpha1.TidbCluster) {
+				for name, m := range tc.Status.PD.Members {
+					m.Health = name == member.PdPodName(tcName, 1)
+					tc.Status.PD.Members[name] = m
+				}
+			},
+			wantAllowed: false,
+		},
+		{
+			name:        "delete tikv holding leaders",
+			component:   label.TiKVLabelVal,
+			podName:     member.TikvPodName(tcName, 0),
+			wantAllowed: true,
+		},
+		{
+			name:        "delete tikv evicting leaders",
+			component:   label.TiKVLabelVal,
+			podName:     member.TikvPodName(tcName, 0),
+			evicting:    true,
+			wantAllowed: false,
+		},
+		{
+			name:        "force delete tikv evicting leaders",
+			component:   label.TiKVLabelVal,
+			podName:     member.TikvPodName(tcName, 0),
+			evicting:    true,
+			forceDelete: true,
+			wantAllowed: true,
+		},
+		{
+			name:      "delete tikv whose leaders are evicted",
+			component: label.TiKVLabelVal,
+			podName:   member.TikvPodName(tcName, 0),
+			evicting:  true,
+			changeTc: func(tc *v1alpha1.TidbCluster) {
+				for id, store := range tc.Status.TiKV.Stores {
+					store.LeaderCount = 0
+					tc.Status.TiKV.Stores[id] = store
+				}
+			},
+			wantAllowed: true,
+		},
+		{
+			name:      "delete the last healthy tidb",
+			component: label.TiDBLabelVal,
+			podName:   "tc-tidb-0",
+			changeTc: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.TiDB.Members = map[string]v1alpha1.TiDBMember{
+					"tc-tidb-0": {Name: "tc-tidb-0", Health: true},
+					"tc-tidb-1": {Name: "tc-tidb-1", Health: false},
+				}
+			},
+			wantAllowed: false,
+		},
+		{
+			name:      "delete a tidb with other healthy members",
+			component: label.TiDBLabelVal,
+			podName:   "tc-tidb-0",
+			changeTc: func(tc *v1alpha1.TidbCluster) {
+				tc.Status.TiDB.Members = map[string]v1alpha1.TiDBMember{
+					"tc-tidb-0": {Name: "tc-tidb-0", Health: true},
+					"tc-tidb-1": {Name: "tc-tidb-1", Health: true},
+				}
+			},
+			wantAllowed: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Log(test.name)
+
+		tc := newTidbClusterForPodAdmissionControl(pdReplicas, tikvReplicas)
+		if test.changeTc != nil {
+			test.changeTc(tc)
+		}
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      test.podName,
+				Namespace: namespace,
+				Labels: map[string]string{
+					label.ManagedByLabelKey: label.TiDBOperator,
+					label.NameLabelKey:      "tidb-cluster",
+					label.ComponentLabelKey: test.component,
+					label.InstanceLabelKey:  tcName,
+				},
+			},
+		}
+		pod.Annotations = map[string]string{}
+		if test.forceDelete {
+			pod.Annotations[label.AnnForceDeletePod] = label.AnnForceDeletePodVal
+		}
+		if test.evicting {
+			pod.Annotations[member.EvictLeaderBeginTime] = time.Now().Format(time.RFC3339)
+		}
+
+		kubeCli := kubefake.NewSimpleClientset()
+		kubeCli.CoreV1().Pods(namespace).Create(pod)
+		cli := fake.NewSimpleClientset()
+		cli.PingcapV1alpha1().TidbClusters(namespace).Create(tc)
+
+		podAdmissionControl := newPodAdmissionControl(nil, kubeCli, cli)
+		pdControl := pdapi.NewFakePDControl(kubeCli)
+		podAdmissionControl.pdControl = pdControl
+		fakePDClient := controller.NewFakePDClient(pdControl, tc)
+		fakePDClient.AddReaction(pdapi.GetPDLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+			if test.pdErr {
+				return (*pdpb.Member)(nil), fmt.Errorf("connection refused")
+			}
+			return &pdpb.Member{Name: test.pdLeader}, nil
+		})
+
+		resp := podAdmissionControl.admitDeleteProtectedPods(pod.Name, namespace)
+		g.Expect(resp.Allowed).Should(Equal(test.wantAllowed))
+		if test.pdErr && !test.wantAllowed {
+			_, err := podAdmissionControl.protectedReason(tc, pod)
+			g.Expect(err).NotTo(HaveOccurred())
+		}
+	}
+}
+
+func TestProtectedReasonPDUnavailable(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tc := newTidbClusterForPodAdmissionControl(pdReplicas, tikvReplicas)
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      member.PdPodName(tcName, 0),
+		Namespace: namespace,
+		Labels:    map[string]string{label.ComponentLabelKey: label.PDLabelVal},
+	}}
+	kubeCli := kubefake.NewSimpleClientset()
+	podAdmissionControl := newPodAdmissionControl(nil, kubeCli, fake.NewSimpleClientset())
+	pdControl := pdapi.NewFakePDControl(kubeCli)
+	podAdmissionControl.pdControl = pdControl
+	fakePDClient := controller.NewFakePDClient(pdControl, tc)
+	fakePDClient.AddReaction(pdapi.GetPDLeaderActionType, func(action *pdapi.Action) (interface{}, error) {
+		return (*pdpb.Member)(nil), fmt.Errorf("connection refused")
+	})
+
+	reason, err := podAdmissionControl.protectedReason(tc, pod)
+	g.Expect(reason).To(BeEmpty())
+	g.Expect(controller.IsRequeueError(err)).To(BeTrue())
+	g.Expect(err.Error()).To(ContainSubstring("PD of tidbcluster %s/%s is unavailable", namespace, tcName))
+}
+
+func TestIsLastHealthyReplica(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(isLastHealthyReplica("a", map[string]bool{"a": true})).Should(BeTrue())
+	g.Expect(isLastHealthyReplica("a", map[string]bool{"a": true, "b": false})).Should(BeTrue())
+	g.Expect(isLastHealthyReplica("a", map[string]bool{"a": true, "b": true})).Should(BeFalse())
+	g.Expect(isLastHealthyReplica("a", map[string]bool{"a": false, "b": true})).Should(BeFalse())
+	g.Expect(isLastHealthyReplica("a", map[string]bool{})).Should(BeFalse())
+}
@@ -0,0 +1,297 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+)
+
+// Policy defines the limits of the TidbClusters per namespace, it is loaded from a ConfigMap
+// maintained by the platform admins, e.g.
+//
+//	default:
+//	  maxReplicas:
+//	    tikv: 3
+//	namespaces:
+//	  tenant-a:
+//	    maxReplicas:
+//	      pd: 3
+//	      tikv: 5
+//	    maxStorage:
+//	      tikv: 500Gi
+//	    allowedStorageClasses: ["ebs-gp3"]
+//	    allowedVersions: ">= 4.0.9, < 5.0.0"
+type Policy struct {
+	// Default is applied to the namespaces not in Namespaces
+	// +optional
+	Default *NamespacePolicy `json:"default,omitempty"`
+	// Namespaces maps the namespace to its policy
+	// +optional
+	Namespaces map[string]NamespacePolicy `json:"namespaces,omitempty"`
+}
+
+// NamespacePolicy defines the limits of the TidbClusters in a namespace
+type NamespacePolicy struct {
+	// MaxReplicas limits the replicas of each component, e.g. pd, tikv, tidb
+	// +optional
+	MaxReplicas map[v1alpha1.MemberType]int32 `json:"maxReplicas,omitempty"`
+	// MaxStorage limits the storage requested by each replica of the component, including the storage volumes
+	// +optional
+	MaxStorage map[v1alpha1.MemberType]resource.Quantity `json:"maxStorage,omitempty"`
+	// AllowedStorageClasses limits the storage classes used by the components,
+	// the components without storageClassName use the default storage class and are not restricted
+	// +optional
+	AllowedStorageClasses []string `json:"allowedStorageClasses,omitempty"`
+	// AllowedVersions is a semantic version constraint of the versions of the components, e.g. ">= 4.0.9, < 5.0.0".
+	// The image tags which are not semantic versions, e.g. latest or nightly, are not restricted
+	// +optional
+	AllowedVersions string `json:"allowedVersions,omitempty"`
+}
+
+// Violation describes a policy rule violated by a TidbCluster
+type Violation struct {
+	// Rule is the name of the violated rule, e.g. maxReplicas.tikv
+	Rule    string
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("rule %s: %s", v.Rule, v.Message)
+}
+
+// ParsePolicy parses the policy in YAML or JSON format
+func ParsePolicy(data []byte) (*Policy, error) {
+	policy := &Policy{}
+	if err := yaml.Unmarshal(data, policy); err != nil {
+		return nil, err
+	}
+	for ns, p := range policy.Namespaces {
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("invalid policy of namespace %s: %v", ns, err)
+		}
+	}
+	if policy.Default != nil {
+		if err := policy.Default.validate(); err != nil {
+			return nil, fmt.Errorf("invalid default policy: %v", err)
+		}
+	}
+	return policy, nil
+}
+
+func (p *NamespacePolicy) validate() error {
+	if p.AllowedVersions == "" {
+		return nil
+	}
+	_, err := semver.NewConstraint(p.AllowedVersions)
+	return err
+}
+
+// ForNamespace returns the policy applied to the namespace, nil is returned if no policy is applied
+func (p *Policy) ForNamespace(ns string) *NamespacePolicy {
+	if p == nil {
+		return nil
+	}
+	if policy, ok := p.Namespaces[ns]; ok {
+		return &policy
+	}
+	return p.Default
+}
+
+// component is the view of a component of TidbCluster checked by the policy
+type component struct {
+	memberType     v1alpha1.MemberType
+	replicas       int32
+	version        string
+	storage        resource.Quantity
+	storageClasses []*string
+}
+
+// Check returns the rules of the policy violated by the TidbCluster
+func (p *NamespacePolicy) Check(tc *v1alpha1.TidbCluster) []Violation {
+	return p.CheckUpdate(nil, tc)
+}
+
+// CheckUpdate returns the rules of the policy violated by the update of the TidbCluster from old, only the
+// rules whose fields are changed are checked, so a cluster created before the policy can still be updated,
+// and the changes which do not make a violation worse, e.g. scaling in, are allowed. All the rules are checked
+// if old is nil.
+func (p *NamespacePolicy) CheckUpdate(old, tc *v1alpha1.TidbCluster) []Violation {
+	if p == nil {
+		return nil
+	}
+	var violations []Violation
+	allowedClasses := sets.NewString(p.AllowedStorageClasses...)
+	var constraint *semver.Constraints
+	if p.AllowedVersions != "" {
+		// the constraint has been validated when the policy is parsed
+		constraint, _ = semver.NewConstraint(p.AllowedVersions)
+	}
+	oldComponents := map[v1alpha1.MemberType]component{}
+	if old != nil {
+		for _, c := range components(old) {
+			oldComponents[c.memberType] = c
+		}
+	}
+
+	for _, c := range components(tc) {
+		oldC, existed := oldComponents[c.memberType]
+		if max, ok := p.MaxReplicas[c.memberType]; ok && c.replicas > max && (!existed || c.replicas > oldC.replicas) {
+			violations = append(violations, Violation{
+				Rule:    fmt.Sprintf("maxReplicas.%s", c.memberType),
+				Message: fmt.Sprintf("%s replicas %d exceeds the limit %d", c.memberType, c.replicas, max),
+			})
+		}
+		if max, ok := p.MaxStorage[c.memberType]; ok && c.storage.Cmp(max) > 0 && (!existed || c.storage.Cmp(oldC.storage) > 0) {
+			violations = append(violations, Violation{
+				Rule:    fmt.Sprintf("maxStorage.%s", c.memberType),
+				Message: fmt.Sprintf("%s storage %s per replica exceeds the limit %s", c.memberType, c.storage.String(), max.String()),
+			})
+		}
+		if allowedClasses.Len() > 0 {
+			oldClasses := sets.NewString()
+			for _, sc := range oldC.storageClasses {
+				if sc != nil {
+					oldClasses.Insert(*sc)
+				}
+			}
+			for _, sc := range c.storageClasses {
+				if sc != nil && !allowedClasses.Has(*sc) && !oldClasses.Has(*sc) {
+					violations = append(violations, Violation{
+						Rule:    "allowedStorageClasses",
+						Message: fmt.Sprintf("%s storage class %s is not in %v", c.memberType, *sc, allowedClasses.List()),
+					})
+				}
+			}
+		}
+		if constraint != nil && c.version != "" && (!existed || c.version != oldC.version) {
+			// the tags which are not semantic versions, e.g. latest or nightly, are not restricted by the constraint
+			if v, err := semver.NewVersion(c.version); err == nil && !constraint.Check(v) {
+				violations = append(violations, Violation{
+					Rule:    "allowedVersions",
+					Message: fmt.Sprintf("%s version %s does not satisfy %q", c.memberType, c.version, p.AllowedVersions),
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// FormatViolations joins the violations into a message
+func FormatViolations(violations []Violation) string {
+	msgs := make([]string, 0, len(violations))
+	for _, v := range violations {
+		msgs = append(msgs, v.String())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func components(tc *v1alpha1.TidbCluster) []component {
+	var result []component
+	if tc.Spec.PD != nil {
+		result = append(result, component{
+			memberType:     v1alpha1.PDMemberType,
+			replicas:       tc.Spec.PD.Replicas,
+			version:        tc.PDVersion(),
+			storage:        storageRequest(tc.Spec.PD.Requests, tc.Spec.PD.StorageVolumes),
+			storageClasses: storageClasses(tc.Spec.PD.StorageClassName, tc.Spec.PD.StorageVolumes),
+		})
+	}
+	if tc.Spec.TiKV != nil {
+		result = append(result, component{
+			memberType:     v1alpha1.TiKVMemberType,
+			replicas:       tc.Spec.TiKV.Replicas,
+			version:        tc.TiKVVersion(),
+			storage:        storageRequest(tc.Spec.TiKV.Requests, tc.Spec.TiKV.StorageVolumes),
+			storageClasses: storageClasses(tc.Spec.TiKV.StorageClassName, tc.Spec.TiKV.StorageVolumes),
+		})
+	}
+	if tc.Spec.TiDB != nil {
+		result = append(result, component{
+			memberType: v1alpha1.TiDBMemberType,
+			replicas:   tc.Spec.TiDB.Replicas,
+			version:    imageVersion(tc.TiDBImage()),
+		})
+	}
+	if tc.Spec.TiFlash != nil {
+		c := component{
+			memberType: v1alpha1.TiFlashMemberType,
+			replicas:   tc.Spec.TiFlash.Replicas,
+			version:    imageVersion(tc.TiFlashImage()),
+		}
+		for _, claim := range tc.Spec.TiFlash.StorageClaims {
+			if q, ok := claim.Resources.Requests[corev1.ResourceStorage]; ok {
+				c.storage.Add(q)
+			}
+			c.storageClasses = append(c.storageClasses, claim.StorageClassName)
+		}
+		result = append(result, c)
+	}
+	if tc.Spec.TiCDC != nil {
+		result = append(result, component{
+			memberType: v1alpha1.TiCDCMemberType,
+			replicas:   tc.Spec.TiCDC.Replicas,
+		})
+	}
+	if tc.Spec.Pump != nil {
+		result = append(result, component{
+			memberType:     v1alpha1.PumpMemberType,
+			replicas:       tc.Spec.Pump.Replicas,
+			storage:        storageRequest(tc.Spec.Pump.Requests, nil),
+			storageClasses: storageClasses(tc.Spec.Pump.StorageClassName, nil),
+		})
+	}
+	return result
+}
+
+// storageRequest returns the sum of the data storage and the storage volumes of a replica
+func storageRequest(requests corev1.ResourceList, volumes []v1alpha1.StorageVolume) resource.Quantity {
+	total := resource.Quantity{}
+	if q, ok := requests[corev1.ResourceStorage]; ok {
+		total.Add(q)
+	}
+	for _, v := range volumes {
+		if q, err := resource.ParseQuantity(v.StorageSize); err == nil {
+			total.Add(q)
+		}
+	}
+	return total
+}
+
+func storageClasses(sc *string, volumes []v1alpha1.StorageVolume) []*string {
+	classes := []*string{sc}
+	for _, v := range volumes {
+		classes = append(classes, v.StorageClassName)
+	}
+	return classes
+}
+
+// imageVersion returns the tag of the image
+func imageVersion(image string) string {
+	if image == "" {
+		return ""
+	}
+	colonIdx := strings.LastIndexByte(image, ':')
+	if colonIdx >= 0 {
+		return image[colonIdx+1:]
+	}
+	return "latest"
+}
@@ -0,0 +1,322 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	admission "k8s.io/api/admission/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/pointer"
+)
+
+const testPolicy = `
+default:
+  maxReplicas:
+    tikv: 3
+namespaces:
+  tenant-a:
+    maxReplicas:
+      tikv: 5
+    maxStorage:
+      tikv: 100Gi
+    allowedStorageClasses: ["fast"]
+    allowedVersions: ">= 4.0.9, < 5.0.0"
+  unlimited: {}
+`
+
+func newTidbCluster(ns string) *v1alpha1.TidbCluster {
+	return &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "tc",
+			Namespace: ns,
+		},
+		Spec: v1alpha1.TidbClusterSpec{
+			Version: "v4.0.10",
+			PD: &v1alpha1.PDSpec{
+				Replicas:  3,
+				BaseImage: "pingcap/pd",
+			},
+			TiKV: &v1alpha1.TiKVSpec{
+				Replicas:         3,
+				BaseImage:        "pingcap/tikv",
+				StorageClassName: pointer.StringPtr("fast"),
+				ResourceRequirements: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceStorage: resource.MustParse("50Gi"),
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestCheck(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	policy, err := ParsePolicy([]byte(testPolicy))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	tests := []struct {
+		name      string
+		namespace string
+		changeTc  func(tc *v1alpha1.TidbCluster)
+		wantRules []string
+	}{
+		{
+			name:      "valid cluster",
+			namespace: "tenant-a",
+		},
+		{
+			name:      "too many replicas",
+			namespace: "tenant-a",
+			changeTc:  func(tc *v1alpha1.TidbCluster) { tc.Spec.TiKV.Replicas = 6 },
+			wantRules: []string{"maxReplicas.tikv"},
+		},
+		{
+			name:      "too much storage with storage volumes",
+			namespace: "tenant-a",
+			changeTc: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.TiKV.StorageVolumes = []v1alpha1.StorageVolume{{Name: "wal", StorageSize: "60Gi"}}
+			},
+			wantRules: []string{"maxStorage.tikv"},
+		},
+		{
+			name:      "storage class not allowed",
+			namespace: "tenant-a",
+			changeTc:  func(tc *v1alpha1.TidbCluster) { tc.Spec.PD.StorageClassName = pointer.StringPtr("slow") },
+			wantRules: []string{"allowedStorageClasses"},
+		},
+		{
+			name:      "version not allowed",
+			namespace: "tenant-a",
+			changeTc:  func(tc *v1alpha1.TidbCluster) { tc.Spec.Version = "v5.0.0" },
+			wantRules: []string{"allowedVersions", "allowedVersions"},
+		},
+		{
+			name:      "default policy",
+			namespace: "tenant-b",
+			changeTc:  func(tc *v1alpha1.TidbCluster) { tc.Spec.TiKV.Replicas = 4 },
+			wantRules: []string{"maxReplicas.tikv"},
+		},
+		{
+			name:      "namespace without limits",
+			namespace: "unlimited",
+			changeTc:  func(tc *v1alpha1.TidbCluster) { tc.Spec.TiKV.Replicas = 10 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+
+		tc := newTidbCluster(tt.namespace)
+		if tt.changeTc != nil {
+			tt.changeTc(tc)
+		}
+		var rules []string
+		for _, v := range policy.ForNamespace(tt.namespace).Check(tc) {
+			rules = append(rules, v.Rule)
+		}
+		g.Expect(rules).Should(Equal(tt.wantRules))
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	_, err := ParsePolicy([]byte(`namespaces: {a: {allowedVersions: "invalid"}}`))
+	g.Expect(err).Should(HaveOccurred())
+	_, err = ParsePolicy([]byte(`default: {maxStorage: {tikv: "not-a-quantity"}}`))
+	g.Expect(err).Should(HaveOccurred())
+}
+
+func TestCheckUpdate(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	policy, err := ParsePolicy([]byte(testPolicy))
+	g.Expect(err).NotTo(HaveOccurred())
+	nsPolicy := policy.ForNamespace("tenant-a")
+
+	tests := []struct {
+		name      string
+		changeOld func(tc *v1alpha1.TidbCluster)
+		changeTc  func(tc *v1alpha1.TidbCluster)
+		wantRules []string
+	}{
+		{
+			name: "unchanged violations",
+			changeOld: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.TiKV.Replicas = 8
+				tc.Spec.PD.StorageClassName = pointer.StringPtr("slow")
+			},
+			changeTc: func(tc *v1alpha1.TidbCluster) {
+				tc.Spec.TiKV.Replicas = 8
+				tc.Spec.PD.StorageClassName = pointer.StringPtr("slow")
+				tc.Annotations = map[string]string{"foo": "bar"}
+			},
+		},
+		{
+			name:      "scale in",
+			changeOld: func(tc *v1alpha1.TidbCluster) { tc.Spec.TiKV.Replicas = 8 },
+			changeTc:  func(tc *v1alpha1.TidbCluster) { tc.Spec.TiKV.Replicas = 7 },
+		},
+		{
+			name:      "scale out",
+			changeOld: func(tc *v1alpha1.TidbCluster) { tc.Spec.TiKV.Replicas = 8 },
+			changeTc:  func(tc *v1alpha1.TidbCluster) { tc.Spec.TiKV.Replicas = 9 },
+			wantRules: []string{"maxReplicas.tikv"},
+		},
+		{
+			name:      "upgrade to a version not allowed",
+			changeTc:  func(tc *v1alpha1.TidbCluster) { tc.Spec.TiKV.Version = pointer.StringPtr("v5.0.0") },
+			wantRules: []string{"allowedVersions"},
+		},
+		{
+			name:     "tags which are not semantic versions",
+			changeTc: func(tc *v1alpha1.TidbCluster) { tc.Spec.Version = "nightly" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+
+		old := newTidbCluster("tenant-a")
+		if tt.changeOld != nil {
+			tt.changeOld(old)
+		}
+		tc := newTidbCluster("tenant-a")
+		tt.changeTc(tc)
+		var rules []string
+		for _, v := range nsPolicy.CheckUpdate(old, tc) {
+			rules = append(rules, v.Rule)
+		}
+		g.Expect(rules).Should(Equal(tt.wantRules))
+	}
+}
+
+func TestPolicyAdmissionControl(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	kubeCli := kubefake.NewSimpleClientset()
+	operatorSA := "system:serviceaccount:tidb-admin:tidb-controller-manager"
+	pc := NewPolicyAdmissionControl("tidb-admin", "tidb-policy", []string{operatorSA}, time.Minute)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	pc.initialize(kubeCli, stopCh)
+
+	old := newTidbCluster("tenant-a")
+	tc := newTidbCluster("tenant-a")
+	tc.Spec.TiKV.Replicas = 6
+	raw, err := json.Marshal(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	oldRaw, err := json.Marshal(old)
+	g.Expect(err).NotTo(HaveOccurred())
+	ar := &admission.AdmissionRequest{
+		Name:      tc.Name,
+		Namespace: tc.Namespace,
+		Operation: admission.Update,
+		Kind:      metav1.GroupVersionKind{Group: "pingcap.com", Version: "v1alpha1", Kind: v1alpha1.TiDBClusterKind},
+		Object:    runtime.RawExtension{Raw: raw},
+		OldObject: runtime.RawExtension{Raw: oldRaw},
+		UserInfo:  authenticationv1.UserInfo{Username: "admin"},
+	}
+
+	// no policy
+	g.Expect(pc.Validate(ar).Allowed).Should(BeTrue())
+
+	_, err = kubeCli.CoreV1().ConfigMaps("tidb-admin").Create(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "tidb-policy", Namespace: "tidb-admin"},
+		Data:       map[string]string{PolicyKey: testPolicy},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Eventually(func() bool { return pc.Validate(ar).Allowed }, 5*time.Second, 10*time.Millisecond).Should(BeFalse())
+	resp := pc.Validate(ar)
+	g.Expect(resp.Result.Message).Should(ContainSubstring("maxReplicas.tikv"))
+
+	// the updates of the operator are not checked
+	ar.UserInfo.Username = operatorSA
+	g.Expect(pc.Validate(ar).Allowed).Should(BeTrue())
+
+	// the update which does not change the violating fields is allowed
+	ar.UserInfo.Username = "admin"
+	ar.OldObject = runtime.RawExtension{Raw: raw}
+	g.Expect(pc.Validate(ar).Allowed).Should(BeTrue())
+}
+
+func TestPolicyAdmissionControlInvalidPolicy(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	kubeCli := kubefake.NewSimpleClientset()
+	pc := NewPolicyAdmissionControl("tidb-admin", "tidb-policy", nil, time.Minute)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	pc.initialize(kubeCli, stopCh)
+
+	tc := newTidbCluster("tenant-a")
+	tc.Spec.TiKV.Replicas = 6
+	raw, err := json.Marshal(tc)
+	g.Expect(err).NotTo(HaveOccurred())
+	ar := &admission.AdmissionRequest{
+		Name:      tc.Name,
+		Namespace: tc.Namespace,
+		Operation: admission.Create,
+		Kind:      metav1.GroupVersionKind{Group: "pingcap.com", Version: "v1alpha1", Kind: v1alpha1.TiDBClusterKind},
+		Object:    runtime.RawExtension{Raw: raw},
+		UserInfo:  authenticationv1.UserInfo{Username: "admin"},
+	}
+
+	// no policy is applied if the policy is invalid from the start
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "tidb-policy", Namespace: "tidb-admin", ResourceVersion: "1"},
+		Data:       map[string]string{PolicyKey: "namespaces: ["},
+	}
+	_, err = kubeCli.CoreV1().ConfigMaps("tidb-admin").Create(cm)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Eventually(func() error {
+		_, err := pc.configMapLister.ConfigMaps("tidb-admin").Get("tidb-policy")
+		return err
+	}, 5*time.Second, 10*time.Millisecond).Should(Succeed())
+	g.Expect(pc.Validate(ar).Allowed).Should(BeTrue())
+
+	cm.ResourceVersion = "2"
+	cm.Data[PolicyKey] = testPolicy
+	_, err = kubeCli.CoreV1().ConfigMaps("tidb-admin").Update(cm)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Eventually(func() bool { return pc.Validate(ar).Allowed }, 5*time.Second, 10*time.Millisecond).Should(BeFalse())
+
+	// the last valid policy is applied after the policy is broken
+	cm.ResourceVersion = "3"
+	cm.Data[PolicyKey] = "namespaces: ["
+	_, err = kubeCli.CoreV1().ConfigMaps("tidb-admin").Update(cm)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Eventually(func() string {
+		cm, err := pc.configMapLister.ConfigMaps("tidb-admin").Get("tidb-policy")
+		if err != nil {
+			return ""
+		}
+		return cm.ResourceVersion
+	}, 5*time.Second, 10*time.Millisecond).Should(Equal("3"))
+	resp := pc.Validate(ar)
+	g.Expect(resp.Allowed).Should(BeFalse())
+	g.Expect(resp.Result.Message).Should(ContainSubstring("maxReplicas.tikv"))
+}
@@ -0,0 +1,189 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/openshift/generic-admission-server/pkg/apiserver"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/webhook/util"
+	admission "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubeinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisterv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog"
+)
+
+const (
+	// PolicyKey is the key of the policy in the data of the ConfigMap
+	PolicyKey = "policy.yaml"
+)
+
+// PolicyAdmissionControl rejects the creations and updates of TidbClusters violating
+// the per-namespace limits defined by the platform admins in a ConfigMap.
+// The updates only violate the policy by the changed fields, and the updates made by
+// the operator, e.g. writing the status or removing the finalizers, are not checked.
+type PolicyAdmissionControl struct {
+	lock        sync.RWMutex
+	initialized bool
+	// lister of the ConfigMaps in the namespace of the policy
+	configMapLister corelisterv1.ConfigMapLister
+	// the namespace and the name of the ConfigMap storing the policy
+	configMapNamespace string
+	configMapName      string
+	// the service accounts of the operator whose updates are not checked
+	operatorServiceAccounts sets.String
+	resyncDuration          time.Duration
+	// the last valid policy and the resource version of the ConfigMap it is parsed from, which
+	// is applied if the policy in the ConfigMap is invalid so a bad edit does not deny all updates
+	policyLock          sync.Mutex
+	lastPolicy          *Policy
+	lastResourceVersion string
+}
+
+var _ apiserver.ValidatingAdmissionHook = &PolicyAdmissionControl{}
+
+func NewPolicyAdmissionControl(configMapNamespace, configMapName string, operatorServiceAccounts []string, resyncDuration time.Duration) *PolicyAdmissionControl {
+	return &PolicyAdmissionControl{
+		configMapNamespace:      configMapNamespace,
+		configMapName:           configMapName,
+		operatorServiceAccounts: sets.NewString(operatorServiceAccounts...),
+		resyncDuration:          resyncDuration,
+	}
+}
+
+func (pc *PolicyAdmissionControl) ValidatingResource() (plural schema.GroupVersionResource, singular string) {
+	return schema.GroupVersionResource{
+			Group:    "admission.tidb.pingcap.com",
+			Version:  "v1alpha1",
+			Resource: "tidbclusterpolicies",
+		},
+		"tidbclusterpolicy"
+}
+
+func (pc *PolicyAdmissionControl) Validate(ar *admission.AdmissionRequest) *admission.AdmissionResponse {
+	pc.lock.RLock()
+	defer pc.lock.RUnlock()
+	if !pc.initialized {
+		return &admission.AdmissionResponse{
+			Allowed: false,
+		}
+	}
+
+	if ar.Kind.Kind != v1alpha1.TiDBClusterKind || (ar.Operation != admission.Create && ar.Operation != admission.Update) {
+		return util.ARSuccess()
+	}
+	if ar.Operation == admission.Update && pc.operatorServiceAccounts.Has(ar.UserInfo.Username) {
+		return util.ARSuccess()
+	}
+
+	policy, err := pc.loadPolicy()
+	if err != nil {
+		klog.Errorf("failed to load the policy from configmap %s/%s: %v", pc.configMapNamespace, pc.configMapName, err)
+		return util.ARFail(err)
+	}
+	nsPolicy := policy.ForNamespace(ar.Namespace)
+	if nsPolicy == nil {
+		return util.ARSuccess()
+	}
+
+	tc := &v1alpha1.TidbCluster{}
+	if err := json.Unmarshal(ar.Object.Raw, tc); err != nil {
+		klog.Errorf("Could not unmarshal raw object: %v", err)
+		return util.ARFail(err)
+	}
+	var old *v1alpha1.TidbCluster
+	if ar.Operation == admission.Update && len(ar.OldObject.Raw) > 0 {
+		old = &v1alpha1.TidbCluster{}
+		if err := json.Unmarshal(ar.OldObject.Raw, old); err != nil {
+			klog.Errorf("Could not unmarshal raw old object: %v", err)
+			return util.ARFail(err)
+		}
+	}
+	if violations := nsPolicy.CheckUpdate(old, tc); len(violations) > 0 {
+		err := fmt.Errorf("tidbcluster %s/%s violates the policy of namespace %s: %s", ar.Namespace, ar.Name, ar.Namespace, FormatViolations(violations))
+		klog.Infof("refuse to %s tidbcluster %s/%s: %v", ar.Operation, ar.Namespace, ar.Name, err)
+		return util.ARFail(err)
+	}
+	return util.ARSuccess()
+}
+
+// loadPolicy loads the policy from the ConfigMap, no policy is applied if the ConfigMap does not exist.
+// The last valid policy is applied if the policy in the ConfigMap is invalid, or no policy if there is none.
+func (pc *PolicyAdmissionControl) loadPolicy() (*Policy, error) {
+	pc.policyLock.Lock()
+	defer pc.policyLock.Unlock()
+	cm, err := pc.configMapLister.ConfigMaps(pc.configMapNamespace).Get(pc.configMapName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			pc.lastPolicy = nil
+			return nil, nil
+		}
+		return nil, err
+	}
+	data, ok := cm.Data[PolicyKey]
+	if !ok {
+		pc.lastPolicy = nil
+		return nil, nil
+	}
+	policy, err := ParsePolicy([]byte(data))
+	if err != nil {
+		if pc.lastPolicy == nil {
+			klog.Warningf("invalid policy in configmap %s/%s, no policy is applied until it is fixed: %v", pc.configMapNamespace, pc.configMapName, err)
+			return nil, nil
+		}
+		klog.Warningf("invalid policy in configmap %s/%s, the last valid policy of resource version %s is applied until it is fixed: %v",
+			pc.configMapNamespace, pc.configMapName, pc.lastResourceVersion, err)
+		return pc.lastPolicy, nil
+	}
+	pc.lastPolicy = policy
+	pc.lastResourceVersion = cm.ResourceVersion
+	return policy, nil
+}
+
+func (pc *PolicyAdmissionControl) initialize(kubeCli kubernetes.Interface, stopCh <-chan struct{}) {
+	// only the ConfigMaps in the namespace of the policy are cached
+	informerFactory := kubeinformers.NewSharedInformerFactoryWithOptions(kubeCli, pc.resyncDuration, kubeinformers.WithNamespace(pc.configMapNamespace))
+	pc.configMapLister = informerFactory.Core().V1().ConfigMaps().Lister()
+	informerFactory.Start(stopCh)
+	for v, synced := range informerFactory.WaitForCacheSync(wait.NeverStop) {
+		if !synced {
+			klog.Fatalf("error syncing informer for %v", v)
+		}
+	}
+	pc.initialized = true
+}
+
+// Initialize implements AdmissionHook.Initialize interface. It's is called as
+// a post-start hook.
+func (pc *PolicyAdmissionControl) Initialize(cfg *rest.Config, stopCh <-chan struct{}) error {
+	pc.lock.Lock()
+	defer pc.lock.Unlock()
+
+	kubeCli, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	pc.initialize(kubeCli, stopCh)
+	return nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"fmt"
+
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	"github.com/pingcap/tidb-operator/pkg/manager/member"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// stsScale is the replicas and the partition of a statefulset
+type stsScale struct {
+	replicas  int32
+	partition int32
+}
+
+// affectedOrdinals returns the ordinals of the pods which would be restarted and removed
+// when the statefulset is changed from old to new
+func affectedOrdinals(old, new stsScale) (restarted, removed []int32) {
+	for i := new.partition; i < old.partition && i < old.replicas && i < new.replicas; i++ {
+		restarted = append(restarted, i)
+	}
+	for i := new.replicas; i < old.replicas; i++ {
+		removed = append(removed, i)
+	}
+	return
+}
+
+// checkMemberHealth cross-checks the partition and replicas changes of the statefulset against
+// the status of the TidbCluster, the changes which would restart or remove members of an unhealthy
+// member set are refused, as the upgrader and the scaler of tidb-operator do.
+func checkMemberHealth(tc *v1alpha1.TidbCluster, l label.Label, old, new stsScale) error {
+	restarted, removed := affectedOrdinals(old, new)
+	if len(restarted) == 0 && len(removed) == 0 {
+		return nil
+	}
+	switch {
+	case l.IsPD():
+		return checkPDHealth(tc, restarted, removed)
+	case l.IsTiKV():
+		return checkTiKVHealth(tc, restarted, removed)
+	case l.IsTiDB():
+		return checkTiDBHealth(tc, restarted, removed)
+	}
+	return nil
+}
+
+// checkPDHealth refuses the changes which would make the PD cluster lose the quorum
+func checkPDHealth(tc *v1alpha1.TidbCluster, restarted, removed []int32) error {
+	if len(tc.Status.PD.Members) == 0 {
+		return nil
+	}
+	affected := sets.NewString()
+	for _, ordinal := range append(restarted, removed...) {
+		affected.Insert(member.PdName(tc.Name, ordinal, tc.Namespace, tc.Spec.ClusterDomain))
+	}
+	healthy := 0
+	for name, m := range tc.Status.PD.Members {
+		if m.Health && !affected.Has(name) {
+			healthy++
+		}
+	}
+	total := len(tc.Status.PD.Members)
+	if healthy <= total/2 {
+		return fmt.Errorf("only %d of %d PD members would be healthy, PD would lose the quorum", healthy, total)
+	}
+	return nil
+}
+
+// checkTiKVHealth refuses to restart stores when other stores are not Up or the restarted stores
+// still hold region leaders, and refuses to remove stores which are not tombstone
+func checkTiKVHealth(tc *v1alpha1.TidbCluster, restarted, removed []int32) error {
+	restartedPods := sets.NewString()
+	for _, ordinal := range restarted {
+		restartedPods.Insert(member.TikvPodName(tc.Name, ordinal))
+	}
+	removedPods := sets.NewString()
+	for _, ordinal := range removed {
+		removedPods.Insert(member.TikvPodName(tc.Name, ordinal))
+	}
+	for _, store := range tc.Status.TiKV.Stores {
+		switch {
+		case removedPods.Has(store.PodName):
+			return fmt.Errorf("TiKV store %s of pod %s is %s, it must be tombstone before the pod is removed", store.ID, store.PodName, store.State)
+		case restartedPods.Has(store.PodName):
+			if store.LeaderCount > 0 {
+				return fmt.Errorf("TiKV store %s of pod %s still holds %d region leaders, they must be evicted before the pod is restarted", store.ID, store.PodName, store.LeaderCount)
+			}
+		case len(restarted) > 0 && store.State != v1alpha1.TiKVStateUp:
+			return fmt.Errorf("TiKV store %s of pod %s is %s, refuse to restart other stores", store.ID, store.PodName, store.State)
+		}
+	}
+	return nil
+}
+
+// checkTiDBHealth refuses the changes which would leave no healthy TiDB member
+func checkTiDBHealth(tc *v1alpha1.TidbCluster, restarted, removed []int32) error {
+	if len(tc.Status.TiDB.Members) == 0 {
+		return nil
+	}
+	affected := sets.NewString()
+	for _, ordinal := range append(restarted, removed...) {
+		name, err := member.MemberPodName(tc.Name, v1alpha1.TiDBClusterKind, ordinal, v1alpha1.TiDBMemberType)
+		if err != nil {
+			return err
+		}
+		affected.Insert(name)
+	}
+	for name, m := range tc.Status.TiDB.Members {
+		if m.Health && !affected.Has(name) {
+			return nil
+		}
+	}
+	return fmt.Errorf("no healthy TiDB member would be left")
+}
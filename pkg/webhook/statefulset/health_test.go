@@ -0,0 +1,173 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statefulset
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	"github.com/pingcap/tidb-operator/pkg/label"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTidbClusterForHealth() *v1alpha1.TidbCluster {
+	return &v1alpha1.TidbCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ownerTCName,
+			Namespace: "default",
+		},
+		Status: v1alpha1.TidbClusterStatus{
+			PD: v1alpha1.PDStatus{
+				Members: map[string]v1alpha1.PDMember{
+					"foo-pd-0": {Name: "foo-pd-0", Health: true},
+					"foo-pd-1": {Name: "foo-pd-1", Health: true},
+					"foo-pd-2": {Name: "foo-pd-2", Health: true},
+				},
+			},
+			TiKV: v1alpha1.TiKVStatus{
+				Stores: map[string]v1alpha1.TiKVStore{
+					"1": {ID: "1", PodName: "foo-tikv-0", State: v1alpha1.TiKVStateUp},
+					"2": {ID: "2", PodName: "foo-tikv-1", State: v1alpha1.TiKVStateUp},
+					"3": {ID: "3", PodName: "foo-tikv-2", State: v1alpha1.TiKVStateUp, LeaderCount: 10},
+				},
+			},
+			TiDB: v1alpha1.TiDBStatus{
+				Members: map[string]v1alpha1.TiDBMember{
+					"foo-tidb-0": {Name: "foo-tidb-0", Health: true},
+					"foo-tidb-1": {Name: "foo-tidb-1", Health: false},
+				},
+			},
+		},
+	}
+}
+
+func TestAffectedOrdinals(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	restarted, removed := affectedOrdinals(stsScale{replicas: 3, partition: 3}, stsScale{replicas: 3, partition: 1})
+	g.Expect(restarted).Should(Equal([]int32{1, 2}))
+	g.Expect(removed).Should(BeEmpty())
+
+	restarted, removed = affectedOrdinals(stsScale{replicas: 3, partition: 3}, stsScale{replicas: 2, partition: 3})
+	g.Expect(restarted).Should(BeEmpty())
+	g.Expect(removed).Should(Equal([]int32{2}))
+
+	restarted, removed = affectedOrdinals(stsScale{replicas: 3, partition: 0}, stsScale{replicas: 4, partition: 2})
+	g.Expect(restarted).Should(BeEmpty())
+	g.Expect(removed).Should(BeEmpty())
+}
+
+func TestCheckMemberHealth(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	tests := []struct {
+		name      string
+		component string
+		changeTc  func(tc *v1alpha1.TidbCluster)
+		old       stsScale
+		new       stsScale
+		wantErr   bool
+	}{
+		{
+			name:      "restart a pd member in a healthy cluster",
+			component: label.PDLabelVal,
+			old:       stsScale{replicas: 3, partition: 3},
+			new:       stsScale{replicas: 3, partition: 2},
+			wantErr:   false,
+		},
+		{
+			name:      "restart a pd member when another member is unhealthy",
+			component: label.PDLabelVal,
+			changeTc: func(tc *v1alpha1.TidbCluster) {
+				m := tc.Status.PD.Members["foo-pd-0"]
+				m.Health = false
+				tc.Status.PD.Members["foo-pd-0"] = m
+			},
+			old:     stsScale{replicas: 3, partition: 3},
+			new:     stsScale{replicas: 3, partition: 2},
+			wantErr: true,
+		},
+		{
+			name:      "restart a tikv store without leaders",
+			component: label.TiKVLabelVal,
+			old:       stsScale{replicas: 3, partition: 2},
+			new:       stsScale{replicas: 3, partition: 1},
+			wantErr:   false,
+		},
+		{
+			name:      "restart a tikv store holding leaders",
+			component: label.TiKVLabelVal,
+			old:       stsScale{replicas: 3, partition: 3},
+			new:       stsScale{replicas: 3, partition: 2},
+			wantErr:   true,
+		},
+		{
+			name:      "restart a tikv store when another store is down",
+			component: label.TiKVLabelVal,
+			changeTc: func(tc *v1alpha1.TidbCluster) {
+				store := tc.Status.TiKV.Stores["1"]
+				store.State = v1alpha1.TiKVStateDown
+				tc.Status.TiKV.Stores["1"] = store
+			},
+			old:     stsScale{replicas: 3, partition: 2},
+			new:     stsScale{replicas: 3, partition: 1},
+			wantErr: true,
+		},
+		{
+			name:      "remove a tikv store which is not tombstone",
+			component: label.TiKVLabelVal,
+			old:       stsScale{replicas: 3, partition: 3},
+			new:       stsScale{replicas: 2, partition: 3},
+			wantErr:   true,
+		},
+		{
+			name:      "restart the last healthy tidb",
+			component: label.TiDBLabelVal,
+			old:       stsScale{replicas: 2, partition: 1},
+			new:       stsScale{replicas: 2, partition: 0},
+			wantErr:   true,
+		},
+		{
+			name:      "restart an unhealthy tidb",
+			component: label.TiDBLabelVal,
+			old:       stsScale{replicas: 2, partition: 2},
+			new:       stsScale{replicas: 2, partition: 1},
+			wantErr:   false,
+		},
+		{
+			name:      "no restart or removal",
+			component: label.TiKVLabelVal,
+			old:       stsScale{replicas: 3, partition: 0},
+			new:       stsScale{replicas: 4, partition: 0},
+			wantErr:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Log(tt.name)
+
+		tc := newTidbClusterForHealth()
+		if tt.changeTc != nil {
+			tt.changeTc(tc)
+		}
+		l := label.Label{label.ComponentLabelKey: tt.component}
+		err := checkMemberHealth(tc, l, tt.old, tt.new)
+		if tt.wantErr {
+			g.Expect(err).Should(HaveOccurred())
+		} else {
+			g.Expect(err).ShouldNot(HaveOccurred())
+		}
+	}
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/openshift/generic-admission-server/pkg/apiserver"
 	asapps "github.com/pingcap/advanced-statefulset/client/apis/apps/v1"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/client/clientset/versioned"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	"github.com/pingcap/tidb-operator/pkg/features"
@@ -30,6 +31,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog"
 )
@@ -43,12 +45,17 @@ type StatefulSetAdmissionControl struct {
 	initialized bool
 	// operator client interface
 	operatorCli versioned.Interface
+	// the service accounts of tidb-operator, the requests sent by them are not checked against
+	// the member health as the upgrader and the scaler have already checked it
+	operatorServiceAccounts sets.String
 }
 
 var _ apiserver.ValidatingAdmissionHook = &StatefulSetAdmissionControl{}
 
-func NewStatefulSetAdmissionControl() *StatefulSetAdmissionControl {
-	return &StatefulSetAdmissionControl{}
+func NewStatefulSetAdmissionControl(operatorServiceAccounts []string) *StatefulSetAdmissionControl {
+	return &StatefulSetAdmissionControl{
+		operatorServiceAccounts: sets.NewString(operatorServiceAccounts...),
+	}
 }
 
 func (sc *StatefulSetAdmissionControl) ValidatingResource() (plural schema.GroupVersionResource, singular string) {
@@ -89,8 +96,8 @@ func (sc *StatefulSetAdmissionControl) Validate(ar *admission.AdmissionRequest)
 
 	l := label.Label(stsObjectMeta.Labels)
 
-	if !(l.IsTiDB() || l.IsTiKV()) {
-		// If it is not statefulset of tikv and tidb, return quickly.
+	if !(l.IsTiDB() || l.IsTiKV() || l.IsPD()) {
+		// If it is not statefulset of pd, tikv and tidb, return quickly.
 		return util.ARSuccess()
 	}
 
@@ -98,7 +105,7 @@ func (sc *StatefulSetAdmissionControl) Validate(ar *admission.AdmissionRequest)
 	if controllerRef == nil || controllerRef.Kind != controller.ControllerKind.Kind {
 		// In this case, we can't tell if this statefulset is controlled by tidb-operator,
 		// so we don't block this statefulset upgrade, return directly.
-		klog.Warningf("statefulset %s/%s has tidb or tikv component label but doesn't have owner reference or the owner reference is not TidbCluster", namespace, name)
+		klog.Warningf("statefulset %s/%s has pd, tidb or tikv component label but doesn't have owner reference or the owner reference is not TidbCluster", namespace, name)
 		return util.ARSuccess()
 	}
 
@@ -110,6 +117,14 @@ func (sc *StatefulSetAdmissionControl) Validate(ar *admission.AdmissionRequest)
 		return util.ARFail(err)
 	}
 
+	if err := sc.checkMemberHealth(ar, tc, l); err != nil {
+		klog.Infof("refuse to update statefulset %s/%s: %v", namespace, name, err)
+		return util.ARFail(fmt.Errorf("statefulset %s/%s: %v", namespace, name, err))
+	}
+
+	if l.IsPD() {
+		return util.ARSuccess()
+	}
 	annKey := label.AnnTiDBPartition
 	if l.IsTiKV() {
 		annKey = label.AnnTiKVPartition
@@ -138,6 +153,23 @@ func (sc *StatefulSetAdmissionControl) Validate(ar *admission.AdmissionRequest)
 	return util.ARSuccess()
 }
 
+// checkMemberHealth checks the partition and replicas changes of the statefulsets edited directly
+// by users or other controllers against the member health in the TidbCluster status
+func (sc *StatefulSetAdmissionControl) checkMemberHealth(ar *admission.AdmissionRequest, tc *v1alpha1.TidbCluster, l label.Label) error {
+	if sc.operatorServiceAccounts.Has(ar.UserInfo.Username) || len(ar.OldObject.Raw) == 0 {
+		return nil
+	}
+	oldScale, err := getStsScale(ar.OldObject.Raw)
+	if err != nil {
+		return fmt.Errorf("decode old object failed, err: %v", err)
+	}
+	newScale, err := getStsScale(ar.Object.Raw)
+	if err != nil {
+		return fmt.Errorf("decode object failed, err: %v", err)
+	}
+	return checkMemberHealth(tc, l, oldScale, newScale)
+}
+
 // Initialize implements AdmissionHook.Initialize interface. It's is called as
 // a post-start hook.
 func (a *StatefulSetAdmissionControl) Initialize(cfg *rest.Config, stopCh <-chan struct{}) error {
@@ -175,3 +207,37 @@ func getStsAttributes(data []byte) (*metav1.ObjectMeta, *int32, error) {
 	}
 	return &(set.ObjectMeta), nil, nil
 }
+
+// getStsScale returns the replicas and the partition of the statefulset,
+// the partition is 0 if it's not set, which means all pods would be updated
+func getStsScale(data []byte) (stsScale, error) {
+	scale := stsScale{}
+	var replicas *int32
+	var rollingUpdate *apps.RollingUpdateStatefulSetStrategy
+	if !features.DefaultFeatureGate.Enabled(features.AdvancedStatefulSet) {
+		set := apps.StatefulSet{}
+		if _, _, err := deserializer.Decode(data, nil, &set); err != nil {
+			return scale, err
+		}
+		replicas = set.Spec.Replicas
+		rollingUpdate = set.Spec.UpdateStrategy.RollingUpdate
+	} else {
+		set := asapps.StatefulSet{}
+		if _, _, err := deserializer.Decode(data, nil, &set); err != nil {
+			return scale, err
+		}
+		replicas = set.Spec.Replicas
+		if set.Spec.UpdateStrategy.RollingUpdate != nil {
+			rollingUpdate = &apps.RollingUpdateStatefulSetStrategy{Partition: set.Spec.UpdateStrategy.RollingUpdate.Partition}
+		}
+	}
+	// replicas defaults to 1
+	scale.replicas = 1
+	if replicas != nil {
+		scale.replicas = *replicas
+	}
+	if rollingUpdate != nil && rollingUpdate.Partition != nil {
+		scale.partition = *rollingUpdate.Partition
+	}
+	return scale, nil
+}
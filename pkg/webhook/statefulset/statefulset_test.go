@@ -230,7 +230,7 @@ func runTest(t *testing.T, tt testcase, asts bool) {
 	}
 
 	cli := fake.NewSimpleClientset()
-	ac := NewStatefulSetAdmissionControl()
+	ac := NewStatefulSetAdmissionControl(nil)
 	ac.initialized = true
 	ac.operatorCli = cli
 	ar := &admission.AdmissionRequest{
@@ -289,7 +289,7 @@ func TestStatefulSetAdmissionControl_ASTS(t *testing.T) {
 }
 
 func TestValidatingResource(t *testing.T) {
-	w := NewStatefulSetAdmissionControl()
+	w := NewStatefulSetAdmissionControl(nil)
 	wantGvr := schema.GroupVersionResource{
 		Group:    "admission.tidb.pingcap.com",
 		Version:  "v1alpha1",
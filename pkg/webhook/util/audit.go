@@ -0,0 +1,136 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+
+	"github.com/openshift/generic-admission-server/pkg/apiserver"
+	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
+	admission "k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	eventv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog"
+)
+
+const (
+	// AuditViolationReason is the reason of the events emitted for the requests
+	// which would be denied if the webhooks were not in audit mode
+	AuditViolationReason = "AdmissionAuditViolation"
+	// AuditAnnotationKey is the key of the audit annotation recording the violation
+	AuditAnnotationKey = "violation"
+)
+
+// AuditValidatingHook wraps a ValidatingAdmissionHook and switches it into audit mode:
+// the requests which would be denied by the wrapped hook are logged, emitted as events
+// and recorded in the audit annotations of the response, but they are allowed.
+// It makes it possible to roll out new validations safely before enforcing them.
+type AuditValidatingHook struct {
+	apiserver.ValidatingAdmissionHook
+	recorder record.EventRecorder
+}
+
+var _ apiserver.ValidatingAdmissionHook = &AuditValidatingHook{}
+
+// NewAuditValidatingHook returns the audit mode wrapper of the given hook
+func NewAuditValidatingHook(hook apiserver.ValidatingAdmissionHook) *AuditValidatingHook {
+	return &AuditValidatingHook{ValidatingAdmissionHook: hook}
+}
+
+// WithAuditMode switches the validation of the hook into audit mode, the mutation of the hook is not affected
+func WithAuditMode(hook apiserver.AdmissionHook) apiserver.AdmissionHook {
+	validatingHook, ok := hook.(apiserver.ValidatingAdmissionHook)
+	if !ok {
+		return hook
+	}
+	auditHook := NewAuditValidatingHook(validatingHook)
+	if mutatingHook, ok := hook.(apiserver.MutatingAdmissionHook); ok {
+		return &auditMutatingHook{AuditValidatingHook: auditHook, mutatingHook: mutatingHook}
+	}
+	return auditHook
+}
+
+// auditMutatingHook is the audit mode wrapper of the hooks which are both validating and mutating
+type auditMutatingHook struct {
+	*AuditValidatingHook
+	mutatingHook apiserver.MutatingAdmissionHook
+}
+
+var _ apiserver.MutatingAdmissionHook = &auditMutatingHook{}
+
+func (h *auditMutatingHook) MutatingResource() (plural schema.GroupVersionResource, singular string) {
+	return h.mutatingHook.MutatingResource()
+}
+
+func (h *auditMutatingHook) Admit(ar *admission.AdmissionRequest) *admission.AdmissionResponse {
+	return h.mutatingHook.Admit(ar)
+}
+
+// Validate validates the request by the wrapped hook and always allows it
+func (h *AuditValidatingHook) Validate(ar *admission.AdmissionRequest) *admission.AdmissionResponse {
+	resp := h.ValidatingAdmissionHook.Validate(ar)
+	if resp == nil || resp.Allowed {
+		return resp
+	}
+
+	message := "denied without a message"
+	if resp.Result != nil && resp.Result.Message != "" {
+		message = resp.Result.Message
+	}
+	resource, _ := h.ValidatingAdmissionHook.ValidatingResource()
+	violation := fmt.Sprintf("%s would deny %s of %s %s/%s: %s", resource.Resource, ar.Operation, ar.Kind.Kind, ar.Namespace, ar.Name, message)
+	klog.Warningf("[audit] %s", violation)
+	if h.recorder != nil && ar.Name != "" {
+		ref := &corev1.ObjectReference{
+			APIVersion: schema.GroupVersion{Group: ar.Kind.Group, Version: ar.Kind.Version}.String(),
+			Kind:       ar.Kind.Kind,
+			Namespace:  ar.Namespace,
+			Name:       ar.Name,
+		}
+		h.recorder.Event(ref, corev1.EventTypeWarning, AuditViolationReason, violation)
+	}
+
+	return &admission.AdmissionResponse{
+		UID:     resp.UID,
+		Allowed: true,
+		Result: &metav1.Status{
+			Message: violation,
+		},
+		AuditAnnotations: map[string]string{
+			AuditAnnotationKey: violation,
+		},
+	}
+}
+
+// Initialize implements AdmissionHook.Initialize interface. It initializes the wrapped
+// hook and the event recorder.
+func (h *AuditValidatingHook) Initialize(cfg *rest.Config, stopCh <-chan struct{}) error {
+	if err := h.ValidatingAdmissionHook.Initialize(cfg, stopCh); err != nil {
+		return err
+	}
+	kubeCli, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&eventv1.EventSinkImpl{
+		Interface: eventv1.New(kubeCli.CoreV1().RESTClient()).Events("")})
+	h.recorder = eventBroadcaster.NewRecorder(v1alpha1.Scheme, corev1.EventSource{Component: "tidb-admission-controller"})
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/openshift/generic-admission-server/pkg/apiserver"
+	admission "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+)
+
+type fakeValidatingHook struct {
+	resp *admission.AdmissionResponse
+}
+
+func (h *fakeValidatingHook) ValidatingResource() (plural schema.GroupVersionResource, singular string) {
+	return schema.GroupVersionResource{Group: "admission.tidb.pingcap.com", Version: "v1alpha1", Resource: "fakevalidations"}, "fakevalidation"
+}
+
+func (h *fakeValidatingHook) Validate(ar *admission.AdmissionRequest) *admission.AdmissionResponse {
+	return h.resp
+}
+
+func (h *fakeValidatingHook) Initialize(cfg *rest.Config, stopCh <-chan struct{}) error {
+	return nil
+}
+
+func TestAuditValidatingHook(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	ar := &admission.AdmissionRequest{
+		Name:      "tc",
+		Namespace: "ns",
+		Operation: admission.Update,
+		Kind:      metav1.GroupVersionKind{Group: "pingcap.com", Version: "v1alpha1", Kind: "TidbCluster"},
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	hook := NewAuditValidatingHook(&fakeValidatingHook{resp: ARSuccess()})
+	hook.recorder = recorder
+	resp := hook.Validate(ar)
+	g.Expect(resp.Allowed).Should(BeTrue())
+	g.Expect(resp.AuditAnnotations).Should(BeEmpty())
+	g.Expect(recorder.Events).Should(BeEmpty())
+
+	hook = NewAuditValidatingHook(&fakeValidatingHook{resp: ARFail(errors.New("invalid spec"))})
+	hook.recorder = recorder
+	resp = hook.Validate(ar)
+	g.Expect(resp.Allowed).Should(BeTrue())
+	g.Expect(resp.AuditAnnotations).Should(HaveKey(AuditAnnotationKey))
+	g.Expect(resp.AuditAnnotations[AuditAnnotationKey]).Should(ContainSubstring("invalid spec"))
+	g.Expect(recorder.Events).Should(HaveLen(1))
+	event := <-recorder.Events
+	g.Expect(event).Should(ContainSubstring(AuditViolationReason))
+}
+
+func TestWithAuditMode(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	hook := WithAuditMode(&fakeValidatingHook{resp: ARFail(errors.New("invalid spec"))})
+	_, ok := hook.(apiserver.ValidatingAdmissionHook)
+	g.Expect(ok).Should(BeTrue())
+	_, ok = hook.(apiserver.MutatingAdmissionHook)
+	g.Expect(ok).Should(BeFalse())
+}
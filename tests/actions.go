@@ -128,7 +128,7 @@ func NewOperatorActions(cli versioned.Interface,
 		framework.ExpectNoError(err, "failed to load config")
 		oa.tidbControl = proxiedtidbclient.NewProxiedTiDBClient(fw, kubeCfg.TLSClientConfig.CAData)
 	} else {
-		oa.tidbControl = controller.NewDefaultTiDBControl(kubeCli)
+		oa.tidbControl = controller.NewDefaultTiDBControl(kubeCli, pdapi.NewTLSConfigCache(kubeCli))
 	}
 	oa.clusterEvents = make(map[string]*clusterEvent)
 	for _, c := range clusters {
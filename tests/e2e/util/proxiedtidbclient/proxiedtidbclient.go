@@ -23,6 +23,7 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/pingcap/tidb-operator/pkg/apis/pingcap/v1alpha1"
 	"github.com/pingcap/tidb-operator/pkg/controller"
 	httputil "github.com/pingcap/tidb-operator/pkg/util/http"
@@ -46,6 +47,22 @@ func (p *proxiedTiDBClient) GetInfo(tc *v1alpha1.TidbCluster, ordinal int32) (*c
 	panic("implement when necessary")
 }
 
+func (p *proxiedTiDBClient) GetStatus(tc *v1alpha1.TidbCluster, ordinal int32) (*controller.TiDBStatus, error) {
+	panic("implement when necessary")
+}
+
+func (p *proxiedTiDBClient) ResignDDLOwner(tc *v1alpha1.TidbCluster, ordinal int32) (bool, error) {
+	panic("implement when necessary")
+}
+
+func (p *proxiedTiDBClient) ProbeSQL(tc *v1alpha1.TidbCluster, ordinal int32) error {
+	panic("implement when necessary")
+}
+
+func (p *proxiedTiDBClient) SetReadOnly(ns string, cfg *mysql.Config, tlsSecretName string, readOnly bool) error {
+	panic("implement when necessary")
+}
+
 func (p *proxiedTiDBClient) GetSettings(tc *v1alpha1.TidbCluster, ordinal int32) (*config.Config, error) {
 	tcName := tc.GetName()
 	ns := tc.GetNamespace()